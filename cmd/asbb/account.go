@@ -5,8 +5,15 @@
 package main
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 	"time"
 
@@ -31,20 +38,39 @@ Examples:
   asbb account create --name="Research" --account=proj001 --incremental --total-budget=600 --allocation-amount=100 --allocation-frequency=monthly --start=2025-01-01
 
   # Show account details
-  asbb account show proj001`,
+  asbb account show proj001
+
+  # Start a new account from an existing one
+  asbb account clone proj001 --account=proj002 --start=2026-01-01 --end=2026-12-31
+
+  # Create accounts in bulk from a CSV file
+  asbb account import --file=accounts.csv
+
+  # Back up an account and restore it later
+  asbb account export proj001 --output=proj001.json
+  asbb account import --backup=proj001.json`,
 }
 
+var listAccountTags []string
+
 var accountListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List budget accounts",
-	Long:  "List all budget accounts with their current status and usage information.",
+	Long: `List all budget accounts with their current status and usage information.
+
+Examples:
+  # List the accounts of one department
+  asbb account list --tag=department=physics
+
+  # List course accounts that have any funding source tag
+  asbb account list --tag=kind=course --tag=funding`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := getAPIClient()
 		if err != nil {
 			return fmt.Errorf("failed to create API client: %w", err)
 		}
 
-		accounts, err := client.ListAccounts(cmd.Context(), &api.ListAccountsRequest{})
+		accounts, err := client.ListAccounts(cmd.Context(), &api.ListAccountsRequest{Tags: listAccountTags})
 		if err != nil {
 			return fmt.Errorf("failed to list accounts: %w", err)
 		}
@@ -68,13 +94,13 @@ var accountListCmd = &cobra.Command{
 		}
 
 		for _, account := range accounts {
-			if _, err := fmt.Fprintf(w, "%s\t%s\t$%.2f\t$%.2f\t$%.2f\t$%.2f\t%s\t%t\n",
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%t\n",
 				account.SlurmAccount,
 				account.Name,
-				account.BudgetLimit,
-				account.BudgetUsed,
-				account.BudgetHeld,
-				account.BudgetAvailable(),
+				formatAccountAmount(account, account.BudgetLimit),
+				formatAccountAmount(account, account.BudgetUsed),
+				formatAccountAmount(account, account.BudgetHeld),
+				formatAccountAmount(account, account.BudgetAvailable()),
 				account.Status,
 				account.HasIncrementalBudget,
 			); err != nil {
@@ -87,16 +113,25 @@ var accountListCmd = &cobra.Command{
 }
 
 var (
-	createAccountName        string
-	createAccountAccount     string
-	createAccountDescription string
-	createAccountBudget      float64
-	createAccountStart       string
-	createAccountEnd         string
-	createIncremental        bool
-	createTotalBudget        float64
-	createAllocationAmount   float64
-	createAllocationFreq     string
+	createAccountName         string
+	createAccountAccount      string
+	createAccountDescription  string
+	createAccountBudget       float64
+	createAccountStart        string
+	createAccountEnd          string
+	createIncremental         bool
+	createTotalBudget         float64
+	createAllocationAmount    float64
+	createAllocationFreq      string
+	createHoldPercentage      float64
+	createAccountTimezone     string
+	createAccountFiscalStart  string
+	createAccountParent       string
+	createAccountTags         []string
+	createAccountEstimation   string
+	createAccountPartitions   []string
+	createAccountUnit         string
+	createAccountDollarsPerSU float64
 )
 
 var accountCreateCmd = &cobra.Command{
@@ -108,6 +143,15 @@ Examples:
   # Create simple account
   asbb account create --name="Research" --account=proj001 --budget=1000 --start=2025-01-01 --end=2025-12-31
 
+  # Create a tagged course account that may only use the shared CPU partition
+  asbb account create --name="Physics 101" --account=phys101 --budget=300 --start=2025-09-01 --end=2025-12-31 --tag=kind=course --tag=department=physics --allowed-partition=cpu
+
+  # Create an account allocated 50,000 SU, with job costs converted at $0.02 per SU
+  asbb account create --name="Allocation" --account=alloc01 --budget=50000 --unit=su --dollars-per-su=0.02 --start=2025-01-01 --end=2025-12-31
+
+  # Create a project account that also draws on its department's budget
+  asbb account create --name="Project A" --account=proj001 --parent=dept01 --budget=500 --start=2025-01-01 --end=2025-12-31
+
   # Create account with monthly incremental allocation
   asbb account create --name="Research" --account=proj001 --incremental --total-budget=1200 --allocation-amount=100 --allocation-frequency=monthly --start=2025-01-01`,
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -116,13 +160,18 @@ Examples:
 			return fmt.Errorf("failed to create API client: %w", err)
 		}
 
-		// Parse dates
-		startDate, err := time.Parse("2006-01-02", createAccountStart)
+		// Parse dates as local midnight in the account's time zone
+		loc, err := api.LoadTimezone(createAccountTimezone)
+		if err != nil {
+			return err
+		}
+
+		startDate, err := time.ParseInLocation("2006-01-02", createAccountStart, loc)
 		if err != nil {
 			return fmt.Errorf("invalid start date format (use YYYY-MM-DD): %w", err)
 		}
 
-		endDate, err := time.Parse("2006-01-02", createAccountEnd)
+		endDate, err := time.ParseInLocation("2006-01-02", createAccountEnd, loc)
 		if err != nil {
 			return fmt.Errorf("invalid end date format (use YYYY-MM-DD): %w", err)
 		}
@@ -135,6 +184,24 @@ Examples:
 			StartDate:            startDate,
 			EndDate:              endDate,
 			HasIncrementalBudget: createIncremental,
+			Timezone:             createAccountTimezone,
+			FiscalYearStart:      createAccountFiscalStart,
+			ParentAccount:        createAccountParent,
+			EstimationSource:     createAccountEstimation,
+			BudgetUnit:           createAccountUnit,
+		}
+
+		if cmd.Flags().Changed("hold-percentage") {
+			req.HoldPercentage = &createHoldPercentage
+		}
+		if cmd.Flags().Changed("dollars-per-su") {
+			req.DollarsPerSU = &createAccountDollarsPerSU
+		}
+		if req.Tags, err = parseTags(createAccountTags); err != nil {
+			return err
+		}
+		if partitions := parsePartitions(createAccountPartitions); len(partitions) > 0 {
+			req.AllowedPartitions = partitions
 		}
 
 		// Add allocation schedule if incremental
@@ -163,18 +230,225 @@ Examples:
 		fmt.Printf("✅ Budget account created successfully!\n")
 		fmt.Printf("Account: %s\n", account.SlurmAccount)
 		fmt.Printf("Name: %s\n", account.Name)
-		fmt.Printf("Budget Limit: $%.2f\n", account.BudgetLimit)
+		fmt.Printf("Budget Limit: %s\n", formatMoney(account.BudgetLimit))
 		if account.HasIncrementalBudget {
-			fmt.Printf("Incremental Budget: $%.2f total, $%.2f per %s\n",
-				createTotalBudget, createAllocationAmount, createAllocationFreq)
+			fmt.Printf("Incremental Budget: %s total, %s per %s\n",
+				formatMoney(createTotalBudget), formatMoney(createAllocationAmount), createAllocationFreq)
 		}
-		fmt.Printf("Period: %s to %s\n", account.StartDate.Format("2006-01-02"), account.EndDate.Format("2006-01-02"))
+		if account.HoldPercentage != nil {
+			fmt.Printf("Hold Percentage: %.2f\n", *account.HoldPercentage)
+		}
+		fmt.Printf("Period: %s to %s\n", account.StartDate.In(account.Location()).Format("2006-01-02"), account.EndDate.In(account.Location()).Format("2006-01-02"))
 		fmt.Printf("Status: %s\n", account.Status)
 
 		return nil
 	},
 }
 
+var (
+	updateAccountName           string
+	updateAccountDescription    string
+	updateAccountBudget         float64
+	updateAccountStatus         string
+	updateAccountHoldPercentage float64
+	updateAccountReserved       float64
+	updateAccountTimezone       string
+	updateAccountBurnRate       bool
+	updateAccountFrozen         bool
+	updateAccountFiscalStart    string
+	updateAccountParent         string
+	updateAccountTags           []string
+	updateAccountEstimation     string
+	updateAccountPartitions     []string
+	updateAccountDollarsPerSU   float64
+)
+
+var accountUpdateCmd = &cobra.Command{
+	Use:   "update <account>",
+	Short: "Update a budget account",
+	Long: `Update settings on an existing budget account. Only flags that are given are changed.
+
+Examples:
+  # Use a tighter hold for a well-characterized production pipeline
+  asbb account update proj001 --hold-percentage=1.05
+
+  # Raise the budget limit
+  asbb account update proj001 --budget=2500
+
+  # Hold back $500 for end-of-grant obligations
+  asbb account update proj001 --reserved=500
+
+  # Move a project under a department; --parent="" detaches it
+  asbb account update proj001 --parent=dept01
+
+  # Stop new bursting while running jobs finish and reconcile
+  asbb account update proj001 --frozen
+
+  # Replace the account's tags; --tag="" clears them
+  asbb account update proj001 --tag=department=chemistry --tag=kind=research
+
+  # Restrict jobs to the CPU partitions; --allowed-partition="" allows every partition
+  asbb account update phys101 --allowed-partition=cpu --allowed-partition=cpu-spot`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		req := &api.UpdateAccountRequest{}
+		if cmd.Flags().Changed("name") {
+			req.Name = &updateAccountName
+		}
+		if cmd.Flags().Changed("description") {
+			req.Description = &updateAccountDescription
+		}
+		if cmd.Flags().Changed("budget") {
+			req.BudgetLimit = &updateAccountBudget
+		}
+		if cmd.Flags().Changed("status") {
+			req.Status = &updateAccountStatus
+		}
+		if cmd.Flags().Changed("hold-percentage") {
+			req.HoldPercentage = &updateAccountHoldPercentage
+		}
+		if cmd.Flags().Changed("reserved") {
+			req.ReservedAmount = &updateAccountReserved
+		}
+		if cmd.Flags().Changed("timezone") {
+			req.Timezone = &updateAccountTimezone
+		}
+		if cmd.Flags().Changed("burn-rate") {
+			req.BurnRateEnabled = &updateAccountBurnRate
+		}
+		if cmd.Flags().Changed("frozen") {
+			req.Frozen = &updateAccountFrozen
+		}
+		if cmd.Flags().Changed("fiscal-year-start") {
+			req.FiscalYearStart = &updateAccountFiscalStart
+		}
+		if cmd.Flags().Changed("parent") {
+			req.ParentAccount = &updateAccountParent
+		}
+		if cmd.Flags().Changed("estimation-source") {
+			req.EstimationSource = &updateAccountEstimation
+		}
+		if cmd.Flags().Changed("tag") {
+			tags, err := parseTags(updateAccountTags)
+			if err != nil {
+				return err
+			}
+			req.Tags = tags
+		}
+		if cmd.Flags().Changed("allowed-partition") {
+			req.AllowedPartitions = parsePartitions(updateAccountPartitions)
+		}
+		if cmd.Flags().Changed("dollars-per-su") {
+			req.DollarsPerSU = &updateAccountDollarsPerSU
+		}
+
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		account, err := client.UpdateAccount(cmd.Context(), args[0], req)
+		if err != nil {
+			return fmt.Errorf("failed to update account: %w", err)
+		}
+
+		fmt.Printf("✅ Budget account %s updated\n", account.SlurmAccount)
+		return nil
+	},
+}
+
+var (
+	cloneAccountAccount     string
+	cloneAccountName        string
+	cloneAccountDescription string
+	cloneAccountBudget      float64
+	cloneAccountStart       string
+	cloneAccountEnd         string
+	cloneAccountSchedule    bool
+)
+
+var accountCloneCmd = &cobra.Command{
+	Use:   "clone <source-account>",
+	Short: "Create an account from an existing one",
+	Long: `Create a new account with the source account's settings, parent, tags, allowed
+partitions and partition limits. Name, description, dates and budget follow the source
+unless given. The new account starts with nothing used or held.
+
+Examples:
+  # Start next year's project from this year's
+  asbb account clone lab-2025 --account=lab-2026 --name="Lab 2026" --start=2026-01-01 --end=2026-12-31
+
+  # Copy the monthly allocation schedule too, restarted from the new start date
+  asbb account clone lab-2025 --account=lab-2026 --start=2026-01-01 --end=2026-12-31 --copy-schedule`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		req := &api.CloneAccountRequest{
+			SlurmAccount:           cloneAccountAccount,
+			Name:                   cloneAccountName,
+			CopyAllocationSchedule: cloneAccountSchedule,
+		}
+		if cmd.Flags().Changed("description") {
+			req.Description = &cloneAccountDescription
+		}
+		if cmd.Flags().Changed("budget") {
+			req.BudgetLimit = &cloneAccountBudget
+		}
+
+		if cloneAccountStart != "" || cloneAccountEnd != "" {
+			// Parse dates as local midnight in the source account's time zone, which the
+			// clone keeps
+			source, err := client.GetAccount(cmd.Context(), args[0])
+			if err != nil {
+				return fmt.Errorf("failed to get source account: %w", err)
+			}
+			if cloneAccountStart != "" {
+				startDate, err := time.ParseInLocation("2006-01-02", cloneAccountStart, source.Location())
+				if err != nil {
+					return fmt.Errorf("invalid start date format (use YYYY-MM-DD): %w", err)
+				}
+				req.StartDate = &startDate
+			}
+			if cloneAccountEnd != "" {
+				endDate, err := time.ParseInLocation("2006-01-02", cloneAccountEnd, source.Location())
+				if err != nil {
+					return fmt.Errorf("invalid end date format (use YYYY-MM-DD): %w", err)
+				}
+				req.EndDate = &endDate
+			}
+		}
+
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		resp, err := client.CloneAccount(cmd.Context(), args[0], req)
+		if err != nil {
+			return fmt.Errorf("failed to clone account: %w", err)
+		}
+
+		account := resp.Account
+		fmt.Printf("✅ Budget account %s cloned from %s\n", account.SlurmAccount, resp.SourceAccount)
+		fmt.Printf("Name: %s\n", account.Name)
+		fmt.Printf("Budget Limit: %s\n", formatMoney(account.BudgetLimit))
+		fmt.Printf("Period: %s to %s\n", account.StartDate.In(account.Location()).Format("2006-01-02"), account.EndDate.In(account.Location()).Format("2006-01-02"))
+		fmt.Printf("Partition Limits Copied: %d\n", resp.PartitionLimitsCopied)
+		if cloneAccountSchedule {
+			fmt.Printf("Allocation Schedules Copied: %d\n", resp.SchedulesCopied)
+		}
+
+		return nil
+	},
+}
+
 var accountShowCmd = &cobra.Command{
 	Use:   "show <account>",
 	Short: "Show detailed account information",
@@ -198,19 +472,49 @@ var accountShowCmd = &cobra.Command{
 		if account.Description != "" {
 			fmt.Printf("Description: %s\n", account.Description)
 		}
+		if account.ParentAccountID != nil {
+			fmt.Printf("Parent Account ID: %d\n", *account.ParentAccountID)
+		}
+		if len(account.Tags) > 0 {
+			fmt.Printf("Tags: %s\n", formatTags(account.Tags))
+		}
+		if len(account.AllowedPartitions) > 0 {
+			fmt.Printf("Allowed Partitions: %s\n", strings.Join(account.AllowedPartitions, ", "))
+		}
 		fmt.Printf("\nBudget Information:\n")
-		fmt.Printf("Limit: $%.2f\n", account.BudgetLimit)
-		fmt.Printf("Used: $%.2f\n", account.BudgetUsed)
-		fmt.Printf("Held: $%.2f\n", account.BudgetHeld)
-		fmt.Printf("Available: $%.2f\n", account.BudgetAvailable())
+		if account.Unit() == api.BudgetUnitSU && account.DollarsPerSU != nil {
+			fmt.Printf("Unit: SU at %s per SU\n", formatMoney(*account.DollarsPerSU))
+		}
+		fmt.Printf("Limit: %s\n", formatAccountAmount(account, account.BudgetLimit))
+		fmt.Printf("Used: %s\n", formatAccountAmount(account, account.BudgetUsed))
+		fmt.Printf("Held: %s\n", formatAccountAmount(account, account.BudgetHeld))
+		fmt.Printf("Available: %s\n", formatAccountAmount(account, account.BudgetAvailable()))
+		if account.ReservedAmount > 0 {
+			fmt.Printf("Reserved: %s\n", formatAccountAmount(account, account.ReservedAmount))
+			fmt.Printf("Available for Jobs: %s\n", formatAccountAmount(account, account.SpendableAvailable()))
+		}
+		if account.HoldPercentage != nil {
+			fmt.Printf("Hold Percentage: %.2f (account override)\n", *account.HoldPercentage)
+		}
 		fmt.Printf("\nAccount Status: %s\n", account.Status)
-		fmt.Printf("Period: %s to %s\n", account.StartDate.Format("2006-01-02"), account.EndDate.Format("2006-01-02"))
+		if account.Frozen {
+			fmt.Printf("Frozen: yes (new jobs are refused)\n")
+		}
+		loc := account.Location()
+		fmt.Printf("Period: %s to %s\n", account.StartDate.In(loc).Format("2006-01-02"), account.EndDate.In(loc).Format("2006-01-02"))
+		fmt.Printf("Time Zone: %s\n", loc)
+		if account.FiscalYearStart != nil {
+			fmt.Printf("Fiscal Year Start: %s (account override)\n", *account.FiscalYearStart)
+		}
+		if account.EstimationSource != nil {
+			fmt.Printf("Estimation Source: %s (account override)\n", *account.EstimationSource)
+		}
 
 		if account.HasIncrementalBudget {
 			fmt.Printf("\nIncremental Budget:\n")
-			fmt.Printf("Total Allocated: $%.2f\n", account.TotalAllocated)
+			fmt.Printf("Total Allocated: %s\n", formatAccountAmount(account, account.TotalAllocated))
 			if account.NextAllocationDate != nil {
-				fmt.Printf("Next Allocation: %s\n", account.NextAllocationDate.Format("2006-01-02 15:04:05"))
+				fmt.Printf("Next Allocation: %s\n", account.NextAllocationDate.In(loc).Format("2006-01-02 15:04:05 MST"))
 			}
 		}
 
@@ -221,8 +525,560 @@ var accountShowCmd = &cobra.Command{
 	},
 }
 
+var (
+	adjustAmount      float64
+	adjustDescription string
+	adjustFromReserve bool
+)
+
+var accountAdjustCmd = &cobra.Command{
+	Use:   "adjust <account>",
+	Short: "Apply an administrative budget adjustment",
+	Long: `Apply an administrative adjustment to an account's used budget. Positive amounts
+spend budget and negative amounts credit it. Reserved funds can only be spent this way,
+using --from-reserve.
+
+Examples:
+  # Pay an end-of-grant storage invoice out of the reserve
+  asbb account adjust proj001 --amount=350 --description="Archive storage" --from-reserve
+
+  # Credit back an incorrect charge
+  asbb account adjust proj001 --amount=-42.50 --description="Duplicate charge for job 1234"`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		req := &api.BudgetAdjustmentRequest{
+			Amount:      adjustAmount,
+			Description: adjustDescription,
+			FromReserve: adjustFromReserve,
+		}
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		resp, err := client.AdjustBudget(cmd.Context(), args[0], req)
+		if err != nil {
+			return fmt.Errorf("failed to adjust budget: %w", err)
+		}
+
+		fmt.Printf("✅ Adjustment %s applied to %s\n", resp.TransactionID, resp.Account.SlurmAccount)
+		fmt.Printf("Used: %s\n", formatMoney(resp.Account.BudgetUsed))
+		fmt.Printf("Reserved: %s\n", formatMoney(resp.Account.ReservedAmount))
+		fmt.Printf("Available for Jobs: %s\n", formatMoney(resp.Account.SpendableAvailable()))
+
+		return nil
+	},
+}
+
+var accountMemberCmd = &cobra.Command{
+	Use:   "member",
+	Short: "Manage the users who may submit under an account",
+	Long: `Manage the users who may submit jobs under an account. With authentication enabled,
+users may only check and reconcile budgets for accounts they are members of. Adding and
+removing members needs an admin API key.
+
+Examples:
+  # Let a researcher submit under a lab's account
+  asbb account member add lab-genomics alice
+
+  # List an account's members
+  asbb account member list lab-genomics
+
+  # Remove a member who has left the lab
+  asbb account member remove lab-genomics alice`,
+}
+
+var accountMemberAddCmd = &cobra.Command{
+	Use:   "add <account> <user>",
+	Short: "Let a user submit under an account",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		member, err := client.AddAccountMember(cmd.Context(), args[0], args[1])
+		if err != nil {
+			return fmt.Errorf("failed to add account member: %w", err)
+		}
+
+		fmt.Printf("✅ %s may submit under %s\n", member.User, member.SlurmAccount)
+		return nil
+	},
+}
+
+var accountMemberRemoveCmd = &cobra.Command{
+	Use:   "remove <account> <user>",
+	Short: "Remove a user from an account's members",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		if err := client.RemoveAccountMember(cmd.Context(), args[0], args[1]); err != nil {
+			return fmt.Errorf("failed to remove account member: %w", err)
+		}
+
+		fmt.Printf("✅ %s removed from %s\n", args[1], args[0])
+		return nil
+	},
+}
+
+var accountMemberListCmd = &cobra.Command{
+	Use:   "list <account>",
+	Short: "List the users who may submit under an account",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		members, err := client.ListAccountMembers(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to list account members: %w", err)
+		}
+
+		if len(members) == 0 {
+			fmt.Printf("No members of %s\n", args[0])
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "USER\tADDED")
+		for _, member := range members {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", member.User, member.CreatedAt.Format("2006-01-02"))
+		}
+		return w.Flush()
+	},
+}
+
+var (
+	simulatePartition  string
+	simulateNodes      int
+	simulateCPUs       int
+	simulateGPUs       int
+	simulateMemory     string
+	simulateWallTime   string
+	simulateCount      int
+	simulatePeriodDays int
+)
+
+var accountSimulateCmd = &cobra.Command{
+	Use:   "simulate <account>",
+	Short: "Project the budget impact of hypothetical jobs",
+	Long: `Estimate what running a batch of identical jobs would cost and project where the
+account's budget lands, including the burn rate and depletion date. Nothing is recorded.
+
+Examples:
+  # 125 four-GPU jobs of one hour each over the next week
+  asbb account simulate proj001 --partition=gpu-aws --nodes=1 --cpus=16 --gpus=4 --wall-time=01:00:00 --count=125`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		req := &api.SimulationRequest{
+			Jobs: []api.SimulatedJob{{
+				Partition: simulatePartition,
+				Nodes:     simulateNodes,
+				CPUs:      simulateCPUs,
+				GPUs:      simulateGPUs,
+				Memory:    simulateMemory,
+				WallTime:  simulateWallTime,
+				Count:     simulateCount,
+			}},
+			PeriodDays: simulatePeriodDays,
+		}
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		resp, err := client.SimulateBudget(cmd.Context(), args[0], req)
+		if err != nil {
+			return fmt.Errorf("failed to simulate budget: %w", err)
+		}
+
+		fmt.Printf("Simulation for %s (%d days)\n", resp.Account, resp.PeriodDays)
+		fmt.Printf("Simulated Cost: %s\n", formatMoney(resp.SimulatedCost))
+		fmt.Printf("Available: %s -> %s\n", formatMoney(resp.CurrentAvailable), formatMoney(resp.ProjectedAvailable))
+		fmt.Printf("Daily Burn Rate: %s -> %s\n", formatMoney(resp.CurrentDailyBurnRate), formatMoney(resp.ProjectedDailyBurnRate))
+		if resp.ProjectedDepletionDate != nil {
+			fmt.Printf("Projected Depletion: %s\n", resp.ProjectedDepletionDate.Format("2006-01-02"))
+		} else {
+			fmt.Printf("Projected Depletion: never at this rate\n")
+		}
+		if resp.DepletesBeforeDeadline {
+			fmt.Printf("⚠️  Budget runs out before %s\n", resp.Deadline.Format("2006-01-02"))
+		}
+		if resp.FailureMode != "" {
+			fmt.Printf("Note: costs are fallback estimates (advisor unavailable)\n")
+		}
+
+		return nil
+	},
+}
+
+var exportAccountOutput string
+
+var accountExportCmd = &cobra.Command{
+	Use:   "export <account>",
+	Short: "Back up an account to a JSON file",
+	Long: `Write a complete copy of an account as JSON: its settings and balances, partition
+limits, allocation schedules and every transaction. Restore it with
+'asbb account import --backup'. Members, budget decisions, alerts and snapshots are
+not included.
+
+Examples:
+  # Back up an account before merging it into another
+  asbb account export proj001 --output=proj001.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		export, err := client.ExportAccount(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to export account: %w", err)
+		}
+
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode export: %w", err)
+		}
+		if exportAccountOutput == "" {
+			fmt.Println(string(data))
+			return nil
+		}
+		if err := os.WriteFile(exportAccountOutput, append(data, '\n'), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", exportAccountOutput, err)
+		}
+
+		fmt.Printf("Exported %s to %s (%d transactions)\n", args[0], exportAccountOutput, len(export.Transactions))
+		return nil
+	},
+}
+
+var (
+	importAccountsFile  string
+	importAccountBackup string
+)
+
+var accountImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Create budget accounts in bulk from a CSV file, or restore one from a backup",
+	Long: `Create budget accounts in bulk from a CSV file, or restore one account from a backup
+written by 'asbb account export'.
+
+A restored account gets back its settings, balances, partition limits, allocation
+schedules and transactions. Its SLURM account must not exist and its parent must.
+
+The CSV file's first row must be a header naming the columns; column order does not
+matter.
+
+Columns:
+  slurm_account      SLURM account name (required)
+  name               Account name (required)
+  description        Account description
+  budget_limit       Budget limit in the account's unit (required)
+  start_date         Start date, YYYY-MM-DD (required)
+  end_date           End date, YYYY-MM-DD (required)
+  hold_percentage    Hold percentage override
+  timezone           IANA time zone for dates and allocations (default UTC)
+  fiscal_year_start  Fiscal year start as MM-DD (default: the service's fiscal year)
+  parent_account     SLURM account of the parent; must already exist or appear earlier
+  estimation_source  Cost model for budget checks: advisor, fallback or static
+  allowed_partitions Partitions jobs may use, separated by ';' (default: all)
+  budget_unit        Unit of the budget and balances: dollars or su (default dollars)
+  dollars_per_su     Dollar cost of one SU; required for su accounts
+
+Every row is attempted even if earlier rows fail. Rows that cannot be parsed and
+accounts that already exist are reported without stopping the import.
+
+Examples:
+  asbb account import --file=accounts.csv
+
+  # Restore an account deleted after its backup was taken
+  asbb account import --backup=proj001.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if importAccountBackup != "" {
+			return restoreAccountBackup(cmd.Context(), importAccountBackup)
+		}
+
+		f, err := os.Open(importAccountsFile)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", importAccountsFile, err)
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to close file: %v\n", err)
+			}
+		}()
+
+		reqs, rowErrs, err := parseAccountsCSV(f)
+		if err != nil {
+			return err
+		}
+
+		for _, rowErr := range rowErrs {
+			fmt.Fprintf(os.Stderr, "❌ %v\n", rowErr)
+		}
+
+		if len(reqs) == 0 {
+			return fmt.Errorf("no valid accounts to import")
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		resp, err := client.BulkCreateAccounts(cmd.Context(), reqs)
+		if err != nil {
+			return fmt.Errorf("failed to import accounts: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		if _, err := fmt.Fprintln(w, "ACCOUNT\tRESULT\tDETAILS"); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		for _, result := range resp.Results {
+			status, details := "created", ""
+			if !result.Success {
+				status, details = "failed", result.Error
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\n", result.SlurmAccount, status, details); err != nil {
+				return fmt.Errorf("failed to write result: %w", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to flush output: %w", err)
+		}
+
+		failed := resp.Failed + len(rowErrs)
+		fmt.Printf("\nImported %d of %d accounts\n", resp.Created, resp.Total+len(rowErrs))
+		if failed > 0 {
+			return fmt.Errorf("%d account(s) were not imported", failed)
+		}
+
+		return nil
+	},
+}
+
+// restoreAccountBackup reads an account export from path and restores it
+func restoreAccountBackup(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var export api.AccountExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if err := export.Validate(); err != nil {
+		return err
+	}
+
+	client, err := getAPIClient()
+	if err != nil {
+		return fmt.Errorf("failed to create API client: %w", err)
+	}
+
+	resp, err := client.ImportAccount(ctx, &export)
+	if err != nil {
+		return fmt.Errorf("failed to restore account: %w", err)
+	}
+
+	fmt.Printf("✅ Restored %s\n", resp.Account.SlurmAccount)
+	fmt.Printf("Partition Limits: %d\n", resp.PartitionLimits)
+	fmt.Printf("Allocation Schedules: %d\n", resp.AllocationSchedules)
+	fmt.Printf("Transactions: %d\n", resp.Transactions)
+	return nil
+}
+
+// parseAccountsCSV maps CSV rows onto create requests. Rows that cannot be parsed are
+// returned as row errors so the remaining rows can still be imported.
+func parseAccountsCSV(r io.Reader) ([]*api.CreateAccountRequest, []error, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"slurm_account", "name", "budget_limit", "start_date", "end_date"} {
+		if _, ok := columns[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV is missing required column %q", required)
+		}
+	}
+
+	var reqs []*api.CreateAccountRequest
+	var rowErrs []error
+	line := 1
+	for {
+		record, err := reader.Read()
+		line++
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErrs = append(rowErrs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+
+		req, err := parseAccountRecord(record, columns)
+		if err != nil {
+			rowErrs = append(rowErrs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		reqs = append(reqs, req)
+	}
+
+	return reqs, rowErrs, nil
+}
+
+// parseAccountRecord converts a single CSV record into a create request
+func parseAccountRecord(record []string, columns map[string]int) (*api.CreateAccountRequest, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := &api.CreateAccountRequest{
+		SlurmAccount: field("slurm_account"),
+		Name:         field("name"),
+		Description:  field("description"),
+	}
+
+	budgetLimit, err := strconv.ParseFloat(field("budget_limit"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid budget_limit %q", field("budget_limit"))
+	}
+	req.BudgetLimit = budgetLimit
+
+	req.ParentAccount = field("parent_account")
+	req.Timezone = field("timezone")
+	req.FiscalYearStart = field("fiscal_year_start")
+	req.EstimationSource = field("estimation_source")
+	req.BudgetUnit = field("budget_unit")
+	loc, err := api.LoadTimezone(req.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q", req.Timezone)
+	}
+
+	if req.StartDate, err = time.ParseInLocation("2006-01-02", field("start_date"), loc); err != nil {
+		return nil, fmt.Errorf("invalid start_date %q (use YYYY-MM-DD)", field("start_date"))
+	}
+	if req.EndDate, err = time.ParseInLocation("2006-01-02", field("end_date"), loc); err != nil {
+		return nil, fmt.Errorf("invalid end_date %q (use YYYY-MM-DD)", field("end_date"))
+	}
+
+	if req.Tags, err = parseTags(strings.Split(field("tags"), ";")); err != nil {
+		return nil, err
+	}
+	if partitions := parsePartitions(strings.Split(field("allowed_partitions"), ";")); len(partitions) > 0 {
+		req.AllowedPartitions = partitions
+	}
+
+	if value := field("hold_percentage"); value != "" {
+		holdPercentage, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hold_percentage %q", value)
+		}
+		req.HoldPercentage = &holdPercentage
+	}
+
+	if value := field("dollars_per_su"); value != "" {
+		dollarsPerSU, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dollars_per_su %q", value)
+		}
+		req.DollarsPerSU = &dollarsPerSU
+	}
+
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("account %q: %w", req.SlurmAccount, err)
+	}
+
+	return req, nil
+}
+
+// parseTags turns key=value pairs into account tags, skipping empty entries. The map is
+// empty rather than nil when there are none, so an update can clear an account's tags.
+func parseTags(pairs []string) (map[string]string, error) {
+	tags := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid tag %q (use key=value)", pair)
+		}
+		tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return tags, nil
+}
+
+// parsePartitions trims partition names, skipping empty entries. The list is empty rather
+// than nil when there are none, so an update can allow every partition again.
+func parsePartitions(names []string) []string {
+	partitions := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			partitions = append(partitions, name)
+		}
+	}
+	return partitions
+}
+
+// formatAccountAmount formats an amount in the account's unit: money for a dollar
+// account, SU for an su account
+func formatAccountAmount(account *api.BudgetAccount, amount float64) string {
+	if account.Unit() == api.BudgetUnitSU {
+		return fmt.Sprintf("%.2f SU", amount)
+	}
+	return formatMoney(amount)
+}
+
+// formatTags lists account tags as key=value pairs in key order
+func formatTags(tags map[string]string) string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + tags[key]
+	}
+	return strings.Join(pairs, ", ")
+}
+
 func init() {
 	// Account list command
+	accountListCmd.Flags().StringArrayVar(&listAccountTags, "tag", nil, "Only accounts with this tag, as key=value or key; repeat to require several")
 	accountCmd.AddCommand(accountListCmd)
 
 	// Account create command
@@ -236,6 +1092,15 @@ func init() {
 	accountCreateCmd.Flags().Float64Var(&createTotalBudget, "total-budget", 0, "Total budget for incremental allocation")
 	accountCreateCmd.Flags().Float64Var(&createAllocationAmount, "allocation-amount", 0, "Amount per allocation")
 	accountCreateCmd.Flags().StringVar(&createAllocationFreq, "allocation-frequency", "", "Allocation frequency (daily, weekly, monthly, quarterly, yearly)")
+	accountCreateCmd.Flags().Float64Var(&createHoldPercentage, "hold-percentage", 0, "Hold percentage override for this account (e.g. 1.05); defaults to the service setting")
+	accountCreateCmd.Flags().StringVar(&createAccountTimezone, "timezone", "", "IANA time zone for dates and allocations, e.g. America/New_York (default UTC)")
+	accountCreateCmd.Flags().StringVar(&createAccountFiscalStart, "fiscal-year-start", "", "Fiscal year start as MM-DD, e.g. 07-01 (default: the service's fiscal year)")
+	accountCreateCmd.Flags().StringVar(&createAccountParent, "parent", "", "Parent account whose budget this account also draws on")
+	accountCreateCmd.Flags().StringArrayVar(&createAccountTags, "tag", nil, "Tag the account, as key=value; repeat for several")
+	accountCreateCmd.Flags().StringArrayVar(&createAccountPartitions, "allowed-partition", nil, "Only allow jobs on this partition; repeat for several (default: all partitions)")
+	accountCreateCmd.Flags().StringVar(&createAccountEstimation, "estimation-source", "", "Cost model for budget checks: advisor, fallback or static (default: the service setting)")
+	accountCreateCmd.Flags().StringVar(&createAccountUnit, "unit", "", "Unit of the budget and balances: dollars or su (default dollars)")
+	accountCreateCmd.Flags().Float64Var(&createAccountDollarsPerSU, "dollars-per-su", 0, "Dollar cost of one SU, converting job costs for an su account")
 
 	if err := accountCreateCmd.MarkFlagRequired("name"); err != nil {
 		panic(err) // This should never happen during initialization
@@ -252,6 +1117,83 @@ func init() {
 
 	accountCmd.AddCommand(accountCreateCmd)
 	accountCmd.AddCommand(accountShowCmd)
+
+	// Account update command
+	accountUpdateCmd.Flags().StringVar(&updateAccountName, "name", "", "New account name")
+	accountUpdateCmd.Flags().StringVar(&updateAccountDescription, "description", "", "New account description")
+	accountUpdateCmd.Flags().Float64Var(&updateAccountBudget, "budget", 0, "New budget limit")
+	accountUpdateCmd.Flags().StringVar(&updateAccountStatus, "status", "", "New status (active, inactive, suspended)")
+	accountUpdateCmd.Flags().Float64Var(&updateAccountHoldPercentage, "hold-percentage", 0, "Hold percentage override for this account (e.g. 1.05)")
+	accountUpdateCmd.Flags().Float64Var(&updateAccountReserved, "reserved", 0, "Amount held back from jobs, spendable only by adjustment")
+	accountUpdateCmd.Flags().StringVar(&updateAccountTimezone, "timezone", "", "IANA time zone for allocations, e.g. America/New_York")
+	accountUpdateCmd.Flags().BoolVar(&updateAccountBurnRate, "burn-rate", false, "Enable burn rate analysis; history is backfilled when first enabled")
+	accountUpdateCmd.Flags().StringVar(&updateAccountFiscalStart, "fiscal-year-start", "", "Fiscal year start as MM-DD; --fiscal-year-start=\"\" reverts to the service's fiscal year")
+	accountUpdateCmd.Flags().BoolVar(&updateAccountFrozen, "frozen", false, "Refuse new jobs while letting running jobs reconcile; --frozen=false resumes")
+	accountUpdateCmd.Flags().StringVar(&updateAccountParent, "parent", "", "Parent account to draw on; empty detaches the account")
+	accountUpdateCmd.Flags().StringArrayVar(&updateAccountTags, "tag", nil, "Replace the account's tags, as key=value; repeat for several")
+	accountUpdateCmd.Flags().StringArrayVar(&updateAccountPartitions, "allowed-partition", nil, "Replace the partitions jobs may use; repeat for several")
+	accountUpdateCmd.Flags().StringVar(&updateAccountEstimation, "estimation-source", "", "Cost model for budget checks: advisor, fallback or static; --estimation-source=\"\" reverts to the service setting")
+	accountUpdateCmd.Flags().Float64Var(&updateAccountDollarsPerSU, "dollars-per-su", 0, "Dollar cost of one SU for an su account; applies to later jobs")
+	accountCmd.AddCommand(accountUpdateCmd)
+
+	// Account clone command
+	accountCloneCmd.Flags().StringVar(&cloneAccountAccount, "account", "", "SLURM account name for the new account (required)")
+	accountCloneCmd.Flags().StringVar(&cloneAccountName, "name", "", "Account name (default: the source's)")
+	accountCloneCmd.Flags().StringVar(&cloneAccountDescription, "description", "", "Account description (default: the source's)")
+	accountCloneCmd.Flags().Float64Var(&cloneAccountBudget, "budget", 0, "Budget limit (default: the source's)")
+	accountCloneCmd.Flags().StringVar(&cloneAccountStart, "start", "", "Start date, YYYY-MM-DD (default: the source's)")
+	accountCloneCmd.Flags().StringVar(&cloneAccountEnd, "end", "", "End date, YYYY-MM-DD (default: the source's)")
+	accountCloneCmd.Flags().BoolVar(&cloneAccountSchedule, "copy-schedule", false, "Copy the source's allocation schedules, restarted from the start date")
+	if err := accountCloneCmd.MarkFlagRequired("account"); err != nil {
+		panic(err) // This should never happen during initialization
+	}
+	accountCmd.AddCommand(accountCloneCmd)
+
+	// Account member commands
+	accountMemberCmd.AddCommand(accountMemberAddCmd)
+	accountMemberCmd.AddCommand(accountMemberRemoveCmd)
+	accountMemberCmd.AddCommand(accountMemberListCmd)
+	accountCmd.AddCommand(accountMemberCmd)
+
+	// Account adjust command
+	accountAdjustCmd.Flags().Float64Var(&adjustAmount, "amount", 0, "Adjustment amount; negative values credit the account (required)")
+	accountAdjustCmd.Flags().StringVar(&adjustDescription, "description", "", "Reason for the adjustment (required)")
+	accountAdjustCmd.Flags().BoolVar(&adjustFromReserve, "from-reserve", false, "Draw the amount from the account's reserve")
+	if err := accountAdjustCmd.MarkFlagRequired("amount"); err != nil {
+		panic(err) // This should never happen during initialization
+	}
+	if err := accountAdjustCmd.MarkFlagRequired("description"); err != nil {
+		panic(err) // This should never happen during initialization
+	}
+	accountCmd.AddCommand(accountAdjustCmd)
+
+	// Account simulate command
+	accountSimulateCmd.Flags().StringVar(&simulatePartition, "partition", "", "Partition the jobs run on (required)")
+	accountSimulateCmd.Flags().IntVar(&simulateNodes, "nodes", 1, "Nodes per job")
+	accountSimulateCmd.Flags().IntVar(&simulateCPUs, "cpus", 1, "CPUs per job")
+	accountSimulateCmd.Flags().IntVar(&simulateGPUs, "gpus", 0, "GPUs per job")
+	accountSimulateCmd.Flags().StringVar(&simulateMemory, "memory", "", "Memory per job, e.g. 64GB")
+	accountSimulateCmd.Flags().StringVar(&simulateWallTime, "wall-time", "", "Wall time per job, HH:MM:SS (required)")
+	accountSimulateCmd.Flags().IntVar(&simulateCount, "count", 1, "Number of jobs")
+	accountSimulateCmd.Flags().IntVar(&simulatePeriodDays, "period-days", 0, "Days over which the jobs run (default 7)")
+	if err := accountSimulateCmd.MarkFlagRequired("partition"); err != nil {
+		panic(err) // This should never happen during initialization
+	}
+	if err := accountSimulateCmd.MarkFlagRequired("wall-time"); err != nil {
+		panic(err) // This should never happen during initialization
+	}
+	accountCmd.AddCommand(accountSimulateCmd)
+
+	// Account export command
+	accountExportCmd.Flags().StringVar(&exportAccountOutput, "output", "", "File to write the export to (default: standard output)")
+	accountCmd.AddCommand(accountExportCmd)
+
+	// Account import command
+	accountImportCmd.Flags().StringVar(&importAccountsFile, "file", "", "CSV file of accounts to create")
+	accountImportCmd.Flags().StringVar(&importAccountBackup, "backup", "", "Account export to restore")
+	accountImportCmd.MarkFlagsOneRequired("file", "backup")
+	accountImportCmd.MarkFlagsMutuallyExclusive("file", "backup")
+	accountCmd.AddCommand(accountImportCmd)
 }
 
 // getAPIClient creates an API client - placeholder implementation
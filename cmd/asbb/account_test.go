@@ -0,0 +1,185 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestParseAccountsCSV_MixedValidity(t *testing.T) {
+	input := `slurm_account,name,description,budget_limit,start_date,end_date,hold_percentage
+cs101,Intro to CS,Fall course,500,2025-09-01,2025-12-20,
+cs201,Data Structures,,not-a-number,2025-09-01,2025-12-20,
+cs301,Algorithms,,750,2025-09-01,2025-12-20,1.05
+cs401,Capstone,,1000,2025-12-20,2025-09-01,
+cs501,Research,,1200,09/01/2025,2025-12-20,
+`
+
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Len(t, reqs, 2)
+	assert.Equal(t, "cs101", reqs[0].SlurmAccount)
+	assert.Equal(t, 500.0, reqs[0].BudgetLimit)
+	assert.Nil(t, reqs[0].HoldPercentage)
+	assert.Equal(t, "cs301", reqs[1].SlurmAccount)
+	require.NotNil(t, reqs[1].HoldPercentage)
+	assert.Equal(t, 1.05, *reqs[1].HoldPercentage)
+
+	require.Len(t, rowErrs, 3)
+	assert.Contains(t, rowErrs[0].Error(), "line 3")
+	assert.Contains(t, rowErrs[0].Error(), "budget_limit")
+	assert.Contains(t, rowErrs[1].Error(), "line 5")
+	assert.Contains(t, rowErrs[2].Error(), "start_date")
+}
+
+func TestParseAccountsCSV_ColumnOrderAndMissingColumns(t *testing.T) {
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(
+		"name,end_date,start_date,budget_limit,slurm_account\nLab,2025-12-31,2025-01-01,100,lab01\n"))
+	require.NoError(t, err)
+	assert.Empty(t, rowErrs)
+	require.Len(t, reqs, 1)
+	assert.Equal(t, "lab01", reqs[0].SlurmAccount)
+	assert.Equal(t, "Lab", reqs[0].Name)
+
+	_, _, err = parseAccountsCSV(strings.NewReader("slurm_account,name\nlab01,Lab\n"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "budget_limit")
+}
+
+func TestParseAccountsCSV_Timezone(t *testing.T) {
+	input := `slurm_account,name,budget_limit,start_date,end_date,timezone
+east,Eastern Lab,500,2025-09-01,2026-08-31,America/New_York
+utc,UTC Lab,500,2025-09-01,2026-08-31,
+bad,Bad Zone,500,2025-09-01,2026-08-31,Nowhere/Land
+`
+
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, reqs, 2)
+	require.Len(t, rowErrs, 1)
+	assert.Contains(t, rowErrs[0].Error(), "timezone")
+
+	// Dates are local midnight in the account's time zone
+	assert.Equal(t, "America/New_York", reqs[0].Timezone)
+	assert.Equal(t, "2025-09-01T04:00:00Z", reqs[0].StartDate.UTC().Format("2006-01-02T15:04:05Z"))
+	assert.Equal(t, "2025-09-01T00:00:00Z", reqs[1].StartDate.UTC().Format("2006-01-02T15:04:05Z"))
+}
+
+func TestParseAccountsCSV_ParentAccount(t *testing.T) {
+	input := `slurm_account,name,budget_limit,start_date,end_date,parent_account
+dept01,Department,5000,2025-01-01,2025-12-31,
+proj01,Project,500,2025-01-01,2025-12-31,dept01
+loop01,Loop,500,2025-01-01,2025-12-31,loop01
+`
+
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, reqs, 2)
+	require.Len(t, rowErrs, 1)
+	assert.Contains(t, rowErrs[0].Error(), "loop01")
+	assert.Empty(t, reqs[0].ParentAccount)
+	assert.Equal(t, "dept01", reqs[1].ParentAccount)
+}
+
+func TestParseAccountsCSV_Tags(t *testing.T) {
+	input := `slurm_account,name,budget_limit,start_date,end_date,tags
+phys101,Physics 101,300,2025-09-01,2025-12-31,kind=course; department=physics
+proj01,Project,500,2025-01-01,2025-12-31,
+bad01,Bad,500,2025-01-01,2025-12-31,course
+`
+
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, reqs, 2)
+	require.Len(t, rowErrs, 1)
+	assert.Contains(t, rowErrs[0].Error(), "course")
+	assert.Equal(t, map[string]string{"kind": "course", "department": "physics"}, reqs[0].Tags)
+	assert.Empty(t, reqs[1].Tags)
+}
+
+func TestParseAccountsCSV_EstimationSource(t *testing.T) {
+	input := `slurm_account,name,budget_limit,start_date,end_date,estimation_source
+prod01,Production,5000,2025-01-01,2025-12-31,advisor
+explore01,Exploratory,500,2025-01-01,2025-12-31,fallback
+default01,Default,500,2025-01-01,2025-12-31,
+bad01,Bad,500,2025-01-01,2025-12-31,guess
+`
+
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, reqs, 3)
+	require.Len(t, rowErrs, 1)
+	assert.Contains(t, rowErrs[0].Error(), "bad01")
+	assert.Equal(t, "advisor", reqs[0].EstimationSource)
+	assert.Equal(t, "fallback", reqs[1].EstimationSource)
+	assert.Empty(t, reqs[2].EstimationSource)
+}
+
+func TestParseAccountsCSV_BudgetUnit(t *testing.T) {
+	input := `slurm_account,name,budget_limit,start_date,end_date,budget_unit,dollars_per_su
+alloc01,Allocation,50000,2025-01-01,2025-12-31,su,0.02
+dollars01,Dollars,500,2025-01-01,2025-12-31,,
+norate01,No Rate,500,2025-01-01,2025-12-31,su,
+`
+
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, reqs, 2)
+	require.Len(t, rowErrs, 1)
+	assert.Contains(t, rowErrs[0].Error(), "norate01")
+	assert.Equal(t, api.BudgetUnitSU, reqs[0].BudgetUnit)
+	require.NotNil(t, reqs[0].DollarsPerSU)
+	assert.Equal(t, 0.02, *reqs[0].DollarsPerSU)
+	assert.Empty(t, reqs[1].BudgetUnit)
+	assert.Nil(t, reqs[1].DollarsPerSU)
+}
+
+func TestParseAccountsCSV_AllowedPartitions(t *testing.T) {
+	input := `slurm_account,name,budget_limit,start_date,end_date,allowed_partitions
+phys101,Physics 101,300,2025-09-01,2025-12-31,cpu
+lab01,Lab,5000,2025-01-01,2025-12-31, cpu ; gpu
+open01,Open,500,2025-01-01,2025-12-31,
+`
+
+	reqs, rowErrs, err := parseAccountsCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Empty(t, rowErrs)
+	require.Len(t, reqs, 3)
+	assert.Equal(t, []string{"cpu"}, reqs[0].AllowedPartitions)
+	assert.Equal(t, []string{"cpu", "gpu"}, reqs[1].AllowedPartitions)
+	assert.Nil(t, reqs[2].AllowedPartitions)
+}
+
+func TestParsePartitions(t *testing.T) {
+	assert.Equal(t, []string{"cpu", "gpu"}, parsePartitions([]string{" cpu", "", "gpu "}))
+
+	// No names is an empty list, which allows every partition again on update
+	partitions := parsePartitions([]string{""})
+	assert.NotNil(t, partitions)
+	assert.Empty(t, partitions)
+}
+
+func TestParseTags(t *testing.T) {
+	tags, err := parseTags([]string{"department=physics", " kind = course ", ""})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"department": "physics", "kind": "course"}, tags)
+	assert.Equal(t, "department=physics, kind=course", formatTags(tags))
+
+	// No pairs is an empty set, which clears an account's tags on update
+	tags, err = parseTags([]string{""})
+	require.NoError(t, err)
+	assert.NotNil(t, tags)
+	assert.Empty(t, tags)
+
+	_, err = parseTags([]string{"physics"})
+	assert.Error(t, err)
+}
@@ -6,8 +6,11 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -26,11 +29,23 @@ Examples:
   # Show specific allocation schedule
   asbb allocations show 123
 
+  # Add a second allocation schedule to an account
+  asbb allocations add proj001 --total-budget=5000 --amount=5000 --frequency=yearly --start=2025-03-01
+
+  # Show an account's schedules and what each has allocated
+  asbb allocations schedules proj001
+
+  # Preview an account's next 6 allocations
+  asbb allocations preview proj001 --count 6
+
   # Process pending allocations
   asbb allocations process
 
-  # Pause an allocation schedule
-  asbb allocations pause 123`,
+  # Pause every allocation schedule at fiscal year-end
+  asbb allocations pause --all
+
+  # Resume one agency's schedules
+  asbb allocations resume --agency="National Science Foundation"`,
 }
 
 var allocationsListCmd = &cobra.Command{
@@ -72,12 +87,12 @@ var allocationsListCmd = &cobra.Command{
 				nextDate = schedule.NextAllocationDate.Format("2006-01-02")
 			}
 
-			if _, err := fmt.Fprintf(w, "%d\t%d\t$%.2f\t$%.2f\t$%.2f\t%s\t%s\t%s\n",
+			if _, err := fmt.Fprintf(w, "%d\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
 				schedule.ID,
 				schedule.AccountID,
-				schedule.TotalBudget,
-				schedule.AllocatedToDate,
-				schedule.RemainingBudget,
+				formatMoney(schedule.TotalBudget),
+				formatMoney(schedule.AllocatedToDate),
+				formatMoney(schedule.RemainingBudget),
 				schedule.AllocationFrequency,
 				nextDate,
 				schedule.Status,
@@ -102,6 +117,191 @@ var allocationsShowCmd = &cobra.Command{
 	},
 }
 
+var allocationsPreviewCmd = &cobra.Command{
+	Use:   "preview <account>",
+	Short: "Preview upcoming allocations",
+	Long: `Preview the next allocations an account's active schedules will make, with their
+dates and amounts. A schedule's last allocation may be a partial top-up of its remaining
+budget.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		count, _ := cmd.Flags().GetInt("count")
+
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		preview, err := client.PreviewAllocations(cmd.Context(), args[0], count)
+		if err != nil {
+			return fmt.Errorf("failed to preview allocations: %w", err)
+		}
+
+		if len(preview.Allocations) == 0 {
+			fmt.Printf("No upcoming allocations for %s.\n", preview.Account)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		defer func() {
+			if err := w.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to flush output: %v\n", err)
+			}
+		}()
+
+		if _, err := fmt.Fprintln(w, "DATE\tSCHEDULE_ID\tAMOUNT\tREMAINING\t"); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		for _, alloc := range preview.Allocations {
+			note := ""
+			if alloc.Partial {
+				note = "partial"
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\n",
+				alloc.AllocationDate.Format("2006-01-02"),
+				alloc.ScheduleID,
+				formatMoney(alloc.Amount),
+				formatMoney(alloc.RemainingBudget),
+				note,
+			); err != nil {
+				return fmt.Errorf("failed to write allocation data: %w", err)
+			}
+		}
+		if _, err := fmt.Fprintf(w, "TOTAL\t\t%s\t\t\n", formatMoney(preview.TotalAmount)); err != nil {
+			return fmt.Errorf("failed to write total: %w", err)
+		}
+
+		return nil
+	},
+}
+
+var (
+	addScheduleTotal     float64
+	addScheduleAmount    float64
+	addScheduleFrequency string
+	addScheduleStart     string
+	addScheduleEnd       string
+)
+
+var allocationsAddCmd = &cobra.Command{
+	Use:   "add <account>",
+	Short: "Add an allocation schedule to an account",
+	Long: `Add an allocation schedule to an account. An account may have several schedules,
+each allocating its own amount on its own dates; the first allocation is made on the start
+date. Dates are in the account's time zone.
+
+Examples:
+  # Add a monthly compute allocation
+  asbb allocations add proj001 --total-budget=1200 --amount=100 --frequency=monthly --start=2025-01-01
+
+  # Add a one-time equipment supplement alongside it
+  asbb allocations add proj001 --total-budget=5000 --amount=5000 --frequency=yearly --start=2025-03-01`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		account, err := client.GetAccount(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get account: %w", err)
+		}
+
+		req := &api.CreateAllocationScheduleRequest{
+			TotalBudget:         addScheduleTotal,
+			AllocationAmount:    addScheduleAmount,
+			AllocationFrequency: addScheduleFrequency,
+			AutoAllocate:        true,
+		}
+		if req.StartDate, err = time.ParseInLocation("2006-01-02", addScheduleStart, account.Location()); err != nil {
+			return fmt.Errorf("invalid start date format (use YYYY-MM-DD): %w", err)
+		}
+		if addScheduleEnd != "" {
+			endDate, err := time.ParseInLocation("2006-01-02", addScheduleEnd, account.Location())
+			if err != nil {
+				return fmt.Errorf("invalid end date format (use YYYY-MM-DD): %w", err)
+			}
+			req.EndDate = &endDate
+		}
+
+		schedule, err := client.CreateAllocationSchedule(cmd.Context(), args[0], req)
+		if err != nil {
+			return fmt.Errorf("failed to add allocation schedule: %w", err)
+		}
+
+		fmt.Printf("✅ Added allocation schedule %d to %s\n", schedule.ID, args[0])
+		fmt.Printf("%s %s, %s in total, first on %s\n",
+			formatMoney(schedule.AllocationAmount),
+			schedule.AllocationFrequency,
+			formatMoney(schedule.TotalBudget),
+			schedule.StartDate.In(account.Location()).Format("2006-01-02"))
+		return nil
+	},
+}
+
+var allocationsSchedulesCmd = &cobra.Command{
+	Use:   "schedules <account>",
+	Short: "Show an account's schedules and their allocations",
+	Long: `Show each of an account's allocation schedules with the allocations it has made,
+and when the next allocation across them is due.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		resp, err := client.ListAccountSchedules(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to list account schedules: %w", err)
+		}
+
+		return renderAccountSchedules(os.Stdout, resp)
+	},
+}
+
+// renderAccountSchedules writes an account's schedules, each followed by its allocations
+func renderAccountSchedules(out io.Writer, resp *api.AccountSchedulesResponse) error {
+	if len(resp.Schedules) == 0 {
+		_, err := fmt.Fprintf(out, "No allocation schedules for %s.\n", resp.Account)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, scheduled := range resp.Schedules {
+		schedule := scheduled.Schedule
+		if _, err := fmt.Fprintf(w, "SCHEDULE %d\t%s %s\t%s of %s\t%s\n",
+			schedule.ID,
+			formatMoney(schedule.AllocationAmount),
+			schedule.AllocationFrequency,
+			formatMoney(schedule.AllocatedToDate),
+			formatMoney(schedule.TotalBudget),
+			schedule.Status,
+		); err != nil {
+			return fmt.Errorf("failed to write schedule: %w", err)
+		}
+		for _, alloc := range scheduled.Allocations {
+			if _, err := fmt.Fprintf(w, "  %s\t%s\t%s\t\n",
+				alloc.AllocatedDate.Format("2006-01-02"),
+				formatMoney(alloc.AllocationAmount),
+				alloc.TransactionID,
+			); err != nil {
+				return fmt.Errorf("failed to write allocation: %w", err)
+			}
+		}
+	}
+	if resp.Summary.NextAllocationDate != nil {
+		if _, err := fmt.Fprintf(w, "NEXT\t%s\t%s\t\n",
+			resp.Summary.NextAllocationDate.Format("2006-01-02"),
+			formatMoney(resp.Summary.NextAllocationAmount),
+		); err != nil {
+			return fmt.Errorf("failed to write next allocation: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
 var allocationsProcessCmd = &cobra.Command{
 	Use:   "process",
 	Short: "Process pending allocations",
@@ -119,7 +319,7 @@ var allocationsProcessCmd = &cobra.Command{
 
 		fmt.Printf("✅ Allocation processing completed!\n")
 		fmt.Printf("Processed: %d allocations\n", result.ProcessedCount)
-		fmt.Printf("Total Allocated: $%.2f\n", result.TotalAllocated)
+		fmt.Printf("Total Allocated: %s\n", formatMoney(result.TotalAllocated))
 
 		if len(result.Allocations) > 0 {
 			fmt.Printf("\nProcessed Allocations:\n")
@@ -134,10 +334,10 @@ var allocationsProcessCmd = &cobra.Command{
 				return fmt.Errorf("failed to write header: %w", err)
 			}
 			for _, alloc := range result.Allocations {
-				if _, err := fmt.Fprintf(w, "%d\t%d\t$%.2f\t%s\n",
+				if _, err := fmt.Fprintf(w, "%d\t%d\t%s\t%s\n",
 					alloc.ScheduleID,
 					alloc.AccountID,
-					alloc.AllocatedAmount,
+					formatMoney(alloc.AllocatedAmount),
 					alloc.TransactionID,
 				); err != nil {
 					return fmt.Errorf("failed to write allocation data: %w", err)
@@ -149,8 +349,145 @@ var allocationsProcessCmd = &cobra.Command{
 	},
 }
 
+var (
+	scheduleStatusAgency     string
+	scheduleStatusCostCenter string
+	scheduleStatusAll        bool
+)
+
+var allocationsPauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause allocation schedules in bulk",
+	Long: `Pause every active allocation schedule, or those of accounts under one funding
+agency or cost center. Paused schedules make no allocations until they are resumed.
+Without a filter, --all is required.
+
+Examples:
+  # Pause every schedule at fiscal year-end
+  asbb allocations pause --all
+
+  # Pause one cost center's schedules
+  asbb allocations pause --cost-center=CC-1042`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := bulkScheduleStatusRequest()
+		if err != nil {
+			return err
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		resp, err := client.PauseAllocationSchedules(cmd.Context(), req)
+		if err != nil {
+			return fmt.Errorf("failed to pause allocation schedules: %w", err)
+		}
+
+		return renderScheduleStatus(os.Stdout, resp)
+	},
+}
+
+var allocationsResumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume paused allocation schedules in bulk",
+	Long: `Resume every paused allocation schedule, or those of accounts under one funding
+agency or cost center. Allocations that came due while a schedule was paused are made
+as it catches up. Without a filter, --all is required.
+
+Examples:
+  # Resume every paused schedule
+  asbb allocations resume --all
+
+  # Resume one agency's schedules
+  asbb allocations resume --agency="National Science Foundation"`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req, err := bulkScheduleStatusRequest()
+		if err != nil {
+			return err
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		resp, err := client.ResumeAllocationSchedules(cmd.Context(), req)
+		if err != nil {
+			return fmt.Errorf("failed to resume allocation schedules: %w", err)
+		}
+
+		return renderScheduleStatus(os.Stdout, resp)
+	},
+}
+
+// bulkScheduleStatusRequest builds a bulk pause or resume from the flags, refusing to
+// touch every schedule unless --all says so
+func bulkScheduleStatusRequest() (*api.BulkScheduleStatusRequest, error) {
+	req := &api.BulkScheduleStatusRequest{FundingAgency: scheduleStatusAgency, CostCenter: scheduleStatusCostCenter}
+	filtered := req.FundingAgency != "" || req.CostCenter != ""
+	if !filtered && !scheduleStatusAll {
+		return nil, fmt.Errorf("give --agency or --cost-center, or --all to change every schedule")
+	}
+	if filtered && scheduleStatusAll {
+		return nil, fmt.Errorf("--all cannot be combined with --agency or --cost-center")
+	}
+	return req, nil
+}
+
+// renderScheduleStatus reports how many schedules a bulk pause or resume changed
+func renderScheduleStatus(out io.Writer, resp *api.BulkScheduleStatusResponse) error {
+	action, verb := "resume", "Resumed"
+	if resp.Status == "paused" {
+		action, verb = "pause", "Paused"
+	}
+	noun := "schedules"
+	if resp.Updated == 1 {
+		noun = "schedule"
+	}
+
+	var err error
+	if resp.Updated == 0 {
+		_, err = fmt.Fprintf(out, "No allocation schedules to %s.\n", action)
+	} else {
+		ids := make([]string, len(resp.ScheduleIDs))
+		for i, id := range resp.ScheduleIDs {
+			ids[i] = fmt.Sprintf("%d", id)
+		}
+		_, err = fmt.Fprintf(out, "%s %d allocation %s: %s\n", verb, resp.Updated, noun, strings.Join(ids, ", "))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write schedule status: %w", err)
+	}
+	return nil
+}
+
 func init() {
 	allocationsCmd.AddCommand(allocationsListCmd)
 	allocationsCmd.AddCommand(allocationsShowCmd)
+	allocationsCmd.AddCommand(allocationsPreviewCmd)
+	allocationsCmd.AddCommand(allocationsAddCmd)
+	allocationsCmd.AddCommand(allocationsSchedulesCmd)
 	allocationsCmd.AddCommand(allocationsProcessCmd)
+	allocationsCmd.AddCommand(allocationsPauseCmd)
+	allocationsCmd.AddCommand(allocationsResumeCmd)
+
+	for _, cmd := range []*cobra.Command{allocationsPauseCmd, allocationsResumeCmd} {
+		cmd.Flags().StringVar(&scheduleStatusAgency, "agency", "", "only accounts funded by this grant agency")
+		cmd.Flags().StringVar(&scheduleStatusCostCenter, "cost-center", "", "only accounts charged to this cost center")
+		cmd.Flags().BoolVar(&scheduleStatusAll, "all", false, "change every schedule")
+	}
+
+	allocationsPreviewCmd.Flags().Int("count", 12, "Number of allocations to preview (1-100)")
+
+	allocationsAddCmd.Flags().Float64Var(&addScheduleTotal, "total-budget", 0, "Total budget the schedule allocates (required)")
+	allocationsAddCmd.Flags().Float64Var(&addScheduleAmount, "amount", 0, "Amount of each allocation (required)")
+	allocationsAddCmd.Flags().StringVar(&addScheduleFrequency, "frequency", "monthly", "Allocation frequency (daily, weekly, monthly, quarterly, yearly)")
+	allocationsAddCmd.Flags().StringVar(&addScheduleStart, "start", "", "Date of the first allocation (YYYY-MM-DD, required)")
+	allocationsAddCmd.Flags().StringVar(&addScheduleEnd, "end", "", "Date after which no allocation is made (YYYY-MM-DD)")
+	for _, flag := range []string{"total-budget", "amount", "start"} {
+		if err := allocationsAddCmd.MarkFlagRequired(flag); err != nil {
+			panic(err) // This should never happen during initialization
+		}
+	}
 }
@@ -0,0 +1,54 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBulkScheduleStatusRequest(t *testing.T) {
+	set := func(agency, costCenter string, all bool) {
+		scheduleStatusAgency, scheduleStatusCostCenter, scheduleStatusAll = agency, costCenter, all
+	}
+	defer set("", "", false)
+
+	set("", "", false)
+	_, err := bulkScheduleStatusRequest()
+	assert.Error(t, err, "an unfiltered change needs --all")
+
+	set("", "", true)
+	req, err := bulkScheduleStatusRequest()
+	require.NoError(t, err)
+	assert.Equal(t, &api.BulkScheduleStatusRequest{}, req)
+
+	set("NSF", "CC-1042", false)
+	req, err = bulkScheduleStatusRequest()
+	require.NoError(t, err)
+	assert.Equal(t, &api.BulkScheduleStatusRequest{FundingAgency: "NSF", CostCenter: "CC-1042"}, req)
+
+	set("NSF", "", true)
+	_, err = bulkScheduleStatusRequest()
+	assert.Error(t, err)
+}
+
+func TestRenderScheduleStatus(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, renderScheduleStatus(&out, &api.BulkScheduleStatusResponse{Status: "paused", Updated: 2, ScheduleIDs: []int64{3, 7}}))
+	assert.Equal(t, "Paused 2 allocation schedules: 3, 7\n", out.String())
+
+	out.Reset()
+	require.NoError(t, renderScheduleStatus(&out, &api.BulkScheduleStatusResponse{Status: "active", Updated: 1, ScheduleIDs: []int64{3}}))
+	assert.Equal(t, "Resumed 1 allocation schedule: 3\n", out.String())
+
+	out.Reset()
+	require.NoError(t, renderScheduleStatus(&out, &api.BulkScheduleStatusResponse{Status: "active", ScheduleIDs: []int64{}}))
+	assert.Equal(t, "No allocation schedules to resume.\n", out.String())
+}
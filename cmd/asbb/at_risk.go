@@ -0,0 +1,71 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+var atRiskWithinDays int
+
+var atRiskCmd = &cobra.Command{
+	Use:   "at-risk",
+	Short: "List accounts nearing budget depletion",
+	Long: `List active accounts whose budget is projected to run out within a number
+of days at their recent burn rate, soonest first, with their available
+budget and daily spend.
+
+Examples:
+  # Accounts that run out within two weeks
+  asbb at-risk
+
+  # Accounts that run out within a month
+  asbb at-risk --within-days=30`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		resp, err := client.ListAtRiskAccounts(cmd.Context(), atRiskWithinDays)
+		if err != nil {
+			return fmt.Errorf("failed to list at-risk accounts: %w", err)
+		}
+
+		return renderAtRisk(os.Stdout, resp)
+	},
+}
+
+// renderAtRisk writes the at-risk accounts as a table
+func renderAtRisk(out io.Writer, resp *api.AtRiskAccountsResponse) error {
+	if len(resp.Accounts) == 0 {
+		_, err := fmt.Fprintf(out, "No accounts are projected to deplete within %d days\n", resp.WithinDays)
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if _, err := fmt.Fprintln(w, "ACCOUNT\tAVAILABLE\tDAILY BURN\tDEPLETES\tDAYS LEFT"); err != nil {
+		return fmt.Errorf("failed to write at-risk accounts: %w", err)
+	}
+	for _, account := range resp.Accounts {
+		_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%.1f\n", account.Account, formatMoney(account.BudgetAvailable),
+			formatMoney(account.DailyBurnRate), account.ProjectedDepletionDate.Format("2006-01-02"), account.DaysUntilDepletion)
+		if err != nil {
+			return fmt.Errorf("failed to write at-risk accounts: %w", err)
+		}
+	}
+	return w.Flush()
+}
+
+func init() {
+	atRiskCmd.Flags().IntVar(&atRiskWithinDays, "within-days", 14, "list accounts projected to deplete within this many days")
+}
@@ -0,0 +1,39 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestRenderAtRisk(t *testing.T) {
+	resp := &api.AtRiskAccountsResponse{
+		WithinDays: 14,
+		Accounts: []api.AtRiskAccount{
+			{Account: "proj002", BudgetAvailable: 0, DailyBurnRate: 40, ProjectedDepletionDate: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)},
+			{Account: "proj001", BudgetAvailable: 500, DailyBurnRate: 100, ProjectedDepletionDate: time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC), DaysUntilDepletion: 5},
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, renderAtRisk(&out, resp))
+	assert.Equal(t, `ACCOUNT  AVAILABLE  DAILY BURN  DEPLETES    DAYS LEFT
+proj002  $0.00      $40.00      2025-06-01  0.0
+proj001  $500.00    $100.00     2025-06-06  5.0
+`, out.String())
+}
+
+func TestRenderAtRisk_None(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, renderAtRisk(&out, &api.AtRiskAccountsResponse{WithinDays: 30}))
+	assert.Equal(t, "No accounts are projected to deplete within 30 days\n", out.String())
+}
@@ -5,7 +5,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 )
@@ -20,7 +22,10 @@ Examples:
   asbb config init
 
   # Validate configuration
-  asbb config validate`,
+  asbb config validate
+
+  # Show the configuration the running service loaded
+  asbb config show`,
 }
 
 var configInitCmd = &cobra.Command{
@@ -41,6 +46,29 @@ var configValidateCmd = &cobra.Command{
 	},
 }
 
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the running service's configuration",
+	Long: `Show the configuration the running service actually loaded, with its file,
+environment variables and defaults merged. Passwords, keys and secrets are redacted;
+a secret that is not set is shown empty. Requires an admin API key.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		effective, err := client.GetEffectiveConfig(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get service configuration: %w", err)
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(effective.Settings)
+	},
+}
+
 var serviceCmd = &cobra.Command{
 	Use:   "service",
 	Short: "Service management",
@@ -60,6 +88,9 @@ var databaseCmd = &cobra.Command{
 	Long: `Manage database operations including migrations.
 
 Examples:
+  # Show the schema version and pending migrations
+  asbb database status
+
   # Run migrations
   asbb database migrate
 
@@ -70,4 +101,5 @@ Examples:
 func init() {
 	configCmd.AddCommand(configInitCmd)
 	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configShowCmd)
 }
@@ -0,0 +1,87 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+var databaseStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the schema version and pending migrations",
+	Long: `Show the database schema version the service is running against and which
+migrations are applied or still pending. Exits with an error when the schema is
+not up to date, so a deploy can be verified before traffic is switched to it.
+
+Examples:
+  # Check the schema before switching traffic
+  asbb database status`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		status, err := client.GetMigrationStatus(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+
+		if err := renderMigrationStatus(os.Stdout, status); err != nil {
+			return err
+		}
+		if !status.UpToDate {
+			return fmt.Errorf("database schema is not up to date")
+		}
+		return nil
+	},
+}
+
+// renderMigrationStatus writes the schema version followed by the pending migrations
+func renderMigrationStatus(out io.Writer, status *api.MigrationStatus) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	var writeErr error
+	line := func(format string, args ...interface{}) {
+		if writeErr == nil {
+			_, writeErr = fmt.Fprintf(w, format+"\n", args...)
+		}
+	}
+
+	state := "up to date"
+	switch {
+	case status.Dirty:
+		state = "dirty: the last migration failed and the schema needs repair"
+	case len(status.Pending) > 0:
+		state = fmt.Sprintf("%d pending", len(status.Pending))
+	}
+	line("Schema version\t%d of %d", status.CurrentVersion, status.LatestVersion)
+	line("Applied\t%d", len(status.Applied))
+	line("Status\t%s", state)
+
+	if len(status.Pending) > 0 {
+		line("")
+		line("PENDING")
+		for _, migration := range status.Pending {
+			line("  %03d\t%s", migration.Version, migration.Name)
+		}
+	}
+
+	if writeErr != nil {
+		return fmt.Errorf("failed to write migration status: %w", writeErr)
+	}
+	return w.Flush()
+}
+
+func init() {
+	databaseCmd.AddCommand(databaseStatusCmd)
+}
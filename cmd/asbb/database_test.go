@@ -0,0 +1,49 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestRenderMigrationStatus(t *testing.T) {
+	status := &api.MigrationStatus{
+		CurrentVersion: 22,
+		LatestVersion:  24,
+		Applied:        make([]api.Migration, 22),
+		Pending: []api.Migration{
+			{Version: 23, Name: "account_estimation_source"},
+			{Version: 24, Name: "cost_sharing"},
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, renderMigrationStatus(&out, status))
+	assert.Equal(t, `Schema version  22 of 24
+Applied         22
+Status          2 pending
+
+PENDING
+  023  account_estimation_source
+  024  cost_sharing
+`, out.String())
+}
+
+func TestRenderMigrationStatus_States(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, renderMigrationStatus(&out, &api.MigrationStatus{CurrentVersion: 24, LatestVersion: 24, UpToDate: true}))
+	assert.Contains(t, out.String(), "up to date")
+	assert.NotContains(t, out.String(), "PENDING")
+
+	out.Reset()
+	require.NoError(t, renderMigrationStatus(&out, &api.MigrationStatus{CurrentVersion: 24, LatestVersion: 24, Dirty: true}))
+	assert.Contains(t, out.String(), "dirty")
+}
@@ -137,7 +137,7 @@ Example:
 		fmt.Printf("Grant Number: %s\n", grant.GrantNumber)
 		fmt.Printf("Funding Agency: %s\n", grant.FundingAgency)
 		fmt.Printf("Principal Investigator: %s\n", grant.PrincipalInvestigator)
-		fmt.Printf("Total Award: $%.2f\n", grant.TotalAwardAmount)
+		fmt.Printf("Total Award: %s\n", formatMoney(grant.TotalAwardAmount))
 		fmt.Printf("Grant Period: %s to %s\n",
 			grant.GrantStartDate.Format("2006-01-02"),
 			grant.GrantEndDate.Format("2006-01-02"))
@@ -191,11 +191,11 @@ var grantListCmd = &cobra.Command{
 		}
 
 		for _, grant := range grants {
-			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t$%.0f\t%s - %s\t%s\n",
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s - %s\t%s\n",
 				grant.GrantNumber,
 				grant.FundingAgency,
 				grant.PrincipalInvestigator,
-				grant.TotalAwardAmount,
+				formatWholeMoney(grant.TotalAwardAmount),
 				grant.GrantStartDate.Format("2006-01-02"),
 				grant.GrantEndDate.Format("2006-01-02"),
 				grant.Status,
@@ -258,11 +258,14 @@ var grantShowCmd = &cobra.Command{
 		}
 
 		fmt.Printf("\nFinancial Summary:\n")
-		fmt.Printf("Total Award: $%.2f\n", grant.TotalAwardAmount)
-		fmt.Printf("Direct Costs: $%.2f\n", grant.DirectCosts)
+		fmt.Printf("Total Award: %s\n", formatMoney(grant.TotalAwardAmount))
+		fmt.Printf("Direct Costs: %s\n", formatMoney(grant.DirectCosts))
+		if grant.ExcludedDirectCosts > 0 {
+			fmt.Printf("Excluded From Indirect: %s\n", formatMoney(grant.ExcludedDirectCosts))
+		}
 		if grant.IndirectCostRate > 0 {
-			fmt.Printf("Indirect Rate: %.1f%% ($%.2f)\n",
-				grant.IndirectCostRate*100, grant.IndirectCosts)
+			fmt.Printf("Indirect Rate: %.1f%% (%s)\n",
+				grant.IndirectCostRate*100, formatMoney(grant.IndirectCosts))
 		}
 
 		fmt.Printf("\nGrant Period:\n")
@@ -281,14 +284,26 @@ var grantShowCmd = &cobra.Command{
 
 		fmt.Printf("Status: %s\n", strings.ToUpper(grant.Status))
 
+		period, err := client.GetGrantPeriodSummary(cmd.Context(), grantNumber, nil)
+		if err != nil {
+			fmt.Printf("Warning: Could not get budget period summary: %v\n", err)
+		} else {
+			fmt.Printf("\nBudget Period %d (%s to %s):\n", period.PeriodNumber,
+				period.PeriodStartDate.Format("2006-01-02"), period.PeriodEndDate.Format("2006-01-02"))
+			fmt.Printf("Budget: %s\n", formatMoney(period.Budget))
+			fmt.Printf("Spent: %s\n", formatMoney(period.Spent))
+			fmt.Printf("Committed: %s (held for running and queued jobs)\n", formatMoney(period.Committed))
+			fmt.Printf("Remaining: %s\n", formatMoney(period.Remaining))
+		}
+
 		// Display burn rate analysis if available
 		if burnAnalysis != nil {
 			fmt.Printf("\nBurn Rate Analysis (Last 30 Days):\n")
 			fmt.Printf("===================================\n")
 
 			metrics := burnAnalysis.CurrentMetrics
-			fmt.Printf("Current Daily Rate: $%.2f (Expected: $%.2f)\n",
-				metrics.DailySpendRate, metrics.DailyExpectedRate)
+			fmt.Printf("Current Daily Rate: %s (Expected: %s)\n",
+				formatMoney(metrics.DailySpendRate), formatMoney(metrics.DailyExpectedRate))
 
 			if metrics.VariancePercentage != 0 {
 				symbol := "+"
@@ -299,26 +314,26 @@ var grantShowCmd = &cobra.Command{
 					symbol, metrics.VariancePercentage, metrics.BurnRateStatus)
 			}
 
-			fmt.Printf("7-Day Average: $%.2f\n", metrics.Rolling7DayAverage)
-			fmt.Printf("30-Day Average: $%.2f\n", metrics.Rolling30DayAverage)
+			fmt.Printf("7-Day Average: %s\n", formatMoney(metrics.Rolling7DayAverage))
+			fmt.Printf("30-Day Average: %s\n", formatMoney(metrics.Rolling30DayAverage))
 			fmt.Printf("Budget Health Score: %.1f%% (%s)\n",
 				metrics.BudgetHealthScore, metrics.BudgetHealthStatus)
 
 			fmt.Printf("\nBudget Status:\n")
-			fmt.Printf("Remaining: $%.2f (%.1f%%)\n",
-				metrics.BudgetRemainingAmount, metrics.BudgetRemainingPercent)
+			fmt.Printf("Remaining: %s (%.1f%%)\n",
+				formatMoney(metrics.BudgetRemainingAmount), metrics.BudgetRemainingPercent)
 			fmt.Printf("Days Remaining: %d\n", metrics.TimeRemainingDays)
 
 			// Show projection if available
 			if burnAnalysis.Projection != nil {
 				proj := burnAnalysis.Projection
 				fmt.Printf("\nProjection:\n")
-				fmt.Printf("Projected End Spend: $%.2f\n", proj.ProjectedFinalSpend)
+				fmt.Printf("Projected End Spend: %s\n", formatMoney(proj.ProjectedFinalSpend))
 
 				if proj.ProjectedOverrun > 0 {
-					fmt.Printf("⚠️  Projected Overrun: $%.2f\n", proj.ProjectedOverrun)
+					fmt.Printf("⚠️  Projected Overrun: %s\n", formatMoney(proj.ProjectedOverrun))
 				} else if proj.ProjectedUnderrun > 0 {
-					fmt.Printf("💰 Projected Underrun: $%.2f\n", proj.ProjectedUnderrun)
+					fmt.Printf("💰 Projected Underrun: %s\n", formatMoney(proj.ProjectedUnderrun))
 				}
 
 				fmt.Printf("Risk Level: %s\n", proj.RiskLevel)
@@ -440,8 +455,8 @@ Examples:
 		metrics := analysis.CurrentMetrics
 
 		fmt.Printf("\nCurrent Metrics:\n")
-		fmt.Printf("Daily Spend Rate: $%.2f (Expected: $%.2f)\n",
-			metrics.DailySpendRate, metrics.DailyExpectedRate)
+		fmt.Printf("Daily Spend Rate: %s (Expected: %s)\n",
+			formatMoney(metrics.DailySpendRate), formatMoney(metrics.DailyExpectedRate))
 
 		if metrics.VariancePercentage != 0 {
 			symbol := "+"
@@ -462,24 +477,24 @@ Examples:
 			metrics.BudgetHealthScore, metrics.BudgetHealthStatus)
 
 		fmt.Printf("\nRolling Averages:\n")
-		fmt.Printf("7-Day Average: $%.2f/day\n", metrics.Rolling7DayAverage)
-		fmt.Printf("30-Day Average: $%.2f/day\n", metrics.Rolling30DayAverage)
+		fmt.Printf("7-Day Average: %s/day\n", formatMoney(metrics.Rolling7DayAverage))
+		fmt.Printf("30-Day Average: %s/day\n", formatMoney(metrics.Rolling30DayAverage))
 
 		fmt.Printf("\nBudget Status:\n")
-		fmt.Printf("Remaining: $%.2f (%.1f%% of total)\n",
-			metrics.BudgetRemainingAmount, metrics.BudgetRemainingPercent)
+		fmt.Printf("Remaining: %s (%.1f%% of total)\n",
+			formatMoney(metrics.BudgetRemainingAmount), metrics.BudgetRemainingPercent)
 		fmt.Printf("Time Remaining: %d days\n", metrics.TimeRemainingDays)
 
 		// Show projection if requested and available
 		if showProjection && analysis.Projection != nil {
 			proj := analysis.Projection
 			fmt.Printf("\nProjection Analysis:\n")
-			fmt.Printf("Projected Final Spend: $%.2f\n", proj.ProjectedFinalSpend)
+			fmt.Printf("Projected Final Spend: %s\n", formatMoney(proj.ProjectedFinalSpend))
 
 			if proj.ProjectedOverrun > 0 {
-				fmt.Printf("⚠️  Projected Overrun: $%.2f\n", proj.ProjectedOverrun)
+				fmt.Printf("⚠️  Projected Overrun: %s\n", formatMoney(proj.ProjectedOverrun))
 			} else if proj.ProjectedUnderrun > 0 {
-				fmt.Printf("💰 Projected Underrun: $%.2f\n", proj.ProjectedUnderrun)
+				fmt.Printf("💰 Projected Underrun: %s\n", formatMoney(proj.ProjectedUnderrun))
 			}
 
 			if proj.ProjectedDepletionDate != nil {
@@ -494,6 +509,38 @@ Examples:
 	},
 }
 
+var grantRecomputeCostsCmd = &cobra.Command{
+	Use:   "recompute-costs <grant-number>",
+	Short: "Recompute a grant's direct and indirect costs",
+	Long: `Recompute a grant's direct and indirect costs from every charge against the accounts it
+funds, leaving the funding agency's excluded cost categories out of the indirect cost base.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		result, err := client.RecomputeGrantCosts(cmd.Context(), args[0])
+		if err != nil {
+			return fmt.Errorf("failed to recompute grant costs: %w", err)
+		}
+
+		grant := result.Grant
+		fmt.Printf("Recomputed costs for grant %s\n", grant.GrantNumber)
+		fmt.Printf("Direct Costs: %s (was %s)\n",
+			formatMoney(grant.DirectCosts), formatMoney(result.PreviousDirectCosts))
+		if len(result.ExcludedCategories) > 0 {
+			fmt.Printf("Excluded From Indirect: %s (%s)\n",
+				formatMoney(grant.ExcludedDirectCosts), strings.Join(result.ExcludedCategories, ", "))
+		}
+		fmt.Printf("Indirect Costs: %s (was %s)\n",
+			formatMoney(grant.IndirectCosts), formatMoney(result.PreviousIndirectCosts))
+
+		return nil
+	},
+}
+
 func init() {
 	// Grant create command flags
 	grantCreateCmd.Flags().StringVar(&createGrantNumber, "number", "", "Grant number (required)")
@@ -543,4 +590,5 @@ func init() {
 	grantCmd.AddCommand(grantCreateCmd)
 	grantCmd.AddCommand(grantListCmd)
 	grantCmd.AddCommand(grantShowCmd)
+	grantCmd.AddCommand(grantRecomputeCostsCmd)
 }
@@ -27,12 +27,18 @@ for HPC clusters that burst workloads to AWS.
 This tool manages budget accounts, monitors usage, and enforces budget limits
 at job submission time through SLURM integration.`,
 	Version: version.String(),
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return configureMoney()
+	},
 }
 
 func main() {
 	// Add persistent flags
 	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "config file path")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().StringVar(&currencyCode, "currency", "USD", "ISO 4217 currency for displayed amounts")
+	rootCmd.PersistentFlags().StringVar(&localeName, "locale", "en-US", "locale for number grouping, e.g. de-DE")
+	rootCmd.PersistentFlags().BoolVar(&noSymbol, "no-symbol", false, "print bare amounts without symbol or grouping, for scripts")
 
 	// Add command groups
 	rootCmd.AddCommand(accountCmd)
@@ -43,7 +49,12 @@ func main() {
 	rootCmd.AddCommand(usageCmd)
 	rootCmd.AddCommand(transactionCmd)
 	rootCmd.AddCommand(reconcileCmd)
+	rootCmd.AddCommand(reconcileSacctCmd)
 	rootCmd.AddCommand(recoverCmd)
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(overviewCmd)
+	rootCmd.AddCommand(atRiskCmd)
 	rootCmd.AddCommand(forecastCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(serviceCmd)
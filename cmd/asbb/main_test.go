@@ -70,3 +70,13 @@ func TestDatabaseCommand_Exists(t *testing.T) {
 	assert.Equal(t, "database", databaseCmd.Use)
 	assert.Contains(t, databaseCmd.Short, "Database management")
 }
+
+func TestAccountUpdateCommand_HoldPercentageFlag(t *testing.T) {
+	assert.NotNil(t, accountUpdateCmd.Flags().Lookup("hold-percentage"))
+	assert.NotNil(t, accountCreateCmd.Flags().Lookup("hold-percentage"))
+}
+
+func TestAccountReserveFlags(t *testing.T) {
+	assert.NotNil(t, accountUpdateCmd.Flags().Lookup("reserved"))
+	assert.NotNil(t, accountAdjustCmd.Flags().Lookup("from-reserve"))
+}
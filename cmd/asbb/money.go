@@ -0,0 +1,141 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// currencyFormat describes how a currency's amounts are written
+type currencyFormat struct {
+	symbol   string
+	decimals int
+}
+
+// localeFormat describes a locale's number grouping and symbol placement
+type localeFormat struct {
+	groupSeparator   string
+	decimalSeparator string
+	symbolAfter      bool // "1.234,56 €" rather than "€1,234.56"
+}
+
+var currencies = map[string]currencyFormat{
+	"USD": {symbol: "$", decimals: 2},
+	"EUR": {symbol: "€", decimals: 2},
+	"GBP": {symbol: "£", decimals: 2},
+	"JPY": {symbol: "¥", decimals: 0},
+	"CAD": {symbol: "CA$", decimals: 2},
+	"AUD": {symbol: "A$", decimals: 2},
+	"CHF": {symbol: "CHF ", decimals: 2},
+}
+
+var locales = map[string]localeFormat{
+	"en-US": {groupSeparator: ",", decimalSeparator: "."},
+	"en-GB": {groupSeparator: ",", decimalSeparator: "."},
+	"ja-JP": {groupSeparator: ",", decimalSeparator: "."},
+	"de-DE": {groupSeparator: ".", decimalSeparator: ",", symbolAfter: true},
+	"fr-FR": {groupSeparator: " ", decimalSeparator: ",", symbolAfter: true},
+	"de-CH": {groupSeparator: "'", decimalSeparator: "."},
+}
+
+// moneyFormatter formats amounts in one currency for one locale
+type moneyFormatter struct {
+	currency currencyFormat
+	locale   localeFormat
+	noSymbol bool
+}
+
+// Global formatting flags, applied by every command that prints amounts
+var (
+	currencyCode string
+	localeName   string
+	noSymbol     bool
+
+	money = &moneyFormatter{currency: currencies["USD"], locale: locales["en-US"]}
+)
+
+// newMoneyFormatter builds a formatter for an ISO 4217 currency code and a locale such as
+// de-DE. Currencies without a known symbol are written with their code. With noSymbol
+// amounts are written bare, without symbol or grouping, for scripts to parse.
+func newMoneyFormatter(code, locale string, noSymbol bool) (*moneyFormatter, error) {
+	code = strings.ToUpper(code)
+	currency, ok := currencies[code]
+	if !ok {
+		if len(code) != 3 {
+			return nil, fmt.Errorf("invalid currency %q: expected a three-letter ISO 4217 code", code)
+		}
+		currency = currencyFormat{symbol: code + " ", decimals: 2}
+	}
+
+	format, ok := locales[strings.Replace(locale, "_", "-", 1)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported locale %q", locale)
+	}
+
+	return &moneyFormatter{currency: currency, locale: format, noSymbol: noSymbol}, nil
+}
+
+// Format writes an amount with the currency's precision
+func (f *moneyFormatter) Format(amount float64) string {
+	return f.format(amount, f.currency.decimals)
+}
+
+// FormatWhole writes an amount rounded to whole units, for compact tables
+func (f *moneyFormatter) FormatWhole(amount float64) string {
+	return f.format(amount, 0)
+}
+
+func (f *moneyFormatter) format(amount float64, decimals int) string {
+	if f.noSymbol {
+		return strconv.FormatFloat(amount, 'f', decimals, 64)
+	}
+
+	digits := strconv.FormatFloat(math.Abs(amount), 'f', decimals, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.locale.groupSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if fraction != "" {
+		b.WriteString(f.locale.decimalSeparator)
+		b.WriteString(fraction)
+	}
+
+	sign := ""
+	if amount < 0 && strings.Trim(digits, "0.") != "" {
+		sign = "-"
+	}
+	if f.locale.symbolAfter {
+		return sign + b.String() + " " + strings.TrimSpace(f.currency.symbol)
+	}
+	return sign + f.currency.symbol + b.String()
+}
+
+// formatMoney formats an amount with the formatter chosen by the global flags
+func formatMoney(amount float64) string {
+	return money.Format(amount)
+}
+
+// formatWholeMoney formats an amount in whole units with the formatter chosen by the global flags
+func formatWholeMoney(amount float64) string {
+	return money.FormatWhole(amount)
+}
+
+// configureMoney applies the --currency, --locale and --no-symbol flags
+func configureMoney() error {
+	formatter, err := newMoneyFormatter(currencyCode, localeName, noSymbol)
+	if err != nil {
+		return err
+	}
+	money = formatter
+	return nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoneyFormatter_Format(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		locale   string
+		noSymbol bool
+		amount   float64
+		expected string
+	}{
+		{"US dollars", "USD", "en-US", false, 1234567.891, "$1,234,567.89"},
+		{"small amount", "USD", "en-US", false, 12.5, "$12.50"},
+		{"negative", "USD", "en-US", false, -1234.5, "-$1,234.50"},
+		{"rounds to zero", "USD", "en-US", false, -0.001, "$0.00"},
+		{"euros in Germany", "EUR", "de-DE", false, 1234567.891, "1.234.567,89 €"},
+		{"negative euros in Germany", "eur", "de_DE", false, -1234.5, "-1.234,50 €"},
+		{"euros in France", "EUR", "fr-FR", false, 9876.5, "9 876,50 €"},
+		{"pounds", "GBP", "en-GB", false, 1000, "£1,000.00"},
+		{"yen has no minor unit", "JPY", "ja-JP", false, 1234567.6, "¥1,234,568"},
+		{"Swiss francs", "CHF", "de-CH", false, 1234.5, "CHF 1'234.50"},
+		{"unknown currency uses its code", "SEK", "en-US", false, 1500, "SEK 1,500.00"},
+		{"bare amounts for scripts", "EUR", "de-DE", true, 1234567.891, "1234567.89"},
+		{"bare negative", "USD", "en-US", true, -42, "-42.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			formatter, err := newMoneyFormatter(tt.currency, tt.locale, tt.noSymbol)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, formatter.Format(tt.amount))
+		})
+	}
+}
+
+func TestMoneyFormatter_FormatWhole(t *testing.T) {
+	formatter, err := newMoneyFormatter("USD", "en-US", false)
+	require.NoError(t, err)
+	assert.Equal(t, "$250,000", formatter.FormatWhole(249999.5))
+}
+
+func TestNewMoneyFormatter_Invalid(t *testing.T) {
+	_, err := newMoneyFormatter("DOLLARS", "en-US", false)
+	assert.Error(t, err)
+
+	_, err = newMoneyFormatter("USD", "xx-XX", false)
+	assert.Error(t, err)
+}
+
+func TestConfigureMoney(t *testing.T) {
+	defer func() {
+		currencyCode, localeName, noSymbol = "USD", "en-US", false
+		require.NoError(t, configureMoney())
+	}()
+
+	currencyCode, localeName = "EUR", "de-DE"
+	require.NoError(t, configureMoney())
+	assert.Equal(t, "1.500,00 €", formatMoney(1500))
+
+	noSymbol = true
+	require.NoError(t, configureMoney())
+	assert.Equal(t, "1500.00", formatMoney(1500))
+
+	localeName = "xx-XX"
+	assert.Error(t, configureMoney())
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+var (
+	overviewAgency     string
+	overviewCostCenter string
+	overviewTags       []string
+)
+
+var overviewCmd = &cobra.Command{
+	Use:   "overview",
+	Short: "Show org-wide budget totals",
+	Long: `Show total allocated, spent and held budget and utilization across all
+active accounts, broken down by account status and funding agency.
+
+Examples:
+  # Show the whole organization
+  asbb overview
+
+  # Show only accounts funded by one agency
+  asbb overview --agency="National Science Foundation"
+
+  # Show only accounts charged to one cost center
+  asbb overview --cost-center=CC-1042
+
+  # Show only course accounts
+  asbb overview --tag=kind=course`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		overview, err := client.GetOverview(cmd.Context(), &api.OverviewRequest{
+			FundingAgency: overviewAgency,
+			CostCenter:    overviewCostCenter,
+			Tags:          overviewTags,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to get overview: %w", err)
+		}
+
+		return renderOverview(os.Stdout, overview)
+	},
+}
+
+// renderOverview writes the org-wide totals and their breakdowns as tables
+func renderOverview(out io.Writer, overview *api.Overview) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	// Keep the first write error rather than checking every line of the tables
+	var writeErr error
+	line := func(format string, args ...interface{}) {
+		if writeErr == nil {
+			_, writeErr = fmt.Fprintf(w, format+"\n", args...)
+		}
+	}
+	row := func(name string, totals api.OverviewTotals) {
+		line("%s\t%d\t%s\t%s\t%s\t%.1f%%", name, totals.Accounts, formatMoney(totals.TotalAllocated),
+			formatMoney(totals.TotalSpent), formatMoney(totals.TotalHeld), totals.Utilization)
+	}
+
+	if overview.FundingAgency != "" {
+		line("Funding agency:\t%s", overview.FundingAgency)
+	}
+	if overview.CostCenter != "" {
+		line("Cost center:\t%s", overview.CostCenter)
+	}
+	if len(overview.Tags) > 0 {
+		line("Tags:\t%s", strings.Join(overview.Tags, ", "))
+	}
+	if overview.FundingAgency != "" || overview.CostCenter != "" || len(overview.Tags) > 0 {
+		line("")
+	}
+
+	line("\tACCOUNTS\tALLOCATED\tSPENT\tHELD\tUTILIZATION")
+	row("TOTAL (active)", overview.Totals)
+	line("")
+
+	line("STATUS\tACCOUNTS\tALLOCATED\tSPENT\tHELD\tUTILIZATION")
+	for _, status := range overview.ByStatus {
+		row(status.Status, status.OverviewTotals)
+	}
+	line("")
+
+	line("AGENCY (active)\tACCOUNTS\tALLOCATED\tSPENT\tHELD\tUTILIZATION")
+	for _, agency := range overview.ByAgency {
+		name := agency.FundingAgency
+		if name == "" {
+			name = "(no grant)"
+		}
+		row(name, agency.OverviewTotals)
+	}
+
+	if writeErr != nil {
+		return fmt.Errorf("failed to write overview: %w", writeErr)
+	}
+	return w.Flush()
+}
+
+func init() {
+	overviewCmd.Flags().StringVar(&overviewAgency, "agency", "", "only accounts funded by this grant agency")
+	overviewCmd.Flags().StringVar(&overviewCostCenter, "cost-center", "", "only accounts charged to this cost center")
+	overviewCmd.Flags().StringArrayVar(&overviewTags, "tag", nil, "only accounts with this tag, as key=value or key; repeat to require several")
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestRenderOverview(t *testing.T) {
+	overview := &api.Overview{
+		Totals: api.OverviewTotals{Accounts: 3, TotalAllocated: 30000, TotalSpent: 9000, TotalHeld: 1500, Utilization: 35},
+		ByStatus: []api.StatusOverview{
+			{Status: "active", OverviewTotals: api.OverviewTotals{Accounts: 3, TotalAllocated: 30000, TotalSpent: 9000, TotalHeld: 1500, Utilization: 35}},
+			{Status: "suspended", OverviewTotals: api.OverviewTotals{Accounts: 1, TotalAllocated: 5000, TotalSpent: 5000, Utilization: 100}},
+		},
+		ByAgency: []api.AgencyOverview{
+			{FundingAgency: "", OverviewTotals: api.OverviewTotals{Accounts: 1, TotalAllocated: 10000, TotalSpent: 1000, Utilization: 10}},
+			{FundingAgency: "NSF", OverviewTotals: api.OverviewTotals{Accounts: 2, TotalAllocated: 20000, TotalSpent: 8000, TotalHeld: 1500, Utilization: 47.5}},
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, renderOverview(&out, overview))
+	assert.Equal(t, `                ACCOUNTS  ALLOCATED   SPENT      HELD       UTILIZATION
+TOTAL (active)  3         $30,000.00  $9,000.00  $1,500.00  35.0%
+
+STATUS     ACCOUNTS  ALLOCATED   SPENT      HELD       UTILIZATION
+active     3         $30,000.00  $9,000.00  $1,500.00  35.0%
+suspended  1         $5,000.00   $5,000.00  $0.00      100.0%
+
+AGENCY (active)  ACCOUNTS  ALLOCATED   SPENT      HELD       UTILIZATION
+(no grant)       1         $10,000.00  $1,000.00  $0.00      10.0%
+NSF              2         $20,000.00  $8,000.00  $1,500.00  47.5%
+`, out.String())
+}
+
+func TestRenderOverview_Filters(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, renderOverview(&out, &api.Overview{FundingAgency: "NSF", CostCenter: "CC-1042", Tags: []string{"kind=course"}}))
+	assert.True(t, strings.HasPrefix(out.String(), "Funding agency:  NSF\nCost center:     CC-1042\nTags:            kind=course\n\n"), out.String())
+}
@@ -0,0 +1,179 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/discovery"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+var (
+	statusWatch    bool
+	statusInterval time.Duration
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show an operational overview of the budget service",
+	Long: `Show service health, database connection stats, ecosystem services,
+pending holds and total held and available budget in one dashboard.
+
+Examples:
+  # Show the dashboard once
+  asbb status
+
+  # Refresh the dashboard every 10 seconds
+  asbb status --watch --interval 10s`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if statusInterval <= 0 {
+			return fmt.Errorf("--interval must be positive")
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		if !statusWatch {
+			return renderStatus(os.Stdout, collectStatus(cmd.Context(), client))
+		}
+
+		ticker := time.NewTicker(statusInterval)
+		defer ticker.Stop()
+		for {
+			// Clear the screen and home the cursor before each refresh
+			fmt.Print("\033[H\033[2J")
+			if err := renderStatus(os.Stdout, collectStatus(cmd.Context(), client)); err != nil {
+				return err
+			}
+
+			select {
+			case <-cmd.Context().Done():
+				return nil
+			case <-ticker.C:
+			}
+		}
+	},
+}
+
+// statusSnapshot is everything the status dashboard shows, with the error for any part
+// that could not be fetched
+type statusSnapshot struct {
+	Taken      time.Time
+	Health     *api.HealthCheckResponse
+	HealthErr  error
+	Summary    *api.AdminSummary
+	SummaryErr error
+	Ecosystem  map[string]*discovery.ServiceInfo
+}
+
+// collectStatus fetches the dashboard's data. A part that fails is reported in the
+// snapshot rather than ending the dashboard.
+func collectStatus(ctx context.Context, client *api.Client) *statusSnapshot {
+	snapshot := &statusSnapshot{Taken: time.Now()}
+	snapshot.Health, snapshot.HealthErr = client.Health(ctx)
+	snapshot.Summary, snapshot.SummaryErr = client.GetAdminSummary(ctx)
+
+	discoveryCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	snapshot.Ecosystem = discovery.NewServiceDiscovery().DiscoverEcosystem(discoveryCtx)
+
+	return snapshot
+}
+
+// renderStatus writes the dashboard for a snapshot
+func renderStatus(out io.Writer, snapshot *statusSnapshot) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	// Keep the first write error rather than checking every line of the dashboard
+	var writeErr error
+	line := func(format string, args ...interface{}) {
+		if writeErr == nil {
+			_, writeErr = fmt.Fprintf(w, format+"\n", args...)
+		}
+	}
+
+	line("ASBB Status\t%s", snapshot.Taken.Format(time.RFC3339))
+	line("")
+
+	line("SERVICE")
+	if snapshot.HealthErr != nil {
+		line("  Health\tunavailable: %v", snapshot.HealthErr)
+	} else {
+		line("  Health\t%s (v%s, up %s)", snapshot.Health.Status, snapshot.Health.Version, snapshot.Health.Uptime)
+		for _, name := range sortedKeys(snapshot.Health.Services) {
+			line("  %s\t%s", name, snapshot.Health.Services[name])
+		}
+	}
+	line("")
+
+	line("BUDGET")
+	if snapshot.SummaryErr != nil {
+		line("  Summary\tunavailable: %v", snapshot.SummaryErr)
+	} else {
+		summary := snapshot.Summary
+		line("  Accounts\t%d (%d active)", summary.Accounts, summary.ActiveAccounts)
+		line("  Pending holds\t%d", summary.PendingHolds)
+		line("  Limit\t%s", formatMoney(summary.TotalLimit))
+		line("  Used\t%s", formatMoney(summary.TotalUsed))
+		line("  Held\t%s", formatMoney(summary.TotalHeld))
+		line("  Available\t%s", formatMoney(summary.TotalAvailable))
+		line("")
+
+		db := summary.Database
+		line("DATABASE")
+		line("  Connections\t%d open (%d in use, %d idle) of %d", db.OpenConnections, db.InUse, db.Idle, db.MaxOpenConnections)
+		line("  Waits\t%d (%s)", db.WaitCount, db.WaitDuration)
+	}
+	line("")
+
+	line("ECOSYSTEM")
+	names := make([]string, 0, len(snapshot.Ecosystem))
+	for name := range snapshot.Ecosystem {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		service := snapshot.Ecosystem[name]
+		state := "unavailable"
+		if service.Available {
+			state = "available"
+			if service.Version != "" {
+				state += " (v" + service.Version + ")"
+			}
+		}
+		line("  %s\t%s\t%s", name, state, service.Endpoint)
+	}
+
+	if writeErr != nil {
+		return fmt.Errorf("failed to write status: %w", writeErr)
+	}
+	return w.Flush()
+}
+
+// sortedKeys returns a map's keys in order, so the dashboard does not reshuffle on refresh
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusWatch, "watch", false, "refresh the dashboard until interrupted")
+	statusCmd.Flags().DurationVar(&statusInterval, "interval", 5*time.Second, "refresh interval with --watch")
+}
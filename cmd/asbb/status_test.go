@@ -0,0 +1,91 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/discovery"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestRenderStatus(t *testing.T) {
+	snapshot := &statusSnapshot{
+		Taken: time.Date(2025, 9, 15, 10, 30, 0, 0, time.UTC),
+		Health: &api.HealthCheckResponse{
+			Status:   "healthy",
+			Version:  "0.3.0",
+			Uptime:   "72h0m0s",
+			Services: map[string]string{"database": "healthy", "advisor": "unknown"},
+		},
+		Summary: &api.AdminSummary{
+			Accounts:       12,
+			ActiveAccounts: 10,
+			PendingHolds:   7,
+			TotalLimit:     50000,
+			TotalUsed:      12500.5,
+			TotalHeld:      820.25,
+			TotalAvailable: 36679.25,
+			Database: api.DatabaseStats{
+				MaxOpenConnections: 25, OpenConnections: 4, InUse: 1, Idle: 3, WaitCount: 2, WaitDuration: "15ms",
+			},
+		},
+		Ecosystem: map[string]*discovery.ServiceInfo{
+			"asbx":    {Endpoint: "http://localhost:8082", Available: true, Version: "1.2.0"},
+			"advisor": {Endpoint: "http://localhost:8081"},
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, renderStatus(&out, snapshot))
+	assert.Equal(t, `ASBB Status  2025-09-15T10:30:00Z
+
+SERVICE
+  Health    healthy (v0.3.0, up 72h0m0s)
+  advisor   unknown
+  database  healthy
+
+BUDGET
+  Accounts       12 (10 active)
+  Pending holds  7
+  Limit          $50,000.00
+  Used           $12,500.50
+  Held           $820.25
+  Available      $36,679.25
+
+DATABASE
+  Connections  4 open (1 in use, 3 idle) of 25
+  Waits        2 (15ms)
+
+ECOSYSTEM
+  advisor  unavailable         http://localhost:8081
+  asbx     available (v1.2.0)  http://localhost:8082
+`, out.String())
+}
+
+func TestRenderStatus_Unavailable(t *testing.T) {
+	snapshot := &statusSnapshot{
+		Taken:      time.Date(2025, 9, 15, 10, 30, 0, 0, time.UTC),
+		HealthErr:  errors.New("connection refused"),
+		SummaryErr: errors.New("connection refused"),
+	}
+
+	var out strings.Builder
+	require.NoError(t, renderStatus(&out, snapshot))
+	assert.Contains(t, out.String(), "Health  unavailable: connection refused")
+	assert.Contains(t, out.String(), "Summary  unavailable: connection refused")
+	assert.NotContains(t, out.String(), "DATABASE")
+}
+
+func TestStatusCommand_Flags(t *testing.T) {
+	assert.NotNil(t, statusCmd.Flags().Lookup("watch"))
+	assert.NotNil(t, statusCmd.Flags().Lookup("interval"))
+}
@@ -6,8 +6,14 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/slurm"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
 var transactionCmd = &cobra.Command{
@@ -45,6 +51,111 @@ var reconcileCmd = &cobra.Command{
 	},
 }
 
+var (
+	reconcileSacctSince  string
+	reconcileSacctInput  string
+	reconcileSacctFormat string
+)
+
+var reconcileSacctCmd = &cobra.Command{
+	Use:   "reconcile-sacct",
+	Short: "Reconcile holds from SLURM accounting data",
+	Long: `Reconcile budget holds from SLURM accounting (sacct) records, for sites that do not run ASBX.
+
+Finished jobs are priced from the resources they actually used and reconciled against their
+hold. The hold is found from an asbb_txn=<transaction-id> token in the job's admin comment or
+comment, or else from a hold recorded with the job ID. Jobs without a hold are reported and
+left uncharged; jobs already charged are skipped, so runs can overlap.
+
+Examples:
+  # Run sacct for jobs started since September 1st
+  asbb reconcile-sacct --since=2025-09-01
+
+  # Reconcile from captured sacct --parsable2 output
+  asbb reconcile-sacct --since=2025-09-01 --input=sacct.txt --format=parsable2`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		since, err := time.ParseInLocation("2006-01-02", reconcileSacctSince, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid --since date %q, expected YYYY-MM-DD", reconcileSacctSince)
+		}
+
+		jobs, err := readAccountingJobs(cmd, since)
+		if err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			fmt.Println("No jobs found in accounting data.")
+			return nil
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		result, err := client.ReconcileAccountingJobs(cmd.Context(), &api.AccountingReconcileRequest{Jobs: jobs})
+		if err != nil {
+			return fmt.Errorf("failed to reconcile accounting data: %w", err)
+		}
+
+		fmt.Printf("Reconciled: %d  Already reconciled: %d  No hold: %d  Unfinished: %d  Failed: %d\n",
+			result.Reconciled, result.AlreadyReconciled, result.NoHold, result.Unfinished, result.Failed)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		defer func() {
+			if err := w.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to flush output: %v\n", err)
+			}
+		}()
+
+		if _, err := fmt.Fprintln(w, "\nJOB_ID\tACCOUNT\tSTATUS\tACTUAL_COST\tREFUND\tMESSAGE"); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		for _, job := range result.Jobs {
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", job.JobID, job.Account, job.Status,
+				formatMoney(job.ActualCost), formatMoney(job.RefundAmount), job.Message); err != nil {
+				return fmt.Errorf("failed to write job: %w", err)
+			}
+		}
+
+		if result.Failed > 0 {
+			return fmt.Errorf("%d jobs failed to reconcile", result.Failed)
+		}
+		return nil
+	},
+}
+
+// readAccountingJobs reads accounting records from --input, or runs sacct when none is given
+func readAccountingJobs(cmd *cobra.Command, since time.Time) ([]*api.AccountingJob, error) {
+	if reconcileSacctInput == "" {
+		jobs, err := slurm.RunSacct(cmd.Context(), since, reconcileSacctFormat)
+		if err != nil {
+			return nil, err
+		}
+		return jobs, nil
+	}
+
+	f, err := os.Open(reconcileSacctInput)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sacct output: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	jobs, err := slurm.Parse(f, reconcileSacctFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	// Captured output may cover more than asked for
+	var recent []*api.AccountingJob
+	for _, job := range jobs {
+		if job.Start == nil || !job.Start.Before(since) {
+			recent = append(recent, job)
+		}
+	}
+	return recent, nil
+}
+
 var recoverCmd = &cobra.Command{
 	Use:   "recover",
 	Short: "Recover orphaned transactions",
@@ -54,6 +165,94 @@ var recoverCmd = &cobra.Command{
 	},
 }
 
+var (
+	verifyRepair bool
+	verifyReason string
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [account]",
+	Short: "Check cached account balances against the transaction ledger",
+	Long: `Recompute each account's used and held balances from its transaction ledger and report
+any account whose cached balances have drifted. With no account every account is checked.
+
+With --repair (admin) drifted balances are corrected to the ledger values. Each repair
+locks the account while it runs and is recorded in the balance repair audit trail.
+
+Examples:
+  # Check every account
+  asbb verify
+
+  # Repair a single account
+  asbb verify proj001 --repair --reason="Manual edit during migration"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := &api.ConsistencyCheckRequest{}
+		if len(args) == 1 {
+			req.Account = args[0]
+		}
+
+		client, err := getAPIClient()
+		if err != nil {
+			return fmt.Errorf("failed to create API client: %w", err)
+		}
+
+		var result *api.ConsistencyCheckResponse
+		if verifyRepair {
+			req.Repair = true
+			req.Reason = verifyReason
+			req.RepairedBy = os.Getenv("USER")
+			result, err = client.RepairConsistency(cmd.Context(), req)
+		} else {
+			result, err = client.CheckConsistency(cmd.Context(), req)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to verify accounts: %w", err)
+		}
+
+		fmt.Printf("Checked: %d  Inconsistent: %d  Repaired: %d\n", result.Checked, result.Inconsistent, result.Repaired)
+		if result.Inconsistent == 0 {
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		defer func() {
+			if err := w.Flush(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Failed to flush output: %v\n", err)
+			}
+		}()
+
+		if _, err := fmt.Fprintln(w, "\nACCOUNT\tCACHED_USED\tEXPECTED_USED\tCACHED_HELD\tEXPECTED_HELD\tREPAIRED"); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		for _, account := range result.Accounts {
+			if account.Consistent {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%t\n", account.Account,
+				formatMoney(account.CachedUsed), formatMoney(account.ExpectedUsed),
+				formatMoney(account.CachedHeld), formatMoney(account.ExpectedHeld), account.Repaired); err != nil {
+				return fmt.Errorf("failed to write account: %w", err)
+			}
+		}
+
+		if !verifyRepair {
+			return fmt.Errorf("%d accounts have drifted from the ledger", result.Inconsistent)
+		}
+		return nil
+	},
+}
+
 func init() {
 	transactionCmd.AddCommand(transactionListCmd)
+
+	verifyCmd.Flags().BoolVar(&verifyRepair, "repair", false, "Correct drifted balances to the ledger values (admin)")
+	verifyCmd.Flags().StringVar(&verifyReason, "reason", "", "Reason recorded in the repair audit trail")
+
+	reconcileSacctCmd.Flags().StringVar(&reconcileSacctSince, "since", "", "Reconcile jobs started on or after this date (YYYY-MM-DD)")
+	reconcileSacctCmd.Flags().StringVar(&reconcileSacctInput, "input", "", "Read captured sacct output from a file instead of running sacct")
+	reconcileSacctCmd.Flags().StringVar(&reconcileSacctFormat, "format", slurm.FormatJSON, "sacct output format (json, parsable2)")
+	if err := reconcileSacctCmd.MarkFlagRequired("since"); err != nil {
+		panic(err) // This should never happen during initialization
+	}
 }
@@ -0,0 +1,138 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// userHeader names the user a request acts for. The SLURM plugin, or a proxy that has
+// authenticated the user, sets it.
+const userHeader = "X-ASBB-User"
+
+// requestUser is who a request acts for once auth.enabled is set
+type requestUser struct {
+	Name  string
+	Admin bool // admin API keys skip membership checks
+}
+
+type requestUserKey struct{}
+
+// userFromContext returns who the request acts for; ok is false when auth is disabled
+func userFromContext(ctx context.Context) (*requestUser, bool) {
+	user, ok := ctx.Value(requestUserKey{}).(*requestUser)
+	return user, ok
+}
+
+// userAuthMiddleware identifies who each request acts for when auth.enabled is set, from
+// the X-ASBB-User header. With auth.api_key_auth a bearer token from auth.api_keys or
+// auth.admin_api_keys is required too. Only an admin API key makes a request an admin's:
+// the header is set by the caller, so naming a user in it never grants more than that
+// user's membership.
+func userAuthMiddleware(cfg config.AuthConfig) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		if !cfg.Enabled {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			admin := token != "" && matchesKey(token, cfg.AdminAPIKeys)
+			if cfg.APIKeyAuth && !admin && (token == "" || !matchesKey(token, cfg.APIKeys)) {
+				writeError(w, api.ErrUnauthorized)
+				return
+			}
+
+			user := &requestUser{Name: strings.TrimSpace(r.Header.Get(userHeader)), Admin: admin}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestUserKey{}, user)))
+		})
+	}
+}
+
+// matchesKey reports whether token is one of keys, in constant time for each key
+func matchesKey(token string, keys []string) bool {
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// memberCheckUser returns the user whose account membership a request needs, or "" when
+// none is needed because auth is disabled or an admin is acting. A request that names no
+// user is unauthorized.
+func memberCheckUser(r *http.Request) (string, error) {
+	user, ok := userFromContext(r.Context())
+	if !ok || user.Admin {
+		return "", nil
+	}
+	if user.Name == "" {
+		return "", api.NewBudgetError(api.ErrCodeUnauthorized, "Unauthorized access",
+			"Set the "+userHeader+" header to the user the request acts for")
+	}
+	return user.Name, nil
+}
+
+// requireAdmin refuses a non-admin request once auth is enabled, for operations such as
+// managing account members that would otherwise let users grant themselves access
+func requireAdmin(r *http.Request) error {
+	user, ok := userFromContext(r.Context())
+	if !ok || user.Admin {
+		return nil
+	}
+	return api.NewBudgetError(api.ErrCodeForbidden, "Access forbidden", "Only administrators may do this")
+}
+
+// requireSelfOrAdmin refuses a non-admin request about another user once auth is enabled
+func requireSelfOrAdmin(r *http.Request, subject string) error {
+	user, err := memberCheckUser(r)
+	if err != nil || user == "" || user == subject {
+		return err
+	}
+	return api.NewBudgetError(api.ErrCodeForbidden, "Access forbidden", "Users may only view their own accounts")
+}
+
+// budgetAuthorizer checks that users act only on the accounts they are members of
+type budgetAuthorizer interface {
+	AuthorizeBudgetCheck(ctx context.Context, user string, req *api.BudgetCheckRequest) error
+	AuthorizeReconcile(ctx context.Context, user string, req *api.JobReconcileRequest) error
+}
+
+// authorizeBudgetCheck refuses a non-admin's budget check on an account they are not a
+// member of, and one made for another user. The check is recorded as the caller's.
+func authorizeBudgetCheck(r *http.Request, authorizer budgetAuthorizer, req *api.BudgetCheckRequest) error {
+	user, err := memberCheckUser(r)
+	if err != nil || user == "" {
+		return err
+	}
+
+	if req.UserID != "" && req.UserID != user {
+		return api.NewBudgetError(api.ErrCodeForbidden, "Budget checks may only be made for your own jobs",
+			"user_id is "+req.UserID+" but the request acts for "+user)
+	}
+	req.UserID = user
+
+	return authorizer.AuthorizeBudgetCheck(r.Context(), user, req)
+}
+
+// authorizeReconcile refuses a non-admin's reconciliation against an account they are not
+// a member of
+func authorizeReconcile(r *http.Request, authorizer budgetAuthorizer, req *api.JobReconcileRequest) error {
+	user, err := memberCheckUser(r)
+	if err != nil || user == "" {
+		return err
+	}
+
+	return authorizer.AuthorizeReconcile(r.Context(), user, req)
+}
@@ -0,0 +1,337 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// fakeMembershipBudget approves every budget check and reconciliation its membership
+// allows; alice is a member of proj001, and hold txn-1 is on proj001
+type fakeMembershipBudget struct {
+	checks, reconciles, previews int
+	lastUserID                   string
+}
+
+func (f *fakeMembershipBudget) authorize(account, user string) error {
+	if account == "proj001" && user == "alice" {
+		return nil
+	}
+	return api.NewNotAccountMemberError(account, user)
+}
+
+func (f *fakeMembershipBudget) AuthorizeBudgetCheck(_ context.Context, user string, req *api.BudgetCheckRequest) error {
+	return f.authorize(req.Account, user)
+}
+
+func (f *fakeMembershipBudget) AuthorizeReconcile(_ context.Context, user string, req *api.JobReconcileRequest) error {
+	account := req.Account
+	if req.TransactionID == "txn-1" {
+		account = "proj001"
+	}
+	return f.authorize(account, user)
+}
+
+func (f *fakeMembershipBudget) CheckBudget(_ context.Context, req *api.BudgetCheckRequest) (*api.BudgetCheckResponse, error) {
+	f.checks++
+	f.lastUserID = req.UserID
+	return &api.BudgetCheckResponse{Available: true}, nil
+}
+
+func (f *fakeMembershipBudget) ReconcileJob(_ context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
+	f.reconciles++
+	return &api.JobReconcileResponse{Success: true}, nil
+}
+
+func (f *fakeMembershipBudget) PreviewReconciliation(_ context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
+	f.previews++
+	return &api.JobReconcileResponse{Success: true, Preview: true}, nil
+}
+
+func TestBudgetCheckMembership(t *testing.T) {
+	authCfg := config.AuthConfig{
+		Enabled:      true,
+		AdminUsers:   []string{"root"},
+		AdminAPIKeys: []string{"admin-key"},
+	}
+
+	newRouter := func(cfg config.AuthConfig, service *fakeMembershipBudget) *mux.Router {
+		router := mux.NewRouter()
+		v1 := router.PathPrefix("/api/v1").Subrouter()
+		v1.Use(userAuthMiddleware(cfg))
+		v1.HandleFunc("/budget/check", handleBudgetCheck(service)).Methods("POST")
+		v1.HandleFunc("/budget/reconcile", handleJobReconcile(service)).Methods("POST")
+		v1.HandleFunc("/budget/reconcile/preview", handleReconcilePreview(service)).Methods("POST")
+		return router
+	}
+
+	post := func(router *mux.Router, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	check := `{"account":"proj001","partition":"cpu","nodes":1,"cpus":4,"wall_time":"01:00:00"}`
+
+	t.Run("member is allowed", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		rec := post(newRouter(authCfg, service), "/api/v1/budget/check", check, map[string]string{userHeader: "alice"})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 1, service.checks)
+		assert.Equal(t, "alice", service.lastUserID)
+	})
+
+	t.Run("non-member is forbidden", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		rec := post(newRouter(authCfg, service), "/api/v1/budget/check", check, map[string]string{userHeader: "mallory"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not a member of account 'proj001'")
+		assert.Zero(t, service.checks)
+	})
+
+	t.Run("checks for another user are forbidden", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		body := `{"account":"proj001","partition":"cpu","nodes":1,"cpus":4,"wall_time":"01:00:00","user_id":"bob"}`
+		rec := post(newRouter(authCfg, service), "/api/v1/budget/check", body, map[string]string{userHeader: "alice"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Zero(t, service.checks)
+	})
+
+	t.Run("request naming no user is unauthorized", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		rec := post(newRouter(authCfg, service), "/api/v1/budget/check", check, nil)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Zero(t, service.checks)
+	})
+
+	t.Run("admins skip membership", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		router := newRouter(authCfg, service)
+		assert.Equal(t, http.StatusOK, post(router, "/api/v1/budget/check", check, map[string]string{"Authorization": "Bearer admin-key"}).Code)
+		assert.Equal(t, 1, service.checks)
+	})
+
+	t.Run("naming an admin user does not make a request an admin's", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		rec := post(newRouter(authCfg, service), "/api/v1/budget/check", check, map[string]string{userHeader: "root"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Zero(t, service.checks)
+	})
+
+	t.Run("reconcile follows the hold's account", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		router := newRouter(authCfg, service)
+		body := `{"job_id":"123","actual_cost":4.5,"transaction_id":"txn-1"}`
+		assert.Equal(t, http.StatusOK, post(router, "/api/v1/budget/reconcile", body, map[string]string{userHeader: "alice"}).Code)
+		assert.Equal(t, http.StatusForbidden, post(router, "/api/v1/budget/reconcile", body, map[string]string{userHeader: "mallory"}).Code)
+		assert.Equal(t, 1, service.reconciles)
+	})
+
+	t.Run("preview follows the hold's account and reconciles nothing", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		router := newRouter(authCfg, service)
+		body := `{"job_id":"123","actual_cost":4.5,"transaction_id":"txn-1"}`
+		rec := post(router, "/api/v1/budget/reconcile/preview", body, map[string]string{userHeader: "alice"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"preview":true`)
+		assert.Equal(t, http.StatusForbidden, post(router, "/api/v1/budget/reconcile/preview", body, map[string]string{userHeader: "mallory"}).Code)
+		assert.Equal(t, 1, service.previews)
+		assert.Zero(t, service.reconciles)
+	})
+
+	t.Run("auth disabled checks nothing", func(t *testing.T) {
+		service := &fakeMembershipBudget{}
+		rec := post(newRouter(config.AuthConfig{}, service), "/api/v1/budget/check", check, nil)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, service.lastUserID)
+	})
+
+	t.Run("API key auth", func(t *testing.T) {
+		cfg := authCfg
+		cfg.APIKeyAuth = true
+		cfg.APIKeys = []string{"plugin-key"}
+		service := &fakeMembershipBudget{}
+		router := newRouter(cfg, service)
+
+		rec := post(router, "/api/v1/budget/check", check, map[string]string{userHeader: "alice"})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = post(router, "/api/v1/budget/check", check, map[string]string{userHeader: "alice", "Authorization": "Bearer wrong"})
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		rec = post(router, "/api/v1/budget/check", check, map[string]string{userHeader: "alice", "Authorization": "Bearer plugin-key"})
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = post(router, "/api/v1/budget/check", check, map[string]string{userHeader: "mallory", "Authorization": "Bearer plugin-key"})
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+func TestMemberManagementNeedsAdmin(t *testing.T) {
+	service := &fakeMemberService{members: map[string][]string{}}
+	router := mux.NewRouter()
+	router.Use(userAuthMiddleware(config.AuthConfig{Enabled: true, AdminUsers: []string{"root"}, AdminAPIKeys: []string{"admin-key"}}))
+	router.HandleFunc("/api/v1/accounts/{account}/members/{user}", handleAddAccountMember(service)).Methods("PUT")
+	router.HandleFunc("/api/v1/users/{user}/accounts", handleListUserAccounts(service)).Methods("GET")
+
+	// do sends a request as user, or with the admin API key when user is empty
+	do := func(method, path, user string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if user == "" {
+			req.Header.Set("Authorization", "Bearer admin-key")
+		} else {
+			req.Header.Set(userHeader, user)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Users cannot grant themselves access to an account
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPut, "/api/v1/accounts/proj001/members/alice", "alice"))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPut, "/api/v1/accounts/proj001/members/alice", "root"),
+		"an admin user named in the header is not an admin")
+	assert.Equal(t, http.StatusOK, do(http.MethodPut, "/api/v1/accounts/proj001/members/alice", ""))
+
+	// Users see only their own accounts
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/users/alice/accounts", "alice"))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/v1/users/alice/accounts", "bob"))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/users/alice/accounts", ""))
+}
+
+func TestAccountAdministrationNeedsAdmin(t *testing.T) {
+	router := mux.NewRouter()
+	router.Use(userAuthMiddleware(config.AuthConfig{Enabled: true, AdminAPIKeys: []string{"admin-key"}}))
+	// The budget.Service handlers refuse a non-admin before touching the service
+	router.HandleFunc("/api/v1/accounts", handleCreateAccount(nil)).Methods("POST")
+	router.HandleFunc("/api/v1/accounts/bulk", handleBulkCreateAccounts(nil)).Methods("POST")
+	router.HandleFunc("/api/v1/accounts/{account}", handleDeleteAccount(nil)).Methods("DELETE")
+	router.HandleFunc("/api/v1/allocations/process", handleProcessAllocations(nil)).Methods("POST")
+	router.HandleFunc("/api/v1/accounts/{account}/clone", handleCloneAccount(&fakeAccountCloner{})).Methods("POST")
+	router.HandleFunc("/api/v1/accounts/{account}/schedules", handleCreateAllocationSchedule(&fakeAccountScheduleService{})).Methods("POST")
+	router.HandleFunc("/api/v1/grants/{grant}/recompute-costs", handleRecomputeGrantCosts(&fakeGrantCostService{})).Methods("POST")
+
+	do := func(method, path, body string, admin bool) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set(userHeader, "alice")
+		if admin {
+			req.Header.Set("Authorization", "Bearer admin-key")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/accounts"},
+		{http.MethodPost, "/api/v1/accounts/bulk"},
+		{http.MethodDelete, "/api/v1/accounts/proj001"},
+		{http.MethodPost, "/api/v1/allocations/process"},
+		{http.MethodPost, "/api/v1/accounts/proj001/clone"},
+		{http.MethodPost, "/api/v1/accounts/proj001/schedules"},
+		{http.MethodPost, "/api/v1/grants/NIH-R01-1/recompute-costs"},
+	} {
+		assert.Equal(t, http.StatusForbidden, do(route.method, route.path, `{}`, false), "%s %s", route.method, route.path)
+	}
+
+	clone := `{"slurm_account":"proj002"}`
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/api/v1/accounts/proj001/clone", clone, true))
+	assert.Equal(t, http.StatusOK, do(http.MethodPost, "/api/v1/grants/NIH-R01-1/recompute-costs", "", true))
+}
+
+func TestJobStartedMembership(t *testing.T) {
+	service := &fakeJobStartedService{}
+	router := mux.NewRouter()
+	router.Use(userAuthMiddleware(config.AuthConfig{Enabled: true, AdminAPIKeys: []string{"admin-key"}}))
+	router.HandleFunc("/api/v1/jobs/{job_id}/started", handleJobStarted(service)).Methods("POST")
+
+	do := func(user string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/4242/started", bytes.NewBufferString(`{"transaction_id":"txn-queued"}`))
+		if user == "" {
+			req.Header.Set("Authorization", "Bearer admin-key")
+		} else {
+			req.Header.Set(userHeader, user)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Only members of the hold's account may escalate it
+	assert.Equal(t, http.StatusForbidden, do("bob"))
+	assert.Nil(t, service.last)
+	assert.Equal(t, http.StatusOK, do("alice"))
+	assert.Equal(t, http.StatusOK, do(""))
+}
+
+// fakeReserveService records the account updates and adjustments that get through
+type fakeReserveService struct {
+	updates, adjustments int
+}
+
+func (f *fakeReserveService) UpdateAccount(_ context.Context, slurmAccount string, req *api.UpdateAccountRequest) (*api.BudgetAccount, error) {
+	f.updates++
+	return &api.BudgetAccount{SlurmAccount: slurmAccount}, nil
+}
+
+func (f *fakeReserveService) AdjustBudget(_ context.Context, slurmAccount string, req *api.BudgetAdjustmentRequest) (*api.BudgetAdjustmentResponse, error) {
+	f.adjustments++
+	return &api.BudgetAdjustmentResponse{}, nil
+}
+
+func TestAccountChangesNeedAdmin(t *testing.T) {
+	service := &fakeReserveService{}
+	router := mux.NewRouter()
+	router.Use(userAuthMiddleware(config.AuthConfig{Enabled: true, AdminAPIKeys: []string{"admin-key"}}))
+	router.HandleFunc("/api/v1/accounts/{account}", handleUpdateAccount(service)).Methods("PUT")
+	router.HandleFunc("/api/v1/accounts/{account}/adjustments", handleAdjustBudget(service)).Methods("POST")
+
+	do := func(method, path, body string, admin bool) int {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set(userHeader, "alice")
+		if admin {
+			req.Header.Set("Authorization", "Bearer admin-key")
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	// Users cannot release the reserve, by adjustment or by lowering it
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/v1/accounts/proj001/adjustments", `{"amount":-50,"from_reserve":true}`, false))
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPut, "/api/v1/accounts/proj001", `{"reserved_amount":0}`, false))
+
+	// Nor raise the limit, unfreeze or reactivate the account, or change anything else about it
+	for _, body := range []string{
+		`{"budget_limit":1000000}`,
+		`{"frozen":false}`,
+		`{"status":"active"}`,
+		`{"hold_percentage":0.01}`,
+		`{"allowed_partitions":[]}`,
+		`{"name":"Project 1"}`,
+	} {
+		assert.Equal(t, http.StatusForbidden, do(http.MethodPut, "/api/v1/accounts/proj001", body, false), body)
+	}
+	assert.Zero(t, service.adjustments)
+	assert.Zero(t, service.updates)
+
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/api/v1/accounts/proj001/adjustments", `{"amount":-50,"from_reserve":true}`, true))
+	assert.Equal(t, http.StatusOK, do(http.MethodPut, "/api/v1/accounts/proj001", `{"reserved_amount":0}`, true))
+	assert.Equal(t, http.StatusOK, do(http.MethodPut, "/api/v1/accounts/proj001", `{"budget_limit":1000000}`, true))
+	assert.Equal(t, 1, service.adjustments)
+	assert.Equal(t, 2, service.updates)
+}
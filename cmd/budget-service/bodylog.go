@@ -0,0 +1,170 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+)
+
+// redactedValue replaces whatever is redacted from a logged request
+const redactedValue = "[REDACTED]"
+
+// defaultRedactFields are the JSON fields whose values are never logged, whatever
+// logging.body_redact_fields adds
+var defaultRedactFields = []string{
+	"jwt_secret", "api_key", "asbx_api_key", "admin_api_keys", "epilog_secret", "password", "secret", "token",
+}
+
+// redactedHeaders are the request headers whose values are never logged
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Api-Key":           true,
+	// An epilog signature could be replayed within the skew window
+	http.CanonicalHeaderKey(asbx.EpilogSignatureHeader): true,
+}
+
+// bodyLogger logs request and response bodies, cut off at maxBytes, with secrets redacted
+type bodyLogger struct {
+	maxBytes int
+	fields   *regexp.Regexp
+}
+
+// newBodyLogger builds a body logger from the logging configuration
+func newBodyLogger(cfg *config.LoggingConfig) *bodyLogger {
+	fields := make([]string, 0, len(defaultRedactFields)+len(cfg.BodyRedactFields))
+	for _, field := range append(append([]string{}, defaultRedactFields...), cfg.BodyRedactFields...) {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, regexp.QuoteMeta(field))
+		}
+	}
+
+	// A field's value is a string, possibly cut off by the size cap, an array of scalars, or
+	// a scalar
+	pattern := `"((?i:` + strings.Join(fields, "|") + `))"\s*:\s*` +
+		`(?:"(?:[^"\\]|\\.)*(?:"|\\?$)|\[[^\]]*(?:\]|$)|[^,}\]\s]+)`
+	return &bodyLogger{maxBytes: cfg.BodyMaxBytes, fields: regexp.MustCompile(pattern)}
+}
+
+// redact replaces the values of secret JSON fields in a body
+func (bl *bodyLogger) redact(body string) string {
+	return bl.fields.ReplaceAllString(body, `"$1":"`+redactedValue+`"`)
+}
+
+// headers returns a request's headers for logging, with credentials redacted
+func (bl *bodyLogger) headers(header http.Header) map[string]string {
+	logged := make(map[string]string, len(header))
+	for name, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			logged[name] = redactedValue
+			continue
+		}
+		logged[name] = strings.Join(values, ", ")
+	}
+	return logged
+}
+
+// bodyLoggingMiddleware logs each request's headers and body and its response's body at
+// debug level. The request body is logged as far as the handler read it. Nothing is
+// captured unless debug logging is on.
+func bodyLoggingMiddleware(bl *bodyLogger) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !debugLogging() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requestBody := &cappedBuffer{max: bl.maxBytes}
+			if r.Body != nil {
+				r.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.TeeReader(r.Body, requestBody), r.Body}
+			}
+			bw := &bodyLoggingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK, body: cappedBuffer{max: bl.maxBytes}}
+
+			next.ServeHTTP(bw, r)
+
+			log.Debug().
+				Str("method", r.Method).
+				Str("uri", r.RequestURI).
+				Int("status", bw.statusCode).
+				Interface("request_headers", bl.headers(r.Header)).
+				Str("request_body", bl.redact(requestBody.String())).
+				Bool("request_body_truncated", requestBody.Truncated()).
+				Str("response_body", bl.redact(bw.body.String())).
+				Bool("response_body_truncated", bw.body.Truncated()).
+				Msg("HTTP request body")
+		})
+	}
+}
+
+// debugLogging reports whether debug messages are logged
+func debugLogging() bool {
+	return zerolog.GlobalLevel() <= zerolog.DebugLevel && log.Logger.GetLevel() <= zerolog.DebugLevel
+}
+
+// cappedBuffer keeps the first max bytes written to it, or everything when max is zero,
+// and counts the rest
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	max   int
+	total int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	keep := p
+	if b.max > 0 {
+		room := b.max - b.buf.Len()
+		if room < 0 {
+			room = 0
+		}
+		if len(keep) > room {
+			keep = keep[:room]
+		}
+	}
+	b.buf.Write(keep)
+	return len(p), nil
+}
+
+func (b *cappedBuffer) String() string {
+	return b.buf.String()
+}
+
+// Truncated reports whether more was written than was kept
+func (b *cappedBuffer) Truncated() bool {
+	return b.total > b.buf.Len()
+}
+
+// bodyLoggingResponseWriter records the status and body of a response as it is written
+type bodyLoggingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       cappedBuffer
+}
+
+func (w *bodyLoggingResponseWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *bodyLoggingResponseWriter) Write(p []byte) (int, error) {
+	_, _ = w.body.Write(p)
+	return w.ResponseWriter.Write(p)
+}
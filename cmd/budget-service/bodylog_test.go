@@ -0,0 +1,117 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+)
+
+func TestBodyLoggingMiddleware(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"account":"proj001","api_key":"sk-response"}`)
+	})
+
+	// serve runs a request through the middleware with the logger at level and returns
+	// what was logged
+	serve := func(t *testing.T, cfg config.LoggingConfig, level zerolog.Level, body string) string {
+		var buf bytes.Buffer
+		saved := log.Logger
+		log.Logger = zerolog.New(&buf).Level(level)
+		defer func() { log.Logger = saved }()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret-token")
+		req.Header.Set(asbx.EpilogSignatureHeader, "epilog-signature")
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		bodyLoggingMiddleware(newBodyLogger(&cfg))(handler).ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), "sk-response", "the response itself is not redacted")
+		return buf.String()
+	}
+
+	request := `{"account":"proj001","jwt_secret":"hunter2","nested":{"password":"pw"},"grant":"NSF-1"}`
+
+	t.Run("bodies logged with secrets redacted", func(t *testing.T) {
+		out := serve(t, config.LoggingConfig{BodyMaxBytes: 4096, BodyRedactFields: []string{"grant"}}, zerolog.DebugLevel, request)
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &entry))
+		assert.Equal(t, "POST", entry["method"])
+		assert.Equal(t, float64(http.StatusCreated), entry["status"])
+		assert.Equal(t,
+			`{"account":"proj001","jwt_secret":"[REDACTED]","nested":{"password":"[REDACTED]"},"grant":"[REDACTED]"}`,
+			entry["request_body"])
+		assert.Equal(t, `{"account":"proj001","api_key":"[REDACTED]"}`, entry["response_body"])
+		assert.Equal(t, false, entry["request_body_truncated"])
+
+		headers := entry["request_headers"].(map[string]interface{})
+		assert.Equal(t, redactedValue, headers["Authorization"])
+		assert.Equal(t, redactedValue, headers[http.CanonicalHeaderKey(asbx.EpilogSignatureHeader)])
+		assert.Equal(t, "application/json", headers["Content-Type"])
+
+		for _, secret := range []string{"hunter2", "secret-token", "epilog-signature", "sk-response", `"pw"`, "NSF-1"} {
+			assert.NotContains(t, out, secret)
+		}
+	})
+
+	t.Run("bodies cut off at the size cap", func(t *testing.T) {
+		out := serve(t, config.LoggingConfig{BodyMaxBytes: 38}, zerolog.DebugLevel, request)
+
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(out), &entry))
+		assert.Equal(t, `{"account":"proj001","jwt_secret":"[REDACTED]"`, entry["request_body"])
+		assert.Equal(t, true, entry["request_body_truncated"])
+		assert.Equal(t, true, entry["response_body_truncated"])
+		assert.NotContains(t, out, "hunter")
+	})
+
+	t.Run("nothing logged above debug level", func(t *testing.T) {
+		out := serve(t, config.LoggingConfig{BodyMaxBytes: 4096}, zerolog.InfoLevel, request)
+		assert.Empty(t, out)
+	})
+}
+
+func TestBodyLoggerRedact(t *testing.T) {
+	bl := newBodyLogger(&config.LoggingConfig{})
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"string", `{"token":"abc"}`, `{"token":"[REDACTED]"}`},
+		{"case insensitive", `{"API_KEY": "abc"}`, `{"API_KEY":"[REDACTED]"}`},
+		{"escaped quote", `{"password":"a\"b","x":1}`, `{"password":"[REDACTED]","x":1}`},
+		{"array", `{"admin_api_keys":["a","b"],"x":1}`, `{"admin_api_keys":"[REDACTED]","x":1}`},
+		{"scalar", `{"secret":12345}`, `{"secret":"[REDACTED]"}`},
+		{"cut off", `{"jwt_secret":"abcd`, `{"jwt_secret":"[REDACTED]"`},
+		{"other fields kept", `{"token_count":3,"account":"proj001"}`, `{"token_count":3,"account":"proj001"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, bl.redact(tt.body))
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+)
+
+// compressionMiddleware gzips response bodies of at least minSize bytes for clients that
+// accept gzip. The body is buffered until it reaches minSize, so smaller responses are sent
+// as they are; the status is passed on only once that is decided, so an outer middleware
+// still sees the handler's status.
+func compressionMiddleware(minSize int) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if r.Method == http.MethodHead || !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize, statusCode: http.StatusOK}
+			defer func() {
+				if err := gw.Close(); err != nil {
+					log.Error().Err(err).Str("uri", r.RequestURI).Msg("Failed to write compressed response")
+				}
+			}()
+			next.ServeHTTP(gw, r)
+		})
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response. A q-value
+// of 0 refuses it.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if weight, err := strconv.ParseFloat(q, 64); err == nil && weight == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter holds back the status and the first minSize bytes of a response until
+// it knows whether the body is large enough to compress
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize     int
+	statusCode  int
+	buf         []byte
+	gz          *gzip.Writer
+	decided     bool
+	wroteHeader bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(code int) {
+	if !g.wroteHeader {
+		g.statusCode = code
+		g.wroteHeader = true
+	}
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	g.wroteHeader = true
+	if g.decided {
+		if g.gz != nil {
+			return g.gz.Write(p)
+		}
+		return g.ResponseWriter.Write(p)
+	}
+
+	g.buf = append(g.buf, p...)
+	if len(g.buf) >= g.minSize {
+		if err := g.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// start sends the status, compressing what follows when compress is set and the handler
+// has not already encoded the body, then writes out the buffered bytes
+func (g *gzipResponseWriter) start(compress bool) error {
+	g.decided = true
+	header := g.Header()
+	if compress && header.Get("Content-Encoding") == "" && bodyAllowed(g.statusCode) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		g.gz = gzip.NewWriter(g.ResponseWriter)
+	}
+	g.ResponseWriter.WriteHeader(g.statusCode)
+
+	buf := g.buf
+	g.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if g.gz != nil {
+		_, err := g.gz.Write(buf)
+		return err
+	}
+	_, err := g.ResponseWriter.Write(buf)
+	return err
+}
+
+// Close sends a response too small to compress as it is, or finishes the gzip stream
+func (g *gzipResponseWriter) Close() error {
+	if !g.decided {
+		return g.start(false)
+	}
+	if g.gz != nil {
+		return g.gz.Close()
+	}
+	return nil
+}
+
+// bodyAllowed reports whether a response with the status may carry a body
+func bodyAllowed(code int) bool {
+	return code >= http.StatusOK && code != http.StatusNoContent && code != http.StatusNotModified
+}
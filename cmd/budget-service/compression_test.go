@@ -0,0 +1,100 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	large := strings.Repeat(`{"account":"proj001","amount":12.5},`, 100)
+	body := large
+	status := http.StatusOK
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = io.WriteString(w, body)
+	})
+
+	// The logger sits outside the compressor, as in setupRoutes
+	serve := func(acceptEncoding string) (*httptest.ResponseRecorder, *loggingResponseWriter) {
+		rec := httptest.NewRecorder()
+		lrw := &loggingResponseWriter{ResponseWriter: rec, statusCode: http.StatusOK}
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/transactions", nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		compressionMiddleware(1024)(handler).ServeHTTP(lrw, req)
+		return rec, lrw
+	}
+
+	t.Run("large response is gzipped", func(t *testing.T) {
+		rec, lrw := serve("gzip, deflate")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, http.StatusOK, lrw.statusCode)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "Accept-Encoding", rec.Header().Get("Vary"))
+		assert.Less(t, rec.Body.Len(), len(large))
+
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		decoded, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(decoded))
+	})
+
+	t.Run("small response is not", func(t *testing.T) {
+		body = `{"status":"ok"}`
+		defer func() { body = large }()
+
+		rec, _ := serve("gzip")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, `{"status":"ok"}`, rec.Body.String())
+	})
+
+	t.Run("client that does not accept gzip", func(t *testing.T) {
+		for _, acceptEncoding := range []string{"", "identity", "gzip;q=0"} {
+			rec, _ := serve(acceptEncoding)
+			assert.Empty(t, rec.Header().Get("Content-Encoding"), acceptEncoding)
+			assert.Equal(t, large, rec.Body.String(), acceptEncoding)
+		}
+	})
+
+	t.Run("logger sees the handler's status", func(t *testing.T) {
+		status = http.StatusNotFound
+		body = `{"error":{"code":"NOT_FOUND"}}`
+		defer func() { status, body = http.StatusOK, large }()
+
+		rec, lrw := serve("gzip")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Equal(t, http.StatusNotFound, lrw.statusCode)
+		assert.Equal(t, body, rec.Body.String())
+	})
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := map[string]bool{
+		"":                      false,
+		"gzip":                  true,
+		"GZIP":                  true,
+		"deflate, gzip;q=0.8":   true,
+		"*":                     true,
+		"br, identity":          false,
+		"gzip;q=0":              false,
+		"gzip; q=0.000, br":     false,
+		"identity;q=1, *;q=0.1": true,
+	}
+	for header, want := range tests {
+		assert.Equal(t, want, acceptsGzip(header), header)
+	}
+}
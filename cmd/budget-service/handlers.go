@@ -5,22 +5,35 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/slurm"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/version"
 )
 
+// budgetCheckService checks budgets for the users allowed to submit under each account
+type budgetCheckService interface {
+	budgetAuthorizer
+	CheckBudget(ctx context.Context, req *api.BudgetCheckRequest) (*api.BudgetCheckResponse, error)
+}
+
 // handleBudgetCheck handles budget availability checks for job submissions
-func handleBudgetCheck(service *budget.Service) http.HandlerFunc {
+func handleBudgetCheck(service budgetCheckService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req api.BudgetCheckRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -28,6 +41,11 @@ func handleBudgetCheck(service *budget.Service) http.HandlerFunc {
 			return
 		}
 
+		if err := authorizeBudgetCheck(r, service, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
 		response, err := service.CheckBudget(r.Context(), &req)
 		if err != nil {
 			writeError(w, err)
@@ -38,8 +56,71 @@ func handleBudgetCheck(service *budget.Service) http.HandlerFunc {
 	}
 }
 
+// estimateService prices jobs without touching any account
+type estimateService interface {
+	EstimateJobCost(ctx context.Context, req *api.EstimateRequest) (*api.EstimateResponse, error)
+}
+
+// handleEstimate returns a job's estimated cost without checking a budget or placing a hold
+func handleEstimate(service estimateService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.EstimateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		response, err := service.EstimateJobCost(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// jobValidateService checks job scripts before they are submitted
+type jobValidateService interface {
+	ValidateJob(ctx context.Context, req *api.JobValidateRequest) (*api.JobValidateResponse, error)
+}
+
+// handleValidateJob parses a job script's directives and reports its estimated cost,
+// affordability and any problems a budget check would find, without placing a hold. With
+// auth enabled, a non-admin is also told when they are not a member of the account.
+func handleValidateJob(service jobValidateService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.JobValidateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		user, err := memberCheckUser(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		req.UserID = user
+
+		response, err := service.ValidateJob(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// jobReconcileService reconciles jobs for the users allowed to submit under each account
+type jobReconcileService interface {
+	budgetAuthorizer
+	ReconcileJob(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error)
+}
+
 // handleJobReconcile handles job reconciliation after completion
-func handleJobReconcile(service *budget.Service) http.HandlerFunc {
+func handleJobReconcile(service jobReconcileService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req api.JobReconcileRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -47,6 +128,11 @@ func handleJobReconcile(service *budget.Service) http.HandlerFunc {
 			return
 		}
 
+		if err := authorizeReconcile(r, service, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
 		response, err := service.ReconcileJob(r.Context(), &req)
 		if err != nil {
 			writeError(w, err)
@@ -57,9 +143,87 @@ func handleJobReconcile(service *budget.Service) http.HandlerFunc {
 	}
 }
 
+// reconcilePreviewService previews reconciliations for the users allowed to submit under
+// each account
+type reconcilePreviewService interface {
+	budgetAuthorizer
+	PreviewReconciliation(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error)
+}
+
+// handleReconcilePreview reports what reconciling a job would charge and refund without
+// committing the reconciliation
+func handleReconcilePreview(service reconcilePreviewService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.JobReconcileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		if err := authorizeReconcile(r, service, &req); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		response, err := service.PreviewReconciliation(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+type jobStartedService interface {
+	AuthorizeJobStart(ctx context.Context, user string, req *api.JobStartedRequest) error
+	StartJob(ctx context.Context, req *api.JobStartedRequest) (*api.JobStartedResponse, error)
+}
+
+// handleJobStarted escalates a queued job's hold to the full amount when its prolog reports
+// that the job started
+func handleJobStarted(service jobStartedService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.JobStartedRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+				return
+			}
+		}
+		req.JobID = mux.Vars(r)["job_id"]
+
+		// Only a member of the hold's account may escalate it
+		user, err := memberCheckUser(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		if user != "" {
+			if err := service.AuthorizeJobStart(r.Context(), user, &req); err != nil {
+				writeError(w, err)
+				return
+			}
+		}
+
+		response, err := service.StartJob(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
 // handleCreateAccount creates a new budget account
 func handleCreateAccount(service *budget.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
 		var req api.CreateAccountRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
@@ -76,6 +240,137 @@ func handleCreateAccount(service *budget.Service) http.HandlerFunc {
 	}
 }
 
+// accountCloner creates accounts from existing ones
+type accountCloner interface {
+	CloneAccount(ctx context.Context, sourceAccount string, req *api.CloneAccountRequest) (*api.AccountCloneResponse, error)
+}
+
+// handleCloneAccount creates a new account from the one in the path
+func handleCloneAccount(service accountCloner) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.CloneAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		response, err := service.CloneAccount(r.Context(), mux.Vars(r)["account"], &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, response)
+	}
+}
+
+// accountMemberService manages the users who may submit under each account
+type accountMemberService interface {
+	AddAccountMember(ctx context.Context, slurmAccount, user string) (*api.AccountMember, error)
+	RemoveAccountMember(ctx context.Context, slurmAccount, user string) error
+	ListAccountMembers(ctx context.Context, slurmAccount string) ([]*api.AccountMember, error)
+	ListUserAccounts(ctx context.Context, user string) (*api.UserAccountsResponse, error)
+}
+
+// handleListAccountMembers lists the users who may submit under the account in the path
+func handleListAccountMembers(service accountMemberService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		members, err := service.ListAccountMembers(r.Context(), mux.Vars(r)["account"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, members)
+	}
+}
+
+// handleAddAccountMember lets the user in the path submit under the account in the path
+func handleAddAccountMember(service accountMemberService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		member, err := service.AddAccountMember(r.Context(), vars["account"], vars["user"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, member)
+	}
+}
+
+// handleRemoveAccountMember removes the user in the path from the account's members
+func handleRemoveAccountMember(service accountMemberService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		if err := service.RemoveAccountMember(r.Context(), vars["account"], vars["user"]); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleListUserAccounts lists the accounts the user in the path may submit under, with
+// each account's available budget and health
+func handleListUserAccounts(service accountMemberService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		user := mux.Vars(r)["user"]
+		if err := requireSelfOrAdmin(r, user); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		response, err := service.ListUserAccounts(r.Context(), user)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// handleBulkCreateAccounts creates many budget accounts in one request
+func handleBulkCreateAccounts(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var reqs []*api.CreateAccountRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		response, err := service.BulkCreateAccounts(r.Context(), reqs)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
 // handleGetAccount retrieves a budget account by name
 func handleGetAccount(service *budget.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -92,29 +387,42 @@ func handleGetAccount(service *budget.Service) http.HandlerFunc {
 	}
 }
 
-// handleListAccounts lists budget accounts with optional filtering
-func handleListAccounts(service *budget.Service) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		req := &api.ListAccountsRequest{}
+// accountLister lists budget accounts
+type accountLister interface {
+	ListAccounts(ctx context.Context, req *api.ListAccountsRequest) ([]*api.BudgetAccount, error)
+}
 
-		// Parse query parameters
-		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-			if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-				req.Limit = limit
-			}
-		}
+// parseListAccountsRequest reads the account list filters from the query string
+func parseListAccountsRequest(r *http.Request) *api.ListAccountsRequest {
+	req := &api.ListAccountsRequest{}
 
-		if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-			if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-				req.Offset = offset
-			}
+	// Parse query parameters
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			req.Limit = limit
 		}
+	}
 
-		if status := r.URL.Query().Get("status"); status != "" {
-			req.Status = status
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			req.Offset = offset
 		}
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		req.Status = status
+	}
+
+	// Repeated tag parameters narrow the list to accounts matching all of them
+	req.Tags = r.URL.Query()["tag"]
 
-		accounts, err := service.ListAccounts(r.Context(), req)
+	return req
+}
+
+// handleListAccounts lists budget accounts as a bare array (API v1)
+func handleListAccounts(service accountLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accounts, err := service.ListAccounts(r.Context(), parseListAccountsRequest(r))
 		if err != nil {
 			writeError(w, err)
 			return
@@ -124,9 +432,54 @@ func handleListAccounts(service *budget.Service) http.HandlerFunc {
 	}
 }
 
-// handleUpdateAccount updates a budget account
-func handleUpdateAccount(service *budget.Service) http.HandlerFunc {
+// defaultAccountPageLimit is the v2 account page size when the request gives none
+const defaultAccountPageLimit = 50
+
+// handleListAccountsV2 lists budget accounts a page at a time, saying where the next page
+// starts (API v2)
+func handleListAccountsV2(service accountLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := parseListAccountsRequest(r)
+		if req.Limit == 0 || req.Limit > 100 {
+			req.Limit = defaultAccountPageLimit
+		}
+
+		// Ask for one more than a page to learn whether another page follows
+		page := *req
+		page.Limit++
+		accounts, err := service.ListAccounts(r.Context(), &page)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		resp := &api.AccountPage{Accounts: accounts, Limit: req.Limit, Offset: req.Offset}
+		if len(accounts) > req.Limit {
+			resp.Accounts = accounts[:req.Limit]
+			next := req.Offset + req.Limit
+			resp.NextOffset = &next
+		}
+		if resp.Accounts == nil {
+			resp.Accounts = []*api.BudgetAccount{}
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+type accountUpdateService interface {
+	UpdateAccount(ctx context.Context, slurmAccount string, req *api.UpdateAccountRequest) (*api.BudgetAccount, error)
+}
+
+// handleUpdateAccount updates a budget account. Only an admin may, since an update can raise
+// the limit, unfreeze the account or release its reserve.
+func handleUpdateAccount(service accountUpdateService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
 		vars := mux.Vars(r)
 		accountName := vars["account"]
 
@@ -136,272 +489,1457 @@ func handleUpdateAccount(service *budget.Service) http.HandlerFunc {
 			return
 		}
 
-		account, err := service.UpdateAccount(r.Context(), accountName, &req)
+		account, err := service.UpdateAccount(r.Context(), accountName, &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, account)
+	}
+}
+
+// handleDeleteAccount deletes a budget account
+func handleDeleteAccount(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		accountName := vars["account"]
+
+		err := service.DeleteAccount(r.Context(), accountName)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+type budgetAdjustmentService interface {
+	AdjustBudget(ctx context.Context, slurmAccount string, req *api.BudgetAdjustmentRequest) (*api.BudgetAdjustmentResponse, error)
+}
+
+// handleAdjustBudget applies an administrative budget adjustment
+func handleAdjustBudget(service budgetAdjustmentService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		vars := mux.Vars(r)
+		accountName := vars["account"]
+
+		var req api.BudgetAdjustmentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		resp, err := service.AdjustBudget(r.Context(), accountName, &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, resp)
+	}
+}
+
+// handleBurnRateBackfill computes missing burn rate history for an account
+func handleBurnRateBackfill(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		accountName := vars["account"]
+
+		var req api.BurnRateBackfillRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+				return
+			}
+		}
+
+		resp, err := service.BackfillBurnRates(r.Context(), accountName, &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// handleSimulateBudget projects the budget impact of hypothetical jobs
+func handleSimulateBudget(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		accountName := vars["account"]
+
+		var req api.SimulationRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		resp, err := service.SimulateBudget(r.Context(), accountName, &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// transactionLister lists transactions
+type transactionLister interface {
+	ListTransactions(ctx context.Context, req *api.TransactionListRequest) ([]*api.BudgetTransaction, error)
+	ListTransactionPage(ctx context.Context, req *api.TransactionListRequest) (*api.TransactionPage, error)
+}
+
+// parseTransactionListRequest reads the transaction list filters from the query string
+func parseTransactionListRequest(r *http.Request) *api.TransactionListRequest {
+	req := &api.TransactionListRequest{}
+
+	// Parse query parameters
+	if account := r.URL.Query().Get("account"); account != "" {
+		req.Account = account
+	}
+
+	if jobID := r.URL.Query().Get("job_id"); jobID != "" {
+		req.JobID = jobID
+	}
+
+	if txnType := r.URL.Query().Get("type"); txnType != "" {
+		req.Type = txnType
+	}
+
+	if status := r.URL.Query().Get("status"); status != "" {
+		req.Status = status
+	}
+
+	if search := strings.TrimSpace(r.URL.Query().Get("search")); search != "" {
+		req.Search = search
+	}
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			req.Limit = limit
+		}
+	}
+
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			req.Offset = offset
+		}
+	}
+
+	// A cursor is validated with the request, since a client passing one expects it honored
+	req.Cursor = r.URL.Query().Get("cursor")
+
+	// Parse date parameters
+	if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse(time.RFC3339, startDateStr); err == nil {
+			req.StartDate = &startDate
+		}
+	}
+
+	if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse(time.RFC3339, endDateStr); err == nil {
+			req.EndDate = &endDate
+		}
+	}
+
+	return req
+}
+
+// handleListTransactions lists transactions with filtering as a bare array (API v1)
+func handleListTransactions(service transactionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		transactions, err := service.ListTransactions(r.Context(), parseTransactionListRequest(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, transactions)
+	}
+}
+
+// handleListTransactionsV2 lists transactions a page at a time, with the cursor the next
+// page starts at (API v2)
+func handleListTransactionsV2(service transactionLister) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page, err := service.ListTransactionPage(r.Context(), parseTransactionListRequest(r))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// handleUsageByComponent reports charged spend grouped by cost component
+func handleUsageByComponent(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseUsageReportRequest(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		req.GroupBy = "cost_component"
+
+		report, err := service.UsageByComponent(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+// handleUsageByFiscalQuarter reports charged spend grouped by fiscal quarter
+func handleUsageByFiscalQuarter(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := parseUsageReportRequest(r)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		req.GroupBy = "fiscal_quarter"
+
+		report, err := service.UsageByFiscalQuarter(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+// handleProcessAllocations makes every incremental allocation that has come due
+func handleProcessAllocations(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.ProcessAllocationsRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+				return
+			}
+		}
+
+		resp, err := service.ProcessAllocations(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// scheduleStatusService pauses and resumes allocation schedules in bulk
+type scheduleStatusService interface {
+	PauseAllocationSchedules(ctx context.Context, req *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error)
+	ResumeAllocationSchedules(ctx context.Context, req *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error)
+}
+
+// handlePauseAllocations pauses every active allocation schedule, or those of one funding
+// agency or cost center
+func handlePauseAllocations(service scheduleStatusService) http.HandlerFunc {
+	return handleBulkScheduleStatus(service.PauseAllocationSchedules)
+}
+
+// handleResumeAllocations reactivates paused allocation schedules, filtered as for a pause
+func handleResumeAllocations(service scheduleStatusService) http.HandlerFunc {
+	return handleBulkScheduleStatus(service.ResumeAllocationSchedules)
+}
+
+func handleBulkScheduleStatus(apply func(context.Context, *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.BulkScheduleStatusRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+				return
+			}
+		}
+
+		resp, err := apply(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// decisionService lists recorded budget check decisions
+type decisionService interface {
+	ListDecisions(ctx context.Context, req *api.DecisionListRequest) ([]*api.BudgetDecision, error)
+}
+
+// handleListDecisions returns an account's budget check decisions, newest first, filtered
+// by YYYY-MM-DD start_date and end_date and by decision
+func handleListDecisions(service decisionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := &api.DecisionListRequest{
+			Account:  mux.Vars(r)["account"],
+			Decision: query.Get("decision"),
+		}
+
+		for field, target := range map[string]**time.Time{"start_date": &req.StartDate, "end_date": &req.EndDate} {
+			value := query.Get(field)
+			if value == "" {
+				continue
+			}
+			parsed, err := time.Parse("2006-01-02", value)
+			if err != nil {
+				writeError(w, api.NewValidationError(field, "must be in YYYY-MM-DD format"))
+				return
+			}
+			*target = &parsed
+		}
+
+		for field, target := range map[string]*int{"limit": &req.Limit, "offset": &req.Offset} {
+			value := query.Get(field)
+			if value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				writeError(w, api.NewValidationError(field, "must be a number"))
+				return
+			}
+			*target = n
+		}
+
+		decisions, err := service.ListDecisions(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, decisions)
+	}
+}
+
+// allocationPreviewService projects upcoming allocations from an account's schedules
+type allocationPreviewService interface {
+	PreviewAllocations(ctx context.Context, slurmAccount string, count int) (*api.AllocationPreviewResponse, error)
+}
+
+// handleAllocationSchedule previews an account's next allocations, 12 unless count is given
+func handleAllocationSchedule(service allocationPreviewService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var count int
+		if countStr := r.URL.Query().Get("count"); countStr != "" {
+			n, err := strconv.Atoi(countStr)
+			if err != nil {
+				writeError(w, api.NewValidationError("count", "must be a number"))
+				return
+			}
+			count = n
+			if count == 0 {
+				writeError(w, api.NewValidationError("count", "must be between 1 and 100"))
+				return
+			}
+		}
+
+		resp, err := service.PreviewAllocations(r.Context(), mux.Vars(r)["account"], count)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// accountScheduleService adds and reports an account's allocation schedules
+type accountScheduleService interface {
+	CreateAllocationSchedule(ctx context.Context, slurmAccount string, req *api.CreateAllocationScheduleRequest) (*api.BudgetAllocationSchedule, error)
+	ListAccountSchedules(ctx context.Context, slurmAccount string) (*api.AccountSchedulesResponse, error)
+}
+
+// handleCreateAllocationSchedule adds an allocation schedule alongside any the account has
+func handleCreateAllocationSchedule(service accountScheduleService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.CreateAllocationScheduleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		schedule, err := service.CreateAllocationSchedule(r.Context(), mux.Vars(r)["account"], &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, schedule)
+	}
+}
+
+// handleListAccountSchedules reports an account's schedules with the allocations each made
+func handleListAccountSchedules(service accountScheduleService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := service.ListAccountSchedules(r.Context(), mux.Vars(r)["account"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// parseUsageReportRequest reads a usage report's account and YYYY-MM-DD date filters
+func parseUsageReportRequest(r *http.Request) (*api.UsageReportRequest, error) {
+	query := r.URL.Query()
+	req := &api.UsageReportRequest{
+		Account:   query.Get("account"),
+		Partition: query.Get("partition"),
+	}
+
+	for field, target := range map[string]**time.Time{"start_date": &req.StartDate, "end_date": &req.EndDate} {
+		value := query.Get(field)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, api.NewValidationError(field, "must be in YYYY-MM-DD format")
+		}
+		*target = &parsed
+	}
+
+	return req, nil
+}
+
+// handleGetSnapshot returns an account's balances as of the end of a given date
+func handleGetSnapshot(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		accountName := vars["account"]
+
+		date := time.Now()
+		if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+			parsed, err := time.Parse("2006-01-02", dateStr)
+			if err != nil {
+				writeError(w, api.NewValidationError("date", "must be in YYYY-MM-DD format"))
+				return
+			}
+			date = parsed
+		}
+
+		snapshot, err := service.GetSnapshot(r.Context(), accountName, date)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, snapshot)
+	}
+}
+
+// limitHistoryService lists the changes to account budget limits
+type limitHistoryService interface {
+	GetLimitHistory(ctx context.Context, slurmAccount string) ([]*api.BudgetLimitChange, error)
+}
+
+// handleGetLimitHistory returns the changes to an account's budget limit, oldest first
+func handleGetLimitHistory(service limitHistoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		changes, err := service.GetLimitHistory(r.Context(), mux.Vars(r)["account"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, changes)
+	}
+}
+
+// handleGrantReport generates a financial report for a grant
+func handleGrantReport(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+
+		req := &api.GrantReportRequest{
+			GrantNumber: vars["grant"],
+			ReportType:  "financial",
+			Format:      "json",
+		}
+
+		if reportType := r.URL.Query().Get("type"); reportType != "" {
+			req.ReportType = reportType
+		}
+
+		if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
+			if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
+				req.StartDate = &startDate
+			}
+		}
+
+		if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
+			if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
+				req.EndDate = &endDate
+			}
+		}
+
+		if periodStr := r.URL.Query().Get("budget_period"); periodStr != "" {
+			period, err := strconv.Atoi(periodStr)
+			if err != nil {
+				writeError(w, api.NewValidationError("budget_period", "must be a number"))
+				return
+			}
+			req.BudgetPeriod = &period
+		}
+
+		if yearStr := r.URL.Query().Get("fiscal_year"); yearStr != "" {
+			year, err := strconv.Atoi(yearStr)
+			if err != nil {
+				writeError(w, api.NewValidationError("fiscal_year", "must be a year such as 2026"))
+				return
+			}
+			req.FiscalYear = &year
+		}
+
+		report, err := service.GenerateGrantReport(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, report)
+	}
+}
+
+// grantPeriodService reports spent, committed and remaining funds for grant budget periods
+type grantPeriodService interface {
+	GetGrantPeriodSummary(ctx context.Context, grantNumber string, period *int) (*api.GrantPeriodSummary, error)
+}
+
+// handleGrantPeriodSummary reports a budget period's spent, committed and remaining funds,
+// for the current period unless budget_period is given
+func handleGrantPeriodSummary(service grantPeriodService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var period *int
+		if periodStr := r.URL.Query().Get("budget_period"); periodStr != "" {
+			number, err := strconv.Atoi(periodStr)
+			if err != nil {
+				writeError(w, api.NewValidationError("budget_period", "must be a number"))
+				return
+			}
+			period = &number
+		}
+
+		summary, err := service.GetGrantPeriodSummary(r.Context(), mux.Vars(r)["grant"], period)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, summary)
+	}
+}
+
+// grantCostService recomputes grants' direct and indirect costs
+type grantCostService interface {
+	RecomputeGrantCosts(ctx context.Context, grantNumber string) (*api.GrantCostRecomputation, error)
+}
+
+// handleRecomputeGrantCosts recomputes a grant's direct and indirect costs from the charges
+// against its accounts
+func handleRecomputeGrantCosts(service grantCostService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		result, err := service.RecomputeGrantCosts(r.Context(), mux.Vars(r)["grant"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// grantDeadlineService manages grant deadlines and the reports generated for them
+type grantDeadlineService interface {
+	CreateGrantDeadline(ctx context.Context, grantNumber string, req *api.CreateGrantDeadlineRequest) (*api.GrantDeadline, error)
+	ListGrantDeadlines(ctx context.Context, grantNumber string) ([]*api.GrantDeadline, error)
+	GetGrantReport(ctx context.Context, grantNumber string, reportID int64) (*api.StoredGrantReport, error)
+}
+
+// handleListGrantDeadlines lists a grant's deadlines in due date order
+func handleListGrantDeadlines(service grantDeadlineService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadlines, err := service.ListGrantDeadlines(r.Context(), mux.Vars(r)["grant"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, deadlines)
+	}
+}
+
+// handleCreateGrantDeadline adds a deadline to a grant
+func handleCreateGrantDeadline(service grantDeadlineService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.CreateGrantDeadlineRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		deadline, err := service.CreateGrantDeadline(r.Context(), mux.Vars(r)["grant"], &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, deadline)
+	}
+}
+
+// handleGetGrantReport downloads a report generated for one of a grant's deadlines
+func handleGetGrantReport(service grantDeadlineService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		reportID, err := strconv.ParseInt(vars["report"], 10, 64)
+		if err != nil {
+			writeError(w, api.NewValidationError("report", "must be a report ID"))
+			return
+		}
+
+		report, err := service.GetGrantReport(r.Context(), vars["grant"], reportID)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("grant-%s-report-%d.json", report.GrantNumber, report.ID)))
+		writeJSON(w, http.StatusOK, report.Report)
+	}
+}
+
+// handleHealth handles health check requests
+func handleHealth(service *budget.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status := "healthy"
+		services := make(map[string]string)
+
+		// Check database
+		if err := service.HealthCheck(r.Context()); err != nil {
+			status = "unhealthy"
+			services["database"] = "unhealthy: " + err.Error()
+		} else {
+			services["database"] = "healthy"
+		}
+
+		// TODO: Add advisor service health check
+		services["advisor"] = "unknown"
+
+		response := &api.HealthCheckResponse{
+			Status:    status,
+			Version:   version.Version,
+			Timestamp: time.Now(),
+			Services:  services,
+			Uptime:    "unknown", // TODO: Calculate actual uptime
+		}
+
+		if status == "unhealthy" {
+			writeJSON(w, http.StatusServiceUnavailable, response)
+		} else {
+			writeJSON(w, http.StatusOK, response)
+		}
+	}
+}
+
+type readinessService interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// workerChecker reports the liveness of the background workers
+type workerChecker interface {
+	check() []api.WorkerStatus
+}
+
+// handleReady reports whether the service is ready: the database answers and every
+// background worker is still ticking. A stalled worker fails readiness, since the service
+// would otherwise keep serving while allocations and recoveries silently stop.
+func handleReady(service readinessService, workers workerChecker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response := &api.ReadinessResponse{
+			Ready:     true,
+			Timestamp: time.Now(),
+			Checks:    make(map[string]string),
+			Workers:   workers.check(),
+		}
+
+		if err := service.HealthCheck(r.Context()); err != nil {
+			response.Ready = false
+			response.Checks["database"] = "unavailable: " + err.Error()
+		} else {
+			response.Checks["database"] = "ok"
+		}
+
+		var stalled []string
+		for _, worker := range response.Workers {
+			if !worker.Alive {
+				stalled = append(stalled, worker.Name)
+			}
+		}
+		if len(stalled) > 0 {
+			response.Ready = false
+			response.Checks["workers"] = "stalled: " + strings.Join(stalled, ", ")
+		} else {
+			response.Checks["workers"] = "ok"
+		}
+
+		if !response.Ready {
+			writeJSON(w, http.StatusServiceUnavailable, response)
+			return
+		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// metricsWriter writes the service's metrics in the Prometheus text exposition format
+type metricsWriter interface {
+	WriteMetrics(w io.Writer) error
+}
+
+// handleMetrics handles Prometheus metrics requests
+func handleMetrics(service metricsWriter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Render first so a failure can still be reported with an error status
+		var body bytes.Buffer
+		if err := service.WriteMetrics(&body); err != nil {
+			log.Error().Err(err).Msg("Failed to collect metrics")
+			http.Error(w, "failed to collect metrics", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(body.Bytes()); err != nil {
+			log.Error().Err(err).Msg("Failed to write metrics response")
+		}
+	}
+}
+
+// handleVersion handles version information requests
+func handleVersion() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		buildInfo := version.GetBuildInfo()
+		writeJSON(w, http.StatusOK, buildInfo)
+	}
+}
+
+// Helper functions
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	// A versioned handler may already have named its media type
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Error().Err(err).Msg("Failed to encode JSON response")
+	}
+}
+
+// writeError writes an error response
+func writeError(w http.ResponseWriter, err error) {
+	budgetErr, ok := api.AsBudgetError(err)
+	if !ok {
+		budgetErr = api.NewBudgetError(api.ErrCodeInternal, "Internal server error")
+		budgetErr.Cause = err
+	}
+
+	response := &api.ErrorResponse{
+		RequestID: generateRequestID(),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+
+	response.Error.Code = budgetErr.Code
+	response.Error.Message = budgetErr.Message
+	response.Error.Details = budgetErr.Details
+	response.Error.Field = budgetErr.Field
+	response.Error.ValidationErrors = budgetErr.ValidationErrors
+	response.Error.CheckFailures = budgetErr.CheckFailures
+	if budgetErr.Code == api.ErrCodeValidation && len(budgetErr.ValidationErrors) == 0 && budgetErr.Field != "" {
+		// Single-field validation errors are listed too, so clients can always read the list
+		response.Error.ValidationErrors = []api.FieldError{{Field: budgetErr.Field, Message: budgetErr.Message}}
+	}
+
+	// Log the error
+	log.Error().
+		Err(budgetErr.Cause).
+		Str("code", string(budgetErr.Code)).
+		Str("message", budgetErr.Message).
+		Str("request_id", response.RequestID).
+		Msg("API error")
+
+	writeJSON(w, budgetErr.HTTPStatus(), response)
+}
+
+// ASBX Integration handlers
+
+// handleASBXReconciliation handles cost reconciliation from ASBX. The costs are charged as
+// sent, so the post must be signed like an epilog post.
+func handleASBXReconciliation(integration asbxIntegration, cfg *config.IntegrationConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if integration == nil {
+			writeError(w, errASBXDisabled())
+			return
+		}
+
+		body, err := readSignedEpilog(w, r, cfg)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.ASBXCostReconciliationRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		response, err := integration.ProcessCostReconciliation(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// maxEpilogBodyBytes bounds how much of an epilog post is read before it is verified
+const maxEpilogBodyBytes = 1 << 20
+
+// epilogProcessor processes epilog posts once their signature has been verified
+type epilogProcessor interface {
+	ProcessEpilogData(ctx context.Context, req *api.ASBXEpilogRequest) (*api.ASBXEpilogResponse, error)
+}
+
+// asbxIntegration is the ASBX integration service behind the ASBX handlers, nil when
+// integration.asbx_enabled is off
+type asbxIntegration interface {
+	epilogProcessor
+	ProcessCostReconciliation(ctx context.Context, req *api.ASBXCostReconciliationRequest) (*api.ASBXCostReconciliationResponse, error)
+	GetIntegrationStatus(ctx context.Context) (*api.ASBXIntegrationStatus, error)
+}
+
+// errASBXDisabled is returned by the ASBX handlers when ASBX integration is turned off
+func errASBXDisabled() error {
+	return api.NewBudgetError(api.ErrCodeServiceUnavailable,
+		"ASBX integration is disabled; set integration.asbx_enabled to enable it")
+}
+
+// readSignedEpilog reads an epilog post's body and verifies its signature against the
+// shared epilog secret
+func readSignedEpilog(w http.ResponseWriter, r *http.Request, cfg *config.IntegrationConfig) ([]byte, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEpilogBodyBytes))
+	if err != nil {
+		return nil, api.NewValidationError("body", "Failed to read request body")
+	}
+
+	if err := asbx.VerifyEpilogSignature(cfg.EpilogSecret,
+		r.Header.Get(asbx.EpilogSignatureHeader), r.Header.Get(asbx.EpilogTimestampHeader),
+		body, time.Now(), cfg.EpilogMaxSkew); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// handleASBXEpilog handles signed epilog data from SLURM
+func handleASBXEpilog(processor epilogProcessor, cfg *config.IntegrationConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if processor == nil {
+			writeError(w, errASBXDisabled())
+			return
+		}
+
+		body, err := readSignedEpilog(w, r, cfg)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.ASBXEpilogRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+		if req.JobID == "" || req.Account == "" {
+			writeError(w, api.NewValidationError("job_id", "job_id and account are required"))
+			return
+		}
+
+		response, err := processor.ProcessEpilogData(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// handleASBXStatus handles ASBX integration status requests
+func handleASBXStatus(integration asbxIntegration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if integration == nil {
+			writeError(w, errASBXDisabled())
+			return
+		}
+
+		status, err := integration.GetIntegrationStatus(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, status)
+	}
+}
+
+// pendingReconciliationService lists holds that are still awaiting reconciliation
+type pendingReconciliationService interface {
+	ListPendingReconciliations(ctx context.Context, slurmAccount string) (*api.PendingReconciliationResponse, error)
+}
+
+// handleListPendingReconciliations lists pending holds so integrations can re-send missing
+// reconciliation data
+func handleListPendingReconciliations(service pendingReconciliationService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := service.ListPendingReconciliations(r.Context(), r.URL.Query().Get("account"))
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// reconciliationLookupService finds ASBX reconciliations by their ID
+type reconciliationLookupService interface {
+	GetASBXReconciliation(ctx context.Context, reconciliationID string) (*api.ASBXReconciliation, error)
+}
+
+// handleGetReconciliation returns an ASBX reconciliation's variance summary and the hold,
+// charges and refunds it involved
+func handleGetReconciliation(service reconciliationLookupService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		reconciliation, err := service.GetASBXReconciliation(r.Context(), mux.Vars(r)["id"])
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, reconciliation)
+	}
+}
+
+// accountingReconcileService reconciles holds from SLURM accounting records
+type accountingReconcileService interface {
+	ReconcileAccountingJobs(ctx context.Context, req *api.AccountingReconcileRequest) (*api.AccountingReconcileResponse, error)
+}
+
+// handleReconcileAccountingJobs reconciles holds from sacct records, for sites without ASBX.
+// The records are taken as given, so only an admin may submit them.
+func handleReconcileAccountingJobs(service accountingReconcileService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := requireAdmin(r); err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.AccountingReconcileRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		response, err := service.ReconcileAccountingJobs(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// handleEpilogEnv reconciles a job from its signed SLURM epilog environment, pricing it with
+// the cost model, for sites without ASBX
+func handleEpilogEnv(service accountingReconcileService, cfg *config.IntegrationConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := readSignedEpilog(w, r, cfg)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		var req api.EpilogEnvRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		job, err := slurm.ParseEpilogEnv(req.Env, time.Now())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		response, err := service.ReconcileAccountingJobs(r.Context(), &api.AccountingReconcileRequest{
+			Jobs: []*api.AccountingJob{job},
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response.Jobs[0])
+	}
+}
+
+// orphanedHoldService lists and recovers holds that were never reconciled
+type orphanedHoldService interface {
+	ListOrphanedHolds(ctx context.Context) (*api.OrphanedHoldsResponse, error)
+	RecoverOrphanedHolds(ctx context.Context) (*api.OrphanRecoveryResponse, error)
+}
+
+// handleListOrphanedHolds lists holds awaiting reconciliation older than the reconciliation timeout
+func handleListOrphanedHolds(service orphanedHoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := service.ListOrphanedHolds(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// handleRecoverOrphanedHolds cancels and refunds orphaned holds on demand
+func handleRecoverOrphanedHolds(service orphanedHoldService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := service.RecoverOrphanedHolds(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+// deadLetterService lists dead-lettered reconciliations and replays or resolves them
+type deadLetterService interface {
+	ListDeadLetters(ctx context.Context) ([]*api.ReconciliationDeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, id int64, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error)
+	ResolveDeadLetter(ctx context.Context, id int64, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error)
+}
+
+// handleListDeadLetters lists reconciliations dead-lettered after failing repeatedly
+func handleListDeadLetters(service deadLetterService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		letters, err := service.ListDeadLetters(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, letters)
+	}
+}
+
+// handleReplayDeadLetter retries a dead-lettered reconciliation
+func handleReplayDeadLetter(service deadLetterService) http.HandlerFunc {
+	return handleDeadLetterAction(service.ReplayDeadLetter)
+}
+
+// handleResolveDeadLetter closes a dead-lettered reconciliation without retrying it
+func handleResolveDeadLetter(service deadLetterService) http.HandlerFunc {
+	return handleDeadLetterAction(service.ResolveDeadLetter)
+}
+
+func handleDeadLetterAction(apply func(context.Context, int64, *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeError(w, api.NewValidationError("id", "must be a dead letter ID"))
+			return
+		}
+
+		var req api.ResolveDeadLetterRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		letter, err := apply(r.Context(), id, &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, letter)
+	}
+}
+
+// notificationService lists queued notifications and resends failed ones
+type notificationService interface {
+	ListNotifications(ctx context.Context, status string) ([]*api.NotificationRecord, error)
+	RetryNotification(ctx context.Context, id int64) (*api.NotificationRecord, error)
+}
+
+// handleListNotifications lists queued notifications and how their delivery went,
+// optionally only those with the status given as status
+func handleListNotifications(service notificationService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		notifications, err := service.ListNotifications(r.Context(), r.URL.Query().Get("status"))
 		if err != nil {
 			writeError(w, err)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, account)
+		writeJSON(w, http.StatusOK, notifications)
 	}
 }
 
-// handleDeleteAccount deletes a budget account
-func handleDeleteAccount(service *budget.Service) http.HandlerFunc {
+// handleRetryNotification resends a failed notification
+func handleRetryNotification(service notificationService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		vars := mux.Vars(r)
-		accountName := vars["account"]
+		id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			writeError(w, api.NewValidationError("id", "must be a notification ID"))
+			return
+		}
 
-		err := service.DeleteAccount(r.Context(), accountName)
+		notification, err := service.RetryNotification(r.Context(), id)
 		if err != nil {
 			writeError(w, err)
 			return
 		}
 
-		w.WriteHeader(http.StatusNoContent)
+		writeJSON(w, http.StatusOK, notification)
 	}
 }
 
-// handleListTransactions lists transactions with filtering
-func handleListTransactions(service *budget.Service) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		req := &api.TransactionListRequest{}
-
-		// Parse query parameters
-		if account := r.URL.Query().Get("account"); account != "" {
-			req.Account = account
-		}
-
-		if jobID := r.URL.Query().Get("job_id"); jobID != "" {
-			req.JobID = jobID
-		}
+// overviewService aggregates budgets across the organization
+type overviewService interface {
+	Overview(ctx context.Context, req *api.OverviewRequest) (*api.Overview, error)
+}
 
-		if txnType := r.URL.Query().Get("type"); txnType != "" {
-			req.Type = txnType
+// handleOverview reports org-wide budget totals, optionally for one funding agency or
+// cost center
+func handleOverview(service overviewService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &api.OverviewRequest{
+			FundingAgency: r.URL.Query().Get("agency"),
+			CostCenter:    r.URL.Query().Get("cost_center"),
+			Tags:          r.URL.Query()["tag"],
 		}
 
-		if status := r.URL.Query().Get("status"); status != "" {
-			req.Status = status
+		response, err := service.Overview(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
 		}
 
-		if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-			if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-				req.Limit = limit
-			}
-		}
+		writeJSON(w, http.StatusOK, response)
+	}
+}
 
-		if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-			if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
-				req.Offset = offset
-			}
-		}
+// atRiskService finds accounts projected to run out of budget
+type atRiskService interface {
+	ListAtRiskAccounts(ctx context.Context, withinDays int) (*api.AtRiskAccountsResponse, error)
+}
 
-		// Parse date parameters
-		if startDateStr := r.URL.Query().Get("start_date"); startDateStr != "" {
-			if startDate, err := time.Parse(time.RFC3339, startDateStr); err == nil {
-				req.StartDate = &startDate
+// handleListAtRiskAccounts lists the accounts projected to deplete within within_days days,
+// 14 unless given
+func handleListAtRiskAccounts(service atRiskService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var withinDays int
+		if value := r.URL.Query().Get("within_days"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				writeError(w, api.NewValidationError("within_days", "must be a number"))
+				return
 			}
-		}
-
-		if endDateStr := r.URL.Query().Get("end_date"); endDateStr != "" {
-			if endDate, err := time.Parse(time.RFC3339, endDateStr); err == nil {
-				req.EndDate = &endDate
+			if n == 0 {
+				writeError(w, api.NewValidationError("within_days", "must be between 1 and 365"))
+				return
 			}
+			withinDays = n
 		}
 
-		transactions, err := service.ListTransactions(r.Context(), req)
+		resp, err := service.ListAtRiskAccounts(r.Context(), withinDays)
 		if err != nil {
 			writeError(w, err)
 			return
 		}
 
-		writeJSON(w, http.StatusOK, transactions)
+		writeJSON(w, http.StatusOK, resp)
 	}
 }
 
-// handleHealth handles health check requests
-func handleHealth(service *budget.Service) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		status := "healthy"
-		services := make(map[string]string)
-
-		// Check database
-		if err := service.HealthCheck(r.Context()); err != nil {
-			status = "unhealthy"
-			services["database"] = "unhealthy: " + err.Error()
-		} else {
-			services["database"] = "healthy"
-		}
-
-		// TODO: Add advisor service health check
-		services["advisor"] = "unknown"
+// adminSummaryService summarizes the service for operators
+type adminSummaryService interface {
+	AdminSummary(ctx context.Context) (*api.AdminSummary, error)
+}
 
-		response := &api.HealthCheckResponse{
-			Status:    status,
-			Version:   version.Version,
-			Timestamp: time.Now(),
-			Services:  services,
-			Uptime:    "unknown", // TODO: Calculate actual uptime
+// handleAdminSummary reports account and hold totals and database connection stats
+func handleAdminSummary(service adminSummaryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		response, err := service.AdminSummary(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
 		}
 
-		if status == "unhealthy" {
-			writeJSON(w, http.StatusServiceUnavailable, response)
-		} else {
-			writeJSON(w, http.StatusOK, response)
-		}
+		writeJSON(w, http.StatusOK, response)
 	}
 }
 
-// handleMetrics handles Prometheus metrics requests
-func handleMetrics() http.HandlerFunc {
+// migrationStatusService reports the database schema version
+type migrationStatusService interface {
+	MigrationStatus(ctx context.Context) (*api.MigrationStatus, error)
+}
+
+// handleMigrationStatus reports the schema version and which migrations are applied or pending
+func handleMigrationStatus(service migrationStatusService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement Prometheus metrics collection
-		w.Header().Set("Content-Type", "text/plain")
-		w.WriteHeader(http.StatusOK)
-		if _, err := w.Write([]byte("# TODO: Implement metrics collection\n")); err != nil {
-			log.Error().Err(err).Msg("Failed to write metrics response")
+		response, err := service.MigrationStatus(r.Context())
+		if err != nil {
+			writeError(w, err)
+			return
 		}
+
+		writeJSON(w, http.StatusOK, response)
 	}
 }
 
-// handleVersion handles version information requests
-func handleVersion() http.HandlerFunc {
+// handleEffectiveConfig reports the configuration the service is running with, secrets
+// redacted, so a misconfiguration can be diagnosed without access to the host
+func handleEffectiveConfig(cfg *config.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		buildInfo := version.GetBuildInfo()
-		writeJSON(w, http.StatusOK, buildInfo)
+		writeJSON(w, http.StatusOK, &api.EffectiveConfig{Settings: cfg.Redacted().Settings()})
 	}
 }
 
-// Helper functions
-
-// writeJSON writes a JSON response
-func writeJSON(w http.ResponseWriter, statusCode int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		log.Error().Err(err).Msg("Failed to encode JSON response")
-	}
+// consistencyService compares cached account balances with the transaction ledger
+type consistencyService interface {
+	CheckConsistency(ctx context.Context, req *api.ConsistencyCheckRequest) (*api.ConsistencyCheckResponse, error)
 }
 
-// writeError writes an error response
-func writeError(w http.ResponseWriter, err error) {
-	budgetErr, ok := api.AsBudgetError(err)
-	if !ok {
-		budgetErr = api.NewBudgetError(api.ErrCodeInternal, "Internal server error")
-		budgetErr.Cause = err
-	}
+// handleCheckConsistency reports balance drift for one account, or every account when
+// no account is given
+func handleCheckConsistency(service consistencyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &api.ConsistencyCheckRequest{Account: r.URL.Query().Get("account")}
 
-	response := &api.ErrorResponse{
-		RequestID: generateRequestID(),
-		Timestamp: time.Now().Format(time.RFC3339),
+		response, err := service.CheckConsistency(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, response)
 	}
+}
 
-	response.Error.Code = budgetErr.Code
-	response.Error.Message = budgetErr.Message
-	response.Error.Details = budgetErr.Details
-	response.Error.Field = budgetErr.Field
+// handleRepairConsistency corrects drifted cached balances from the transaction ledger
+func handleRepairConsistency(service consistencyService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.ConsistencyCheckRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+		req.Repair = true
 
-	// Log the error
-	log.Error().
-		Err(budgetErr.Cause).
-		Str("code", string(budgetErr.Code)).
-		Str("message", budgetErr.Message).
-		Str("request_id", response.RequestID).
-		Msg("API error")
+		response, err := service.CheckConsistency(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
-	writeJSON(w, budgetErr.HTTPStatus(), response)
+		writeJSON(w, http.StatusOK, response)
+	}
 }
 
-// ASBX Integration handlers
+// transferService moves one account into another
+type transferService interface {
+	TransferAccount(ctx context.Context, sourceAccount, destAccount string, req *api.AccountTransferRequest) (*api.AccountTransferResponse, error)
+}
 
-// handleASBXReconciliation handles cost reconciliation from ASBX
-func handleASBXReconciliation(service *budget.Service) http.HandlerFunc {
+// handleTransferAccount merges the source account into the destination and archives it.
+// The request body is optional; by default the source's schedules are merged.
+func handleTransferAccount(service transferService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req api.ASBXCostReconciliationRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var req api.AccountTransferRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
 			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
 			return
 		}
 
-		// TODO: Implement ASBX integration service
-		// For now, return a placeholder response
-		response := &api.ASBXCostReconciliationResponse{
-			Success:          false,
-			Message:          "ASBX integration not yet implemented",
-			ReconciliationID: fmt.Sprintf("placeholder_%d", time.Now().Unix()),
+		vars := mux.Vars(r)
+		response, err := service.TransferAccount(r.Context(), vars["source"], vars["dest"], &req)
+		if err != nil {
+			writeError(w, err)
+			return
 		}
 
-		writeJSON(w, http.StatusNotImplemented, response)
+		writeJSON(w, http.StatusOK, response)
 	}
 }
 
-// handleASBXEpilog handles epilog data from SLURM
-func handleASBXEpilog(service *budget.Service) http.HandlerFunc {
+// holdAdjustService changes a live hold's amount
+type holdAdjustService interface {
+	AdjustHold(ctx context.Context, transactionID string, req *api.HoldAdjustRequest) (*api.HoldAdjustResponse, error)
+}
+
+// handleAdjustHold corrects a live hold's amount in place, with a reason for the audit record
+func handleAdjustHold(service holdAdjustService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req api.ASBXEpilogRequest
+		var req api.HoldAdjustRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
 			return
 		}
 
-		// TODO: Implement ASBX epilog processing
-		response := &api.ASBXEpilogResponse{
-			Success:                 true,
-			JobID:                   req.JobID,
-			DataImportStatus:        "not_implemented",
-			ReconciliationTriggered: false,
-			Message:                 "ASBX epilog processing not yet implemented",
-			NextSteps: []string{
-				"ASBX integration service implementation pending",
-				"Manual reconciliation may be required",
-			},
+		response, err := service.AdjustHold(r.Context(), mux.Vars(r)["transaction_id"], &req)
+		if err != nil {
+			writeError(w, err)
+			return
 		}
 
-		writeJSON(w, http.StatusNotImplemented, response)
+		writeJSON(w, http.StatusOK, response)
 	}
 }
 
-// handleASBXStatus handles ASBX integration status requests
-func handleASBXStatus(service *budget.Service) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement actual ASBX status checking
-		status := &api.ASBXIntegrationStatus{
-			ASBXVersion:               "0.2.0",
-			IntegrationEnabled:        false, // Not yet implemented
-			LastDataImport:            time.Now().Add(-24 * time.Hour),
-			TotalJobsReconciled:       0,
-			SuccessfulReconciliations: 0,
-			FailedReconciliations:     0,
-			AverageReconciliationTime: "0s",
-			CostModelAccuracy:         0.0,
-			LastHealthCheck:           time.Now(),
-			HealthStatus:              "integration_pending",
+// accountExportService backs up and restores whole accounts
+type accountExportService interface {
+	ExportAccount(ctx context.Context, slurmAccount string) (*api.AccountExport, error)
+	ImportAccount(ctx context.Context, export *api.AccountExport) (*api.AccountImportResponse, error)
+}
+
+// handleExportAccount returns a complete copy of an account that the import endpoint restores
+func handleExportAccount(service accountExportService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		export, err := service.ExportAccount(r.Context(), mux.Vars(r)["account"])
+		if err != nil {
+			writeError(w, err)
+			return
 		}
 
-		writeJSON(w, http.StatusOK, status)
+		writeJSON(w, http.StatusOK, export)
+	}
+}
+
+// handleImportAccount restores an account from an export taken by handleExportAccount
+func handleImportAccount(service accountExportService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var export api.AccountExport
+		if err := json.NewDecoder(r.Body).Decode(&export); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		response, err := service.ImportAccount(r.Context(), &export)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, response)
 	}
 }
 
 // ASBA Integration handlers (Issues #2 and #3)
 
+// accountBalanceService reads the balances the ASBA decision endpoints advise from
+type accountBalanceService interface {
+	GetAccountBalance(ctx context.Context, slurmAccount string) (*api.BudgetAccount, error)
+}
+
 // handleASBABudgetStatus handles budget status queries for ASBA decision making
-func handleASBABudgetStatus(service *budget.Service) http.HandlerFunc {
+func handleASBABudgetStatus(service accountBalanceService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req api.BudgetStatusQuery
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
 			return
 		}
+		if req.Account == "" {
+			writeError(w, api.NewValidationError("account", "account is required"))
+			return
+		}
+
+		account, err := service.GetAccountBalance(r.Context(), req.Account)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		utilization := 0.0
+		if account.BudgetLimit > 0 {
+			utilization = (account.BudgetUsed / account.BudgetLimit) * 100
+		}
 
-		// TODO: Implement comprehensive budget status analysis
+		// TODO: Implement burn rate, health and risk analysis
 		response := &api.BudgetStatusResponse{
 			Account:             req.Account,
-			BudgetLimit:         5000.00,
-			BudgetUsed:          1250.75,
-			BudgetHeld:          320.50,
-			BudgetAvailable:     3428.75,
-			BudgetUtilization:   25.015,
+			BudgetLimit:         account.BudgetLimit,
+			BudgetUsed:          account.BudgetUsed,
+			BudgetHeld:          account.BudgetHeld,
+			BudgetAvailable:     account.BudgetAvailable(),
+			BudgetUtilization:   utilization,
 			DailyBurnRate:       125.50,
 			ExpectedDailyRate:   100.00,
 			BurnRateVariance:    25.5,
@@ -409,7 +1947,7 @@ func handleASBABudgetStatus(service *budget.Service) http.HandlerFunc {
 			HealthStatus:        "CONCERN",
 			DaysRemaining:       90,
 			RiskLevel:           "MEDIUM",
-			CanAffordAWSBurst:   true,
+			CanAffordAWSBurst:   account.SpendableAvailable() > 0,
 			RecommendedDecision: "PREFER_LOCAL",
 			DecisionReasoning: []string{
 				"Budget health is concerning with 25.5% overspend rate",
@@ -423,22 +1961,40 @@ func handleASBABudgetStatus(service *budget.Service) http.HandlerFunc {
 	}
 }
 
-// handleASBAAffordabilityCheck handles affordability checks for job submissions
-func handleASBAAffordabilityCheck(service *budget.Service) http.HandlerFunc {
+// handleASBAAffordabilityCheck handles affordability checks for job submissions. The
+// answer is advisory; the budget check at submission makes the authoritative decision.
+func handleASBAAffordabilityCheck(service accountBalanceService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req api.AffordabilityCheckRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
 			return
 		}
+		if req.Account == "" {
+			writeError(w, api.NewValidationError("account", "account is required"))
+			return
+		}
+
+		account, err := service.GetAccountBalance(r.Context(), req.Account)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		cost := account.FromDollars(req.EstimatedAWSCost)
+		available := account.SpendableAvailable()
+		impact := 100.0
+		if available > 0 {
+			impact = (cost / available) * 100
+		}
 
 		// TODO: Implement sophisticated affordability analysis
 		response := &api.AffordabilityCheckResponse{
-			Affordable:          req.EstimatedAWSCost <= 500.00, // Simple threshold
+			Affordable:          cost <= available,
 			RecommendedDecision: "AWS",
 			ConfidenceLevel:     0.85,
 			EstimatedAWSCost:    req.EstimatedAWSCost,
-			BudgetImpact:        (req.EstimatedAWSCost / 5000.00) * 100, // Percentage
+			BudgetImpact:        impact,
 			BudgetRisk:          "LOW",
 			DeadlineRisk:        "MEDIUM",
 			OverallRisk:         "LOW",
@@ -453,13 +2009,29 @@ func handleASBAAffordabilityCheck(service *budget.Service) http.HandlerFunc {
 			},
 			Message: "Job is affordable and recommended for AWS execution",
 		}
+		if !response.Affordable {
+			response.RecommendedDecision = "LOCAL"
+			response.BudgetRisk = "HIGH"
+			response.OverallRisk = "HIGH"
+			response.Reasoning = []string{
+				fmt.Sprintf("Job cost $%.2f exceeds the available budget", req.EstimatedAWSCost),
+			}
+			response.Message = "Job is not affordable on AWS; run it locally"
+		}
 
 		writeJSON(w, http.StatusOK, response)
 	}
 }
 
-// handleASBAGrantTimeline handles grant timeline queries
-func handleASBAGrantTimeline(service *budget.Service) http.HandlerFunc {
+// grantTimelineService reports grant periods and the emergency options for an account
+type grantTimelineService interface {
+	grantPeriodService
+	GetEmergencyOptions(ctx context.Context, slurmAccount string) ([]api.EmergencyOption, error)
+}
+
+// handleASBAGrantTimeline handles grant timeline queries. An account in critical budget
+// health near a deadline gets emergency options, and its urgency is CRITICAL.
+func handleASBAGrantTimeline(service grantTimelineService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req api.GrantTimelineQuery
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -467,6 +2039,16 @@ func handleASBAGrantTimeline(service *budget.Service) http.HandlerFunc {
 			return
 		}
 
+		var period *api.GrantPeriodSummary
+		if req.GrantNumber != "" {
+			summary, err := service.GetGrantPeriodSummary(r.Context(), req.GrantNumber, nil)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			period = summary
+		}
+
 		// TODO: Implement grant timeline analysis
 		now := time.Now()
 		response := &api.GrantTimelineResponse{
@@ -509,18 +2091,75 @@ func handleASBAGrantTimeline(service *budget.Service) http.HandlerFunc {
 			LastUpdated: now,
 		}
 
+		if period != nil {
+			response.GrantNumber = period.GrantNumber
+			response.CurrentPeriod = period.PeriodNumber
+			response.PeriodEndDate = period.PeriodEndDate
+			response.DaysUntilPeriodEnd = int(time.Until(period.PeriodEndDate).Hours() / 24)
+			response.Period = period
+		}
+
+		if req.Account != "" {
+			options, err := service.GetEmergencyOptions(r.Context(), req.Account)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			if len(options) > 0 {
+				response.EmergencyOptions = options
+				response.CurrentUrgency = "CRITICAL"
+			}
+		}
+
 		writeJSON(w, http.StatusOK, response)
 	}
 }
 
+// burstDecisionService weights burst decisions by the outcomes ASBA has reported
+type burstDecisionService interface {
+	WeightBurstDecisionFactors(ctx context.Context, slurmAccount string, factors []api.DecisionFactor) ([]api.DecisionFactor, *api.ASBAFeedbackSummary, error)
+}
+
 // handleASBABurstDecision handles comprehensive burst decision making
-func handleASBABurstDecision(service *budget.Service) http.HandlerFunc {
+func handleASBABurstDecision(service burstDecisionService) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req api.BurstDecisionRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
 			return
 		}
+		if req.Account == "" {
+			writeError(w, api.NewValidationError("account", "is required"))
+			return
+		}
+
+		factors, feedback, err := service.WeightBurstDecisionFactors(r.Context(), req.Account, []api.DecisionFactor{
+			{
+				Factor:      api.DecisionFactorBudgetHealth,
+				Weight:      0.3,
+				Value:       0.85,
+				Impact:      "POSITIVE",
+				Description: "Account has healthy budget status",
+			},
+			{
+				Factor:      api.DecisionFactorDeadlinePressure,
+				Weight:      0.4,
+				Value:       0.6,
+				Impact:      "NEUTRAL",
+				Description: "Moderate deadline pressure",
+			},
+			{
+				Factor:      api.DecisionFactorCostEfficiency,
+				Weight:      0.3,
+				Value:       0.75,
+				Impact:      "POSITIVE",
+				Description: "AWS cost is reasonable for time savings",
+			},
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
 
 		// TODO: Implement sophisticated burst decision logic
 		urgency := "MEDIUM"
@@ -537,29 +2176,8 @@ func handleASBABurstDecision(service *budget.Service) http.HandlerFunc {
 			TimelinePressure:   0.45,
 			DeadlineRisk:       "MEDIUM",
 			GrantHealthImpact:  "MINIMAL",
-			DecisionFactors: []api.DecisionFactor{
-				{
-					Factor:      "Budget Health",
-					Weight:      0.3,
-					Value:       0.85,
-					Impact:      "POSITIVE",
-					Description: "Account has healthy budget status",
-				},
-				{
-					Factor:      "Deadline Pressure",
-					Weight:      0.4,
-					Value:       0.6,
-					Impact:      "NEUTRAL",
-					Description: "Moderate deadline pressure",
-				},
-				{
-					Factor:      "Cost Efficiency",
-					Weight:      0.3,
-					Value:       0.75,
-					Impact:      "POSITIVE",
-					Description: "AWS cost is reasonable for time savings",
-				},
-			},
+			DecisionFactors:    factors,
+			Feedback:           feedback,
 			ImmediateActions: []string{
 				"Submit job to AWS for faster completion",
 				"Monitor budget impact after job completion",
@@ -575,6 +2193,60 @@ func handleASBABurstDecision(service *budget.Service) http.HandlerFunc {
 	}
 }
 
+// asbaFeedbackService records and lists the job outcomes ASBA reports
+type asbaFeedbackService interface {
+	RecordASBAFeedback(ctx context.Context, req *api.ASBAFeedbackRequest) (*api.ASBAFeedback, error)
+	ListASBAFeedback(ctx context.Context, req *api.ASBAFeedbackListRequest) ([]*api.ASBAFeedback, error)
+}
+
+// handleASBAFeedback records what actually happened to a job after an ASBA recommendation
+func handleASBAFeedback(service asbaFeedbackService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req api.ASBAFeedbackRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, api.NewValidationError("body", "Invalid JSON format"))
+			return
+		}
+
+		feedback, err := service.RecordASBAFeedback(r.Context(), &req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusCreated, feedback)
+	}
+}
+
+// handleListASBAFeedback returns an account's ASBA feedback, newest first
+func handleListASBAFeedback(service asbaFeedbackService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		req := &api.ASBAFeedbackListRequest{Account: query.Get("account")}
+
+		for field, target := range map[string]*int{"limit": &req.Limit, "offset": &req.Offset} {
+			value := query.Get(field)
+			if value == "" {
+				continue
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				writeError(w, api.NewValidationError(field, "must be a number"))
+				return
+			}
+			*target = n
+		}
+
+		feedback, err := service.ListASBAFeedback(r.Context(), req)
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, feedback)
+	}
+}
+
 // generateRequestID generates a simple request ID
 func generateRequestID() string {
 	return strconv.FormatInt(time.Now().UnixNano(), 36)
@@ -0,0 +1,2295 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// fakeEpilogProcessor records the epilog posts that reach processing
+type fakeEpilogProcessor struct {
+	received []*api.ASBXEpilogRequest
+}
+
+func (f *fakeEpilogProcessor) ProcessEpilogData(_ context.Context, req *api.ASBXEpilogRequest) (*api.ASBXEpilogResponse, error) {
+	f.received = append(f.received, req)
+	return &api.ASBXEpilogResponse{Success: true, JobID: req.JobID, DataImportStatus: "completed"}, nil
+}
+
+func TestHandleASBXEpilog(t *testing.T) {
+	cfg := &config.IntegrationConfig{EpilogSecret: "epilog-secret", EpilogMaxSkew: 5 * time.Minute}
+	body := []byte(`{"job_id":"67890","account":"NSF-2025-12345","job_state":"COMPLETED"}`)
+
+	post := func(processor epilogProcessor, payload []byte, signature string, signedAt int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/asbx/epilog", bytes.NewReader(payload))
+		req.Header.Set(asbx.EpilogSignatureHeader, signature)
+		req.Header.Set(asbx.EpilogTimestampHeader, strconv.FormatInt(signedAt, 10))
+		rec := httptest.NewRecorder()
+		handleASBXEpilog(processor, cfg)(rec, req)
+		return rec
+	}
+
+	t.Run("valid signed epilog", func(t *testing.T) {
+		processor := &fakeEpilogProcessor{}
+		signedAt := time.Now().Unix()
+
+		rec := post(processor, body, asbx.SignEpilog(cfg.EpilogSecret, signedAt, body), signedAt)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, processor.received, 1)
+		assert.Equal(t, "NSF-2025-12345", processor.received[0].Account)
+
+		var resp api.ASBXEpilogResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.True(t, resp.Success)
+		assert.Equal(t, "67890", resp.JobID)
+	})
+
+	t.Run("forged epilog is rejected", func(t *testing.T) {
+		processor := &fakeEpilogProcessor{}
+		signedAt := time.Now().Unix()
+		forged := []byte(`{"job_id":"67890","account":"NIH-2025-99999","job_state":"COMPLETED"}`)
+
+		rec := post(processor, forged, asbx.SignEpilog(cfg.EpilogSecret, signedAt, body), signedAt)
+
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, processor.received)
+	})
+}
+
+// fakeAccountingReconciler records the accounting jobs it is asked to reconcile
+type fakeAccountingReconciler struct {
+	jobs []*api.AccountingJob
+}
+
+func (f *fakeAccountingReconciler) ReconcileAccountingJobs(_ context.Context, req *api.AccountingReconcileRequest) (*api.AccountingReconcileResponse, error) {
+	f.jobs = append(f.jobs, req.Jobs...)
+	resp := &api.AccountingReconcileResponse{Reconciled: len(req.Jobs)}
+	for _, job := range req.Jobs {
+		resp.Jobs = append(resp.Jobs, &api.AccountingJobResult{
+			JobID: job.JobID, Account: job.Account, Status: api.AccountingJobReconciled,
+			TransactionID: job.HoldTransactionID, ActualCost: 8.5,
+		})
+	}
+	return resp, nil
+}
+
+func TestHandleEpilogEnv(t *testing.T) {
+	cfg := &config.IntegrationConfig{EpilogSecret: "epilog-secret", EpilogMaxSkew: 5 * time.Minute}
+	body := []byte(`{"env":{"SLURM_JOB_ID":"4201","SLURM_JOB_ACCOUNT":"proj001","SLURM_JOB_PARTITION":"aws-cpu",` +
+		`"SLURM_JOB_CPUS_PER_NODE":"8(x2)","SLURM_JOB_START_TIME":"1757844000","SLURM_JOB_END_TIME":"1757847600",` +
+		`"SLURM_JOB_EXIT_CODE2":"0:0","SLURM_JOB_COMMENT":"asbb_txn=txn_42"}}`)
+
+	post := func(service accountingReconcileService, payload []byte, signature string) *httptest.ResponseRecorder {
+		signedAt := time.Now().Unix()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/epilog/env", bytes.NewReader(payload))
+		req.Header.Set(asbx.EpilogSignatureHeader, signature)
+		req.Header.Set(asbx.EpilogTimestampHeader, strconv.FormatInt(signedAt, 10))
+		rec := httptest.NewRecorder()
+		handleEpilogEnv(service, cfg)(rec, req)
+		return rec
+	}
+	sign := func(payload []byte) string {
+		return asbx.SignEpilog(cfg.EpilogSecret, time.Now().Unix(), payload)
+	}
+
+	t.Run("environment becomes an accounting job", func(t *testing.T) {
+		service := &fakeAccountingReconciler{}
+		rec := post(service, body, sign(body))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		require.Len(t, service.jobs, 1)
+		job := service.jobs[0]
+		assert.Equal(t, "4201", job.JobID)
+		assert.Equal(t, "proj001", job.Account)
+		assert.Equal(t, "COMPLETED", job.State)
+		assert.Equal(t, int64(3600), job.ElapsedSeconds)
+		assert.Equal(t, 2, job.Nodes)
+		assert.Equal(t, 16, job.CPUs)
+		assert.Equal(t, "txn_42", job.HoldTransactionID)
+
+		var result api.AccountingJobResult
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		assert.Equal(t, api.AccountingJobReconciled, result.Status)
+		assert.Equal(t, "txn_42", result.TransactionID)
+	})
+
+	t.Run("unsigned environment is rejected", func(t *testing.T) {
+		service := &fakeAccountingReconciler{}
+		rec := post(service, body, "")
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, service.jobs)
+	})
+
+	t.Run("incomplete environment is rejected", func(t *testing.T) {
+		service := &fakeAccountingReconciler{}
+		incomplete := []byte(`{"env":{"SLURM_JOB_ACCOUNT":"proj001","SLURM_JOB_START_TIME":"1757844000"}}`)
+		rec := post(service, incomplete, sign(incomplete))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "SLURM_JOB_ID")
+		assert.Empty(t, service.jobs)
+	})
+}
+
+// fakeASBXIntegration records the reconciliations and status requests the ASBX handlers make
+type fakeASBXIntegration struct {
+	fakeEpilogProcessor
+	reconciled    []*api.ASBXCostReconciliationRequest
+	statusChecked bool
+}
+
+func (f *fakeASBXIntegration) ProcessCostReconciliation(_ context.Context, req *api.ASBXCostReconciliationRequest) (*api.ASBXCostReconciliationResponse, error) {
+	f.reconciled = append(f.reconciled, req)
+	if req.JobCostData.BudgetTransactionID == "" {
+		return nil, api.NewBudgetError(api.ErrCodeValidation, "Budget transaction ID is required for reconciliation")
+	}
+	return &api.ASBXCostReconciliationResponse{Success: true, ReconciliationID: "asbx_recon_1"}, nil
+}
+
+func (f *fakeASBXIntegration) GetIntegrationStatus(_ context.Context) (*api.ASBXIntegrationStatus, error) {
+	f.statusChecked = true
+	return &api.ASBXIntegrationStatus{IntegrationEnabled: true, TotalJobsReconciled: 3}, nil
+}
+
+func TestASBXHandlers(t *testing.T) {
+	cfg := &config.IntegrationConfig{EpilogSecret: "epilog-secret", EpilogMaxSkew: 5 * time.Minute}
+
+	// reconcile posts body to the reconciliation handler, signed when signed is set
+	reconcile := func(integration asbxIntegration, body string, signed bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/asbx/reconcile", bytes.NewBufferString(body))
+		if signed {
+			signedAt := time.Now().Unix()
+			req.Header.Set(asbx.EpilogSignatureHeader, asbx.SignEpilog(cfg.EpilogSecret, signedAt, []byte(body)))
+			req.Header.Set(asbx.EpilogTimestampHeader, strconv.FormatInt(signedAt, 10))
+		}
+		rec := httptest.NewRecorder()
+		handleASBXReconciliation(integration, cfg)(rec, req)
+		return rec
+	}
+
+	t.Run("reconciliation reaches the integration service", func(t *testing.T) {
+		integration := &fakeASBXIntegration{}
+		rec := reconcile(integration, `{"job_cost_data":{"job_id":"67890","account":"proj001","budget_transaction_id":"txn_1","actual_cost":8.5}}`, true)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, integration.reconciled, 1)
+		assert.Equal(t, "txn_1", integration.reconciled[0].JobCostData.BudgetTransactionID)
+
+		var resp api.ASBXCostReconciliationResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.True(t, resp.Success)
+		assert.Equal(t, "asbx_recon_1", resp.ReconciliationID)
+	})
+
+	t.Run("reconciliation errors are reported", func(t *testing.T) {
+		integration := &fakeASBXIntegration{}
+		assert.Equal(t, http.StatusBadRequest, reconcile(integration, `{"job_cost_data":{"job_id":"67890"}}`, true).Code)
+		assert.Equal(t, http.StatusBadRequest, reconcile(integration, `{`, true).Code)
+		assert.Len(t, integration.reconciled, 1)
+	})
+
+	t.Run("unsigned reconciliation is rejected", func(t *testing.T) {
+		integration := &fakeASBXIntegration{}
+		rec := reconcile(integration, `{"job_cost_data":{"job_id":"67890","account":"proj001","budget_transaction_id":"txn_1","actual_cost":0}}`, false)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Empty(t, integration.reconciled)
+	})
+
+	t.Run("status comes from the integration service", func(t *testing.T) {
+		integration := &fakeASBXIntegration{}
+		rec := httptest.NewRecorder()
+		handleASBXStatus(integration)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/asbx/status", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, integration.statusChecked)
+
+		var status api.ASBXIntegrationStatus
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		assert.True(t, status.IntegrationEnabled)
+		assert.Equal(t, int64(3), status.TotalJobsReconciled)
+	})
+
+	t.Run("disabled integration answers 503", func(t *testing.T) {
+		var disabled asbxIntegration
+
+		for name, handler := range map[string]http.HandlerFunc{
+			"reconcile": handleASBXReconciliation(disabled, cfg),
+			"epilog":    handleASBXEpilog(disabled, cfg),
+			"status":    handleASBXStatus(disabled),
+		} {
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asbx/"+name, bytes.NewBufferString(`{}`)))
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code, name)
+			assert.Contains(t, rec.Body.String(), "asbx_enabled", name)
+		}
+	})
+}
+
+// fakeOrphanedHoldService serves a fixed set of seeded orphaned holds
+type fakeOrphanedHoldService struct {
+	holds     []*api.OrphanedHold
+	recovered bool
+}
+
+func (f *fakeOrphanedHoldService) ListOrphanedHolds(_ context.Context) (*api.OrphanedHoldsResponse, error) {
+	return &api.OrphanedHoldsResponse{ReconciliationTimeout: "24h0m0s", Holds: f.holds}, nil
+}
+
+func (f *fakeOrphanedHoldService) RecoverOrphanedHolds(_ context.Context) (*api.OrphanRecoveryResponse, error) {
+	f.recovered = true
+	resp := &api.OrphanRecoveryResponse{Found: len(f.holds), Recovered: []string{}}
+	for _, hold := range f.holds {
+		if hold.AgeHours > 48 {
+			resp.Recovered = append(resp.Recovered, hold.TransactionID)
+		}
+	}
+	return resp, nil
+}
+
+func TestAdminOrphanedHolds(t *testing.T) {
+	now := time.Now()
+	service := &fakeOrphanedHoldService{holds: []*api.OrphanedHold{
+		{TransactionID: "txn_old", Account: "proj001", Amount: 12, CreatedAt: now.Add(-72 * time.Hour), AgeHours: 72},
+		{TransactionID: "txn_recent", Account: "proj002", Amount: 30, CreatedAt: now.Add(-30 * time.Hour), AgeHours: 30},
+	}}
+
+	router := mux.NewRouter()
+	admin := router.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(adminAuthMiddleware([]string{"admin-key"}))
+	admin.HandleFunc("/orphaned-holds", handleListOrphanedHolds(service)).Methods("GET")
+	admin.HandleFunc("/recover", handleRecoverOrphanedHolds(service)).Methods("POST")
+
+	do := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("lists seeded holds", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/v1/admin/orphaned-holds", "admin-key")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp api.OrphanedHoldsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Holds, 2)
+		assert.Equal(t, "txn_old", resp.Holds[0].TransactionID)
+		assert.Equal(t, "proj001", resp.Holds[0].Account)
+		assert.Equal(t, 72.0, resp.Holds[0].AgeHours)
+	})
+
+	t.Run("recovers on demand", func(t *testing.T) {
+		rec := do(http.MethodPost, "/api/v1/admin/recover", "admin-key")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp api.OrphanRecoveryResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 2, resp.Found)
+		assert.Equal(t, []string{"txn_old"}, resp.Recovered)
+	})
+
+	t.Run("rejects missing and wrong tokens", func(t *testing.T) {
+		service.recovered = false
+		assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/admin/orphaned-holds", "").Code)
+		assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/api/v1/admin/recover", "guessed").Code)
+		assert.False(t, service.recovered)
+	})
+
+	t.Run("closed without configured keys", func(t *testing.T) {
+		closed := mux.NewRouter()
+		closed.Use(adminAuthMiddleware(nil))
+		closed.HandleFunc("/api/v1/admin/recover", handleRecoverOrphanedHolds(service)).Methods("POST")
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/recover", nil)
+		req.Header.Set("Authorization", "Bearer anything")
+		rec := httptest.NewRecorder()
+		closed.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+}
+
+// fakePendingReconciliationService records the account filter it was asked for
+type fakePendingReconciliationService struct {
+	account string
+}
+
+func (f *fakePendingReconciliationService) ListPendingReconciliations(_ context.Context, slurmAccount string) (*api.PendingReconciliationResponse, error) {
+	f.account = slurmAccount
+	if slurmAccount == "missing" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Account not found")
+	}
+	return &api.PendingReconciliationResponse{
+		Account:               slurmAccount,
+		ReconciliationTimeout: "24h0m0s",
+		PastTimeout:           1,
+		Holds: []*api.PendingReconciliation{
+			{TransactionID: "txn_stale", JobID: "1001", Account: "proj001", Amount: 12, AgeHours: 30, PastTimeout: true},
+			{TransactionID: "txn_fresh", JobID: "1002", Account: "proj001", Amount: 6, AgeHours: 1},
+		},
+	}, nil
+}
+
+func TestHandleListPendingReconciliations(t *testing.T) {
+	service := &fakePendingReconciliationService{}
+	handler := handleListPendingReconciliations(service)
+
+	t.Run("filters by account", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconciliation/pending?account=proj001", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "proj001", service.account)
+
+		var resp api.PendingReconciliationResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Holds, 2)
+		assert.Equal(t, 1, resp.PastTimeout)
+		assert.Equal(t, "1001", resp.Holds[0].JobID)
+		assert.True(t, resp.Holds[0].PastTimeout)
+		assert.False(t, resp.Holds[1].PastTimeout)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/reconciliation/pending?account=missing", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+// fakeAccountingReconcileService reports every submitted job as reconciled
+type fakeAccountingReconcileService struct {
+	jobs []*api.AccountingJob
+}
+
+func (f *fakeAccountingReconcileService) ReconcileAccountingJobs(_ context.Context, req *api.AccountingReconcileRequest) (*api.AccountingReconcileResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	f.jobs = req.Jobs
+	resp := &api.AccountingReconcileResponse{}
+	for _, job := range req.Jobs {
+		resp.Reconciled++
+		resp.Jobs = append(resp.Jobs, &api.AccountingJobResult{JobID: job.JobID, Account: job.Account, Status: api.AccountingJobReconciled})
+	}
+	return resp, nil
+}
+
+func TestHandleReconcileAccountingJobs(t *testing.T) {
+	service := &fakeAccountingReconcileService{}
+	handler := handleReconcileAccountingJobs(service)
+
+	t.Run("reconciles jobs", func(t *testing.T) {
+		body := `{"jobs":[{"job_id":"4201","account":"proj001","state":"COMPLETED","elapsed_seconds":3600,"hold_transaction_id":"txn_abc"}]}`
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconciliation/sacct", bytes.NewBufferString(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Len(t, service.jobs, 1)
+		assert.Equal(t, "txn_abc", service.jobs[0].HoldTransactionID)
+
+		var resp api.AccountingReconcileResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 1, resp.Reconciled)
+	})
+
+	t.Run("rejects an empty job list", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconciliation/sacct", bytes.NewBufferString(`{"jobs":[]}`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("rejects invalid JSON", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/reconciliation/sacct", bytes.NewBufferString(`{`)))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("only admins may submit records once auth is enabled", func(t *testing.T) {
+		service := &fakeAccountingReconcileService{}
+		authed := userAuthMiddleware(config.AuthConfig{Enabled: true, AdminAPIKeys: []string{"admin-key"}})(
+			handleReconcileAccountingJobs(service))
+		body := `{"jobs":[{"job_id":"4201","account":"proj001","state":"FAILED","elapsed_seconds":1,"hold_transaction_id":"txn_abc"}]}`
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/reconciliation/sacct", bytes.NewBufferString(body))
+		req.Header.Set(userHeader, "alice")
+		rec := httptest.NewRecorder()
+		authed.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Nil(t, service.jobs)
+
+		req = httptest.NewRequest(http.MethodPost, "/api/v1/reconciliation/sacct", bytes.NewBufferString(body))
+		req.Header.Set("Authorization", "Bearer admin-key")
+		rec = httptest.NewRecorder()
+		authed.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, service.jobs, 1)
+	})
+}
+
+// fakeConsistencyService reports a single drifted account and repairs it on request
+type fakeConsistencyService struct {
+	last *api.ConsistencyCheckRequest
+}
+
+func (f *fakeConsistencyService) CheckConsistency(_ context.Context, req *api.ConsistencyCheckRequest) (*api.ConsistencyCheckResponse, error) {
+	f.last = req
+	if req.Account == "missing" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Account not found")
+	}
+	result := &api.AccountConsistency{Account: "proj001", CachedUsed: 150, ExpectedUsed: 100, UsedDrift: 50, Repaired: req.Repair}
+	resp := &api.ConsistencyCheckResponse{Checked: 1, Inconsistent: 1, Accounts: []*api.AccountConsistency{result}}
+	if req.Repair {
+		resp.Repaired = 1
+	}
+	return resp, nil
+}
+
+func TestAdminConsistency(t *testing.T) {
+	service := &fakeConsistencyService{}
+
+	router := mux.NewRouter()
+	admin := router.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(adminAuthMiddleware([]string{"admin-key"}))
+	admin.HandleFunc("/consistency", handleCheckConsistency(service)).Methods("GET")
+	admin.HandleFunc("/consistency/repair", handleRepairConsistency(service)).Methods("POST")
+
+	do := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("verifies without repairing", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/v1/admin/consistency?account=proj001", "admin-key", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "proj001", service.last.Account)
+		assert.False(t, service.last.Repair)
+
+		var resp api.ConsistencyCheckResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 1, resp.Inconsistent)
+		assert.Equal(t, 0, resp.Repaired)
+		assert.Equal(t, 50.0, resp.Accounts[0].UsedDrift)
+	})
+
+	t.Run("repairs with an audit reason", func(t *testing.T) {
+		rec := do(http.MethodPost, "/api/v1/admin/consistency/repair", "admin-key",
+			`{"account":"proj001","reason":"manual edit","repaired_by":"ops"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, service.last.Repair)
+		assert.Equal(t, "manual edit", service.last.Reason)
+		assert.Equal(t, "ops", service.last.RepairedBy)
+
+		var resp api.ConsistencyCheckResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 1, resp.Repaired)
+		assert.True(t, resp.Accounts[0].Repaired)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/admin/consistency?account=missing", "admin-key", "").Code)
+	})
+
+	t.Run("requires an admin token to repair", func(t *testing.T) {
+		service.last = nil
+		assert.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "/api/v1/admin/consistency/repair", "", `{}`).Code)
+		assert.Nil(t, service.last)
+	})
+
+	t.Run("rejects invalid JSON", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/admin/consistency/repair", "admin-key", `{`).Code)
+	})
+}
+
+// fakeEstimateService prices every job at $10 and is unavailable for the "down" partition
+type fakeEstimateService struct {
+	last *api.EstimateRequest
+}
+
+func (f *fakeEstimateService) EstimateJobCost(_ context.Context, req *api.EstimateRequest) (*api.EstimateResponse, error) {
+	f.last = req
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Partition == "down" {
+		return nil, api.NewBudgetError(api.ErrCodeAdvisorUnavailable, "Advisor service is unavailable and failure_mode is STRICT")
+	}
+	return &api.EstimateResponse{EstimatedCost: 10, Confidence: 0.8, Recommendation: "Run on cpu"}, nil
+}
+
+func TestHandleEstimate(t *testing.T) {
+	service := &fakeEstimateService{}
+	handler := handleEstimate(service)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/estimate", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	t.Run("estimates without an account", func(t *testing.T) {
+		rec := post(`{"partition":"gpu","nodes":1,"cpus":8,"gpus":2,"wall_time":"02:00:00"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 2, service.last.GPUs)
+		assert.Empty(t, service.last.Account)
+
+		var resp api.EstimateResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 10.0, resp.EstimatedCost)
+		assert.Equal(t, 0.8, resp.Confidence)
+		assert.Equal(t, "Run on cpu", resp.Recommendation)
+	})
+
+	t.Run("advisor unavailable in strict mode", func(t *testing.T) {
+		assert.Equal(t, http.StatusServiceUnavailable, post(`{"partition":"down","nodes":1,"cpus":1,"wall_time":"01:00:00"}`).Code)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post(`{`).Code)
+		assert.Equal(t, http.StatusBadRequest, post(`{"partition":"cpu","nodes":0,"cpus":1,"wall_time":"01:00:00"}`).Code)
+	})
+
+	t.Run("lists every invalid field", func(t *testing.T) {
+		rec := post(`{"nodes":0,"cpus":1,"gpus":-1,"wall_time":"01:00:00"}`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var resp api.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, api.ErrCodeValidation, resp.Error.Code)
+		assert.Equal(t, "partition", resp.Error.Field)
+		assert.Equal(t, []api.FieldError{
+			{Field: "partition", Message: "is required"},
+			{Field: "nodes", Message: "must be at least 1"},
+			{Field: "gpus", Message: "must not be negative"},
+		}, resp.Error.ValidationErrors)
+	})
+
+	t.Run("lists a single invalid field", func(t *testing.T) {
+		rec := post(`{`)
+		var resp api.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, []api.FieldError{{Field: "body", Message: resp.Error.Message}}, resp.Error.ValidationErrors)
+	})
+}
+
+// fakeJobValidateService approves jobs on account proj001, which has $50 left, and
+// reports an unaffordable hold for every other account
+type fakeJobValidateService struct {
+	last *api.JobValidateRequest
+}
+
+func (f *fakeJobValidateService) ValidateJob(_ context.Context, req *api.JobValidateRequest) (*api.JobValidateResponse, error) {
+	f.last = req
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	remaining := 50.0
+	resp := &api.JobValidateResponse{
+		Valid:           true,
+		Resources:       api.JobResources{Account: "proj001", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "02:00:00"},
+		EstimatedCost:   10,
+		HoldAmount:      12,
+		Affordable:      true,
+		BudgetRemaining: &remaining,
+		Issues:          []api.JobValidationIssue{},
+	}
+	if req.Account != "proj001" {
+		resp.Valid, resp.Affordable = false, false
+		resp.Resources.Account = req.Account
+		resp.Issues = append(resp.Issues, api.JobValidationIssue{
+			Severity: api.ValidationSeverityError, Field: "account", Message: "Insufficient budget",
+		})
+	}
+	return resp, nil
+}
+
+func TestHandleValidateJob(t *testing.T) {
+	service := &fakeJobValidateService{}
+	handler := handleValidateJob(service)
+
+	post := func(body string, user *requestUser) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs/validate", bytes.NewBufferString(body))
+		if user != nil {
+			req = req.WithContext(context.WithValue(req.Context(), requestUserKey{}, user))
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	t.Run("valid script", func(t *testing.T) {
+		rec := post(`{"script":"#!/bin/bash\n#SBATCH -A proj001\n","account":"proj001"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, service.last.UserID)
+
+		var resp api.JobValidateResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.True(t, resp.Valid)
+		assert.True(t, resp.Affordable)
+		assert.Equal(t, 10.0, resp.EstimatedCost)
+		require.NotNil(t, resp.BudgetRemaining)
+		assert.Equal(t, 50.0, *resp.BudgetRemaining)
+		assert.Empty(t, resp.Issues)
+		assert.Contains(t, rec.Body.String(), `"issues":[]`)
+	})
+
+	t.Run("over budget is reported, not refused", func(t *testing.T) {
+		rec := post(`{"script":"#SBATCH -A proj002\n","account":"proj002"}`, nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp api.JobValidateResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.False(t, resp.Valid)
+		assert.False(t, resp.Affordable)
+		require.Len(t, resp.Issues, 1)
+		assert.Equal(t, "account", resp.Issues[0].Field)
+	})
+
+	t.Run("checks membership for the caller", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, post(`{"script":"#SBATCH -A proj001\n"}`, &requestUser{Name: "alice"}).Code)
+		assert.Equal(t, "alice", service.last.UserID)
+
+		require.Equal(t, http.StatusOK, post(`{"script":"#SBATCH -A proj001\n","user_id":"mallory"}`, &requestUser{Name: "root", Admin: true}).Code)
+		assert.Empty(t, service.last.UserID)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post(`{`, nil).Code)
+		assert.Equal(t, http.StatusBadRequest, post(`{"script":""}`, nil).Code)
+		assert.Equal(t, http.StatusUnauthorized, post(`{"script":"#SBATCH -A proj001\n"}`, &requestUser{}).Code)
+	})
+}
+
+// fakeJobStartedService escalates txn-queued, held on proj001, from 1.20 to 12.00 and knows
+// no other hold; alice is a member of proj001
+type fakeJobStartedService struct {
+	last *api.JobStartedRequest
+}
+
+func (f *fakeJobStartedService) AuthorizeJobStart(_ context.Context, user string, req *api.JobStartedRequest) error {
+	if req.TransactionID == "txn-queued" && user == "alice" {
+		return nil
+	}
+	return api.NewNotAccountMemberError("proj001", user)
+}
+
+func (f *fakeJobStartedService) StartJob(_ context.Context, req *api.JobStartedRequest) (*api.JobStartedResponse, error) {
+	f.last = req
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.TransactionID != "txn-queued" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Transaction %s not found", req.TransactionID))
+	}
+	return &api.JobStartedResponse{
+		JobID:          req.JobID,
+		TransactionID:  req.TransactionID,
+		Escalated:      true,
+		ReservedAmount: 1.2,
+		HoldAmount:     12,
+	}, nil
+}
+
+func TestHandleJobStarted(t *testing.T) {
+	service := &fakeJobStartedService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/jobs/{job_id}/started", handleJobStarted(service)).Methods("POST")
+
+	post := func(jobID, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/jobs/"+jobID+"/started", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	t.Run("escalates the hold", func(t *testing.T) {
+		rec := post("4242", `{"transaction_id":"txn-queued"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "4242", service.last.JobID)
+
+		var resp api.JobStartedResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.True(t, resp.Escalated)
+		assert.Equal(t, "4242", resp.JobID)
+		assert.Equal(t, 1.2, resp.ReservedAmount)
+		assert.Equal(t, 12.0, resp.HoldAmount)
+	})
+
+	t.Run("requires the hold transaction", func(t *testing.T) {
+		rec := post("4242", "")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var resp api.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "transaction_id", resp.Error.Field)
+	})
+
+	t.Run("unknown hold", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, post("4242", `{"transaction_id":"txn-missing"}`).Code)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post("4242", `{`).Code)
+	})
+}
+
+// fakeAccountCloner clones proj001 and reports any other source as missing
+type fakeAccountCloner struct {
+	source string
+	last   *api.CloneAccountRequest
+}
+
+func (f *fakeAccountCloner) CloneAccount(_ context.Context, sourceAccount string, req *api.CloneAccountRequest) (*api.AccountCloneResponse, error) {
+	f.source, f.last = sourceAccount, req
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if sourceAccount != "proj001" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Account '"+sourceAccount+"' not found")
+	}
+	return &api.AccountCloneResponse{
+		Account:               &api.BudgetAccount{SlurmAccount: req.SlurmAccount, BudgetLimit: 1000},
+		SourceAccount:         sourceAccount,
+		PartitionLimitsCopied: 2,
+	}, nil
+}
+
+func TestCloneAccount(t *testing.T) {
+	service := &fakeAccountCloner{}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/accounts/{account}/clone", handleCloneAccount(service)).Methods("POST")
+
+	post := func(source, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/accounts/"+source+"/clone", bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("clones with overrides", func(t *testing.T) {
+		rec := post("proj001", `{"slurm_account":"proj002","budget_limit":2500,"start_date":"2026-01-01T00:00:00Z","copy_allocation_schedule":true}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "proj001", service.source)
+		require.NotNil(t, service.last.BudgetLimit)
+		assert.Equal(t, 2500.0, *service.last.BudgetLimit)
+		require.NotNil(t, service.last.StartDate)
+		assert.Nil(t, service.last.EndDate)
+		assert.True(t, service.last.CopyAllocationSchedule)
+
+		var resp api.AccountCloneResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "proj002", resp.Account.SlurmAccount)
+		assert.Equal(t, "proj001", resp.SourceAccount)
+		assert.Equal(t, int64(2), resp.PartitionLimitsCopied)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post("proj001", `{`).Code)
+		assert.Equal(t, http.StatusBadRequest, post("proj001", `{}`).Code)
+		assert.Equal(t, http.StatusBadRequest, post("proj001", `{"slurm_account":"proj002","budget_limit":0}`).Code)
+	})
+
+	t.Run("unknown source", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, post("missing", `{"slurm_account":"proj002"}`).Code)
+	})
+}
+
+// fakeMemberService keeps memberships in memory; only proj001 and proj002 exist
+type fakeMemberService struct {
+	members map[string][]string // account to users
+}
+
+func (f *fakeMemberService) account(slurmAccount string) error {
+	if slurmAccount != "proj001" && slurmAccount != "proj002" {
+		return api.NewAccountNotFoundError(slurmAccount)
+	}
+	return nil
+}
+
+func (f *fakeMemberService) AddAccountMember(_ context.Context, slurmAccount, user string) (*api.AccountMember, error) {
+	if err := f.account(slurmAccount); err != nil {
+		return nil, err
+	}
+	f.members[slurmAccount] = append(f.members[slurmAccount], user)
+	return &api.AccountMember{SlurmAccount: slurmAccount, User: user}, nil
+}
+
+func (f *fakeMemberService) RemoveAccountMember(_ context.Context, slurmAccount, user string) error {
+	if err := f.account(slurmAccount); err != nil {
+		return err
+	}
+	for i, member := range f.members[slurmAccount] {
+		if member == user {
+			f.members[slurmAccount] = append(f.members[slurmAccount][:i], f.members[slurmAccount][i+1:]...)
+			return nil
+		}
+	}
+	return api.NewBudgetError(api.ErrCodeNotFound, "not a member")
+}
+
+func (f *fakeMemberService) ListAccountMembers(_ context.Context, slurmAccount string) ([]*api.AccountMember, error) {
+	if err := f.account(slurmAccount); err != nil {
+		return nil, err
+	}
+	members := []*api.AccountMember{}
+	for _, user := range f.members[slurmAccount] {
+		members = append(members, &api.AccountMember{SlurmAccount: slurmAccount, User: user})
+	}
+	return members, nil
+}
+
+func (f *fakeMemberService) ListUserAccounts(_ context.Context, user string) (*api.UserAccountsResponse, error) {
+	resp := &api.UserAccountsResponse{User: user, Accounts: []*api.UserAccount{}}
+	for _, slurmAccount := range []string{"proj001", "proj002"} {
+		for _, member := range f.members[slurmAccount] {
+			if member == user {
+				resp.Accounts = append(resp.Accounts, &api.UserAccount{
+					SlurmAccount:    slurmAccount,
+					BudgetLimit:     1000,
+					BudgetAvailable: 250.5,
+					Health:          api.AccountHealthHealthy,
+				})
+			}
+		}
+	}
+	return resp, nil
+}
+
+func TestAccountMembers(t *testing.T) {
+	service := &fakeMemberService{members: map[string][]string{}}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/accounts/{account}/members", handleListAccountMembers(service)).Methods("GET")
+	router.HandleFunc("/api/v1/accounts/{account}/members/{user}", handleAddAccountMember(service)).Methods("PUT")
+	router.HandleFunc("/api/v1/accounts/{account}/members/{user}", handleRemoveAccountMember(service)).Methods("DELETE")
+	router.HandleFunc("/api/v1/users/{user}/accounts", handleListUserAccounts(service)).Methods("GET")
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/api/v1/accounts/proj001/members/alice").Code)
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/api/v1/accounts/proj002/members/alice").Code)
+	require.Equal(t, http.StatusOK, do(http.MethodPut, "/api/v1/accounts/proj002/members/bob").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPut, "/api/v1/accounts/missing/members/alice").Code)
+
+	t.Run("user in two accounts", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/v1/users/alice/accounts")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp api.UserAccountsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "alice", resp.User)
+		require.Len(t, resp.Accounts, 2)
+		assert.Equal(t, "proj001", resp.Accounts[0].SlurmAccount)
+		assert.Equal(t, "proj002", resp.Accounts[1].SlurmAccount)
+		// Amounts are written as Money, with the currency's decimal places
+		assert.Contains(t, rec.Body.String(), `"budget_available":250.50`)
+		assert.Equal(t, 250.5, resp.Accounts[1].BudgetAvailable)
+		assert.Equal(t, api.AccountHealthHealthy, resp.Accounts[0].Health)
+	})
+
+	t.Run("list and remove members", func(t *testing.T) {
+		var members []api.AccountMember
+		rec := do(http.MethodGet, "/api/v1/accounts/proj002/members")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &members))
+		assert.Len(t, members, 2)
+
+		assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/v1/accounts/proj002/members/alice").Code)
+		assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/v1/accounts/proj002/members/alice").Code)
+
+		rec = do(http.MethodGet, "/api/v1/users/alice/accounts")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var resp api.UserAccountsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Accounts, 1)
+		assert.Equal(t, "proj001", resp.Accounts[0].SlurmAccount)
+	})
+}
+
+// fakeTransferService merges proj001 into proj002 and refuses an inactive destination
+type fakeTransferService struct {
+	source, dest string
+	last         *api.AccountTransferRequest
+}
+
+func (f *fakeTransferService) TransferAccount(_ context.Context, sourceAccount, destAccount string, req *api.AccountTransferRequest) (*api.AccountTransferResponse, error) {
+	f.source, f.dest, f.last = sourceAccount, destAccount, req
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if destAccount == "closed" {
+		return nil, api.NewAccountInactiveError(destAccount, "inactive")
+	}
+	return &api.AccountTransferResponse{
+		Transfer:    &api.AccountTransfer{SourceAccount: sourceAccount, DestAccount: destAccount, BudgetLimit: 500, TransactionsMoved: 3},
+		Destination: &api.BudgetAccount{SlurmAccount: destAccount, BudgetLimit: 1500},
+	}, nil
+}
+
+func TestAdminTransferAccount(t *testing.T) {
+	service := &fakeTransferService{}
+
+	router := mux.NewRouter()
+	admin := router.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(adminAuthMiddleware([]string{"admin-key"}))
+	admin.HandleFunc("/accounts/{source}/transfer-to/{dest}", handleTransferAccount(service)).Methods("POST")
+
+	post := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("transfers with an audit reason", func(t *testing.T) {
+		rec := post("/api/v1/admin/accounts/proj001/transfer-to/proj002", "admin-key",
+			`{"schedules":"retire","reason":"grant consolidation","transferred_by":"ops"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "proj001", service.source)
+		assert.Equal(t, "proj002", service.dest)
+		assert.Equal(t, api.TransferSchedulesRetire, service.last.Schedules)
+		assert.Equal(t, "ops", service.last.TransferredBy)
+
+		var resp api.AccountTransferResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, int64(3), resp.Transfer.TransactionsMoved)
+		assert.Equal(t, 1500.0, resp.Destination.BudgetLimit)
+	})
+
+	t.Run("body is optional", func(t *testing.T) {
+		rec := post("/api/v1/admin/accounts/proj001/transfer-to/proj002", "admin-key", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, service.last.Schedules)
+	})
+
+	t.Run("refuses an inactive destination", func(t *testing.T) {
+		assert.Equal(t, http.StatusPaymentRequired, post("/api/v1/admin/accounts/proj001/transfer-to/closed", "admin-key", "").Code)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post("/api/v1/admin/accounts/proj001/transfer-to/proj002", "admin-key", `{`).Code)
+		assert.Equal(t, http.StatusBadRequest, post("/api/v1/admin/accounts/proj001/transfer-to/proj002", "admin-key", `{"schedules":"keep"}`).Code)
+	})
+
+	t.Run("requires an admin token", func(t *testing.T) {
+		service.last = nil
+		assert.Equal(t, http.StatusUnauthorized, post("/api/v1/admin/accounts/proj001/transfer-to/proj002", "", "").Code)
+		assert.Nil(t, service.last)
+	})
+}
+
+// fakeHoldAdjustService adjusts hold txn-1 and reports any other hold as settled
+type fakeHoldAdjustService struct {
+	last *api.HoldAdjustRequest
+}
+
+func (f *fakeHoldAdjustService) AdjustHold(_ context.Context, transactionID string, req *api.HoldAdjustRequest) (*api.HoldAdjustResponse, error) {
+	f.last = req
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if transactionID != "txn-1" {
+		return nil, api.NewHoldSettledError(transactionID)
+	}
+	return &api.HoldAdjustResponse{
+		Adjustment: &api.HoldAdjustment{TransactionID: transactionID, PreviousAmount: 12, NewAmount: req.Amount, Reason: req.Reason},
+		Account:    &api.BudgetAccount{SlurmAccount: "proj001", BudgetHeld: req.Amount},
+	}, nil
+}
+
+func TestAdminAdjustHold(t *testing.T) {
+	service := &fakeHoldAdjustService{}
+
+	router := mux.NewRouter()
+	admin := router.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(adminAuthMiddleware([]string{"admin-key"}))
+	admin.HandleFunc("/holds/{transaction_id}/adjust", handleAdjustHold(service)).Methods("POST")
+
+	post := func(path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("adjusts a live hold", func(t *testing.T) {
+		rec := post("/api/v1/admin/holds/txn-1/adjust", "admin-key",
+			`{"amount":20,"reason":"estimate missed GPU nodes","adjusted_by":"ops"}`)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "ops", service.last.AdjustedBy)
+
+		var resp api.HoldAdjustResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 12.0, resp.Adjustment.PreviousAmount)
+		assert.Equal(t, 20.0, resp.Adjustment.NewAmount)
+		assert.Equal(t, 20.0, resp.Account.BudgetHeld)
+	})
+
+	t.Run("refuses a settled hold", func(t *testing.T) {
+		rec := post("/api/v1/admin/holds/txn-2/adjust", "admin-key", `{"amount":20,"reason":"late"}`)
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, post("/api/v1/admin/holds/txn-1/adjust", "admin-key", "").Code)
+		assert.Equal(t, http.StatusBadRequest, post("/api/v1/admin/holds/txn-1/adjust", "admin-key", `{"amount":0,"reason":"x"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, post("/api/v1/admin/holds/txn-1/adjust", "admin-key", `{"amount":5}`).Code)
+	})
+
+	t.Run("requires an admin token", func(t *testing.T) {
+		service.last = nil
+		assert.Equal(t, http.StatusUnauthorized, post("/api/v1/admin/holds/txn-1/adjust", "", `{"amount":5,"reason":"x"}`).Code)
+		assert.Nil(t, service.last)
+	})
+}
+
+// fakeAccountExportService exports one known account and imports any account that does
+// not already exist
+type fakeAccountExportService struct {
+	imported *api.AccountExport
+}
+
+func (f *fakeAccountExportService) ExportAccount(_ context.Context, slurmAccount string) (*api.AccountExport, error) {
+	if slurmAccount != "proj001" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Account not found")
+	}
+	return &api.AccountExport{
+		Version:       api.AccountExportVersion,
+		Account:       &api.BudgetAccount{SlurmAccount: "proj001", BudgetLimit: 100},
+		ParentAccount: "dept",
+		Transactions:  []api.BudgetTransaction{{TransactionID: "txn-1", Amount: 12}},
+	}, nil
+}
+
+func (f *fakeAccountExportService) ImportAccount(_ context.Context, export *api.AccountExport) (*api.AccountImportResponse, error) {
+	f.imported = export
+	if err := export.Validate(); err != nil {
+		return nil, err
+	}
+	if export.Account.SlurmAccount == "proj001" {
+		return nil, api.NewBudgetError(api.ErrCodeDuplicateAccount, "Account already exists")
+	}
+	return &api.AccountImportResponse{Account: export.Account, Transactions: len(export.Transactions)}, nil
+}
+
+func TestAdminAccountExportImport(t *testing.T) {
+	service := &fakeAccountExportService{}
+
+	router := mux.NewRouter()
+	admin := router.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(adminAuthMiddleware([]string{"admin-key"}))
+	admin.HandleFunc("/accounts/import", handleImportAccount(service)).Methods("POST")
+	admin.HandleFunc("/accounts/{account}/export", handleExportAccount(service)).Methods("GET")
+
+	serve := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("export round-trips through import", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/v1/admin/accounts/proj001/export", "admin-key", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var export api.AccountExport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &export))
+		assert.Equal(t, "dept", export.ParentAccount)
+		require.Len(t, export.Transactions, 1)
+
+		// Restoring under the same name conflicts while the original still exists
+		rec = serve(http.MethodPost, "/api/v1/admin/accounts/import", "admin-key", rec.Body.String())
+		assert.Equal(t, http.StatusConflict, rec.Code)
+
+		export.Account.SlurmAccount = "proj001-restored"
+		body, err := json.Marshal(export)
+		require.NoError(t, err)
+		rec = serve(http.MethodPost, "/api/v1/admin/accounts/import", "admin-key", string(body))
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		var resp api.AccountImportResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "proj001-restored", resp.Account.SlurmAccount)
+		assert.Equal(t, 1, resp.Transactions)
+		assert.Equal(t, "txn-1", service.imported.Transactions[0].TransactionID)
+	})
+
+	t.Run("unknown account is not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/admin/accounts/missing/export", "admin-key", "").Code)
+	})
+
+	t.Run("rejects invalid exports", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/api/v1/admin/accounts/import", "admin-key", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/api/v1/admin/accounts/import", "admin-key",
+			`{"version":99,"account":{"slurm_account":"proj002"}}`).Code)
+	})
+
+	t.Run("requires an admin token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/api/v1/admin/accounts/proj001/export", "", "").Code)
+		service.imported = nil
+		assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "/api/v1/admin/accounts/import", "", `{}`).Code)
+		assert.Nil(t, service.imported)
+	})
+}
+
+// fakeGrantPeriodService reports a fixed period split for one known grant
+type fakeGrantPeriodService struct {
+	period *int
+}
+
+func (f *fakeGrantPeriodService) GetGrantPeriodSummary(_ context.Context, grantNumber string, period *int) (*api.GrantPeriodSummary, error) {
+	f.period = period
+	if grantNumber != "NSF-123" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Grant not found")
+	}
+	number := 2
+	if period != nil {
+		number = *period
+	}
+	return &api.GrantPeriodSummary{
+		GrantNumber:   grantNumber,
+		PeriodNumber:  number,
+		PeriodEndDate: time.Now().Add(10 * 24 * time.Hour),
+		Budget:        1000,
+		Spent:         400,
+		Committed:     150,
+		Remaining:     450,
+	}, nil
+}
+
+func TestHandleGrantPeriodSummary(t *testing.T) {
+	service := &fakeGrantPeriodService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/grants/{grant}/period-summary", handleGrantPeriodSummary(service)).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("defaults to the current period", func(t *testing.T) {
+		rec := get("/api/v1/grants/NSF-123/period-summary")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Nil(t, service.period)
+
+		var resp api.GrantPeriodSummary
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 2, resp.PeriodNumber)
+		assert.Equal(t, 400.0, resp.Spent)
+		assert.Equal(t, 150.0, resp.Committed)
+		assert.Equal(t, 450.0, resp.Remaining)
+	})
+
+	t.Run("selects a period", func(t *testing.T) {
+		rec := get("/api/v1/grants/NSF-123/period-summary?budget_period=1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, service.period)
+		assert.Equal(t, 1, *service.period)
+	})
+
+	t.Run("rejects a non-numeric period", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/grants/NSF-123/period-summary?budget_period=first").Code)
+	})
+
+	t.Run("unknown grant", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/api/v1/grants/NIH-999/period-summary").Code)
+	})
+}
+
+// fakeGrantCostService recomputes the costs of one known grant
+type fakeGrantCostService struct {
+	grantNumber string
+}
+
+func (f *fakeGrantCostService) RecomputeGrantCosts(_ context.Context, grantNumber string) (*api.GrantCostRecomputation, error) {
+	f.grantNumber = grantNumber
+	if grantNumber != "NIH-R01-1" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Grant not found")
+	}
+	return &api.GrantCostRecomputation{
+		Grant: &api.GrantAccount{
+			GrantNumber:         grantNumber,
+			DirectCosts:         1000,
+			ExcludedDirectCosts: 200,
+			IndirectCostRate:    0.5,
+			IndirectCosts:       400,
+		},
+		ExcludedCategories:  []string{"storage"},
+		PreviousDirectCosts: 600,
+	}, nil
+}
+
+func TestHandleRecomputeGrantCosts(t *testing.T) {
+	service := &fakeGrantCostService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/grants/{grant}/recompute-costs", handleRecomputeGrantCosts(service)).Methods("POST")
+
+	post := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+		return rec
+	}
+
+	t.Run("returns the recomputed costs", func(t *testing.T) {
+		rec := post("/api/v1/grants/NIH-R01-1/recompute-costs")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "NIH-R01-1", service.grantNumber)
+
+		var resp api.GrantCostRecomputation
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.NotNil(t, resp.Grant)
+		assert.Equal(t, 1000.0, resp.Grant.DirectCosts)
+		assert.Equal(t, 400.0, resp.Grant.IndirectCosts)
+		assert.Equal(t, 600.0, resp.PreviousDirectCosts)
+		assert.Equal(t, []string{"storage"}, resp.ExcludedCategories)
+	})
+
+	t.Run("unknown grant", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, post("/api/v1/grants/NSF-999/recompute-costs").Code)
+	})
+}
+
+func TestHandleASBAGrantTimeline_PeriodSummary(t *testing.T) {
+	handler := handleASBAGrantTimeline(&fakeGrantTimelineService{})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/grant-timeline",
+		bytes.NewBufferString(`{"grant_number":"NSF-123"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp api.GrantTimelineResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.NotNil(t, resp.Period)
+	assert.Equal(t, "NSF-123", resp.GrantNumber)
+	assert.Equal(t, 2, resp.CurrentPeriod)
+	assert.Equal(t, 150.0, resp.Period.Committed)
+	assert.Equal(t, 400.0, resp.Period.Spent)
+	assert.Equal(t, 450.0, resp.Period.Remaining)
+	assert.InDelta(t, 9, resp.DaysUntilPeriodEnd, 1)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/grant-timeline",
+		bytes.NewBufferString(`{"grant_number":"NIH-999"}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// fakeGrantTimelineService has emergency options only for account critical
+type fakeGrantTimelineService struct {
+	fakeGrantPeriodService
+}
+
+func (f *fakeGrantTimelineService) GetEmergencyOptions(_ context.Context, slurmAccount string) ([]api.EmergencyOption, error) {
+	switch slurmAccount {
+	case "critical":
+		return []api.EmergencyOption{{Option: api.EmergencyOptionReallocate, Account: "sibling", Available: 800, EstimatedCost: 300}}, nil
+	case "missing":
+		return nil, api.NewAccountNotFoundError(slurmAccount)
+	}
+	return []api.EmergencyOption{}, nil
+}
+
+func TestHandleASBAGrantTimeline_EmergencyOptions(t *testing.T) {
+	handler := handleASBAGrantTimeline(&fakeGrantTimelineService{})
+	timeline := func(account string) (*httptest.ResponseRecorder, *api.GrantTimelineResponse) {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/grant-timeline",
+			bytes.NewBufferString(fmt.Sprintf(`{"account":%q}`, account))))
+		var resp api.GrantTimelineResponse
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		}
+		return rec, &resp
+	}
+
+	_, resp := timeline("healthy")
+	assert.Empty(t, resp.EmergencyOptions)
+	assert.NotEqual(t, "CRITICAL", resp.CurrentUrgency)
+
+	_, resp = timeline("critical")
+	require.Len(t, resp.EmergencyOptions, 1)
+	assert.Equal(t, api.EmergencyOptionReallocate, resp.EmergencyOptions[0].Option)
+	assert.Equal(t, "sibling", resp.EmergencyOptions[0].Account)
+	assert.Equal(t, 800.0, resp.EmergencyOptions[0].Available)
+	assert.Equal(t, "CRITICAL", resp.CurrentUrgency)
+
+	rec, _ := timeline("missing")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+type fakeDecisionService struct {
+	last *api.DecisionListRequest
+}
+
+func (f *fakeDecisionService) ListDecisions(_ context.Context, req *api.DecisionListRequest) ([]*api.BudgetDecision, error) {
+	f.last = req
+	if req.Account != "proj001" {
+		return nil, api.NewAccountNotFoundError(req.Account)
+	}
+	return []*api.BudgetDecision{
+		{ID: 2, Decision: api.DecisionRejected, Reason: "Insufficient budget", EstimatedCost: 500},
+		{ID: 1, Decision: api.DecisionApproved, Reason: "Budget check passed", HoldAmount: 12, TransactionID: "txn_1"},
+	}, nil
+}
+
+func TestHandleListDecisions(t *testing.T) {
+	service := &fakeDecisionService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/accounts/{account}/decisions", handleListDecisions(service)).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("filters by date and decision", func(t *testing.T) {
+		rec := get("/api/v1/accounts/proj001/decisions?start_date=2025-01-01&end_date=2025-01-31&decision=rejected&limit=10")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NotNil(t, service.last.StartDate)
+		require.NotNil(t, service.last.EndDate)
+		assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), *service.last.EndDate)
+		assert.Equal(t, "rejected", service.last.Decision)
+		assert.Equal(t, 10, service.last.Limit)
+
+		var decisions []api.BudgetDecision
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &decisions))
+		require.Len(t, decisions, 2)
+		assert.Equal(t, api.DecisionRejected, decisions[0].Decision)
+		assert.Equal(t, "txn_1", decisions[1].TransactionID)
+	})
+
+	t.Run("rejects malformed filters", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/accounts/proj001/decisions?start_date=01/01/2025").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/accounts/proj001/decisions?limit=all").Code)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/api/v1/accounts/nobody/decisions").Code)
+	})
+}
+
+type fakeLimitHistoryService struct{}
+
+func (fakeLimitHistoryService) GetLimitHistory(_ context.Context, slurmAccount string) ([]*api.BudgetLimitChange, error) {
+	if slurmAccount != "proj001" {
+		return nil, api.NewAccountNotFoundError(slurmAccount)
+	}
+	previous := 1000.0
+	return []*api.BudgetLimitChange{
+		{ID: 1, AccountID: 7, BudgetLimit: 1000, EffectiveAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, AccountID: 7, PreviousLimit: &previous, BudgetLimit: 1500, EffectiveAt: time.Date(2025, 2, 15, 9, 0, 0, 0, time.UTC)},
+	}, nil
+}
+
+func TestHandleGetLimitHistory(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/accounts/{account}/limit-history", handleGetLimitHistory(fakeLimitHistoryService{})).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/v1/accounts/proj001/limit-history")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var changes []map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &changes))
+	require.Len(t, changes, 2)
+	assert.NotContains(t, changes[0], "previous_limit")
+	assert.Equal(t, 1000.0, changes[1]["previous_limit"])
+	assert.Equal(t, 1500.0, changes[1]["budget_limit"])
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/accounts/nobody/limit-history").Code)
+}
+
+type fakeReconciliationLookupService struct{}
+
+func (fakeReconciliationLookupService) GetASBXReconciliation(_ context.Context, reconciliationID string) (*api.ASBXReconciliation, error) {
+	if reconciliationID != "asbx_recon_1" {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Reconciliation "+reconciliationID+" not found")
+	}
+	hold := "txn_hold"
+	return &api.ASBXReconciliation{
+		Summary: &api.ASBXReconciliationSummary{
+			ReconciliationID: reconciliationID, JobID: "job-1", OriginalTransaction: hold,
+			EstimatedCost: 10, ActualCost: 8, CostVariance: -2, RefundAmount: 4, ChargedAmount: 8,
+		},
+		Transactions: []*api.BudgetTransaction{
+			{TransactionID: hold, Type: "hold", Amount: 12},
+			{TransactionID: "txn_charge", Type: "charge", Amount: 8, ParentTransactionID: &hold},
+			{TransactionID: "txn_refund", Type: "refund", Amount: 4, ParentTransactionID: &hold},
+		},
+	}, nil
+}
+
+func TestHandleGetReconciliation(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/reconciliations/{id}", handleGetReconciliation(fakeReconciliationLookupService{})).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/v1/reconciliations/asbx_recon_1")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var reconciliation api.ASBXReconciliation
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &reconciliation))
+	require.NotNil(t, reconciliation.Summary)
+	assert.Equal(t, "txn_hold", reconciliation.Summary.OriginalTransaction)
+	assert.InDelta(t, -2.0, reconciliation.Summary.CostVariance, 0.001)
+	require.Len(t, reconciliation.Transactions, 3)
+	assert.Equal(t, "refund", reconciliation.Transactions[2].Type)
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/reconciliations/asbx_recon_2").Code)
+}
+
+type fakeASBAFeedbackService struct {
+	recorded *api.ASBAFeedbackRequest
+	listed   *api.ASBAFeedbackListRequest
+}
+
+func (f *fakeASBAFeedbackService) RecordASBAFeedback(_ context.Context, req *api.ASBAFeedbackRequest) (*api.ASBAFeedback, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	f.recorded = req
+	return &api.ASBAFeedback{ID: 7, DecisionID: req.DecisionID, ActualLocation: req.ActualLocation, ActualCost: req.ActualCost}, nil
+}
+
+func (f *fakeASBAFeedbackService) ListASBAFeedback(_ context.Context, req *api.ASBAFeedbackListRequest) ([]*api.ASBAFeedback, error) {
+	f.listed = req
+	if req.Account != "proj001" {
+		return nil, api.NewAccountNotFoundError(req.Account)
+	}
+	return []*api.ASBAFeedback{{ID: 7, DecisionID: 3, ActualLocation: api.BurstActionAWS}}, nil
+}
+
+func TestHandleASBAFeedback(t *testing.T) {
+	service := &fakeASBAFeedbackService{}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handleASBAFeedback(service)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/feedback", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec := post(`{"account":"proj001","decision_id":3,"recommended_action":"AWS","actual_location":"AWS","actual_cost":14.5,"recommendation_followed":true}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	assert.True(t, service.recorded.RecommendationFollowed)
+	var feedback api.ASBAFeedback
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &feedback))
+	assert.Equal(t, int64(3), feedback.DecisionID)
+	assert.Equal(t, 14.5, feedback.ActualCost)
+
+	assert.Equal(t, http.StatusBadRequest, post(`{"account":"proj001","recommended_action":"AWS","actual_location":"AWS"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"account":"proj001","decision_id":3,"recommended_action":"AWS","actual_location":"MOON"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`not json`).Code)
+}
+
+func TestHandleListASBAFeedback(t *testing.T) {
+	service := &fakeASBAFeedbackService{}
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handleListASBAFeedback(service)(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/api/v1/asba/feedback?account=proj001&limit=5&offset=10")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &api.ASBAFeedbackListRequest{Account: "proj001", Limit: 5, Offset: 10}, service.listed)
+	var feedback []api.ASBAFeedback
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &feedback))
+	require.Len(t, feedback, 1)
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/v1/asba/feedback?account=proj001&limit=all").Code)
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/asba/feedback?account=nobody").Code)
+}
+
+type fakeBurstDecisionService struct{}
+
+func (fakeBurstDecisionService) WeightBurstDecisionFactors(_ context.Context, account string, factors []api.DecisionFactor) ([]api.DecisionFactor, *api.ASBAFeedbackSummary, error) {
+	if account != "proj001" {
+		return nil, nil, api.NewAccountNotFoundError(account)
+	}
+	factors[2].Weight = 0.5
+	return factors, &api.ASBAFeedbackSummary{Outcomes: 4, AWSOutcomes: 4}, nil
+}
+
+func TestHandleASBABurstDecision_WeightsFactors(t *testing.T) {
+	post := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handleASBABurstDecision(fakeBurstDecisionService{})(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/burst-decision", bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec := post(`{"account":"proj001","estimated_aws_cost":100}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var resp api.BurstDecisionResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.DecisionFactors, 3)
+	assert.Equal(t, api.DecisionFactorCostEfficiency, resp.DecisionFactors[2].Factor)
+	assert.Equal(t, 0.5, resp.DecisionFactors[2].Weight)
+	require.NotNil(t, resp.Feedback)
+	assert.Equal(t, 4, resp.Feedback.AWSOutcomes)
+
+	assert.Equal(t, http.StatusNotFound, post(`{"account":"nobody","estimated_aws_cost":100}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post(`{"estimated_aws_cost":100}`).Code)
+}
+
+type fakeScheduleStatusService struct {
+	paused, resumed *api.BulkScheduleStatusRequest
+}
+
+func (f *fakeScheduleStatusService) PauseAllocationSchedules(_ context.Context, req *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error) {
+	f.paused = req
+	return &api.BulkScheduleStatusResponse{Status: "paused", Updated: 2, ScheduleIDs: []int64{1, 2}}, nil
+}
+
+func (f *fakeScheduleStatusService) ResumeAllocationSchedules(_ context.Context, req *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error) {
+	f.resumed = req
+	return &api.BulkScheduleStatusResponse{Status: "active", Updated: 1, ScheduleIDs: []int64{1}}, nil
+}
+
+func TestHandleBulkScheduleStatus(t *testing.T) {
+	service := &fakeScheduleStatusService{}
+
+	rec := httptest.NewRecorder()
+	handlePauseAllocations(service)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/allocations/pause",
+		bytes.NewBufferString(`{"funding_agency":"NSF","cost_center":"CC-1042"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &api.BulkScheduleStatusRequest{FundingAgency: "NSF", CostCenter: "CC-1042"}, service.paused)
+	var resp api.BulkScheduleStatusResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "paused", resp.Status)
+	assert.Equal(t, []int64{1, 2}, resp.ScheduleIDs)
+
+	// An empty body resumes every schedule
+	rec = httptest.NewRecorder()
+	handleResumeAllocations(service)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/allocations/resume", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &api.BulkScheduleStatusRequest{}, service.resumed)
+
+	rec = httptest.NewRecorder()
+	handlePauseAllocations(service)(rec, httptest.NewRequest(http.MethodPost, "/api/v1/admin/allocations/pause", bytes.NewBufferString("{")))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+type fakeAllocationPreviewService struct {
+	count int
+}
+
+func (f *fakeAllocationPreviewService) PreviewAllocations(_ context.Context, slurmAccount string, count int) (*api.AllocationPreviewResponse, error) {
+	f.count = count
+	if slurmAccount != "proj001" {
+		return nil, api.NewAccountNotFoundError(slurmAccount)
+	}
+	return &api.AllocationPreviewResponse{
+		Account:     slurmAccount,
+		Count:       2,
+		TotalAmount: 150,
+		Allocations: []api.ProjectedAllocation{
+			{ScheduleID: 1, AllocationDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Amount: 100, RemainingBudget: 50},
+			{ScheduleID: 1, AllocationDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), Amount: 50, Partial: true},
+		},
+	}, nil
+}
+
+func TestHandleAllocationSchedule(t *testing.T) {
+	service := &fakeAllocationPreviewService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/accounts/{account}/allocations/schedule", handleAllocationSchedule(service)).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("previews allocations", func(t *testing.T) {
+		rec := get("/api/v1/accounts/proj001/allocations/schedule?count=5")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 5, service.count)
+
+		var resp api.AllocationPreviewResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Allocations, 2)
+		assert.True(t, resp.Allocations[1].Partial)
+		assert.Equal(t, 150.0, resp.TotalAmount)
+	})
+
+	t.Run("leaves the default count to the service", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, get("/api/v1/accounts/proj001/allocations/schedule").Code)
+		assert.Equal(t, 0, service.count)
+	})
+
+	t.Run("rejects a bad count", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/accounts/proj001/allocations/schedule?count=many").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/accounts/proj001/allocations/schedule?count=0").Code)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("/api/v1/accounts/nobody/allocations/schedule").Code)
+	})
+}
+
+// fakeAccountScheduleService keeps the schedules added to one known account
+type fakeAccountScheduleService struct {
+	schedules []*api.BudgetAllocationSchedule
+}
+
+func (f *fakeAccountScheduleService) CreateAllocationSchedule(_ context.Context, slurmAccount string, req *api.CreateAllocationScheduleRequest) (*api.BudgetAllocationSchedule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if slurmAccount != "proj001" {
+		return nil, api.NewAccountNotFoundError(slurmAccount)
+	}
+	schedule := &api.BudgetAllocationSchedule{
+		ID:                  int64(len(f.schedules) + 1),
+		TotalBudget:         req.TotalBudget,
+		AllocationAmount:    req.AllocationAmount,
+		AllocationFrequency: req.AllocationFrequency,
+		NextAllocationDate:  req.StartDate,
+		Status:              "active",
+	}
+	f.schedules = append(f.schedules, schedule)
+	return schedule, nil
+}
+
+func (f *fakeAccountScheduleService) ListAccountSchedules(_ context.Context, slurmAccount string) (*api.AccountSchedulesResponse, error) {
+	if slurmAccount != "proj001" {
+		return nil, api.NewAccountNotFoundError(slurmAccount)
+	}
+	resp := &api.AccountSchedulesResponse{Account: slurmAccount}
+	for _, schedule := range f.schedules {
+		resp.Schedules = append(resp.Schedules, api.ScheduleAllocations{
+			Schedule:    schedule,
+			Allocations: []*api.BudgetAllocation{{ScheduleID: schedule.ID, AllocationAmount: schedule.AllocationAmount}},
+		})
+	}
+	return resp, nil
+}
+
+func TestHandleAccountSchedules(t *testing.T) {
+	service := &fakeAccountScheduleService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/accounts/{account}/schedules", handleListAccountSchedules(service)).Methods("GET")
+	router.HandleFunc("/api/v1/accounts/{account}/schedules", handleCreateAllocationSchedule(service)).Methods("POST")
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	t.Run("adds schedules alongside each other", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/api/v1/accounts/proj001/schedules",
+			`{"total_budget":1200,"allocation_amount":100,"allocation_frequency":"monthly","start_date":"2025-01-01T00:00:00Z","auto_allocate":true}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+		rec = serve(http.MethodPost, "/api/v1/accounts/proj001/schedules",
+			`{"total_budget":5000,"allocation_amount":5000,"allocation_frequency":"yearly","start_date":"2025-02-15T00:00:00Z","auto_allocate":true}`)
+		require.Equal(t, http.StatusCreated, rec.Code)
+
+		var schedule api.BudgetAllocationSchedule
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &schedule))
+		assert.Equal(t, int64(2), schedule.ID)
+
+		rec = serve(http.MethodGet, "/api/v1/accounts/proj001/schedules", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var resp api.AccountSchedulesResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		require.Len(t, resp.Schedules, 2)
+		assert.Equal(t, int64(2), resp.Schedules[1].Allocations[0].ScheduleID)
+		assert.Equal(t, 5000.0, resp.Schedules[1].Allocations[0].AllocationAmount)
+	})
+
+	t.Run("rejects invalid schedules", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/api/v1/accounts/proj001/schedules", "{").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/api/v1/accounts/proj001/schedules",
+			`{"total_budget":100,"allocation_amount":200,"allocation_frequency":"hourly","start_date":"2025-01-01T00:00:00Z"}`).Code)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/api/v1/accounts/nobody/schedules", "").Code)
+	})
+}
+
+type fakeAtRiskService struct {
+	withinDays int
+}
+
+func (f *fakeAtRiskService) ListAtRiskAccounts(_ context.Context, withinDays int) (*api.AtRiskAccountsResponse, error) {
+	f.withinDays = withinDays
+	if withinDays == 0 {
+		withinDays = 14
+	}
+	if withinDays < 0 {
+		return nil, api.NewValidationError("within_days", "must be between 1 and 365")
+	}
+	return &api.AtRiskAccountsResponse{
+		WithinDays: withinDays,
+		Accounts: []api.AtRiskAccount{{
+			Account:                "proj001",
+			BudgetLimit:            1000,
+			BudgetAvailable:        500,
+			DailyBurnRate:          100,
+			ProjectedDepletionDate: time.Date(2025, 6, 6, 12, 0, 0, 0, time.UTC),
+			DaysUntilDepletion:     5,
+		}},
+	}, nil
+}
+
+func TestHandleListAtRiskAccounts(t *testing.T) {
+	service := &fakeAtRiskService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/v1/accounts/at-risk", handleListAtRiskAccounts(service)).Methods("GET")
+	router.HandleFunc("/api/v1/accounts/{account}", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("at-risk request routed to account %s", mux.Vars(r)["account"])
+	}).Methods("GET")
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("lists accounts nearing depletion", func(t *testing.T) {
+		rec := get("/api/v1/accounts/at-risk?within_days=30")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 30, service.withinDays)
+
+		var resp api.AtRiskAccountsResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, 30, resp.WithinDays)
+		require.Len(t, resp.Accounts, 1)
+		assert.Equal(t, "proj001", resp.Accounts[0].Account)
+		assert.Equal(t, 500.0, resp.Accounts[0].BudgetAvailable)
+		assert.Equal(t, 100.0, resp.Accounts[0].DailyBurnRate)
+	})
+
+	t.Run("leaves the default window to the service", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, get("/api/v1/accounts/at-risk").Code)
+		assert.Equal(t, 0, service.withinDays)
+	})
+
+	t.Run("rejects a bad window", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/accounts/at-risk?within_days=soon").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/accounts/at-risk?within_days=0").Code)
+		assert.Equal(t, http.StatusBadRequest, get("/api/v1/accounts/at-risk?within_days=-3").Code)
+	})
+}
+
+func TestParseUsageReportRequest(t *testing.T) {
+	req, err := parseUsageReportRequest(httptest.NewRequest(http.MethodGet,
+		"/api/v1/usage/by-component?account=proj001&start_date=2025-09-01&end_date=2025-09-30", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "proj001", req.Account)
+	require.NotNil(t, req.StartDate)
+	require.NotNil(t, req.EndDate)
+	assert.Equal(t, "2025-09-01", req.StartDate.Format("2006-01-02"))
+	assert.Equal(t, "2025-09-30", req.EndDate.Format("2006-01-02"))
+
+	req, err = parseUsageReportRequest(httptest.NewRequest(http.MethodGet, "/api/v1/usage/by-component", nil))
+	require.NoError(t, err)
+	assert.Nil(t, req.StartDate)
+	assert.Nil(t, req.EndDate)
+
+	_, err = parseUsageReportRequest(httptest.NewRequest(http.MethodGet, "/api/v1/usage/by-component?end_date=30/09/2025", nil))
+	assert.Error(t, err)
+}
+
+// fakeMetricsWriter writes fixed metrics, or fails
+// fakeReadinessService answers health checks with err
+type fakeReadinessService struct {
+	err error
+}
+
+func (f *fakeReadinessService) HealthCheck(context.Context) error {
+	return f.err
+}
+
+// fakeWorkerChecker reports a fixed set of workers
+type fakeWorkerChecker []api.WorkerStatus
+
+func (f fakeWorkerChecker) check() []api.WorkerStatus {
+	return f
+}
+
+func TestHandleReady(t *testing.T) {
+	alive := api.WorkerStatus{Name: "allocations", Interval: "5m0s", Alive: true}
+	stalled := api.WorkerStatus{Name: "recovery", Interval: "1m0s", Alive: false}
+
+	get := func(service readinessService, workers workerChecker) (*httptest.ResponseRecorder, api.ReadinessResponse) {
+		rec := httptest.NewRecorder()
+		handleReady(service, workers)(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var resp api.ReadinessResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec, resp
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		rec, resp := get(&fakeReadinessService{}, fakeWorkerChecker{alive})
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, resp.Ready)
+		assert.Equal(t, "ok", resp.Checks["database"])
+		assert.Equal(t, "ok", resp.Checks["workers"])
+		assert.Len(t, resp.Workers, 1)
+	})
+
+	t.Run("stalled worker fails readiness", func(t *testing.T) {
+		rec, resp := get(&fakeReadinessService{}, fakeWorkerChecker{alive, stalled})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.False(t, resp.Ready)
+		assert.Equal(t, "stalled: recovery", resp.Checks["workers"])
+	})
+
+	t.Run("database unavailable", func(t *testing.T) {
+		rec, resp := get(&fakeReadinessService{err: errors.New("connection refused")}, fakeWorkerChecker{})
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.False(t, resp.Ready)
+		assert.Equal(t, "unavailable: connection refused", resp.Checks["database"])
+	})
+}
+
+type fakeMetricsWriter struct {
+	err error
+}
+
+func (f *fakeMetricsWriter) WriteMetrics(w io.Writer) error {
+	if f.err != nil {
+		return f.err
+	}
+	_, err := io.WriteString(w, "asbb_reconciliation_latency_seconds_count 3\n")
+	return err
+}
+
+func TestHandleMetrics(t *testing.T) {
+	t.Run("writes the exposition", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleMetrics(&fakeMetricsWriter{})(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+		assert.Equal(t, "asbb_reconciliation_latency_seconds_count 3\n", rec.Body.String())
+	})
+
+	t.Run("collection failure", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handleMetrics(&fakeMetricsWriter{err: errors.New("boom")})(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
+
+// fakeAdminSummaryService returns a fixed summary
+type fakeAdminSummaryService struct{}
+
+func (f *fakeAdminSummaryService) AdminSummary(_ context.Context) (*api.AdminSummary, error) {
+	return &api.AdminSummary{
+		Accounts: 3, ActiveAccounts: 2, PendingHolds: 4, TotalLimit: 1000, TotalUsed: 250, TotalHeld: 50, TotalAvailable: 700,
+		Database: api.DatabaseStats{MaxOpenConnections: 25, OpenConnections: 2, InUse: 1, Idle: 1, WaitDuration: "0s"},
+	}, nil
+}
+
+func TestHandleAdminSummary(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleAdminSummary(&fakeAdminSummaryService{})(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/summary", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp api.AdminSummary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 4, resp.PendingHolds)
+	assert.InDelta(t, 700.0, resp.TotalAvailable, 0.001)
+	assert.Equal(t, 2, resp.Database.OpenConnections)
+}
+
+// fakeMigrationStatusService returns a fixed migration status, or err when set
+type fakeMigrationStatusService struct {
+	err error
+}
+
+func (f *fakeMigrationStatusService) MigrationStatus(_ context.Context) (*api.MigrationStatus, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &api.MigrationStatus{
+		CurrentVersion: 23,
+		LatestVersion:  24,
+		Applied:        []api.Migration{{Version: 23, Name: "account_estimation_source"}},
+		Pending:        []api.Migration{{Version: 24, Name: "cost_sharing"}},
+	}, nil
+}
+
+func TestHandleMigrationStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleMigrationStatus(&fakeMigrationStatusService{})(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/migrations", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp api.MigrationStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, uint(23), resp.CurrentVersion)
+	assert.False(t, resp.UpToDate)
+	assert.Equal(t, []api.Migration{{Version: 24, Name: "cost_sharing"}}, resp.Pending)
+
+	rec = httptest.NewRecorder()
+	failing := &fakeMigrationStatusService{err: api.NewDatabaseError("read schema version", errors.New("connection refused"))}
+	handleMigrationStatus(failing)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/admin/migrations", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestAdminEffectiveConfig(t *testing.T) {
+	cfg := &config.Config{
+		Database:    config.DatabaseConfig{DSN: "host=db user=asbb password=hunter2 dbname=asbb"},
+		Integration: config.IntegrationConfig{ASBXAPIKey: "asbx-key", FallbackCostRate: 0.25, FailureMode: "GRACEFUL"},
+		Auth:        config.AuthConfig{JWTSecret: "jwt-secret", APIKeys: []string{"user-key"}, AdminAPIKeys: []string{"admin-key"}},
+	}
+
+	router := mux.NewRouter()
+	admin := router.PathPrefix("/api/v1/admin").Subrouter()
+	admin.Use(adminAuthMiddleware(cfg.Auth.AdminAPIKeys))
+	admin.HandleFunc("/config", handleEffectiveConfig(cfg)).Methods("GET")
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/config", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve("admin-key")
+	require.Equal(t, http.StatusOK, rec.Code)
+	for _, secret := range []string{"hunter2", "asbx-key", "jwt-secret", "user-key", "admin-key"} {
+		assert.NotContains(t, rec.Body.String(), secret)
+	}
+
+	var resp api.EffectiveConfig
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	integration := resp.Settings["integration"].(map[string]interface{})
+	assert.Equal(t, 0.25, integration["fallback_cost_rate"])
+	assert.Equal(t, "GRACEFUL", integration["failure_mode"])
+	assert.Equal(t, "***", integration["asbx_api_key"])
+	assert.Equal(t, "host=db user=asbb password=*** dbname=asbb", resp.Settings["database"].(map[string]interface{})["dsn"])
+
+	assert.Equal(t, http.StatusUnauthorized, serve("").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve("user-key").Code)
+}
+
+// fakeOverviewService records the overview request it was given
+type fakeOverviewService struct {
+	req *api.OverviewRequest
+}
+
+func (f *fakeOverviewService) Overview(_ context.Context, req *api.OverviewRequest) (*api.Overview, error) {
+	f.req = req
+	return &api.Overview{
+		FundingAgency: req.FundingAgency,
+		CostCenter:    req.CostCenter,
+		Totals:        api.OverviewTotals{Accounts: 2, TotalAllocated: 1000, TotalSpent: 250, TotalHeld: 50, Utilization: 30},
+	}, nil
+}
+
+func TestHandleOverview(t *testing.T) {
+	service := &fakeOverviewService{}
+	rec := httptest.NewRecorder()
+	handleOverview(service)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/overview?agency=NSF&cost_center=CC-42&tag=kind=course", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &api.OverviewRequest{FundingAgency: "NSF", CostCenter: "CC-42", Tags: []string{"kind=course"}}, service.req)
+
+	var resp api.Overview
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "NSF", resp.FundingAgency)
+	assert.InDelta(t, 30.0, resp.Totals.Utilization, 0.001)
+}
+
+func TestParseListAccountsRequest(t *testing.T) {
+	req := parseListAccountsRequest(httptest.NewRequest(http.MethodGet,
+		"/api/v1/accounts?status=active&limit=10&tag=department=physics&tag=course", nil))
+	assert.Equal(t, &api.ListAccountsRequest{
+		Limit:  10,
+		Status: "active",
+		Tags:   []string{"department=physics", "course"},
+	}, req)
+
+	req = parseListAccountsRequest(httptest.NewRequest(http.MethodGet, "/api/v1/accounts", nil))
+	assert.Empty(t, req.Tags)
+}
+
+// fakeGrantDeadlineService holds NSF-1's deadlines and its one stored report, report 3
+type fakeGrantDeadlineService struct {
+	deadlines []*api.GrantDeadline
+}
+
+func (f *fakeGrantDeadlineService) CreateGrantDeadline(_ context.Context, grantNumber string, req *api.CreateGrantDeadlineRequest) (*api.GrantDeadline, error) {
+	deadline := &api.GrantDeadline{ID: int64(len(f.deadlines) + 1), GrantNumber: grantNumber, Type: req.Type, DueDate: req.DueDate}
+	f.deadlines = append(f.deadlines, deadline)
+	return deadline, nil
+}
+
+func (f *fakeGrantDeadlineService) ListGrantDeadlines(_ context.Context, grantNumber string) ([]*api.GrantDeadline, error) {
+	return f.deadlines, nil
+}
+
+func (f *fakeGrantDeadlineService) GetGrantReport(_ context.Context, grantNumber string, reportID int64) (*api.StoredGrantReport, error) {
+	if grantNumber != "NSF-1" || reportID != 3 {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Report not found")
+	}
+	return &api.StoredGrantReport{
+		ID:          3,
+		GrantNumber: "NSF-1",
+		ReportType:  "financial",
+		Report:      json.RawMessage(`{"grant_number":"NSF-1","total_spent":120.5}`),
+	}, nil
+}
+
+func TestGrantDeadlines(t *testing.T) {
+	service := &fakeGrantDeadlineService{}
+	router := mux.NewRouter()
+	router.Use(userAuthMiddleware(config.AuthConfig{Enabled: true, AdminAPIKeys: []string{"admin-key"}}))
+	router.HandleFunc("/api/v1/grants/{grant}/deadlines", handleListGrantDeadlines(service)).Methods("GET")
+	router.HandleFunc("/api/v1/grants/{grant}/deadlines", handleCreateGrantDeadline(service)).Methods("POST")
+	router.HandleFunc("/api/v1/grants/{grant}/reports/{report}", handleGetGrantReport(service)).Methods("GET")
+
+	// do sends a request as user, or with the admin API key when user is empty
+	do := func(method, path, user, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if user == "" {
+			req.Header.Set("Authorization", "Bearer admin-key")
+		} else {
+			req.Header.Set(userHeader, user)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{"type":"GRANT_REPORT","due_date":"2026-01-31T00:00:00Z"}`
+	assert.Equal(t, http.StatusForbidden, do(http.MethodPost, "/api/v1/grants/NSF-1/deadlines", "alice", body).Code)
+	rec := do(http.MethodPost, "/api/v1/grants/NSF-1/deadlines", "", body)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Len(t, service.deadlines, 1)
+	assert.Equal(t, api.DeadlineTypeGrantReport, service.deadlines[0].Type)
+
+	rec = do(http.MethodGet, "/api/v1/grants/NSF-1/deadlines", "alice", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var deadlines []api.GrantDeadline
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &deadlines))
+	assert.Len(t, deadlines, 1)
+
+	rec = do(http.MethodGet, "/api/v1/grants/NSF-1/reports/3", "alice", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"grant_number":"NSF-1","total_spent":120.5}`, rec.Body.String())
+	assert.Equal(t, `attachment; filename="grant-NSF-1-report-3.json"`, rec.Header().Get("Content-Disposition"))
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/grants/NSF-1/reports/4", "alice", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/api/v1/grants/NSF-1/reports/latest", "alice", "").Code)
+}
+
+// fakeDeadLetterService holds one dead-lettered reconciliation, 7, and records how it was closed
+type fakeDeadLetterService struct {
+	resolution string
+	req        *api.ResolveDeadLetterRequest
+}
+
+func (f *fakeDeadLetterService) ListDeadLetters(_ context.Context) ([]*api.ReconciliationDeadLetter, error) {
+	return []*api.ReconciliationDeadLetter{{ID: 7, TransactionID: "txn_7", Attempts: 5, Reason: "Transaction txn_7 not found"}}, nil
+}
+
+func (f *fakeDeadLetterService) ReplayDeadLetter(_ context.Context, id int64, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error) {
+	return f.close(id, api.DeadLetterReplayed, req)
+}
+
+func (f *fakeDeadLetterService) ResolveDeadLetter(_ context.Context, id int64, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error) {
+	return f.close(id, api.DeadLetterResolved, req)
+}
+
+func (f *fakeDeadLetterService) close(id int64, resolution string, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error) {
+	if id != 7 {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Dead-lettered reconciliation not found")
+	}
+	f.resolution, f.req = resolution, req
+	return &api.ReconciliationDeadLetter{ID: 7, TransactionID: "txn_7", Resolution: resolution, ResolvedBy: req.ResolvedBy}, nil
+}
+
+func TestDeadLetters(t *testing.T) {
+	service := &fakeDeadLetterService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/reconciliation/dead-letter", handleListDeadLetters(service)).Methods("GET")
+	router.HandleFunc("/admin/reconciliation/dead-letter/{id}/replay", handleReplayDeadLetter(service)).Methods("POST")
+	router.HandleFunc("/admin/reconciliation/dead-letter/{id}/resolve", handleResolveDeadLetter(service)).Methods("POST")
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/admin/reconciliation/dead-letter", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var letters []api.ReconciliationDeadLetter
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &letters))
+	require.Len(t, letters, 1)
+	assert.Equal(t, "txn_7", letters[0].TransactionID)
+
+	// A replay needs no body
+	rec = do(http.MethodPost, "/admin/reconciliation/dead-letter/7/replay", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, api.DeadLetterReplayed, service.resolution)
+
+	rec = do(http.MethodPost, "/admin/reconciliation/dead-letter/7/resolve", `{"resolved_by":"ops","note":"refunded by hand"}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, api.DeadLetterResolved, service.resolution)
+	assert.Equal(t, "refunded by hand", service.req.Note)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/admin/reconciliation/dead-letter/8/replay", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/admin/reconciliation/dead-letter/x/resolve", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/admin/reconciliation/dead-letter/7/resolve", "{").Code)
+}
+
+// fakeNotificationService holds one failed notification, 3, and marks it delivered on retry
+type fakeNotificationService struct {
+	status string
+}
+
+func (f *fakeNotificationService) ListNotifications(_ context.Context, status string) ([]*api.NotificationRecord, error) {
+	f.status = status
+	return []*api.NotificationRecord{{ID: 3, Recipient: "pi@example.edu", Status: api.NotificationFailed, Attempts: 5, LastError: "webhook returned 503"}}, nil
+}
+
+func (f *fakeNotificationService) RetryNotification(_ context.Context, id int64) (*api.NotificationRecord, error) {
+	if id != 3 {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, "Failed notification not found")
+	}
+	return &api.NotificationRecord{ID: 3, Recipient: "pi@example.edu", Status: api.NotificationDelivered, Attempts: 6}, nil
+}
+
+func TestNotifications(t *testing.T) {
+	service := &fakeNotificationService{}
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/notifications", handleListNotifications(service)).Methods("GET")
+	router.HandleFunc("/admin/notifications/{id}/retry", handleRetryNotification(service)).Methods("POST")
+
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/admin/notifications?status=failed")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, api.NotificationFailed, service.status)
+	var notifications []api.NotificationRecord
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &notifications))
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "webhook returned 503", notifications[0].LastError)
+
+	rec = do(http.MethodPost, "/admin/notifications/3/retry")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var notification api.NotificationRecord
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &notification))
+	assert.Equal(t, api.NotificationDelivered, notification.Status)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/admin/notifications/4/retry").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/admin/notifications/x/retry").Code)
+}
+
+// fakeAccountBalanceService holds one account, lab, with $600 of a $1000 limit spent or held
+type fakeAccountBalanceService struct{}
+
+func (fakeAccountBalanceService) GetAccountBalance(_ context.Context, slurmAccount string) (*api.BudgetAccount, error) {
+	if slurmAccount != "lab" {
+		return nil, api.NewAccountNotFoundError(slurmAccount)
+	}
+	return &api.BudgetAccount{SlurmAccount: "lab", BudgetLimit: 1000, BudgetUsed: 500, BudgetHeld: 100}, nil
+}
+
+func TestHandleASBABudgetStatus(t *testing.T) {
+	handler := handleASBABudgetStatus(fakeAccountBalanceService{})
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/budget-status", bytes.NewBufferString(`{"account":"lab"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp api.BudgetStatusResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 1000.0, resp.BudgetLimit)
+	assert.Equal(t, 500.0, resp.BudgetUsed)
+	assert.Equal(t, 100.0, resp.BudgetHeld)
+	assert.Equal(t, 400.0, resp.BudgetAvailable)
+	assert.InDelta(t, 50.0, resp.BudgetUtilization, 0.001)
+	assert.True(t, resp.CanAffordAWSBurst)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/budget-status", bytes.NewBufferString(`{"account":"other"}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/budget-status", bytes.NewBufferString(`{}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandleASBAAffordabilityCheck(t *testing.T) {
+	handler := handleASBAAffordabilityCheck(fakeAccountBalanceService{})
+	check := func(cost string) api.AffordabilityCheckResponse {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodPost, "/api/v1/asba/affordability-check",
+			bytes.NewBufferString(`{"account":"lab","estimated_aws_cost":`+cost+`}`)))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var resp api.AffordabilityCheckResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	resp := check("100")
+	assert.True(t, resp.Affordable)
+	assert.InDelta(t, 25.0, resp.BudgetImpact, 0.001)
+
+	resp = check("450")
+	assert.False(t, resp.Affordable)
+	assert.Equal(t, "LOCAL", resp.RecommendedDecision)
+}
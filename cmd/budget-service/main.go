@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,9 +18,12 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/notify"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/version"
 )
 
@@ -32,6 +36,7 @@ func main() {
 
 	// Setup logging
 	setupLogging(&cfg.Logging)
+	api.SetCurrency(cfg.Budget.MoneyCurrency())
 
 	log.Info().
 		Str("version", version.Version).
@@ -63,16 +68,56 @@ func main() {
 
 	// Initialize budget service
 	budgetService := budget.NewService(db, advisorClient, &cfg.Budget)
+	budgetService.SetFailureMode(cfg.Integration.FailureMode)
+	budgetService.SetStaticCostRate(cfg.Integration.FallbackCostRate)
+	budgetService.SetFallbackRates(budget.FallbackRates{
+		CostRate:             cfg.Integration.FallbackCostRate,
+		PartitionMultipliers: cfg.Integration.FallbackPartitionMultipliers,
+	})
+	budgetService.SetAdvisorDivergence(cfg.Integration.AdvisorDivergenceRatio, cfg.Integration.AdvisorDivergencePolicy)
+	budgetService.SetInvalidEstimatePolicy(cfg.Integration.InvalidEstimatePolicy, cfg.Integration.InvalidEstimateMinimum)
+	budgetService.SetAccountLabelLimit(cfg.Metrics.AccountLabelLimit)
+
+	// Notify alerts through the webhook relay, when one is configured
+	if cfg.Integration.NotificationWebhookURL != "" {
+		budgetService.SetNotifier(notify.NewWebhook(cfg.Integration.NotificationWebhookURL, cfg.Integration.NotificationTimeout))
+	}
+
+	// Initialize ASBX integration service; the ASBX endpoints answer 503 without it
+	var asbxService *asbx.IntegrationService
+	if cfg.Integration.ASBXEnabled {
+		asbxService = asbx.NewIntegrationService(budgetService, &asbx.IntegrationConfig{
+			Enabled:               true,
+			ASBXEndpoint:          cfg.Integration.ASBXEndpoint,
+			AutoReconcile:         true,
+			ReconciliationTimeout: cfg.Integration.ASBXTimeout,
+			MaxRetries:            cfg.Integration.RetryAttempts,
+
+			VarianceWarningPct:       cfg.Integration.VarianceWarningPct,
+			VarianceWarningMinAmount: cfg.Integration.VarianceWarningMinAmount,
+			UnheldJobPolicy:          cfg.Integration.UnheldJobPolicy,
+		})
+
+		// Ask ASBX for late job costs before recovery cancels orphaned holds
+		if cfg.Integration.ASBXHoldCallback {
+			budgetService.SetHoldExpiryChecker(
+				asbx.NewClient(cfg.Integration.ASBXEndpoint, cfg.Integration.ASBXAPIKey),
+				cfg.Integration.ASBXHoldCallbackTimeout)
+		}
+	}
+
+	// Background workers record a heartbeat each tick, which readiness checks
+	workers := newWorkerMonitor()
 
 	// Setup HTTP server
 	router := mux.NewRouter()
-	setupRoutes(router, budgetService, cfg)
+	setupRoutes(router, budgetService, asbxService, workers, cfg)
 
 	server := &http.Server{
 		Addr:         cfg.Service.ListenAddr,
 		Handler:      router,
 		ReadTimeout:  cfg.Service.ReadTimeout,
-		WriteTimeout: cfg.Service.WriteTimeout,
+		WriteTimeout: newRouteTimeouts(&cfg.Service).writeTimeout(cfg.Service.WriteTimeout),
 	}
 
 	// Start server in background
@@ -93,20 +138,94 @@ func main() {
 
 	// Start background recovery process
 	if cfg.Budget.AutoRecoveryEnabled {
-		go func() {
-			ticker := time.NewTicker(cfg.Budget.RecoveryCheckInterval)
-			defer ticker.Stop()
-
-			for range ticker.C {
-				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-				if err := budgetService.RecoverOrphanedTransactions(ctx); err != nil {
-					log.Error().Err(err).Msg("Failed to recover orphaned transactions")
-				}
-				cancel()
+		workers.start("recovery", cfg.Budget.RecoveryCheckInterval, 30*time.Second, func(ctx context.Context) {
+			if err := budgetService.RecoverOrphanedTransactions(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to recover orphaned transactions")
+			}
+		})
+	}
+
+	// Start background budget alert evaluation
+	if cfg.Budget.AlertCheckInterval > 0 {
+		workers.start("alerts", cfg.Budget.AlertCheckInterval, 5*time.Minute, func(ctx context.Context) {
+			if err := budgetService.EvaluateBudgetAlerts(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to evaluate budget alerts")
+			}
+			if err := budgetService.EnforceDepletionPolicy(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to enforce depletion policy")
+			}
+		})
+	}
+
+	// Send the queued notifications, trying failed posts again until they are given up on
+	if cfg.Integration.NotificationWebhookURL != "" && cfg.Budget.NotificationSendInterval > 0 {
+		workers.start("notifications", cfg.Budget.NotificationSendInterval, 5*time.Minute, func(ctx context.Context) {
+			if _, err := budgetService.SendQueuedNotifications(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to send queued notifications")
+			}
+		})
+	}
+
+	// Queue each recipient one digest of the alerts held for them
+	if cfg.Integration.NotificationWebhookURL != "" && cfg.Budget.NotificationMode == "DIGEST" {
+		workers.start("notification-digests", cfg.Budget.NotificationDigestInterval, 5*time.Minute, func(ctx context.Context) {
+			if _, err := budgetService.FlushNotificationDigests(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to queue notification digests")
+			}
+		})
+	}
+
+	// Start background incremental allocations
+	if cfg.Integration.AllocationSchedulingEnabled && cfg.Budget.AllocationCheckInterval > 0 {
+		workers.start("allocations", cfg.Budget.AllocationCheckInterval, 5*time.Minute, func(ctx context.Context) {
+			if _, err := budgetService.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{}); err != nil {
+				log.Error().Err(err).Msg("Failed to process pending allocations")
+			}
+		})
+	}
+
+	// Generate financial reports ahead of grant reporting deadlines
+	if cfg.Budget.GrantReportCheckInterval > 0 {
+		workers.start("grant-reports", cfg.Budget.GrantReportCheckInterval, 5*time.Minute, func(ctx context.Context) {
+			if _, err := budgetService.GenerateDueGrantReports(ctx, time.Now()); err != nil {
+				log.Error().Err(err).Msg("Failed to generate scheduled grant reports")
+			}
+		})
+	}
+
+	// Refresh the per-account metrics on an interval rather than per request
+	if cfg.Metrics.Enabled && cfg.Metrics.CollectInterval > 0 {
+		workers.start("metrics", cfg.Metrics.CollectInterval, 30*time.Second, func(ctx context.Context) {
+			if err := budgetService.CollectMetrics(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to collect metrics")
 			}
-		}()
+		})
 	}
 
+	// Capture nightly budget snapshots for point-in-time reporting
+	workers.register("snapshots", 24*time.Hour, 5*time.Minute)
+	go func() {
+		for {
+			now := time.Now().UTC()
+			nextMidnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			time.Sleep(time.Until(nextMidnight))
+
+			// Snapshot the day that just ended
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			if err := budgetService.CaptureDailySnapshots(ctx, nextMidnight.Add(-time.Second)); err != nil {
+				log.Error().Err(err).Msg("Failed to capture budget snapshots")
+			}
+			if err := budgetService.RecordDailyBurnRates(ctx, nextMidnight.Add(-time.Second)); err != nil {
+				log.Error().Err(err).Msg("Failed to record daily burn rates")
+			}
+			cancel()
+			workers.beat("snapshots")
+		}
+	}()
+
+	// Alert on stalled workers even when nothing probes readiness
+	workers.watch(time.Minute)
+
 	// Wait for interrupt signal
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -145,7 +264,11 @@ func setupLogging(cfg *config.LoggingConfig) {
 	}
 }
 
-func setupRoutes(router *mux.Router, service *budget.Service, cfg *config.Config) {
+// accountListV1Sunset is when the v1 account list, a bare array, is to be removed in favor
+// of the paginated v2 list
+var accountListV1Sunset = time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+func setupRoutes(router *mux.Router, service *budget.Service, asbxService *asbx.IntegrationService, workers *workerMonitor, cfg *config.Config) {
 	// Setup CORS if enabled
 	if cfg.Service.CORSEnabled {
 		router.Use(corsMiddleware(cfg.Service.CORSOrigins))
@@ -154,37 +277,142 @@ func setupRoutes(router *mux.Router, service *budget.Service, cfg *config.Config
 	// Add request logging middleware
 	router.Use(loggingMiddleware)
 
+	// Compress inside the logger, so it logs the status the compressor passes on
+	if cfg.Service.CompressionEnabled {
+		router.Use(compressionMiddleware(cfg.Service.CompressionMinSize))
+	}
+
+	// Cut off requests at their route's timeout, inside the compressor so the 503 is
+	// compressed and logged like any other response
+	router.Use(timeoutMiddleware(newRouteTimeouts(&cfg.Service)))
+
+	// Log request and response bodies at debug level when asked to, inside the timeout so a
+	// request cut off is not logged while its handler still reads the body. Never done in
+	// production, where bodies may hold personal data and credentials.
+	if cfg.Logging.LogBodies {
+		if cfg.IsProduction() {
+			log.Warn().Msg("logging.log_bodies is ignored in production")
+		} else {
+			router.Use(bodyLoggingMiddleware(newBodyLogger(&cfg.Logging)))
+		}
+	}
+
 	// API v1 routes
 	api := router.PathPrefix("/api/v1").Subrouter()
 
+	// API v2 routes, only for endpoints whose v2 shape differs; clients may also ask a v1
+	// route for its v2 shape with the application/vnd.asbb.v2+json media type
+	apiV2Router := router.PathPrefix("/api/v2").Subrouter()
+	apiV2Router.Use(pinAPIVersion(apiV2))
+
+	// With auth enabled, identify who each request acts for; budget checks and
+	// reconciliations are then limited to the accounts the user is a member of
+	api.Use(userAuthMiddleware(cfg.Auth))
+	apiV2Router.Use(userAuthMiddleware(cfg.Auth))
+
 	// Budget operations
 	api.HandleFunc("/budget/check", handleBudgetCheck(service)).Methods("POST")
+	api.HandleFunc("/estimate", handleEstimate(service)).Methods("POST")
 	api.HandleFunc("/budget/reconcile", handleJobReconcile(service)).Methods("POST")
+	api.HandleFunc("/budget/reconcile/preview", handleReconcilePreview(service)).Methods("POST")
+	api.HandleFunc("/jobs/validate", handleValidateJob(service)).Methods("POST")
+	api.HandleFunc("/jobs/{job_id}/started", handleJobStarted(service)).Methods("POST")
 
 	// Account management
-	api.HandleFunc("/accounts", handleListAccounts(service)).Methods("GET")
+	handleVersioned(api, apiV2Router, "/accounts",
+		versioned(handleListAccounts(service), handleListAccountsV2(service)).
+			deprecateV1(accountListV1Sunset, "/api/v2/accounts"),
+		"GET")
 	api.HandleFunc("/accounts", handleCreateAccount(service)).Methods("POST")
+	api.HandleFunc("/accounts/bulk", handleBulkCreateAccounts(service)).Methods("POST")
+	api.HandleFunc("/accounts/at-risk", handleListAtRiskAccounts(service)).Methods("GET")
 	api.HandleFunc("/accounts/{account}", handleGetAccount(service)).Methods("GET")
 	api.HandleFunc("/accounts/{account}", handleUpdateAccount(service)).Methods("PUT")
 	api.HandleFunc("/accounts/{account}", handleDeleteAccount(service)).Methods("DELETE")
+	api.HandleFunc("/accounts/{account}/clone", handleCloneAccount(service)).Methods("POST")
+	api.HandleFunc("/accounts/{account}/members", handleListAccountMembers(service)).Methods("GET")
+	api.HandleFunc("/accounts/{account}/members/{user}", handleAddAccountMember(service)).Methods("PUT")
+	api.HandleFunc("/accounts/{account}/members/{user}", handleRemoveAccountMember(service)).Methods("DELETE")
+	api.HandleFunc("/accounts/{account}/snapshot", handleGetSnapshot(service)).Methods("GET")
+	api.HandleFunc("/accounts/{account}/limit-history", handleGetLimitHistory(service)).Methods("GET")
+	api.HandleFunc("/accounts/{account}/adjustments", handleAdjustBudget(service)).Methods("POST")
+	api.HandleFunc("/accounts/{account}/burn-rate/backfill", handleBurnRateBackfill(service)).Methods("POST")
+	api.HandleFunc("/accounts/{account}/simulate", handleSimulateBudget(service)).Methods("POST")
+	api.HandleFunc("/accounts/{account}/decisions", handleListDecisions(service)).Methods("GET")
+	api.HandleFunc("/accounts/{account}/allocations/schedule", handleAllocationSchedule(service)).Methods("GET")
+	api.HandleFunc("/accounts/{account}/schedules", handleListAccountSchedules(service)).Methods("GET")
+	api.HandleFunc("/accounts/{account}/schedules", handleCreateAllocationSchedule(service)).Methods("POST")
+
+	// User views
+	api.HandleFunc("/users/{user}/accounts", handleListUserAccounts(service)).Methods("GET")
+
+	// Grant reporting
+	api.HandleFunc("/grants/{grant}/report", handleGrantReport(service)).Methods("GET")
+	api.HandleFunc("/grants/{grant}/period-summary", handleGrantPeriodSummary(service)).Methods("GET")
+	api.HandleFunc("/grants/{grant}/recompute-costs", handleRecomputeGrantCosts(service)).Methods("POST")
+	api.HandleFunc("/grants/{grant}/deadlines", handleListGrantDeadlines(service)).Methods("GET")
+	api.HandleFunc("/grants/{grant}/deadlines", handleCreateGrantDeadline(service)).Methods("POST")
+	api.HandleFunc("/grants/{grant}/reports/{report}", handleGetGrantReport(service)).Methods("GET")
 
 	// Transaction management
-	api.HandleFunc("/transactions", handleListTransactions(service)).Methods("GET")
+	handleVersioned(api, apiV2Router, "/transactions",
+		versioned(handleListTransactions(service), handleListTransactionsV2(service)), "GET")
+	api.HandleFunc("/reconciliation/pending", handleListPendingReconciliations(service)).Methods("GET")
+	api.HandleFunc("/reconciliation/sacct", handleReconcileAccountingJobs(service)).Methods("POST")
+	api.HandleFunc("/reconciliations/{id}", handleGetReconciliation(service)).Methods("GET")
+	api.HandleFunc("/epilog/env", handleEpilogEnv(service, &cfg.Integration)).Methods("POST")
+
+	// Usage reporting
+	api.HandleFunc("/usage/by-component", handleUsageByComponent(service)).Methods("GET")
+	api.HandleFunc("/usage/by-fiscal-quarter", handleUsageByFiscalQuarter(service)).Methods("GET")
+	api.HandleFunc("/overview", handleOverview(service)).Methods("GET")
+
+	// Incremental allocations
+	api.HandleFunc("/allocations/process", handleProcessAllocations(service)).Methods("POST")
 
 	// ASBX Integration endpoints
-	api.HandleFunc("/asbx/reconcile", handleASBXReconciliation(service)).Methods("POST")
-	api.HandleFunc("/asbx/epilog", handleASBXEpilog(service)).Methods("POST")
-	api.HandleFunc("/asbx/status", handleASBXStatus(service)).Methods("GET")
+	var integration asbxIntegration
+	if asbxService != nil {
+		integration = asbxService
+	}
+	api.HandleFunc("/asbx/reconcile", handleASBXReconciliation(integration, &cfg.Integration)).Methods("POST")
+	api.HandleFunc("/asbx/epilog", handleASBXEpilog(integration, &cfg.Integration)).Methods("POST")
+	api.HandleFunc("/asbx/status", handleASBXStatus(integration)).Methods("GET")
 
 	// ASBA Integration endpoints (Issues #2 and #3)
 	api.HandleFunc("/asba/budget-status", handleASBABudgetStatus(service)).Methods("POST")
 	api.HandleFunc("/asba/affordability-check", handleASBAAffordabilityCheck(service)).Methods("POST")
 	api.HandleFunc("/asba/grant-timeline", handleASBAGrantTimeline(service)).Methods("POST")
 	api.HandleFunc("/asba/burst-decision", handleASBABurstDecision(service)).Methods("POST")
+	api.HandleFunc("/asba/feedback", handleASBAFeedback(service)).Methods("POST")
+	api.HandleFunc("/asba/feedback", handleListASBAFeedback(service)).Methods("GET")
+
+	// Administrative operations
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(adminAuthMiddleware(cfg.Auth.AdminAPIKeys))
+	admin.HandleFunc("/orphaned-holds", handleListOrphanedHolds(service)).Methods("GET")
+	admin.HandleFunc("/recover", handleRecoverOrphanedHolds(service)).Methods("POST")
+	admin.HandleFunc("/reconciliation/dead-letter", handleListDeadLetters(service)).Methods("GET")
+	admin.HandleFunc("/reconciliation/dead-letter/{id}/replay", handleReplayDeadLetter(service)).Methods("POST")
+	admin.HandleFunc("/reconciliation/dead-letter/{id}/resolve", handleResolveDeadLetter(service)).Methods("POST")
+	admin.HandleFunc("/notifications", handleListNotifications(service)).Methods("GET")
+	admin.HandleFunc("/notifications/{id}/retry", handleRetryNotification(service)).Methods("POST")
+	admin.HandleFunc("/allocations/pause", handlePauseAllocations(service)).Methods("POST")
+	admin.HandleFunc("/allocations/resume", handleResumeAllocations(service)).Methods("POST")
+	admin.HandleFunc("/summary", handleAdminSummary(service)).Methods("GET")
+	admin.HandleFunc("/migrations", handleMigrationStatus(service)).Methods("GET")
+	admin.HandleFunc("/config", handleEffectiveConfig(cfg)).Methods("GET")
+	admin.HandleFunc("/consistency", handleCheckConsistency(service)).Methods("GET")
+	admin.HandleFunc("/consistency/repair", handleRepairConsistency(service)).Methods("POST")
+	admin.HandleFunc("/accounts/{source}/transfer-to/{dest}", handleTransferAccount(service)).Methods("POST")
+	admin.HandleFunc("/holds/{transaction_id}/adjust", handleAdjustHold(service)).Methods("POST")
+	admin.HandleFunc("/accounts/import", handleImportAccount(service)).Methods("POST")
+	admin.HandleFunc("/accounts/{account}/export", handleExportAccount(service)).Methods("GET")
 
 	// Health and metrics
 	router.HandleFunc("/health", handleHealth(service)).Methods("GET")
-	router.HandleFunc("/metrics", handleMetrics()).Methods("GET")
+	router.HandleFunc("/readyz", handleReady(service, workers)).Methods("GET")
+	router.HandleFunc("/metrics", handleMetrics(service)).Methods("GET")
 
 	// Version information
 	router.HandleFunc("/version", handleVersion()).Methods("GET")
@@ -220,6 +448,30 @@ func corsMiddleware(origins []string) mux.MiddlewareFunc {
 	}
 }
 
+// adminAuthMiddleware requires a bearer token from the configured admin API keys. With no
+// keys configured every request is refused.
+func adminAuthMiddleware(keys []string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(keys) == 0 {
+				writeError(w, api.NewBudgetError(api.ErrCodeForbidden, "Admin API is disabled: no admin API keys are configured"))
+				return
+			}
+
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || token == "" {
+				writeError(w, api.ErrUnauthorized)
+				return
+			}
+			if !matchesKey(token, keys) {
+				writeError(w, api.ErrUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
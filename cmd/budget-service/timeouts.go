@@ -0,0 +1,142 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// longRequestTimeout is the built-in timeout of routes that generate reports or work
+// through batches, which legitimately run far longer than a budget check
+const longRequestTimeout = 2 * time.Minute
+
+// longRoutes are the routes given longRequestTimeout unless service.route_timeouts says
+// otherwise
+var longRoutes = []string{
+	"/api/v1/grants/{grant}/report",
+	"/api/v1/grants/{grant}/recompute-costs",
+	"/api/v1/reconciliation/sacct",
+	"/api/v1/accounts/bulk",
+	"/api/v1/accounts/{account}/burn-rate/backfill",
+	"/api/v1/allocations/process",
+	"/api/v1/admin/recover",
+	"/api/v1/admin/consistency",
+	"/api/v1/admin/consistency/repair",
+	"/api/v1/admin/accounts/import",
+	"/api/v1/admin/accounts/{account}/export",
+}
+
+// routeTimeouts resolves the timeout of routes by path template: the configured one, else
+// the built-in one, else service.request_timeout
+type routeTimeouts struct {
+	defaultTimeout time.Duration
+	routes         map[string]time.Duration
+}
+
+// newRouteTimeouts builds the route timeouts from the service configuration
+func newRouteTimeouts(cfg *config.ServiceConfig) *routeTimeouts {
+	routes := make(map[string]time.Duration, len(longRoutes)+len(cfg.RouteTimeouts))
+	for _, route := range longRoutes {
+		routes[route] = longRequestTimeout
+	}
+	for route, timeout := range cfg.RouteTimeouts {
+		routes[route] = timeout
+	}
+	return &routeTimeouts{defaultTimeout: cfg.RequestTimeout, routes: routes}
+}
+
+// forRoute returns a route's timeout; zero means none
+func (rt *routeTimeouts) forRoute(template string) time.Duration {
+	if timeout, ok := rt.routes[template]; ok {
+		return timeout
+	}
+	return rt.defaultTimeout
+}
+
+// longest returns the longest timeout any route has
+func (rt *routeTimeouts) longest() time.Duration {
+	longest := rt.defaultTimeout
+	for _, timeout := range rt.routes {
+		if timeout > longest {
+			longest = timeout
+		}
+	}
+	return longest
+}
+
+// writeTimeout returns the server's write timeout: the configured one, raised when needed
+// so a route is cut off by its own timeout, with a clean response, rather than by the
+// server dropping the connection. Zero, no write timeout, is left as it is.
+func (rt *routeTimeouts) writeTimeout(configured time.Duration) time.Duration {
+	if configured == 0 {
+		return 0
+	}
+	if needed := rt.longest() + time.Second; needed > configured {
+		return needed
+	}
+	return configured
+}
+
+// timeoutMiddleware cuts off a request once it has run for its route's timeout, answering
+// 503 Service Unavailable with a JSON error. The handler's context is cancelled, so a hung
+// handler stops its database work and the connection is freed.
+func timeoutMiddleware(timeouts *routeTimeouts) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var template string
+			if route := mux.CurrentRoute(r); route != nil {
+				template, _ = route.GetPathTemplate()
+			}
+			timeout := timeouts.forRoute(template)
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tw := &timeoutResponseWriter{ResponseWriter: w, route: template, timeout: timeout}
+			http.TimeoutHandler(next, timeout, timeoutErrorBody(timeout)).ServeHTTP(tw, r)
+		})
+	}
+}
+
+// timeoutErrorBody is the JSON error sent when a request is cut off
+func timeoutErrorBody(timeout time.Duration) string {
+	response := &api.ErrorResponse{
+		RequestID: generateRequestID(),
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	response.Error.Code = api.ErrCodeServiceUnavailable
+	response.Error.Message = "Request timed out after " + timeout.String()
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return `{"error":{"code":"SERVICE_UNAVAILABLE","message":"Request timed out"}}`
+	}
+	return string(body)
+}
+
+// timeoutResponseWriter marks the 503 http.TimeoutHandler sends for a timed-out request as
+// JSON and logs it. The handler's own responses, 503s included, carry their content type.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	route   string
+	timeout time.Duration
+}
+
+func (w *timeoutResponseWriter) WriteHeader(statusCode int) {
+	if statusCode == http.StatusServiceUnavailable && w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+		log.Warn().Str("route", w.route).Dur("timeout", w.timeout).Msg("Request cut off at its route timeout")
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
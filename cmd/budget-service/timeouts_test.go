@@ -0,0 +1,126 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestTimeoutMiddleware(t *testing.T) {
+	timeouts := newRouteTimeouts(&config.ServiceConfig{
+		RequestTimeout: 20 * time.Millisecond,
+		RouteTimeouts:  map[string]time.Duration{"/api/v1/grants/{grant}/report": time.Second},
+	})
+
+	// slow waits for its request to be cancelled, or 500ms
+	cancelled := make(chan struct{}, 2)
+	slow := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+			return
+		case <-time.After(500 * time.Millisecond):
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "done"})
+	}
+	quick := func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		writeJSON(w, http.StatusOK, map[string]string{"status": "done"})
+	}
+
+	router := mux.NewRouter()
+	router.Use(timeoutMiddleware(timeouts))
+	router.HandleFunc("/api/v1/budget/check", slow).Methods("POST")
+	router.HandleFunc("/api/v1/grants/{grant}/report", quick).Methods("GET")
+	router.HandleFunc("/api/v1/admin/unavailable", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, api.NewBudgetError(api.ErrCodeServiceUnavailable, "Database unavailable"))
+	}).Methods("GET")
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	t.Run("slow handler is cut off at its route timeout", func(t *testing.T) {
+		start := time.Now()
+		rec := serve(http.MethodPost, "/api/v1/budget/check")
+		assert.Less(t, time.Since(start), 400*time.Millisecond)
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+		var resp api.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, api.ErrCodeServiceUnavailable, resp.Error.Code)
+		assert.Contains(t, resp.Error.Message, "20ms")
+
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("the timed-out handler's context was not cancelled")
+		}
+	})
+
+	t.Run("longer route timeout lets a slower route finish", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/v1/grants/NSF-1/report")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"done"}`, rec.Body.String())
+	})
+
+	t.Run("handler's own 503 passes through", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/api/v1/admin/unavailable")
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		var resp api.ErrorResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, "Database unavailable", resp.Error.Message)
+	})
+}
+
+func TestRouteTimeouts(t *testing.T) {
+	timeouts := newRouteTimeouts(&config.ServiceConfig{
+		RequestTimeout: 30 * time.Second,
+		RouteTimeouts: map[string]time.Duration{
+			"/api/v1/reconciliation/sacct": 10 * time.Minute,
+			"/api/v1/estimate":             0,
+		},
+	})
+
+	assert.Equal(t, 30*time.Second, timeouts.forRoute("/api/v1/budget/check"))
+	assert.Equal(t, longRequestTimeout, timeouts.forRoute("/api/v1/grants/{grant}/report"))
+	assert.Equal(t, 10*time.Minute, timeouts.forRoute("/api/v1/reconciliation/sacct"), "configured overrides built-in")
+	assert.Zero(t, timeouts.forRoute("/api/v1/estimate"), "zero leaves a route without a timeout")
+
+	// The write timeout is raised to fit the longest route
+	assert.Equal(t, 10*time.Minute+time.Second, timeouts.writeTimeout(30*time.Second))
+	assert.Equal(t, time.Hour, timeouts.writeTimeout(time.Hour))
+	assert.Zero(t, timeouts.writeTimeout(0))
+}
+
+func TestLongRoutesAreRegistered(t *testing.T) {
+	router := mux.NewRouter()
+	setupRoutes(router, nil, nil, newWorkerMonitor(), &config.Config{})
+
+	templates := map[string]bool{}
+	require.NoError(t, router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if template, err := route.GetPathTemplate(); err == nil {
+			templates[template] = true
+		}
+		return nil
+	}))
+	for _, route := range longRoutes {
+		assert.True(t, templates[route], "%s is not a route", route)
+	}
+}
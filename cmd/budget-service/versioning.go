@@ -0,0 +1,99 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// API versions a request can ask for
+const (
+	apiV1 = 1
+	apiV2 = 2
+)
+
+// mediaTypeV2 is the Accept media type that asks a /api/v1 route for its v2 shape
+const mediaTypeV2 = "application/vnd.asbb.v2+json"
+
+// apiVersionKey is the request context key for a version pinned by the URL prefix
+type apiVersionKey struct{}
+
+// pinAPIVersion is middleware for a versioned subrouter, so /api/v2 routes answer in the v2
+// shape whatever the Accept header says
+func pinAPIVersion(version int) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiVersionKey{}, version)))
+		})
+	}
+}
+
+// requestAPIVersion returns the version a request asks for: the one pinned by its URL
+// prefix, else v2 when it accepts the v2 media type, else v1
+func requestAPIVersion(r *http.Request) int {
+	if version, ok := r.Context().Value(apiVersionKey{}).(int); ok {
+		return version
+	}
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == mediaTypeV2 {
+			return apiV2
+		}
+	}
+	return apiV1
+}
+
+// versionedHandler serves an endpoint whose response shape differs between API versions
+type versionedHandler struct {
+	v1        http.HandlerFunc
+	v2        http.HandlerFunc
+	sunset    time.Time
+	successor string
+}
+
+// versioned pairs the v1 and v2 variants of an endpoint
+func versioned(v1, v2 http.HandlerFunc) *versionedHandler {
+	return &versionedHandler{v1: v1, v2: v2}
+}
+
+// deprecateV1 marks the v1 variant as slated for removal at sunset, pointing clients at
+// its successor
+func (vh *versionedHandler) deprecateV1(sunset time.Time, successor string) *versionedHandler {
+	vh.sunset = sunset
+	vh.successor = successor
+	return vh
+}
+
+// ServeHTTP dispatches to the variant the request asks for
+func (vh *versionedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Caches must not serve one version's response for the other
+	w.Header().Add("Vary", "Accept")
+
+	if requestAPIVersion(r) == apiV2 {
+		w.Header().Set("Content-Type", mediaTypeV2)
+		vh.v2(w, r)
+		return
+	}
+
+	if !vh.sunset.IsZero() {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", vh.sunset.UTC().Format(http.TimeFormat))
+		w.Header().Set("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", vh.successor))
+	}
+	vh.v1(w, r)
+}
+
+// handleVersioned registers an endpoint on the v1 router, where the Accept header chooses
+// its shape, and on the v2 router, where it always answers in the v2 shape
+func handleVersioned(v1Router, v2Router *mux.Router, path string, handler *versionedHandler, methods ...string) {
+	v1Router.Handle(path, handler).Methods(methods...)
+	v2Router.Handle(path, handler).Methods(methods...)
+}
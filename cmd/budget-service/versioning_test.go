@@ -0,0 +1,197 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// fakeAccountLister pages through a fixed list of accounts
+type fakeAccountLister struct {
+	accounts []*api.BudgetAccount
+}
+
+func (f *fakeAccountLister) ListAccounts(_ context.Context, req *api.ListAccountsRequest) ([]*api.BudgetAccount, error) {
+	accounts := f.accounts
+	if req.Offset >= len(accounts) {
+		return nil, nil
+	}
+	accounts = accounts[req.Offset:]
+	if req.Limit > 0 && req.Limit < len(accounts) {
+		accounts = accounts[:req.Limit]
+	}
+	return accounts, nil
+}
+
+func TestVersionedAccountList(t *testing.T) {
+	lister := &fakeAccountLister{accounts: []*api.BudgetAccount{
+		{SlurmAccount: "proj001"}, {SlurmAccount: "proj002"}, {SlurmAccount: "proj003"},
+	}}
+	sunset := time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC)
+
+	router := mux.NewRouter()
+	v1 := router.PathPrefix("/api/v1").Subrouter()
+	v2 := router.PathPrefix("/api/v2").Subrouter()
+	v2.Use(pinAPIVersion(apiV2))
+	handleVersioned(v1, v2, "/accounts",
+		versioned(handleListAccounts(lister), handleListAccountsV2(lister)).deprecateV1(sunset, "/api/v2/accounts"),
+		"GET")
+
+	serve := func(path, accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("v1 returns a deprecated bare array", func(t *testing.T) {
+		rec := serve("/api/v1/accounts", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		assert.Equal(t, "true", rec.Header().Get("Deprecation"))
+		assert.Equal(t, "Wed, 30 Jun 2027 00:00:00 GMT", rec.Header().Get("Sunset"))
+		assert.Equal(t, `</api/v2/accounts>; rel="successor-version"`, rec.Header().Get("Link"))
+		assert.Equal(t, "Accept", rec.Header().Get("Vary"))
+
+		var accounts []*api.BudgetAccount
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &accounts))
+		assert.Len(t, accounts, 3)
+	})
+
+	t.Run("v2 prefix returns a page", func(t *testing.T) {
+		rec := serve("/api/v2/accounts?limit=2", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, mediaTypeV2, rec.Header().Get("Content-Type"))
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+
+		var page api.AccountPage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		require.Len(t, page.Accounts, 2)
+		assert.Equal(t, "proj001", page.Accounts[0].SlurmAccount)
+		assert.Equal(t, 2, page.Limit)
+		require.NotNil(t, page.NextOffset)
+		assert.Equal(t, 2, *page.NextOffset)
+	})
+
+	t.Run("v2 last page has no next offset", func(t *testing.T) {
+		rec := serve("/api/v2/accounts?limit=2&offset=2", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var page api.AccountPage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		require.Len(t, page.Accounts, 1)
+		assert.Equal(t, "proj003", page.Accounts[0].SlurmAccount)
+		assert.Nil(t, page.NextOffset)
+	})
+
+	t.Run("accept header selects v2 on a v1 route", func(t *testing.T) {
+		rec := serve("/api/v1/accounts", "application/json;q=0.5, "+mediaTypeV2)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, mediaTypeV2, rec.Header().Get("Content-Type"))
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+
+		var page api.AccountPage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		assert.Len(t, page.Accounts, 3)
+		assert.Equal(t, defaultAccountPageLimit, page.Limit)
+		assert.Nil(t, page.NextOffset)
+	})
+
+	t.Run("v2 prefix ignores a v1 accept header", func(t *testing.T) {
+		rec := serve("/api/v2/accounts", "application/json")
+		assert.Equal(t, mediaTypeV2, rec.Header().Get("Content-Type"))
+	})
+}
+
+// fakeTransactionLister serves a fixed list of transactions, recording the last request
+type fakeTransactionLister struct {
+	transactions []*api.BudgetTransaction
+	last         *api.TransactionListRequest
+}
+
+func (f *fakeTransactionLister) ListTransactions(_ context.Context, req *api.TransactionListRequest) ([]*api.BudgetTransaction, error) {
+	f.last = req
+	return f.transactions, nil
+}
+
+func (f *fakeTransactionLister) ListTransactionPage(_ context.Context, req *api.TransactionListRequest) (*api.TransactionPage, error) {
+	f.last = req
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	page := &api.TransactionPage{Transactions: f.transactions[:1], Limit: 1}
+	page.NextCursor = api.NewTransactionCursor(f.transactions[0]).Encode()
+	return page, nil
+}
+
+func TestVersionedTransactionList(t *testing.T) {
+	created := time.Date(2025, time.May, 1, 12, 0, 0, 0, time.UTC)
+	lister := &fakeTransactionLister{transactions: []*api.BudgetTransaction{
+		{ID: 2, TransactionID: "txn_2", Type: "charge", CreatedAt: created},
+		{ID: 1, TransactionID: "txn_1", Type: "hold", CreatedAt: created},
+	}}
+
+	router := mux.NewRouter()
+	v1 := router.PathPrefix("/api/v1").Subrouter()
+	v2 := router.PathPrefix("/api/v2").Subrouter()
+	v2.Use(pinAPIVersion(apiV2))
+	handleVersioned(v1, v2, "/transactions",
+		versioned(handleListTransactions(lister), handleListTransactionsV2(lister)), "GET")
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	t.Run("v1 returns a bare array and is not deprecated", func(t *testing.T) {
+		rec := serve("/api/v1/transactions?account=proj001&limit=20&offset=40")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, rec.Header().Get("Deprecation"))
+
+		var transactions []*api.BudgetTransaction
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &transactions))
+		assert.Len(t, transactions, 2)
+		assert.Equal(t, "proj001", lister.last.Account)
+		assert.Equal(t, 20, lister.last.Limit)
+		assert.Equal(t, 40, lister.last.Offset)
+	})
+
+	t.Run("v2 returns a page with the next cursor", func(t *testing.T) {
+		rec := serve("/api/v2/transactions?limit=1")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, mediaTypeV2, rec.Header().Get("Content-Type"))
+
+		var page api.TransactionPage
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+		require.Len(t, page.Transactions, 1)
+		assert.Equal(t, "txn_2", page.Transactions[0].TransactionID)
+		require.NotEmpty(t, page.NextCursor)
+
+		// The cursor is passed back as given to continue the list
+		rec = serve("/api/v2/transactions?limit=1&cursor=" + page.NextCursor)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, page.NextCursor, lister.last.Cursor)
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		rec := serve("/api/v2/transactions?cursor=garbage")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// workerStallFactor is how many intervals a worker may go without a tick, beyond the time
+// one tick may take, before it counts as stalled
+const workerStallFactor = 2
+
+// workerMonitor tracks the heartbeat of each background worker, so a worker that has died
+// or hung is noticed rather than silently leaving allocations and recoveries undone
+type workerMonitor struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	workers map[string]*workerHeartbeat
+}
+
+// workerHeartbeat is one worker's expected cadence and last tick
+type workerHeartbeat struct {
+	interval time.Duration
+	timeout  time.Duration // The longest one tick may take
+	lastTick time.Time
+	stalled  bool // Whether the stall has been alerted on
+}
+
+func newWorkerMonitor() *workerMonitor {
+	return &workerMonitor{now: time.Now, workers: make(map[string]*workerHeartbeat)}
+}
+
+// register starts tracking a worker that ticks every interval, each tick taking up to
+// timeout. Registration counts as its first heartbeat.
+func (m *workerMonitor) register(name string, interval, timeout time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workers[name] = &workerHeartbeat{interval: interval, timeout: timeout, lastTick: m.now()}
+}
+
+// beat records that a worker finished a tick
+func (m *workerMonitor) beat(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if worker, ok := m.workers[name]; ok {
+		worker.lastTick = m.now()
+	}
+}
+
+// start registers a worker and runs tick every interval in the background, with a context
+// that expires after timeout, recording a heartbeat after each tick
+func (m *workerMonitor) start(name string, interval, timeout time.Duration, tick func(ctx context.Context)) {
+	m.register(name, interval, timeout)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			tick(ctx)
+			cancel()
+			m.beat(name)
+		}
+	}()
+}
+
+// check reports the liveness of every worker, by name. A worker found stalled is alerted
+// on once, and its recovery is logged when it ticks again.
+func (m *workerMonitor) check() []api.WorkerStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	statuses := make([]api.WorkerStatus, 0, len(m.workers))
+	for name, worker := range m.workers {
+		stallAfter := worker.stallAfter()
+		age := now.Sub(worker.lastTick)
+		alive := age <= stallAfter
+
+		switch {
+		case !alive && !worker.stalled:
+			log.Error().
+				Str("alert", "worker_stalled").
+				Str("worker", name).
+				Time("last_tick", worker.lastTick).
+				Dur("stall_after", stallAfter).
+				Msg("Background worker has stopped ticking")
+		case alive && worker.stalled:
+			log.Info().Str("worker", name).Msg("Background worker is ticking again")
+		}
+		worker.stalled = !alive
+
+		statuses = append(statuses, api.WorkerStatus{
+			Name:               name,
+			Interval:           worker.interval.String(),
+			StallAfter:         stallAfter.String(),
+			LastTick:           worker.lastTick,
+			LastTickAgeSeconds: age.Seconds(),
+			Alive:              alive,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// watch checks the workers every interval, so a stall is alerted on even when nothing
+// probes readiness
+func (m *workerMonitor) watch(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			m.check()
+		}
+	}()
+}
+
+// stallAfter is how old the last tick may be before the worker counts as stalled
+func (w *workerHeartbeat) stallAfter() time.Duration {
+	return workerStallFactor*w.interval + w.timeout
+}
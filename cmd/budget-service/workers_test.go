@@ -0,0 +1,99 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable clock for the worker monitor
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestWorkerMonitor_DetectsStalledWorker(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2025, 9, 14, 12, 0, 0, 0, time.UTC)}
+	monitor := newWorkerMonitor()
+	monitor.now = clock.Now
+
+	// Stalled once the last tick is over 2 × 1m + 30s old
+	monitor.register("recovery", time.Minute, 30*time.Second)
+	monitor.register("allocations", 5*time.Minute, 5*time.Minute)
+
+	statuses := monitor.check()
+	require.Len(t, statuses, 2)
+	assert.Equal(t, "allocations", statuses[0].Name)
+	assert.Equal(t, "recovery", statuses[1].Name)
+	assert.Equal(t, "2m30s", statuses[1].StallAfter)
+	assert.True(t, statuses[0].Alive)
+	assert.True(t, statuses[1].Alive)
+
+	// Allocations keeps ticking while recovery stops
+	for i := 0; i < 3; i++ {
+		clock.Advance(time.Minute)
+		monitor.beat("allocations")
+	}
+
+	statuses = monitor.check()
+	assert.True(t, statuses[0].Alive)
+	assert.False(t, statuses[1].Alive)
+	assert.Equal(t, 180.0, statuses[1].LastTickAgeSeconds)
+
+	// A tick clears the stall
+	monitor.beat("recovery")
+	statuses = monitor.check()
+	assert.True(t, statuses[1].Alive)
+	assert.Zero(t, statuses[1].LastTickAgeSeconds)
+}
+
+func TestWorkerMonitor_BeatUnknownWorker(t *testing.T) {
+	monitor := newWorkerMonitor()
+	monitor.beat("missing")
+	assert.Empty(t, monitor.check())
+}
+
+func TestWorkerMonitor_StartBeatsEachTick(t *testing.T) {
+	monitor := newWorkerMonitor()
+	ticked := make(chan struct{}, 1)
+	monitor.start("fast", 5*time.Millisecond, time.Second, func(ctx context.Context) {
+		// The worker outlives the test, so it only signals rather than asserting
+		if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+			return
+		}
+		select {
+		case ticked <- struct{}{}:
+		default:
+		}
+	})
+
+	registered := monitor.check()[0].LastTick
+	select {
+	case <-ticked:
+	case <-time.After(time.Second):
+		t.Fatal("worker did not tick")
+	}
+	assert.Eventually(t, func() bool {
+		return monitor.check()[0].LastTick.After(registered)
+	}, time.Second, 5*time.Millisecond)
+}
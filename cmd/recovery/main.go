@@ -12,6 +12,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
@@ -44,6 +45,11 @@ func main() {
 
 	// Initialize budget service
 	budgetService := budget.NewService(db, advisorClient, &cfg.Budget)
+	if cfg.Integration.ASBXEnabled && cfg.Integration.ASBXHoldCallback {
+		budgetService.SetHoldExpiryChecker(
+			asbx.NewClient(cfg.Integration.ASBXEndpoint, cfg.Integration.ASBXAPIKey),
+			cfg.Integration.ASBXHoldCallbackTimeout)
+	}
 
 	// Run recovery operation
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
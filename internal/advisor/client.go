@@ -9,6 +9,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"time"
 
@@ -17,12 +18,17 @@ import (
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/version"
 )
 
+// maxRetryDelay caps the backoff between estimate attempts
+const maxRetryDelay = 30 * time.Second
+
 // Client provides HTTP client for the AWS SLURM Burst Advisor service
 type Client struct {
-	httpClient *http.Client
-	baseURL    string
-	apiKey     string
-	headers    map[string]string
+	httpClient    *http.Client
+	baseURL       string
+	apiKey        string
+	headers       map[string]string
+	retryAttempts int
+	retryDelay    time.Duration
 }
 
 // NewClient creates a new advisor client
@@ -31,9 +37,11 @@ func NewClient(cfg *config.AdvisorConfig) *Client {
 		httpClient: &http.Client{
 			Timeout: cfg.Timeout,
 		},
-		baseURL: cfg.URL,
-		apiKey:  cfg.APIKey,
-		headers: make(map[string]string),
+		baseURL:       cfg.URL,
+		apiKey:        cfg.APIKey,
+		headers:       make(map[string]string),
+		retryAttempts: cfg.RetryAttempts,
+		retryDelay:    cfg.RetryDelay,
 	}
 
 	// Set default headers
@@ -82,11 +90,30 @@ func (c *Client) EstimateCost(ctx context.Context, req *budget.CostEstimateReque
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
+	// Estimates are idempotent, so transient failures are retried with backoff before the
+	// error reaches the fallback layer
+	for attempt := 0; ; attempt++ {
+		resp, retryable, err := c.estimateOnce(ctx, reqBody)
+		if err == nil || !retryable || attempt >= c.retryAttempts {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (retry abandoned: %v)", err, ctx.Err())
+		case <-time.After(backoffDelay(c.retryDelay, attempt)):
+		}
+	}
+}
+
+// estimateOnce makes a single estimate request. The returned flag reports whether a failure
+// is transient, a transport error or a 5xx response, and so worth retrying.
+func (c *Client) estimateOnce(ctx context.Context, reqBody []byte) (*budget.CostEstimateResponse, bool, error) {
 	// Create HTTP request
 	url := fmt.Sprintf("%s/api/v1/analyze", c.baseURL)
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(reqBody))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -101,7 +128,8 @@ func (c *Client) EstimateCost(ctx context.Context, req *budget.CostEstimateReque
 	// Execute request
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
-		return nil, fmt.Errorf("advisor request failed: %w", err)
+		// A cancelled or expired caller context is final; anything else may be transient
+		return nil, ctx.Err() == nil, fmt.Errorf("advisor request failed: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -112,7 +140,7 @@ func (c *Client) EstimateCost(ctx context.Context, req *budget.CostEstimateReque
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("advisor returned status %d", resp.StatusCode)
+		return nil, resp.StatusCode >= http.StatusInternalServerError, fmt.Errorf("advisor returned status %d", resp.StatusCode)
 	}
 
 	// Parse response
@@ -126,18 +154,37 @@ func (c *Client) EstimateCost(ctx context.Context, req *budget.CostEstimateReque
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&advisorResp); err != nil {
-		return nil, fmt.Errorf("failed to decode advisor response: %w", err)
+		return nil, false, fmt.Errorf("failed to decode advisor response: %w", err)
 	}
 
 	if advisorResp.Error != "" {
-		return nil, fmt.Errorf("advisor error: %s", advisorResp.Error)
+		return nil, false, fmt.Errorf("advisor error: %s", advisorResp.Error)
 	}
 
 	return &budget.CostEstimateResponse{
 		EstimatedCost:  advisorResp.EstimatedCost,
 		Confidence:     advisorResp.Confidence,
 		Recommendation: advisorResp.Recommendation,
-	}, nil
+	}, false, nil
+}
+
+// backoffDelay returns how long to wait after the given zero-based attempt: the base delay
+// doubled per attempt, capped at maxRetryDelay, with up to half of it replaced by jitter so
+// that callers failing together do not retry in lockstep
+func backoffDelay(base time.Duration, attempt int) time.Duration {
+	if base <= 0 {
+		return 0
+	}
+	delay := base
+	for i := 0; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1)) // #nosec G404 - jitter does not need a secure source
 }
 
 // HealthCheck checks if the advisor service is available
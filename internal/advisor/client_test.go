@@ -8,6 +8,7 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -116,6 +117,114 @@ func TestClient_EstimateCost_ServerError(t *testing.T) {
 	assert.Contains(t, err.Error(), "advisor returned status 500")
 }
 
+// flakyAdvisor fails the first failures requests with status, then succeeds
+func flakyAdvisor(t *testing.T, failures int, status int) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= int32(failures) {
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write([]byte(`{"estimated_cost": 12.25, "confidence": 0.9}`)); err != nil {
+			t.Errorf("failed to write response: %v", err)
+		}
+	}))
+	return server, &calls
+}
+
+func TestClient_EstimateCost_Retry(t *testing.T) {
+	req := &budget.CostEstimateRequest{
+		Account:   "test-account",
+		Partition: "cpu",
+		Nodes:     1,
+		CPUs:      4,
+		WallTime:  "01:00:00",
+	}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		server, calls := flakyAdvisor(t, 2, http.StatusServiceUnavailable)
+		defer server.Close()
+
+		client := NewClient(&config.AdvisorConfig{URL: server.URL, Timeout: time.Second, RetryAttempts: 3, RetryDelay: time.Millisecond})
+		resp, err := client.EstimateCost(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 12.25, resp.EstimatedCost)
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("fails once retries are exhausted", func(t *testing.T) {
+		server, calls := flakyAdvisor(t, 10, http.StatusBadGateway)
+		defer server.Close()
+
+		client := NewClient(&config.AdvisorConfig{URL: server.URL, Timeout: time.Second, RetryAttempts: 2, RetryDelay: time.Millisecond})
+		resp, err := client.EstimateCost(context.Background(), req)
+
+		require.Error(t, err)
+		assert.Nil(t, resp)
+		assert.Contains(t, err.Error(), "advisor returned status 502")
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls))
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		server, calls := flakyAdvisor(t, 1, http.StatusBadRequest)
+		defer server.Close()
+
+		client := NewClient(&config.AdvisorConfig{URL: server.URL, Timeout: time.Second, RetryAttempts: 3, RetryDelay: time.Millisecond})
+		_, err := client.EstimateCost(context.Background(), req)
+
+		require.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("timeouts are retried", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				time.Sleep(200 * time.Millisecond)
+			}
+			if _, err := w.Write([]byte(`{"estimated_cost": 7.5}`)); err != nil {
+				t.Errorf("failed to write response: %v", err)
+			}
+		}))
+		defer server.Close()
+
+		client := NewClient(&config.AdvisorConfig{URL: server.URL, Timeout: 50 * time.Millisecond, RetryAttempts: 1, RetryDelay: time.Millisecond})
+		resp, err := client.EstimateCost(context.Background(), req)
+
+		require.NoError(t, err)
+		assert.Equal(t, 7.5, resp.EstimatedCost)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("cancelled context stops retrying", func(t *testing.T) {
+		server, calls := flakyAdvisor(t, 10, http.StatusInternalServerError)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		client := NewClient(&config.AdvisorConfig{URL: server.URL, Timeout: time.Second, RetryAttempts: 5, RetryDelay: time.Second})
+		_, err := client.EstimateCost(ctx, req)
+
+		require.Error(t, err)
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+}
+
+func TestBackoffDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), backoffDelay(0, 3))
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		delay := backoffDelay(100*time.Millisecond, attempt)
+		assert.GreaterOrEqual(t, delay, want/2)
+		assert.LessOrEqual(t, delay, want)
+	}
+
+	assert.LessOrEqual(t, backoffDelay(time.Second, 20), maxRetryDelay)
+}
+
 func TestClient_EstimateCost_AdvisorError(t *testing.T) {
 	// Create mock server that returns advisor error
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -7,14 +7,13 @@ package advisor
 import (
 	"context"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
 // FallbackClient provides cost estimation with graceful degradation when advisor service is unavailable
@@ -88,153 +87,30 @@ func (fc *FallbackClient) fallbackEstimate(req *budget.CostEstimateRequest) (*bu
 // staticEstimate provides a fixed cost estimate
 func (fc *FallbackClient) staticEstimate(req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
 	// Parse wall time to get duration
-	duration := fc.parseWallTime(req.WallTime)
+	duration, wallTimeErr := api.ParseWallTime(req.WallTime)
 
 	// Simple calculation: nodes * CPUs * fallback_rate * hours
 	cost := float64(req.Nodes*req.CPUs) * fc.config.FallbackCostRate * duration
 
-	return &budget.CostEstimateResponse{
+	resp := &budget.CostEstimateResponse{
 		EstimatedCost:  cost,
 		Confidence:     0.5, // Low confidence for static estimates
 		Recommendation: "Static cost estimate - advisor service unavailable",
-	}, nil
-}
-
-// simpleEstimate provides basic cost estimation based on resource requirements
-func (fc *FallbackClient) simpleEstimate(req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
-	// Parse wall time to get duration
-	duration := fc.parseWallTime(req.WallTime)
-
-	// Simple heuristic-based estimation
-	var baseCost float64
-
-	// Base cost per CPU-hour
-	cpuCost := float64(req.CPUs) * fc.config.FallbackCostRate * duration
-
-	// GPU multiplier if GPUs requested
-	gpuCost := 0.0
-	if req.GPUs > 0 {
-		gpuCost = float64(req.GPUs) * fc.config.FallbackCostRate * 10.0 * duration // 10x multiplier for GPUs
-	}
-
-	// Memory cost estimation (if specified)
-	memoryCost := 0.0
-	if req.Memory != "" {
-		memoryGB := fc.parseMemory(req.Memory)
-		memoryCost = memoryGB * 0.01 * duration // $0.01/GB-hour
-	}
-
-	baseCost = cpuCost + gpuCost + memoryCost
-
-	// Partition-based adjustments
-	partitionMultiplier := 1.0
-	switch strings.ToLower(req.Partition) {
-	case "gpu", "gpu-aws":
-		partitionMultiplier = 2.0 // GPU partitions more expensive
-	case "high-mem", "himem":
-		partitionMultiplier = 1.5 // High memory premium
-	case "debug", "test":
-		partitionMultiplier = 0.5 // Test partitions cheaper
-	}
-
-	finalCost := baseCost * partitionMultiplier
-
-	// Ensure minimum cost
-	if finalCost < 0.01 {
-		finalCost = 0.01
 	}
-
-	confidence := 0.7 // Moderate confidence for heuristic estimates
-	recommendation := "Simple heuristic estimate - advisor service unavailable"
-
-	if finalCost > 100.0 {
-		recommendation += ". Consider optimization for high-cost job."
+	if wallTimeErr != nil {
+		resp.Confidence = 0.3
+		resp.Recommendation += fmt.Sprintf(". %v", wallTimeErr)
 	}
-
-	return &budget.CostEstimateResponse{
-		EstimatedCost:  finalCost,
-		Confidence:     confidence,
-		Recommendation: recommendation,
-	}, nil
+	return resp, nil
 }
 
-// parseWallTime converts wall time string to hours
-func (fc *FallbackClient) parseWallTime(wallTime string) float64 {
-	// Parse common formats: HH:MM:SS, HH:MM, or just minutes
-	parts := strings.Split(wallTime, ":")
-
-	var hours, minutes, seconds float64
-
-	switch len(parts) {
-	case 3: // HH:MM:SS
-		if h, err := strconv.ParseFloat(parts[0], 64); err == nil {
-			hours = h
-		}
-		if m, err := strconv.ParseFloat(parts[1], 64); err == nil {
-			minutes = m
-		}
-		if s, err := strconv.ParseFloat(parts[2], 64); err == nil {
-			seconds = s
-		}
-	case 2: // HH:MM
-		if h, err := strconv.ParseFloat(parts[0], 64); err == nil {
-			hours = h
-		}
-		if m, err := strconv.ParseFloat(parts[1], 64); err == nil {
-			minutes = m
-		}
-	case 1: // Assume minutes
-		if m, err := strconv.ParseFloat(parts[0], 64); err == nil {
-			minutes = m
-		}
-	}
-
-	totalHours := hours + (minutes / 60.0) + (seconds / 3600.0)
-
-	// Minimum of 1 minute
-	if totalHours < (1.0 / 60.0) {
-		totalHours = 1.0 / 60.0
-	}
-
-	return totalHours
-}
-
-// parseMemory converts memory string to GB
-func (fc *FallbackClient) parseMemory(memory string) float64 {
-	memory = strings.ToUpper(memory)
-	memory = strings.TrimSpace(memory)
-
-	var value float64
-	var unit string
-
-	// Parse number and unit
-	if strings.HasSuffix(memory, "GB") {
-		unit = "GB"
-		if v, err := strconv.ParseFloat(strings.TrimSuffix(memory, "GB"), 64); err == nil {
-			value = v
-		}
-	} else if strings.HasSuffix(memory, "MB") {
-		unit = "MB"
-		if v, err := strconv.ParseFloat(strings.TrimSuffix(memory, "MB"), 64); err == nil {
-			value = v
-		}
-	} else {
-		// Assume MB if no unit
-		if v, err := strconv.ParseFloat(memory, 64); err == nil {
-			value = v
-			unit = "MB"
-		}
-	}
-
-	// Convert to GB
-	switch unit {
-	case "GB":
-		return value
-	case "MB":
-		return value / 1024.0
-	default:
-		return 1.0 // Default 1GB
-	}
+// simpleEstimate prices the job by the fallback heuristic the budget service also uses,
+// at the configured rates
+func (fc *FallbackClient) simpleEstimate(req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
+	return budget.SimpleCostEstimate(req, budget.FallbackRates{
+		CostRate:             fc.config.FallbackCostRate,
+		PartitionMultipliers: fc.config.FallbackPartitionMultipliers,
+	}), nil
 }
 
 // HealthCheck checks if the advisor service is available
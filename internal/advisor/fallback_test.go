@@ -0,0 +1,97 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package advisor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+)
+
+func TestFallbackClient_PartitionMultipliers(t *testing.T) {
+	// One CPU for an hour at $0.10 costs $0.10 before any partition adjustment
+	estimate := func(t *testing.T, multipliers map[string]float64, partition string) float64 {
+		t.Helper()
+		fc := NewFallbackClient(&config.AdvisorConfig{}, &config.IntegrationConfig{
+			AdvisorFallback:              "SIMPLE",
+			FallbackCostRate:             0.10,
+			FallbackPartitionMultipliers: multipliers,
+		})
+		resp, err := fc.EstimateCost(context.Background(), &budget.CostEstimateRequest{
+			Partition: partition, Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		return resp.EstimatedCost
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		assert.InDelta(t, 0.20, estimate(t, nil, "gpu"), 0.0001)
+		assert.InDelta(t, 0.15, estimate(t, nil, "HiMem"), 0.0001)
+		assert.InDelta(t, 0.05, estimate(t, nil, "debug"), 0.0001)
+		assert.InDelta(t, 0.10, estimate(t, nil, "cpu"), 0.0001)
+	})
+
+	t.Run("configured overrides", func(t *testing.T) {
+		multipliers := map[string]float64{"gpu": 3.0, "a100": 8.0}
+		assert.InDelta(t, 0.30, estimate(t, multipliers, "gpu"), 0.0001)
+		assert.InDelta(t, 0.80, estimate(t, multipliers, "A100"), 0.0001)
+		// Setting the map replaces the defaults, so debug is no longer discounted
+		assert.InDelta(t, 0.10, estimate(t, multipliers, "debug"), 0.0001)
+	})
+
+	t.Run("unknown partitions use the base rate", func(t *testing.T) {
+		assert.InDelta(t, 0.10, estimate(t, map[string]float64{"gpu": 3.0}, "standard"), 0.0001)
+	})
+}
+
+func TestFallbackClient_UnreadableMemoryLowersConfidence(t *testing.T) {
+	fc := NewFallbackClient(&config.AdvisorConfig{}, &config.IntegrationConfig{
+		AdvisorFallback:  "SIMPLE",
+		FallbackCostRate: 0.10,
+	})
+	estimate := func(memory string) *budget.CostEstimateResponse {
+		resp, err := fc.EstimateCost(context.Background(), &budget.CostEstimateRequest{
+			Partition: "cpu", Nodes: 1, CPUs: 1, Memory: memory, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// 2TB at $0.01/GB-hour is $20.48 on top of the $0.10 CPU cost
+	sized := estimate("2TB")
+	assert.InDelta(t, 20.58, sized.EstimatedCost, 0.0001)
+	assert.InDelta(t, 0.7, sized.Confidence, 0.0001)
+
+	unreadable := estimate("lots")
+	assert.InDelta(t, 0.10, unreadable.EstimatedCost, 0.0001)
+	assert.InDelta(t, 0.3, unreadable.Confidence, 0.0001)
+	assert.Contains(t, unreadable.Recommendation, "Memory not costed")
+}
+
+func TestFallbackClient_DayWallTimeEstimate(t *testing.T) {
+	fc := NewFallbackClient(&config.AdvisorConfig{}, &config.IntegrationConfig{
+		AdvisorFallback:  "STATIC",
+		FallbackCostRate: 0.10,
+	})
+
+	resp, err := fc.EstimateCost(context.Background(), &budget.CostEstimateRequest{
+		Nodes: 2, CPUs: 4, WallTime: "2-12:00:00",
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 48.0, resp.EstimatedCost, 0.0001) // 8 CPUs for 60 hours at $0.10
+	assert.InDelta(t, 0.5, resp.Confidence, 0.0001)
+
+	resp, err = fc.EstimateCost(context.Background(), &budget.CostEstimateRequest{
+		Nodes: 1, CPUs: 1, WallTime: "whenever",
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 0.3, resp.Confidence, 0.0001)
+	assert.Contains(t, resp.Recommendation, "not understood")
+}
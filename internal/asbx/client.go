@@ -0,0 +1,103 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package asbx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/version"
+)
+
+// Client calls back to ASBX for what it knows about a budget hold's job
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+}
+
+// NewClient creates a new ASBX client. Each call is bounded by its context, so the
+// caller decides how long to wait.
+func NewClient(endpoint, apiKey string) *Client {
+	return &Client{
+		httpClient: &http.Client{},
+		baseURL:    endpoint,
+		apiKey:     apiKey,
+	}
+}
+
+// CheckExpiringHold asks ASBX for the job cost data recorded against a budget hold, and
+// turns it into a reconciliation. It returns nil when ASBX has no record of the hold.
+func (c *Client) CheckExpiringHold(ctx context.Context, hold *api.BudgetTransaction) (*api.JobReconcileRequest, error) {
+	jobData, err := c.getHoldJobCost(ctx, hold.TransactionID)
+	if err != nil || jobData == nil {
+		return nil, err
+	}
+	if jobData.BudgetTransactionID != "" && jobData.BudgetTransactionID != hold.TransactionID {
+		return nil, fmt.Errorf("ASBX returned job data for transaction %s, not %s",
+			jobData.BudgetTransactionID, hold.TransactionID)
+	}
+
+	if jobData.ActualCost == nil {
+		return nil, fmt.Errorf("ASBX job data for transaction %s has no actual cost", hold.TransactionID)
+	}
+
+	req := &api.JobReconcileRequest{
+		JobID:         jobData.JobID,
+		ActualCost:    *jobData.ActualCost,
+		TransactionID: hold.TransactionID,
+		JobMetadata:   buildJobMetadata(*jobData),
+		JobState:      jobData.JobState,
+		CostBreakdown: jobData.CostBreakdown,
+	}
+	if !jobData.CompletedAt.IsZero() {
+		req.CompletedAt = &jobData.CompletedAt
+	}
+	return req, nil
+}
+
+// getHoldJobCost fetches the job cost data ASBX holds for a budget transaction, or nil
+// when it has none
+func (c *Client) getHoldJobCost(ctx context.Context, transactionID string) (*api.ASBXJobCostData, error) {
+	endpoint := fmt.Sprintf("%s/api/v1/budget-holds/%s/job-cost", c.baseURL, url.PathEscape(transactionID))
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ASBX request: %w", err)
+	}
+
+	httpReq.Header.Set("User-Agent", version.UserAgent())
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ASBX request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			// HTTP response body close failed - acknowledge error
+			_ = err // Error is handled by acknowledging it
+		}
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusNoContent:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("ASBX returned status %d", resp.StatusCode)
+	}
+
+	var jobData api.ASBXJobCostData
+	if err := json.NewDecoder(resp.Body).Decode(&jobData); err != nil {
+		return nil, fmt.Errorf("failed to decode ASBX response: %w", err)
+	}
+	return &jobData, nil
+}
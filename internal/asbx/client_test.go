@@ -0,0 +1,86 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package asbx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestClient_CheckExpiringHold(t *testing.T) {
+	known := api.ASBXJobCostData{
+		JobID:               "67890",
+		Account:             "NSF-2025-12345",
+		JobState:            "COMPLETED",
+		ActualCost:          float64Ptr(9.5),
+		CostBreakdown:       map[string]float64{"compute": 9.0, "storage": 0.5},
+		BudgetTransactionID: "txn_known",
+	}
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/api/v1/budget-holds/txn_known/job-cost":
+			_ = json.NewEncoder(w).Encode(known)
+		case "/api/v1/budget-holds/txn_mismatch/job-cost":
+			_ = json.NewEncoder(w).Encode(known)
+		case "/api/v1/budget-holds/txn_no_actual/job-cost":
+			partial := known
+			partial.ActualCost = nil
+			partial.BudgetTransactionID = "txn_no_actual"
+			_ = json.NewEncoder(w).Encode(partial)
+		case "/api/v1/budget-holds/txn_broken/job-cost":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	ctx := context.Background()
+
+	t.Run("job data becomes a reconciliation", func(t *testing.T) {
+		req, err := client.CheckExpiringHold(ctx, &api.BudgetTransaction{TransactionID: "txn_known"})
+		require.NoError(t, err)
+		require.NotNil(t, req)
+		assert.Equal(t, "67890", req.JobID)
+		assert.Equal(t, "txn_known", req.TransactionID)
+		assert.InDelta(t, 9.5, req.ActualCost, 0.001)
+		assert.Equal(t, "COMPLETED", req.JobState)
+		assert.Equal(t, known.CostBreakdown, req.CostBreakdown)
+		assert.Equal(t, "Bearer secret", authorization)
+	})
+
+	t.Run("unknown hold returns nothing", func(t *testing.T) {
+		req, err := client.CheckExpiringHold(ctx, &api.BudgetTransaction{TransactionID: "txn_unknown"})
+		require.NoError(t, err)
+		assert.Nil(t, req)
+	})
+
+	t.Run("data for another hold is an error", func(t *testing.T) {
+		_, err := client.CheckExpiringHold(ctx, &api.BudgetTransaction{TransactionID: "txn_mismatch"})
+		assert.Error(t, err)
+	})
+
+	t.Run("job data without an actual cost is an error", func(t *testing.T) {
+		_, err := client.CheckExpiringHold(ctx, &api.BudgetTransaction{TransactionID: "txn_no_actual"})
+		assert.Error(t, err)
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		_, err := client.CheckExpiringHold(ctx, &api.BudgetTransaction{TransactionID: "txn_broken"})
+		assert.Error(t, err)
+	})
+}
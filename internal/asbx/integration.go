@@ -6,7 +6,9 @@ package asbx
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -32,6 +34,16 @@ type IntegrationConfig struct {
 	MaxRetries            int           `json:"max_retries"`
 	NotificationEnabled   bool          `json:"notification_enabled"`
 	ComplianceReporting   bool          `json:"compliance_reporting"`
+
+	// A cost variance is warned about only when it is more than VarianceWarningPct of the
+	// estimate and more than VarianceWarningMinAmount dollars, so rounding on cheap jobs
+	// stays quiet. Zero leaves that bound out.
+	VarianceWarningPct       float64 `json:"variance_warning_pct"`
+	VarianceWarningMinAmount float64 `json:"variance_warning_min_amount"`
+
+	// UnheldJobPolicy is what a reconciliation of a job with no budget transaction does:
+	// api.UnheldJobPolicyReject refuses it; anything else charges the job's account
+	UnheldJobPolicy string `json:"unheld_job_policy"`
 }
 
 // NewIntegrationService creates a new ASBX integration service
@@ -50,48 +62,86 @@ func (s *IntegrationService) ProcessCostReconciliation(ctx context.Context, req
 
 	jobData := req.JobCostData
 
+	// A job with no budget transaction bypassed the budget check and was never held
+	unheld := jobData.BudgetTransactionID == ""
+	if unheld && s.config.UnheldJobPolicy == api.UnheldJobPolicyReject {
+		return nil, api.NewBudgetError(api.ErrCodeValidation, "Budget transaction ID is required for reconciliation")
+	}
+
+	// The hold must be the job's account's own, so one account's costs cannot settle another's
+	var holdAmount func() (float64, error)
+	if !unheld {
+		hold, err := s.accountHold(ctx, jobData.BudgetTransactionID, jobData.Account)
+		if err != nil {
+			return nil, err
+		}
+		holdAmount = func() (float64, error) { return hold.Amount, nil }
+	}
+	costs, err := resolveCosts(jobData, holdAmount)
+	if err != nil {
+		return nil, err
+	}
+
 	log.Info().
 		Str("job_id", jobData.JobID).
 		Str("account", jobData.Account).
-		Float64("estimated_cost", jobData.EstimatedCost).
-		Float64("actual_cost", jobData.ActualCost).
+		Float64("estimated_cost", costs.Estimated).
+		Str("estimate_source", costs.EstimateSource).
+		Float64("actual_cost", costs.Actual).
 		Msg("Processing ASBX cost reconciliation")
 
-	// Find the original budget transaction
-	if jobData.BudgetTransactionID == "" {
-		return nil, api.NewBudgetError(api.ErrCodeValidation, "Budget transaction ID is required for reconciliation")
-	}
-
-	// Prepare reconciliation request
+	// Prepare reconciliation request; its charges and refunds carry the reconciliation's ID
+	reconciliationID := s.generateReconciliationID()
 	reconcileReq := &api.JobReconcileRequest{
-		JobID:         jobData.JobID,
-		ActualCost:    jobData.ActualCost,
-		TransactionID: jobData.BudgetTransactionID,
-		JobMetadata:   s.buildJobMetadata(jobData),
+		JobID:            jobData.JobID,
+		ActualCost:       costs.Actual,
+		TransactionID:    jobData.BudgetTransactionID,
+		JobMetadata:      buildJobMetadata(jobData),
+		JobState:         jobData.JobState,
+		CostBreakdown:    jobData.CostBreakdown,
+		ReconciliationID: reconciliationID,
+	}
+	if !jobData.CompletedAt.IsZero() {
+		reconcileReq.CompletedAt = &jobData.CompletedAt
 	}
 
-	// Perform budget reconciliation
-	reconcileResp, err := s.budgetService.ReconcileJob(ctx, reconcileReq)
+	// Perform budget reconciliation; a job never held is charged to its account instead
+	var reconcileResp *api.JobReconcileResponse
+	if unheld {
+		reconcileReq.Account = jobData.Account
+		reconcileResp, err = s.budgetService.ReconcileUnreservedJob(ctx, reconcileReq)
+	} else {
+		reconcileResp, err = s.budgetService.ReconcileJob(ctx, reconcileReq)
+	}
 	if err != nil {
+		// A replayed post finds its hold settled, which the caller is told as such
+		if budgetErr, ok := api.AsBudgetError(err); ok && budgetErr.Code == api.ErrCodeHoldSettled {
+			return nil, err
+		}
 		return nil, fmt.Errorf("failed to reconcile job costs: %w", err)
 	}
 
-	// Calculate performance metrics
-	costVariance := jobData.ActualCost - jobData.EstimatedCost
-	costVariancePct := 0.0
-	if jobData.EstimatedCost > 0 {
-		costVariancePct = (costVariance / jobData.EstimatedCost) * 100
+	// Feed the research domain's estimate history that domain factor learning draws on. A
+	// hold amount is not an estimate the domain's jobs were priced at, so it is left out.
+	if costs.EstimateSource == estimateSourceASBX {
+		if err := s.budgetService.RecordEstimateAccuracy(ctx, jobData.ResearchDomain, jobData.JobID,
+			costs.Estimated, costs.Actual); err != nil {
+			log.Warn().Err(err).Str("job_id", jobData.JobID).Msg("Failed to record estimate accuracy")
+		}
 	}
 
-	estimationAccuracy := 1.0 - (abs(costVariance) / max(jobData.EstimatedCost, 0.01))
-	if estimationAccuracy < 0 {
-		estimationAccuracy = 0
+	// Calculate performance metrics
+	costVariance := costs.Actual - costs.Estimated
+	costVariancePct := 0.0
+	if costs.Estimated > 0 {
+		costVariancePct = (costVariance / costs.Estimated) * 100
 	}
+	estimationAccuracy := costs.accuracy()
 
 	// Process performance feedback for cost model improvement
 	var modelUpdateApplied bool
 	if req.UpdateCostModel && s.config.UpdateCostModel {
-		feedback := s.buildPerformanceFeedback(jobData, costVariancePct, estimationAccuracy)
+		feedback := s.buildPerformanceFeedback(jobData, costs)
 		if err := s.processPerformanceFeedback(ctx, feedback); err != nil {
 			log.Warn().Err(err).Msg("Failed to process performance feedback")
 		} else {
@@ -114,14 +164,18 @@ func (s *IntegrationService) ProcessCostReconciliation(ctx context.Context, req
 	// Build response
 	response := &api.ASBXCostReconciliationResponse{
 		Success:                   true,
-		ReconciliationID:          s.generateReconciliationID(),
+		ReconciliationID:          reconciliationID,
 		OriginalTransaction:       jobData.BudgetTransactionID,
-		EstimatedCost:             jobData.EstimatedCost,
-		ActualCost:                jobData.ActualCost,
+		EstimatedCost:             costs.Estimated,
+		EstimateSource:            costs.EstimateSource,
+		ActualCost:                costs.Actual,
 		CostVariance:              costVariance,
 		CostVariancePct:           costVariancePct,
+		ChargedAmount:             reconcileResp.ActualCharge,
 		RefundAmount:              reconcileResp.RefundAmount,
-		AdditionalCharge:          max(0, -reconcileResp.RefundAmount), // If refund is negative, it's additional charge
+		AdditionalCharge:          reconcileResp.AdditionalCharge,
+		FailedJobPolicy:           reconcileResp.FailedJobPolicy,
+		Unreserved:                unheld,
 		EstimationAccuracy:        estimationAccuracy,
 		ModelUpdateApplied:        modelUpdateApplied,
 		ComplianceReportGenerated: reportGenerated,
@@ -130,10 +184,19 @@ func (s *IntegrationService) ProcessCostReconciliation(ctx context.Context, req
 	}
 
 	// Add recommendations based on performance data
-	response.Recommendations = s.generateRecommendations(jobData, costVariancePct, estimationAccuracy)
+	response.Recommendations = s.generateRecommendations(jobData, costs, costVariancePct, estimationAccuracy)
 
 	// Add warnings if needed
-	if abs(costVariancePct) > 50 {
+	if unheld {
+		response.ChargeTransaction = reconcileResp.TransactionID
+		response.Warnings = append(response.Warnings,
+			fmt.Sprintf("Job %s ran without a budget check; its cost was charged to %s retroactively", jobData.JobID, jobData.Account))
+	}
+	if costs.EstimateSource == estimateSourceHold {
+		response.Warnings = append(response.Warnings,
+			fmt.Sprintf("ASBX sent no estimated cost; variance is measured against the $%.2f hold", costs.Estimated))
+	}
+	if s.largeCostVariance(costVariance, costVariancePct) {
 		response.Warnings = append(response.Warnings,
 			fmt.Sprintf("Large cost variance: %.1f%% difference from estimate", costVariancePct))
 	}
@@ -142,6 +205,12 @@ func (s *IntegrationService) ProcessCostReconciliation(ctx context.Context, req
 		response.Warnings = append(response.Warnings,
 			fmt.Sprintf("Job ended with state: %s", jobData.JobState))
 	}
+	if reconcileResp.FailedJobPolicy == api.FailedJobPolicyFullRefund {
+		response.Warnings = append(response.Warnings,
+			fmt.Sprintf("Failed job refunded in full; actual cost of $%.2f not charged", costs.Actual))
+	}
+
+	s.recordReconciliation(ctx, jobData, response)
 
 	log.Info().
 		Str("reconciliation_id", response.ReconciliationID).
@@ -181,6 +250,11 @@ func (s *IntegrationService) ProcessEpilogData(ctx context.Context, req *api.ASB
 					"Consider manual reconciliation",
 				}
 			} else {
+				// A node may only reconcile the job it reported, against that job's own hold
+				if err := s.verifyEpilogCostData(ctx, req, costData); err != nil {
+					return nil, err
+				}
+
 				// Trigger automatic reconciliation
 				reconcileReq := &api.ASBXCostReconciliationRequest{
 					JobCostData:     *costData,
@@ -218,47 +292,45 @@ func (s *IntegrationService) ProcessEpilogData(ctx context.Context, req *api.ASB
 	return response, nil
 }
 
-// GetIntegrationStatus returns the current status of ASBX integration
+// GetIntegrationStatus reports the ASBX reconciliations recorded so far
 func (s *IntegrationService) GetIntegrationStatus(ctx context.Context) (*api.ASBXIntegrationStatus, error) {
-	// TODO: Implement actual status collection
-	return &api.ASBXIntegrationStatus{
-		ASBXVersion:               "0.2.0",
-		IntegrationEnabled:        s.config.Enabled,
-		LastDataImport:            time.Now().Add(-1 * time.Hour), // Mock data
-		TotalJobsReconciled:       245,
-		SuccessfulReconciliations: 238,
-		FailedReconciliations:     7,
-		AverageReconciliationTime: "2.3s",
-		CostModelAccuracy:         0.87,
-		LastHealthCheck:           time.Now().Add(-5 * time.Minute),
-		HealthStatus:              "healthy",
-	}, nil
+	status, err := s.budgetService.ASBXReconciliationStatus(ctx, estimateSourceASBX)
+	if err != nil {
+		return nil, err
+	}
+	status.ASBXVersion = supportedASBXVersion
+	status.IntegrationEnabled = s.config.Enabled
+	return status, nil
 }
 
 // Helper functions
 
-func (s *IntegrationService) buildJobMetadata(jobData api.ASBXJobCostData) string {
-	// Convert job data to JSON metadata string
-	// TODO: Implement proper JSON marshaling
-	return fmt.Sprintf(`{
-		"asbx_job_id": "%s",
-		"burst_decision": "%s",
-		"instance_types": %v,
-		"cpu_efficiency": %.2f,
-		"memory_efficiency": %.2f
-	}`, jobData.JobID, jobData.BurstDecision, jobData.InstanceTypes, jobData.CPUEfficiency, jobData.MemoryEfficiency)
+// buildJobMetadata encodes what ASBX reported about a job as the job metadata of its
+// reconciliation
+func buildJobMetadata(jobData api.ASBXJobCostData) string {
+	metadata := api.JobMetadata{
+		ASBXJobID:        jobData.JobID,
+		BurstDecision:    jobData.BurstDecision,
+		InstanceTypes:    jobData.InstanceTypes,
+		CPUEfficiency:    jobData.CPUEfficiency,
+		MemoryEfficiency: jobData.MemoryEfficiency,
+	}
+	return metadata.String()
 }
 
-func (s *IntegrationService) buildPerformanceFeedback(jobData api.ASBXJobCostData, _ float64, _ float64) *api.ASBXPerformanceFeedback {
-	return &api.ASBXPerformanceFeedback{
-		JobID:                  jobData.JobID,
-		Account:                jobData.Account,
-		Partition:              jobData.Partition,
-		CPUEfficiency:          jobData.CPUEfficiency,
-		MemoryEfficiency:       jobData.MemoryEfficiency,
-		ActualVsEstimatedRatio: jobData.ActualCost / max(jobData.EstimatedCost, 0.01),
-		PerformanceProfile:     jobData.PerformanceProfile,
+func (s *IntegrationService) buildPerformanceFeedback(jobData api.ASBXJobCostData, costs *reconciledCosts) *api.ASBXPerformanceFeedback {
+	feedback := &api.ASBXPerformanceFeedback{
+		JobID:              jobData.JobID,
+		Account:            jobData.Account,
+		Partition:          jobData.Partition,
+		CPUEfficiency:      jobData.CPUEfficiency,
+		MemoryEfficiency:   jobData.MemoryEfficiency,
+		PerformanceProfile: jobData.PerformanceProfile,
 	}
+	if costs.Estimated > 0 {
+		feedback.ActualVsEstimatedRatio = costs.Actual / costs.Estimated
+	}
+	return feedback
 }
 
 func (s *IntegrationService) processPerformanceFeedback(_ context.Context, feedback *api.ASBXPerformanceFeedback) error {
@@ -289,18 +361,96 @@ func (s *IntegrationService) generateComplianceReport(_ context.Context, jobData
 }
 
 func (s *IntegrationService) importASBXCostData(dataPath string) (*api.ASBXJobCostData, error) {
-	// TODO: Implement actual ASBX data import
-	// This would read cost data from ASBX v0.2.0 export format
 	log.Info().Str("data_path", dataPath).Msg("Importing ASBX cost data")
 
-	return &api.ASBXJobCostData{}, fmt.Errorf("ASBX data import not yet implemented for path %s", dataPath)
+	data, err := os.ReadFile(dataPath) // #nosec G304 - path comes from a signed epilog post
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ASBX cost data %s: %w", dataPath, err)
+	}
+
+	var costData api.ASBXJobCostData
+	if err := json.Unmarshal(data, &costData); err != nil {
+		return nil, fmt.Errorf("failed to parse ASBX cost data %s: %w", dataPath, err)
+	}
+	return &costData, nil
 }
 
+// verifyEpilogCostData rejects cost data that belongs to a different job or account than
+// the epilog that pointed at it, or that would reconcile another account's hold
+func (s *IntegrationService) verifyEpilogCostData(ctx context.Context, req *api.ASBXEpilogRequest, costData *api.ASBXJobCostData) error {
+	if err := matchEpilogCostData(req, costData); err != nil {
+		return err
+	}
+	if costData.BudgetTransactionID == "" {
+		return nil
+	}
+
+	_, err := s.accountHold(ctx, costData.BudgetTransactionID, req.Account)
+	return err
+}
+
+// accountHold returns the hold transaction transactionID, refusing it unless it belongs to
+// slurmAccount
+func (s *IntegrationService) accountHold(ctx context.Context, transactionID, slurmAccount string) (*api.BudgetTransaction, error) {
+	hold, err := s.budgetService.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := s.budgetService.GetAccount(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+	if hold.AccountID != account.ID {
+		return nil, api.NewBudgetError(api.ErrCodeForbidden,
+			fmt.Sprintf("Transaction %s does not belong to account %s", transactionID, slurmAccount))
+	}
+	return hold, nil
+}
+
+// matchEpilogCostData checks that ASBX cost data describes the job and account the epilog
+// reported
+func matchEpilogCostData(req *api.ASBXEpilogRequest, costData *api.ASBXJobCostData) error {
+	if costData.JobID != req.JobID && costData.SlurmJobID != req.JobID {
+		return api.NewBudgetError(api.ErrCodeForbidden,
+			fmt.Sprintf("ASBX cost data is for job %s, not job %s", costData.JobID, req.JobID))
+	}
+	if costData.Account != req.Account {
+		return api.NewBudgetError(api.ErrCodeForbidden,
+			fmt.Sprintf("ASBX cost data is for account %s, not account %s", costData.Account, req.Account))
+	}
+	return nil
+}
+
+// recordReconciliation records a completed reconciliation's variance summary for later
+// lookup by its ID. Errors are logged; the reconciliation itself has succeeded.
+func (s *IntegrationService) recordReconciliation(ctx context.Context, jobData api.ASBXJobCostData, response *api.ASBXCostReconciliationResponse) {
+	summary := &api.ASBXReconciliationSummary{
+		ReconciliationID:    response.ReconciliationID,
+		JobID:               jobData.JobID,
+		Account:             jobData.Account,
+		OriginalTransaction: response.OriginalTransaction,
+		EstimatedCost:       response.EstimatedCost,
+		EstimateSource:      response.EstimateSource,
+		ActualCost:          response.ActualCost,
+		CostVariance:        response.CostVariance,
+		CostVariancePct:     response.CostVariancePct,
+		EstimationAccuracy:  response.EstimationAccuracy,
+		ChargedAmount:       response.ChargedAmount,
+		RefundAmount:        response.RefundAmount,
+		Unreserved:          response.Unreserved,
+	}
+	if err := s.budgetService.RecordASBXReconciliation(ctx, summary); err != nil {
+		log.Warn().Err(err).Str("reconciliation_id", response.ReconciliationID).Msg("Failed to record reconciliation summary")
+	}
+}
+
+// generateReconciliationID generates a random reconciliation ID, as transaction IDs are;
+// the reconciliations table's unique constraint rejects the unlikely duplicate
 func (s *IntegrationService) generateReconciliationID() string {
-	return fmt.Sprintf("asbx_recon_%d", time.Now().UnixNano())
+	return budget.RandomID("asbx_recon_")
 }
 
-func (s *IntegrationService) generateRecommendations(jobData api.ASBXJobCostData, costVariancePct, accuracy float64) []string {
+func (s *IntegrationService) generateRecommendations(jobData api.ASBXJobCostData, costs *reconciledCosts, costVariancePct, accuracy float64) []string {
 	var recommendations []string
 
 	if abs(costVariancePct) > 20 {
@@ -319,13 +469,80 @@ func (s *IntegrationService) generateRecommendations(jobData api.ASBXJobCostData
 		recommendations = append(recommendations, "Cost estimation accuracy is below target - review job characteristics")
 	}
 
-	if jobData.BurstDecision == "AWS" && jobData.ActualCost > jobData.EstimatedCost*1.5 {
+	if jobData.BurstDecision == "AWS" && costs.Actual > costs.Estimated*1.5 {
 		recommendations = append(recommendations, "AWS burst was significantly more expensive than estimated - consider local execution for similar jobs")
 	}
 
 	return recommendations
 }
 
+// largeCostVariance reports whether a cost variance is large enough to warn about, both as
+// a percentage of the estimate and in dollars
+func (s *IntegrationService) largeCostVariance(variance, variancePct float64) bool {
+	return abs(variancePct) > s.config.VarianceWarningPct && abs(variance) > s.config.VarianceWarningMinAmount
+}
+
+// supportedASBXVersion is the ASBX release whose cost data format the integration reads
+const supportedASBXVersion = "0.2.0"
+
+// Where a reconciliation's estimated cost came from
+const (
+	estimateSourceASBX = "asbx"
+	estimateSourceHold = "hold"
+	estimateSourceNone = "none" // no estimate from ASBX and no hold to stand in for one
+)
+
+// reconciledCosts are the estimated and actual cost a reconciliation works from
+type reconciledCosts struct {
+	Estimated      float64
+	Actual         float64
+	EstimateSource string
+}
+
+// resolveCosts takes the costs from ASBX job data, which may be partial. A missing actual
+// cost is rejected rather than charged as zero. A missing estimate is replaced by the hold
+// amount, looked up only then, or left at zero for a job with no hold.
+func resolveCosts(jobData api.ASBXJobCostData, holdAmount func() (float64, error)) (*reconciledCosts, error) {
+	if jobData.ActualCost == nil {
+		return nil, api.NewValidationError("actual_cost",
+			fmt.Sprintf("ASBX sent no actual cost for job %s; it cannot be charged without one", jobData.JobID))
+	}
+	if *jobData.ActualCost < 0 {
+		return nil, api.NewValidationError("actual_cost", "cannot be negative")
+	}
+	costs := &reconciledCosts{Actual: *jobData.ActualCost, EstimateSource: estimateSourceASBX}
+
+	if jobData.EstimatedCost != nil {
+		costs.Estimated = *jobData.EstimatedCost
+		return costs, nil
+	}
+
+	if holdAmount == nil {
+		costs.EstimateSource = estimateSourceNone
+		return costs, nil
+	}
+
+	amount, err := holdAmount()
+	if err != nil {
+		return nil, err
+	}
+	costs.Estimated = amount
+	costs.EstimateSource = estimateSourceHold
+	return costs, nil
+}
+
+// accuracy scores how close the estimate was to the actual cost, from 1 for exact to 0
+// for off by the whole estimate or more. Without an estimate only a free job is accurate.
+func (c *reconciledCosts) accuracy() float64 {
+	if c.Estimated <= 0 {
+		if c.Actual == 0 {
+			return 1
+		}
+		return 0
+	}
+	return max(0, 1-abs(c.Actual-c.Estimated)/c.Estimated)
+}
+
 // Helper functions
 func abs(x float64) float64 {
 	if x < 0 {
@@ -0,0 +1,186 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package asbx
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestMatchEpilogCostData(t *testing.T) {
+	req := &api.ASBXEpilogRequest{JobID: "67890", Account: "NSF-2025-12345"}
+
+	tests := []struct {
+		name     string
+		costData api.ASBXJobCostData
+		wantErr  bool
+	}{
+		{
+			name:     "matching job and account",
+			costData: api.ASBXJobCostData{JobID: "67890", Account: "NSF-2025-12345"},
+		},
+		{
+			name:     "matching slurm job ID",
+			costData: api.ASBXJobCostData{JobID: "asbx-1", SlurmJobID: "67890", Account: "NSF-2025-12345"},
+		},
+		{
+			name:     "another job",
+			costData: api.ASBXJobCostData{JobID: "11111", Account: "NSF-2025-12345"},
+			wantErr:  true,
+		},
+		{
+			name:     "another account",
+			costData: api.ASBXJobCostData{JobID: "67890", Account: "NIH-2025-99999"},
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := matchEpilogCostData(req, &tt.costData)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			budgetErr, ok := api.AsBudgetError(err)
+			if assert.True(t, ok) {
+				assert.Equal(t, api.ErrCodeForbidden, budgetErr.Code)
+			}
+		})
+	}
+}
+
+func TestResolveCosts(t *testing.T) {
+	holdAmount := func() (float64, error) { return 12.0, nil }
+
+	t.Run("estimate and actual present", func(t *testing.T) {
+		costs, err := resolveCosts(api.ASBXJobCostData{EstimatedCost: float64Ptr(10), ActualCost: float64Ptr(8)},
+			func() (float64, error) {
+				t.Fatal("hold looked up although ASBX sent an estimate")
+				return 0, nil
+			})
+		require.NoError(t, err)
+		assert.Equal(t, &reconciledCosts{Estimated: 10, Actual: 8, EstimateSource: estimateSourceASBX}, costs)
+	})
+
+	t.Run("missing estimate falls back to the hold", func(t *testing.T) {
+		costs, err := resolveCosts(api.ASBXJobCostData{ActualCost: float64Ptr(8)}, holdAmount)
+		require.NoError(t, err)
+		assert.Equal(t, &reconciledCosts{Estimated: 12, Actual: 8, EstimateSource: estimateSourceHold}, costs)
+	})
+
+	t.Run("missing estimate with no hold", func(t *testing.T) {
+		_, err := resolveCosts(api.ASBXJobCostData{ActualCost: float64Ptr(8)}, func() (float64, error) {
+			return 0, errors.New("transaction not found")
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("job never held has no estimate to fall back to", func(t *testing.T) {
+		costs, err := resolveCosts(api.ASBXJobCostData{ActualCost: float64Ptr(8)}, nil)
+		require.NoError(t, err)
+		assert.Equal(t, &reconciledCosts{Actual: 8, EstimateSource: estimateSourceNone}, costs)
+	})
+
+	t.Run("missing actual is rejected", func(t *testing.T) {
+		_, err := resolveCosts(api.ASBXJobCostData{JobID: "67890", EstimatedCost: float64Ptr(10)}, holdAmount)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		assert.Equal(t, "actual_cost", budgetErr.Field)
+	})
+
+	t.Run("negative actual is rejected", func(t *testing.T) {
+		_, err := resolveCosts(api.ASBXJobCostData{ActualCost: float64Ptr(-1)}, holdAmount)
+		assert.Error(t, err)
+	})
+}
+
+func TestReconciledCosts_Accuracy(t *testing.T) {
+	tests := []struct {
+		name      string
+		estimated float64
+		actual    float64
+		want      float64
+	}{
+		{"exact", 10, 10, 1},
+		{"under estimate", 10, 8, 0.8},
+		{"over estimate", 10, 12, 0.8},
+		{"off by more than the estimate", 10, 25, 0},
+		{"no estimate for a free job", 0, 0, 1},
+		{"no estimate for a charged job", 0, 5, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			costs := &reconciledCosts{Estimated: tt.estimated, Actual: tt.actual}
+			assert.InDelta(t, tt.want, costs.accuracy(), 0.001)
+		})
+	}
+}
+
+func TestLargeCostVariance(t *testing.T) {
+	s := NewIntegrationService(nil, &IntegrationConfig{VarianceWarningPct: 50, VarianceWarningMinAmount: 1.00})
+
+	tests := []struct {
+		name      string
+		estimated float64
+		actual    float64
+		want      bool
+	}{
+		{"penny over on a cheap job", 0.02, 0.03, false},
+		{"doubled cheap job", 0.01, 0.02, false},
+		{"large dollar overrun", 100, 180, true},
+		{"large dollar underrun", 100, 20, true},
+		{"large dollar variance within percentage", 1000, 1400, false},
+		{"just over both bounds", 2, 3.02, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variance := tt.actual - tt.estimated
+			pct := variance / tt.estimated * 100
+			assert.Equal(t, tt.want, s.largeCostVariance(variance, pct))
+		})
+	}
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestBuildJobMetadata(t *testing.T) {
+	metadata := buildJobMetadata(api.ASBXJobCostData{
+		JobID:            "asbx-1",
+		BurstDecision:    "burst",
+		InstanceTypes:    []string{"c5.large", "c5.xlarge"},
+		CPUEfficiency:    0.82,
+		MemoryEfficiency: 0.6,
+	})
+
+	job, err := api.ParseJobMetadata(metadata)
+	require.NoError(t, err)
+	assert.Equal(t, "asbx-1", job.ASBXJobID)
+	assert.Equal(t, "burst", job.BurstDecision)
+	assert.Equal(t, []string{"c5.large", "c5.xlarge"}, job.InstanceTypes)
+	assert.Equal(t, 0.82, job.CPUEfficiency)
+	assert.Equal(t, 0.6, job.MemoryEfficiency)
+}
+
+func TestGenerateReconciliationID(t *testing.T) {
+	service := &IntegrationService{}
+
+	id1 := service.generateReconciliationID()
+	id2 := service.generateReconciliationID()
+
+	assert.NotEqual(t, id1, id2)
+	assert.Regexp(t, `^asbx_recon_[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id1)
+	assert.LessOrEqual(t, len(id1), 64, "fits asbx_reconciliations.reconciliation_id")
+}
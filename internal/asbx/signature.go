@@ -0,0 +1,64 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package asbx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const (
+	// EpilogSignatureHeader carries the HMAC-SHA256 of an epilog post as "sha256=<hex>"
+	EpilogSignatureHeader = "X-ASBB-Signature"
+	// EpilogTimestampHeader carries the Unix time the epilog post was signed
+	EpilogTimestampHeader = "X-ASBB-Timestamp"
+
+	signaturePrefix = "sha256="
+)
+
+// SignEpilog returns the signature header value for an epilog body signed at timestamp.
+// The timestamp is part of the signed message, so a captured post is only accepted within
+// the allowed skew of its signing; reconciliation refuses a hold already settled, so a post
+// replayed within that window charges nothing.
+func SignEpilog(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyEpilogSignature checks an epilog post's signature and timestamp headers against
+// the shared secret, rejecting posts signed more than maxSkew away from now
+func VerifyEpilogSignature(secret, signature, timestamp string, body []byte, now time.Time, maxSkew time.Duration) error {
+	if secret == "" {
+		return api.NewBudgetError(api.ErrCodeServiceUnavailable, "Epilog signing secret is not configured")
+	}
+	if signature == "" || timestamp == "" {
+		return api.NewBudgetError(api.ErrCodeUnauthorized, "Missing epilog signature")
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return api.NewBudgetError(api.ErrCodeUnauthorized, "Invalid epilog timestamp")
+	}
+	if skew := now.Sub(time.Unix(signedAt, 0)); skew > maxSkew || skew < -maxSkew {
+		return api.NewBudgetError(api.ErrCodeUnauthorized,
+			fmt.Sprintf("Epilog timestamp is outside the allowed skew of %s", maxSkew))
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) ||
+		!hmac.Equal([]byte(signature), []byte(SignEpilog(secret, signedAt, body))) {
+		return api.NewBudgetError(api.ErrCodeUnauthorized, "Invalid epilog signature")
+	}
+	return nil
+}
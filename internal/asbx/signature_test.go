@@ -0,0 +1,100 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package asbx
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestVerifyEpilogSignature(t *testing.T) {
+	const secret = "epilog-secret"
+	now := time.Date(2025, 9, 14, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"job_id":"67890","account":"NSF-2025-12345","job_state":"COMPLETED"}`)
+	signedAt := now.Add(-time.Minute).Unix()
+	timestamp := strconv.FormatInt(signedAt, 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		body      []byte
+		wantCode  api.ErrorCode
+	}{
+		{
+			name:      "valid signature",
+			secret:    secret,
+			signature: SignEpilog(secret, signedAt, body),
+			timestamp: timestamp,
+			body:      body,
+		},
+		{
+			name:      "forged body",
+			secret:    secret,
+			signature: SignEpilog(secret, signedAt, body),
+			timestamp: timestamp,
+			body:      []byte(`{"job_id":"67890","account":"OTHER-ACCOUNT","job_state":"COMPLETED"}`),
+			wantCode:  api.ErrCodeUnauthorized,
+		},
+		{
+			name:      "wrong secret",
+			secret:    secret,
+			signature: SignEpilog("guessed", signedAt, body),
+			timestamp: timestamp,
+			body:      body,
+			wantCode:  api.ErrCodeUnauthorized,
+		},
+		{
+			name:      "timestamp not covered by signature",
+			secret:    secret,
+			signature: SignEpilog(secret, signedAt, body),
+			timestamp: strconv.FormatInt(signedAt+1, 10),
+			body:      body,
+			wantCode:  api.ErrCodeUnauthorized,
+		},
+		{
+			name:      "stale post",
+			secret:    secret,
+			signature: SignEpilog(secret, now.Add(-time.Hour).Unix(), body),
+			timestamp: strconv.FormatInt(now.Add(-time.Hour).Unix(), 10),
+			body:      body,
+			wantCode:  api.ErrCodeUnauthorized,
+		},
+		{
+			name:      "missing signature",
+			secret:    secret,
+			timestamp: timestamp,
+			body:      body,
+			wantCode:  api.ErrCodeUnauthorized,
+		},
+		{
+			name:      "secret not configured",
+			signature: SignEpilog("", signedAt, body),
+			timestamp: timestamp,
+			body:      body,
+			wantCode:  api.ErrCodeServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifyEpilogSignature(tt.secret, tt.signature, tt.timestamp, tt.body, now, 5*time.Minute)
+			if tt.wantCode == "" {
+				assert.NoError(t, err)
+				return
+			}
+			budgetErr, ok := api.AsBudgetError(err)
+			if assert.True(t, ok) {
+				assert.Equal(t, tt.wantCode, budgetErr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// finishedJobStates are the SLURM job states whose usage is final
+var finishedJobStates = map[string]bool{
+	"COMPLETED":     true,
+	"FAILED":        true,
+	"CANCELLED":     true,
+	"TIMEOUT":       true,
+	"NODE_FAIL":     true,
+	"OUT_OF_MEMORY": true,
+	"PREEMPTED":     true,
+	"BOOT_FAIL":     true,
+	"DEADLINE":      true,
+}
+
+// ReconcileAccountingJobs reconciles holds from SLURM accounting records, for sites where
+// ASBX does not report job costs. The actual cost comes from the same cost model as budget
+// checks, applied to the resources the job actually used. Jobs still running are skipped,
+// and jobs without a hold are reported rather than charged, since accounting cannot tell a
+// burst job that ran below the minimum chargeable cost from one on a local partition.
+func (s *Service) ReconcileAccountingJobs(ctx context.Context, req *api.AccountingReconcileRequest) (*api.AccountingReconcileResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp := &api.AccountingReconcileResponse{Jobs: make([]*api.AccountingJobResult, 0, len(req.Jobs))}
+	for _, job := range req.Jobs {
+		result := s.reconcileAccountingJob(ctx, job)
+		switch result.Status {
+		case api.AccountingJobReconciled:
+			resp.Reconciled++
+		case api.AccountingJobAlreadyReconciled:
+			resp.AlreadyReconciled++
+		case api.AccountingJobNoHold:
+			resp.NoHold++
+		case api.AccountingJobUnfinished:
+			resp.Unfinished++
+		default:
+			resp.Failed++
+			log.Warn().Str("job_id", job.JobID).Str("account", job.Account).Str("reason", result.Message).
+				Msg("Failed to reconcile job from accounting data")
+		}
+		resp.Jobs = append(resp.Jobs, result)
+	}
+
+	return resp, nil
+}
+
+func (s *Service) reconcileAccountingJob(ctx context.Context, job *api.AccountingJob) *api.AccountingJobResult {
+	result := &api.AccountingJobResult{JobID: job.JobID, Account: job.Account}
+	failed := func(err error) *api.AccountingJobResult {
+		result.Status, result.Message = api.AccountingJobFailed, err.Error()
+		return result
+	}
+
+	if !isFinishedJobState(job.State) {
+		result.Status, result.Message = api.AccountingJobUnfinished, fmt.Sprintf("job state is %s", job.State)
+		return result
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, job.Account)
+	if err != nil {
+		return failed(err)
+	}
+
+	charges, err := s.transactionQueries.ListTransactions(ctx, &api.TransactionListRequest{
+		Account: job.Account,
+		JobID:   job.JobID,
+		Type:    "charge",
+		Limit:   1,
+	})
+	if err != nil {
+		return failed(err)
+	}
+	if len(charges) > 0 {
+		result.Status, result.TransactionID = api.AccountingJobAlreadyReconciled, charges[0].TransactionID
+		return result
+	}
+
+	hold, err := s.findAccountingHold(ctx, job, account.ID)
+	if err != nil {
+		return failed(err)
+	}
+	if hold == nil {
+		result.Status, result.Message = api.AccountingJobNoHold, "no budget hold found for job"
+		return result
+	}
+	result.TransactionID = hold.TransactionID
+
+	actualCost, err := s.accountingJobCost(ctx, job)
+	if err != nil {
+		return failed(err)
+	}
+
+	reconciled, err := s.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID:         job.JobID,
+		ActualCost:    actualCost,
+		TransactionID: hold.TransactionID,
+		JobState:      job.State,
+		CompletedAt:   job.End,
+	})
+	if err != nil {
+		return failed(err)
+	}
+
+	result.Status = api.AccountingJobReconciled
+	result.ActualCost = reconciled.ActualCharge
+	result.RefundAmount = reconciled.RefundAmount
+	return result
+}
+
+// findAccountingHold finds the hold for a job, by the hold transaction ID recorded on the
+// job at submission or else by a hold carrying the job ID. A nil hold means there is none.
+func (s *Service) findAccountingHold(ctx context.Context, job *api.AccountingJob, accountID int64) (*api.BudgetTransaction, error) {
+	if job.HoldTransactionID != "" {
+		hold, err := s.transactionQueries.GetTransaction(ctx, job.HoldTransactionID)
+		if err != nil {
+			if budgetErr, ok := api.AsBudgetError(err); ok && budgetErr.Code == api.ErrCodeNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if hold.Type != "hold" {
+			return nil, fmt.Errorf("transaction %s recorded on the job is not a hold", hold.TransactionID)
+		}
+		if hold.AccountID != accountID {
+			return nil, fmt.Errorf("hold %s belongs to a different account than the job", hold.TransactionID)
+		}
+		return hold, nil
+	}
+
+	holds, err := s.transactionQueries.ListTransactions(ctx, &api.TransactionListRequest{
+		Account: job.Account,
+		JobID:   job.JobID,
+		Type:    "hold",
+		Limit:   1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, nil
+	}
+	return holds[0], nil
+}
+
+// accountingJobCost prices the resources a job actually used. A job that never ran costs
+// nothing.
+func (s *Service) accountingJobCost(ctx context.Context, job *api.AccountingJob) (float64, error) {
+	if job.ElapsedSeconds == 0 {
+		return 0, nil
+	}
+
+	estimate, err := s.estimateCost(ctx, accountingCheckRequest(job), "")
+	if err != nil {
+		return 0, err
+	}
+	if estimate.FailureMode != "" {
+		log.Warn().Str("job_id", job.JobID).Msg("Pricing job from accounting data with the fallback cost model")
+	}
+	return estimate.EstimatedCost, nil
+}
+
+// accountingCheckRequest describes an accounting record as a budget check for the cost
+// model. sacct reports CPUs for the whole job, while a check counts them per node.
+func accountingCheckRequest(job *api.AccountingJob) *api.BudgetCheckRequest {
+	nodes := job.Nodes
+	if nodes < 1 {
+		nodes = 1
+	}
+	cpus := (job.CPUs + nodes - 1) / nodes
+	if cpus < 1 {
+		cpus = 1
+	}
+
+	return &api.BudgetCheckRequest{
+		Account:   job.Account,
+		Partition: job.Partition,
+		Nodes:     nodes,
+		CPUs:      cpus,
+		GPUs:      job.GPUs,
+		Memory:    job.Memory,
+		WallTime:  formatWallTime(job.ElapsedSeconds),
+		UserID:    job.User,
+	}
+}
+
+// formatWallTime formats seconds as HH:MM:SS, with hours allowed past 24
+func formatWallTime(seconds int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+}
+
+// isFinishedJobState reports whether a job in the given SLURM state has final usage.
+// States may carry a reason, as in "CANCELLED by 1000".
+func isFinishedJobState(state string) bool {
+	fields := strings.Fields(strings.ToUpper(state))
+	return len(fields) > 0 && finishedJobStates[fields[0]]
+}
@@ -0,0 +1,53 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestIsFinishedJobState(t *testing.T) {
+	for _, state := range []string{"COMPLETED", "FAILED", "TIMEOUT", "CANCELLED by 1000", "cancelled", "OUT_OF_MEMORY"} {
+		assert.True(t, isFinishedJobState(state), state)
+	}
+	for _, state := range []string{"", "RUNNING", "PENDING", "SUSPENDED", "REQUEUED"} {
+		assert.False(t, isFinishedJobState(state), state)
+	}
+}
+
+func TestAccountingCheckRequest(t *testing.T) {
+	req := accountingCheckRequest(&api.AccountingJob{
+		JobID:          "4201",
+		Account:        "proj001",
+		Partition:      "aws-gpu",
+		User:           "alice",
+		ElapsedSeconds: 5400,
+		Nodes:          2,
+		CPUs:           33,
+		GPUs:           4,
+		Memory:         "128G",
+	})
+
+	assert.Equal(t, "proj001", req.Account)
+	assert.Equal(t, "aws-gpu", req.Partition)
+	assert.Equal(t, 2, req.Nodes)
+	assert.Equal(t, 17, req.CPUs, "job CPUs are spread across nodes, rounding up")
+	assert.Equal(t, 4, req.GPUs)
+	assert.Equal(t, "01:30:00", req.WallTime)
+
+	req = accountingCheckRequest(&api.AccountingJob{Account: "proj001", ElapsedSeconds: 60})
+	assert.Equal(t, 1, req.Nodes)
+	assert.Equal(t, 1, req.CPUs)
+}
+
+func TestFormatWallTime(t *testing.T) {
+	assert.Equal(t, "00:00:00", formatWallTime(0))
+	assert.Equal(t, "00:01:05", formatWallTime(65))
+	assert.Equal(t, "48:00:12", formatWallTime(2*24*3600+12))
+}
@@ -0,0 +1,193 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// Admission failure modes, as configured by budget.admission_failures
+const (
+	admissionFailuresFirst = "FIRST"
+	admissionFailuresAll   = "ALL"
+)
+
+// admissionJob is what the admission checks judge: a job and an account funding it, with
+// the ancestors the account draws on. cost is set once the job is priced.
+type admissionJob struct {
+	account   *api.BudgetAccount
+	ancestors []*api.BudgetAccount
+	req       *api.BudgetCheckRequest
+	cost      *admissionCost
+}
+
+// admissionCost is what pricing a job gives the checks that need its cost
+type admissionCost struct {
+	estimate  float64            // The job's estimated cost, in dollars
+	hold      float64            // The hold it needs in the account's unit; zero when it holds nothing
+	available float64            // The budget available to it across the account's chain
+	limiting  *api.BudgetAccount // The account in the chain with the least available
+	// A cost-shared job's funding accounts are each checked as its holds are placed
+	costShared bool
+}
+
+// admissionCheck is one of the checks a job must pass. It returns a warning when the job
+// may go ahead with one, or the budget error refusing it. An error of the service rather
+// than of the job means the check could not be made.
+type admissionCheck struct {
+	name  string
+	check func(ctx context.Context, s *Service, job admissionJob) (string, error)
+}
+
+// admissionChecks are evaluated in order before the job is priced: whether the account
+// takes holds at all, then whether the job may run on the partition it asks for, then
+// whether the grants funding the account last as long as the job
+var admissionChecks = []admissionCheck{
+	{name: "account_status", check: func(ctx context.Context, s *Service, job admissionJob) (string, error) {
+		return "", checkAcceptsHolds(job.account, job.ancestors)
+	}},
+	{name: "allowed_partition", check: func(ctx context.Context, s *Service, job admissionJob) (string, error) {
+		if !job.account.AllowsPartition(job.req.Partition) {
+			return "", api.NewPartitionNotAllowedError(job.account.SlurmAccount, job.req.Partition, job.account.AllowedPartitions)
+		}
+		return "", nil
+	}},
+	{name: "grant_end_date", check: func(ctx context.Context, s *Service, job admissionJob) (string, error) {
+		return s.checkGrantEndDate(ctx, job.account, job.req)
+	}},
+}
+
+// costChecks are evaluated in order once the job is priced: whether its estimate is under
+// the single-job cap, then whether its hold fits the account's limit on the partition, then
+// whether it fits the budget available. A job that holds nothing passes the last two.
+var costChecks = []admissionCheck{
+	{name: "max_job_cost", check: func(ctx context.Context, s *Service, job admissionJob) (string, error) {
+		if limit := s.maxSingleJobCost(job.req.Partition); exceedsMaxSingleJobCost(job.cost.estimate, limit) {
+			return "", api.NewJobCostExceededError(job.req.Partition, job.cost.estimate, limit)
+		}
+		return "", nil
+	}},
+	{name: "partition_limit", check: func(ctx context.Context, s *Service, job admissionJob) (string, error) {
+		if job.cost.hold <= 0 || job.cost.costShared {
+			return "", nil
+		}
+		limit, err := s.accountQueries.GetPartitionLimit(ctx, job.account.ID, job.req.Partition)
+		if err != nil || limit == nil {
+			return "", err
+		}
+		if job.cost.hold > limit.Available() {
+			return "", api.NewPartitionLimitError(job.account.SlurmAccount, job.req.Partition, job.cost.hold, limit.Available())
+		}
+		return "", nil
+	}},
+	{name: "budget_available", check: func(ctx context.Context, s *Service, job admissionJob) (string, error) {
+		if job.cost.costShared || job.cost.hold <= job.cost.available {
+			return "", nil
+		}
+		return "", api.NewInsufficientBudgetError(job.cost.limiting.SlurmAccount, job.cost.hold, job.cost.available)
+	}},
+}
+
+// admissionFailures returns the configured admission failure mode; an empty mode is FIRST
+func (s *Service) admissionFailures() string {
+	if s.config.AdmissionFailures == "" {
+		return admissionFailuresFirst
+	}
+	return s.config.AdmissionFailures
+}
+
+// admission gathers the warnings and refusals of admission checks run in one or more
+// stages against the same job
+type admission struct {
+	all        bool // Under ALL every check runs; under FIRST the first refusal ends them
+	warning    string
+	first      *api.BudgetError
+	firstCheck string
+	failures   []api.CheckFailure
+}
+
+// newAdmission starts an admission under the configured failure mode
+func (s *Service) newAdmission() *admission {
+	return &admission{all: s.admissionFailures() == admissionFailuresAll}
+}
+
+// refused reports whether any check has refused the job so far
+func (a *admission) refused() bool {
+	return a.first != nil
+}
+
+// done reports whether no further checks should run: under FIRST, once one has refused
+func (a *admission) done() bool {
+	return a.refused() && !a.all
+}
+
+// run runs checks against the job in order, unless an earlier stage has already ended the
+// admission. It returns an error only for a check that could not be made, which stops the
+// checks under either mode.
+func (a *admission) run(ctx context.Context, s *Service, checks []admissionCheck, job admissionJob) error {
+	for _, c := range checks {
+		if a.done() {
+			return nil
+		}
+		checkWarning, err := c.check(ctx, s, job)
+		if err == nil {
+			a.warning = appendWarning(a.warning, checkWarning)
+			continue
+		}
+
+		budgetErr, ok := api.AsBudgetError(err)
+		if !ok || budgetErr.HTTPStatus() >= http.StatusInternalServerError {
+			return err
+		}
+		if a.first == nil {
+			a.first = budgetErr
+			a.firstCheck = c.name
+		}
+		a.failures = append(a.failures, api.CheckFailure{Check: c.name, Code: budgetErr.Code, Message: budgetErr.Message})
+	}
+	return nil
+}
+
+// refusal returns the error refusing the job, or nil when no check refused it. Under FIRST
+// it is the first check's own error; under ALL it keeps the first failure's code and
+// message for clients that read only one, and lists each failed check.
+func (a *admission) refusal() error {
+	if a.first == nil {
+		return nil
+	}
+	if !a.all {
+		return a.first
+	}
+
+	aggregate := *a.first
+	aggregate.CheckFailures = a.failures
+	if len(a.failures) > 1 {
+		names := make([]string, len(a.failures))
+		for i, failure := range a.failures {
+			names[i] = failure.Check + ": " + failure.Message
+		}
+		aggregate.Details = fmt.Sprintf("%d checks failed: %s", len(a.failures), strings.Join(names, "; "))
+	}
+	return &aggregate
+}
+
+// admit runs the admission checks against a job and returns their warnings, joined, or
+// the refusal of the job under the configured failure mode. A check that cannot be made
+// stops the run either way.
+func (s *Service) admit(ctx context.Context, checks []admissionCheck, job admissionJob) (string, error) {
+	admission := s.newAdmission()
+	if err := admission.run(ctx, s, checks, job); err != nil {
+		return "", err
+	}
+	if err := admission.refusal(); err != nil {
+		return "", err
+	}
+	return admission.warning, nil
+}
@@ -0,0 +1,211 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestAdmissionChecks_Order(t *testing.T) {
+	var names []string
+	for _, c := range admissionChecks {
+		names = append(names, c.name)
+	}
+	assert.Equal(t, []string{"account_status", "allowed_partition", "grant_end_date"}, names)
+
+	names = nil
+	for _, c := range costChecks {
+		names = append(names, c.name)
+	}
+	assert.Equal(t, []string{"max_job_cost", "partition_limit", "budget_available"}, names)
+}
+
+func TestService_Admit(t *testing.T) {
+	ctx := context.Background()
+	job := admissionJob{
+		account: &api.BudgetAccount{SlurmAccount: "proj001", Status: "active"},
+		req:     &api.BudgetCheckRequest{Account: "proj001", Partition: "gpu"},
+	}
+
+	// recording builds checks that note when they run and return what they are given
+	recording := func(ran *[]string, results map[string]error, warnings map[string]string) []admissionCheck {
+		var checks []admissionCheck
+		for _, name := range []string{"first", "second", "third"} {
+			name := name
+			checks = append(checks, admissionCheck{name: name, check: func(ctx context.Context, s *Service, job admissionJob) (string, error) {
+				*ran = append(*ran, name)
+				return warnings[name], results[name]
+			}})
+		}
+		return checks
+	}
+	inactive := api.NewAccountInactiveError("proj001", "suspended")
+	notAllowed := api.NewPartitionNotAllowedError("proj001", "gpu", []string{"cpu"})
+
+	for _, mode := range []string{"", admissionFailuresFirst, admissionFailuresAll} {
+		t.Run("passing checks all run in order under "+mode, func(t *testing.T) {
+			s := &Service{config: &config.BudgetConfig{AdmissionFailures: mode}}
+			var ran []string
+			warning, err := s.admit(ctx, recording(&ran, nil, map[string]string{"first": "a", "third": "c"}), job)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"first", "second", "third"}, ran)
+			assert.Equal(t, "a; c", warning)
+		})
+	}
+
+	t.Run("the first failure stops the checks", func(t *testing.T) {
+		s := &Service{config: &config.BudgetConfig{AdmissionFailures: admissionFailuresFirst}}
+		var ran []string
+		_, err := s.admit(ctx, recording(&ran, map[string]error{"second": notAllowed, "third": inactive}, nil), job)
+		assert.Same(t, notAllowed, err)
+		assert.Equal(t, []string{"first", "second"}, ran)
+	})
+
+	t.Run("every failure is reported", func(t *testing.T) {
+		s := &Service{config: &config.BudgetConfig{AdmissionFailures: admissionFailuresAll}}
+		var ran []string
+		_, err := s.admit(ctx, recording(&ran, map[string]error{"first": inactive, "third": notAllowed}, nil), job)
+		assert.Equal(t, []string{"first", "second", "third"}, ran)
+
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAccountInactive, budgetErr.Code, "the first failure's code is kept")
+		assert.Equal(t, inactive.Message, budgetErr.Message)
+		assert.Equal(t, []api.CheckFailure{
+			{Check: "first", Code: api.ErrCodeAccountInactive, Message: inactive.Message},
+			{Check: "third", Code: api.ErrCodeForbidden, Message: notAllowed.Message},
+		}, budgetErr.CheckFailures)
+		assert.Contains(t, budgetErr.Details, "2 checks failed")
+		assert.Empty(t, inactive.CheckFailures, "the check's own error is left alone")
+	})
+
+	t.Run("a single failure is listed too", func(t *testing.T) {
+		s := &Service{config: &config.BudgetConfig{AdmissionFailures: admissionFailuresAll}}
+		var ran []string
+		_, err := s.admit(ctx, recording(&ran, map[string]error{"second": notAllowed}, nil), job)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Len(t, budgetErr.CheckFailures, 1)
+		assert.Equal(t, notAllowed.Details, budgetErr.Details)
+	})
+
+	t.Run("a check that cannot be made stops the checks", func(t *testing.T) {
+		s := &Service{config: &config.BudgetConfig{AdmissionFailures: admissionFailuresAll}}
+		for _, failure := range []error{errors.New("connection reset"), api.NewDatabaseError("list grants", errors.New("timeout"))} {
+			var ran []string
+			_, err := s.admit(ctx, recording(&ran, map[string]error{"first": inactive, "second": failure}, nil), job)
+			assert.Same(t, failure, err)
+			assert.Equal(t, []string{"first", "second"}, ran)
+		}
+	})
+
+	t.Run("the account's own checks", func(t *testing.T) {
+		s := &Service{config: &config.BudgetConfig{AdmissionFailures: admissionFailuresAll}}
+		frozen := admissionJob{
+			account: &api.BudgetAccount{
+				SlurmAccount:      "proj001",
+				Status:            "active",
+				StartDate:         time.Now().Add(-time.Hour),
+				EndDate:           time.Now().Add(time.Hour),
+				Frozen:            true,
+				AllowedPartitions: []string{"cpu"},
+			},
+			req: job.req,
+		}
+		// The grant end date check needs the database, so only the account's own run here
+		_, err := s.admit(ctx, admissionChecks[:2], frozen)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAccountFrozen, budgetErr.Code)
+		require.Len(t, budgetErr.CheckFailures, 2)
+		assert.Equal(t, "allowed_partition", budgetErr.CheckFailures[1].Check)
+	})
+}
+
+func TestAdmission_CostChecks(t *testing.T) {
+	ctx := context.Background()
+	account := &api.BudgetAccount{
+		SlurmAccount:      "proj001",
+		Status:            "active",
+		StartDate:         time.Now().Add(-time.Hour),
+		EndDate:           time.Now().Add(time.Hour),
+		Frozen:            true,
+		AllowedPartitions: []string{"cpu"},
+	}
+	// A $600 job needing a $720 hold against $500, on a partition capped at $400 a job
+	job := admissionJob{
+		account: account,
+		req:     &api.BudgetCheckRequest{Account: "proj001", Partition: "gpu"},
+		cost:    &admissionCost{estimate: 600, hold: 720, available: 500, limiting: account},
+	}
+	// The partition limit check needs the database, so only the others run here
+	priced := []admissionCheck{costChecks[0], costChecks[2]}
+	newService := func(mode string) *Service {
+		return &Service{config: &config.BudgetConfig{
+			AdmissionFailures:         mode,
+			PartitionMaxSingleJobCost: map[string]float64{"gpu": 400},
+		}}
+	}
+
+	t.Run("every refusal is listed across both stages", func(t *testing.T) {
+		s := newService(admissionFailuresAll)
+		admission := s.newAdmission()
+		require.NoError(t, admission.run(ctx, s, admissionChecks[:2], job))
+		require.NoError(t, admission.run(ctx, s, priced, job))
+
+		var checks []string
+		for _, failure := range admission.failures {
+			checks = append(checks, failure.Check)
+		}
+		assert.Equal(t, []string{"account_status", "allowed_partition", "max_job_cost", "budget_available"}, checks)
+		assert.Equal(t, api.ErrCodeInsufficientBudget, admission.failures[3].Code)
+
+		budgetErr, ok := api.AsBudgetError(admission.refusal())
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAccountFrozen, budgetErr.Code)
+		assert.Contains(t, budgetErr.Details, "4 checks failed")
+	})
+
+	t.Run("the first refusal ends the later stage", func(t *testing.T) {
+		s := newService(admissionFailuresFirst)
+		admission := s.newAdmission()
+		require.NoError(t, admission.run(ctx, s, admissionChecks[:2], job))
+		require.True(t, admission.done())
+		require.NoError(t, admission.run(ctx, s, priced, job))
+		assert.Len(t, admission.failures, 1)
+		assert.Equal(t, "account_status", admission.firstCheck)
+	})
+
+	t.Run("a priced job is refused by its first failed cost check", func(t *testing.T) {
+		s := newService(admissionFailuresFirst)
+		admission := s.newAdmission()
+		unfrozen := *account
+		unfrozen.Frozen = false
+		unfrozen.AllowedPartitions = nil
+		priced := job
+		priced.account = &unfrozen
+		require.NoError(t, admission.run(ctx, s, admissionChecks[:2], priced))
+		require.NoError(t, admission.run(ctx, s, costChecks[:1], priced))
+		assert.Equal(t, "max_job_cost", admission.firstCheck)
+		assert.Contains(t, admission.refusal().Error(), "exceeds the maximum single-job cost of 400.00")
+	})
+
+	t.Run("a job holding nothing fits any budget", func(t *testing.T) {
+		s := newService(admissionFailuresAll)
+		free := job
+		free.cost = &admissionCost{estimate: 0.5, available: 0, limiting: account}
+		_, err := s.admit(ctx, costChecks, free)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// alertTypeBudgetThreshold is the alert type raised for budget utilization
+const alertTypeBudgetThreshold = "budget_threshold"
+
+// alertAction is what evaluating a metric against the open alert requires
+type alertAction int
+
+const (
+	alertNone alertAction = iota
+	alertTrigger
+	alertEscalate
+	alertResolve
+)
+
+// alertBand is a severity level and the metric value at which it begins
+type alertBand struct {
+	Severity  string
+	Threshold float64
+}
+
+// utilizationBands returns the configured utilization bands in ascending order
+func (s *Service) utilizationBands() []alertBand {
+	var bands []alertBand
+	if s.config.AlertWarningThreshold > 0 {
+		bands = append(bands, alertBand{Severity: "warning", Threshold: s.config.AlertWarningThreshold})
+	}
+	if s.config.AlertCriticalThreshold > 0 {
+		bands = append(bands, alertBand{Severity: "critical", Threshold: s.config.AlertCriticalThreshold})
+	}
+	return bands
+}
+
+// evaluateAlert decides how a metric value changes the open alert, if any. An alert is
+// raised when the value enters a band and escalated when it enters a higher one. It is
+// only cleared once the value falls margin below the lowest band, so a value hovering
+// around a threshold does not flap between raised and cleared.
+func evaluateAlert(open *api.BudgetAlert, value float64, bands []alertBand, margin float64) (alertAction, *alertBand) {
+	if len(bands) == 0 {
+		return alertNone, nil
+	}
+
+	var current *alertBand
+	for i := range bands {
+		if value >= bands[i].Threshold {
+			current = &bands[i]
+		}
+	}
+
+	if open == nil {
+		if current != nil {
+			return alertTrigger, current
+		}
+		return alertNone, nil
+	}
+
+	if current != nil && severityRank(current.Severity) > severityRank(open.Severity) {
+		return alertEscalate, current
+	}
+
+	if value < bands[0].Threshold-margin {
+		return alertResolve, nil
+	}
+
+	return alertNone, nil
+}
+
+// severityRank orders alert severities
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 2
+	case "warning":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// EvaluateBudgetAlerts raises, escalates and resolves utilization alerts for active accounts
+func (s *Service) EvaluateBudgetAlerts(ctx context.Context) error {
+	bands := s.utilizationBands()
+	if len(bands) == 0 {
+		return nil
+	}
+
+	accounts, err := s.accountQueries.ListAccounts(ctx, &api.ListAccountsRequest{Status: "active"})
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		if account.BudgetLimit <= 0 {
+			continue
+		}
+		utilization := (account.BudgetUsed + account.BudgetHeld) / account.BudgetLimit * 100
+
+		if err := s.evaluateAccountAlert(ctx, account, utilization, bands); err != nil {
+			log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to evaluate budget alert")
+		}
+	}
+
+	return nil
+}
+
+// refreshAccountAlert re-evaluates one account's utilization alert straight away, so an
+// account coming back into use does not carry alert state that went stale while it was
+// skipped by EvaluateBudgetAlerts
+func (s *Service) refreshAccountAlert(ctx context.Context, account *api.BudgetAccount) {
+	bands := s.utilizationBands()
+	if len(bands) == 0 || account.BudgetLimit <= 0 {
+		return
+	}
+
+	utilization := (account.BudgetUsed + account.BudgetHeld) / account.BudgetLimit * 100
+	if err := s.evaluateAccountAlert(ctx, account, utilization, bands); err != nil {
+		log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to evaluate budget alert")
+	}
+}
+
+// evaluateAccountAlert applies the alert decision for one account
+func (s *Service) evaluateAccountAlert(ctx context.Context, account *api.BudgetAccount, utilization float64, bands []alertBand) error {
+	open, err := s.alertQueries.GetOpenAlert(ctx, account.ID, alertTypeBudgetThreshold)
+	if err != nil {
+		return err
+	}
+
+	action, band := evaluateAlert(open, utilization, bands, s.config.AlertHysteresisMargin)
+	switch action {
+	case alertTrigger:
+		return s.raiseAlert(ctx, &api.BudgetAlert{
+			AccountID:      account.ID,
+			AlertType:      alertTypeBudgetThreshold,
+			Severity:       band.Severity,
+			ThresholdValue: band.Threshold,
+			ActualValue:    utilization,
+			Message:        utilizationMessage(account, utilization, band),
+		})
+	case alertEscalate:
+		message := utilizationMessage(account, utilization, band)
+		if err := s.alertQueries.EscalateAlert(ctx, open.ID, band.Severity, band.Threshold, utilization, message); err != nil {
+			return err
+		}
+		escalated := *open
+		escalated.Severity = band.Severity
+		escalated.ThresholdValue = band.Threshold
+		escalated.ActualValue = utilization
+		escalated.Message = message
+		s.notifyAlert(ctx, &escalated)
+		return nil
+	case alertResolve:
+		return s.alertQueries.ResolveAlert(ctx, open.ID, utilization)
+	}
+
+	return nil
+}
+
+// utilizationMessage describes a utilization alert
+func utilizationMessage(account *api.BudgetAccount, utilization float64, band *alertBand) string {
+	return fmt.Sprintf("Account %s has used or reserved %.1f%% of its budget (%s threshold %.0f%%)",
+		account.SlurmAccount, utilization, band.Severity, band.Threshold)
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestEvaluateAlert_OscillatingMetric(t *testing.T) {
+	bands := []alertBand{
+		{Severity: "warning", Threshold: 80},
+		{Severity: "critical", Threshold: 95},
+	}
+	margin := 5.0
+
+	// Simulate the alerts table: every alert ever raised and the currently open one
+	var history []*api.BudgetAlert
+	var open *api.BudgetAlert
+	var actions []alertAction
+
+	values := []float64{70, 81, 79, 80.5, 78, 82, 96, 90, 94.9, 96, 79, 76, 75.5, 74.9, 79, 81}
+	for _, value := range values {
+		action, band := evaluateAlert(open, value, bands, margin)
+		actions = append(actions, action)
+
+		switch action {
+		case alertTrigger:
+			open = &api.BudgetAlert{Severity: band.Severity, ThresholdValue: band.Threshold, ActualValue: value, Status: "active"}
+			history = append(history, open)
+		case alertEscalate:
+			open.Severity = band.Severity
+			open.ThresholdValue = band.Threshold
+		case alertResolve:
+			open.Status = "resolved"
+			open = nil
+		}
+	}
+
+	assert.Equal(t, []alertAction{
+		alertNone,     // 70: healthy
+		alertTrigger,  // 81: warning raised
+		alertNone,     // 79: below threshold but inside the margin
+		alertNone,     // 80.5: still the same warning, not a duplicate
+		alertNone,     // 78
+		alertNone,     // 82
+		alertEscalate, // 96: critical band, escalate in place
+		alertNone,     // 90: no de-escalation
+		alertNone,     // 94.9
+		alertNone,     // 96: already critical
+		alertNone,     // 79
+		alertNone,     // 76
+		alertNone,     // 75.5
+		alertResolve,  // 74.9: more than the margin below the warning threshold
+		alertNone,     // 79: below threshold, no new alert
+		alertTrigger,  // 81: a genuinely new crossing
+	}, actions)
+
+	require.Len(t, history, 2)
+	assert.Equal(t, "critical", history[0].Severity)
+	assert.Equal(t, "resolved", history[0].Status)
+	assert.Equal(t, "warning", history[1].Severity)
+	assert.Equal(t, "active", history[1].Status)
+}
+
+func TestEvaluateAlert_DirectToCritical(t *testing.T) {
+	bands := []alertBand{
+		{Severity: "warning", Threshold: 80},
+		{Severity: "critical", Threshold: 95},
+	}
+
+	action, band := evaluateAlert(nil, 99, bands, 5)
+	assert.Equal(t, alertTrigger, action)
+	assert.Equal(t, "critical", band.Severity)
+}
+
+func TestEvaluateAlert_NoBands(t *testing.T) {
+	action, band := evaluateAlert(nil, 100, nil, 5)
+	assert.Equal(t, alertNone, action)
+	assert.Nil(t, band)
+}
+
+func TestService_UtilizationBands(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{AlertWarningThreshold: 80, AlertCriticalThreshold: 95}}
+	bands := service.utilizationBands()
+	require.Len(t, bands, 2)
+	assert.Equal(t, "warning", bands[0].Severity)
+	assert.Equal(t, "critical", bands[1].Severity)
+
+	disabled := &Service{config: &config.BudgetConfig{}}
+	assert.Empty(t, disabled.utilizationBands())
+}
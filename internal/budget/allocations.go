@@ -0,0 +1,298 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const (
+	// defaultAllocationPreviewCount is how many allocations a preview projects by default
+	defaultAllocationPreviewCount = 12
+
+	// maxAllocationPreviewCount bounds how far ahead a preview projects
+	maxAllocationPreviewCount = 100
+)
+
+// PreviewAllocations projects an account's next allocations from its active schedules,
+// stepping each schedule by its frequency in the account's time zone and fiscal year as
+// process_pending_allocations does. A schedule stops at its end date or once its total
+// budget is allocated, so its last allocation may be a partial top-up. With
+// budget.prorate_first_allocation, a schedule's first allocation is prorated too.
+func (s *Service) PreviewAllocations(ctx context.Context, slurmAccount string, count int) (*api.AllocationPreviewResponse, error) {
+	if count == 0 {
+		count = defaultAllocationPreviewCount
+	}
+	if count < 1 || count > maxAllocationPreviewCount {
+		return nil, api.NewValidationError("count", "must be between 1 and 100")
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.allocationQueries.ListActiveSchedules(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	fiscal := s.allocationFiscalYearStart(account)
+	loc := account.Location()
+
+	var allocations []api.ProjectedAllocation
+	for _, schedule := range schedules {
+		projected, err := projectAllocations(schedule, fiscal, loc, count, s.config.ProrateFirstAllocation)
+		if err != nil {
+			return nil, api.NewBudgetError(api.ErrCodeInternal, err.Error())
+		}
+		allocations = append(allocations, projected...)
+	}
+
+	sort.SliceStable(allocations, func(i, j int) bool {
+		return allocations[i].AllocationDate.Before(allocations[j].AllocationDate)
+	})
+	if len(allocations) > count {
+		allocations = allocations[:count]
+	}
+
+	resp := &api.AllocationPreviewResponse{
+		Account:     slurmAccount,
+		Allocations: []api.ProjectedAllocation{},
+	}
+	for _, allocation := range allocations {
+		resp.Allocations = append(resp.Allocations, allocation)
+		resp.TotalAmount += allocation.Amount
+	}
+	resp.Count = len(resp.Allocations)
+	resp.TotalAmount = roundCents(resp.TotalAmount)
+
+	return resp, nil
+}
+
+// CreateAllocationSchedule adds an allocation schedule to an account. An account may hold
+// several, such as a monthly compute allocation alongside a one-time equipment supplement,
+// and each allocates on its own cadence.
+func (s *Service) CreateAllocationSchedule(ctx context.Context, slurmAccount string, req *api.CreateAllocationScheduleRequest) (*api.BudgetAllocationSchedule, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+	if !account.IsActive() {
+		return nil, api.NewAccountInactiveError(account.SlurmAccount, account.Status)
+	}
+
+	schedule, err := s.allocationQueries.CreateSchedule(ctx, account.ID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("account", slurmAccount).
+		Int64("schedule_id", schedule.ID).
+		Float64("total_budget", schedule.TotalBudget).
+		Str("frequency", schedule.AllocationFrequency).
+		Msg("Allocation schedule created")
+
+	return schedule, nil
+}
+
+// ListAccountSchedules reports each of an account's allocation schedules, whatever its
+// status, with the allocations it has made, and summarizes them
+func (s *Service) ListAccountSchedules(ctx context.Context, slurmAccount string) (*api.AccountSchedulesResponse, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.allocationQueries.ListSchedules(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	allocations, err := s.allocationQueries.ListAllocations(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bySchedule := make(map[int64][]*api.BudgetAllocation, len(schedules))
+	for _, allocation := range allocations {
+		bySchedule[allocation.ScheduleID] = append(bySchedule[allocation.ScheduleID], allocation)
+	}
+
+	resp := &api.AccountSchedulesResponse{
+		Account:   slurmAccount,
+		Summary:   summarizeSchedules(schedules),
+		Schedules: []api.ScheduleAllocations{},
+	}
+	for _, schedule := range schedules {
+		scheduled := bySchedule[schedule.ID]
+		if scheduled == nil {
+			scheduled = []*api.BudgetAllocation{}
+		}
+		resp.Schedules = append(resp.Schedules, api.ScheduleAllocations{Schedule: schedule, Allocations: scheduled})
+	}
+
+	return resp, nil
+}
+
+// summarizeSchedules totals an account's schedules, leaving out cancelled ones. The next
+// allocation is the soonest any active schedule makes, and its amount the total every
+// schedule due then allocates. The frequency is given only when the schedules share one.
+func summarizeSchedules(schedules []*api.BudgetAllocationSchedule) api.AllocationScheduleSummary {
+	var summary api.AllocationScheduleSummary
+	frequencies := map[string]bool{}
+	for _, schedule := range schedules {
+		if schedule.Status == "cancelled" {
+			continue
+		}
+		summary.TotalBudget += schedule.TotalBudget
+		summary.AllocatedToDate += schedule.AllocatedToDate
+		summary.RemainingBudget += schedule.RemainingBudget
+		frequencies[schedule.AllocationFrequency] = true
+
+		if schedule.Status != scheduleStatusActive || !schedule.AutoAllocate || schedule.NextAllocationDate.IsZero() {
+			continue
+		}
+		amount := math.Min(schedule.AllocationAmount, schedule.TotalBudget-schedule.AllocatedToDate)
+		next := schedule.NextAllocationDate
+		switch {
+		case summary.NextAllocationDate == nil || next.Before(*summary.NextAllocationDate):
+			summary.NextAllocationDate = &next
+			summary.NextAllocationAmount = amount
+		case next.Equal(*summary.NextAllocationDate):
+			summary.NextAllocationAmount += amount
+		}
+	}
+
+	if len(frequencies) == 1 {
+		for frequency := range frequencies {
+			summary.AllocationFrequency = frequency
+		}
+	}
+	summary.TotalBudget = roundCents(summary.TotalBudget)
+	summary.AllocatedToDate = roundCents(summary.AllocatedToDate)
+	summary.RemainingBudget = roundCents(summary.RemainingBudget)
+	summary.NextAllocationAmount = roundCents(summary.NextAllocationAmount)
+	return summary
+}
+
+// allocationFiscalYearStart returns the fiscal year quarterly and yearly allocations step
+// by, or nil when neither the account nor the configuration sets one and they step by
+// calendar months
+func (s *Service) allocationFiscalYearStart(account *api.BudgetAccount) *api.FiscalYearStart {
+	if account.FiscalYearStart == nil && s.config.FiscalYearStart == "" {
+		return nil
+	}
+	fiscal := s.fiscalYearStartFor(account)
+	return &fiscal
+}
+
+// projectAllocations lists up to count allocations a schedule will make from its next
+// allocation date. Each allocation is capped at the budget the schedule has left. With
+// prorateFirst, a schedule that has allocated nothing yet first allocates the share of
+// its period left and then steps from the start of the next period.
+func projectAllocations(schedule *api.BudgetAllocationSchedule, fiscal *api.FiscalYearStart, loc *time.Location, count int, prorateFirst bool) ([]api.ProjectedAllocation, error) {
+	var allocations []api.ProjectedAllocation
+
+	remaining := roundCents(schedule.TotalBudget - schedule.AllocatedToDate)
+	date := schedule.NextAllocationDate.In(loc)
+	prorate := prorateFirst && schedule.AllocatedToDate == 0
+	for len(allocations) < count && remaining > 0 {
+		if schedule.EndDate != nil && date.After(*schedule.EndDate) {
+			break
+		}
+
+		amount := schedule.AllocationAmount
+		var periodEnd time.Time
+		if prorate {
+			var periods api.FiscalYearStart
+			if fiscal != nil {
+				periods = *fiscal
+			}
+			share, end, err := periods.RemainingShare(date, schedule.AllocationFrequency, loc)
+			if err != nil {
+				return nil, err
+			}
+			amount, periodEnd = roundCents(amount*share), end
+		}
+		amount = math.Min(amount, remaining)
+		remaining = roundCents(remaining - amount)
+		allocations = append(allocations, api.ProjectedAllocation{
+			ScheduleID:      schedule.ID,
+			AllocationDate:  date,
+			Amount:          amount,
+			Partial:         amount < schedule.AllocationAmount,
+			RemainingBudget: remaining,
+		})
+
+		if prorate {
+			date, prorate = periodEnd, false
+			continue
+		}
+
+		var err error
+		if fiscal != nil {
+			date, err = fiscal.NextAllocationDate(date, schedule.AllocationFrequency, loc)
+		} else {
+			date, err = api.NextAllocationDate(date, schedule.AllocationFrequency, loc)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return allocations, nil
+}
+
+// Allocation schedule statuses a bulk pause or resume moves between
+const (
+	scheduleStatusActive = "active"
+	scheduleStatusPaused = "paused"
+)
+
+// PauseAllocationSchedules pauses every active schedule the request selects, e.g. at fiscal
+// year-end. Paused schedules make no allocations until they are resumed.
+func (s *Service) PauseAllocationSchedules(ctx context.Context, req *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error) {
+	return s.setSchedulesStatus(ctx, scheduleStatusActive, scheduleStatusPaused, req)
+}
+
+// ResumeAllocationSchedules reactivates every paused schedule the request selects.
+// Allocations that came due while a schedule was paused are made as it catches up.
+func (s *Service) ResumeAllocationSchedules(ctx context.Context, req *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error) {
+	return s.setSchedulesStatus(ctx, scheduleStatusPaused, scheduleStatusActive, req)
+}
+
+func (s *Service) setSchedulesStatus(ctx context.Context, from, to string, req *api.BulkScheduleStatusRequest) (*api.BulkScheduleStatusResponse, error) {
+	var ids []int64
+	err := s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var err error
+		ids, err = s.allocationQueries.SetSchedulesStatus(ctx, tx, from, to, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("status", to).
+		Str("funding_agency", req.FundingAgency).
+		Str("cost_center", req.CostCenter).
+		Int("schedules", len(ids)).
+		Msg("Changed allocation schedule status in bulk")
+
+	return &api.BulkScheduleStatusResponse{Status: to, Updated: len(ids), ScheduleIDs: ids}, nil
+}
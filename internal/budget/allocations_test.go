@@ -0,0 +1,189 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestProjectAllocations_PartialFinalAllocation(t *testing.T) {
+	schedule := &api.BudgetAllocationSchedule{
+		ID:                  7,
+		TotalBudget:         1000,
+		AllocationAmount:    300,
+		AllocationFrequency: "monthly",
+		NextAllocationDate:  time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC),
+		AllocatedToDate:     200,
+	}
+
+	allocations, err := projectAllocations(schedule, nil, time.UTC, 12, false)
+	require.NoError(t, err)
+	require.Len(t, allocations, 3)
+
+	// Month steps clamp to the end of February
+	assert.Equal(t, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), allocations[0].AllocationDate)
+	assert.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), allocations[1].AllocationDate)
+	assert.Equal(t, time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC), allocations[2].AllocationDate)
+
+	assert.Equal(t, 300.0, allocations[0].Amount)
+	assert.False(t, allocations[0].Partial)
+	assert.Equal(t, 500.0, allocations[0].RemainingBudget)
+	assert.Equal(t, 200.0, allocations[2].Amount)
+	assert.True(t, allocations[2].Partial)
+	assert.Equal(t, 0.0, allocations[2].RemainingBudget)
+	assert.Equal(t, int64(7), allocations[2].ScheduleID)
+}
+
+func TestProjectAllocations_StopsAtEndDateAndCount(t *testing.T) {
+	end := time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)
+	schedule := &api.BudgetAllocationSchedule{
+		TotalBudget:         10000,
+		AllocationAmount:    100,
+		AllocationFrequency: "weekly",
+		NextAllocationDate:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:             &end,
+	}
+
+	allocations, err := projectAllocations(schedule, nil, time.UTC, 12, false)
+	require.NoError(t, err)
+	require.Len(t, allocations, 3, "January 1st, 8th and 15th fall before the end date")
+	assert.Equal(t, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), allocations[2].AllocationDate)
+
+	schedule.EndDate = nil
+	allocations, err = projectAllocations(schedule, nil, time.UTC, 2, false)
+	require.NoError(t, err)
+	assert.Len(t, allocations, 2)
+}
+
+func TestProjectAllocations_FiscalQuarters(t *testing.T) {
+	july := api.FiscalYearStart{Month: time.July, Day: 1}
+	schedule := &api.BudgetAllocationSchedule{
+		TotalBudget:         1000,
+		AllocationAmount:    250,
+		AllocationFrequency: "quarterly",
+		NextAllocationDate:  time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	allocations, err := projectAllocations(schedule, &july, time.UTC, 3, false)
+	require.NoError(t, err)
+	require.Len(t, allocations, 3)
+	assert.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), allocations[1].AllocationDate)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), allocations[2].AllocationDate)
+}
+
+func TestProjectAllocations_ProratedFirstAllocation(t *testing.T) {
+	// An account created mid-month, its first $310 monthly allocation due on January 15th
+	schedule := &api.BudgetAllocationSchedule{
+		TotalBudget:         1000,
+		AllocationAmount:    310,
+		AllocationFrequency: "monthly",
+		NextAllocationDate:  time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	allocations, err := projectAllocations(schedule, nil, time.UTC, 12, false)
+	require.NoError(t, err)
+	require.Len(t, allocations, 4)
+	assert.Equal(t, 310.0, allocations[0].Amount)
+	assert.Equal(t, time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC), allocations[1].AllocationDate)
+
+	// Prorated, January's 17 remaining days get $170, then full months from February 1st
+	// until the last $210 of the total tops it up
+	allocations, err = projectAllocations(schedule, nil, time.UTC, 12, true)
+	require.NoError(t, err)
+	require.Len(t, allocations, 4)
+	assert.Equal(t, 170.0, allocations[0].Amount)
+	assert.True(t, allocations[0].Partial)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), allocations[1].AllocationDate)
+	assert.Equal(t, 310.0, allocations[1].Amount)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), allocations[2].AllocationDate)
+	assert.Equal(t, 210.0, allocations[3].Amount)
+	assert.Equal(t, 0.0, allocations[3].RemainingBudget)
+
+	// A schedule that has already allocated is past its first allocation
+	schedule.AllocatedToDate = 310
+	allocations, err = projectAllocations(schedule, nil, time.UTC, 1, true)
+	require.NoError(t, err)
+	assert.Equal(t, 310.0, allocations[0].Amount)
+}
+
+func TestAllocationFiscalYearStart(t *testing.T) {
+	october := "10-01"
+
+	calendar := &Service{config: &config.BudgetConfig{}}
+	assert.Nil(t, calendar.allocationFiscalYearStart(&api.BudgetAccount{}))
+	require.NotNil(t, calendar.allocationFiscalYearStart(&api.BudgetAccount{FiscalYearStart: &october}))
+
+	configured := &Service{config: &config.BudgetConfig{FiscalYearStart: "07-01"}}
+	fiscal := configured.allocationFiscalYearStart(&api.BudgetAccount{})
+	require.NotNil(t, fiscal)
+	assert.Equal(t, time.July, fiscal.Month)
+}
+
+func TestSummarizeSchedules(t *testing.T) {
+	march := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	compute := &api.BudgetAllocationSchedule{
+		ID: 1, TotalBudget: 1200, AllocationAmount: 100, AllocationFrequency: "monthly",
+		NextAllocationDate: march, AllocatedToDate: 200, RemainingBudget: 1000,
+		Status: "active", AutoAllocate: true,
+	}
+	supplement := &api.BudgetAllocationSchedule{
+		ID: 2, TotalBudget: 5000, AllocationAmount: 5000, AllocationFrequency: "yearly",
+		NextAllocationDate: time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC), RemainingBudget: 5000,
+		Status: "active", AutoAllocate: true,
+	}
+
+	t.Run("soonest schedule is next", func(t *testing.T) {
+		summary := summarizeSchedules([]*api.BudgetAllocationSchedule{compute, supplement})
+		assert.Equal(t, 6200.0, summary.TotalBudget)
+		assert.Equal(t, 200.0, summary.AllocatedToDate)
+		assert.Equal(t, 6000.0, summary.RemainingBudget)
+		require.NotNil(t, summary.NextAllocationDate)
+		assert.Equal(t, supplement.NextAllocationDate, *summary.NextAllocationDate)
+		assert.Equal(t, 5000.0, summary.NextAllocationAmount)
+		assert.Empty(t, summary.AllocationFrequency)
+	})
+
+	t.Run("schedules due together add up", func(t *testing.T) {
+		together := *supplement
+		together.NextAllocationDate = march
+		summary := summarizeSchedules([]*api.BudgetAllocationSchedule{compute, &together})
+		assert.Equal(t, march, *summary.NextAllocationDate)
+		assert.Equal(t, 5100.0, summary.NextAllocationAmount)
+	})
+
+	t.Run("completed and paused schedules allocate nothing next", func(t *testing.T) {
+		done := *supplement
+		done.Status, done.NextAllocationDate = "completed", time.Time{}
+		done.AllocatedToDate, done.RemainingBudget = 5000, 0
+		paused := *compute
+		paused.ID, paused.Status = 3, "paused"
+
+		summary := summarizeSchedules([]*api.BudgetAllocationSchedule{compute, &done, &paused})
+		assert.Equal(t, march, *summary.NextAllocationDate)
+		assert.Equal(t, 100.0, summary.NextAllocationAmount)
+		assert.Equal(t, 5400.0, summary.AllocatedToDate)
+	})
+
+	t.Run("cancelled schedules are left out", func(t *testing.T) {
+		cancelled := *supplement
+		cancelled.Status = "cancelled"
+		summary := summarizeSchedules([]*api.BudgetAllocationSchedule{compute, &cancelled})
+		assert.Equal(t, 1200.0, summary.TotalBudget)
+		assert.Equal(t, "monthly", summary.AllocationFrequency)
+	})
+
+	t.Run("no schedules", func(t *testing.T) {
+		summary := summarizeSchedules(nil)
+		assert.Nil(t, summary.NextAllocationDate)
+		assert.Zero(t, summary.TotalBudget)
+	})
+}
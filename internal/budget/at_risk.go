@@ -0,0 +1,92 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const (
+	// defaultAtRiskWindowDays is how far ahead the at-risk list looks by default
+	defaultAtRiskWindowDays = 14
+
+	// maxAtRiskWindowDays bounds how far ahead the at-risk list looks
+	maxAtRiskWindowDays = 365
+)
+
+// ListAtRiskAccounts returns the active accounts whose spendable budget is projected to
+// run out within withinDays days, 14 when zero, at their recent burn rate: the same rate
+// and projection a budget simulation uses. Accounts that run out soonest come first.
+func (s *Service) ListAtRiskAccounts(ctx context.Context, withinDays int) (*api.AtRiskAccountsResponse, error) {
+	if withinDays == 0 {
+		withinDays = defaultAtRiskWindowDays
+	}
+	if withinDays < 1 || withinDays > maxAtRiskWindowDays {
+		return nil, api.NewValidationError("within_days", "must be between 1 and 365")
+	}
+
+	accounts, err := s.accountQueries.ListAccounts(ctx, &api.ListAccountsRequest{Status: "active"})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	resp := &api.AtRiskAccountsResponse{WithinDays: withinDays, Accounts: []api.AtRiskAccount{}}
+	for _, account := range accounts {
+		if account.BudgetLimit <= 0 {
+			continue
+		}
+		rate, err := s.recentBurnRate(ctx, account, now)
+		if err != nil {
+			return nil, err
+		}
+		if atRisk := atRiskAccount(account, rate, now, withinDays); atRisk != nil {
+			resp.Accounts = append(resp.Accounts, *atRisk)
+		}
+	}
+
+	sortAtRisk(resp.Accounts)
+	return resp, nil
+}
+
+// atRiskAccount returns an account as at risk when, spending dailyRate a day, its spendable
+// budget runs out within withinDays days of now and before the account ends, else nil. An
+// account without a limit has nothing to run out of.
+func atRiskAccount(account *api.BudgetAccount, dailyRate float64, now time.Time, withinDays int) *api.AtRiskAccount {
+	if account.BudgetLimit <= 0 {
+		return nil
+	}
+
+	available := account.SpendableAvailable()
+	depletion := projectDepletion(now, available, dailyRate, 0, 1)
+	if depletion == nil || depletion.After(now.AddDate(0, 0, withinDays)) || depletion.After(account.EndDate) {
+		return nil
+	}
+
+	return &api.AtRiskAccount{
+		Account:                account.SlurmAccount,
+		Description:            account.Description,
+		BudgetLimit:            account.BudgetLimit,
+		BudgetAvailable:        roundCents(available),
+		DailyBurnRate:          roundCents(dailyRate),
+		ProjectedDepletionDate: *depletion,
+		DaysUntilDepletion:     math.Round(depletion.Sub(now).Hours()/24*10) / 10,
+	}
+}
+
+// sortAtRisk orders at-risk accounts by projected depletion, soonest first, then by name
+func sortAtRisk(accounts []api.AtRiskAccount) {
+	sort.SliceStable(accounts, func(i, j int) bool {
+		if !accounts[i].ProjectedDepletionDate.Equal(accounts[j].ProjectedDepletionDate) {
+			return accounts[i].ProjectedDepletionDate.Before(accounts[j].ProjectedDepletionDate)
+		}
+		return accounts[i].Account < accounts[j].Account
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestAtRiskAccount(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	account := func(limit, used float64) *api.BudgetAccount {
+		return &api.BudgetAccount{
+			SlurmAccount: "proj001",
+			BudgetLimit:  limit,
+			BudgetUsed:   used,
+			StartDate:    now.AddDate(0, -6, 0),
+			EndDate:      now.AddDate(1, 0, 0),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		account   *api.BudgetAccount
+		dailyRate float64
+		atRisk    bool
+		days      float64
+	}{
+		// $500 left at $100/day lasts 5 days
+		{name: "fast burn", account: account(1000, 500), dailyRate: 100, atRisk: true, days: 5},
+		// $500 left at $35.71/day lasts exactly 14 days
+		{name: "at the window's edge", account: account(1000, 500), dailyRate: 500.0 / 14, atRisk: true, days: 14},
+		// $500 left at $20/day lasts 25 days
+		{name: "slow burn", account: account(1000, 500), dailyRate: 20},
+		{name: "no spending", account: account(1000, 500)},
+		{name: "already depleted", account: account(1000, 1000), dailyRate: 10, atRisk: true, days: 0},
+		{name: "no limit", account: account(0, 500), dailyRate: 100},
+		{
+			name: "account ends before depleting",
+			account: func() *api.BudgetAccount {
+				a := account(1000, 500)
+				a.EndDate = now.AddDate(0, 0, 3)
+				return a
+			}(),
+			dailyRate: 100,
+		},
+		{
+			// Reserved and held budget is not spendable: $200 left at $100/day
+			name: "reserve counts against the budget",
+			account: func() *api.BudgetAccount {
+				a := account(1000, 500)
+				a.BudgetHeld = 100
+				a.ReservedAmount = 200
+				return a
+			}(),
+			dailyRate: 100,
+			atRisk:    true,
+			days:      2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := atRiskAccount(tt.account, tt.dailyRate, now, 14)
+			if !tt.atRisk {
+				assert.Nil(t, got)
+				return
+			}
+			require.NotNil(t, got)
+			assert.Equal(t, "proj001", got.Account)
+			assert.Equal(t, tt.days, got.DaysUntilDepletion)
+			assert.WithinDuration(t, now.Add(time.Duration(tt.days*24*float64(time.Hour))), got.ProjectedDepletionDate, time.Minute)
+		})
+	}
+}
+
+func TestAtRiskAccount_Window(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	account := &api.BudgetAccount{
+		SlurmAccount: "proj001",
+		BudgetLimit:  1000,
+		BudgetUsed:   500,
+		StartDate:    now.AddDate(0, -6, 0),
+		EndDate:      now.AddDate(1, 0, 0),
+	}
+
+	// $500 left at $20/day lasts 25 days
+	assert.Nil(t, atRiskAccount(account, 20, now, 14))
+	got := atRiskAccount(account, 20, now, 30)
+	require.NotNil(t, got)
+	assert.Equal(t, 500.0, got.BudgetAvailable)
+	assert.Equal(t, 20.0, got.DailyBurnRate)
+	assert.Equal(t, 25.0, got.DaysUntilDepletion)
+}
+
+func TestSortAtRisk(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	accounts := []api.AtRiskAccount{
+		{Account: "slow", ProjectedDepletionDate: now.AddDate(0, 0, 10)},
+		{Account: "proj002", ProjectedDepletionDate: now.AddDate(0, 0, 2)},
+		{Account: "proj001", ProjectedDepletionDate: now.AddDate(0, 0, 2)},
+		{Account: "depleted", ProjectedDepletionDate: now},
+	}
+
+	sortAtRisk(accounts)
+
+	var names []string
+	for _, a := range accounts {
+		names = append(names, a.Account)
+	}
+	assert.Equal(t, []string{"depleted", "proj001", "proj002", "slow"}, names)
+}
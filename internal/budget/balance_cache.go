@@ -0,0 +1,125 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// balanceLoader reads an account with the IDs of its descendants, whose transactions roll
+// up into its balances
+type balanceLoader func(ctx context.Context, slurmAccount string) (*api.BudgetAccount, []int64, error)
+
+// balanceCache keeps the accounts read by the read-heavy decision endpoints, so they need
+// not query the database on every call. An entry is dropped as soon as the database
+// reports a write that may change its balances: one to the account or any descendant, or
+// a change to the hierarchy. Entries also expire after the TTL, in case a change is never
+// reported. Budget checks never read from it.
+type balanceCache struct {
+	ttl  time.Duration
+	now  func() time.Time
+	load balanceLoader
+
+	mu sync.Mutex
+	// generation counts invalidations, so a load that raced one is not kept
+	generation uint64
+	entries    map[string]*balanceCacheEntry
+}
+
+// balanceCacheEntry is a cached account and the accounts whose writes invalidate it
+type balanceCacheEntry struct {
+	account *api.BudgetAccount
+	watched map[int64]bool
+	expires time.Time
+}
+
+// newBalanceCache returns a cache keeping accounts read with load for ttl
+func newBalanceCache(ttl time.Duration, load balanceLoader) *balanceCache {
+	return &balanceCache{
+		ttl:     ttl,
+		now:     time.Now,
+		load:    load,
+		entries: make(map[string]*balanceCacheEntry),
+	}
+}
+
+// get returns the account, from the cache while its entry is current and otherwise read
+// afresh and kept. A read that an invalidation overtook is returned but not kept, since
+// it may predate the write. The account returned is a copy the caller may modify.
+func (c *balanceCache) get(ctx context.Context, slurmAccount string) (*api.BudgetAccount, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[slurmAccount]; ok && c.now().Before(entry.expires) {
+		account := *entry.account
+		c.mu.Unlock()
+		return &account, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	account, descendants, err := c.load(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if c.generation == generation {
+		watched := make(map[int64]bool, len(descendants)+1)
+		watched[account.ID] = true
+		for _, id := range descendants {
+			watched[id] = true
+		}
+		c.entries[slurmAccount] = &balanceCacheEntry{account: account, watched: watched, expires: c.now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+
+	copied := *account
+	return &copied, nil
+}
+
+// invalidate drops the entries whose balances a write to the account may have changed:
+// its own and its ancestors'. database.AnyAccount drops every entry.
+func (c *balanceCache) invalidate(accountID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if accountID == database.AnyAccount {
+		c.entries = make(map[string]*balanceCacheEntry)
+		return
+	}
+	for slurmAccount, entry := range c.entries {
+		if entry.watched[accountID] {
+			delete(c.entries, slurmAccount)
+		}
+	}
+}
+
+// loadAccountBalance reads an account for the balance cache, with its descendants' IDs
+func (s *Service) loadAccountBalance(ctx context.Context, slurmAccount string) (*api.BudgetAccount, []int64, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+	descendants, err := s.accountQueries.ListDescendantIDs(ctx, account.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return account, descendants, nil
+}
+
+// GetAccountBalance returns an account for the decision endpoints, which only advise:
+// through the balance cache when budget.balance_cache_ttl enables it, and otherwise from
+// the database. Approving or rejecting a job must read the database instead.
+func (s *Service) GetAccountBalance(ctx context.Context, slurmAccount string) (*api.BudgetAccount, error) {
+	if s.balanceCache == nil {
+		return s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	}
+	return s.balanceCache.get(ctx, slurmAccount)
+}
@@ -0,0 +1,145 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// fakeBalances is a balance loader over a fixed hierarchy: org (1) is the parent of lab (2)
+type fakeBalances struct {
+	used  map[string]float64
+	loads int
+}
+
+func (f *fakeBalances) load(ctx context.Context, slurmAccount string) (*api.BudgetAccount, []int64, error) {
+	f.loads++
+	switch slurmAccount {
+	case "org":
+		return &api.BudgetAccount{ID: 1, SlurmAccount: "org", BudgetUsed: f.used["org"]}, []int64{2}, nil
+	case "lab":
+		return &api.BudgetAccount{ID: 2, SlurmAccount: "lab", BudgetUsed: f.used["lab"]}, nil, nil
+	}
+	return nil, nil, api.NewAccountNotFoundError(slurmAccount)
+}
+
+func TestBalanceCache_InvalidatedByTransaction(t *testing.T) {
+	balances := &fakeBalances{used: map[string]float64{"org": 10, "lab": 10}}
+	cache := newBalanceCache(time.Hour, balances.load)
+	ctx := context.Background()
+
+	for _, name := range []string{"org", "lab", "org", "lab"} {
+		_, err := cache.get(ctx, name)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, balances.loads)
+
+	// A transaction on lab changes lab's balances and its parent's
+	balances.used["lab"], balances.used["org"] = 22, 22
+	cache.invalidate(2)
+
+	lab, err := cache.get(ctx, "lab")
+	require.NoError(t, err)
+	assert.Equal(t, 22.0, lab.BudgetUsed)
+	org, err := cache.get(ctx, "org")
+	require.NoError(t, err)
+	assert.Equal(t, 22.0, org.BudgetUsed)
+	assert.Equal(t, 4, balances.loads)
+
+	// A transaction on org leaves lab's entry in place
+	cache.invalidate(1)
+	_, err = cache.get(ctx, "lab")
+	require.NoError(t, err)
+	_, err = cache.get(ctx, "org")
+	require.NoError(t, err)
+	assert.Equal(t, 5, balances.loads)
+}
+
+func TestBalanceCache_AnyAccountFlushes(t *testing.T) {
+	balances := &fakeBalances{}
+	cache := newBalanceCache(time.Hour, balances.load)
+	ctx := context.Background()
+
+	_, _ = cache.get(ctx, "org")
+	_, _ = cache.get(ctx, "lab")
+	cache.invalidate(database.AnyAccount)
+	_, _ = cache.get(ctx, "org")
+	_, _ = cache.get(ctx, "lab")
+
+	assert.Equal(t, 4, balances.loads)
+}
+
+func TestBalanceCache_Expires(t *testing.T) {
+	balances := &fakeBalances{}
+	cache := newBalanceCache(time.Minute, balances.load)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, _ = cache.get(ctx, "lab")
+	now = now.Add(59 * time.Second)
+	_, _ = cache.get(ctx, "lab")
+	assert.Equal(t, 1, balances.loads)
+
+	now = now.Add(time.Second)
+	_, _ = cache.get(ctx, "lab")
+	assert.Equal(t, 2, balances.loads)
+}
+
+func TestBalanceCache_DiscardsLoadOvertakenByInvalidation(t *testing.T) {
+	var cache *balanceCache
+	loads := 0
+	cache = newBalanceCache(time.Hour, func(ctx context.Context, slurmAccount string) (*api.BudgetAccount, []int64, error) {
+		loads++
+		if loads == 1 {
+			// A write lands while the first read is in flight
+			cache.invalidate(2)
+		}
+		return &api.BudgetAccount{ID: 2, SlurmAccount: slurmAccount}, nil, nil
+	})
+	ctx := context.Background()
+
+	_, err := cache.get(ctx, "lab")
+	require.NoError(t, err)
+	_, err = cache.get(ctx, "lab")
+	require.NoError(t, err)
+	_, err = cache.get(ctx, "lab")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, loads)
+}
+
+func TestBalanceCache_ReturnsCopies(t *testing.T) {
+	balances := &fakeBalances{used: map[string]float64{"lab": 10}}
+	cache := newBalanceCache(time.Hour, balances.load)
+	ctx := context.Background()
+
+	first, err := cache.get(ctx, "lab")
+	require.NoError(t, err)
+	first.BudgetUsed = 99
+
+	second, err := cache.get(ctx, "lab")
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, second.BudgetUsed)
+}
+
+func TestBalanceCache_LoadErrorNotCached(t *testing.T) {
+	balances := &fakeBalances{}
+	cache := newBalanceCache(time.Hour, balances.load)
+
+	_, err := cache.get(context.Background(), "missing")
+	assert.Error(t, err)
+	_, err = cache.get(context.Background(), "missing")
+	assert.Error(t, err)
+	assert.Equal(t, 2, balances.loads)
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// defaultWorkerBatchSize is how many schedules or holds a worker batch takes when the
+	// configuration does not say
+	defaultWorkerBatchSize = 100
+
+	// defaultWorkerConcurrency is how many database connections the background workers use
+	// at once when the configuration does not say
+	defaultWorkerConcurrency = 4
+)
+
+// workerBatchSize returns the configured worker batch size, or the default
+func (s *Service) workerBatchSize() int {
+	if s.config.WorkerBatchSize > 0 {
+		return s.config.WorkerBatchSize
+	}
+	return defaultWorkerBatchSize
+}
+
+// workerConcurrency returns the configured worker concurrency, or the default
+func (s *Service) workerConcurrency() int {
+	if s.config.WorkerConcurrency > 0 {
+		return s.config.WorkerConcurrency
+	}
+	return defaultWorkerConcurrency
+}
+
+// splitGroupedBatches splits items into consecutive [start, end) ranges of at most size
+// items, except that a run of items in the same group, given in order in groups, is never
+// split: the range holding it grows to take all of it
+func splitGroupedBatches(groups []int64, size int) [][2]int {
+	var batches [][2]int
+	for start := 0; start < len(groups); {
+		end := min(start+size, len(groups))
+		for end < len(groups) && groups[end] == groups[end-1] {
+			end++
+		}
+		batches = append(batches, [2]int{start, end})
+		start = end
+	}
+	return batches
+}
+
+// runConcurrently calls work for each index below n on at most workers goroutines at once
+// and waits for them all. Every call is made even if another fails; the errors are
+// returned by index. Once ctx is cancelled no further calls start and those not started
+// report the context's error.
+func runConcurrently(ctx context.Context, n, workers int, work func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	if n == 0 {
+		return errs
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = work(ctx, i)
+			}
+		}()
+	}
+
+	i := 0
+dispatch:
+	for ; i < n; i++ {
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	for ; i < n; i++ {
+		errs[i] = ctx.Err()
+	}
+	return errs
+}
@@ -0,0 +1,259 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestWorkerSettings(t *testing.T) {
+	defaults := &Service{config: &config.BudgetConfig{}}
+	assert.Equal(t, defaultWorkerBatchSize, defaults.workerBatchSize())
+	assert.Equal(t, defaultWorkerConcurrency, defaults.workerConcurrency())
+
+	configured := &Service{config: &config.BudgetConfig{WorkerBatchSize: 25, WorkerConcurrency: 2}}
+	assert.Equal(t, 25, configured.workerBatchSize())
+	assert.Equal(t, 2, configured.workerConcurrency())
+}
+
+func TestSplitGroupedBatches(t *testing.T) {
+	distinct := func(n int) []int64 {
+		groups := make([]int64, n)
+		for i := range groups {
+			groups[i] = int64(i)
+		}
+		return groups
+	}
+	assert.Empty(t, splitGroupedBatches(nil, 100))
+	assert.Equal(t, [][2]int{{0, 100}}, splitGroupedBatches(distinct(100), 100))
+	assert.Equal(t, [][2]int{{0, 100}, {100, 200}, {200, 250}}, splitGroupedBatches(distinct(250), 100))
+
+	// A group straddling a batch boundary stays whole in the earlier batch
+	assert.Equal(t, [][2]int{{0, 4}, {4, 6}}, splitGroupedBatches([]int64{1, 2, 3, 3, 4, 5}, 3))
+	assert.Equal(t, [][2]int{{0, 5}}, splitGroupedBatches([]int64{7, 7, 7, 7, 7}, 2))
+}
+
+// concurrencyTracker records the most calls that were ever in flight at once
+type concurrencyTracker struct {
+	current, peak int64
+}
+
+func (c *concurrencyTracker) enter() {
+	n := atomic.AddInt64(&c.current, 1)
+	for {
+		peak := atomic.LoadInt64(&c.peak)
+		if n <= peak || atomic.CompareAndSwapInt64(&c.peak, peak, n) {
+			return
+		}
+	}
+}
+
+func (c *concurrencyTracker) exit() {
+	atomic.AddInt64(&c.current, -1)
+}
+
+func TestRunConcurrently(t *testing.T) {
+	t.Run("bounds the workers in flight", func(t *testing.T) {
+		var tracker concurrencyTracker
+		var calls int64
+		errs := runConcurrently(context.Background(), 50, 3, func(ctx context.Context, i int) error {
+			tracker.enter()
+			defer tracker.exit()
+			atomic.AddInt64(&calls, 1)
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+
+		assert.Len(t, errs, 50)
+		assert.Equal(t, int64(50), calls)
+		assert.LessOrEqual(t, tracker.peak, int64(3))
+		assert.Greater(t, tracker.peak, int64(1))
+	})
+
+	t.Run("reports errors by index", func(t *testing.T) {
+		boom := errors.New("boom")
+		errs := runConcurrently(context.Background(), 5, 2, func(ctx context.Context, i int) error {
+			if i == 3 {
+				return boom
+			}
+			return nil
+		})
+		assert.Equal(t, []error{nil, nil, nil, boom, nil}, errs)
+	})
+
+	t.Run("stops dispatching once cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var calls int64
+		errs := runConcurrently(ctx, 100, 1, func(ctx context.Context, i int) error {
+			if atomic.AddInt64(&calls, 1) == 5 {
+				cancel()
+			}
+			return nil
+		})
+		assert.Less(t, calls, int64(100))
+		assert.ErrorIs(t, errs[99], context.Canceled)
+	})
+}
+
+func TestAllocateInBatches(t *testing.T) {
+	ids := make([]int64, 250)
+	for i := range ids {
+		ids[i] = int64(i + 1)
+	}
+
+	t.Run("large backlog is allocated in bounded batches", func(t *testing.T) {
+		var tracker concurrencyTracker
+		var mu sync.Mutex
+		var sizes []int
+		seen := map[int64]bool{}
+
+		resp, err := allocateInBatches(context.Background(), ids, ids, 100, 2,
+			func(ctx context.Context, batch []int64) ([]api.ProcessedAllocation, error) {
+				tracker.enter()
+				defer tracker.exit()
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				defer mu.Unlock()
+				sizes = append(sizes, len(batch))
+				var allocations []api.ProcessedAllocation
+				for _, id := range batch {
+					seen[id] = true
+					allocations = append(allocations, api.ProcessedAllocation{ScheduleID: id, AllocatedAmount: 10})
+				}
+				return allocations, nil
+			})
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, resp.Batches)
+		assert.ElementsMatch(t, []int{100, 100, 50}, sizes)
+		assert.Len(t, seen, 250)
+		assert.Equal(t, int64(250), resp.ProcessedCount)
+		assert.InDelta(t, 2500.0, resp.TotalAllocated, 0.001)
+		assert.LessOrEqual(t, tracker.peak, int64(2))
+
+		// Results keep the due order whichever batch finishes first
+		assert.Equal(t, int64(1), resp.Allocations[0].ScheduleID)
+		assert.Equal(t, int64(250), resp.Allocations[249].ScheduleID)
+	})
+
+	t.Run("failed batch does not stop the others", func(t *testing.T) {
+		boom := errors.New("deadlock detected")
+		resp, err := allocateInBatches(context.Background(), ids, ids, 100, 4,
+			func(ctx context.Context, batch []int64) ([]api.ProcessedAllocation, error) {
+				if batch[0] == 101 {
+					return nil, boom
+				}
+				return make([]api.ProcessedAllocation, len(batch)), nil
+			})
+		assert.ErrorIs(t, err, boom)
+		assert.Equal(t, int64(150), resp.ProcessedCount)
+	})
+
+	t.Run("an account's schedules share a batch", func(t *testing.T) {
+		// Schedules 1-3 belong to account 10, which would otherwise straddle two batches
+		var mu sync.Mutex
+		var batches [][]int64
+		_, err := allocateInBatches(context.Background(), []int64{1, 2, 3, 4}, []int64{10, 10, 10, 11}, 2, 2,
+			func(ctx context.Context, batch []int64) ([]api.ProcessedAllocation, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				batches = append(batches, batch)
+				return nil, nil
+			})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, [][]int64{{1, 2, 3}, {4}}, batches)
+	})
+
+	t.Run("nothing due", func(t *testing.T) {
+		resp, err := allocateInBatches(context.Background(), nil, nil, 100, 4,
+			func(ctx context.Context, batch []int64) ([]api.ProcessedAllocation, error) {
+				t.Fatal("no batch expected")
+				return nil, nil
+			})
+		require.NoError(t, err)
+		assert.Zero(t, resp.Batches)
+		assert.Zero(t, resp.ProcessedCount)
+	})
+}
+
+func TestRecoverInBatches(t *testing.T) {
+	var holds []*api.BudgetTransaction
+	for i := 1; i <= 250; i++ {
+		holds = append(holds, &api.BudgetTransaction{ID: int64(i), TransactionID: fmt.Sprintf("txn_%03d", i)})
+	}
+
+	// fetch pages through the pending holds after afterID, as GetPendingHolds does
+	var fetches []int64
+	fetch := func(ctx context.Context, afterID int64, limit int) ([]*api.BudgetTransaction, error) {
+		fetches = append(fetches, afterID)
+		var page []*api.BudgetTransaction
+		for _, hold := range holds {
+			if hold.ID > afterID && len(page) < limit {
+				page = append(page, hold)
+			}
+		}
+		return page, nil
+	}
+
+	var tracker concurrencyTracker
+	resp, err := recoverInBatches(context.Background(), 100, 3, fetch,
+		func(ctx context.Context, hold *api.BudgetTransaction) (holdOutcome, error) {
+			tracker.enter()
+			defer tracker.exit()
+			switch {
+			case hold.ID == 7:
+				return holdKept, errors.New("connection reset")
+			case hold.ID == 9:
+				return holdReconciled, nil
+			case hold.ID%2 == 0:
+				return holdKept, nil // not yet old enough to cancel
+			default:
+				return holdCancelled, nil
+			}
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{0, 100, 200}, fetches)
+	assert.Equal(t, 3, resp.Batches)
+	assert.Equal(t, 250, resp.Found)
+	assert.Len(t, resp.Recovered, 123)
+	assert.Equal(t, []string{"txn_009"}, resp.Reconciled)
+	assert.Equal(t, []string{"txn_007"}, resp.Failed)
+	assert.Equal(t, "txn_001", resp.Recovered[0])
+	assert.LessOrEqual(t, tracker.peak, int64(3))
+
+	t.Run("exact multiple reads one empty page", func(t *testing.T) {
+		fetches = nil
+		holds = holds[:200]
+		resp, err := recoverInBatches(context.Background(), 100, 3, fetch,
+			func(ctx context.Context, hold *api.BudgetTransaction) (holdOutcome, error) { return holdCancelled, nil })
+		require.NoError(t, err)
+		assert.Equal(t, []int64{0, 100, 200}, fetches)
+		assert.Equal(t, 2, resp.Batches)
+		assert.Len(t, resp.Recovered, 200)
+	})
+
+	t.Run("fetch failure is returned", func(t *testing.T) {
+		_, err := recoverInBatches(context.Background(), 100, 3,
+			func(ctx context.Context, afterID int64, limit int) ([]*api.BudgetTransaction, error) {
+				return nil, api.NewDatabaseError("get pending holds", errors.New("timeout"))
+			},
+			func(ctx context.Context, hold *api.BudgetTransaction) (holdOutcome, error) { return holdCancelled, nil })
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,219 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// RecordDailyBurnRates records the burn rate for the given day on every active account
+// with burn rate analysis enabled
+func (s *Service) RecordDailyBurnRates(ctx context.Context, date time.Time) error {
+	day := truncateToDay(date)
+
+	accounts, err := s.accountQueries.ListAccounts(ctx, &api.ListAccountsRequest{Status: "active"})
+	if err != nil {
+		return err
+	}
+
+	calendar := s.burnRateCalendar()
+	recorded := 0
+	for _, account := range accounts {
+		if !account.BurnRateEnabled {
+			continue
+		}
+
+		opening, err := s.snapshotForAccount(ctx, account, day.AddDate(0, 0, -1))
+		if err != nil {
+			log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to load opening balance for burn rate")
+			continue
+		}
+		closing, err := s.snapshotForAccount(ctx, account, day)
+		if err != nil {
+			log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to load closing balance for burn rate")
+			continue
+		}
+
+		inserted, err := s.burnRateQueries.InsertBurnRate(ctx, burnRateFor(account, day, opening, closing, calendar))
+		if err != nil {
+			log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to record burn rate")
+			continue
+		}
+		if inserted {
+			recorded++
+		}
+	}
+
+	log.Info().Int("accounts", recorded).Str("date", day.Format("2006-01-02")).Msg("Recorded daily burn rates")
+	return nil
+}
+
+// BackfillBurnRates computes burn rate history for days that have not been recorded yet.
+// Days that already have a row are skipped, so the backfill can be re-run safely.
+func (s *Service) BackfillBurnRates(ctx context.Context, slurmAccount string, req *api.BurnRateBackfillRequest) (*api.BurnRateBackfillResponse, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	start := truncateToDay(account.StartDate)
+	if req.StartDate != nil {
+		start = truncateToDay(*req.StartDate)
+	}
+	// Today is still in progress and is recorded by the nightly job once it ends
+	end := truncateToDay(time.Now()).AddDate(0, 0, -1)
+	if req.EndDate != nil {
+		end = truncateToDay(*req.EndDate)
+	}
+	if end.Before(start) {
+		return nil, api.NewValidationError("end_date", "must not be before start_date")
+	}
+
+	return s.backfillBurnRates(ctx, account, start, end)
+}
+
+// backfillBurnRates replays the ledger over a date range, clamped to the account's
+// lifetime and to completed days, and records a burn rate row for each missing day
+func (s *Service) backfillBurnRates(ctx context.Context, account *api.BudgetAccount, start, end time.Time) (*api.BurnRateBackfillResponse, error) {
+	if accountStart := truncateToDay(account.StartDate); start.Before(accountStart) {
+		start = accountStart
+	}
+	if accountEnd := truncateToDay(account.EndDate); end.After(accountEnd) {
+		end = accountEnd
+	}
+	if yesterday := truncateToDay(time.Now()).AddDate(0, 0, -1); end.After(yesterday) {
+		end = yesterday
+	}
+
+	resp := &api.BurnRateBackfillResponse{
+		Account:   account.SlurmAccount,
+		StartDate: start,
+		EndDate:   end,
+	}
+	if end.Before(start) {
+		return resp, nil
+	}
+
+	existing, err := s.burnRateQueries.ListBurnRates(ctx, account.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	recorded := make(map[string]bool, len(existing))
+	for _, rate := range existing {
+		recorded[rate.MeasurementDate.Format("2006-01-02")] = true
+	}
+
+	opening, err := s.snapshotForAccount(ctx, account, start.AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.snapshotQueries.ListLedgerEntries(ctx, nil, account.ID, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rate := range burnRateSeries(account, opening, entries, start, end, s.burnRateCalendar()) {
+		if recorded[rate.MeasurementDate.Format("2006-01-02")] {
+			resp.Skipped++
+			continue
+		}
+		inserted, err := s.burnRateQueries.InsertBurnRate(ctx, rate)
+		if err != nil {
+			return nil, err
+		}
+		if inserted {
+			resp.Computed++
+		} else {
+			resp.Skipped++
+		}
+	}
+
+	return resp, nil
+}
+
+// burnRateSeries computes a burn rate row for each day from start to end by replaying
+// ledger entries forward from the balances at the close of the day before start
+func burnRateSeries(account *api.BudgetAccount, opening *api.BudgetSnapshot, entries []*database.LedgerEntry, start, end time.Time, calendar api.WorkCalendar) []*api.BudgetBurnRate {
+	var rates []*api.BudgetBurnRate
+	next := 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1)
+
+		first := next
+		for next < len(entries) && !entries[next].EffectiveAt.After(endOfDay) {
+			next++
+		}
+
+		closing := replayLedger(opening, entries[first:next])
+		rates = append(rates, burnRateFor(account, day, opening, closing, calendar))
+		opening = closing
+	}
+	return rates
+}
+
+// burnRateFor computes one day's burn rate from the balances at the close of the previous
+// day and of the day itself. The budget is expected to be spent evenly over the calendar's
+// working days in the account's lifetime, and nothing is expected on other days.
+func burnRateFor(account *api.BudgetAccount, day time.Time, opening, closing *api.BudgetSnapshot, calendar api.WorkCalendar) *api.BudgetBurnRate {
+	totalDays := calendar.WorkingDays(account.StartDate, account.EndDate)
+	daysElapsed := calendar.WorkingDays(account.StartDate, day)
+
+	rate := &api.BudgetBurnRate{
+		AccountID:         account.ID,
+		MeasurementDate:   day,
+		DailySpendAmount:  closing.BudgetUsed - opening.BudgetUsed,
+		CumulativeSpend:   closing.BudgetUsed,
+		BudgetHealthScore: 100,
+	}
+
+	if totalDays <= 0 {
+		return rate
+	}
+
+	perWorkingDay := closing.BudgetLimit / float64(totalDays)
+	if calendar.IsWorkingDay(day) {
+		rate.DailyExpectedAmount = perWorkingDay
+	}
+	rate.CumulativeExpected = perWorkingDay * float64(daysElapsed)
+
+	// 100 is exactly on track; the score drops one point per percent of variance
+	if rate.CumulativeExpected > 0 {
+		variance := rate.CumulativeSpend/rate.CumulativeExpected - 1.0
+		rate.BudgetHealthScore = math.Max(0, 100-math.Abs(variance)*100)
+	}
+
+	return rate
+}
+
+// burnRateCalendar returns the working days burn rates expect spending on, which
+// configuration validation has already checked
+func (s *Service) burnRateCalendar() api.WorkCalendar {
+	if s.config == nil {
+		return api.WorkCalendar{}
+	}
+	calendar, err := api.ParseWorkCalendar(s.config.BurnRateWorkingDays, s.config.BurnRateHolidays)
+	if err != nil {
+		return api.WorkCalendar{}
+	}
+	return calendar
+}
+
+// enableBurnRateHistory backfills burn rate history for an account that has just turned
+// on burn rate analysis, logging rather than failing the account change
+func (s *Service) enableBurnRateHistory(ctx context.Context, account *api.BudgetAccount) {
+	resp, err := s.backfillBurnRates(ctx, account, truncateToDay(account.StartDate), truncateToDay(time.Now()))
+	if err != nil {
+		log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to backfill burn rate history")
+		return
+	}
+	log.Info().Str("account", account.SlurmAccount).Int("computed", resp.Computed).Msg("Backfilled burn rate history")
+}
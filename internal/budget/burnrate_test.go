@@ -0,0 +1,142 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBurnRateFor(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	account := &api.BudgetAccount{
+		ID:        7,
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 100),
+	}
+
+	opening := &api.BudgetSnapshot{BudgetLimit: 1000, BudgetUsed: 90}
+	closing := &api.BudgetSnapshot{BudgetLimit: 1000, BudgetUsed: 120}
+	rate := burnRateFor(account, start.AddDate(0, 0, 10), opening, closing, api.WorkCalendar{})
+
+	assert.Equal(t, int64(7), rate.AccountID)
+	assert.InDelta(t, 30.0, rate.DailySpendAmount, 0.001)
+	assert.InDelta(t, 10.0, rate.DailyExpectedAmount, 0.001)
+	assert.InDelta(t, 120.0, rate.CumulativeSpend, 0.001)
+	assert.InDelta(t, 100.0, rate.CumulativeExpected, 0.001)
+	assert.InDelta(t, 80.0, rate.BudgetHealthScore, 0.001)
+
+	// On the first day nothing is expected yet, so the account is on track
+	first := burnRateFor(account, start, opening, closing, api.WorkCalendar{})
+	assert.Equal(t, 100.0, first.BudgetHealthScore)
+}
+
+func TestBurnRateSeries_MatchesDailyComputation(t *testing.T) {
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	account := &api.BudgetAccount{
+		ID:        1,
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 60),
+	}
+	opening := &api.BudgetSnapshot{AccountID: 1, BudgetLimit: 600}
+
+	at := func(day, hour int) time.Time { return start.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour) }
+	entries := []*database.LedgerEntry{
+		{Type: "hold", Amount: 50, EffectiveAt: at(0, 9)},
+		{Type: "charge", Amount: 40, ParentType: "hold", EffectiveAt: at(0, 17)},
+		{Type: "charge", Amount: 15, EffectiveAt: at(2, 0)}, // exactly midnight closes day 1
+		{Type: "allocation", Amount: 300, EffectiveAt: at(3, 1)},
+		{Type: "refund", Amount: 5, ParentType: "charge", EffectiveAt: at(3, 12)},
+		{Type: "adjustment", Amount: 20, EffectiveAt: at(6, 23)},
+	}
+	end := start.AddDate(0, 0, 7)
+
+	series := burnRateSeries(account, opening, entries, start, end, api.WorkCalendar{})
+	require.Len(t, series, 8)
+
+	// The nightly job computes each day from that day's opening and closing balances
+	closeOf := func(day time.Time) *api.BudgetSnapshot {
+		endOfDay := day.AddDate(0, 0, 1)
+		var upTo []*database.LedgerEntry
+		for _, entry := range entries {
+			if !entry.EffectiveAt.After(endOfDay) {
+				upTo = append(upTo, entry)
+			}
+		}
+		return replayLedger(opening, upTo)
+	}
+
+	for i, day := 0, start; !day.After(end); i, day = i+1, day.AddDate(0, 0, 1) {
+		var dayOpening *api.BudgetSnapshot
+		if day.Equal(start) {
+			dayOpening = opening
+		} else {
+			dayOpening = closeOf(day.AddDate(0, 0, -1))
+		}
+		expected := burnRateFor(account, day, dayOpening, closeOf(day), api.WorkCalendar{})
+		assert.Equal(t, expected, series[i], "day %s", day.Format("2006-01-02"))
+	}
+
+	assert.InDelta(t, 15.0, series[1].DailySpendAmount, 0.001)
+	assert.InDelta(t, -5.0, series[3].DailySpendAmount, 0.001)
+	assert.InDelta(t, 15.0, series[3].DailyExpectedAmount, 0.001)
+	assert.InDelta(t, 70.0, series[7].CumulativeSpend, 0.001)
+}
+
+func TestBurnRateSeries_WeekdayCalendarReducesVariance(t *testing.T) {
+	start := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC) // a Monday
+	account := &api.BudgetAccount{
+		ID:        1,
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 28),
+	}
+	opening := &api.BudgetSnapshot{AccountID: 1, BudgetLimit: 2000}
+
+	// The account runs $100 of jobs every weekday and nothing at weekends, exactly spending
+	// its budget over its 20 weekdays
+	var entries []*database.LedgerEntry
+	for day := start; day.Before(account.EndDate); day = day.AddDate(0, 0, 1) {
+		if day.Weekday() != time.Saturday && day.Weekday() != time.Sunday {
+			entries = append(entries, &database.LedgerEntry{Type: "charge", Amount: 100, EffectiveAt: day.Add(12 * time.Hour)})
+		}
+	}
+	end := start.AddDate(0, 0, 27)
+
+	weekdays, err := api.ParseWorkCalendar([]string{"mon", "tue", "wed", "thu", "fri"}, nil)
+	require.NoError(t, err)
+	uniform := burnRateSeries(account, opening, entries, start, end, api.WorkCalendar{})
+	calendar := burnRateSeries(account, opening, entries, start, end, weekdays)
+	require.Len(t, uniform, 28)
+	require.Len(t, calendar, 28)
+
+	// The health score drops one point per percent of cumulative variance
+	var uniformVariance, calendarVariance float64
+	for i := range uniform {
+		day := uniform[i].MeasurementDate.Format("Mon 2006-01-02")
+		assert.LessOrEqual(t, 100-calendar[i].BudgetHealthScore, 100-uniform[i].BudgetHealthScore+0.001, day)
+		uniformVariance += 100 - uniform[i].BudgetHealthScore
+		calendarVariance += 100 - calendar[i].BudgetHealthScore
+
+		if weekday := uniform[i].MeasurementDate.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			// A weekend after a full week of spending is exactly on track
+			assert.Zero(t, calendar[i].DailyExpectedAmount, day)
+			assert.InDelta(t, 100.0, calendar[i].BudgetHealthScore, 0.001, day)
+		} else {
+			assert.InDelta(t, 100.0, calendar[i].DailyExpectedAmount, 0.001, day)
+		}
+	}
+	assert.InDelta(t, 2000.0/28, uniform[0].DailyExpectedAmount, 0.001)
+	assert.Less(t, calendarVariance, uniformVariance)
+
+	// In the last week the uniform expectation still reads as overspending on Friday
+	assert.Less(t, uniform[25].BudgetHealthScore, calendar[25].BudgetHealthScore)
+	assert.InDelta(t, 100.0, calendar[27].BudgetHealthScore, 0.001)
+}
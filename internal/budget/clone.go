@@ -0,0 +1,124 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// CloneAccount creates an account from an existing one, so a lab's next project or a
+// renewed grant can start from a template. The new account takes the source's settings,
+// parent and partition limits, and optionally its allocation schedules, but none of its
+// balances or history.
+func (s *Service) CloneAccount(ctx context.Context, sourceAccount string, req *api.CloneAccountRequest) (*api.AccountCloneResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	source, err := s.accountQueries.GetAccountByName(ctx, sourceAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	var parent string
+	if source.ParentAccountID != nil {
+		parentAccount, err := s.accountQueries.GetAccountByID(ctx, *source.ParentAccountID)
+		if err != nil {
+			return nil, err
+		}
+		parent = parentAccount.SlurmAccount
+	}
+
+	createReq := cloneAccountRequest(source, parent, req)
+	if err := createReq.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateHoldPercentage(createReq.HoldPercentage); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.accountQueries.CloneAccount(ctx, source.ID, createReq, req.CopyAllocationSchedule)
+	if err != nil {
+		return nil, err
+	}
+	resp.SourceAccount = source.SlurmAccount
+
+	if resp.Account.BurnRateEnabled {
+		s.enableBurnRateHistory(ctx, resp.Account)
+	}
+
+	log.Info().
+		Str("source", source.SlurmAccount).
+		Str("account", resp.Account.SlurmAccount).
+		Int64("partition_limits_copied", resp.PartitionLimitsCopied).
+		Int64("schedules_copied", resp.SchedulesCopied).
+		Msg("Account cloned")
+
+	return resp, nil
+}
+
+// cloneAccountRequest builds the request creating a clone of source under parent, the
+// source's parent SLURM account, with req's overrides applied
+func cloneAccountRequest(source *api.BudgetAccount, parent string, req *api.CloneAccountRequest) *api.CreateAccountRequest {
+	createReq := &api.CreateAccountRequest{
+		SlurmAccount:    req.SlurmAccount,
+		Name:            source.Name,
+		Description:     source.Description,
+		BudgetLimit:     source.BudgetLimit,
+		StartDate:       source.StartDate,
+		EndDate:         source.EndDate,
+		ReservedAmount:  source.ReservedAmount,
+		Timezone:        source.Timezone,
+		BurnRateEnabled: source.BurnRateEnabled,
+		ParentAccount:   parent,
+		BudgetUnit:      source.BudgetUnit,
+	}
+
+	if source.HoldPercentage != nil {
+		holdPercentage := *source.HoldPercentage
+		createReq.HoldPercentage = &holdPercentage
+	}
+	if source.DollarsPerSU != nil {
+		dollarsPerSU := *source.DollarsPerSU
+		createReq.DollarsPerSU = &dollarsPerSU
+	}
+	if source.FiscalYearStart != nil {
+		createReq.FiscalYearStart = *source.FiscalYearStart
+	}
+	if source.EstimationSource != nil {
+		createReq.EstimationSource = *source.EstimationSource
+	}
+	if len(source.Tags) > 0 {
+		createReq.Tags = make(map[string]string, len(source.Tags))
+		for k, v := range source.Tags {
+			createReq.Tags[k] = v
+		}
+	}
+	if len(source.AllowedPartitions) > 0 {
+		createReq.AllowedPartitions = append([]string(nil), source.AllowedPartitions...)
+	}
+
+	if req.Name != "" {
+		createReq.Name = req.Name
+	}
+	if req.Description != nil {
+		createReq.Description = *req.Description
+	}
+	if req.BudgetLimit != nil {
+		createReq.BudgetLimit = *req.BudgetLimit
+	}
+	if req.StartDate != nil {
+		createReq.StartDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		createReq.EndDate = *req.EndDate
+	}
+
+	return createReq
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestCloneAccountRequest(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	holdPercentage := 1.5
+	fiscalYearStart := "07-01"
+	estimationSource := api.EstimationSourceStatic
+	source := &api.BudgetAccount{
+		ID:                7,
+		SlurmAccount:      "lab-2025",
+		Name:              "Lab 2025",
+		Description:       "Imaging lab",
+		BudgetLimit:       5000,
+		BudgetUsed:        1200,
+		BudgetHeld:        300,
+		TotalAllocated:    2500,
+		HoldPercentage:    &holdPercentage,
+		ReservedAmount:    250,
+		Timezone:          "America/Chicago",
+		BurnRateEnabled:   true,
+		FiscalYearStart:   &fiscalYearStart,
+		Tags:              map[string]string{"department": "biology"},
+		EstimationSource:  &estimationSource,
+		AllowedPartitions: []string{"gpu", "cpu"},
+		StartDate:         start,
+		EndDate:           end,
+	}
+
+	t.Run("copies the source's settings", func(t *testing.T) {
+		req := cloneAccountRequest(source, "biology", &api.CloneAccountRequest{SlurmAccount: "lab-2026"})
+		require.NoError(t, req.Validate())
+
+		assert.Equal(t, "lab-2026", req.SlurmAccount)
+		assert.Equal(t, "Lab 2025", req.Name)
+		assert.Equal(t, "Imaging lab", req.Description)
+		assert.Equal(t, 5000.0, req.BudgetLimit)
+		assert.True(t, start.Equal(req.StartDate))
+		assert.True(t, end.Equal(req.EndDate))
+		require.NotNil(t, req.HoldPercentage)
+		assert.Equal(t, 1.5, *req.HoldPercentage)
+		assert.Equal(t, 250.0, req.ReservedAmount)
+		assert.Equal(t, "America/Chicago", req.Timezone)
+		assert.True(t, req.BurnRateEnabled)
+		assert.Equal(t, "07-01", req.FiscalYearStart)
+		assert.Equal(t, "biology", req.ParentAccount)
+		assert.Equal(t, map[string]string{"department": "biology"}, req.Tags)
+		assert.Equal(t, api.EstimationSourceStatic, req.EstimationSource)
+		assert.Equal(t, []string{"gpu", "cpu"}, req.AllowedPartitions)
+	})
+
+	t.Run("shares nothing mutable with the source", func(t *testing.T) {
+		req := cloneAccountRequest(source, "", &api.CloneAccountRequest{SlurmAccount: "lab-2026"})
+		*req.HoldPercentage = 2.0
+		req.Tags["department"] = "physics"
+		req.AllowedPartitions[0] = "debug"
+
+		assert.Equal(t, 1.5, *source.HoldPercentage)
+		assert.Equal(t, "biology", source.Tags["department"])
+		assert.Equal(t, "gpu", source.AllowedPartitions[0])
+	})
+
+	t.Run("applies overrides", func(t *testing.T) {
+		description := ""
+		budgetLimit := 8000.0
+		newStart := end.AddDate(0, 0, 1)
+		newEnd := newStart.AddDate(1, 0, 0)
+		req := cloneAccountRequest(source, "", &api.CloneAccountRequest{
+			SlurmAccount: "lab-2026",
+			Name:         "Lab 2026",
+			Description:  &description,
+			BudgetLimit:  &budgetLimit,
+			StartDate:    &newStart,
+			EndDate:      &newEnd,
+		})
+		require.NoError(t, req.Validate())
+
+		assert.Equal(t, "Lab 2026", req.Name)
+		assert.Empty(t, req.Description)
+		assert.Equal(t, 8000.0, req.BudgetLimit)
+		assert.True(t, newStart.Equal(req.StartDate))
+		assert.True(t, newEnd.Equal(req.EndDate))
+	})
+
+	t.Run("overrides are checked against the source's settings", func(t *testing.T) {
+		// The source reserves $250, more than the new budget
+		budgetLimit := 100.0
+		req := cloneAccountRequest(source, "", &api.CloneAccountRequest{SlurmAccount: "lab-2026", BudgetLimit: &budgetLimit})
+		assert.Error(t, req.Validate())
+
+		// Only the start date moves, past the source's end date
+		newStart := end.AddDate(0, 1, 0)
+		req = cloneAccountRequest(source, "", &api.CloneAccountRequest{SlurmAccount: "lab-2026", StartDate: &newStart})
+		assert.Error(t, req.Validate())
+	})
+}
+
+func TestCloneAccount_ValidatesBeforeQuerying(t *testing.T) {
+	service := &Service{}
+
+	_, err := service.CloneAccount(context.Background(), "lab-2025", &api.CloneAccountRequest{})
+	budgetErr, ok := api.AsBudgetError(err)
+	require.True(t, ok)
+	assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+}
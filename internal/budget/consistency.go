@@ -0,0 +1,196 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// balanceTolerance is the largest difference between a cached and a recomputed balance
+// that is put down to rounding rather than drift
+const balanceTolerance = 0.005
+
+// defaultRepairReason is recorded when a repair is requested without a reason
+const defaultRepairReason = "Cached balances did not match the transaction ledger"
+
+// ledgerEnd bounds a full ledger replay; every transaction takes effect before it
+var ledgerEnd = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// VerifyAccountConsistency recomputes an account's used and held balances from its
+// transaction ledger, including rolled-up descendant transactions, and compares them with
+// the cached balances on the account. The limit is not compared since it can change
+// without a ledger entry.
+func (s *Service) VerifyAccountConsistency(ctx context.Context, slurmAccount string) (*api.AccountConsistency, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.verifyAccount(ctx, account)
+}
+
+// RepairAccountConsistency corrects an account's cached used and held balances to the
+// values recomputed from its ledger. The account row is locked while the ledger is
+// replayed, and each correction is recorded in the balance repair audit trail. An account
+// that is already consistent is left untouched.
+func (s *Service) RepairAccountConsistency(ctx context.Context, slurmAccount, reason, repairedBy string) (*api.AccountConsistency, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repairAccount(ctx, account, reason, repairedBy)
+}
+
+// CheckConsistency verifies one account, or every account when none is named, repairing
+// any drift found when requested
+func (s *Service) CheckConsistency(ctx context.Context, req *api.ConsistencyCheckRequest) (*api.ConsistencyCheckResponse, error) {
+	var accounts []*api.BudgetAccount
+	if req.Account != "" {
+		account, err := s.accountQueries.GetAccountByName(ctx, req.Account)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	} else {
+		all, err := s.accountQueries.ListAccounts(ctx, &api.ListAccountsRequest{})
+		if err != nil {
+			return nil, err
+		}
+		accounts = all
+	}
+
+	resp := &api.ConsistencyCheckResponse{Accounts: []*api.AccountConsistency{}}
+	for _, account := range accounts {
+		var result *api.AccountConsistency
+		var err error
+		if req.Repair {
+			result, err = s.repairAccount(ctx, account, req.Reason, req.RepairedBy)
+		} else {
+			result, err = s.verifyAccount(ctx, account)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		resp.Checked++
+		if !result.Consistent {
+			resp.Inconsistent++
+		}
+		if result.Repaired {
+			resp.Repaired++
+		}
+		resp.Accounts = append(resp.Accounts, result)
+	}
+
+	return resp, nil
+}
+
+// verifyAccount compares an already loaded account with its ledger
+func (s *Service) verifyAccount(ctx context.Context, account *api.BudgetAccount) (*api.AccountConsistency, error) {
+	expected, err := s.ledgerBalances(ctx, nil, account.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return compareBalances(account, expected), nil
+}
+
+// repairAccount re-reads an account's balances and ledger under a row lock and corrects
+// the cached balances if they have drifted
+func (s *Service) repairAccount(ctx context.Context, account *api.BudgetAccount, reason, repairedBy string) (*api.AccountConsistency, error) {
+	if reason == "" {
+		reason = defaultRepairReason
+	}
+
+	var result *api.AccountConsistency
+	err := s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		used, held, err := s.accountQueries.LockAccountBalance(ctx, tx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		expected, err := s.ledgerBalances(ctx, tx, account.ID)
+		if err != nil {
+			return err
+		}
+
+		locked := *account
+		locked.BudgetUsed = used
+		locked.BudgetHeld = held
+		result = compareBalances(&locked, expected)
+		if result.Consistent {
+			return nil
+		}
+
+		repair := &api.BalanceRepair{
+			AccountID:    account.ID,
+			PreviousUsed: used,
+			PreviousHeld: held,
+			RepairedUsed: result.ExpectedUsed,
+			RepairedHeld: result.ExpectedHeld,
+			Reason:       reason,
+			RepairedBy:   repairedBy,
+		}
+		if err := s.accountQueries.RepairAccountBalance(ctx, tx, repair); err != nil {
+			return err
+		}
+		result.Repaired = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Repaired {
+		log.Warn().
+			Str("account", account.SlurmAccount).
+			Float64("used_drift", result.UsedDrift).
+			Float64("held_drift", result.HeldDrift).
+			Str("repaired_by", repairedBy).
+			Msg("Repaired cached account balances from the transaction ledger")
+	}
+
+	return result, nil
+}
+
+// ledgerBalances replays an account's whole ledger from zero balances
+func (s *Service) ledgerBalances(ctx context.Context, tx *sql.Tx, accountID int64) (*api.BudgetSnapshot, error) {
+	entries, err := s.snapshotQueries.ListLedgerEntries(ctx, tx, accountID, time.Time{}, ledgerEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return replayLedger(&api.BudgetSnapshot{AccountID: accountID}, entries), nil
+}
+
+// compareBalances reports the drift between an account's cached balances and the
+// balances expected from its ledger, both rounded to cents
+func compareBalances(account *api.BudgetAccount, expected *api.BudgetSnapshot) *api.AccountConsistency {
+	result := &api.AccountConsistency{
+		AccountID:    account.ID,
+		Account:      account.SlurmAccount,
+		CachedUsed:   account.BudgetUsed,
+		CachedHeld:   account.BudgetHeld,
+		ExpectedUsed: roundCents(expected.BudgetUsed),
+		ExpectedHeld: roundCents(expected.BudgetHeld),
+	}
+	result.UsedDrift = roundCents(result.CachedUsed - result.ExpectedUsed)
+	result.HeldDrift = roundCents(result.CachedHeld - result.ExpectedHeld)
+	result.Consistent = math.Abs(result.UsedDrift) < balanceTolerance && math.Abs(result.HeldDrift) < balanceTolerance
+	return result
+}
+
+// roundCents rounds an amount to whole cents, matching the DECIMAL(12,2) balance columns
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,71 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestCompareBalances(t *testing.T) {
+	tests := []struct {
+		name       string
+		cachedUsed float64
+		cachedHeld float64
+		expected   *api.BudgetSnapshot
+		usedDrift  float64
+		heldDrift  float64
+		consistent bool
+	}{
+		{
+			name:       "matching balances",
+			cachedUsed: 120.50,
+			cachedHeld: 30.00,
+			expected:   &api.BudgetSnapshot{BudgetUsed: 120.50, BudgetHeld: 30.00},
+			consistent: true,
+		},
+		{
+			name:       "float noise within a cent is ignored",
+			cachedUsed: 0.30,
+			cachedHeld: 0,
+			expected:   &api.BudgetSnapshot{BudgetUsed: 0.1 + 0.2, BudgetHeld: 0},
+			consistent: true,
+		},
+		{
+			name:       "used drifted upward",
+			cachedUsed: 250.00,
+			cachedHeld: 30.00,
+			expected:   &api.BudgetSnapshot{BudgetUsed: 200.00, BudgetHeld: 30.00},
+			usedDrift:  50.00,
+			consistent: false,
+		},
+		{
+			name:       "held left behind by a lost release",
+			cachedUsed: 100.00,
+			cachedHeld: 0,
+			expected:   &api.BudgetSnapshot{BudgetUsed: 100.00, BudgetHeld: 12.34},
+			heldDrift:  -12.34,
+			consistent: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account := &api.BudgetAccount{ID: 7, SlurmAccount: "drift", BudgetUsed: tt.cachedUsed, BudgetHeld: tt.cachedHeld}
+
+			result := compareBalances(account, tt.expected)
+
+			assert.Equal(t, int64(7), result.AccountID)
+			assert.Equal(t, "drift", result.Account)
+			assert.InDelta(t, tt.usedDrift, result.UsedDrift, 0.0001)
+			assert.InDelta(t, tt.heldDrift, result.HeldDrift, 0.0001)
+			assert.Equal(t, tt.consistent, result.Consistent)
+			assert.False(t, result.Repaired)
+		})
+	}
+}
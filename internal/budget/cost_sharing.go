@@ -0,0 +1,398 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// costShareFunder is one funding account of a cost-shared job, with the ancestors whose
+// pools its share also draws on
+type costShareFunder struct {
+	account    *api.BudgetAccount
+	ancestors  []*api.BudgetAccount
+	percentage float64
+	// grantWarning is set when the job would run past the end of a grant funding the account
+	grantWarning string
+}
+
+// checkCostSharedBudget places a cost-shared job's holds, splitting the hold between the
+// funding accounts by their percentages. Every share must fit within its account and the
+// account's ancestors, and an ancestor common to several shares must fit all of them; if
+// any does not, the job is rejected and no account holds anything.
+func (s *Service) checkCostSharedBudget(ctx context.Context, req *api.BudgetCheckRequest, account *api.BudgetAccount, ancestors []*api.BudgetAccount, costResp *costEstimate, holdPercentage float64, holdSource string) (*api.BudgetCheckResponse, error) {
+	funders, err := s.costShareFunders(ctx, req, account, ancestors)
+	if err != nil {
+		s.recordRejection(ctx, account, req, err)
+		return nil, err
+	}
+	for _, funder := range funders {
+		costResp.Warning = appendWarning(costResp.Warning, funder.grantWarning)
+	}
+
+	holdAmount := accountCost(account, costResp.EstimatedCost) * holdPercentage
+	if costResp.NoHold {
+		holdAmount = 0
+	}
+	percentages := make([]float64, len(funders))
+	for i, funder := range funders {
+		percentages[i] = funder.percentage
+	}
+	amounts := splitCostShares(holdAmount, percentages)
+	demand, ids := costShareDemand(funders, amounts)
+
+	graceCredit, err := s.holdGraceCredit(ctx, funders[0].account, chainAccounts(funders)[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	reject := func(rows map[int64]*api.BudgetAccount, limiting *api.BudgetAccount) *api.BudgetCheckResponse {
+		available := limiting.SpendableAvailable() + graceCredit[limiting.ID]
+		resp := insufficientBudgetResponse(rows[account.ID], limiting, costResp, holdAmount, holdPercentage, holdSource, available, graceCredit)
+		if limiting.ID != account.ID {
+			resp.Message = fmt.Sprintf("Insufficient budget in %s for its share of the job", limiting.SlurmAccount)
+		}
+		resp.CostShares = costShareAllocations(funders, amounts)
+		return resp
+	}
+
+	current := make(map[int64]*api.BudgetAccount, len(ids))
+	for _, a := range chainAccounts(funders) {
+		current[a.ID] = a
+	}
+	if _, limiting := costShareHeadroom(current, demand, graceCredit); limiting != nil && costShareShort(limiting, demand, graceCredit) {
+		resp := reject(current, limiting)
+		s.logDecision(ctx, newDecision(account, req, resp))
+		s.enforceDepletion(ctx, limiting)
+		return resp, nil
+	}
+
+	// As for a single account, the approval is decided again against the rows locked on
+	// the primary, here every funding account's chain at once
+	var resp *api.BudgetCheckResponse
+	var limiting *api.BudgetAccount
+	var group string
+	err = s.withTransactionIDRetry(ctx, func(tx *sql.Tx) error {
+		locked, err := lockAccounts(ctx, s.accountQueries, tx, ids)
+		if err != nil {
+			return err
+		}
+		var headroom float64
+		headroom, limiting = costShareHeadroom(locked, demand, graceCredit)
+		if costShareShort(limiting, demand, graceCredit) {
+			resp = reject(locked, limiting)
+			return s.decisionQueries.RecordDecision(ctx, tx, newDecision(locked[account.ID], req, resp))
+		}
+
+		metadata, err := holdMetadata(req, costResp, holdPercentage, holdSource)
+		if err != nil {
+			return err
+		}
+		group = s.generateTransactionID()
+		allocations := costShareAllocations(funders, amounts)
+		for i, funder := range funders {
+			percentage := funder.percentage
+			hold := &api.BudgetTransaction{
+				TransactionID:       group,
+				AccountID:           funder.account.ID,
+				Type:                "hold",
+				Amount:              amounts[i],
+				Description:         fmt.Sprintf("Budget hold for %g%% share of job on %s partition", percentage, req.Partition),
+				Metadata:            metadata,
+				Status:              "pending",
+				CostShareGroup:      &group,
+				CostSharePercentage: &percentage,
+			}
+			if i > 0 {
+				hold.TransactionID = s.generateTransactionID()
+			}
+			if err := s.transactionQueries.CreateTransaction(ctx, tx, hold); err != nil {
+				return err
+			}
+			if err := s.transactionQueries.UpdateTransactionStatus(ctx, tx, hold.TransactionID, "completed"); err != nil {
+				return err
+			}
+			allocations[i].TransactionID = hold.TransactionID
+		}
+
+		resp = &api.BudgetCheckResponse{
+			Available:         true,
+			EstimatedCost:     costResp.EstimatedCost,
+			HoldAmount:        holdAmount,
+			TransactionID:     group,
+			Message:           fmt.Sprintf("Budget check passed; cost shared across %d accounts", len(funders)),
+			BudgetRemaining:   headroom,
+			Recommendation:    costResp.Recommendation,
+			FailureMode:       costResp.FailureMode,
+			DomainFactor:      costResp.DomainFactor,
+			ScriptHistory:     costResp.ScriptHistory,
+			AdvisorDivergence: costResp.AdvisorDivergence,
+			HoldGraceCredit:   graceCredit[limiting.ID],
+			Warning:           costResp.Warning,
+			BudgetUnit:        account.Unit(),
+			CostShares:        allocations,
+		}
+		resp.Details.AccountBalance = headroom + demand[limiting.ID]
+		resp.Details.CurrentHold = locked[account.ID].BudgetHeld + amounts[0]
+		resp.Details.HoldPercentage = holdPercentage
+		resp.Details.HoldPercentageSource = holdSource
+		resp.Details.AdvisorConfidence = costResp.Confidence
+		return s.decisionQueries.RecordDecision(ctx, tx, newDecision(locked[account.ID], req, resp))
+	})
+
+	if err != nil {
+		return nil, api.NewTransactionFailedError(group, err)
+	}
+	if !resp.Available {
+		s.enforceDepletion(ctx, limiting)
+	}
+
+	return resp, nil
+}
+
+// costShareFunders loads a cost-shared job's funding accounts, the job's own account first
+// and the rest in the order given. Every one of them, and their ancestors, must accept holds,
+// and each funder must allow the job's partition and pass the grant end date check; the
+// job's own account was checked already.
+func (s *Service) costShareFunders(ctx context.Context, req *api.BudgetCheckRequest, account *api.BudgetAccount, ancestors []*api.BudgetAccount) ([]costShareFunder, error) {
+	funders := make([]costShareFunder, 0, len(req.CostShares))
+	funders = append(funders, costShareFunder{account: account, ancestors: ancestors})
+
+	for _, share := range req.CostShares {
+		if share.Account == account.SlurmAccount {
+			funders[0].percentage = share.Percentage
+			continue
+		}
+
+		funder, err := s.accountQueries.GetAccountByName(ctx, share.Account)
+		if err != nil {
+			return nil, err
+		}
+		funderAncestors, err := s.accountQueries.ListAncestors(ctx, funder.ID)
+		if err != nil {
+			return nil, err
+		}
+		grantWarning, err := s.admit(ctx, admissionChecks, admissionJob{account: funder, ancestors: funderAncestors, req: req})
+		if err != nil {
+			return nil, err
+		}
+		// The hold is split in the job account's unit, so every share must be worth the same
+		if !sameDenomination(account, funder) {
+			return nil, api.NewValidationError("cost_shares",
+				fmt.Sprintf("%s is not denominated in the same unit and rate as %s", funder.SlurmAccount, account.SlurmAccount))
+		}
+		funders = append(funders, costShareFunder{account: funder, ancestors: funderAncestors, percentage: share.Percentage, grantWarning: grantWarning})
+	}
+
+	return funders, nil
+}
+
+// chainAccounts returns every account the funders draw on, each once, funders first
+func chainAccounts(funders []costShareFunder) []*api.BudgetAccount {
+	seen := make(map[int64]bool)
+	var accounts []*api.BudgetAccount
+	add := func(a *api.BudgetAccount) {
+		if !seen[a.ID] {
+			seen[a.ID] = true
+			accounts = append(accounts, a)
+		}
+	}
+	for _, funder := range funders {
+		add(funder.account)
+	}
+	for _, funder := range funders {
+		for _, ancestor := range funder.ancestors {
+			add(ancestor)
+		}
+	}
+	return accounts
+}
+
+// costShareDemand totals the share holds each account must cover: a funder's share draws
+// on the funder and every ancestor, so an ancestor common to several funders covers them
+// all. It also returns the IDs of the accounts drawn on.
+func costShareDemand(funders []costShareFunder, amounts []float64) (map[int64]float64, []int64) {
+	demand := make(map[int64]float64)
+	for i, funder := range funders {
+		demand[funder.account.ID] += amounts[i]
+		for _, ancestor := range funder.ancestors {
+			demand[ancestor.ID] += amounts[i]
+		}
+	}
+
+	ids := make([]int64, 0, len(demand))
+	for id := range demand {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return demand, ids
+}
+
+// costShareHeadroom returns the smallest budget left once every account covers its share
+// holds, and the account that has it. Each account's balance is raised by its grace credit.
+func costShareHeadroom(rows map[int64]*api.BudgetAccount, demand, graceCredit map[int64]float64) (float64, *api.BudgetAccount) {
+	ids := make([]int64, 0, len(demand))
+	for id := range demand {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var limiting *api.BudgetAccount
+	headroom := math.Inf(1)
+	for _, id := range ids {
+		a := rows[id]
+		if a == nil {
+			continue
+		}
+		if left := a.SpendableAvailable() + graceCredit[id] - demand[id]; left < headroom {
+			headroom, limiting = left, a
+		}
+	}
+	return headroom, limiting
+}
+
+// costShareShort reports whether the limiting account cannot cover its share holds
+func costShareShort(limiting *api.BudgetAccount, demand, graceCredit map[int64]float64) bool {
+	return limiting != nil && limiting.SpendableAvailable()+graceCredit[limiting.ID] < demand[limiting.ID]
+}
+
+// splitCostShares divides amount by percentages, rounding each share to the cent. The last
+// share takes what is left, so the shares always add up to the rounded amount.
+func splitCostShares(amount float64, percentages []float64) []float64 {
+	shares := make([]float64, len(percentages))
+	total := roundCents(amount)
+	allocated := 0.0
+	for i, percentage := range percentages {
+		if i == len(percentages)-1 {
+			shares[i] = math.Max(0, roundCents(total-allocated))
+			break
+		}
+		shares[i] = roundCents(amount * percentage / 100)
+		allocated += shares[i]
+	}
+	return shares
+}
+
+// costShareAllocations describes each funder's part of a hold split into amounts
+func costShareAllocations(funders []costShareFunder, amounts []float64) []api.CostShareAllocation {
+	allocations := make([]api.CostShareAllocation, len(funders))
+	for i, funder := range funders {
+		allocations[i] = api.CostShareAllocation{
+			Account:    funder.account.SlurmAccount,
+			Percentage: funder.percentage,
+			HoldAmount: amounts[i],
+		}
+	}
+	return allocations
+}
+
+// reconcileCostShared reconciles every hold of a cost-shared job in one transaction,
+// splitting the actual cost by the percentages the holds were placed with. Each share is
+// settled against its own hold, and a cost breakdown is split the same way.
+func (s *Service) reconcileCostShared(ctx context.Context, req *api.JobReconcileRequest, group string, actualCost float64, policy string, outcome api.JobOutcome) (*api.JobReconcileResponse, error) {
+	holds, err := s.transactionQueries.ListCostShareHolds(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Cost share holds for %s not found", group))
+	}
+
+	percentages, charges := costShareCharges(holds, actualCost)
+
+	holdAccountIDs := make([]int64, len(holds))
+	for i, h := range holds {
+		holdAccountIDs[i] = h.Hold.AccountID
+	}
+	ids, err := chainIDs(ctx, s.accountQueries, holdAccountIDs...)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations := make([]api.CostShareAllocation, len(holds))
+	latencies := make([]time.Duration, len(holds))
+	err = s.withTransactionIDRetry(ctx, func(tx *sql.Tx) error {
+		// Every funder's chain is locked in ID order before any hold is settled, so
+		// reconciliations of jobs shared between the same accounts in a different order
+		// cannot deadlock
+		if _, err := lockAccounts(ctx, s.accountQueries, tx, ids); err != nil {
+			return err
+		}
+
+		for i, h := range holds {
+			refund, chargeIDs, latency, err := s.settleHold(ctx, tx, h.Hold, req.JobID, charges[i], outcome)
+			if err != nil {
+				return err
+			}
+			if len(chargeIDs) > 0 && len(req.CostBreakdown) > 0 {
+				breakdown := scaleCostBreakdown(req.CostBreakdown, percentages[i])
+				if err := s.transactionQueries.CreateCostComponents(ctx, tx, chargeIDs[0], breakdown); err != nil {
+					return err
+				}
+			}
+
+			latencies[i] = latency
+			allocations[i] = api.CostShareAllocation{
+				Account:       h.Account,
+				Percentage:    percentages[i],
+				TransactionID: h.Hold.TransactionID,
+				HoldAmount:    h.Hold.Amount,
+				ActualCharge:  charges[i],
+				RefundAmount:  refund,
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, settleError(req.TransactionID, err)
+	}
+
+	resp := &api.JobReconcileResponse{
+		Success:         true,
+		ActualCharge:    actualCost,
+		TransactionID:   req.TransactionID,
+		Message:         reconcileMessage(policy),
+		FailedJobPolicy: policy,
+		CostShares:      allocations,
+	}
+	for i, h := range holds {
+		s.recordReconciliationLatency(ctx, h.Hold, latencies[i])
+		resp.OriginalHold += allocations[i].HoldAmount
+		resp.RefundAmount += allocations[i].RefundAmount
+		_, additionalCharge, _ := holdSettlement(h.Hold.Amount, charges[i])
+		resp.AdditionalCharge += additionalCharge
+	}
+	s.refreshGrantCosts(ctx, holdAccountIDs...)
+	return resp, nil
+}
+
+// costShareCharges returns each cost-shared hold's percentage of a job and its share of
+// the job's actual cost
+func costShareCharges(holds []*database.CostShareHold, actualCost float64) ([]float64, []float64) {
+	percentages := make([]float64, len(holds))
+	for i, h := range holds {
+		if h.Hold.CostSharePercentage != nil {
+			percentages[i] = *h.Hold.CostSharePercentage
+		}
+	}
+	return percentages, splitCostShares(actualCost, percentages)
+}
+
+// scaleCostBreakdown returns a job's cost breakdown scaled to one account's share of it
+func scaleCostBreakdown(breakdown map[string]float64, percentage float64) map[string]float64 {
+	scaled := make(map[string]float64, len(breakdown))
+	for component, amount := range breakdown {
+		scaled[component] = amount * percentage / 100
+	}
+	return scaled
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestSplitCostShares(t *testing.T) {
+	tests := []struct {
+		name        string
+		amount      float64
+		percentages []float64
+		want        []float64
+	}{
+		{"60/40 hold", 12.0, []float64{60, 40}, []float64{7.2, 4.8}},
+		{"60/40 charge", 10.0, []float64{60, 40}, []float64{6.0, 4.0}},
+		{"last share takes the rounding", 10.0, []float64{33.33, 33.33, 33.34}, []float64{3.33, 3.33, 3.34}},
+		{"uneven cents", 0.05, []float64{60, 40}, []float64{0.03, 0.02}},
+		{"nothing to split", 0, []float64{60, 40}, []float64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares := splitCostShares(tt.amount, tt.percentages)
+			require.Len(t, shares, len(tt.want))
+			total := 0.0
+			for i := range tt.want {
+				assert.InDelta(t, tt.want[i], shares[i], 0.0001)
+				total += shares[i]
+			}
+			assert.InDelta(t, roundCents(tt.amount), total, 0.0001, "shares add up to the amount")
+		})
+	}
+}
+
+func TestCostShareDemand_SharedAncestor(t *testing.T) {
+	department := &api.BudgetAccount{ID: 1, SlurmAccount: "dept", BudgetLimit: 10.0}
+	projA := &api.BudgetAccount{ID: 2, SlurmAccount: "proj-a", BudgetLimit: 100.0}
+	projB := &api.BudgetAccount{ID: 3, SlurmAccount: "proj-b", BudgetLimit: 100.0}
+	funders := []costShareFunder{
+		{account: projA, ancestors: []*api.BudgetAccount{department}, percentage: 60},
+		{account: projB, ancestors: []*api.BudgetAccount{department}, percentage: 40},
+	}
+
+	// Each project could hold its own share, but the department has to cover both
+	demand, ids := costShareDemand(funders, []float64{7.2, 4.8})
+	assert.Equal(t, []int64{1, 2, 3}, ids)
+	assert.InDelta(t, 12.0, demand[1], 0.0001)
+	assert.InDelta(t, 7.2, demand[2], 0.0001)
+	assert.InDelta(t, 4.8, demand[3], 0.0001)
+
+	rows := map[int64]*api.BudgetAccount{1: department, 2: projA, 3: projB}
+	headroom, limiting := costShareHeadroom(rows, demand, nil)
+	assert.InDelta(t, -2.0, headroom, 0.0001)
+	assert.Same(t, department, limiting)
+	assert.True(t, costShareShort(limiting, demand, nil))
+
+	// A grace credit on the department makes room for both shares
+	credit := map[int64]float64{1: 2.0}
+	headroom, limiting = costShareHeadroom(rows, demand, credit)
+	assert.InDelta(t, 0.0, headroom, 0.0001)
+	assert.Same(t, department, limiting)
+	assert.False(t, costShareShort(limiting, demand, credit))
+}
+
+func TestChainAccounts(t *testing.T) {
+	department := &api.BudgetAccount{ID: 1, SlurmAccount: "dept"}
+	projA := &api.BudgetAccount{ID: 2, SlurmAccount: "proj-a"}
+	projB := &api.BudgetAccount{ID: 3, SlurmAccount: "proj-b"}
+
+	accounts := chainAccounts([]costShareFunder{
+		{account: projB, ancestors: []*api.BudgetAccount{department}},
+		{account: projA, ancestors: []*api.BudgetAccount{department}},
+	})
+	assert.Equal(t, []*api.BudgetAccount{projB, projA, department}, accounts)
+}
+
+func TestLockAccounts_Dedup(t *testing.T) {
+	tx := &sql.Tx{}
+	writer := &writerLocker{tx: tx, rows: map[int64]*api.BudgetAccount{
+		1: {ID: 1, SlurmAccount: "dept"},
+		2: {ID: 2, SlurmAccount: "proj-a"},
+		3: {ID: 3, SlurmAccount: "proj-b"},
+	}}
+
+	locked, err := lockAccounts(context.Background(), writer, tx, []int64{3, 1, 2, 1})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 3}, writer.locked, "each row is locked once, in ID order")
+	assert.Len(t, locked, 3)
+	assert.Same(t, writer.rows[2], locked[2])
+}
+
+func TestScaleCostBreakdown(t *testing.T) {
+	scaled := scaleCostBreakdown(map[string]float64{"compute": 8.0, "storage": 2.0}, 40)
+	assert.InDelta(t, 3.2, scaled["compute"], 0.0001)
+	assert.InDelta(t, 0.8, scaled["storage"], 0.0001)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// recordReconciliationFailure counts a failed reconciliation of a hold and dead-letters it
+// once it has failed budget.dead_letter_after times, so orphan recovery stops retrying it.
+// Errors are logged; the reconciliation's own error is what the caller reports.
+func (s *Service) recordReconciliationFailure(ctx context.Context, transactionID, jobID, source string, req *api.JobReconcileRequest, cause error) {
+	failure, err := s.deadLetterQueries.RecordFailure(ctx, transactionID, jobID, source, req, cause.Error())
+	if err != nil {
+		log.Error().Err(err).Str("transaction_id", transactionID).Msg("Failed to record reconciliation failure")
+		return
+	}
+	if failure.DeadLetteredAt != nil || !shouldDeadLetter(failure.Attempts, s.config.DeadLetterAfter) {
+		return
+	}
+
+	if err := s.deadLetterQueries.MarkDeadLettered(ctx, failure.ID); err != nil {
+		log.Error().Err(err).Str("transaction_id", transactionID).Msg("Failed to dead-letter reconciliation")
+		return
+	}
+	log.Warn().Str("transaction_id", transactionID).Str("job_id", jobID).Int("attempts", failure.Attempts).
+		Str("reason", failure.Reason).Msg("Reconciliation dead-lettered after repeated failures")
+}
+
+// shouldDeadLetter reports whether a reconciliation that has failed attempts times is
+// dead-lettered; a zero threshold never dead-letters
+func shouldDeadLetter(attempts, after int) bool {
+	return after > 0 && attempts >= after
+}
+
+// clearReconciliationFailures forgets a reconciled hold's earlier failures. Errors are
+// logged; the reconciliation itself has succeeded.
+func (s *Service) clearReconciliationFailures(ctx context.Context, transactionID string) {
+	if err := s.deadLetterQueries.ClearFailures(ctx, transactionID); err != nil {
+		log.Error().Err(err).Str("transaction_id", transactionID).Msg("Failed to clear reconciliation failures")
+	}
+}
+
+// ListDeadLetters returns the dead-lettered reconciliations awaiting an administrator
+func (s *Service) ListDeadLetters(ctx context.Context) ([]*api.ReconciliationDeadLetter, error) {
+	return s.deadLetterQueries.ListDeadLetters(ctx)
+}
+
+// ReplayDeadLetter retries a dead-lettered reconciliation once its underlying problem has
+// been fixed: its reconciliation request is run again, or a hold recovery was cancelling
+// is cancelled. A replay that fails again counts as another failure and stays queued.
+func (s *Service) ReplayDeadLetter(ctx context.Context, id int64, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error) {
+	letter, err := s.deadLetterQueries.GetDeadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if letter.Request != nil {
+		_, err = s.reconcileHeldJob(ctx, letter.Request)
+	} else {
+		err = s.replayHoldCancellation(ctx, letter.TransactionID)
+	}
+	if err != nil {
+		s.recordReconciliationFailure(ctx, letter.TransactionID, letter.JobID, letter.Source, letter.Request, err)
+		return nil, err
+	}
+
+	log.Info().Int64("id", id).Str("transaction_id", letter.TransactionID).Str("resolved_by", req.ResolvedBy).
+		Msg("Replayed dead-lettered reconciliation")
+	return s.deadLetterQueries.ResolveDeadLetter(ctx, id, api.DeadLetterReplayed, req)
+}
+
+// replayHoldCancellation cancels a dead-lettered hold that orphan recovery failed to cancel
+func (s *Service) replayHoldCancellation(ctx context.Context, transactionID string) error {
+	hold, err := s.transactionQueries.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if hold.Type != "hold" {
+		return api.NewBudgetError(api.ErrCodeValidation,
+			fmt.Sprintf("Transaction %s is not a hold; resolve it instead", transactionID))
+	}
+
+	// A hold settled since it was dead-lettered is refused; it needs resolving instead
+	return s.cancelOrphanedHold(ctx, hold)
+}
+
+// ResolveDeadLetter closes a dead-lettered reconciliation without replaying it, as when
+// the hold was settled by hand
+func (s *Service) ResolveDeadLetter(ctx context.Context, id int64, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error) {
+	letter, err := s.deadLetterQueries.ResolveDeadLetter(ctx, id, api.DeadLetterResolved, req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Int64("id", id).Str("transaction_id", letter.TransactionID).Str("resolved_by", req.ResolvedBy).
+		Msg("Resolved dead-lettered reconciliation")
+	return letter, nil
+}
+
+// holdJobID returns the job a hold was placed for, if recorded
+func holdJobID(hold *api.BudgetTransaction) string {
+	if hold.JobID == nil {
+		return ""
+	}
+	return *hold.JobID
+}
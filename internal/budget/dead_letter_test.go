@@ -0,0 +1,26 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestShouldDeadLetter(t *testing.T) {
+	assert.False(t, shouldDeadLetter(4, 5))
+	assert.True(t, shouldDeadLetter(5, 5))
+	assert.True(t, shouldDeadLetter(6, 5), "a failure past the threshold still dead-letters")
+	assert.False(t, shouldDeadLetter(100, 0), "zero never dead-letters")
+}
+
+func TestHoldJobID(t *testing.T) {
+	job := "12345"
+	assert.Equal(t, "12345", holdJobID(&api.BudgetTransaction{JobID: &job}))
+	assert.Empty(t, holdJobID(&api.BudgetTransaction{}))
+}
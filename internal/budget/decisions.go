@@ -0,0 +1,106 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// defaultDecisionListLimit caps a decision listing when no limit is given
+const defaultDecisionListLimit = 100
+
+// ListDecisions returns an account's recorded budget check decisions, newest first
+func (s *Service) ListDecisions(ctx context.Context, req *api.DecisionListRequest) ([]*api.BudgetDecision, error) {
+	if req.Decision != "" && req.Decision != api.DecisionApproved && req.Decision != api.DecisionRejected {
+		return nil, api.NewValidationError("decision", "must be approved or rejected")
+	}
+	if req.Limit < 0 || req.Limit > 1000 {
+		return nil, api.NewValidationError("limit", "must be between 1 and 1000")
+	}
+	if req.Offset < 0 {
+		return nil, api.NewValidationError("offset", "must not be negative")
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	// Whole days in the account's time zone, the end date inclusive
+	loc := account.Location()
+	filter := *req
+	if filter.StartDate != nil {
+		start := localMidnight(*filter.StartDate, loc)
+		filter.StartDate = &start
+	}
+	if filter.EndDate != nil {
+		end := localMidnight(*filter.EndDate, loc).AddDate(0, 0, 1)
+		filter.EndDate = &end
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && !filter.EndDate.After(*filter.StartDate) {
+		return nil, api.NewValidationError("end_date", "must not be before start_date")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultDecisionListLimit
+	}
+
+	return s.decisionQueries.ListDecisions(ctx, account.ID, &filter)
+}
+
+// newDecision records a budget check response as a decision, with the job request as its
+// inputs. The job script is left out; it can be large and the resources describe the job.
+func newDecision(account *api.BudgetAccount, req *api.BudgetCheckRequest, resp *api.BudgetCheckResponse) *api.BudgetDecision {
+	decision := decisionInputs(account, req)
+	decision.Decision = api.DecisionRejected
+	if resp.Available {
+		decision.Decision = api.DecisionApproved
+	}
+	decision.Reason = resp.Message
+	decision.EstimatedCost = resp.EstimatedCost
+	decision.HoldAmount = resp.HoldAmount
+	decision.BudgetAvailable = resp.Details.AccountBalance
+	decision.Confidence = resp.Details.AdvisorConfidence
+	decision.FailureMode = resp.FailureMode
+	decision.TransactionID = resp.TransactionID
+	return decision
+}
+
+// decisionInputs starts a decision for a budget check on account
+func decisionInputs(account *api.BudgetAccount, req *api.BudgetCheckRequest) *api.BudgetDecision {
+	inputs := *req
+	inputs.JobScript = ""
+	return &api.BudgetDecision{
+		AccountID: account.ID,
+		Partition: req.Partition,
+		UserID:    req.UserID,
+		Inputs:    &inputs,
+	}
+}
+
+// recordRejection logs a budget check refused with an error, such as a frozen account or
+// an unavailable advisor in STRICT mode, before any estimate was held
+func (s *Service) recordRejection(ctx context.Context, account *api.BudgetAccount, req *api.BudgetCheckRequest, cause error) {
+	decision := decisionInputs(account, req)
+	decision.Decision = api.DecisionRejected
+	decision.Reason = cause.Error()
+	decision.BudgetAvailable = account.SpendableAvailable()
+	s.logDecision(ctx, decision)
+}
+
+// logDecision records a decision that has no hold to be written with. The check's outcome
+// stands if it cannot be recorded, so the failure is logged rather than returned.
+func (s *Service) logDecision(ctx context.Context, decision *api.BudgetDecision) {
+	if err := s.decisionQueries.RecordDecision(ctx, nil, decision); err != nil {
+		log.Error().Err(err).
+			Int64("account_id", decision.AccountID).
+			Str("decision", decision.Decision).
+			Str("reason", decision.Reason).
+			Msg("Failed to record budget decision")
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestNewDecision(t *testing.T) {
+	account := &api.BudgetAccount{ID: 4, SlurmAccount: "proj001"}
+	req := &api.BudgetCheckRequest{
+		Account: "proj001", Partition: "gpu", Nodes: 2, CPUs: 8, WallTime: "02:00:00",
+		UserID: "alice", JobScript: "#!/bin/bash\nsrun train.py",
+	}
+
+	approved := newDecision(account, req, &api.BudgetCheckResponse{
+		Available: true, EstimatedCost: 10, HoldAmount: 12, TransactionID: "txn_1",
+		Message: "Budget check passed", FailureMode: "GRACEFUL",
+	})
+	assert.Equal(t, api.DecisionApproved, approved.Decision)
+	assert.Equal(t, int64(4), approved.AccountID)
+	assert.Equal(t, "gpu", approved.Partition)
+	assert.Equal(t, "alice", approved.UserID)
+	assert.Equal(t, 12.0, approved.HoldAmount)
+	assert.Equal(t, "txn_1", approved.TransactionID)
+	assert.Equal(t, "GRACEFUL", approved.FailureMode)
+	require.NotNil(t, approved.Inputs)
+	assert.Equal(t, 2, approved.Inputs.Nodes)
+	assert.Empty(t, approved.Inputs.JobScript, "the job script is not recorded")
+	assert.NotEmpty(t, req.JobScript, "the request itself is left alone")
+
+	resp := &api.BudgetCheckResponse{
+		Available: false, EstimatedCost: 900, HoldAmount: 1080, Message: "Insufficient budget",
+	}
+	resp.Details.AccountBalance = 100
+	rejected := newDecision(account, req, resp)
+	assert.Equal(t, api.DecisionRejected, rejected.Decision)
+	assert.Equal(t, "Insufficient budget", rejected.Reason)
+	assert.Equal(t, 100.0, rejected.BudgetAvailable)
+	assert.Empty(t, rejected.TransactionID)
+}
+
+func TestListDecisions_Validation(t *testing.T) {
+	service := &Service{}
+	ctx := context.Background()
+
+	for _, req := range []*api.DecisionListRequest{
+		{Account: "proj001", Decision: "maybe"},
+		{Account: "proj001", Limit: 5000},
+		{Account: "proj001", Offset: -1},
+	} {
+		_, err := service.ListDecisions(ctx, req)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a validation error for %+v", req)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	}
+}
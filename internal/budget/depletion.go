@@ -0,0 +1,129 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// alertTypeBudgetDepleted is the alert type raised when the depletion policy suspends or
+// freezes an account
+const alertTypeBudgetDepleted = "budget_depleted"
+
+// Depletion policies, as configured by budget.auto_suspend_on_depletion
+const (
+	depletionOff     = "OFF"
+	depletionSuspend = "SUSPEND"
+	depletionFreeze  = "FREEZE"
+)
+
+// depletionPolicy returns the configured depletion policy; an empty policy is OFF
+func (s *Service) depletionPolicy() string {
+	if s.config.AutoSuspendOnDepletion == "" {
+		return depletionOff
+	}
+	return s.config.AutoSuspendOnDepletion
+}
+
+// isDepleted reports whether an account has no spendable budget left. An account without
+// a limit has nothing to deplete.
+func isDepleted(account *api.BudgetAccount) bool {
+	return account.BudgetLimit > 0 && account.SpendableAvailable() <= 0
+}
+
+// EnforceDepletionPolicy suspends or freezes every active account whose spendable budget
+// is used up, as the depletion policy says
+func (s *Service) EnforceDepletionPolicy(ctx context.Context) error {
+	if s.depletionPolicy() == depletionOff {
+		return nil
+	}
+
+	accounts, err := s.accountQueries.ListAccounts(ctx, &api.ListAccountsRequest{Status: "active"})
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		s.enforceDepletion(ctx, account)
+	}
+	return nil
+}
+
+// enforceDepletion suspends or freezes an account found to be depleted and raises a
+// critical alert. Errors are logged; the caller carries on either way.
+func (s *Service) enforceDepletion(ctx context.Context, account *api.BudgetAccount) {
+	policy := s.depletionPolicy()
+	if policy == depletionOff || account.DepletedAt != nil || account.Frozen || !isDepleted(account) {
+		return
+	}
+
+	depleted, err := s.accountQueries.MarkDepleted(ctx, account.ID, policy == depletionSuspend)
+	if err != nil {
+		log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to apply depletion policy")
+		return
+	}
+	if !depleted {
+		return
+	}
+
+	log.Warn().Str("account", account.SlurmAccount).Str("policy", policy).Msg("Account budget depleted")
+	err = s.raiseAlert(ctx, &api.BudgetAlert{
+		AccountID:      account.ID,
+		AlertType:      alertTypeBudgetDepleted,
+		Severity:       "critical",
+		ThresholdValue: account.BudgetLimit,
+		ActualValue:    account.BudgetUsed + account.BudgetHeld + account.ReservedAmount,
+		Message:        depletionMessage(account.SlurmAccount, policy),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to raise budget depletion alert")
+	}
+}
+
+// depletionMessage describes an account the depletion policy has suspended or frozen
+func depletionMessage(slurmAccount, policy string) string {
+	action := "suspended"
+	if policy == depletionFreeze {
+		action = "frozen"
+	}
+	return fmt.Sprintf("Account %s has used up its budget and was %s; it is reactivated when a new allocation lands",
+		slurmAccount, action)
+}
+
+// reactivateReplenished reactivates each of the accounts the depletion policy suspended or
+// froze that has spendable budget again, and resolves its depletion alert
+func (s *Service) reactivateReplenished(ctx context.Context, accountIDs []int64) {
+	seen := make(map[int64]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		account, err := s.accountQueries.ClearDepletion(ctx, id)
+		if err != nil {
+			log.Error().Err(err).Int64("account_id", id).Msg("Failed to reactivate depleted account")
+			continue
+		}
+		if account == nil {
+			continue
+		}
+
+		log.Info().Str("account", account.SlurmAccount).Msg("Reactivated depleted account after allocation")
+		open, err := s.alertQueries.GetOpenAlert(ctx, account.ID, alertTypeBudgetDepleted)
+		if err == nil && open != nil {
+			err = s.alertQueries.ResolveAlert(ctx, open.ID, account.BudgetUsed+account.BudgetHeld+account.ReservedAmount)
+		}
+		if err != nil {
+			log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to resolve budget depletion alert")
+		}
+		s.refreshAccountAlert(ctx, account)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestIsDepleted(t *testing.T) {
+	assert.True(t, isDepleted(&api.BudgetAccount{BudgetLimit: 100.0, BudgetUsed: 80.0, BudgetHeld: 20.0}))
+	assert.True(t, isDepleted(&api.BudgetAccount{BudgetLimit: 100.0, BudgetUsed: 130.0}), "overspent")
+	assert.True(t, isDepleted(&api.BudgetAccount{BudgetLimit: 100.0, BudgetUsed: 90.0, ReservedAmount: 10.0}), "only the reserve is left")
+	assert.False(t, isDepleted(&api.BudgetAccount{BudgetLimit: 100.0, BudgetUsed: 99.0}))
+	assert.False(t, isDepleted(&api.BudgetAccount{}), "an account without a limit has nothing to deplete")
+}
+
+func TestDepletionPolicy(t *testing.T) {
+	assert.Equal(t, depletionOff, (&Service{config: &config.BudgetConfig{}}).depletionPolicy())
+	assert.Equal(t, depletionFreeze, (&Service{config: &config.BudgetConfig{AutoSuspendOnDepletion: "FREEZE"}}).depletionPolicy())
+}
+
+func TestDepletionMessage(t *testing.T) {
+	assert.Equal(t, "Account proj001 has used up its budget and was suspended; it is reactivated when a new allocation lands",
+		depletionMessage("proj001", depletionSuspend))
+	assert.Contains(t, depletionMessage("proj001", depletionFreeze), "was frozen")
+}
+
+func TestEnforceDepletion_Skipped(t *testing.T) {
+	depleted := &api.BudgetAccount{ID: 1, SlurmAccount: "proj001", BudgetLimit: 100.0, BudgetUsed: 100.0}
+	frozen := *depleted
+	frozen.Frozen = true
+
+	// Each of these returns before touching the database, which these services do not have
+	(&Service{config: &config.BudgetConfig{}}).enforceDepletion(context.Background(), depleted)
+	suspending := &Service{config: &config.BudgetConfig{AutoSuspendOnDepletion: "SUSPEND"}}
+	suspending.enforceDepletion(context.Background(), &frozen)
+	suspending.enforceDepletion(context.Background(), &api.BudgetAccount{ID: 2, BudgetLimit: 100.0, BudgetUsed: 10.0})
+	assert.NoError(t, (&Service{config: &config.BudgetConfig{}}).EnforceDepletionPolicy(context.Background()))
+}
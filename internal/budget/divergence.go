@@ -0,0 +1,128 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// Policies for an advisor estimate that diverges from the fallback heuristic, as configured
+// by integration.advisor_divergence_policy
+const (
+	advisorDivergenceMax   = "MAX"
+	advisorDivergenceBlend = "BLEND"
+)
+
+// Responses to an advisor estimate that is not a positive cost, as configured by
+// integration.invalid_estimate_policy
+const (
+	invalidEstimateFallback = "FALLBACK"
+	invalidEstimateMinimum  = "MINIMUM"
+)
+
+// checkAdvisorEstimate replaces an advisor estimate that is zero, negative or not a number,
+// which would otherwise approve the job against a zero hold, with the fallback heuristic's
+// or, with policy MINIMUM, the configured minimum. Unlike the divergence check it applies
+// in every failure mode.
+func (s *Service) checkAdvisorEstimate(req *api.BudgetCheckRequest, estimate *costEstimate) *costEstimate {
+	if estimate.EstimatedCost > 0 {
+		return estimate
+	}
+
+	guarded := replaceInvalidEstimate(estimate, s.fallbackCostEstimate(req), s.invalidEstimatePolicy, s.invalidEstimateMinimum)
+	log.Warn().
+		Str("account", req.Account).
+		Str("partition", req.Partition).
+		Float64("advisor_estimate", estimate.EstimatedCost).
+		Float64("estimate", guarded.EstimatedCost).
+		Msg("Advisor returned a non-positive cost estimate")
+	return guarded
+}
+
+// replaceInvalidEstimate returns a copy of estimate costed at minimum with policy MINIMUM,
+// or at the fallback estimate otherwise, with the replacement's confidence
+func replaceInvalidEstimate(estimate *costEstimate, fallback *CostEstimateResponse, policy string, minimum float64) *costEstimate {
+	response := *estimate.CostEstimateResponse
+	if policy == invalidEstimateMinimum && minimum > 0 {
+		response.EstimatedCost = minimum
+		response.Confidence = 0
+	} else {
+		response.EstimatedCost = fallback.EstimatedCost
+		response.Confidence = fallback.Confidence
+	}
+
+	guarded := *estimate
+	guarded.CostEstimateResponse = &response
+	guarded.Warning = fmt.Sprintf("Advisor estimate %.2f is not a positive cost; estimating %.2f instead",
+		estimate.EstimatedCost, response.EstimatedCost)
+	return &guarded
+}
+
+// checkAdvisorDivergence compares an advisor estimate with the fallback heuristic's for the
+// same job, at the configured fallback rates and for its whole wall time, so a
+// misconfigured or regressed advisor cannot quietly under-hold. The estimate
+// is returned unchanged when the check is off, in STRICT mode, or when the two agree
+// within the configured ratio.
+func (s *Service) checkAdvisorDivergence(req *api.BudgetCheckRequest, estimate *costEstimate) *costEstimate {
+	if s.divergenceRatio <= 0 || s.failureMode == failureModeStrict {
+		return estimate
+	}
+
+	guarded := guardAdvisorDivergence(estimate, s.fallbackCostEstimate(req), s.divergenceRatio, s.divergencePolicy)
+	if divergence := guarded.AdvisorDivergence; divergence != nil {
+		log.Warn().
+			Str("account", req.Account).
+			Str("partition", req.Partition).
+			Float64("advisor_estimate", divergence.AdvisorEstimate).
+			Float64("fallback_estimate", divergence.FallbackEstimate).
+			Float64("ratio", divergence.Ratio).
+			Str("policy", divergence.Policy).
+			Msg("Advisor estimate diverges from the fallback estimate")
+	}
+	return guarded
+}
+
+// guardAdvisorDivergence returns a copy of estimate raised to the fallback estimate, or
+// with policy BLEND to the mean of the two, when the fallback is more than ratio times the
+// advisor's. The confidence reported is the lower of the two estimates' confidences,
+// scaled down by how far past ratio the divergence is. Otherwise estimate is returned
+// unchanged.
+func guardAdvisorDivergence(estimate *costEstimate, fallback *CostEstimateResponse, ratio float64, policy string) *costEstimate {
+	advisorCost, fallbackCost := estimate.EstimatedCost, fallback.EstimatedCost
+	if fallbackCost <= 0 || advisorCost*ratio >= fallbackCost {
+		return estimate
+	}
+
+	if policy != advisorDivergenceBlend {
+		policy = advisorDivergenceMax
+	}
+	divergence := &api.AdvisorDivergence{
+		AdvisorEstimate:   advisorCost,
+		FallbackEstimate:  fallbackCost,
+		Policy:            policy,
+		AdvisorConfidence: estimate.Confidence,
+	}
+	if advisorCost > 0 {
+		divergence.Ratio = fallbackCost / advisorCost
+	}
+
+	guarded := *estimate
+	response := *estimate.CostEstimateResponse
+	response.EstimatedCost = fallbackCost
+	if policy == advisorDivergenceBlend {
+		response.EstimatedCost = (advisorCost + fallbackCost) / 2
+	}
+	response.Confidence = math.Min(estimate.Confidence, fallback.Confidence) * ratio * advisorCost / fallbackCost
+	guarded.CostEstimateResponse = &response
+	guarded.AdvisorDivergence = divergence
+	guarded.Warning = fmt.Sprintf("Advisor estimate %.2f is far below the fallback estimate %.2f; estimating %.2f instead",
+		advisorCost, fallbackCost, response.EstimatedCost)
+	return &guarded
+}
@@ -0,0 +1,199 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestGuardAdvisorDivergence(t *testing.T) {
+	fallback := &CostEstimateResponse{EstimatedCost: 8.0, Confidence: 0.6}
+	advisor := func(cost float64) *costEstimate {
+		return &costEstimate{CostEstimateResponse: &CostEstimateResponse{EstimatedCost: cost, Confidence: 0.9}}
+	}
+
+	t.Run("convergent estimates are left alone", func(t *testing.T) {
+		for _, cost := range []float64{8.0, 2.0, 0.8, 50.0} {
+			estimate := advisor(cost)
+			assert.Same(t, estimate, guardAdvisorDivergence(estimate, fallback, 10, advisorDivergenceMax), "advisor estimate %.2f", cost)
+		}
+	})
+
+	t.Run("divergent estimate is raised to the fallback", func(t *testing.T) {
+		estimate := advisor(0.2)
+		guarded := guardAdvisorDivergence(estimate, fallback, 10, advisorDivergenceMax)
+
+		assert.Equal(t, 8.0, guarded.EstimatedCost)
+		// The lower confidence, 0.6, scaled by 10 / 40, how far past the ratio it diverges
+		assert.InDelta(t, 0.15, guarded.Confidence, 0.0001)
+		require.NotNil(t, guarded.AdvisorDivergence)
+		assert.Equal(t, 0.2, guarded.AdvisorDivergence.AdvisorEstimate)
+		assert.Equal(t, 8.0, guarded.AdvisorDivergence.FallbackEstimate)
+		assert.InDelta(t, 40.0, guarded.AdvisorDivergence.Ratio, 0.0001)
+		assert.Equal(t, advisorDivergenceMax, guarded.AdvisorDivergence.Policy)
+		assert.Equal(t, 0.9, guarded.AdvisorDivergence.AdvisorConfidence)
+		assert.NotEmpty(t, guarded.Warning)
+
+		// The advisor's response is not modified
+		assert.Equal(t, 0.2, estimate.EstimatedCost)
+		assert.Equal(t, 0.9, estimate.Confidence)
+		assert.Nil(t, estimate.AdvisorDivergence)
+	})
+
+	t.Run("blend takes the mean", func(t *testing.T) {
+		guarded := guardAdvisorDivergence(advisor(0.4), fallback, 10, advisorDivergenceBlend)
+		assert.InDelta(t, 4.2, guarded.EstimatedCost, 0.0001)
+		assert.InDelta(t, 0.3, guarded.Confidence, 0.0001)
+		assert.Equal(t, advisorDivergenceBlend, guarded.AdvisorDivergence.Policy)
+	})
+
+	t.Run("unset policy is MAX", func(t *testing.T) {
+		guarded := guardAdvisorDivergence(advisor(0.4), fallback, 10, "")
+		assert.Equal(t, 8.0, guarded.EstimatedCost)
+		assert.Equal(t, advisorDivergenceMax, guarded.AdvisorDivergence.Policy)
+	})
+
+	t.Run("zero advisor estimate", func(t *testing.T) {
+		guarded := guardAdvisorDivergence(advisor(0), fallback, 10, advisorDivergenceMax)
+		assert.Equal(t, 8.0, guarded.EstimatedCost)
+		assert.Zero(t, guarded.Confidence)
+		require.NotNil(t, guarded.AdvisorDivergence)
+		assert.Zero(t, guarded.AdvisorDivergence.Ratio)
+	})
+}
+
+func TestService_EstimateCost_AdvisorDivergence(t *testing.T) {
+	// The fallback prices this job at 4 CPUs x 2 hours x $0.10, $0.80
+	req := &api.BudgetCheckRequest{
+		Account:   "test-account",
+		Partition: "cpu",
+		Nodes:     1,
+		CPUs:      4,
+		WallTime:  "02:00:00",
+	}
+	regressed := &MockAdvisorClient{EstimateResponse: &CostEstimateResponse{EstimatedCost: 0.01, Confidence: 0.95}}
+
+	t.Run("wildly divergent estimate is raised", func(t *testing.T) {
+		service := &Service{advisorClient: regressed, failureMode: failureModeGraceful}
+		service.SetAdvisorDivergence(10, advisorDivergenceMax)
+
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.8, estimate.EstimatedCost, 0.0001)
+		assert.Less(t, estimate.Confidence, 0.95)
+		require.NotNil(t, estimate.AdvisorDivergence)
+		assert.Empty(t, estimate.FailureMode)
+		assert.False(t, estimate.NoHold)
+	})
+
+	t.Run("convergent estimate is the advisor's", func(t *testing.T) {
+		service := &Service{advisorClient: &MockAdvisorClient{}, failureMode: failureModeGraceful}
+		service.SetAdvisorDivergence(10, advisorDivergenceMax)
+
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, 10.0, estimate.EstimatedCost)
+		assert.Equal(t, 0.8, estimate.Confidence)
+		assert.Nil(t, estimate.AdvisorDivergence)
+		assert.Empty(t, estimate.Warning)
+	})
+
+	t.Run("strict trusts the advisor", func(t *testing.T) {
+		service := &Service{advisorClient: regressed, failureMode: failureModeStrict}
+		service.SetAdvisorDivergence(10, advisorDivergenceMax)
+
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, 0.01, estimate.EstimatedCost)
+		assert.Nil(t, estimate.AdvisorDivergence)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		service := &Service{advisorClient: regressed, failureMode: failureModeGraceful}
+
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, 0.01, estimate.EstimatedCost)
+		assert.Nil(t, estimate.AdvisorDivergence)
+	})
+}
+
+func TestService_EstimateCost_InvalidAdvisorEstimate(t *testing.T) {
+	// The fallback prices this job at 4 CPUs x 2 hours x $0.10, $0.80
+	req := &api.BudgetCheckRequest{
+		Account:   "test-account",
+		Partition: "cpu",
+		Nodes:     1,
+		CPUs:      4,
+		WallTime:  "02:00:00",
+	}
+
+	for _, cost := range []float64{0, -5} {
+		advisor := &MockAdvisorClient{EstimateResponse: &CostEstimateResponse{EstimatedCost: cost, Confidence: 0.95}}
+
+		t.Run(fmt.Sprintf("%.0f falls back to the heuristic", cost), func(t *testing.T) {
+			// Divergence is off, so only the invalid estimate check can raise the estimate
+			service := &Service{advisorClient: advisor, failureMode: failureModeGraceful}
+
+			estimate, err := service.estimateCost(context.Background(), req, "")
+			require.NoError(t, err)
+			assert.InDelta(t, 0.8, estimate.EstimatedCost, 0.0001)
+			assert.Equal(t, 0.7, estimate.Confidence)
+			assert.NotEmpty(t, estimate.Warning)
+			assert.False(t, estimate.NoHold)
+		})
+
+		t.Run(fmt.Sprintf("%.0f is raised to the minimum", cost), func(t *testing.T) {
+			service := &Service{advisorClient: advisor, failureMode: failureModeStrict}
+			service.SetInvalidEstimatePolicy(invalidEstimateMinimum, 2.5)
+
+			estimate, err := service.estimateCost(context.Background(), req, "")
+			require.NoError(t, err)
+			assert.Equal(t, 2.5, estimate.EstimatedCost)
+			assert.Zero(t, estimate.Confidence)
+			assert.NotEmpty(t, estimate.Warning)
+		})
+	}
+
+	t.Run("positive estimate is the advisor's", func(t *testing.T) {
+		service := &Service{advisorClient: &MockAdvisorClient{EstimateResponse: &CostEstimateResponse{EstimatedCost: 0.01}}}
+
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, 0.01, estimate.EstimatedCost)
+		assert.Empty(t, estimate.Warning)
+	})
+}
+
+func TestService_CheckAdvisorDivergence_ConfiguredBaseline(t *testing.T) {
+	// At the site's $0.01 rate the fallback prices 8 CPUs for two days at $3.84
+	req := &api.BudgetCheckRequest{Account: "lab", Partition: "cpu", Nodes: 1, CPUs: 8, WallTime: "2-00:00:00"}
+	service := &Service{failureMode: failureModeGraceful}
+	service.SetAdvisorDivergence(10, advisorDivergenceMax)
+	service.SetFallbackRates(FallbackRates{CostRate: 0.01})
+	advisor := func(cost float64) *costEstimate {
+		return &costEstimate{CostEstimateResponse: &CostEstimateResponse{EstimatedCost: cost, Confidence: 0.9}}
+	}
+
+	t.Run("a correct advisor estimate is left alone", func(t *testing.T) {
+		// Against the built-in $0.10 rate, $38.40, this would diverge more than tenfold
+		estimate := advisor(3.5)
+		assert.Same(t, estimate, service.checkAdvisorDivergence(req, estimate))
+	})
+
+	t.Run("an estimate for an hour of a two-day job is raised", func(t *testing.T) {
+		guarded := service.checkAdvisorDivergence(req, advisor(0.3))
+		require.NotNil(t, guarded.AdvisorDivergence)
+		assert.InDelta(t, 3.84, guarded.AdvisorDivergence.FallbackEstimate, 0.0001)
+		assert.InDelta(t, 3.84, guarded.EstimatedCost, 0.0001)
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// normalizeDomain lower-cases a research domain to match config map keys, which are
+// lower-cased on load, and the recorded accuracy history
+func normalizeDomain(domain string) string {
+	return strings.ToLower(strings.TrimSpace(domain))
+}
+
+// RecordEstimateAccuracy records a reconciled job's estimated and actual cost against its
+// research domain, the history domain factor learning draws on. Jobs without a domain or
+// an estimate are skipped.
+func (s *Service) RecordEstimateAccuracy(ctx context.Context, domain, jobID string, estimatedCost, actualCost float64) error {
+	domain = normalizeDomain(domain)
+	if domain == "" || estimatedCost <= 0 || actualCost < 0 {
+		return nil
+	}
+	return s.accuracyQueries.RecordEstimateAccuracy(ctx, domain, jobID, estimatedCost, actualCost)
+}
+
+// domainFactor returns the multiplier for cost estimates in a research domain: the learned
+// actual-to-estimated ratio once learning has enough history, otherwise the configured
+// factor, otherwise 1
+func (s *Service) domainFactor(ctx context.Context, domain string) float64 {
+	domain = normalizeDomain(domain)
+	if domain == "" {
+		return 1
+	}
+
+	if s.config.DomainFactorLearning {
+		ratio, samples, err := s.accuracyQueries.DomainCostRatio(ctx, domain, s.config.DomainFactorSampleLimit)
+		if err != nil {
+			log.Warn().Err(err).Str("domain", domain).Msg("Failed to load domain estimate history, using configured factor")
+		} else if samples >= s.config.DomainFactorMinSamples && ratio > 0 {
+			return ratio
+		}
+	}
+
+	return s.configuredDomainFactor(domain)
+}
+
+// configuredDomainFactor returns the configured factor for a normalized domain, or 1
+func (s *Service) configuredDomainFactor(domain string) float64 {
+	if factor, ok := s.config.DomainInflationFactors[domain]; ok && factor > 0 {
+		return factor
+	}
+	return 1
+}
+
+// applyDomainFactor scales a cost estimate by its research domain's factor. The advisor's
+// response is copied rather than modified.
+func (s *Service) applyDomainFactor(ctx context.Context, estimate *costEstimate, domain string) *costEstimate {
+	factor := s.domainFactor(ctx, domain)
+	if factor == 1 {
+		return estimate
+	}
+	return scaleEstimate(estimate, factor)
+}
+
+// scaleEstimate returns a copy of estimate with its cost multiplied by factor
+func scaleEstimate(estimate *costEstimate, factor float64) *costEstimate {
+	scaled := *estimate
+	response := *estimate.CostEstimateResponse
+	response.EstimatedCost *= factor
+	scaled.CostEstimateResponse = &response
+	scaled.DomainFactor = factor
+	return &scaled
+}
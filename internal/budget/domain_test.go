@@ -0,0 +1,62 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+)
+
+func TestApplyDomainFactor(t *testing.T) {
+	service := NewService(nil, nil, &config.BudgetConfig{
+		DefaultHoldPercentage:  1.2,
+		DomainInflationFactors: map[string]float64{"genomics": 1.3, "cfd": 0.8},
+	})
+	advisorResponse := &CostEstimateResponse{EstimatedCost: 100.0, Confidence: 0.9}
+	estimate := &costEstimate{CostEstimateResponse: advisorResponse}
+
+	tests := []struct {
+		name     string
+		domain   string
+		cost     float64
+		factor   float64
+		unscaled bool
+	}{
+		{name: "overrunning domain is inflated", domain: "genomics", cost: 130.0, factor: 1.3},
+		{name: "domain match ignores case", domain: " Genomics ", cost: 130.0, factor: 1.3},
+		{name: "underrunning domain is deflated", domain: "cfd", cost: 80.0, factor: 0.8},
+		{name: "unconfigured domain is unchanged", domain: "astronomy", cost: 100.0, unscaled: true},
+		{name: "no domain is unchanged", cost: 100.0, unscaled: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := service.applyDomainFactor(context.Background(), estimate, tt.domain)
+
+			assert.InDelta(t, tt.cost, result.EstimatedCost, 0.0001)
+			assert.InDelta(t, tt.factor, result.DomainFactor, 0.0001)
+			if tt.unscaled {
+				assert.Same(t, estimate, result)
+			}
+			assert.Equal(t, 0.9, result.Confidence)
+		})
+	}
+
+	assert.Equal(t, 100.0, advisorResponse.EstimatedCost, "the advisor's response is not modified")
+}
+
+func TestRecordEstimateAccuracy_SkipsIncompleteJobs(t *testing.T) {
+	// With no database, anything that reached the queries would fail
+	service := NewService(nil, nil, &config.BudgetConfig{})
+	ctx := context.Background()
+
+	assert.NoError(t, service.RecordEstimateAccuracy(ctx, "", "1001", 10, 12))
+	assert.NoError(t, service.RecordEstimateAccuracy(ctx, "genomics", "1002", 0, 12))
+	assert.NoError(t, service.RecordEstimateAccuracy(ctx, "genomics", "1003", 10, -1))
+}
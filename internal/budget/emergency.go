@@ -0,0 +1,271 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// maxReallocationOptions caps how many sibling accounts are proposed to reallocate from
+const maxReallocationOptions = 3
+
+// emergencyDeadline is the nearest date an account must keep running until
+type emergencyDeadline struct {
+	Date        time.Time
+	Description string
+}
+
+// emergencyCase is what emergency options are drawn up from: an account in critical health,
+// the account whose pool limits it, the sibling accounts that could help and the deadline
+type emergencyCase struct {
+	Account  *api.BudgetAccount
+	Limiting *api.BudgetAccount
+	Siblings []*api.BudgetAccount
+	Deadline emergencyDeadline
+	// Funds the account needs to reach the deadline, and what it spends a day
+	Shortfall  float64
+	DailySpend float64
+}
+
+// GetEmergencyOptions proposes ways to fund an account in critical budget health whose end
+// date, grant end date or grant deadline falls within budget.emergency_deadline_window:
+// reallocating from sibling accounts with budget to spare, releasing the reserve of the
+// account whose pool limits it, or sharing job costs with a sibling. Any other account
+// gets no options.
+func (s *Service) GetEmergencyOptions(ctx context.Context, slurmAccount string) ([]api.EmergencyOption, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.EmergencyDeadlineWindow <= 0 {
+		return []api.EmergencyOption{}, nil
+	}
+
+	ancestors, err := s.accountQueries.ListAncestors(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	graceCredit, err := s.holdGraceCredit(ctx, account, ancestors)
+	if err != nil {
+		return nil, err
+	}
+	health := userAccount(account, ancestors, graceCredit, s.utilizationBands())
+	if health.Health != api.AccountHealthCritical {
+		return []api.EmergencyOption{}, nil
+	}
+
+	now := time.Now()
+	deadline, err := s.nextDeadline(ctx, account, now)
+	if err != nil {
+		return nil, err
+	}
+	if deadline == nil || deadline.Date.After(now.Add(s.config.EmergencyDeadlineWindow)) {
+		return []api.EmergencyOption{}, nil
+	}
+
+	siblings, err := s.accountQueries.ListSiblings(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	_, limiting := chainAvailable(account, ancestors, graceCredit)
+	shortfall, daily := projectShortfall(account, health.BudgetAvailable, s.config.AlertCriticalThreshold, deadline.Date, now)
+	return emergencyOptions(&emergencyCase{
+		Account:    account,
+		Limiting:   limiting,
+		Siblings:   siblings,
+		Deadline:   *deadline,
+		Shortfall:  shortfall,
+		DailySpend: daily,
+	}), nil
+}
+
+// nextDeadline returns the earliest of the account's end date, its grants' end dates and
+// its grants' deadlines still to come, or nil when none is
+func (s *Service) nextDeadline(ctx context.Context, account *api.BudgetAccount, now time.Time) (*emergencyDeadline, error) {
+	candidates := []emergencyDeadline{{
+		Date:        account.EndDate,
+		Description: fmt.Sprintf("account '%s' ends", account.SlurmAccount),
+	}}
+
+	grants, err := s.grantQueries.ListAccountGrants(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range grants {
+		candidates = append(candidates, emergencyDeadline{
+			Date:        grant.GrantEndDate,
+			Description: fmt.Sprintf("grant %s ends", grant.GrantNumber),
+		})
+		deadlines, err := s.deadlineQueries.ListDeadlines(ctx, grant.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, deadline := range deadlines {
+			candidates = append(candidates, emergencyDeadline{
+				Date:        deadline.DueDate,
+				Description: grantDeadlineDescription(grant, deadline),
+			})
+		}
+	}
+
+	return earliestDeadline(candidates, now), nil
+}
+
+// grantDeadlineDescription names a grant deadline for an emergency option
+func grantDeadlineDescription(grant *api.GrantAccount, deadline *api.GrantDeadline) string {
+	if deadline.Description != "" {
+		return fmt.Sprintf("%s (%s, grant %s)", deadline.Description, deadline.Type, grant.GrantNumber)
+	}
+	return fmt.Sprintf("%s deadline for grant %s", deadline.Type, grant.GrantNumber)
+}
+
+// earliestDeadline returns the earliest candidate after now, or nil when none is
+func earliestDeadline(candidates []emergencyDeadline, now time.Time) *emergencyDeadline {
+	var earliest *emergencyDeadline
+	for i := range candidates {
+		if !candidates[i].Date.After(now) {
+			continue
+		}
+		if earliest == nil || candidates[i].Date.Before(earliest.Date) {
+			earliest = &candidates[i]
+		}
+	}
+	return earliest
+}
+
+// projectShortfall estimates the funds an account needs to reach the deadline: spending at
+// its average daily rate since it started, less what it has available, and at least enough
+// to bring its utilization back under the critical threshold. It also returns the daily rate.
+func projectShortfall(account *api.BudgetAccount, available, criticalThreshold float64, deadline, now time.Time) (float64, float64) {
+	elapsed := math.Max(1, now.Sub(account.StartDate).Hours()/24)
+	daily := account.BudgetUsed / elapsed
+	remaining := math.Max(0, deadline.Sub(now).Hours()/24)
+
+	shortfall := daily*remaining - math.Max(0, available)
+	if criticalThreshold > 0 {
+		committed := account.BudgetUsed + account.BudgetHeld
+		shortfall = math.Max(shortfall, committed*100/criticalThreshold-account.BudgetLimit)
+	}
+
+	return roundCents(math.Max(0, shortfall)), roundCents(daily)
+}
+
+// emergencyOptions draws up the options for an emergency case: reallocation from up to
+// maxReallocationOptions siblings with the most to spare, the limiting account's reserve,
+// and cost sharing with the sibling with the most to spare
+func emergencyOptions(c *emergencyCase) []api.EmergencyOption {
+	options := []api.EmergencyOption{}
+
+	var donors []*api.BudgetAccount
+	for _, sibling := range c.Siblings {
+		if sibling.IsActive() && !sibling.Frozen && sibling.SpendableAvailable() > 0 {
+			donors = append(donors, sibling)
+		}
+	}
+	sort.SliceStable(donors, func(i, j int) bool {
+		return donors[i].SpendableAvailable() > donors[j].SpendableAvailable()
+	})
+
+	for i, donor := range donors {
+		if i == maxReallocationOptions {
+			break
+		}
+		spare := roundCents(donor.SpendableAvailable())
+		amount := c.fundsFrom(spare)
+		risk := "MEDIUM"
+		if spare >= 2*amount {
+			risk = "LOW"
+		}
+		options = append(options, api.EmergencyOption{
+			Option:        api.EmergencyOptionReallocate,
+			Description:   fmt.Sprintf("Reallocate $%.2f from sibling account '%s'", amount, donor.SlurmAccount),
+			Account:       donor.SlurmAccount,
+			Available:     spare,
+			EstimatedCost: amount,
+			Impact:        c.impact(amount),
+			Timeline:      "Immediate once both accounts' managers approve",
+			Requirements: []string{
+				fmt.Sprintf("Approval from the manager of '%s'", donor.SlurmAccount),
+				fmt.Sprintf("Lower the budget_limit of '%s' by $%.2f and raise that of '%s' by the same amount",
+					donor.SlurmAccount, amount, c.Account.SlurmAccount),
+			},
+			RiskLevel: risk,
+		})
+	}
+
+	if reserve := roundCents(c.Limiting.ReservedAmount); reserve > 0 {
+		amount := c.fundsFrom(reserve)
+		options = append(options, api.EmergencyOption{
+			Option:        api.EmergencyOptionEmergencyFund,
+			Description:   fmt.Sprintf("Release $%.2f of the emergency reserve held back in '%s'", amount, c.Limiting.SlurmAccount),
+			Account:       c.Limiting.SlurmAccount,
+			Available:     reserve,
+			EstimatedCost: amount,
+			Impact:        c.impact(amount),
+			Timeline:      "Immediate once an administrator releases the reserve",
+			Requirements: []string{
+				"Administrator approval to spend reserved funds",
+				fmt.Sprintf("Lower the reserved_amount of '%s' by $%.2f", c.Limiting.SlurmAccount, amount),
+			},
+			RiskLevel: "MEDIUM",
+		})
+	}
+
+	if len(donors) > 0 {
+		partner := donors[0]
+		spare := roundCents(partner.SpendableAvailable())
+		amount := c.fundsFrom(spare / 2)
+		options = append(options, api.EmergencyOption{
+			Option:        api.EmergencyOptionCostSharing,
+			Description:   fmt.Sprintf("Split job costs evenly with '%s' until %s", partner.SlurmAccount, c.Deadline.Description),
+			Account:       partner.SlurmAccount,
+			Available:     spare,
+			EstimatedCost: amount,
+			Impact:        fmt.Sprintf("Halves what each job costs '%s'; %s", c.Account.SlurmAccount, c.impact(amount)),
+			Timeline:      fmt.Sprintf("Applies to jobs submitted until %s", c.Deadline.Date.Format("2006-01-02")),
+			Requirements: []string{
+				fmt.Sprintf("Agreement from '%s' to fund half of each job", partner.SlurmAccount),
+				fmt.Sprintf("Job submitters are members of '%s'", partner.SlurmAccount),
+				fmt.Sprintf("Budget checks name '%s' in cost_shares at 50%%", partner.SlurmAccount),
+			},
+			RiskLevel: "LOW",
+		})
+	}
+
+	return options
+}
+
+// fundsFrom returns how much of what a source has to spare an option would draw: the
+// shortfall, or everything when the shortfall is larger or unknown
+func (c *emergencyCase) fundsFrom(spare float64) float64 {
+	if c.Shortfall > 0 && c.Shortfall < spare {
+		return c.Shortfall
+	}
+	return roundCents(spare)
+}
+
+// impact describes how far an option's funds go toward the deadline
+func (c *emergencyCase) impact(amount float64) string {
+	var impact string
+	switch {
+	case c.Shortfall <= 0:
+		impact = fmt.Sprintf("Adds $%.2f of headroom before %s", amount, c.Deadline.Description)
+	case amount >= c.Shortfall:
+		impact = fmt.Sprintf("Covers the projected $%.2f shortfall until %s", c.Shortfall, c.Deadline.Description)
+	default:
+		impact = fmt.Sprintf("Covers $%.2f of the projected $%.2f shortfall until %s", amount, c.Shortfall, c.Deadline.Description)
+	}
+	if c.DailySpend > 0 {
+		impact += fmt.Sprintf(", about %.0f days at the current $%.2f a day", math.Floor(amount/c.DailySpend), c.DailySpend)
+	}
+	return impact
+}
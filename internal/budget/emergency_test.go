@@ -0,0 +1,117 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestEarliestDeadline(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	deadline := earliestDeadline([]emergencyDeadline{
+		{Date: now.AddDate(1, 0, 0), Description: "account ends"},
+		{Date: now.AddDate(0, 0, -3), Description: "past report"},
+		{Date: now.AddDate(0, 0, 20), Description: "conference"},
+	}, now)
+	require.NotNil(t, deadline)
+	assert.Equal(t, "conference", deadline.Description, "past deadlines are skipped")
+
+	assert.Nil(t, earliestDeadline([]emergencyDeadline{{Date: now.AddDate(0, 0, -1)}}, now))
+}
+
+func TestProjectShortfall(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	account := &api.BudgetAccount{
+		BudgetLimit: 1000,
+		BudgetUsed:  900,
+		StartDate:   now.AddDate(0, 0, -90),
+	}
+
+	// $10 a day for 20 more days, with $100 left
+	shortfall, daily := projectShortfall(account, 100, 0, now.AddDate(0, 0, 20), now)
+	assert.InDelta(t, 10.0, daily, 0.001)
+	assert.InDelta(t, 100.0, shortfall, 0.001)
+
+	// Getting back under a 80% critical threshold needs the limit raised to $1125
+	shortfall, _ = projectShortfall(account, 100, 80, now.AddDate(0, 0, 5), now)
+	assert.InDelta(t, 125.0, shortfall, 0.001)
+
+	shortfall, _ = projectShortfall(account, 100, 0, now.AddDate(0, 0, 5), now)
+	assert.Zero(t, shortfall, "enough is left to reach the deadline")
+}
+
+func TestEmergencyOptions(t *testing.T) {
+	active := func(name string, limit, used, reserved float64) *api.BudgetAccount {
+		return &api.BudgetAccount{
+			SlurmAccount:   name,
+			Status:         "active",
+			BudgetLimit:    limit,
+			BudgetUsed:     used,
+			ReservedAmount: reserved,
+			StartDate:      time.Now().AddDate(0, -1, 0),
+			EndDate:        time.Now().AddDate(1, 0, 0),
+		}
+	}
+	account := active("proj-critical", 1000, 990, 50)
+	deadline := emergencyDeadline{Date: time.Now().AddDate(0, 0, 14), Description: "grant NSF-1 ends"}
+
+	t.Run("critical account with siblings and a reserve", func(t *testing.T) {
+		frozen := active("proj-frozen", 1000, 0, 0)
+		frozen.Frozen = true
+		options := emergencyOptions(&emergencyCase{
+			Account:  account,
+			Limiting: account,
+			Siblings: []*api.BudgetAccount{
+				active("proj-small", 1000, 900, 0),
+				frozen,
+				active("proj-spent", 500, 500, 0),
+				active("proj-large", 2000, 500, 0),
+			},
+			Deadline:   deadline,
+			Shortfall:  200,
+			DailySpend: 20,
+		})
+
+		var kinds []string
+		for _, option := range options {
+			kinds = append(kinds, option.Option)
+		}
+		assert.Equal(t, []string{
+			api.EmergencyOptionReallocate, api.EmergencyOptionReallocate,
+			api.EmergencyOptionEmergencyFund, api.EmergencyOptionCostSharing,
+		}, kinds)
+
+		// The sibling with the most to spare comes first and covers the shortfall
+		assert.Equal(t, "proj-large", options[0].Account)
+		assert.Equal(t, 1500.0, options[0].Available)
+		assert.Equal(t, 200.0, options[0].EstimatedCost)
+		assert.Equal(t, "LOW", options[0].RiskLevel)
+		assert.Contains(t, options[0].Impact, "Covers the projected $200.00 shortfall until grant NSF-1 ends")
+		assert.NotEmpty(t, options[0].Requirements)
+
+		assert.Equal(t, "proj-small", options[1].Account)
+		assert.Equal(t, 100.0, options[1].EstimatedCost, "a sibling with less to spare gives what it has")
+		assert.Equal(t, "MEDIUM", options[1].RiskLevel)
+		assert.Contains(t, options[1].Impact, "about 5 days")
+
+		assert.Equal(t, "proj-critical", options[2].Account)
+		assert.Equal(t, 50.0, options[2].EstimatedCost)
+
+		assert.Equal(t, "proj-large", options[3].Account)
+		assert.Equal(t, 200.0, options[3].EstimatedCost)
+	})
+
+	t.Run("nowhere to draw funds from", func(t *testing.T) {
+		alone := active("proj-alone", 1000, 990, 0)
+		options := emergencyOptions(&emergencyCase{Account: alone, Limiting: alone, Deadline: deadline, Shortfall: 200})
+		assert.Empty(t, options)
+	})
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// EstimateJobCost prices a job shape as a budget check would, through the advisor or its
+// fallback, the research domain's factor and the job script's history, without reading
+// any account or placing a hold. In STRICT mode an unavailable advisor is an error;
+// otherwise the fallback estimate is returned with a warning.
+func (s *Service) EstimateJobCost(ctx context.Context, req *api.EstimateRequest) (*api.EstimateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	estimate, err := s.estimateCost(ctx, &api.BudgetCheckRequest{
+		Account:   req.Account,
+		Partition: req.Partition,
+		Nodes:     req.Nodes,
+		CPUs:      req.CPUs,
+		GPUs:      req.GPUs,
+		Memory:    req.Memory,
+		WallTime:  req.WallTime,
+		JobScript: req.JobScript,
+	}, "")
+	if err != nil {
+		return nil, err
+	}
+	estimate = s.applyDomainFactor(ctx, estimate, req.ResearchDomain)
+	estimate = s.applyScriptHistory(ctx, estimate, req.JobScript)
+
+	resp := &api.EstimateResponse{
+		EstimatedCost:     estimate.EstimatedCost,
+		Confidence:        estimate.Confidence,
+		Recommendation:    estimate.Recommendation,
+		FailureMode:       estimate.FailureMode,
+		DomainFactor:      estimate.DomainFactor,
+		ScriptHistory:     estimate.ScriptHistory,
+		AdvisorDivergence: estimate.AdvisorDivergence,
+	}
+	if estimate.FailureMode != "" {
+		// The budget check's warning describes its hold; there is none here
+		resp.Warning = "Advisor service unavailable; estimate is from the fallback heuristic"
+	} else if estimate.AdvisorDivergence != nil {
+		resp.Warning = estimate.Warning
+	}
+	return resp, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// recordingAdvisorClient returns a fixed estimate and records the request it was asked to price
+type recordingAdvisorClient struct {
+	MockAdvisorClient
+	last *CostEstimateRequest
+}
+
+func (r *recordingAdvisorClient) EstimateCost(ctx context.Context, req *CostEstimateRequest) (*CostEstimateResponse, error) {
+	r.last = req
+	return r.MockAdvisorClient.EstimateCost(ctx, req)
+}
+
+func TestService_EstimateJobCost(t *testing.T) {
+	gpuJob := &api.EstimateRequest{
+		Partition: "gpu",
+		Nodes:     1,
+		CPUs:      8,
+		GPUs:      4,
+		Memory:    "64G",
+		WallTime:  "04:00:00",
+	}
+	failing := &MockAdvisorClient{EstimateError: api.NewServiceUnavailableError("advisor", assert.AnError)}
+
+	t.Run("GPU job priced by the advisor", func(t *testing.T) {
+		advisor := &recordingAdvisorClient{MockAdvisorClient: MockAdvisorClient{EstimateResponse: &CostEstimateResponse{
+			EstimatedCost: 48.0, Confidence: 0.9, Recommendation: "Use p3.8xlarge",
+		}}}
+		service := &Service{advisorClient: advisor, config: &config.BudgetConfig{}}
+
+		resp, err := service.EstimateJobCost(context.Background(), gpuJob)
+		require.NoError(t, err)
+		assert.Equal(t, 48.0, resp.EstimatedCost)
+		assert.Equal(t, 0.9, resp.Confidence)
+		assert.Equal(t, "Use p3.8xlarge", resp.Recommendation)
+		assert.Empty(t, resp.FailureMode)
+		assert.Empty(t, resp.Warning)
+
+		require.NotNil(t, advisor.last)
+		assert.Equal(t, 4, advisor.last.GPUs)
+		assert.Equal(t, "64G", advisor.last.Memory)
+		assert.Empty(t, advisor.last.Account)
+	})
+
+	t.Run("research domain factor applies", func(t *testing.T) {
+		service := &Service{advisorClient: &MockAdvisorClient{}, config: &config.BudgetConfig{
+			DomainInflationFactors: map[string]float64{"genomics": 1.5},
+		}}
+		req := *gpuJob
+		req.ResearchDomain = "Genomics"
+
+		resp, err := service.EstimateJobCost(context.Background(), &req)
+		require.NoError(t, err)
+		assert.InDelta(t, 15.0, resp.EstimatedCost, 0.001)
+		assert.Equal(t, 1.5, resp.DomainFactor)
+	})
+
+	t.Run("fallback when the advisor is down", func(t *testing.T) {
+		service := &Service{advisorClient: failing, config: &config.BudgetConfig{}}
+
+		resp, err := service.EstimateJobCost(context.Background(), gpuJob)
+		require.NoError(t, err)
+		assert.Equal(t, failureModeGraceful, resp.FailureMode)
+		assert.NotEmpty(t, resp.Warning)
+		assert.Equal(t, service.fallbackCostEstimate(&api.BudgetCheckRequest{
+			Partition: "gpu", Nodes: 1, CPUs: 8, GPUs: 4, Memory: "64G", WallTime: "04:00:00",
+		}).EstimatedCost, resp.EstimatedCost)
+
+		cpuJob := *gpuJob
+		cpuJob.Partition, cpuJob.GPUs = "cpu", 0
+		cpu, err := service.EstimateJobCost(context.Background(), &cpuJob)
+		require.NoError(t, err)
+		assert.Greater(t, resp.EstimatedCost, cpu.EstimatedCost)
+	})
+
+	t.Run("a wall time in days is priced for every day", func(t *testing.T) {
+		service := &Service{advisorClient: failing, config: &config.BudgetConfig{}}
+		hour := service.fallbackCostEstimate(&api.BudgetCheckRequest{Partition: "cpu", Nodes: 1, CPUs: 8, WallTime: "01:00:00"})
+		days := service.fallbackCostEstimate(&api.BudgetCheckRequest{Partition: "cpu", Nodes: 1, CPUs: 8, WallTime: "2-00:00:00"})
+		assert.InDelta(t, 48*hour.EstimatedCost, days.EstimatedCost, 0.0001)
+	})
+
+	t.Run("divergent advisor estimate is raised", func(t *testing.T) {
+		regressed := &MockAdvisorClient{EstimateResponse: &CostEstimateResponse{EstimatedCost: 0.05, Confidence: 0.9}}
+		service := &Service{advisorClient: regressed, config: &config.BudgetConfig{}}
+		service.SetAdvisorDivergence(10, advisorDivergenceMax)
+
+		resp, err := service.EstimateJobCost(context.Background(), gpuJob)
+		require.NoError(t, err)
+		require.NotNil(t, resp.AdvisorDivergence)
+		assert.Equal(t, resp.AdvisorDivergence.FallbackEstimate, resp.EstimatedCost)
+		assert.Less(t, resp.Confidence, 0.9)
+		assert.Empty(t, resp.FailureMode)
+		assert.NotEmpty(t, resp.Warning)
+	})
+
+	t.Run("strict mode refuses without the advisor", func(t *testing.T) {
+		service := &Service{advisorClient: failing, failureMode: failureModeStrict, config: &config.BudgetConfig{}}
+
+		_, err := service.EstimateJobCost(context.Background(), gpuJob)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAdvisorUnavailable, budgetErr.Code)
+	})
+
+	t.Run("invalid job shape", func(t *testing.T) {
+		service := &Service{advisorClient: &MockAdvisorClient{}, config: &config.BudgetConfig{}}
+
+		for _, req := range []*api.EstimateRequest{
+			{Nodes: 1, CPUs: 1, WallTime: "01:00:00"},
+			{Partition: "cpu", CPUs: 1, WallTime: "01:00:00"},
+			{Partition: "cpu", Nodes: 1, WallTime: "01:00:00"},
+			{Partition: "cpu", Nodes: 1, CPUs: 1, GPUs: -1, WallTime: "01:00:00"},
+			{Partition: "cpu", Nodes: 1, CPUs: 1},
+		} {
+			_, err := service.EstimateJobCost(context.Background(), req)
+			budgetErr, ok := api.AsBudgetError(err)
+			require.True(t, ok)
+			assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		}
+	})
+}
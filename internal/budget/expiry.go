@@ -0,0 +1,139 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// defaultHoldExpiryTimeout bounds the pre-expiry callback when no timeout is configured
+const defaultHoldExpiryTimeout = 5 * time.Second
+
+// HoldExpiryChecker asks an outside system what became of a job before recovery cancels
+// its orphaned hold
+type HoldExpiryChecker interface {
+	// CheckExpiringHold returns a reconciliation for the hold's job, or nil when the
+	// system has nothing for it
+	CheckExpiringHold(ctx context.Context, hold *api.BudgetTransaction) (*api.JobReconcileRequest, error)
+}
+
+// holdOutcome is what recovery did with one pending hold
+type holdOutcome int
+
+const (
+	holdKept       holdOutcome = iota // not yet old enough to recover
+	holdCancelled                     // cancelled and refunded
+	holdReconciled                    // reconciled from late job data
+	holdCharged                       // charged at its estimate under the CHARGE policy
+)
+
+// Expired hold policies, what recovery does with a hold nobody reconciled
+const (
+	expiredHoldRefund = "REFUND"
+	expiredHoldCharge = "CHARGE"
+)
+
+// SetHoldExpiryChecker has recovery consult checker, waiting at most timeout, before it
+// cancels an orphaned hold. A nil checker leaves recovery cancelling without asking.
+func (s *Service) SetHoldExpiryChecker(checker HoldExpiryChecker, timeout time.Duration) {
+	s.holdExpiryChecker = checker
+	s.holdExpiryTimeout = timeout
+}
+
+// expiredHoldAge returns how old a pending hold must be before recovery expires it
+func (s *Service) expiredHoldAge() time.Duration {
+	if s.config.ExpiredHoldTimeout > 0 {
+		return s.config.ExpiredHoldTimeout
+	}
+	return s.config.ReconciliationTimeout * 2
+}
+
+// expiredHoldPolicy returns the partition's expired hold policy, or the configured default
+// when the partition has no override
+func (s *Service) expiredHoldPolicy(partition string) string {
+	if policy, ok := s.config.PartitionExpiredHoldPolicy[strings.ToLower(partition)]; ok {
+		return policy
+	}
+	if s.config.ExpiredHoldPolicy == "" {
+		return expiredHoldRefund
+	}
+	return s.config.ExpiredHoldPolicy
+}
+
+// expiredHoldCharge returns the reconciliation charging an expired hold's job what it was
+// estimated to cost, when the hold's partition follows the CHARGE policy. It returns nil,
+// so that the hold is refunded, under REFUND or when the hold records no estimate.
+func (s *Service) expiredHoldCharge(hold *api.BudgetTransaction) *api.JobReconcileRequest {
+	metadata, err := api.DecodeTransactionMetadata(hold.Type, hold.Metadata)
+	if err != nil {
+		log.Warn().Err(err).Str("transaction_id", hold.TransactionID).
+			Msg("Unreadable hold metadata; refunding expired hold")
+		return nil
+	}
+	holdMetadata, ok := metadata.(*api.HoldMetadata)
+	if !ok || s.expiredHoldPolicy(holdMetadata.Partition) != expiredHoldCharge {
+		return nil
+	}
+	if holdMetadata.EstimatedCost <= 0 {
+		log.Warn().Str("transaction_id", hold.TransactionID).
+			Msg("Expired hold records no estimate to charge; refunding it")
+		return nil
+	}
+
+	// A hold never tagged with its job is charged under its own ID
+	jobID := holdJobID(hold)
+	if jobID == "" {
+		jobID = hold.TransactionID
+	}
+	return &api.JobReconcileRequest{
+		JobID:             jobID,
+		ActualCost:        holdMetadata.EstimatedCost,
+		TransactionID:     hold.TransactionID,
+		ChargedAtEstimate: true,
+	}
+}
+
+// expiringHoldReconciliation asks the hold expiry checker for the job's final cost. It
+// returns nil, so that the hold is cancelled, when there is no checker, the checker has
+// nothing for the job, or the callback fails or runs out of time.
+func (s *Service) expiringHoldReconciliation(ctx context.Context, hold *api.BudgetTransaction) *api.JobReconcileRequest {
+	if s.holdExpiryChecker == nil {
+		return nil
+	}
+
+	timeout := s.holdExpiryTimeout
+	if timeout <= 0 {
+		timeout = defaultHoldExpiryTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := s.holdExpiryChecker.CheckExpiringHold(ctx, hold)
+	if err != nil {
+		log.Warn().Err(err).Str("transaction_id", hold.TransactionID).
+			Msg("Hold expiry callback failed; cancelling hold")
+		return nil
+	}
+	if req == nil {
+		return nil
+	}
+
+	req.TransactionID = hold.TransactionID
+	if req.JobID == "" && hold.JobID != nil {
+		req.JobID = *hold.JobID
+	}
+	if req.JobID == "" {
+		log.Warn().Str("transaction_id", hold.TransactionID).
+			Msg("Hold expiry callback returned no job ID; cancelling hold")
+		return nil
+	}
+	return req
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// fakeHoldExpiryChecker stands in for ASBX, answering with a fixed result or waiting for
+// the callback's deadline
+type fakeHoldExpiryChecker struct {
+	req   *api.JobReconcileRequest
+	err   error
+	block bool
+	calls int
+}
+
+func (f *fakeHoldExpiryChecker) CheckExpiringHold(ctx context.Context, hold *api.BudgetTransaction) (*api.JobReconcileRequest, error) {
+	f.calls++
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return f.req, f.err
+}
+
+func TestExpiringHoldReconciliation(t *testing.T) {
+	jobID := "12345"
+	hold := &api.BudgetTransaction{TransactionID: "txn_expiring", JobID: &jobID, Amount: 12}
+	ctx := context.Background()
+
+	t.Run("no checker cancels", func(t *testing.T) {
+		service := &Service{}
+		assert.Nil(t, service.expiringHoldReconciliation(ctx, hold))
+	})
+
+	t.Run("ASBX has data so the hold is reconciled", func(t *testing.T) {
+		checker := &fakeHoldExpiryChecker{req: &api.JobReconcileRequest{ActualCost: 9.5, JobState: "COMPLETED"}}
+		service := &Service{}
+		service.SetHoldExpiryChecker(checker, time.Second)
+
+		req := service.expiringHoldReconciliation(ctx, hold)
+		require.NotNil(t, req)
+		assert.Equal(t, "txn_expiring", req.TransactionID)
+		assert.Equal(t, "12345", req.JobID)
+		assert.InDelta(t, 9.5, req.ActualCost, 0.001)
+		assert.Equal(t, 1, checker.calls)
+	})
+
+	t.Run("ASBX has nothing so the hold is cancelled", func(t *testing.T) {
+		service := &Service{}
+		service.SetHoldExpiryChecker(&fakeHoldExpiryChecker{}, time.Second)
+		assert.Nil(t, service.expiringHoldReconciliation(ctx, hold))
+	})
+
+	t.Run("callback failure cancels", func(t *testing.T) {
+		service := &Service{}
+		service.SetHoldExpiryChecker(&fakeHoldExpiryChecker{err: errors.New("connection refused")}, time.Second)
+		assert.Nil(t, service.expiringHoldReconciliation(ctx, hold))
+	})
+
+	t.Run("slow callback is cut off", func(t *testing.T) {
+		service := &Service{}
+		service.SetHoldExpiryChecker(&fakeHoldExpiryChecker{block: true}, 20*time.Millisecond)
+
+		start := time.Now()
+		assert.Nil(t, service.expiringHoldReconciliation(ctx, hold))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("data without a job ID cancels", func(t *testing.T) {
+		service := &Service{}
+		service.SetHoldExpiryChecker(&fakeHoldExpiryChecker{req: &api.JobReconcileRequest{ActualCost: 9.5}}, time.Second)
+		assert.Nil(t, service.expiringHoldReconciliation(ctx, &api.BudgetTransaction{TransactionID: "txn_no_job"}))
+	})
+}
+
+func TestExpiredHoldPolicy(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{
+		ReconciliationTimeout:      24 * time.Hour,
+		PartitionExpiredHoldPolicy: map[string]string{"aws": expiredHoldCharge},
+	}}
+	assert.Equal(t, expiredHoldRefund, service.expiredHoldPolicy("cpu"), "unset policy refunds")
+	assert.Equal(t, expiredHoldCharge, service.expiredHoldPolicy("AWS"))
+	assert.Equal(t, 48*time.Hour, service.expiredHoldAge(), "default timeout is twice the reconciliation timeout")
+
+	service.config.ExpiredHoldPolicy = expiredHoldCharge
+	service.config.PartitionExpiredHoldPolicy["onprem"] = expiredHoldRefund
+	service.config.ExpiredHoldTimeout = 36 * time.Hour
+	assert.Equal(t, expiredHoldCharge, service.expiredHoldPolicy("cpu"))
+	assert.Equal(t, expiredHoldRefund, service.expiredHoldPolicy("onprem"))
+	assert.Equal(t, 36*time.Hour, service.expiredHoldAge())
+}
+
+func TestExpiredHoldCharge(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{
+		ExpiredHoldPolicy:          expiredHoldRefund,
+		PartitionExpiredHoldPolicy: map[string]string{"aws": expiredHoldCharge},
+	}}
+	hold := func(partition string, estimate float64) *api.BudgetTransaction {
+		metadata, err := api.EncodeTransactionMetadata(&api.HoldMetadata{
+			Partition: partition, EstimatedCost: estimate, HoldPercentage: 1.2,
+		})
+		require.NoError(t, err)
+		return &api.BudgetTransaction{TransactionID: "txn_expired", Type: "hold", Amount: estimate * 1.2, Metadata: metadata}
+	}
+
+	t.Run("AWS partition is charged its estimate", func(t *testing.T) {
+		req := service.expiredHoldCharge(hold("aws", 10))
+		require.NotNil(t, req)
+		assert.Equal(t, "txn_expired", req.TransactionID)
+		assert.Equal(t, "txn_expired", req.JobID, "an untagged hold is charged under its own ID")
+		assert.InDelta(t, 10.0, req.ActualCost, 0.001)
+		assert.True(t, req.ChargedAtEstimate)
+		assert.NoError(t, req.Validate())
+	})
+
+	t.Run("tagged hold is charged under its job", func(t *testing.T) {
+		tagged := hold("aws", 10)
+		jobID := "12345"
+		tagged.JobID = &jobID
+		req := service.expiredHoldCharge(tagged)
+		require.NotNil(t, req)
+		assert.Equal(t, "12345", req.JobID)
+	})
+
+	t.Run("on-premises partition is refunded", func(t *testing.T) {
+		assert.Nil(t, service.expiredHoldCharge(hold("cpu", 10)))
+	})
+
+	t.Run("hold without an estimate is refunded", func(t *testing.T) {
+		assert.Nil(t, service.expiredHoldCharge(hold("aws", 0)))
+		assert.Nil(t, service.expiredHoldCharge(&api.BudgetTransaction{TransactionID: "txn_bare", Type: "hold"}))
+	})
+}
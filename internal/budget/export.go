@@ -0,0 +1,91 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// ExportAccount takes a complete copy of an account, for an administrator to keep before a
+// risky migration or merge: its settings and balances, partition limits, allocation
+// schedules and every transaction. Importing the export restores the account.
+func (s *Service) ExportAccount(ctx context.Context, slurmAccount string) (*api.AccountExport, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &api.AccountExport{
+		Version:    api.AccountExportVersion,
+		ExportedAt: time.Now().UTC(),
+		Account:    account,
+	}
+	if err := s.exportQueries.ExportAccount(ctx, export); err != nil {
+		return nil, err
+	}
+
+	if export.Account.ParentAccountID != nil {
+		parent, err := s.accountQueries.GetAccountByID(ctx, *export.Account.ParentAccountID)
+		if err != nil {
+			return nil, err
+		}
+		export.ParentAccount = parent.SlurmAccount
+	}
+
+	log.Info().
+		Str("account", slurmAccount).
+		Int("transactions", len(export.Transactions)).
+		Int("schedules", len(export.AllocationSchedules)).
+		Msg("Account exported")
+
+	return export, nil
+}
+
+// ImportAccount restores an exported account under its SLURM account, which must not
+// exist. Its parent, when it has one, must. The account comes back with the exported
+// settings, balances, partition limits, schedules and transactions, and its balances are
+// added to its parent's and every ancestor's. Budget decisions, alerts, snapshots and
+// members are not part of an export.
+func (s *Service) ImportAccount(ctx context.Context, export *api.AccountExport) (*api.AccountImportResponse, error) {
+	if err := export.Validate(); err != nil {
+		return nil, err
+	}
+
+	if export.ParentAccount != "" {
+		if _, err := s.accountQueries.GetAccountByName(ctx, export.ParentAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	// The account is created as a clone of itself would be, so its settings are checked as
+	// a new account's are
+	createReq := cloneAccountRequest(export.Account, export.ParentAccount,
+		&api.CloneAccountRequest{SlurmAccount: export.Account.SlurmAccount})
+	if err := createReq.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateHoldPercentage(createReq.HoldPercentage); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.exportQueries.ImportAccount(ctx, export, createReq)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("account", resp.Account.SlurmAccount).
+		Int("transactions", resp.Transactions).
+		Int("schedules", resp.AllocationSchedules).
+		Int("partition_limits", resp.PartitionLimits).
+		Msg("Account imported")
+
+	return resp, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"strings"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// failedJobState is the SLURM state of a job that ended with a non-zero exit code
+const failedJobState = "FAILED"
+
+// failedJobPolicy returns how a job in the given SLURM state is reconciled: charged its
+// actual cost or refunded in full, as budget.charge_failed_jobs says, if it failed, and
+// empty otherwise
+func (s *Service) failedJobPolicy(state string) string {
+	if !isFailedJobState(state) {
+		return ""
+	}
+	if s.config.ChargeFailedJobs {
+		return api.FailedJobPolicyChargeActual
+	}
+	return api.FailedJobPolicyFullRefund
+}
+
+// isFailedJobState reports whether a SLURM job state is FAILED, ignoring any reason
+func isFailedJobState(state string) bool {
+	fields := strings.Fields(strings.ToUpper(state))
+	return len(fields) > 0 && fields[0] == failedJobState
+}
+
+// jobOutcome describes a reconciled job for the metadata of its charges and refunds: the
+// cost it reported, what it said about itself and, if it failed, the failed job policy
+// applied. The hold's amount is filled in when each hold is settled.
+func jobOutcome(req *api.JobReconcileRequest, policy string) api.JobOutcome {
+	// The request was validated, so its job metadata parses
+	job, _ := api.ParseJobMetadata(req.JobMetadata)
+	return api.JobOutcome{
+		ReportedCost:      req.ActualCost,
+		JobState:          req.JobState,
+		FailedJobPolicy:   policy,
+		Job:               job,
+		ReconciliationID:  req.ReconciliationID,
+		ChargedAtEstimate: req.ChargedAtEstimate,
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestFailedJobPolicy(t *testing.T) {
+	charging := &Service{config: &config.BudgetConfig{ChargeFailedJobs: true}}
+	refunding := &Service{config: &config.BudgetConfig{ChargeFailedJobs: false}}
+
+	assert.Equal(t, api.FailedJobPolicyChargeActual, charging.failedJobPolicy("FAILED"))
+	assert.Equal(t, api.FailedJobPolicyFullRefund, refunding.failedJobPolicy("FAILED"))
+	assert.Equal(t, api.FailedJobPolicyFullRefund, refunding.failedJobPolicy("failed"))
+
+	for _, state := range []string{"", "COMPLETED", "TIMEOUT", "CANCELLED by 1000", "NODE_FAIL"} {
+		assert.Empty(t, refunding.failedJobPolicy(state), state)
+		assert.Empty(t, charging.failedJobPolicy(state), state)
+	}
+}
+
+func TestJobOutcome(t *testing.T) {
+	req := &api.JobReconcileRequest{
+		JobID:       "12345",
+		ActualCost:  0.42,
+		JobState:    "FAILED",
+		JobMetadata: `{"asbx_job_id":"asbx-1","cpu_efficiency":0.8}`,
+	}
+
+	outcome := jobOutcome(req, api.FailedJobPolicyFullRefund)
+	assert.Equal(t, 0.42, outcome.ReportedCost)
+	assert.Equal(t, "FAILED", outcome.JobState)
+	assert.Equal(t, api.FailedJobPolicyFullRefund, outcome.FailedJobPolicy)
+	require.NotNil(t, outcome.Job)
+	assert.Equal(t, "asbx-1", outcome.Job.ASBXJobID)
+
+	encoded, err := api.EncodeTransactionMetadata(&api.ChargeMetadata{JobOutcome: outcome})
+	require.NoError(t, err)
+	var metadata map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(encoded), &metadata))
+	assert.Equal(t, float64(api.TransactionMetadataVersion), metadata["version"])
+	assert.Equal(t, "FAILED", metadata["job_state"])
+	assert.Equal(t, api.FailedJobPolicyFullRefund, metadata["failed_job_policy"])
+	assert.Equal(t, 0.42, metadata["reported_cost"])
+
+	completed := jobOutcome(&api.JobReconcileRequest{JobID: "12346", ActualCost: 1}, "")
+	assert.Empty(t, completed.FailedJobPolicy)
+	assert.Nil(t, completed.Job)
+}
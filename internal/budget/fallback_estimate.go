@@ -0,0 +1,154 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// defaultFallbackCostRate prices CPU-hours in fallback estimates when no rate is set
+const defaultFallbackCostRate = 0.10
+
+// DefaultFallbackPartitionMultipliers are the fallback partition adjustments used when the
+// configuration sets none
+var DefaultFallbackPartitionMultipliers = map[string]float64{
+	"gpu":      2.0, // GPU partitions more expensive
+	"gpu-aws":  2.0,
+	"high-mem": 1.5, // High memory premium
+	"himem":    1.5,
+	"debug":    0.5, // Test partitions cheaper
+	"test":     0.5,
+}
+
+// FallbackRates are the configured rates jobs are priced at without the advisor, as
+// integration.fallback_cost_rate and integration.fallback_partition_multipliers
+type FallbackRates struct {
+	CostRate             float64            // Per CPU-hour; zero uses $0.10
+	PartitionMultipliers map[string]float64 // Keyed by lower-case partition; nil uses the defaults
+}
+
+// partitionMultiplier returns the fallback estimate multiplier for a partition; partitions
+// without one are priced at the base rate
+func (r FallbackRates) partitionMultiplier(partition string) float64 {
+	multipliers := r.PartitionMultipliers
+	if multipliers == nil {
+		multipliers = DefaultFallbackPartitionMultipliers
+	}
+	if multiplier, ok := multipliers[strings.ToLower(partition)]; ok {
+		return multiplier
+	}
+	return 1.0
+}
+
+// SimpleCostEstimate prices a job by the fallback heuristic: its CPU-hours at the cost
+// rate, GPU-hours at ten times it and memory at $0.01 per GB-hour, scaled by the
+// partition's multiplier. Memory or a wall time that cannot be read lowers the estimate's
+// confidence rather than failing it.
+func SimpleCostEstimate(req *CostEstimateRequest, rates FallbackRates) *CostEstimateResponse {
+	rate := rates.CostRate
+	if rate <= 0 {
+		rate = defaultFallbackCostRate
+	}
+
+	duration, wallTimeErr := api.ParseWallTime(req.WallTime)
+
+	// Base cost per CPU-hour
+	cpuCost := float64(req.Nodes*req.CPUs) * rate * duration
+
+	// GPU multiplier if GPUs requested
+	gpuCost := 0.0
+	if req.GPUs > 0 {
+		gpuCost = float64(req.GPUs) * rate * 10.0 * duration // 10x multiplier for GPUs
+	}
+
+	// Memory cost estimation (if specified). Memory that cannot be read is left out of the
+	// estimate, which is then reported with low confidence rather than guessed at.
+	memoryCost := 0.0
+	var memoryErr error
+	if req.Memory != "" {
+		var memoryGB float64
+		if memoryGB, memoryErr = parseMemoryGB(req.Memory); memoryErr == nil {
+			memoryCost = memoryGB * 0.01 * duration // $0.01/GB-hour
+		}
+	}
+
+	finalCost := (cpuCost + gpuCost + memoryCost) * rates.partitionMultiplier(req.Partition)
+
+	// Ensure minimum cost
+	if finalCost < 0.01 {
+		finalCost = 0.01
+	}
+
+	confidence := 0.7 // Moderate confidence for heuristic estimates
+	recommendation := "Simple heuristic estimate - advisor service unavailable"
+
+	if finalCost > 100.0 {
+		recommendation += ". Consider optimization for high-cost job."
+	}
+
+	if memoryErr != nil {
+		confidence = 0.3
+		recommendation += fmt.Sprintf(". Memory not costed: %v", memoryErr)
+	}
+	if wallTimeErr != nil {
+		confidence = 0.3
+		recommendation += fmt.Sprintf(". %v", wallTimeErr)
+	}
+
+	return &CostEstimateResponse{
+		EstimatedCost:  finalCost,
+		Confidence:     confidence,
+		Recommendation: recommendation,
+	}
+}
+
+// memoryUnitsGB maps memory suffixes, lower-cased, to GB. Decimal and binary suffixes are
+// treated alike as powers of 1024, as SLURM does; a bare number is MB.
+var memoryUnitsGB = map[string]float64{
+	"":    1.0 / 1024,
+	"k":   1.0 / (1024 * 1024),
+	"kb":  1.0 / (1024 * 1024),
+	"kib": 1.0 / (1024 * 1024),
+	"m":   1.0 / 1024,
+	"mb":  1.0 / 1024,
+	"mib": 1.0 / 1024,
+	"g":   1,
+	"gb":  1,
+	"gib": 1,
+	"t":   1024,
+	"tb":  1024,
+	"tib": 1024,
+	"p":   1024 * 1024,
+	"pb":  1024 * 1024,
+	"pib": 1024 * 1024,
+}
+
+// memoryPattern splits a memory request into its amount and unit suffix
+var memoryPattern = regexp.MustCompile(`^([0-9]*\.?[0-9]+)\s*([a-zA-Z]*)$`)
+
+// parseMemoryGB converts a memory request such as 64G, 512GiB or 2TB to GB
+func parseMemoryGB(memory string) (float64, error) {
+	match := memoryPattern.FindStringSubmatch(strings.TrimSpace(memory))
+	if match == nil {
+		return 0, fmt.Errorf("invalid memory %q", memory)
+	}
+
+	perGB, ok := memoryUnitsGB[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid memory %q: unknown unit %q", memory, match[2])
+	}
+
+	value, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory %q: %w", memory, err)
+	}
+
+	return value * perGB, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestSimpleCostEstimate(t *testing.T) {
+	job := func(partition string) *CostEstimateRequest {
+		return &CostEstimateRequest{Partition: partition, Nodes: 2, CPUs: 4, GPUs: 1, WallTime: "1-00:00:00"}
+	}
+
+	// 8 CPU-hours and 1 GPU-hour at ten times the rate, each for 24 hours
+	resp := SimpleCostEstimate(job("cpu"), FallbackRates{CostRate: 0.10})
+	assert.InDelta(t, (8*0.10+10*0.10)*24, resp.EstimatedCost, 0.0001)
+	assert.InDelta(t, 0.7, resp.Confidence, 0.0001)
+
+	assert.InDelta(t, 2*resp.EstimatedCost, SimpleCostEstimate(job("gpu"), FallbackRates{CostRate: 0.10}).EstimatedCost, 0.0001,
+		"the default multipliers apply when none are configured")
+	configured := FallbackRates{CostRate: 0.20, PartitionMultipliers: map[string]float64{"a100": 3}}
+	assert.InDelta(t, 6*resp.EstimatedCost, SimpleCostEstimate(job("A100"), configured).EstimatedCost, 0.0001)
+	assert.InDelta(t, 2*resp.EstimatedCost, SimpleCostEstimate(job("gpu"), configured).EstimatedCost, 0.0001,
+		"configured multipliers replace the defaults")
+	assert.InDelta(t, resp.EstimatedCost, SimpleCostEstimate(job("cpu"), FallbackRates{}).EstimatedCost, 0.0001,
+		"no rate uses $0.10")
+}
+
+func TestService_FallbackCostEstimate_ConfiguredRates(t *testing.T) {
+	req := &api.BudgetCheckRequest{Account: "lab", Partition: "a100", Nodes: 1, CPUs: 4, WallTime: "02:00:00"}
+	service := &Service{}
+	assert.InDelta(t, 0.8, service.fallbackCostEstimate(req).EstimatedCost, 0.0001)
+
+	// Accounts pinned to the fallback cost model are priced at the configured rates
+	service.SetFallbackRates(FallbackRates{CostRate: 0.25, PartitionMultipliers: map[string]float64{"a100": 4}})
+	estimate, err := service.estimateCost(context.Background(), req, api.EstimationSourceFallback)
+	require.NoError(t, err)
+	assert.InDelta(t, 8.0, estimate.EstimatedCost, 0.0001)
+}
+
+func TestParseMemoryGB(t *testing.T) {
+	tests := []struct {
+		memory string
+		wantGB float64
+	}{
+		{"2048", 2},
+		{"512K", 512.0 / (1024 * 1024)},
+		{"512KB", 512.0 / (1024 * 1024)},
+		{"512kib", 512.0 / (1024 * 1024)},
+		{"1536M", 1.5},
+		{"1536MB", 1.5},
+		{"1536MiB", 1.5},
+		{"64G", 64},
+		{"16GB", 16},
+		{"512GiB", 512},
+		{"0.5gb", 0.5},
+		{"2T", 2048},
+		{"2TB", 2048},
+		{"1TiB", 1024},
+		{"1P", 1024 * 1024},
+		{"1PB", 1024 * 1024},
+		{"1PiB", 1024 * 1024},
+		{" 32 GB ", 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.memory, func(t *testing.T) {
+			got, err := parseMemoryGB(tt.memory)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantGB, got, 1e-9)
+		})
+	}
+
+	for _, memory := range []string{"", "GB", "lots", "12XB", "-4GB", "1.2.3G", "4 G B", "1e3GB"} {
+		t.Run("invalid "+memory, func(t *testing.T) {
+			_, err := parseMemoryGB(memory)
+			assert.Error(t, err)
+		})
+	}
+}
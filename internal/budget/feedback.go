@@ -0,0 +1,126 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const (
+	// defaultFeedbackListLimit caps a feedback listing when no limit is given
+	defaultFeedbackListLimit = 100
+
+	// feedbackWindow is how far back ASBA feedback weighs on burst decisions
+	feedbackWindow = 90 * 24 * time.Hour
+
+	// feedbackShrinkage is how many AWS outcomes it takes for the feedback to carry half
+	// its full weight, so a few unusual jobs do not swing every decision
+	feedbackShrinkage = 10
+)
+
+// RecordASBAFeedback stores what ASBA observed about a job after its burst recommendation,
+// linked to the job's budget decision
+func (s *Service) RecordASBAFeedback(ctx context.Context, req *api.ASBAFeedbackRequest) (*api.ASBAFeedback, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.feedbackQueries.RecordFeedback(ctx, account.ID, req)
+}
+
+// ListASBAFeedback returns an account's ASBA feedback, newest first
+func (s *Service) ListASBAFeedback(ctx context.Context, req *api.ASBAFeedbackListRequest) ([]*api.ASBAFeedback, error) {
+	if req.Account == "" {
+		return nil, api.NewValidationError("account", "is required")
+	}
+	if req.Limit < 0 || req.Limit > 1000 {
+		return nil, api.NewValidationError("limit", "must be between 1 and 1000")
+	}
+	if req.Offset < 0 {
+		return nil, api.NewValidationError("offset", "must not be negative")
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := *req
+	if filter.Limit == 0 {
+		filter.Limit = defaultFeedbackListLimit
+	}
+	return s.feedbackQueries.ListFeedback(ctx, account.ID, &filter)
+}
+
+// WeightBurstDecisionFactors reweights a burst decision's factors by the account's recent
+// ASBA feedback, returning them with the feedback they were weighted by
+func (s *Service) WeightBurstDecisionFactors(ctx context.Context, slurmAccount string, factors []api.DecisionFactor) ([]api.DecisionFactor, *api.ASBAFeedbackSummary, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	summary, err := s.feedbackQueries.SummarizeFeedback(ctx, account.ID, time.Now().Add(-feedbackWindow))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return weightDecisionFactors(factors, summary), summary, nil
+}
+
+// weightDecisionFactors scales the cost efficiency factor's weight by how AWS jobs' actual
+// costs compared with their estimates: jobs that overran make cost weigh more, and jobs that
+// came in under make it weigh less. The ratio counts in proportion to how many AWS outcomes
+// back it and is clamped to between half and double. The weights are then rescaled to their
+// original total, so the other factors give way.
+func weightDecisionFactors(factors []api.DecisionFactor, summary *api.ASBAFeedbackSummary) []api.DecisionFactor {
+	weighted := make([]api.DecisionFactor, len(factors))
+	copy(weighted, factors)
+
+	ratio := summary.AWSCostRatio()
+	if ratio == 0 {
+		return weighted
+	}
+	ratio = clampFloat(ratio, 0.5, 2)
+	trust := float64(summary.AWSOutcomes) / float64(summary.AWSOutcomes+feedbackShrinkage)
+	scale := 1 + (ratio-1)*trust
+
+	var total, scaledTotal float64
+	for i := range weighted {
+		total += weighted[i].Weight
+		if weighted[i].Factor == api.DecisionFactorCostEfficiency {
+			weighted[i].Weight *= scale
+			weighted[i].Description += fmt.Sprintf("; weighted by %d AWS outcomes costing %.0f%% of estimate",
+				summary.AWSOutcomes, summary.AWSCostRatio()*100)
+		}
+		scaledTotal += weighted[i].Weight
+	}
+	if scaledTotal > 0 {
+		for i := range weighted {
+			weighted[i].Weight *= total / scaledTotal
+		}
+	}
+	return weighted
+}
+
+// clampFloat limits v to between lo and hi
+func clampFloat(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
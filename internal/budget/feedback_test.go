@@ -0,0 +1,90 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestWeightDecisionFactors(t *testing.T) {
+	factors := []api.DecisionFactor{
+		{Factor: api.DecisionFactorBudgetHealth, Weight: 0.3},
+		{Factor: api.DecisionFactorDeadlinePressure, Weight: 0.4},
+		{Factor: api.DecisionFactorCostEfficiency, Weight: 0.3},
+	}
+	weightOf := func(weighted []api.DecisionFactor, factor string) float64 {
+		for _, f := range weighted {
+			if f.Factor == factor {
+				return f.Weight
+			}
+		}
+		t.Fatalf("factor %s missing", factor)
+		return 0
+	}
+	total := func(weighted []api.DecisionFactor) float64 {
+		var sum float64
+		for _, f := range weighted {
+			sum += f.Weight
+		}
+		return sum
+	}
+
+	t.Run("no feedback leaves weights alone", func(t *testing.T) {
+		assert.Equal(t, factors, weightDecisionFactors(factors, &api.ASBAFeedbackSummary{}))
+	})
+
+	t.Run("overruns make cost weigh more", func(t *testing.T) {
+		// 10 AWS outcomes at 150% of estimate carry half the ratio: cost weight 0.3 * 1.25
+		weighted := weightDecisionFactors(factors, &api.ASBAFeedbackSummary{
+			Outcomes: 10, AWSOutcomes: 10, AWSActualCost: 150, AWSEstimatedCost: 100,
+		})
+		assert.InDelta(t, 1.0, total(weighted), 1e-9)
+		assert.InDelta(t, 0.375/1.075, weightOf(weighted, api.DecisionFactorCostEfficiency), 1e-9)
+		assert.Less(t, weightOf(weighted, api.DecisionFactorDeadlinePressure), 0.4)
+		assert.Contains(t, weighted[2].Description, "10 AWS outcomes costing 150% of estimate")
+		assert.Equal(t, 0.3, factors[2].Weight, "the factors given are not modified")
+	})
+
+	t.Run("underruns make cost weigh less", func(t *testing.T) {
+		weighted := weightDecisionFactors(factors, &api.ASBAFeedbackSummary{
+			Outcomes: 30, AWSOutcomes: 30, AWSActualCost: 60, AWSEstimatedCost: 100,
+		})
+		assert.InDelta(t, 1.0, total(weighted), 1e-9)
+		assert.Less(t, weightOf(weighted, api.DecisionFactorCostEfficiency), 0.3)
+	})
+
+	t.Run("more outcomes weigh more", func(t *testing.T) {
+		few := weightDecisionFactors(factors, &api.ASBAFeedbackSummary{AWSOutcomes: 2, AWSActualCost: 20, AWSEstimatedCost: 10})
+		many := weightDecisionFactors(factors, &api.ASBAFeedbackSummary{AWSOutcomes: 50, AWSActualCost: 500, AWSEstimatedCost: 250})
+		assert.Greater(t, weightOf(many, api.DecisionFactorCostEfficiency), weightOf(few, api.DecisionFactorCostEfficiency))
+	})
+
+	t.Run("extreme ratios are clamped", func(t *testing.T) {
+		double := weightDecisionFactors(factors, &api.ASBAFeedbackSummary{AWSOutcomes: 10, AWSActualCost: 200, AWSEstimatedCost: 100})
+		tenfold := weightDecisionFactors(factors, &api.ASBAFeedbackSummary{AWSOutcomes: 10, AWSActualCost: 1000, AWSEstimatedCost: 100})
+		require.Len(t, tenfold, 3)
+		assert.InDelta(t, weightOf(double, api.DecisionFactorCostEfficiency), weightOf(tenfold, api.DecisionFactorCostEfficiency), 1e-9)
+	})
+}
+
+func TestListASBAFeedback_Validation(t *testing.T) {
+	s := &Service{}
+	for _, req := range []*api.ASBAFeedbackListRequest{
+		{},
+		{Account: "proj001", Limit: 1001},
+		{Account: "proj001", Offset: -1},
+	} {
+		_, err := s.ListASBAFeedback(context.Background(), req)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	}
+}
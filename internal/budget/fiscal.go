@@ -0,0 +1,217 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// fiscalQuarterCategory is the usage report grouping by fiscal quarter
+const fiscalQuarterCategory = "fiscal_quarter"
+
+// fiscalYearStartFor returns the fiscal year an account reports and allocates by: its own
+// fiscal year start when set, otherwise the configured one
+func (s *Service) fiscalYearStartFor(account *api.BudgetAccount) api.FiscalYearStart {
+	if account != nil && account.FiscalYearStart != nil {
+		if start, err := api.ParseFiscalYearStart(*account.FiscalYearStart); err == nil {
+			return start
+		}
+	}
+	return s.defaultFiscalYearStart()
+}
+
+// defaultFiscalYearStart returns the configured fiscal year start, which configuration
+// validation has already checked
+func (s *Service) defaultFiscalYearStart() api.FiscalYearStart {
+	start, err := api.ParseFiscalYearStart(s.config.FiscalYearStart)
+	if err != nil {
+		return api.FiscalYearStart{}
+	}
+	return start
+}
+
+// ProcessAllocations makes every incremental allocation that has come due. Quarterly and
+// yearly schedules then step to the next fiscal quarter or year boundary of their account.
+// With budget.prorate_first_allocation, a schedule's first allocation is prorated. Due
+// schedules are allocated in batches of the configured size, each in its own
+// transaction, several batches at once.
+func (s *Service) ProcessAllocations(ctx context.Context, req *api.ProcessAllocationsRequest) (*api.ProcessAllocationsResponse, error) {
+	if req.AccountID != nil || req.ScheduleID != nil {
+		return nil, api.NewValidationError("account_id", "processing is not limited to one account or schedule; all due allocations are made")
+	}
+	if req.DryRun {
+		return nil, api.NewValidationError("dry_run", "is not supported")
+	}
+
+	scheduleIDs, accountIDs, err := s.allocationQueries.ListDueScheduleIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := allocateInBatches(ctx, scheduleIDs, accountIDs, s.workerBatchSize(), s.workerConcurrency(),
+		func(ctx context.Context, batch []int64) ([]api.ProcessedAllocation, error) {
+			return s.allocationQueries.ProcessPendingAllocations(ctx, s.config.FiscalYearStart, s.config.ProrateFirstAllocation, batch)
+		})
+	if resp.ProcessedCount > 0 {
+		accountIDs := make([]int64, len(resp.Allocations))
+		for i, allocation := range resp.Allocations {
+			accountIDs[i] = allocation.AccountID
+		}
+		s.reactivateReplenished(ctx, accountIDs)
+
+		log.Info().
+			Int64("allocations", resp.ProcessedCount).
+			Float64("total", resp.TotalAllocated).
+			Int("batches", resp.Batches).
+			Msg("Processed pending allocations")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// allocateInBatches allocates the due schedules batchSize at a time on up to workers
+// batches at once. An account's schedules, given in accountIDs, share a batch, so the
+// account's next allocation date is computed seeing all of them. Batches that succeed
+// stay committed when another fails; the first failure is returned after every batch has
+// run.
+func allocateInBatches(ctx context.Context, scheduleIDs, accountIDs []int64, batchSize, workers int,
+	allocate func(ctx context.Context, batch []int64) ([]api.ProcessedAllocation, error)) (*api.ProcessAllocationsResponse, error) {
+	batches := splitGroupedBatches(accountIDs, batchSize)
+	results := make([][]api.ProcessedAllocation, len(batches))
+
+	errs := runConcurrently(ctx, len(batches), workers, func(ctx context.Context, i int) error {
+		var err error
+		results[i], err = allocate(ctx, scheduleIDs[batches[i][0]:batches[i][1]])
+		return err
+	})
+
+	resp := &api.ProcessAllocationsResponse{Batches: len(batches)}
+	var firstErr error
+	for i, allocations := range results {
+		if errs[i] != nil {
+			log.Error().Err(errs[i]).Int("batch", i).Int("schedules", batches[i][1]-batches[i][0]).
+				Msg("Failed to process allocation batch")
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		for _, allocation := range allocations {
+			resp.Allocations = append(resp.Allocations, allocation)
+			resp.ProcessedCount++
+			resp.TotalAllocated += allocation.AllocatedAmount
+		}
+	}
+	return resp, firstErr
+}
+
+// UsageByFiscalQuarter reports charged spend grouped by fiscal quarter, in the account's
+// fiscal year and time zone, or the configured fiscal year in UTC across all accounts.
+// Dates are whole days with the end date inclusive.
+func (s *Service) UsageByFiscalQuarter(ctx context.Context, req *api.UsageReportRequest) (*api.UsageReportResponse, error) {
+	if req.GroupBy != "" && req.GroupBy != fiscalQuarterCategory {
+		return nil, api.NewValidationError("group_by", "must be fiscal_quarter")
+	}
+	if req.Partition != "" {
+		return nil, api.NewValidationError("partition", "is not supported when grouping by fiscal quarter")
+	}
+
+	var account *api.BudgetAccount
+	loc := time.UTC
+	if req.Account != "" {
+		var err error
+		if account, err = s.accountQueries.GetAccountByName(ctx, req.Account); err != nil {
+			return nil, err
+		}
+		loc = account.Location()
+	}
+	fiscal := s.fiscalYearStartFor(account)
+
+	filter := *req
+	if filter.StartDate != nil {
+		start := localMidnight(*filter.StartDate, loc)
+		filter.StartDate = &start
+	}
+	if filter.EndDate != nil {
+		end := localMidnight(*filter.EndDate, loc).AddDate(0, 0, 1)
+		filter.EndDate = &end
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && !filter.EndDate.After(*filter.StartDate) {
+		return nil, api.NewValidationError("end_date", "must not be before start_date")
+	}
+
+	totals, err := s.usageQueries.ChargeTotals(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+	days, err := s.usageQueries.UsageByDay(ctx, &filter, loc.String())
+	if err != nil {
+		return nil, err
+	}
+	latency, err := s.usageQueries.ReconciliationLatency(ctx, &filter, s.config.ReconciliationSLA)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.UsageReportResponse{
+		Account:               req.Account,
+		Period:                describePeriod(req),
+		ReconciliationLatency: latency,
+		Summary: api.UsageSummary{
+			TotalSpent: totals.Amount,
+			TotalJobs:  totals.JobCount,
+		},
+		Breakdown: fiscalQuarterBreakdown(days, fiscal, totals.Amount),
+	}
+	if totals.JobCount > 0 {
+		resp.Summary.AvgCostPerJob = totals.Amount / float64(totals.JobCount)
+	}
+	if account != nil && account.BudgetLimit > 0 {
+		resp.Summary.BudgetUtilized = totals.Amount / account.BudgetLimit * 100
+	}
+
+	return resp, nil
+}
+
+// fiscalQuarterBreakdown rolls daily totals up into fiscal quarters, earliest first. Days
+// are local dates carried as midnight UTC.
+func fiscalQuarterBreakdown(days []*database.DailyUsage, fiscal api.FiscalYearStart, total float64) []api.UsageBreakdownItem {
+	items := []api.UsageBreakdownItem{}
+	for _, day := range days {
+		label := fiscal.QuarterLabel(day.Date, time.UTC)
+		if n := len(items); n > 0 && items[n-1].Label == label {
+			items[n-1].Amount += day.Amount
+			items[n-1].JobCount += day.JobCount
+			continue
+		}
+		items = append(items, api.UsageBreakdownItem{
+			Category: fiscalQuarterCategory,
+			Label:    label,
+			Amount:   day.Amount,
+			JobCount: day.JobCount,
+		})
+	}
+
+	for i := range items {
+		if total > 0 {
+			items[i].Percentage = items[i].Amount / total * 100
+		}
+	}
+	return items
+}
+
+// localMidnight returns midnight in loc of the calendar date t carries. Report dates are
+// parsed as UTC midnights, so the date is taken as written.
+func localMidnight(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,85 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestFiscalYearStartFor(t *testing.T) {
+	july := api.FiscalYearStart{Month: time.July, Day: 1}
+	october := "10-01"
+
+	service := &Service{config: &config.BudgetConfig{FiscalYearStart: "07-01"}}
+	assert.Equal(t, july, service.fiscalYearStartFor(nil))
+	assert.Equal(t, july, service.fiscalYearStartFor(&api.BudgetAccount{}))
+	assert.Equal(t, api.FiscalYearStart{Month: time.October, Day: 1},
+		service.fiscalYearStartFor(&api.BudgetAccount{FiscalYearStart: &october}))
+
+	calendar := &Service{config: &config.BudgetConfig{}}
+	assert.True(t, calendar.fiscalYearStartFor(&api.BudgetAccount{}).IsCalendar())
+}
+
+func TestFiscalQuarterBreakdown(t *testing.T) {
+	july := api.FiscalYearStart{Month: time.July, Day: 1}
+	day := func(year int, month time.Month, d int, amount float64, jobs int64) *database.DailyUsage {
+		return &database.DailyUsage{Date: time.Date(year, month, d, 0, 0, 0, 0, time.UTC), Amount: amount, JobCount: jobs}
+	}
+	days := []*database.DailyUsage{
+		day(2025, 6, 30, 10, 1), // last day of FY2025
+		day(2025, 7, 1, 20, 2),
+		day(2025, 9, 30, 30, 1),
+		day(2025, 10, 1, 40, 3),
+	}
+
+	items := fiscalQuarterBreakdown(days, july, 100)
+	require.Len(t, items, 3)
+	assert.Equal(t, "FY2025 Q4", items[0].Label)
+	assert.Equal(t, "FY2026 Q1", items[1].Label)
+	assert.InDelta(t, 50.0, items[1].Amount, 0.001)
+	assert.Equal(t, int64(3), items[1].JobCount)
+	assert.InDelta(t, 50.0, items[1].Percentage, 0.001)
+	assert.Equal(t, "FY2026 Q2", items[2].Label)
+	assert.Equal(t, "fiscal_quarter", items[2].Category)
+
+	// The same days by calendar quarter
+	calendar := fiscalQuarterBreakdown(days, api.FiscalYearStart{}, 100)
+	require.Len(t, calendar, 3)
+	assert.Equal(t, "FY2025 Q2", calendar[0].Label)
+	assert.Equal(t, "FY2025 Q3", calendar[1].Label)
+	assert.Equal(t, "FY2025 Q4", calendar[2].Label)
+
+	assert.Empty(t, fiscalQuarterBreakdown(nil, july, 0))
+}
+
+func TestUsageByFiscalQuarter_Validation(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{}}
+
+	_, err := service.UsageByFiscalQuarter(context.Background(), &api.UsageReportRequest{GroupBy: "month"})
+	assert.Error(t, err)
+
+	_, err = service.UsageByFiscalQuarter(context.Background(), &api.UsageReportRequest{Partition: "gpu"})
+	assert.Error(t, err)
+}
+
+func TestProcessAllocations_Validation(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{}}
+	accountID := int64(1)
+
+	_, err := service.ProcessAllocations(context.Background(), &api.ProcessAllocationsRequest{AccountID: &accountID})
+	assert.Error(t, err)
+
+	_, err = service.ProcessAllocations(context.Background(), &api.ProcessAllocationsRequest{DryRun: true})
+	assert.Error(t, err)
+}
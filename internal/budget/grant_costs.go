@@ -0,0 +1,92 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// indirectCostExclusions returns the lower-cased cost categories a funding agency does not
+// charge indirect costs on, from budget.indirect_cost_exclusions
+func (s *Service) indirectCostExclusions(agency string) []string {
+	// Viper lower-cases map keys, so agencies are matched case-insensitively
+	var categories []string
+	for key, excluded := range s.config.IndirectCostExclusions {
+		if !strings.EqualFold(key, strings.TrimSpace(agency)) {
+			continue
+		}
+		for _, category := range excluded {
+			categories = append(categories, strings.ToLower(strings.TrimSpace(category)))
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// RecomputeGrantCosts recomputes a grant's direct and indirect costs from every charge
+// against the accounts it funds, leaving the agency's excluded cost categories out of the
+// indirect cost base. Costs are kept current as jobs are charged, so this backfills
+// charges made before they were tracked and repairs any drift.
+func (s *Service) RecomputeGrantCosts(ctx context.Context, grantNumber string) (*api.GrantCostRecomputation, error) {
+	if grantNumber == "" {
+		return nil, api.NewValidationError("grant_number", "is required")
+	}
+
+	grant, err := s.grantQueries.GetGrantByNumber(ctx, grantNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := s.indirectCostExclusions(grant.FundingAgency)
+	previous, updated, err := s.grantQueries.RecomputeGrantCosts(ctx, grant.ID, excluded)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("grant_number", grantNumber).
+		Float64("previous_direct_costs", previous.DirectCosts).
+		Float64("direct_costs", updated.DirectCosts).
+		Float64("indirect_costs", updated.IndirectCosts).
+		Msg("Recomputed grant costs")
+
+	return &api.GrantCostRecomputation{
+		Grant:                 updated,
+		ExcludedCategories:    excluded,
+		PreviousDirectCosts:   previous.DirectCosts,
+		PreviousIndirectCosts: previous.IndirectCosts,
+	}, nil
+}
+
+// refreshGrantCosts recomputes the costs of the grants funding the accounts a
+// reconciliation charged. A failure is logged rather than failing the reconciliation,
+// which has already been committed; recomputing the grant's costs repairs it.
+func (s *Service) refreshGrantCosts(ctx context.Context, accountIDs ...int64) {
+	refreshed := make(map[int64]bool)
+	for _, accountID := range accountIDs {
+		grants, err := s.grantQueries.ListAccountGrants(ctx, accountID)
+		if err != nil {
+			log.Warn().Err(err).Int64("account_id", accountID).Msg("Failed to find grants to update costs for")
+			continue
+		}
+
+		for _, grant := range grants {
+			if refreshed[grant.ID] {
+				continue
+			}
+			refreshed[grant.ID] = true
+
+			if _, _, err := s.grantQueries.RecomputeGrantCosts(ctx, grant.ID, s.indirectCostExclusions(grant.FundingAgency)); err != nil {
+				log.Warn().Err(err).Str("grant_number", grant.GrantNumber).Msg("Failed to update grant costs")
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+)
+
+func TestIndirectCostExclusions(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{
+		IndirectCostExclusions: map[string][]string{
+			"nih": {"Storage", " equipment "},
+			"nsf": {"data_transfer"},
+		},
+	}}
+
+	assert.Equal(t, []string{"equipment", "storage"}, service.indirectCostExclusions("NIH"))
+	assert.Equal(t, []string{"data_transfer"}, service.indirectCostExclusions(" nsf"))
+	assert.Nil(t, service.indirectCostExclusions("DOE"))
+	assert.Nil(t, (&Service{config: &config.BudgetConfig{}}).indirectCostExclusions("NIH"))
+}
+
+func TestRecomputeGrantCosts_RequiresGrantNumber(t *testing.T) {
+	_, err := (&Service{config: &config.BudgetConfig{}}).RecomputeGrantCosts(context.Background(), "")
+	assert.Error(t, err)
+}
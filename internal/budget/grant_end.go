@@ -0,0 +1,122 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// Grant end date policies, as configured by budget.grant_end_date_policy
+const (
+	grantEndWarn  = "WARN"
+	grantEndBlock = "BLOCK"
+)
+
+// grantEndDatePolicy returns the configured grant end date policy; an empty policy is WARN
+func (s *Service) grantEndDatePolicy() string {
+	if s.config.GrantEndDatePolicy == "" {
+		return grantEndWarn
+	}
+	return s.config.GrantEndDatePolicy
+}
+
+// grantsEndedBy returns the grants funding an account, directly or through one of its
+// ancestors, that end before at, earliest first
+func (s *Service) grantsEndedBy(ctx context.Context, accountID int64, at time.Time) ([]*api.GrantAccount, error) {
+	grants, err := s.grantQueries.ListAccountGrants(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return endedGrants(grants, at), nil
+}
+
+// endedGrants returns the grants that end before at, earliest first
+func endedGrants(grants []*api.GrantAccount, at time.Time) []*api.GrantAccount {
+	var ended []*api.GrantAccount
+	for _, grant := range grants {
+		if grant.GrantEndDate.Before(at) {
+			ended = append(ended, grant)
+		}
+	}
+	sort.SliceStable(ended, func(i, j int) bool {
+		return ended[i].GrantEndDate.Before(ended[j].GrantEndDate)
+	})
+	return ended
+}
+
+// jobEndTime returns when a job starting at start would end by its wall time
+func jobEndTime(start time.Time, wallTime string) time.Time {
+	return start.Add(time.Duration(wallTimeHours(wallTime) * float64(time.Hour)))
+}
+
+// checkGrantEndDate checks a job, by its wall time, against the end dates of the grants
+// funding its account. Under WARN a job that would run past one is approved with the
+// warning returned; under BLOCK its hold is refused.
+func (s *Service) checkGrantEndDate(ctx context.Context, account *api.BudgetAccount, req *api.BudgetCheckRequest) (string, error) {
+	jobEnd := jobEndTime(time.Now(), req.WallTime)
+	ended, err := s.grantsEndedBy(ctx, account.ID, jobEnd)
+	if err != nil {
+		return "", err
+	}
+	if len(ended) == 0 {
+		return "", nil
+	}
+
+	grant := ended[0]
+	if s.grantEndDatePolicy() == grantEndBlock {
+		return "", api.NewGrantEndedError(account.SlurmAccount, grant.GrantNumber, grant.GrantEndDate)
+	}
+	return fmt.Sprintf("Job on account %s would run past the end of grant %s on %s; charges after it ends may not be allowable",
+		account.SlurmAccount, grant.GrantNumber, grant.GrantEndDate.Format("2006-01-02")), nil
+}
+
+// flagAfterGrantEnd returns the numbers of the grants funding an account that had ended by
+// the time a job completed, the reconciliation time unless the request says otherwise, and
+// a warning naming them. The job is still charged, since its costs were incurred; the flag
+// lets grant managers find and move them. Errors are logged; the reconciliation goes ahead.
+func (s *Service) flagAfterGrantEnd(ctx context.Context, accountID int64, req *api.JobReconcileRequest) ([]string, string) {
+	completedAt := time.Now()
+	if req.CompletedAt != nil {
+		completedAt = *req.CompletedAt
+	}
+
+	ended, err := s.grantsEndedBy(ctx, accountID, completedAt)
+	if err != nil {
+		log.Error().Err(err).Str("job_id", req.JobID).Msg("Failed to check grant end dates for reconciliation")
+		return nil, ""
+	}
+	if len(ended) == 0 {
+		return nil, ""
+	}
+
+	numbers := make([]string, 0, len(ended))
+	for _, grant := range ended {
+		numbers = append(numbers, grant.GrantNumber)
+	}
+	log.Warn().Str("job_id", req.JobID).Strs("grants", numbers).Time("completed_at", completedAt).
+		Msg("Reconciled job completed after its grant ended")
+	return numbers, fmt.Sprintf("Job completed after grant %s ended; its charge is flagged for review",
+		strings.Join(numbers, ", "))
+}
+
+// appendWarning adds a warning to a response's existing warning
+func appendWarning(existing, warning string) string {
+	switch {
+	case warning == "":
+		return existing
+	case existing == "":
+		return warning
+	default:
+		return existing + "; " + warning
+	}
+}
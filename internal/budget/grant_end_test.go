@@ -0,0 +1,56 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestEndedGrants(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	grants := []*api.GrantAccount{
+		{GrantNumber: "NSF-LATER", GrantEndDate: now.AddDate(1, 0, 0)},
+		{GrantNumber: "NIH-SOON", GrantEndDate: now.Add(2 * time.Hour)},
+		{GrantNumber: "DOE-ENDED", GrantEndDate: now.AddDate(0, 0, -3)},
+	}
+
+	numbers := func(grants []*api.GrantAccount) []string {
+		var n []string
+		for _, g := range grants {
+			n = append(n, g.GrantNumber)
+		}
+		return n
+	}
+
+	assert.Equal(t, []string{"DOE-ENDED"}, numbers(endedGrants(grants, now)))
+	assert.Equal(t, []string{"DOE-ENDED", "NIH-SOON"}, numbers(endedGrants(grants, now.Add(4*time.Hour))),
+		"a job running past a grant's end is caught, earliest end first")
+	assert.Empty(t, endedGrants(grants, now.AddDate(0, 0, -4)))
+	assert.Empty(t, endedGrants(nil, now))
+}
+
+func TestJobEndTime(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, now.Add(4*time.Hour), jobEndTime(now, "04:00:00"))
+	assert.Equal(t, now.Add(48*time.Hour), jobEndTime(now, "2-00:00:00"))
+	assert.Equal(t, now.Add(36*time.Hour+30*time.Minute), jobEndTime(now, "1-12:30:00"))
+
+	// A grant ending tomorrow is caught for a two-day job, not waved through as an hour
+	grants := []*api.GrantAccount{{GrantNumber: "NIH-TOMORROW", GrantEndDate: now.AddDate(0, 0, 1)}}
+	assert.Len(t, endedGrants(grants, jobEndTime(now, "2-00:00:00")), 1)
+	assert.Empty(t, endedGrants(grants, jobEndTime(now, "04:00:00")))
+}
+
+func TestAppendWarning(t *testing.T) {
+	assert.Equal(t, "", appendWarning("", ""))
+	assert.Equal(t, "a", appendWarning("a", ""))
+	assert.Equal(t, "b", appendWarning("", "b"))
+	assert.Equal(t, "a; b", appendWarning("a", "b"))
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// alertTypeGrantReportReady is the alert type raised on a grant's accounts when a report
+// has been generated for one of its deadlines
+const alertTypeGrantReportReady = "grant_report_ready"
+
+// scheduledReportType is the type of report generated for GRANT_REPORT deadlines
+const scheduledReportType = "financial"
+
+// CreateGrantDeadline records a deadline for a grant
+func (s *Service) CreateGrantDeadline(ctx context.Context, grantNumber string, req *api.CreateGrantDeadlineRequest) (*api.GrantDeadline, error) {
+	var errs api.ValidationErrors
+	switch req.Type {
+	case api.DeadlineTypeConference, api.DeadlineTypeGrantReport, api.DeadlineTypePeriodEnd, api.DeadlineTypeRenewal:
+	default:
+		errs.Add("type", "must be CONFERENCE, GRANT_REPORT, PERIOD_END or RENEWAL")
+	}
+	if req.DueDate.IsZero() {
+		errs.Add("due_date", "is required")
+	}
+	if req.BudgetPeriod != nil && *req.BudgetPeriod < 1 {
+		errs.Add("budget_period", "must be at least 1")
+	}
+	if err := errs.Err(); err != nil {
+		return nil, err
+	}
+
+	grant, err := s.grantQueries.GetGrantByNumber(ctx, grantNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deadlineQueries.CreateDeadline(ctx, grant, req)
+}
+
+// ListGrantDeadlines returns a grant's deadlines in due date order
+func (s *Service) ListGrantDeadlines(ctx context.Context, grantNumber string) ([]*api.GrantDeadline, error) {
+	grant, err := s.grantQueries.GetGrantByNumber(ctx, grantNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deadlineQueries.ListDeadlines(ctx, grant.ID)
+}
+
+// GetGrantReport returns a report generated for one of a grant's deadlines
+func (s *Service) GetGrantReport(ctx context.Context, grantNumber string, reportID int64) (*api.StoredGrantReport, error) {
+	grant, err := s.grantQueries.GetGrantByNumber(ctx, grantNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.deadlineQueries.GetReport(ctx, grant, reportID)
+}
+
+// GenerateDueGrantReports generates the financial report for each GRANT_REPORT deadline
+// falling due within budget.grant_report_lead_time of now, stores it and notifies the
+// grant's accounts. Each deadline's report is generated once; one that fails is logged
+// and retried on the next run. It returns how many reports were generated.
+func (s *Service) GenerateDueGrantReports(ctx context.Context, now time.Time) (int, error) {
+	deadlines, err := s.deadlineQueries.ListDueReportDeadlines(ctx, now.Add(s.config.GrantReportLeadTime), s.workerBatchSize())
+	if err != nil {
+		return 0, err
+	}
+
+	generated := 0
+	for _, deadline := range deadlines {
+		report, err := s.generateDeadlineReport(ctx, deadline)
+		if err != nil {
+			log.Error().Err(err).Str("grant", deadline.GrantNumber).Int64("deadline_id", deadline.ID).
+				Msg("Failed to generate scheduled grant report")
+			continue
+		}
+		if report != nil {
+			generated++
+		}
+	}
+
+	return generated, nil
+}
+
+// generateDeadlineReport generates, stores and announces a deadline's report. It returns
+// nil when another run has already stored one.
+func (s *Service) generateDeadlineReport(ctx context.Context, deadline *api.GrantDeadline) (*api.StoredGrantReport, error) {
+	grant, err := s.grantQueries.GetGrantByNumber(ctx, deadline.GrantNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	period := deadlineReportPeriod(grant, deadline)
+	report, err := s.GenerateGrantReport(ctx, &api.GrantReportRequest{
+		GrantNumber:  grant.GrantNumber,
+		ReportType:   scheduledReportType,
+		BudgetPeriod: &period,
+		Format:       "json",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode grant report: %w", err)
+	}
+
+	stored, err := s.deadlineQueries.StoreDeadlineReport(ctx, deadline, scheduledReportType, body)
+	if err != nil || stored == nil {
+		return nil, err
+	}
+
+	log.Info().Str("grant", grant.GrantNumber).Int64("report_id", stored.ID).
+		Time("due_date", deadline.DueDate).Msg("Generated scheduled grant report")
+	s.notifyGrantReportReady(ctx, grant, deadline, stored)
+	return stored, nil
+}
+
+// deadlineReportPeriod returns the budget period a deadline's report covers: the one the
+// deadline names, or else the one containing its due date. A report due after the grant
+// ends covers its last period.
+func deadlineReportPeriod(grant *api.GrantAccount, deadline *api.GrantDeadline) int {
+	if deadline.BudgetPeriod != nil {
+		return *deadline.BudgetPeriod
+	}
+	at := deadline.DueDate
+	if !at.Before(grant.GrantEndDate) {
+		at = grant.GrantEndDate.Add(-time.Second)
+	}
+	return grant.BudgetPeriodAt(at)
+}
+
+// grantReportPath is where a stored grant report can be downloaded
+func grantReportPath(grantNumber string, reportID int64) string {
+	return fmt.Sprintf("/api/v1/grants/%s/reports/%d", url.PathEscape(grantNumber), reportID)
+}
+
+// notifyGrantReportReady raises an alert on each of the grant's accounts, for its PI and
+// account managers, with the link to the report. Errors are logged; the report is kept
+// either way.
+func (s *Service) notifyGrantReportReady(ctx context.Context, grant *api.GrantAccount, deadline *api.GrantDeadline, report *api.StoredGrantReport) {
+	accounts, err := s.grantQueries.ListGrantAccounts(ctx, grant.ID)
+	if err != nil {
+		log.Error().Err(err).Str("grant", grant.GrantNumber).Msg("Failed to list accounts for grant report notification")
+		return
+	}
+	if len(accounts) == 0 {
+		log.Warn().Str("grant", grant.GrantNumber).Int64("report_id", report.ID).
+			Msg("Grant report generated but the grant funds no accounts to notify")
+		return
+	}
+
+	message := grantReportMessage(grant, deadline, report)
+	for _, account := range accounts {
+		err := s.raiseAlert(ctx, &api.BudgetAlert{
+			AccountID: account.ID,
+			GrantID:   &grant.ID,
+			AlertType: alertTypeGrantReportReady,
+			Severity:  "info",
+			Message:   message,
+		})
+		if err != nil {
+			log.Error().Err(err).Str("account", account.SlurmAccount).Msg("Failed to raise grant report alert")
+		}
+	}
+}
+
+// grantReportMessage tells the grant's PI that a deadline's report is ready and where to
+// download it
+func grantReportMessage(grant *api.GrantAccount, deadline *api.GrantDeadline, report *api.StoredGrantReport) string {
+	due := deadline.DueDate.In(grant.Location()).Format("2006-01-02")
+	return fmt.Sprintf("Financial report for grant %s (PI %s), due %s, is ready to download at %s",
+		grant.GrantNumber, grant.PrincipalInvestigator, due, grantReportPath(grant.GrantNumber, report.ID))
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestDeadlineReportPeriod(t *testing.T) {
+	grant := &api.GrantAccount{
+		GrantNumber:        "NSF-2025-12345",
+		GrantStartDate:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		GrantEndDate:       time.Date(2028, 1, 1, 0, 0, 0, 0, time.UTC),
+		BudgetPeriodMonths: 12,
+	}
+	period := func(n int) *int { return &n }
+
+	tests := []struct {
+		name     string
+		deadline api.GrantDeadline
+		want     int
+	}{
+		{"due in the first period", api.GrantDeadline{DueDate: time.Date(2025, 12, 15, 0, 0, 0, 0, time.UTC)}, 1},
+		{"due in the second period", api.GrantDeadline{DueDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)}, 2},
+		{"period named", api.GrantDeadline{DueDate: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), BudgetPeriod: period(1)}, 1},
+		{"due after the grant ends", api.GrantDeadline{DueDate: time.Date(2028, 3, 31, 0, 0, 0, 0, time.UTC)}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, deadlineReportPeriod(grant, &tt.deadline))
+		})
+	}
+}
+
+func TestGrantReportMessage(t *testing.T) {
+	grant := &api.GrantAccount{
+		GrantNumber:           "NIH R01/42",
+		PrincipalInvestigator: "Dr. Smith",
+		Timezone:              "America/New_York",
+	}
+	deadline := &api.GrantDeadline{DueDate: time.Date(2026, 2, 1, 3, 0, 0, 0, time.UTC)}
+
+	message := grantReportMessage(grant, deadline, &api.StoredGrantReport{ID: 7})
+	assert.Equal(t, "Financial report for grant NIH R01/42 (PI Dr. Smith), due 2026-01-31, is ready to download at "+
+		"/api/v1/grants/NIH%20R01%2F42/reports/7", message)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// AdjustHold changes a live hold's amount in place, when an administrator corrects a bad
+// estimate without cancelling and resubmitting the job. The account's held budget and its
+// ancestors' move by the difference, and the change is recorded, in one transaction. An
+// increase is not checked against the budget: the job is already holding. A hold that
+// has been charged, released or cancelled cannot be adjusted.
+func (s *Service) AdjustHold(ctx context.Context, transactionID string, req *api.HoldAdjustRequest) (*api.HoldAdjustResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	hold, err := s.transactionQueries.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Type != "hold" {
+		return nil, api.NewBudgetError(api.ErrCodeValidation, "Transaction is not a hold transaction")
+	}
+	if hold.Status == "cancelled" {
+		return nil, api.NewHoldSettledError(hold.TransactionID)
+	}
+	if hold.FullHoldAmount != nil && hold.StartedAt == nil {
+		// Escalation replaces a queued reservation with the full hold, which would undo the
+		// adjustment
+		return nil, api.NewBudgetError(api.ErrCodeValidation,
+			fmt.Sprintf("Hold %s is a queued reservation; adjust it once the job has started", hold.TransactionID))
+	}
+
+	ids, err := chainIDs(ctx, s.accountQueries, hold.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	adjustment := &api.HoldAdjustment{
+		TransactionID: hold.TransactionID,
+		NewAmount:     roundCents(req.Amount),
+		Reason:        req.Reason,
+		AdjustedBy:    req.AdjustedBy,
+	}
+	var adjusted bool
+	err = s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		// The chain is locked as budget checks lock it, so held budget moves under balances
+		// no concurrent check is reading
+		if _, err := lockAccounts(ctx, s.accountQueries, tx, ids); err != nil {
+			return err
+		}
+		adjusted, err = s.transactionQueries.AdjustHold(ctx, tx, adjustment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !adjusted {
+		// The job was reconciled or its hold recovered since it was read
+		return nil, api.NewHoldSettledError(hold.TransactionID)
+	}
+
+	account, err := s.accountQueries.GetAccountByID(ctx, adjustment.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	adjustment.Account = account.SlurmAccount
+
+	log.Info().
+		Str("transaction_id", adjustment.TransactionID).
+		Str("account", adjustment.Account).
+		Float64("previous_amount", adjustment.PreviousAmount).
+		Float64("new_amount", adjustment.NewAmount).
+		Str("adjusted_by", adjustment.AdjustedBy).
+		Msg("Hold adjusted")
+
+	return &api.HoldAdjustResponse{Adjustment: adjustment, Account: account}, nil
+}
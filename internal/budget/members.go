@@ -0,0 +1,263 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// maxUserNameLength matches the user_name column
+const maxUserNameLength = 255
+
+// validateUserName checks a user name fits the user_name column and a URL path segment
+func validateUserName(user string) error {
+	switch {
+	case user == "":
+		return api.NewValidationError("user", "is required")
+	case len(user) > maxUserNameLength:
+		return api.NewValidationError("user", fmt.Sprintf("must be at most %d characters", maxUserNameLength))
+	case strings.ContainsAny(user, " \t\n/"):
+		return api.NewValidationError("user", "must not contain whitespace or slashes")
+	}
+	return nil
+}
+
+// AddAccountMember lets user submit jobs under the account
+func (s *Service) AddAccountMember(ctx context.Context, slurmAccount, user string) (*api.AccountMember, error) {
+	if err := validateUserName(user); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.memberQueries.AddMember(ctx, account, user)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("account", account.SlurmAccount).Str("user", user).Msg("Account member added")
+	return member, nil
+}
+
+// RemoveAccountMember removes user from the account's members
+func (s *Service) RemoveAccountMember(ctx context.Context, slurmAccount, user string) error {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return err
+	}
+
+	if err := s.memberQueries.RemoveMember(ctx, account, user); err != nil {
+		return err
+	}
+
+	log.Info().Str("account", account.SlurmAccount).Str("user", user).Msg("Account member removed")
+	return nil
+}
+
+// ListAccountMembers returns the users who may submit under the account
+func (s *Service) ListAccountMembers(ctx context.Context, slurmAccount string) ([]*api.AccountMember, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.memberQueries.ListMembers(ctx, account)
+}
+
+// ListUserAccounts returns the accounts user may submit under, each with the budget a job
+// there could draw on, as a budget check would count it, and the account's health, so a
+// researcher in several accounts can see where they can afford to run
+func (s *Service) ListUserAccounts(ctx context.Context, user string) (*api.UserAccountsResponse, error) {
+	if err := validateUserName(user); err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.memberQueries.ListMemberAccounts(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.UserAccountsResponse{User: user, Accounts: make([]*api.UserAccount, 0, len(accounts))}
+	for _, account := range accounts {
+		ancestors, err := s.accountQueries.ListAncestors(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		graceCredit, err := s.holdGraceCredit(ctx, account, ancestors)
+		if err != nil {
+			return nil, err
+		}
+		resp.Accounts = append(resp.Accounts, userAccount(account, ancestors, graceCredit, s.utilizationBands()))
+	}
+
+	return resp, nil
+}
+
+// userAccount describes account to one of its members. Its available budget is the least
+// spendable in the account and its ancestors, and its health is, in order: unavailable
+// when any of them refuses new jobs, critical when nothing is left to spend, and otherwise
+// the highest utilization band the account has reached.
+func userAccount(account *api.BudgetAccount, ancestors []*api.BudgetAccount, graceCredit map[int64]float64, bands []alertBand) *api.UserAccount {
+	available, limiting := chainAvailable(account, ancestors, graceCredit)
+
+	ua := &api.UserAccount{
+		SlurmAccount:    account.SlurmAccount,
+		Name:            account.Name,
+		Status:          account.Status,
+		BudgetLimit:     account.BudgetLimit,
+		BudgetUsed:      account.BudgetUsed,
+		BudgetHeld:      account.BudgetHeld,
+		BudgetUnit:      account.Unit(),
+		BudgetAvailable: available,
+		EndDate:         account.EndDate,
+		Health:          api.AccountHealthHealthy,
+	}
+	if limiting.ID != account.ID {
+		ua.LimitingAccount = limiting.SlurmAccount
+	}
+	if account.BudgetLimit > 0 {
+		ua.Utilization = (account.BudgetUsed + account.BudgetHeld) / account.BudgetLimit * 100
+	}
+
+	for _, a := range append([]*api.BudgetAccount{account}, ancestors...) {
+		if !a.IsActive() {
+			ua.Health = api.AccountHealthUnavailable
+			ua.HealthReason = inactiveReason(a)
+			return ua
+		}
+		if a.Frozen {
+			ua.Health = api.AccountHealthUnavailable
+			ua.HealthReason = fmt.Sprintf("Account '%s' is frozen", a.SlurmAccount)
+			return ua
+		}
+	}
+
+	if available <= 0 {
+		ua.Health = api.AccountHealthCritical
+		ua.HealthReason = "No budget left to spend"
+		if ua.LimitingAccount != "" {
+			ua.HealthReason = fmt.Sprintf("No budget left in parent account '%s'", ua.LimitingAccount)
+		}
+		return ua
+	}
+
+	for i := len(bands) - 1; i >= 0; i-- {
+		if ua.Utilization >= bands[i].Threshold {
+			ua.Health = strings.ToUpper(bands[i].Severity)
+			ua.HealthReason = fmt.Sprintf("%.1f%% of the budget used or held (%s threshold %.0f%%)",
+				ua.Utilization, bands[i].Severity, bands[i].Threshold)
+			break
+		}
+	}
+
+	return ua
+}
+
+// inactiveReason explains why an account that is not active refuses new jobs
+func inactiveReason(account *api.BudgetAccount) string {
+	switch {
+	case account.Status != "active":
+		return fmt.Sprintf("Account '%s' is %s", account.SlurmAccount, account.Status)
+	case time.Now().Before(account.StartDate):
+		return fmt.Sprintf("Account '%s' has not started", account.SlurmAccount)
+	default:
+		return fmt.Sprintf("Account '%s' has ended", account.SlurmAccount)
+	}
+}
+
+// AuthorizeBudgetCheck refuses a budget check made for user unless user is a member of
+// the account the job is submitted under and of every account sharing its cost
+func (s *Service) AuthorizeBudgetCheck(ctx context.Context, user string, req *api.BudgetCheckRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	accounts := []string{req.Account}
+	for _, share := range req.CostShares {
+		if share.Account != req.Account {
+			accounts = append(accounts, share.Account)
+		}
+	}
+
+	for _, slurmAccount := range accounts {
+		account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+		if err != nil {
+			return err
+		}
+		if err := s.authorizeMember(ctx, account, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AuthorizeReconcile refuses a reconciliation made for user unless user is a member of the
+// account holding the job's budget or, for a job approved without a hold, the account the
+// request names
+func (s *Service) AuthorizeReconcile(ctx context.Context, user string, req *api.JobReconcileRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	var account *api.BudgetAccount
+	var err error
+	if req.TransactionID != "" {
+		hold, err := s.transactionQueries.GetTransaction(ctx, req.TransactionID)
+		if err != nil {
+			return err
+		}
+		account, err = s.accountQueries.GetAccountByID(ctx, hold.AccountID)
+		if err != nil {
+			return err
+		}
+	} else {
+		account, err = s.accountQueries.GetAccountByName(ctx, req.Account)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.authorizeMember(ctx, account, user)
+}
+
+// AuthorizeJobStart refuses a started-job report made for user unless user is a member of
+// the account holding the job's budget
+func (s *Service) AuthorizeJobStart(ctx context.Context, user string, req *api.JobStartedRequest) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+
+	hold, err := s.transactionQueries.GetTransaction(ctx, req.TransactionID)
+	if err != nil {
+		return err
+	}
+	account, err := s.accountQueries.GetAccountByID(ctx, hold.AccountID)
+	if err != nil {
+		return err
+	}
+	return s.authorizeMember(ctx, account, user)
+}
+
+// authorizeMember returns a forbidden error unless user is a member of account
+func (s *Service) authorizeMember(ctx context.Context, account *api.BudgetAccount, user string) error {
+	member, err := s.memberQueries.IsMember(ctx, account.ID, user)
+	if err != nil {
+		return err
+	}
+	if !member {
+		return api.NewNotAccountMemberError(account.SlurmAccount, user)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestValidateUserName(t *testing.T) {
+	assert.NoError(t, validateUserName("researcher1"))
+	assert.NoError(t, validateUserName("jane.doe@example.edu"))
+
+	for _, user := range []string{"", "jane doe", "a/b", strings.Repeat("u", maxUserNameLength+1)} {
+		err := validateUserName(user)
+		if assert.Error(t, err, "user %q", user) {
+			var budgetErr *api.BudgetError
+			assert.ErrorAs(t, err, &budgetErr)
+			assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		}
+	}
+}
+
+func TestUserAccount(t *testing.T) {
+	bands := []alertBand{{Severity: "warning", Threshold: 80}, {Severity: "critical", Threshold: 95}}
+	account := func(id int64, limit, used, held float64) *api.BudgetAccount {
+		return &api.BudgetAccount{
+			ID: id, SlurmAccount: "acct" + string(rune('0'+id)), Name: "Account",
+			BudgetLimit: limit, BudgetUsed: used, BudgetHeld: held, Status: "active",
+			StartDate: time.Now().Add(-24 * time.Hour), EndDate: time.Now().Add(24 * time.Hour),
+		}
+	}
+
+	t.Run("healthy below the warning threshold", func(t *testing.T) {
+		ua := userAccount(account(1, 1000, 300, 100), nil, nil, bands)
+		assert.Equal(t, api.AccountHealthHealthy, ua.Health)
+		assert.Empty(t, ua.HealthReason)
+		assert.Equal(t, 600.0, ua.BudgetAvailable)
+		assert.Equal(t, 40.0, ua.Utilization)
+		assert.Empty(t, ua.LimitingAccount)
+	})
+
+	t.Run("highest utilization band reached", func(t *testing.T) {
+		assert.Equal(t, api.AccountHealthWarning, userAccount(account(1, 1000, 800, 50), nil, nil, bands).Health)
+
+		ua := userAccount(account(1, 1000, 950, 0), nil, nil, bands)
+		assert.Equal(t, api.AccountHealthCritical, ua.Health)
+		assert.Contains(t, ua.HealthReason, "critical threshold 95%")
+	})
+
+	t.Run("no bands configured", func(t *testing.T) {
+		assert.Equal(t, api.AccountHealthHealthy, userAccount(account(1, 1000, 990, 0), nil, nil, nil).Health)
+	})
+
+	t.Run("reserve and grace credit", func(t *testing.T) {
+		a := account(1, 1000, 300, 100)
+		a.ReservedAmount = 200
+		ua := userAccount(a, nil, map[int64]float64{1: 50}, bands)
+		assert.Equal(t, 450.0, ua.BudgetAvailable)
+	})
+
+	t.Run("parent pool limits availability", func(t *testing.T) {
+		parent := account(2, 5000, 4900, 0)
+		ua := userAccount(account(1, 1000, 100, 0), []*api.BudgetAccount{parent}, nil, bands)
+		assert.Equal(t, 100.0, ua.BudgetAvailable)
+		assert.Equal(t, parent.SlurmAccount, ua.LimitingAccount)
+		assert.Equal(t, api.AccountHealthHealthy, ua.Health)
+
+		parent.BudgetUsed = 5000
+		ua = userAccount(account(1, 1000, 100, 0), []*api.BudgetAccount{parent}, nil, bands)
+		assert.Equal(t, api.AccountHealthCritical, ua.Health)
+		assert.Contains(t, ua.HealthReason, "parent account '"+parent.SlurmAccount+"'")
+	})
+
+	t.Run("nothing left to spend", func(t *testing.T) {
+		ua := userAccount(account(1, 1000, 1000, 0), nil, nil, nil)
+		assert.Equal(t, api.AccountHealthCritical, ua.Health)
+		assert.Equal(t, "No budget left to spend", ua.HealthReason)
+	})
+
+	t.Run("unavailable accounts", func(t *testing.T) {
+		frozen := account(1, 1000, 0, 0)
+		frozen.Frozen = true
+		assert.Equal(t, api.AccountHealthUnavailable, userAccount(frozen, nil, nil, bands).Health)
+
+		suspendedParent := account(2, 5000, 0, 0)
+		suspendedParent.Status = "suspended"
+		ua := userAccount(account(1, 1000, 0, 0), []*api.BudgetAccount{suspendedParent}, nil, bands)
+		assert.Equal(t, api.AccountHealthUnavailable, ua.Health)
+		assert.Equal(t, "Account 'acct2' is suspended", ua.HealthReason)
+
+		ended := account(1, 1000, 0, 0)
+		ended.EndDate = time.Now().Add(-time.Hour)
+		ua = userAccount(ended, nil, nil, bands)
+		assert.Equal(t, api.AccountHealthUnavailable, ua.Health)
+		assert.Equal(t, "Account 'acct1' has ended", ua.HealthReason)
+	})
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"io"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/metrics"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// newAccountGauges creates the per-account balance gauges, labelling at most limit accounts
+// individually; zero labels every account. Accounts are ranked by budget used, so the
+// biggest spenders keep their own series.
+func newAccountGauges(limit int) *metrics.AccountGauges {
+	return metrics.NewAccountGauges(limit, "asbb_account_metrics_rolled_up",
+		metrics.AccountGauge{Name: "asbb_account_budget_used", Help: "Budget spent by the account, as of the last collection."},
+		metrics.AccountGauge{Name: "asbb_account_budget_held", Help: "Budget held for the account's running jobs, as of the last collection."},
+		metrics.AccountGauge{Name: "asbb_account_budget_limit", Help: "The account's budget limit, as of the last collection."},
+	)
+}
+
+// SetAccountLabelLimit sets how many accounts the per-account gauges label individually,
+// the rest being rolled into an "other" account; zero labels every account. Values
+// collected so far are dropped until the next collection.
+func (s *Service) SetAccountLabelLimit(limit int) {
+	s.accountGauges = newAccountGauges(limit)
+}
+
+// CollectMetrics refreshes the per-account gauges from every active account's balances.
+// It runs on metrics.collect_interval, so budget checks and reconciliations never touch the
+// gauges and a burst of them costs nothing in metrics.
+func (s *Service) CollectMetrics(ctx context.Context) error {
+	accounts, err := s.accountQueries.ListAccounts(ctx, &api.ListAccountsRequest{Status: "active"})
+	if err != nil {
+		return err
+	}
+
+	snapshot := make(map[string][]float64, len(accounts))
+	for _, account := range accounts {
+		snapshot[account.SlurmAccount] = []float64{account.BudgetUsed, account.BudgetHeld, account.BudgetLimit}
+	}
+	s.accountGauges.Update(snapshot)
+	return nil
+}
+
+// WriteMetrics writes the service's metrics in the Prometheus text exposition format
+func (s *Service) WriteMetrics(w io.Writer) error {
+	if s.reconciliationLatency != nil {
+		if _, err := s.reconciliationLatency.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	if s.accountGauges != nil {
+		if _, err := s.accountGauges.WriteTo(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
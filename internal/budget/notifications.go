@@ -0,0 +1,346 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// Notification modes, as configured by budget.notification_mode
+const (
+	notificationModeImmediate = "IMMEDIATE"
+	notificationModeDigest    = "DIGEST"
+)
+
+// Notifier delivers alert notifications to their recipients
+type Notifier interface {
+	Notify(ctx context.Context, notification *api.Notification) error
+}
+
+// SetNotifier has raised alerts notified through notifier to the recipients following their
+// accounts. A nil notifier notifies no one.
+func (s *Service) SetNotifier(notifier Notifier) {
+	s.notifier = notifier
+}
+
+// notificationMode returns the configured notification mode; an empty mode is IMMEDIATE
+func (s *Service) notificationMode() string {
+	if s.config.NotificationMode == "" {
+		return notificationModeImmediate
+	}
+	return s.config.NotificationMode
+}
+
+// sendsImmediately reports whether an alert of the given severity is sent as it is raised
+// rather than held for a digest. Critical alerts never wait.
+func sendsImmediately(mode, severity string) bool {
+	return mode != notificationModeDigest || severity == "critical"
+}
+
+// followers returns the recipients following any of the accounts, in order. recipients
+// maps each recipient to the SLURM accounts they follow.
+func followers(recipients map[string][]string, accounts []string) []string {
+	var matched []string
+	for recipient, followed := range recipients {
+	match:
+		for _, f := range followed {
+			for _, account := range accounts {
+				if f == account {
+					matched = append(matched, recipient)
+					break match
+				}
+			}
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// alertRecipients returns the SLURM account an alert was raised on and the recipients
+// following it or one of its ancestors
+func (s *Service) alertRecipients(ctx context.Context, accountID int64) (string, []string, error) {
+	account, err := s.accountQueries.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return "", nil, err
+	}
+	ancestors, err := s.accountQueries.ListAncestors(ctx, accountID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	chain := []string{account.SlurmAccount}
+	for _, ancestor := range ancestors {
+		chain = append(chain, ancestor.SlurmAccount)
+	}
+	return account.SlurmAccount, followers(s.config.NotificationRecipients, chain), nil
+}
+
+// raiseAlert records a newly triggered alert and notifies it
+func (s *Service) raiseAlert(ctx context.Context, alert *api.BudgetAlert) error {
+	if err := s.alertQueries.CreateAlert(ctx, alert); err != nil {
+		return err
+	}
+	s.notifyAlert(ctx, alert)
+	return nil
+}
+
+// notifyAlert queues an alert to be sent to each recipient following its account, or holds
+// it for their next digest. Nothing is sent here, so raising an alert never waits on the
+// webhook. Errors are logged; the alert stands either way.
+func (s *Service) notifyAlert(ctx context.Context, alert *api.BudgetAlert) {
+	if s.notifier == nil || len(s.config.NotificationRecipients) == 0 {
+		return
+	}
+
+	account, recipients, err := s.alertRecipients(ctx, alert.AccountID)
+	if err != nil {
+		log.Error().Err(err).Int64("alert_id", alert.ID).Msg("Failed to find alert notification recipients")
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	item := api.NotificationItem{
+		AlertID:   alert.ID,
+		Account:   account,
+		AlertType: alert.AlertType,
+		Severity:  alert.Severity,
+		Message:   alert.Message,
+		RaisedAt:  alert.TriggeredAt,
+	}
+	if !sendsImmediately(s.notificationMode(), alert.Severity) {
+		if err := s.notificationQueries.AddDigestItem(ctx, recipients, &item); err != nil {
+			log.Error().Err(err).Int64("alert_id", alert.ID).Msg("Failed to hold alert for notification digest")
+		}
+		return
+	}
+
+	for _, recipient := range recipients {
+		notification := &api.Notification{
+			Recipient: recipient,
+			Subject:   fmt.Sprintf("[%s] %s alert on %s", alert.Severity, alert.AlertType, account),
+			Items:     []api.NotificationItem{item},
+		}
+		if err := s.notificationQueries.QueueNotification(ctx, nil, notification); err != nil {
+			log.Error().Err(err).Int64("alert_id", alert.ID).Str("recipient", recipient).Msg("Failed to queue alert notification")
+		}
+	}
+}
+
+// digestNotification summarizes the alerts held for a recipient in one notification
+func digestNotification(recipient string, items []api.NotificationItem) *api.Notification {
+	critical := 0
+	accounts := make(map[string]bool)
+	for _, item := range items {
+		if item.Severity == "critical" {
+			critical++
+		}
+		accounts[item.Account] = true
+	}
+
+	subject := fmt.Sprintf("Budget alert digest: %d alerts on %d accounts", len(items), len(accounts))
+	if critical > 0 {
+		subject += fmt.Sprintf(" (%d critical)", critical)
+	}
+	return &api.Notification{Recipient: recipient, Digest: true, Subject: subject, Items: items}
+}
+
+// FlushNotificationDigests queues each recipient one digest of the alerts held for them,
+// for the notification worker to send, and returns how many digests were queued
+func (s *Service) FlushNotificationDigests(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+
+	recipients, err := s.notificationQueries.ListDigestRecipients(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	for _, recipient := range recipients {
+		// A flush running alongside may already have taken the recipient's alerts
+		taken := false
+		err := s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+			items, err := s.notificationQueries.TakeDigestItems(ctx, tx, recipient)
+			if err != nil || len(items) == 0 {
+				return err
+			}
+			if err := s.notificationQueries.QueueNotification(ctx, tx, digestNotification(recipient, items)); err != nil {
+				return err
+			}
+			taken = true
+			return nil
+		})
+		if err != nil {
+			log.Error().Err(err).Str("recipient", recipient).Msg("Failed to queue notification digest")
+			continue
+		}
+		if taken {
+			queued++
+		}
+	}
+
+	if queued > 0 {
+		log.Info().Int("digests", queued).Msg("Queued notification digests")
+	}
+	return queued, nil
+}
+
+// notificationHistory returns how many delivered and failed notifications are kept; zero
+// keeps 1000
+func (s *Service) notificationHistory() int {
+	if s.config.NotificationHistory <= 0 {
+		return 1000
+	}
+	return s.config.NotificationHistory
+}
+
+// notificationOutcome returns the status a notification is left in by its attempts-th try
+// to send it failing with err, or succeeding when err is nil. A failed try leaves it to be
+// tried again until maxAttempts tries have failed; zero tries forever.
+func notificationOutcome(attempts, maxAttempts int, err error) string {
+	switch {
+	case err == nil:
+		return api.NotificationDelivered
+	case maxAttempts > 0 && attempts >= maxAttempts:
+		return api.NotificationFailed
+	default:
+		return api.NotificationPending
+	}
+}
+
+// sendNotification tries to send a notification claimed within tx and records the outcome
+// as its status, returning the notification as recorded and why the send failed
+func (s *Service) sendNotification(ctx context.Context, tx *sql.Tx, notification *api.NotificationRecord) (*api.NotificationRecord, error) {
+	sendErr := s.notifier.Notify(ctx, notification.Notification)
+	status := notificationOutcome(notification.Attempts+1, s.config.NotificationMaxAttempts, sendErr)
+	if notification.Status == api.NotificationFailed && sendErr != nil {
+		// An administrator's retry that fails leaves the notification failed
+		status = api.NotificationFailed
+	}
+
+	lastError := ""
+	if sendErr != nil {
+		lastError = sendErr.Error()
+	}
+	recorded, err := s.notificationQueries.RecordNotificationAttempt(ctx, tx, notification.ID, status, lastError)
+	if err != nil {
+		return nil, err
+	}
+	return recorded, sendErr
+}
+
+// SendQueuedNotifications sends the notifications waiting in the queue, oldest first, and
+// returns how many were delivered. A notification that cannot be sent is tried again on
+// the next run until budget.notification_max_attempts tries have failed, then marked
+// failed for an administrator to retry; the others are sent regardless. Delivered and
+// failed notifications beyond budget.notification_history are then pruned.
+func (s *Service) SendQueuedNotifications(ctx context.Context) (int, error) {
+	if s.notifier == nil {
+		return 0, nil
+	}
+
+	ids, err := s.notificationQueries.ListPendingNotifications(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, id := range ids {
+		// A run alongside may already have claimed the notification
+		var recorded *api.NotificationRecord
+		var sendErr error
+		err := s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+			notification, err := s.notificationQueries.ClaimNotification(ctx, tx, id, api.NotificationPending)
+			if err != nil || notification == nil {
+				return err
+			}
+			recorded, sendErr = s.sendNotification(ctx, tx, notification)
+			if recorded == nil {
+				return sendErr
+			}
+			return nil
+		})
+		switch {
+		case err != nil:
+			log.Error().Err(err).Int64("notification_id", id).Msg("Failed to send notification")
+		case recorded == nil:
+			// Already sent by a run alongside
+		case recorded.Status == api.NotificationDelivered:
+			delivered++
+		case recorded.Status == api.NotificationFailed:
+			log.Error().Err(sendErr).Int64("notification_id", id).Int("attempts", recorded.Attempts).
+				Msg("Notification failed after repeated attempts")
+		default:
+			log.Warn().Err(sendErr).Int64("notification_id", id).Int("attempts", recorded.Attempts).
+				Msg("Failed to send notification; it will be tried again")
+		}
+	}
+
+	if delivered > 0 {
+		log.Info().Int("notifications", delivered).Msg("Sent notifications")
+	}
+
+	if _, err := s.notificationQueries.PruneNotifications(ctx, s.notificationHistory()); err != nil {
+		log.Error().Err(err).Msg("Failed to prune notification history")
+	}
+	return delivered, nil
+}
+
+// ListNotifications returns the queued notifications with the status, or every one when
+// status is empty, newest first
+func (s *Service) ListNotifications(ctx context.Context, status string) ([]*api.NotificationRecord, error) {
+	switch status {
+	case "", api.NotificationPending, api.NotificationDelivered, api.NotificationFailed:
+	default:
+		return nil, api.NewValidationError("status", "must be pending, delivered or failed")
+	}
+	return s.notificationQueries.ListNotifications(ctx, status)
+}
+
+// RetryNotification resends a failed notification once its webhook is reachable again. The
+// attempt is recorded either way: a delivered notification is marked delivered, and one
+// that fails again stays failed with the new error.
+func (s *Service) RetryNotification(ctx context.Context, id int64) (*api.NotificationRecord, error) {
+	if s.notifier == nil {
+		return nil, api.NewBudgetError(api.ErrCodeServiceUnavailable, "Notifications are not configured")
+	}
+
+	var recorded *api.NotificationRecord
+	var sendErr error
+	err := s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		notification, err := s.notificationQueries.ClaimNotification(ctx, tx, id, api.NotificationFailed)
+		if err != nil {
+			return err
+		}
+		if notification == nil {
+			return api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Failed notification %d not found", id))
+		}
+		recorded, sendErr = s.sendNotification(ctx, tx, notification)
+		if recorded == nil {
+			return sendErr
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sendErr != nil {
+		return nil, api.NewBudgetErrorWithCause(api.ErrCodeExternalService,
+			fmt.Sprintf("Failed to resend notification %d", id), sendErr)
+	}
+
+	log.Info().Int64("notification_id", id).Str("recipient", recorded.Recipient).Msg("Resent failed notification")
+	return recorded, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestSendsImmediately(t *testing.T) {
+	assert.True(t, sendsImmediately(notificationModeImmediate, "warning"))
+	assert.True(t, sendsImmediately(notificationModeImmediate, "critical"))
+	assert.False(t, sendsImmediately(notificationModeDigest, "info"))
+	assert.False(t, sendsImmediately(notificationModeDigest, "warning"))
+	assert.True(t, sendsImmediately(notificationModeDigest, "critical"))
+}
+
+func TestFollowers(t *testing.T) {
+	recipients := map[string][]string{
+		"grants@example.edu": {"dept"},
+		"pi@example.edu":     {"lab", "other-lab"},
+		"other@example.edu":  {"other-lab"},
+	}
+
+	// An alert on the lab reaches those following it and those following its department
+	assert.Equal(t, []string{"grants@example.edu", "pi@example.edu"}, followers(recipients, []string{"lab", "dept"}))
+	assert.Equal(t, []string{"grants@example.edu"}, followers(recipients, []string{"dept"}))
+	assert.Empty(t, followers(recipients, []string{"unfollowed"}))
+	assert.Empty(t, followers(nil, []string{"lab"}))
+}
+
+func TestDigestNotification(t *testing.T) {
+	items := []api.NotificationItem{
+		{AlertID: 1, Account: "lab1", Severity: "warning"},
+		{AlertID: 2, Account: "lab2", Severity: "warning"},
+		{AlertID: 3, Account: "lab1", Severity: "critical"},
+	}
+
+	digest := digestNotification("grants@example.edu", items)
+	assert.Equal(t, "grants@example.edu", digest.Recipient)
+	assert.True(t, digest.Digest)
+	assert.Equal(t, "Budget alert digest: 3 alerts on 2 accounts (1 critical)", digest.Subject)
+	assert.Equal(t, items, digest.Items)
+
+	assert.NotContains(t, digestNotification("pi@example.edu", items[:2]).Subject, "critical")
+}
+
+func TestNotificationOutcome(t *testing.T) {
+	refused := errors.New("webhook returned 503")
+	assert.Equal(t, api.NotificationDelivered, notificationOutcome(1, 5, nil))
+	assert.Equal(t, api.NotificationDelivered, notificationOutcome(9, 5, nil), "a late success still delivers")
+	assert.Equal(t, api.NotificationPending, notificationOutcome(4, 5, refused))
+	assert.Equal(t, api.NotificationFailed, notificationOutcome(5, 5, refused))
+	assert.Equal(t, api.NotificationPending, notificationOutcome(100, 0, refused), "zero tries forever")
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// Advisor request metadata keys describing recent reconciled jobs like the one estimated
+const (
+	metadataPriorActuals           = "prior_actuals" // JSON list of api.PriorActual, newest first
+	metadataPriorActualCount       = "prior_actual_count"
+	metadataPriorMeanActualCost    = "prior_mean_actual_cost"
+	metadataPriorMeanCPUEfficiency = "prior_mean_cpu_efficiency" // Over the jobs that reported one
+)
+
+// priorActuals returns the advisor request metadata describing the account's most recent
+// reconciled jobs with the job's partition and resource shape, or nil when there are none.
+// History that cannot be read is logged and left out, so the estimate goes ahead without it.
+func (s *Service) priorActuals(ctx context.Context, req *api.BudgetCheckRequest) map[string]string {
+	if s.config == nil || s.config.AdvisorPriorActuals <= 0 {
+		return nil
+	}
+
+	actuals, err := s.accuracyQueries.RecentActuals(ctx, req.Account, req.Partition,
+		req.Nodes, req.CPUs, req.GPUs, s.config.AdvisorPriorActuals)
+	if err != nil {
+		log.Warn().Err(err).Str("account", req.Account).Msg("Failed to read recent actuals; estimating without them")
+		return nil
+	}
+	return priorActualsMetadata(actuals)
+}
+
+// priorActualsMetadata encodes recent actuals as advisor request metadata, with their mean
+// actual cost and CPU efficiency for advisors that read no further
+func priorActualsMetadata(actuals []api.PriorActual) map[string]string {
+	if len(actuals) == 0 {
+		return nil
+	}
+
+	encoded, err := json.Marshal(actuals)
+	if err != nil {
+		return nil
+	}
+
+	var totalCost, totalEfficiency float64
+	efficiencies := 0
+	for _, actual := range actuals {
+		totalCost += actual.ActualCost
+		if actual.CPUEfficiency > 0 {
+			totalEfficiency += actual.CPUEfficiency
+			efficiencies++
+		}
+	}
+
+	metadata := map[string]string{
+		metadataPriorActuals:        string(encoded),
+		metadataPriorActualCount:    strconv.Itoa(len(actuals)),
+		metadataPriorMeanActualCost: strconv.FormatFloat(totalCost/float64(len(actuals)), 'f', 2, 64),
+	}
+	if efficiencies > 0 {
+		metadata[metadataPriorMeanCPUEfficiency] = strconv.FormatFloat(totalEfficiency/float64(efficiencies), 'f', 3, 64)
+	}
+	return metadata
+}
@@ -0,0 +1,49 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestPriorActualsMetadata(t *testing.T) {
+	assert.Nil(t, priorActualsMetadata(nil))
+
+	completed := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	actuals := []api.PriorActual{
+		{EstimatedCost: 10, ActualCost: 8, CPUEfficiency: 0.8, MemoryEfficiency: 0.5, CompletedAt: completed},
+		{EstimatedCost: 10, ActualCost: 11, CompletedAt: completed.Add(-time.Hour)},
+		{EstimatedCost: 12, ActualCost: 9.5, CPUEfficiency: 0.6, CompletedAt: completed.Add(-2 * time.Hour)},
+	}
+
+	metadata := priorActualsMetadata(actuals)
+	assert.Equal(t, "3", metadata[metadataPriorActualCount])
+	assert.Equal(t, "9.50", metadata[metadataPriorMeanActualCost])
+	assert.Equal(t, "0.700", metadata[metadataPriorMeanCPUEfficiency], "jobs that reported no efficiency are left out of its mean")
+
+	var decoded []api.PriorActual
+	require.NoError(t, json.Unmarshal([]byte(metadata[metadataPriorActuals]), &decoded))
+	assert.Equal(t, actuals, decoded)
+
+	t.Run("no efficiencies reported", func(t *testing.T) {
+		metadata := priorActualsMetadata(actuals[1:2])
+		assert.NotContains(t, metadata, metadataPriorMeanCPUEfficiency)
+	})
+}
+
+func TestService_PriorActuals_Disabled(t *testing.T) {
+	// With no priors configured the history is never read
+	service := &Service{config: &config.BudgetConfig{}}
+	assert.Nil(t, service.priorActuals(context.Background(), &api.BudgetCheckRequest{Account: "proj001", Partition: "cpu"}))
+}
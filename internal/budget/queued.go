@@ -0,0 +1,131 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// queuedHoldFraction returns the fraction of a job's hold reserved while it queues on the
+// given partition. Zero holds in full at submission.
+func (s *Service) queuedHoldFraction(partition string) float64 {
+	// Partition overrides come from a config map, whose keys are lower-cased on load
+	if fraction, ok := s.config.PartitionQueuedHoldFraction[strings.ToLower(partition)]; ok {
+		return fraction
+	}
+	return s.config.QueuedHoldFraction
+}
+
+// queuedReservation returns what a job holds while it queues, and whether that is only a
+// reservation of the full hold to be escalated when the job starts
+func queuedReservation(holdAmount, fraction float64) (float64, bool) {
+	if holdAmount <= 0 || fraction <= 0 || fraction >= 1 {
+		return holdAmount, false
+	}
+	return roundCents(holdAmount * fraction), true
+}
+
+// StartJob escalates a queued job's hold from its reservation to the full hold, when the
+// job's prolog reports that it started. The job is already running, so the hold is
+// escalated even when the budget no longer covers it, with a warning. Reporting a job
+// started again, or one whose hold was placed in full, changes nothing. A hold tagged with
+// another job is refused.
+func (s *Service) StartJob(ctx context.Context, req *api.JobStartedRequest) (*api.JobStartedResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	hold, err := s.transactionQueries.GetTransaction(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Type != "hold" {
+		return nil, api.NewBudgetError(api.ErrCodeValidation, "Transaction is not a hold transaction")
+	}
+	// A hold already tagged with a job escalates only for that job's own prolog
+	if hold.JobID != nil && *hold.JobID != "" && *hold.JobID != req.JobID {
+		return nil, api.NewBudgetError(api.ErrCodeValidation,
+			fmt.Sprintf("Hold %s is for job %s, not job %s", hold.TransactionID, *hold.JobID, req.JobID))
+	}
+
+	resp := &api.JobStartedResponse{
+		JobID:          req.JobID,
+		TransactionID:  hold.TransactionID,
+		ReservedAmount: hold.Amount,
+		HoldAmount:     hold.Amount,
+	}
+	switch {
+	case hold.FullHoldAmount == nil:
+		resp.Message = "Hold was placed in full at submission"
+		return resp, nil
+	case hold.StartedAt != nil:
+		resp.Message = "Job was already reported started"
+		return resp, nil
+	case hold.Status != "completed":
+		return nil, api.NewBudgetError(api.ErrCodeValidation, fmt.Sprintf("Hold %s is %s", hold.TransactionID, hold.Status))
+	}
+
+	ancestors, err := s.accountQueries.ListAncestors(ctx, hold.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	ids := []int64{hold.AccountID}
+	for _, ancestor := range ancestors {
+		ids = append(ids, ancestor.ID)
+	}
+
+	var added, available float64
+	var limiting *api.BudgetAccount
+	var escalated bool
+	err = s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		// The chain is locked as budget checks lock it, so the escalation is measured against
+		// balances no concurrent check can change
+		locked, err := lockAccounts(ctx, s.accountQueries, tx, ids)
+		if err != nil {
+			return err
+		}
+		lockedAncestors := make([]*api.BudgetAccount, len(ancestors))
+		for i, ancestor := range ancestors {
+			lockedAncestors[i] = locked[ancestor.ID]
+		}
+		available, limiting = chainAvailable(locked[hold.AccountID], lockedAncestors, nil)
+
+		added, escalated, err = s.transactionQueries.EscalateHold(ctx, tx, hold.TransactionID, req.JobID)
+		return err
+	})
+	if err != nil {
+		return nil, api.NewTransactionFailedError(hold.TransactionID, err)
+	}
+	if !escalated {
+		// The job was reconciled or its hold recovered before its prolog reported in, or
+		// another job's report tagged the hold first
+		return nil, api.NewBudgetError(api.ErrCodeValidation,
+			fmt.Sprintf("Hold %s was already charged, released or started by another job", hold.TransactionID))
+	}
+
+	resp.Escalated = true
+	resp.HoldAmount = hold.Amount + added
+	resp.Message = "Hold escalated to the full amount"
+	if added > available {
+		resp.Warning = fmt.Sprintf("Account %s had %.2f available for the %.2f escalation; the hold exceeds its budget",
+			limiting.SlurmAccount, available, added)
+		log.Warn().
+			Str("job_id", req.JobID).
+			Str("transaction_id", hold.TransactionID).
+			Str("account", limiting.SlurmAccount).
+			Float64("available", available).
+			Float64("escalation", added).
+			Msg("Escalated queued hold beyond the available budget")
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestService_QueuedHoldFraction(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{
+		QueuedHoldFraction:          0.25,
+		PartitionQueuedHoldFraction: map[string]float64{"aws": 0.1, "debug": 0},
+	}}
+
+	assert.Equal(t, 0.25, service.queuedHoldFraction("cpu"))
+	assert.Equal(t, 0.1, service.queuedHoldFraction("aws"))
+	assert.Equal(t, 0.1, service.queuedHoldFraction("AWS"))
+	assert.Equal(t, 0.0, service.queuedHoldFraction("debug"))
+
+	disabled := &Service{config: &config.BudgetConfig{}}
+	assert.Equal(t, 0.0, disabled.queuedHoldFraction("aws"))
+}
+
+func TestQueuedReservation(t *testing.T) {
+	tests := []struct {
+		name     string
+		hold     float64
+		fraction float64
+		reserved float64
+		queued   bool
+	}{
+		{"disabled", 120, 0, 120, false},
+		{"tenth", 120, 0.1, 12, true},
+		{"rounded to cents", 10.01, 0.1, 1, true},
+		{"whole hold", 120, 1, 120, false},
+		{"no hold", 0, 0.1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reserved, queued := queuedReservation(tt.hold, tt.fraction)
+			assert.InDelta(t, tt.reserved, reserved, 0.001)
+			assert.Equal(t, tt.queued, queued)
+		})
+	}
+}
+
+func TestService_StartJob_InvalidRequest(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{}}
+
+	_, err := service.StartJob(context.Background(), &api.JobStartedRequest{JobID: "4242"})
+	budgetErr, ok := api.AsBudgetError(err)
+	require.True(t, ok)
+	assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	assert.Equal(t, "transaction_id", budgetErr.Field)
+}
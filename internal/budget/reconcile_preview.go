@@ -0,0 +1,123 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// previewMessage describes a previewed reconciliation
+const previewMessage = "Reconciliation preview; nothing was charged or refunded"
+
+// PreviewReconciliation works out what reconciling a job would charge and refund, under
+// the same failed job policy and cost sharing as ReconcileJob, without writing anything.
+// The response has the shape ReconcileJob would return, with Preview set; a job run
+// without a hold has no transaction ID since its charge is not made.
+func (s *Service) PreviewReconciliation(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.TransactionID == "" {
+		return s.previewUnheldJob(ctx, req)
+	}
+
+	hold, err := s.transactionQueries.GetTransaction(ctx, req.TransactionID)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Type != "hold" {
+		return nil, api.NewBudgetError(api.ErrCodeValidation, "Transaction is not a hold transaction")
+	}
+
+	account, err := s.accountQueries.GetAccountByID(ctx, hold.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	actualCost := accountCost(account, req.ActualCost)
+	policy := s.failedJobPolicy(req.JobState)
+	if policy == api.FailedJobPolicyFullRefund {
+		actualCost = 0
+	}
+	_, grantWarning := s.flagAfterGrantEnd(ctx, hold.AccountID, req)
+
+	resp := &api.JobReconcileResponse{
+		Success:         true,
+		ActualCharge:    actualCost,
+		TransactionID:   req.TransactionID,
+		Message:         previewMessage,
+		FailedJobPolicy: policy,
+		Warning:         grantWarning,
+		BudgetUnit:      account.Unit(),
+		Preview:         true,
+	}
+
+	// A cost-shared job's holds would be reconciled together, whichever of them was given
+	if hold.CostShareGroup != nil {
+		holds, err := s.transactionQueries.ListCostShareHolds(ctx, *hold.CostShareGroup)
+		if err != nil {
+			return nil, err
+		}
+		if len(holds) == 0 {
+			return nil, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Cost share holds for %s not found", *hold.CostShareGroup))
+		}
+
+		percentages, charges := costShareCharges(holds, actualCost)
+		for i, h := range holds {
+			_, additionalCharge, refundAmount := holdSettlement(h.Hold.Amount, charges[i])
+			resp.CostShares = append(resp.CostShares, api.CostShareAllocation{
+				Account:       h.Account,
+				Percentage:    percentages[i],
+				TransactionID: h.Hold.TransactionID,
+				HoldAmount:    h.Hold.Amount,
+				ActualCharge:  charges[i],
+				RefundAmount:  refundAmount,
+			})
+			resp.OriginalHold += h.Hold.Amount
+			resp.RefundAmount += refundAmount
+			resp.AdditionalCharge += additionalCharge
+		}
+		return resp, nil
+	}
+
+	resp.OriginalHold = hold.Amount
+	_, resp.AdditionalCharge, resp.RefundAmount = holdSettlement(hold.Amount, actualCost)
+	return resp, nil
+}
+
+// previewUnheldJob works out the charge for a job run without a hold
+func (s *Service) previewUnheldJob(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
+	if req.Account == "" {
+		return nil, api.NewValidationError("transaction_id", "transaction_id is required unless account is given for a job run without a hold")
+	}
+	if req.ActualCost < 0 {
+		return nil, api.NewValidationError("actual_cost", "must not be negative")
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.JobReconcileResponse{
+		Success:         true,
+		Message:         previewMessage,
+		FailedJobPolicy: s.failedJobPolicy(req.JobState),
+		BudgetUnit:      account.Unit(),
+		Preview:         true,
+	}
+	if resp.FailedJobPolicy == api.FailedJobPolicyFullRefund {
+		return resp, nil
+	}
+
+	resp.ActualCharge = accountCost(account, req.ActualCost)
+	// Without a hold the whole cost is charged directly
+	resp.AdditionalCharge = resp.ActualCharge
+	_, resp.Warning = s.flagAfterGrantEnd(ctx, account.ID, req)
+	return resp, nil
+}
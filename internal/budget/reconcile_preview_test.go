@@ -0,0 +1,34 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestService_PreviewReconciliation_ValidatesBeforeQuerying(t *testing.T) {
+	// The service has no database, so these must be refused before one is needed
+	s := &Service{}
+	ctx := context.Background()
+
+	for name, req := range map[string]*api.JobReconcileRequest{
+		"negative component":   {TransactionID: "txn_1", ActualCost: 5.0, CostBreakdown: map[string]float64{"compute": -1}},
+		"no hold or account":   {JobID: "123", ActualCost: 5.0},
+		"negative unheld cost": {JobID: "123", Account: "proj001", ActualCost: -1.0},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.PreviewReconciliation(ctx, req)
+			budgetErr, ok := api.AsBudgetError(err)
+			require.True(t, ok)
+			assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		})
+	}
+}
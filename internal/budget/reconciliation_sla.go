@@ -0,0 +1,72 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// alertTypeReconciliationSLA is the alert type raised when a hold is reconciled later than
+// budget.reconciliation_sla
+const alertTypeReconciliationSLA = "reconciliation_sla"
+
+// reconciliationLatencyBuckets are the histogram bounds, in seconds, from a minute to a week
+var reconciliationLatencyBuckets = []float64{60, 300, 900, 3600, 14400, 43200, 86400, 259200, 604800}
+
+// recordReconciliationLatency observes how long a hold waited to be reconciled and raises
+// a reconciliation_sla alert when it waited longer than the SLA. An account keeps one open
+// SLA alert; further breaches are left to it until it is resolved.
+func (s *Service) recordReconciliationLatency(ctx context.Context, hold *api.BudgetTransaction, latency time.Duration) {
+	if s.reconciliationLatency != nil {
+		s.reconciliationLatency.Observe(latency.Seconds())
+	}
+
+	if !breachesSLA(latency, s.config.ReconciliationSLA) {
+		return
+	}
+
+	open, err := s.alertQueries.GetOpenAlert(ctx, hold.AccountID, alertTypeReconciliationSLA)
+	if err != nil {
+		log.Error().Err(err).Str("transaction_id", hold.TransactionID).Msg("Failed to check reconciliation SLA alert")
+		return
+	}
+	if open != nil {
+		return
+	}
+
+	account := fmt.Sprintf("account %d", hold.AccountID)
+	if a, err := s.accountQueries.GetAccountByID(ctx, hold.AccountID); err == nil {
+		account = a.SlurmAccount
+	}
+
+	err = s.raiseAlert(ctx, &api.BudgetAlert{
+		AccountID:      hold.AccountID,
+		AlertType:      alertTypeReconciliationSLA,
+		Severity:       "warning",
+		ThresholdValue: s.config.ReconciliationSLA.Hours(),
+		ActualValue:    latency.Hours(),
+		Message:        slaBreachMessage(hold.TransactionID, account, latency, s.config.ReconciliationSLA),
+	})
+	if err != nil {
+		log.Error().Err(err).Str("transaction_id", hold.TransactionID).Msg("Failed to raise reconciliation SLA alert")
+	}
+}
+
+// breachesSLA reports whether a reconciliation latency is over the SLA; a zero SLA is off
+func breachesSLA(latency, sla time.Duration) bool {
+	return sla > 0 && latency > sla
+}
+
+// slaBreachMessage describes a hold reconciled later than the SLA
+func slaBreachMessage(transactionID, account string, latency, sla time.Duration) string {
+	return fmt.Sprintf("Hold %s for %s was reconciled after %.1f hours, beyond the %s reconciliation SLA",
+		transactionID, account, latency.Hours(), sla)
+}
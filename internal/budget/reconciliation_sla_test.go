@@ -0,0 +1,50 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/metrics"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBreachesSLA(t *testing.T) {
+	assert.True(t, breachesSLA(30*time.Hour, 24*time.Hour))
+	assert.False(t, breachesSLA(24*time.Hour, 24*time.Hour))
+	assert.False(t, breachesSLA(2*time.Hour, 24*time.Hour))
+	assert.False(t, breachesSLA(300*time.Hour, 0), "a zero SLA is off")
+}
+
+func TestSLABreachMessage(t *testing.T) {
+	msg := slaBreachMessage("txn_slow", "proj001", 30*time.Hour, 24*time.Hour)
+	assert.Equal(t, "Hold txn_slow for proj001 was reconciled after 30.0 hours, beyond the 24h0m0s reconciliation SLA", msg)
+}
+
+func TestRecordReconciliationLatency_Metric(t *testing.T) {
+	service := &Service{
+		config:                &config.BudgetConfig{},
+		reconciliationLatency: metrics.NewHistogram("asbb_reconciliation_latency_seconds", "Latency.", reconciliationLatencyBuckets...),
+	}
+
+	// With the SLA off no alert is looked up, so no database is needed
+	hold := &api.BudgetTransaction{TransactionID: "txn_1", AccountID: 1}
+	service.recordReconciliationLatency(context.Background(), hold, 90*time.Second)
+	service.recordReconciliationLatency(context.Background(), hold, 30*time.Hour)
+
+	var out strings.Builder
+	require.NoError(t, service.WriteMetrics(&out))
+	assert.Contains(t, out.String(), `asbb_reconciliation_latency_seconds_bucket{le="300"} 1`)
+	assert.Contains(t, out.String(), `asbb_reconciliation_latency_seconds_bucket{le="259200"} 2`)
+	assert.Contains(t, out.String(), "asbb_reconciliation_latency_seconds_sum 108090\n")
+	assert.Contains(t, out.String(), "asbb_reconciliation_latency_seconds_count 2\n")
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// RecordASBXReconciliation records the variance summary of an ASBX reconciliation, so it
+// can be looked up by its ID along with its transactions
+func (s *Service) RecordASBXReconciliation(ctx context.Context, summary *api.ASBXReconciliationSummary) error {
+	return s.reconcileQueries.RecordSummary(ctx, summary)
+}
+
+// ASBXReconciliationStatus summarizes the recorded ASBX reconciliations, measuring
+// estimation accuracy over those whose estimate came from estimateSource
+func (s *Service) ASBXReconciliationStatus(ctx context.Context, estimateSource string) (*api.ASBXIntegrationStatus, error) {
+	return s.reconcileQueries.GetStatus(ctx, estimateSource)
+}
+
+// GetASBXReconciliation returns an ASBX reconciliation's variance summary and every
+// transaction it involved: the holds it settled and the charges and refunds it wrote
+func (s *Service) GetASBXReconciliation(ctx context.Context, reconciliationID string) (*api.ASBXReconciliation, error) {
+	summary, err := s.reconcileQueries.GetSummary(ctx, reconciliationID)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := s.reconcileQueries.ListTransactions(ctx, reconciliationID)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil && len(transactions) == 0 {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Reconciliation %s not found", reconciliationID))
+	}
+
+	return &api.ASBXReconciliation{Summary: summary, Transactions: transactions}, nil
+}
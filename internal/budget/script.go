@@ -0,0 +1,121 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// completedJobState is the SLURM state of a job that ran to the end successfully
+const completedJobState = "COMPLETED"
+
+// isCompletedJobState reports whether a SLURM job state is COMPLETED, ignoring any reason
+func isCompletedJobState(state string) bool {
+	fields := strings.Fields(strings.ToUpper(state))
+	return len(fields) > 0 && fields[0] == completedJobState
+}
+
+// normalizeJobScript reduces a job script to the lines that decide what it runs, so
+// scripts differing only in layout and comments match. Line endings are unified and each
+// line trimmed; blank lines and comments are dropped, except the shebang and #SBATCH
+// directives.
+func normalizeJobScript(script string) string {
+	lines := strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n")
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "#SBATCH") && !(i == 0 && strings.HasPrefix(line, "#!")) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// jobScriptHash returns the hex SHA-256 of a normalized job script, or empty when there is
+// no script
+func jobScriptHash(script string) string {
+	normalized := normalizeJobScript(script)
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// applyScriptHistory blends a cost estimate with the mean actual cost of recent completed
+// jobs run from the same job script, once there are enough of them. The estimate is
+// returned unchanged when script history is off, the job has no script or the history
+// cannot be read.
+func (s *Service) applyScriptHistory(ctx context.Context, estimate *costEstimate, script string) *costEstimate {
+	if !s.config.ScriptHistoryEnabled {
+		return estimate
+	}
+	hash := jobScriptHash(script)
+	if hash == "" {
+		return estimate
+	}
+
+	mean, samples, err := s.accuracyQueries.ScriptCostHistory(ctx, hash, s.config.ScriptHistorySampleLimit)
+	if err != nil {
+		log.Warn().Err(err).Str("script_hash", hash).Msg("Failed to load job script cost history, using the estimate alone")
+		return estimate
+	}
+	if samples < s.config.ScriptHistoryMinSamples {
+		return estimate
+	}
+
+	return blendScriptHistory(estimate, &api.ScriptHistory{
+		ScriptHash:     hash,
+		Samples:        samples,
+		MeanActualCost: mean,
+		Weight:         s.config.ScriptHistoryWeight,
+	})
+}
+
+// blendScriptHistory returns a copy of estimate whose cost takes history's weight from the
+// script's mean actual cost and the rest from the estimate
+func blendScriptHistory(estimate *costEstimate, history *api.ScriptHistory) *costEstimate {
+	blended := *estimate
+	response := *estimate.CostEstimateResponse
+	history.AdvisorEstimate = response.EstimatedCost
+	response.EstimatedCost = history.Weight*history.MeanActualCost + (1-history.Weight)*response.EstimatedCost
+	blended.CostEstimateResponse = &response
+	blended.ScriptHistory = history
+	return &blended
+}
+
+// recordScriptCost records a reconciled job's actual cost against the script recorded on
+// its hold. Only jobs that completed are recorded, since a failed or cancelled run says
+// little about what the script costs. A failure is logged rather than failing the
+// reconciliation, which has already been committed.
+func (s *Service) recordScriptCost(ctx context.Context, hold *api.BudgetTransaction, req *api.JobReconcileRequest) {
+	if req.JobState != "" && !isCompletedJobState(req.JobState) {
+		return
+	}
+	metadata, err := api.DecodeTransactionMetadata(hold.Type, hold.Metadata)
+	if err != nil {
+		log.Warn().Err(err).Str("transaction_id", hold.TransactionID).Msg("Failed to read hold metadata for job script history")
+		return
+	}
+	holdMetadata, ok := metadata.(*api.HoldMetadata)
+	if !ok || holdMetadata.ScriptHash == "" {
+		return
+	}
+
+	if err := s.accuracyQueries.RecordScriptCost(ctx, holdMetadata.ScriptHash, req.JobID, req.ActualCost); err != nil {
+		log.Warn().Err(err).Str("job_id", req.JobID).Msg("Failed to record job script cost")
+	}
+}
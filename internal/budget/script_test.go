@@ -0,0 +1,88 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestNormalizeJobScript(t *testing.T) {
+	script := "#!/bin/bash\n#SBATCH --nodes=2\n\n# run the solver\nsrun ./solver --steps 100\n"
+	reformatted := "#!/bin/bash\r\n  #SBATCH --nodes=2\r\n# the solver, again\r\n\r\n\tsrun ./solver --steps 100   \r\n"
+
+	assert.Equal(t, "#!/bin/bash\n#SBATCH --nodes=2\nsrun ./solver --steps 100", normalizeJobScript(script))
+	assert.Equal(t, normalizeJobScript(script), normalizeJobScript(reformatted))
+	assert.Empty(t, normalizeJobScript("\n# only a comment\n\n"))
+}
+
+func TestJobScriptHash(t *testing.T) {
+	hash := jobScriptHash("#!/bin/bash\nsrun ./solver\n")
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, jobScriptHash("#!/bin/bash\n\n# comment\nsrun ./solver"))
+	assert.NotEqual(t, hash, jobScriptHash("#!/bin/bash\nsrun ./solver --steps 200\n"))
+	assert.NotEqual(t, hash, jobScriptHash("#!/bin/bash\n#SBATCH --gpus=4\nsrun ./solver\n"))
+	assert.Empty(t, jobScriptHash(""))
+	assert.Empty(t, jobScriptHash("  \n# nothing to run\n"))
+}
+
+func TestBlendScriptHistory(t *testing.T) {
+	advisorResponse := &CostEstimateResponse{EstimatedCost: 100.0, Confidence: 0.9}
+	estimate := &costEstimate{CostEstimateResponse: advisorResponse, DomainFactor: 1.3}
+
+	result := blendScriptHistory(estimate, &api.ScriptHistory{
+		ScriptHash:     "abc",
+		Samples:        5,
+		MeanActualCost: 40.0,
+		Weight:         0.75,
+	})
+
+	assert.InDelta(t, 55.0, result.EstimatedCost, 0.0001)
+	assert.Equal(t, 0.9, result.Confidence)
+	assert.Equal(t, 1.3, result.DomainFactor)
+	if assert.NotNil(t, result.ScriptHistory) {
+		assert.Equal(t, 5, result.ScriptHistory.Samples)
+		assert.InDelta(t, 100.0, result.ScriptHistory.AdvisorEstimate, 0.0001)
+	}
+	assert.Equal(t, 100.0, advisorResponse.EstimatedCost, "the advisor's response is not modified")
+	assert.Nil(t, estimate.ScriptHistory)
+}
+
+func TestApplyScriptHistory_Unused(t *testing.T) {
+	// With no database, anything that reached the queries would fail
+	estimate := &costEstimate{CostEstimateResponse: &CostEstimateResponse{EstimatedCost: 100.0}}
+	ctx := context.Background()
+
+	disabled := NewService(nil, nil, &config.BudgetConfig{})
+	assert.Same(t, estimate, disabled.applyScriptHistory(ctx, estimate, "#!/bin/bash\nsrun ./solver"))
+
+	enabled := NewService(nil, nil, &config.BudgetConfig{
+		ScriptHistoryEnabled:     true,
+		ScriptHistoryMinSamples:  3,
+		ScriptHistorySampleLimit: 20,
+		ScriptHistoryWeight:      0.7,
+	})
+	assert.Same(t, estimate, enabled.applyScriptHistory(ctx, estimate, ""))
+}
+
+func TestRecordScriptCost_SkipsUnrecordedJobs(t *testing.T) {
+	// With no database, anything that reached the queries would panic
+	service := NewService(nil, nil, &config.BudgetConfig{ScriptHistoryEnabled: true})
+	ctx := context.Background()
+	withScript, err := api.EncodeTransactionMetadata(&api.HoldMetadata{Partition: "aws", ScriptHash: "abc"})
+	require.NoError(t, err)
+
+	service.recordScriptCost(ctx, &api.BudgetTransaction{Type: "hold", Metadata: withScript},
+		&api.JobReconcileRequest{JobID: "1001", ActualCost: 10, JobState: "FAILED"})
+	service.recordScriptCost(ctx, &api.BudgetTransaction{Type: "hold"},
+		&api.JobReconcileRequest{JobID: "1002", ActualCost: 10, JobState: "COMPLETED"})
+}
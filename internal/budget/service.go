@@ -6,9 +6,10 @@ package budget
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
-	"strconv"
+	"sort"
 	"strings"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/metrics"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
@@ -46,22 +48,140 @@ type CostEstimateResponse struct {
 
 // Service provides budget management operations
 type Service struct {
-	db                 *database.DB
-	accountQueries     *database.AccountQueries
-	transactionQueries *database.TransactionQueries
-	advisorClient      AdvisorClient
-	config             *config.BudgetConfig
+	db                  *database.DB
+	accountQueries      *database.AccountQueries
+	transactionQueries  *database.TransactionQueries
+	snapshotQueries     *database.SnapshotQueries
+	grantQueries        *database.GrantQueries
+	alertQueries        *database.AlertQueries
+	burnRateQueries     *database.BurnRateQueries
+	usageQueries        *database.UsageQueries
+	allocationQueries   *database.AllocationQueries
+	accuracyQueries     *database.AccuracyQueries
+	decisionQueries     *database.DecisionQueries
+	transferQueries     *database.TransferQueries
+	exportQueries       *database.ExportQueries
+	feedbackQueries     *database.FeedbackQueries
+	memberQueries       *database.MemberQueries
+	deadlineQueries     *database.DeadlineQueries
+	deadLetterQueries   *database.DeadLetterQueries
+	reconcileQueries    *database.ReconciliationQueries
+	notificationQueries *database.NotificationQueries
+	advisorClient       AdvisorClient
+	config              *config.BudgetConfig
+	failureMode         string
+	staticCostRate      float64
+	fallbackRates       FallbackRates
+	holdExpiryChecker   HoldExpiryChecker
+	holdExpiryTimeout   time.Duration
+	// notifier delivers alert notifications; nil notifies no one
+	notifier Notifier
+	// An advisor estimate divergenceRatio times below the fallback estimate is raised per
+	// divergencePolicy; a zero ratio disables the check
+	divergenceRatio  float64
+	divergencePolicy string
+	// An advisor estimate that is not a positive cost is replaced per invalidEstimatePolicy
+	invalidEstimatePolicy  string
+	invalidEstimateMinimum float64
+	// reconciliationLatency observes how long each hold waited to be reconciled
+	reconciliationLatency *metrics.Histogram
+	// accountGauges report each account's balances as of the last metrics collection
+	accountGauges *metrics.AccountGauges
+	// balanceCache serves GetAccountBalance; nil when budget.balance_cache_ttl is zero
+	balanceCache *balanceCache
+}
+
+// Advisor failure modes, as configured by integration.failure_mode
+const (
+	failureModeStrict     = "STRICT"
+	failureModeGraceful   = "GRACEFUL"
+	failureModePermissive = "PERMISSIVE"
+)
+
+// holdAvailabilityGrace is the budget.hold_availability mode that discounts recent holds
+const holdAvailabilityGrace = "GRACE"
+
+// costEstimate is the estimate a budget check works from once the failure mode has been
+// applied to an unavailable advisor
+type costEstimate struct {
+	*CostEstimateResponse
+	FailureMode   string             // set only when the advisor was unavailable
+	DomainFactor  float64            // set only when the estimate was scaled for a research domain
+	ScriptHistory *api.ScriptHistory // set only when the job script's actual costs were blended in
+	// AdvisorDivergence is set only when the advisor's estimate was raised toward the fallback's
+	AdvisorDivergence *api.AdvisorDivergence
+	NoHold            bool
+	Warning           string
 }
 
 // NewService creates a new budget service
 func NewService(db *database.DB, advisorClient AdvisorClient, cfg *config.BudgetConfig) *Service {
-	return &Service{
-		db:                 db,
-		accountQueries:     database.NewAccountQueries(db),
-		transactionQueries: database.NewTransactionQueries(db),
-		advisorClient:      advisorClient,
-		config:             cfg,
+	s := &Service{
+		db:                  db,
+		accountQueries:      database.NewAccountQueries(db),
+		transactionQueries:  database.NewTransactionQueries(db),
+		snapshotQueries:     database.NewSnapshotQueries(db),
+		grantQueries:        database.NewGrantQueries(db),
+		alertQueries:        database.NewAlertQueries(db),
+		burnRateQueries:     database.NewBurnRateQueries(db),
+		usageQueries:        database.NewUsageQueries(db),
+		allocationQueries:   database.NewAllocationQueries(db),
+		accuracyQueries:     database.NewAccuracyQueries(db),
+		decisionQueries:     database.NewDecisionQueries(db),
+		transferQueries:     database.NewTransferQueries(db),
+		exportQueries:       database.NewExportQueries(db),
+		feedbackQueries:     database.NewFeedbackQueries(db),
+		memberQueries:       database.NewMemberQueries(db),
+		deadlineQueries:     database.NewDeadlineQueries(db),
+		deadLetterQueries:   database.NewDeadLetterQueries(db),
+		reconcileQueries:    database.NewReconciliationQueries(db),
+		notificationQueries: database.NewNotificationQueries(db),
+		advisorClient:       advisorClient,
+		config:              cfg,
+		reconciliationLatency: metrics.NewHistogram("asbb_reconciliation_latency_seconds",
+			"Time from placing a hold to reconciling it.", reconciliationLatencyBuckets...),
+		accountGauges: newAccountGauges(0),
+	}
+	if cfg != nil && cfg.BalanceCacheTTL > 0 && db != nil {
+		s.balanceCache = newBalanceCache(cfg.BalanceCacheTTL, s.loadAccountBalance)
+		db.OnAccountChange(s.balanceCache.invalidate)
 	}
+	return s
+}
+
+// SetFailureMode sets how budget checks behave when the advisor is unavailable. An empty
+// mode is treated as GRACEFUL.
+func (s *Service) SetFailureMode(mode string) {
+	s.failureMode = mode
+}
+
+// SetAdvisorDivergence sets how far below the fallback estimate an advisor estimate may
+// fall before it is raised, to the fallback estimate with MAX or the mean of the two with
+// BLEND. A ratio of zero disables the check.
+func (s *Service) SetAdvisorDivergence(ratio float64, policy string) {
+	s.divergenceRatio = ratio
+	s.divergencePolicy = policy
+}
+
+// SetInvalidEstimatePolicy sets what replaces an advisor estimate that is not a positive
+// cost: the fallback estimate with FALLBACK, or minimum with MINIMUM. An empty policy is
+// treated as FALLBACK.
+func (s *Service) SetInvalidEstimatePolicy(policy string, minimum float64) {
+	s.invalidEstimatePolicy = policy
+	s.invalidEstimateMinimum = minimum
+}
+
+// SetStaticCostRate sets the per CPU-hour rate of the static cost model, which accounts
+// pinned to the static estimation source are priced with
+func (s *Service) SetStaticCostRate(rate float64) {
+	s.staticCostRate = rate
+}
+
+// SetFallbackRates sets the rates of the fallback estimate, which GRACEFUL checks hold
+// against when the advisor is down, accounts pinned to the fallback estimation source are
+// priced with, and advisor estimates are checked against
+func (s *Service) SetFallbackRates(rates FallbackRates) {
+	s.fallbackRates = rates
 }
 
 // CheckBudget checks if a job submission can be accommodated within the budget
@@ -77,12 +197,202 @@ func (s *Service) CheckBudget(ctx context.Context, req *api.BudgetCheckRequest)
 		return nil, err
 	}
 
-	// Check if account is active
-	if !account.IsActive() {
-		return nil, api.NewAccountInactiveError(req.Account, account.Status)
+	// A child account also draws on every ancestor's pool
+	ancestors, err := s.accountQueries.ListAncestors(ctx, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	// The checks that need the job's cost run once it is priced. Under ALL a job already
+	// refused is still priced, so that every check refusing it is reported.
+	admission := s.newAdmission()
+	job := admissionJob{account: account, ancestors: ancestors, req: req}
+	if err := admission.run(ctx, s, admissionChecks, job); err != nil {
+		s.recordRejection(ctx, account, req, err)
+		return nil, err
+	}
+	if admission.done() {
+		err := admission.refusal()
+		s.recordRejection(ctx, account, req, err)
+		return nil, err
+	}
+
+	costResp, err := s.estimateCost(ctx, req, estimationSourceFor(account))
+	if err != nil {
+		if refusal := admission.refusal(); refusal != nil {
+			err = refusal
+		}
+		s.recordRejection(ctx, account, req, err)
+		return nil, err
+	}
+	costResp = s.applyDomainFactor(ctx, costResp, req.ResearchDomain)
+	costResp = s.applyScriptHistory(ctx, costResp, req.JobScript)
+
+	// Calculate hold amount with buffer
+	holdPercentage, holdSource := s.holdPercentageFor(account, req)
+	holdAmount := accountCost(account, costResp.EstimatedCost) * holdPercentage
+	if costResp.NoHold {
+		holdAmount = 0
+	}
+	graceCredit, err := s.holdGraceCredit(ctx, account, ancestors)
+	if err != nil {
+		return nil, err
+	}
+	budgetAvailable, limiting := chainAvailable(account, ancestors, graceCredit)
+
+	// Jobs too cheap to be worth a hold run free and are charged once at reconciliation; a
+	// cost-shared job always holds, so the charge can be split when the job is reconciled
+	threshold := s.minChargeableCost(req.Partition)
+	belowMinChargeable := len(req.CostShares) == 0 && isBelowMinChargeable(costResp.EstimatedCost, threshold)
+	job.cost = &admissionCost{
+		estimate:   costResp.EstimatedCost,
+		hold:       holdAmount,
+		available:  budgetAvailable,
+		limiting:   limiting,
+		costShared: len(req.CostShares) > 0,
+	}
+	if belowMinChargeable {
+		job.cost.hold = 0
+	}
+
+	refusedUnpriced := admission.refused()
+	if err := admission.run(ctx, s, costChecks, job); err != nil {
+		s.recordRejection(ctx, account, req, err)
+		return nil, err
+	}
+	if refusedUnpriced {
+		err := admission.refusal()
+		s.recordRejection(ctx, account, req, err)
+		return nil, err
+	}
+	costResp.Warning = appendWarning(costResp.Warning, admission.warning)
+	if admission.refused() {
+		return s.refusePricedJob(ctx, admission, account, req, costResp, holdAmount, holdPercentage, holdSource, budgetAvailable, limiting, graceCredit), nil
+	}
+
+	// A cost-shared job holds on every funding account
+	if len(req.CostShares) > 0 {
+		return s.checkCostSharedBudget(ctx, req, account, ancestors, costResp, holdPercentage, holdSource)
+	}
+
+	if belowMinChargeable {
+		resp := &api.BudgetCheckResponse{
+			Available:         true,
+			EstimatedCost:     costResp.EstimatedCost,
+			Message:           fmt.Sprintf("Estimated cost is below the minimum chargeable cost of %.2f; no hold placed", threshold),
+			BudgetRemaining:   budgetAvailable,
+			Recommendation:    costResp.Recommendation,
+			FailureMode:       costResp.FailureMode,
+			DomainFactor:      costResp.DomainFactor,
+			ScriptHistory:     costResp.ScriptHistory,
+			AdvisorDivergence: costResp.AdvisorDivergence,
+			HoldGraceCredit:   graceCredit[limiting.ID],
+			Warning:           costResp.Warning,
+			BudgetUnit:        account.Unit(),
+		}
+		resp.Details.AccountBalance = budgetAvailable
+		resp.Details.CurrentHold = account.BudgetHeld
+		resp.Details.HoldPercentage = holdPercentage
+		resp.Details.HoldPercentageSource = holdSource
+		resp.Details.AdvisorConfidence = costResp.Confidence
+		s.logDecision(ctx, newDecision(account, req, resp))
+		return resp, nil
+	}
+
+	// Create hold transaction. A queued job may hold only a reservation of its hold until it
+	// starts, though the full hold must fit for it to be approved.
+	metadata, err := holdMetadata(req, costResp, holdPercentage, holdSource)
+	if err != nil {
+		return nil, err
+	}
+	reservedAmount, queued := queuedReservation(holdAmount, s.queuedHoldFraction(req.Partition))
+	transaction := &api.BudgetTransaction{
+		AccountID:   account.ID,
+		Type:        "hold",
+		Amount:      reservedAmount,
+		Description: fmt.Sprintf("Budget hold for job on %s partition", req.Partition),
+		Metadata:    metadata,
+		Status:      "pending",
+	}
+	if queued {
+		transaction.FullHoldAmount = &holdAmount
+	}
+
+	// Store hold transaction in database, with the approval recorded alongside it. The
+	// balances read above may be stale, so the approval is decided again against the chain's
+	// rows locked on the primary; a check that no longer fits is rejected instead.
+	var resp *api.BudgetCheckResponse
+	err = s.withTransactionIDRetry(ctx, func(tx *sql.Tx) error {
+		locked, lockedAncestors, err := lockChain(ctx, s.accountQueries, tx, account, ancestors)
+		if err != nil {
+			return err
+		}
+		account = locked
+		budgetAvailable, limiting = chainAvailable(locked, lockedAncestors, graceCredit)
+		if holdAmount > budgetAvailable {
+			resp = insufficientBudgetResponse(account, limiting, costResp, holdAmount, holdPercentage, holdSource, budgetAvailable, graceCredit)
+			return s.decisionQueries.RecordDecision(ctx, tx, newDecision(account, req, resp))
+		}
+
+		resp = &api.BudgetCheckResponse{
+			Available:         true,
+			EstimatedCost:     costResp.EstimatedCost,
+			HoldAmount:        reservedAmount,
+			Message:           "Budget check passed",
+			BudgetRemaining:   budgetAvailable - reservedAmount,
+			Recommendation:    costResp.Recommendation,
+			FailureMode:       costResp.FailureMode,
+			DomainFactor:      costResp.DomainFactor,
+			ScriptHistory:     costResp.ScriptHistory,
+			AdvisorDivergence: costResp.AdvisorDivergence,
+			HoldGraceCredit:   graceCredit[limiting.ID],
+			Warning:           costResp.Warning,
+			BudgetUnit:        account.Unit(),
+		}
+		if queued {
+			resp.FullHoldAmount = holdAmount
+		}
+		resp.Details.AccountBalance = budgetAvailable
+		resp.Details.CurrentHold = account.BudgetHeld + reservedAmount
+		resp.Details.HoldPercentage = holdPercentage
+		resp.Details.HoldPercentageSource = holdSource
+		resp.Details.AdvisorConfidence = costResp.Confidence
+
+		transaction.TransactionID = s.generateTransactionID()
+		if err := s.transactionQueries.CreateTransaction(ctx, tx, transaction); err != nil {
+			return err
+		}
+		if err := s.transactionQueries.UpdateTransactionStatus(ctx, tx, transaction.TransactionID, "completed"); err != nil {
+			return err
+		}
+		resp.TransactionID = transaction.TransactionID
+		return s.decisionQueries.RecordDecision(ctx, tx, newDecision(account, req, resp))
+	})
+
+	if err != nil {
+		return nil, api.NewTransactionFailedError(transaction.TransactionID, err)
+	}
+	if !resp.Available {
+		s.enforceDepletion(ctx, limiting)
+	}
+
+	return resp, nil
+}
+
+// estimateCost asks the advisor for a cost estimate. When the advisor is unavailable the
+// failure mode decides what happens: STRICT rejects the check, GRACEFUL holds against the
+// fallback estimate and PERMISSIVE approves the job without holding any budget. An
+// account's estimation source may pin it to the fallback or static cost model instead.
+func (s *Service) estimateCost(ctx context.Context, req *api.BudgetCheckRequest, source string) (*costEstimate, error) {
+	// Accounts pinned to a local cost model never reach the advisor, however healthy it is
+	switch source {
+	case api.EstimationSourceFallback:
+		estimate := s.fallbackCostEstimate(req)
+		estimate.Recommendation = "Fallback cost estimate - account uses the fallback cost model"
+		return &costEstimate{CostEstimateResponse: estimate}, nil
+	case api.EstimationSourceStatic:
+		return &costEstimate{CostEstimateResponse: s.staticCostEstimate(req)}, nil
 	}
 
-	// Get cost estimate from advisor with graceful fallback
 	costReq := &CostEstimateRequest{
 		Account:   req.Account,
 		Partition: req.Partition,
@@ -92,92 +402,261 @@ func (s *Service) CheckBudget(ctx context.Context, req *api.BudgetCheckRequest)
 		Memory:    req.Memory,
 		WallTime:  req.WallTime,
 		JobScript: req.JobScript,
+		// What jobs like this one actually cost the account, as priors for the advisor
+		Metadata: s.priorActuals(ctx, req),
 	}
 
 	costResp, err := s.advisorClient.EstimateCost(ctx, costReq)
-	if err != nil {
-		log.Warn().Err(err).Msg("Advisor service unavailable, using fallback cost estimation")
-		// Graceful fallback: use simple cost estimation
-		costResp = s.fallbackCostEstimate(req)
+	if err == nil {
+		estimate := s.checkAdvisorEstimate(req, &costEstimate{CostEstimateResponse: costResp})
+		return s.checkAdvisorDivergence(req, estimate), nil
 	}
 
-	// Calculate hold amount with buffer
-	holdAmount := costResp.EstimatedCost * s.config.DefaultHoldPercentage
-	budgetAvailable := account.BudgetAvailable()
-
-	// Check if sufficient budget is available
-	if holdAmount > budgetAvailable {
-		return &api.BudgetCheckResponse{
-			Available:       false,
-			EstimatedCost:   costResp.EstimatedCost,
-			HoldAmount:      holdAmount,
-			Message:         "Insufficient budget",
-			BudgetRemaining: budgetAvailable,
-			Details: struct {
-				AccountBalance    float64 `json:"account_balance"`
-				CurrentHold       float64 `json:"current_hold"`
-				PartitionUsed     float64 `json:"partition_used,omitempty"`
-				PartitionLimit    float64 `json:"partition_limit,omitempty"`
-				HoldPercentage    float64 `json:"hold_percentage"`
-				AdvisorConfidence float64 `json:"advisor_confidence,omitempty"`
-			}{
-				AccountBalance:    budgetAvailable,
-				CurrentHold:       account.BudgetHeld,
-				HoldPercentage:    s.config.DefaultHoldPercentage,
-				AdvisorConfidence: costResp.Confidence,
-			},
+	switch s.failureMode {
+	case failureModeStrict:
+		log.Warn().Err(err).Msg("Advisor service unavailable, rejecting budget check in STRICT mode")
+		return nil, api.NewBudgetErrorWithCause(api.ErrCodeAdvisorUnavailable,
+			"Advisor service is unavailable and failure_mode is STRICT", err)
+	case failureModePermissive:
+		log.Warn().Err(err).Msg("Advisor service unavailable, approving without a hold in PERMISSIVE mode")
+		return &costEstimate{
+			CostEstimateResponse: s.fallbackCostEstimate(req),
+			FailureMode:          failureModePermissive,
+			NoHold:               true,
+			Warning:              "Advisor service unavailable; job approved without a budget hold",
+		}, nil
+	default:
+		log.Warn().Err(err).Msg("Advisor service unavailable, using fallback cost estimation")
+		return &costEstimate{
+			CostEstimateResponse: s.fallbackCostEstimate(req),
+			FailureMode:          failureModeGraceful,
+			Warning:              "Advisor service unavailable; hold is based on a fallback estimate",
 		}, nil
 	}
+}
 
-	// Create hold transaction
-	transactionID := s.generateTransactionID()
-	transaction := &api.BudgetTransaction{
-		TransactionID: transactionID,
-		AccountID:     account.ID,
-		Type:          "hold",
-		Amount:        holdAmount,
-		Description:   fmt.Sprintf("Budget hold for job on %s partition", req.Partition),
-		Status:        "pending",
+// checkAcceptsHolds refuses a new hold unless the account and every ancestor are active
+// and none of them is frozen. Freezing only stops new holds, so nothing else checks it.
+func checkAcceptsHolds(account *api.BudgetAccount, ancestors []*api.BudgetAccount) error {
+	for _, a := range append([]*api.BudgetAccount{account}, ancestors...) {
+		if !a.IsActive() {
+			return api.NewAccountInactiveError(a.SlurmAccount, a.Status)
+		}
+		if a.Frozen {
+			return api.NewAccountFrozenError(a.SlurmAccount)
+		}
 	}
+	return nil
+}
 
-	// Store hold transaction in database
-	err = s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
-		if err := s.transactionQueries.CreateTransaction(ctx, tx, transaction); err != nil {
-			return err
+// insufficientBudgetResponse rejects a hold that does not fit within the limiting account
+func insufficientBudgetResponse(account, limiting *api.BudgetAccount, costResp *costEstimate, holdAmount, holdPercentage float64, holdSource string, budgetAvailable float64, graceCredit map[int64]float64) *api.BudgetCheckResponse {
+	message := "Insufficient budget"
+	if limiting.ID != account.ID {
+		message = fmt.Sprintf("Insufficient budget in parent account %s", limiting.SlurmAccount)
+	}
+	resp := &api.BudgetCheckResponse{
+		Available:         false,
+		EstimatedCost:     costResp.EstimatedCost,
+		HoldAmount:        holdAmount,
+		Message:           message,
+		BudgetRemaining:   budgetAvailable,
+		FailureMode:       costResp.FailureMode,
+		DomainFactor:      costResp.DomainFactor,
+		ScriptHistory:     costResp.ScriptHistory,
+		AdvisorDivergence: costResp.AdvisorDivergence,
+		HoldGraceCredit:   graceCredit[limiting.ID],
+		Warning:           costResp.Warning,
+		BudgetUnit:        account.Unit(),
+	}
+	resp.Details.AccountBalance = budgetAvailable
+	resp.Details.CurrentHold = account.BudgetHeld
+	resp.Details.HoldPercentage = holdPercentage
+	resp.Details.HoldPercentageSource = holdSource
+	resp.Details.AdvisorConfidence = costResp.Confidence
+	return resp
+}
+
+// refusePricedJob answers a budget check refused only by the checks that need the job's
+// cost. The job is reported unavailable rather than failed, as the first failed check
+// describes; a budget shortfall also enforces the limiting account's depletion policy.
+func (s *Service) refusePricedJob(ctx context.Context, admission *admission, account *api.BudgetAccount, req *api.BudgetCheckRequest, costResp *costEstimate, holdAmount, holdPercentage float64, holdSource string, budgetAvailable float64, limiting *api.BudgetAccount, graceCredit map[int64]float64) *api.BudgetCheckResponse {
+	var resp *api.BudgetCheckResponse
+	if admission.firstCheck == "budget_available" {
+		resp = insufficientBudgetResponse(account, limiting, costResp, holdAmount, holdPercentage, holdSource, budgetAvailable, graceCredit)
+	} else {
+		resp = &api.BudgetCheckResponse{
+			Available:         false,
+			EstimatedCost:     costResp.EstimatedCost,
+			Message:           admission.first.Message,
+			BudgetRemaining:   budgetAvailable,
+			Recommendation:    costResp.Recommendation,
+			FailureMode:       costResp.FailureMode,
+			DomainFactor:      costResp.DomainFactor,
+			ScriptHistory:     costResp.ScriptHistory,
+			AdvisorDivergence: costResp.AdvisorDivergence,
+			HoldGraceCredit:   graceCredit[limiting.ID],
+			Warning:           costResp.Warning,
+			BudgetUnit:        account.Unit(),
 		}
-		return s.transactionQueries.UpdateTransactionStatus(ctx, tx, transactionID, "completed")
-	})
+		resp.Details.AccountBalance = budgetAvailable
+		resp.Details.CurrentHold = account.BudgetHeld
+		resp.Details.HoldPercentage = holdPercentage
+		resp.Details.HoldPercentageSource = holdSource
+		resp.Details.AdvisorConfidence = costResp.Confidence
+	}
+	if admission.all {
+		resp.CheckFailures = admission.failures
+	}
 
+	s.logDecision(ctx, newDecision(account, req, resp))
+	if admission.firstCheck == "budget_available" {
+		s.enforceDepletion(ctx, limiting)
+	}
+	return resp
+}
+
+// accountLocker locks account rows on the primary for the rest of a transaction
+type accountLocker interface {
+	LockAccount(ctx context.Context, tx *sql.Tx, accountID int64) (*api.BudgetAccount, error)
+}
+
+// lockChain locks an account and its ancestors and returns their current rows, in the
+// order they were given. Rows are locked in ID order, as transfers lock them, so checks
+// against overlapping chains cannot deadlock.
+func lockChain(ctx context.Context, locker accountLocker, tx *sql.Tx, account *api.BudgetAccount, ancestors []*api.BudgetAccount) (*api.BudgetAccount, []*api.BudgetAccount, error) {
+	ids := []int64{account.ID}
+	for _, ancestor := range ancestors {
+		ids = append(ids, ancestor.ID)
+	}
+	locked, err := lockAccounts(ctx, locker, tx, ids)
 	if err != nil {
-		return nil, api.NewTransactionFailedError(transactionID, err)
-	}
-
-	return &api.BudgetCheckResponse{
-		Available:       true,
-		EstimatedCost:   costResp.EstimatedCost,
-		HoldAmount:      holdAmount,
-		TransactionID:   transactionID,
-		Message:         "Budget check passed",
-		BudgetRemaining: budgetAvailable - holdAmount,
-		Recommendation:  costResp.Recommendation,
-		Details: struct {
-			AccountBalance    float64 `json:"account_balance"`
-			CurrentHold       float64 `json:"current_hold"`
-			PartitionUsed     float64 `json:"partition_used,omitempty"`
-			PartitionLimit    float64 `json:"partition_limit,omitempty"`
-			HoldPercentage    float64 `json:"hold_percentage"`
-			AdvisorConfidence float64 `json:"advisor_confidence,omitempty"`
-		}{
-			AccountBalance:    budgetAvailable,
-			CurrentHold:       account.BudgetHeld + holdAmount,
-			HoldPercentage:    s.config.DefaultHoldPercentage,
-			AdvisorConfidence: costResp.Confidence,
-		},
-	}, nil
+		return nil, nil, err
+	}
+
+	lockedAncestors := make([]*api.BudgetAccount, len(ancestors))
+	for i, ancestor := range ancestors {
+		lockedAncestors[i] = locked[ancestor.ID]
+	}
+	return locked[account.ID], lockedAncestors, nil
+}
+
+// lockAccounts locks the given account rows on the primary in ID order, so lockers of
+// overlapping sets cannot deadlock, and returns their current rows by ID
+func lockAccounts(ctx context.Context, locker accountLocker, tx *sql.Tx, ids []int64) (map[int64]*api.BudgetAccount, error) {
+	sorted := append([]int64(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	locked := make(map[int64]*api.BudgetAccount, len(sorted))
+	for _, id := range sorted {
+		if _, ok := locked[id]; ok {
+			continue
+		}
+		a, err := locker.LockAccount(ctx, tx, id)
+		if err != nil {
+			return nil, err
+		}
+		locked[id] = a
+	}
+	return locked, nil
+}
+
+// ancestorLister lists an account's ancestors
+type ancestorLister interface {
+	ListAncestors(ctx context.Context, accountID int64) ([]*api.BudgetAccount, error)
+}
+
+// chainIDs returns the IDs of the given accounts and all their ancestors, once each, for
+// locking with lockAccounts. Balance changes update an account's whole chain, so a
+// transaction changing several accounts must lock every chain before it writes.
+func chainIDs(ctx context.Context, lister ancestorLister, accountIDs ...int64) ([]int64, error) {
+	seen := make(map[int64]bool)
+	var ids []int64
+	add := func(id int64) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	for _, accountID := range accountIDs {
+		add(accountID)
+		ancestors, err := lister.ListAncestors(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestor := range ancestors {
+			add(ancestor.ID)
+		}
+	}
+	return ids, nil
+}
+
+// chainAvailable returns the smallest spendable balance across an account and its
+// ancestors, and the account that has it. A hold must fit within every one of them. Each
+// account's balance is raised by its grace credit, if it has one.
+func chainAvailable(account *api.BudgetAccount, ancestors []*api.BudgetAccount, graceCredit map[int64]float64) (float64, *api.BudgetAccount) {
+	available, limiting := account.SpendableAvailable()+graceCredit[account.ID], account
+	for _, ancestor := range ancestors {
+		if spendable := ancestor.SpendableAvailable() + graceCredit[ancestor.ID]; spendable < available {
+			available, limiting = spendable, ancestor
+		}
+	}
+	return available, limiting
+}
+
+// holdGraceCredit returns how much of each chain account's held balance a budget check
+// discounts. In GRACE mode that is the configured share of the holds placed within the
+// grace window and not yet charged or released; in STRICT mode nothing is discounted.
+func (s *Service) holdGraceCredit(ctx context.Context, account *api.BudgetAccount, ancestors []*api.BudgetAccount) (map[int64]float64, error) {
+	if s.config.HoldAvailability != holdAvailabilityGrace {
+		return nil, nil
+	}
+
+	ids := []int64{account.ID}
+	for _, ancestor := range ancestors {
+		ids = append(ids, ancestor.ID)
+	}
+	recent, err := s.transactionQueries.SumRecentHolds(ctx, ids, time.Now().Add(-s.config.HoldGraceWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return graceCredits(recent, s.config.HoldGraceDiscount), nil
+}
+
+// graceCredits scales each account's recent holds by the grace discount
+func graceCredits(recent map[int64]float64, discount float64) map[int64]float64 {
+	credits := make(map[int64]float64, len(recent))
+	for id, held := range recent {
+		credits[id] = roundCents(held * discount)
+	}
+	return credits
 }
 
 // ReconcileJob reconciles a completed job with actual costs
 func (s *Service) ReconcileJob(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.TransactionID == "" {
+		return s.reconcileUnheldJob(ctx, req)
+	}
+
+	resp, err := s.reconcileHeldJob(ctx, req)
+	if err != nil {
+		// A hold already settled needs no retrying
+		if budgetErr, ok := api.AsBudgetError(err); !ok || budgetErr.Code != api.ErrCodeHoldSettled {
+			s.recordReconciliationFailure(ctx, req.TransactionID, req.JobID, api.ReconciliationSourceReconcile, req, err)
+		}
+		return nil, err
+	}
+	s.clearReconciliationFailures(ctx, req.TransactionID)
+	return resp, nil
+}
+
+// reconcileHeldJob settles a validated request's hold with the job's actual cost
+func (s *Service) reconcileHeldJob(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
 	// Get the original hold transaction
 	holdTransaction, err := s.transactionQueries.GetTransaction(ctx, req.TransactionID)
 	if err != nil {
@@ -188,214 +667,1079 @@ func (s *Service) ReconcileJob(ctx context.Context, req *api.JobReconcileRequest
 		return nil, api.NewBudgetError(api.ErrCodeValidation, "Transaction is not a hold transaction")
 	}
 
-	// Calculate refund/additional charge
-	actualCost := req.ActualCost
-	heldAmount := holdTransaction.Amount
-	var refundAmount float64
+	// The actual cost is in dollars; the hold is in the account's unit
+	account, err := s.accountQueries.GetAccountByID(ctx, holdTransaction.AccountID)
+	if err != nil {
+		return nil, err
+	}
 
-	if actualCost < heldAmount {
-		refundAmount = heldAmount - actualCost
+	// Calculate refund/additional charge
+	actualCost := accountCost(account, req.ActualCost)
+	policy := s.failedJobPolicy(req.JobState)
+	if policy == api.FailedJobPolicyFullRefund {
+		actualCost = 0
 	}
-	// Note: additionalCharge not used in current implementation
-	// Future versions could handle cases where actual cost exceeds held amount
+	outcome := jobOutcome(req, policy)
+	var grantWarning string
+	outcome.AfterGrantEnd, grantWarning = s.flagAfterGrantEnd(ctx, holdTransaction.AccountID, req)
 
-	err = s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
-		// Create charge transaction for actual cost
-		chargeID := s.generateTransactionID()
-		chargeTransaction := &api.BudgetTransaction{
-			TransactionID: chargeID,
-			AccountID:     holdTransaction.AccountID,
-			JobID:         &req.JobID,
-			Type:          "charge",
-			Amount:        actualCost,
-			Description:   fmt.Sprintf("Actual cost for job %s", req.JobID),
-			Status:        "completed",
+	// A cost-shared job's holds are reconciled together, whichever of them was given
+	if holdTransaction.CostShareGroup != nil {
+		resp, err := s.reconcileCostShared(ctx, req, *holdTransaction.CostShareGroup, actualCost, policy, outcome)
+		if err != nil {
+			return nil, err
 		}
+		s.recordScriptCost(ctx, holdTransaction, req)
+		resp.Warning = grantWarning
+		resp.BudgetUnit = account.Unit()
+		return resp, nil
+	}
 
-		if err := s.transactionQueries.CreateTransaction(ctx, tx, chargeTransaction); err != nil {
+	heldAmount := holdTransaction.Amount
+	var refundAmount float64
+	var latency time.Duration
+	err = s.withTransactionIDRetry(ctx, func(tx *sql.Tx) error {
+		var chargeIDs []string
+		var err error
+		refundAmount, chargeIDs, latency, err = s.settleHold(ctx, tx, holdTransaction, req.JobID, actualCost, outcome)
+		if err != nil {
 			return err
 		}
 
-		// Create refund transaction if needed
-		if refundAmount > 0 {
-			refundID := s.generateTransactionID()
-			refundTransaction := &api.BudgetTransaction{
-				TransactionID: refundID,
-				AccountID:     holdTransaction.AccountID,
-				JobID:         &req.JobID,
-				Type:          "refund",
-				Amount:        refundAmount,
-				Description:   fmt.Sprintf("Refund for job %s (held: %.2f, actual: %.2f)", req.JobID, heldAmount, actualCost),
-				Status:        "completed",
-			}
-
-			if err := s.transactionQueries.CreateTransaction(ctx, tx, refundTransaction); err != nil {
-				return err
-			}
+		// The breakdown describes the whole job, so it goes on the job's first charge
+		if len(chargeIDs) > 0 && len(req.CostBreakdown) > 0 {
+			return s.transactionQueries.CreateCostComponents(ctx, tx, chargeIDs[0], req.CostBreakdown)
 		}
-
-		// Mark original hold as completed
-		return s.transactionQueries.UpdateTransactionStatus(ctx, tx, req.TransactionID, "completed")
+		return nil
 	})
 
 	if err != nil {
-		return nil, api.NewTransactionFailedError(req.TransactionID, err)
+		return nil, settleError(req.TransactionID, err)
 	}
 
+	s.recordReconciliationLatency(ctx, holdTransaction, latency)
+	s.recordScriptCost(ctx, holdTransaction, req)
+	s.refreshGrantCosts(ctx, holdTransaction.AccountID)
+
+	_, additionalCharge, _ := holdSettlement(heldAmount, actualCost)
 	return &api.JobReconcileResponse{
-		Success:       true,
-		OriginalHold:  heldAmount,
-		ActualCharge:  actualCost,
-		RefundAmount:  refundAmount,
-		TransactionID: req.TransactionID,
-		Message:       "Job reconciliation completed successfully",
+		Success:          true,
+		OriginalHold:     heldAmount,
+		ActualCharge:     actualCost,
+		AdditionalCharge: additionalCharge,
+		RefundAmount:     refundAmount,
+		TransactionID:    req.TransactionID,
+		Message:          reconcileMessage(policy),
+		FailedJobPolicy:  policy,
+		Warning:          grantWarning,
+		BudgetUnit:       account.Unit(),
 	}, nil
 }
 
-// CreateAccount creates a new budget account
-func (s *Service) CreateAccount(ctx context.Context, req *api.CreateAccountRequest) (*api.BudgetAccount, error) {
-	if err := req.Validate(); err != nil {
-		return nil, err
+// settleError reports a failure to settle a hold: a hold already settled is refused as
+// such, and anything else fails the transaction
+func settleError(transactionID string, err error) error {
+	if budgetErr, ok := api.AsBudgetError(err); ok && budgetErr.Code == api.ErrCodeHoldSettled {
+		return err
 	}
-
-	return s.accountQueries.CreateAccount(ctx, req)
-}
-
-// GetAccount retrieves a budget account by name
-func (s *Service) GetAccount(ctx context.Context, slurmAccount string) (*api.BudgetAccount, error) {
-	return s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	return api.NewTransactionFailedError(transactionID, err)
 }
 
-// ListAccounts lists budget accounts
-func (s *Service) ListAccounts(ctx context.Context, req *api.ListAccountsRequest) ([]*api.BudgetAccount, error) {
-	return s.accountQueries.ListAccounts(ctx, req)
-}
-
-// UpdateAccount updates a budget account
-func (s *Service) UpdateAccount(ctx context.Context, slurmAccount string, req *api.UpdateAccountRequest) (*api.BudgetAccount, error) {
-	return s.accountQueries.UpdateAccount(ctx, slurmAccount, req)
+// reconcileMessage describes a reconciliation under the failed job policy it applied
+func reconcileMessage(policy string) string {
+	if policy == api.FailedJobPolicyFullRefund {
+		return "Failed job refunded in full"
+	}
+	return "Job reconciliation completed successfully"
 }
 
-// DeleteAccount deletes a budget account
-func (s *Service) DeleteAccount(ctx context.Context, slurmAccount string) error {
-	return s.accountQueries.DeleteAccount(ctx, slurmAccount)
+// holdMetadata records how a job's hold was sized
+func holdMetadata(req *api.BudgetCheckRequest, costResp *costEstimate, holdPercentage float64, holdSource string) (string, error) {
+	metadata := &api.HoldMetadata{
+		Partition:            req.Partition,
+		EstimatedCost:        costResp.EstimatedCost,
+		HoldPercentage:       holdPercentage,
+		HoldPercentageSource: holdSource,
+		ResearchDomain:       req.ResearchDomain,
+		FailureMode:          costResp.FailureMode,
+		ScriptHash:           jobScriptHash(req.JobScript),
+		Nodes:                req.Nodes,
+		CPUs:                 req.CPUs,
+		GPUs:                 req.GPUs,
+	}
+	if costResp.AdvisorDivergence != nil {
+		metadata.AdvisorEstimate = costResp.AdvisorDivergence.AdvisorEstimate
+	}
+	return api.EncodeTransactionMetadata(metadata)
 }
 
-// ListTransactions lists transactions with filtering
-func (s *Service) ListTransactions(ctx context.Context, req *api.TransactionListRequest) ([]*api.BudgetTransaction, error) {
-	return s.transactionQueries.ListTransactions(ctx, req)
+// holdSettlement splits a job's actual cost against the amount held for it: the part
+// charged against the hold, the part charged beyond it and the part of the hold refunded.
+// Only the held part is charged against the hold, since a charge against a hold releases
+// its amount; anything beyond the hold is charged directly.
+func holdSettlement(heldAmount, actualCost float64) (heldCharge, additionalCharge, refundAmount float64) {
+	heldCharge = actualCost
+	if heldCharge > heldAmount {
+		heldCharge = heldAmount
+	}
+	if actualCost < heldAmount {
+		refundAmount = heldAmount - actualCost
+	}
+	return heldCharge, actualCost - heldCharge, refundAmount
 }
 
-// RecoverOrphanedTransactions recovers transactions that may have been orphaned
-func (s *Service) RecoverOrphanedTransactions(ctx context.Context) error {
-	if !s.config.AutoRecoveryEnabled {
-		return nil
+// settleHold charges a job's actual cost against its hold within tx. The held part is
+// charged against the hold, releasing it from the account and its ancestors; anything
+// beyond the hold is charged directly, and whatever the hold over-reserved is refunded.
+// Every transaction written counts against the grant budget period the hold was placed in,
+// even when the job reconciles after that period has ended. It returns the refund and the
+// charges written, the one against the hold first. A hold already settled is refused with
+// a HOLD_SETTLED error.
+func (s *Service) settleHold(ctx context.Context, tx *sql.Tx, hold *api.BudgetTransaction, jobID string, actualCost float64, outcome api.JobOutcome) (float64, []string, time.Duration, error) {
+	// A hold is settled once; reconciling it again would charge the job twice
+	unsettled, err := s.transactionQueries.LockUnsettledHold(ctx, tx, hold.TransactionID)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	if !unsettled {
+		return 0, nil, 0, api.NewHoldSettledError(hold.TransactionID)
 	}
 
-	pendingHolds, err := s.transactionQueries.GetPendingHolds(ctx, s.config.ReconciliationTimeout)
+	heldAmount := hold.Amount
+	outcome.HeldAmount = heldAmount
+	chargeMetadata, err := api.EncodeTransactionMetadata(&api.ChargeMetadata{JobOutcome: outcome})
 	if err != nil {
-		return err
+		return 0, nil, 0, err
 	}
+	heldCharge, additionalCharge, refundAmount := holdSettlement(heldAmount, actualCost)
 
-	log.Info().Int("count", len(pendingHolds)).Msg("Found orphaned hold transactions for recovery")
-
-	for _, hold := range pendingHolds {
-		// In a real implementation, you would check with SLURM if the job completed
-		// For now, we'll just log and potentially cancel very old holds
-		if time.Since(hold.CreatedAt) > s.config.ReconciliationTimeout*2 {
-			log.Warn().Str("transaction_id", hold.TransactionID).Msg("Cancelling very old orphaned hold")
-
-			err := s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
-				// Cancel the hold
-				if err := s.transactionQueries.UpdateTransactionStatus(ctx, tx, hold.TransactionID, "cancelled"); err != nil {
-					return err
-				}
-
-				// Create refund transaction
-				refundID := s.generateTransactionID()
-				refundTransaction := &api.BudgetTransaction{
-					TransactionID: refundID,
-					AccountID:     hold.AccountID,
-					Type:          "refund",
-					Amount:        hold.Amount,
-					Description:   fmt.Sprintf("Recovery refund for orphaned hold %s", hold.TransactionID),
-					Status:        "completed",
-				}
-
-				return s.transactionQueries.CreateTransaction(ctx, tx, refundTransaction)
-			})
-
-			if err != nil {
-				log.Error().Err(err).Str("transaction_id", hold.TransactionID).Msg("Failed to recover orphaned transaction")
-			}
+	var chargeIDs []string
+
+	// Create charge transaction for actual cost
+	if heldCharge > 0 {
+		chargeTransaction := &api.BudgetTransaction{
+			TransactionID: s.generateTransactionID(),
+			AccountID:     hold.AccountID,
+			JobID:         &jobID,
+			Type:          "charge",
+			Amount:        heldCharge,
+			Description:   fmt.Sprintf("Actual cost for job %s", jobID),
+			Metadata:      chargeMetadata,
+			Status:        "completed",
+			// Charging against the hold releases it from the account and its ancestors
+			ParentTransactionID: &hold.TransactionID,
+			GrantBudgetPeriodID: hold.GrantBudgetPeriodID,
 		}
-	}
 
-	return nil
-}
+		if err := s.transactionQueries.CreateTransaction(ctx, tx, chargeTransaction); err != nil {
+			return 0, nil, 0, err
+		}
+		chargeIDs = append(chargeIDs, chargeTransaction.TransactionID)
+	}
 
-// generateTransactionID generates a unique transaction ID
-func (s *Service) generateTransactionID() string {
-	return fmt.Sprintf("txn_%d_%d", time.Now().UnixNano(), time.Now().UnixMicro()%1000000)
-}
+	if additionalCharge > 0 {
+		overrunTransaction := &api.BudgetTransaction{
+			TransactionID: s.generateTransactionID(),
+			AccountID:     hold.AccountID,
+			JobID:         &jobID,
+			Type:          "charge",
+			Amount:        additionalCharge,
+			Description:   fmt.Sprintf("Cost above hold for job %s (held: %.2f, actual: %.2f)", jobID, heldAmount, actualCost),
+			Metadata:      chargeMetadata,
+			Status:        "completed",
+			// The overrun has no parent to take its period from
+			GrantBudgetPeriodID: hold.GrantBudgetPeriodID,
+		}
 
-// fallbackCostEstimate provides cost estimation when advisor service is unavailable
-func (s *Service) fallbackCostEstimate(req *api.BudgetCheckRequest) *CostEstimateResponse {
-	// Simple heuristic-based cost estimation for operational independence
-	baseCostPerCPUHour := 0.10 // $0.10/CPU-hour default
-
-	// Parse wall time (simple parsing)
-	duration := 1.0 // Default 1 hour
-	if strings.Contains(req.WallTime, ":") {
-		parts := strings.Split(req.WallTime, ":")
-		if len(parts) >= 1 {
-			if hours, err := strconv.ParseFloat(parts[0], 64); err == nil {
-				duration = hours
-				if len(parts) >= 2 {
-					if minutes, err := strconv.ParseFloat(parts[1], 64); err == nil {
-						duration += minutes / 60.0
-					}
-				}
-			}
+		if err := s.transactionQueries.CreateTransaction(ctx, tx, overrunTransaction); err != nil {
+			return 0, nil, 0, err
 		}
+		chargeIDs = append(chargeIDs, overrunTransaction.TransactionID)
 	}
 
-	// Calculate base cost
-	cpuCost := float64(req.Nodes*req.CPUs) * baseCostPerCPUHour * duration
+	// Create refund transaction if needed
+	if refundAmount > 0 {
+		refundMetadata, err := api.EncodeTransactionMetadata(&api.RefundMetadata{Reason: api.RefundReasonReconciled, JobOutcome: &outcome})
+		if err != nil {
+			return 0, nil, 0, err
+		}
+		refundTransaction := &api.BudgetTransaction{
+			TransactionID:       s.generateTransactionID(),
+			AccountID:           hold.AccountID,
+			JobID:               &jobID,
+			Type:                "refund",
+			Amount:              refundAmount,
+			Description:         fmt.Sprintf("Refund for job %s (held: %.2f, actual: %.2f)", jobID, heldAmount, actualCost),
+			Metadata:            refundMetadata,
+			Status:              "completed",
+			ParentTransactionID: &hold.TransactionID,
+			GrantBudgetPeriodID: hold.GrantBudgetPeriodID,
+		}
 
-	// GPU premium
-	gpuCost := 0.0
-	if req.GPUs > 0 {
-		gpuCost = float64(req.GPUs) * baseCostPerCPUHour * 20.0 * duration // 20x premium for GPUs
+		if err := s.transactionQueries.CreateTransaction(ctx, tx, refundTransaction); err != nil {
+			return 0, nil, 0, err
+		}
 	}
 
-	// Partition-based adjustments
-	partitionMultiplier := 1.0
-	partition := strings.ToLower(req.Partition)
-	switch {
-	case strings.Contains(partition, "gpu"):
-		partitionMultiplier = 2.0
-	case strings.Contains(partition, "aws"):
-		partitionMultiplier = 1.5
-	case strings.Contains(partition, "debug"):
-		partitionMultiplier = 0.5
+	// Mark original hold as completed
+	if err := s.transactionQueries.UpdateTransactionStatus(ctx, tx, hold.TransactionID, "completed"); err != nil {
+		return 0, nil, 0, err
 	}
 
-	totalCost := (cpuCost + gpuCost) * partitionMultiplier
+	latency, err := s.transactionQueries.MarkHoldReconciled(ctx, tx, hold.TransactionID)
+	return refundAmount, chargeIDs, latency, err
+}
 
-	// Ensure minimum cost
-	if totalCost < 0.01 {
-		totalCost = 0.01
+// reconcileUnheldJob records the cost of a job that ran below the minimum chargeable cost,
+// and so without a hold, as a single charge
+func (s *Service) reconcileUnheldJob(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
+	if req.Account == "" {
+		return nil, api.NewValidationError("transaction_id", "transaction_id is required unless account is given for a job run without a hold")
 	}
+	return s.chargeUnheldJob(ctx, req, false)
+}
 
-	return &CostEstimateResponse{
-		EstimatedCost:  totalCost,
-		Confidence:     0.6, // Moderate confidence for fallback estimates
-		Recommendation: "Fallback cost estimate - advisor service unavailable",
+// ReconcileUnreservedJob retroactively charges a job that ran without a budget check, as on
+// a partition whose prolog was misconfigured, to the account it ran under. The charge is
+// flagged unreserved, so spend that bypassed the check is recorded rather than lost.
+func (s *Service) ReconcileUnreservedJob(ctx context.Context, req *api.JobReconcileRequest) (*api.JobReconcileResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if req.Account == "" {
+		return nil, api.NewValidationError("account", "is required to charge a job run without a budget check")
+	}
+
+	resp, err := s.chargeUnheldJob(ctx, req, true)
+	if err != nil {
+		return nil, err
 	}
+	if resp.TransactionID != "" {
+		log.Warn().Str("job_id", req.JobID).Str("account", req.Account).Float64("amount", resp.ActualCharge).
+			Msg("Charged job that ran without a budget check")
+	}
+	return resp, nil
 }
 
-// HealthCheck performs a health check on the service
-func (s *Service) HealthCheck(ctx context.Context) error {
-	return s.db.HealthCheck(ctx)
+// chargeUnheldJob charges a job that has no hold its actual cost; an unreserved charge is
+// one for a job that bypassed the budget check altogether
+func (s *Service) chargeUnheldJob(ctx context.Context, req *api.JobReconcileRequest, unreserved bool) (*api.JobReconcileResponse, error) {
+	if req.ActualCost < 0 {
+		return nil, api.NewValidationError("actual_cost", "must not be negative")
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, req.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failed job refunded in full has nothing to charge
+	policy := s.failedJobPolicy(req.JobState)
+	if policy == api.FailedJobPolicyFullRefund {
+		return &api.JobReconcileResponse{
+			Success:         true,
+			Message:         "Failed job run without a hold not charged",
+			FailedJobPolicy: policy,
+			BudgetUnit:      account.Unit(),
+		}, nil
+	}
+
+	outcome := jobOutcome(req, policy)
+	var grantWarning string
+	outcome.AfterGrantEnd, grantWarning = s.flagAfterGrantEnd(ctx, account.ID, req)
+	metadata, err := api.EncodeTransactionMetadata(&api.ChargeMetadata{JobOutcome: outcome, Unreserved: unreserved})
+	if err != nil {
+		return nil, err
+	}
+	description, message := fmt.Sprintf("Cost for job %s run without a hold", req.JobID), "Job run without a hold charged"
+	if unreserved {
+		description = fmt.Sprintf("Retroactive cost for job %s run without a budget check", req.JobID)
+		message = "Job run without a budget check charged retroactively"
+	}
+	charge := &api.BudgetTransaction{
+		AccountID:   account.ID,
+		JobID:       &req.JobID,
+		Type:        "charge",
+		Amount:      accountCost(account, req.ActualCost),
+		Description: description,
+		Metadata:    metadata,
+		Status:      "completed",
+	}
+	err = s.withTransactionIDRetry(ctx, func(tx *sql.Tx) error {
+		charge.TransactionID = s.generateTransactionID()
+		if err := s.transactionQueries.CreateTransaction(ctx, tx, charge); err != nil {
+			return err
+		}
+		return s.transactionQueries.CreateCostComponents(ctx, tx, charge.TransactionID, req.CostBreakdown)
+	})
+	if err != nil {
+		return nil, api.NewTransactionFailedError(charge.TransactionID, err)
+	}
+	s.refreshGrantCosts(ctx, account.ID)
+
+	return &api.JobReconcileResponse{
+		Success:      true,
+		ActualCharge: charge.Amount,
+		// Without a hold the whole cost is charged directly
+		AdditionalCharge: charge.Amount,
+		TransactionID:    charge.TransactionID,
+		Message:          message,
+		FailedJobPolicy:  policy,
+		Warning:          grantWarning,
+		BudgetUnit:       account.Unit(),
+	}, nil
+}
+
+// CreateAccount creates a new budget account
+func (s *Service) CreateAccount(ctx context.Context, req *api.CreateAccountRequest) (*api.BudgetAccount, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateHoldPercentage(req.HoldPercentage); err != nil {
+		return nil, err
+	}
+
+	if req.ParentAccount != "" {
+		parent, err := s.accountQueries.GetAccountByName(ctx, req.ParentAccount)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkParentUnit(req.BudgetUnit, parent); err != nil {
+			return nil, err
+		}
+	}
+
+	account, err := s.accountQueries.CreateAccount(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.BurnRateEnabled {
+		s.enableBurnRateHistory(ctx, account)
+	}
+
+	return account, nil
+}
+
+// BulkCreateAccounts creates each requested account independently, continuing past
+// failures and reporting the outcome of every item
+func (s *Service) BulkCreateAccounts(ctx context.Context, reqs []*api.CreateAccountRequest) (*api.BulkCreateAccountsResponse, error) {
+	if len(reqs) == 0 {
+		return nil, api.NewValidationError("accounts", "at least one account is required")
+	}
+
+	return bulkCreateAccounts(ctx, reqs, s.CreateAccount), nil
+}
+
+// bulkCreateAccounts runs create for each request and collects the results
+func bulkCreateAccounts(ctx context.Context, reqs []*api.CreateAccountRequest,
+	create func(context.Context, *api.CreateAccountRequest) (*api.BudgetAccount, error)) *api.BulkCreateAccountsResponse {
+	resp := &api.BulkCreateAccountsResponse{
+		Total:   len(reqs),
+		Results: make([]api.BulkAccountResult, 0, len(reqs)),
+	}
+
+	for i, req := range reqs {
+		result := api.BulkAccountResult{Index: i}
+		if req == nil {
+			result.ErrorCode = api.ErrCodeValidation
+			result.Error = "account entry is empty"
+			resp.Failed++
+			resp.Results = append(resp.Results, result)
+			continue
+		}
+
+		result.SlurmAccount = req.SlurmAccount
+		account, err := create(ctx, req)
+		if err != nil {
+			result.ErrorCode = api.ErrCodeInternal
+			if budgetErr, ok := api.AsBudgetError(err); ok {
+				result.ErrorCode = budgetErr.Code
+			}
+			result.Error = err.Error()
+			resp.Failed++
+		} else {
+			result.Success = true
+			result.Account = account
+			resp.Created++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	return resp
+}
+
+// GetAccount retrieves a budget account by name
+func (s *Service) GetAccount(ctx context.Context, slurmAccount string) (*api.BudgetAccount, error) {
+	return s.accountQueries.GetAccountByName(ctx, slurmAccount)
+}
+
+// ListAccounts lists budget accounts
+func (s *Service) ListAccounts(ctx context.Context, req *api.ListAccountsRequest) ([]*api.BudgetAccount, error) {
+	return s.accountQueries.ListAccounts(ctx, req)
+}
+
+// UpdateAccount updates a budget account
+func (s *Service) UpdateAccount(ctx context.Context, slurmAccount string, req *api.UpdateAccountRequest) (*api.BudgetAccount, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.validateHoldPercentage(req.HoldPercentage); err != nil {
+		return nil, err
+	}
+
+	current, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateReserve(current, req); err != nil {
+		return nil, err
+	}
+	if req.DollarsPerSU != nil && current.Unit() != api.BudgetUnitSU {
+		return nil, api.NewValidationError("dollars_per_su", "only applies to su accounts")
+	}
+	reactivating := false
+	if req.Status != nil {
+		if err := validateStatusTransition(current, *req.Status); err != nil {
+			return nil, err
+		}
+		reactivating = *req.Status == "active" && current.Status != "active"
+	}
+
+	if req.ParentAccount != nil {
+		if err := s.setParent(ctx, current, *req.ParentAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	account, err := s.accountQueries.UpdateAccount(ctx, slurmAccount, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.BurnRateEnabled && !current.BurnRateEnabled {
+		s.enableBurnRateHistory(ctx, account)
+	}
+	if reactivating {
+		s.refreshAccountAlert(ctx, account)
+	}
+
+	return account, nil
+}
+
+// accountStatusTransitions lists the statuses each status may change to. Suspension is a
+// sanction on an account in use, so only active accounts can be suspended; expired accounts
+// are final, as are archived ones, which only an account transfer produces. New holds are
+// only placed on active accounts.
+var accountStatusTransitions = map[string][]string{
+	"active":    {"inactive", "suspended"},
+	"inactive":  {"active"},
+	"suspended": {"active", "inactive"},
+	"expired":   {},
+	"archived":  {},
+}
+
+// validateStatusTransition checks that an account may move to the requested status.
+// Reactivation is also refused once the account's end date has passed.
+func validateStatusTransition(account *api.BudgetAccount, to string) error {
+	if to == account.Status {
+		return nil
+	}
+
+	allowed := false
+	for _, next := range accountStatusTransitions[account.Status] {
+		if next == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return api.NewInvalidStatusTransitionError(account.SlurmAccount, account.Status, to)
+	}
+
+	if to == "active" && time.Now().After(account.EndDate) {
+		err := api.NewInvalidStatusTransitionError(account.SlurmAccount, account.Status, to)
+		err.Details = "account end date has passed"
+		return err
+	}
+	return nil
+}
+
+// setParent moves an account under the named parent, or detaches it when the name is empty
+func (s *Service) setParent(ctx context.Context, account *api.BudgetAccount, parentAccount string) error {
+	if parentAccount == "" {
+		return s.accountQueries.SetParent(ctx, account.ID, nil)
+	}
+
+	parent, err := s.accountQueries.GetAccountByName(ctx, parentAccount)
+	if err != nil {
+		return err
+	}
+	ancestors, err := s.accountQueries.ListAncestors(ctx, parent.ID)
+	if err != nil {
+		return err
+	}
+	if createsCycle(account, parent, ancestors) {
+		return api.NewValidationError("parent_account", "would create a cycle in the account hierarchy")
+	}
+	if err := checkParentUnit(account.Unit(), parent); err != nil {
+		return err
+	}
+
+	return s.accountQueries.SetParent(ctx, account.ID, &parent.ID)
+}
+
+// createsCycle reports whether making parent the parent of account would create a cycle,
+// which is the case when account is parent itself or one of parent's ancestors
+func createsCycle(account, parent *api.BudgetAccount, parentAncestors []*api.BudgetAccount) bool {
+	if parent.ID == account.ID {
+		return true
+	}
+	for _, ancestor := range parentAncestors {
+		if ancestor.ID == account.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// validateReserve checks that an update leaves the reserve within the budget limit
+func validateReserve(account *api.BudgetAccount, req *api.UpdateAccountRequest) error {
+	limit := account.BudgetLimit
+	if req.BudgetLimit != nil {
+		limit = *req.BudgetLimit
+	}
+	reserved := account.ReservedAmount
+	if req.ReservedAmount != nil {
+		reserved = *req.ReservedAmount
+	}
+	if reserved > limit {
+		return api.NewValidationError("reserved_amount", "must not exceed budget_limit")
+	}
+	return nil
+}
+
+// AdjustBudget applies an administrative adjustment to an account's used budget. This is
+// the only way to spend reserved funds: with FromReserve set the reserve is drawn down by
+// the same amount, otherwise a positive adjustment must fit within the spendable balance.
+func (s *Service) AdjustBudget(ctx context.Context, slurmAccount string, req *api.BudgetAdjustmentRequest) (*api.BudgetAdjustmentResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.FromReserve && req.Amount > account.SpendableAvailable() {
+		return nil, api.NewInsufficientBudgetError(slurmAccount, req.Amount, account.SpendableAvailable())
+	}
+
+	metadata, err := api.EncodeTransactionMetadata(&api.AdjustmentMetadata{FromReserve: req.FromReserve})
+	if err != nil {
+		return nil, err
+	}
+	transaction := &api.BudgetTransaction{
+		AccountID:   account.ID,
+		Type:        "adjustment",
+		Amount:      req.Amount,
+		Description: req.Description,
+		Metadata:    metadata,
+		Status:      "pending",
+	}
+
+	err = s.withTransactionIDRetry(ctx, func(tx *sql.Tx) error {
+		transaction.TransactionID = s.generateTransactionID()
+		if req.FromReserve {
+			if err := s.accountQueries.DrawReserve(ctx, tx, account.ID, req.Amount); err != nil {
+				return err
+			}
+		}
+		if err := s.transactionQueries.CreateTransaction(ctx, tx, transaction); err != nil {
+			return err
+		}
+		return s.transactionQueries.UpdateTransactionStatus(ctx, tx, transaction.TransactionID, "completed")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.BudgetAdjustmentResponse{
+		TransactionID: transaction.TransactionID,
+		Account:       updated,
+	}, nil
+}
+
+// DeleteAccount deletes a budget account
+func (s *Service) DeleteAccount(ctx context.Context, slurmAccount string) error {
+	return s.accountQueries.DeleteAccount(ctx, slurmAccount)
+}
+
+// ListTransactions lists transactions with filtering
+func (s *Service) ListTransactions(ctx context.Context, req *api.TransactionListRequest) ([]*api.BudgetTransaction, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return s.transactionQueries.ListTransactions(ctx, req)
+}
+
+// defaultTransactionPageLimit is the transaction page size when the request gives none
+const defaultTransactionPageLimit = 100
+
+// ListTransactionPage lists a page of transactions with the cursor for the next page, if
+// there is one. A page may start at an offset, for a shallow page, or at a cursor.
+func (s *Service) ListTransactionPage(ctx context.Context, req *api.TransactionListRequest) (*api.TransactionPage, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultTransactionPageLimit
+	}
+
+	// Ask for one more than a page to learn whether another page follows
+	page := *req
+	page.Limit = limit + 1
+	transactions, err := s.transactionQueries.ListTransactions(ctx, &page)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.TransactionPage{Transactions: transactions, Limit: limit}
+	if len(transactions) > limit {
+		resp.Transactions = transactions[:limit]
+		resp.NextCursor = api.NewTransactionCursor(resp.Transactions[limit-1]).Encode()
+	}
+	if resp.Transactions == nil {
+		resp.Transactions = []*api.BudgetTransaction{}
+	}
+	return resp, nil
+}
+
+// GetTransaction retrieves a transaction by its transaction ID, along with any cost breakdown
+func (s *Service) GetTransaction(ctx context.Context, transactionID string) (*api.BudgetTransaction, error) {
+	transaction, err := s.transactionQueries.GetTransaction(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	transaction.CostBreakdown, err = s.usageQueries.GetCostComponents(ctx, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// RecoverOrphanedTransactions recovers transactions that may have been orphaned
+func (s *Service) RecoverOrphanedTransactions(ctx context.Context) error {
+	if !s.config.AutoRecoveryEnabled {
+		return nil
+	}
+
+	_, err := s.RecoverOrphanedHolds(ctx)
+	return err
+}
+
+// ListOrphanedHolds lists holds awaiting reconciliation older than the reconciliation timeout
+func (s *Service) ListOrphanedHolds(ctx context.Context) (*api.OrphanedHoldsResponse, error) {
+	holds, err := s.transactionQueries.ListOrphanedHolds(ctx, s.config.ReconciliationTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.OrphanedHoldsResponse{
+		ReconciliationTimeout: s.config.ReconciliationTimeout.String(),
+		Holds:                 holds,
+	}, nil
+}
+
+// ListPendingReconciliations lists the holds still waiting for reconciliation data, flagging
+// those older than the reconciliation timeout. An empty account lists every account.
+func (s *Service) ListPendingReconciliations(ctx context.Context, slurmAccount string) (*api.PendingReconciliationResponse, error) {
+	if slurmAccount != "" {
+		if _, err := s.accountQueries.GetAccountByName(ctx, slurmAccount); err != nil {
+			return nil, err
+		}
+	}
+
+	holds, err := s.transactionQueries.ListPendingReconciliations(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.PendingReconciliationResponse{
+		Account:               slurmAccount,
+		ReconciliationTimeout: s.config.ReconciliationTimeout.String(),
+		Holds:                 holds,
+	}
+	resp.PastTimeout = flagPastTimeout(holds, time.Now(), s.config.ReconciliationTimeout)
+
+	resp.ReconciliationLatency, err = s.usageQueries.ReconciliationLatency(ctx, &api.UsageReportRequest{Account: slurmAccount}, s.config.ReconciliationSLA)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.ReconciliationSLA > 0 {
+		resp.ReconciliationSLA = s.config.ReconciliationSLA.String()
+	}
+	return resp, nil
+}
+
+// flagPastTimeout sets each hold's age and marks those older than the timeout, returning
+// how many were marked
+func flagPastTimeout(holds []*api.PendingReconciliation, now time.Time, timeout time.Duration) int {
+	count := 0
+	for _, hold := range holds {
+		age := now.Sub(hold.CreatedAt)
+		hold.AgeHours = age.Hours()
+		hold.PastTimeout = age > timeout
+		if hold.PastTimeout {
+			count++
+		}
+	}
+	return count
+}
+
+// RecoverOrphanedHolds cancels and refunds pending holds older than the expired hold
+// timeout, or charges them at their estimate under the CHARGE expired hold policy. Unlike
+// RecoverOrphanedTransactions it runs even when automatic recovery is disabled, since it
+// is only called on an operator's request. Pending holds are read a batch at a time and
+// each batch is recovered on several connections at once. With a hold expiry checker set,
+// holds whose job it has a cost for are reconciled instead.
+func (s *Service) RecoverOrphanedHolds(ctx context.Context) (*api.OrphanRecoveryResponse, error) {
+	resp, err := recoverInBatches(ctx, s.workerBatchSize(), s.workerConcurrency(),
+		func(ctx context.Context, afterID int64, limit int) ([]*api.BudgetTransaction, error) {
+			return s.transactionQueries.GetPendingHolds(ctx, s.config.ReconciliationTimeout, afterID, limit)
+		},
+		s.recoverOrphanedHold)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().Int("count", resp.Found).Int("recovered", len(resp.Recovered)).
+		Int("reconciled", len(resp.Reconciled)).Int("charged", len(resp.Charged)).Int("batches", resp.Batches).
+		Msg("Found orphaned hold transactions for recovery")
+	return resp, nil
+}
+
+// recoverInBatches pages through pending holds batchSize at a time, recovering each page
+// on up to workers holds at once before reading the next. It returns once a page comes
+// back short. Holds that fail are reported rather than stopping the run.
+func recoverInBatches(ctx context.Context, batchSize, workers int,
+	fetch func(ctx context.Context, afterID int64, limit int) ([]*api.BudgetTransaction, error),
+	recoverHold func(ctx context.Context, hold *api.BudgetTransaction) (holdOutcome, error)) (*api.OrphanRecoveryResponse, error) {
+	resp := &api.OrphanRecoveryResponse{Recovered: []string{}}
+
+	var afterID int64
+	for {
+		holds, err := fetch(ctx, afterID, batchSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(holds) == 0 {
+			break
+		}
+		resp.Found += len(holds)
+		resp.Batches++
+
+		outcomes := make([]holdOutcome, len(holds))
+		errs := runConcurrently(ctx, len(holds), workers, func(ctx context.Context, i int) error {
+			var err error
+			outcomes[i], err = recoverHold(ctx, holds[i])
+			return err
+		})
+		for i, hold := range holds {
+			if errs[i] != nil {
+				log.Error().Err(errs[i]).Str("transaction_id", hold.TransactionID).Msg("Failed to recover orphaned transaction")
+				resp.Failed = append(resp.Failed, hold.TransactionID)
+				continue
+			}
+			switch outcomes[i] {
+			case holdCancelled:
+				resp.Recovered = append(resp.Recovered, hold.TransactionID)
+			case holdReconciled:
+				resp.Reconciled = append(resp.Reconciled, hold.TransactionID)
+			case holdCharged:
+				resp.Charged = append(resp.Charged, hold.TransactionID)
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(holds) < batchSize {
+			break
+		}
+		afterID = holds[len(holds)-1].ID
+	}
+
+	return resp, nil
+}
+
+// recoverOrphanedHold recovers a pending hold once it is older than the expired hold
+// timeout. The hold is reconciled when the hold expiry checker has the job's cost, charged
+// at its estimate when its partition follows the CHARGE expired hold policy, and otherwise
+// cancelled and refunded.
+func (s *Service) recoverOrphanedHold(ctx context.Context, hold *api.BudgetTransaction) (holdOutcome, error) {
+	if time.Since(hold.CreatedAt) <= s.expiredHoldAge() {
+		return holdKept, nil
+	}
+
+	if req := s.expiringHoldReconciliation(ctx, hold); req != nil {
+		if _, err := s.ReconcileJob(ctx, req); err != nil {
+			return holdKept, err
+		}
+		log.Info().Str("transaction_id", hold.TransactionID).Str("job_id", req.JobID).
+			Float64("actual_cost", req.ActualCost).Msg("Reconciled expiring hold from late job data")
+		return holdReconciled, nil
+	}
+	if req := s.expiredHoldCharge(hold); req != nil {
+		if _, err := s.ReconcileJob(ctx, req); err != nil {
+			return holdKept, err
+		}
+		log.Info().Str("transaction_id", hold.TransactionID).Str("job_id", req.JobID).
+			Float64("estimated_cost", req.ActualCost).Msg("Charged expired hold at its estimate")
+		return holdCharged, nil
+	}
+	log.Warn().Str("transaction_id", hold.TransactionID).Msg("Cancelling very old orphaned hold")
+
+	if err := s.cancelOrphanedHold(ctx, hold); err != nil {
+		s.recordReconciliationFailure(ctx, hold.TransactionID, holdJobID(hold), api.ReconciliationSourceRecovery, nil, err)
+		return holdKept, err
+	}
+	return holdCancelled, nil
+}
+
+// cancelOrphanedHold cancels a hold and refunds its amount. Only a hold whose balance was
+// applied is held on the account, so only its refund is made against it to release it; a
+// hold never applied is refunded for the record alone.
+func (s *Service) cancelOrphanedHold(ctx context.Context, hold *api.BudgetTransaction) error {
+	metadata, err := api.EncodeTransactionMetadata(&api.RefundMetadata{Reason: api.RefundReasonRecovered})
+	if err != nil {
+		return err
+	}
+	return s.withTransactionIDRetry(ctx, func(tx *sql.Tx) error {
+		// The job may have been reconciled since the hold was found
+		unsettled, err := s.transactionQueries.LockUnsettledHold(ctx, tx, hold.TransactionID)
+		if err != nil {
+			return err
+		}
+		if !unsettled {
+			return api.NewHoldSettledError(hold.TransactionID)
+		}
+
+		// Cancel the hold
+		if err := s.transactionQueries.UpdateTransactionStatus(ctx, tx, hold.TransactionID, "cancelled"); err != nil {
+			return err
+		}
+
+		refundTransaction := &api.BudgetTransaction{
+			TransactionID: s.generateTransactionID(),
+			AccountID:     hold.AccountID,
+			Type:          "refund",
+			Amount:        hold.Amount,
+			Description:   fmt.Sprintf("Recovery refund for orphaned hold %s", hold.TransactionID),
+			Metadata:      metadata,
+			Status:        "completed",
+		}
+		if hold.Status == "completed" {
+			// A refund against the hold releases it from the account and its ancestors
+			refundTransaction.ParentTransactionID = &hold.TransactionID
+		}
+
+		return s.transactionQueries.CreateTransaction(ctx, tx, refundTransaction)
+	})
+}
+
+// minChargeableCost returns the partition's minimum chargeable cost, or the configured
+// default when the partition has no override
+func (s *Service) minChargeableCost(partition string) float64 {
+	// Partition overrides come from a config map, whose keys are lower-cased on load
+	if threshold, ok := s.config.PartitionMinChargeableCost[strings.ToLower(partition)]; ok {
+		return threshold
+	}
+	return s.config.MinChargeableCost
+}
+
+// isBelowMinChargeable reports whether a job estimated at cost runs without a hold under the
+// given threshold. A zero threshold turns the free tier off.
+func isBelowMinChargeable(cost, threshold float64) bool {
+	return threshold > 0 && cost < threshold
+}
+
+// maxSingleJobCost returns the partition's maximum single-job cost, or the configured
+// default when the partition has no override
+func (s *Service) maxSingleJobCost(partition string) float64 {
+	if limit, ok := s.config.PartitionMaxSingleJobCost[strings.ToLower(partition)]; ok {
+		return limit
+	}
+	return s.config.MaxSingleJobCost
+}
+
+// exceedsMaxSingleJobCost reports whether a job estimated at cost is over the given cap.
+// A zero cap turns the guardrail off.
+func exceedsMaxSingleJobCost(cost, limit float64) bool {
+	return limit > 0 && cost > limit
+}
+
+// holdPercentageFor returns the multiplier a job's hold is sized with, and where it came
+// from: the account's hold percentage override, then for a job requesting GPUs the
+// configured GPU hold percentage, then the configured default. Only the GPUs requested
+// make a job a GPU job, whatever its partition is called.
+func (s *Service) holdPercentageFor(account *api.BudgetAccount, req *api.BudgetCheckRequest) (float64, string) {
+	if account.HoldPercentage != nil {
+		return *account.HoldPercentage, api.HoldPercentageSourceAccount
+	}
+	if req.GPUs > 0 && s.config.GPUHoldPercentage > 0 {
+		return s.config.GPUHoldPercentage, api.HoldPercentageSourceGPU
+	}
+	return s.config.DefaultHoldPercentage, api.HoldPercentageSourceDefault
+}
+
+// validateHoldPercentage checks an account's hold percentage override, if any, against the
+// configured bounds
+func (s *Service) validateHoldPercentage(holdPercentage *float64) error {
+	if holdPercentage == nil {
+		return nil
+	}
+	if err := s.config.CheckHoldPercentage(*holdPercentage); err != nil {
+		return api.NewValidationError("hold_percentage", err.Error())
+	}
+	return nil
+}
+
+// maxTransactionIDAttempts is how many times a write is attempted when its generated
+// transaction ID collides with an existing one
+const maxTransactionIDAttempts = 3
+
+// generateTransactionID generates a random transaction ID that reveals nothing about when
+// it was issued
+func (s *Service) generateTransactionID() string {
+	return RandomID("txn_")
+}
+
+// RandomID returns prefix followed by a random (version 4) UUID, for IDs that must be
+// unguessable and reveal nothing about when they were issued
+func RandomID(prefix string) string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err) // This should never happen - crypto/rand does not fail on supported platforms
+	}
+	// Version 4 (random) UUID, RFC 4122 variant
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%s%x-%x-%x-%x-%x", prefix, b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// withTransactionIDRetry runs fn in a database transaction, rolling back and running it
+// again when a transaction ID generated inside fn collides with an existing one
+func (s *Service) withTransactionIDRetry(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	return retryOnDuplicateTransactionID(maxTransactionIDAttempts, func() error {
+		return s.db.WithTransaction(ctx, fn)
+	})
+}
+
+// retryOnDuplicateTransactionID calls fn until it succeeds, fails with an error that is not
+// retryable, or has been attempted the given number of times
+func retryOnDuplicateTransactionID(attempts int, fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil || !api.IsRetryable(err) {
+			return err
+		}
+		log.Warn().Err(err).Int("attempt", attempt).Msg("Transaction ID collision, retrying with a new ID")
+	}
+	return err
+}
+
+// defaultStaticCostRate prices CPU-hours under the static cost model when no rate is set
+const defaultStaticCostRate = 0.10
+
+// estimationSourceFor returns the cost model an account's budget checks use, empty for
+// the service default
+func estimationSourceFor(account *api.BudgetAccount) string {
+	if account == nil || account.EstimationSource == nil {
+		return ""
+	}
+	return *account.EstimationSource
+}
+
+// staticCostEstimate prices a job at a flat rate per CPU-hour, ignoring GPUs and partition
+func (s *Service) staticCostEstimate(req *api.BudgetCheckRequest) *CostEstimateResponse {
+	rate := s.staticCostRate
+	if rate <= 0 {
+		rate = defaultStaticCostRate
+	}
+
+	cost := float64(req.Nodes*req.CPUs) * rate * wallTimeHours(req.WallTime)
+	if cost < 0.01 {
+		cost = 0.01
+	}
+
+	return &CostEstimateResponse{
+		EstimatedCost:  cost,
+		Confidence:     0.5, // Low confidence for a flat rate
+		Recommendation: "Static cost estimate - account uses the static cost model",
+	}
+}
+
+// wallTimeHours converts a job's wall time to hours. Requests are validated with a
+// readable wall time, so the only error left is a wall time capped at a year, which is
+// priced at the cap.
+func wallTimeHours(wallTime string) float64 {
+	hours, _ := api.ParseWallTime(wallTime)
+	return hours
+}
+
+// fallbackCostEstimate prices a job by the fallback heuristic at the configured rates
+func (s *Service) fallbackCostEstimate(req *api.BudgetCheckRequest) *CostEstimateResponse {
+	return SimpleCostEstimate(&CostEstimateRequest{
+		Account:   req.Account,
+		Partition: req.Partition,
+		Nodes:     req.Nodes,
+		CPUs:      req.CPUs,
+		GPUs:      req.GPUs,
+		Memory:    req.Memory,
+		WallTime:  req.WallTime,
+	}, s.fallbackRates)
+}
+
+// HealthCheck performs a health check on the service
+func (s *Service) HealthCheck(ctx context.Context) error {
+	return s.db.HealthCheck(ctx)
+}
+
+// Overview reports total allocated, spent and held budget across the organization,
+// broken down by account status and funding agency
+func (s *Service) Overview(ctx context.Context, req *api.OverviewRequest) (*api.Overview, error) {
+	return s.accountQueries.Overview(ctx, req)
+}
+
+// AdminSummary reports account and hold totals with the database connection pool's state
+func (s *Service) AdminSummary(ctx context.Context) (*api.AdminSummary, error) {
+	summary, err := s.accountQueries.SummarizeAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := s.db.GetStats()
+	summary.Database = api.DatabaseStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+	}
+	return summary, nil
+}
+
+// MigrationStatus reports the database schema version and any migrations not yet applied
+func (s *Service) MigrationStatus(ctx context.Context) (*api.MigrationStatus, error) {
+	return s.db.MigrationStatus(ctx)
 }
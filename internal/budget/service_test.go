@@ -6,10 +6,13 @@ package budget
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
@@ -42,9 +45,47 @@ func TestService_GenerateTransactionID(t *testing.T) {
 	assert.Contains(t, id1, "txn_")
 	assert.Contains(t, id2, "txn_")
 
-	// Test format: txn_nanoseconds_microseconds
-	assert.True(t, len(id1) > 10)
-	assert.True(t, len(id2) > 10)
+	// Test format: txn_ followed by a version 4 UUID
+	assert.Regexp(t, `^txn_[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id1)
+	assert.Regexp(t, `^txn_[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id2)
+}
+
+func TestRetryOnDuplicateTransactionID(t *testing.T) {
+	collision := api.NewDuplicateTransactionError("txn_collided", errors.New("unique violation"))
+
+	t.Run("collision on first insert is retried", func(t *testing.T) {
+		calls := 0
+		err := retryOnDuplicateTransactionID(3, func() error {
+			calls++
+			if calls == 1 {
+				return collision
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		calls := 0
+		err := retryOnDuplicateTransactionID(3, func() error {
+			calls++
+			return collision
+		})
+		assert.True(t, api.IsRetryable(err))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		calls := 0
+		err := retryOnDuplicateTransactionID(3, func() error {
+			calls++
+			return api.NewDatabaseError("create transaction", errors.New("connection reset"))
+		})
+		assert.Error(t, err)
+		assert.False(t, api.IsRetryable(err))
+		assert.Equal(t, 1, calls)
+	})
 }
 
 func TestService_RecoverOrphanedTransactions_Disabled(t *testing.T) {
@@ -283,9 +324,11 @@ func TestService_AdvisorClientMock(t *testing.T) {
 type MockAdvisorClient struct {
 	EstimateResponse *CostEstimateResponse
 	EstimateError    error
+	Calls            int
 }
 
 func (m *MockAdvisorClient) EstimateCost(ctx context.Context, req *CostEstimateRequest) (*CostEstimateResponse, error) {
+	m.Calls++
 	if m.EstimateError != nil {
 		return nil, m.EstimateError
 	}
@@ -298,3 +341,607 @@ func (m *MockAdvisorClient) EstimateCost(ctx context.Context, req *CostEstimateR
 		Recommendation: "Default mock response",
 	}, nil
 }
+
+func TestService_HoldPercentageFor(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{DefaultHoldPercentage: 1.2, GPUHoldPercentage: 1.5}}
+
+	override := 1.05
+	cpuJob := &api.BudgetCheckRequest{Partition: "cpu", CPUs: 4}
+	gpuJob := &api.BudgetCheckRequest{Partition: "accel", CPUs: 4, GPUs: 1}
+	tests := []struct {
+		name           string
+		account        *api.BudgetAccount
+		req            *api.BudgetCheckRequest
+		expected       float64
+		expectedSource string
+	}{
+		{
+			name:           "account without override uses global default",
+			account:        &api.BudgetAccount{SlurmAccount: "explore"},
+			req:            cpuJob,
+			expected:       1.2,
+			expectedSource: api.HoldPercentageSourceDefault,
+		},
+		{
+			name:           "account override is applied",
+			account:        &api.BudgetAccount{SlurmAccount: "pipeline", HoldPercentage: &override},
+			req:            cpuJob,
+			expected:       1.05,
+			expectedSource: api.HoldPercentageSourceAccount,
+		},
+		{
+			name:           "gpu job gets the gpu buffer",
+			account:        &api.BudgetAccount{SlurmAccount: "explore"},
+			req:            gpuJob,
+			expected:       1.5,
+			expectedSource: api.HoldPercentageSourceGPU,
+		},
+		{
+			name:           "cpu job on a gpu-named partition does not",
+			account:        &api.BudgetAccount{SlurmAccount: "explore"},
+			req:            &api.BudgetCheckRequest{Partition: "gpu-a100", CPUs: 4},
+			expected:       1.2,
+			expectedSource: api.HoldPercentageSourceDefault,
+		},
+		{
+			name:           "account override wins over the gpu buffer",
+			account:        &api.BudgetAccount{SlurmAccount: "pipeline", HoldPercentage: &override},
+			req:            gpuJob,
+			expected:       1.05,
+			expectedSource: api.HoldPercentageSourceAccount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			percentage, source := service.holdPercentageFor(tt.account, tt.req)
+			assert.Equal(t, tt.expected, percentage)
+			assert.Equal(t, tt.expectedSource, source)
+		})
+	}
+
+	unset := &Service{config: &config.BudgetConfig{DefaultHoldPercentage: 1.2}}
+	percentage, source := unset.holdPercentageFor(&api.BudgetAccount{SlurmAccount: "explore"}, gpuJob)
+	assert.Equal(t, 1.2, percentage, "without a gpu buffer gpu jobs use the default")
+	assert.Equal(t, api.HoldPercentageSourceDefault, source)
+}
+
+func TestBulkCreateAccounts_PartialSuccess(t *testing.T) {
+	now := time.Now()
+	newReq := func(account string, limit float64) *api.CreateAccountRequest {
+		return &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         account,
+			BudgetLimit:  limit,
+			StartDate:    now,
+			EndDate:      now.Add(24 * time.Hour),
+		}
+	}
+
+	existing := map[string]bool{"dup": true}
+	create := func(ctx context.Context, req *api.CreateAccountRequest) (*api.BudgetAccount, error) {
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+		if existing[req.SlurmAccount] {
+			return nil, api.NewBudgetError(api.ErrCodeDuplicateAccount, "Account '"+req.SlurmAccount+"' already exists")
+		}
+		existing[req.SlurmAccount] = true
+		return &api.BudgetAccount{SlurmAccount: req.SlurmAccount, BudgetLimit: req.BudgetLimit}, nil
+	}
+
+	reqs := []*api.CreateAccountRequest{
+		newReq("ok1", 100),
+		newReq("bad", 0),
+		newReq("dup", 100),
+		nil,
+		newReq("ok2", 200),
+	}
+
+	resp := bulkCreateAccounts(context.Background(), reqs, create)
+
+	assert.Equal(t, 5, resp.Total)
+	assert.Equal(t, 2, resp.Created)
+	assert.Equal(t, 3, resp.Failed)
+	assert.Len(t, resp.Results, 5)
+
+	assert.True(t, resp.Results[0].Success)
+	assert.Equal(t, "ok1", resp.Results[0].Account.SlurmAccount)
+	assert.Equal(t, api.ErrCodeValidation, resp.Results[1].ErrorCode)
+	assert.Equal(t, api.ErrCodeDuplicateAccount, resp.Results[2].ErrorCode)
+	assert.Equal(t, "dup", resp.Results[2].SlurmAccount)
+	assert.Equal(t, api.ErrCodeValidation, resp.Results[3].ErrorCode)
+	assert.True(t, resp.Results[4].Success, "items after failures must still be processed")
+	assert.Equal(t, 4, resp.Results[4].Index)
+}
+
+func TestService_BulkCreateAccounts_Empty(t *testing.T) {
+	service := &Service{}
+
+	resp, err := service.BulkCreateAccounts(context.Background(), nil)
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+}
+
+func TestValidateReserve(t *testing.T) {
+	account := &api.BudgetAccount{BudgetLimit: 1000.0, ReservedAmount: 200.0}
+	reserve := func(v float64) *float64 { return &v }
+
+	assert.NoError(t, validateReserve(account, &api.UpdateAccountRequest{ReservedAmount: reserve(1000.0)}))
+	assert.Error(t, validateReserve(account, &api.UpdateAccountRequest{ReservedAmount: reserve(1000.01)}))
+	assert.Error(t, validateReserve(account, &api.UpdateAccountRequest{BudgetLimit: reserve(150.0)}))
+	assert.NoError(t, validateReserve(account, &api.UpdateAccountRequest{BudgetLimit: reserve(150.0), ReservedAmount: reserve(0)}))
+}
+
+func TestValidateStatusTransition(t *testing.T) {
+	statuses := []string{"active", "inactive", "suspended", "expired", "archived"}
+	allowed := map[string]map[string]bool{
+		"active":    {"active": true, "inactive": true, "suspended": true},
+		"inactive":  {"inactive": true, "active": true},
+		"suspended": {"suspended": true, "active": true, "inactive": true},
+		"expired":   {"expired": true},
+		"archived":  {"archived": true},
+	}
+
+	for _, from := range statuses {
+		for _, to := range statuses {
+			t.Run(from+" to "+to, func(t *testing.T) {
+				account := &api.BudgetAccount{
+					SlurmAccount: "proj001",
+					Status:       from,
+					EndDate:      time.Now().AddDate(0, 1, 0),
+				}
+
+				err := validateStatusTransition(account, to)
+				if allowed[from][to] {
+					assert.NoError(t, err)
+					return
+				}
+				budgetErr, ok := api.AsBudgetError(err)
+				require.True(t, ok)
+				assert.Equal(t, api.ErrCodeInvalidStatusTransition, budgetErr.Code)
+			})
+		}
+	}
+
+	t.Run("reactivation after end date", func(t *testing.T) {
+		account := &api.BudgetAccount{
+			SlurmAccount: "proj001",
+			Status:       "suspended",
+			EndDate:      time.Now().AddDate(0, 0, -1),
+		}
+
+		budgetErr, ok := api.AsBudgetError(validateStatusTransition(account, "active"))
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeInvalidStatusTransition, budgetErr.Code)
+		assert.NoError(t, validateStatusTransition(account, "inactive"))
+	})
+}
+
+func TestFlagPastTimeout(t *testing.T) {
+	now := time.Now()
+	holds := []*api.PendingReconciliation{
+		{TransactionID: "txn_stale", CreatedAt: now.Add(-30 * time.Hour)},
+		{TransactionID: "txn_fresh", CreatedAt: now.Add(-time.Hour)},
+		{TransactionID: "txn_just_stale", CreatedAt: now.Add(-25 * time.Hour)},
+	}
+
+	assert.Equal(t, 2, flagPastTimeout(holds, now, 24*time.Hour))
+	assert.True(t, holds[0].PastTimeout)
+	assert.InDelta(t, 30, holds[0].AgeHours, 0.001)
+	assert.False(t, holds[1].PastTimeout)
+	assert.InDelta(t, 1, holds[1].AgeHours, 0.001)
+	assert.True(t, holds[2].PastTimeout)
+}
+
+func TestHoldSettlement(t *testing.T) {
+	tests := []struct {
+		name                                 string
+		held, actual                         float64
+		heldCharge, additionalCharge, refund float64
+	}{
+		{"under the hold", 12.0, 10.0, 10.0, 0, 2.0},
+		{"exactly the hold", 12.0, 12.0, 12.0, 0, 0},
+		{"over the hold", 12.0, 15.0, 12.0, 3.0, 0},
+		{"nothing charged", 12.0, 0, 0, 0, 12.0},
+		{"nothing held", 0, 5.0, 0, 5.0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			heldCharge, additionalCharge, refund := holdSettlement(tt.held, tt.actual)
+			assert.InDelta(t, tt.heldCharge, heldCharge, 0.0001)
+			assert.InDelta(t, tt.additionalCharge, additionalCharge, 0.0001)
+			assert.InDelta(t, tt.refund, refund, 0.0001)
+		})
+	}
+}
+
+func TestCheckAcceptsHolds(t *testing.T) {
+	now := time.Now()
+	account := func(name, status string, frozen bool) *api.BudgetAccount {
+		return &api.BudgetAccount{
+			SlurmAccount: name,
+			Status:       status,
+			Frozen:       frozen,
+			StartDate:    now.AddDate(0, -1, 0),
+			EndDate:      now.AddDate(0, 1, 0),
+		}
+	}
+	code := func(err error) api.ErrorCode {
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		return budgetErr.Code
+	}
+
+	assert.NoError(t, checkAcceptsHolds(account("proj", "active", false), nil))
+	assert.NoError(t, checkAcceptsHolds(account("proj", "active", false), []*api.BudgetAccount{account("dept", "active", false)}))
+	assert.Equal(t, api.ErrCodeAccountFrozen, code(checkAcceptsHolds(account("proj", "active", true), nil)))
+	assert.Equal(t, api.ErrCodeAccountInactive, code(checkAcceptsHolds(account("proj", "suspended", false), nil)))
+	// Suspension takes precedence over a freeze on the same account
+	assert.Equal(t, api.ErrCodeAccountInactive, code(checkAcceptsHolds(account("proj", "suspended", true), nil)))
+
+	err := checkAcceptsHolds(account("proj", "active", false), []*api.BudgetAccount{account("dept", "active", true)})
+	assert.Equal(t, api.ErrCodeAccountFrozen, code(err))
+	assert.Contains(t, err.Error(), "dept")
+}
+
+func TestChainAvailable(t *testing.T) {
+	child := &api.BudgetAccount{ID: 3, SlurmAccount: "proj-a", BudgetLimit: 500.0, BudgetUsed: 100.0}
+	department := &api.BudgetAccount{ID: 2, SlurmAccount: "dept", BudgetLimit: 1000.0, BudgetUsed: 850.0, ReservedAmount: 50.0}
+	college := &api.BudgetAccount{ID: 1, SlurmAccount: "college", BudgetLimit: 10000.0, BudgetUsed: 2000.0}
+
+	available, limiting := chainAvailable(child, nil, nil)
+	assert.InDelta(t, 400.0, available, 0.001)
+	assert.Equal(t, child, limiting)
+
+	available, limiting = chainAvailable(child, []*api.BudgetAccount{department, college}, nil)
+	assert.InDelta(t, 100.0, available, 0.001)
+	assert.Equal(t, department, limiting)
+}
+
+func TestChainAvailable_GraceCredit(t *testing.T) {
+	child := &api.BudgetAccount{ID: 3, SlurmAccount: "proj-a", BudgetLimit: 500.0, BudgetHeld: 300.0}
+	department := &api.BudgetAccount{ID: 2, SlurmAccount: "dept", BudgetLimit: 1000.0, BudgetUsed: 650.0, BudgetHeld: 300.0}
+
+	// Half of the child's recent holds come back, which leaves the department limiting
+	credit := graceCredits(map[int64]float64{3: 300.0, 2: 300.0}, 0.5)
+	available, limiting := chainAvailable(child, []*api.BudgetAccount{department}, credit)
+	assert.InDelta(t, 200.0, available, 0.001)
+	assert.Equal(t, department, limiting)
+
+	available, limiting = chainAvailable(child, nil, credit)
+	assert.InDelta(t, 350.0, available, 0.001)
+	assert.Equal(t, child, limiting)
+}
+
+// writerLocker stands in for the primary, serving rows fresher than an earlier stale read
+type writerLocker struct {
+	rows   map[int64]*api.BudgetAccount
+	tx     *sql.Tx
+	locked []int64
+}
+
+func (w *writerLocker) LockAccount(ctx context.Context, tx *sql.Tx, accountID int64) (*api.BudgetAccount, error) {
+	if tx != w.tx {
+		return nil, errors.New("lock taken outside the hold transaction")
+	}
+	w.locked = append(w.locked, accountID)
+	return w.rows[accountID], nil
+}
+
+func TestLockChain_DecidesOnWriterRows(t *testing.T) {
+	// A stale read of the chain still shows room for a $100 hold...
+	child := &api.BudgetAccount{ID: 3, SlurmAccount: "proj-a", BudgetLimit: 500.0}
+	department := &api.BudgetAccount{ID: 2, SlurmAccount: "dept", BudgetLimit: 1000.0, BudgetUsed: 700.0}
+	available, _ := chainAvailable(child, []*api.BudgetAccount{department}, nil)
+	require.GreaterOrEqual(t, available, 100.0)
+
+	// ...but holds placed since then have used up the department on the primary
+	tx := &sql.Tx{}
+	writer := &writerLocker{tx: tx, rows: map[int64]*api.BudgetAccount{
+		3: {ID: 3, SlurmAccount: "proj-a", BudgetLimit: 500.0, BudgetHeld: 250.0},
+		2: {ID: 2, SlurmAccount: "dept", BudgetLimit: 1000.0, BudgetUsed: 700.0, BudgetHeld: 250.0},
+	}}
+
+	locked, lockedAncestors, err := lockChain(context.Background(), writer, tx, child, []*api.BudgetAccount{department})
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, writer.locked, "rows are locked in ID order")
+	assert.Same(t, writer.rows[3], locked)
+	assert.Equal(t, []*api.BudgetAccount{writer.rows[2]}, lockedAncestors)
+
+	available, limiting := chainAvailable(locked, lockedAncestors, nil)
+	assert.InDelta(t, 50.0, available, 0.001)
+	assert.Equal(t, "dept", limiting.SlurmAccount)
+}
+
+func TestLockChain_Error(t *testing.T) {
+	writer := &writerLocker{tx: &sql.Tx{}}
+	_, _, err := lockChain(context.Background(), writer, nil, &api.BudgetAccount{ID: 1}, nil)
+	assert.Error(t, err)
+}
+
+// ancestorTree serves ancestors from parent links
+type ancestorTree map[int64]int64
+
+func (tree ancestorTree) ListAncestors(ctx context.Context, accountID int64) ([]*api.BudgetAccount, error) {
+	if accountID < 0 {
+		return nil, errors.New("account not found")
+	}
+	var ancestors []*api.BudgetAccount
+	for parent, ok := tree[accountID]; ok; parent, ok = tree[parent] {
+		ancestors = append(ancestors, &api.BudgetAccount{ID: parent})
+	}
+	return ancestors, nil
+}
+
+func TestChainIDs(t *testing.T) {
+	// 5 and 4 share department 2 under institution 1; 7 stands alone
+	tree := ancestorTree{5: 2, 4: 2, 2: 1}
+
+	ids, err := chainIDs(context.Background(), tree, 5, 4, 7)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{5, 2, 1, 4, 7}, ids, "shared ancestors are listed once")
+
+	// Two reconciliations of the same accounts in opposite orders lock the same rows in
+	// the same order
+	reversed, err := chainIDs(context.Background(), tree, 7, 4, 5)
+	require.NoError(t, err)
+	forward, backward := &writerLocker{}, &writerLocker{}
+	_, err = lockAccounts(context.Background(), forward, nil, ids)
+	require.NoError(t, err)
+	_, err = lockAccounts(context.Background(), backward, nil, reversed)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 2, 4, 5, 7}, forward.locked)
+	assert.Equal(t, forward.locked, backward.locked)
+
+	_, err = chainIDs(context.Background(), tree, 5, -1)
+	assert.Error(t, err)
+}
+
+func TestListTransactionPage_ValidatesBeforeQuerying(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{}}
+
+	_, err := service.ListTransactionPage(context.Background(), &api.TransactionListRequest{Cursor: "garbage"})
+	budgetErr, ok := api.AsBudgetError(err)
+	require.True(t, ok)
+	assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+
+	_, err = service.ListTransactions(context.Background(), &api.TransactionListRequest{Limit: api.MaxTransactionListLimit + 1})
+	assert.Error(t, err)
+}
+
+func TestHoldAvailability_Burst(t *testing.T) {
+	// A job array submits 20 jobs at once, each needing a $12 hold against a $100 budget
+	burst := func(graceDiscount float64) int {
+		account := &api.BudgetAccount{ID: 1, SlurmAccount: "burst", BudgetLimit: 100.0}
+		approved := 0
+		for i := 0; i < 20; i++ {
+			var credit map[int64]float64
+			if graceDiscount > 0 {
+				credit = graceCredits(map[int64]float64{1: account.BudgetHeld}, graceDiscount)
+			}
+			if available, _ := chainAvailable(account, nil, credit); 12.0 > available {
+				continue
+			}
+			account.BudgetHeld += 12.0
+			approved++
+		}
+		return approved
+	}
+
+	assert.Equal(t, 8, burst(0), "STRICT counts every hold in full")
+	assert.Equal(t, 15, burst(0.5), "GRACE counts recent holds at half")
+}
+
+func TestHoldGraceCredit_Strict(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{}}
+	credit, err := service.holdGraceCredit(context.Background(), &api.BudgetAccount{ID: 1}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, credit)
+}
+
+func TestCreatesCycle(t *testing.T) {
+	root := &api.BudgetAccount{ID: 1}
+	middle := &api.BudgetAccount{ID: 2}
+	leaf := &api.BudgetAccount{ID: 3}
+	other := &api.BudgetAccount{ID: 4}
+
+	assert.True(t, createsCycle(root, root, nil))
+	assert.True(t, createsCycle(root, leaf, []*api.BudgetAccount{middle, root}))
+	assert.False(t, createsCycle(leaf, middle, []*api.BudgetAccount{root}))
+	assert.False(t, createsCycle(other, leaf, []*api.BudgetAccount{middle, root}))
+}
+
+func TestService_EstimateCost_FailureModes(t *testing.T) {
+	req := &api.BudgetCheckRequest{
+		Account:   "test-account",
+		Partition: "cpu",
+		Nodes:     1,
+		CPUs:      4,
+		WallTime:  "02:00:00",
+	}
+	failing := &MockAdvisorClient{EstimateError: api.NewServiceUnavailableError("advisor", assert.AnError)}
+
+	t.Run("advisor available", func(t *testing.T) {
+		service := &Service{advisorClient: &MockAdvisorClient{}, failureMode: failureModeStrict}
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, 10.0, estimate.EstimatedCost)
+		assert.Empty(t, estimate.FailureMode)
+		assert.False(t, estimate.NoHold)
+	})
+
+	t.Run("strict rejects", func(t *testing.T) {
+		service := &Service{advisorClient: failing, failureMode: failureModeStrict}
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.Error(t, err)
+		assert.Nil(t, estimate)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAdvisorUnavailable, budgetErr.Code)
+	})
+
+	for _, mode := range []string{failureModeGraceful, ""} {
+		t.Run("graceful uses fallback estimate "+mode, func(t *testing.T) {
+			service := &Service{advisorClient: failing, failureMode: mode}
+			estimate, err := service.estimateCost(context.Background(), req, "")
+			require.NoError(t, err)
+			assert.Equal(t, failureModeGraceful, estimate.FailureMode)
+			assert.Greater(t, estimate.EstimatedCost, 0.0)
+			assert.False(t, estimate.NoHold)
+			assert.NotEmpty(t, estimate.Warning)
+		})
+	}
+
+	t.Run("permissive approves without hold", func(t *testing.T) {
+		service := &Service{advisorClient: failing, failureMode: failureModePermissive}
+		estimate, err := service.estimateCost(context.Background(), req, "")
+		require.NoError(t, err)
+		assert.Equal(t, failureModePermissive, estimate.FailureMode)
+		assert.True(t, estimate.NoHold)
+		assert.NotEmpty(t, estimate.Warning)
+	})
+}
+
+func TestService_EstimateCost_EstimationSource(t *testing.T) {
+	req := &api.BudgetCheckRequest{
+		Account:   "test-account",
+		Partition: "gpu",
+		Nodes:     2,
+		CPUs:      4,
+		GPUs:      1,
+		WallTime:  "03:30:00",
+	}
+
+	t.Run("fallback bypasses a healthy advisor", func(t *testing.T) {
+		advisor := &MockAdvisorClient{}
+		service := &Service{advisorClient: advisor, failureMode: failureModeStrict}
+		estimate, err := service.estimateCost(context.Background(), req, api.EstimationSourceFallback)
+		require.NoError(t, err)
+		assert.Zero(t, advisor.Calls)
+		assert.Equal(t, service.fallbackCostEstimate(req).EstimatedCost, estimate.EstimatedCost)
+		assert.Empty(t, estimate.FailureMode)
+		assert.Empty(t, estimate.Warning)
+		assert.False(t, estimate.NoHold)
+	})
+
+	t.Run("static prices CPU-hours at the configured rate", func(t *testing.T) {
+		advisor := &MockAdvisorClient{}
+		service := &Service{advisorClient: advisor, staticCostRate: 0.20}
+		estimate, err := service.estimateCost(context.Background(), req, api.EstimationSourceStatic)
+		require.NoError(t, err)
+		assert.Zero(t, advisor.Calls)
+		assert.InDelta(t, 2*4*0.20*3.5, estimate.EstimatedCost, 0.0001)
+	})
+
+	t.Run("static defaults its rate", func(t *testing.T) {
+		service := &Service{advisorClient: &MockAdvisorClient{}}
+		estimate, err := service.estimateCost(context.Background(), req, api.EstimationSourceStatic)
+		require.NoError(t, err)
+		assert.InDelta(t, 2*4*defaultStaticCostRate*3.5, estimate.EstimatedCost, 0.0001)
+	})
+
+	for _, source := range []string{api.EstimationSourceAdvisor, ""} {
+		t.Run("advisor source asks the advisor "+source, func(t *testing.T) {
+			advisor := &MockAdvisorClient{}
+			service := &Service{advisorClient: advisor}
+			estimate, err := service.estimateCost(context.Background(), req, source)
+			require.NoError(t, err)
+			assert.Equal(t, 1, advisor.Calls)
+			assert.Equal(t, 10.0, estimate.EstimatedCost)
+		})
+	}
+}
+
+func TestEstimationSourceFor(t *testing.T) {
+	static := api.EstimationSourceStatic
+	assert.Empty(t, estimationSourceFor(nil))
+	assert.Empty(t, estimationSourceFor(&api.BudgetAccount{}))
+	assert.Equal(t, static, estimationSourceFor(&api.BudgetAccount{EstimationSource: &static}))
+}
+
+func TestService_MinChargeableCost(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{
+		MinChargeableCost:          0.05,
+		PartitionMinChargeableCost: map[string]float64{"debug": 1000, "gpu": 0},
+	}}
+
+	tests := []struct {
+		partition string
+		cost      float64
+		free      bool
+	}{
+		{"cpu", 0.01, true},
+		{"cpu", 0.05, false},
+		{"cpu", 2.50, false},
+		{"debug", 250.0, true},
+		{"DEBUG", 250.0, true},
+		{"gpu", 0.01, false},
+	}
+
+	for _, tt := range tests {
+		threshold := service.minChargeableCost(tt.partition)
+		assert.Equal(t, tt.free, isBelowMinChargeable(tt.cost, threshold), "%s at %.2f", tt.partition, tt.cost)
+	}
+
+	disabled := &Service{config: &config.BudgetConfig{}}
+	assert.False(t, isBelowMinChargeable(0.0001, disabled.minChargeableCost("cpu")))
+}
+
+func TestService_MaxSingleJobCost(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{
+		MaxSingleJobCost:          500,
+		PartitionMaxSingleJobCost: map[string]float64{"gpu": 5000, "debug": 0},
+	}}
+
+	tests := []struct {
+		partition string
+		cost      float64
+		rejected  bool
+	}{
+		{"cpu", 499.99, false},
+		{"cpu", 500, false},
+		{"cpu", 500.01, true},
+		{"gpu", 4999.99, false},
+		{"GPU", 5000.01, true},
+		{"debug", 1000000, false},
+	}
+
+	for _, tt := range tests {
+		limit := service.maxSingleJobCost(tt.partition)
+		assert.Equal(t, tt.rejected, exceedsMaxSingleJobCost(tt.cost, limit), "%s at %.2f", tt.partition, tt.cost)
+	}
+
+	disabled := &Service{config: &config.BudgetConfig{}}
+	assert.False(t, exceedsMaxSingleJobCost(1e9, disabled.maxSingleJobCost("cpu")))
+}
+
+func TestAccountHoldPercentageBounds(t *testing.T) {
+	service := &Service{config: &config.BudgetConfig{MinHoldPercentage: 1.0, MaxHoldPercentage: 3.0}}
+	ctx := context.Background()
+
+	for _, holdPercentage := range []float64{12.0, 0.01} {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount:   "lab",
+			Name:           "Lab",
+			BudgetLimit:    1000.0,
+			StartDate:      time.Now(),
+			EndDate:        time.Now().AddDate(1, 0, 0),
+			HoldPercentage: &holdPercentage,
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "create with %.2f", holdPercentage)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		assert.Equal(t, "hold_percentage", budgetErr.Field)
+
+		_, err = service.UpdateAccount(ctx, "lab", &api.UpdateAccountRequest{HoldPercentage: &holdPercentage})
+		budgetErr, ok = api.AsBudgetError(err)
+		require.True(t, ok, "update with %.2f", holdPercentage)
+		assert.Equal(t, "hold_percentage", budgetErr.Field)
+	}
+
+	assert.NoError(t, service.validateHoldPercentage(nil))
+	inRange := 1.05
+	assert.NoError(t, service.validateHoldPercentage(&inRange))
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const (
+	// defaultSimulationPeriodDays is how long simulated jobs take to run when not specified
+	defaultSimulationPeriodDays = 7
+	// simulationBurnWindowDays is how much recent history sets the current burn rate
+	simulationBurnWindowDays = 30
+)
+
+// SimulateBudget projects where an account's budget lands if a set of hypothetical jobs
+// runs over the coming period, on top of its recent spending. Nothing is written.
+func (s *Service) SimulateBudget(ctx context.Context, slurmAccount string, req *api.SimulationRequest) (*api.SimulationResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	periodDays := req.PeriodDays
+	if periodDays == 0 {
+		periodDays = defaultSimulationPeriodDays
+	}
+	deadline := account.EndDate
+	if req.Deadline != nil {
+		deadline = *req.Deadline
+	}
+
+	resp := &api.SimulationResponse{
+		Account:          account.SlurmAccount,
+		Jobs:             make([]api.SimulatedJobCost, 0, len(req.Jobs)),
+		CurrentAvailable: account.SpendableAvailable(),
+		PeriodDays:       periodDays,
+		Deadline:         deadline,
+	}
+
+	for _, job := range req.Jobs {
+		estimate, err := s.estimateCost(ctx, &api.BudgetCheckRequest{
+			Account:   account.SlurmAccount,
+			Partition: job.Partition,
+			Nodes:     job.Nodes,
+			CPUs:      job.CPUs,
+			GPUs:      job.GPUs,
+			Memory:    job.Memory,
+			WallTime:  job.WallTime,
+		}, estimationSourceFor(account))
+		if err != nil {
+			return nil, err
+		}
+		if estimate.FailureMode != "" {
+			resp.FailureMode = estimate.FailureMode
+		}
+
+		cost := api.SimulatedJobCost{
+			SimulatedJob: job,
+			UnitCost:     accountCost(account, estimate.EstimatedCost),
+			TotalCost:    accountCost(account, estimate.EstimatedCost) * float64(job.Count),
+		}
+		resp.Jobs = append(resp.Jobs, cost)
+		resp.SimulatedCost += cost.TotalCost
+	}
+
+	now := time.Now()
+	resp.CurrentDailyBurnRate, err = s.recentBurnRate(ctx, account, now)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.ProjectedAvailable = resp.CurrentAvailable - resp.SimulatedCost
+	resp.ProjectedDailyBurnRate = resp.CurrentDailyBurnRate + resp.SimulatedCost/float64(periodDays)
+	resp.ProjectedDepletionDate = projectDepletion(now, resp.CurrentAvailable, resp.CurrentDailyBurnRate, resp.SimulatedCost, periodDays)
+	resp.DepletesBeforeDeadline = resp.ProjectedDepletionDate != nil && resp.ProjectedDepletionDate.Before(deadline)
+
+	return resp, nil
+}
+
+// recentBurnRate returns the account's average daily spend over the burn window, or over
+// its lifetime when that is shorter
+func (s *Service) recentBurnRate(ctx context.Context, account *api.BudgetAccount, now time.Time) (float64, error) {
+	today := truncateToDay(now)
+	windowStart := today.AddDate(0, 0, -(simulationBurnWindowDays - 1))
+	if start := truncateToDay(account.StartDate); start.After(windowStart) {
+		windowStart = start
+	}
+	if windowStart.After(today) {
+		return 0, nil
+	}
+
+	opening, err := s.snapshotForAccount(ctx, account, windowStart.AddDate(0, 0, -1))
+	if err != nil {
+		return 0, err
+	}
+
+	days := today.Sub(windowStart).Hours()/24 + 1
+	return nonNegative(account.BudgetUsed-opening.BudgetUsed) / days, nil
+}
+
+// projectDepletion returns when the available budget runs out if the simulated cost is
+// spent evenly over periodDays on top of the base daily rate, after which spending returns
+// to the base rate. It returns nil when the budget is never exhausted.
+func projectDepletion(now time.Time, available, baseRate, simulatedCost float64, periodDays int) *time.Time {
+	if available <= 0 {
+		return &now
+	}
+
+	days := float64(periodDays)
+	periodRate := baseRate + simulatedCost/days
+	var depletionDays float64
+	switch {
+	case periodRate > 0 && available <= periodRate*days:
+		depletionDays = available / periodRate
+	case baseRate > 0:
+		depletionDays = days + (available-periodRate*days)/baseRate
+	default:
+		return nil
+	}
+
+	depletion := now.Add(time.Duration(depletionDays * 24 * float64(time.Hour)))
+	return &depletion
+}
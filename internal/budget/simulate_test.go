@@ -0,0 +1,89 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectDepletion(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name          string
+		available     float64
+		baseRate      float64
+		simulatedCost float64
+		periodDays    int
+		expected      *time.Duration
+	}{
+		{
+			// $10/day with no extra jobs lasts 100 days
+			name:       "base rate only",
+			available:  1000.0,
+			baseRate:   10.0,
+			periodDays: 7,
+			expected:   durationPtr(100 * day),
+		},
+		{
+			// $30/day for the week spends $210, leaving $790 at $10/day
+			name:          "jobs speed up depletion after the period",
+			available:     1000.0,
+			baseRate:      10.0,
+			simulatedCost: 140.0,
+			periodDays:    7,
+			expected:      durationPtr(86 * day),
+		},
+		{
+			// $150/day runs out of $600 on day four
+			name:          "depletes during the simulated period",
+			available:     600.0,
+			baseRate:      10.0,
+			simulatedCost: 980.0,
+			periodDays:    7,
+			expected:      durationPtr(4 * day),
+		},
+		{
+			name:          "no spending never depletes",
+			available:     1000.0,
+			simulatedCost: 0,
+			periodDays:    7,
+		},
+		{
+			name:          "jobs alone that fit never deplete",
+			available:     1000.0,
+			simulatedCost: 500.0,
+			periodDays:    7,
+		},
+		{
+			name:       "already exhausted",
+			available:  0,
+			baseRate:   10.0,
+			periodDays: 7,
+			expected:   durationPtr(0),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			depletion := projectDepletion(now, tt.available, tt.baseRate, tt.simulatedCost, tt.periodDays)
+			if tt.expected == nil {
+				assert.Nil(t, depletion)
+				return
+			}
+			require.NotNil(t, depletion)
+			assert.WithinDuration(t, now.Add(*tt.expected), *depletion, time.Minute)
+		})
+	}
+}
+
+func durationPtr(d time.Duration) *time.Duration {
+	return &d
+}
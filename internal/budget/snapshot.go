@@ -0,0 +1,307 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// CaptureDailySnapshots records end-of-day balances for every account
+func (s *Service) CaptureDailySnapshots(ctx context.Context, date time.Time) error {
+	count, err := s.snapshotQueries.CaptureSnapshots(ctx, truncateToDay(date))
+	if err != nil {
+		return err
+	}
+
+	log.Info().Int("accounts", count).Str("date", truncateToDay(date).Format("2006-01-02")).Msg("Captured budget snapshots")
+	return nil
+}
+
+// GetSnapshot returns an account's balances as of the end of the given date. A stored
+// snapshot for that date is returned as-is; otherwise the ledger is replayed forward from
+// the nearest earlier snapshot, or from account creation when none exists, and given the
+// limit in force at the end of that date.
+func (s *Service) GetSnapshot(ctx context.Context, slurmAccount string, date time.Time) (*api.BudgetSnapshot, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.snapshotForAccount(ctx, account, date)
+}
+
+// snapshotForAccount resolves the end-of-day snapshot for an already loaded account
+func (s *Service) snapshotForAccount(ctx context.Context, account *api.BudgetAccount, date time.Time) (*api.BudgetSnapshot, error) {
+	day := truncateToDay(date)
+	endOfDay := day.AddDate(0, 0, 1)
+
+	base, err := s.snapshotQueries.GetLatestSnapshot(ctx, account.ID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	if base != nil && base.SnapshotDate.Equal(day) {
+		return base, nil
+	}
+
+	if base == nil {
+		// No snapshot yet - start from the account's opening limit, before any allocations
+		base = &api.BudgetSnapshot{
+			AccountID:   account.ID,
+			BudgetLimit: account.BudgetLimit - account.TotalAllocated,
+			CapturedAt:  account.CreatedAt.Add(-time.Nanosecond),
+		}
+	}
+
+	entries, err := s.snapshotQueries.ListLedgerEntries(ctx, nil, account.ID, base.CapturedAt, endOfDay)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := replayLedger(base, entries)
+	snapshot.SnapshotDate = day
+	snapshot.CapturedAt = endOfDay
+
+	// The ledger only knows limits raised by allocations; the limit history also has those
+	// set by hand, so it decides the limit wherever it reaches back far enough
+	limit, err := s.snapshotQueries.GetLimitAt(ctx, account.ID, endOfDay)
+	if err != nil {
+		return nil, err
+	}
+	if limit != nil {
+		applyLimit(snapshot, *limit)
+	}
+	return snapshot, nil
+}
+
+// applyLimit sets a replayed snapshot's limit to the one in force at its date
+func applyLimit(snapshot *api.BudgetSnapshot, limit float64) {
+	snapshot.BudgetLimit = limit
+	snapshot.BudgetAvailable = limit - snapshot.BudgetUsed - snapshot.BudgetHeld
+}
+
+// GetLimitHistory returns the changes to an account's budget limit, oldest first
+func (s *Service) GetLimitHistory(ctx context.Context, slurmAccount string) ([]*api.BudgetLimitChange, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, slurmAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.snapshotQueries.ListLimitChanges(ctx, account.ID)
+}
+
+// replayLedger applies ledger entries to a starting snapshot using the same rules as the
+// update_account_balance trigger, producing the resulting balances. Entries rolled up from
+// descendants move used and held amounts but never the limit, which is per account.
+func replayLedger(base *api.BudgetSnapshot, entries []*database.LedgerEntry) *api.BudgetSnapshot {
+	result := &api.BudgetSnapshot{
+		AccountID:   base.AccountID,
+		BudgetLimit: base.BudgetLimit,
+		BudgetUsed:  base.BudgetUsed,
+		BudgetHeld:  base.BudgetHeld,
+		Source:      "replay",
+	}
+
+	for _, entry := range entries {
+		switch entry.Type {
+		case "hold":
+			result.BudgetHeld += entry.Amount
+		case "charge":
+			result.BudgetUsed += entry.Amount
+			if entry.ParentType != "" {
+				result.BudgetHeld = nonNegative(result.BudgetHeld - entry.Amount)
+			}
+		case "refund":
+			switch entry.ParentType {
+			case "charge":
+				result.BudgetUsed = nonNegative(result.BudgetUsed - entry.Amount)
+			case "hold":
+				result.BudgetHeld = nonNegative(result.BudgetHeld - entry.Amount)
+			}
+		case "adjustment":
+			result.BudgetUsed = nonNegative(result.BudgetUsed + entry.Amount)
+		case "allocation":
+			if !entry.FromDescendant {
+				result.BudgetLimit += entry.Amount
+			}
+		}
+	}
+
+	result.BudgetAvailable = result.BudgetLimit - result.BudgetUsed - result.BudgetHeld
+	return result
+}
+
+// GenerateGrantReport builds a financial report for a grant from the opening and
+// closing snapshots of each grant-funded account
+func (s *Service) GenerateGrantReport(ctx context.Context, req *api.GrantReportRequest) (*api.GrantReportResponse, error) {
+	if req.GrantNumber == "" {
+		return nil, api.NewValidationError("grant_number", "is required")
+	}
+
+	grant, err := s.grantQueries.GetGrantByNumber(ctx, req.GrantNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	startDate := grant.GrantStartDate
+	endDate := now
+	if req.BudgetPeriod != nil {
+		if *req.BudgetPeriod < 1 {
+			return nil, api.NewValidationError("budget_period", "must be at least 1")
+		}
+		// Period bounds are local midnights in the grant's time zone; snapshots are keyed
+		// by calendar date, so carry the local dates over rather than the instants
+		periodStart, periodEnd := grant.BudgetPeriodBounds(*req.BudgetPeriod)
+		startDate = calendarDate(periodStart)
+		endDate = calendarDate(periodEnd).AddDate(0, 0, -1)
+		if endDate.After(now) {
+			endDate = now
+		}
+	}
+	if req.FiscalYear != nil {
+		if req.BudgetPeriod != nil {
+			return nil, api.NewValidationError("fiscal_year", "cannot be combined with budget_period")
+		}
+		// A fiscal year report covers the part of the fiscal year the grant was active
+		yearStart, yearEnd := s.defaultFiscalYearStart().YearBounds(*req.FiscalYear, grant.Location())
+		startDate = calendarDate(yearStart)
+		endDate = calendarDate(yearEnd).AddDate(0, 0, -1)
+		if grantStart := calendarDate(grant.GrantStartDate.In(grant.Location())); grantStart.After(startDate) {
+			startDate = grantStart
+		}
+		if endDate.After(now) {
+			endDate = now
+		}
+	}
+	if req.StartDate != nil {
+		startDate = *req.StartDate
+	}
+	if req.EndDate != nil {
+		endDate = *req.EndDate
+	}
+	if endDate.Before(startDate) {
+		return nil, api.NewValidationError("end_date", "must be after start_date")
+	}
+
+	accounts, err := s.grantQueries.ListGrantAccounts(ctx, grant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &api.GrantReportResponse{
+		GrantNumber:   grant.GrantNumber,
+		ReportType:    req.ReportType,
+		StartDate:     truncateToDay(startDate),
+		EndDate:       truncateToDay(endDate),
+		Accounts:      []api.GrantAccountReport{},
+		TotalAwarded:  grant.TotalAwardAmount,
+		BudgetPeriod:  grant.BudgetPeriodAt(endDate),
+		FiscalYear:    s.defaultFiscalYearStart().Year(truncateToDay(endDate), time.UTC),
+		DaysRemaining: grant.DaysRemaining(now),
+		GeneratedAt:   now,
+	}
+
+	for _, account := range accounts {
+		// The opening balance is the close of the day before the reporting period
+		opening, err := s.snapshotForAccount(ctx, account, startDate.AddDate(0, 0, -1))
+		if err != nil {
+			return nil, err
+		}
+		closing, err := s.snapshotForAccount(ctx, account, endDate)
+		if err != nil {
+			return nil, err
+		}
+
+		accountReport := api.GrantAccountReport{
+			Account:         account.SlurmAccount,
+			Opening:         *opening,
+			Closing:         *closing,
+			PeriodSpend:     closing.BudgetUsed - opening.BudgetUsed,
+			PeriodAllocated: closing.BudgetLimit - opening.BudgetLimit,
+		}
+		report.Accounts = append(report.Accounts, accountReport)
+		report.TotalSpent += accountReport.PeriodSpend
+		report.TotalHeld += closing.BudgetHeld
+	}
+
+	if report.TotalAwarded > 0 {
+		report.PercentSpent = report.TotalSpent / report.TotalAwarded * 100
+	}
+
+	return report, nil
+}
+
+// GetGrantPeriodSummary splits a grant budget period into spent, committed and remaining
+// funds, for the current period when period is nil. Spent is the charges attributed to the
+// period, those of jobs whose holds were placed in it, less refunds of them; committed is the
+// held budget rolled up onto the period from its accounts.
+func (s *Service) GetGrantPeriodSummary(ctx context.Context, grantNumber string, period *int) (*api.GrantPeriodSummary, error) {
+	if grantNumber == "" {
+		return nil, api.NewValidationError("grant_number", "is required")
+	}
+
+	grant, err := s.grantQueries.GetGrantByNumber(ctx, grantNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	number := grant.BudgetPeriodAt(now)
+	if period != nil {
+		if *period < 1 {
+			return nil, api.NewValidationError("budget_period", "must be at least 1")
+		}
+		number = *period
+	}
+
+	budgetPeriod, err := s.grantQueries.GetBudgetPeriod(ctx, grant.ID, number)
+	if err != nil {
+		return nil, err
+	}
+	if budgetPeriod == nil {
+		return nil, api.NewBudgetError(api.ErrCodeNotFound,
+			fmt.Sprintf("Budget period %d of grant %s not found", number, grantNumber))
+	}
+
+	summary := &api.GrantPeriodSummary{
+		GrantNumber:     grant.GrantNumber,
+		PeriodNumber:    number,
+		PeriodStartDate: budgetPeriod.PeriodStartDate,
+		PeriodEndDate:   budgetPeriod.PeriodEndDate,
+		Budget:          budgetPeriod.PeriodBudgetAmount,
+		Spent:           budgetPeriod.PeriodSpentAmount,
+		Committed:       budgetPeriod.PeriodCommittedAmount,
+	}
+
+	summary.Remaining = summary.Budget - summary.Spent - summary.Committed
+	return summary, nil
+}
+
+// calendarDate returns midnight UTC of t's date in t's own location
+func calendarDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// truncateToDay returns midnight UTC of the given time's date
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// nonNegative clamps a balance at zero, mirroring GREATEST(0, ...) in the database
+func nonNegative(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	return v
+}
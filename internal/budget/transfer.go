@@ -0,0 +1,148 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// TransferAccount moves one account into another when a project is renamed or grants are
+// consolidated. The source's transactions, allocation history and schedules move to the
+// destination, its balances are added to the destination's, and the source is archived,
+// all in one transaction with an audit record.
+func (s *Service) TransferAccount(ctx context.Context, sourceAccount, destAccount string, req *api.AccountTransferRequest) (*api.AccountTransferResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if sourceAccount == destAccount {
+		return nil, api.NewValidationError("dest", "must not be the source account")
+	}
+
+	source, err := s.accountQueries.GetAccountByName(ctx, sourceAccount)
+	if err != nil {
+		return nil, err
+	}
+	dest, err := s.accountQueries.GetAccountByName(ctx, destAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	// The transfer moves balances out of the source's ancestors and into the destination's
+	// chain, so both chains are locked
+	ids, err := chainIDs(ctx, s.accountQueries, source.ID, dest.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfer *api.AccountTransfer
+	err = s.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		// Lock in ID order so opposing transfers, and charges on overlapping chains, cannot
+		// deadlock
+		locked, err := lockAccounts(ctx, s.accountQueries, tx, ids)
+		if err != nil {
+			return err
+		}
+		source, dest = locked[source.ID], locked[dest.ID]
+
+		children, err := s.transferQueries.CountChildren(ctx, tx, source.ID)
+		if err != nil {
+			return err
+		}
+		sourceAncestors, err := s.accountQueries.ListAncestors(ctx, source.ID)
+		if err != nil {
+			return err
+		}
+		destAncestors, err := s.accountQueries.ListAncestors(ctx, dest.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := validateTransfer(source, dest, sourceAncestors, destAncestors, children); err != nil {
+			return err
+		}
+
+		transfer = &api.AccountTransfer{
+			SourceAccountID: source.ID,
+			DestAccountID:   dest.ID,
+			SourceAccount:   source.SlurmAccount,
+			DestAccount:     dest.SlurmAccount,
+			BudgetLimit:     source.BudgetLimit,
+			BudgetUsed:      source.BudgetUsed,
+			BudgetHeld:      source.BudgetHeld,
+			ReservedAmount:  source.ReservedAmount,
+			TotalAllocated:  source.TotalAllocated,
+			Reason:          req.Reason,
+			TransferredBy:   req.TransferredBy,
+		}
+		return s.transferQueries.TransferAccount(ctx, tx, transfer, req.Schedules == api.TransferSchedulesRetire)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Str("source", transfer.SourceAccount).
+		Str("dest", transfer.DestAccount).
+		Float64("budget_limit", transfer.BudgetLimit).
+		Int64("transactions_moved", transfer.TransactionsMoved).
+		Str("transferred_by", transfer.TransferredBy).
+		Msg("Account transferred")
+
+	destination, err := s.accountQueries.GetAccountByID(ctx, transfer.DestAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.AccountTransferResponse{Transfer: transfer, Destination: destination}, nil
+}
+
+// validateTransfer checks that source may be merged into dest. The destination must be
+// active, and neither it nor any ancestor it gains the source's usage under may be left
+// with a negative spendable balance. A source with child accounts must have them moved
+// first, and an archived source has already been transferred.
+func validateTransfer(source, dest *api.BudgetAccount, sourceAncestors, destAncestors []*api.BudgetAccount, children int64) error {
+	if source.Status == "archived" {
+		return api.NewInvalidStatusTransitionError(source.SlurmAccount, source.Status, "archived")
+	}
+	if children > 0 {
+		return api.NewValidationError("source", "must not have child accounts; move them first")
+	}
+	if !dest.IsActive() {
+		return api.NewAccountInactiveError(dest.SlurmAccount, dest.Status)
+	}
+
+	// Ancestors shared with the source already count its usage
+	shared := map[int64]bool{source.ID: true}
+	for _, ancestor := range sourceAncestors {
+		shared[ancestor.ID] = true
+	}
+	moved := source.BudgetUsed + source.BudgetHeld
+
+	gained := moved
+	if shared[dest.ID] {
+		gained = 0
+	}
+	required := gained + source.ReservedAmount
+	available := dest.SpendableAvailable() + source.BudgetLimit
+	if roundCents(available-required) < 0 {
+		return api.NewInsufficientBudgetError(dest.SlurmAccount, required, available)
+	}
+
+	for _, ancestor := range destAncestors {
+		if shared[ancestor.ID] {
+			continue
+		}
+		if available := ancestor.SpendableAvailable(); roundCents(available-moved) < 0 {
+			return api.NewInsufficientBudgetError(ancestor.SlurmAccount, moved, available)
+		}
+	}
+
+	return nil
+}
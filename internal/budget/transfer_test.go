@@ -0,0 +1,109 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestValidateTransfer(t *testing.T) {
+	account := func(id int64, name string, limit, used, held float64) *api.BudgetAccount {
+		return &api.BudgetAccount{
+			ID:           id,
+			SlurmAccount: name,
+			BudgetLimit:  limit,
+			BudgetUsed:   used,
+			BudgetHeld:   held,
+			Status:       "active",
+			StartDate:    time.Now().AddDate(0, -1, 0),
+			EndDate:      time.Now().AddDate(1, 0, 0),
+		}
+	}
+	code := func(err error) api.ErrorCode {
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		return budgetErr.Code
+	}
+
+	t.Run("combines balances", func(t *testing.T) {
+		assert.NoError(t, validateTransfer(account(1, "old", 500, 200, 50), account(2, "new", 1000, 100, 0), nil, nil, 0))
+	})
+
+	t.Run("overspent source covered by destination", func(t *testing.T) {
+		assert.NoError(t, validateTransfer(account(1, "old", 100, 300, 0), account(2, "new", 1000, 100, 0), nil, nil, 0))
+	})
+
+	t.Run("merge would leave destination negative", func(t *testing.T) {
+		err := validateTransfer(account(1, "old", 100, 300, 0), account(2, "new", 200, 100, 0), nil, nil, 0)
+		assert.Equal(t, api.ErrCodeInsufficientBudget, code(err))
+	})
+
+	t.Run("reserve counts against the destination", func(t *testing.T) {
+		source := account(1, "old", 100, 0, 0)
+		source.ReservedAmount = 200
+		err := validateTransfer(source, account(2, "new", 50, 0, 0), nil, nil, 0)
+		assert.Equal(t, api.ErrCodeInsufficientBudget, code(err))
+	})
+
+	t.Run("inactive destination", func(t *testing.T) {
+		dest := account(2, "new", 1000, 0, 0)
+		dest.Status = "suspended"
+		err := validateTransfer(account(1, "old", 100, 0, 0), dest, nil, nil, 0)
+		assert.Equal(t, api.ErrCodeAccountInactive, code(err))
+
+		dest.Status = "active"
+		dest.EndDate = time.Now().AddDate(0, 0, -1)
+		err = validateTransfer(account(1, "old", 100, 0, 0), dest, nil, nil, 0)
+		assert.Equal(t, api.ErrCodeAccountInactive, code(err))
+	})
+
+	t.Run("archived source", func(t *testing.T) {
+		source := account(1, "old", 0, 0, 0)
+		source.Status = "archived"
+		err := validateTransfer(source, account(2, "new", 1000, 0, 0), nil, nil, 0)
+		assert.Equal(t, api.ErrCodeInvalidStatusTransition, code(err))
+	})
+
+	t.Run("source with child accounts", func(t *testing.T) {
+		err := validateTransfer(account(1, "old", 100, 0, 0), account(2, "new", 1000, 0, 0), nil, nil, 2)
+		assert.Equal(t, api.ErrCodeValidation, code(err))
+	})
+
+	t.Run("destination ancestor would go negative", func(t *testing.T) {
+		umbrella := account(3, "umbrella", 1000, 950, 0)
+		err := validateTransfer(account(1, "old", 500, 200, 0), account(2, "new", 1000, 0, 0), nil, []*api.BudgetAccount{umbrella}, 0)
+		assert.Equal(t, api.ErrCodeInsufficientBudget, code(err))
+	})
+
+	t.Run("shared ancestor already counts the source", func(t *testing.T) {
+		umbrella := account(3, "umbrella", 1000, 950, 0)
+		err := validateTransfer(account(1, "old", 500, 200, 0), account(2, "new", 1000, 0, 0),
+			[]*api.BudgetAccount{umbrella}, []*api.BudgetAccount{umbrella}, 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("parent destination already counts the source", func(t *testing.T) {
+		parent := account(2, "parent", 300, 300, 0)
+		err := validateTransfer(account(1, "child", 100, 300, 0), parent, []*api.BudgetAccount{parent}, nil, 0)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTransferAccountValidation(t *testing.T) {
+	service := &Service{}
+
+	_, err := service.TransferAccount(context.Background(), "proj001", "proj001", &api.AccountTransferRequest{})
+	assert.Error(t, err)
+
+	_, err = service.TransferAccount(context.Background(), "proj001", "proj002", &api.AccountTransferRequest{Schedules: "keep"})
+	assert.Error(t, err)
+}
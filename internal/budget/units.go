@@ -0,0 +1,45 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"fmt"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// accountCost converts a dollar cost, as estimates and ASBX report costs, into the unit
+// the account holds and charges in. SU amounts are rounded to the cents the ledger keeps.
+func accountCost(account *api.BudgetAccount, cost float64) float64 {
+	if account.Unit() != api.BudgetUnitSU {
+		return cost
+	}
+	return roundCents(account.FromDollars(cost))
+}
+
+// checkParentUnit checks that an account and its parent share a unit, since a child's
+// holds and charges also draw on the parent's pool
+func checkParentUnit(unit string, parent *api.BudgetAccount) error {
+	if unit == "" {
+		unit = api.BudgetUnitDollars
+	}
+	if parent.Unit() != unit {
+		return api.NewValidationError("parent_account",
+			fmt.Sprintf("%s is denominated in %s, not %s", parent.SlurmAccount, parent.Unit(), unit))
+	}
+	return nil
+}
+
+// sameDenomination reports whether two accounts hold in the same unit at the same rate,
+// so that a hold split between them is worth the same in each
+func sameDenomination(a, b *api.BudgetAccount) bool {
+	if a.Unit() != b.Unit() {
+		return false
+	}
+	if a.Unit() != api.BudgetUnitSU {
+		return true
+	}
+	return a.DollarsPerSU != nil && b.DollarsPerSU != nil && *a.DollarsPerSU == *b.DollarsPerSU
+}
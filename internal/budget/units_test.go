@@ -0,0 +1,54 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func suAccount(name string, dollarsPerSU float64) *api.BudgetAccount {
+	return &api.BudgetAccount{SlurmAccount: name, BudgetUnit: api.BudgetUnitSU, DollarsPerSU: &dollarsPerSU}
+}
+
+func TestAccountCost(t *testing.T) {
+	dollars := &api.BudgetAccount{SlurmAccount: "proj001"}
+	assert.Equal(t, 12.345, accountCost(dollars, 12.345), "dollar costs are held as they are")
+
+	// $12.345 at $0.03 per SU is 411.5 SU
+	assert.Equal(t, 411.5, accountCost(suAccount("alloc01", 0.03), 12.345))
+	// $1 at $0.07 per SU is 14.2857... SU, kept to the cent
+	assert.Equal(t, 14.29, accountCost(suAccount("alloc01", 0.07), 1))
+}
+
+func TestCheckParentUnit(t *testing.T) {
+	dollarParent := &api.BudgetAccount{SlurmAccount: "dept01"}
+	suParent := suAccount("center01", 0.02)
+
+	assert.NoError(t, checkParentUnit("", dollarParent))
+	assert.NoError(t, checkParentUnit(api.BudgetUnitDollars, dollarParent))
+	assert.NoError(t, checkParentUnit(api.BudgetUnitSU, suParent))
+
+	err := checkParentUnit(api.BudgetUnitSU, dollarParent)
+	budgetErr, ok := api.AsBudgetError(err)
+	if assert.True(t, ok) {
+		assert.Equal(t, "parent_account", budgetErr.Field)
+		assert.Contains(t, err.Error(), "dept01 is denominated in dollars")
+	}
+	assert.Error(t, checkParentUnit("", suParent))
+}
+
+func TestSameDenomination(t *testing.T) {
+	dollars := &api.BudgetAccount{SlurmAccount: "proj001"}
+	explicit := &api.BudgetAccount{SlurmAccount: "proj002", BudgetUnit: api.BudgetUnitDollars}
+
+	assert.True(t, sameDenomination(dollars, explicit))
+	assert.True(t, sameDenomination(suAccount("a", 0.02), suAccount("b", 0.02)))
+	assert.False(t, sameDenomination(suAccount("a", 0.02), suAccount("b", 0.03)), "shares must be worth the same")
+	assert.False(t, sameDenomination(dollars, suAccount("b", 0.02)))
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const (
+	// costComponentCategory is the usage report grouping by reported cost component
+	costComponentCategory = "cost_component"
+	// unattributedComponent labels charged spend that came without a cost breakdown
+	unattributedComponent = "unattributed"
+)
+
+// UsageByComponent reports charged spend grouped by cost component. Dates are whole days
+// with the end date inclusive; an account includes the spend of its descendants. Spend
+// from charges without a breakdown is reported as unattributed.
+func (s *Service) UsageByComponent(ctx context.Context, req *api.UsageReportRequest) (*api.UsageReportResponse, error) {
+	if req.GroupBy != "" && req.GroupBy != costComponentCategory {
+		return nil, api.NewValidationError("group_by", "must be cost_component")
+	}
+	if req.Partition != "" {
+		return nil, api.NewValidationError("partition", "is not supported when grouping by cost component")
+	}
+
+	filter := *req
+	if filter.StartDate != nil {
+		start := truncateToDay(*filter.StartDate)
+		filter.StartDate = &start
+	}
+	if filter.EndDate != nil {
+		end := truncateToDay(*filter.EndDate).AddDate(0, 0, 1)
+		filter.EndDate = &end
+	}
+	if filter.StartDate != nil && filter.EndDate != nil && !filter.EndDate.After(*filter.StartDate) {
+		return nil, api.NewValidationError("end_date", "must not be before start_date")
+	}
+
+	var account *api.BudgetAccount
+	if req.Account != "" {
+		var err error
+		if account, err = s.accountQueries.GetAccountByName(ctx, req.Account); err != nil {
+			return nil, err
+		}
+	}
+
+	totals, err := s.usageQueries.ChargeTotals(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+	components, err := s.usageQueries.UsageByComponent(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+	latency, err := s.usageQueries.ReconciliationLatency(ctx, &filter, s.config.ReconciliationSLA)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &api.UsageReportResponse{
+		Account:               req.Account,
+		Period:                describePeriod(req),
+		ReconciliationLatency: latency,
+		Summary: api.UsageSummary{
+			TotalSpent: totals.Amount,
+			TotalJobs:  totals.JobCount,
+		},
+		Breakdown: componentBreakdown(components, totals.Amount),
+	}
+	if totals.JobCount > 0 {
+		resp.Summary.AvgCostPerJob = totals.Amount / float64(totals.JobCount)
+	}
+	if account != nil && account.BudgetLimit > 0 {
+		resp.Summary.BudgetUtilized = totals.Amount / account.BudgetLimit * 100
+	}
+
+	return resp, nil
+}
+
+// componentBreakdown turns per-component totals into report items, adding an unattributed
+// item for any charged spend the breakdowns do not cover
+func componentBreakdown(components []*database.ComponentUsage, total float64) []api.UsageBreakdownItem {
+	items := make([]api.UsageBreakdownItem, 0, len(components)+1)
+	attributed := 0.0
+	for _, component := range components {
+		items = append(items, api.UsageBreakdownItem{
+			Category: costComponentCategory,
+			Label:    component.Component,
+			Amount:   component.Amount,
+			JobCount: component.JobCount,
+		})
+		attributed += component.Amount
+	}
+
+	// Amounts are stored to the cent, so anything under half a cent is rounding
+	if remainder := total - attributed; remainder >= 0.005 {
+		items = append(items, api.UsageBreakdownItem{
+			Category: costComponentCategory,
+			Label:    unattributedComponent,
+			Amount:   math.Round(remainder*100) / 100,
+		})
+	}
+
+	base := math.Max(total, attributed)
+	for i := range items {
+		if base > 0 {
+			items[i].Percentage = items[i].Amount / base * 100
+		}
+	}
+	return items
+}
+
+// describePeriod labels the date range a usage report covers
+func describePeriod(req *api.UsageReportRequest) string {
+	const layout = "2006-01-02"
+	switch {
+	case req.StartDate != nil && req.EndDate != nil:
+		return fmt.Sprintf("%s to %s", req.StartDate.Format(layout), req.EndDate.Format(layout))
+	case req.StartDate != nil:
+		return "since " + req.StartDate.Format(layout)
+	case req.EndDate != nil:
+		return "through " + req.EndDate.Format(layout)
+	default:
+		return "all time"
+	}
+}
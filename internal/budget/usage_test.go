@@ -0,0 +1,75 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestComponentBreakdown(t *testing.T) {
+	components := []*database.ComponentUsage{
+		{Component: "compute", Amount: 60, JobCount: 2},
+		{Component: "storage", Amount: 15, JobCount: 2},
+		{Component: "network", Amount: 5, JobCount: 1},
+	}
+
+	t.Run("fully attributed", func(t *testing.T) {
+		items := componentBreakdown(components, 80)
+		require.Len(t, items, 3)
+		assert.Equal(t, "compute", items[0].Label)
+		assert.Equal(t, "cost_component", items[0].Category)
+		assert.InDelta(t, 75.0, items[0].Percentage, 0.001)
+		assert.Equal(t, int64(2), items[1].JobCount)
+	})
+
+	t.Run("charges without a breakdown", func(t *testing.T) {
+		items := componentBreakdown(components, 100)
+		require.Len(t, items, 4)
+		assert.Equal(t, "unattributed", items[3].Label)
+		assert.InDelta(t, 20.0, items[3].Amount, 0.001)
+		assert.InDelta(t, 20.0, items[3].Percentage, 0.001)
+		assert.InDelta(t, 60.0, items[0].Percentage, 0.001)
+	})
+
+	t.Run("rounding is not unattributed", func(t *testing.T) {
+		assert.Len(t, componentBreakdown(components, 80.004), 3)
+	})
+
+	t.Run("no spend", func(t *testing.T) {
+		assert.Empty(t, componentBreakdown(nil, 0))
+	})
+}
+
+func TestDescribePeriod(t *testing.T) {
+	start := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "2025-09-01 to 2025-09-30", describePeriod(&api.UsageReportRequest{StartDate: &start, EndDate: &end}))
+	assert.Equal(t, "since 2025-09-01", describePeriod(&api.UsageReportRequest{StartDate: &start}))
+	assert.Equal(t, "through 2025-09-30", describePeriod(&api.UsageReportRequest{EndDate: &end}))
+	assert.Equal(t, "all time", describePeriod(&api.UsageReportRequest{}))
+}
+
+func TestService_UsageByComponent_Validation(t *testing.T) {
+	service := NewService(nil, nil, nil)
+	ctx := context.Background()
+	start := time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.UsageByComponent(ctx, &api.UsageReportRequest{GroupBy: "partition"})
+	assert.Error(t, err)
+	_, err = service.UsageByComponent(ctx, &api.UsageReportRequest{Partition: "gpu"})
+	assert.Error(t, err)
+	_, err = service.UsageByComponent(ctx, &api.UsageReportRequest{StartDate: &start, EndDate: &end})
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/slurm"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// ValidateJob checks a batch script before it is submitted. The resources its #SBATCH
+// directives request are priced and checked against the account as a budget check would,
+// but no hold is placed and no decision recorded. What would make the job fail its budget
+// check (a missing account, a partition the account may not use, a cost over the single-job
+// cap or over the budget available) is returned as an issue, not an error, so every
+// problem is reported at once.
+func (s *Service) ValidateJob(ctx context.Context, req *api.JobValidateRequest) (*api.JobValidateResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.validateJob(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Valid = !hasValidationError(resp.Issues)
+	return resp, nil
+}
+
+// validateJob returns a job validation's resources, pricing and issues
+func (s *Service) validateJob(ctx context.Context, req *api.JobValidateRequest) (*api.JobValidateResponse, error) {
+	resources, issues := slurm.ParseJobScript(req.Script)
+	if req.Account != "" {
+		resources.Account = req.Account
+	}
+	if req.Partition != "" {
+		resources.Partition = req.Partition
+	}
+	resp := &api.JobValidateResponse{Resources: *resources, Issues: append(make([]api.JobValidationIssue, 0, len(issues)), issues...)}
+
+	if resources.Account == "" {
+		addValidationIssue(resp, api.ValidationSeverityError, "account",
+			"No account given; add #SBATCH --account so the job is charged to a budget")
+	}
+	if resources.Partition == "" {
+		addValidationIssue(resp, api.ValidationSeverityError, "partition", "No partition given; add #SBATCH --partition")
+	}
+	if resources.WallTime == "" && !hasValidationIssue(resp.Issues, "wall_time") {
+		addValidationIssue(resp, api.ValidationSeverityError, "wall_time",
+			"No time limit given; add #SBATCH --time so the job can be priced")
+	}
+
+	checkReq := &api.BudgetCheckRequest{
+		Account:        resources.Account,
+		Partition:      resources.Partition,
+		Nodes:          resources.Nodes,
+		CPUs:           resources.CPUs,
+		GPUs:           resources.GPUs,
+		Memory:         resources.Memory,
+		WallTime:       resources.WallTime,
+		JobScript:      req.Script,
+		UserID:         req.UserID,
+		ResearchDomain: req.ResearchDomain,
+	}
+
+	var account *api.BudgetAccount
+	var ancestors []*api.BudgetAccount
+	if resources.Account != "" {
+		var err error
+		if account, ancestors, err = s.validateJobAccount(ctx, resp, checkReq); err != nil {
+			return nil, err
+		}
+	}
+	if resources.Partition == "" || resources.WallTime == "" {
+		return resp, nil // The job cannot be priced
+	}
+
+	if account != nil {
+		grantWarning, err := s.checkGrantEndDate(ctx, account, checkReq)
+		if err != nil && !addErrorIssue(resp, "wall_time", err) {
+			return nil, err
+		}
+		if grantWarning != "" {
+			addValidationIssue(resp, api.ValidationSeverityWarning, "wall_time", grantWarning)
+		}
+	}
+
+	estimate, err := s.estimateCost(ctx, checkReq, estimationSourceFor(account))
+	if err != nil {
+		if addErrorIssue(resp, "estimated_cost", err) {
+			return resp, nil
+		}
+		return nil, err
+	}
+	estimate = s.applyDomainFactor(ctx, estimate, req.ResearchDomain)
+	estimate = s.applyScriptHistory(ctx, estimate, req.Script)
+	resp.EstimatedCost = estimate.EstimatedCost
+	if estimate.FailureMode != "" {
+		addValidationIssue(resp, api.ValidationSeverityWarning, "estimated_cost",
+			"Advisor service unavailable; estimate is from the fallback heuristic")
+	} else if estimate.AdvisorDivergence != nil {
+		addValidationIssue(resp, api.ValidationSeverityWarning, "estimated_cost", estimate.Warning)
+	}
+	if limit := s.maxSingleJobCost(resources.Partition); exceedsMaxSingleJobCost(estimate.EstimatedCost, limit) {
+		addValidationIssue(resp, api.ValidationSeverityError, "estimated_cost",
+			fmt.Sprintf("Estimated cost %.2f exceeds the maximum single-job cost of %.2f for partition %s; "+
+				"verify the job's time limit and resource requests", estimate.EstimatedCost, limit, resources.Partition))
+	}
+	if account == nil {
+		return resp, nil
+	}
+
+	holdPercentage, _ := s.holdPercentageFor(account, checkReq)
+	holdAmount := accountCost(account, estimate.EstimatedCost) * holdPercentage
+	if estimate.NoHold || isBelowMinChargeable(estimate.EstimatedCost, s.minChargeableCost(resources.Partition)) {
+		holdAmount = 0
+	}
+	graceCredit, err := s.holdGraceCredit(ctx, account, ancestors)
+	if err != nil {
+		return nil, err
+	}
+	available, limiting := chainAvailable(account, ancestors, graceCredit)
+
+	resp.HoldAmount = holdAmount
+	resp.BudgetRemaining = &available
+	resp.BudgetUnit = account.Unit()
+	resp.Affordable = holdAmount <= available
+	if !resp.Affordable {
+		addValidationIssue(resp, api.ValidationSeverityError, "account",
+			fmt.Sprintf("Insufficient budget: the job's hold of %.2f exceeds the %.2f available in account %s",
+				holdAmount, available, limiting.SlurmAccount))
+	}
+	return resp, nil
+}
+
+// validateJobAccount reads the account a job would be submitted under, with its
+// ancestors, and adds an issue for each reason its budget check would be refused before
+// the job is priced. It returns a nil account when there is none by that name.
+func (s *Service) validateJobAccount(ctx context.Context, resp *api.JobValidateResponse, req *api.BudgetCheckRequest) (*api.BudgetAccount, []*api.BudgetAccount, error) {
+	account, err := s.accountQueries.GetAccountByName(ctx, req.Account)
+	if err != nil {
+		if addErrorIssue(resp, "account", err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	ancestors, err := s.accountQueries.ListAncestors(ctx, account.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkAcceptsHolds(account, ancestors); err != nil {
+		addErrorIssue(resp, "account", err)
+	}
+	if req.UserID != "" {
+		if err := s.authorizeMember(ctx, account, req.UserID); err != nil && !addErrorIssue(resp, "account", err) {
+			return nil, nil, err
+		}
+	}
+	if req.Partition != "" && !account.AllowsPartition(req.Partition) {
+		addErrorIssue(resp, "partition", api.NewPartitionNotAllowedError(account.SlurmAccount, req.Partition, account.AllowedPartitions))
+	}
+	return account, ancestors, nil
+}
+
+// addValidationIssue adds an issue to a job validation
+func addValidationIssue(resp *api.JobValidateResponse, severity, field, message string) {
+	resp.Issues = append(resp.Issues, api.JobValidationIssue{Severity: severity, Field: field, Message: message})
+}
+
+// addErrorIssue adds a budget check's refusal to a job validation as an error issue. It
+// reports false, adding nothing, for a failure of the service rather than of the job.
+func addErrorIssue(resp *api.JobValidateResponse, field string, err error) bool {
+	budgetErr, ok := api.AsBudgetError(err)
+	if !ok || budgetErr.HTTPStatus() >= http.StatusInternalServerError {
+		return false
+	}
+	message := budgetErr.Message
+	if budgetErr.Details != "" {
+		message += ": " + budgetErr.Details
+	}
+	addValidationIssue(resp, api.ValidationSeverityError, field, message)
+	return true
+}
+
+// hasValidationIssue reports whether any issue concerns field
+func hasValidationIssue(issues []api.JobValidationIssue, field string) bool {
+	for _, issue := range issues {
+		if issue.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// hasValidationError reports whether any issue is an error
+func hasValidationError(issues []api.JobValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == api.ValidationSeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package budget
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestService_ValidateJob(t *testing.T) {
+	service := &Service{advisorClient: &MockAdvisorClient{}, config: &config.BudgetConfig{}}
+
+	t.Run("script is required", func(t *testing.T) {
+		_, err := service.ValidateJob(context.Background(), &api.JobValidateRequest{Script: "  \n"})
+		require.Error(t, err)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, "script", budgetErr.Field)
+	})
+
+	t.Run("missing account is priced but invalid", func(t *testing.T) {
+		resp, err := service.ValidateJob(context.Background(), &api.JobValidateRequest{
+			Script: "#!/bin/bash\n#SBATCH -p cpu\n#SBATCH -N 2 -c 4\n#SBATCH -t 2:00:00\nsrun hostname\n",
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Valid)
+		assert.False(t, resp.Affordable)
+		assert.Equal(t, api.JobResources{Partition: "cpu", Nodes: 2, CPUs: 4, WallTime: "02:00:00"}, resp.Resources)
+		assert.Equal(t, 10.0, resp.EstimatedCost)
+		assert.Nil(t, resp.BudgetRemaining)
+		require.Len(t, resp.Issues, 1)
+		assert.Equal(t, api.ValidationSeverityError, resp.Issues[0].Severity)
+		assert.Equal(t, "account", resp.Issues[0].Field)
+	})
+
+	t.Run("request overrides directives", func(t *testing.T) {
+		resp, err := service.ValidateJob(context.Background(), &api.JobValidateRequest{
+			Script:    "#SBATCH --time=1:00:00\n",
+			Partition: "gpu",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "gpu", resp.Resources.Partition)
+		assert.Equal(t, 10.0, resp.EstimatedCost)
+	})
+
+	t.Run("unpriceable job", func(t *testing.T) {
+		resp, err := service.ValidateJob(context.Background(), &api.JobValidateRequest{Script: "#SBATCH --nodes=1\n"})
+		require.NoError(t, err)
+		assert.False(t, resp.Valid)
+		assert.Zero(t, resp.EstimatedCost)
+		fields := make([]string, 0, len(resp.Issues))
+		for _, issue := range resp.Issues {
+			fields = append(fields, issue.Field)
+		}
+		assert.ElementsMatch(t, []string{"account", "partition", "wall_time"}, fields)
+	})
+
+	t.Run("over the single-job cap", func(t *testing.T) {
+		capped := &Service{advisorClient: &MockAdvisorClient{}, config: &config.BudgetConfig{
+			PartitionMaxSingleJobCost: map[string]float64{"gpu": 5},
+		}}
+		resp, err := capped.ValidateJob(context.Background(), &api.JobValidateRequest{
+			Script: "#SBATCH -p gpu\n#SBATCH -t 60\n",
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Valid)
+		assert.True(t, hasValidationIssue(resp.Issues, "estimated_cost"))
+	})
+}
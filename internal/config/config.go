@@ -7,10 +7,13 @@ package config
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
 // Config represents the application configuration
@@ -34,6 +37,14 @@ type IntegrationConfig struct {
 	ASBXTimeout  time.Duration `mapstructure:"asbx_timeout" yaml:"asbx_timeout"`
 	ASBXAPIKey   string        `mapstructure:"asbx_api_key" yaml:"asbx_api_key"`
 
+	// Ask ASBX for a job's cost before recovery cancels its orphaned hold, waiting at most the timeout
+	ASBXHoldCallback        bool          `mapstructure:"asbx_hold_callback" yaml:"asbx_hold_callback"`
+	ASBXHoldCallbackTimeout time.Duration `mapstructure:"asbx_hold_callback_timeout" yaml:"asbx_hold_callback_timeout"`
+
+	// Shared secret SLURM epilog posts are signed with, and how far their signing time may drift
+	EpilogSecret  string        `mapstructure:"epilog_secret" yaml:"epilog_secret"`
+	EpilogMaxSkew time.Duration `mapstructure:"epilog_max_skew" yaml:"epilog_max_skew"`
+
 	// ASBA (Academic Slurm Burst Allocation) integration - OPTIONAL
 	ASBAEnabled  bool          `mapstructure:"asba_enabled" yaml:"asba_enabled"`
 	ASBAEndpoint string        `mapstructure:"asba_endpoint" yaml:"asba_endpoint"`
@@ -43,6 +54,9 @@ type IntegrationConfig struct {
 	AdvisorEnabled   bool    `mapstructure:"advisor_enabled" yaml:"advisor_enabled"`
 	AdvisorFallback  string  `mapstructure:"advisor_fallback" yaml:"advisor_fallback"`     // STATIC, SIMPLE, NONE
 	FallbackCostRate float64 `mapstructure:"fallback_cost_rate" yaml:"fallback_cost_rate"` // $/hour when advisor unavailable
+	// Multipliers on fallback estimates for jobs in a partition, keyed by lower-case
+	// partition name. Unset keeps the built-in defaults; setting it replaces them.
+	FallbackPartitionMultipliers map[string]float64 `mapstructure:"fallback_partition_multipliers" yaml:"fallback_partition_multipliers"`
 
 	// Feature toggles for optional functionality
 	GrantManagementEnabled      bool `mapstructure:"grant_management_enabled" yaml:"grant_management_enabled"`
@@ -54,6 +68,34 @@ type IntegrationConfig struct {
 	RetryAttempts         int           `mapstructure:"retry_attempts" yaml:"retry_attempts"`
 	CircuitBreakerEnabled bool          `mapstructure:"circuit_breaker_enabled" yaml:"circuit_breaker_enabled"`
 	HealthCheckInterval   time.Duration `mapstructure:"health_check_interval" yaml:"health_check_interval"`
+
+	// An advisor estimate more than AdvisorDivergenceRatio times below the fallback
+	// heuristic's for the same job is treated as suspect, and its reported confidence is
+	// lowered. MAX holds against the fallback estimate and BLEND against the mean of the
+	// two. Zero turns the check off; in STRICT failure mode the advisor is trusted alone.
+	AdvisorDivergenceRatio  float64 `mapstructure:"advisor_divergence_ratio" yaml:"advisor_divergence_ratio"`
+	AdvisorDivergencePolicy string  `mapstructure:"advisor_divergence_policy" yaml:"advisor_divergence_policy"`
+
+	// An advisor estimate that is zero or negative would hold nothing, so it is replaced:
+	// FALLBACK with the fallback heuristic's estimate, MINIMUM with InvalidEstimateMinimum
+	// dollars
+	InvalidEstimatePolicy  string  `mapstructure:"invalid_estimate_policy" yaml:"invalid_estimate_policy"`
+	InvalidEstimateMinimum float64 `mapstructure:"invalid_estimate_minimum" yaml:"invalid_estimate_minimum"`
+
+	// ASBX reconciliations warn of a cost variance only when it is more than
+	// VarianceWarningPct of the estimate and more than VarianceWarningMinAmount dollars
+	VarianceWarningPct       float64 `mapstructure:"variance_warning_pct" yaml:"variance_warning_pct"`
+	VarianceWarningMinAmount float64 `mapstructure:"variance_warning_min_amount" yaml:"variance_warning_min_amount"`
+
+	// What an ASBX reconciliation of a job never held, having bypassed the budget check,
+	// does: charge the job's account retroactively, flagged unreserved, or reject it
+	UnheldJobPolicy string `mapstructure:"unheld_job_policy" yaml:"unheld_job_policy"`
+
+	// Alert notifications are posted here as JSON, for a mail or chat relay to deliver;
+	// empty sends none. The URL may carry a token, so it is redacted like a secret.
+	NotificationWebhookURL string `mapstructure:"notification_webhook_url" yaml:"notification_webhook_url"`
+	// How long a notification post may take before it is given up and left to be retried
+	NotificationTimeout time.Duration `mapstructure:"notification_timeout" yaml:"notification_timeout"`
 }
 
 // ServiceConfig contains HTTP service configuration
@@ -67,6 +109,17 @@ type ServiceConfig struct {
 	TLSKeyFile      string        `mapstructure:"tls_key_file" yaml:"tls_key_file"`
 	CORSEnabled     bool          `mapstructure:"cors_enabled" yaml:"cors_enabled"`
 	CORSOrigins     []string      `mapstructure:"cors_origins" yaml:"cors_origins"`
+
+	// Gzip responses for clients that accept it, leaving bodies under the minimum size as they are
+	CompressionEnabled bool `mapstructure:"compression_enabled" yaml:"compression_enabled"`
+	CompressionMinSize int  `mapstructure:"compression_min_size" yaml:"compression_min_size"`
+
+	// Requests running longer than their route's timeout are cut off with 503 Service
+	// Unavailable. Report and batch routes have longer built-in timeouts; RouteTimeouts
+	// overrides any route's by its path template, e.g. /api/v1/grants/{grant}/report. Zero
+	// leaves a route without a timeout.
+	RequestTimeout time.Duration            `mapstructure:"request_timeout" yaml:"request_timeout"`
+	RouteTimeouts  map[string]time.Duration `mapstructure:"route_timeouts" yaml:"route_timeouts"`
 }
 
 // DatabaseConfig contains database connection configuration
@@ -96,12 +149,175 @@ type AdvisorConfig struct {
 type BudgetConfig struct {
 	DefaultHoldPercentage float64       `mapstructure:"default_hold_percentage" yaml:"default_hold_percentage"`
 	ReconciliationTimeout time.Duration `mapstructure:"reconciliation_timeout" yaml:"reconciliation_timeout"`
+	// Hold percentage for jobs requesting GPUs, whose estimates are the least reliable. It
+	// replaces the default for any job with GPUs, whatever its partition; an account's own
+	// hold percentage still takes precedence. Zero uses the default.
+	GPUHoldPercentage float64 `mapstructure:"gpu_hold_percentage" yaml:"gpu_hold_percentage"`
+	// Bounds on every hold percentage: the default, the GPU percentage and account
+	// overrides, so a mistyped 12.0 or 0.01 is refused rather than holding far too much or
+	// nothing. Zero leaves that bound out.
+	MinHoldPercentage float64 `mapstructure:"min_hold_percentage" yaml:"min_hold_percentage"`
+	MaxHoldPercentage float64 `mapstructure:"max_hold_percentage" yaml:"max_hold_percentage"`
+	// Holds reconciled later than this after being placed raise a reconciliation_sla alert;
+	// zero turns the alert off
+	ReconciliationSLA time.Duration `mapstructure:"reconciliation_sla" yaml:"reconciliation_sla"`
+	// A hold whose reconciliation fails this many times in a row is dead-lettered: orphan
+	// recovery stops retrying it and it is listed for an administrator to replay or
+	// resolve. Zero never dead-letters.
+	DeadLetterAfter       int           `mapstructure:"dead_letter_after" yaml:"dead_letter_after"`
 	MinBudgetAmount       float64       `mapstructure:"min_budget_amount" yaml:"min_budget_amount"`
 	MaxBudgetAmount       float64       `mapstructure:"max_budget_amount" yaml:"max_budget_amount"`
 	AllowNegativeBalance  bool          `mapstructure:"allow_negative_balance" yaml:"allow_negative_balance"`
 	AutoRecoveryEnabled   bool          `mapstructure:"auto_recovery_enabled" yaml:"auto_recovery_enabled"`
 	RecoveryCheckInterval time.Duration `mapstructure:"recovery_check_interval" yaml:"recovery_check_interval"`
 	TransactionRetention  time.Duration `mapstructure:"transaction_retention" yaml:"transaction_retention"`
+
+	// Budget utilization alerting (percent of limit used or held)
+	AlertWarningThreshold  float64       `mapstructure:"alert_warning_threshold" yaml:"alert_warning_threshold"`
+	AlertCriticalThreshold float64       `mapstructure:"alert_critical_threshold" yaml:"alert_critical_threshold"`
+	AlertHysteresisMargin  float64       `mapstructure:"alert_hysteresis_margin" yaml:"alert_hysteresis_margin"`
+	AlertCheckInterval     time.Duration `mapstructure:"alert_check_interval" yaml:"alert_check_interval"`
+
+	// Alerts are notified to the recipients following their account, NotificationRecipients
+	// listing the accounts each follows; an account covers its descendants. IMMEDIATE sends
+	// each alert as it is raised. DIGEST holds all but critical alerts and sends each
+	// recipient one digest of them every NotificationDigestInterval. Notifications, digests
+	// included, are queued and sent every NotificationSendInterval, so raising an alert
+	// never waits on the webhook; zero leaves them queued. A notification is tried NotificationMaxAttempts
+	// times before it is marked failed for an administrator to retry (zero tries forever),
+	// and the NotificationHistory most recent delivered and failed notifications are kept
+	// (zero keeps 1000).
+	NotificationMode           string              `mapstructure:"notification_mode" yaml:"notification_mode"`
+	NotificationDigestInterval time.Duration       `mapstructure:"notification_digest_interval" yaml:"notification_digest_interval"`
+	NotificationSendInterval   time.Duration       `mapstructure:"notification_send_interval" yaml:"notification_send_interval"`
+	NotificationMaxAttempts    int                 `mapstructure:"notification_max_attempts" yaml:"notification_max_attempts"`
+	NotificationHistory        int                 `mapstructure:"notification_history" yaml:"notification_history"`
+	NotificationRecipients     map[string][]string `mapstructure:"notification_recipients" yaml:"notification_recipients"`
+
+	// How often due incremental allocations are made; zero disables the background run
+	AllocationCheckInterval time.Duration `mapstructure:"allocation_check_interval" yaml:"allocation_check_interval"`
+
+	// How often GRANT_REPORT deadlines are checked, and how long before its due date each
+	// deadline's financial report is generated; a zero interval disables the background run
+	GrantReportCheckInterval time.Duration `mapstructure:"grant_report_check_interval" yaml:"grant_report_check_interval"`
+	GrantReportLeadTime      time.Duration `mapstructure:"grant_report_lead_time" yaml:"grant_report_lead_time"`
+
+	// Emergency budget options are proposed for an account in critical health when its
+	// end date, its grant's end date or one of its grant's deadlines falls within this
+	// window; zero never proposes them
+	EmergencyDeadlineWindow time.Duration `mapstructure:"emergency_deadline_window" yaml:"emergency_deadline_window"`
+
+	// The allocation and recovery workers take due schedules and orphaned holds this many
+	// at a time, working on up to WorkerConcurrency database connections at once, so a
+	// large backlog neither runs as one long transaction nor exhausts the pool
+	WorkerBatchSize   int `mapstructure:"worker_batch_size" yaml:"worker_batch_size"`
+	WorkerConcurrency int `mapstructure:"worker_concurrency" yaml:"worker_concurrency"`
+
+	// Jobs estimated below the minimum chargeable cost run without a hold and are charged
+	// once at reconciliation. Zero disables this; partitions may set their own threshold.
+	MinChargeableCost          float64            `mapstructure:"min_chargeable_cost" yaml:"min_chargeable_cost"`
+	PartitionMinChargeableCost map[string]float64 `mapstructure:"partition_min_chargeable_cost" yaml:"partition_min_chargeable_cost"`
+
+	// Jobs estimated above the maximum single-job cost are rejected even when the budget
+	// could cover them, catching mistyped wall times and resource requests before they tie
+	// up a large hold. Zero disables this; partitions may set their own cap.
+	MaxSingleJobCost          float64            `mapstructure:"max_single_job_cost" yaml:"max_single_job_cost"`
+	PartitionMaxSingleJobCost map[string]float64 `mapstructure:"partition_max_single_job_cost" yaml:"partition_max_single_job_cost"`
+
+	// Fiscal year start as MM-DD, e.g. 07-01. Empty keeps calendar boundaries; accounts may
+	// set their own.
+	FiscalYearStart string `mapstructure:"fiscal_year_start" yaml:"fiscal_year_start"`
+
+	// Prorate a schedule's first allocation by the share of its period left, e.g. 17/31 of
+	// the monthly amount for a schedule starting January 15th, then allocate full amounts
+	// from the start of the next period
+	ProrateFirstAllocation bool `mapstructure:"prorate_first_allocation" yaml:"prorate_first_allocation"`
+
+	// Multipliers applied to cost estimates for jobs in a research domain, e.g. 1.3 for a
+	// domain whose jobs overrun their estimates. With learning enabled, a domain's factor is
+	// replaced by its actual-to-estimated cost ratio once enough reconciled jobs are recorded.
+	DomainInflationFactors  map[string]float64 `mapstructure:"domain_inflation_factors" yaml:"domain_inflation_factors"`
+	DomainFactorLearning    bool               `mapstructure:"domain_factor_learning" yaml:"domain_factor_learning"`
+	DomainFactorMinSamples  int                `mapstructure:"domain_factor_min_samples" yaml:"domain_factor_min_samples"`
+	DomainFactorSampleLimit int                `mapstructure:"domain_factor_sample_limit" yaml:"domain_factor_sample_limit"`
+
+	// With script history enabled, estimates for a job script already run at least
+	// ScriptHistoryMinSamples times are blended with the mean actual cost of its most recent
+	// ScriptHistorySampleLimit completed jobs, which gets ScriptHistoryWeight of the blend
+	ScriptHistoryEnabled     bool    `mapstructure:"script_history_enabled" yaml:"script_history_enabled"`
+	ScriptHistoryMinSamples  int     `mapstructure:"script_history_min_samples" yaml:"script_history_min_samples"`
+	ScriptHistorySampleLimit int     `mapstructure:"script_history_sample_limit" yaml:"script_history_sample_limit"`
+	ScriptHistoryWeight      float64 `mapstructure:"script_history_weight" yaml:"script_history_weight"`
+
+	// Advisor estimates are sent the estimated and actual costs, and efficiencies, of up to
+	// this many of the account's most recent reconciled jobs with the same partition, nodes,
+	// CPUs and GPUs, as priors; zero sends none
+	AdvisorPriorActuals int `mapstructure:"advisor_prior_actuals" yaml:"advisor_prior_actuals"`
+
+	// Currency is the ISO 4217 code the service's amounts are in, and CurrencyDecimals the
+	// decimal places amounts are written with in API responses
+	Currency         string `mapstructure:"currency" yaml:"currency"`
+	CurrencyDecimals int    `mapstructure:"currency_decimals" yaml:"currency_decimals"`
+
+	// IndirectCostExclusions lists, by funding agency, the charge cost categories (cost
+	// breakdown components such as storage) the agency does not charge indirect costs on.
+	// Direct costs in them are left out of the grant's indirect cost base.
+	IndirectCostExclusions map[string][]string `mapstructure:"indirect_cost_exclusions" yaml:"indirect_cost_exclusions"`
+
+	// Whether a job that ends in the FAILED state is charged its actual cost. When false its
+	// whole hold is refunded as a courtesy, however much it consumed.
+	ChargeFailedJobs bool `mapstructure:"charge_failed_jobs" yaml:"charge_failed_jobs"`
+
+	// How held budget counts against availability in budget checks. STRICT counts every hold
+	// in full. GRACE counts only HoldGraceDiscount's complement of holds placed within the
+	// last HoldGraceWindow, so a burst of submissions whose jobs fail fast is not refused on
+	// holds that are about to be released.
+	HoldAvailability  string        `mapstructure:"hold_availability" yaml:"hold_availability"`
+	HoldGraceWindow   time.Duration `mapstructure:"hold_grace_window" yaml:"hold_grace_window"`
+	HoldGraceDiscount float64       `mapstructure:"hold_grace_discount" yaml:"hold_grace_discount"`
+
+	// Jobs hold only this fraction of their hold while they queue, and are escalated to the
+	// full hold when their prolog reports them started. The full hold must still fit for the
+	// job to be approved. Zero holds in full at submission; partitions may set their own.
+	QueuedHoldFraction          float64            `mapstructure:"queued_hold_fraction" yaml:"queued_hold_fraction"`
+	PartitionQueuedHoldFraction map[string]float64 `mapstructure:"partition_queued_hold_fraction" yaml:"partition_queued_hold_fraction"`
+
+	// What happens to an account once its spendable budget is used up. OFF leaves it to
+	// reject each submission; SUSPEND suspends it and FREEZE freezes it, with a critical
+	// alert, until an incremental allocation gives it budget again.
+	AutoSuspendOnDepletion string `mapstructure:"auto_suspend_on_depletion" yaml:"auto_suspend_on_depletion"`
+
+	// What happens to a job on an account funded by a grant, directly or through a parent,
+	// that would run past the grant's end date. WARN approves it with a warning; BLOCK
+	// rejects its hold. Either way a job that completes after the end date is charged and
+	// its reconciliation flagged.
+	GrantEndDatePolicy string `mapstructure:"grant_end_date_policy" yaml:"grant_end_date_policy"`
+
+	// How a budget check refused before its job is priced reports why. The account's
+	// status, the job's partition and its grants' end dates are checked in that order;
+	// FIRST reports the first failure, ALL runs every check and lists each failure.
+	AdmissionFailures string `mapstructure:"admission_failures" yaml:"admission_failures"`
+
+	// What orphan recovery does with a hold nobody reconciled once it is ExpiredHoldTimeout
+	// old; zero is twice ReconciliationTimeout. REFUND cancels and refunds it. CHARGE assumes
+	// the job cost what it was estimated to and charges that, for sites without ASBX or
+	// SLURM accounting whose spend would otherwise never be charged. Partitions may set
+	// their own, e.g. REFUND for on-premises partitions and CHARGE for AWS ones.
+	ExpiredHoldPolicy          string            `mapstructure:"expired_hold_policy" yaml:"expired_hold_policy"`
+	PartitionExpiredHoldPolicy map[string]string `mapstructure:"partition_expired_hold_policy" yaml:"partition_expired_hold_policy"`
+	ExpiredHoldTimeout         time.Duration     `mapstructure:"expired_hold_timeout" yaml:"expired_hold_timeout"`
+
+	// Decision endpoints such as ASBA's budget status read account balances through a cache
+	// that keeps them for BalanceCacheTTL; zero disables it. An entry is dropped as soon as
+	// a write changes the account's balances, so the TTL only bounds how long a change made
+	// outside the service goes unseen. Budget checks always read the database.
+	BalanceCacheTTL time.Duration `mapstructure:"balance_cache_ttl" yaml:"balance_cache_ttl"`
+
+	// Burn rate analysis expects an account's budget to be spent evenly over the working days
+	// of its lifetime: BurnRateWorkingDays, such as mon to fri, less BurnRateHolidays, written
+	// YYYY-MM-DD. No working days spreads it evenly over every day.
+	BurnRateWorkingDays []string `mapstructure:"burn_rate_working_days" yaml:"burn_rate_working_days"`
+	BurnRateHolidays    []string `mapstructure:"burn_rate_holidays" yaml:"burn_rate_holidays"`
 }
 
 // SLURMConfig contains SLURM integration configuration
@@ -125,6 +341,13 @@ type LoggingConfig struct {
 		Thereafter uint32        `mapstructure:"thereafter" yaml:"thereafter"`
 		Tick       time.Duration `mapstructure:"tick" yaml:"tick"`
 	} `mapstructure:"sampling" yaml:"sampling"`
+
+	// Log request and response bodies at debug level, for diagnosing failed requests. Never
+	// done in production. Bodies are cut off at BodyMaxBytes, and the values of JSON fields
+	// named in BodyRedactFields are redacted along with the built-in secret fields.
+	LogBodies        bool     `mapstructure:"log_bodies" yaml:"log_bodies"`
+	BodyMaxBytes     int      `mapstructure:"body_max_bytes" yaml:"body_max_bytes"`
+	BodyRedactFields []string `mapstructure:"body_redact_fields" yaml:"body_redact_fields"`
 }
 
 // AuthConfig contains authentication configuration
@@ -135,6 +358,8 @@ type AuthConfig struct {
 	APIKeyAuth bool          `mapstructure:"api_key_auth" yaml:"api_key_auth"`
 	APIKeys    []string      `mapstructure:"api_keys" yaml:"api_keys"`
 	AdminUsers []string      `mapstructure:"admin_users" yaml:"admin_users"`
+	// Bearer tokens accepted by the /api/v1/admin endpoints, which stay closed when empty
+	AdminAPIKeys []string `mapstructure:"admin_api_keys" yaml:"admin_api_keys"`
 }
 
 // MetricsConfig contains metrics/monitoring configuration
@@ -145,6 +370,11 @@ type MetricsConfig struct {
 	Subsystem       string        `mapstructure:"subsystem" yaml:"subsystem"`
 	CollectInterval time.Duration `mapstructure:"collect_interval" yaml:"collect_interval"`
 	PrometheusURL   string        `mapstructure:"prometheus_url" yaml:"prometheus_url"`
+
+	// Per-account gauges are refreshed every CollectInterval rather than per request, and
+	// label at most AccountLabelLimit accounts, the biggest spenders; the rest are summed
+	// into an "other" account. Zero labels every account.
+	AccountLabelLimit int `mapstructure:"account_label_limit" yaml:"account_label_limit"`
 }
 
 // Load loads configuration from multiple sources
@@ -205,6 +435,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("service.tls_enabled", false)
 	v.SetDefault("service.cors_enabled", false)
 	v.SetDefault("service.cors_origins", []string{"*"})
+	v.SetDefault("service.compression_enabled", false)
+	v.SetDefault("service.compression_min_size", 1024)
+	v.SetDefault("service.request_timeout", "30s")
 
 	// Database defaults (REQUIRED - core functionality)
 	v.SetDefault("database.driver", "postgres")
@@ -226,6 +459,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("integration.asbx_enabled", false)
 	v.SetDefault("integration.asbx_endpoint", "http://localhost:8082")
 	v.SetDefault("integration.asbx_timeout", "30s")
+	v.SetDefault("integration.asbx_hold_callback", false)
+	v.SetDefault("integration.asbx_hold_callback_timeout", "5s")
+	v.SetDefault("integration.epilog_max_skew", "5m")
+	v.SetDefault("integration.notification_timeout", "10s")
 
 	v.SetDefault("integration.asba_enabled", false)
 	v.SetDefault("integration.asba_endpoint", "http://localhost:8083")
@@ -243,16 +480,64 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("integration.retry_attempts", 3)
 	v.SetDefault("integration.circuit_breaker_enabled", true)
 	v.SetDefault("integration.health_check_interval", "60s")
+	v.SetDefault("integration.advisor_divergence_ratio", 10.0)
+	v.SetDefault("integration.advisor_divergence_policy", "MAX")
+	v.SetDefault("integration.invalid_estimate_policy", "FALLBACK")
+	v.SetDefault("integration.invalid_estimate_minimum", 0.0)
+	v.SetDefault("integration.variance_warning_pct", 50.0)
+	v.SetDefault("integration.variance_warning_min_amount", 1.00)
+	v.SetDefault("integration.unheld_job_policy", api.UnheldJobPolicyCharge)
 
 	// Budget defaults
 	v.SetDefault("budget.default_hold_percentage", 1.2)
+	v.SetDefault("budget.min_hold_percentage", 1.0)
+	v.SetDefault("budget.max_hold_percentage", 3.0)
 	v.SetDefault("budget.reconciliation_timeout", "24h")
+	v.SetDefault("budget.dead_letter_after", 5)
 	v.SetDefault("budget.min_budget_amount", 0.01)
 	v.SetDefault("budget.max_budget_amount", 1000000.0)
 	v.SetDefault("budget.allow_negative_balance", false)
 	v.SetDefault("budget.auto_recovery_enabled", true)
 	v.SetDefault("budget.recovery_check_interval", "1h")
 	v.SetDefault("budget.transaction_retention", "2160h") // 90 days
+	v.SetDefault("budget.alert_warning_threshold", 80.0)
+	v.SetDefault("budget.alert_critical_threshold", 95.0)
+	v.SetDefault("budget.alert_hysteresis_margin", 5.0)
+	v.SetDefault("budget.alert_check_interval", "1h")
+	v.SetDefault("budget.notification_mode", "IMMEDIATE")
+	v.SetDefault("budget.notification_digest_interval", "24h")
+	v.SetDefault("budget.notification_send_interval", "15s")
+	v.SetDefault("budget.notification_max_attempts", 5)
+	v.SetDefault("budget.notification_history", 1000)
+	v.SetDefault("budget.allocation_check_interval", "1h")
+	v.SetDefault("budget.grant_report_check_interval", "1h")
+	v.SetDefault("budget.grant_report_lead_time", "168h")    // 7 days
+	v.SetDefault("budget.emergency_deadline_window", "720h") // 30 days
+	v.SetDefault("budget.prorate_first_allocation", false)
+	v.SetDefault("budget.worker_batch_size", 100)
+	v.SetDefault("budget.worker_concurrency", 4)
+	v.SetDefault("budget.domain_factor_learning", false)
+	v.SetDefault("budget.domain_factor_min_samples", 10)
+	v.SetDefault("budget.domain_factor_sample_limit", 100)
+	v.SetDefault("budget.script_history_enabled", false)
+	v.SetDefault("budget.script_history_min_samples", 3)
+	v.SetDefault("budget.script_history_sample_limit", 20)
+	v.SetDefault("budget.script_history_weight", 0.7)
+	v.SetDefault("budget.advisor_prior_actuals", 5)
+	v.SetDefault("budget.currency", api.DefaultCurrency.Code)
+	v.SetDefault("budget.currency_decimals", api.DefaultCurrency.Decimals)
+	v.SetDefault("budget.charge_failed_jobs", true)
+	v.SetDefault("budget.hold_availability", "STRICT")
+	v.SetDefault("budget.hold_grace_window", "5m")
+	v.SetDefault("budget.hold_grace_discount", 0.5)
+	v.SetDefault("budget.auto_suspend_on_depletion", "OFF")
+	v.SetDefault("budget.grant_end_date_policy", "WARN")
+	v.SetDefault("budget.admission_failures", "FIRST")
+	v.SetDefault("budget.expired_hold_policy", "REFUND")
+	v.SetDefault("budget.expired_hold_timeout", "0s")
+	v.SetDefault("budget.balance_cache_ttl", "0s")
+	v.SetDefault("budget.burn_rate_working_days", []string{})
+	v.SetDefault("budget.burn_rate_holidays", []string{})
 
 	// SLURM defaults
 	v.SetDefault("slurm.bin_path", "/usr/bin")
@@ -269,6 +554,8 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("logging.sampling.initial", 100)
 	v.SetDefault("logging.sampling.thereafter", 100)
 	v.SetDefault("logging.sampling.tick", "1s")
+	v.SetDefault("logging.log_bodies", false)
+	v.SetDefault("logging.body_max_bytes", 4096)
 
 	// Auth defaults
 	v.SetDefault("auth.enabled", false)
@@ -281,6 +568,7 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("metrics.namespace", "asbb")
 	v.SetDefault("metrics.subsystem", "budget")
 	v.SetDefault("metrics.collect_interval", "15s")
+	v.SetDefault("metrics.account_label_limit", 100)
 }
 
 // Validate validates the configuration
@@ -300,6 +588,87 @@ func (c *Config) Validate() error {
 	if err := c.Budget.Validate(); err != nil {
 		return fmt.Errorf("budget config: %w", err)
 	}
+	// Leave connections free for API requests while the background workers run
+	if c.Database.MaxOpenConns > 0 && c.Budget.WorkerConcurrency >= c.Database.MaxOpenConns {
+		return fmt.Errorf("budget config: worker_concurrency must be less than database max_open_conns (%d)", c.Database.MaxOpenConns)
+	}
+	if err := c.Integration.Validate(); err != nil {
+		return fmt.Errorf("integration config: %w", err)
+	}
+	if err := c.Logging.Validate(); err != nil {
+		return fmt.Errorf("logging config: %w", err)
+	}
+	if err := c.Metrics.Validate(); err != nil {
+		return fmt.Errorf("metrics config: %w", err)
+	}
+	return nil
+}
+
+// Validate validates MetricsConfig
+func (mc *MetricsConfig) Validate() error {
+	if mc.Enabled && mc.CollectInterval <= 0 {
+		return fmt.Errorf("collect_interval must be positive when metrics are enabled")
+	}
+	if mc.AccountLabelLimit < 0 {
+		return fmt.Errorf("account_label_limit must not be negative")
+	}
+	return nil
+}
+
+// Validate validates LoggingConfig
+func (lc *LoggingConfig) Validate() error {
+	if lc.BodyMaxBytes < 0 {
+		return fmt.Errorf("body_max_bytes must not be negative")
+	}
+	return nil
+}
+
+// Validate validates IntegrationConfig
+func (ic *IntegrationConfig) Validate() error {
+	switch ic.FailureMode {
+	case "", "STRICT", "GRACEFUL", "PERMISSIVE":
+	default:
+		return fmt.Errorf("failure_mode must be STRICT, GRACEFUL or PERMISSIVE, got %q", ic.FailureMode)
+	}
+	if ic.EpilogMaxSkew < 0 {
+		return fmt.Errorf("epilog_max_skew must not be negative")
+	}
+	if ic.ASBXHoldCallbackTimeout < 0 {
+		return fmt.Errorf("asbx_hold_callback_timeout must not be negative")
+	}
+	if ic.NotificationTimeout < 0 {
+		return fmt.Errorf("notification_timeout must not be negative")
+	}
+	for partition, multiplier := range ic.FallbackPartitionMultipliers {
+		if multiplier <= 0 {
+			return fmt.Errorf("fallback_partition_multipliers for %s must be positive", partition)
+		}
+	}
+	if ic.AdvisorDivergenceRatio != 0 && ic.AdvisorDivergenceRatio <= 1 {
+		return fmt.Errorf("advisor_divergence_ratio must be greater than 1, or 0 to disable the check")
+	}
+	switch ic.AdvisorDivergencePolicy {
+	case "", "MAX", "BLEND":
+	default:
+		return fmt.Errorf("advisor_divergence_policy must be MAX or BLEND, got %q", ic.AdvisorDivergencePolicy)
+	}
+	switch ic.InvalidEstimatePolicy {
+	case "", "FALLBACK":
+	case "MINIMUM":
+		if ic.InvalidEstimateMinimum <= 0 {
+			return fmt.Errorf("invalid_estimate_minimum must be positive with invalid_estimate_policy MINIMUM")
+		}
+	default:
+		return fmt.Errorf("invalid_estimate_policy must be FALLBACK or MINIMUM, got %q", ic.InvalidEstimatePolicy)
+	}
+	if ic.VarianceWarningPct < 0 || ic.VarianceWarningMinAmount < 0 {
+		return fmt.Errorf("variance_warning_pct and variance_warning_min_amount must not be negative")
+	}
+	switch ic.UnheldJobPolicy {
+	case "", api.UnheldJobPolicyCharge, api.UnheldJobPolicyReject:
+	default:
+		return fmt.Errorf("unheld_job_policy must be %s or %s, got %q", api.UnheldJobPolicyCharge, api.UnheldJobPolicyReject, ic.UnheldJobPolicy)
+	}
 	return nil
 }
 
@@ -311,6 +680,17 @@ func (sc *ServiceConfig) Validate() error {
 	if sc.TLSEnabled && (sc.TLSCertFile == "" || sc.TLSKeyFile == "") {
 		return fmt.Errorf("TLS cert and key files are required when TLS is enabled")
 	}
+	if sc.CompressionMinSize < 0 {
+		return fmt.Errorf("compression_min_size must not be negative")
+	}
+	if sc.RequestTimeout < 0 {
+		return fmt.Errorf("request_timeout must not be negative")
+	}
+	for route, timeout := range sc.RouteTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("route_timeouts for %s must not be negative", route)
+		}
+	}
 	return nil
 }
 
@@ -339,20 +719,227 @@ func (ac *AdvisorConfig) Validate() error {
 	return nil
 }
 
+// CheckHoldPercentage checks a hold percentage against min_hold_percentage and
+// max_hold_percentage. The error completes a sentence naming the setting.
+func (bc *BudgetConfig) CheckHoldPercentage(holdPercentage float64) error {
+	tooLow := bc.MinHoldPercentage > 0 && holdPercentage < bc.MinHoldPercentage
+	tooHigh := bc.MaxHoldPercentage > 0 && holdPercentage > bc.MaxHoldPercentage
+	switch {
+	case (tooLow || tooHigh) && bc.MinHoldPercentage > 0 && bc.MaxHoldPercentage > 0:
+		return fmt.Errorf("must be between %.2f and %.2f, got %.2f", bc.MinHoldPercentage, bc.MaxHoldPercentage, holdPercentage)
+	case tooLow:
+		return fmt.Errorf("must be at least %.2f, got %.2f", bc.MinHoldPercentage, holdPercentage)
+	case tooHigh:
+		return fmt.Errorf("must be at most %.2f, got %.2f", bc.MaxHoldPercentage, holdPercentage)
+	}
+	return nil
+}
+
 // Validate validates BudgetConfig
 func (bc *BudgetConfig) Validate() error {
 	if bc.DefaultHoldPercentage <= 0 {
 		return fmt.Errorf("default_hold_percentage must be positive")
 	}
+	if bc.GPUHoldPercentage < 0 {
+		return fmt.Errorf("gpu_hold_percentage cannot be negative")
+	}
+	if bc.MinHoldPercentage < 0 || bc.MaxHoldPercentage < 0 {
+		return fmt.Errorf("min_hold_percentage and max_hold_percentage cannot be negative")
+	}
+	if bc.MinHoldPercentage > 0 && bc.MaxHoldPercentage > 0 && bc.MinHoldPercentage > bc.MaxHoldPercentage {
+		return fmt.Errorf("min_hold_percentage must not be greater than max_hold_percentage")
+	}
+	if err := bc.CheckHoldPercentage(bc.DefaultHoldPercentage); err != nil {
+		return fmt.Errorf("default_hold_percentage %s", err)
+	}
+	if bc.GPUHoldPercentage > 0 {
+		if err := bc.CheckHoldPercentage(bc.GPUHoldPercentage); err != nil {
+			return fmt.Errorf("gpu_hold_percentage %s", err)
+		}
+	}
 	if bc.MinBudgetAmount < 0 {
 		return fmt.Errorf("min_budget_amount cannot be negative")
 	}
+	if bc.ReconciliationSLA < 0 {
+		return fmt.Errorf("reconciliation_sla cannot be negative")
+	}
+	if bc.DeadLetterAfter < 0 {
+		return fmt.Errorf("dead_letter_after cannot be negative")
+	}
 	if bc.MaxBudgetAmount <= bc.MinBudgetAmount {
 		return fmt.Errorf("max_budget_amount must be greater than min_budget_amount")
 	}
+	if bc.AlertHysteresisMargin < 0 {
+		return fmt.Errorf("alert_hysteresis_margin cannot be negative")
+	}
+	if bc.AlertWarningThreshold > 0 && bc.AlertCriticalThreshold > 0 && bc.AlertWarningThreshold >= bc.AlertCriticalThreshold {
+		return fmt.Errorf("alert_warning_threshold must be less than alert_critical_threshold")
+	}
+	switch bc.NotificationMode {
+	case "", "IMMEDIATE":
+	case "DIGEST":
+		if bc.NotificationDigestInterval <= 0 {
+			return fmt.Errorf("notification_digest_interval must be positive in DIGEST notification mode")
+		}
+	default:
+		return fmt.Errorf("notification_mode must be IMMEDIATE or DIGEST, got %q", bc.NotificationMode)
+	}
+	if bc.NotificationSendInterval < 0 {
+		return fmt.Errorf("notification_send_interval cannot be negative")
+	}
+	if bc.NotificationMaxAttempts < 0 {
+		return fmt.Errorf("notification_max_attempts cannot be negative")
+	}
+	if bc.NotificationHistory < 0 {
+		return fmt.Errorf("notification_history cannot be negative")
+	}
+	if bc.GrantReportLeadTime < 0 {
+		return fmt.Errorf("grant_report_lead_time cannot be negative")
+	}
+	if bc.EmergencyDeadlineWindow < 0 {
+		return fmt.Errorf("emergency_deadline_window cannot be negative")
+	}
+	if bc.WorkerBatchSize < 0 {
+		return fmt.Errorf("worker_batch_size cannot be negative")
+	}
+	if bc.WorkerConcurrency < 0 {
+		return fmt.Errorf("worker_concurrency cannot be negative")
+	}
+	if bc.MinChargeableCost < 0 {
+		return fmt.Errorf("min_chargeable_cost cannot be negative")
+	}
+	for partition, threshold := range bc.PartitionMinChargeableCost {
+		if threshold < 0 {
+			return fmt.Errorf("partition_min_chargeable_cost for %s cannot be negative", partition)
+		}
+	}
+	if bc.MaxSingleJobCost < 0 {
+		return fmt.Errorf("max_single_job_cost cannot be negative")
+	}
+	for partition, limit := range bc.PartitionMaxSingleJobCost {
+		if limit < 0 {
+			return fmt.Errorf("partition_max_single_job_cost for %s cannot be negative", partition)
+		}
+	}
+	if bc.QueuedHoldFraction < 0 || bc.QueuedHoldFraction > 1 {
+		return fmt.Errorf("queued_hold_fraction must be between 0 and 1")
+	}
+	for partition, fraction := range bc.PartitionQueuedHoldFraction {
+		if fraction < 0 || fraction > 1 {
+			return fmt.Errorf("partition_queued_hold_fraction for %s must be between 0 and 1", partition)
+		}
+	}
+	if _, err := api.ParseFiscalYearStart(bc.FiscalYearStart); err != nil {
+		return err
+	}
+	for domain, factor := range bc.DomainInflationFactors {
+		if factor <= 0 {
+			return fmt.Errorf("domain_inflation_factors for %s must be positive", domain)
+		}
+	}
+	if bc.DomainFactorLearning && bc.DomainFactorMinSamples < 1 {
+		return fmt.Errorf("domain_factor_min_samples must be at least 1 when domain_factor_learning is enabled")
+	}
+	if bc.DomainFactorLearning && bc.DomainFactorSampleLimit < bc.DomainFactorMinSamples {
+		return fmt.Errorf("domain_factor_sample_limit cannot be less than domain_factor_min_samples")
+	}
+	if bc.AdvisorPriorActuals < 0 {
+		return fmt.Errorf("advisor_prior_actuals cannot be negative")
+	}
+	if bc.ScriptHistoryEnabled {
+		if bc.ScriptHistoryMinSamples < 1 {
+			return fmt.Errorf("script_history_min_samples must be at least 1 when script_history_enabled is on")
+		}
+		if bc.ScriptHistorySampleLimit < bc.ScriptHistoryMinSamples {
+			return fmt.Errorf("script_history_sample_limit cannot be less than script_history_min_samples")
+		}
+		if bc.ScriptHistoryWeight <= 0 || bc.ScriptHistoryWeight > 1 {
+			return fmt.Errorf("script_history_weight must be greater than 0 and at most 1")
+		}
+	}
+
+	if bc.Currency != "" && !api.ValidCurrencyCode(bc.Currency) {
+		return fmt.Errorf("invalid currency %q: expected a three-letter ISO 4217 code such as USD", bc.Currency)
+	}
+	if bc.CurrencyDecimals < 0 || bc.CurrencyDecimals > api.MaxCurrencyDecimals {
+		return fmt.Errorf("currency_decimals must be between 0 and %d", api.MaxCurrencyDecimals)
+	}
+	for agency, categories := range bc.IndirectCostExclusions {
+		for _, category := range categories {
+			if strings.TrimSpace(category) == "" {
+				return fmt.Errorf("indirect_cost_exclusions for %s cannot list an empty category", agency)
+			}
+		}
+	}
+	switch bc.HoldAvailability {
+	case "", "STRICT":
+	case "GRACE":
+		if bc.HoldGraceWindow <= 0 {
+			return fmt.Errorf("hold_grace_window must be positive when hold_availability is GRACE")
+		}
+		if bc.HoldGraceDiscount <= 0 || bc.HoldGraceDiscount > 1 {
+			return fmt.Errorf("hold_grace_discount must be greater than 0 and at most 1")
+		}
+	default:
+		return fmt.Errorf("hold_availability must be STRICT or GRACE, got %q", bc.HoldAvailability)
+	}
+	switch bc.AutoSuspendOnDepletion {
+	case "", "OFF", "SUSPEND", "FREEZE":
+	default:
+		return fmt.Errorf("auto_suspend_on_depletion must be OFF, SUSPEND or FREEZE, got %q", bc.AutoSuspendOnDepletion)
+	}
+	switch bc.GrantEndDatePolicy {
+	case "", "WARN", "BLOCK":
+	default:
+		return fmt.Errorf("grant_end_date_policy must be WARN or BLOCK, got %q", bc.GrantEndDatePolicy)
+	}
+	switch bc.AdmissionFailures {
+	case "", "FIRST", "ALL":
+	default:
+		return fmt.Errorf("admission_failures must be FIRST or ALL, got %q", bc.AdmissionFailures)
+	}
+	if !validExpiredHoldPolicy(bc.ExpiredHoldPolicy) {
+		return fmt.Errorf("expired_hold_policy must be REFUND or CHARGE, got %q", bc.ExpiredHoldPolicy)
+	}
+	for partition, policy := range bc.PartitionExpiredHoldPolicy {
+		if policy == "" || !validExpiredHoldPolicy(policy) {
+			return fmt.Errorf("partition_expired_hold_policy for %s must be REFUND or CHARGE, got %q", partition, policy)
+		}
+	}
+	if bc.ExpiredHoldTimeout < 0 {
+		return fmt.Errorf("expired_hold_timeout cannot be negative")
+	}
+	// Recovery only looks at holds older than the reconciliation timeout
+	if bc.ExpiredHoldTimeout > 0 && bc.ExpiredHoldTimeout < bc.ReconciliationTimeout {
+		return fmt.Errorf("expired_hold_timeout cannot be shorter than reconciliation_timeout")
+	}
+	if bc.BalanceCacheTTL < 0 {
+		return fmt.Errorf("balance_cache_ttl cannot be negative")
+	}
+	if _, err := api.ParseWorkCalendar(bc.BurnRateWorkingDays, bc.BurnRateHolidays); err != nil {
+		return err
+	}
 	return nil
 }
 
+// validExpiredHoldPolicy reports whether policy is an expired hold policy; empty is REFUND
+func validExpiredHoldPolicy(policy string) bool {
+	switch policy {
+	case "", "REFUND", "CHARGE":
+		return true
+	}
+	return false
+}
+
+// MoneyCurrency returns the currency amounts are written in; an unset currency is the
+// default
+func (bc *BudgetConfig) MoneyCurrency() api.Currency {
+	if bc.Currency == "" {
+		return api.DefaultCurrency
+	}
+	return api.Currency{Code: bc.Currency, Decimals: bc.CurrencyDecimals}
+}
+
 // IsStandalone returns true if running in standalone mode (no integrations)
 func (c *Config) IsStandalone() bool {
 	return !c.Integration.AdvisorEnabled &&
@@ -416,3 +1003,87 @@ func (dc *DatabaseConfig) GetDSNWithoutPassword() string {
 	}
 	return dsn
 }
+
+// redactedSecret replaces a secret in configuration shown outside the service
+const redactedSecret = "***"
+
+// Redacted returns a copy of the configuration that is safe to show: the database password
+// is masked as GetDSNWithoutPassword masks it, and every key, secret and advisor header
+// value is replaced. A secret left unset stays empty, so it can be seen to be missing.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Database.DSN = c.Database.GetDSNWithoutPassword()
+	redacted.Advisor.APIKey = redactSecret(c.Advisor.APIKey)
+	if c.Advisor.Headers != nil {
+		redacted.Advisor.Headers = make(map[string]string, len(c.Advisor.Headers))
+		for name, value := range c.Advisor.Headers {
+			redacted.Advisor.Headers[name] = redactSecret(value)
+		}
+	}
+	redacted.Integration.ASBXAPIKey = redactSecret(c.Integration.ASBXAPIKey)
+	redacted.Integration.EpilogSecret = redactSecret(c.Integration.EpilogSecret)
+	redacted.Integration.NotificationWebhookURL = redactSecret(c.Integration.NotificationWebhookURL)
+	redacted.Auth.JWTSecret = redactSecret(c.Auth.JWTSecret)
+	redacted.Auth.APIKeys = redactSecrets(c.Auth.APIKeys)
+	redacted.Auth.AdminAPIKeys = redactSecrets(c.Auth.AdminAPIKeys)
+	return &redacted
+}
+
+// redactSecret replaces a secret that is set
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedSecret
+}
+
+// redactSecrets replaces each secret in a list, keeping how many there are
+func redactSecrets(secrets []string) []string {
+	if secrets == nil {
+		return nil
+	}
+	redacted := make([]string, len(secrets))
+	for i, secret := range secrets {
+		redacted[i] = redactSecret(secret)
+	}
+	return redacted
+}
+
+// Settings returns the configuration as nested maps keyed the way the configuration file
+// is, e.g. settings["budget"]["default_hold_percentage"], with durations written as in the
+// file. Redact the configuration first if it is to be shown.
+func (c *Config) Settings() map[string]interface{} {
+	return structSettings(reflect.ValueOf(*c))
+}
+
+// structSettings maps a configuration struct's fields by their mapstructure keys
+func structSettings(v reflect.Value) map[string]interface{} {
+	settings := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" || !field.IsExported() {
+			continue
+		}
+		settings[key] = settingValue(v.Field(i))
+	}
+	return settings
+}
+
+// settingValue returns a configuration value as it is shown in Settings
+func settingValue(v reflect.Value) interface{} {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return d.String()
+	}
+	if v.Kind() == reflect.Map && v.Type().Elem() == reflect.TypeOf(time.Duration(0)) {
+		durations := make(map[string]string, v.Len())
+		for _, key := range v.MapKeys() {
+			durations[fmt.Sprint(key.Interface())] = v.MapIndex(key).Interface().(time.Duration).String()
+		}
+		return durations
+	}
+	if v.Kind() == reflect.Struct {
+		return structSettings(v)
+	}
+	return v.Interface()
+}
@@ -5,6 +5,7 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
 	"testing"
 	"time"
@@ -18,6 +19,8 @@ func TestLoadWithPath(t *testing.T) {
 	cfg, err := LoadWithPath("../../test_config.yaml")
 	require.NoError(t, err)
 	assert.Equal(t, ":9999", cfg.Service.ListenAddr)
+	assert.Equal(t, 30*time.Second, cfg.Service.RequestTimeout)
+	assert.Equal(t, 5*time.Minute, cfg.Service.RouteTimeouts["/api/v1/grants/{grant}/report"])
 	assert.Equal(t, "postgres", cfg.Database.Driver)
 	// DSN may be overridden by environment variables in CI
 	assert.NotEmpty(t, cfg.Database.DSN)
@@ -62,6 +65,24 @@ func TestServiceConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative compression min size",
+			config: ServiceConfig{
+				ListenAddr:         ":8080",
+				CompressionEnabled: true,
+				CompressionMinSize: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative route timeout",
+			config: ServiceConfig{
+				ListenAddr:     ":8080",
+				RequestTimeout: 30 * time.Second,
+				RouteTimeouts:  map[string]time.Duration{"/api/v1/budget/check": -time.Second},
+			},
+			wantErr: true,
+		},
 		{
 			name: "TLS enabled with cert and key",
 			config: ServiceConfig{
@@ -198,6 +219,100 @@ func TestAdvisorConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestIntegrationConfig_Validate(t *testing.T) {
+	for _, mode := range []string{"", "STRICT", "GRACEFUL", "PERMISSIVE"} {
+		config := IntegrationConfig{FailureMode: mode}
+		assert.NoError(t, config.Validate(), mode)
+	}
+
+	config := IntegrationConfig{FailureMode: "graceful"}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{ASBXHoldCallback: true, ASBXHoldCallbackTimeout: -time.Second}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{NotificationWebhookURL: "https://hooks.example.edu", NotificationTimeout: -time.Second}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{FallbackPartitionMultipliers: map[string]float64{"gpu": 2.5}}
+	assert.NoError(t, config.Validate())
+
+	config = IntegrationConfig{FallbackPartitionMultipliers: map[string]float64{"gpu": 0}}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{AdvisorDivergenceRatio: 5, AdvisorDivergencePolicy: "BLEND"}
+	assert.NoError(t, config.Validate())
+
+	config = IntegrationConfig{AdvisorDivergenceRatio: 1}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{AdvisorDivergenceRatio: 5, AdvisorDivergencePolicy: "MIN"}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{InvalidEstimatePolicy: "MINIMUM", InvalidEstimateMinimum: 1.0}
+	assert.NoError(t, config.Validate())
+
+	config = IntegrationConfig{InvalidEstimatePolicy: "MINIMUM"}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{InvalidEstimatePolicy: "REJECT"}
+	assert.Error(t, config.Validate())
+
+	config = IntegrationConfig{VarianceWarningPct: 50, VarianceWarningMinAmount: -1}
+	assert.Error(t, config.Validate())
+
+	for _, policy := range []string{"charge", "reject"} {
+		config = IntegrationConfig{UnheldJobPolicy: policy}
+		assert.NoError(t, config.Validate(), policy)
+	}
+
+	config = IntegrationConfig{UnheldJobPolicy: "ignore"}
+	assert.Error(t, config.Validate())
+}
+
+func TestLoggingConfig_Validate(t *testing.T) {
+	config := LoggingConfig{LogBodies: true, BodyMaxBytes: 4096}
+	assert.NoError(t, config.Validate())
+
+	config = LoggingConfig{LogBodies: true}
+	assert.NoError(t, config.Validate(), "zero logs bodies in full")
+
+	config = LoggingConfig{BodyMaxBytes: -1}
+	assert.Error(t, config.Validate())
+}
+
+func TestMetricsConfig_Validate(t *testing.T) {
+	config := MetricsConfig{Enabled: true, CollectInterval: 15 * time.Second, AccountLabelLimit: 100}
+	assert.NoError(t, config.Validate())
+
+	config = MetricsConfig{Enabled: true, CollectInterval: 15 * time.Second}
+	assert.NoError(t, config.Validate(), "zero labels every account")
+
+	config = MetricsConfig{Enabled: true}
+	assert.Error(t, config.Validate())
+
+	config = MetricsConfig{}
+	assert.NoError(t, config.Validate(), "nothing is collected while disabled")
+
+	config = MetricsConfig{Enabled: true, CollectInterval: 15 * time.Second, AccountLabelLimit: -1}
+	assert.Error(t, config.Validate())
+}
+
+func TestBudgetConfig_CheckHoldPercentage(t *testing.T) {
+	bounded := BudgetConfig{MinHoldPercentage: 1.0, MaxHoldPercentage: 3.0}
+	assert.NoError(t, bounded.CheckHoldPercentage(1.0))
+	assert.NoError(t, bounded.CheckHoldPercentage(3.0))
+	assert.EqualError(t, bounded.CheckHoldPercentage(12.0), "must be between 1.00 and 3.00, got 12.00")
+	assert.EqualError(t, bounded.CheckHoldPercentage(0.01), "must be between 1.00 and 3.00, got 0.01")
+
+	floorOnly := BudgetConfig{MinHoldPercentage: 1.0}
+	assert.EqualError(t, floorOnly.CheckHoldPercentage(0.5), "must be at least 1.00, got 0.50")
+	assert.NoError(t, floorOnly.CheckHoldPercentage(12.0))
+
+	unbounded := BudgetConfig{}
+	assert.NoError(t, unbounded.CheckHoldPercentage(12.0))
+}
+
 func TestBudgetConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -240,6 +355,639 @@ func TestBudgetConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative reconciliation SLA",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				ReconciliationSLA:     -time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "default hold percentage above the ceiling",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 12.0,
+				MinHoldPercentage:     1.0,
+				MaxHoldPercentage:     3.0,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "default hold percentage below the floor",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 0.01,
+				MinHoldPercentage:     1.0,
+				MaxHoldPercentage:     3.0,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "GPU hold percentage above the ceiling",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				GPUHoldPercentage:     4.0,
+				MinHoldPercentage:     1.0,
+				MaxHoldPercentage:     3.0,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "hold percentage floor above ceiling",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinHoldPercentage:     2.0,
+				MaxHoldPercentage:     1.5,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "hold percentages within bounds",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				GPUHoldPercentage:     1.5,
+				MinHoldPercentage:     1.0,
+				MaxHoldPercentage:     3.0,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative dead letter threshold",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				DeadLetterAfter:       -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative emergency deadline window",
+			config: BudgetConfig{
+				DefaultHoldPercentage:   1.2,
+				MinBudgetAmount:         0.01,
+				MaxBudgetAmount:         1000000.0,
+				EmergencyDeadlineWindow: -time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative grant report lead time",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				GrantReportLeadTime:   -time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative alert hysteresis margin",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				AlertHysteresisMargin: -1.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "alert warning threshold above critical",
+			config: BudgetConfig{
+				DefaultHoldPercentage:  1.2,
+				MinBudgetAmount:        0.01,
+				MaxBudgetAmount:        1000000.0,
+				AlertWarningThreshold:  95.0,
+				AlertCriticalThreshold: 80.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative min chargeable cost",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				MinChargeableCost:     -0.01,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative partition min chargeable cost",
+			config: BudgetConfig{
+				DefaultHoldPercentage:      1.2,
+				MinBudgetAmount:            0.01,
+				MaxBudgetAmount:            1000000.0,
+				PartitionMinChargeableCost: map[string]float64{"debug": -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative max single job cost",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				MaxSingleJobCost:      -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative partition max single job cost",
+			config: BudgetConfig{
+				DefaultHoldPercentage:     1.2,
+				MinBudgetAmount:           0.01,
+				MaxBudgetAmount:           1000000.0,
+				PartitionMaxSingleJobCost: map[string]float64{"gpu": -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "max single job cost",
+			config: BudgetConfig{
+				DefaultHoldPercentage:     1.2,
+				MinBudgetAmount:           0.01,
+				MaxBudgetAmount:           1000000.0,
+				MaxSingleJobCost:          5000,
+				PartitionMaxSingleJobCost: map[string]float64{"gpu": 20000, "debug": 0},
+			},
+			wantErr: false,
+		},
+		{
+			name: "queued hold fraction",
+			config: BudgetConfig{
+				DefaultHoldPercentage:       1.2,
+				MinBudgetAmount:             0.01,
+				MaxBudgetAmount:             1000000.0,
+				QueuedHoldFraction:          0.1,
+				PartitionQueuedHoldFraction: map[string]float64{"aws": 0.05, "debug": 0},
+			},
+			wantErr: false,
+		},
+		{
+			name: "queued hold fraction above one",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				QueuedHoldFraction:    1.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative partition queued hold fraction",
+			config: BudgetConfig{
+				DefaultHoldPercentage:       1.2,
+				MinBudgetAmount:             0.01,
+				MaxBudgetAmount:             1000000.0,
+				PartitionQueuedHoldFraction: map[string]float64{"aws": -0.1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "july fiscal year start",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				FiscalYearStart:       "07-01",
+			},
+			wantErr: false,
+		},
+		{
+			name: "domain inflation factors",
+			config: BudgetConfig{
+				DefaultHoldPercentage:  1.2,
+				MinBudgetAmount:        0.01,
+				MaxBudgetAmount:        1000000.0,
+				DomainInflationFactors: map[string]float64{"genomics": 1.3, "cfd": 0.9},
+			},
+			wantErr: false,
+		},
+		{
+			name: "non-positive domain inflation factor",
+			config: BudgetConfig{
+				DefaultHoldPercentage:  1.2,
+				MinBudgetAmount:        0.01,
+				MaxBudgetAmount:        1000000.0,
+				DomainInflationFactors: map[string]float64{"genomics": 0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "domain factor learning without samples",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				DomainFactorLearning:  true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "script history",
+			config: BudgetConfig{
+				DefaultHoldPercentage:    1.2,
+				MinBudgetAmount:          0.01,
+				MaxBudgetAmount:          1000000.0,
+				ScriptHistoryEnabled:     true,
+				ScriptHistoryMinSamples:  3,
+				ScriptHistorySampleLimit: 20,
+				ScriptHistoryWeight:      0.7,
+			},
+			wantErr: false,
+		},
+		{
+			name: "script history without weight",
+			config: BudgetConfig{
+				DefaultHoldPercentage:    1.2,
+				MinBudgetAmount:          0.01,
+				MaxBudgetAmount:          1000000.0,
+				ScriptHistoryEnabled:     true,
+				ScriptHistoryMinSamples:  3,
+				ScriptHistorySampleLimit: 20,
+			},
+			wantErr: true,
+		},
+		{
+			name: "script history sample limit below min samples",
+			config: BudgetConfig{
+				DefaultHoldPercentage:    1.2,
+				MinBudgetAmount:          0.01,
+				MaxBudgetAmount:          1000000.0,
+				ScriptHistoryEnabled:     true,
+				ScriptHistoryMinSamples:  5,
+				ScriptHistorySampleLimit: 2,
+				ScriptHistoryWeight:      0.7,
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero-decimal currency",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				Currency:              "JPY",
+				CurrencyDecimals:      0,
+			},
+			wantErr: false,
+		},
+		{
+			name: "lower-case currency",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				Currency:              "usd",
+				CurrencyDecimals:      2,
+			},
+			wantErr: true,
+		},
+		{
+			name: "too many currency decimals",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				Currency:              "USD",
+				CurrencyDecimals:      5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "gpu hold percentage",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				GPUHoldPercentage:     1.5,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative gpu hold percentage",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				GPUHoldPercentage:     -1.5,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+			},
+			wantErr: true,
+		},
+		{
+			name: "indirect cost exclusions",
+			config: BudgetConfig{
+				DefaultHoldPercentage:  1.2,
+				MinBudgetAmount:        0.01,
+				MaxBudgetAmount:        1000000.0,
+				IndirectCostExclusions: map[string][]string{"nih": {"storage", "equipment"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty indirect cost exclusion category",
+			config: BudgetConfig{
+				DefaultHoldPercentage:  1.2,
+				MinBudgetAmount:        0.01,
+				MaxBudgetAmount:        1000000.0,
+				IndirectCostExclusions: map[string][]string{"nih": {"storage", " "}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid fiscal year start",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				FiscalYearStart:       "July 1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid burn rate working day",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				BurnRateWorkingDays:   []string{"mon", "weekday"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid burn rate holiday",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				BurnRateHolidays:      []string{"12/25"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative worker batch size",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				WorkerBatchSize:       -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative worker concurrency",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				WorkerConcurrency:     -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "grace hold availability",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				HoldAvailability:      "GRACE",
+				HoldGraceWindow:       5 * time.Minute,
+				HoldGraceDiscount:     0.5,
+			},
+			wantErr: false,
+		},
+		{
+			name: "grace hold availability without window",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				HoldAvailability:      "GRACE",
+				HoldGraceDiscount:     0.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "grace hold discount above one",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				HoldAvailability:      "GRACE",
+				HoldGraceWindow:       5 * time.Minute,
+				HoldGraceDiscount:     1.5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown hold availability",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				HoldAvailability:      "grace",
+			},
+			wantErr: true,
+		},
+		{
+			name: "freeze on depletion",
+			config: BudgetConfig{
+				DefaultHoldPercentage:  1.2,
+				MinBudgetAmount:        0.01,
+				MaxBudgetAmount:        1000000.0,
+				AutoSuspendOnDepletion: "FREEZE",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown depletion policy",
+			config: BudgetConfig{
+				DefaultHoldPercentage:  1.2,
+				MinBudgetAmount:        0.01,
+				MaxBudgetAmount:        1000000.0,
+				AutoSuspendOnDepletion: "suspend",
+			},
+			wantErr: true,
+		},
+		{
+			name: "block past grant end",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				GrantEndDatePolicy:    "BLOCK",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown grant end date policy",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				GrantEndDatePolicy:    "block",
+			},
+			wantErr: true,
+		},
+		{
+			name: "report every admission failure",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				AdmissionFailures:     "ALL",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown admission failure mode",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				AdmissionFailures:     "EVERY",
+			},
+			wantErr: true,
+		},
+		{
+			name: "daily notification digest",
+			config: BudgetConfig{
+				DefaultHoldPercentage:      1.2,
+				MinBudgetAmount:            0.01,
+				MaxBudgetAmount:            1000000.0,
+				NotificationMode:           "DIGEST",
+				NotificationDigestInterval: 24 * time.Hour,
+				NotificationRecipients:     map[string][]string{"grants@example.edu": {"physics"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "notification digest without an interval",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				NotificationMode:      "DIGEST",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative notification max attempts",
+			config: BudgetConfig{
+				DefaultHoldPercentage:   1.2,
+				MinBudgetAmount:         0.01,
+				MaxBudgetAmount:         1000000.0,
+				NotificationMaxAttempts: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative notification history",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				NotificationHistory:   -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative notification send interval",
+			config: BudgetConfig{
+				DefaultHoldPercentage:    1.2,
+				MinBudgetAmount:          0.01,
+				MaxBudgetAmount:          1000000.0,
+				NotificationSendInterval: -time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown notification mode",
+			config: BudgetConfig{
+				DefaultHoldPercentage:      1.2,
+				MinBudgetAmount:            0.01,
+				MaxBudgetAmount:            1000000.0,
+				NotificationMode:           "HOURLY",
+				NotificationDigestInterval: time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "charge expired holds on AWS partitions",
+			config: BudgetConfig{
+				DefaultHoldPercentage:      1.2,
+				MinBudgetAmount:            0.01,
+				MaxBudgetAmount:            1000000.0,
+				ReconciliationTimeout:      24 * time.Hour,
+				ExpiredHoldPolicy:          "REFUND",
+				PartitionExpiredHoldPolicy: map[string]string{"aws": "CHARGE"},
+				ExpiredHoldTimeout:         72 * time.Hour,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown expired hold policy",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				ExpiredHoldPolicy:     "charge",
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty partition expired hold policy",
+			config: BudgetConfig{
+				DefaultHoldPercentage:      1.2,
+				MinBudgetAmount:            0.01,
+				MaxBudgetAmount:            1000000.0,
+				PartitionExpiredHoldPolicy: map[string]string{"aws": ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired hold timeout shorter than reconciliation timeout",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				ReconciliationTimeout: 24 * time.Hour,
+				ExpiredHoldTimeout:    time.Hour,
+			},
+			wantErr: true,
+		},
+		{
+			name: "balance cache ttl",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				BalanceCacheTTL:       5 * time.Second,
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative balance cache ttl",
+			config: BudgetConfig{
+				DefaultHoldPercentage: 1.2,
+				MinBudgetAmount:       0.01,
+				MaxBudgetAmount:       1000000.0,
+				BalanceCacheTTL:       -time.Second,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -254,6 +1002,30 @@ func TestBudgetConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestConfig_ValidateWorkerConcurrency(t *testing.T) {
+	cfg := &Config{
+		Service:  ServiceConfig{ListenAddr: ":8080"},
+		Database: DatabaseConfig{Driver: "postgres", DSN: "postgresql://localhost/asbb", MaxOpenConns: 10},
+		Budget: BudgetConfig{
+			DefaultHoldPercentage: 1.2,
+			MinBudgetAmount:       0.01,
+			MaxBudgetAmount:       1000000.0,
+			WorkerConcurrency:     4,
+		},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	// The workers must leave connections free for API requests
+	cfg.Budget.WorkerConcurrency = 10
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "worker_concurrency")
+
+	// An unlimited pool places no bound
+	cfg.Database.MaxOpenConns = 0
+	assert.NoError(t, cfg.Validate())
+}
+
 func TestConfig_IsDevelopment(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -399,3 +1171,78 @@ func TestDatabaseConfig_GetDSNWithoutPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_Redacted(t *testing.T) {
+	cfg := &Config{
+		Database: DatabaseConfig{Driver: "postgres", DSN: "host=db user=asbb password=hunter2 dbname=asbb"},
+		Advisor: AdvisorConfig{
+			URL:     "http://advisor:8081",
+			APIKey:  "advisor-key",
+			Headers: map[string]string{"Authorization": "Bearer advisor-token"},
+			Timeout: 30 * time.Second,
+		},
+		Integration: IntegrationConfig{
+			ASBXAPIKey:       "asbx-key",
+			EpilogSecret:     "epilog-secret",
+			FallbackCostRate: 0.5,
+			AdvisorEnabled:   true,
+
+			NotificationWebhookURL: "https://hooks.example.edu/services/webhook-token",
+		},
+		Auth: AuthConfig{
+			JWTSecret:    "jwt-secret",
+			APIKeys:      []string{"key-1", "key-2"},
+			AdminAPIKeys: []string{"admin-key"},
+			AdminUsers:   []string{"alice"},
+		},
+		Service: ServiceConfig{RouteTimeouts: map[string]time.Duration{"/api/v1/grants/{grant}/report": 2 * time.Minute}},
+	}
+
+	settings := cfg.Redacted().Settings()
+	section := func(name string) map[string]interface{} {
+		return settings[name].(map[string]interface{})
+	}
+
+	t.Run("secrets are redacted", func(t *testing.T) {
+		assert.Equal(t, "host=db user=asbb password=*** dbname=asbb", section("database")["dsn"])
+		assert.Equal(t, "***", section("advisor")["api_key"])
+		assert.Equal(t, map[string]string{"Authorization": "***"}, section("advisor")["headers"])
+		assert.Equal(t, "***", section("integration")["asbx_api_key"])
+		assert.Equal(t, "***", section("integration")["epilog_secret"])
+		assert.Equal(t, "***", section("integration")["notification_webhook_url"])
+		assert.Equal(t, "***", section("auth")["jwt_secret"])
+		assert.Equal(t, []string{"***", "***"}, section("auth")["api_keys"])
+		assert.Equal(t, []string{"***"}, section("auth")["admin_api_keys"])
+
+		data, err := json.Marshal(settings)
+		require.NoError(t, err)
+		for _, secret := range []string{"hunter2", "advisor-key", "advisor-token", "asbx-key", "epilog-secret", "webhook-token", "jwt-secret", "key-1", "admin-key"} {
+			assert.NotContains(t, string(data), secret)
+		}
+	})
+
+	t.Run("other settings are shown", func(t *testing.T) {
+		assert.Equal(t, "postgres", section("database")["driver"])
+		assert.Equal(t, "http://advisor:8081", section("advisor")["url"])
+		assert.Equal(t, "30s", section("advisor")["timeout"])
+		assert.Equal(t, 0.5, section("integration")["fallback_cost_rate"])
+		assert.Equal(t, true, section("integration")["advisor_enabled"])
+		assert.Equal(t, []string{"alice"}, section("auth")["admin_users"])
+		assert.Equal(t, map[string]string{"/api/v1/grants/{grant}/report": "2m0s"}, section("service")["route_timeouts"])
+		assert.Contains(t, section("logging")["sampling"], "thereafter")
+		assert.Contains(t, section("budget"), "default_hold_percentage")
+	})
+
+	t.Run("an unset secret is shown empty", func(t *testing.T) {
+		unset := (&Config{}).Redacted().Settings()
+		assert.Equal(t, "", unset["auth"].(map[string]interface{})["jwt_secret"])
+		assert.Nil(t, unset["auth"].(map[string]interface{})["api_keys"])
+	})
+
+	t.Run("the configuration itself is unchanged", func(t *testing.T) {
+		assert.Equal(t, "jwt-secret", cfg.Auth.JWTSecret)
+		assert.Equal(t, []string{"key-1", "key-2"}, cfg.Auth.APIKeys)
+		assert.Equal(t, "Bearer advisor-token", cfg.Advisor.Headers["Authorization"])
+		assert.Contains(t, cfg.Database.DSN, "hunter2")
+	})
+}
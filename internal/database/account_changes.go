@@ -0,0 +1,71 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// AnyAccount is the account ID an AccountChangeFunc is given when the account hierarchy
+// changed, so any account's balances may have
+const AnyAccount int64 = 0
+
+// AccountChangeFunc is told the ID of an account whose own balances a write may have
+// changed. A transaction also changes the balances of the account's ancestors, which are
+// not named.
+type AccountChangeFunc func(accountID int64)
+
+// accountChanges reports balance-changing writes to the registered AccountChangeFunc.
+// A write inside a database transaction is reported when it is made and again once the
+// transaction commits, so a reader cannot keep balances read while it was uncommitted.
+type accountChanges struct {
+	fn      AccountChangeFunc
+	mu      sync.Mutex
+	pending map[*sql.Tx][]int64
+}
+
+// OnAccountChange registers fn to be told of every write that may change an account's
+// balances: budget transactions created, completed or escalated, allocations, transfers,
+// limit changes and balance repairs. It replaces any earlier registration and must be
+// made before the database is used concurrently.
+func (db *DB) OnAccountChange(fn AccountChangeFunc) {
+	db.changes = &accountChanges{fn: fn, pending: make(map[*sql.Tx][]int64)}
+}
+
+// accountChanged reports a write that may have changed the account's balances. A write
+// made in tx is reported again when tx commits.
+func (db *DB) accountChanged(tx *sql.Tx, accountID int64) {
+	changes := db.changes
+	if changes == nil {
+		return
+	}
+	changes.fn(accountID)
+	if tx == nil {
+		return
+	}
+	changes.mu.Lock()
+	changes.pending[tx] = append(changes.pending[tx], accountID)
+	changes.mu.Unlock()
+}
+
+// finishTransaction reports the changes made in tx again once it has committed, and
+// forgets them either way
+func (db *DB) finishTransaction(tx *sql.Tx, committed bool) {
+	changes := db.changes
+	if changes == nil {
+		return
+	}
+	changes.mu.Lock()
+	accountIDs := changes.pending[tx]
+	delete(changes.pending, tx)
+	changes.mu.Unlock()
+
+	if committed {
+		for _, accountID := range accountIDs {
+			changes.fn(accountID)
+		}
+	}
+}
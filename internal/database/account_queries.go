@@ -7,12 +7,105 @@ package database
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	"github.com/lib/pq"
+
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
+// accountColumns is the column list shared by every query that returns a full account
+const accountColumns = `id, slurm_account, name, description, budget_limit,
+		       budget_used, budget_held, has_incremental_budget, next_allocation_date,
+		       total_allocated, hold_percentage, reserved_amount, timezone, burn_rate_enabled,
+		       frozen, depleted_at, fiscal_year_start, parent_account_id, tags, estimation_source, allowed_partitions, budget_unit, dollars_per_su, start_date, end_date, status,
+		       created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanAccount scans a row selected with accountColumns into a BudgetAccount
+func scanAccount(row rowScanner) (*api.BudgetAccount, error) {
+	var account api.BudgetAccount
+	var tags []byte
+	err := row.Scan(
+		&account.ID, &account.SlurmAccount, &account.Name, &account.Description,
+		&account.BudgetLimit, &account.BudgetUsed, &account.BudgetHeld,
+		&account.HasIncrementalBudget, &account.NextAllocationDate, &account.TotalAllocated,
+		&account.HoldPercentage, &account.ReservedAmount, &account.Timezone, &account.BurnRateEnabled,
+		&account.Frozen, &account.DepletedAt, &account.FiscalYearStart, &account.ParentAccountID, &tags, &account.EstimationSource, pq.Array(&account.AllowedPartitions), &account.BudgetUnit, &account.DollarsPerSU, &account.StartDate, &account.EndDate, &account.Status,
+		&account.CreatedAt, &account.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(account.AllowedPartitions) == 0 {
+		account.AllowedPartitions = nil
+	}
+	if len(tags) > 0 {
+		if err := json.Unmarshal(tags, &account.Tags); err != nil {
+			return nil, fmt.Errorf("decode account tags: %w", err)
+		}
+		if len(account.Tags) == 0 {
+			account.Tags = nil
+		}
+	}
+	return &account, nil
+}
+
+// encodeTags encodes account tags for the tags column, where no tags is an empty object
+func encodeTags(tags map[string]string) ([]byte, error) {
+	if tags == nil {
+		tags = map[string]string{}
+	}
+	return json.Marshal(tags)
+}
+
+// partitionsOrEmpty returns an account's allowed partitions for the allowed_partitions
+// column, which is never NULL; an empty list allows every partition
+func partitionsOrEmpty(partitions []string) []string {
+	if partitions == nil {
+		return []string{}
+	}
+	return partitions
+}
+
+// tagConditions builds the conditions selecting accounts, whose tags are in column, that
+// match every filter. A key and value is a containment test and a bare key an existence
+// test, both served by the GIN index on tags.
+func tagConditions(column string, filters []api.TagFilter, argIndex int) ([]string, []interface{}, int, error) {
+	var conditions []string
+	var args []interface{}
+	for _, filter := range filters {
+		if !filter.HasValue {
+			conditions = append(conditions, fmt.Sprintf("%s ? $%d", column, argIndex))
+			args = append(args, filter.Key)
+			argIndex++
+			continue
+		}
+		contained, err := json.Marshal(map[string]string{filter.Key: filter.Value})
+		if err != nil {
+			return nil, nil, argIndex, err
+		}
+		conditions = append(conditions, fmt.Sprintf("%s @> $%d::jsonb", column, argIndex))
+		args = append(args, string(contained))
+		argIndex++
+	}
+	return conditions, args, argIndex, nil
+}
+
+// timezoneOrDefault returns the stored time zone for a possibly empty request value
+func timezoneOrDefault(timezone string) string {
+	if timezone == "" {
+		return api.DefaultTimezone
+	}
+	return timezone
+}
+
 // AccountQueries provides database operations for budget accounts
 type AccountQueries struct {
 	db *DB
@@ -25,20 +118,9 @@ func NewAccountQueries(db *DB) *AccountQueries {
 
 // GetAccountByID retrieves a budget account by ID
 func (q *AccountQueries) GetAccountByID(ctx context.Context, id int64) (*api.BudgetAccount, error) {
-	query := `
-		SELECT id, slurm_account, name, description, budget_limit,
-		       budget_used, budget_held, start_date, end_date, status,
-		       created_at, updated_at
-		FROM budget_accounts
-		WHERE id = $1`
+	query := `SELECT ` + accountColumns + ` FROM budget_accounts WHERE id = $1`
 
-	var account api.BudgetAccount
-	err := q.db.QueryRowContext(ctx, query, id).Scan(
-		&account.ID, &account.SlurmAccount, &account.Name, &account.Description,
-		&account.BudgetLimit, &account.BudgetUsed, &account.BudgetHeld,
-		&account.StartDate, &account.EndDate, &account.Status,
-		&account.CreatedAt, &account.UpdatedAt,
-	)
+	account, err := scanAccount(q.db.QueryRowContext(ctx, query, id))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -47,25 +129,14 @@ func (q *AccountQueries) GetAccountByID(ctx context.Context, id int64) (*api.Bud
 		return nil, api.NewDatabaseError("get account by ID", err)
 	}
 
-	return &account, nil
+	return account, nil
 }
 
 // GetAccountByName retrieves a budget account by SLURM account name
 func (q *AccountQueries) GetAccountByName(ctx context.Context, slurmAccount string) (*api.BudgetAccount, error) {
-	query := `
-		SELECT id, slurm_account, name, description, budget_limit,
-		       budget_used, budget_held, start_date, end_date, status,
-		       created_at, updated_at
-		FROM budget_accounts
-		WHERE slurm_account = $1`
+	query := `SELECT ` + accountColumns + ` FROM budget_accounts WHERE slurm_account = $1`
 
-	var account api.BudgetAccount
-	err := q.db.QueryRowContext(ctx, query, slurmAccount).Scan(
-		&account.ID, &account.SlurmAccount, &account.Name, &account.Description,
-		&account.BudgetLimit, &account.BudgetUsed, &account.BudgetHeld,
-		&account.StartDate, &account.EndDate, &account.Status,
-		&account.CreatedAt, &account.UpdatedAt,
-	)
+	account, err := scanAccount(q.db.QueryRowContext(ctx, query, slurmAccount))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -74,16 +145,33 @@ func (q *AccountQueries) GetAccountByName(ctx context.Context, slurmAccount stri
 		return nil, api.NewDatabaseError("get account by name", err)
 	}
 
-	return &account, nil
+	return account, nil
+}
+
+// GetPartitionLimit retrieves an account's budget limit on a partition, or nil when the
+// account has none there
+func (q *AccountQueries) GetPartitionLimit(ctx context.Context, accountID int64, partition string) (*api.BudgetPartitionLimit, error) {
+	query := `
+		SELECT id, account_id, partition, limit_amount, used_amount, held_amount
+		FROM budget_partition_limits
+		WHERE account_id = $1 AND partition = $2`
+
+	var limit api.BudgetPartitionLimit
+	err := q.db.QueryRowContext(ctx, query, accountID, partition).Scan(
+		&limit.ID, &limit.AccountID, &limit.Partition, &limit.Limit, &limit.Used, &limit.Held)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("get partition limit", err)
+	}
+
+	return &limit, nil
 }
 
 // ListAccounts retrieves a list of budget accounts with optional filtering
 func (q *AccountQueries) ListAccounts(ctx context.Context, req *api.ListAccountsRequest) ([]*api.BudgetAccount, error) {
-	baseQuery := `
-		SELECT id, slurm_account, name, description, budget_limit,
-		       budget_used, budget_held, start_date, end_date, status,
-		       created_at, updated_at
-		FROM budget_accounts`
+	baseQuery := `SELECT ` + accountColumns + ` FROM budget_accounts`
 
 	var conditions []string
 	var args []interface{}
@@ -96,6 +184,21 @@ func (q *AccountQueries) ListAccounts(ctx context.Context, req *api.ListAccounts
 		argIndex++
 	}
 
+	// Add tag filters if specified
+	if len(req.Tags) > 0 {
+		filters, err := api.ParseTagFilters(req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tagConds, tagArgs, next, err := tagConditions("tags", filters, argIndex)
+		if err != nil {
+			return nil, api.NewDatabaseError("encode tag filter", err)
+		}
+		conditions = append(conditions, tagConds...)
+		args = append(args, tagArgs...)
+		argIndex = next
+	}
+
 	// Build WHERE clause
 	if len(conditions) > 0 {
 		baseQuery += " WHERE " + strings.Join(conditions, " AND ")
@@ -128,17 +231,11 @@ func (q *AccountQueries) ListAccounts(ctx context.Context, req *api.ListAccounts
 
 	var accounts []*api.BudgetAccount
 	for rows.Next() {
-		var account api.BudgetAccount
-		err := rows.Scan(
-			&account.ID, &account.SlurmAccount, &account.Name, &account.Description,
-			&account.BudgetLimit, &account.BudgetUsed, &account.BudgetHeld,
-			&account.StartDate, &account.EndDate, &account.Status,
-			&account.CreatedAt, &account.UpdatedAt,
-		)
+		account, err := scanAccount(rows)
 		if err != nil {
 			return nil, api.NewDatabaseError("scan account row", err)
 		}
-		accounts = append(accounts, &account)
+		accounts = append(accounts, account)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -148,24 +245,40 @@ func (q *AccountQueries) ListAccounts(ctx context.Context, req *api.ListAccounts
 	return accounts, nil
 }
 
+// rowQuerier is satisfied by both *DB and *sql.Tx
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
 // CreateAccount creates a new budget account
 func (q *AccountQueries) CreateAccount(ctx context.Context, req *api.CreateAccountRequest) (*api.BudgetAccount, error) {
+	account, err := insertAccount(ctx, q.db, req)
+	if err == nil && req.ParentAccount != "" {
+		q.db.accountChanged(nil, AnyAccount)
+	}
+	return account, err
+}
+
+// insertAccount inserts the account req describes with db, which may be a transaction
+func insertAccount(ctx context.Context, db rowQuerier, req *api.CreateAccountRequest) (*api.BudgetAccount, error) {
 	query := `
-		INSERT INTO budget_accounts (slurm_account, name, description, budget_limit, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, slurm_account, name, description, budget_limit, budget_used, budget_held,
-		          start_date, end_date, status, created_at, updated_at`
+		INSERT INTO budget_accounts (slurm_account, name, description, budget_limit, start_date, end_date, hold_percentage, reserved_amount, timezone, burn_rate_enabled, parent_account_id, fiscal_year_start, tags, estimation_source, allowed_partitions, budget_unit, dollars_per_su)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+		        (SELECT id FROM budget_accounts WHERE slurm_account = NULLIF($11, '')), NULLIF($12, ''), $13::jsonb, NULLIF($14, ''), $15,
+		        COALESCE(NULLIF($16, ''), 'dollars'), $17)
+		RETURNING ` + accountColumns
 
-	var account api.BudgetAccount
-	err := q.db.QueryRowContext(ctx, query,
+	tags, err := encodeTags(req.Tags)
+	if err != nil {
+		return nil, api.NewDatabaseError("encode account tags", err)
+	}
+
+	account, err := scanAccount(db.QueryRowContext(ctx, query,
 		req.SlurmAccount, req.Name, req.Description,
-		req.BudgetLimit, req.StartDate, req.EndDate,
-	).Scan(
-		&account.ID, &account.SlurmAccount, &account.Name, &account.Description,
-		&account.BudgetLimit, &account.BudgetUsed, &account.BudgetHeld,
-		&account.StartDate, &account.EndDate, &account.Status,
-		&account.CreatedAt, &account.UpdatedAt,
-	)
+		req.BudgetLimit, req.StartDate, req.EndDate, req.HoldPercentage, req.ReservedAmount, timezoneOrDefault(req.Timezone), req.BurnRateEnabled,
+		req.ParentAccount, req.FiscalYearStart, string(tags), req.EstimationSource, pq.Array(partitionsOrEmpty(req.AllowedPartitions)),
+		req.BudgetUnit, req.DollarsPerSU,
+	))
 
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate") || strings.Contains(err.Error(), "unique") {
@@ -175,7 +288,82 @@ func (q *AccountQueries) CreateAccount(ctx context.Context, req *api.CreateAccou
 		return nil, api.NewDatabaseError("create account", err)
 	}
 
-	return &account, nil
+	return account, nil
+}
+
+// CloneAccount creates the account req describes and copies the source account's partition
+// limits to it, all in one transaction. With copySchedules the source's active and paused
+// allocation schedules are copied too, restarted from the new account's start date with
+// nothing allocated; a schedule with an end date ends with the new account. The new
+// account's balances start at zero. It returns the account with the number of partition
+// limits and schedules copied.
+func (q *AccountQueries) CloneAccount(ctx context.Context, sourceID int64, req *api.CreateAccountRequest, copySchedules bool) (*api.AccountCloneResponse, error) {
+	resp := &api.AccountCloneResponse{}
+	err := q.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		account, err := insertAccount(ctx, tx, req)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO budget_partition_limits (account_id, partition, limit_amount)
+			SELECT $1, partition, limit_amount
+			FROM budget_partition_limits
+			WHERE account_id = $2`, account.ID, sourceID)
+		if err != nil {
+			return api.NewDatabaseError("copy partition limits", err)
+		}
+		if resp.PartitionLimitsCopied, err = result.RowsAffected(); err != nil {
+			return api.NewDatabaseError("copy partition limits", err)
+		}
+
+		if copySchedules {
+			result, err := tx.ExecContext(ctx, `
+				INSERT INTO budget_allocation_schedules
+					(account_id, total_budget, allocation_amount, allocation_frequency, start_date, end_date,
+					 next_allocation_date, remaining_budget, status, auto_allocate)
+				SELECT $1, total_budget, allocation_amount, allocation_frequency, $3,
+				       CASE WHEN end_date IS NULL THEN NULL ELSE $4::timestamptz END,
+				       $3, total_budget, status, auto_allocate
+				FROM budget_allocation_schedules
+				WHERE account_id = $2 AND status IN ('active', 'paused')
+				ORDER BY id`, account.ID, sourceID, req.StartDate, req.EndDate)
+			if err != nil {
+				return api.NewDatabaseError("copy allocation schedules", err)
+			}
+			if resp.SchedulesCopied, err = result.RowsAffected(); err != nil {
+				return api.NewDatabaseError("copy allocation schedules", err)
+			}
+
+			if resp.SchedulesCopied > 0 {
+				account, err = scanAccount(tx.QueryRowContext(ctx, `
+					UPDATE budget_accounts
+					SET has_incremental_budget = TRUE,
+					    next_allocation_date = (
+					        SELECT MIN(next_allocation_date)
+					        FROM budget_allocation_schedules
+					        WHERE account_id = $1 AND status = 'active'
+					    ),
+					    updated_at = NOW()
+					WHERE id = $1
+					RETURNING `+accountColumns, account.ID))
+				if err != nil {
+					return api.NewDatabaseError("mark account incremental", err)
+				}
+			}
+		}
+
+		resp.Account = account
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.ParentAccount != "" {
+		q.db.accountChanged(nil, AnyAccount)
+	}
+
+	return resp, nil
 }
 
 // UpdateAccount updates an existing budget account
@@ -221,32 +409,92 @@ func (q *AccountQueries) UpdateAccount(ctx context.Context, slurmAccount string,
 		argIndex++
 	}
 
+	if req.HoldPercentage != nil {
+		setParts = append(setParts, fmt.Sprintf("hold_percentage = $%d", argIndex))
+		args = append(args, *req.HoldPercentage)
+		argIndex++
+	}
+
+	if req.ReservedAmount != nil {
+		setParts = append(setParts, fmt.Sprintf("reserved_amount = $%d", argIndex))
+		args = append(args, *req.ReservedAmount)
+		argIndex++
+	}
+
+	if req.Timezone != nil {
+		setParts = append(setParts, fmt.Sprintf("timezone = $%d", argIndex))
+		args = append(args, *req.Timezone)
+		argIndex++
+	}
+
+	if req.BurnRateEnabled != nil {
+		setParts = append(setParts, fmt.Sprintf("burn_rate_enabled = $%d", argIndex))
+		args = append(args, *req.BurnRateEnabled)
+		argIndex++
+	}
+
+	if req.Frozen != nil {
+		setParts = append(setParts, fmt.Sprintf("frozen = $%d", argIndex))
+		args = append(args, *req.Frozen)
+		argIndex++
+	}
+
+	if req.FiscalYearStart != nil {
+		setParts = append(setParts, fmt.Sprintf("fiscal_year_start = NULLIF($%d, '')", argIndex))
+		args = append(args, *req.FiscalYearStart)
+		argIndex++
+	}
+
+	if req.Tags != nil {
+		tags, err := encodeTags(req.Tags)
+		if err != nil {
+			return nil, api.NewDatabaseError("encode account tags", err)
+		}
+		setParts = append(setParts, fmt.Sprintf("tags = $%d::jsonb", argIndex))
+		args = append(args, string(tags))
+		argIndex++
+	}
+
+	if req.EstimationSource != nil {
+		setParts = append(setParts, fmt.Sprintf("estimation_source = NULLIF($%d, '')", argIndex))
+		args = append(args, *req.EstimationSource)
+		argIndex++
+	}
+
+	if req.AllowedPartitions != nil {
+		setParts = append(setParts, fmt.Sprintf("allowed_partitions = $%d", argIndex))
+		args = append(args, pq.Array(req.AllowedPartitions))
+		argIndex++
+	}
+
+	if req.DollarsPerSU != nil {
+		setParts = append(setParts, fmt.Sprintf("dollars_per_su = $%d", argIndex))
+		args = append(args, *req.DollarsPerSU)
+		argIndex++
+	}
+
 	if len(setParts) == 0 {
 		return q.GetAccountByName(ctx, slurmAccount)
 	}
 
+	// An operator setting the status or freeze takes over from the depletion policy
+	if req.Status != nil || req.Frozen != nil {
+		setParts = append(setParts, "depleted_at = NULL")
+	}
+
 	// Always update updated_at
-	setParts = append(setParts, fmt.Sprintf("updated_at = $%d", argIndex))
-	args = append(args, "NOW()")
-	argIndex++
+	setParts = append(setParts, "updated_at = NOW()")
 
 	query := fmt.Sprintf(`
 		UPDATE budget_accounts
 		SET %s
 		WHERE slurm_account = $%d
-		RETURNING id, slurm_account, name, description, budget_limit, budget_used, budget_held,
-		          start_date, end_date, status, created_at, updated_at`,
+		RETURNING `+accountColumns,
 		strings.Join(setParts, ", "), argIndex)
 
 	args = append(args, slurmAccount)
 
-	var account api.BudgetAccount
-	err := q.db.QueryRowContext(ctx, query, args...).Scan(
-		&account.ID, &account.SlurmAccount, &account.Name, &account.Description,
-		&account.BudgetLimit, &account.BudgetUsed, &account.BudgetHeld,
-		&account.StartDate, &account.EndDate, &account.Status,
-		&account.CreatedAt, &account.UpdatedAt,
-	)
+	account, err := scanAccount(q.db.QueryRowContext(ctx, query, args...))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -254,28 +502,149 @@ func (q *AccountQueries) UpdateAccount(ctx context.Context, slurmAccount string,
 		}
 		return nil, api.NewDatabaseError("update account", err)
 	}
+	q.db.accountChanged(nil, account.ID)
 
-	return &account, nil
+	return account, nil
 }
 
-// DeleteAccount deletes a budget account
-func (q *AccountQueries) DeleteAccount(ctx context.Context, slurmAccount string) error {
-	query := `DELETE FROM budget_accounts WHERE slurm_account = $1`
+// ListAncestors returns an account's parent, grandparent and so on, nearest first
+func (q *AccountQueries) ListAncestors(ctx context.Context, accountID int64) ([]*api.BudgetAccount, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM budget_accounts
+		JOIN account_and_ancestors($1) chain ON chain.account_id = budget_accounts.id
+		WHERE chain.depth > 0
+		ORDER BY chain.depth ASC`
 
-	result, err := q.db.ExecContext(ctx, query, slurmAccount)
+	rows, err := q.db.QueryContext(ctx, query, accountID)
 	if err != nil {
-		return api.NewDatabaseError("delete account", err)
+		return nil, api.NewDatabaseError("list account ancestors", err)
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
 
-	rowsAffected, err := result.RowsAffected()
+	var ancestors []*api.BudgetAccount
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan ancestor row", err)
+		}
+		ancestors = append(ancestors, account)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate ancestor rows", err)
+	}
+
+	return ancestors, nil
+}
+
+// ListDescendantIDs returns the IDs of an account's children, grandchildren and so on
+func (q *AccountQueries) ListDescendantIDs(ctx context.Context, accountID int64) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx,
+		`SELECT account_id FROM account_and_descendants($1) WHERE depth > 0 ORDER BY account_id`, accountID)
 	if err != nil {
-		return api.NewDatabaseError("get affected rows", err)
+		return nil, api.NewDatabaseError("list account descendants", err)
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
 
-	if rowsAffected == 0 {
-		return api.NewAccountNotFoundError(slurmAccount)
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, api.NewDatabaseError("scan descendant row", err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate descendant rows", err)
 	}
 
+	return ids, nil
+}
+
+// ListSiblings returns the other accounts under the account's parent or funded by its
+// grant, in name order
+func (q *AccountQueries) ListSiblings(ctx context.Context, account *api.BudgetAccount) ([]*api.BudgetAccount, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM budget_accounts
+		WHERE id <> $1
+		  AND (parent_account_id = $2
+		       OR grant_id = (SELECT grant_id FROM budget_accounts WHERE id = $1))
+		ORDER BY slurm_account`
+
+	rows, err := q.db.QueryContext(ctx, query, account.ID, account.ParentAccountID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list sibling accounts", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var siblings []*api.BudgetAccount
+	for rows.Next() {
+		sibling, err := scanAccount(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan sibling row", err)
+		}
+		siblings = append(siblings, sibling)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate sibling rows", err)
+	}
+
+	return siblings, nil
+}
+
+// SetParent moves an account under a new parent, or detaches it when parentID is nil,
+// carrying its used and held balances from the old ancestors to the new ones
+func (q *AccountQueries) SetParent(ctx context.Context, accountID int64, parentID *int64) error {
+	if _, err := q.db.ExecContext(ctx, `SELECT set_account_parent($1, $2)`, accountID, parentID); err != nil {
+		return api.NewDatabaseError("set account parent", err)
+	}
+	q.db.accountChanged(nil, AnyAccount)
+	return nil
+}
+
+// DeleteAccount deletes a budget account. It is detached from its parent first, so its
+// balances come off its ancestors' along with it.
+func (q *AccountQueries) DeleteAccount(ctx context.Context, slurmAccount string) error {
+	err := q.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`SELECT set_account_parent(id, NULL) FROM budget_accounts WHERE slurm_account = $1`, slurmAccount); err != nil {
+			return api.NewDatabaseError("detach account", err)
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM budget_accounts WHERE slurm_account = $1`, slurmAccount)
+		if err != nil {
+			return api.NewDatabaseError("delete account", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return api.NewDatabaseError("get affected rows", err)
+		}
+
+		if rowsAffected == 0 {
+			return api.NewAccountNotFoundError(slurmAccount)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	q.db.accountChanged(nil, AnyAccount)
+
 	return nil
 }
 
@@ -307,6 +676,147 @@ func (q *AccountQueries) GetAccountSummary(ctx context.Context, accountID int64)
 	return q.GetAccountByID(ctx, accountID)
 }
 
+// SummarizeAccounts counts accounts and pending holds and totals the balances of top-level
+// accounts, leaving out archived ones. A hold is pending until it has been charged or
+// released, whether or not its balance was applied.
+func (q *AccountQueries) SummarizeAccounts(ctx context.Context) (*api.AdminSummary, error) {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE status = 'active'),
+		       COALESCE(SUM(budget_limit) FILTER (WHERE parent_account_id IS NULL), 0),
+		       COALESCE(SUM(budget_used) FILTER (WHERE parent_account_id IS NULL), 0),
+		       COALESCE(SUM(budget_held) FILTER (WHERE parent_account_id IS NULL), 0),
+		       (SELECT COUNT(*) FROM budget_transactions bt
+		        WHERE bt.type = 'hold' AND bt.status IN ('pending', 'completed')
+		          AND NOT EXISTS (
+		            SELECT 1 FROM budget_transactions released
+		            WHERE released.parent_transaction_id = bt.transaction_id
+		          ))
+		FROM budget_accounts
+		WHERE status <> 'archived'`
+
+	var summary api.AdminSummary
+	err := q.db.QueryRowContext(ctx, query).Scan(&summary.Accounts, &summary.ActiveAccounts,
+		&summary.TotalLimit, &summary.TotalUsed, &summary.TotalHeld, &summary.PendingHolds)
+	if err != nil {
+		return nil, api.NewDatabaseError("summarize accounts", err)
+	}
+
+	summary.TotalAvailable = summary.TotalLimit - summary.TotalUsed - summary.TotalHeld
+	return &summary, nil
+}
+
+// Overview aggregates account balances org-wide in one pass, with breakdowns by status
+// and by funding agency, optionally narrowed to one agency or cost center. Within the
+// accounts selected, only those with no selected ancestor add their balances, since a
+// parent's balances already include its descendants'.
+func (q *AccountQueries) Overview(ctx context.Context, req *api.OverviewRequest) (*api.Overview, error) {
+	var conditions []string
+	var args []interface{}
+	argIndex := 1
+
+	if req.FundingAgency != "" {
+		conditions = append(conditions, fmt.Sprintf("ga.funding_agency = $%d", argIndex))
+		args = append(args, req.FundingAgency)
+		argIndex++
+	}
+	if req.CostCenter != "" {
+		conditions = append(conditions, fmt.Sprintf("ga.cost_center = $%d", argIndex))
+		args = append(args, req.CostCenter)
+		argIndex++
+	}
+	if len(req.Tags) > 0 {
+		filters, err := api.ParseTagFilters(req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tagConds, tagArgs, _, err := tagConditions("ba.tags", filters, argIndex)
+		if err != nil {
+			return nil, api.NewDatabaseError("encode tag filter", err)
+		}
+		conditions = append(conditions, tagConds...)
+		args = append(args, tagArgs...)
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := `
+		WITH selected AS (
+			SELECT ba.id, ba.status, ba.budget_limit, ba.budget_used, ba.budget_held,
+			       COALESCE(ga.funding_agency, '') AS agency
+			FROM budget_accounts ba
+			LEFT JOIN grant_accounts ga ON ga.id = ba.grant_id
+			` + whereClause + `
+		), rolled AS (
+			SELECT selected.*, NOT EXISTS (
+				SELECT 1 FROM account_and_ancestors(selected.id) chain
+				JOIN selected ancestor ON ancestor.id = chain.account_id
+				WHERE chain.depth > 0
+			) AS rolls_up
+			FROM selected
+		)
+		SELECT GROUPING(status), GROUPING(agency), COALESCE(status, ''), COALESCE(agency, ''),
+		       COUNT(*),
+		       COALESCE(SUM(budget_limit) FILTER (WHERE rolls_up), 0),
+		       COALESCE(SUM(budget_used) FILTER (WHERE rolls_up), 0),
+		       COALESCE(SUM(budget_held) FILTER (WHERE rolls_up), 0),
+		       COUNT(*) FILTER (WHERE status = 'active'),
+		       COALESCE(SUM(budget_limit) FILTER (WHERE rolls_up AND status = 'active'), 0),
+		       COALESCE(SUM(budget_used) FILTER (WHERE rolls_up AND status = 'active'), 0),
+		       COALESCE(SUM(budget_held) FILTER (WHERE rolls_up AND status = 'active'), 0)
+		FROM rolled
+		GROUP BY GROUPING SETS ((), (status), (agency))
+		ORDER BY GROUPING(status), GROUPING(agency), 3, 4`
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("query overview", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	overview := &api.Overview{
+		FundingAgency: req.FundingAgency,
+		CostCenter:    req.CostCenter,
+		Tags:          req.Tags,
+		ByStatus:      []api.StatusOverview{},
+		ByAgency:      []api.AgencyOverview{},
+	}
+	for rows.Next() {
+		var statusGrouped, agencyGrouped int
+		var status, agency string
+		var all, active api.OverviewTotals
+		if err := rows.Scan(&statusGrouped, &agencyGrouped, &status, &agency,
+			&all.Accounts, &all.TotalAllocated, &all.TotalSpent, &all.TotalHeld,
+			&active.Accounts, &active.TotalAllocated, &active.TotalSpent, &active.TotalHeld); err != nil {
+			return nil, api.NewDatabaseError("scan overview row", err)
+		}
+
+		// GROUPING() is 1 for a column rolled up in the row's grouping set
+		switch {
+		case statusGrouped == 1 && agencyGrouped == 1:
+			overview.Totals = active.WithUtilization()
+		case agencyGrouped == 1:
+			overview.ByStatus = append(overview.ByStatus, api.StatusOverview{Status: status, OverviewTotals: all.WithUtilization()})
+		case active.Accounts > 0:
+			overview.ByAgency = append(overview.ByAgency, api.AgencyOverview{FundingAgency: agency, OverviewTotals: active.WithUtilization()})
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate overview rows", err)
+	}
+
+	return overview, nil
+}
+
 // UpdateAccountBalance updates account balances - called by triggers but available for manual use
 func (q *AccountQueries) UpdateAccountBalance(ctx context.Context, accountID int64, budgetUsed, budgetHeld float64) error {
 	query := `
@@ -327,6 +837,196 @@ func (q *AccountQueries) UpdateAccountBalance(ctx context.Context, accountID int
 	if rowsAffected == 0 {
 		return api.NewAccountNotFoundError(fmt.Sprintf("ID:%d", accountID))
 	}
+	q.db.accountChanged(nil, accountID)
+
+	return nil
+}
+
+// MarkDepleted applies the depletion policy to an active, unfrozen account whose spendable
+// budget is used up, suspending it or freezing it, and reports whether it did. The balance
+// is checked again here, so a stale read cannot deplete an account that has budget left.
+func (q *AccountQueries) MarkDepleted(ctx context.Context, accountID int64, suspend bool) (bool, error) {
+	result, err := q.db.ExecContext(ctx, `
+		UPDATE budget_accounts
+		SET depleted_at = NOW(),
+		    status = CASE WHEN $2 THEN 'suspended' ELSE status END,
+		    frozen = NOT $2,
+		    updated_at = NOW()
+		WHERE id = $1 AND status = 'active' AND NOT frozen AND depleted_at IS NULL
+		  AND budget_limit > 0
+		  AND budget_limit - budget_used - budget_held - reserved_amount <= 0`,
+		accountID, suspend)
+	if err != nil {
+		return false, api.NewDatabaseError("mark account depleted", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, api.NewDatabaseError("get affected rows", err)
+	}
+	if rowsAffected > 0 {
+		q.db.accountChanged(nil, accountID)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ClearDepletion reactivates an account the depletion policy suspended or froze once it
+// has spendable budget again, returning the account, or nil when it was left as it was.
+// An account past its end date stays suspended.
+func (q *AccountQueries) ClearDepletion(ctx context.Context, accountID int64) (*api.BudgetAccount, error) {
+	account, err := scanAccount(q.db.QueryRowContext(ctx, `
+		UPDATE budget_accounts
+		SET depleted_at = NULL,
+		    status = CASE WHEN status = 'suspended' THEN 'active' ELSE status END,
+		    frozen = FALSE,
+		    updated_at = NOW()
+		WHERE id = $1 AND depleted_at IS NOT NULL AND end_date > NOW()
+		  AND budget_limit - budget_used - budget_held - reserved_amount > 0
+		RETURNING `+accountColumns, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("clear account depletion", err)
+	}
+	q.db.accountChanged(nil, accountID)
+	return account, nil
+}
+
+// LockAccountBalance locks an account row for the rest of the transaction and returns its
+// cached used and held balances
+func (q *AccountQueries) LockAccountBalance(ctx context.Context, tx *sql.Tx, accountID int64) (float64, float64, error) {
+	var used, held float64
+	err := tx.QueryRowContext(ctx,
+		`SELECT budget_used, budget_held FROM budget_accounts WHERE id = $1 FOR UPDATE`,
+		accountID).Scan(&used, &held)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, 0, api.NewAccountNotFoundError(fmt.Sprintf("ID:%d", accountID))
+		}
+		return 0, 0, api.NewDatabaseError("lock account balance", err)
+	}
+	return used, held, nil
+}
+
+// LockAccount locks an account row for the rest of the transaction and returns it
+func (q *AccountQueries) LockAccount(ctx context.Context, tx *sql.Tx, accountID int64) (*api.BudgetAccount, error) {
+	account, err := scanAccount(tx.QueryRowContext(ctx,
+		`SELECT `+accountColumns+` FROM budget_accounts WHERE id = $1 FOR UPDATE`, accountID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, api.NewAccountNotFoundError(fmt.Sprintf("ID:%d", accountID))
+		}
+		return nil, api.NewDatabaseError("lock account", err)
+	}
+	return account, nil
+}
+
+// RepairAccountBalance overwrites an account's cached used and held balances with the
+// repaired values and records the correction in the balance repair audit trail
+func (q *AccountQueries) RepairAccountBalance(ctx context.Context, tx *sql.Tx, repair *api.BalanceRepair) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE budget_accounts
+		SET budget_used = $2, budget_held = $3, updated_at = NOW()
+		WHERE id = $1`,
+		repair.AccountID, repair.RepairedUsed, repair.RepairedHeld)
+	if err != nil {
+		return api.NewDatabaseError("repair account balance", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return api.NewDatabaseError("get affected rows", err)
+	}
+
+	if rowsAffected == 0 {
+		return api.NewAccountNotFoundError(fmt.Sprintf("ID:%d", repair.AccountID))
+	}
+	q.db.accountChanged(tx, repair.AccountID)
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO budget_balance_repairs (
+			account_id, previous_used, previous_held, repaired_used, repaired_held,
+			reason, repaired_by
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''))
+		RETURNING id, created_at`,
+		repair.AccountID, repair.PreviousUsed, repair.PreviousHeld, repair.RepairedUsed, repair.RepairedHeld,
+		repair.Reason, repair.RepairedBy,
+	).Scan(&repair.ID, &repair.CreatedAt)
+	if err != nil {
+		return api.NewDatabaseError("record balance repair", err)
+	}
+
+	return nil
+}
+
+// ListBalanceRepairs returns the balance repairs recorded for an account, newest first
+func (q *AccountQueries) ListBalanceRepairs(ctx context.Context, accountID int64) ([]*api.BalanceRepair, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT id, account_id, previous_used, previous_held, repaired_used, repaired_held,
+		       COALESCE(reason, ''), COALESCE(repaired_by, ''), created_at
+		FROM budget_balance_repairs
+		WHERE account_id = $1
+		ORDER BY created_at DESC, id DESC`, accountID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list balance repairs", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	repairs := []*api.BalanceRepair{}
+	for rows.Next() {
+		var repair api.BalanceRepair
+		if err := rows.Scan(&repair.ID, &repair.AccountID, &repair.PreviousUsed, &repair.PreviousHeld,
+			&repair.RepairedUsed, &repair.RepairedHeld, &repair.Reason, &repair.RepairedBy, &repair.CreatedAt); err != nil {
+			return nil, api.NewDatabaseError("scan balance repair", err)
+		}
+		repairs = append(repairs, &repair)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate balance repairs", err)
+	}
+
+	return repairs, nil
+}
+
+// DrawReserve reduces an account's reserved amount, failing if the reserve is smaller than amount
+func (q *AccountQueries) DrawReserve(ctx context.Context, tx *sql.Tx, accountID int64, amount float64) error {
+	query := `
+		UPDATE budget_accounts
+		SET reserved_amount = reserved_amount - $2, updated_at = NOW()
+		WHERE id = $1 AND reserved_amount >= $2`
+
+	var execer interface {
+		ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	}
+
+	if tx != nil {
+		execer = tx
+	} else {
+		execer = q.db
+	}
+
+	result, err := execer.ExecContext(ctx, query, accountID, amount)
+	if err != nil {
+		return api.NewDatabaseError("draw reserve", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return api.NewDatabaseError("get affected rows", err)
+	}
+
+	if rowsAffected == 0 {
+		return api.NewBudgetError(api.ErrCodeInsufficientBudget,
+			fmt.Sprintf("Reserve for account ID:%d is smaller than $%.2f", accountID, amount))
+	}
+	q.db.accountChanged(tx, accountID)
 
 	return nil
 }
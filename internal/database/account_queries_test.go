@@ -0,0 +1,25 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestTagConditions(t *testing.T) {
+	conditions, args, next, err := tagConditions("ba.tags", []api.TagFilter{
+		{Key: "department", Value: "physics", HasValue: true},
+		{Key: "funding"},
+	}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ba.tags @> $3::jsonb", "ba.tags ? $4"}, conditions)
+	assert.Equal(t, []interface{}{`{"department":"physics"}`, "funding"}, args)
+	assert.Equal(t, 5, next)
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// AccuracyQueries provides database operations for cost estimate accuracy history
+type AccuracyQueries struct {
+	db *DB
+}
+
+// NewAccuracyQueries creates a new AccuracyQueries instance
+func NewAccuracyQueries(db *DB) *AccuracyQueries {
+	return &AccuracyQueries{db: db}
+}
+
+// RecordEstimateAccuracy records a reconciled job's estimated and actual cost for its
+// research domain
+func (q *AccuracyQueries) RecordEstimateAccuracy(ctx context.Context, domain, jobID string, estimatedCost, actualCost float64) error {
+	_, err := q.db.ExecContext(ctx, `
+		INSERT INTO domain_estimate_accuracy (research_domain, job_id, estimated_cost, actual_cost)
+		VALUES ($1, NULLIF($2, ''), $3, $4)`,
+		domain, jobID, estimatedCost, actualCost)
+	if err != nil {
+		return api.NewDatabaseError("record estimate accuracy", err)
+	}
+	return nil
+}
+
+// DomainCostRatio returns the ratio of actual to estimated cost over a research domain's
+// most recent reconciled jobs, up to limit of them, along with the number of jobs used
+func (q *AccuracyQueries) DomainCostRatio(ctx context.Context, domain string, limit int) (float64, int, error) {
+	var ratio float64
+	var samples int
+	err := q.db.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(actual_cost) / NULLIF(SUM(estimated_cost), 0), 0), COUNT(*)
+		FROM (
+			SELECT actual_cost, estimated_cost
+			FROM domain_estimate_accuracy
+			WHERE research_domain = $1
+			ORDER BY recorded_at DESC, id DESC
+			LIMIT $2
+		) recent`,
+		domain, limit).Scan(&ratio, &samples)
+	if err != nil {
+		return 0, 0, api.NewDatabaseError("get domain cost ratio", err)
+	}
+	return ratio, samples, nil
+}
+
+// RecordScriptCost records a reconciled job's actual cost against the hash of its job script
+func (q *AccuracyQueries) RecordScriptCost(ctx context.Context, scriptHash, jobID string, actualCost float64) error {
+	_, err := q.db.ExecContext(ctx, `
+		INSERT INTO job_script_costs (script_hash, job_id, actual_cost)
+		VALUES ($1, NULLIF($2, ''), $3)`,
+		scriptHash, jobID, actualCost)
+	if err != nil {
+		return api.NewDatabaseError("record script cost", err)
+	}
+	return nil
+}
+
+// ScriptCostHistory returns the mean actual cost of the most recent reconciled jobs run
+// from a job script, up to limit of them, along with the number of jobs used
+func (q *AccuracyQueries) ScriptCostHistory(ctx context.Context, scriptHash string, limit int) (float64, int, error) {
+	var mean float64
+	var samples int
+	err := q.db.QueryRowContext(ctx, `
+		SELECT COALESCE(AVG(actual_cost), 0), COUNT(*)
+		FROM (
+			SELECT actual_cost
+			FROM job_script_costs
+			WHERE script_hash = $1
+			ORDER BY recorded_at DESC, id DESC
+			LIMIT $2
+		) recent`,
+		scriptHash, limit).Scan(&mean, &samples)
+	if err != nil {
+		return 0, 0, api.NewDatabaseError("get script cost history", err)
+	}
+	return mean, samples, nil
+}
+
+// RecentActuals returns what an account's most recent reconciled jobs on a partition with
+// the given nodes, CPUs and GPUs were estimated at and actually cost, newest first, up to
+// limit of them. Each job is read from its hold and the charge against it; jobs charged at
+// their estimate because nothing reconciled them are left out, having no actual cost.
+func (q *AccuracyQueries) RecentActuals(ctx context.Context, slurmAccount, partition string, nodes, cpus, gpus, limit int) ([]api.PriorActual, error) {
+	query := `
+		SELECT COALESCE((h.metadata->>'estimated_cost')::numeric, 0),
+		       COALESCE((c.metadata->>'reported_cost')::numeric, c.amount),
+		       COALESCE((c.metadata->'job'->>'cpu_efficiency')::numeric, 0),
+		       COALESCE((c.metadata->'job'->>'memory_efficiency')::numeric, 0),
+		       COALESCE(h.reconciled_at, c.created_at)
+		FROM budget_transactions h
+		JOIN budget_accounts ba ON ba.id = h.account_id
+		JOIN LATERAL (
+			SELECT metadata, amount, created_at
+			FROM budget_transactions
+			WHERE parent_transaction_id = h.transaction_id AND type = 'charge' AND status = 'completed'
+			ORDER BY id
+			LIMIT 1
+		) c ON TRUE
+		WHERE ba.slurm_account = $1
+		  AND h.type = 'hold' AND h.status = 'completed'
+		  AND h.metadata->>'partition' = $2
+		  AND COALESCE(h.metadata->>'nodes', '0') = $3
+		  AND COALESCE(h.metadata->>'cpus', '0') = $4
+		  AND COALESCE(h.metadata->>'gpus', '0') = $5
+		  AND NOT COALESCE((c.metadata->>'charged_at_estimate')::boolean, FALSE)
+		ORDER BY COALESCE(h.reconciled_at, c.created_at) DESC, h.id DESC
+		LIMIT $6`
+
+	rows, err := q.db.QueryContext(ctx, query, slurmAccount, partition,
+		strconv.Itoa(nodes), strconv.Itoa(cpus), strconv.Itoa(gpus), limit)
+	if err != nil {
+		return nil, api.NewDatabaseError("list recent actuals", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var actuals []api.PriorActual
+	for rows.Next() {
+		var actual api.PriorActual
+		err := rows.Scan(&actual.EstimatedCost, &actual.ActualCost, &actual.CPUEfficiency,
+			&actual.MemoryEfficiency, &actual.CompletedAt)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan recent actual", err)
+		}
+		actuals = append(actuals, actual)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate recent actuals", err)
+	}
+
+	return actuals, nil
+}
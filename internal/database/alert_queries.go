@@ -0,0 +1,124 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// alertColumns is the column list shared by every query that returns a full alert
+const alertColumns = `id, account_id, grant_id, alert_type, severity,
+		       COALESCE(threshold_value, 0), COALESCE(actual_value, 0), message,
+		       COALESCE(details::text, ''), triggered_at, acknowledged_at,
+		       COALESCE(acknowledged_by, ''), resolved_at, status`
+
+// scanAlert scans a row selected with alertColumns into a BudgetAlert
+func scanAlert(row rowScanner) (*api.BudgetAlert, error) {
+	var alert api.BudgetAlert
+	err := row.Scan(
+		&alert.ID, &alert.AccountID, &alert.GrantID, &alert.AlertType, &alert.Severity,
+		&alert.ThresholdValue, &alert.ActualValue, &alert.Message,
+		&alert.Details, &alert.TriggeredAt, &alert.AcknowledgedAt,
+		&alert.AcknowledgedBy, &alert.ResolvedAt, &alert.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// AlertQueries provides database operations for budget alerts
+type AlertQueries struct {
+	db *DB
+}
+
+// NewAlertQueries creates a new AlertQueries instance
+func NewAlertQueries(db *DB) *AlertQueries {
+	return &AlertQueries{db: db}
+}
+
+// GetOpenAlert returns the unresolved alert of the given type for an account, or nil if none
+func (q *AlertQueries) GetOpenAlert(ctx context.Context, accountID int64, alertType string) (*api.BudgetAlert, error) {
+	query := `SELECT ` + alertColumns + `
+		FROM budget_alerts
+		WHERE account_id = $1 AND alert_type = $2
+		  AND status IN ('active', 'acknowledged') AND resolved_at IS NULL
+		ORDER BY triggered_at DESC
+		LIMIT 1`
+
+	alert, err := scanAlert(q.db.QueryRowContext(ctx, query, accountID, alertType))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("get open alert", err)
+	}
+
+	return alert, nil
+}
+
+// CreateAlert records a newly triggered alert
+func (q *AlertQueries) CreateAlert(ctx context.Context, alert *api.BudgetAlert) error {
+	query := `
+		INSERT INTO budget_alerts (account_id, grant_id, alert_type, severity, threshold_value, actual_value, message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, triggered_at, status`
+
+	err := q.db.QueryRowContext(ctx, query,
+		alert.AccountID, alert.GrantID, alert.AlertType, alert.Severity,
+		alert.ThresholdValue, alert.ActualValue, alert.Message,
+	).Scan(&alert.ID, &alert.TriggeredAt, &alert.Status)
+
+	if err != nil {
+		return api.NewDatabaseError("create alert", err)
+	}
+
+	return nil
+}
+
+// EscalateAlert raises the severity of an open alert in place. Escalation re-activates
+// an acknowledged alert so that it is seen again.
+func (q *AlertQueries) EscalateAlert(ctx context.Context, alertID int64, severity string, threshold, actual float64, message string) error {
+	query := `
+		UPDATE budget_alerts
+		SET severity = $2, threshold_value = $3, actual_value = $4, message = $5,
+		    status = 'active', acknowledged_at = NULL, acknowledged_by = NULL
+		WHERE id = $1`
+
+	return q.execAlertUpdate(ctx, "escalate alert", alertID, query, severity, threshold, actual, message)
+}
+
+// ResolveAlert closes an open alert
+func (q *AlertQueries) ResolveAlert(ctx context.Context, alertID int64, actual float64) error {
+	query := `
+		UPDATE budget_alerts
+		SET status = 'resolved', resolved_at = NOW(), actual_value = $2
+		WHERE id = $1`
+
+	return q.execAlertUpdate(ctx, "resolve alert", alertID, query, actual)
+}
+
+// execAlertUpdate runs an update against a single alert and checks that it exists
+func (q *AlertQueries) execAlertUpdate(ctx context.Context, operation string, alertID int64, query string, args ...interface{}) error {
+	result, err := q.db.ExecContext(ctx, query, append([]interface{}{alertID}, args...)...)
+	if err != nil {
+		return api.NewDatabaseError(operation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return api.NewDatabaseError("get affected rows", err)
+	}
+
+	if rowsAffected == 0 {
+		return api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Alert %d not found", alertID))
+	}
+
+	return nil
+}
@@ -0,0 +1,316 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// AllocationQueries provides database operations for incremental allocation schedules
+type AllocationQueries struct {
+	db *DB
+}
+
+// NewAllocationQueries creates a new AllocationQueries instance
+func NewAllocationQueries(db *DB) *AllocationQueries {
+	return &AllocationQueries{db: db}
+}
+
+// ListDueScheduleIDs returns the schedules with an allocation due, in the account order
+// process_pending_allocations works through them, with the account each belongs to
+func (q *AllocationQueries) ListDueScheduleIDs(ctx context.Context) (scheduleIDs, accountIDs []int64, err error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT id, account_id
+		FROM budget_allocation_schedules
+		WHERE status = 'active'
+		  AND auto_allocate = TRUE
+		  AND next_allocation_date <= NOW()
+		  AND allocated_to_date < total_budget
+		ORDER BY account_id, id`)
+	if err != nil {
+		return nil, nil, api.NewDatabaseError("list due allocation schedules", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	for rows.Next() {
+		var id, accountID int64
+		if err := rows.Scan(&id, &accountID); err != nil {
+			return nil, nil, api.NewDatabaseError("scan due allocation schedule", err)
+		}
+		scheduleIDs = append(scheduleIDs, id)
+		accountIDs = append(accountIDs, accountID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, api.NewDatabaseError("iterate due allocation schedules", err)
+	}
+
+	return scheduleIDs, accountIDs, nil
+}
+
+// ProcessPendingAllocations makes the allocations that have come due, in one transaction.
+// A nil scheduleIDs processes every due schedule; otherwise only those listed. Accounts
+// without their own fiscal year start use defaultFiscalYearStart; empty keeps calendar
+// stepping. With prorateFirst, a schedule's first allocation is prorated by the share of
+// its period left.
+func (q *AllocationQueries) ProcessPendingAllocations(ctx context.Context, defaultFiscalYearStart string, prorateFirst bool, scheduleIDs []int64) ([]api.ProcessedAllocation, error) {
+	rows, err := q.db.QueryContext(ctx,
+		`SELECT schedule_id, account_id, allocated_amount, transaction_id FROM process_pending_allocations(NULLIF($1, ''), $2, $3)`,
+		defaultFiscalYearStart, pq.Array(scheduleIDs), prorateFirst)
+	if err != nil {
+		return nil, api.NewDatabaseError("process pending allocations", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var allocations []api.ProcessedAllocation
+	for rows.Next() {
+		var allocation api.ProcessedAllocation
+		if err := rows.Scan(&allocation.ScheduleID, &allocation.AccountID, &allocation.AllocatedAmount, &allocation.TransactionID); err != nil {
+			return nil, api.NewDatabaseError("scan processed allocation", err)
+		}
+		allocations = append(allocations, allocation)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate processed allocations", err)
+	}
+	for _, allocation := range allocations {
+		q.db.accountChanged(nil, allocation.AccountID)
+	}
+
+	return allocations, nil
+}
+
+// scheduleColumns lists the allocation schedule columns scanSchedule reads, in order
+const scheduleColumns = `id, account_id, total_budget, allocation_amount, allocation_frequency,
+		       start_date, end_date, next_allocation_date, allocated_to_date, remaining_budget,
+		       status, auto_allocate, created_at, updated_at`
+
+// scanSchedule scans a row selected with scheduleColumns. A fully allocated schedule has no
+// next allocation date and is left with the zero time.
+func scanSchedule(row rowScanner) (*api.BudgetAllocationSchedule, error) {
+	var schedule api.BudgetAllocationSchedule
+	var endDate, nextDate sql.NullTime
+	if err := row.Scan(
+		&schedule.ID, &schedule.AccountID, &schedule.TotalBudget, &schedule.AllocationAmount,
+		&schedule.AllocationFrequency, &schedule.StartDate, &endDate, &nextDate,
+		&schedule.AllocatedToDate, &schedule.RemainingBudget, &schedule.Status, &schedule.AutoAllocate,
+		&schedule.CreatedAt, &schedule.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if endDate.Valid {
+		schedule.EndDate = &endDate.Time
+	}
+	if nextDate.Valid {
+		schedule.NextAllocationDate = nextDate.Time
+	}
+	return &schedule, nil
+}
+
+// querier runs queries on the database or within a transaction
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// querySchedules lists the allocation schedules a query selects with scheduleColumns
+func querySchedules(ctx context.Context, db querier, query string, args ...interface{}) ([]*api.BudgetAllocationSchedule, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("list allocation schedules", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	schedules := []*api.BudgetAllocationSchedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan allocation schedule", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate allocation schedules", err)
+	}
+	return schedules, nil
+}
+
+// ListActiveSchedules returns an account's active, automatically allocated schedules,
+// earliest next allocation first
+func (q *AllocationQueries) ListActiveSchedules(ctx context.Context, accountID int64) ([]*api.BudgetAllocationSchedule, error) {
+	schedules, err := querySchedules(ctx, q.db, `
+		SELECT `+scheduleColumns+`
+		FROM budget_allocation_schedules
+		WHERE account_id = $1 AND status = 'active' AND auto_allocate = TRUE
+		  AND next_allocation_date IS NOT NULL
+		ORDER BY next_allocation_date ASC, id ASC`, accountID)
+	if err != nil {
+		return nil, err
+	}
+	return schedules, nil
+}
+
+// ListSchedules returns all of an account's allocation schedules, whatever their status,
+// oldest first
+func (q *AllocationQueries) ListSchedules(ctx context.Context, accountID int64) ([]*api.BudgetAllocationSchedule, error) {
+	return querySchedules(ctx, q.db, `
+		SELECT `+scheduleColumns+`
+		FROM budget_allocation_schedules
+		WHERE account_id = $1
+		ORDER BY id`, accountID)
+}
+
+// ListAllocations returns the allocations an account's schedules have made, oldest first
+func (q *AllocationQueries) ListAllocations(ctx context.Context, accountID int64) ([]*api.BudgetAllocation, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT id, schedule_id, account_id, allocation_amount, allocated_date,
+		       COALESCE(transaction_id, ''), COALESCE(notes, ''), created_at
+		FROM budget_allocations
+		WHERE account_id = $1
+		ORDER BY allocated_date, id`, accountID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list allocations", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	allocations := []*api.BudgetAllocation{}
+	for rows.Next() {
+		var allocation api.BudgetAllocation
+		if err := rows.Scan(
+			&allocation.ID, &allocation.ScheduleID, &allocation.AccountID, &allocation.AllocationAmount,
+			&allocation.AllocatedDate, &allocation.TransactionID, &allocation.Notes, &allocation.CreatedAt,
+		); err != nil {
+			return nil, api.NewDatabaseError("scan allocation", err)
+		}
+		allocations = append(allocations, &allocation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate allocations", err)
+	}
+	return allocations, nil
+}
+
+// CreateSchedule adds an allocation schedule to an account, which may already have others;
+// each allocates independently. The first allocation is due on the schedule's start date.
+// The account is marked incremental and its next allocation date becomes the soonest of
+// its active schedules'.
+func (q *AllocationQueries) CreateSchedule(ctx context.Context, accountID int64, req *api.CreateAllocationScheduleRequest) (*api.BudgetAllocationSchedule, error) {
+	var schedule *api.BudgetAllocationSchedule
+	err := q.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var err error
+		schedule, err = scanSchedule(tx.QueryRowContext(ctx, `
+			INSERT INTO budget_allocation_schedules
+				(account_id, total_budget, allocation_amount, allocation_frequency, start_date, end_date,
+				 next_allocation_date, remaining_budget, auto_allocate)
+			VALUES ($1, $2, $3, $4, $5, $6, $5, $2, $7)
+			RETURNING `+scheduleColumns,
+			accountID, req.TotalBudget, req.AllocationAmount, req.AllocationFrequency, req.StartDate,
+			req.EndDate, req.AutoAllocate))
+		if err != nil {
+			return api.NewDatabaseError("create allocation schedule", err)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE budget_accounts
+			SET has_incremental_budget = TRUE,
+			    next_allocation_date = (
+			        SELECT MIN(next_allocation_date)
+			        FROM budget_allocation_schedules
+			        WHERE account_id = $1 AND status = 'active'
+			    ),
+			    updated_at = NOW()
+			WHERE id = $1`, accountID)
+		if err != nil {
+			return api.NewDatabaseError("update next allocation date", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	q.db.accountChanged(nil, accountID)
+
+	return schedule, nil
+}
+
+// SetSchedulesStatus moves every schedule in status from to status to within tx, optionally
+// only those of accounts under one funding agency or cost center, and returns the IDs of
+// the schedules changed. Archived accounts' schedules are left alone. Each affected
+// account's next allocation date is recomputed from its active schedules, as
+// process_pending_allocations keeps it.
+func (q *AllocationQueries) SetSchedulesStatus(ctx context.Context, tx *sql.Tx, from, to string, req *api.BulkScheduleStatusRequest) ([]int64, error) {
+	conditions := []string{"ba.id = bas.account_id", "bas.status = $1", "ba.status <> 'archived'"}
+	args := []interface{}{from, to}
+
+	if req.FundingAgency != "" {
+		args = append(args, req.FundingAgency)
+		conditions = append(conditions, fmt.Sprintf("ga.funding_agency = $%d", len(args)))
+	}
+	if req.CostCenter != "" {
+		args = append(args, req.CostCenter)
+		conditions = append(conditions, fmt.Sprintf("ga.cost_center = $%d", len(args)))
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE budget_allocation_schedules bas
+		SET status = $2, updated_at = NOW()
+		FROM budget_accounts ba
+		LEFT JOIN grant_accounts ga ON ga.id = ba.grant_id
+		WHERE `+strings.Join(conditions, " AND ")+`
+		RETURNING bas.id`, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("update allocation schedule status", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, api.NewDatabaseError("scan allocation schedule", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate allocation schedules", err)
+	}
+	if len(ids) == 0 {
+		return ids, nil
+	}
+
+	// A separate statement, so the recomputed dates see the new statuses
+	_, err = tx.ExecContext(ctx, `
+		UPDATE budget_accounts ba
+		SET next_allocation_date = (
+		        SELECT MIN(next_allocation_date)
+		        FROM budget_allocation_schedules
+		        WHERE account_id = ba.id AND status = 'active'
+		    ),
+		    updated_at = NOW()
+		WHERE ba.id IN (SELECT account_id FROM budget_allocation_schedules WHERE id = ANY($1))`,
+		pq.Array(ids))
+	if err != nil {
+		return nil, api.NewDatabaseError("update next allocation dates", err)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// BurnRateQueries provides database operations for daily burn rate history
+type BurnRateQueries struct {
+	db *DB
+}
+
+// NewBurnRateQueries creates a new BurnRateQueries instance
+func NewBurnRateQueries(db *DB) *BurnRateQueries {
+	return &BurnRateQueries{db: db}
+}
+
+// ListBurnRates returns an account's burn rate rows between two dates inclusive, oldest first
+func (q *BurnRateQueries) ListBurnRates(ctx context.Context, accountID int64, start, end time.Time) ([]*api.BudgetBurnRate, error) {
+	query := `
+		SELECT id, account_id, measurement_date, daily_spend_amount, daily_expected_amount,
+		       daily_variance_pct, rolling_7day_avg, rolling_30day_avg, cumulative_spend,
+		       cumulative_expected, cumulative_variance_pct, COALESCE(budget_health_score, 0),
+		       created_at
+		FROM budget_burn_rates
+		WHERE account_id = $1 AND measurement_date BETWEEN $2 AND $3
+		ORDER BY measurement_date ASC`
+
+	rows, err := q.db.QueryContext(ctx, query, accountID, start, end)
+	if err != nil {
+		return nil, api.NewDatabaseError("list burn rates", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var rates []*api.BudgetBurnRate
+	for rows.Next() {
+		var rate api.BudgetBurnRate
+		var rolling7, rolling30 sql.NullFloat64
+		if err := rows.Scan(
+			&rate.ID, &rate.AccountID, &rate.MeasurementDate, &rate.DailySpendAmount, &rate.DailyExpectedAmount,
+			&rate.DailyVariancePct, &rolling7, &rolling30, &rate.CumulativeSpend,
+			&rate.CumulativeExpected, &rate.CumulativeVariancePct, &rate.BudgetHealthScore,
+			&rate.CreatedAt,
+		); err != nil {
+			return nil, api.NewDatabaseError("scan burn rate row", err)
+		}
+		rate.Rolling7DayAvg = rolling7.Float64
+		rate.Rolling30DayAvg = rolling30.Float64
+		rates = append(rates, &rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate burn rate rows", err)
+	}
+
+	return rates, nil
+}
+
+// InsertBurnRate records a day's burn rate and its rolling averages. A row that already
+// exists for the account and date is left untouched and false is returned.
+func (q *BurnRateQueries) InsertBurnRate(ctx context.Context, rate *api.BudgetBurnRate) (bool, error) {
+	insertQuery := `
+		INSERT INTO budget_burn_rates (
+			account_id, measurement_date, daily_spend_amount, daily_expected_amount,
+			cumulative_spend, cumulative_expected, budget_health_score
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (account_id, measurement_date) DO NOTHING`
+
+	rollingQuery := `
+		UPDATE budget_burn_rates
+		SET rolling_7day_avg = (
+		        SELECT AVG(daily_spend_amount) FROM budget_burn_rates
+		        WHERE account_id = $1 AND measurement_date BETWEEN $2::date - 6 AND $2::date
+		    ),
+		    rolling_30day_avg = (
+		        SELECT AVG(daily_spend_amount) FROM budget_burn_rates
+		        WHERE account_id = $1 AND measurement_date BETWEEN $2::date - 29 AND $2::date
+		    )
+		WHERE account_id = $1 AND measurement_date = $2`
+
+	inserted := false
+	err := q.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, insertQuery,
+			rate.AccountID, rate.MeasurementDate, rate.DailySpendAmount, rate.DailyExpectedAmount,
+			rate.CumulativeSpend, rate.CumulativeExpected, rate.BudgetHealthScore,
+		)
+		if err != nil {
+			return api.NewDatabaseError("insert burn rate", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return api.NewDatabaseError("get affected rows", err)
+		}
+		if rowsAffected == 0 {
+			return nil
+		}
+		inserted = true
+
+		if _, err := tx.ExecContext(ctx, rollingQuery, rate.AccountID, rate.MeasurementDate); err != nil {
+			return api.NewDatabaseError("update burn rate rolling averages", err)
+		}
+		return nil
+	})
+
+	return inserted, err
+}
@@ -7,7 +7,9 @@ package database
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql" // Register MySQL driver
@@ -16,15 +18,17 @@ import (
 	"github.com/golang-migrate/migrate/v4/database/mysql"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/file"
-	_ "github.com/lib/pq" // Register PostgreSQL driver
+	"github.com/lib/pq" // Also registers the PostgreSQL driver
 
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
 // DB wraps the database connection with additional functionality
 type DB struct {
 	*sql.DB
-	config *config.DatabaseConfig
+	config  *config.DatabaseConfig
+	changes *accountChanges // Set when balance changes are being watched
 }
 
 // Connect establishes a connection to the database
@@ -128,6 +132,79 @@ func (db *DB) MigrateDown() error {
 	return nil
 }
 
+// MigrationStatus reports the schema version recorded by the migrations and which of the
+// migrations in the migrations directory are applied or pending. It reads the version table
+// directly rather than through a migrate instance, whose MySQL driver would close the
+// service's connection pool when released.
+func (db *DB) MigrationStatus(ctx context.Context) (*api.MigrationStatus, error) {
+	available, err := listMigrations(db.config.MigrationsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var version int64
+	var dirty bool
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	switch {
+	case err == sql.ErrNoRows || isUndefinedTable(err):
+		// No migration has been applied yet
+		version, dirty = 0, false
+	case err != nil:
+		return nil, api.NewDatabaseError("read schema version", err)
+	}
+
+	return buildMigrationStatus(available, uint(version), dirty), nil
+}
+
+// listMigrations returns the migrations in a migrations directory, oldest first
+func listMigrations(migrationsPath string) ([]api.Migration, error) {
+	sourceDriver, err := (&file.File{}).Open(fmt.Sprintf("file://%s", migrationsPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open migrations directory: %w", err)
+	}
+	defer func() { _ = sourceDriver.Close() }()
+
+	var migrations []api.Migration
+	version, err := sourceDriver.First()
+	for err == nil {
+		r, name, readErr := sourceDriver.ReadUp(version)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, readErr)
+		}
+		_ = r.Close()
+		migrations = append(migrations, api.Migration{Version: version, Name: name})
+		version, err = sourceDriver.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	return migrations, nil
+}
+
+// buildMigrationStatus splits the available migrations at the current schema version. A
+// dirty schema's current migration failed part way, so it counts as neither applied nor
+// pending until the schema is repaired.
+func buildMigrationStatus(available []api.Migration, version uint, dirty bool) *api.MigrationStatus {
+	status := &api.MigrationStatus{
+		CurrentVersion: version,
+		Dirty:          dirty,
+		Applied:        []api.Migration{},
+		Pending:        []api.Migration{},
+	}
+	for _, migration := range available {
+		switch {
+		case migration.Version < version || (migration.Version == version && !dirty):
+			status.Applied = append(status.Applied, migration)
+		case migration.Version > version:
+			status.Pending = append(status.Pending, migration)
+		}
+		status.LatestVersion = migration.Version
+	}
+	status.UpToDate = !dirty && len(status.Pending) == 0
+	return status
+}
+
 // HealthCheck performs a health check on the database
 func (db *DB) HealthCheck(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
@@ -161,6 +238,7 @@ func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error
 
 	defer func() {
 		if p := recover(); p != nil {
+			db.finishTransaction(tx, false)
 			if rbErr := tx.Rollback(); rbErr != nil {
 				// Rollback failed - this is a serious issue but we still need to panic with original error
 				// In production, this would be logged to a monitoring system
@@ -171,19 +249,35 @@ func (db *DB) WithTransaction(ctx context.Context, fn func(*sql.Tx) error) error
 	}()
 
 	if err := fn(tx); err != nil {
+		db.finishTransaction(tx, false)
 		if rbErr := tx.Rollback(); rbErr != nil {
 			return fmt.Errorf("transaction failed: %v, rollback failed: %w", err, rbErr)
 		}
 		return err
 	}
 
-	if err := tx.Commit(); err != nil {
+	err = tx.Commit()
+	db.finishTransaction(tx, err == nil)
+	if err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return nil
 }
 
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation on
+// the named constraint
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
+
+// isUndefinedTable reports whether err is a PostgreSQL error for a table that does not exist
+func isUndefinedTable(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "42P01"
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	return db.DB.Close()
@@ -7,12 +7,16 @@ package database
 import (
 	"context"
 	"database/sql"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
 func TestConnect_InvalidDriver(t *testing.T) {
@@ -120,3 +124,75 @@ func BenchmarkDB_HealthCheck(b *testing.B) {
 	// Would benchmark actual health checks with real DB
 	b.Skip("Benchmark test - requires test database")
 }
+
+func TestListMigrations(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"002_add_grants.up.sql", "002_add_grants.down.sql",
+		"001_initial_schema.up.sql", "001_initial_schema.down.sql",
+		"010_add_alerts.up.sql", "010_add_alerts.down.sql",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("SELECT 1;"), 0o600))
+	}
+
+	migrations, err := listMigrations(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []api.Migration{
+		{Version: 1, Name: "initial_schema"},
+		{Version: 2, Name: "add_grants"},
+		{Version: 10, Name: "add_alerts"},
+	}, migrations)
+
+	_, err = listMigrations(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestListMigrations_Repository(t *testing.T) {
+	migrations, err := listMigrations("../../migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, uint(1), migrations[0].Version)
+	for i := 1; i < len(migrations); i++ {
+		assert.Greater(t, migrations[i].Version, migrations[i-1].Version, "migrations are listed oldest first")
+	}
+}
+
+func TestBuildMigrationStatus(t *testing.T) {
+	available := []api.Migration{
+		{Version: 1, Name: "initial_schema"},
+		{Version: 2, Name: "add_grants"},
+		{Version: 3, Name: "add_alerts"},
+	}
+
+	t.Run("up to date", func(t *testing.T) {
+		status := buildMigrationStatus(available, 3, false)
+		assert.True(t, status.UpToDate)
+		assert.Equal(t, uint(3), status.CurrentVersion)
+		assert.Equal(t, uint(3), status.LatestVersion)
+		assert.Len(t, status.Applied, 3)
+		assert.Empty(t, status.Pending)
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		status := buildMigrationStatus(available, 1, false)
+		assert.False(t, status.UpToDate)
+		assert.Equal(t, available[:1], status.Applied)
+		assert.Equal(t, available[1:], status.Pending)
+	})
+
+	t.Run("never migrated", func(t *testing.T) {
+		status := buildMigrationStatus(available, 0, false)
+		assert.False(t, status.UpToDate)
+		assert.Empty(t, status.Applied)
+		assert.Equal(t, available, status.Pending)
+	})
+
+	t.Run("dirty", func(t *testing.T) {
+		// The failed migration is neither applied nor pending until the schema is repaired
+		status := buildMigrationStatus(available, 3, true)
+		assert.False(t, status.UpToDate)
+		assert.True(t, status.Dirty)
+		assert.Equal(t, available[:2], status.Applied)
+		assert.Empty(t, status.Pending)
+	})
+}
@@ -0,0 +1,197 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// deadLetterColumns is the column list shared by every query that returns a full
+// reconciliation failure
+const deadLetterColumns = `id, transaction_id, job_id, source, COALESCE(request::text, ''), attempts, reason,
+		       first_failed_at, last_failed_at, dead_lettered_at, resolved_at, COALESCE(resolution, ''),
+		       resolved_by, note`
+
+// scanDeadLetter scans a row selected with deadLetterColumns into a ReconciliationDeadLetter
+func scanDeadLetter(row rowScanner) (*api.ReconciliationDeadLetter, error) {
+	var letter api.ReconciliationDeadLetter
+	var request string
+	var deadLetteredAt, resolvedAt sql.NullTime
+	err := row.Scan(
+		&letter.ID, &letter.TransactionID, &letter.JobID, &letter.Source, &request, &letter.Attempts, &letter.Reason,
+		&letter.FirstFailedAt, &letter.LastFailedAt, &deadLetteredAt, &resolvedAt, &letter.Resolution,
+		&letter.ResolvedBy, &letter.Note,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if request != "" {
+		letter.Request = &api.JobReconcileRequest{}
+		if err := json.Unmarshal([]byte(request), letter.Request); err != nil {
+			return nil, fmt.Errorf("decode reconciliation request: %w", err)
+		}
+	}
+	if deadLetteredAt.Valid {
+		letter.DeadLetteredAt = &deadLetteredAt.Time
+	}
+	if resolvedAt.Valid {
+		letter.ResolvedAt = &resolvedAt.Time
+	}
+	return &letter, nil
+}
+
+// DeadLetterQueries provides database operations for failed reconciliations and the
+// dead-letter queue they land in once they keep failing
+type DeadLetterQueries struct {
+	db *DB
+}
+
+// NewDeadLetterQueries creates a new DeadLetterQueries instance
+func NewDeadLetterQueries(db *DB) *DeadLetterQueries {
+	return &DeadLetterQueries{db: db}
+}
+
+// RecordFailure counts a failed reconciliation of a hold and keeps its latest reason and
+// request; a recovery failure, which has no request, keeps the one already recorded. A
+// failure after the hold's previous failures were resolved starts the count again. It
+// returns the failure as recorded.
+func (q *DeadLetterQueries) RecordFailure(ctx context.Context, transactionID, jobID, source string, req *api.JobReconcileRequest, reason string) (*api.ReconciliationDeadLetter, error) {
+	var request interface{}
+	if req != nil {
+		body, err := json.Marshal(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode reconciliation request: %w", err)
+		}
+		request = string(body)
+	}
+
+	row := q.db.QueryRowContext(ctx, `
+		INSERT INTO reconciliation_failures AS f (transaction_id, job_id, source, request, reason)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (transaction_id) DO UPDATE SET
+			job_id = EXCLUDED.job_id,
+			source = CASE WHEN EXCLUDED.request IS NULL AND f.request IS NOT NULL THEN f.source ELSE EXCLUDED.source END,
+			request = COALESCE(EXCLUDED.request, f.request),
+			reason = EXCLUDED.reason,
+			last_failed_at = NOW(),
+			attempts = CASE WHEN f.resolved_at IS NULL THEN f.attempts + 1 ELSE 1 END,
+			first_failed_at = CASE WHEN f.resolved_at IS NULL THEN f.first_failed_at ELSE NOW() END,
+			dead_lettered_at = CASE WHEN f.resolved_at IS NULL THEN f.dead_lettered_at END,
+			resolved_at = NULL,
+			resolution = NULL,
+			resolved_by = '',
+			note = ''
+		RETURNING `+deadLetterColumns,
+		transactionID, jobID, source, request, reason,
+	)
+	letter, err := scanDeadLetter(row)
+	if err != nil {
+		return nil, api.NewDatabaseError("record reconciliation failure", err)
+	}
+
+	return letter, nil
+}
+
+// MarkDeadLettered moves a failing reconciliation to the dead-letter queue
+func (q *DeadLetterQueries) MarkDeadLettered(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, `
+		UPDATE reconciliation_failures SET dead_lettered_at = NOW()
+		WHERE id = $1 AND dead_lettered_at IS NULL`, id)
+	if err != nil {
+		return api.NewDatabaseError("dead-letter reconciliation", err)
+	}
+	return nil
+}
+
+// ClearFailures forgets a hold's failures once it has been reconciled. A dead-lettered
+// reconciliation is kept, resolved as reconciled, so the queue's history stays complete.
+func (q *DeadLetterQueries) ClearFailures(ctx context.Context, transactionID string) error {
+	_, err := q.db.ExecContext(ctx, `
+		WITH cleared AS (
+			DELETE FROM reconciliation_failures
+			WHERE transaction_id = $1 AND dead_lettered_at IS NULL
+		)
+		UPDATE reconciliation_failures SET resolved_at = NOW(), resolution = 'reconciled'
+		WHERE transaction_id = $1 AND dead_lettered_at IS NOT NULL AND resolved_at IS NULL`,
+		transactionID)
+	if err != nil {
+		return api.NewDatabaseError("clear reconciliation failures", err)
+	}
+	return nil
+}
+
+// ListDeadLetters returns the unresolved dead-lettered reconciliations, oldest first
+func (q *DeadLetterQueries) ListDeadLetters(ctx context.Context) ([]*api.ReconciliationDeadLetter, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT `+deadLetterColumns+`
+		FROM reconciliation_failures
+		WHERE dead_lettered_at IS NOT NULL AND resolved_at IS NULL
+		ORDER BY dead_lettered_at, id`)
+	if err != nil {
+		return nil, api.NewDatabaseError("list dead-lettered reconciliations", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	letters := []*api.ReconciliationDeadLetter{}
+	for rows.Next() {
+		letter, err := scanDeadLetter(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan dead-lettered reconciliation", err)
+		}
+		letters = append(letters, letter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate dead-lettered reconciliations", err)
+	}
+
+	return letters, nil
+}
+
+// GetDeadLetter retrieves an unresolved dead-lettered reconciliation
+func (q *DeadLetterQueries) GetDeadLetter(ctx context.Context, id int64) (*api.ReconciliationDeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, `
+		SELECT `+deadLetterColumns+`
+		FROM reconciliation_failures
+		WHERE id = $1 AND dead_lettered_at IS NOT NULL AND resolved_at IS NULL`, id)
+	letter, err := scanDeadLetter(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, deadLetterNotFound(id)
+		}
+		return nil, api.NewDatabaseError("get dead-lettered reconciliation", err)
+	}
+
+	return letter, nil
+}
+
+// ResolveDeadLetter closes an unresolved dead-lettered reconciliation with the resolution
+func (q *DeadLetterQueries) ResolveDeadLetter(ctx context.Context, id int64, resolution string, req *api.ResolveDeadLetterRequest) (*api.ReconciliationDeadLetter, error) {
+	row := q.db.QueryRowContext(ctx, `
+		UPDATE reconciliation_failures
+		SET resolved_at = NOW(), resolution = $2, resolved_by = $3, note = $4
+		WHERE id = $1 AND dead_lettered_at IS NOT NULL AND resolved_at IS NULL
+		RETURNING `+deadLetterColumns,
+		id, resolution, req.ResolvedBy, req.Note)
+	letter, err := scanDeadLetter(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, deadLetterNotFound(id)
+		}
+		return nil, api.NewDatabaseError("resolve dead-lettered reconciliation", err)
+	}
+
+	return letter, nil
+}
+
+// deadLetterNotFound reports an ID that is not an unresolved dead letter
+func deadLetterNotFound(id int64) error {
+	return api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Dead-lettered reconciliation %d not found", id))
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// deadlineColumns is the column list shared by every query that returns a full deadline,
+// selected from grant_deadlines d joined to grant_accounts g and left joined to grant_reports r
+const deadlineColumns = `d.id, d.grant_id, g.grant_number, d.deadline_type, d.description,
+		       d.due_date, d.budget_period, r.id, d.created_at`
+
+// deadlineJoins joins a deadline to its grant and to its report, if generated
+const deadlineJoins = `grant_deadlines d
+		JOIN grant_accounts g ON g.id = d.grant_id
+		LEFT JOIN grant_reports r ON r.deadline_id = d.id`
+
+// scanDeadline scans a row selected with deadlineColumns into a GrantDeadline
+func scanDeadline(row rowScanner) (*api.GrantDeadline, error) {
+	var deadline api.GrantDeadline
+	var period sql.NullInt64
+	var reportID sql.NullInt64
+	err := row.Scan(
+		&deadline.ID, &deadline.GrantID, &deadline.GrantNumber, &deadline.Type, &deadline.Description,
+		&deadline.DueDate, &period, &reportID, &deadline.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if period.Valid {
+		number := int(period.Int64)
+		deadline.BudgetPeriod = &number
+	}
+	if reportID.Valid {
+		deadline.ReportID = &reportID.Int64
+	}
+	return &deadline, nil
+}
+
+// DeadlineQueries provides database operations for grant deadlines and the reports
+// generated for them
+type DeadlineQueries struct {
+	db *DB
+}
+
+// NewDeadlineQueries creates a new DeadlineQueries instance
+func NewDeadlineQueries(db *DB) *DeadlineQueries {
+	return &DeadlineQueries{db: db}
+}
+
+// CreateDeadline records a deadline for the grant
+func (q *DeadlineQueries) CreateDeadline(ctx context.Context, grant *api.GrantAccount, req *api.CreateGrantDeadlineRequest) (*api.GrantDeadline, error) {
+	deadline := &api.GrantDeadline{
+		GrantID:      grant.ID,
+		GrantNumber:  grant.GrantNumber,
+		Type:         req.Type,
+		Description:  req.Description,
+		DueDate:      req.DueDate,
+		BudgetPeriod: req.BudgetPeriod,
+	}
+
+	err := q.db.QueryRowContext(ctx, `
+		INSERT INTO grant_deadlines (grant_id, deadline_type, description, due_date, budget_period)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		grant.ID, req.Type, req.Description, req.DueDate, req.BudgetPeriod,
+	).Scan(&deadline.ID, &deadline.CreatedAt)
+	if err != nil {
+		return nil, api.NewDatabaseError("create grant deadline", err)
+	}
+
+	return deadline, nil
+}
+
+// ListDeadlines returns the grant's deadlines in due date order
+func (q *DeadlineQueries) ListDeadlines(ctx context.Context, grantID int64) ([]*api.GrantDeadline, error) {
+	query := `SELECT ` + deadlineColumns + `
+		FROM ` + deadlineJoins + `
+		WHERE d.grant_id = $1
+		ORDER BY d.due_date, d.id`
+
+	return q.queryDeadlines(ctx, "list grant deadlines", query, grantID)
+}
+
+// ListDueReportDeadlines returns up to limit GRANT_REPORT deadlines due by the given time
+// whose report has not been generated, earliest first
+func (q *DeadlineQueries) ListDueReportDeadlines(ctx context.Context, dueBy time.Time, limit int) ([]*api.GrantDeadline, error) {
+	query := `SELECT ` + deadlineColumns + `
+		FROM ` + deadlineJoins + `
+		WHERE d.deadline_type = 'GRANT_REPORT' AND d.due_date <= $1 AND r.id IS NULL
+		ORDER BY d.due_date, d.id
+		LIMIT $2`
+
+	return q.queryDeadlines(ctx, "list due report deadlines", query, dueBy, limit)
+}
+
+// queryDeadlines runs a query selecting deadlineColumns and scans every row
+func (q *DeadlineQueries) queryDeadlines(ctx context.Context, operation, query string, args ...interface{}) ([]*api.GrantDeadline, error) {
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError(operation, err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	deadlines := []*api.GrantDeadline{}
+	for rows.Next() {
+		deadline, err := scanDeadline(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan grant deadline", err)
+		}
+		deadlines = append(deadlines, deadline)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate grant deadlines", err)
+	}
+
+	return deadlines, nil
+}
+
+// StoreDeadlineReport keeps the report generated for a deadline. It returns nil when the
+// deadline already has a report, as when another worker generated it first.
+func (q *DeadlineQueries) StoreDeadlineReport(ctx context.Context, deadline *api.GrantDeadline, reportType string, report []byte) (*api.StoredGrantReport, error) {
+	stored := &api.StoredGrantReport{
+		GrantID:     deadline.GrantID,
+		GrantNumber: deadline.GrantNumber,
+		DeadlineID:  &deadline.ID,
+		ReportType:  reportType,
+		Report:      report,
+	}
+
+	err := q.db.QueryRowContext(ctx, `
+		INSERT INTO grant_reports (grant_id, deadline_id, report_type, report)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (deadline_id) DO NOTHING
+		RETURNING id, generated_at`,
+		deadline.GrantID, deadline.ID, reportType, string(report),
+	).Scan(&stored.ID, &stored.GeneratedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, api.NewDatabaseError("store grant report", err)
+	}
+
+	return stored, nil
+}
+
+// GetReport retrieves a stored report of the grant
+func (q *DeadlineQueries) GetReport(ctx context.Context, grant *api.GrantAccount, reportID int64) (*api.StoredGrantReport, error) {
+	stored := &api.StoredGrantReport{GrantNumber: grant.GrantNumber}
+	var deadlineID sql.NullInt64
+	var report string
+
+	err := q.db.QueryRowContext(ctx, `
+		SELECT id, grant_id, deadline_id, report_type, report::text, generated_at
+		FROM grant_reports
+		WHERE id = $1 AND grant_id = $2`, reportID, grant.ID,
+	).Scan(&stored.ID, &stored.GrantID, &deadlineID, &stored.ReportType, &report, &stored.GeneratedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, api.NewBudgetError(api.ErrCodeNotFound,
+				fmt.Sprintf("Report %d not found for grant %s", reportID, grant.GrantNumber))
+		}
+		return nil, api.NewDatabaseError("get grant report", err)
+	}
+
+	if deadlineID.Valid {
+		stored.DeadlineID = &deadlineID.Int64
+	}
+	stored.Report = []byte(report)
+	return stored, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// DecisionQueries provides database operations for the budget decision log
+type DecisionQueries struct {
+	db *DB
+}
+
+// NewDecisionQueries creates a new DecisionQueries instance
+func NewDecisionQueries(db *DB) *DecisionQueries {
+	return &DecisionQueries{db: db}
+}
+
+// RecordDecision appends a budget check decision to the log, filling in its ID and
+// creation time. Passing the hold's transaction records an approval atomically with it.
+func (q *DecisionQueries) RecordDecision(ctx context.Context, tx *sql.Tx, decision *api.BudgetDecision) error {
+	var inputs []byte
+	if decision.Inputs != nil {
+		var err error
+		if inputs, err = json.Marshal(decision.Inputs); err != nil {
+			return api.NewDatabaseError("encode decision inputs", err)
+		}
+	}
+
+	query := `
+		INSERT INTO budget_decisions (
+			account_id, partition, user_id, decision, reason, estimated_cost, hold_amount,
+			budget_available, confidence, failure_mode, transaction_id, inputs
+		) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), $12)
+		RETURNING id, created_at`
+
+	var queryer interface {
+		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+	}
+
+	if tx != nil {
+		queryer = tx
+	} else {
+		queryer = q.db
+	}
+
+	err := queryer.QueryRowContext(ctx, query,
+		decision.AccountID, decision.Partition, decision.UserID, decision.Decision, decision.Reason,
+		decision.EstimatedCost, decision.HoldAmount, decision.BudgetAvailable, decision.Confidence,
+		decision.FailureMode, decision.TransactionID, inputs,
+	).Scan(&decision.ID, &decision.CreatedAt)
+	if err != nil {
+		return api.NewDatabaseError("record budget decision", err)
+	}
+
+	return nil
+}
+
+// ListDecisions returns an account's budget decisions, newest first. Dates bound
+// created_at from StartDate inclusive to EndDate exclusive.
+func (q *DecisionQueries) ListDecisions(ctx context.Context, accountID int64, req *api.DecisionListRequest) ([]*api.BudgetDecision, error) {
+	conditions := []string{"account_id = $1"}
+	args := []interface{}{accountID}
+	argIndex := 2
+
+	if req.Decision != "" {
+		conditions = append(conditions, fmt.Sprintf("decision = $%d", argIndex))
+		args = append(args, req.Decision)
+		argIndex++
+	}
+
+	if req.StartDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", argIndex))
+		args = append(args, *req.StartDate)
+		argIndex++
+	}
+
+	if req.EndDate != nil {
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", argIndex))
+		args = append(args, *req.EndDate)
+		argIndex++
+	}
+
+	query := `
+		SELECT id, account_id, partition, COALESCE(user_id, ''), decision, reason, estimated_cost,
+		       hold_amount, budget_available, COALESCE(confidence, 0), COALESCE(failure_mode, ''),
+		       COALESCE(transaction_id, ''), inputs, created_at
+		FROM budget_decisions
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY created_at DESC, id DESC`
+
+	if req.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argIndex)
+		args = append(args, req.Limit)
+		argIndex++
+	}
+
+	if req.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", argIndex)
+		args = append(args, req.Offset)
+	}
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("list budget decisions", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	decisions := []*api.BudgetDecision{}
+	for rows.Next() {
+		var decision api.BudgetDecision
+		var inputs []byte
+		if err := rows.Scan(&decision.ID, &decision.AccountID, &decision.Partition, &decision.UserID,
+			&decision.Decision, &decision.Reason, &decision.EstimatedCost, &decision.HoldAmount,
+			&decision.BudgetAvailable, &decision.Confidence, &decision.FailureMode,
+			&decision.TransactionID, &inputs, &decision.CreatedAt); err != nil {
+			return nil, api.NewDatabaseError("scan budget decision", err)
+		}
+		if len(inputs) > 0 {
+			decision.Inputs = &api.BudgetCheckRequest{}
+			if err := json.Unmarshal(inputs, decision.Inputs); err != nil {
+				return nil, api.NewDatabaseError("decode decision inputs", err)
+			}
+		}
+		decisions = append(decisions, &decision)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate budget decisions", err)
+	}
+
+	return decisions, nil
+}
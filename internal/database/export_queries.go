@@ -0,0 +1,323 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// ExportQueries provides database operations for exporting an account and restoring it
+type ExportQueries struct {
+	db *DB
+}
+
+// NewExportQueries creates a new ExportQueries instance
+func NewExportQueries(db *DB) *ExportQueries {
+	return &ExportQueries{db: db}
+}
+
+// ExportAccount reads an account's partition limits, allocation schedules and transactions
+// into the export, all as of one snapshot so balances and the ledger agree
+func (q *ExportQueries) ExportAccount(ctx context.Context, export *api.AccountExport) error {
+	accountID := export.Account.ID
+	return q.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ`); err != nil {
+			return api.NewDatabaseError("begin account export", err)
+		}
+
+		account, err := scanAccount(tx.QueryRowContext(ctx,
+			`SELECT `+accountColumns+` FROM budget_accounts WHERE id = $1`, accountID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return api.NewAccountNotFoundError(export.Account.SlurmAccount)
+			}
+			return api.NewDatabaseError("export account", err)
+		}
+		export.Account = account
+
+		if export.PartitionLimits, err = exportPartitionLimits(ctx, tx, accountID); err != nil {
+			return err
+		}
+		if export.AllocationSchedules, err = exportSchedules(ctx, tx, accountID); err != nil {
+			return err
+		}
+		export.Transactions, err = exportTransactions(ctx, tx, accountID)
+		return err
+	})
+}
+
+// exportPartitionLimits reads an account's partition limits with their usage
+func exportPartitionLimits(ctx context.Context, tx *sql.Tx, accountID int64) ([]api.BudgetPartitionLimit, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, account_id, partition, limit_amount, used_amount, held_amount
+		FROM budget_partition_limits
+		WHERE account_id = $1
+		ORDER BY partition`, accountID)
+	if err != nil {
+		return nil, api.NewDatabaseError("export partition limits", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	limits := []api.BudgetPartitionLimit{}
+	for rows.Next() {
+		var limit api.BudgetPartitionLimit
+		if err := rows.Scan(&limit.ID, &limit.AccountID, &limit.Partition, &limit.Limit, &limit.Used, &limit.Held); err != nil {
+			return nil, api.NewDatabaseError("scan partition limit", err)
+		}
+		limits = append(limits, limit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("export partition limits", err)
+	}
+	return limits, nil
+}
+
+// exportSchedules reads all of an account's allocation schedules, whatever their status
+func exportSchedules(ctx context.Context, tx *sql.Tx, accountID int64) ([]api.BudgetAllocationSchedule, error) {
+	rows, err := querySchedules(ctx, tx, `
+		SELECT `+scheduleColumns+`
+		FROM budget_allocation_schedules
+		WHERE account_id = $1
+		ORDER BY id`, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]api.BudgetAllocationSchedule, len(rows))
+	for i, schedule := range rows {
+		schedules[i] = *schedule
+	}
+	return schedules, nil
+}
+
+// exportTransactions reads all of an account's transactions in the order they were made,
+// so each comes after any transaction it settles
+func exportTransactions(ctx context.Context, tx *sql.Tx, accountID int64) ([]api.BudgetTransaction, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, transaction_id, account_id, job_id, type, amount, description, COALESCE(metadata::text, ''), status,
+		       parent_transaction_id, cost_share_group, cost_share_percentage, created_at, completed_at,
+		       full_hold_amount, started_at, reconciled_at
+		FROM budget_transactions
+		WHERE account_id = $1
+		ORDER BY id`, accountID)
+	if err != nil {
+		return nil, api.NewDatabaseError("export transactions", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	transactions := []api.BudgetTransaction{}
+	for rows.Next() {
+		var transaction api.BudgetTransaction
+		err := rows.Scan(
+			&transaction.ID, &transaction.TransactionID, &transaction.AccountID, &transaction.JobID,
+			&transaction.Type, &transaction.Amount, &transaction.Description, &transaction.Metadata,
+			&transaction.Status, &transaction.ParentTransactionID, &transaction.CostShareGroup,
+			&transaction.CostSharePercentage, &transaction.CreatedAt, &transaction.CompletedAt,
+			&transaction.FullHoldAmount, &transaction.StartedAt, &transaction.ReconciledAt,
+		)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan transaction", err)
+		}
+		transactions = append(transactions, transaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("export transactions", err)
+	}
+	return transactions, nil
+}
+
+// ImportAccount recreates an exported account, all in one transaction. The account is
+// created from req, which carries its settings and parent; then its partition limits,
+// schedules and transactions are restored and its balances set to the exported ones.
+// Finally it is attached to its parent, whose balances and every ancestor's then include
+// the restored ones. Transaction IDs must not already exist, and a transaction settling
+// one outside the export must find it here.
+func (q *ExportQueries) ImportAccount(ctx context.Context, export *api.AccountExport, req *api.CreateAccountRequest) (*api.AccountImportResponse, error) {
+	exported := export.Account
+	resp := &api.AccountImportResponse{}
+	err := q.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		if err := checkImportTransactions(ctx, tx, export.Transactions); err != nil {
+			return err
+		}
+
+		// The account is created detached, so restoring its ledger moves only its own
+		// balances; it joins its parent once they are set to the exported ones
+		var parentID *int64
+		if req.ParentAccount != "" {
+			var id int64
+			err := tx.QueryRowContext(ctx,
+				`SELECT id FROM budget_accounts WHERE slurm_account = $1`, req.ParentAccount).Scan(&id)
+			if err == sql.ErrNoRows {
+				return api.NewAccountNotFoundError(req.ParentAccount)
+			}
+			if err != nil {
+				return api.NewDatabaseError("get parent account", err)
+			}
+			parentID = &id
+		}
+		detached := *req
+		detached.ParentAccount = ""
+		account, err := insertAccount(ctx, tx, &detached)
+		if err != nil {
+			return err
+		}
+
+		for _, limit := range export.PartitionLimits {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO budget_partition_limits (account_id, partition, limit_amount, used_amount, held_amount)
+				VALUES ($1, $2, $3, $4, $5)`,
+				account.ID, limit.Partition, limit.Limit, limit.Used, limit.Held)
+			if err != nil {
+				return api.NewDatabaseError("import partition limit", err)
+			}
+		}
+		resp.PartitionLimits = len(export.PartitionLimits)
+
+		for _, schedule := range export.AllocationSchedules {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO budget_allocation_schedules
+					(account_id, total_budget, allocation_amount, allocation_frequency, start_date, end_date,
+					 next_allocation_date, allocated_to_date, remaining_budget, status, auto_allocate, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+				account.ID, schedule.TotalBudget, schedule.AllocationAmount, schedule.AllocationFrequency,
+				schedule.StartDate, schedule.EndDate, nextAllocationDate(schedule), schedule.AllocatedToDate,
+				schedule.RemainingBudget, schedule.Status, schedule.AutoAllocate, schedule.CreatedAt)
+			if err != nil {
+				return api.NewDatabaseError("import allocation schedule", err)
+			}
+		}
+		resp.AllocationSchedules = len(export.AllocationSchedules)
+
+		for _, transaction := range export.Transactions {
+			_, err := tx.ExecContext(ctx, `
+				INSERT INTO budget_transactions
+					(transaction_id, account_id, job_id, type, amount, description, metadata, status,
+					 parent_transaction_id, cost_share_group, cost_share_percentage, created_at, completed_at,
+					 full_hold_amount, started_at, reconciled_at)
+				VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::jsonb, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+				transaction.TransactionID, account.ID, transaction.JobID, transaction.Type, transaction.Amount,
+				transaction.Description, transaction.Metadata, transaction.Status, transaction.ParentTransactionID,
+				transaction.CostShareGroup, transaction.CostSharePercentage, transaction.CreatedAt,
+				transaction.CompletedAt, transaction.FullHoldAmount, transaction.StartedAt, transaction.ReconciledAt)
+			if err != nil {
+				return api.NewDatabaseError("import transaction "+transaction.TransactionID, err)
+			}
+		}
+		resp.Transactions = len(export.Transactions)
+
+		// The balance trigger replayed the ledger onto the account; its balances and state
+		// are set to what was exported instead
+		account, err = scanAccount(tx.QueryRowContext(ctx, `
+			UPDATE budget_accounts
+			SET budget_used = $2, budget_held = $3, total_allocated = $4, has_incremental_budget = $5,
+			    next_allocation_date = $6, frozen = $7, depleted_at = $8, status = $9, created_at = $10
+			WHERE id = $1
+			RETURNING `+accountColumns,
+			account.ID, exported.BudgetUsed, exported.BudgetHeld, exported.TotalAllocated,
+			exported.HasIncrementalBudget, exported.NextAllocationDate, exported.Frozen, exported.DepletedAt,
+			exported.Status, exported.CreatedAt))
+		if err != nil {
+			return api.NewDatabaseError("restore account balances", err)
+		}
+		// The account's opening limit took effect when it was first created
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE budget_limit_history SET effective_at = $2 WHERE account_id = $1`, account.ID, exported.CreatedAt); err != nil {
+			return api.NewDatabaseError("restore budget limit history", err)
+		}
+
+		// Attaching the account adds its restored balances to its parent and every ancestor
+		if parentID != nil {
+			if _, err := tx.ExecContext(ctx, `SELECT set_account_parent($1, $2)`, account.ID, *parentID); err != nil {
+				return api.NewDatabaseError("attach account to parent", err)
+			}
+			account, err = scanAccount(tx.QueryRowContext(ctx,
+				`SELECT `+accountColumns+` FROM budget_accounts WHERE id = $1`, account.ID))
+			if err != nil {
+				return api.NewDatabaseError("get imported account", err)
+			}
+		}
+
+		resp.Account = account
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	q.db.accountChanged(nil, AnyAccount)
+
+	return resp, nil
+}
+
+// checkImportTransactions refuses an import whose transaction IDs already exist, or whose
+// transactions settle ones that neither it nor the database holds
+func checkImportTransactions(ctx context.Context, tx *sql.Tx, transactions []api.BudgetTransaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(transactions))
+	included := make(map[string]bool, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.TransactionID
+		included[transaction.TransactionID] = true
+	}
+	var existing string
+	err := tx.QueryRowContext(ctx,
+		`SELECT transaction_id FROM budget_transactions WHERE transaction_id = ANY($1) LIMIT 1`, pq.Array(ids)).Scan(&existing)
+	if err == nil {
+		return api.NewDuplicateTransactionError(existing, nil)
+	}
+	if err != sql.ErrNoRows {
+		return api.NewDatabaseError("check imported transactions", err)
+	}
+
+	var outside []string
+	for _, transaction := range transactions {
+		if parent := transaction.ParentTransactionID; parent != nil && !included[*parent] {
+			outside = append(outside, *parent)
+		}
+	}
+	if len(outside) == 0 {
+		return nil
+	}
+	var found int
+	err = tx.QueryRowContext(ctx,
+		`SELECT COUNT(DISTINCT transaction_id) FROM budget_transactions WHERE transaction_id = ANY($1)`, pq.Array(outside)).Scan(&found)
+	if err != nil {
+		return api.NewDatabaseError("check imported transactions", err)
+	}
+	if distinct := countDistinct(outside); found < distinct {
+		return api.NewValidationError("transactions",
+			fmt.Sprintf("%d settled transaction(s) are neither in the export nor in the database: %s",
+				distinct-found, strings.Join(outside, ", ")))
+	}
+	return nil
+}
+
+// countDistinct counts the distinct values in values
+func countDistinct(values []string) int {
+	distinct := make(map[string]bool, len(values))
+	for _, value := range values {
+		distinct[value] = true
+	}
+	return len(distinct)
+}
+
+// nextAllocationDate returns a schedule's next allocation date, or nil for a fully
+// allocated schedule, which has none
+func nextAllocationDate(schedule api.BudgetAllocationSchedule) *time.Time {
+	if schedule.NextAllocationDate.IsZero() {
+		return nil
+	}
+	return &schedule.NextAllocationDate
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// FeedbackQueries provides database operations for the job outcomes ASBA reports
+type FeedbackQueries struct {
+	db *DB
+}
+
+// NewFeedbackQueries creates a new FeedbackQueries instance
+func NewFeedbackQueries(db *DB) *FeedbackQueries {
+	return &FeedbackQueries{db: db}
+}
+
+// RecordFeedback stores a job outcome against the account's budget decision with the
+// request's decision ID or, failing that, its hold's transaction ID, filling in the
+// feedback's IDs, the decision's estimate and the timestamps. Feedback sent again for the
+// same decision replaces what was recorded, so ASBA can safely retry.
+func (q *FeedbackQueries) RecordFeedback(ctx context.Context, accountID int64, req *api.ASBAFeedbackRequest) (*api.ASBAFeedback, error) {
+	query := `
+		WITH decision AS (
+			SELECT id, account_id, estimated_cost
+			FROM budget_decisions
+			WHERE account_id = $1 AND (id = $2 OR ($2 = 0 AND transaction_id = $3))
+			ORDER BY id DESC
+			LIMIT 1
+		), saved AS (
+			INSERT INTO asba_feedback (account_id, decision_id, job_id, recommended_action, actual_location,
+			                           actual_cost, recommendation_followed, notes)
+			SELECT account_id, id, NULLIF($4, ''), $5, $6, $7, $8, NULLIF($9, '')
+			FROM decision
+			ON CONFLICT (decision_id) DO UPDATE
+			SET job_id = EXCLUDED.job_id,
+			    recommended_action = EXCLUDED.recommended_action,
+			    actual_location = EXCLUDED.actual_location,
+			    actual_cost = EXCLUDED.actual_cost,
+			    recommendation_followed = EXCLUDED.recommendation_followed,
+			    notes = EXCLUDED.notes,
+			    updated_at = NOW()
+			RETURNING id, decision_id, created_at, updated_at
+		)
+		SELECT saved.id, saved.decision_id, decision.estimated_cost, saved.created_at, saved.updated_at
+		FROM saved JOIN decision ON decision.id = saved.decision_id`
+
+	feedback := &api.ASBAFeedback{
+		AccountID:              accountID,
+		JobID:                  req.JobID,
+		RecommendedAction:      req.RecommendedAction,
+		ActualLocation:         req.ActualLocation,
+		ActualCost:             req.ActualCost,
+		RecommendationFollowed: req.RecommendationFollowed,
+		Notes:                  req.Notes,
+	}
+	err := q.db.QueryRowContext(ctx, query,
+		accountID, req.DecisionID, req.TransactionID, req.JobID, req.RecommendedAction,
+		req.ActualLocation, req.ActualCost, req.RecommendationFollowed, req.Notes,
+	).Scan(&feedback.ID, &feedback.DecisionID, &feedback.EstimatedCost, &feedback.CreatedAt, &feedback.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			if req.DecisionID != 0 {
+				return nil, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Budget decision %d not found", req.DecisionID))
+			}
+			return nil, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Budget decision for transaction %s not found", req.TransactionID))
+		}
+		return nil, api.NewDatabaseError("record ASBA feedback", err)
+	}
+
+	return feedback, nil
+}
+
+// ListFeedback returns an account's ASBA feedback, newest first
+func (q *FeedbackQueries) ListFeedback(ctx context.Context, accountID int64, req *api.ASBAFeedbackListRequest) ([]*api.ASBAFeedback, error) {
+	query := `
+		SELECT f.id, f.account_id, f.decision_id, COALESCE(f.job_id, ''), f.recommended_action,
+		       f.actual_location, f.actual_cost, d.estimated_cost, f.recommendation_followed,
+		       COALESCE(f.notes, ''), f.created_at, f.updated_at
+		FROM asba_feedback f
+		JOIN budget_decisions d ON d.id = f.decision_id
+		WHERE f.account_id = $1
+		ORDER BY f.created_at DESC, f.id DESC`
+	args := []interface{}{accountID}
+
+	if req.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", len(args)+1)
+		args = append(args, req.Limit)
+	}
+
+	if req.Offset > 0 {
+		query += fmt.Sprintf(" OFFSET $%d", len(args)+1)
+		args = append(args, req.Offset)
+	}
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("list ASBA feedback", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	feedback := []*api.ASBAFeedback{}
+	for rows.Next() {
+		var f api.ASBAFeedback
+		if err := rows.Scan(&f.ID, &f.AccountID, &f.DecisionID, &f.JobID, &f.RecommendedAction,
+			&f.ActualLocation, &f.ActualCost, &f.EstimatedCost, &f.RecommendationFollowed,
+			&f.Notes, &f.CreatedAt, &f.UpdatedAt); err != nil {
+			return nil, api.NewDatabaseError("scan ASBA feedback", err)
+		}
+		feedback = append(feedback, &f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate ASBA feedback", err)
+	}
+
+	return feedback, nil
+}
+
+// SummarizeFeedback totals an account's ASBA feedback recorded or updated since the given
+// time. Only AWS runs whose decision had an estimate count towards the AWS costs.
+func (q *FeedbackQueries) SummarizeFeedback(ctx context.Context, accountID int64, since time.Time) (*api.ASBAFeedbackSummary, error) {
+	query := `
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE f.recommendation_followed),
+		       COUNT(*) FILTER (WHERE f.actual_location = 'AWS' AND d.estimated_cost > 0),
+		       COALESCE(SUM(f.actual_cost) FILTER (WHERE f.actual_location = 'AWS' AND d.estimated_cost > 0), 0),
+		       COALESCE(SUM(d.estimated_cost) FILTER (WHERE f.actual_location = 'AWS' AND d.estimated_cost > 0), 0)
+		FROM asba_feedback f
+		JOIN budget_decisions d ON d.id = f.decision_id
+		WHERE f.account_id = $1 AND f.updated_at >= $2`
+
+	var summary api.ASBAFeedbackSummary
+	err := q.db.QueryRowContext(ctx, query, accountID, since).Scan(&summary.Outcomes, &summary.Followed,
+		&summary.AWSOutcomes, &summary.AWSActualCost, &summary.AWSEstimatedCost)
+	if err != nil {
+		return nil, api.NewDatabaseError("summarize ASBA feedback", err)
+	}
+
+	return &summary, nil
+}
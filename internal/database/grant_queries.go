@@ -0,0 +1,236 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// grantColumns is the column list shared by every query that returns a full grant
+const grantColumns = `id, grant_number, funding_agency, COALESCE(agency_program, ''),
+		       principal_investigator, co_investigators, institution, COALESCE(department, ''),
+		       grant_start_date, grant_end_date, total_award_amount, direct_costs,
+		       excluded_direct_costs, COALESCE(indirect_cost_rate, 0), indirect_costs, budget_period_months,
+		       current_budget_period, status, COALESCE(compliance_requirements::text, ''),
+		       COALESCE(federal_award_id, ''), COALESCE(internal_project_code, ''),
+		       COALESCE(cost_center, ''), timezone, created_at, updated_at`
+
+// scanGrant scans a row selected with grantColumns into a GrantAccount
+func scanGrant(row rowScanner) (*api.GrantAccount, error) {
+	var grant api.GrantAccount
+	var indirectCosts sql.NullFloat64
+	err := row.Scan(
+		&grant.ID, &grant.GrantNumber, &grant.FundingAgency, &grant.AgencyProgram,
+		&grant.PrincipalInvestigator, pq.Array(&grant.CoInvestigators), &grant.Institution, &grant.Department,
+		&grant.GrantStartDate, &grant.GrantEndDate, &grant.TotalAwardAmount, &grant.DirectCosts,
+		&grant.ExcludedDirectCosts, &grant.IndirectCostRate, &indirectCosts, &grant.BudgetPeriodMonths,
+		&grant.CurrentBudgetPeriod, &grant.Status, &grant.ComplianceRequirements,
+		&grant.FederalAwardID, &grant.InternalProjectCode,
+		&grant.CostCenter, &grant.Timezone, &grant.CreatedAt, &grant.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	grant.IndirectCosts = indirectCosts.Float64
+	return &grant, nil
+}
+
+// GrantQueries provides database operations for grant accounts
+type GrantQueries struct {
+	db *DB
+}
+
+// NewGrantQueries creates a new GrantQueries instance
+func NewGrantQueries(db *DB) *GrantQueries {
+	return &GrantQueries{db: db}
+}
+
+// GetGrantByNumber retrieves a grant by its grant number
+func (q *GrantQueries) GetGrantByNumber(ctx context.Context, grantNumber string) (*api.GrantAccount, error) {
+	query := `SELECT ` + grantColumns + ` FROM grant_accounts WHERE grant_number = $1`
+
+	grant, err := scanGrant(q.db.QueryRowContext(ctx, query, grantNumber))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Grant %s not found", grantNumber))
+		}
+		return nil, api.NewDatabaseError("get grant by number", err)
+	}
+
+	return grant, nil
+}
+
+// ListGrantAccounts retrieves the budget accounts funded by a grant
+func (q *GrantQueries) ListGrantAccounts(ctx context.Context, grantID int64) ([]*api.BudgetAccount, error) {
+	query := `SELECT ` + accountColumns + ` FROM budget_accounts WHERE grant_id = $1 ORDER BY slurm_account`
+
+	rows, err := q.db.QueryContext(ctx, query, grantID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list grant accounts", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var accounts []*api.BudgetAccount
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan grant account row", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate grant account rows", err)
+	}
+
+	return accounts, nil
+}
+
+// ListAccountGrants retrieves the grants funding an account, directly or through one of its
+// ancestors
+func (q *GrantQueries) ListAccountGrants(ctx context.Context, accountID int64) ([]*api.GrantAccount, error) {
+	query := `SELECT ` + grantColumns + ` FROM grant_accounts
+		WHERE id IN (
+			SELECT ba.grant_id
+			FROM account_and_ancestors($1) anc
+			JOIN budget_accounts ba ON ba.id = anc.account_id
+			WHERE ba.grant_id IS NOT NULL
+		)
+		ORDER BY grant_number`
+
+	rows, err := q.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list account grants", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var grants []*api.GrantAccount
+	for rows.Next() {
+		grant, err := scanGrant(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan account grant row", err)
+		}
+		grants = append(grants, grant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate account grant rows", err)
+	}
+
+	return grants, nil
+}
+
+// RecomputeGrantCosts recomputes a grant's direct costs from the completed charges, less
+// refunds of them, against the accounts it funds and their descendants; charges in SU are
+// costed at the account's current dollars_per_su. Direct costs in
+// excludedCategories, matched against the charges' lower-cased cost components, are
+// recorded as excluded; the indirect costs column follows from both. The grant is locked
+// while its costs are summed, so concurrent recomputations each see every charge committed
+// before them. It returns the grant before and after.
+func (q *GrantQueries) RecomputeGrantCosts(ctx context.Context, grantID int64, excludedCategories []string) (*api.GrantAccount, *api.GrantAccount, error) {
+	lockQuery := `SELECT ` + grantColumns + ` FROM grant_accounts WHERE id = $1 FOR UPDATE`
+
+	updateQuery := `
+		WITH grant_accounts_tree AS (
+			SELECT DISTINCT tree.account_id
+			FROM budget_accounts ba
+			CROSS JOIN LATERAL account_and_descendants(ba.id) tree
+			WHERE ba.grant_id = $1
+		),
+		grant_transactions AS (
+			SELECT t.transaction_id, t.type,
+			       CASE WHEN ba.budget_unit = 'su' THEN t.amount * ba.dollars_per_su ELSE t.amount END AS amount
+			FROM budget_transactions t
+			JOIN budget_accounts ba ON ba.id = t.account_id
+			LEFT JOIN budget_transactions parent ON parent.transaction_id = t.parent_transaction_id
+			WHERE t.account_id IN (SELECT account_id FROM grant_accounts_tree)
+			  AND t.status = 'completed'
+			  AND (t.type = 'charge' OR (t.type = 'refund' AND parent.type = 'charge'))
+		),
+		costs AS (
+			SELECT
+				GREATEST(0, COALESCE(SUM(amount) FILTER (WHERE type = 'charge'), 0)
+				          - COALESCE(SUM(amount) FILTER (WHERE type = 'refund'), 0)) AS direct,
+				(SELECT COALESCE(SUM(c.amount), 0)
+				 FROM transaction_cost_components c
+				 JOIN grant_transactions gt ON gt.transaction_id = c.transaction_id AND gt.type = 'charge'
+				 WHERE LOWER(c.component) = ANY($2)) AS excluded
+			FROM grant_transactions
+		)
+		UPDATE grant_accounts
+		SET direct_costs = costs.direct,
+		    excluded_direct_costs = LEAST(costs.direct, costs.excluded),
+		    updated_at = NOW()
+		FROM costs
+		WHERE grant_accounts.id = $1
+		RETURNING ` + grantColumns
+
+	var previous, updated *api.GrantAccount
+	err := q.db.WithTransaction(ctx, func(tx *sql.Tx) error {
+		var err error
+		previous, err = scanGrant(tx.QueryRowContext(ctx, lockQuery, grantID))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Grant %d not found", grantID))
+			}
+			return api.NewDatabaseError("lock grant", err)
+		}
+
+		updated, err = scanGrant(tx.QueryRowContext(ctx, updateQuery, grantID, pq.Array(excludedCategories)))
+		if err != nil {
+			return api.NewDatabaseError("recompute grant costs", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return previous, updated, nil
+}
+
+// GetBudgetPeriod retrieves a grant's budget period by number, or nil if the period has
+// not been set up
+func (q *GrantQueries) GetBudgetPeriod(ctx context.Context, grantID int64, periodNumber int) (*api.GrantBudgetPeriod, error) {
+	query := `
+		SELECT id, grant_id, period_number, period_start_date, period_end_date,
+		       period_budget_amount, period_spent_amount, period_committed_amount,
+		       COALESCE(expected_burn_rate, 0), COALESCE(actual_burn_rate, 0),
+		       COALESCE(burn_rate_variance, 0), status, created_at, updated_at
+		FROM grant_budget_periods
+		WHERE grant_id = $1 AND period_number = $2`
+
+	var period api.GrantBudgetPeriod
+	err := q.db.QueryRowContext(ctx, query, grantID, periodNumber).Scan(
+		&period.ID, &period.GrantID, &period.PeriodNumber, &period.PeriodStartDate, &period.PeriodEndDate,
+		&period.PeriodBudgetAmount, &period.PeriodSpentAmount, &period.PeriodCommittedAmount,
+		&period.ExpectedBurnRate, &period.ActualBurnRate,
+		&period.BurnRateVariance, &period.Status, &period.CreatedAt, &period.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("get grant budget period", err)
+	}
+
+	return &period, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// MemberQueries provides database operations for the users who may submit under each account
+type MemberQueries struct {
+	db *DB
+}
+
+// NewMemberQueries creates a new MemberQueries instance
+func NewMemberQueries(db *DB) *MemberQueries {
+	return &MemberQueries{db: db}
+}
+
+// AddMember makes user a member of the account. Adding an existing member changes
+// nothing and returns the membership as it was first recorded.
+func (q *MemberQueries) AddMember(ctx context.Context, account *api.BudgetAccount, user string) (*api.AccountMember, error) {
+	query := `
+		WITH added AS (
+			INSERT INTO budget_account_members (account_id, user_name)
+			VALUES ($1, $2)
+			ON CONFLICT (account_id, user_name) DO NOTHING
+			RETURNING created_at
+		)
+		SELECT created_at FROM added
+		UNION ALL
+		SELECT created_at FROM budget_account_members WHERE account_id = $1 AND user_name = $2
+		LIMIT 1`
+
+	member := &api.AccountMember{
+		AccountID:    account.ID,
+		SlurmAccount: account.SlurmAccount,
+		User:         user,
+	}
+	if err := q.db.QueryRowContext(ctx, query, account.ID, user).Scan(&member.CreatedAt); err != nil {
+		return nil, api.NewDatabaseError("add account member", err)
+	}
+
+	return member, nil
+}
+
+// RemoveMember removes user from the account's members
+func (q *MemberQueries) RemoveMember(ctx context.Context, account *api.BudgetAccount, user string) error {
+	result, err := q.db.ExecContext(ctx,
+		`DELETE FROM budget_account_members WHERE account_id = $1 AND user_name = $2`, account.ID, user)
+	if err != nil {
+		return api.NewDatabaseError("remove account member", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return api.NewDatabaseError("get affected rows", err)
+	}
+	if rowsAffected == 0 {
+		return api.NewBudgetError(api.ErrCodeNotFound,
+			fmt.Sprintf("User '%s' is not a member of account '%s'", user, account.SlurmAccount))
+	}
+
+	return nil
+}
+
+// IsMember reports whether user is a member of the account
+func (q *MemberQueries) IsMember(ctx context.Context, accountID int64, user string) (bool, error) {
+	var member bool
+	err := q.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM budget_account_members WHERE account_id = $1 AND user_name = $2
+		)`, accountID, user).Scan(&member)
+	if err != nil {
+		return false, api.NewDatabaseError("check account membership", err)
+	}
+	return member, nil
+}
+
+// ListMembers returns the account's members in user order
+func (q *MemberQueries) ListMembers(ctx context.Context, account *api.BudgetAccount) ([]*api.AccountMember, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT user_name, created_at
+		FROM budget_account_members
+		WHERE account_id = $1
+		ORDER BY user_name`, account.ID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list account members", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	members := []*api.AccountMember{}
+	for rows.Next() {
+		member := &api.AccountMember{AccountID: account.ID, SlurmAccount: account.SlurmAccount}
+		if err := rows.Scan(&member.User, &member.CreatedAt); err != nil {
+			return nil, api.NewDatabaseError("scan account member", err)
+		}
+		members = append(members, member)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate account members", err)
+	}
+
+	return members, nil
+}
+
+// ListMemberAccounts returns the accounts user is a member of in account order. Archived
+// accounts have been merged into another account and are left out.
+func (q *MemberQueries) ListMemberAccounts(ctx context.Context, user string) ([]*api.BudgetAccount, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM budget_accounts
+		WHERE id IN (SELECT account_id FROM budget_account_members WHERE user_name = $1)
+		  AND status <> 'archived'
+		ORDER BY slurm_account`
+
+	rows, err := q.db.QueryContext(ctx, query, user)
+	if err != nil {
+		return nil, api.NewDatabaseError("list member accounts", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	accounts := []*api.BudgetAccount{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan member account", err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate member accounts", err)
+	}
+
+	return accounts, nil
+}
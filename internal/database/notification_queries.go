@@ -0,0 +1,287 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// NotificationQueries provides database operations for the alert notifications queued to
+// be sent, with the outcome of their delivery, and those held for each recipient's next
+// digest
+type NotificationQueries struct {
+	db *DB
+}
+
+// NewNotificationQueries creates a new NotificationQueries instance
+func NewNotificationQueries(db *DB) *NotificationQueries {
+	return &NotificationQueries{db: db}
+}
+
+// AddDigestItem holds an alert for the next digest of each of the recipients
+func (q *NotificationQueries) AddDigestItem(ctx context.Context, recipients []string, item *api.NotificationItem) error {
+	query := `
+		INSERT INTO notification_digest_items (recipient, alert_id, account, alert_type, severity, message, raised_at)
+		SELECT recipient, $2, $3, $4, $5, $6, $7
+		FROM UNNEST($1::text[]) AS recipient`
+
+	_, err := q.db.ExecContext(ctx, query, pq.Array(recipients),
+		item.AlertID, item.Account, item.AlertType, item.Severity, item.Message, item.RaisedAt)
+	if err != nil {
+		return api.NewDatabaseError("add notification digest item", err)
+	}
+	return nil
+}
+
+// ListDigestRecipients returns the recipients with alerts held for their next digest
+func (q *NotificationQueries) ListDigestRecipients(ctx context.Context) ([]string, error) {
+	query := `SELECT DISTINCT recipient FROM notification_digest_items ORDER BY recipient`
+
+	rows, err := q.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, api.NewDatabaseError("list notification digest recipients", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var recipients []string
+	for rows.Next() {
+		var recipient string
+		if err := rows.Scan(&recipient); err != nil {
+			return nil, api.NewDatabaseError("scan notification digest recipient", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate notification digest recipients", err)
+	}
+
+	return recipients, nil
+}
+
+// TakeDigestItems removes and returns the alerts held for a recipient's digest, oldest
+// first. Within tx the items stay locked until it ends, and come back if it rolls back.
+func (q *NotificationQueries) TakeDigestItems(ctx context.Context, tx *sql.Tx, recipient string) ([]api.NotificationItem, error) {
+	query := `
+		WITH taken AS (
+			DELETE FROM notification_digest_items
+			WHERE recipient = $1
+			RETURNING id, alert_id, account, alert_type, severity, message, raised_at
+		)
+		SELECT alert_id, account, alert_type, severity, message, raised_at
+		FROM taken
+		ORDER BY raised_at, id`
+
+	rows, err := tx.QueryContext(ctx, query, recipient)
+	if err != nil {
+		return nil, api.NewDatabaseError("take notification digest items", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var items []api.NotificationItem
+	for rows.Next() {
+		var item api.NotificationItem
+		err := rows.Scan(&item.AlertID, &item.Account, &item.AlertType, &item.Severity, &item.Message, &item.RaisedAt)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan notification digest item", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate notification digest items", err)
+	}
+
+	return items, nil
+}
+
+// notificationColumns is the column list shared by every query that returns a full
+// queued notification
+const notificationColumns = `id, recipient, notification::text, status, attempts, last_error,
+		       created_at, last_attempt_at, delivered_at`
+
+// scanNotification scans a row selected with notificationColumns into a NotificationRecord
+func scanNotification(row rowScanner) (*api.NotificationRecord, error) {
+	var record api.NotificationRecord
+	var body string
+	var lastAttemptAt, deliveredAt sql.NullTime
+	err := row.Scan(
+		&record.ID, &record.Recipient, &body, &record.Status, &record.Attempts, &record.LastError,
+		&record.CreatedAt, &lastAttemptAt, &deliveredAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	record.Notification = &api.Notification{}
+	if err := json.Unmarshal([]byte(body), record.Notification); err != nil {
+		return nil, fmt.Errorf("decode notification: %w", err)
+	}
+	if lastAttemptAt.Valid {
+		record.LastAttemptAt = &lastAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		record.DeliveredAt = &deliveredAt.Time
+	}
+	return &record, nil
+}
+
+// QueueNotification queues a notification for the notification worker to send, within tx
+// when one is given
+func (q *NotificationQueries) QueueNotification(ctx context.Context, tx *sql.Tx, notification *api.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return api.NewDatabaseError("encode notification", err)
+	}
+
+	var execer interface {
+		ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	}
+	if tx != nil {
+		execer = tx
+	} else {
+		execer = q.db
+	}
+
+	query := `INSERT INTO notifications (recipient, notification) VALUES ($1, $2)`
+	if _, err := execer.ExecContext(ctx, query, notification.Recipient, body); err != nil {
+		return api.NewDatabaseError("queue notification", err)
+	}
+	return nil
+}
+
+// ListPendingNotifications returns the IDs of the notifications waiting to be sent, oldest
+// first
+func (q *NotificationQueries) ListPendingNotifications(ctx context.Context) ([]int64, error) {
+	query := `SELECT id FROM notifications WHERE status = 'pending' ORDER BY id`
+
+	rows, err := q.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, api.NewDatabaseError("list pending notifications", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, api.NewDatabaseError("scan pending notification", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate pending notifications", err)
+	}
+
+	return ids, nil
+}
+
+// ListNotifications returns the queued notifications with the status, or every one when
+// status is empty, newest first
+func (q *NotificationQueries) ListNotifications(ctx context.Context, status string) ([]*api.NotificationRecord, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT `+notificationColumns+`
+		FROM notifications
+		WHERE $1::text = '' OR status = $1
+		ORDER BY id DESC`, status)
+	if err != nil {
+		return nil, api.NewDatabaseError("list notifications", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	records := []*api.NotificationRecord{}
+	for rows.Next() {
+		record, err := scanNotification(rows)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan notification", err)
+		}
+		records = append(records, record)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate notifications", err)
+	}
+
+	return records, nil
+}
+
+// ClaimNotification locks a notification with the status for the rest of tx and returns
+// it, or nil when it no longer has the status or another sender holds it
+func (q *NotificationQueries) ClaimNotification(ctx context.Context, tx *sql.Tx, id int64, status string) (*api.NotificationRecord, error) {
+	row := tx.QueryRowContext(ctx, `
+		SELECT `+notificationColumns+`
+		FROM notifications
+		WHERE id = $1 AND status = $2
+		FOR UPDATE SKIP LOCKED`, id, status)
+	record, err := scanNotification(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("claim notification", err)
+	}
+
+	return record, nil
+}
+
+// RecordNotificationAttempt counts an attempt to send a claimed notification and moves it
+// to the status the attempt left it in, keeping why it failed
+func (q *NotificationQueries) RecordNotificationAttempt(ctx context.Context, tx *sql.Tx, id int64, status, lastError string) (*api.NotificationRecord, error) {
+	row := tx.QueryRowContext(ctx, `
+		UPDATE notifications
+		SET status = $2, attempts = attempts + 1, last_error = $3, last_attempt_at = NOW(),
+		    delivered_at = CASE WHEN $2::text = 'delivered' THEN NOW() END
+		WHERE id = $1
+		RETURNING `+notificationColumns,
+		id, status, lastError)
+	record, err := scanNotification(row)
+	if err != nil {
+		return nil, api.NewDatabaseError("record notification attempt", err)
+	}
+
+	return record, nil
+}
+
+// PruneNotifications deletes all but the keep most recently attempted delivered and failed
+// notifications, so their history stays bounded, and returns how many it deleted.
+// Notifications still to be sent are never pruned.
+func (q *NotificationQueries) PruneNotifications(ctx context.Context, keep int) (int64, error) {
+	result, err := q.db.ExecContext(ctx, `
+		DELETE FROM notifications
+		WHERE status <> 'pending' AND id NOT IN (
+			SELECT id FROM notifications
+			WHERE status <> 'pending'
+			ORDER BY last_attempt_at DESC NULLS LAST, id DESC
+			LIMIT $1
+		)`, keep)
+	if err != nil {
+		return 0, api.NewDatabaseError("prune notifications", err)
+	}
+
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, api.NewDatabaseError("prune notifications", err)
+	}
+	return pruned, nil
+}
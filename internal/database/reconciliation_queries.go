@@ -0,0 +1,146 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// ReconciliationQueries provides database operations for ASBX reconciliations and the
+// transactions they involve
+type ReconciliationQueries struct {
+	db *DB
+}
+
+// NewReconciliationQueries creates a new ReconciliationQueries instance
+func NewReconciliationQueries(db *DB) *ReconciliationQueries {
+	return &ReconciliationQueries{db: db}
+}
+
+// RecordSummary records the variance summary of an ASBX reconciliation
+func (q *ReconciliationQueries) RecordSummary(ctx context.Context, summary *api.ASBXReconciliationSummary) error {
+	var originalTransaction interface{}
+	if summary.OriginalTransaction != "" {
+		originalTransaction = summary.OriginalTransaction
+	}
+
+	err := q.db.QueryRowContext(ctx, `
+		INSERT INTO asbx_reconciliations (
+			reconciliation_id, job_id, account, transaction_id, estimated_cost, estimate_source, actual_cost,
+			cost_variance, cost_variance_pct, estimation_accuracy, charged_amount, refund_amount, unreserved
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at`,
+		summary.ReconciliationID, summary.JobID, summary.Account, originalTransaction,
+		summary.EstimatedCost, summary.EstimateSource, summary.ActualCost,
+		summary.CostVariance, summary.CostVariancePct, summary.EstimationAccuracy,
+		summary.ChargedAmount, summary.RefundAmount, summary.Unreserved,
+	).Scan(&summary.CreatedAt)
+	if err != nil {
+		return api.NewDatabaseError("record reconciliation summary", err)
+	}
+	return nil
+}
+
+// GetSummary returns the variance summary of an ASBX reconciliation, or nil when none
+// was recorded
+func (q *ReconciliationQueries) GetSummary(ctx context.Context, reconciliationID string) (*api.ASBXReconciliationSummary, error) {
+	var summary api.ASBXReconciliationSummary
+	err := q.db.QueryRowContext(ctx, `
+		SELECT reconciliation_id, job_id, account, COALESCE(transaction_id, ''), estimated_cost, estimate_source,
+		       actual_cost, cost_variance, cost_variance_pct, estimation_accuracy, charged_amount, refund_amount,
+		       unreserved, created_at
+		FROM asbx_reconciliations
+		WHERE reconciliation_id = $1`, reconciliationID).Scan(
+		&summary.ReconciliationID, &summary.JobID, &summary.Account, &summary.OriginalTransaction,
+		&summary.EstimatedCost, &summary.EstimateSource, &summary.ActualCost, &summary.CostVariance,
+		&summary.CostVariancePct, &summary.EstimationAccuracy, &summary.ChargedAmount, &summary.RefundAmount,
+		&summary.Unreserved, &summary.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("get reconciliation summary", err)
+	}
+
+	return &summary, nil
+}
+
+// GetStatus summarizes the recorded ASBX reconciliations: how many there are, when the
+// latest was made, and the mean accuracy of those whose estimate came from estimateSource
+func (q *ReconciliationQueries) GetStatus(ctx context.Context, estimateSource string) (*api.ASBXIntegrationStatus, error) {
+	var status api.ASBXIntegrationStatus
+	var lastReconciled sql.NullTime
+	err := q.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), MAX(created_at),
+		       COALESCE(AVG(estimation_accuracy) FILTER (WHERE estimate_source = $1), 0)
+		FROM asbx_reconciliations`, estimateSource).Scan(
+		&status.TotalJobsReconciled, &lastReconciled, &status.CostModelAccuracy,
+	)
+	if err != nil {
+		return nil, api.NewDatabaseError("get reconciliation status", err)
+	}
+	if lastReconciled.Valid {
+		status.LastDataImport = &lastReconciled.Time
+	}
+
+	return &status, nil
+}
+
+// ListTransactions returns the transactions of an ASBX reconciliation, oldest first: the
+// charges and refunds carrying its ID and the holds they settled
+func (q *ReconciliationQueries) ListTransactions(ctx context.Context, reconciliationID string) ([]*api.BudgetTransaction, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		WITH settled AS (
+			SELECT transaction_id, parent_transaction_id
+			FROM budget_transactions
+			WHERE metadata->>'reconciliation_id' = $1
+		)
+		SELECT id, transaction_id, account_id, job_id, type, amount, description, COALESCE(metadata::text, ''), status,
+		       created_at, completed_at, cost_share_group, cost_share_percentage, full_hold_amount, started_at
+		FROM budget_transactions
+		WHERE transaction_id IN (SELECT transaction_id FROM settled)
+		   OR transaction_id IN (SELECT parent_transaction_id FROM settled WHERE parent_transaction_id IS NOT NULL)
+		ORDER BY created_at, id`, reconciliationID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list reconciliation transactions", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	transactions := []*api.BudgetTransaction{}
+	for rows.Next() {
+		var transaction api.BudgetTransaction
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.TransactionID,
+			&transaction.AccountID,
+			&transaction.JobID,
+			&transaction.Type,
+			&transaction.Amount,
+			&transaction.Description,
+			&transaction.Metadata,
+			&transaction.Status,
+			&transaction.CreatedAt,
+			&transaction.CompletedAt,
+			&transaction.CostShareGroup,
+			&transaction.CostSharePercentage,
+			&transaction.FullHoldAmount,
+			&transaction.StartedAt,
+		)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan reconciliation transaction", err)
+		}
+		transactions = append(transactions, &transaction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate reconciliation transactions", err)
+	}
+
+	return transactions, nil
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// LedgerEntry is a balance-affecting transaction along with the type of its parent
+type LedgerEntry struct {
+	Type           string
+	Amount         float64
+	ParentType     string // empty when the transaction has no parent
+	FromDescendant bool   // recorded against a child account and rolled up to this one
+	EffectiveAt    time.Time
+}
+
+// SnapshotQueries provides database operations for budget snapshots
+type SnapshotQueries struct {
+	db *DB
+}
+
+// NewSnapshotQueries creates a new SnapshotQueries instance
+func NewSnapshotQueries(db *DB) *SnapshotQueries {
+	return &SnapshotQueries{db: db}
+}
+
+// CaptureSnapshots records the current balances of every account for the given date
+func (q *SnapshotQueries) CaptureSnapshots(ctx context.Context, snapshotDate time.Time) (int, error) {
+	var captured int
+	err := q.db.QueryRowContext(ctx, `SELECT capture_budget_snapshots($1)`, snapshotDate).Scan(&captured)
+	if err != nil {
+		return 0, api.NewDatabaseError("capture budget snapshots", err)
+	}
+	return captured, nil
+}
+
+// GetLatestSnapshot returns the most recent snapshot taken on or before the given date,
+// or nil if the account has no snapshot that old
+func (q *SnapshotQueries) GetLatestSnapshot(ctx context.Context, accountID int64, onOrBefore time.Time) (*api.BudgetSnapshot, error) {
+	query := `
+		SELECT id, account_id, snapshot_date, budget_limit, budget_used, budget_held,
+		       budget_available, captured_at
+		FROM budget_snapshots
+		WHERE account_id = $1 AND snapshot_date <= $2
+		ORDER BY snapshot_date DESC
+		LIMIT 1`
+
+	var snapshot api.BudgetSnapshot
+	err := q.db.QueryRowContext(ctx, query, accountID, onOrBefore).Scan(
+		&snapshot.ID, &snapshot.AccountID, &snapshot.SnapshotDate,
+		&snapshot.BudgetLimit, &snapshot.BudgetUsed, &snapshot.BudgetHeld,
+		&snapshot.BudgetAvailable, &snapshot.CapturedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("get latest snapshot", err)
+	}
+
+	snapshot.Source = "snapshot"
+	return &snapshot, nil
+}
+
+// ListLedgerEntries returns the balance-affecting transactions for an account and its
+// descendants that took effect after `after` and no later than `until`, in the order they
+// were applied. A non-nil tx reads the ledger within that transaction.
+func (q *SnapshotQueries) ListLedgerEntries(ctx context.Context, tx *sql.Tx, accountID int64, after, until time.Time) ([]*LedgerEntry, error) {
+	query := `
+		SELECT bt.type, bt.amount, COALESCE(p.type, ''), bt.account_id <> $1,
+		       COALESCE(bt.completed_at, bt.created_at) AS effective_at
+		FROM budget_transactions bt
+		LEFT JOIN budget_transactions p ON p.transaction_id = bt.parent_transaction_id
+		WHERE bt.account_id IN (SELECT account_id FROM account_and_descendants($1))
+		  AND (bt.completed_at IS NOT NULL OR bt.status = 'completed')
+		  AND COALESCE(bt.completed_at, bt.created_at) > $2
+		  AND COALESCE(bt.completed_at, bt.created_at) <= $3
+		ORDER BY effective_at ASC, bt.id ASC`
+
+	var queryer interface {
+		QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
+	}
+
+	if tx != nil {
+		queryer = tx
+	} else {
+		queryer = q.db
+	}
+
+	rows, err := queryer.QueryContext(ctx, query, accountID, after, until)
+	if err != nil {
+		return nil, api.NewDatabaseError("list ledger entries", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var entries []*LedgerEntry
+	for rows.Next() {
+		var entry LedgerEntry
+		if err := rows.Scan(&entry.Type, &entry.Amount, &entry.ParentType, &entry.FromDescendant, &entry.EffectiveAt); err != nil {
+			return nil, api.NewDatabaseError("scan ledger entry", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate ledger entries", err)
+	}
+
+	return entries, nil
+}
+
+// GetLimitAt returns the budget limit an account had in force at the given time, or nil
+// when its limit history does not reach back that far
+func (q *SnapshotQueries) GetLimitAt(ctx context.Context, accountID int64, at time.Time) (*float64, error) {
+	var limit float64
+	err := q.db.QueryRowContext(ctx, `
+		SELECT budget_limit
+		FROM budget_limit_history
+		WHERE account_id = $1 AND effective_at <= $2
+		ORDER BY effective_at DESC, id DESC
+		LIMIT 1`, accountID, at).Scan(&limit)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, api.NewDatabaseError("get budget limit", err)
+	}
+
+	return &limit, nil
+}
+
+// ListLimitChanges returns an account's budget limit history, oldest first
+func (q *SnapshotQueries) ListLimitChanges(ctx context.Context, accountID int64) ([]*api.BudgetLimitChange, error) {
+	rows, err := q.db.QueryContext(ctx, `
+		SELECT id, account_id, previous_limit, budget_limit, effective_at
+		FROM budget_limit_history
+		WHERE account_id = $1
+		ORDER BY effective_at, id`, accountID)
+	if err != nil {
+		return nil, api.NewDatabaseError("list budget limit changes", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	changes := []*api.BudgetLimitChange{}
+	for rows.Next() {
+		var change api.BudgetLimitChange
+		var previous sql.NullFloat64
+		if err := rows.Scan(&change.ID, &change.AccountID, &previous, &change.BudgetLimit, &change.EffectiveAt); err != nil {
+			return nil, api.NewDatabaseError("scan budget limit change", err)
+		}
+		if previous.Valid {
+			change.PreviousLimit = &previous.Float64
+		}
+		changes = append(changes, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate budget limit changes", err)
+	}
+
+	return changes, nil
+}
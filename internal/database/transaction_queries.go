@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
+
 	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
@@ -24,12 +26,18 @@ func NewTransactionQueries(db *DB) *TransactionQueries {
 	return &TransactionQueries{db: db}
 }
 
-// CreateTransaction creates a new budget transaction
+// CreateTransaction creates a new budget transaction. Its metadata must match the schema of
+// its type; empty metadata is stored as NULL.
 func (q *TransactionQueries) CreateTransaction(ctx context.Context, tx *sql.Tx, transaction *api.BudgetTransaction) error {
+	if err := api.ValidateTransactionMetadata(transaction.Type, transaction.Metadata); err != nil {
+		return api.NewValidationError("metadata", err.Error())
+	}
+
 	query := `
-		INSERT INTO budget_transactions (transaction_id, account_id, job_id, type, amount, description, metadata, status, parent_transaction_id)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, created_at`
+		INSERT INTO budget_transactions (transaction_id, account_id, job_id, type, amount, description, metadata, status, parent_transaction_id,
+		                                 cost_share_group, cost_share_percentage, full_hold_amount, grant_budget_period_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::jsonb, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, grant_budget_period_id`
 
 	var execer interface {
 		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
@@ -50,12 +58,20 @@ func (q *TransactionQueries) CreateTransaction(ctx context.Context, tx *sql.Tx,
 		transaction.Description,
 		transaction.Metadata,
 		transaction.Status,
-		nil, // parent_transaction_id - set separately if needed
-	).Scan(&transaction.ID, &transaction.CreatedAt)
+		transaction.ParentTransactionID,
+		transaction.CostShareGroup,
+		transaction.CostSharePercentage,
+		transaction.FullHoldAmount,
+		transaction.GrantBudgetPeriodID,
+	).Scan(&transaction.ID, &transaction.CreatedAt, &transaction.GrantBudgetPeriodID)
 
 	if err != nil {
+		if isUniqueViolation(err, "budget_transactions_transaction_id_key") {
+			return api.NewDuplicateTransactionError(transaction.TransactionID, err)
+		}
 		return api.NewDatabaseError("create transaction", err)
 	}
+	q.db.accountChanged(tx, transaction.AccountID)
 
 	return nil
 }
@@ -63,7 +79,8 @@ func (q *TransactionQueries) CreateTransaction(ctx context.Context, tx *sql.Tx,
 // GetTransaction retrieves a transaction by ID
 func (q *TransactionQueries) GetTransaction(ctx context.Context, transactionID string) (*api.BudgetTransaction, error) {
 	query := `
-		SELECT id, transaction_id, account_id, job_id, type, amount, description, metadata, status, created_at, completed_at
+		SELECT id, transaction_id, account_id, job_id, type, amount, description, COALESCE(metadata::text, ''), status, created_at, completed_at,
+		       cost_share_group, cost_share_percentage, full_hold_amount, started_at, grant_budget_period_id
 		FROM budget_transactions
 		WHERE transaction_id = $1`
 
@@ -80,6 +97,11 @@ func (q *TransactionQueries) GetTransaction(ctx context.Context, transactionID s
 		&transaction.Status,
 		&transaction.CreatedAt,
 		&transaction.CompletedAt,
+		&transaction.CostShareGroup,
+		&transaction.CostSharePercentage,
+		&transaction.FullHoldAmount,
+		&transaction.StartedAt,
+		&transaction.GrantBudgetPeriodID,
 	)
 
 	if err != nil {
@@ -92,35 +114,280 @@ func (q *TransactionQueries) GetTransaction(ctx context.Context, transactionID s
 	return &transaction, nil
 }
 
+// CostShareHold is one funding account's hold for a cost-shared job
+type CostShareHold struct {
+	Hold    *api.BudgetTransaction
+	Account string // SLURM account of the hold
+}
+
+// ListCostShareHolds returns the holds of a cost-shared job in the order they were placed,
+// the job's own account first
+func (q *TransactionQueries) ListCostShareHolds(ctx context.Context, group string) ([]*CostShareHold, error) {
+	query := `
+		SELECT bt.id, bt.transaction_id, bt.account_id, bt.job_id, bt.type, bt.amount, bt.description,
+		       COALESCE(bt.metadata::text, ''), bt.status, bt.created_at, bt.completed_at, bt.cost_share_group,
+		       bt.cost_share_percentage, bt.grant_budget_period_id, ba.slurm_account
+		FROM budget_transactions bt
+		JOIN budget_accounts ba ON ba.id = bt.account_id
+		WHERE bt.cost_share_group = $1 AND bt.type = 'hold'
+		ORDER BY bt.id`
+
+	rows, err := q.db.QueryContext(ctx, query, group)
+	if err != nil {
+		return nil, api.NewDatabaseError("list cost share holds", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var holds []*CostShareHold
+	for rows.Next() {
+		var transaction api.BudgetTransaction
+		hold := &CostShareHold{Hold: &transaction}
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.TransactionID,
+			&transaction.AccountID,
+			&transaction.JobID,
+			&transaction.Type,
+			&transaction.Amount,
+			&transaction.Description,
+			&transaction.Metadata,
+			&transaction.Status,
+			&transaction.CreatedAt,
+			&transaction.CompletedAt,
+			&transaction.CostShareGroup,
+			&transaction.CostSharePercentage,
+			&transaction.GrantBudgetPeriodID,
+			&hold.Account,
+		)
+		if err != nil {
+			return nil, api.NewDatabaseError("scan cost share hold", err)
+		}
+		holds = append(holds, hold)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate cost share holds", err)
+	}
+
+	return holds, nil
+}
+
 // UpdateTransactionStatus updates a transaction's status
 func (q *TransactionQueries) UpdateTransactionStatus(ctx context.Context, tx *sql.Tx, transactionID string, status string) error {
 	query := `
 		UPDATE budget_transactions
 		SET status = $2, completed_at = CASE WHEN $2 = 'completed' THEN NOW() ELSE completed_at END
-		WHERE transaction_id = $1`
+		WHERE transaction_id = $1
+		RETURNING account_id`
 
-	var execer interface {
-		ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	var queryer interface {
+		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
 	}
 
 	if tx != nil {
-		execer = tx
+		queryer = tx
 	} else {
-		execer = q.db
+		queryer = q.db
 	}
 
-	result, err := execer.ExecContext(ctx, query, transactionID, status)
-	if err != nil {
+	var accountID int64
+	if err := queryer.QueryRowContext(ctx, query, transactionID, status).Scan(&accountID); err != nil {
+		if err == sql.ErrNoRows {
+			return api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Transaction %s not found", transactionID))
+		}
 		return api.NewDatabaseError("update transaction status", err)
 	}
+	q.db.accountChanged(tx, accountID)
+
+	return nil
+}
+
+// unsettledHold matches, on rows aliased bt, a hold awaiting reconciliation: placed,
+// whether or not its balance was applied, and not yet reconciled, cancelled, or charged or
+// released by a transaction against it
+const unsettledHold = `bt.type = 'hold' AND bt.status IN ('pending', 'completed') AND bt.reconciled_at IS NULL
+	AND NOT EXISTS (
+		SELECT 1 FROM budget_transactions settled
+		WHERE settled.parent_transaction_id = bt.transaction_id AND settled.type IN ('charge', 'refund')
+	)`
+
+// LockUnsettledHold locks a hold awaiting reconciliation within tx, so that reconciliations
+// of the same hold, such as a replayed epilog post, settle it one at a time. False is
+// returned when the hold has already been settled.
+func (q *TransactionQueries) LockUnsettledHold(ctx context.Context, tx *sql.Tx, transactionID string) (bool, error) {
+	query := `
+		SELECT bt.id
+		FROM budget_transactions bt
+		WHERE bt.transaction_id = $1 AND ` + unsettledHold + `
+		FOR UPDATE`
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, query, transactionID).Scan(&id); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, api.NewDatabaseError("lock hold", err)
+	}
+	return true, nil
+}
+
+// MarkHoldReconciled records when a hold was reconciled, keeping the first time if it is
+// reconciled again, and returns how long it waited for reconciliation
+func (q *TransactionQueries) MarkHoldReconciled(ctx context.Context, tx *sql.Tx, transactionID string) (time.Duration, error) {
+	query := `
+		UPDATE budget_transactions
+		SET reconciled_at = COALESCE(reconciled_at, NOW())
+		WHERE transaction_id = $1 AND type = 'hold'
+		RETURNING EXTRACT(EPOCH FROM reconciled_at - created_at)`
+
+	var queryer interface {
+		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+	}
+
+	if tx != nil {
+		queryer = tx
+	} else {
+		queryer = q.db
+	}
+
+	var seconds float64
+	if err := queryer.QueryRowContext(ctx, query, transactionID).Scan(&seconds); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Hold %s not found", transactionID))
+		}
+		return 0, api.NewDatabaseError("mark hold reconciled", err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// EscalateHold starts a queued job's hold: it raises the hold from its reservation to the
+// full amount, records when the job started and tags the hold with the job ID. The
+// balance trigger only acts when a hold is placed, so the difference is added to the held
+// budget of the account and its ancestors here. A hold that was placed in full, has
+// already started, has been charged or released, or is tagged with another job is left
+// alone, and false is returned.
+func (q *TransactionQueries) EscalateHold(ctx context.Context, tx *sql.Tx, transactionID, jobID string) (float64, bool, error) {
+	query := `
+		WITH queued AS (
+			SELECT id, account_id, full_hold_amount - amount AS added
+			FROM budget_transactions
+			WHERE transaction_id = $1 AND type = 'hold' AND status = 'completed'
+			  AND full_hold_amount IS NOT NULL AND started_at IS NULL
+			  AND (job_id IS NULL OR job_id = '' OR job_id = $2)
+			  AND NOT EXISTS (
+			      SELECT 1 FROM budget_transactions released WHERE released.parent_transaction_id = $1
+			  )
+			FOR UPDATE
+		), started AS (
+			UPDATE budget_transactions hold
+			SET amount = hold.full_hold_amount, started_at = NOW(), job_id = $2
+			FROM queued
+			WHERE hold.id = queued.id
+			RETURNING queued.account_id, queued.added
+		), held AS (
+			UPDATE budget_accounts
+			SET budget_held = budget_held + started.added, updated_at = NOW()
+			FROM started
+			WHERE budget_accounts.id IN (SELECT account_id FROM account_and_ancestors(started.account_id))
+		)
+		SELECT account_id, added FROM started`
+
+	var queryer interface {
+		QueryRowContext(context.Context, string, ...interface{}) *sql.Row
+	}
+
+	if tx != nil {
+		queryer = tx
+	} else {
+		queryer = q.db
+	}
+
+	var accountID int64
+	var added float64
+	if err := queryer.QueryRowContext(ctx, query, transactionID, jobID).Scan(&accountID, &added); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, false, nil
+		}
+		return 0, false, api.NewDatabaseError("escalate hold", err)
+	}
+	q.db.accountChanged(tx, accountID)
+
+	return added, true, nil
+}
 
-	rowsAffected, err := result.RowsAffected()
+// AdjustHold changes a live hold's amount to adjustment.NewAmount, moves the account's
+// and its ancestors' held budget by the difference, and records the adjustment. A live
+// hold has been placed and not yet charged, released or cancelled; a queued hold must
+// have started. The previous amount, account and audit fields are filled in on success.
+// False is returned, with nothing changed, when the hold is not live.
+func (q *TransactionQueries) AdjustHold(ctx context.Context, tx *sql.Tx, adjustment *api.HoldAdjustment) (bool, error) {
+	query := `
+		WITH live AS (
+			SELECT id, account_id, amount
+			FROM budget_transactions
+			WHERE transaction_id = $1 AND type = 'hold' AND status = 'completed' AND reconciled_at IS NULL
+			  AND (full_hold_amount IS NULL OR started_at IS NOT NULL)
+			  AND NOT EXISTS (
+			      SELECT 1 FROM budget_transactions released WHERE released.parent_transaction_id = $1
+			  )
+			FOR UPDATE
+		), adjusted AS (
+			UPDATE budget_transactions hold
+			SET amount = $2,
+			    full_hold_amount = CASE WHEN hold.full_hold_amount IS NULL THEN NULL ELSE $2 END
+			FROM live
+			WHERE hold.id = live.id
+			RETURNING live.account_id, live.amount AS previous_amount
+		), held AS (
+			UPDATE budget_accounts
+			SET budget_held = GREATEST(0, budget_held + $2 - adjusted.previous_amount), updated_at = NOW()
+			FROM adjusted
+			WHERE budget_accounts.id IN (SELECT account_id FROM account_and_ancestors(adjusted.account_id))
+		)
+		INSERT INTO budget_hold_adjustments (transaction_id, account_id, previous_amount, new_amount, reason, adjusted_by)
+		SELECT $1, account_id, previous_amount, $2, $3, NULLIF($4, '')
+		FROM adjusted
+		RETURNING id, account_id, previous_amount, created_at`
+
+	err := tx.QueryRowContext(ctx, query,
+		adjustment.TransactionID, adjustment.NewAmount, adjustment.Reason, adjustment.AdjustedBy,
+	).Scan(&adjustment.ID, &adjustment.AccountID, &adjustment.PreviousAmount, &adjustment.CreatedAt)
 	if err != nil {
-		return api.NewDatabaseError("get affected rows", err)
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, api.NewDatabaseError("adjust hold", err)
+	}
+	q.db.accountChanged(tx, adjustment.AccountID)
+
+	return true, nil
+}
+
+// CreateCostComponents records the per-component breakdown of a charge transaction
+func (q *TransactionQueries) CreateCostComponents(ctx context.Context, tx *sql.Tx, transactionID string, breakdown map[string]float64) error {
+	query := `
+		INSERT INTO transaction_cost_components (transaction_id, component, amount)
+		VALUES ($1, $2, $3)`
+
+	var execer interface {
+		ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
 	}
 
-	if rowsAffected == 0 {
-		return api.NewBudgetError(api.ErrCodeNotFound, fmt.Sprintf("Transaction %s not found", transactionID))
+	if tx != nil {
+		execer = tx
+	} else {
+		execer = q.db
+	}
+
+	for component, amount := range breakdown {
+		if _, err := execer.ExecContext(ctx, query, transactionID, component, amount); err != nil {
+			return api.NewDatabaseError("create cost component", err)
+		}
 	}
 
 	return nil
@@ -130,7 +397,8 @@ func (q *TransactionQueries) UpdateTransactionStatus(ctx context.Context, tx *sq
 func (q *TransactionQueries) ListTransactions(ctx context.Context, req *api.TransactionListRequest) ([]*api.BudgetTransaction, error) {
 	baseQuery := `
 		SELECT bt.id, bt.transaction_id, bt.account_id, bt.job_id, bt.type, bt.amount,
-		       bt.description, bt.metadata, bt.status, bt.created_at, bt.completed_at
+		       bt.description, COALESCE(bt.metadata::text, ''), bt.status, bt.created_at, bt.completed_at,
+		       bt.cost_share_group, bt.cost_share_percentage, bt.full_hold_amount, bt.started_at
 		FROM budget_transactions bt`
 
 	var joins []string
@@ -164,6 +432,14 @@ func (q *TransactionQueries) ListTransactions(ctx context.Context, req *api.Tran
 		argIndex++
 	}
 
+	// Case-insensitive substring search, served by the trigram indexes
+	if req.Search != "" {
+		conditions = append(conditions, fmt.Sprintf(
+			`(bt.description ILIKE $%d ESCAPE '\' OR bt.metadata::text ILIKE $%d ESCAPE '\')`, argIndex, argIndex))
+		args = append(args, "%"+escapeLike(req.Search)+"%")
+		argIndex++
+	}
+
 	if req.StartDate != nil {
 		conditions = append(conditions, fmt.Sprintf("bt.created_at >= $%d", argIndex))
 		args = append(args, *req.StartDate)
@@ -176,6 +452,18 @@ func (q *TransactionQueries) ListTransactions(ctx context.Context, req *api.Tran
 		argIndex++
 	}
 
+	// Keyset pagination continues after the cursor's row, seeking into the (created_at, id)
+	// index rather than scanning past every earlier page as an offset does
+	if req.Cursor != "" {
+		cursor, err := api.ParseTransactionCursor(req.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(bt.created_at, bt.id) < ($%d, $%d)", argIndex, argIndex+1))
+		args = append(args, cursor.CreatedAt, cursor.ID)
+		argIndex += 2
+	}
+
 	// Build final query
 	if len(joins) > 0 {
 		baseQuery += " " + strings.Join(joins, " ")
@@ -185,7 +473,9 @@ func (q *TransactionQueries) ListTransactions(ctx context.Context, req *api.Tran
 		baseQuery += " WHERE " + strings.Join(conditions, " AND ")
 	}
 
-	baseQuery += " ORDER BY bt.created_at DESC"
+	// The ID breaks ties between transactions created at the same instant, so the order,
+	// and so every page, is stable
+	baseQuery += " ORDER BY bt.created_at DESC, bt.id DESC"
 
 	if req.Limit > 0 {
 		baseQuery += fmt.Sprintf(" LIMIT $%d", argIndex)
@@ -224,6 +514,10 @@ func (q *TransactionQueries) ListTransactions(ctx context.Context, req *api.Tran
 			&transaction.Status,
 			&transaction.CreatedAt,
 			&transaction.CompletedAt,
+			&transaction.CostShareGroup,
+			&transaction.CostSharePercentage,
+			&transaction.FullHoldAmount,
+			&transaction.StartedAt,
 		)
 		if err != nil {
 			return nil, api.NewDatabaseError("scan transaction row", err)
@@ -234,16 +528,26 @@ func (q *TransactionQueries) ListTransactions(ctx context.Context, req *api.Tran
 	return transactions, nil
 }
 
-// GetPendingHolds retrieves pending hold transactions for reconciliation
-func (q *TransactionQueries) GetPendingHolds(ctx context.Context, olderThan time.Duration) ([]*api.BudgetTransaction, error) {
+// GetPendingHolds retrieves up to limit holds awaiting reconciliation older than olderThan
+// for recovery, in ID order after afterID, so a large backlog can be paged through.
+// Dead-lettered holds are left out until an administrator replays or resolves them.
+func (q *TransactionQueries) GetPendingHolds(ctx context.Context, olderThan time.Duration, afterID int64, limit int) ([]*api.BudgetTransaction, error) {
 	query := `
-		SELECT id, transaction_id, account_id, job_id, type, amount, description, metadata, status, created_at, completed_at
-		FROM budget_transactions
-		WHERE type = 'hold' AND status = 'pending' AND created_at < $1`
+		SELECT bt.id, bt.transaction_id, bt.account_id, bt.job_id, bt.type, bt.amount, bt.description,
+		       COALESCE(bt.metadata::text, ''), bt.status, bt.created_at, bt.completed_at
+		FROM budget_transactions bt
+		WHERE ` + unsettledHold + ` AND bt.created_at < $1 AND bt.id > $2
+		  AND NOT EXISTS (
+			SELECT 1 FROM reconciliation_failures f
+			WHERE f.transaction_id = bt.transaction_id
+			  AND f.dead_lettered_at IS NOT NULL AND f.resolved_at IS NULL
+		  )
+		ORDER BY bt.id
+		LIMIT $3`
 
 	cutoff := time.Now().Add(-olderThan)
 
-	rows, err := q.db.QueryContext(ctx, query, cutoff)
+	rows, err := q.db.QueryContext(ctx, query, cutoff, afterID, limit)
 	if err != nil {
 		return nil, api.NewDatabaseError("get pending holds", err)
 	}
@@ -278,3 +582,136 @@ func (q *TransactionQueries) GetPendingHolds(ctx context.Context, olderThan time
 
 	return transactions, nil
 }
+
+// likeEscaper escapes the LIKE wildcards, and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike makes s match literally within a LIKE pattern
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// SumRecentHolds totals, for each of the given accounts, the holds placed on it or its
+// descendants since the given time that have not yet been charged or released
+func (q *TransactionQueries) SumRecentHolds(ctx context.Context, accountIDs []int64, since time.Time) (map[int64]float64, error) {
+	query := `
+		SELECT chain.account_id, SUM(bt.amount)
+		FROM budget_transactions bt
+		CROSS JOIN LATERAL account_and_ancestors(bt.account_id) chain
+		WHERE bt.type = 'hold' AND bt.status = 'completed' AND bt.created_at > $1
+		  AND chain.account_id = ANY($2)
+		  AND NOT EXISTS (
+			SELECT 1 FROM budget_transactions released
+			WHERE released.parent_transaction_id = bt.transaction_id
+		  )
+		GROUP BY chain.account_id`
+
+	rows, err := q.db.QueryContext(ctx, query, since, pq.Array(accountIDs))
+	if err != nil {
+		return nil, api.NewDatabaseError("sum recent holds", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	totals := make(map[int64]float64)
+	for rows.Next() {
+		var accountID int64
+		var total float64
+		if err := rows.Scan(&accountID, &total); err != nil {
+			return nil, api.NewDatabaseError("scan recent hold total", err)
+		}
+		totals[accountID] = total
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate recent hold totals", err)
+	}
+
+	return totals, nil
+}
+
+// ListPendingReconciliations returns holds awaiting reconciliation, oldest first,
+// optionally limited to one account
+func (q *TransactionQueries) ListPendingReconciliations(ctx context.Context, slurmAccount string) ([]*api.PendingReconciliation, error) {
+	query := `
+		SELECT bt.transaction_id, COALESCE(bt.job_id, ''), bt.account_id, ba.slurm_account, bt.amount, bt.created_at
+		FROM budget_transactions bt
+		JOIN budget_accounts ba ON ba.id = bt.account_id
+		WHERE ` + unsettledHold
+
+	var args []interface{}
+	if slurmAccount != "" {
+		query += " AND ba.slurm_account = $1"
+		args = append(args, slurmAccount)
+	}
+	query += " ORDER BY bt.created_at ASC"
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("list pending reconciliations", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	holds := []*api.PendingReconciliation{}
+	for rows.Next() {
+		var hold api.PendingReconciliation
+		if err := rows.Scan(&hold.TransactionID, &hold.JobID, &hold.AccountID, &hold.Account, &hold.Amount, &hold.CreatedAt); err != nil {
+			return nil, api.NewDatabaseError("scan pending reconciliation", err)
+		}
+		holds = append(holds, &hold)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate pending reconciliations", err)
+	}
+
+	return holds, nil
+}
+
+// ListOrphanedHolds returns holds awaiting reconciliation created more than olderThan ago
+// with the name of the account they are held on, oldest first
+func (q *TransactionQueries) ListOrphanedHolds(ctx context.Context, olderThan time.Duration) ([]*api.OrphanedHold, error) {
+	query := `
+		SELECT bt.transaction_id, bt.account_id, ba.slurm_account, bt.amount, bt.created_at
+		FROM budget_transactions bt
+		JOIN budget_accounts ba ON ba.id = bt.account_id
+		WHERE ` + unsettledHold + ` AND bt.created_at < $1
+		ORDER BY bt.created_at ASC`
+
+	now := time.Now()
+	rows, err := q.db.QueryContext(ctx, query, now.Add(-olderThan))
+	if err != nil {
+		return nil, api.NewDatabaseError("list orphaned holds", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	holds := []*api.OrphanedHold{}
+	for rows.Next() {
+		var hold api.OrphanedHold
+		if err := rows.Scan(&hold.TransactionID, &hold.AccountID, &hold.Account, &hold.Amount, &hold.CreatedAt); err != nil {
+			return nil, api.NewDatabaseError("scan orphaned hold", err)
+		}
+		hold.AgeHours = now.Sub(hold.CreatedAt).Hours()
+		holds = append(holds, &hold)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate orphaned holds", err)
+	}
+
+	return holds, nil
+}
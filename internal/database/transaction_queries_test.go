@@ -0,0 +1,37 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "gpu node", escapeLike("gpu node"))
+	assert.Equal(t, `100\%`, escapeLike("100%"))
+	assert.Equal(t, `fee\_waiver`, escapeLike("fee_waiver"))
+	assert.Equal(t, `C:\\scratch`, escapeLike(`C:\scratch`))
+}
+
+func TestCreateTransaction_RejectsInvalidMetadata(t *testing.T) {
+	q := NewTransactionQueries(nil)
+
+	err := q.CreateTransaction(context.Background(), nil, &api.BudgetTransaction{
+		TransactionID: "txn_1",
+		Type:          "hold",
+		Amount:        12,
+		Metadata:      `{"version":1}`,
+	})
+	budgetErr, ok := api.AsBudgetError(err)
+	require.True(t, ok)
+	assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	assert.Equal(t, "metadata", budgetErr.Field)
+}
@@ -0,0 +1,178 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// TransferQueries provides database operations for transferring one account into another
+type TransferQueries struct {
+	db *DB
+}
+
+// NewTransferQueries creates a new TransferQueries instance
+func NewTransferQueries(db *DB) *TransferQueries {
+	return &TransferQueries{db: db}
+}
+
+// CountChildren returns how many accounts have accountID as their parent
+func (q *TransferQueries) CountChildren(ctx context.Context, tx *sql.Tx, accountID int64) (int64, error) {
+	var count int64
+	err := tx.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM budget_accounts WHERE parent_account_id = $1`, accountID).Scan(&count)
+	if err != nil {
+		return 0, api.NewDatabaseError("count child accounts", err)
+	}
+	return count, nil
+}
+
+// TransferAccount moves the source account's transactions, job submissions, allocations and
+// schedules to the destination and combines its balances into the destination's, then
+// archives the source and records the transfer. The transfer's amounts must hold the
+// source's balances, read with both accounts locked. Retired schedules are cancelled before
+// they move, so the destination keeps them only as history.
+func (q *TransferQueries) TransferAccount(ctx context.Context, tx *sql.Tx, transfer *api.AccountTransfer, retireSchedules bool) error {
+	source, dest := transfer.SourceAccountID, transfer.DestAccountID
+
+	// Completed transactions have already been counted in the balances moved below, and
+	// pending ones complete against the destination
+	moved, err := execCount(ctx, tx, "move transactions",
+		`UPDATE budget_transactions SET account_id = $2 WHERE account_id = $1`, source, dest)
+	if err != nil {
+		return err
+	}
+	transfer.TransactionsMoved = moved
+
+	if _, err := execCount(ctx, tx, "move job submissions",
+		`UPDATE job_submissions SET account_id = $2 WHERE account_id = $1`, source, dest); err != nil {
+		return err
+	}
+
+	if _, err := execCount(ctx, tx, "move allocations",
+		`UPDATE budget_allocations SET account_id = $2 WHERE account_id = $1`, source, dest); err != nil {
+		return err
+	}
+
+	// The source's members keep a view of the budget they submitted against
+	if _, err := execCount(ctx, tx, "move account members", `
+		INSERT INTO budget_account_members (account_id, user_name)
+		SELECT $2, user_name FROM budget_account_members WHERE account_id = $1
+		ON CONFLICT (account_id, user_name) DO NOTHING`, source, dest); err != nil {
+		return err
+	}
+
+	if retireSchedules {
+		retired, err := execCount(ctx, tx, "retire allocation schedules", `
+			UPDATE budget_allocation_schedules
+			SET status = 'cancelled', updated_at = NOW()
+			WHERE account_id = $1 AND status IN ('active', 'paused')`, source)
+		if err != nil {
+			return err
+		}
+		transfer.SchedulesRetired = retired
+	} else {
+		merged, err := execCount(ctx, tx, "merge allocation schedules", `
+			UPDATE budget_allocation_schedules
+			SET account_id = $2, updated_at = NOW()
+			WHERE account_id = $1 AND status IN ('active', 'paused')`, source, dest)
+		if err != nil {
+			return err
+		}
+		transfer.SchedulesMerged = merged
+	}
+
+	if _, err := execCount(ctx, tx, "move allocation schedules",
+		`UPDATE budget_allocation_schedules SET account_id = $2 WHERE account_id = $1`, source, dest); err != nil {
+		return err
+	}
+
+	// Used and held roll up the hierarchy, so they leave the source's ancestors and join
+	// the destination's, as set_account_parent moves them. Limits do not roll up.
+	if _, err := execCount(ctx, tx, "release source ancestors", `
+		UPDATE budget_accounts
+		SET budget_used = GREATEST(0, budget_used - $2),
+		    budget_held = GREATEST(0, budget_held - $3),
+		    updated_at = NOW()
+		WHERE id IN (SELECT account_id FROM account_and_ancestors($1) WHERE depth > 0)`,
+		source, transfer.BudgetUsed, transfer.BudgetHeld); err != nil {
+		return err
+	}
+
+	if _, err := execCount(ctx, tx, "charge destination hierarchy", `
+		UPDATE budget_accounts
+		SET budget_used = budget_used + $2,
+		    budget_held = budget_held + $3,
+		    updated_at = NOW()
+		WHERE id IN (SELECT account_id FROM account_and_ancestors($1))`,
+		dest, transfer.BudgetUsed, transfer.BudgetHeld); err != nil {
+		return err
+	}
+
+	if _, err := execCount(ctx, tx, "combine destination budget", `
+		UPDATE budget_accounts
+		SET budget_limit = budget_limit + $2,
+		    reserved_amount = reserved_amount + $3,
+		    total_allocated = total_allocated + $4,
+		    has_incremental_budget = has_incremental_budget OR EXISTS (
+		        SELECT 1 FROM budget_allocation_schedules
+		        WHERE account_id = $1 AND status = 'active'
+		    ),
+		    next_allocation_date = COALESCE((
+		        SELECT MIN(next_allocation_date) FROM budget_allocation_schedules
+		        WHERE account_id = $1 AND status = 'active'
+		    ), next_allocation_date),
+		    updated_at = NOW()
+		WHERE id = $1`,
+		dest, transfer.BudgetLimit, transfer.ReservedAmount, transfer.TotalAllocated); err != nil {
+		return err
+	}
+
+	if _, err := execCount(ctx, tx, "archive source account", `
+		UPDATE budget_accounts
+		SET budget_limit = 0, budget_used = 0, budget_held = 0, reserved_amount = 0,
+		    total_allocated = 0, has_incremental_budget = FALSE, next_allocation_date = NULL,
+		    status = 'archived', updated_at = NOW()
+		WHERE id = $1`, source); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO budget_account_transfers (
+			source_account_id, dest_account_id, budget_limit, budget_used, budget_held,
+			reserved_amount, total_allocated, transactions_moved, schedules_merged,
+			schedules_retired, reason, transferred_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULLIF($11, ''), NULLIF($12, ''))
+		RETURNING id, created_at`,
+		source, dest, transfer.BudgetLimit, transfer.BudgetUsed, transfer.BudgetHeld,
+		transfer.ReservedAmount, transfer.TotalAllocated, transfer.TransactionsMoved,
+		transfer.SchedulesMerged, transfer.SchedulesRetired, transfer.Reason, transfer.TransferredBy,
+	).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return api.NewDatabaseError("record account transfer", err)
+	}
+	q.db.accountChanged(tx, source)
+	q.db.accountChanged(tx, dest)
+
+	return nil
+}
+
+// execCount runs a statement in the transaction and returns how many rows it affected
+func execCount(ctx context.Context, tx *sql.Tx, operation, query string, args ...interface{}) (int64, error) {
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, api.NewDatabaseError(operation, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, api.NewDatabaseError("get affected rows", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,247 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// ComponentUsage is the total charged to one cost component
+type ComponentUsage struct {
+	Component string
+	Amount    float64
+	JobCount  int64
+}
+
+// ChargeTotals is the total charged and the number of jobs charged
+type ChargeTotals struct {
+	Amount   float64
+	JobCount int64
+}
+
+// DailyUsage is the total charged on one local calendar day
+type DailyUsage struct {
+	Date     time.Time // midnight UTC of the local date
+	Amount   float64
+	JobCount int64
+}
+
+// UsageQueries provides read-only aggregations over charged spend
+type UsageQueries struct {
+	db *DB
+}
+
+// NewUsageQueries creates a new UsageQueries instance
+func NewUsageQueries(db *DB) *UsageQueries {
+	return &UsageQueries{db: db}
+}
+
+// GetCostComponents returns the cost breakdown recorded on a transaction, or nil if it has none
+func (q *UsageQueries) GetCostComponents(ctx context.Context, transactionID string) (map[string]float64, error) {
+	rows, err := q.db.QueryContext(ctx,
+		`SELECT component, amount FROM transaction_cost_components WHERE transaction_id = $1`, transactionID)
+	if err != nil {
+		return nil, api.NewDatabaseError("get cost components", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var breakdown map[string]float64
+	for rows.Next() {
+		var component string
+		var amount float64
+		if err := rows.Scan(&component, &amount); err != nil {
+			return nil, api.NewDatabaseError("scan cost component", err)
+		}
+		if breakdown == nil {
+			breakdown = make(map[string]float64)
+		}
+		breakdown[component] = amount
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate cost components", err)
+	}
+
+	return breakdown, nil
+}
+
+// ChargeTotals sums completed charges matching the report's account and date filters
+func (q *UsageQueries) ChargeTotals(ctx context.Context, req *api.UsageReportRequest) (*ChargeTotals, error) {
+	where, args := chargeFilter(req)
+	query := `
+		SELECT COALESCE(SUM(bt.amount), 0), COUNT(DISTINCT bt.job_id)
+		FROM budget_transactions bt
+		WHERE ` + where
+
+	var totals ChargeTotals
+	if err := q.db.QueryRowContext(ctx, query, args...).Scan(&totals.Amount, &totals.JobCount); err != nil {
+		return nil, api.NewDatabaseError("sum charges", err)
+	}
+	return &totals, nil
+}
+
+// UsageByComponent sums the cost breakdowns of completed charges matching the report's
+// account and date filters, largest component first
+func (q *UsageQueries) UsageByComponent(ctx context.Context, req *api.UsageReportRequest) ([]*ComponentUsage, error) {
+	where, args := chargeFilter(req)
+	query := `
+		SELECT tcc.component, SUM(tcc.amount), COUNT(DISTINCT bt.job_id)
+		FROM transaction_cost_components tcc
+		JOIN budget_transactions bt ON bt.transaction_id = tcc.transaction_id
+		WHERE ` + where + `
+		GROUP BY tcc.component
+		ORDER BY SUM(tcc.amount) DESC, tcc.component ASC`
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("sum cost components", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var usage []*ComponentUsage
+	for rows.Next() {
+		var item ComponentUsage
+		if err := rows.Scan(&item.Component, &item.Amount, &item.JobCount); err != nil {
+			return nil, api.NewDatabaseError("scan component usage", err)
+		}
+		usage = append(usage, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate component usage", err)
+	}
+
+	return usage, nil
+}
+
+// UsageByDay sums completed charges matching the report's account and date filters by
+// calendar day in the given time zone, earliest first
+func (q *UsageQueries) UsageByDay(ctx context.Context, req *api.UsageReportRequest, timezone string) ([]*DailyUsage, error) {
+	where, args := chargeFilter(req)
+	args = append(args, timezone)
+	day := fmt.Sprintf("(bt.created_at AT TIME ZONE $%d)::date", len(args))
+	query := `
+		SELECT ` + day + `, SUM(bt.amount), COUNT(DISTINCT bt.job_id)
+		FROM budget_transactions bt
+		WHERE ` + where + `
+		GROUP BY 1
+		ORDER BY 1`
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("sum daily charges", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var usage []*DailyUsage
+	for rows.Next() {
+		var item DailyUsage
+		if err := rows.Scan(&item.Date, &item.Amount, &item.JobCount); err != nil {
+			return nil, api.NewDatabaseError("scan daily usage", err)
+		}
+		item.Date = time.Date(item.Date.Year(), item.Date.Month(), item.Date.Day(), 0, 0, 0, 0, time.UTC)
+		usage = append(usage, &item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate daily usage", err)
+	}
+
+	return usage, nil
+}
+
+// chargeFilter builds the WHERE clause selecting completed charges for a usage report, with
+// the end date exclusive. Charges on descendants are included, so a parent account reports
+// its whole subtree.
+func chargeFilter(req *api.UsageReportRequest) (string, []interface{}) {
+	return usageFilter(req, "bt.created_at", "bt.type = 'charge'", "bt.status = 'completed'")
+}
+
+// usageFilter builds the WHERE clause limiting transactions to the report's account, with
+// its descendants, and to the report period by timeColumn, on top of the given conditions
+func usageFilter(req *api.UsageReportRequest, timeColumn string, conditions ...string) (string, []interface{}) {
+	var args []interface{}
+
+	if req.Account != "" {
+		args = append(args, req.Account)
+		conditions = append(conditions, fmt.Sprintf(
+			"bt.account_id IN (SELECT account_id FROM account_and_descendants((SELECT id FROM budget_accounts WHERE slurm_account = $%d)))",
+			len(args)))
+	}
+	if req.StartDate != nil {
+		args = append(args, *req.StartDate)
+		conditions = append(conditions, fmt.Sprintf("%s >= $%d", timeColumn, len(args)))
+	}
+	if req.EndDate != nil {
+		args = append(args, *req.EndDate)
+		conditions = append(conditions, fmt.Sprintf("%s < $%d", timeColumn, len(args)))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// ReconciliationLatency summarizes, per account, how long holds reconciled within the
+// report's account and period waited between being placed and being reconciled. Holds
+// that waited longer than sla are counted as over it; a zero sla counts none.
+func (q *UsageQueries) ReconciliationLatency(ctx context.Context, req *api.UsageReportRequest, sla time.Duration) ([]*api.AccountReconciliationLatency, error) {
+	where, args := usageFilter(req, "bt.reconciled_at", "bt.type = 'hold'", "bt.reconciled_at IS NOT NULL")
+	args = append(args, sla.Seconds())
+	query := fmt.Sprintf(`
+		SELECT ba.slurm_account, COUNT(*),
+		       AVG(EXTRACT(EPOCH FROM bt.reconciled_at - bt.created_at)) / 3600,
+		       MAX(EXTRACT(EPOCH FROM bt.reconciled_at - bt.created_at)) / 3600,
+		       COUNT(*) FILTER (WHERE $%d > 0 AND EXTRACT(EPOCH FROM bt.reconciled_at - bt.created_at) > $%d)
+		FROM budget_transactions bt
+		JOIN budget_accounts ba ON ba.id = bt.account_id
+		WHERE %s
+		GROUP BY ba.slurm_account
+		ORDER BY ba.slurm_account`, len(args), len(args), where)
+
+	rows, err := q.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, api.NewDatabaseError("summarize reconciliation latency", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Database row close failed - log for debugging
+			_ = err // Acknowledge error is handled
+		}
+	}()
+
+	var latencies []*api.AccountReconciliationLatency
+	for rows.Next() {
+		var latency api.AccountReconciliationLatency
+		if err := rows.Scan(&latency.Account, &latency.Reconciled, &latency.AvgHours, &latency.MaxHours, &latency.OverSLA); err != nil {
+			return nil, api.NewDatabaseError("scan reconciliation latency", err)
+		}
+		latencies = append(latencies, &latency)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, api.NewDatabaseError("iterate reconciliation latency", err)
+	}
+
+	return latencies, nil
+}
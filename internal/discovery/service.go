@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -18,6 +19,12 @@ import (
 type ServiceDiscovery struct {
 	httpClient *http.Client
 	services   map[string]*ServiceInfo
+	// targets are the candidate endpoints for each service, most preferred first
+	targets map[string][]string
+	// deadline bounds a whole discovery run
+	deadline time.Duration
+	// maxProbes is how many endpoints are probed at once
+	maxProbes int
 }
 
 // ServiceInfo represents information about a discovered service
@@ -31,53 +38,98 @@ type ServiceInfo struct {
 	HealthStatus string    `json:"health_status"`
 }
 
+const (
+	// defaultDiscoveryDeadline bounds a discovery run when the caller's context allows longer
+	defaultDiscoveryDeadline = 10 * time.Second
+
+	// defaultMaxProbes is how many endpoints are probed at once; enough for every default
+	// candidate, so an endpoint that hangs never queues a healthy one behind it
+	defaultMaxProbes = 9
+)
+
 // NewServiceDiscovery creates a new service discovery instance
 func NewServiceDiscovery() *ServiceDiscovery {
 	return &ServiceDiscovery{
 		httpClient: &http.Client{Timeout: 5 * time.Second},
 		services:   make(map[string]*ServiceInfo),
+		// Common service discovery endpoints and ports
+		targets: map[string][]string{
+			"advisor": {
+				"http://localhost:8081",
+				"http://advisor:8081",
+				"http://aws-slurm-burst-advisor:8081",
+			},
+			"asbx": {
+				"http://localhost:8082",
+				"http://asbx:8082",
+				"http://aws-slurm-burst:8082",
+			},
+			"asba": {
+				"http://localhost:8083",
+				"http://asba:8083",
+				"http://academic-slurm-burst:8083",
+			},
+		},
+		deadline:  defaultDiscoveryDeadline,
+		maxProbes: defaultMaxProbes,
 	}
 }
 
-// DiscoverEcosystem auto-detects available companion tools in the ecosystem
+// DiscoverEcosystem auto-detects available companion tools in the ecosystem. Every
+// candidate endpoint of every service is probed concurrently, a few at a time, and the
+// run ends at the discovery deadline or the context's, whichever is sooner. Services not
+// found by then are reported unavailable; a service found at several endpoints is reported
+// at its most preferred one.
 func (sd *ServiceDiscovery) DiscoverEcosystem(ctx context.Context) map[string]*ServiceInfo {
 	log.Info().Msg("Starting ecosystem service discovery...")
 
-	// Common service discovery endpoints and ports
-	discoveryTargets := map[string][]string{
-		"advisor": {
-			"http://localhost:8081",
-			"http://advisor:8081",
-			"http://aws-slurm-burst-advisor:8081",
-		},
-		"asbx": {
-			"http://localhost:8082",
-			"http://asbx:8082",
-			"http://aws-slurm-burst:8082",
-		},
-		"asba": {
-			"http://localhost:8083",
-			"http://asba:8083",
-			"http://academic-slurm-burst:8083",
-		},
+	ctx, cancel := context.WithTimeout(ctx, sd.deadline)
+	defer cancel()
+
+	// found[service][i] is what the service's i-th candidate endpoint answered, if anything
+	found := make(map[string][]*ServiceInfo, len(sd.targets))
+	for serviceName, endpoints := range sd.targets {
+		found[serviceName] = make([]*ServiceInfo, len(endpoints))
 	}
 
-	for serviceName, endpoints := range discoveryTargets {
-		sd.discoverService(ctx, serviceName, endpoints)
+	slots := make(chan struct{}, max(sd.maxProbes, 1))
+	var wg sync.WaitGroup
+	for serviceName, endpoints := range sd.targets {
+		for i, endpoint := range endpoints {
+			wg.Add(1)
+			go func(serviceName, endpoint string, i int) {
+				defer wg.Done()
+				select {
+				case slots <- struct{}{}:
+					defer func() { <-slots }()
+				case <-ctx.Done():
+					return
+				}
+				// Each goroutine writes only its own slot
+				found[serviceName][i] = sd.probeEndpoint(ctx, serviceName, endpoint)
+			}(serviceName, endpoint, i)
+		}
+	}
+	wg.Wait()
+
+	for serviceName, endpoints := range sd.targets {
+		sd.recordDiscovery(serviceName, endpoints, found[serviceName])
 	}
 
 	return sd.services
 }
 
-// discoverService attempts to discover a specific service
-func (sd *ServiceDiscovery) discoverService(ctx context.Context, serviceName string, endpoints []string) {
-	for _, endpoint := range endpoints {
-		if sd.probeService(ctx, serviceName, endpoint) {
+// recordDiscovery records a service at its most preferred endpoint that answered, or as
+// not found at its first endpoint
+func (sd *ServiceDiscovery) recordDiscovery(serviceName string, endpoints []string, found []*ServiceInfo) {
+	for _, info := range found {
+		if info != nil {
+			sd.services[serviceName] = info
 			log.Info().
 				Str("service", serviceName).
-				Str("endpoint", endpoint).
+				Str("endpoint", info.Endpoint).
 				Msg("Ecosystem service discovered")
-			return // Found it, stop looking
+			return
 		}
 	}
 
@@ -93,8 +145,19 @@ func (sd *ServiceDiscovery) discoverService(ctx context.Context, serviceName str
 	log.Debug().Str("service", serviceName).Msg("Ecosystem service not available")
 }
 
-// probeService checks if a service is available at the given endpoint
+// probeService checks if a service is available at the given endpoint, recording it if so
 func (sd *ServiceDiscovery) probeService(ctx context.Context, serviceName, endpoint string) bool {
+	info := sd.probeEndpoint(ctx, serviceName, endpoint)
+	if info == nil {
+		return false
+	}
+	sd.services[serviceName] = info
+	return true
+}
+
+// probeEndpoint returns what a service at the given endpoint reports about itself, or nil
+// if none of its health checks answer
+func (sd *ServiceDiscovery) probeEndpoint(ctx context.Context, serviceName, endpoint string) *ServiceInfo {
 	// Try common health check endpoints
 	healthEndpoints := []string{
 		"/health",
@@ -113,17 +176,21 @@ func (sd *ServiceDiscovery) probeService(ctx context.Context, serviceName, endpo
 
 		resp, err := sd.httpClient.Do(req)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil // Out of time; the remaining paths would fail the same way
+			}
 			continue
 		}
 
 		if resp.StatusCode == http.StatusOK {
 			// Try to parse service information
+			var info *ServiceInfo
 			var serviceInfo map[string]interface{}
 			if err := json.NewDecoder(resp.Body).Decode(&serviceInfo); err == nil {
-				sd.parseServiceInfo(serviceName, endpoint, serviceInfo)
+				info = parseServiceInfo(serviceName, endpoint, serviceInfo)
 			} else {
 				// Basic service info if parsing fails
-				sd.services[serviceName] = &ServiceInfo{
+				info = &ServiceInfo{
 					Name:         serviceName,
 					Endpoint:     endpoint,
 					Available:    true,
@@ -136,7 +203,7 @@ func (sd *ServiceDiscovery) probeService(ctx context.Context, serviceName, endpo
 				// Log error but continue
 				_ = err
 			}
-			return true
+			return info
 		}
 
 		if err := resp.Body.Close(); err != nil {
@@ -145,11 +212,11 @@ func (sd *ServiceDiscovery) probeService(ctx context.Context, serviceName, endpo
 		}
 	}
 
-	return false
+	return nil
 }
 
 // parseServiceInfo extracts service information from health check response
-func (sd *ServiceDiscovery) parseServiceInfo(serviceName, endpoint string, info map[string]interface{}) {
+func parseServiceInfo(serviceName, endpoint string, info map[string]interface{}) *ServiceInfo {
 	serviceInfo := &ServiceInfo{
 		Name:         serviceName,
 		Endpoint:     endpoint,
@@ -181,7 +248,7 @@ func (sd *ServiceDiscovery) parseServiceInfo(serviceName, endpoint string, info
 		serviceInfo.Capabilities = []string{"decision_making", "resource_allocation", "burst_optimization"}
 	}
 
-	sd.services[serviceName] = serviceInfo
+	return serviceInfo
 }
 
 // GetService returns information about a specific service
@@ -0,0 +1,99 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// healthyServer answers every health check with a version
+func healthyServer(t *testing.T, version string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "ok", "version": version})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// hangingServer accepts connections but never answers until the request is abandoned
+func hangingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDiscoverEcosystem_PartialResultsWithinDeadline(t *testing.T) {
+	advisor := healthyServer(t, "2.1.0")
+	hanging := hangingServer(t)
+
+	sd := NewServiceDiscovery()
+	sd.deadline = 300 * time.Millisecond
+	sd.maxProbes = 9 // Probe every candidate at once, so the healthy one is never queued
+	sd.targets = map[string][]string{
+		// Found at its second candidate while the first hangs
+		"advisor": {hanging.URL, advisor.URL, hanging.URL},
+		// Every candidate hangs
+		"asbx": {hanging.URL, hanging.URL, hanging.URL},
+		"asba": {hanging.URL, hanging.URL, hanging.URL},
+	}
+
+	start := time.Now()
+	services := sd.DiscoverEcosystem(context.Background())
+	elapsed := time.Since(start)
+
+	// Sequential probing would spend the deadline on each hanging endpoint in turn
+	assert.Less(t, elapsed, time.Second)
+
+	require.Contains(t, services, "advisor")
+	assert.True(t, services["advisor"].Available)
+	assert.Equal(t, advisor.URL, services["advisor"].Endpoint)
+	assert.Equal(t, "2.1.0", services["advisor"].Version)
+
+	for _, name := range []string{"asbx", "asba"} {
+		require.Contains(t, services, name)
+		assert.False(t, services[name].Available, name)
+		assert.Equal(t, "not_found", services[name].HealthStatus, name)
+	}
+}
+
+func TestDiscoverEcosystem_PrefersEarlierCandidate(t *testing.T) {
+	first := healthyServer(t, "1.0.0")
+	second := healthyServer(t, "2.0.0")
+
+	sd := NewServiceDiscovery()
+	sd.targets = map[string][]string{"asbx": {first.URL, second.URL}}
+
+	services := sd.DiscoverEcosystem(context.Background())
+	require.Contains(t, services, "asbx")
+	assert.Equal(t, first.URL, services["asbx"].Endpoint)
+	assert.Equal(t, []string{"cost_reconciliation", "performance_data", "job_tracking"}, services["asbx"].Capabilities)
+}
+
+func TestDiscoverEcosystem_CallerDeadline(t *testing.T) {
+	hanging := hangingServer(t)
+
+	sd := NewServiceDiscovery()
+	sd.targets = map[string][]string{"asba": {hanging.URL}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	services := sd.DiscoverEcosystem(ctx)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.False(t, services["asba"].Available)
+}
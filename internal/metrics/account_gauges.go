@@ -0,0 +1,157 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// OtherAccount is the account label the accounts past the label limit are rolled into
+const OtherAccount = "other"
+
+// AccountGauge names one gauge of a set of account gauges
+type AccountGauge struct {
+	Name string
+	Help string
+}
+
+// AccountGauges is a set of gauges labelled by account, replaced together from a snapshot
+// of every account rather than updated as each request changes a balance. Past the label
+// limit, the accounts ranked lowest by the first gauge are summed into a single "other"
+// series, so the number of series stays bounded however many accounts there are. Every
+// gauge labels the same accounts.
+type AccountGauges struct {
+	gauges  []AccountGauge
+	limit   int // Accounts labelled individually; zero labels every account
+	dropped string
+
+	mu       sync.Mutex
+	accounts []string    // Labelled accounts, in order, then OtherAccount if any were rolled up
+	values   [][]float64 // Values of each gauge, by the index of the account
+	rolledUp int
+}
+
+// NewAccountGauges creates a set of account gauges labelling at most limit accounts
+// individually; a limit of zero labels every account. droppedName names the gauge that
+// counts the accounts rolled into "other".
+func NewAccountGauges(limit int, droppedName string, gauges ...AccountGauge) *AccountGauges {
+	if limit < 0 {
+		limit = 0
+	}
+	return &AccountGauges{gauges: gauges, limit: limit, dropped: droppedName}
+}
+
+// Update replaces the gauges' values with a snapshot: each account's values, one per gauge
+// in the order the gauges were given
+func (g *AccountGauges) Update(snapshot map[string][]float64) {
+	ranked := make([]string, 0, len(snapshot))
+	for account := range snapshot {
+		ranked = append(ranked, account)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := g.value(snapshot[ranked[i]], 0), g.value(snapshot[ranked[j]], 0)
+		if a != b {
+			return a > b
+		}
+		return ranked[i] < ranked[j]
+	})
+
+	labelled := ranked
+	var excess []string
+	if g.limit > 0 && len(ranked) > g.limit {
+		labelled, excess = nil, ranked[g.limit:]
+		// An account named like the roll-up is rolled up too, so no series is written twice
+		for _, account := range ranked[:g.limit] {
+			if account == OtherAccount {
+				excess = append(excess, account)
+				continue
+			}
+			labelled = append(labelled, account)
+		}
+	}
+	sort.Strings(labelled)
+
+	accounts := append([]string(nil), labelled...)
+	values := make([][]float64, 0, len(accounts)+1)
+	for _, account := range labelled {
+		row := make([]float64, len(g.gauges))
+		for i := range g.gauges {
+			row[i] = g.value(snapshot[account], i)
+		}
+		values = append(values, row)
+	}
+	if len(excess) > 0 {
+		other := make([]float64, len(g.gauges))
+		for _, account := range excess {
+			for i := range g.gauges {
+				other[i] += g.value(snapshot[account], i)
+			}
+		}
+		accounts = append(accounts, OtherAccount)
+		values = append(values, other)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.accounts, g.values, g.rolledUp = accounts, values, len(excess)
+}
+
+// value returns a snapshot row's value of gauge i, zero when the row is short
+func (g *AccountGauges) value(row []float64, i int) float64 {
+	if i < len(row) {
+		return row[i]
+	}
+	return 0
+}
+
+// Series returns how many account series each gauge has
+func (g *AccountGauges) Series() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.accounts)
+}
+
+// WriteTo writes the gauges in the Prometheus text exposition format
+func (g *AccountGauges) WriteTo(w io.Writer) (int64, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var written int64
+	write := func(format string, args ...interface{}) error {
+		n, err := fmt.Fprintf(w, format, args...)
+		written += int64(n)
+		return err
+	}
+
+	for i, gauge := range g.gauges {
+		if err := write("# HELP %s %s\n# TYPE %s gauge\n", gauge.Name, gauge.Help, gauge.Name); err != nil {
+			return written, err
+		}
+		for j, account := range g.accounts {
+			if err := write("%s{account=\"%s\"} %s\n", gauge.Name, escapeLabel(account),
+				strconv.FormatFloat(g.values[j][i], 'g', -1, 64)); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	if g.dropped != "" {
+		if err := write("# HELP %s Accounts rolled into the \"%s\" account series past the label limit.\n# TYPE %s gauge\n%s %d\n",
+			g.dropped, OtherAccount, g.dropped, g.dropped, g.rolledUp); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// escapeLabel escapes a label value for the Prometheus text exposition format
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAccountGauges(limit int) *AccountGauges {
+	return NewAccountGauges(limit, "asbb_test_accounts_rolled_up",
+		AccountGauge{Name: "asbb_test_used", Help: "Budget used."},
+		AccountGauge{Name: "asbb_test_limit", Help: "Budget limit."})
+}
+
+func TestAccountGauges(t *testing.T) {
+	g := newTestAccountGauges(2)
+	g.Update(map[string][]float64{
+		"proj001": {100, 1000},
+		"proj002": {5, 500},
+		"proj003": {50, 800},
+		"proj004": {1, 200},
+	})
+
+	var out strings.Builder
+	n, err := g.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(out.Len()), n)
+	assert.Equal(t, `# HELP asbb_test_used Budget used.
+# TYPE asbb_test_used gauge
+asbb_test_used{account="proj001"} 100
+asbb_test_used{account="proj003"} 50
+asbb_test_used{account="other"} 6
+# HELP asbb_test_limit Budget limit.
+# TYPE asbb_test_limit gauge
+asbb_test_limit{account="proj001"} 1000
+asbb_test_limit{account="proj003"} 800
+asbb_test_limit{account="other"} 700
+# HELP asbb_test_accounts_rolled_up Accounts rolled into the "other" account series past the label limit.
+# TYPE asbb_test_accounts_rolled_up gauge
+asbb_test_accounts_rolled_up 2
+`, out.String())
+}
+
+func TestAccountGauges_CardinalityBounded(t *testing.T) {
+	const limit = 50
+	g := newTestAccountGauges(limit)
+
+	// Each collection sees more accounts, as a busy cluster would
+	for _, accounts := range []int{10, 1000, 10000} {
+		snapshot := make(map[string][]float64, accounts)
+		var total float64
+		for i := 0; i < accounts; i++ {
+			snapshot[fmt.Sprintf("proj%05d", i)] = []float64{float64(i), 1}
+			total += float64(i)
+		}
+		g.Update(snapshot)
+
+		var out strings.Builder
+		_, err := g.WriteTo(&out)
+		require.NoError(t, err)
+
+		series := strings.Count(out.String(), "asbb_test_used{")
+		assert.LessOrEqual(t, series, limit+1, "%d accounts", accounts)
+		assert.Equal(t, series, g.Series())
+		assert.Equal(t, series, strings.Count(out.String(), "asbb_test_limit{"))
+
+		var sum float64
+		for _, line := range strings.Split(out.String(), "\n") {
+			if !strings.HasPrefix(line, "asbb_test_used{") {
+				continue
+			}
+			var value float64
+			_, err := fmt.Sscanf(line[strings.Index(line, "} ")+2:], "%g", &value)
+			require.NoError(t, err)
+			sum += value
+		}
+		assert.InDelta(t, total, sum, 0.001, "rolling up loses nothing")
+	}
+}
+
+func TestAccountGauges_NoLimit(t *testing.T) {
+	g := newTestAccountGauges(0)
+	snapshot := make(map[string][]float64)
+	for i := 0; i < 200; i++ {
+		snapshot[fmt.Sprintf("proj%03d", i)] = []float64{1, 1}
+	}
+	g.Update(snapshot)
+	assert.Equal(t, 200, g.Series())
+
+	var out strings.Builder
+	_, err := g.WriteTo(&out)
+	require.NoError(t, err)
+	assert.NotContains(t, out.String(), `account="other"`)
+	assert.Contains(t, out.String(), "asbb_test_accounts_rolled_up 0\n")
+}
+
+func TestAccountGauges_OtherAccountName(t *testing.T) {
+	g := newTestAccountGauges(2)
+	g.Update(map[string][]float64{
+		"other":   {100, 1},
+		"proj001": {50, 1},
+		"proj002": {10, 1},
+	})
+
+	var out strings.Builder
+	_, err := g.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(out.String(), `asbb_test_used{account="other"}`))
+	assert.Contains(t, out.String(), `asbb_test_used{account="other"} 110`)
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
@@ -0,0 +1,96 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+// Package metrics provides the in-process metrics the budget service exposes in the
+// Prometheus text exposition format
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Histogram counts observations into cumulative buckets, as a Prometheus histogram does
+type Histogram struct {
+	name    string
+	help    string
+	mu      sync.Mutex
+	bounds  []float64 // upper bounds, ascending
+	buckets []uint64  // observations at or below each bound, not cumulative
+	count   uint64
+	sum     float64
+}
+
+// NewHistogram creates a histogram with the given bucket upper bounds. An implicit +Inf
+// bucket catches everything above the largest bound.
+func NewHistogram(name, help string, bounds ...float64) *Histogram {
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+	return &Histogram{
+		name:    name,
+		help:    help,
+		bounds:  sorted,
+		buckets: make([]uint64, len(sorted)),
+	}
+}
+
+// Observe records one value
+func (h *Histogram) Observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += value
+	if i := sort.SearchFloat64s(h.bounds, value); i < len(h.bounds) {
+		h.buckets[i]++
+	}
+}
+
+// Count returns how many values have been observed
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Sum returns the total of the observed values
+func (h *Histogram) Sum() float64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.sum
+}
+
+// WriteTo writes the histogram in the Prometheus text exposition format
+func (h *Histogram) WriteTo(w io.Writer) (int64, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var written int64
+	write := func(format string, args ...interface{}) error {
+		n, err := fmt.Fprintf(w, format, args...)
+		written += int64(n)
+		return err
+	}
+
+	if err := write("# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return written, err
+	}
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.buckets[i]
+		if err := write("%s_bucket{le=\"%s\"} %d\n", h.name, strconv.FormatFloat(bound, 'g', -1, 64), cumulative); err != nil {
+			return written, err
+		}
+	}
+	if err := write("%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count); err != nil {
+		return written, err
+	}
+	if err := write("%s_sum %s\n%s_count %d\n", h.name, strconv.FormatFloat(h.sum, 'g', -1, 64), h.name, h.count); err != nil {
+		return written, err
+	}
+	return written, nil
+}
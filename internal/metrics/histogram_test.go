@@ -0,0 +1,52 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package metrics
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("asbb_test_seconds", "Test latency.", 60, 10, 3600)
+	for _, v := range []float64{5, 10, 30, 120, 7200} {
+		h.Observe(v)
+	}
+
+	assert.Equal(t, uint64(5), h.Count())
+	assert.InDelta(t, 7365.0, h.Sum(), 0.001)
+
+	var out strings.Builder
+	n, err := h.WriteTo(&out)
+	require.NoError(t, err)
+	assert.Equal(t, int64(out.Len()), n)
+	assert.Equal(t, `# HELP asbb_test_seconds Test latency.
+# TYPE asbb_test_seconds histogram
+asbb_test_seconds_bucket{le="10"} 2
+asbb_test_seconds_bucket{le="60"} 3
+asbb_test_seconds_bucket{le="3600"} 4
+asbb_test_seconds_bucket{le="+Inf"} 5
+asbb_test_seconds_sum 7365
+asbb_test_seconds_count 5
+`, out.String())
+}
+
+func TestHistogram_ConcurrentObserve(t *testing.T) {
+	h := NewHistogram("asbb_test_seconds", "Test latency.", 1)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.Observe(0.5)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, uint64(50), h.Count())
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/version"
+)
+
+// Webhook posts each notification as JSON to a URL, for a mail or chat relay to deliver to
+// its recipient
+type Webhook struct {
+	httpClient *http.Client
+	url        string
+}
+
+// defaultTimeout bounds each post when no timeout is configured
+const defaultTimeout = 10 * time.Second
+
+// NewWebhook creates a webhook notifier posting to url. Each post is given up after
+// timeout, or sooner when its context ends; zero uses a default.
+func NewWebhook(url string, timeout time.Duration) *Webhook {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Webhook{httpClient: &http.Client{Timeout: timeout}, url: url}
+}
+
+// Notify posts a notification; any status but 2xx is an error
+func (w *Webhook) Notify(ctx context.Context, notification *api.Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent())
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("notification request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			// HTTP response body close failed - acknowledge error
+			_ = err // Error is handled by acknowledging it
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
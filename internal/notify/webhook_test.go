@@ -0,0 +1,71 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestWebhook_Notify(t *testing.T) {
+	var received api.Notification
+	var contentType string
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(server.URL, time.Second)
+	notification := &api.Notification{
+		Recipient: "grants@example.edu",
+		Digest:    true,
+		Subject:   "Budget alert digest: 2 alerts on 1 accounts",
+		Items: []api.NotificationItem{
+			{AlertID: 1, Account: "physics", AlertType: "budget_threshold", Severity: "warning", Message: "80% used"},
+			{AlertID: 2, Account: "physics", AlertType: "reconciliation_sla", Severity: "warning", Message: "late"},
+		},
+	}
+
+	t.Run("the notification is posted as JSON", func(t *testing.T) {
+		require.NoError(t, webhook.Notify(context.Background(), notification))
+		assert.Equal(t, "application/json", contentType)
+		assert.Equal(t, "grants@example.edu", received.Recipient)
+		assert.True(t, received.Digest)
+		assert.Len(t, received.Items, 2)
+	})
+
+	t.Run("a failed delivery is an error", func(t *testing.T) {
+		status = http.StatusBadGateway
+		err := webhook.Notify(context.Background(), notification)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "502")
+	})
+}
+
+func TestWebhook_NotifyTimesOut(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	err := NewWebhook(server.URL, 50*time.Millisecond).Notify(context.Background(), &api.Notification{Recipient: "pi@example.edu"})
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second, "a relay that never answers is given up on")
+}
@@ -0,0 +1,162 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package slurm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// ParseEpilogEnv reads the finished job an epilog's SLURM environment describes, so it can
+// be reconciled like an accounting record. The job ID, account and start time are required.
+// The end time defaults to now, since the epilog runs as the job ends.
+//
+// Epilogs are not told the job state, so unless SLURM_JOB_STATE is set it is derived from
+// the exit code: COMPLETED for a zero exit code or none at all, and FAILED otherwise.
+// Resources follow slurmctld's epilog: SLURM_JOB_CPUS_PER_NODE gives the CPUs of each node,
+// and SLURM_JOB_GPUS and SLURM_MEM_PER_NODE the GPUs and memory of one node.
+func ParseEpilogEnv(env map[string]string, now time.Time) (*api.AccountingJob, error) {
+	get := func(names ...string) string {
+		for _, name := range names {
+			if value := strings.TrimSpace(env[name]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	job := &api.AccountingJob{
+		JobID:             get("SLURM_JOB_ID", "SLURM_JOBID"),
+		Account:           get("SLURM_JOB_ACCOUNT"),
+		Partition:         get("SLURM_JOB_PARTITION"),
+		User:              get("SLURM_JOB_USER"),
+		HoldTransactionID: holdTransactionID(get("SLURM_JOB_ADMIN_COMMENT"), get("SLURM_JOB_COMMENT")),
+	}
+	if job.JobID == "" {
+		return nil, api.NewValidationError("SLURM_JOB_ID", "is required")
+	}
+	if job.Account == "" {
+		return nil, api.NewValidationError("SLURM_JOB_ACCOUNT", "is required")
+	}
+
+	start, err := epochTime(get("SLURM_JOB_START_TIME"))
+	if err != nil || start == nil {
+		return nil, api.NewValidationError("SLURM_JOB_START_TIME", "must be the job's start time in Unix seconds")
+	}
+	end, err := epochTime(get("SLURM_JOB_END_TIME"))
+	if err != nil {
+		return nil, api.NewValidationError("SLURM_JOB_END_TIME", "must be the job's end time in Unix seconds")
+	}
+	if end == nil {
+		end = &now
+	}
+	job.Start, job.End = start, end
+	if elapsed := end.Sub(*start); elapsed > 0 {
+		job.ElapsedSeconds = int64(elapsed.Seconds())
+	}
+
+	if job.State, err = epilogJobState(get("SLURM_JOB_STATE"), get("SLURM_JOB_EXIT_CODE2"), get("SLURM_JOB_EXIT_CODE")); err != nil {
+		return nil, err
+	}
+
+	cpuNodes, cpus, err := parseCPUsPerNode(get("SLURM_JOB_CPUS_PER_NODE"))
+	if err != nil {
+		return nil, api.NewValidationError("SLURM_JOB_CPUS_PER_NODE", err.Error())
+	}
+	job.CPUs = cpus
+	job.Nodes = atoi(get("SLURM_JOB_NUM_NODES", "SLURM_NNODES"))
+	if job.Nodes < 1 {
+		job.Nodes = max(cpuNodes, 1)
+	}
+
+	if gpus := get("SLURM_JOB_GPUS"); gpus != "" {
+		job.GPUs = len(strings.Split(gpus, ",")) * job.Nodes
+	}
+	if mem := atoi(get("SLURM_MEM_PER_NODE")); mem > 0 {
+		job.Memory = fmt.Sprintf("%dM", mem*job.Nodes) // SLURM counts memory in megabytes
+	}
+
+	return job, nil
+}
+
+// epilogJobState returns the job state an epilog environment gives or implies
+func epilogJobState(state, exitCode2, exitCode string) (string, error) {
+	if state != "" {
+		return strings.Fields(strings.ToUpper(state))[0], nil
+	}
+
+	// SLURM_JOB_EXIT_CODE2 is "exit:signal"; SLURM_JOB_EXIT_CODE is the raw wait status
+	if exitCode2 != "" {
+		code, signal, _ := strings.Cut(exitCode2, ":")
+		c, err := strconv.Atoi(code)
+		if err != nil {
+			return "", api.NewValidationError("SLURM_JOB_EXIT_CODE2", "must be exit:signal, e.g. 0:0")
+		}
+		s := 0
+		if signal != "" {
+			if s, err = strconv.Atoi(signal); err != nil {
+				return "", api.NewValidationError("SLURM_JOB_EXIT_CODE2", "must be exit:signal, e.g. 0:0")
+			}
+		}
+		return exitState(c == 0 && s == 0), nil
+	}
+	if exitCode != "" {
+		status, err := strconv.Atoi(exitCode)
+		if err != nil {
+			return "", api.NewValidationError("SLURM_JOB_EXIT_CODE", "must be a number")
+		}
+		return exitState(status == 0), nil
+	}
+	return exitState(true), nil
+}
+
+func exitState(success bool) string {
+	if success {
+		return "COMPLETED"
+	}
+	return "FAILED"
+}
+
+// parseCPUsPerNode totals the nodes and CPUs in SLURM_JOB_CPUS_PER_NODE, such as
+// "16(x2),8" for two 16-CPU nodes and one 8-CPU node
+func parseCPUsPerNode(value string) (nodes, cpus int, err error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	for _, group := range strings.Split(value, ",") {
+		count, repeat := group, "1"
+		if before, after, ok := strings.Cut(group, "(x"); ok {
+			count, repeat = before, strings.TrimSuffix(after, ")")
+		}
+		c, err := strconv.Atoi(count)
+		if err != nil || c < 0 {
+			return 0, 0, fmt.Errorf("invalid CPUs per node %q", value)
+		}
+		r, err := strconv.Atoi(repeat)
+		if err != nil || r < 1 {
+			return 0, 0, fmt.Errorf("invalid CPUs per node %q", value)
+		}
+		nodes += r
+		cpus += c * r
+	}
+	return nodes, cpus, nil
+}
+
+// epochTime parses Unix seconds; an empty value is no time
+func epochTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return unixTime(seconds), nil
+}
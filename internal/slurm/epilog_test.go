@@ -0,0 +1,134 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package slurm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// epilogEnv is the environment slurmctld gives EpilogSlurmctld for a two-node GPU job
+func epilogEnv() map[string]string {
+	return map[string]string{
+		"SLURM_CLUSTER_NAME":      "burst",
+		"SLURM_JOB_ID":            "4201",
+		"SLURM_JOB_ACCOUNT":       "proj001",
+		"SLURM_JOB_PARTITION":     "aws-gpu",
+		"SLURM_JOB_USER":          "alice",
+		"SLURM_JOB_UID":           "1001",
+		"SLURM_JOB_NAME":          "train.sh",
+		"SLURM_JOB_NODELIST":      "aws-gpu-[001-002]",
+		"SLURM_JOB_CPUS_PER_NODE": "16(x2)",
+		"SLURM_JOB_GPUS":          "0,1",
+		"SLURM_MEM_PER_NODE":      "65536",
+		"SLURM_JOB_START_TIME":    "1757844000",
+		"SLURM_JOB_END_TIME":      "1757849400",
+		"SLURM_JOB_EXIT_CODE":     "0",
+		"SLURM_JOB_EXIT_CODE2":    "0:0",
+		"SLURM_JOB_DERIVED_EC":    "0",
+		"SLURM_JOB_COMMENT":       "asbb_txn=txn_1757844000000000001",
+		"SLURM_SCRIPT_CONTEXT":    "epilog_slurmctld",
+	}
+}
+
+func TestParseEpilogEnv(t *testing.T) {
+	now := time.Unix(1757850000, 0).UTC()
+
+	job, err := ParseEpilogEnv(epilogEnv(), now)
+	require.NoError(t, err)
+	assert.Equal(t, &api.AccountingJob{
+		JobID:             "4201",
+		Account:           "proj001",
+		Partition:         "aws-gpu",
+		User:              "alice",
+		State:             "COMPLETED",
+		ElapsedSeconds:    5400,
+		Nodes:             2,
+		CPUs:              32,
+		GPUs:              4,
+		Memory:            "131072M",
+		Start:             unixTime(1757844000),
+		End:               unixTime(1757849400),
+		HoldTransactionID: "txn_1757844000000000001",
+	}, job)
+
+	t.Run("failed job", func(t *testing.T) {
+		env := epilogEnv()
+		env["SLURM_JOB_EXIT_CODE2"] = "1:0"
+		job, err := ParseEpilogEnv(env, now)
+		require.NoError(t, err)
+		assert.Equal(t, "FAILED", job.State)
+	})
+
+	t.Run("killed by a signal", func(t *testing.T) {
+		env := epilogEnv()
+		delete(env, "SLURM_JOB_EXIT_CODE2")
+		env["SLURM_JOB_EXIT_CODE"] = "9"
+		job, err := ParseEpilogEnv(env, now)
+		require.NoError(t, err)
+		assert.Equal(t, "FAILED", job.State)
+	})
+
+	t.Run("explicit state wins", func(t *testing.T) {
+		env := epilogEnv()
+		env["SLURM_JOB_STATE"] = "timeout"
+		job, err := ParseEpilogEnv(env, now)
+		require.NoError(t, err)
+		assert.Equal(t, "TIMEOUT", job.State)
+	})
+
+	t.Run("minimal environment", func(t *testing.T) {
+		job, err := ParseEpilogEnv(map[string]string{
+			"SLURM_JOB_ID":         "4202",
+			"SLURM_JOB_ACCOUNT":    "proj001",
+			"SLURM_JOB_START_TIME": "1757849400",
+		}, now)
+		require.NoError(t, err)
+		assert.Equal(t, "COMPLETED", job.State)
+		assert.Equal(t, int64(600), job.ElapsedSeconds, "a job without an end time ends now")
+		assert.Equal(t, 1, job.Nodes)
+		assert.Empty(t, job.HoldTransactionID)
+	})
+
+	t.Run("invalid environments", func(t *testing.T) {
+		for name, mutate := range map[string]func(map[string]string){
+			"SLURM_JOB_ID":            func(env map[string]string) { delete(env, "SLURM_JOB_ID") },
+			"SLURM_JOB_ACCOUNT":       func(env map[string]string) { env["SLURM_JOB_ACCOUNT"] = " " },
+			"SLURM_JOB_START_TIME":    func(env map[string]string) { delete(env, "SLURM_JOB_START_TIME") },
+			"SLURM_JOB_END_TIME":      func(env map[string]string) { env["SLURM_JOB_END_TIME"] = "yesterday" },
+			"SLURM_JOB_EXIT_CODE2":    func(env map[string]string) { env["SLURM_JOB_EXIT_CODE2"] = "ok" },
+			"SLURM_JOB_CPUS_PER_NODE": func(env map[string]string) { env["SLURM_JOB_CPUS_PER_NODE"] = "16(x0)" },
+		} {
+			t.Run(name, func(t *testing.T) {
+				env := epilogEnv()
+				mutate(env)
+				_, err := ParseEpilogEnv(env, now)
+				budgetErr, ok := api.AsBudgetError(err)
+				require.True(t, ok)
+				assert.Equal(t, name, budgetErr.Field)
+			})
+		}
+	})
+}
+
+func TestParseCPUsPerNode(t *testing.T) {
+	nodes, cpus, err := parseCPUsPerNode("16(x2),8")
+	require.NoError(t, err)
+	assert.Equal(t, 3, nodes)
+	assert.Equal(t, 40, cpus)
+
+	nodes, cpus, err = parseCPUsPerNode("")
+	require.NoError(t, err)
+	assert.Zero(t, nodes)
+	assert.Zero(t, cpus)
+
+	_, _, err = parseCPUsPerNode("16(x")
+	assert.Error(t, err)
+}
@@ -0,0 +1,335 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+// Package slurm reads job accounting records from SLURM's sacct so completed jobs can be
+// reconciled on sites that do not run ASBX, and the resources batch scripts request so
+// jobs can be checked before they are submitted.
+package slurm
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const (
+	// FormatJSON is sacct's --json output
+	FormatJSON = "json"
+	// FormatParsable2 is sacct's --parsable2 output with a header row
+	FormatParsable2 = "parsable2"
+
+	// sacctTimeLayout is how sacct prints times in parsable output
+	sacctTimeLayout = "2006-01-02T15:04:05"
+)
+
+// ParsableFields is the --format column list ParseParsable2 expects
+var ParsableFields = []string{
+	"JobID", "Account", "Partition", "User", "State", "Elapsed", "NNodes",
+	"AllocCPUS", "AllocTRES", "Start", "End", "AdminComment", "Comment",
+}
+
+// holdTokenPattern finds the hold transaction ID the job submit plugin records in a job's
+// admin comment or comment
+var holdTokenPattern = regexp.MustCompile(`\basbb_txn=([A-Za-z0-9_-]+)`)
+
+// SacctArgs returns the sacct arguments listing allocations that started since a time,
+// across all users, in the given output format
+func SacctArgs(since time.Time, format string) ([]string, error) {
+	args := []string{"--allusers", "--allocations", "--starttime=" + since.Format(sacctTimeLayout)}
+	switch format {
+	case FormatJSON:
+		return append(args, "--json"), nil
+	case FormatParsable2:
+		return append(args, "--parsable2", "--format="+strings.Join(ParsableFields, ",")), nil
+	default:
+		return nil, fmt.Errorf("unsupported sacct format %q", format)
+	}
+}
+
+// RunSacct runs sacct for allocations started since a time and parses its output
+func RunSacct(ctx context.Context, since time.Time, format string) ([]*api.AccountingJob, error) {
+	args, err := SacctArgs(since, format)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := exec.CommandContext(ctx, "sacct", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run sacct: %w", err)
+	}
+	return Parse(strings.NewReader(string(output)), format)
+}
+
+// Parse reads sacct output in the given format
+func Parse(r io.Reader, format string) ([]*api.AccountingJob, error) {
+	switch format {
+	case FormatJSON:
+		return ParseJSON(r)
+	case FormatParsable2:
+		return ParseParsable2(r)
+	default:
+		return nil, fmt.Errorf("unsupported sacct format %q", format)
+	}
+}
+
+// sacctJSON is the subset of sacct --json output that accounting needs. Field names are
+// those of Slurm 21.08 and later.
+type sacctJSON struct {
+	Jobs []struct {
+		JobID     int64  `json:"job_id"`
+		Account   string `json:"account"`
+		Partition string `json:"partition"`
+		User      string `json:"user"`
+		State     struct {
+			// A string before Slurm 23.02, a list of flags from then on
+			Current json.RawMessage `json:"current"`
+		} `json:"state"`
+		Time struct {
+			Elapsed int64 `json:"elapsed"`
+			Start   int64 `json:"start"`
+			End     int64 `json:"end"`
+		} `json:"time"`
+		AllocationNodes int64 `json:"allocation_nodes"`
+		TRES            struct {
+			Allocated []struct {
+				Type  string `json:"type"`
+				Name  string `json:"name"`
+				Count int64  `json:"count"`
+			} `json:"allocated"`
+		} `json:"tres"`
+		Comment struct {
+			Administrator string `json:"administrator"`
+			Job           string `json:"job"`
+		} `json:"comment"`
+	} `json:"jobs"`
+}
+
+// ParseJSON reads sacct --json output
+func ParseJSON(r io.Reader) ([]*api.AccountingJob, error) {
+	var doc sacctJSON
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid sacct JSON: %w", err)
+	}
+
+	jobs := make([]*api.AccountingJob, 0, len(doc.Jobs))
+	for _, record := range doc.Jobs {
+		state, err := jsonState(record.State.Current)
+		if err != nil {
+			return nil, fmt.Errorf("job %d: %w", record.JobID, err)
+		}
+
+		job := &api.AccountingJob{
+			JobID:          strconv.FormatInt(record.JobID, 10),
+			Account:        record.Account,
+			Partition:      record.Partition,
+			User:           record.User,
+			State:          state,
+			ElapsedSeconds: record.Time.Elapsed,
+			Nodes:          int(record.AllocationNodes),
+			Start:          unixTime(record.Time.Start),
+			End:            unixTime(record.Time.End),
+		}
+		for _, tres := range record.TRES.Allocated {
+			switch {
+			case tres.Type == "cpu":
+				job.CPUs = int(tres.Count)
+			case tres.Type == "node":
+				job.Nodes = int(tres.Count)
+			case tres.Type == "mem":
+				job.Memory = fmt.Sprintf("%dM", tres.Count) // sacct counts memory in megabytes
+			case tres.Type == "gres" && tres.Name == "gpu":
+				job.GPUs = int(tres.Count)
+			}
+		}
+		job.HoldTransactionID = holdTransactionID(record.Comment.Administrator, record.Comment.Job)
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+// ParseParsable2 reads sacct --parsable2 output. The header row names the columns, so they
+// may come in any order; job steps such as 1234.batch are skipped.
+func ParseParsable2(r io.Reader) ([]*api.AccountingJob, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read sacct output: %w", err)
+		}
+		return nil, fmt.Errorf("sacct output is empty")
+	}
+
+	columns := make(map[string]int)
+	for i, name := range strings.Split(scanner.Text(), "|") {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"JobID", "Account", "State", "Elapsed"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("sacct output is missing the %s column", required)
+		}
+	}
+
+	var jobs []*api.AccountingJob
+	line := 1
+	for scanner.Scan() {
+		line++
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		fields := strings.Split(scanner.Text(), "|")
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+
+		if strings.Contains(field("JobID"), ".") {
+			continue
+		}
+
+		elapsed, err := ParseElapsed(field("Elapsed"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		job := &api.AccountingJob{
+			JobID:             field("JobID"),
+			Account:           field("Account"),
+			Partition:         field("Partition"),
+			User:              field("User"),
+			State:             strings.Fields(field("State") + " ")[0], // "CANCELLED by 1000"
+			ElapsedSeconds:    int64(elapsed.Seconds()),
+			Nodes:             atoi(field("NNodes")),
+			CPUs:              atoi(field("AllocCPUS")),
+			Start:             parsableTime(field("Start")),
+			End:               parsableTime(field("End")),
+			HoldTransactionID: holdTransactionID(field("AdminComment"), field("Comment")),
+		}
+		applyTRES(job, field("AllocTRES"))
+		jobs = append(jobs, job)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read sacct output: %w", err)
+	}
+	return jobs, nil
+}
+
+// ParseElapsed parses sacct's [DD-[HH:]]MM:SS elapsed time
+func ParseElapsed(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	days := 0
+	if before, after, ok := strings.Cut(value, "-"); ok {
+		d, err := strconv.Atoi(before)
+		if err != nil {
+			return 0, fmt.Errorf("invalid elapsed time %q", value)
+		}
+		days, value = d, after
+	}
+
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid elapsed time %q", value)
+	}
+	seconds := 0.0
+	for _, part := range parts {
+		n, err := strconv.ParseFloat(part, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid elapsed time %q", value)
+		}
+		seconds = seconds*60 + n
+	}
+
+	return time.Duration(days)*24*time.Hour + time.Duration(seconds*float64(time.Second)), nil
+}
+
+// applyTRES fills node, CPU, GPU and memory counts from an AllocTRES value such as
+// "billing=16,cpu=16,gres/gpu=4,mem=64G,node=2"
+func applyTRES(job *api.AccountingJob, tres string) {
+	for _, item := range strings.Split(tres, ",") {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "cpu":
+			job.CPUs = atoi(value)
+		case "node":
+			job.Nodes = atoi(value)
+		case "mem":
+			job.Memory = value
+		case "gres/gpu":
+			job.GPUs = atoi(value)
+		}
+	}
+}
+
+// jsonState reads a job state that is either a string or a list of state flags, the first
+// of which is the base state
+func jsonState(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var state string
+	if err := json.Unmarshal(raw, &state); err == nil {
+		return state, nil
+	}
+	var flags []string
+	if err := json.Unmarshal(raw, &flags); err != nil {
+		return "", fmt.Errorf("invalid job state %s", raw)
+	}
+	if len(flags) == 0 {
+		return "", nil
+	}
+	return flags[0], nil
+}
+
+// holdTransactionID returns the first hold transaction token found in the given comments
+func holdTransactionID(comments ...string) string {
+	for _, comment := range comments {
+		if match := holdTokenPattern.FindStringSubmatch(comment); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+func unixTime(seconds int64) *time.Time {
+	if seconds <= 0 {
+		return nil
+	}
+	t := time.Unix(seconds, 0).UTC()
+	return &t
+}
+
+func parsableTime(value string) *time.Time {
+	t, err := time.ParseInLocation(sacctTimeLayout, value, time.Local)
+	if err != nil {
+		return nil // "Unknown", "None" and empty values
+	}
+	return &t
+}
+
+func atoi(value string) int {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package slurm
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSON(t *testing.T) {
+	f, err := os.Open("testdata/sacct.json")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	jobs, err := ParseJSON(f)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3)
+
+	gpuJob := jobs[0]
+	assert.Equal(t, "4201", gpuJob.JobID)
+	assert.Equal(t, "proj001", gpuJob.Account)
+	assert.Equal(t, "aws-gpu", gpuJob.Partition)
+	assert.Equal(t, "alice", gpuJob.User)
+	assert.Equal(t, "COMPLETED", gpuJob.State)
+	assert.Equal(t, int64(5400), gpuJob.ElapsedSeconds)
+	assert.Equal(t, 2, gpuJob.Nodes)
+	assert.Equal(t, 32, gpuJob.CPUs)
+	assert.Equal(t, 4, gpuJob.GPUs)
+	assert.Equal(t, "131072M", gpuJob.Memory)
+	assert.Equal(t, "txn_1757844000000000001", gpuJob.HoldTransactionID)
+	require.NotNil(t, gpuJob.Start)
+	assert.Equal(t, time.Unix(1757844000, 0).UTC(), *gpuJob.Start)
+
+	cancelled := jobs[1]
+	assert.Equal(t, "CANCELLED", cancelled.State, "state flag lists use the first flag")
+	assert.Zero(t, cancelled.ElapsedSeconds)
+	assert.Nil(t, cancelled.Start)
+	assert.Empty(t, cancelled.HoldTransactionID)
+
+	assert.Equal(t, "RUNNING", jobs[2].State)
+}
+
+func TestParseParsable2(t *testing.T) {
+	f, err := os.Open("testdata/sacct_parsable2.txt")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	jobs, err := ParseParsable2(f)
+	require.NoError(t, err)
+	require.Len(t, jobs, 3, "job steps are skipped")
+
+	gpuJob := jobs[0]
+	assert.Equal(t, "4201", gpuJob.JobID)
+	assert.Equal(t, "COMPLETED", gpuJob.State)
+	assert.Equal(t, int64(5400), gpuJob.ElapsedSeconds)
+	assert.Equal(t, 2, gpuJob.Nodes)
+	assert.Equal(t, 32, gpuJob.CPUs)
+	assert.Equal(t, 4, gpuJob.GPUs)
+	assert.Equal(t, "128G", gpuJob.Memory)
+	assert.Equal(t, "txn_1757844000000000001", gpuJob.HoldTransactionID)
+	require.NotNil(t, gpuJob.End)
+
+	cancelled := jobs[1]
+	assert.Equal(t, "CANCELLED", cancelled.State)
+	assert.Nil(t, cancelled.Start, "Unknown start times are left unset")
+	assert.Equal(t, "txn_1757844000000000002", cancelled.HoldTransactionID, "the job comment is searched too")
+
+	timedOut := jobs[2]
+	assert.Equal(t, "TIMEOUT", timedOut.State)
+	assert.Equal(t, int64(2*24*3600+12), timedOut.ElapsedSeconds)
+}
+
+func TestParseParsable2Errors(t *testing.T) {
+	_, err := ParseParsable2(strings.NewReader(""))
+	assert.Error(t, err)
+
+	_, err = ParseParsable2(strings.NewReader("JobID|Account|State\n"))
+	assert.ErrorContains(t, err, "Elapsed")
+
+	_, err = ParseParsable2(strings.NewReader("JobID|Account|State|Elapsed\n1|proj|COMPLETED|soon\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestParseElapsed(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"", 0, false},
+		{"00:00:00", 0, false},
+		{"05:30", 5*time.Minute + 30*time.Second, false},
+		{"01:30:00", 90 * time.Minute, false},
+		{"1-02:00:00", 26 * time.Hour, false},
+		{"12:34.567", 12*time.Minute + 34567*time.Millisecond, false},
+		{"later", 0, true},
+		{"x-01:00:00", 0, true},
+		{"1:2:3:4", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseElapsed(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestSacctArgs(t *testing.T) {
+	since := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	args, err := SacctArgs(since, FormatJSON)
+	require.NoError(t, err)
+	assert.Contains(t, args, "--json")
+	assert.Contains(t, args, "--starttime=2025-09-01T00:00:00")
+
+	args, err = SacctArgs(since, FormatParsable2)
+	require.NoError(t, err)
+	assert.Contains(t, args, "--parsable2")
+	assert.Contains(t, args, "--format="+strings.Join(ParsableFields, ","))
+
+	_, err = SacctArgs(since, "xml")
+	assert.Error(t, err)
+}
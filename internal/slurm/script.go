@@ -0,0 +1,320 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package slurm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// directivePrefix starts the lines of a batch script sbatch reads options from
+const directivePrefix = "#SBATCH"
+
+// scriptOptions maps the short and long forms of the sbatch options that decide what a
+// job costs or which budget it charges to a resource name
+var scriptOptions = map[string]string{
+	"A":               "account",
+	"account":         "account",
+	"p":               "partition",
+	"partition":       "partition",
+	"N":               "nodes",
+	"nodes":           "nodes",
+	"n":               "ntasks",
+	"ntasks":          "ntasks",
+	"ntasks-per-node": "ntasks-per-node",
+	"c":               "cpus-per-task",
+	"cpus-per-task":   "cpus-per-task",
+	"mem":             "mem",
+	"mem-per-cpu":     "mem-per-cpu",
+	"G":               "gpus",
+	"gpus":            "gpus",
+	"gpus-per-node":   "gpus-per-node",
+	"gres":            "gres",
+	"t":               "time",
+	"time":            "time",
+}
+
+// ParseJobScript reads the resources a batch script requests from its #SBATCH directives,
+// as sbatch would: directives are read until the first command, and a later directive
+// overrides an earlier one. CPUs are per node and GPUs and memory are for the whole job,
+// as a budget check counts them; nodes default to one. Options that do not bear on the
+// job's cost are ignored. Directives whose values cannot be read are returned as errors,
+// and the resource they set is left at its default.
+func ParseJobScript(script string) (*api.JobResources, []api.JobValidationIssue) {
+	options, issues := scriptDirectives(script)
+
+	resources := &api.JobResources{
+		Account:   options["account"],
+		Partition: options["partition"],
+		Nodes:     1,
+	}
+	count := func(option, field string) int {
+		value, ok := options[option]
+		if !ok {
+			return 0
+		}
+		n, err := parseCount(value)
+		if err != nil || n < 1 {
+			issues = append(issues, scriptIssue(field, "--%s must be a positive number, not %q", option, value))
+			return 0
+		}
+		return n
+	}
+
+	if nodes := count("nodes", "nodes"); nodes > 0 {
+		resources.Nodes = nodes
+	}
+	cpusPerTask := max(count("cpus-per-task", "cpus"), 1)
+	switch tasksPerNode, tasks := count("ntasks-per-node", "cpus"), count("ntasks", "cpus"); {
+	case tasksPerNode > 0:
+		resources.CPUs = tasksPerNode * cpusPerTask
+	case tasks > 0:
+		resources.CPUs = ceilDiv(tasks*cpusPerTask, resources.Nodes)
+	default:
+		resources.CPUs = cpusPerTask
+	}
+
+	if gpus, ok := options["gpus"]; ok {
+		n, err := gpuCount(gpus)
+		if err != nil {
+			issues = append(issues, scriptIssue("gpus", "--gpus must be a GPU count, optionally typed, not %q", gpus))
+		}
+		resources.GPUs = n
+	} else if gpus, ok := options["gpus-per-node"]; ok {
+		n, err := gpuCount(gpus)
+		if err != nil {
+			issues = append(issues, scriptIssue("gpus", "--gpus-per-node must be a GPU count, optionally typed, not %q", gpus))
+		}
+		resources.GPUs = n * resources.Nodes
+	} else if gres, ok := options["gres"]; ok {
+		n, err := gresGPUs(gres)
+		if err != nil {
+			issues = append(issues, scriptIssue("gpus", "--gres must list generic resources such as gpu:2, not %q", gres))
+		}
+		resources.GPUs = n * resources.Nodes
+	}
+
+	if mem, ok := options["mem"]; ok {
+		mb, err := parseMemory(mem)
+		if err != nil {
+			issues = append(issues, scriptIssue("memory", "--mem must be a size such as 16G, not %q", mem))
+		} else if mb > 0 {
+			resources.Memory = fmt.Sprintf("%dM", mb*int64(resources.Nodes))
+		}
+	} else if mem, ok := options["mem-per-cpu"]; ok {
+		mb, err := parseMemory(mem)
+		if err != nil {
+			issues = append(issues, scriptIssue("memory", "--mem-per-cpu must be a size such as 4G, not %q", mem))
+		} else if mb > 0 {
+			resources.Memory = fmt.Sprintf("%dM", mb*int64(resources.CPUs*resources.Nodes))
+		}
+	}
+
+	if limit, ok := options["time"]; ok {
+		wallTime, err := parseTimeLimit(limit)
+		if err != nil {
+			issues = append(issues, scriptIssue("wall_time", "--time must be a time limit such as 2:00:00 or 1-12:00:00, not %q", limit))
+		}
+		resources.WallTime = wallTime
+	}
+
+	return resources, issues
+}
+
+// scriptDirectives returns the values of the cost-bearing options a script's directives
+// set, keyed by long option name, with errors for directives that cannot be read
+func scriptDirectives(script string) (map[string]string, []api.JobValidationIssue) {
+	options := make(map[string]string)
+	var issues []api.JobValidationIssue
+
+	for _, line := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !strings.HasPrefix(line, "#") {
+			break // sbatch stops reading directives at the first command
+		}
+		rest, ok := strings.CutPrefix(line, directivePrefix)
+		if !ok || (rest != "" && rest[0] != ' ' && rest[0] != '\t') {
+			continue
+		}
+		if comment := strings.Index(rest, " #"); comment >= 0 {
+			rest = rest[:comment]
+		}
+
+		args := strings.Fields(rest)
+		for i := 0; i < len(args); i++ {
+			name, value, hasValue := splitOption(args[i])
+			if name == "" {
+				issues = append(issues, scriptIssue("script", "unexpected %q in directive %q", args[i], line))
+				continue
+			}
+			if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+				value, hasValue = args[i], true
+			}
+			resource, known := scriptOptions[name]
+			if !known {
+				continue
+			}
+			if !hasValue {
+				issues = append(issues, scriptIssue("script", "directive %q is missing a value", line))
+				continue
+			}
+			options[resource] = value
+		}
+	}
+	return options, issues
+}
+
+// splitOption splits a command-line option into its name and any value attached to it, as
+// in --nodes=2 and -N2. It returns an empty name for an argument that is not an option.
+func splitOption(arg string) (name, value string, hasValue bool) {
+	if long, ok := strings.CutPrefix(arg, "--"); ok {
+		name, value, hasValue = strings.Cut(long, "=")
+		return name, value, hasValue
+	}
+	if short, ok := strings.CutPrefix(arg, "-"); ok && short != "" {
+		name, value = short[:1], strings.TrimPrefix(short[1:], "=")
+		return name, value, value != ""
+	}
+	return "", "", false
+}
+
+// scriptIssue returns an error found reading a job script's directives
+func scriptIssue(field, format string, args ...any) api.JobValidationIssue {
+	return api.JobValidationIssue{
+		Severity: api.ValidationSeverityError,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// parseCount parses a count option, taking the minimum of a range such as 2-4 since
+// that is all a job is guaranteed to be given
+func parseCount(value string) (int, error) {
+	minimum, _, _ := strings.Cut(value, "-")
+	return strconv.Atoi(minimum)
+}
+
+// gpuCount parses a GPU count, optionally prefixed with a GPU type as in a100:2
+func gpuCount(value string) (int, error) {
+	n, err := strconv.Atoi(value[strings.LastIndex(value, ":")+1:])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid GPU count %q", value)
+	}
+	return n, nil
+}
+
+// gresGPUs returns the GPUs per node a --gres list requests, such as gpu:2 or
+// gpu:a100:2,license:1. A GPU with no count is one GPU.
+func gresGPUs(value string) (int, error) {
+	gpus := 0
+	for _, gres := range strings.Split(value, ",") {
+		name, spec, _ := strings.Cut(gres, ":")
+		if name == "" {
+			return 0, fmt.Errorf("invalid generic resource %q", value)
+		}
+		if name != "gpu" {
+			continue
+		}
+		count := spec[strings.LastIndex(spec, ":")+1:]
+		if n, err := strconv.Atoi(count); err == nil && n >= 0 {
+			gpus += n
+		} else if spec == "" || !strings.Contains(spec, ":") {
+			gpus++ // gpu and gpu:a100 are one GPU
+		} else {
+			return 0, fmt.Errorf("invalid GPU count in %q", value)
+		}
+	}
+	return gpus, nil
+}
+
+// parseMemory returns a memory size in megabytes. A bare number is megabytes, as sbatch
+// reads it; K, M, G and T suffixes scale it.
+func parseMemory(value string) (int64, error) {
+	number, scale := strings.ToUpper(value), 1.0
+	if number != "" {
+		switch number[len(number)-1] {
+		case 'K':
+			scale = 1.0 / 1024
+		case 'G':
+			scale = 1024
+		case 'T':
+			scale = 1024 * 1024
+		}
+		number = strings.TrimRight(number, "KMGT")
+	}
+	size, err := strconv.ParseFloat(number, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid memory size %q", value)
+	}
+	return int64(size*scale + 0.5), nil
+}
+
+// parseTimeLimit converts a SLURM time limit to HH:MM:SS, with hours beyond a day.
+// SLURM accepts minutes, minutes:seconds, hours:minutes:seconds, days-hours,
+// days-hours:minutes and days-hours:minutes:seconds. An unlimited job cannot be priced,
+// so UNLIMITED and INFINITE are errors.
+func parseTimeLimit(value string) (string, error) {
+	days, clock := 0, value
+	d, rest, hasDays := strings.Cut(value, "-")
+	if hasDays {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid time limit %q", value)
+		}
+		days, clock = n, rest
+	}
+
+	parts := strings.Split(clock, ":")
+	fields := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("invalid time limit %q", value)
+		}
+		fields[i] = n
+	}
+
+	var hours, minutes, seconds int
+	switch {
+	case hasDays:
+		// days-hours[:minutes[:seconds]]
+		if len(fields) > 3 {
+			return "", fmt.Errorf("invalid time limit %q", value)
+		}
+		hours = fields[0]
+		if len(fields) > 1 {
+			minutes = fields[1]
+		}
+		if len(fields) > 2 {
+			seconds = fields[2]
+		}
+	case len(fields) == 1:
+		minutes = fields[0]
+	case len(fields) == 2:
+		minutes, seconds = fields[0], fields[1]
+	case len(fields) == 3:
+		hours, minutes, seconds = fields[0], fields[1], fields[2]
+	default:
+		return "", fmt.Errorf("invalid time limit %q", value)
+	}
+
+	total := ((days*24+hours)*60+minutes)*60 + seconds
+	if total == 0 {
+		return "", fmt.Errorf("invalid time limit %q", value)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60), nil
+}
+
+// ceilDiv divides a by b, rounding up
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package slurm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+const trainScript = `#!/bin/bash
+#SBATCH --job-name=train
+#SBATCH --account=proj001
+#SBATCH -p aws-gpu
+#SBATCH --nodes=2 --ntasks-per-node=4   # one task per GPU
+#SBATCH -c 8
+#SBATCH --gres=gpu:a100:4
+#SBATCH --mem=64G
+#SBATCH --time=1-12:30:00
+# #SBATCH --partition=debug is commented out
+
+module load cuda
+srun python train.py
+#SBATCH --account=ignored-after-the-first-command
+`
+
+func TestParseJobScript(t *testing.T) {
+	resources, issues := ParseJobScript(trainScript)
+	assert.Empty(t, issues)
+	assert.Equal(t, &api.JobResources{
+		Account:   "proj001",
+		Partition: "aws-gpu",
+		Nodes:     2,
+		CPUs:      32,
+		GPUs:      8,
+		Memory:    "131072M",
+		WallTime:  "36:30:00",
+	}, resources)
+
+	t.Run("defaults", func(t *testing.T) {
+		resources, issues := ParseJobScript("#!/bin/bash\n#SBATCH --time=30\nhostname\n")
+		assert.Empty(t, issues)
+		assert.Equal(t, &api.JobResources{Nodes: 1, CPUs: 1, WallTime: "00:30:00"}, resources)
+	})
+
+	t.Run("tasks spread over nodes", func(t *testing.T) {
+		resources, issues := ParseJobScript("#SBATCH -N 3\n#SBATCH --ntasks=10\n#SBATCH --mem-per-cpu=2G\n")
+		assert.Empty(t, issues)
+		assert.Equal(t, 3, resources.Nodes)
+		assert.Equal(t, 4, resources.CPUs)
+		assert.Equal(t, "24576M", resources.Memory)
+	})
+
+	t.Run("later directive wins", func(t *testing.T) {
+		resources, _ := ParseJobScript("#SBATCH -A first\n#SBATCH --account second\n")
+		assert.Equal(t, "second", resources.Account)
+	})
+
+	t.Run("unreadable values", func(t *testing.T) {
+		resources, issues := ParseJobScript("#SBATCH --nodes=two\n#SBATCH --time=UNLIMITED\n#SBATCH --gpus=lots\n")
+		require.Len(t, issues, 3)
+		fields := []string{issues[0].Field, issues[1].Field, issues[2].Field}
+		assert.ElementsMatch(t, []string{"nodes", "wall_time", "gpus"}, fields)
+		for _, issue := range issues {
+			assert.Equal(t, api.ValidationSeverityError, issue.Severity)
+		}
+		assert.Equal(t, 1, resources.Nodes)
+		assert.Empty(t, resources.WallTime)
+	})
+}
+
+func TestParseTimeLimit(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"90", "01:30:00"},
+		{"5:30", "00:05:30"},
+		{"2:00:00", "02:00:00"},
+		{"1-0", "24:00:00"},
+		{"2-06:15", "54:15:00"},
+		{"1-02:03:04", "26:03:04"},
+	}
+	for _, tt := range tests {
+		got, err := parseTimeLimit(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+
+	for _, value := range []string{"", "0", "UNLIMITED", "1:2:3:4", "x-01:00"} {
+		_, err := parseTimeLimit(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestGresGPUs(t *testing.T) {
+	tests := map[string]int{
+		"gpu":                   1,
+		"gpu:2":                 2,
+		"gpu:a100":              1,
+		"gpu:a100:4":            4,
+		"license:matlab:1":      0,
+		"gpu:v100:2,gpu:t4:1":   3,
+		"gpu:1,license:ansys:2": 1,
+	}
+	for gres, want := range tests {
+		got, err := gresGPUs(gres)
+		require.NoError(t, err, gres)
+		assert.Equal(t, want, got, gres)
+	}
+
+	_, err := gresGPUs("gpu:a100:many")
+	assert.Error(t, err)
+}
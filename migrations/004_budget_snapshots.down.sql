@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback budget snapshots
+
+DROP FUNCTION IF EXISTS capture_budget_snapshots(DATE);
+DROP TABLE IF EXISTS budget_snapshots;
@@ -0,0 +1,40 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Nightly per-account budget snapshots for point-in-time reporting
+
+CREATE TABLE budget_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    budget_limit DECIMAL(12,2) NOT NULL,
+    budget_used DECIMAL(12,2) NOT NULL,
+    budget_held DECIMAL(12,2) NOT NULL,
+    budget_available DECIMAL(12,2) NOT NULL,
+    captured_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(account_id, snapshot_date)
+);
+
+CREATE INDEX idx_budget_snapshots_account_date ON budget_snapshots(account_id, snapshot_date DESC);
+
+-- Capture a snapshot of every account's balances for the given date
+CREATE OR REPLACE FUNCTION capture_budget_snapshots(p_snapshot_date DATE)
+RETURNS INTEGER AS $$
+DECLARE
+    captured INTEGER;
+BEGIN
+    INSERT INTO budget_snapshots (
+        account_id, snapshot_date, budget_limit, budget_used, budget_held, budget_available
+    )
+    SELECT ba.id, p_snapshot_date, ba.budget_limit, ba.budget_used, ba.budget_held,
+           ba.budget_limit - ba.budget_used - ba.budget_held
+    FROM budget_accounts ba
+    ON CONFLICT (account_id, snapshot_date) DO UPDATE
+    SET budget_limit = EXCLUDED.budget_limit,
+        budget_used = EXCLUDED.budget_used,
+        budget_held = EXCLUDED.budget_held,
+        budget_available = EXCLUDED.budget_available,
+        captured_at = NOW();
+
+    GET DIAGNOSTICS captured = ROW_COUNT;
+    RETURN captured;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Per-account override of the default hold percentage
+
+ALTER TABLE budget_accounts
+ADD COLUMN hold_percentage DECIMAL(5,3) CHECK (hold_percentage IS NULL OR hold_percentage > 0);
@@ -0,0 +1,115 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback per-account and per-grant time zones
+
+DROP FUNCTION IF EXISTS calculate_next_allocation_date(TIMESTAMP WITH TIME ZONE, VARCHAR(32), VARCHAR(64));
+
+CREATE OR REPLACE FUNCTION calculate_next_allocation_date(
+    p_current_date TIMESTAMP WITH TIME ZONE,
+    p_frequency VARCHAR(32)
+) RETURNS TIMESTAMP WITH TIME ZONE AS $$
+BEGIN
+    CASE p_frequency
+        WHEN 'daily' THEN
+            RETURN p_current_date + INTERVAL '1 day';
+        WHEN 'weekly' THEN
+            RETURN p_current_date + INTERVAL '1 week';
+        WHEN 'monthly' THEN
+            RETURN p_current_date + INTERVAL '1 month';
+        WHEN 'quarterly' THEN
+            RETURN p_current_date + INTERVAL '3 months';
+        WHEN 'yearly' THEN
+            RETURN p_current_date + INTERVAL '1 year';
+        ELSE
+            RAISE EXCEPTION 'Invalid allocation frequency: %', p_frequency;
+    END CASE;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION process_pending_allocations()
+RETURNS TABLE(
+    schedule_id BIGINT,
+    account_id BIGINT,
+    allocated_amount DECIMAL(12,2),
+    transaction_id VARCHAR(128)
+) AS $$
+DECLARE
+    schedule_rec RECORD;
+    txn_id VARCHAR(128);
+    allocation_amount DECIMAL(12,2);
+BEGIN
+    -- Find active schedules that are due for allocation
+    FOR schedule_rec IN
+        SELECT bas.id, bas.account_id, bas.allocation_amount, bas.allocated_to_date,
+               bas.total_budget, bas.allocation_frequency, bas.next_allocation_date
+        FROM budget_allocation_schedules bas
+        WHERE bas.status = 'active'
+          AND bas.auto_allocate = TRUE
+          AND bas.next_allocation_date <= NOW()
+          AND bas.allocated_to_date < bas.total_budget
+    LOOP
+        -- Calculate allocation amount (don't exceed total budget)
+        allocation_amount := LEAST(schedule_rec.allocation_amount,
+                                  schedule_rec.total_budget - schedule_rec.allocated_to_date);
+
+        -- Generate transaction ID
+        txn_id := 'alloc_' || schedule_rec.id || '_' || extract(epoch from now())::bigint;
+
+        -- Create budget transaction
+        INSERT INTO budget_transactions (
+            transaction_id, account_id, type, amount, description, status
+        ) VALUES (
+            txn_id, schedule_rec.account_id, 'allocation', allocation_amount,
+            'Automated budget allocation', 'completed'
+        );
+
+        -- Record the allocation
+        INSERT INTO budget_allocations (
+            schedule_id, account_id, allocation_amount, transaction_id, notes
+        ) VALUES (
+            schedule_rec.id, schedule_rec.account_id, allocation_amount, txn_id,
+            'Automated allocation'
+        );
+
+        -- Update allocation schedule
+        UPDATE budget_allocation_schedules
+        SET allocated_to_date = allocated_to_date + allocation_amount,
+            remaining_budget = total_budget - (allocated_to_date + allocation_amount),
+            next_allocation_date = CASE
+                WHEN (allocated_to_date + allocation_amount) >= total_budget THEN NULL
+                ELSE calculate_next_allocation_date(next_allocation_date, allocation_frequency)
+            END,
+            status = CASE
+                WHEN (allocated_to_date + allocation_amount) >= total_budget THEN 'completed'
+                ELSE status
+            END,
+            updated_at = NOW()
+        WHERE id = schedule_rec.id;
+
+        -- Update account's budget limit and next allocation date
+        UPDATE budget_accounts
+        SET budget_limit = budget_limit + allocation_amount,
+            total_allocated = total_allocated + allocation_amount,
+            next_allocation_date = (
+                SELECT next_allocation_date
+                FROM budget_allocation_schedules
+                WHERE account_id = schedule_rec.account_id
+                  AND status = 'active'
+                ORDER BY next_allocation_date ASC
+                LIMIT 1
+            ),
+            updated_at = NOW()
+        WHERE id = schedule_rec.account_id;
+
+        -- Return result
+        RETURN QUERY SELECT schedule_rec.id, schedule_rec.account_id, allocation_amount, txn_id;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The widened transaction type constraint is kept since allocation rows may now exist
+
+ALTER TABLE grant_accounts
+DROP COLUMN IF EXISTS timezone;
+
+ALTER TABLE budget_accounts
+DROP COLUMN IF EXISTS timezone;
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback per-account burn rate opt-in
+
+ALTER TABLE budget_accounts
+DROP COLUMN IF EXISTS burn_rate_enabled;
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Per-account opt-in for burn rate analysis
+
+ALTER TABLE budget_accounts
+ADD COLUMN burn_rate_enabled BOOLEAN NOT NULL DEFAULT FALSE;
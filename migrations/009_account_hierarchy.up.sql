@@ -0,0 +1,152 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Parent/child account hierarchy with balances rolled up to every ancestor
+
+ALTER TABLE budget_accounts
+ADD COLUMN parent_account_id BIGINT REFERENCES budget_accounts(id) ON DELETE RESTRICT,
+ADD CONSTRAINT budget_accounts_parent_not_self CHECK (parent_account_id IS NULL OR parent_account_id <> id);
+
+CREATE INDEX idx_budget_accounts_parent ON budget_accounts(parent_account_id) WHERE parent_account_id IS NOT NULL;
+
+-- An account followed by its ancestors, nearest first. The depth cap stops a runaway
+-- walk should a cycle ever slip past set_account_parent.
+CREATE OR REPLACE FUNCTION account_and_ancestors(p_account_id BIGINT)
+RETURNS TABLE(account_id BIGINT, depth INTEGER) AS $$
+    WITH RECURSIVE chain(account_id, parent_id, depth) AS (
+        SELECT id, parent_account_id, 0 FROM budget_accounts WHERE id = p_account_id
+        UNION ALL
+        SELECT ba.id, ba.parent_account_id, chain.depth + 1
+        FROM budget_accounts ba
+        JOIN chain ON ba.id = chain.parent_id
+        WHERE chain.depth < 32
+    )
+    SELECT account_id, depth FROM chain;
+$$ LANGUAGE sql STABLE;
+
+-- An account followed by all of its descendants
+CREATE OR REPLACE FUNCTION account_and_descendants(p_account_id BIGINT)
+RETURNS TABLE(account_id BIGINT, depth INTEGER) AS $$
+    WITH RECURSIVE tree(account_id, depth) AS (
+        SELECT id, 0 FROM budget_accounts WHERE id = p_account_id
+        UNION ALL
+        SELECT ba.id, tree.depth + 1
+        FROM budget_accounts ba
+        JOIN tree ON ba.parent_account_id = tree.account_id
+        WHERE tree.depth < 32
+    )
+    SELECT account_id, depth FROM tree;
+$$ LANGUAGE sql STABLE;
+
+-- Moves an account (and its subtree) under a new parent, or detaches it when p_parent_id
+-- is NULL. The account's used and held balances already include its descendants, so they
+-- are taken off the old ancestors and added to the new ones.
+CREATE OR REPLACE FUNCTION set_account_parent(p_account_id BIGINT, p_parent_id BIGINT)
+RETURNS VOID AS $$
+DECLARE
+    account_rec budget_accounts%ROWTYPE;
+BEGIN
+    SELECT * INTO account_rec FROM budget_accounts WHERE id = p_account_id FOR UPDATE;
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'account % does not exist', p_account_id;
+    END IF;
+
+    IF p_parent_id IS NOT DISTINCT FROM account_rec.parent_account_id THEN
+        RETURN;
+    END IF;
+
+    IF p_parent_id IS NOT NULL AND EXISTS (
+        SELECT 1 FROM account_and_ancestors(p_parent_id) WHERE account_id = p_account_id
+    ) THEN
+        RAISE EXCEPTION 'account hierarchy cycle: % is an ancestor of %', p_account_id, p_parent_id;
+    END IF;
+
+    IF account_rec.parent_account_id IS NOT NULL THEN
+        UPDATE budget_accounts
+        SET budget_used = GREATEST(0, budget_used - account_rec.budget_used),
+            budget_held = GREATEST(0, budget_held - account_rec.budget_held),
+            updated_at = NOW()
+        WHERE id IN (SELECT account_id FROM account_and_ancestors(account_rec.parent_account_id));
+    END IF;
+
+    UPDATE budget_accounts
+    SET parent_account_id = p_parent_id,
+        updated_at = NOW()
+    WHERE id = p_account_id;
+
+    IF p_parent_id IS NOT NULL THEN
+        UPDATE budget_accounts
+        SET budget_used = budget_used + account_rec.budget_used,
+            budget_held = budget_held + account_rec.budget_held,
+            updated_at = NOW()
+        WHERE id IN (SELECT account_id FROM account_and_ancestors(p_parent_id));
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Balance changes now apply to the transaction's account and every ancestor, so a parent's
+-- used and held amounts cover its whole subtree
+CREATE OR REPLACE FUNCTION update_account_balance()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Only process completed transactions
+    IF NEW.status = 'completed' AND (OLD.status IS NULL OR OLD.status != 'completed') THEN
+        IF NEW.type = 'hold' THEN
+            -- Increase held amount
+            UPDATE budget_accounts
+            SET budget_held = budget_held + NEW.amount,
+                updated_at = NOW()
+            WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+
+        ELSIF NEW.type = 'charge' THEN
+            -- Increase used amount, decrease held amount if this was from a hold
+            IF NEW.parent_transaction_id IS NOT NULL THEN
+                -- This is a charge from a previous hold
+                UPDATE budget_accounts
+                SET budget_used = budget_used + NEW.amount,
+                    budget_held = GREATEST(0, budget_held - NEW.amount),
+                    updated_at = NOW()
+                WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+            ELSE
+                -- Direct charge
+                UPDATE budget_accounts
+                SET budget_used = budget_used + NEW.amount,
+                    updated_at = NOW()
+                WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+            END IF;
+
+        ELSIF NEW.type = 'refund' THEN
+            -- Decrease used amount or held amount
+            IF NEW.parent_transaction_id IS NOT NULL THEN
+                -- Get the parent transaction to determine what to refund
+                DECLARE
+                    parent_type VARCHAR(32);
+                BEGIN
+                    SELECT type INTO parent_type
+                    FROM budget_transactions
+                    WHERE transaction_id = NEW.parent_transaction_id;
+
+                    IF parent_type = 'charge' THEN
+                        UPDATE budget_accounts
+                        SET budget_used = GREATEST(0, budget_used - NEW.amount),
+                            updated_at = NOW()
+                        WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+                    ELSIF parent_type = 'hold' THEN
+                        UPDATE budget_accounts
+                        SET budget_held = GREATEST(0, budget_held - NEW.amount),
+                            updated_at = NOW()
+                        WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+                    END IF;
+                END;
+            END IF;
+
+        ELSIF NEW.type = 'adjustment' THEN
+            -- Administrative adjustment of used budget; negative amounts are credits
+            UPDATE budget_accounts
+            SET budget_used = GREATEST(0, budget_used + NEW.amount),
+                updated_at = NOW()
+            WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback per-component cost breakdown of job charges
+
+DROP TABLE IF EXISTS transaction_cost_components;
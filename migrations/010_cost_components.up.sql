@@ -0,0 +1,12 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Per-component cost breakdown (compute, storage, network, ...) of job charges
+
+CREATE TABLE transaction_cost_components (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id VARCHAR(128) NOT NULL REFERENCES budget_transactions(transaction_id) ON DELETE CASCADE,
+    component VARCHAR(64) NOT NULL,
+    amount DECIMAL(12,2) NOT NULL CHECK (amount >= 0),
+    UNIQUE(transaction_id, component)
+);
+
+CREATE INDEX idx_transaction_cost_components_component ON transaction_cost_components(component);
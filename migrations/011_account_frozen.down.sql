@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback per-account freeze
+
+ALTER TABLE budget_accounts
+DROP COLUMN IF EXISTS frozen;
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Per-account freeze that blocks new holds without suspending the account
+
+ALTER TABLE budget_accounts
+ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT FALSE;
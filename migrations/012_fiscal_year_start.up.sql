@@ -0,0 +1,144 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Fiscal year start for allocation boundaries, configurable per account
+
+ALTER TABLE budget_accounts
+ADD COLUMN fiscal_year_start VARCHAR(5)
+CHECK (fiscal_year_start ~ '^(0[1-9]|1[0-2])-(0[1-9]|1[0-9]|2[0-8])$');
+
+DROP FUNCTION IF EXISTS calculate_next_allocation_date(TIMESTAMP WITH TIME ZONE, VARCHAR(32), VARCHAR(64));
+DROP FUNCTION IF EXISTS process_pending_allocations();
+
+CREATE OR REPLACE FUNCTION calculate_next_allocation_date(
+    p_current_date TIMESTAMP WITH TIME ZONE,
+    p_frequency VARCHAR(32),
+    p_timezone VARCHAR(64) DEFAULT 'UTC',
+    p_fiscal_year_start VARCHAR(5) DEFAULT NULL
+) RETURNS TIMESTAMP WITH TIME ZONE AS $$
+DECLARE
+    local_date TIMESTAMP;
+    fiscal_start TIMESTAMP;
+    step INTERVAL;
+    steps INTEGER := 1;
+BEGIN
+    -- Step in the account's wall-clock time so local midnight stays local midnight across DST
+    local_date := p_current_date AT TIME ZONE p_timezone;
+
+    -- With a fiscal year start, quarterly and yearly allocations land on the next fiscal
+    -- quarter or year boundary, as FiscalYearStart.NextAllocationDate does
+    IF p_fiscal_year_start IS NOT NULL AND p_frequency IN ('quarterly', 'yearly') THEN
+        step := CASE p_frequency WHEN 'quarterly' THEN INTERVAL '3 months' ELSE INTERVAL '1 year' END;
+        fiscal_start := make_timestamp(EXTRACT(YEAR FROM local_date)::INTEGER,
+                                       split_part(p_fiscal_year_start, '-', 1)::INTEGER,
+                                       split_part(p_fiscal_year_start, '-', 2)::INTEGER, 0, 0, 0);
+        IF fiscal_start > date_trunc('day', local_date) THEN
+            fiscal_start := fiscal_start - INTERVAL '1 year';
+        END IF;
+        WHILE fiscal_start + steps * step <= local_date LOOP
+            steps := steps + 1;
+        END LOOP;
+        RETURN (fiscal_start + steps * step) AT TIME ZONE p_timezone;
+    END IF;
+
+    CASE p_frequency
+        WHEN 'daily' THEN
+            local_date := local_date + INTERVAL '1 day';
+        WHEN 'weekly' THEN
+            local_date := local_date + INTERVAL '1 week';
+        WHEN 'monthly' THEN
+            local_date := local_date + INTERVAL '1 month';
+        WHEN 'quarterly' THEN
+            local_date := local_date + INTERVAL '3 months';
+        WHEN 'yearly' THEN
+            local_date := local_date + INTERVAL '1 year';
+        ELSE
+            RAISE EXCEPTION 'Invalid allocation frequency: %', p_frequency;
+    END CASE;
+
+    RETURN local_date AT TIME ZONE p_timezone;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Accounts without a fiscal year start use the service's configured default, if any
+CREATE OR REPLACE FUNCTION process_pending_allocations(p_default_fiscal_year_start VARCHAR(5) DEFAULT NULL)
+RETURNS TABLE(
+    schedule_id BIGINT,
+    account_id BIGINT,
+    allocated_amount DECIMAL(12,2),
+    transaction_id VARCHAR(128)
+) AS $$
+DECLARE
+    schedule_rec RECORD;
+    txn_id VARCHAR(128);
+    allocation_amount DECIMAL(12,2);
+BEGIN
+    -- Find active schedules that are due for allocation
+    FOR schedule_rec IN
+        SELECT bas.id, bas.account_id, bas.allocation_amount, bas.allocated_to_date,
+               bas.total_budget, bas.allocation_frequency, bas.next_allocation_date,
+               ba.timezone, COALESCE(ba.fiscal_year_start, p_default_fiscal_year_start) AS fiscal_year_start
+        FROM budget_allocation_schedules bas
+        JOIN budget_accounts ba ON ba.id = bas.account_id
+        WHERE bas.status = 'active'
+          AND bas.auto_allocate = TRUE
+          AND bas.next_allocation_date <= NOW()
+          AND bas.allocated_to_date < bas.total_budget
+    LOOP
+        -- Calculate allocation amount (don't exceed total budget)
+        allocation_amount := LEAST(schedule_rec.allocation_amount,
+                                  schedule_rec.total_budget - schedule_rec.allocated_to_date);
+
+        -- Generate transaction ID
+        txn_id := 'alloc_' || schedule_rec.id || '_' || extract(epoch from now())::bigint;
+
+        -- Create budget transaction
+        INSERT INTO budget_transactions (
+            transaction_id, account_id, type, amount, description, status
+        ) VALUES (
+            txn_id, schedule_rec.account_id, 'allocation', allocation_amount,
+            'Automated budget allocation', 'completed'
+        );
+
+        -- Record the allocation
+        INSERT INTO budget_allocations (
+            schedule_id, account_id, allocation_amount, transaction_id, notes
+        ) VALUES (
+            schedule_rec.id, schedule_rec.account_id, allocation_amount, txn_id,
+            'Automated allocation'
+        );
+
+        -- Update allocation schedule
+        UPDATE budget_allocation_schedules
+        SET allocated_to_date = allocated_to_date + allocation_amount,
+            remaining_budget = total_budget - (allocated_to_date + allocation_amount),
+            next_allocation_date = CASE
+                WHEN (allocated_to_date + allocation_amount) >= total_budget THEN NULL
+                ELSE calculate_next_allocation_date(next_allocation_date, allocation_frequency, schedule_rec.timezone,
+                                                    schedule_rec.fiscal_year_start)
+            END,
+            status = CASE
+                WHEN (allocated_to_date + allocation_amount) >= total_budget THEN 'completed'
+                ELSE status
+            END,
+            updated_at = NOW()
+        WHERE id = schedule_rec.id;
+
+        -- Update account's budget limit and next allocation date
+        UPDATE budget_accounts
+        SET budget_limit = budget_limit + allocation_amount,
+            total_allocated = total_allocated + allocation_amount,
+            next_allocation_date = (
+                SELECT next_allocation_date
+                FROM budget_allocation_schedules
+                WHERE account_id = schedule_rec.account_id
+                  AND status = 'active'
+                ORDER BY next_allocation_date ASC
+                LIMIT 1
+            ),
+            updated_at = NOW()
+        WHERE id = schedule_rec.account_id;
+
+        -- Return result
+        RETURN QUERY SELECT schedule_rec.id, schedule_rec.account_id, allocation_amount, txn_id;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
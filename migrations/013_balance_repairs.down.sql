@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback balance repair audit trail
+
+DROP TABLE IF EXISTS budget_balance_repairs;
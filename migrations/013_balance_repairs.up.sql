@@ -0,0 +1,16 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Audit trail for cached account balances corrected from the transaction ledger
+
+CREATE TABLE budget_balance_repairs (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    previous_used DECIMAL(12,2) NOT NULL,
+    previous_held DECIMAL(12,2) NOT NULL,
+    repaired_used DECIMAL(12,2) NOT NULL,
+    repaired_held DECIMAL(12,2) NOT NULL,
+    reason TEXT,
+    repaired_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_budget_balance_repairs_account ON budget_balance_repairs(account_id, created_at DESC);
@@ -0,0 +1,6 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback grant period committed amounts
+
+DROP TRIGGER IF EXISTS budget_accounts_grant_period_committed ON budget_accounts;
+DROP FUNCTION IF EXISTS update_grant_period_committed();
+DROP FUNCTION IF EXISTS refresh_grant_period_committed(BIGINT);
@@ -0,0 +1,49 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Roll held budget on grant-period accounts up into the period's committed amount
+
+-- Recomputes a budget period's committed amount from the held balances of the accounts tied
+-- to it. An account's held balance already covers its descendants, so an account is only
+-- counted when no ancestor is tied to the same period.
+CREATE OR REPLACE FUNCTION refresh_grant_period_committed(p_period_id BIGINT)
+RETURNS VOID AS $$
+    UPDATE grant_budget_periods
+    SET period_committed_amount = (
+        SELECT COALESCE(SUM(ba.budget_held), 0.00)
+        FROM budget_accounts ba
+        WHERE ba.grant_budget_period_id = p_period_id
+          AND NOT EXISTS (
+              SELECT 1
+              FROM account_and_ancestors(ba.parent_account_id) anc
+              JOIN budget_accounts ancestor ON ancestor.id = anc.account_id
+              WHERE ancestor.grant_budget_period_id = p_period_id
+          )
+    )
+    WHERE id = p_period_id;
+$$ LANGUAGE sql;
+
+-- Keeps committed amounts current as holds are placed and released, and as accounts are
+-- tied to, moved between or removed from budget periods
+CREATE OR REPLACE FUNCTION update_grant_period_committed()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.grant_budget_period_id IS NOT NULL THEN
+        PERFORM refresh_grant_period_committed(OLD.grant_budget_period_id);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.grant_budget_period_id IS NOT NULL
+       AND (TG_OP = 'INSERT' OR NEW.grant_budget_period_id IS DISTINCT FROM OLD.grant_budget_period_id) THEN
+        PERFORM refresh_grant_period_committed(NEW.grant_budget_period_id);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER budget_accounts_grant_period_committed
+    AFTER INSERT OR DELETE OR UPDATE OF budget_held, grant_budget_period_id, parent_account_id
+    ON budget_accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION update_grant_period_committed();
+
+-- Bring existing periods up to date
+SELECT refresh_grant_period_committed(id) FROM grant_budget_periods;
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback domain estimate accuracy
+
+DROP TABLE IF EXISTS domain_estimate_accuracy;
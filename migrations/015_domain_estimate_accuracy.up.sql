@@ -0,0 +1,13 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Estimated and actual costs of reconciled jobs by research domain
+
+CREATE TABLE domain_estimate_accuracy (
+    id BIGSERIAL PRIMARY KEY,
+    research_domain VARCHAR(128) NOT NULL,
+    job_id VARCHAR(255),
+    estimated_cost DECIMAL(12,2) NOT NULL CHECK (estimated_cost > 0),
+    actual_cost DECIMAL(12,2) NOT NULL CHECK (actual_cost >= 0),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_domain_estimate_accuracy_domain ON domain_estimate_accuracy(research_domain, recorded_at DESC);
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback budget decision log
+
+DROP TABLE IF EXISTS budget_decisions;
@@ -0,0 +1,22 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Durable record of every budget check decision, approved or rejected, for compliance audits
+
+CREATE TABLE budget_decisions (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    partition VARCHAR(64) NOT NULL,
+    user_id VARCHAR(255),
+    decision VARCHAR(16) NOT NULL CHECK (decision IN ('approved', 'rejected')),
+    reason TEXT NOT NULL,
+    estimated_cost DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+    hold_amount DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+    budget_available DECIMAL(12,2) NOT NULL DEFAULT 0.00,
+    confidence DOUBLE PRECISION,
+    failure_mode VARCHAR(16),
+    transaction_id VARCHAR(128),
+    inputs JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_budget_decisions_account ON budget_decisions(account_id, created_at DESC);
+CREATE INDEX idx_budget_decisions_decision ON budget_decisions(decision);
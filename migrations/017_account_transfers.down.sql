@@ -0,0 +1,11 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback account transfers
+
+DROP TABLE IF EXISTS budget_account_transfers;
+
+UPDATE budget_accounts SET status = 'inactive' WHERE status = 'archived';
+
+ALTER TABLE budget_accounts DROP CONSTRAINT IF EXISTS budget_accounts_status_check;
+ALTER TABLE budget_accounts
+ADD CONSTRAINT budget_accounts_status_check
+CHECK (status IN ('active', 'inactive', 'suspended', 'expired'));
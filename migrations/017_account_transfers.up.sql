@@ -0,0 +1,28 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Account transfers: an archived status for merged accounts and the transfer audit trail
+
+ALTER TABLE budget_accounts DROP CONSTRAINT IF EXISTS budget_accounts_status_check;
+ALTER TABLE budget_accounts
+ADD CONSTRAINT budget_accounts_status_check
+CHECK (status IN ('active', 'inactive', 'suspended', 'expired', 'archived'));
+
+CREATE TABLE budget_account_transfers (
+    id BIGSERIAL PRIMARY KEY,
+    source_account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    dest_account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    budget_limit DECIMAL(12,2) NOT NULL,
+    budget_used DECIMAL(12,2) NOT NULL,
+    budget_held DECIMAL(12,2) NOT NULL,
+    reserved_amount DECIMAL(12,2) NOT NULL,
+    total_allocated DECIMAL(12,2) NOT NULL,
+    transactions_moved INTEGER NOT NULL DEFAULT 0,
+    schedules_merged INTEGER NOT NULL DEFAULT 0,
+    schedules_retired INTEGER NOT NULL DEFAULT 0,
+    reason TEXT,
+    transferred_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT budget_account_transfers_distinct CHECK (source_account_id <> dest_account_id)
+);
+
+CREATE INDEX idx_budget_account_transfers_source ON budget_account_transfers(source_account_id);
+CREATE INDEX idx_budget_account_transfers_dest ON budget_account_transfers(dest_account_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback transaction search indexes
+
+DROP INDEX IF EXISTS idx_budget_transactions_metadata_trgm;
+DROP INDEX IF EXISTS idx_budget_transactions_description_trgm;
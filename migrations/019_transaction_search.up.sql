@@ -0,0 +1,9 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Trigram indexes so transactions can be searched by substrings of their description and metadata
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_budget_transactions_description_trgm
+    ON budget_transactions USING GIN (description gin_trgm_ops);
+CREATE INDEX idx_budget_transactions_metadata_trgm
+    ON budget_transactions USING GIN ((metadata::text) gin_trgm_ops);
@@ -0,0 +1,15 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback reconciliation latency tracking
+
+DELETE FROM budget_alerts WHERE alert_type = 'reconciliation_sla';
+
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_alert_type_check;
+ALTER TABLE budget_alerts
+ADD CONSTRAINT budget_alerts_alert_type_check
+CHECK (alert_type IN (
+    'burn_rate_high', 'burn_rate_low', 'budget_threshold', 'grant_expiring',
+    'period_ending', 'overspend_risk', 'underspend_risk', 'compliance_warning'
+));
+
+DROP INDEX IF EXISTS idx_budget_transactions_reconciled_at;
+ALTER TABLE budget_transactions DROP COLUMN IF EXISTS reconciled_at;
@@ -0,0 +1,27 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- When each hold was reconciled, for reconciliation latency reporting, and an alert type for holds reconciled past the SLA
+
+ALTER TABLE budget_transactions ADD COLUMN reconciled_at TIMESTAMP WITH TIME ZONE;
+
+-- Holds reconciled before this migration were reconciled when their first charge or refund was written
+UPDATE budget_transactions hold
+SET reconciled_at = released.first_at
+FROM (
+    SELECT parent_transaction_id, MIN(created_at) AS first_at
+    FROM budget_transactions
+    WHERE parent_transaction_id IS NOT NULL AND type IN ('charge', 'refund')
+    GROUP BY parent_transaction_id
+) released
+WHERE hold.transaction_id = released.parent_transaction_id AND hold.type = 'hold' AND hold.status <> 'cancelled';
+
+CREATE INDEX idx_budget_transactions_reconciled_at
+    ON budget_transactions(reconciled_at) WHERE type = 'hold' AND reconciled_at IS NOT NULL;
+
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_alert_type_check;
+ALTER TABLE budget_alerts
+ADD CONSTRAINT budget_alerts_alert_type_check
+CHECK (alert_type IN (
+    'burn_rate_high', 'burn_rate_low', 'budget_threshold', 'grant_expiring',
+    'period_ending', 'overspend_risk', 'underspend_risk', 'compliance_warning',
+    'reconciliation_sla'
+));
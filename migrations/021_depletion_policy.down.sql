@@ -0,0 +1,16 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback the depletion policy
+
+DELETE FROM budget_alerts WHERE alert_type = 'budget_depleted';
+
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_alert_type_check;
+ALTER TABLE budget_alerts
+ADD CONSTRAINT budget_alerts_alert_type_check
+CHECK (alert_type IN (
+    'burn_rate_high', 'burn_rate_low', 'budget_threshold', 'grant_expiring',
+    'period_ending', 'overspend_risk', 'underspend_risk', 'compliance_warning',
+    'reconciliation_sla'
+));
+
+DROP INDEX IF EXISTS idx_budget_accounts_depleted;
+ALTER TABLE budget_accounts DROP COLUMN IF EXISTS depleted_at;
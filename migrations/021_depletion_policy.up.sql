@@ -0,0 +1,15 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- When the depletion policy suspended or froze an account, and an alert type for depletion
+
+ALTER TABLE budget_accounts ADD COLUMN depleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_budget_accounts_depleted ON budget_accounts(depleted_at) WHERE depleted_at IS NOT NULL;
+
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_alert_type_check;
+ALTER TABLE budget_alerts
+ADD CONSTRAINT budget_alerts_alert_type_check
+CHECK (alert_type IN (
+    'burn_rate_high', 'burn_rate_low', 'budget_threshold', 'grant_expiring',
+    'period_ending', 'overspend_risk', 'underspend_risk', 'compliance_warning',
+    'reconciliation_sla', 'budget_depleted'
+));
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback account tags
+
+DROP INDEX IF EXISTS idx_budget_accounts_tags;
+ALTER TABLE budget_accounts DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,6 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Free-form key/value tags on budget accounts, indexed for tag filters
+
+ALTER TABLE budget_accounts ADD COLUMN tags JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX idx_budget_accounts_tags ON budget_accounts USING GIN (tags);
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback per-account estimation source
+
+ALTER TABLE budget_accounts DROP COLUMN IF EXISTS estimation_source;
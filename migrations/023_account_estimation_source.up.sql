@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Per-account cost model for budget checks; NULL uses the service default
+
+ALTER TABLE budget_accounts ADD COLUMN estimation_source VARCHAR(16)
+    CHECK (estimation_source IN ('advisor', 'fallback', 'static'));
@@ -0,0 +1,7 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback cost-shared holds
+
+DROP INDEX IF EXISTS idx_budget_transactions_cost_share_group;
+ALTER TABLE budget_transactions
+DROP COLUMN IF EXISTS cost_share_percentage,
+DROP COLUMN IF EXISTS cost_share_group;
@@ -0,0 +1,10 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Cost-shared jobs: each funding account's hold records the job's group and its percentage
+
+ALTER TABLE budget_transactions
+ADD COLUMN cost_share_group VARCHAR(128),
+ADD COLUMN cost_share_percentage DECIMAL(7,4)
+    CHECK (cost_share_percentage IS NULL OR (cost_share_percentage > 0 AND cost_share_percentage <= 100));
+
+CREATE INDEX idx_budget_transactions_cost_share_group
+    ON budget_transactions(cost_share_group) WHERE cost_share_group IS NOT NULL;
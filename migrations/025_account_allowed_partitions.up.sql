@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Partitions an account's jobs may use; an empty list allows every partition
+
+ALTER TABLE budget_accounts ADD COLUMN allowed_partitions TEXT[] NOT NULL DEFAULT '{}';
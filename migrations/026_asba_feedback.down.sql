@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback ASBA feedback
+
+DROP TABLE IF EXISTS asba_feedback;
@@ -0,0 +1,19 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- ASBA feedback: what actually happened to a job after a burst recommendation, one per budget decision
+
+CREATE TABLE asba_feedback (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    decision_id BIGINT NOT NULL UNIQUE REFERENCES budget_decisions(id) ON DELETE CASCADE,
+    job_id VARCHAR(255),
+    recommended_action VARCHAR(16) NOT NULL
+        CHECK (recommended_action IN ('LOCAL', 'AWS', 'DEFER', 'OPTIMIZE')),
+    actual_location VARCHAR(16) NOT NULL CHECK (actual_location IN ('LOCAL', 'AWS')),
+    actual_cost DECIMAL(12,2) NOT NULL DEFAULT 0.00 CHECK (actual_cost >= 0),
+    recommendation_followed BOOLEAN NOT NULL,
+    notes TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_asba_feedback_account ON asba_feedback(account_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback two-phase holds
+
+ALTER TABLE budget_transactions DROP COLUMN IF EXISTS started_at;
+ALTER TABLE budget_transactions DROP COLUMN IF EXISTS full_hold_amount;
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Two-phase holds: a queued job reserves part of its hold until its prolog reports it started
+
+ALTER TABLE budget_transactions ADD COLUMN full_hold_amount DECIMAL(12,2) CHECK (full_hold_amount >= 0);
+ALTER TABLE budget_transactions ADD COLUMN started_at TIMESTAMP WITH TIME ZONE;
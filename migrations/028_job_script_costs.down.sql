@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback job script cost history
+
+DROP TABLE IF EXISTS job_script_costs;
@@ -0,0 +1,12 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Actual costs of reconciled jobs by job script hash, for estimates of recurring scripts
+
+CREATE TABLE job_script_costs (
+    id BIGSERIAL PRIMARY KEY,
+    script_hash CHAR(64) NOT NULL,
+    job_id VARCHAR(255),
+    actual_cost DECIMAL(12,2) NOT NULL CHECK (actual_cost >= 0),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_job_script_costs_hash ON job_script_costs(script_hash, recorded_at DESC);
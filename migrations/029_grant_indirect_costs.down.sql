@@ -0,0 +1,8 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback indirect cost exclusions
+
+ALTER TABLE grant_accounts DROP COLUMN IF EXISTS indirect_costs;
+ALTER TABLE grant_accounts
+ADD COLUMN indirect_costs DECIMAL(15,2) GENERATED ALWAYS AS (direct_costs * indirect_cost_rate) STORED;
+
+ALTER TABLE grant_accounts DROP COLUMN IF EXISTS excluded_direct_costs;
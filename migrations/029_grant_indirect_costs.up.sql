@@ -0,0 +1,13 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Exclude direct costs in capped categories from a grant's indirect cost base
+
+-- Direct costs in cost categories the funding agency does not charge indirect costs on
+ALTER TABLE grant_accounts
+ADD COLUMN excluded_direct_costs DECIMAL(15,2) NOT NULL DEFAULT 0.00 CHECK (excluded_direct_costs >= 0);
+
+-- A generated column's expression cannot be altered, so it is recreated
+ALTER TABLE grant_accounts DROP COLUMN indirect_costs;
+ALTER TABLE grant_accounts
+ADD COLUMN indirect_costs DECIMAL(15,2) GENERATED ALWAYS AS (
+    GREATEST(direct_costs - excluded_direct_costs, 0) * COALESCE(indirect_cost_rate, 0)
+) STORED;
@@ -0,0 +1,72 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback ordered account chain locking
+
+-- Restore the balance trigger from 009
+CREATE OR REPLACE FUNCTION update_account_balance()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Only process completed transactions
+    IF NEW.status = 'completed' AND (OLD.status IS NULL OR OLD.status != 'completed') THEN
+        IF NEW.type = 'hold' THEN
+            -- Increase held amount
+            UPDATE budget_accounts
+            SET budget_held = budget_held + NEW.amount,
+                updated_at = NOW()
+            WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+
+        ELSIF NEW.type = 'charge' THEN
+            -- Increase used amount, decrease held amount if this was from a hold
+            IF NEW.parent_transaction_id IS NOT NULL THEN
+                -- This is a charge from a previous hold
+                UPDATE budget_accounts
+                SET budget_used = budget_used + NEW.amount,
+                    budget_held = GREATEST(0, budget_held - NEW.amount),
+                    updated_at = NOW()
+                WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+            ELSE
+                -- Direct charge
+                UPDATE budget_accounts
+                SET budget_used = budget_used + NEW.amount,
+                    updated_at = NOW()
+                WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+            END IF;
+
+        ELSIF NEW.type = 'refund' THEN
+            -- Decrease used amount or held amount
+            IF NEW.parent_transaction_id IS NOT NULL THEN
+                -- Get the parent transaction to determine what to refund
+                DECLARE
+                    parent_type VARCHAR(32);
+                BEGIN
+                    SELECT type INTO parent_type
+                    FROM budget_transactions
+                    WHERE transaction_id = NEW.parent_transaction_id;
+
+                    IF parent_type = 'charge' THEN
+                        UPDATE budget_accounts
+                        SET budget_used = GREATEST(0, budget_used - NEW.amount),
+                            updated_at = NOW()
+                        WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+                    ELSIF parent_type = 'hold' THEN
+                        UPDATE budget_accounts
+                        SET budget_held = GREATEST(0, budget_held - NEW.amount),
+                            updated_at = NOW()
+                        WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+                    END IF;
+                END;
+            END IF;
+
+        ELSIF NEW.type = 'adjustment' THEN
+            -- Administrative adjustment of used budget; negative amounts are credits
+            UPDATE budget_accounts
+            SET budget_used = GREATEST(0, budget_used + NEW.amount),
+                updated_at = NOW()
+            WHERE id IN (SELECT account_id FROM account_and_ancestors(NEW.account_id));
+        END IF;
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS lock_account_chain(BIGINT);
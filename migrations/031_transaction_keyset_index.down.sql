@@ -0,0 +1,6 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback transaction list keyset indexes
+
+CREATE INDEX idx_budget_transactions_created_at ON budget_transactions(created_at);
+DROP INDEX IF EXISTS idx_budget_transactions_account_created_id;
+DROP INDEX IF EXISTS idx_budget_transactions_created_id;
@@ -0,0 +1,8 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Index the transaction list's (created_at, id) order for cursor pagination
+
+-- The composite indexes serve the list's order and cursor predicate, for all transactions
+-- and for one account's, and replace the index on created_at alone
+CREATE INDEX idx_budget_transactions_created_id ON budget_transactions(created_at, id);
+CREATE INDEX idx_budget_transactions_account_created_id ON budget_transactions(account_id, created_at, id);
+DROP INDEX IF EXISTS idx_budget_transactions_created_at;
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback account membership
+
+DROP TABLE IF EXISTS budget_account_members;
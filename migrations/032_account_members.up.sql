@@ -0,0 +1,11 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Account membership: the users who may submit jobs under each account
+
+CREATE TABLE budget_account_members (
+    account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    user_name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (account_id, user_name)
+);
+
+CREATE INDEX idx_budget_account_members_user ON budget_account_members(user_name);
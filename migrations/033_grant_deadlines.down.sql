@@ -0,0 +1,16 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback grant deadlines and generated reports
+
+DELETE FROM budget_alerts WHERE alert_type = 'grant_report_ready';
+
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_alert_type_check;
+ALTER TABLE budget_alerts
+ADD CONSTRAINT budget_alerts_alert_type_check
+CHECK (alert_type IN (
+    'burn_rate_high', 'burn_rate_low', 'budget_threshold', 'grant_expiring',
+    'period_ending', 'overspend_risk', 'underspend_risk', 'compliance_warning',
+    'reconciliation_sla', 'budget_depleted'
+));
+
+DROP TABLE IF EXISTS grant_reports;
+DROP TABLE IF EXISTS grant_deadlines;
@@ -0,0 +1,37 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Grant deadlines, the financial reports generated for GRANT_REPORT deadlines, and an
+-- alert type announcing them
+
+CREATE TABLE grant_deadlines (
+    id BIGSERIAL PRIMARY KEY,
+    grant_id BIGINT NOT NULL REFERENCES grant_accounts(id) ON DELETE CASCADE,
+    deadline_type VARCHAR(32) NOT NULL CHECK (deadline_type IN ('CONFERENCE', 'GRANT_REPORT', 'PERIOD_END', 'RENEWAL')),
+    description TEXT NOT NULL DEFAULT '',
+    due_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    budget_period INTEGER CHECK (budget_period > 0), -- Period a report covers; NULL for the period at the due date
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_grant_deadlines_grant ON grant_deadlines(grant_id, due_date);
+CREATE INDEX idx_grant_deadlines_report_due ON grant_deadlines(due_date) WHERE deadline_type = 'GRANT_REPORT';
+
+-- Reports generated for deadlines; each deadline gets one
+CREATE TABLE grant_reports (
+    id BIGSERIAL PRIMARY KEY,
+    grant_id BIGINT NOT NULL REFERENCES grant_accounts(id) ON DELETE CASCADE,
+    deadline_id BIGINT UNIQUE REFERENCES grant_deadlines(id) ON DELETE SET NULL,
+    report_type VARCHAR(32) NOT NULL,
+    report JSONB NOT NULL,
+    generated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_grant_reports_grant ON grant_reports(grant_id, generated_at);
+
+ALTER TABLE budget_alerts DROP CONSTRAINT IF EXISTS budget_alerts_alert_type_check;
+ALTER TABLE budget_alerts
+ADD CONSTRAINT budget_alerts_alert_type_check
+CHECK (alert_type IN (
+    'burn_rate_high', 'burn_rate_low', 'budget_threshold', 'grant_expiring',
+    'period_ending', 'overspend_risk', 'underspend_risk', 'compliance_warning',
+    'reconciliation_sla', 'budget_depleted', 'grant_report_ready'
+));
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback the reconciliation dead-letter queue
+
+DROP TABLE IF EXISTS reconciliation_failures;
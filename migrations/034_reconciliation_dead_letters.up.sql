@@ -0,0 +1,22 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Failed reconciliations, dead-lettered for an administrator once they keep failing
+
+CREATE TABLE reconciliation_failures (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id VARCHAR(255) NOT NULL UNIQUE, -- The hold being reconciled
+    job_id VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(32) NOT NULL CHECK (source IN ('reconcile', 'recovery')),
+    request JSONB, -- The reconciliation request to replay; NULL when recovery would cancel the hold
+    attempts INTEGER NOT NULL DEFAULT 1 CHECK (attempts > 0),
+    reason TEXT NOT NULL,
+    first_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    dead_lettered_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    resolution VARCHAR(32) CHECK (resolution IN ('replayed', 'resolved', 'reconciled')),
+    resolved_by VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_reconciliation_failures_dead_letter ON reconciliation_failures(dead_lettered_at)
+    WHERE dead_lettered_at IS NOT NULL AND resolved_at IS NULL;
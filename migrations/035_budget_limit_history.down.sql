@@ -0,0 +1,6 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback the account budget limit history
+
+DROP TRIGGER IF EXISTS budget_accounts_limit_history ON budget_accounts;
+DROP FUNCTION IF EXISTS record_budget_limit_change();
+DROP TABLE IF EXISTS budget_limit_history;
@@ -0,0 +1,38 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- History of account budget limits, so point-in-time reports use the limit in force at the
+-- report date rather than the current one
+
+CREATE TABLE budget_limit_history (
+    id BIGSERIAL PRIMARY KEY,
+    account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    previous_limit DECIMAL(12,2), -- NULL for the limit an account opened with
+    budget_limit DECIMAL(12,2) NOT NULL,
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_budget_limit_history_account ON budget_limit_history(account_id, effective_at);
+
+-- Record every limit an account has: its opening limit, then each change, whether made by
+-- an administrator, an allocation or a transfer
+CREATE OR REPLACE FUNCTION record_budget_limit_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO budget_limit_history (account_id, previous_limit, budget_limit, effective_at)
+        VALUES (NEW.id, NULL, NEW.budget_limit, COALESCE(NEW.created_at, NOW()));
+    ELSIF NEW.budget_limit IS DISTINCT FROM OLD.budget_limit THEN
+        INSERT INTO budget_limit_history (account_id, previous_limit, budget_limit)
+        VALUES (NEW.id, OLD.budget_limit, NEW.budget_limit);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER budget_accounts_limit_history
+    AFTER INSERT OR UPDATE OF budget_limit ON budget_accounts
+    FOR EACH ROW
+    EXECUTE FUNCTION record_budget_limit_change();
+
+-- Accounts that predate the history start it with their limit as of now
+INSERT INTO budget_limit_history (account_id, previous_limit, budget_limit)
+SELECT id, NULL, budget_limit FROM budget_accounts;
@@ -0,0 +1,5 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback ASBX reconciliation records
+
+DROP INDEX IF EXISTS idx_budget_transactions_reconciliation;
+DROP TABLE IF EXISTS asbx_reconciliations;
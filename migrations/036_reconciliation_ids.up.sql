@@ -0,0 +1,26 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- ASBX reconciliations and their variance summaries; the charges and refunds a
+-- reconciliation writes carry its ID in their metadata
+
+CREATE TABLE asbx_reconciliations (
+    id BIGSERIAL PRIMARY KEY,
+    reconciliation_id VARCHAR(64) NOT NULL UNIQUE,
+    job_id VARCHAR(128) NOT NULL,
+    account VARCHAR(255) NOT NULL DEFAULT '',
+    transaction_id VARCHAR(128), -- Original hold; NULL for a job never held
+    estimated_cost DECIMAL(12,2) NOT NULL,
+    estimate_source VARCHAR(16) NOT NULL,
+    actual_cost DECIMAL(12,2) NOT NULL,
+    cost_variance DECIMAL(12,2) NOT NULL,
+    cost_variance_pct DECIMAL(12,2) NOT NULL,
+    estimation_accuracy DECIMAL(6,4) NOT NULL,
+    charged_amount DECIMAL(12,2) NOT NULL,
+    refund_amount DECIMAL(12,2) NOT NULL,
+    unreserved BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_asbx_reconciliations_job ON asbx_reconciliations(job_id);
+
+CREATE INDEX idx_budget_transactions_reconciliation ON budget_transactions ((metadata->>'reconciliation_id'))
+    WHERE metadata->>'reconciliation_id' IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback accounts denominated in service units
+
+ALTER TABLE budget_accounts
+    DROP CONSTRAINT IF EXISTS budget_accounts_su_rate,
+    DROP COLUMN IF EXISTS dollars_per_su,
+    DROP COLUMN IF EXISTS budget_unit;
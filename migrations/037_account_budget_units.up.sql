@@ -0,0 +1,10 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Accounts denominated in service units (SU); an su account's limit, balances and
+-- transactions are in SU, and dollar costs are converted at its dollars_per_su
+
+ALTER TABLE budget_accounts
+    ADD COLUMN budget_unit VARCHAR(8) NOT NULL DEFAULT 'dollars'
+        CHECK (budget_unit IN ('dollars', 'su')),
+    ADD COLUMN dollars_per_su DECIMAL(12,6) CHECK (dollars_per_su > 0),
+    ADD CONSTRAINT budget_accounts_su_rate
+        CHECK (budget_unit = 'dollars' OR dollars_per_su IS NOT NULL);
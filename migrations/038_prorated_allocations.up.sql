@@ -0,0 +1,174 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Prorated first allocations: a schedule's first allocation covers only the part of its
+-- period left, and later allocations land on period boundaries
+
+-- The period an allocation made at p_date covers, as in FiscalYearStart.AllocationPeriod:
+-- the day, the ISO week from Monday, the calendar month, or the fiscal quarter or year
+-- containing it, in the account's time zone. Without a fiscal year start quarters and
+-- years are calendar ones.
+CREATE OR REPLACE FUNCTION allocation_period_bounds(
+    p_date TIMESTAMP WITH TIME ZONE,
+    p_frequency VARCHAR(32),
+    p_timezone VARCHAR(64) DEFAULT 'UTC',
+    p_fiscal_year_start VARCHAR(5) DEFAULT NULL
+)
+RETURNS TABLE(
+    period_start TIMESTAMP WITH TIME ZONE,
+    period_end TIMESTAMP WITH TIME ZONE
+) AS $$
+DECLARE
+    local_day TIMESTAMP;
+    fiscal_start TIMESTAMP;
+    step INTERVAL;
+    steps INTEGER := 1;
+    bound_start TIMESTAMP;
+    bound_end TIMESTAMP;
+BEGIN
+    local_day := date_trunc('day', p_date AT TIME ZONE p_timezone);
+
+    CASE p_frequency
+        WHEN 'daily' THEN
+            bound_start := local_day;
+            bound_end := local_day + INTERVAL '1 day';
+        WHEN 'weekly' THEN
+            bound_start := date_trunc('week', local_day);
+            bound_end := bound_start + INTERVAL '1 week';
+        WHEN 'monthly' THEN
+            bound_start := date_trunc('month', local_day);
+            bound_end := bound_start + INTERVAL '1 month';
+        WHEN 'quarterly', 'yearly' THEN
+            step := CASE p_frequency WHEN 'quarterly' THEN INTERVAL '3 months' ELSE INTERVAL '1 year' END;
+            fiscal_start := make_timestamp(EXTRACT(YEAR FROM local_day)::INTEGER,
+                                           split_part(COALESCE(p_fiscal_year_start, '01-01'), '-', 1)::INTEGER,
+                                           split_part(COALESCE(p_fiscal_year_start, '01-01'), '-', 2)::INTEGER, 0, 0, 0);
+            IF fiscal_start > local_day THEN
+                fiscal_start := fiscal_start - INTERVAL '1 year';
+            END IF;
+            WHILE fiscal_start + steps * step <= local_day LOOP
+                steps := steps + 1;
+            END LOOP;
+            bound_end := fiscal_start + steps * step;
+            bound_start := bound_end - step;
+        ELSE
+            RAISE EXCEPTION 'Invalid allocation frequency: %', p_frequency;
+    END CASE;
+
+    RETURN QUERY SELECT bound_start AT TIME ZONE p_timezone, bound_end AT TIME ZONE p_timezone;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS process_pending_allocations(VARCHAR(5), BIGINT[]);
+
+-- With p_prorate_first_allocation, a schedule that has allocated nothing yet allocates the
+-- share of its period left, counted in local calendar days, and next allocates at the
+-- start of the following period. The total budget still caps every allocation.
+CREATE OR REPLACE FUNCTION process_pending_allocations(
+    p_default_fiscal_year_start VARCHAR(5) DEFAULT NULL,
+    p_schedule_ids BIGINT[] DEFAULT NULL,
+    p_prorate_first_allocation BOOLEAN DEFAULT FALSE
+)
+RETURNS TABLE(
+    schedule_id BIGINT,
+    account_id BIGINT,
+    allocated_amount DECIMAL(12,2),
+    transaction_id VARCHAR(128)
+) AS $$
+DECLARE
+    schedule_rec RECORD;
+    period_rec RECORD;
+    txn_id VARCHAR(128);
+    allocation_amount DECIMAL(12,2);
+    prorated_next_date TIMESTAMP WITH TIME ZONE;
+    local_day DATE;
+BEGIN
+    -- Find active schedules that are due for allocation
+    FOR schedule_rec IN
+        SELECT bas.id, bas.account_id, bas.allocation_amount, bas.allocated_to_date,
+               bas.total_budget, bas.allocation_frequency, bas.next_allocation_date,
+               ba.timezone, COALESCE(ba.fiscal_year_start, p_default_fiscal_year_start) AS fiscal_year_start
+        FROM budget_allocation_schedules bas
+        JOIN budget_accounts ba ON ba.id = bas.account_id
+        WHERE bas.status = 'active'
+          AND bas.auto_allocate = TRUE
+          AND bas.next_allocation_date <= NOW()
+          AND bas.allocated_to_date < bas.total_budget
+          AND (p_schedule_ids IS NULL OR bas.id = ANY(p_schedule_ids))
+        ORDER BY bas.account_id, bas.id
+        FOR UPDATE OF bas SKIP LOCKED
+    LOOP
+        allocation_amount := schedule_rec.allocation_amount;
+        prorated_next_date := NULL;
+
+        -- Prorate the first allocation by the days left in its period
+        IF p_prorate_first_allocation AND schedule_rec.allocated_to_date = 0 THEN
+            SELECT * INTO period_rec
+            FROM allocation_period_bounds(schedule_rec.next_allocation_date, schedule_rec.allocation_frequency,
+                                          schedule_rec.timezone, schedule_rec.fiscal_year_start);
+            local_day := (schedule_rec.next_allocation_date AT TIME ZONE schedule_rec.timezone)::DATE;
+            allocation_amount := ROUND(allocation_amount
+                * ((period_rec.period_end AT TIME ZONE schedule_rec.timezone)::DATE - local_day)
+                / ((period_rec.period_end AT TIME ZONE schedule_rec.timezone)::DATE
+                   - (period_rec.period_start AT TIME ZONE schedule_rec.timezone)::DATE), 2);
+            prorated_next_date := period_rec.period_end;
+        END IF;
+
+        -- Calculate allocation amount (don't exceed total budget)
+        allocation_amount := LEAST(allocation_amount,
+                                  schedule_rec.total_budget - schedule_rec.allocated_to_date);
+
+        -- Generate transaction ID
+        txn_id := 'alloc_' || schedule_rec.id || '_' || extract(epoch from now())::bigint;
+
+        -- Create budget transaction
+        INSERT INTO budget_transactions (
+            transaction_id, account_id, type, amount, description, status
+        ) VALUES (
+            txn_id, schedule_rec.account_id, 'allocation', allocation_amount,
+            'Automated budget allocation', 'completed'
+        );
+
+        -- Record the allocation
+        INSERT INTO budget_allocations (
+            schedule_id, account_id, allocation_amount, transaction_id, notes
+        ) VALUES (
+            schedule_rec.id, schedule_rec.account_id, allocation_amount, txn_id,
+            CASE WHEN prorated_next_date IS NULL THEN 'Automated allocation' ELSE 'Automated allocation, prorated' END
+        );
+
+        -- Update allocation schedule
+        UPDATE budget_allocation_schedules
+        SET allocated_to_date = allocated_to_date + allocation_amount,
+            remaining_budget = total_budget - (allocated_to_date + allocation_amount),
+            next_allocation_date = CASE
+                WHEN (allocated_to_date + allocation_amount) >= total_budget THEN NULL
+                ELSE COALESCE(prorated_next_date,
+                              calculate_next_allocation_date(next_allocation_date, allocation_frequency, schedule_rec.timezone,
+                                                             schedule_rec.fiscal_year_start))
+            END,
+            status = CASE
+                WHEN (allocated_to_date + allocation_amount) >= total_budget THEN 'completed'
+                ELSE status
+            END,
+            updated_at = NOW()
+        WHERE id = schedule_rec.id;
+
+        -- Update account's budget limit and next allocation date
+        UPDATE budget_accounts
+        SET budget_limit = budget_limit + allocation_amount,
+            total_allocated = total_allocated + allocation_amount,
+            next_allocation_date = (
+                SELECT next_allocation_date
+                FROM budget_allocation_schedules
+                WHERE account_id = schedule_rec.account_id
+                  AND status = 'active'
+                ORDER BY next_allocation_date ASC
+                LIMIT 1
+            ),
+            updated_at = NOW()
+        WHERE id = schedule_rec.account_id;
+
+        -- Return result
+        RETURN QUERY SELECT schedule_rec.id, schedule_rec.account_id, allocation_amount, txn_id;
+    END LOOP;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback hold adjustment audit trail
+
+DROP TABLE IF EXISTS budget_hold_adjustments;
@@ -0,0 +1,15 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Audit trail for live holds whose amount an administrator changed in place
+
+CREATE TABLE budget_hold_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id VARCHAR(128) NOT NULL REFERENCES budget_transactions(transaction_id) ON DELETE CASCADE,
+    account_id BIGINT NOT NULL REFERENCES budget_accounts(id) ON DELETE CASCADE,
+    previous_amount DECIMAL(12,2) NOT NULL,
+    new_amount DECIMAL(12,2) NOT NULL CHECK (new_amount > 0),
+    reason TEXT NOT NULL,
+    adjusted_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_budget_hold_adjustments_transaction ON budget_hold_adjustments(transaction_id, created_at DESC);
@@ -0,0 +1,14 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback grant period attribution of transactions
+
+DROP TRIGGER IF EXISTS budget_transactions_grant_period_spent ON budget_transactions;
+DROP TRIGGER IF EXISTS budget_transactions_grant_period ON budget_transactions;
+DROP FUNCTION IF EXISTS update_grant_period_spent();
+DROP FUNCTION IF EXISTS refresh_grant_period_spent(BIGINT);
+DROP FUNCTION IF EXISTS attribute_transaction_grant_period();
+DROP FUNCTION IF EXISTS grant_period_in_force(BIGINT, TIMESTAMP WITH TIME ZONE);
+DROP INDEX IF EXISTS idx_budget_transactions_grant_period;
+
+ALTER TABLE budget_transactions DROP COLUMN IF EXISTS grant_budget_period_id;
+
+UPDATE grant_budget_periods SET period_spent_amount = 0.00;
@@ -0,0 +1,114 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Attribute transactions to the grant budget period in force when their hold was placed,
+-- and roll charges up into each period's spent amount
+
+ALTER TABLE budget_transactions
+ADD COLUMN grant_budget_period_id BIGINT REFERENCES grant_budget_periods(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_budget_transactions_grant_period ON budget_transactions(grant_budget_period_id)
+    WHERE grant_budget_period_id IS NOT NULL;
+
+-- Returns the budget period in force at p_at of the grant funding an account: the grant of
+-- the account itself or of its nearest ancestor with one
+CREATE OR REPLACE FUNCTION grant_period_in_force(p_account_id BIGINT, p_at TIMESTAMP WITH TIME ZONE)
+RETURNS BIGINT AS $$
+    SELECT gbp.id
+    FROM account_and_ancestors(p_account_id) chain
+    JOIN budget_accounts ba ON ba.id = chain.account_id
+    JOIN grant_budget_periods gbp ON gbp.grant_id = ba.grant_id
+    WHERE gbp.period_start_date <= p_at AND p_at < gbp.period_end_date
+    ORDER BY chain.depth
+    LIMIT 1;
+$$ LANGUAGE sql STABLE;
+
+-- A transaction written without a period takes its parent's, so a job's charges and
+-- refunds land in the period its hold was placed in however late it reconciles; one
+-- without a parent takes the period in force when it is written
+CREATE OR REPLACE FUNCTION attribute_transaction_grant_period()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.grant_budget_period_id IS NOT NULL THEN
+        RETURN NEW;
+    END IF;
+
+    IF NEW.parent_transaction_id IS NOT NULL THEN
+        SELECT grant_budget_period_id INTO NEW.grant_budget_period_id
+        FROM budget_transactions
+        WHERE transaction_id = NEW.parent_transaction_id;
+    ELSE
+        NEW.grant_budget_period_id := grant_period_in_force(NEW.account_id, COALESCE(NEW.created_at, NOW()));
+    END IF;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER budget_transactions_grant_period
+    BEFORE INSERT ON budget_transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION attribute_transaction_grant_period();
+
+-- Recomputes a budget period's spent amount from the completed charges attributed to it,
+-- less refunds of them; charges in SU are costed at the account's current dollars_per_su
+CREATE OR REPLACE FUNCTION refresh_grant_period_spent(p_period_id BIGINT)
+RETURNS VOID AS $$
+    UPDATE grant_budget_periods
+    SET period_spent_amount = (
+        SELECT GREATEST(0, COALESCE(SUM(
+                   CASE WHEN t.type = 'charge' THEN 1 ELSE -1 END
+                   * CASE WHEN ba.budget_unit = 'su' THEN t.amount * ba.dollars_per_su ELSE t.amount END), 0.00))
+        FROM budget_transactions t
+        JOIN budget_accounts ba ON ba.id = t.account_id
+        LEFT JOIN budget_transactions parent ON parent.transaction_id = t.parent_transaction_id
+        WHERE t.grant_budget_period_id = p_period_id
+          AND t.status = 'completed'
+          AND (t.type = 'charge' OR (t.type = 'refund' AND parent.type = 'charge'))
+    ),
+    updated_at = NOW()
+    WHERE id = p_period_id;
+$$ LANGUAGE sql;
+
+-- Attribute existing transactions: those without a parent to the period in force when they
+-- were written, and every other to the period of the transaction it descends from
+WITH RECURSIVE attributed AS (
+    SELECT transaction_id, grant_period_in_force(account_id, created_at) AS period_id
+    FROM budget_transactions
+    WHERE parent_transaction_id IS NULL
+    UNION ALL
+    SELECT child.transaction_id, attributed.period_id
+    FROM budget_transactions child
+    JOIN attributed ON child.parent_transaction_id = attributed.transaction_id
+)
+UPDATE budget_transactions t
+SET grant_budget_period_id = attributed.period_id
+FROM attributed
+WHERE t.transaction_id = attributed.transaction_id
+  AND attributed.period_id IS NOT NULL;
+
+-- Keeps spent amounts current as charges and refunds complete, change or move period
+CREATE OR REPLACE FUNCTION update_grant_period_spent()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.grant_budget_period_id IS NOT NULL
+       AND OLD.type IN ('charge', 'refund') THEN
+        PERFORM refresh_grant_period_spent(OLD.grant_budget_period_id);
+    END IF;
+
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.grant_budget_period_id IS NOT NULL
+       AND NEW.type IN ('charge', 'refund')
+       AND (TG_OP = 'INSERT' OR NEW.grant_budget_period_id IS DISTINCT FROM OLD.grant_budget_period_id) THEN
+        PERFORM refresh_grant_period_spent(NEW.grant_budget_period_id);
+    END IF;
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER budget_transactions_grant_period_spent
+    AFTER INSERT OR DELETE OR UPDATE OF status, amount, grant_budget_period_id
+    ON budget_transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_grant_period_spent();
+
+-- Bring existing periods up to date
+SELECT refresh_grant_period_spent(id) FROM grant_budget_periods;
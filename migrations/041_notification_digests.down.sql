@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback notification digests
+
+DROP TABLE IF EXISTS notification_digest_items;
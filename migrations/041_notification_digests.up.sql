@@ -0,0 +1,16 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Alert notifications held for each recipient's next digest
+
+CREATE TABLE notification_digest_items (
+    id BIGSERIAL PRIMARY KEY,
+    recipient VARCHAR(255) NOT NULL,
+    alert_id BIGINT NOT NULL REFERENCES budget_alerts(id) ON DELETE CASCADE,
+    account VARCHAR(255) NOT NULL,
+    alert_type VARCHAR(64) NOT NULL,
+    severity VARCHAR(32) NOT NULL,
+    message TEXT NOT NULL,
+    raised_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_notification_digest_items_recipient ON notification_digest_items(recipient, id);
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback reconciled hold index
+
+DROP INDEX IF EXISTS idx_budget_transactions_reconciled_holds;
@@ -0,0 +1,7 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Find an account's reconciled holds by partition, for the recent actuals of jobs like a
+-- new one that are sent to the advisor
+
+CREATE INDEX idx_budget_transactions_reconciled_holds
+    ON budget_transactions(account_id, (metadata->>'partition'), reconciled_at DESC)
+    WHERE type = 'hold' AND status = 'completed';
@@ -0,0 +1,4 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback the notification queue
+
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,10 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Alert notifications queued as alerts are raised, for the notification worker to send off
+-- the request path
+
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    recipient VARCHAR(255) NOT NULL,
+    notification JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
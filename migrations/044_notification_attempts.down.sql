@@ -0,0 +1,14 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Rollback notification delivery attempts
+
+DROP INDEX IF EXISTS idx_notifications_status;
+
+-- Only notifications still to be sent belong in the queue without attempts
+DELETE FROM notifications WHERE status <> 'pending';
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS last_attempt_at,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS status;
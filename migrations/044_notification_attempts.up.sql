@@ -0,0 +1,12 @@
+-- Copyright 2025 Scott Friedman. All rights reserved.
+-- Delivery attempts of queued notifications, kept once sent so an administrator can see and
+-- resend those that failed
+
+ALTER TABLE notifications
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+    ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0 CHECK (attempts >= 0),
+    ADD COLUMN last_error TEXT NOT NULL DEFAULT '', -- Why the latest attempt failed
+    ADD COLUMN last_attempt_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN delivered_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_notifications_status ON notifications(status, id);
@@ -132,6 +132,9 @@ type GrantTimelineResponse struct {
 	DaysUntilPeriodEnd int       `json:"days_until_period_end"`
 	DaysUntilGrantEnd  int       `json:"days_until_grant_end"`
 
+	// Current budget period split into spent, committed and remaining funds
+	Period *GrantPeriodSummary `json:"period,omitempty"`
+
 	// Budget allocation timeline
 	AllocationSchedule []AllocationEvent `json:"allocation_schedule"`
 	NextAllocation     *AllocationEvent  `json:"next_allocation,omitempty"`
@@ -187,12 +190,23 @@ type BudgetTimelinePoint struct {
 type EmergencyOption struct {
 	Option        string   `json:"option"` // REALLOCATE, EMERGENCY_FUND, COST_SHARING, DEADLINE_EXTENSION
 	Description   string   `json:"description"`
-	EstimatedCost float64  `json:"estimated_cost,omitempty"`
+	Account       string   `json:"account,omitempty"`        // Account the funds would come from
+	Available     float64  `json:"available,omitempty"`      // What that account has to spare
+	EstimatedCost float64  `json:"estimated_cost,omitempty"` // Funds the option would provide
+	Impact        string   `json:"impact,omitempty"`         // How far those funds would go
 	Timeline      string   `json:"timeline"`
 	Requirements  []string `json:"requirements"`
 	RiskLevel     string   `json:"risk_level"`
 }
 
+// Emergency options proposed for an account in critical budget health near a deadline
+const (
+	EmergencyOptionReallocate        = "REALLOCATE"
+	EmergencyOptionEmergencyFund     = "EMERGENCY_FUND"
+	EmergencyOptionCostSharing       = "COST_SHARING"
+	EmergencyOptionDeadlineExtension = "DEADLINE_EXTENSION"
+)
+
 // BurstDecisionRequest represents a request for burst decision making
 type BurstDecisionRequest struct {
 	Account             string            `json:"account" validate:"required"`
@@ -226,9 +240,10 @@ type BurstDecisionResponse struct {
 	BudgetPreservation float64 `json:"budget_preservation"` // How much budget to preserve
 
 	// Decision reasoning
-	DecisionFactors []DecisionFactor `json:"decision_factors"`
-	RiskAssessment  RiskAssessment   `json:"risk_assessment"`
-	Alternatives    []ResourceOption `json:"alternatives"`
+	DecisionFactors []DecisionFactor     `json:"decision_factors"`
+	Feedback        *ASBAFeedbackSummary `json:"feedback,omitempty"` // The outcomes the factors were weighted by
+	RiskAssessment  RiskAssessment       `json:"risk_assessment"`
+	Alternatives    []ResourceOption     `json:"alternatives"`
 
 	// Actionable guidance
 	ImmediateActions    []string `json:"immediate_actions"`
@@ -246,6 +261,13 @@ type DecisionFactor struct {
 	Description string  `json:"description"`
 }
 
+// Decision factors of a burst decision
+const (
+	DecisionFactorBudgetHealth     = "Budget Health"
+	DecisionFactorDeadlinePressure = "Deadline Pressure"
+	DecisionFactorCostEfficiency   = "Cost Efficiency"
+)
+
 // RiskAssessment provides comprehensive risk analysis
 type RiskAssessment struct {
 	OverallRisk          string   `json:"overall_risk"`
@@ -256,3 +278,105 @@ type RiskAssessment struct {
 	MitigationStrategies []string `json:"mitigation_strategies"`
 	ConfidenceLevel      float64  `json:"confidence_level"`
 }
+
+// Burst actions ASBA recommends, and where a job can actually run
+const (
+	BurstActionLocal    = "LOCAL"
+	BurstActionAWS      = "AWS"
+	BurstActionDefer    = "DEFER"
+	BurstActionOptimize = "OPTIMIZE"
+)
+
+// ASBAFeedbackRequest reports what actually happened to a job after an ASBA burst
+// recommendation. It is linked to the job's budget decision, given by its ID or by the
+// transaction ID of the hold the budget check placed.
+type ASBAFeedbackRequest struct {
+	Account                string  `json:"account" validate:"required"`
+	DecisionID             int64   `json:"decision_id,omitempty"`
+	TransactionID          string  `json:"transaction_id,omitempty"`
+	JobID                  string  `json:"job_id,omitempty"`
+	RecommendedAction      string  `json:"recommended_action" validate:"required,oneof=LOCAL AWS DEFER OPTIMIZE"`
+	ActualLocation         string  `json:"actual_location" validate:"required,oneof=LOCAL AWS"`
+	ActualCost             float64 `json:"actual_cost" validate:"min=0"`
+	RecommendationFollowed bool    `json:"recommendation_followed"`
+	Notes                  string  `json:"notes,omitempty"`
+}
+
+// Validate performs basic validation on ASBAFeedbackRequest
+func (r *ASBAFeedbackRequest) Validate() error {
+	var errs ValidationErrors
+	if r.Account == "" {
+		errs.Add("account", "is required")
+	}
+	switch {
+	case r.DecisionID == 0 && r.TransactionID == "":
+		errs.Add("decision_id", "decision_id or transaction_id is required")
+	case r.DecisionID != 0 && r.TransactionID != "":
+		errs.Add("decision_id", "give decision_id or transaction_id, not both")
+	case r.DecisionID < 0:
+		errs.Add("decision_id", "must be positive")
+	}
+	switch r.RecommendedAction {
+	case BurstActionLocal, BurstActionAWS, BurstActionDefer, BurstActionOptimize:
+	default:
+		errs.Add("recommended_action", "must be LOCAL, AWS, DEFER or OPTIMIZE")
+	}
+	if r.ActualLocation != BurstActionLocal && r.ActualLocation != BurstActionAWS {
+		errs.Add("actual_location", "must be LOCAL or AWS")
+	}
+	if r.ActualCost < 0 {
+		errs.Add("actual_cost", "must not be negative")
+	}
+	return errs.Err()
+}
+
+// ASBAFeedback is a job outcome ASBA reported, with the estimate its budget decision was
+// made on
+type ASBAFeedback struct {
+	ID                     int64     `json:"id"`
+	AccountID              int64     `json:"account_id"`
+	DecisionID             int64     `json:"decision_id"`
+	JobID                  string    `json:"job_id,omitempty"`
+	RecommendedAction      string    `json:"recommended_action"`
+	ActualLocation         string    `json:"actual_location"`
+	ActualCost             float64   `json:"actual_cost"`
+	EstimatedCost          float64   `json:"estimated_cost"`
+	RecommendationFollowed bool      `json:"recommendation_followed"`
+	Notes                  string    `json:"notes,omitempty"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// ASBAFeedbackListRequest pages through an account's ASBA feedback
+type ASBAFeedbackListRequest struct {
+	Account string `json:"account"`
+	Limit   int    `json:"limit,omitempty" validate:"omitempty,min=1,max=1000"`
+	Offset  int    `json:"offset,omitempty" validate:"omitempty,min=0"`
+}
+
+// ASBAFeedbackSummary totals an account's recent ASBA feedback
+type ASBAFeedbackSummary struct {
+	Outcomes         int     `json:"outcomes"`
+	Followed         int     `json:"followed"`
+	AWSOutcomes      int     `json:"aws_outcomes"`
+	AWSActualCost    float64 `json:"aws_actual_cost"`
+	AWSEstimatedCost float64 `json:"aws_estimated_cost"`
+}
+
+// FollowedRate is the share of outcomes where the recommendation was followed, 0 without
+// any outcomes
+func (s *ASBAFeedbackSummary) FollowedRate() float64 {
+	if s.Outcomes == 0 {
+		return 0
+	}
+	return float64(s.Followed) / float64(s.Outcomes)
+}
+
+// AWSCostRatio is what jobs that ran on AWS actually cost relative to their estimates,
+// 0 when there is nothing to compare
+func (s *ASBAFeedbackSummary) AWSCostRatio() float64 {
+	if s.AWSEstimatedCost <= 0 {
+		return 0
+	}
+	return s.AWSActualCost / s.AWSEstimatedCost
+}
@@ -38,9 +38,11 @@ type ASBXJobCostData struct {
 	WallTimeLimit   string `json:"wall_time_limit"`
 	ActualWallTime  string `json:"actual_wall_time"`
 
-	// Cost breakdown from ASBX
-	EstimatedCost float64            `json:"estimated_cost"`
-	ActualCost    float64            `json:"actual_cost"`
+	// Cost breakdown from ASBX. Either cost may be missing from partial job data: a missing
+	// estimate falls back to the hold amount, while a job without an actual cost cannot be
+	// reconciled.
+	EstimatedCost *float64           `json:"estimated_cost,omitempty"`
+	ActualCost    *float64           `json:"actual_cost,omitempty"`
 	LocalCost     float64            `json:"local_cost,omitempty"`
 	AWSCost       float64            `json:"aws_cost,omitempty"`
 	CostBreakdown map[string]float64 `json:"cost_breakdown,omitempty"`
@@ -82,15 +84,23 @@ type ASBXCostReconciliationResponse struct {
 	ReconciliationID    string `json:"reconciliation_id"`
 	OriginalTransaction string `json:"original_transaction"`
 
-	// Cost reconciliation details
+	// Cost reconciliation details. EstimateSource is asbx, or hold when ASBX sent no estimate
+	// and the hold amount was used in its place, or none when there was no hold either.
 	EstimatedCost   float64 `json:"estimated_cost"`
+	EstimateSource  string  `json:"estimate_source"`
 	ActualCost      float64 `json:"actual_cost"`
 	CostVariance    float64 `json:"cost_variance"`
 	CostVariancePct float64 `json:"cost_variance_pct"`
 
 	// Budget impact
+	ChargedAmount    float64 `json:"charged_amount"`
 	RefundAmount     float64 `json:"refund_amount"`
 	AdditionalCharge float64 `json:"additional_charge"`
+	FailedJobPolicy  string  `json:"failed_job_policy,omitempty"` // Applied when the job FAILED
+	// Unreserved is set when the job was never held, having bypassed the budget check, and
+	// ChargeTransaction is then the retroactive charge, if any
+	Unreserved        bool   `json:"unreserved,omitempty"`
+	ChargeTransaction string `json:"charge_transaction,omitempty"`
 
 	// Performance learning
 	EstimationAccuracy float64 `json:"estimation_accuracy"`
@@ -105,6 +115,31 @@ type ASBXCostReconciliationResponse struct {
 	Recommendations []string `json:"recommendations,omitempty"`
 }
 
+// ASBXReconciliationSummary is the variance summary recorded for an ASBX reconciliation
+type ASBXReconciliationSummary struct {
+	ReconciliationID    string    `json:"reconciliation_id"`
+	JobID               string    `json:"job_id"`
+	Account             string    `json:"account,omitempty"`
+	OriginalTransaction string    `json:"original_transaction,omitempty"` // Empty for a job never held
+	EstimatedCost       float64   `json:"estimated_cost"`
+	EstimateSource      string    `json:"estimate_source"`
+	ActualCost          float64   `json:"actual_cost"`
+	CostVariance        float64   `json:"cost_variance"`
+	CostVariancePct     float64   `json:"cost_variance_pct"`
+	EstimationAccuracy  float64   `json:"estimation_accuracy"`
+	ChargedAmount       float64   `json:"charged_amount"`
+	RefundAmount        float64   `json:"refund_amount"`
+	Unreserved          bool      `json:"unreserved,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// ASBXReconciliation is an ASBX reconciliation with every transaction it involved: the
+// holds it settled and the charges and refunds it wrote, oldest first
+type ASBXReconciliation struct {
+	Summary      *ASBXReconciliationSummary `json:"summary,omitempty"` // Missing if it failed to be recorded
+	Transactions []*BudgetTransaction       `json:"transactions"`
+}
+
 // ASBXPerformanceFeedback represents performance data to improve cost estimation
 type ASBXPerformanceFeedback struct {
 	JobID     string `json:"job_id"`
@@ -132,18 +167,15 @@ type ASBXPerformanceFeedback struct {
 	ResourceRecommendations map[string]string      `json:"resource_recommendations,omitempty"`
 }
 
-// ASBXIntegrationStatus represents the status of ASBX integration
+// ASBXIntegrationStatus summarizes the ASBX reconciliations recorded so far
 type ASBXIntegrationStatus struct {
-	ASBXVersion               string    `json:"asbx_version"`
-	IntegrationEnabled        bool      `json:"integration_enabled"`
-	LastDataImport            time.Time `json:"last_data_import"`
-	TotalJobsReconciled       int64     `json:"total_jobs_reconciled"`
-	SuccessfulReconciliations int64     `json:"successful_reconciliations"`
-	FailedReconciliations     int64     `json:"failed_reconciliations"`
-	AverageReconciliationTime string    `json:"average_reconciliation_time"`
-	CostModelAccuracy         float64   `json:"cost_model_accuracy"`
-	LastHealthCheck           time.Time `json:"last_health_check"`
-	HealthStatus              string    `json:"health_status"`
+	ASBXVersion         string     `json:"asbx_version"` // ASBX cost data format the service reads
+	IntegrationEnabled  bool       `json:"integration_enabled"`
+	LastDataImport      *time.Time `json:"last_data_import,omitempty"` // Latest reconciliation; nil before the first
+	TotalJobsReconciled int64      `json:"total_jobs_reconciled"`
+	// CostModelAccuracy is the mean estimation accuracy of the reconciliations ASBX sent an
+	// estimate for; zero before the first
+	CostModelAccuracy float64 `json:"cost_model_accuracy"`
 }
 
 // ASBXEpilogRequest represents data from SLURM epilog script
@@ -0,0 +1,101 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkCalendar is the days an account is expected to spend on: its working weekdays less
+// holidays. The zero value expects spending every day.
+type WorkCalendar struct {
+	weekdays [7]bool
+	limited  bool
+	holidays map[string]bool
+}
+
+// weekdayNames maps the names weekdays may be written with to the weekday
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// ParseWorkCalendar parses working weekdays, written as names such as mon or monday, and
+// holidays written as YYYY-MM-DD. No working days means every day is one.
+func ParseWorkCalendar(workingDays, holidays []string) (WorkCalendar, error) {
+	var calendar WorkCalendar
+	for _, name := range workingDays {
+		weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return WorkCalendar{}, fmt.Errorf("working day %q is not a weekday", name)
+		}
+		calendar.weekdays[weekday] = true
+		calendar.limited = true
+	}
+
+	for _, value := range holidays {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(value))
+		if err != nil {
+			return WorkCalendar{}, fmt.Errorf("holiday %q must be YYYY-MM-DD", value)
+		}
+		if calendar.holidays == nil {
+			calendar.holidays = make(map[string]bool, len(holidays))
+		}
+		calendar.holidays[date.Format("2006-01-02")] = true
+	}
+	return calendar, nil
+}
+
+// IsWorkingDay reports whether spending is expected on the calendar date day carries
+func (c WorkCalendar) IsWorkingDay(day time.Time) bool {
+	if c.limited && !c.weekdays[day.Weekday()] {
+		return false
+	}
+	return !c.holidays[day.Format("2006-01-02")]
+}
+
+// WorkingDays counts the working days from the date start carries up to, but not
+// including, the date end carries. Both are taken as UTC dates.
+func (c WorkCalendar) WorkingDays(start, end time.Time) int {
+	start = localDate(start, time.UTC)
+	end = localDate(end, time.UTC)
+	if !end.After(start) {
+		return 0
+	}
+	days := int(end.Sub(start).Hours() / 24)
+
+	perWeek := 7
+	if c.limited {
+		perWeek = 0
+		for _, working := range c.weekdays {
+			if working {
+				perWeek++
+			}
+		}
+	}
+
+	// Whole weeks hold every weekday once; the remaining days are counted one by one
+	count := days / 7 * perWeek
+	for day := start.AddDate(0, 0, days/7*7); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !c.limited || c.weekdays[day.Weekday()] {
+			count++
+		}
+	}
+
+	for holiday := range c.holidays {
+		date, _ := time.Parse("2006-01-02", holiday)
+		if !date.Before(start) && date.Before(end) && (!c.limited || c.weekdays[date.Weekday()]) {
+			count--
+		}
+	}
+	return count
+}
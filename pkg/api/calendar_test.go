@@ -0,0 +1,51 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorkCalendar(t *testing.T) {
+	calendar, err := ParseWorkCalendar([]string{"mon", "Tuesday", " WED ", "thu", "fri"}, []string{"2025-12-25"})
+	require.NoError(t, err)
+
+	assert.True(t, calendar.IsWorkingDay(time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC)))  // Monday
+	assert.False(t, calendar.IsWorkingDay(time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC))) // Christmas
+	assert.False(t, calendar.IsWorkingDay(time.Date(2025, 12, 27, 0, 0, 0, 0, time.UTC))) // Saturday
+
+	_, err = ParseWorkCalendar([]string{"funday"}, nil)
+	assert.Error(t, err)
+	_, err = ParseWorkCalendar(nil, []string{"25/12/2025"})
+	assert.Error(t, err)
+}
+
+func TestWorkCalendar_WorkingDays(t *testing.T) {
+	start := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC) // a Monday
+	end := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// The zero value counts every day
+	assert.Equal(t, 31, WorkCalendar{}.WorkingDays(start, end))
+	assert.True(t, WorkCalendar{}.IsWorkingDay(start.AddDate(0, 0, 5)))
+
+	weekdays, err := ParseWorkCalendar([]string{"mon", "tue", "wed", "thu", "fri"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 23, weekdays.WorkingDays(start, end))
+	assert.Equal(t, 5, weekdays.WorkingDays(start, start.AddDate(0, 0, 7)))
+	assert.Equal(t, 0, weekdays.WorkingDays(end, start))
+
+	// Holidays count only within the range and on working days
+	holidays, err := ParseWorkCalendar([]string{"mon", "tue", "wed", "thu", "fri"},
+		[]string{"2025-12-25", "2025-12-26", "2025-12-27", "2026-01-01"})
+	require.NoError(t, err)
+	assert.Equal(t, 21, holidays.WorkingDays(start, end))
+
+	// Times within a day count as that UTC date
+	assert.Equal(t, 1, weekdays.WorkingDays(start.Add(18*time.Hour), start.AddDate(0, 0, 1).Add(time.Hour)))
+}
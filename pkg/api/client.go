@@ -34,16 +34,86 @@ func (c *Client) CreateAccount(ctx context.Context, req *CreateAccountRequest) (
 	return nil, fmt.Errorf("not implemented")
 }
 
+// BulkCreateAccounts creates several budget accounts, reporting the outcome of each
+func (c *Client) BulkCreateAccounts(ctx context.Context, reqs []*CreateAccountRequest) (*BulkCreateAccountsResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// CloneAccount creates a budget account from an existing one
+func (c *Client) CloneAccount(ctx context.Context, sourceAccount string, req *CloneAccountRequest) (*AccountCloneResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// AddAccountMember lets a user submit jobs under a budget account
+func (c *Client) AddAccountMember(ctx context.Context, account, user string) (*AccountMember, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// RemoveAccountMember removes a user from a budget account's members
+func (c *Client) RemoveAccountMember(ctx context.Context, account, user string) error {
+	return fmt.Errorf("not implemented")
+}
+
+// ListAccountMembers lists the users who may submit under a budget account
+func (c *Client) ListAccountMembers(ctx context.Context, account string) ([]*AccountMember, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ListUserAccounts lists the budget accounts a user may submit under
+func (c *Client) ListUserAccounts(ctx context.Context, user string) (*UserAccountsResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 // GetAccount retrieves a budget account
 func (c *Client) GetAccount(ctx context.Context, account string) (*BudgetAccount, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
+// UpdateAccount updates a budget account
+func (c *Client) UpdateAccount(ctx context.Context, account string, req *UpdateAccountRequest) (*BudgetAccount, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// AdjustBudget applies an administrative budget adjustment to an account
+func (c *Client) AdjustBudget(ctx context.Context, account string, req *BudgetAdjustmentRequest) (*BudgetAdjustmentResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ListDecisions lists an account's recorded budget check decisions
+func (c *Client) ListDecisions(ctx context.Context, req *DecisionListRequest) ([]*BudgetDecision, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 // ListAllocationSchedules lists allocation schedules
 func (c *Client) ListAllocationSchedules(ctx context.Context, req *AllocationScheduleRequest) ([]*BudgetAllocationSchedule, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
+// PreviewAllocations projects an account's next count allocations
+func (c *Client) PreviewAllocations(ctx context.Context, account string, count int) (*AllocationPreviewResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// CreateAllocationSchedule adds an allocation schedule to an account
+func (c *Client) CreateAllocationSchedule(ctx context.Context, account string, req *CreateAllocationScheduleRequest) (*BudgetAllocationSchedule, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ListAccountSchedules retrieves an account's allocation schedules and the allocations each made
+func (c *Client) ListAccountSchedules(ctx context.Context, account string) (*AccountSchedulesResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// PauseAllocationSchedules pauses active allocation schedules in bulk
+func (c *Client) PauseAllocationSchedules(ctx context.Context, req *BulkScheduleStatusRequest) (*BulkScheduleStatusResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ResumeAllocationSchedules reactivates paused allocation schedules in bulk
+func (c *Client) ResumeAllocationSchedules(ctx context.Context, req *BulkScheduleStatusRequest) (*BulkScheduleStatusResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 // ProcessAllocations processes pending allocations
 func (c *Client) ProcessAllocations(ctx context.Context, req *ProcessAllocationsRequest) (*ProcessAllocationsResponse, error) {
 	return nil, fmt.Errorf("not implemented")
@@ -61,12 +131,159 @@ func (c *Client) GetGrant(ctx context.Context, grantNumber string) (*GrantAccoun
 	return nil, fmt.Errorf("not implemented")
 }
 
+// GetGrantPeriodSummary retrieves a grant budget period's spent, committed and remaining
+// funds, for the current period when period is nil
+func (c *Client) GetGrantPeriodSummary(ctx context.Context, grantNumber string, period *int) (*GrantPeriodSummary, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// RecomputeGrantCosts recomputes a grant's direct and indirect costs from its charges
+func (c *Client) RecomputeGrantCosts(ctx context.Context, grantNumber string) (*GrantCostRecomputation, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// CreateGrantDeadline adds a deadline to a grant
+func (c *Client) CreateGrantDeadline(ctx context.Context, grantNumber string, req *CreateGrantDeadlineRequest) (*GrantDeadline, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ListGrantDeadlines lists a grant's deadlines
+func (c *Client) ListGrantDeadlines(ctx context.Context, grantNumber string) ([]*GrantDeadline, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetGrantReport downloads a report generated for one of a grant's deadlines
+func (c *Client) GetGrantReport(ctx context.Context, grantNumber string, reportID int64) (*GrantReportResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 // ListGrants lists grants with filtering
 func (c *Client) ListGrants(ctx context.Context, req *GrantListRequest) ([]*GrantAccount, error) {
 	return nil, fmt.Errorf("not implemented")
 }
 
+// BackfillBurnRates computes missing burn rate history for an account
+func (c *Client) BackfillBurnRates(ctx context.Context, account string, req *BurnRateBackfillRequest) (*BurnRateBackfillResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetLimitHistory lists the changes to an account's budget limit, oldest first
+func (c *Client) GetLimitHistory(ctx context.Context, account string) ([]*BudgetLimitChange, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// EstimateCost prices a job shape without checking any account's budget
+func (c *Client) EstimateCost(ctx context.Context, req *EstimateRequest) (*EstimateResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// SimulateBudget projects the budget impact of hypothetical jobs without recording anything
+func (c *Client) SimulateBudget(ctx context.Context, account string, req *SimulationRequest) (*SimulationResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
 // GetBurnRateAnalysis retrieves burn rate analysis
 func (c *Client) GetBurnRateAnalysis(ctx context.Context, req *BurnRateAnalysisRequest) (*BurnRateAnalysisResponse, error) {
 	return nil, fmt.Errorf("not implemented")
 }
+
+// ReportJobStarted escalates a queued job's hold to the full amount once the job starts
+func (c *Client) ReportJobStarted(ctx context.Context, req *JobStartedRequest) (*JobStartedResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ReconcileAccountingJobs reconciles holds from SLURM accounting records
+func (c *Client) ReconcileAccountingJobs(ctx context.Context, req *AccountingReconcileRequest) (*AccountingReconcileResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// CheckConsistency compares cached account balances with the transaction ledger
+func (c *Client) CheckConsistency(ctx context.Context, req *ConsistencyCheckRequest) (*ConsistencyCheckResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// RepairConsistency corrects drifted cached account balances from the transaction ledger
+func (c *Client) RepairConsistency(ctx context.Context, req *ConsistencyCheckRequest) (*ConsistencyCheckResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetReconciliation retrieves an ASBX reconciliation and its transactions by its ID
+func (c *Client) GetReconciliation(ctx context.Context, reconciliationID string) (*ASBXReconciliation, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ListDeadLetters lists reconciliations dead-lettered after failing repeatedly
+func (c *Client) ListDeadLetters(ctx context.Context) ([]*ReconciliationDeadLetter, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ReplayDeadLetter retries a dead-lettered reconciliation
+func (c *Client) ReplayDeadLetter(ctx context.Context, id int64) (*ReconciliationDeadLetter, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ResolveDeadLetter closes a dead-lettered reconciliation without retrying it
+func (c *Client) ResolveDeadLetter(ctx context.Context, id int64, req *ResolveDeadLetterRequest) (*ReconciliationDeadLetter, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ListNotifications lists queued notifications with the status, or every one when status
+// is empty
+func (c *Client) ListNotifications(ctx context.Context, status string) ([]*NotificationRecord, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// RetryNotification resends a failed notification
+func (c *Client) RetryNotification(ctx context.Context, id int64) (*NotificationRecord, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// TransferAccount merges the source account into the destination and archives it
+func (c *Client) TransferAccount(ctx context.Context, sourceAccount, destAccount string, req *AccountTransferRequest) (*AccountTransferResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// AdjustHold changes the amount a live hold reserves
+func (c *Client) AdjustHold(ctx context.Context, transactionID string, req *HoldAdjustRequest) (*HoldAdjustResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ExportAccount retrieves a complete copy of an account for backup
+func (c *Client) ExportAccount(ctx context.Context, slurmAccount string) (*AccountExport, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ImportAccount restores an account from an export
+func (c *Client) ImportAccount(ctx context.Context, export *AccountExport) (*AccountImportResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// Health retrieves the service's health check
+func (c *Client) Health(ctx context.Context) (*HealthCheckResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetAdminSummary retrieves account and hold totals and database connection stats
+func (c *Client) GetAdminSummary(ctx context.Context) (*AdminSummary, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetMigrationStatus retrieves the database schema version and pending migrations
+func (c *Client) GetMigrationStatus(ctx context.Context) (*MigrationStatus, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetEffectiveConfig retrieves the service's effective configuration, with secrets redacted
+func (c *Client) GetEffectiveConfig(ctx context.Context) (*EffectiveConfig, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetOverview retrieves org-wide budget totals
+func (c *Client) GetOverview(ctx context.Context, req *OverviewRequest) (*Overview, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// ListAtRiskAccounts retrieves the accounts projected to deplete within withinDays days
+func (c *Client) ListAtRiskAccounts(ctx context.Context, withinDays int) (*AtRiskAccountsResponse, error) {
+	return nil, fmt.Errorf("not implemented")
+}
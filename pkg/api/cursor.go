@@ -0,0 +1,57 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransactionCursor is a position in the transaction list, which is ordered newest first
+// by creation time and then ID. A page continues with the transactions after its cursor,
+// so rows added while a client pages through the list neither shift nor repeat its pages.
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// NewTransactionCursor returns the cursor just after a transaction
+func NewTransactionCursor(transaction *BudgetTransaction) TransactionCursor {
+	return TransactionCursor{CreatedAt: transaction.CreatedAt, ID: transaction.ID}
+}
+
+// Encode returns the cursor as an opaque, URL-safe string. Creation times are kept to the
+// microsecond, the database's precision.
+func (c TransactionCursor) Encode() string {
+	raw := fmt.Sprintf("%d.%d", c.CreatedAt.UnixMicro(), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParseTransactionCursor decodes a cursor returned by Encode
+func ParseTransactionCursor(s string) (*TransactionCursor, error) {
+	invalid := NewValidationError("cursor", "is not a valid transaction cursor")
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+	micros, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return nil, invalid
+	}
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	transactionID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || transactionID <= 0 {
+		return nil, invalid
+	}
+
+	return &TransactionCursor{CreatedAt: time.UnixMicro(createdAt).UTC(), ID: transactionID}, nil
+}
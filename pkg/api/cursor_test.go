@@ -0,0 +1,44 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionCursor_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, time.March, 14, 9, 26, 53, 589793000, time.UTC)
+	cursor := NewTransactionCursor(&BudgetTransaction{ID: 4321, CreatedAt: createdAt})
+
+	encoded := cursor.Encode()
+	assert.NotContains(t, encoded, "4321", "cursors are opaque")
+
+	decoded, err := ParseTransactionCursor(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4321), decoded.ID)
+	assert.True(t, createdAt.Equal(decoded.CreatedAt))
+}
+
+func TestParseTransactionCursor_Invalid(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"not base64!",
+		base64.RawURLEncoding.EncodeToString([]byte("1700000000000000")),
+		base64.RawURLEncoding.EncodeToString([]byte("soon.12")),
+		base64.RawURLEncoding.EncodeToString([]byte("1700000000000000.0")),
+		base64.RawURLEncoding.EncodeToString([]byte("1700000000000000.x")),
+	} {
+		_, err := ParseTransactionCursor(raw)
+		budgetErr, ok := AsBudgetError(err)
+		require.True(t, ok, raw)
+		assert.Equal(t, ErrCodeValidation, budgetErr.Code, raw)
+		assert.Equal(t, "cursor", budgetErr.Field, raw)
+	}
+}
@@ -5,8 +5,11 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // Error types for the budget system
@@ -30,14 +33,26 @@ const (
 	ErrCodeInsufficientBudget ErrorCode = "INSUFFICIENT_BUDGET"
 	// ErrCodeAccountInactive represents account inactive errors
 	ErrCodeAccountInactive ErrorCode = "ACCOUNT_INACTIVE"
+	// ErrCodeAccountFrozen represents accounts frozen against new holds
+	ErrCodeAccountFrozen ErrorCode = "ACCOUNT_FROZEN"
 	// ErrCodeAccountExpired represents account expired errors
 	ErrCodeAccountExpired ErrorCode = "ACCOUNT_EXPIRED"
+	// ErrCodeGrantEnded represents a hold refused because the job would run past the end
+	// of a grant funding the account
+	ErrCodeGrantEnded ErrorCode = "GRANT_ENDED"
 	// ErrCodePartitionExceeded represents partition limit exceeded errors
 	ErrCodePartitionExceeded ErrorCode = "PARTITION_LIMIT_EXCEEDED"
 	// ErrCodeTransactionFailed represents transaction failure errors
 	ErrCodeTransactionFailed ErrorCode = "TRANSACTION_FAILED"
 	// ErrCodeDuplicateAccount represents duplicate account errors
 	ErrCodeDuplicateAccount ErrorCode = "DUPLICATE_ACCOUNT"
+	// ErrCodeDuplicateTransaction represents a transaction ID that is already in use
+	ErrCodeDuplicateTransaction ErrorCode = "DUPLICATE_TRANSACTION"
+	// ErrCodeInvalidStatusTransition represents a disallowed account status change
+	ErrCodeInvalidStatusTransition ErrorCode = "INVALID_STATUS_TRANSITION"
+	// ErrCodeHoldSettled represents a change to a hold that has been charged, released or
+	// cancelled
+	ErrCodeHoldSettled ErrorCode = "HOLD_SETTLED"
 
 	// ErrCodeServiceUnavailable represents service unavailable errors
 	ErrCodeServiceUnavailable ErrorCode = "SERVICE_UNAVAILABLE"
@@ -51,11 +66,56 @@ const (
 
 // BudgetError represents an error in the budget system
 type BudgetError struct {
+	Code             ErrorCode    `json:"code"`
+	Message          string       `json:"message"`
+	Details          string       `json:"details,omitempty"`
+	Field            string       `json:"field,omitempty"`
+	ValidationErrors []FieldError `json:"validation_errors,omitempty"` // Every invalid field when validation found several
+	// Every check a budget check failed, when budget.admission_failures reports them all
+	CheckFailures []CheckFailure `json:"check_failures,omitempty"`
+	Cause         error          `json:"-"`
+}
+
+// FieldError is one invalid field of a request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// CheckFailure is one check a budget check failed, named as the check is in the documented
+// evaluation order
+type CheckFailure struct {
+	Check   string    `json:"check"`
 	Code    ErrorCode `json:"code"`
 	Message string    `json:"message"`
-	Details string    `json:"details,omitempty"`
-	Field   string    `json:"field,omitempty"`
-	Cause   error     `json:"-"`
+}
+
+// ValidationErrors collects every invalid field of a request, so a client can fix them
+// all before resubmitting rather than discovering them one at a time
+type ValidationErrors []FieldError
+
+// Add records an invalid field
+func (v *ValidationErrors) Add(field, message string) {
+	*v = append(*v, FieldError{Field: field, Message: message})
+}
+
+// Err returns nil when no field was invalid. Otherwise it returns a validation error whose
+// Field and Message describe the first invalid field, for clients that read only one, and
+// whose ValidationErrors list them all.
+func (v ValidationErrors) Err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	err := NewValidationError(v[0].Field, v[0].Message)
+	err.ValidationErrors = v
+	if len(v) > 1 {
+		fields := make([]string, len(v))
+		for i, fe := range v {
+			fields[i] = fe.Field + " " + fe.Message
+		}
+		err.Details = fmt.Sprintf("%d invalid fields: %s", len(v), strings.Join(fields, "; "))
+	}
+	return err
 }
 
 // Error implements the error interface
@@ -82,9 +142,9 @@ func (e *BudgetError) HTTPStatus() int {
 		return http.StatusUnauthorized
 	case ErrCodeForbidden:
 		return http.StatusForbidden
-	case ErrCodeInsufficientBudget, ErrCodeAccountInactive, ErrCodeAccountExpired, ErrCodePartitionExceeded:
+	case ErrCodeInsufficientBudget, ErrCodeAccountInactive, ErrCodeAccountFrozen, ErrCodeAccountExpired, ErrCodeGrantEnded, ErrCodePartitionExceeded:
 		return http.StatusPaymentRequired
-	case ErrCodeDuplicateAccount:
+	case ErrCodeDuplicateAccount, ErrCodeDuplicateTransaction, ErrCodeInvalidStatusTransition, ErrCodeHoldSettled:
 		return http.StatusConflict
 	case ErrCodeServiceUnavailable, ErrCodeAdvisorUnavailable:
 		return http.StatusServiceUnavailable
@@ -102,6 +162,10 @@ type ErrorResponse struct {
 		Message string    `json:"message"`
 		Details string    `json:"details,omitempty"`
 		Field   string    `json:"field,omitempty"`
+		// Every invalid field of a request that failed validation
+		ValidationErrors []FieldError `json:"validation_errors,omitempty"`
+		// Every check a budget check failed, when all of them are reported
+		CheckFailures []CheckFailure `json:"check_failures,omitempty"`
 	} `json:"error"`
 	RequestID string `json:"request_id,omitempty"`
 	Timestamp string `json:"timestamp"`
@@ -167,6 +231,61 @@ func NewAccountInactiveError(account string, status string) *BudgetError {
 	}
 }
 
+// NewAccountFrozenError creates an error for a hold refused by a frozen account
+func NewAccountFrozenError(account string) *BudgetError {
+	return &BudgetError{
+		Code:    ErrCodeAccountFrozen,
+		Message: fmt.Sprintf("Account '%s' is frozen and not accepting new jobs", account),
+		Details: "Running jobs still reconcile; unfreeze the account to resume bursting",
+	}
+}
+
+// NewGrantEndedError creates an error for a hold refused because the job would run past the
+// end date of a grant funding the account
+func NewGrantEndedError(account, grantNumber string, endDate time.Time) *BudgetError {
+	return &BudgetError{
+		Code:    ErrCodeGrantEnded,
+		Message: fmt.Sprintf("Job on account '%s' would run past the end of grant %s", account, grantNumber),
+		Details: fmt.Sprintf("Grant ends %s; it may not incur charges after that", endDate.Format(time.RFC3339)),
+	}
+}
+
+// NewPartitionNotAllowedError creates an error for a hold on a partition the account may not use
+func NewPartitionNotAllowedError(account, partition string, allowed []string) *BudgetError {
+	return &BudgetError{
+		Code:    ErrCodeForbidden,
+		Message: fmt.Sprintf("Account '%s' may not submit jobs to partition '%s'", account, partition),
+		Details: fmt.Sprintf("Allowed partitions: %s", strings.Join(allowed, ", ")),
+	}
+}
+
+// NewNotAccountMemberError creates an error for a user acting on an account they are not a member of
+func NewNotAccountMemberError(account, user string) *BudgetError {
+	return &BudgetError{
+		Code:    ErrCodeForbidden,
+		Message: fmt.Sprintf("User '%s' is not a member of account '%s'", user, account),
+		Details: "Ask an administrator to add you with asbb account member add",
+	}
+}
+
+// NewInvalidStatusTransitionError creates an error for a disallowed account status change
+func NewInvalidStatusTransitionError(account, from, to string) *BudgetError {
+	return &BudgetError{
+		Code:    ErrCodeInvalidStatusTransition,
+		Message: fmt.Sprintf("Account '%s' cannot change status from %s to %s", account, from, to),
+		Field:   "status",
+	}
+}
+
+// NewHoldSettledError creates an error for a change to a hold that is no longer live
+func NewHoldSettledError(transactionID string) *BudgetError {
+	return &BudgetError{
+		Code:    ErrCodeHoldSettled,
+		Message: fmt.Sprintf("Hold %s has already been charged, released or cancelled", transactionID),
+		Field:   "transaction_id",
+	}
+}
+
 // NewPartitionLimitError creates a partition limit exceeded error
 func NewPartitionLimitError(account, partition string, required, available float64) *BudgetError {
 	return &BudgetError{
@@ -176,6 +295,16 @@ func NewPartitionLimitError(account, partition string, required, available float
 	}
 }
 
+// NewJobCostExceededError creates an error for a job estimated over its partition's
+// maximum single-job cost
+func NewJobCostExceededError(partition string, cost, limit float64) *BudgetError {
+	return &BudgetError{
+		Code: ErrCodeValidation,
+		Message: fmt.Sprintf("Estimated cost %.2f exceeds the maximum single-job cost of %.2f for partition %s; "+
+			"verify the job's time limit and resource requests", cost, limit, partition),
+	}
+}
+
 // NewServiceUnavailableError creates a service unavailable error
 func NewServiceUnavailableError(service string, cause error) *BudgetError {
 	return &BudgetError{
@@ -203,6 +332,15 @@ func NewTransactionFailedError(transactionID string, cause error) *BudgetError {
 	}
 }
 
+// NewDuplicateTransactionError creates an error for a transaction ID that already exists
+func NewDuplicateTransactionError(transactionID string, cause error) *BudgetError {
+	return &BudgetError{
+		Code:    ErrCodeDuplicateTransaction,
+		Message: fmt.Sprintf("Transaction ID %s already exists", transactionID),
+		Cause:   cause,
+	}
+}
+
 // Common error instances
 var (
 	ErrInternalServer = NewBudgetError(ErrCodeInternal, "Internal server error")
@@ -222,6 +360,13 @@ func AsBudgetError(err error) (*BudgetError, bool) {
 	return budgetErr, ok
 }
 
+// IsRetryable reports whether an operation that failed with err may succeed if retried,
+// such as an insert whose generated transaction ID collided with an existing one
+func IsRetryable(err error) bool {
+	var budgetErr *BudgetError
+	return errors.As(err, &budgetErr) && budgetErr.Code == ErrCodeDuplicateTransaction
+}
+
 // WrapError wraps a generic error as a BudgetError
 func WrapError(err error, code ErrorCode, message string) *BudgetError {
 	if budgetErr, ok := err.(*BudgetError); ok {
@@ -6,6 +6,7 @@ package api
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -67,9 +68,13 @@ func TestBudgetError_HTTPStatus(t *testing.T) {
 		{"forbidden", ErrCodeForbidden, http.StatusForbidden},
 		{"insufficient budget", ErrCodeInsufficientBudget, http.StatusPaymentRequired},
 		{"account inactive", ErrCodeAccountInactive, http.StatusPaymentRequired},
+		{"account frozen", ErrCodeAccountFrozen, http.StatusPaymentRequired},
 		{"account expired", ErrCodeAccountExpired, http.StatusPaymentRequired},
 		{"partition exceeded", ErrCodePartitionExceeded, http.StatusPaymentRequired},
 		{"duplicate account", ErrCodeDuplicateAccount, http.StatusConflict},
+		{"duplicate transaction", ErrCodeDuplicateTransaction, http.StatusConflict},
+		{"invalid status transition", ErrCodeInvalidStatusTransition, http.StatusConflict},
+		{"hold settled", ErrCodeHoldSettled, http.StatusConflict},
 		{"service unavailable", ErrCodeServiceUnavailable, http.StatusServiceUnavailable},
 		{"advisor unavailable", ErrCodeAdvisorUnavailable, http.StatusServiceUnavailable},
 		{"database error", ErrCodeDatabaseError, http.StatusInternalServerError},
@@ -114,6 +119,31 @@ func TestNewValidationError(t *testing.T) {
 	assert.Equal(t, "field_name", err.Field)
 }
 
+func TestValidationErrors_Err(t *testing.T) {
+	var none ValidationErrors
+	assert.NoError(t, none.Err())
+
+	var one ValidationErrors
+	one.Add("name", "is required")
+	budgetErr, ok := AsBudgetError(one.Err())
+	assert.True(t, ok)
+	assert.Equal(t, ErrCodeValidation, budgetErr.Code)
+	assert.Equal(t, "name", budgetErr.Field)
+	assert.Equal(t, "is required", budgetErr.Message)
+	assert.Empty(t, budgetErr.Details)
+	assert.Equal(t, []FieldError{{Field: "name", Message: "is required"}}, budgetErr.ValidationErrors)
+
+	var several ValidationErrors
+	several.Add("name", "is required")
+	several.Add("budget_limit", "must be greater than 0")
+	budgetErr, ok = AsBudgetError(several.Err())
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, budgetErr.HTTPStatus())
+	assert.Equal(t, "name", budgetErr.Field)
+	assert.Len(t, budgetErr.ValidationErrors, 2)
+	assert.Equal(t, "2 invalid fields: name is required; budget_limit must be greater than 0", budgetErr.Details)
+}
+
 func TestNewInsufficientBudgetError(t *testing.T) {
 	err := NewInsufficientBudgetError("proj001", 100.0, 50.0)
 
@@ -145,6 +175,14 @@ func TestNewPartitionLimitError(t *testing.T) {
 	assert.Equal(t, "Required: $100.00, Available: $50.00", err.Details)
 }
 
+func TestNewJobCostExceededError(t *testing.T) {
+	err := NewJobCostExceededError("gpu", 600.0, 400.0)
+
+	assert.Equal(t, ErrCodeValidation, err.Code)
+	assert.Equal(t, "Estimated cost 600.00 exceeds the maximum single-job cost of 400.00 for partition gpu; "+
+		"verify the job's time limit and resource requests", err.Message)
+}
+
 func TestNewServiceUnavailableError(t *testing.T) {
 	cause := errors.New("connection failed")
 	err := NewServiceUnavailableError("advisor", cause)
@@ -172,6 +210,55 @@ func TestNewTransactionFailedError(t *testing.T) {
 	assert.Equal(t, cause, err.Cause)
 }
 
+func TestNewDuplicateTransactionError(t *testing.T) {
+	cause := errors.New("unique violation")
+	err := NewDuplicateTransactionError("txn_123", cause)
+
+	assert.Equal(t, ErrCodeDuplicateTransaction, err.Code)
+	assert.Equal(t, "Transaction ID txn_123 already exists", err.Message)
+	assert.Equal(t, cause, err.Cause)
+}
+
+func TestNewAccountFrozenError(t *testing.T) {
+	err := NewAccountFrozenError("proj001")
+
+	assert.Equal(t, ErrCodeAccountFrozen, err.Code)
+	assert.Equal(t, "Account 'proj001' is frozen and not accepting new jobs", err.Message)
+}
+
+func TestNewPartitionNotAllowedError(t *testing.T) {
+	err := NewPartitionNotAllowedError("phys101", "gpu", []string{"cpu", "cpu-spot"})
+
+	assert.Equal(t, ErrCodeForbidden, err.Code)
+	assert.Equal(t, "Account 'phys101' may not submit jobs to partition 'gpu'", err.Message)
+	assert.Equal(t, "Allowed partitions: cpu, cpu-spot", err.Details)
+}
+
+func TestNewInvalidStatusTransitionError(t *testing.T) {
+	err := NewInvalidStatusTransitionError("proj001", "expired", "active")
+
+	assert.Equal(t, ErrCodeInvalidStatusTransition, err.Code)
+	assert.Equal(t, "Account 'proj001' cannot change status from expired to active", err.Message)
+	assert.Equal(t, "status", err.Field)
+}
+
+func TestNewHoldSettledError(t *testing.T) {
+	err := NewHoldSettledError("txn_123")
+
+	assert.Equal(t, ErrCodeHoldSettled, err.Code)
+	assert.Equal(t, http.StatusConflict, err.HTTPStatus())
+	assert.Equal(t, "transaction_id", err.Field)
+}
+
+func TestIsRetryable(t *testing.T) {
+	duplicate := NewDuplicateTransactionError("txn_123", errors.New("unique violation"))
+
+	assert.True(t, IsRetryable(duplicate))
+	assert.True(t, IsRetryable(fmt.Errorf("transaction failed: %w", duplicate)))
+	assert.False(t, IsRetryable(NewDatabaseError("create transaction", errors.New("connection reset"))))
+	assert.False(t, IsRetryable(errors.New("generic error")))
+}
+
 func TestIsBudgetError(t *testing.T) {
 	budgetErr := &BudgetError{Code: ErrCodeValidation, Message: "Test"}
 	genericErr := errors.New("generic error")
@@ -0,0 +1,176 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// FiscalYearStart is the month and day a fiscal year begins. The zero value is January 1st,
+// the calendar year.
+type FiscalYearStart struct {
+	Month time.Month
+	Day   int
+}
+
+// ParseFiscalYearStart parses a fiscal year start written as MM-DD, such as 07-01. An empty
+// value is the calendar year. Days are limited to 28 so every year and quarter has the date.
+func ParseFiscalYearStart(value string) (FiscalYearStart, error) {
+	if value == "" {
+		return FiscalYearStart{Month: time.January, Day: 1}, nil
+	}
+
+	if len(value) != 5 || value[2] != '-' {
+		return FiscalYearStart{}, fmt.Errorf("fiscal year start %q must be MM-DD", value)
+	}
+	month, monthErr := strconv.Atoi(value[:2])
+	day, dayErr := strconv.Atoi(value[3:])
+	if monthErr != nil || dayErr != nil {
+		return FiscalYearStart{}, fmt.Errorf("fiscal year start %q must be MM-DD", value)
+	}
+	if month < 1 || month > 12 {
+		return FiscalYearStart{}, fmt.Errorf("fiscal year start %q has an invalid month", value)
+	}
+	if day < 1 || day > 28 {
+		return FiscalYearStart{}, fmt.Errorf("fiscal year start %q must fall on day 1 to 28", value)
+	}
+	return FiscalYearStart{Month: time.Month(month), Day: day}, nil
+}
+
+// String formats the fiscal year start as MM-DD
+func (f FiscalYearStart) String() string {
+	month, day := f.normalized()
+	return fmt.Sprintf("%02d-%02d", int(month), day)
+}
+
+// IsCalendar reports whether the fiscal year is the calendar year
+func (f FiscalYearStart) IsCalendar() bool {
+	month, day := f.normalized()
+	return month == time.January && day == 1
+}
+
+// YearStart returns local midnight in loc of the start of the fiscal year containing t
+func (f FiscalYearStart) YearStart(t time.Time, loc *time.Location) time.Time {
+	month, day := f.normalized()
+	date := localDate(t, loc)
+	start := time.Date(date.Year(), month, day, 0, 0, 0, 0, loc)
+	if start.After(date) {
+		start = time.Date(date.Year()-1, month, day, 0, 0, 0, 0, loc)
+	}
+	return start
+}
+
+// Year returns the fiscal year containing t, named by the calendar year in which it ends:
+// with a July 1st start, FY2026 runs from July 1st 2025 to June 30th 2026
+func (f FiscalYearStart) Year(t time.Time, loc *time.Location) int {
+	start := f.YearStart(t, loc)
+	if f.IsCalendar() {
+		return start.Year()
+	}
+	return start.Year() + 1
+}
+
+// YearBounds returns local midnight in loc at the start and end of a fiscal year, the end
+// being the start of the following year
+func (f FiscalYearStart) YearBounds(fiscalYear int, loc *time.Location) (start, end time.Time) {
+	month, day := f.normalized()
+	startYear := fiscalYear
+	if !f.IsCalendar() {
+		startYear--
+	}
+	start = time.Date(startYear, month, day, 0, 0, 0, 0, loc)
+	return start, start.AddDate(1, 0, 0)
+}
+
+// QuarterBounds returns the fiscal quarter containing t, numbered 1 to 4, and its start
+// and end as local midnights in loc
+func (f FiscalYearStart) QuarterBounds(t time.Time, loc *time.Location) (quarter int, start, end time.Time) {
+	yearStart := f.YearStart(t, loc)
+	date := localDate(t, loc)
+	for quarter = 1; quarter < 4; quarter++ {
+		if yearStart.AddDate(0, quarter*3, 0).After(date) {
+			break
+		}
+	}
+	return quarter, yearStart.AddDate(0, (quarter-1)*3, 0), yearStart.AddDate(0, quarter*3, 0)
+}
+
+// QuarterLabel names the fiscal quarter containing t, such as FY2026 Q1
+func (f FiscalYearStart) QuarterLabel(t time.Time, loc *time.Location) string {
+	quarter, _, _ := f.QuarterBounds(t, loc)
+	return fmt.Sprintf("FY%d Q%d", f.Year(t, loc), quarter)
+}
+
+// NextAllocationDate advances an allocation date by one frequency step. Quarterly and
+// yearly steps land on the next fiscal quarter or year boundary, at local midnight, so a
+// schedule started mid-quarter falls into line; other frequencies step as
+// NextAllocationDate does. This mirrors calculate_next_allocation_date when the account
+// has a fiscal year start.
+func (f FiscalYearStart) NextAllocationDate(current time.Time, frequency string, loc *time.Location) (time.Time, error) {
+	var months int
+	switch frequency {
+	case "quarterly":
+		months = 3
+	case "yearly":
+		months = 12
+	default:
+		return NextAllocationDate(current, frequency, loc)
+	}
+
+	local := current.In(loc)
+	yearStart := f.YearStart(local, loc)
+	for step := months; ; step += months {
+		if next := yearStart.AddDate(0, step, 0); next.After(local) {
+			return next, nil
+		}
+	}
+}
+
+// AllocationPeriod returns the period an allocation made at t covers, as local midnights
+// in loc: the day, the ISO week from Monday, the calendar month, or the fiscal quarter or
+// year containing t. This mirrors allocation_period_bounds.
+func (f FiscalYearStart) AllocationPeriod(t time.Time, frequency string, loc *time.Location) (start, end time.Time, err error) {
+	date := localDate(t, loc)
+	switch frequency {
+	case "daily":
+		return date, date.AddDate(0, 0, 1), nil
+	case "weekly":
+		start = date.AddDate(0, 0, -((int(date.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7), nil
+	case "monthly":
+		start = time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0), nil
+	case "quarterly":
+		_, start, end = f.QuarterBounds(t, loc)
+		return start, end, nil
+	case "yearly":
+		start = f.YearStart(t, loc)
+		return start, start.AddDate(1, 0, 0), nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid allocation frequency: %s", frequency)
+	}
+}
+
+// RemainingShare returns the share of t's allocation period left from t's date on,
+// counted in calendar days, and the period's end. A prorated first allocation is this
+// share of the full amount.
+func (f FiscalYearStart) RemainingShare(t time.Time, frequency string, loc *time.Location) (float64, time.Time, error) {
+	start, end, err := f.AllocationPeriod(t, frequency, loc)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	left := calendarDaysBetween(t, end, loc)
+	return float64(left) / float64(calendarDaysBetween(start, end, loc)), end, nil
+}
+
+// normalized treats the zero value as January 1st
+func (f FiscalYearStart) normalized() (time.Month, int) {
+	if f.Month == 0 {
+		return time.January, 1
+	}
+	return f.Month, f.Day
+}
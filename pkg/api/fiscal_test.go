@@ -0,0 +1,177 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFiscalYearStart(t *testing.T) {
+	july, err := ParseFiscalYearStart("07-01")
+	require.NoError(t, err)
+	assert.Equal(t, FiscalYearStart{Month: time.July, Day: 1}, july)
+	assert.Equal(t, "07-01", july.String())
+	assert.False(t, july.IsCalendar())
+
+	calendar, err := ParseFiscalYearStart("")
+	require.NoError(t, err)
+	assert.True(t, calendar.IsCalendar())
+	assert.Equal(t, "01-01", FiscalYearStart{}.String())
+
+	for _, value := range []string{"7-01", "07/01", "13-01", "00-10", "02-29", "07-00", "ab-cd"} {
+		_, err := ParseFiscalYearStart(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestFiscalYearStart_JulyYear(t *testing.T) {
+	july := FiscalYearStart{Month: time.July, Day: 1}
+	utc := time.UTC
+
+	tests := []struct {
+		date      time.Time
+		year      int
+		yearStart time.Time
+		quarter   int
+		label     string
+	}{
+		{time.Date(2025, 7, 1, 0, 0, 0, 0, utc), 2026, time.Date(2025, 7, 1, 0, 0, 0, 0, utc), 1, "FY2026 Q1"},
+		{time.Date(2025, 9, 30, 23, 0, 0, 0, utc), 2026, time.Date(2025, 7, 1, 0, 0, 0, 0, utc), 1, "FY2026 Q1"},
+		{time.Date(2025, 10, 1, 0, 0, 0, 0, utc), 2026, time.Date(2025, 7, 1, 0, 0, 0, 0, utc), 2, "FY2026 Q2"},
+		{time.Date(2026, 1, 15, 0, 0, 0, 0, utc), 2026, time.Date(2025, 7, 1, 0, 0, 0, 0, utc), 3, "FY2026 Q3"},
+		{time.Date(2026, 6, 30, 12, 0, 0, 0, utc), 2026, time.Date(2025, 7, 1, 0, 0, 0, 0, utc), 4, "FY2026 Q4"},
+		{time.Date(2025, 6, 30, 12, 0, 0, 0, utc), 2025, time.Date(2024, 7, 1, 0, 0, 0, 0, utc), 4, "FY2025 Q4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.date.Format(time.RFC3339), func(t *testing.T) {
+			assert.Equal(t, tt.year, july.Year(tt.date, utc))
+			assert.True(t, tt.yearStart.Equal(july.YearStart(tt.date, utc)))
+			quarter, start, end := july.QuarterBounds(tt.date, utc)
+			assert.Equal(t, tt.quarter, quarter)
+			assert.False(t, tt.date.Before(start))
+			assert.True(t, tt.date.Before(end))
+			assert.Equal(t, tt.label, july.QuarterLabel(tt.date, utc))
+		})
+	}
+
+	start, end := july.YearBounds(2026, utc)
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, utc), start)
+	assert.Equal(t, time.Date(2026, 7, 1, 0, 0, 0, 0, utc), end)
+}
+
+func TestFiscalYearStart_CalendarYear(t *testing.T) {
+	calendar := FiscalYearStart{}
+	date := time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 2025, calendar.Year(date, time.UTC))
+	assert.Equal(t, "FY2025 Q3", calendar.QuarterLabel(date, time.UTC))
+
+	start, end := calendar.YearBounds(2025, time.UTC)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestFiscalYearStart_LocalBoundaries(t *testing.T) {
+	eastern := mustLoad(t, "America/New_York")
+	july := FiscalYearStart{Month: time.July, Day: 1}
+
+	// 02:00 UTC on July 1st is still June 30th in New York
+	instant := time.Date(2025, 7, 1, 2, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2025, july.Year(instant, eastern))
+	assert.Equal(t, 2026, july.Year(instant, time.UTC))
+
+	start := july.YearStart(time.Date(2025, 8, 1, 0, 0, 0, 0, eastern), eastern)
+	assert.True(t, time.Date(2025, 7, 1, 0, 0, 0, 0, eastern).Equal(start))
+}
+
+func TestFiscalYearStart_NextAllocationDate(t *testing.T) {
+	july := FiscalYearStart{Month: time.July, Day: 1}
+	october := FiscalYearStart{Month: time.October, Day: 1}
+	utc := time.UTC
+
+	tests := []struct {
+		name      string
+		fiscal    FiscalYearStart
+		current   time.Time
+		frequency string
+		expected  time.Time
+	}{
+		{"yearly on the boundary", july, time.Date(2025, 7, 1, 0, 0, 0, 0, utc), "yearly", time.Date(2026, 7, 1, 0, 0, 0, 0, utc)},
+		{"yearly mid-year aligns", july, time.Date(2025, 9, 15, 0, 0, 0, 0, utc), "yearly", time.Date(2026, 7, 1, 0, 0, 0, 0, utc)},
+		{"yearly before the start aligns", july, time.Date(2025, 3, 1, 0, 0, 0, 0, utc), "yearly", time.Date(2025, 7, 1, 0, 0, 0, 0, utc)},
+		{"quarterly on the boundary", july, time.Date(2025, 10, 1, 0, 0, 0, 0, utc), "quarterly", time.Date(2026, 1, 1, 0, 0, 0, 0, utc)},
+		{"quarterly mid-quarter aligns", july, time.Date(2025, 8, 15, 0, 0, 0, 0, utc), "quarterly", time.Date(2025, 10, 1, 0, 0, 0, 0, utc)},
+		{"quarterly across the year end", july, time.Date(2026, 5, 2, 0, 0, 0, 0, utc), "quarterly", time.Date(2026, 7, 1, 0, 0, 0, 0, utc)},
+		{"october quarters", october, time.Date(2025, 11, 20, 0, 0, 0, 0, utc), "quarterly", time.Date(2026, 1, 1, 0, 0, 0, 0, utc)},
+		{"monthly is unaffected", july, time.Date(2025, 8, 15, 0, 0, 0, 0, utc), "monthly", time.Date(2025, 9, 15, 0, 0, 0, 0, utc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := tt.fiscal.NextAllocationDate(tt.current, tt.frequency, utc)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(next), "expected %s, got %s", tt.expected, next)
+		})
+	}
+
+	_, err := july.NextAllocationDate(time.Now(), "hourly", utc)
+	assert.Error(t, err)
+}
+
+func TestFiscalYearStart_AllocationPeriod(t *testing.T) {
+	july := FiscalYearStart{Month: time.July, Day: 1}
+	// Wednesday January 15th 2025, mid-afternoon
+	at := time.Date(2025, 1, 15, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		frequency  string
+		fiscal     FiscalYearStart
+		start, end time.Time
+	}{
+		{"daily", FiscalYearStart{}, time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"weekly", FiscalYearStart{}, time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{"monthly", FiscalYearStart{}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"quarterly", FiscalYearStart{}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"yearly", july, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.frequency, func(t *testing.T) {
+			start, end, err := tt.fiscal.AllocationPeriod(at, tt.frequency, time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+		})
+	}
+
+	_, _, err := july.AllocationPeriod(at, "hourly", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestFiscalYearStart_RemainingShare(t *testing.T) {
+	calendar := FiscalYearStart{}
+
+	// January 15th to February 1st is 17 of January's 31 days
+	share, end, err := calendar.RemainingShare(time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC), "monthly", time.UTC)
+	require.NoError(t, err)
+	assert.InDelta(t, 17.0/31.0, share, 1e-9)
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), end)
+
+	share, _, err = calendar.RemainingShare(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "monthly", time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, share)
+
+	// Days are counted in the account's time zone across the March DST change
+	eastern, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	share, end, err = calendar.RemainingShare(time.Date(2025, 3, 20, 0, 0, 0, 0, eastern), "monthly", eastern)
+	require.NoError(t, err)
+	assert.InDelta(t, 12.0/31.0, share, 1e-9)
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, eastern), end)
+}
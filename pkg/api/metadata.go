@@ -0,0 +1,313 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// TransactionMetadataVersion is the metadata schema version this build writes. Readers
+// accept newer versions, ignoring fields they do not know, so metadata written by a newer
+// service still decodes.
+const TransactionMetadataVersion = 1
+
+// TransactionMetadata is the typed metadata recorded with a transaction of one type
+type TransactionMetadata interface {
+	// TransactionType is the type of transaction the metadata belongs to
+	TransactionType() string
+	// Validate checks the metadata against its schema
+	Validate() error
+	setVersion(version int)
+}
+
+// Reasons a refund is issued
+const (
+	RefundReasonReconciled = "reconciled" // the job cost less than its hold
+	RefundReasonRecovered  = "recovered"  // an orphaned hold was cancelled
+)
+
+// MetadataVersion carries the schema version every transaction's metadata starts with
+type MetadataVersion struct {
+	Version int `json:"version"`
+}
+
+func (m *MetadataVersion) setVersion(version int) {
+	m.Version = version
+}
+
+func (m *MetadataVersion) validateVersion() error {
+	if m.Version < 1 {
+		return fmt.Errorf("version must be at least 1")
+	}
+	return nil
+}
+
+// HoldMetadata records how a hold was sized
+type HoldMetadata struct {
+	MetadataVersion
+	Partition      string  `json:"partition"`
+	EstimatedCost  float64 `json:"estimated_cost"`
+	HoldPercentage float64 `json:"hold_percentage"`
+	// HoldPercentageSource is where HoldPercentage came from, such as gpu for a GPU job
+	HoldPercentageSource string `json:"hold_percentage_source,omitempty"`
+	ResearchDomain       string `json:"research_domain,omitempty"`
+	FailureMode          string `json:"failure_mode,omitempty"` // Set when the advisor was unavailable
+	// AdvisorEstimate is the advisor's own estimate, set when it diverged so far from the
+	// fallback estimate that EstimatedCost was raised
+	AdvisorEstimate float64 `json:"advisor_estimate,omitempty"`
+	// ScriptHash identifies the job's normalized script, so its actual cost is recorded
+	// against the script when it is reconciled
+	ScriptHash string `json:"script_hash,omitempty"`
+	// The job's resource shape, so later jobs of the same shape can be estimated from what
+	// it actually cost
+	Nodes int `json:"nodes,omitempty"`
+	CPUs  int `json:"cpus,omitempty"`
+	GPUs  int `json:"gpus,omitempty"`
+}
+
+// TransactionType implements TransactionMetadata
+func (m *HoldMetadata) TransactionType() string { return "hold" }
+
+// Validate implements TransactionMetadata
+func (m *HoldMetadata) Validate() error {
+	if err := m.validateVersion(); err != nil {
+		return err
+	}
+	if m.Partition == "" {
+		return fmt.Errorf("partition is required")
+	}
+	if m.EstimatedCost < 0 {
+		return fmt.Errorf("estimated_cost must not be negative")
+	}
+	if m.HoldPercentage < 0 {
+		return fmt.Errorf("hold_percentage must not be negative")
+	}
+	if m.AdvisorEstimate < 0 {
+		return fmt.Errorf("advisor_estimate must not be negative")
+	}
+	if m.Nodes < 0 || m.CPUs < 0 || m.GPUs < 0 {
+		return fmt.Errorf("nodes, cpus and gpus must not be negative")
+	}
+	return nil
+}
+
+// JobOutcome describes how a job ended and what it reported costing, for the charges and
+// refunds that reconcile it
+type JobOutcome struct {
+	ReportedCost    float64      `json:"reported_cost"`
+	HeldAmount      float64      `json:"held_amount"`
+	JobState        string       `json:"job_state,omitempty"`
+	FailedJobPolicy string       `json:"failed_job_policy,omitempty"`
+	Job             *JobMetadata `json:"job,omitempty"` // What the job reported about itself, e.g. from ASBX
+	// ReconciliationID links the charges and refunds of one ASBX reconciliation
+	ReconciliationID string `json:"reconciliation_id,omitempty"`
+	// AfterGrantEnd lists the grants funding the account that had ended when the job
+	// completed
+	AfterGrantEnd []string `json:"after_grant_end,omitempty"`
+	// ChargedAtEstimate is set when nothing reconciled the job, so recovery charged its
+	// estimate under the CHARGE expired hold policy
+	ChargedAtEstimate bool `json:"charged_at_estimate,omitempty"`
+}
+
+func (o *JobOutcome) validate() error {
+	if o.ReportedCost < 0 {
+		return fmt.Errorf("reported_cost must not be negative")
+	}
+	if o.HeldAmount < 0 {
+		return fmt.Errorf("held_amount must not be negative")
+	}
+	switch o.FailedJobPolicy {
+	case "":
+	case FailedJobPolicyChargeActual, FailedJobPolicyFullRefund:
+		if o.JobState == "" {
+			return fmt.Errorf("job_state is required with a failed_job_policy")
+		}
+	default:
+		return fmt.Errorf("failed_job_policy must be %s or %s", FailedJobPolicyChargeActual, FailedJobPolicyFullRefund)
+	}
+	if o.Job != nil {
+		return o.Job.Validate()
+	}
+	return nil
+}
+
+// ChargeMetadata records the job a charge reconciles
+type ChargeMetadata struct {
+	MetadataVersion
+	JobOutcome
+	// Unreserved marks a retroactive charge for a job that ran without a budget check
+	Unreserved bool `json:"unreserved,omitempty"`
+}
+
+// TransactionType implements TransactionMetadata
+func (m *ChargeMetadata) TransactionType() string { return "charge" }
+
+// Validate implements TransactionMetadata
+func (m *ChargeMetadata) Validate() error {
+	if err := m.validateVersion(); err != nil {
+		return err
+	}
+	return m.validate()
+}
+
+// RefundMetadata records why a hold was refunded. A refund for a reconciled job also
+// describes the job; a recovered hold has no job outcome.
+type RefundMetadata struct {
+	MetadataVersion
+	Reason string `json:"reason"`
+	*JobOutcome
+}
+
+// TransactionType implements TransactionMetadata
+func (m *RefundMetadata) TransactionType() string { return "refund" }
+
+// Validate implements TransactionMetadata
+func (m *RefundMetadata) Validate() error {
+	if err := m.validateVersion(); err != nil {
+		return err
+	}
+	switch m.Reason {
+	case RefundReasonReconciled:
+		if m.JobOutcome == nil {
+			return fmt.Errorf("a reconciled refund must describe the job")
+		}
+		return m.validate()
+	case RefundReasonRecovered:
+		return nil
+	default:
+		return fmt.Errorf("reason must be %s or %s", RefundReasonReconciled, RefundReasonRecovered)
+	}
+}
+
+// AdjustmentMetadata records where an administrative adjustment drew its budget from
+type AdjustmentMetadata struct {
+	MetadataVersion
+	FromReserve bool `json:"from_reserve"`
+}
+
+// TransactionType implements TransactionMetadata
+func (m *AdjustmentMetadata) TransactionType() string { return "adjustment" }
+
+// Validate implements TransactionMetadata
+func (m *AdjustmentMetadata) Validate() error {
+	return m.validateVersion()
+}
+
+// JobMetadata is what a job reports about its own run when it is reconciled
+type JobMetadata struct {
+	ASBXJobID        string   `json:"asbx_job_id,omitempty"`
+	BurstDecision    string   `json:"burst_decision,omitempty"`
+	InstanceTypes    []string `json:"instance_types,omitempty"`
+	CPUEfficiency    float64  `json:"cpu_efficiency,omitempty"`
+	MemoryEfficiency float64  `json:"memory_efficiency,omitempty"`
+}
+
+// Validate checks a job's reported metadata
+func (m *JobMetadata) Validate() error {
+	if m.CPUEfficiency < 0 || m.MemoryEfficiency < 0 {
+		return fmt.Errorf("job efficiencies must not be negative")
+	}
+	return nil
+}
+
+// ParseJobMetadata decodes the job metadata sent with a reconciliation. Empty metadata is
+// nil; anything else must be a JSON object, and keys the schema does not know are dropped.
+func ParseJobMetadata(raw string) (*JobMetadata, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	var job JobMetadata
+	if err := decodeObject(raw, &job); err != nil {
+		return nil, err
+	}
+	if err := job.Validate(); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// String encodes job metadata as the JSON a reconciliation sends
+func (m *JobMetadata) String() string {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// newTransactionMetadata returns empty metadata of a transaction type's schema, or nil for
+// a type that carries no metadata
+func newTransactionMetadata(transactionType string) TransactionMetadata {
+	switch transactionType {
+	case "hold":
+		return &HoldMetadata{}
+	case "charge":
+		return &ChargeMetadata{}
+	case "refund":
+		return &RefundMetadata{}
+	case "adjustment":
+		return &AdjustmentMetadata{}
+	}
+	return nil
+}
+
+// EncodeTransactionMetadata validates metadata and serializes it at the current schema
+// version, for the metadata column of a transaction of the metadata's type
+func EncodeTransactionMetadata(metadata TransactionMetadata) (string, error) {
+	metadata.setVersion(TransactionMetadataVersion)
+	if err := metadata.Validate(); err != nil {
+		return "", fmt.Errorf("invalid %s metadata: %w", metadata.TransactionType(), err)
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("encode %s metadata: %w", metadata.TransactionType(), err)
+	}
+	return string(data), nil
+}
+
+// DecodeTransactionMetadata parses a transaction's metadata into its type's schema. It
+// returns nil for empty metadata and for types that carry none.
+func DecodeTransactionMetadata(transactionType, raw string) (TransactionMetadata, error) {
+	metadata := newTransactionMetadata(transactionType)
+	if raw == "" || metadata == nil {
+		return nil, nil
+	}
+	if err := decodeObject(raw, metadata); err != nil {
+		return nil, fmt.Errorf("decode %s metadata: %w", transactionType, err)
+	}
+	return metadata, nil
+}
+
+// ValidateTransactionMetadata checks a transaction's metadata against its type's schema.
+// Empty metadata is valid for every type; a type without a schema must have none.
+func ValidateTransactionMetadata(transactionType, raw string) error {
+	if raw == "" {
+		return nil
+	}
+	if newTransactionMetadata(transactionType) == nil {
+		return fmt.Errorf("%s transactions carry no metadata", transactionType)
+	}
+	metadata, err := DecodeTransactionMetadata(transactionType, raw)
+	if err != nil {
+		return err
+	}
+	if err := metadata.Validate(); err != nil {
+		return fmt.Errorf("invalid %s metadata: %w", transactionType, err)
+	}
+	return nil
+}
+
+// decodeObject decodes a JSON object into v, refusing any other JSON value
+func decodeObject(raw string, v interface{}) error {
+	if trimmed := bytes.TrimSpace([]byte(raw)); len(trimmed) == 0 || trimmed[0] != '{' {
+		return fmt.Errorf("must be a JSON object")
+	}
+	if err := json.Unmarshal([]byte(raw), v); err != nil {
+		return fmt.Errorf("must be a JSON object: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransactionMetadata_Schemas(t *testing.T) {
+	outcome := JobOutcome{ReportedCost: 9.5, HeldAmount: 12, JobState: "COMPLETED", Job: &JobMetadata{ASBXJobID: "asbx-1"}}
+
+	tests := []struct {
+		name     string
+		metadata TransactionMetadata
+		wantErr  bool
+	}{
+		{name: "hold", metadata: &HoldMetadata{Partition: "aws", EstimatedCost: 10, HoldPercentage: 1.2}},
+		{name: "hold fallback estimate", metadata: &HoldMetadata{Partition: "aws", EstimatedCost: 10, HoldPercentage: 1.2, FailureMode: "advisor_unavailable"}},
+		{name: "hold without partition", metadata: &HoldMetadata{EstimatedCost: 10}, wantErr: true},
+		{name: "hold negative estimate", metadata: &HoldMetadata{Partition: "aws", EstimatedCost: -1}, wantErr: true},
+		{name: "hold raised from advisor estimate", metadata: &HoldMetadata{Partition: "aws", EstimatedCost: 10, HoldPercentage: 1.2, AdvisorEstimate: 0.5}},
+		{name: "hold negative advisor estimate", metadata: &HoldMetadata{Partition: "aws", EstimatedCost: 10, AdvisorEstimate: -1}, wantErr: true},
+		{name: "charge", metadata: &ChargeMetadata{JobOutcome: outcome}},
+		{name: "charge failed job", metadata: &ChargeMetadata{JobOutcome: JobOutcome{ReportedCost: 1, JobState: "FAILED", FailedJobPolicy: FailedJobPolicyChargeActual}}},
+		{name: "charge policy without state", metadata: &ChargeMetadata{JobOutcome: JobOutcome{FailedJobPolicy: FailedJobPolicyFullRefund}}, wantErr: true},
+		{name: "charge unknown policy", metadata: &ChargeMetadata{JobOutcome: JobOutcome{JobState: "FAILED", FailedJobPolicy: "waive"}}, wantErr: true},
+		{name: "charge negative cost", metadata: &ChargeMetadata{JobOutcome: JobOutcome{ReportedCost: -1}}, wantErr: true},
+		{name: "charge negative efficiency", metadata: &ChargeMetadata{JobOutcome: JobOutcome{Job: &JobMetadata{CPUEfficiency: -0.1}}}, wantErr: true},
+		{name: "reconciled refund", metadata: &RefundMetadata{Reason: RefundReasonReconciled, JobOutcome: &outcome}},
+		{name: "reconciled refund without job", metadata: &RefundMetadata{Reason: RefundReasonReconciled}, wantErr: true},
+		{name: "recovered refund", metadata: &RefundMetadata{Reason: RefundReasonRecovered}},
+		{name: "refund without reason", metadata: &RefundMetadata{}, wantErr: true},
+		{name: "adjustment", metadata: &AdjustmentMetadata{FromReserve: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := EncodeTransactionMetadata(tt.metadata)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, ValidateTransactionMetadata(tt.metadata.TransactionType(), encoded))
+
+			var fields map[string]interface{}
+			require.NoError(t, json.Unmarshal([]byte(encoded), &fields))
+			assert.Equal(t, float64(TransactionMetadataVersion), fields["version"])
+
+			decoded, err := DecodeTransactionMetadata(tt.metadata.TransactionType(), encoded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.metadata, decoded)
+		})
+	}
+}
+
+func TestValidateTransactionMetadata(t *testing.T) {
+	assert.NoError(t, ValidateTransactionMetadata("hold", ""))
+	assert.NoError(t, ValidateTransactionMetadata("allocation", ""))
+
+	// Metadata from a newer service still validates; fields this build does not know are ignored
+	assert.NoError(t, ValidateTransactionMetadata("hold", `{"version":2,"partition":"aws","estimated_cost":10,"hold_percentage":1.2,"spot":true}`))
+
+	assert.Error(t, ValidateTransactionMetadata("allocation", `{"version":1}`))
+	assert.Error(t, ValidateTransactionMetadata("hold", `{"partition":"aws"}`), "version is required")
+	assert.Error(t, ValidateTransactionMetadata("charge", `[1,2]`))
+	assert.Error(t, ValidateTransactionMetadata("charge", `{"version":1`))
+	assert.Error(t, ValidateTransactionMetadata("refund", `{"version":1,"reason":"waived"}`))
+	assert.Error(t, ValidateTransactionMetadata("charge", `{"version":1,"reported_cost":"ten"}`))
+}
+
+func TestParseJobMetadata(t *testing.T) {
+	job, err := ParseJobMetadata("")
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	job, err = ParseJobMetadata(`{"asbx_job_id":"asbx-1","instance_types":["c5.large"],"cpu_efficiency":0.8,"extra":1}`)
+	require.NoError(t, err)
+	assert.Equal(t, &JobMetadata{ASBXJobID: "asbx-1", InstanceTypes: []string{"c5.large"}, CPUEfficiency: 0.8}, job)
+	assert.JSONEq(t, `{"asbx_job_id":"asbx-1","instance_types":["c5.large"],"cpu_efficiency":0.8}`, job.String())
+
+	for _, raw := range []string{`"asbx-1"`, `{"cpu_efficiency":`, `{"memory_efficiency":-1}`} {
+		_, err := ParseJobMetadata(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestJobReconcileRequest_Validate_JobMetadata(t *testing.T) {
+	req := &JobReconcileRequest{JobID: "12345", JobMetadata: `{"asbx_job_id":"asbx-1"}`}
+	assert.NoError(t, req.Validate())
+
+	req.JobMetadata = "not json"
+	budgetErr, ok := AsBudgetError(req.Validate())
+	require.True(t, ok)
+	assert.Equal(t, "job_metadata", budgetErr.Field)
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+)
+
+// Currency is the currency the service's amounts are denominated in, and how many decimal
+// places they are written with
+type Currency struct {
+	Code     string `json:"code"`     // ISO 4217, e.g. USD
+	Decimals int    `json:"decimals"` // 2 for USD, 0 for JPY
+}
+
+// DefaultCurrency is used until SetCurrency is called
+var DefaultCurrency = Currency{Code: "USD", Decimals: 2}
+
+// MaxCurrencyDecimals is the most decimal places amounts may be written with
+const MaxCurrencyDecimals = 4
+
+var currency atomic.Pointer[Currency]
+
+// SetCurrency sets the currency amounts are written in. The service sets it once at
+// startup from budget.currency and budget.currency_decimals.
+func SetCurrency(c Currency) {
+	currency.Store(&c)
+}
+
+// CurrentCurrency returns the currency amounts are written in
+func CurrentCurrency() Currency {
+	if c := currency.Load(); c != nil {
+		return *c
+	}
+	return DefaultCurrency
+}
+
+// ServiceUnitCode is reported as the currency of an account denominated in service units
+const ServiceUnitCode = "SU"
+
+// unitCurrency returns the code of the currency an account's amounts are in: SU for an
+// account in service units, otherwise the service's currency
+func unitCurrency(unit string) string {
+	if unit == BudgetUnitSU {
+		return ServiceUnitCode
+	}
+	return CurrentCurrency().Code
+}
+
+// ValidCurrencyCode reports whether code looks like an ISO 4217 code: three upper-case
+// letters
+func ValidCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// Money is an amount of the service's currency. It is a float64 for arithmetic, but is
+// always written to JSON with the currency's decimal places, so 0.1+0.2 is written 0.30
+// rather than 0.30000000000000004. Response types keep their amounts as float64 and
+// encode them as Money in their MarshalJSON methods.
+type Money float64
+
+// MarshalJSON writes the amount rounded to the current currency's decimal places, with
+// exact ties rounded to even
+func (m Money) MarshalJSON() ([]byte, error) {
+	v := float64(m)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("json: unsupported money value: %v", v)
+	}
+	decimals := CurrentCurrency().Decimals
+	// An amount that rounds to zero is written without a sign
+	if math.Abs(v) < 0.5*math.Pow10(-decimals) {
+		v = 0
+	}
+	return strconv.AppendFloat(nil, v, 'f', decimals, 64), nil
+}
+
+// moneyPtr converts an optional amount
+func moneyPtr(v *float64) *Money {
+	if v == nil {
+		return nil
+	}
+	m := Money(*v)
+	return &m
+}
+
+// moneyMap converts amounts keyed by name, such as a cost breakdown
+func moneyMap(v map[string]float64) map[string]Money {
+	if v == nil {
+		return nil
+	}
+	m := make(map[string]Money, len(v))
+	for k, amount := range v {
+		m[k] = Money(amount)
+	}
+	return m
+}
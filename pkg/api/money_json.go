@@ -0,0 +1,1048 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import "encoding/json"
+
+// The MarshalJSON methods below write each response type's amounts as Money, so they
+// always carry the currency's decimal places. Each encodes a copy of the value whose
+// amount fields are shadowed by Money fields of the same JSON name; the copy's type has
+// no MarshalJSON of its own, so it is encoded field by field. Percentages, ratios and
+// scores are left as they are. A type embedded in another must not get a MarshalJSON,
+// since the embedding type would inherit it; OverviewTotals is encoded by the overviews.
+
+// MarshalJSON writes the account's amounts as Money, along with the currency they are in
+func (a BudgetAccount) MarshalJSON() ([]byte, error) {
+	type budgetAccount BudgetAccount
+	return json.Marshal(struct {
+		budgetAccount
+		BudgetLimit    Money  `json:"budget_limit"`
+		BudgetUsed     Money  `json:"budget_used"`
+		BudgetHeld     Money  `json:"budget_held"`
+		TotalAllocated Money  `json:"total_allocated"`
+		ReservedAmount Money  `json:"reserved_amount"`
+		Currency       string `json:"currency"`
+	}{
+		budgetAccount(a),
+		Money(a.BudgetLimit),
+		Money(a.BudgetUsed),
+		Money(a.BudgetHeld),
+		Money(a.TotalAllocated),
+		Money(a.ReservedAmount),
+		unitCurrency(a.BudgetUnit),
+	})
+}
+
+// MarshalJSON writes the user's view of an account's amounts as Money, along with the
+// currency they are in
+func (a UserAccount) MarshalJSON() ([]byte, error) {
+	type userAccount UserAccount
+	return json.Marshal(struct {
+		userAccount
+		BudgetLimit     Money  `json:"budget_limit"`
+		BudgetUsed      Money  `json:"budget_used"`
+		BudgetHeld      Money  `json:"budget_held"`
+		BudgetAvailable Money  `json:"budget_available"`
+		Currency        string `json:"currency"`
+	}{
+		userAccount(a),
+		Money(a.BudgetLimit),
+		Money(a.BudgetUsed),
+		Money(a.BudgetHeld),
+		Money(a.BudgetAvailable),
+		unitCurrency(a.BudgetUnit),
+	})
+}
+
+// MarshalJSON writes the transaction's amounts as Money
+func (t BudgetTransaction) MarshalJSON() ([]byte, error) {
+	type budgetTransaction BudgetTransaction
+	return json.Marshal(struct {
+		budgetTransaction
+		Amount         Money            `json:"amount"`
+		FullHoldAmount *Money           `json:"full_hold_amount,omitempty"`
+		CostBreakdown  map[string]Money `json:"cost_breakdown,omitempty"`
+	}{
+		budgetTransaction(t),
+		Money(t.Amount),
+		moneyPtr(t.FullHoldAmount),
+		moneyMap(t.CostBreakdown),
+	})
+}
+
+// MarshalJSON writes the partition limit's amounts as Money
+func (l BudgetPartitionLimit) MarshalJSON() ([]byte, error) {
+	type budgetPartitionLimit BudgetPartitionLimit
+	return json.Marshal(struct {
+		budgetPartitionLimit
+		Limit Money `json:"limit"`
+		Used  Money `json:"used"`
+		Held  Money `json:"held"`
+	}{
+		budgetPartitionLimit(l),
+		Money(l.Limit),
+		Money(l.Used),
+		Money(l.Held),
+	})
+}
+
+// MarshalJSON writes the schedule's amounts as Money
+func (s BudgetAllocationSchedule) MarshalJSON() ([]byte, error) {
+	type budgetAllocationSchedule BudgetAllocationSchedule
+	return json.Marshal(struct {
+		budgetAllocationSchedule
+		TotalBudget      Money `json:"total_budget"`
+		AllocationAmount Money `json:"allocation_amount"`
+		AllocatedToDate  Money `json:"allocated_to_date"`
+		RemainingBudget  Money `json:"remaining_budget"`
+	}{
+		budgetAllocationSchedule(s),
+		Money(s.TotalBudget),
+		Money(s.AllocationAmount),
+		Money(s.AllocatedToDate),
+		Money(s.RemainingBudget),
+	})
+}
+
+// MarshalJSON writes the allocation's amounts as Money
+func (a BudgetAllocation) MarshalJSON() ([]byte, error) {
+	type budgetAllocation BudgetAllocation
+	return json.Marshal(struct {
+		budgetAllocation
+		AllocationAmount Money `json:"allocation_amount"`
+	}{
+		budgetAllocation(a),
+		Money(a.AllocationAmount),
+	})
+}
+
+// MarshalJSON writes the summary's amounts as Money
+func (s AllocationScheduleSummary) MarshalJSON() ([]byte, error) {
+	type allocationScheduleSummary AllocationScheduleSummary
+	return json.Marshal(struct {
+		allocationScheduleSummary
+		TotalBudget          Money `json:"total_budget"`
+		AllocatedToDate      Money `json:"allocated_to_date"`
+		RemainingBudget      Money `json:"remaining_budget"`
+		NextAllocationAmount Money `json:"next_allocation_amount"`
+	}{
+		allocationScheduleSummary(s),
+		Money(s.TotalBudget),
+		Money(s.AllocatedToDate),
+		Money(s.RemainingBudget),
+		Money(s.NextAllocationAmount),
+	})
+}
+
+// MarshalJSON writes the projected allocation's amounts as Money
+func (p ProjectedAllocation) MarshalJSON() ([]byte, error) {
+	type projectedAllocation ProjectedAllocation
+	return json.Marshal(struct {
+		projectedAllocation
+		Amount          Money `json:"amount"`
+		RemainingBudget Money `json:"remaining_budget"`
+	}{
+		projectedAllocation(p),
+		Money(p.Amount),
+		Money(p.RemainingBudget),
+	})
+}
+
+// MarshalJSON writes the preview's amounts as Money
+func (p AllocationPreviewResponse) MarshalJSON() ([]byte, error) {
+	type allocationPreviewResponse AllocationPreviewResponse
+	return json.Marshal(struct {
+		allocationPreviewResponse
+		TotalAmount Money `json:"total_amount"`
+	}{
+		allocationPreviewResponse(p),
+		Money(p.TotalAmount),
+	})
+}
+
+// MarshalJSON writes the share's amounts as Money
+func (a CostShareAllocation) MarshalJSON() ([]byte, error) {
+	type costShareAllocation CostShareAllocation
+	return json.Marshal(struct {
+		costShareAllocation
+		HoldAmount   Money `json:"hold_amount"`
+		ActualCharge Money `json:"actual_charge,omitempty"`
+		RefundAmount Money `json:"refund_amount,omitempty"`
+	}{
+		costShareAllocation(a),
+		Money(a.HoldAmount),
+		Money(a.ActualCharge),
+		Money(a.RefundAmount),
+	})
+}
+
+// MarshalJSON writes the estimate's amounts as Money
+func (r EstimateResponse) MarshalJSON() ([]byte, error) {
+	type estimateResponse EstimateResponse
+	return json.Marshal(struct {
+		estimateResponse
+		EstimatedCost Money `json:"estimated_cost"`
+	}{
+		estimateResponse(r),
+		Money(r.EstimatedCost),
+	})
+}
+
+// MarshalJSON writes the validation's amounts as Money
+func (r JobValidateResponse) MarshalJSON() ([]byte, error) {
+	type jobValidateResponse JobValidateResponse
+	return json.Marshal(struct {
+		jobValidateResponse
+		EstimatedCost   Money  `json:"estimated_cost,omitempty"`
+		HoldAmount      Money  `json:"hold_amount,omitempty"`
+		BudgetRemaining *Money `json:"budget_remaining,omitempty"`
+	}{
+		jobValidateResponse(r),
+		Money(r.EstimatedCost),
+		Money(r.HoldAmount),
+		moneyPtr(r.BudgetRemaining),
+	})
+}
+
+// MarshalJSON writes the history's amounts as Money
+func (h ScriptHistory) MarshalJSON() ([]byte, error) {
+	type scriptHistory ScriptHistory
+	return json.Marshal(struct {
+		scriptHistory
+		MeanActualCost  Money `json:"mean_actual_cost"`
+		AdvisorEstimate Money `json:"advisor_estimate"`
+	}{
+		scriptHistory(h),
+		Money(h.MeanActualCost),
+		Money(h.AdvisorEstimate),
+	})
+}
+
+// MarshalJSON writes the divergence's amounts as Money
+func (d AdvisorDivergence) MarshalJSON() ([]byte, error) {
+	type advisorDivergence AdvisorDivergence
+	return json.Marshal(struct {
+		advisorDivergence
+		AdvisorEstimate  Money `json:"advisor_estimate"`
+		FallbackEstimate Money `json:"fallback_estimate"`
+	}{
+		advisorDivergence(d),
+		Money(d.AdvisorEstimate),
+		Money(d.FallbackEstimate),
+	})
+}
+
+// MarshalJSON writes the check's amounts as Money
+func (r BudgetCheckResponse) MarshalJSON() ([]byte, error) {
+	type budgetCheckResponse BudgetCheckResponse
+	return json.Marshal(struct {
+		budgetCheckResponse
+		EstimatedCost   Money                  `json:"estimated_cost"`
+		HoldAmount      Money                  `json:"hold_amount"`
+		BudgetRemaining Money                  `json:"budget_remaining"`
+		HoldGraceCredit Money                  `json:"hold_grace_credit,omitempty"`
+		FullHoldAmount  Money                  `json:"full_hold_amount,omitempty"`
+		Details         budgetCheckDetailsJSON `json:"details,omitempty"`
+	}{
+		budgetCheckResponse(r),
+		Money(r.EstimatedCost),
+		Money(r.HoldAmount),
+		Money(r.BudgetRemaining),
+		Money(r.HoldGraceCredit),
+		Money(r.FullHoldAmount),
+		newBudgetCheckDetailsJSON(r),
+	})
+}
+
+// MarshalJSON writes the reconciliation's amounts as Money
+func (r JobReconcileResponse) MarshalJSON() ([]byte, error) {
+	type jobReconcileResponse JobReconcileResponse
+	return json.Marshal(struct {
+		jobReconcileResponse
+		OriginalHold Money `json:"original_hold"`
+		ActualCharge Money `json:"actual_charge"`
+		RefundAmount Money `json:"refund_amount"`
+	}{
+		jobReconcileResponse(r),
+		Money(r.OriginalHold),
+		Money(r.ActualCharge),
+		Money(r.RefundAmount),
+	})
+}
+
+// MarshalJSON writes the response's amounts as Money
+func (r JobStartedResponse) MarshalJSON() ([]byte, error) {
+	type jobStartedResponse JobStartedResponse
+	return json.Marshal(struct {
+		jobStartedResponse
+		ReservedAmount Money `json:"reserved_amount"`
+		HoldAmount     Money `json:"hold_amount"`
+	}{
+		jobStartedResponse(r),
+		Money(r.ReservedAmount),
+		Money(r.HoldAmount),
+	})
+}
+
+// MarshalJSON writes the summary's amounts as Money
+func (s UsageSummary) MarshalJSON() ([]byte, error) {
+	type usageSummary UsageSummary
+	return json.Marshal(struct {
+		usageSummary
+		TotalSpent    Money `json:"total_spent"`
+		TotalHeld     Money `json:"total_held"`
+		AvgCostPerJob Money `json:"avg_cost_per_job"`
+	}{
+		usageSummary(s),
+		Money(s.TotalSpent),
+		Money(s.TotalHeld),
+		Money(s.AvgCostPerJob),
+	})
+}
+
+// MarshalJSON writes the item's amounts as Money
+func (i UsageBreakdownItem) MarshalJSON() ([]byte, error) {
+	type usageBreakdownItem UsageBreakdownItem
+	return json.Marshal(struct {
+		usageBreakdownItem
+		Amount Money `json:"amount"`
+	}{
+		usageBreakdownItem(i),
+		Money(i.Amount),
+	})
+}
+
+// MarshalJSON writes the forecast's amounts as Money
+func (f UsageForecast) MarshalJSON() ([]byte, error) {
+	type usageForecast UsageForecast
+	return json.Marshal(struct {
+		usageForecast
+		ProjectedSpend Money `json:"projected_spend"`
+		BurnRate       Money `json:"burn_rate"`
+	}{
+		usageForecast(f),
+		Money(f.ProjectedSpend),
+		Money(f.BurnRate),
+	})
+}
+
+// MarshalJSON writes the hold's amounts as Money
+func (h OrphanedHold) MarshalJSON() ([]byte, error) {
+	type orphanedHold OrphanedHold
+	return json.Marshal(struct {
+		orphanedHold
+		Amount Money `json:"amount"`
+	}{
+		orphanedHold(h),
+		Money(h.Amount),
+	})
+}
+
+// MarshalJSON writes the reconciliation's amounts as Money
+func (p PendingReconciliation) MarshalJSON() ([]byte, error) {
+	type pendingReconciliation PendingReconciliation
+	return json.Marshal(struct {
+		pendingReconciliation
+		Amount Money `json:"amount"`
+	}{
+		pendingReconciliation(p),
+		Money(p.Amount),
+	})
+}
+
+// MarshalJSON writes the result's amounts as Money
+func (r AccountingJobResult) MarshalJSON() ([]byte, error) {
+	type accountingJobResult AccountingJobResult
+	return json.Marshal(struct {
+		accountingJobResult
+		ActualCost   Money `json:"actual_cost,omitempty"`
+		RefundAmount Money `json:"refund_amount,omitempty"`
+	}{
+		accountingJobResult(r),
+		Money(r.ActualCost),
+		Money(r.RefundAmount),
+	})
+}
+
+// MarshalJSON writes the consistency report's amounts as Money
+func (c AccountConsistency) MarshalJSON() ([]byte, error) {
+	type accountConsistency AccountConsistency
+	return json.Marshal(struct {
+		accountConsistency
+		CachedUsed   Money `json:"cached_used"`
+		CachedHeld   Money `json:"cached_held"`
+		ExpectedUsed Money `json:"expected_used"`
+		ExpectedHeld Money `json:"expected_held"`
+		UsedDrift    Money `json:"used_drift"`
+		HeldDrift    Money `json:"held_drift"`
+	}{
+		accountConsistency(c),
+		Money(c.CachedUsed),
+		Money(c.CachedHeld),
+		Money(c.ExpectedUsed),
+		Money(c.ExpectedHeld),
+		Money(c.UsedDrift),
+		Money(c.HeldDrift),
+	})
+}
+
+// MarshalJSON writes the summary's amounts as Money
+func (s AdminSummary) MarshalJSON() ([]byte, error) {
+	type adminSummary AdminSummary
+	return json.Marshal(struct {
+		adminSummary
+		TotalLimit     Money `json:"total_limit"`
+		TotalUsed      Money `json:"total_used"`
+		TotalHeld      Money `json:"total_held"`
+		TotalAvailable Money `json:"total_available"`
+	}{
+		adminSummary(s),
+		Money(s.TotalLimit),
+		Money(s.TotalUsed),
+		Money(s.TotalHeld),
+		Money(s.TotalAvailable),
+	})
+}
+
+// MarshalJSON writes the transfer's amounts as Money
+func (t AccountTransfer) MarshalJSON() ([]byte, error) {
+	type accountTransfer AccountTransfer
+	return json.Marshal(struct {
+		accountTransfer
+		BudgetLimit    Money `json:"budget_limit"`
+		BudgetUsed     Money `json:"budget_used"`
+		BudgetHeld     Money `json:"budget_held"`
+		ReservedAmount Money `json:"reserved_amount"`
+		TotalAllocated Money `json:"total_allocated"`
+	}{
+		accountTransfer(t),
+		Money(t.BudgetLimit),
+		Money(t.BudgetUsed),
+		Money(t.BudgetHeld),
+		Money(t.ReservedAmount),
+		Money(t.TotalAllocated),
+	})
+}
+
+// MarshalJSON writes the adjustment's amounts as Money
+func (a HoldAdjustment) MarshalJSON() ([]byte, error) {
+	type holdAdjustment HoldAdjustment
+	return json.Marshal(struct {
+		holdAdjustment
+		PreviousAmount Money `json:"previous_amount"`
+		NewAmount      Money `json:"new_amount"`
+	}{
+		holdAdjustment(a),
+		Money(a.PreviousAmount),
+		Money(a.NewAmount),
+	})
+}
+
+// MarshalJSON writes the decision's amounts as Money
+func (d BudgetDecision) MarshalJSON() ([]byte, error) {
+	type budgetDecision BudgetDecision
+	return json.Marshal(struct {
+		budgetDecision
+		EstimatedCost   Money `json:"estimated_cost"`
+		HoldAmount      Money `json:"hold_amount"`
+		BudgetAvailable Money `json:"budget_available"`
+	}{
+		budgetDecision(d),
+		Money(d.EstimatedCost),
+		Money(d.HoldAmount),
+		Money(d.BudgetAvailable),
+	})
+}
+
+// MarshalJSON writes the repair's amounts as Money
+func (r BalanceRepair) MarshalJSON() ([]byte, error) {
+	type balanceRepair BalanceRepair
+	return json.Marshal(struct {
+		balanceRepair
+		PreviousUsed Money `json:"previous_used"`
+		PreviousHeld Money `json:"previous_held"`
+		RepairedUsed Money `json:"repaired_used"`
+		RepairedHeld Money `json:"repaired_held"`
+	}{
+		balanceRepair(r),
+		Money(r.PreviousUsed),
+		Money(r.PreviousHeld),
+		Money(r.RepairedUsed),
+		Money(r.RepairedHeld),
+	})
+}
+
+// MarshalJSON writes the response's amounts as Money
+func (r ProcessAllocationsResponse) MarshalJSON() ([]byte, error) {
+	type processAllocationsResponse ProcessAllocationsResponse
+	return json.Marshal(struct {
+		processAllocationsResponse
+		TotalAllocated Money `json:"total_allocated"`
+	}{
+		processAllocationsResponse(r),
+		Money(r.TotalAllocated),
+	})
+}
+
+// MarshalJSON writes the metrics's amounts as Money
+func (m BurnRateMetrics) MarshalJSON() ([]byte, error) {
+	type burnRateMetrics BurnRateMetrics
+	return json.Marshal(struct {
+		burnRateMetrics
+		DailySpendRate        Money `json:"daily_spend_rate"`
+		DailyExpectedRate     Money `json:"daily_expected_rate"`
+		Rolling7DayAverage    Money `json:"rolling_7day_average"`
+		Rolling30DayAverage   Money `json:"rolling_30day_average"`
+		CumulativeSpend       Money `json:"cumulative_spend"`
+		CumulativeExpected    Money `json:"cumulative_expected"`
+		BudgetRemainingAmount Money `json:"budget_remaining_amount"`
+	}{
+		burnRateMetrics(m),
+		Money(m.DailySpendRate),
+		Money(m.DailyExpectedRate),
+		Money(m.Rolling7DayAverage),
+		Money(m.Rolling30DayAverage),
+		Money(m.CumulativeSpend),
+		Money(m.CumulativeExpected),
+		Money(m.BudgetRemainingAmount),
+	})
+}
+
+// MarshalJSON writes the data point's amounts as Money
+func (p BurnRateDataPoint) MarshalJSON() ([]byte, error) {
+	type burnRateDataPoint BurnRateDataPoint
+	return json.Marshal(struct {
+		burnRateDataPoint
+		DailySpend         Money `json:"daily_spend"`
+		DailyExpected      Money `json:"daily_expected"`
+		CumulativeSpend    Money `json:"cumulative_spend"`
+		CumulativeExpected Money `json:"cumulative_expected"`
+	}{
+		burnRateDataPoint(p),
+		Money(p.DailySpend),
+		Money(p.DailyExpected),
+		Money(p.CumulativeSpend),
+		Money(p.CumulativeExpected),
+	})
+}
+
+// MarshalJSON writes the projection's amounts as Money
+func (p BurnRateProjection) MarshalJSON() ([]byte, error) {
+	type burnRateProjection BurnRateProjection
+	return json.Marshal(struct {
+		burnRateProjection
+		ProjectedFinalSpend Money `json:"projected_final_spend"`
+		ProjectedOverrun    Money `json:"projected_overrun"`
+		ProjectedUnderrun   Money `json:"projected_underrun"`
+	}{
+		burnRateProjection(p),
+		Money(p.ProjectedFinalSpend),
+		Money(p.ProjectedOverrun),
+		Money(p.ProjectedUnderrun),
+	})
+}
+
+// MarshalJSON writes the allocation's amounts as Money
+func (a ProcessedAllocation) MarshalJSON() ([]byte, error) {
+	type processedAllocation ProcessedAllocation
+	return json.Marshal(struct {
+		processedAllocation
+		AllocatedAmount Money `json:"allocated_amount"`
+	}{
+		processedAllocation(a),
+		Money(a.AllocatedAmount),
+	})
+}
+
+// MarshalJSON writes the grant's amounts as Money
+func (g GrantAccount) MarshalJSON() ([]byte, error) {
+	type grantAccount GrantAccount
+	return json.Marshal(struct {
+		grantAccount
+		TotalAwardAmount    Money `json:"total_award_amount"`
+		DirectCosts         Money `json:"direct_costs"`
+		ExcludedDirectCosts Money `json:"excluded_direct_costs"`
+		IndirectCosts       Money `json:"indirect_costs"`
+	}{
+		grantAccount(g),
+		Money(g.TotalAwardAmount),
+		Money(g.DirectCosts),
+		Money(g.ExcludedDirectCosts),
+		Money(g.IndirectCosts),
+	})
+}
+
+// MarshalJSON writes the period's amounts as Money
+func (p GrantBudgetPeriod) MarshalJSON() ([]byte, error) {
+	type grantBudgetPeriod GrantBudgetPeriod
+	return json.Marshal(struct {
+		grantBudgetPeriod
+		PeriodBudgetAmount    Money `json:"period_budget_amount"`
+		PeriodSpentAmount     Money `json:"period_spent_amount"`
+		PeriodCommittedAmount Money `json:"period_committed_amount"`
+		ExpectedBurnRate      Money `json:"expected_burn_rate"`
+		ActualBurnRate        Money `json:"actual_burn_rate"`
+	}{
+		grantBudgetPeriod(p),
+		Money(p.PeriodBudgetAmount),
+		Money(p.PeriodSpentAmount),
+		Money(p.PeriodCommittedAmount),
+		Money(p.ExpectedBurnRate),
+		Money(p.ActualBurnRate),
+	})
+}
+
+// MarshalJSON writes the summary's amounts as Money
+func (s GrantPeriodSummary) MarshalJSON() ([]byte, error) {
+	type grantPeriodSummary GrantPeriodSummary
+	return json.Marshal(struct {
+		grantPeriodSummary
+		Budget    Money `json:"budget"`
+		Spent     Money `json:"spent"`
+		Committed Money `json:"committed"`
+		Remaining Money `json:"remaining"`
+	}{
+		grantPeriodSummary(s),
+		Money(s.Budget),
+		Money(s.Spent),
+		Money(s.Committed),
+		Money(s.Remaining),
+	})
+}
+
+// MarshalJSON writes the recomputation's amounts as Money
+func (r GrantCostRecomputation) MarshalJSON() ([]byte, error) {
+	type grantCostRecomputation GrantCostRecomputation
+	return json.Marshal(struct {
+		grantCostRecomputation
+		PreviousDirectCosts   Money `json:"previous_direct_costs"`
+		PreviousIndirectCosts Money `json:"previous_indirect_costs"`
+	}{
+		grantCostRecomputation(r),
+		Money(r.PreviousDirectCosts),
+		Money(r.PreviousIndirectCosts),
+	})
+}
+
+// MarshalJSON writes the burn rate's amounts as Money
+func (b BudgetBurnRate) MarshalJSON() ([]byte, error) {
+	type budgetBurnRate BudgetBurnRate
+	return json.Marshal(struct {
+		budgetBurnRate
+		DailySpendAmount    Money `json:"daily_spend_amount"`
+		DailyExpectedAmount Money `json:"daily_expected_amount"`
+		Rolling7DayAvg      Money `json:"rolling_7day_avg"`
+		Rolling30DayAvg     Money `json:"rolling_30day_avg"`
+		CumulativeSpend     Money `json:"cumulative_spend"`
+		CumulativeExpected  Money `json:"cumulative_expected"`
+	}{
+		budgetBurnRate(b),
+		Money(b.DailySpendAmount),
+		Money(b.DailyExpectedAmount),
+		Money(b.Rolling7DayAvg),
+		Money(b.Rolling30DayAvg),
+		Money(b.CumulativeSpend),
+		Money(b.CumulativeExpected),
+	})
+}
+
+// MarshalJSON writes the job cost's amounts as Money
+func (c SimulatedJobCost) MarshalJSON() ([]byte, error) {
+	type simulatedJobCost SimulatedJobCost
+	return json.Marshal(struct {
+		simulatedJobCost
+		UnitCost  Money `json:"unit_cost"`
+		TotalCost Money `json:"total_cost"`
+	}{
+		simulatedJobCost(c),
+		Money(c.UnitCost),
+		Money(c.TotalCost),
+	})
+}
+
+// MarshalJSON writes the simulation's amounts as Money
+func (r SimulationResponse) MarshalJSON() ([]byte, error) {
+	type simulationResponse SimulationResponse
+	return json.Marshal(struct {
+		simulationResponse
+		SimulatedCost          Money `json:"simulated_cost"`
+		CurrentAvailable       Money `json:"current_available"`
+		ProjectedAvailable     Money `json:"projected_available"`
+		CurrentDailyBurnRate   Money `json:"current_daily_burn_rate"`
+		ProjectedDailyBurnRate Money `json:"projected_daily_burn_rate"`
+	}{
+		simulationResponse(r),
+		Money(r.SimulatedCost),
+		Money(r.CurrentAvailable),
+		Money(r.ProjectedAvailable),
+		Money(r.CurrentDailyBurnRate),
+		Money(r.ProjectedDailyBurnRate),
+	})
+}
+
+// MarshalJSON writes the snapshot's amounts as Money
+func (s BudgetSnapshot) MarshalJSON() ([]byte, error) {
+	type budgetSnapshot BudgetSnapshot
+	return json.Marshal(struct {
+		budgetSnapshot
+		BudgetLimit     Money `json:"budget_limit"`
+		BudgetUsed      Money `json:"budget_used"`
+		BudgetHeld      Money `json:"budget_held"`
+		BudgetAvailable Money `json:"budget_available"`
+	}{
+		budgetSnapshot(s),
+		Money(s.BudgetLimit),
+		Money(s.BudgetUsed),
+		Money(s.BudgetHeld),
+		Money(s.BudgetAvailable),
+	})
+}
+
+// MarshalJSON writes the change's limits as Money
+func (c BudgetLimitChange) MarshalJSON() ([]byte, error) {
+	type budgetLimitChange BudgetLimitChange
+	return json.Marshal(struct {
+		budgetLimitChange
+		PreviousLimit *Money `json:"previous_limit,omitempty"`
+		BudgetLimit   Money  `json:"budget_limit"`
+	}{
+		budgetLimitChange(c),
+		moneyPtr(c.PreviousLimit),
+		Money(c.BudgetLimit),
+	})
+}
+
+// MarshalJSON writes the report's amounts as Money
+func (r GrantReportResponse) MarshalJSON() ([]byte, error) {
+	type grantReportResponse GrantReportResponse
+	return json.Marshal(struct {
+		grantReportResponse
+		TotalSpent   Money `json:"total_spent"`
+		TotalHeld    Money `json:"total_held"`
+		TotalAwarded Money `json:"total_awarded"`
+	}{
+		grantReportResponse(r),
+		Money(r.TotalSpent),
+		Money(r.TotalHeld),
+		Money(r.TotalAwarded),
+	})
+}
+
+// MarshalJSON writes the report's amounts as Money
+func (r GrantAccountReport) MarshalJSON() ([]byte, error) {
+	type grantAccountReport GrantAccountReport
+	return json.Marshal(struct {
+		grantAccountReport
+		PeriodSpend     Money `json:"period_spend"`
+		PeriodAllocated Money `json:"period_allocated"`
+	}{
+		grantAccountReport(r),
+		Money(r.PeriodSpend),
+		Money(r.PeriodAllocated),
+	})
+}
+
+// MarshalJSON writes the status's amounts as Money
+func (r BudgetStatusResponse) MarshalJSON() ([]byte, error) {
+	type budgetStatusResponse BudgetStatusResponse
+	return json.Marshal(struct {
+		budgetStatusResponse
+		BudgetLimit       Money `json:"budget_limit"`
+		BudgetUsed        Money `json:"budget_used"`
+		BudgetHeld        Money `json:"budget_held"`
+		BudgetAvailable   Money `json:"budget_available"`
+		DailyBurnRate     Money `json:"daily_burn_rate"`
+		ExpectedDailyRate Money `json:"expected_daily_rate"`
+	}{
+		budgetStatusResponse(r),
+		Money(r.BudgetLimit),
+		Money(r.BudgetUsed),
+		Money(r.BudgetHeld),
+		Money(r.BudgetAvailable),
+		Money(r.DailyBurnRate),
+		Money(r.ExpectedDailyRate),
+	})
+}
+
+// MarshalJSON writes the check's amounts as Money
+func (r AffordabilityCheckResponse) MarshalJSON() ([]byte, error) {
+	type affordabilityCheckResponse AffordabilityCheckResponse
+	return json.Marshal(struct {
+		affordabilityCheckResponse
+		EstimatedAWSCost Money `json:"estimated_aws_cost"`
+	}{
+		affordabilityCheckResponse(r),
+		Money(r.EstimatedAWSCost),
+	})
+}
+
+// MarshalJSON writes the option's amounts as Money
+func (o ResourceOption) MarshalJSON() ([]byte, error) {
+	type resourceOption ResourceOption
+	return json.Marshal(struct {
+		resourceOption
+		EstimatedCost Money `json:"estimated_cost"`
+	}{
+		resourceOption(o),
+		Money(o.EstimatedCost),
+	})
+}
+
+// MarshalJSON writes the event's amounts as Money
+func (e AllocationEvent) MarshalJSON() ([]byte, error) {
+	type allocationEvent AllocationEvent
+	return json.Marshal(struct {
+		allocationEvent
+		Amount Money `json:"amount"`
+	}{
+		allocationEvent(e),
+		Money(e.Amount),
+	})
+}
+
+// MarshalJSON writes the point's amounts as Money
+func (p BudgetTimelinePoint) MarshalJSON() ([]byte, error) {
+	type budgetTimelinePoint BudgetTimelinePoint
+	return json.Marshal(struct {
+		budgetTimelinePoint
+		CumulativeSpend    Money `json:"cumulative_spend"`
+		CumulativeExpected Money `json:"cumulative_expected"`
+		RemainingBudget    Money `json:"remaining_budget"`
+	}{
+		budgetTimelinePoint(p),
+		Money(p.CumulativeSpend),
+		Money(p.CumulativeExpected),
+		Money(p.RemainingBudget),
+	})
+}
+
+// MarshalJSON writes the option's amounts as Money
+func (o EmergencyOption) MarshalJSON() ([]byte, error) {
+	type emergencyOption EmergencyOption
+	return json.Marshal(struct {
+		emergencyOption
+		Available     Money `json:"available,omitempty"`
+		EstimatedCost Money `json:"estimated_cost,omitempty"`
+	}{
+		emergencyOption(o),
+		Money(o.Available),
+		Money(o.EstimatedCost),
+	})
+}
+
+// MarshalJSON writes the feedback's amounts as Money
+func (f ASBAFeedback) MarshalJSON() ([]byte, error) {
+	type aSBAFeedback ASBAFeedback
+	return json.Marshal(struct {
+		aSBAFeedback
+		ActualCost    Money `json:"actual_cost"`
+		EstimatedCost Money `json:"estimated_cost"`
+	}{
+		aSBAFeedback(f),
+		Money(f.ActualCost),
+		Money(f.EstimatedCost),
+	})
+}
+
+// MarshalJSON writes the summary's amounts as Money
+func (s ASBAFeedbackSummary) MarshalJSON() ([]byte, error) {
+	type aSBAFeedbackSummary ASBAFeedbackSummary
+	return json.Marshal(struct {
+		aSBAFeedbackSummary
+		AWSActualCost    Money `json:"aws_actual_cost"`
+		AWSEstimatedCost Money `json:"aws_estimated_cost"`
+	}{
+		aSBAFeedbackSummary(s),
+		Money(s.AWSActualCost),
+		Money(s.AWSEstimatedCost),
+	})
+}
+
+// MarshalJSON writes the reconciliation's amounts as Money
+func (r ASBXCostReconciliationResponse) MarshalJSON() ([]byte, error) {
+	type aSBXCostReconciliationResponse ASBXCostReconciliationResponse
+	return json.Marshal(struct {
+		aSBXCostReconciliationResponse
+		EstimatedCost    Money `json:"estimated_cost"`
+		ActualCost       Money `json:"actual_cost"`
+		CostVariance     Money `json:"cost_variance"`
+		ChargedAmount    Money `json:"charged_amount"`
+		RefundAmount     Money `json:"refund_amount"`
+		AdditionalCharge Money `json:"additional_charge"`
+	}{
+		aSBXCostReconciliationResponse(r),
+		Money(r.EstimatedCost),
+		Money(r.ActualCost),
+		Money(r.CostVariance),
+		Money(r.ChargedAmount),
+		Money(r.RefundAmount),
+		Money(r.AdditionalCharge),
+	})
+}
+
+// MarshalJSON writes the summary's amounts as Money
+func (r ASBXReconciliationSummary) MarshalJSON() ([]byte, error) {
+	type aSBXReconciliationSummary ASBXReconciliationSummary
+	return json.Marshal(struct {
+		aSBXReconciliationSummary
+		EstimatedCost Money `json:"estimated_cost"`
+		ActualCost    Money `json:"actual_cost"`
+		CostVariance  Money `json:"cost_variance"`
+		ChargedAmount Money `json:"charged_amount"`
+		RefundAmount  Money `json:"refund_amount"`
+	}{
+		aSBXReconciliationSummary(r),
+		Money(r.EstimatedCost),
+		Money(r.ActualCost),
+		Money(r.CostVariance),
+		Money(r.ChargedAmount),
+		Money(r.RefundAmount),
+	})
+}
+
+// budgetCheckDetailsJSON is a budget check's details with its amounts as Money
+type budgetCheckDetailsJSON struct {
+	AccountBalance       Money   `json:"account_balance"`
+	CurrentHold          Money   `json:"current_hold"`
+	PartitionUsed        Money   `json:"partition_used,omitempty"`
+	PartitionLimit       Money   `json:"partition_limit,omitempty"`
+	HoldPercentage       float64 `json:"hold_percentage"`
+	HoldPercentageSource string  `json:"hold_percentage_source,omitempty"`
+	AdvisorConfidence    float64 `json:"advisor_confidence,omitempty"`
+}
+
+func newBudgetCheckDetailsJSON(r BudgetCheckResponse) budgetCheckDetailsJSON {
+	return budgetCheckDetailsJSON{
+		AccountBalance:       Money(r.Details.AccountBalance),
+		CurrentHold:          Money(r.Details.CurrentHold),
+		PartitionUsed:        Money(r.Details.PartitionUsed),
+		PartitionLimit:       Money(r.Details.PartitionLimit),
+		HoldPercentage:       r.Details.HoldPercentage,
+		HoldPercentageSource: r.Details.HoldPercentageSource,
+		AdvisorConfidence:    r.Details.AdvisorConfidence,
+	}
+}
+
+// MarshalJSON writes the at-risk account's balances and burn rate as Money
+func (a AtRiskAccount) MarshalJSON() ([]byte, error) {
+	type atRiskAccount AtRiskAccount
+	return json.Marshal(struct {
+		atRiskAccount
+		BudgetLimit     Money `json:"budget_limit"`
+		BudgetAvailable Money `json:"budget_available"`
+		DailyBurnRate   Money `json:"daily_burn_rate"`
+	}{
+		atRiskAccount(a),
+		Money(a.BudgetLimit),
+		Money(a.BudgetAvailable),
+		Money(a.DailyBurnRate),
+	})
+}
+
+// overviewTotalsJSON is OverviewTotals with its amounts as Money
+type overviewTotalsJSON struct {
+	Accounts       int     `json:"accounts"`
+	TotalAllocated Money   `json:"total_allocated"`
+	TotalSpent     Money   `json:"total_spent"`
+	TotalHeld      Money   `json:"total_held"`
+	Utilization    float64 `json:"utilization"`
+}
+
+func newOverviewTotalsJSON(t OverviewTotals) overviewTotalsJSON {
+	return overviewTotalsJSON{
+		Accounts:       t.Accounts,
+		TotalAllocated: Money(t.TotalAllocated),
+		TotalSpent:     Money(t.TotalSpent),
+		TotalHeld:      Money(t.TotalHeld),
+		Utilization:    t.Utilization,
+	}
+}
+
+// MarshalJSON writes the overview's totals as Money
+func (o Overview) MarshalJSON() ([]byte, error) {
+	type overview Overview
+	return json.Marshal(struct {
+		overview
+		Totals overviewTotalsJSON `json:"totals"`
+	}{
+		overview(o),
+		newOverviewTotalsJSON(o.Totals),
+	})
+}
+
+// MarshalJSON writes the status's totals as Money
+func (o StatusOverview) MarshalJSON() ([]byte, error) {
+	type statusOverview StatusOverview
+	return json.Marshal(struct {
+		statusOverview
+		TotalAllocated Money `json:"total_allocated"`
+		TotalSpent     Money `json:"total_spent"`
+		TotalHeld      Money `json:"total_held"`
+	}{
+		statusOverview(o),
+		Money(o.TotalAllocated),
+		Money(o.TotalSpent),
+		Money(o.TotalHeld),
+	})
+}
+
+// MarshalJSON writes the agency's totals as Money
+func (o AgencyOverview) MarshalJSON() ([]byte, error) {
+	type agencyOverview AgencyOverview
+	return json.Marshal(struct {
+		agencyOverview
+		TotalAllocated Money `json:"total_allocated"`
+		TotalSpent     Money `json:"total_spent"`
+		TotalHeld      Money `json:"total_held"`
+	}{
+		agencyOverview(o),
+		Money(o.TotalAllocated),
+		Money(o.TotalSpent),
+		Money(o.TotalHeld),
+	})
+}
+
+// MarshalJSON writes the export's amounts as they are stored rather than as Money, so an
+// export keeps every cent whatever decimal places the service's currency is set to and
+// restores the account exactly
+func (e AccountExport) MarshalJSON() ([]byte, error) {
+	type budgetAccount BudgetAccount
+	type partitionLimit BudgetPartitionLimit
+	type allocationSchedule BudgetAllocationSchedule
+	type transaction BudgetTransaction
+	type accountExport AccountExport
+
+	var account *budgetAccount
+	if e.Account != nil {
+		a := budgetAccount(*e.Account)
+		account = &a
+	}
+	limits := make([]partitionLimit, len(e.PartitionLimits))
+	for i, l := range e.PartitionLimits {
+		limits[i] = partitionLimit(l)
+	}
+	schedules := make([]allocationSchedule, len(e.AllocationSchedules))
+	for i, s := range e.AllocationSchedules {
+		schedules[i] = allocationSchedule(s)
+	}
+	transactions := make([]transaction, len(e.Transactions))
+	for i, t := range e.Transactions {
+		transactions[i] = transaction(t)
+	}
+
+	return json.Marshal(struct {
+		accountExport
+		Account             *budgetAccount       `json:"account"`
+		PartitionLimits     []partitionLimit     `json:"partition_limits"`
+		AllocationSchedules []allocationSchedule `json:"allocation_schedules"`
+		Transactions        []transaction        `json:"transactions"`
+	}{
+		accountExport(e),
+		account,
+		limits,
+		schedules,
+		transactions,
+	})
+}
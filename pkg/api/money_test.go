@@ -0,0 +1,214 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useCurrency sets the currency for one test
+func useCurrency(t *testing.T, c Currency) {
+	SetCurrency(c)
+	t.Cleanup(func() { SetCurrency(DefaultCurrency) })
+}
+
+func TestMoney_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency Currency
+		amount   float64
+		expected string
+	}{
+		{"float noise", DefaultCurrency, 0.1 + 0.2, "0.30"},
+		{"extra places rounded", DefaultCurrency, 9.1625, "9.16"},
+		{"whole amount padded", DefaultCurrency, 12, "12.00"},
+		{"negative", DefaultCurrency, -4.5, "-4.50"},
+		{"rounds to zero without a sign", DefaultCurrency, -0.001, "0.00"},
+		{"exact tie rounded to even", DefaultCurrency, 0.125, "0.12"},
+		{"zero decimals", Currency{Code: "JPY", Decimals: 0}, 1234.4, "1234"},
+		{"three decimals", Currency{Code: "KWD", Decimals: 3}, 1.23456, "1.235"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCurrency(t, tt.currency)
+
+			data, err := json.Marshal(Money(tt.amount))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(data))
+		})
+	}
+
+	_, err := json.Marshal(Money(math.NaN()))
+	assert.Error(t, err)
+}
+
+func TestValidCurrencyCode(t *testing.T) {
+	assert.True(t, ValidCurrencyCode("USD"))
+	assert.True(t, ValidCurrencyCode("EUR"))
+	assert.False(t, ValidCurrencyCode("usd"))
+	assert.False(t, ValidCurrencyCode("US"))
+	assert.False(t, ValidCurrencyCode("US$"))
+	assert.False(t, ValidCurrencyCode(""))
+}
+
+func TestBudgetAccount_MarshalJSON(t *testing.T) {
+	useCurrency(t, Currency{Code: "EUR", Decimals: 2})
+	account := BudgetAccount{
+		SlurmAccount: "proj001",
+		BudgetLimit:  1000,
+		BudgetUsed:   0.1 + 0.2,
+		BudgetHeld:   9.1625,
+		Status:       "active",
+	}
+
+	data, err := json.Marshal(account)
+	require.NoError(t, err)
+	body := string(data)
+	assert.Contains(t, body, `"budget_limit":1000.00`)
+	assert.Contains(t, body, `"budget_used":0.30`)
+	assert.Contains(t, body, `"budget_held":9.16`)
+	assert.Contains(t, body, `"reserved_amount":0.00`)
+	assert.Contains(t, body, `"currency":"EUR"`)
+	assert.Contains(t, body, `"slurm_account":"proj001"`)
+	assert.NotContains(t, body, "0.30000000000000004")
+
+	// Pointers and slices encode the same way, and decode back to the amounts
+	data, err = json.Marshal([]*BudgetAccount{&account})
+	require.NoError(t, err)
+	var decoded []BudgetAccount
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded, 1)
+	assert.Equal(t, "proj001", decoded[0].SlurmAccount)
+	assert.Equal(t, 0.3, decoded[0].BudgetUsed)
+	assert.Equal(t, 9.16, decoded[0].BudgetHeld)
+
+	// An account in service units reports them rather than the service's currency
+	account.BudgetUnit = BudgetUnitSU
+	data, err = json.Marshal(account)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"currency":"SU"`)
+
+	data, err = json.Marshal(UserAccount{SlurmAccount: "proj001", BudgetUnit: BudgetUnitSU})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"currency":"SU"`)
+	data, err = json.Marshal(UserAccount{SlurmAccount: "proj001", BudgetUnit: BudgetUnitDollars})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"currency":"EUR"`)
+}
+
+func TestBudgetTransaction_MarshalJSON(t *testing.T) {
+	full := 120.0 / 7
+	data, err := json.Marshal(BudgetTransaction{
+		TransactionID:  "txn_1",
+		Type:           "hold",
+		Amount:         12.0 / 7,
+		FullHoldAmount: &full,
+		CostBreakdown:  map[string]float64{"compute": 10.0 / 3},
+	})
+	require.NoError(t, err)
+	body := string(data)
+	assert.Contains(t, body, `"amount":1.71`)
+	assert.Contains(t, body, `"full_hold_amount":17.14`)
+	assert.Contains(t, body, `"cost_breakdown":{"compute":3.33}`)
+	assert.Contains(t, body, `"transaction_id":"txn_1"`)
+
+	data, err = json.Marshal(BudgetTransaction{TransactionID: "txn_2", Amount: 5})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "full_hold_amount")
+	assert.NotContains(t, string(data), "cost_breakdown")
+}
+
+func TestBudgetCheckResponse_MarshalJSON(t *testing.T) {
+	resp := BudgetCheckResponse{
+		Available:       true,
+		EstimatedCost:   10.0 / 3,
+		HoldAmount:      4,
+		BudgetRemaining: 0.1 + 0.2,
+		CostShares:      []CostShareAllocation{{Account: "lab", Percentage: 33.333, HoldAmount: 4.0 / 3}},
+		AdvisorDivergence: &AdvisorDivergence{
+			AdvisorEstimate: 0.5, FallbackEstimate: 10.0 / 3, Ratio: 6.667, Policy: "MAX", AdvisorConfidence: 0.9,
+		},
+	}
+	resp.Details.AccountBalance = 100.0 / 3
+	resp.Details.HoldPercentage = 1.2
+	resp.Details.AdvisorConfidence = 0.875
+
+	data, err := json.Marshal(&resp)
+	require.NoError(t, err)
+	body := string(data)
+	assert.Contains(t, body, `"estimated_cost":3.33`)
+	assert.Contains(t, body, `"hold_amount":4.00`)
+	assert.Contains(t, body, `"budget_remaining":0.30`)
+	assert.Contains(t, body, `"account_balance":33.33`)
+	// Percentages and ratios are not amounts
+	assert.Contains(t, body, `"hold_percentage":1.2`)
+	assert.Contains(t, body, `"advisor_confidence":0.875`)
+	assert.Contains(t, body, `"percentage":33.333`)
+	assert.Contains(t, body, `"hold_amount":1.33`)
+	assert.Contains(t, body, `"advisor_estimate":0.50`)
+	assert.Contains(t, body, `"fallback_estimate":3.33`)
+	assert.Contains(t, body, `"ratio":6.667`)
+	assert.NotContains(t, body, "full_hold_amount")
+}
+
+func TestOverview_MarshalJSON(t *testing.T) {
+	totals := OverviewTotals{Accounts: 2, TotalAllocated: 300, TotalSpent: 0.1 + 0.2, TotalHeld: 1.005}.WithUtilization()
+	overview := Overview{
+		Totals:   totals,
+		ByStatus: []StatusOverview{{Status: "active", OverviewTotals: totals}},
+		ByAgency: []AgencyOverview{{FundingAgency: "NSF", OverviewTotals: totals}},
+	}
+
+	data, err := json.Marshal(overview)
+	require.NoError(t, err)
+
+	var decoded struct {
+		Totals   map[string]json.RawMessage   `json:"totals"`
+		ByStatus []map[string]json.RawMessage `json:"by_status"`
+		ByAgency []map[string]json.RawMessage `json:"by_agency"`
+	}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Len(t, decoded.ByStatus, 1)
+	require.Len(t, decoded.ByAgency, 1)
+	for _, fields := range []map[string]json.RawMessage{decoded.Totals, decoded.ByStatus[0], decoded.ByAgency[0]} {
+		assert.Equal(t, "2", string(fields["accounts"]))
+		assert.Equal(t, "300.00", string(fields["total_allocated"]))
+		assert.Equal(t, "0.30", string(fields["total_spent"]))
+	}
+	assert.Equal(t, `"active"`, string(decoded.ByStatus[0]["status"]))
+	assert.Equal(t, `"NSF"`, string(decoded.ByAgency[0]["funding_agency"]))
+}
+
+func TestAccountExport_MarshalJSON(t *testing.T) {
+	useCurrency(t, Currency{Code: "JPY", Decimals: 0})
+	export := AccountExport{
+		Version:             AccountExportVersion,
+		Account:             &BudgetAccount{SlurmAccount: "proj001", BudgetLimit: 1000.5, BudgetUsed: 12.34},
+		PartitionLimits:     []BudgetPartitionLimit{{Partition: "gpu", Limit: 250.75}},
+		AllocationSchedules: []BudgetAllocationSchedule{{TotalBudget: 99.99, AllocationAmount: 33.33}},
+		Transactions:        []BudgetTransaction{{TransactionID: "txn_1", Amount: 0.01}},
+	}
+
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+	body := string(data)
+	assert.Contains(t, body, `"budget_limit":1000.5`)
+	assert.Contains(t, body, `"budget_used":12.34`)
+	assert.Contains(t, body, `"limit":250.75`)
+	assert.Contains(t, body, `"total_budget":99.99`)
+	assert.Contains(t, body, `"amount":0.01`)
+
+	var decoded AccountExport
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, export.Account.BudgetUsed, decoded.Account.BudgetUsed)
+	assert.Equal(t, export.PartitionLimits, decoded.PartitionLimits)
+	assert.Equal(t, export.Transactions, decoded.Transactions)
+}
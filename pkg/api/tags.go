@@ -0,0 +1,79 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Limits on account tags
+const (
+	MaxAccountTags = 50
+	maxTagKeyLen   = 64
+	maxTagValueLen = 256
+)
+
+// ValidateTags checks an account's tags: at most MaxAccountTags, with keys that are
+// non-empty, contain no '=' or whitespace, and stay within the length limits
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxAccountTags {
+		return fmt.Errorf("at most %d tags are allowed", MaxAccountTags)
+	}
+	for key, value := range tags {
+		if err := validateTagKey(key); err != nil {
+			return err
+		}
+		if len(value) > maxTagValueLen {
+			return fmt.Errorf("tag %q value is longer than %d characters", key, maxTagValueLen)
+		}
+	}
+	return nil
+}
+
+// validateTagKey checks a single tag key
+func validateTagKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("tag keys must not be empty")
+	}
+	if len(key) > maxTagKeyLen {
+		return fmt.Errorf("tag key %q is longer than %d characters", key, maxTagKeyLen)
+	}
+	if strings.Contains(key, "=") || strings.IndexFunc(key, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("tag key %q must not contain '=' or whitespace", key)
+	}
+	return nil
+}
+
+// TagFilter selects accounts by tag: those with the key set to the value, or, without a
+// value, those with the key set at all
+type TagFilter struct {
+	Key      string
+	Value    string
+	HasValue bool
+}
+
+// ParseTagFilter parses a "key=value" or bare "key" tag filter
+func ParseTagFilter(filter string) (TagFilter, error) {
+	key, value, hasValue := strings.Cut(filter, "=")
+	if err := validateTagKey(key); err != nil {
+		return TagFilter{}, err
+	}
+	return TagFilter{Key: key, Value: value, HasValue: hasValue}, nil
+}
+
+// ParseTagFilters parses each of a request's tag filters
+func ParseTagFilters(filters []string) ([]TagFilter, error) {
+	parsed := make([]TagFilter, 0, len(filters))
+	for _, filter := range filters {
+		tf, err := ParseTagFilter(filter)
+		if err != nil {
+			return nil, NewValidationError("tag", err.Error())
+		}
+		parsed = append(parsed, tf)
+	}
+	return parsed, nil
+}
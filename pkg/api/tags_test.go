@@ -0,0 +1,68 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTags(t *testing.T) {
+	tooMany := make(map[string]string, MaxAccountTags+1)
+	for i := 0; i <= MaxAccountTags; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	tests := []struct {
+		name    string
+		tags    map[string]string
+		wantErr bool
+	}{
+		{name: "none", tags: nil},
+		{name: "key and value", tags: map[string]string{"department": "physics", "kind": "course"}},
+		{name: "empty value", tags: map[string]string{"archived": ""}},
+		{name: "empty key", tags: map[string]string{"": "x"}, wantErr: true},
+		{name: "equals in key", tags: map[string]string{"a=b": "x"}, wantErr: true},
+		{name: "space in key", tags: map[string]string{"cost center": "x"}, wantErr: true},
+		{name: "long value", tags: map[string]string{"note": strings.Repeat("x", maxTagValueLen+1)}, wantErr: true},
+		{name: "too many", tags: tooMany, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTags(tt.tags)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseTagFilter(t *testing.T) {
+	tf, err := ParseTagFilter("department=physics")
+	require.NoError(t, err)
+	assert.Equal(t, TagFilter{Key: "department", Value: "physics", HasValue: true}, tf)
+
+	tf, err = ParseTagFilter("department")
+	require.NoError(t, err)
+	assert.Equal(t, TagFilter{Key: "department"}, tf)
+
+	tf, err = ParseTagFilter("note=a=b")
+	require.NoError(t, err)
+	assert.Equal(t, TagFilter{Key: "note", Value: "a=b", HasValue: true}, tf)
+
+	_, err = ParseTagFilter("=physics")
+	assert.Error(t, err)
+
+	_, err = ParseTagFilters([]string{"kind=course", ""})
+	budgetErr, ok := AsBudgetError(err)
+	require.True(t, ok)
+	assert.Equal(t, ErrCodeValidation, budgetErr.Code)
+}
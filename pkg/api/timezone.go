@@ -0,0 +1,87 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultTimezone is used for accounts and grants that do not set a time zone
+const DefaultTimezone = "UTC"
+
+// LoadTimezone resolves an IANA time zone name, treating an empty name as UTC
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
+
+// locationOrUTC resolves a stored time zone name, falling back to UTC if it cannot be loaded
+func locationOrUTC(name string) *time.Location {
+	loc, err := LoadTimezone(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// NextAllocationDate advances an allocation date by one frequency step in loc's wall-clock
+// time, so an allocation at local midnight stays at local midnight across DST changes.
+// Month-based steps clamp to the end of shorter months, as PostgreSQL interval arithmetic
+// does in calculate_next_allocation_date.
+func NextAllocationDate(current time.Time, frequency string, loc *time.Location) (time.Time, error) {
+	local := current.In(loc)
+	switch frequency {
+	case "daily":
+		return local.AddDate(0, 0, 1), nil
+	case "weekly":
+		return local.AddDate(0, 0, 7), nil
+	case "monthly":
+		return addMonthsClamped(local, 1), nil
+	case "quarterly":
+		return addMonthsClamped(local, 3), nil
+	case "yearly":
+		return addMonthsClamped(local, 12), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid allocation frequency: %s", frequency)
+	}
+}
+
+// addMonthsClamped adds months to t, keeping its wall-clock time and clamping the day to
+// the last day of the target month
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfTarget := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := firstOfTarget.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(firstOfTarget.Year(), firstOfTarget.Month(), day,
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// localDate returns local midnight of t's calendar date in loc
+func localDate(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// calendarDaysBetween counts the calendar days from from's date to to's date in loc,
+// independent of DST changes in between
+func calendarDaysBetween(from, to time.Time, loc *time.Location) int {
+	start := localDate(from, loc)
+	end := localDate(to, loc)
+	// Compare as UTC dates so 23- and 25-hour days still count as one day
+	startUTC := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	endUTC := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+	return int(endUTC.Sub(startUTC).Hours() / 24)
+}
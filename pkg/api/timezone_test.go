@@ -0,0 +1,173 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := LoadTimezone(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestLoadTimezone(t *testing.T) {
+	loc, err := LoadTimezone("")
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+
+	_, err = LoadTimezone("America/New_York")
+	assert.NoError(t, err)
+
+	_, err = LoadTimezone("Mars/Olympus_Mons")
+	assert.Error(t, err)
+}
+
+func TestNextAllocationDate_AcrossDST(t *testing.T) {
+	eastern := mustLoad(t, "America/New_York")
+
+	tests := []struct {
+		name      string
+		current   time.Time
+		frequency string
+		expected  time.Time
+	}{
+		{
+			name:      "monthly into daylight time stays at local midnight",
+			current:   time.Date(2025, 3, 1, 0, 0, 0, 0, eastern),
+			frequency: "monthly",
+			expected:  time.Date(2025, 4, 1, 0, 0, 0, 0, eastern),
+		},
+		{
+			name:      "monthly out of daylight time stays at local midnight",
+			current:   time.Date(2025, 10, 1, 0, 0, 0, 0, eastern),
+			frequency: "monthly",
+			expected:  time.Date(2025, 11, 1, 0, 0, 0, 0, eastern),
+		},
+		{
+			name:      "daily over the spring-forward night is a 23 hour step",
+			current:   time.Date(2025, 3, 9, 0, 0, 0, 0, eastern),
+			frequency: "daily",
+			expected:  time.Date(2025, 3, 10, 0, 0, 0, 0, eastern),
+		},
+		{
+			name:      "weekly over the fall-back night",
+			current:   time.Date(2025, 10, 30, 0, 0, 0, 0, eastern),
+			frequency: "weekly",
+			expected:  time.Date(2025, 11, 6, 0, 0, 0, 0, eastern),
+		},
+		{
+			name:      "month end clamps like PostgreSQL",
+			current:   time.Date(2025, 1, 31, 0, 0, 0, 0, eastern),
+			frequency: "monthly",
+			expected:  time.Date(2025, 2, 28, 0, 0, 0, 0, eastern),
+		},
+		{
+			name:      "quarterly clamps to shorter month",
+			current:   time.Date(2025, 11, 30, 0, 0, 0, 0, eastern),
+			frequency: "quarterly",
+			expected:  time.Date(2026, 2, 28, 0, 0, 0, 0, eastern),
+		},
+		{
+			name:      "yearly from leap day",
+			current:   time.Date(2024, 2, 29, 0, 0, 0, 0, eastern),
+			frequency: "yearly",
+			expected:  time.Date(2025, 2, 28, 0, 0, 0, 0, eastern),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Feed the current date in as UTC, as it comes back from the database
+			next, err := NextAllocationDate(tt.current.UTC(), tt.frequency, eastern)
+			require.NoError(t, err)
+			assert.True(t, tt.expected.Equal(next), "expected %s, got %s", tt.expected, next)
+		})
+	}
+
+	spring, err := NextAllocationDate(time.Date(2025, 3, 9, 0, 0, 0, 0, eastern), "daily", eastern)
+	require.NoError(t, err)
+	assert.Equal(t, 23*time.Hour, spring.Sub(time.Date(2025, 3, 9, 0, 0, 0, 0, eastern)))
+}
+
+func TestNextAllocationDate_NonUTCFiresOnLocalDay(t *testing.T) {
+	tokyo := mustLoad(t, "Asia/Tokyo")
+
+	// Local midnight on April 1st in Tokyo is still March 31st in UTC
+	current := time.Date(2025, 3, 1, 0, 0, 0, 0, tokyo)
+	next, err := NextAllocationDate(current, "monthly", tokyo)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-04-01 00:00", next.In(tokyo).Format("2006-01-02 15:04"))
+	assert.Equal(t, "2025-03-31 15:00", next.UTC().Format("2006-01-02 15:04"))
+
+	// The same step computed in UTC would land nine hours late on the local calendar
+	utcNext, err := NextAllocationDate(current, "monthly", time.UTC)
+	require.NoError(t, err)
+	assert.False(t, next.Equal(utcNext))
+}
+
+func TestNextAllocationDate_InvalidFrequency(t *testing.T) {
+	_, err := NextAllocationDate(time.Now(), "hourly", time.UTC)
+	assert.Error(t, err)
+}
+
+func TestGrantAccount_DaysRemaining(t *testing.T) {
+	eastern := mustLoad(t, "America/New_York")
+	grant := GrantAccount{
+		Timezone:     "America/New_York",
+		GrantEndDate: time.Date(2025, 3, 15, 0, 0, 0, 0, eastern),
+	}
+
+	// 11pm Eastern on March 1st is already March 2nd in UTC
+	now := time.Date(2025, 3, 1, 23, 0, 0, 0, eastern)
+	assert.Equal(t, 14, grant.DaysRemaining(now))
+
+	// Counting across the spring-forward night still gives whole days
+	assert.Equal(t, 7, grant.DaysRemaining(time.Date(2025, 3, 8, 12, 0, 0, 0, eastern)))
+
+	assert.Equal(t, 0, grant.DaysRemaining(time.Date(2025, 4, 1, 0, 0, 0, 0, eastern)))
+
+	utcGrant := grant
+	utcGrant.Timezone = ""
+	assert.Equal(t, 13, utcGrant.DaysRemaining(now))
+}
+
+func TestGrantAccount_BudgetPeriods(t *testing.T) {
+	pacific := mustLoad(t, "America/Los_Angeles")
+	grant := GrantAccount{
+		Timezone:           "America/Los_Angeles",
+		GrantStartDate:     time.Date(2024, 7, 1, 0, 0, 0, 0, pacific),
+		GrantEndDate:       time.Date(2027, 7, 1, 0, 0, 0, 0, pacific),
+		BudgetPeriodMonths: 12,
+	}
+
+	start, end := grant.BudgetPeriodBounds(2)
+	assert.True(t, time.Date(2025, 7, 1, 0, 0, 0, 0, pacific).Equal(start))
+	assert.True(t, time.Date(2026, 7, 1, 0, 0, 0, 0, pacific).Equal(end))
+
+	// Rollover happens at local midnight, not UTC midnight
+	lastMinute := time.Date(2025, 6, 30, 23, 59, 0, 0, pacific)
+	assert.Equal(t, 1, grant.BudgetPeriodAt(lastMinute))
+	assert.Equal(t, "2025-07-01", lastMinute.UTC().Format("2006-01-02"))
+	assert.Equal(t, 2, grant.BudgetPeriodAt(time.Date(2025, 7, 1, 0, 0, 0, 0, pacific)))
+	assert.Equal(t, 3, grant.BudgetPeriodAt(time.Date(2026, 12, 25, 0, 0, 0, 0, pacific)))
+}
+
+func TestUpdateAccountRequest_Validate_Timezone(t *testing.T) {
+	valid := "Europe/Berlin"
+	invalid := "Not/AZone"
+	empty := ""
+
+	assert.NoError(t, (&UpdateAccountRequest{Timezone: &valid}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{Timezone: &invalid}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{Timezone: &empty}).Validate())
+}
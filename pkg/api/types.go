@@ -5,27 +5,96 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 )
 
 // BudgetAccount represents a budget account in the system
 type BudgetAccount struct {
-	ID                   int64      `json:"id" db:"id"`
-	SlurmAccount         string     `json:"slurm_account" db:"slurm_account"`
-	Name                 string     `json:"name" db:"name"`
-	Description          string     `json:"description" db:"description"`
-	BudgetLimit          float64    `json:"budget_limit" db:"budget_limit"`
-	BudgetUsed           float64    `json:"budget_used" db:"budget_used"`
-	BudgetHeld           float64    `json:"budget_held" db:"budget_held"`
-	HasIncrementalBudget bool       `json:"has_incremental_budget" db:"has_incremental_budget"`
-	NextAllocationDate   *time.Time `json:"next_allocation_date,omitempty" db:"next_allocation_date"`
-	TotalAllocated       float64    `json:"total_allocated" db:"total_allocated"`
-	StartDate            time.Time  `json:"start_date" db:"start_date"`
-	EndDate              time.Time  `json:"end_date" db:"end_date"`
-	Status               string     `json:"status" db:"status"`
-	CreatedAt            time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt            time.Time  `json:"updated_at" db:"updated_at"`
+	ID                   int64             `json:"id" db:"id"`
+	SlurmAccount         string            `json:"slurm_account" db:"slurm_account"`
+	Name                 string            `json:"name" db:"name"`
+	Description          string            `json:"description" db:"description"`
+	BudgetLimit          float64           `json:"budget_limit" db:"budget_limit"`
+	BudgetUsed           float64           `json:"budget_used" db:"budget_used"`
+	BudgetHeld           float64           `json:"budget_held" db:"budget_held"`
+	HasIncrementalBudget bool              `json:"has_incremental_budget" db:"has_incremental_budget"`
+	NextAllocationDate   *time.Time        `json:"next_allocation_date,omitempty" db:"next_allocation_date"`
+	TotalAllocated       float64           `json:"total_allocated" db:"total_allocated"`
+	HoldPercentage       *float64          `json:"hold_percentage,omitempty" db:"hold_percentage"` // Overrides the configured default when set
+	ReservedAmount       float64           `json:"reserved_amount" db:"reserved_amount"`           // Held back from jobs; spendable only by adjustment
+	Timezone             string            `json:"timezone" db:"timezone"`                         // IANA zone for allocation dates
+	BurnRateEnabled      bool              `json:"burn_rate_enabled" db:"burn_rate_enabled"`
+	Frozen               bool              `json:"frozen" db:"frozen"`                                   // Refuses new holds; existing jobs still reconcile
+	DepletedAt           *time.Time        `json:"depleted_at,omitempty" db:"depleted_at"`               // Set while the depletion policy has the account suspended or frozen
+	FiscalYearStart      *string           `json:"fiscal_year_start,omitempty" db:"fiscal_year_start"`   // MM-DD; overrides the configured fiscal year
+	ParentAccountID      *int64            `json:"parent_account_id,omitempty" db:"parent_account_id"`   // Umbrella account whose pool this account also draws on
+	Tags                 map[string]string `json:"tags,omitempty" db:"tags"`                             // Free-form categories, e.g. department=physics
+	EstimationSource     *string           `json:"estimation_source,omitempty" db:"estimation_source"`   // Cost model for budget checks; overrides the advisor default
+	AllowedPartitions    []string          `json:"allowed_partitions,omitempty" db:"allowed_partitions"` // Partitions jobs may use; empty allows all
+	BudgetUnit           string            `json:"budget_unit" db:"budget_unit"`                         // dollars or su; the unit of the limit and balances
+	DollarsPerSU         *float64          `json:"dollars_per_su,omitempty" db:"dollars_per_su"`         // Converts dollar costs to SU; set for su accounts
+	StartDate            time.Time         `json:"start_date" db:"start_date"`
+	EndDate              time.Time         `json:"end_date" db:"end_date"`
+	Status               string            `json:"status" db:"status"`
+	CreatedAt            time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Cost models an account's budget checks may be pinned to
+const (
+	EstimationSourceAdvisor  = "advisor"  // the advisor, falling back as integration.failure_mode says
+	EstimationSourceFallback = "fallback" // the built-in heuristic, without calling the advisor
+	EstimationSourceStatic   = "static"   // a flat rate per CPU-hour, without calling the advisor
+)
+
+// ValidEstimationSource reports whether source is a cost model accounts may use; empty
+// leaves the account on the service default
+func ValidEstimationSource(source string) bool {
+	switch source {
+	case "", EstimationSourceAdvisor, EstimationSourceFallback, EstimationSourceStatic:
+		return true
+	}
+	return false
+}
+
+// Units an account's limit and balances may be denominated in
+const (
+	BudgetUnitDollars = "dollars" // costs are held and charged as they are
+	BudgetUnitSU      = "su"      // service units; costs are converted at the account's dollars_per_su
+)
+
+// ValidBudgetUnit reports whether unit is a unit accounts may be denominated in; empty
+// leaves the account in dollars
+func ValidBudgetUnit(unit string) bool {
+	switch unit {
+	case "", BudgetUnitDollars, BudgetUnitSU:
+		return true
+	}
+	return false
+}
+
+// Unit returns the unit the account's limit and balances are denominated in, defaulting
+// to dollars
+func (ba *BudgetAccount) Unit() string {
+	if ba.BudgetUnit == "" {
+		return BudgetUnitDollars
+	}
+	return ba.BudgetUnit
+}
+
+// FromDollars converts a dollar cost, as the advisor and ASBX report costs, into the
+// account's unit
+func (ba *BudgetAccount) FromDollars(cost float64) float64 {
+	if ba.Unit() != BudgetUnitSU || ba.DollarsPerSU == nil || *ba.DollarsPerSU <= 0 {
+		return cost
+	}
+	return cost / *ba.DollarsPerSU
 }
 
 // BudgetAvailable returns the available budget amount
@@ -33,6 +102,30 @@ func (ba *BudgetAccount) BudgetAvailable() float64 {
 	return ba.BudgetLimit - ba.BudgetUsed - ba.BudgetHeld
 }
 
+// SpendableAvailable returns the available budget that jobs may draw on, excluding the reserve
+func (ba *BudgetAccount) SpendableAvailable() float64 {
+	return ba.BudgetAvailable() - ba.ReservedAmount
+}
+
+// Location returns the account's time zone, defaulting to UTC
+func (ba *BudgetAccount) Location() *time.Location {
+	return locationOrUTC(ba.Timezone)
+}
+
+// AllowsPartition reports whether the account's jobs may run on a partition. Partition
+// names match without regard to case, as in the per-partition settings.
+func (ba *BudgetAccount) AllowsPartition(partition string) bool {
+	if len(ba.AllowedPartitions) == 0 {
+		return true
+	}
+	for _, allowed := range ba.AllowedPartitions {
+		if strings.EqualFold(allowed, partition) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsActive returns true if the account is currently active
 func (ba *BudgetAccount) IsActive() bool {
 	now := time.Now()
@@ -41,17 +134,34 @@ func (ba *BudgetAccount) IsActive() bool {
 
 // BudgetTransaction represents a budget transaction
 type BudgetTransaction struct {
-	ID            int64      `json:"id" db:"id"`
-	AccountID     int64      `json:"account_id" db:"account_id"`
-	JobID         *string    `json:"job_id,omitempty" db:"job_id"`
-	TransactionID string     `json:"transaction_id" db:"transaction_id"`
-	Type          string     `json:"type" db:"type"` // hold, charge, refund, adjustment
-	Amount        float64    `json:"amount" db:"amount"`
-	Description   string     `json:"description" db:"description"`
-	Metadata      string     `json:"metadata,omitempty" db:"metadata"` // JSON metadata
-	Status        string     `json:"status" db:"status"`               // pending, completed, failed, cancelled
-	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
-	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	ID                  int64   `json:"id" db:"id"`
+	AccountID           int64   `json:"account_id" db:"account_id"`
+	JobID               *string `json:"job_id,omitempty" db:"job_id"`
+	TransactionID       string  `json:"transaction_id" db:"transaction_id"`
+	Type                string  `json:"type" db:"type"` // hold, charge, refund, adjustment
+	Amount              float64 `json:"amount" db:"amount"`
+	Description         string  `json:"description" db:"description"`
+	Metadata            string  `json:"metadata,omitempty" db:"metadata"` // JSON metadata
+	Status              string  `json:"status" db:"status"`               // pending, completed, failed, cancelled
+	ParentTransactionID *string `json:"parent_transaction_id,omitempty" db:"parent_transaction_id"`
+	// A cost-shared job's holds share a group, the transaction ID of its first hold, and
+	// each records the percentage of the job its account funds
+	CostShareGroup      *string    `json:"cost_share_group,omitempty" db:"cost_share_group"`
+	CostSharePercentage *float64   `json:"cost_share_percentage,omitempty" db:"cost_share_percentage"`
+	CreatedAt           time.Time  `json:"created_at" db:"created_at"`
+	CompletedAt         *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	// A queued job's hold reserves part of FullHoldAmount until the job's prolog reports it
+	// started, at StartedAt, when the hold is escalated to the full amount
+	FullHoldAmount *float64   `json:"full_hold_amount,omitempty" db:"full_hold_amount"`
+	StartedAt      *time.Time `json:"started_at,omitempty" db:"started_at"`
+	// ReconciledAt is when a hold's job was reconciled; only account exports read it
+	ReconciledAt *time.Time `json:"reconciled_at,omitempty" db:"reconciled_at"`
+	// GrantBudgetPeriodID is the grant budget period the transaction counts against: for a
+	// hold, the one in force when it was placed, and for a job's charges and refunds, their
+	// hold's
+	GrantBudgetPeriodID *int64 `json:"grant_budget_period_id,omitempty" db:"grant_budget_period_id"`
+	// CostBreakdown splits a job charge by cost component, when one was reported
+	CostBreakdown map[string]float64 `json:"cost_breakdown,omitempty" db:"-"`
 }
 
 // BudgetPartitionLimit represents per-partition budget limits
@@ -99,6 +209,16 @@ type BudgetAllocation struct {
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 }
 
+// ValidAllocationFrequency reports whether frequency is one an allocation schedule can
+// step by
+func ValidAllocationFrequency(frequency string) bool {
+	switch frequency {
+	case "daily", "weekly", "monthly", "quarterly", "yearly":
+		return true
+	}
+	return false
+}
+
 // AllocationScheduleSummary provides summary information about allocation schedules
 type AllocationScheduleSummary struct {
 	TotalBudget          float64    `json:"total_budget"`
@@ -109,6 +229,38 @@ type AllocationScheduleSummary struct {
 	AllocationFrequency  string     `json:"allocation_frequency,omitempty"`
 }
 
+// ProjectedAllocation is an allocation a schedule is expected to make
+type ProjectedAllocation struct {
+	ScheduleID      int64     `json:"schedule_id"`
+	AllocationDate  time.Time `json:"allocation_date"`
+	Amount          float64   `json:"amount"`
+	Partial         bool      `json:"partial"`
+	RemainingBudget float64   `json:"remaining_budget"`
+}
+
+// ScheduleAllocations is an allocation schedule with the allocations it has made, oldest
+// first
+type ScheduleAllocations struct {
+	Schedule    *BudgetAllocationSchedule `json:"schedule"`
+	Allocations []*BudgetAllocation       `json:"allocations"`
+}
+
+// AccountSchedulesResponse reports each of an account's allocation schedules with the
+// allocations it made, and a summary across the schedules
+type AccountSchedulesResponse struct {
+	Account   string                    `json:"account"`
+	Summary   AllocationScheduleSummary `json:"summary"`
+	Schedules []ScheduleAllocations     `json:"schedules"`
+}
+
+// AllocationPreviewResponse lists an account's upcoming allocations, earliest first
+type AllocationPreviewResponse struct {
+	Account     string                `json:"account"`
+	Count       int                   `json:"count"`
+	TotalAmount float64               `json:"total_amount"`
+	Allocations []ProjectedAllocation `json:"allocations"`
+}
+
 // Request and Response Types
 
 // CreateAccountRequest represents a request to create a new budget account
@@ -120,9 +272,125 @@ type CreateAccountRequest struct {
 	StartDate            time.Time                        `json:"start_date" validate:"required"`
 	EndDate              time.Time                        `json:"end_date" validate:"required,gtfield=StartDate"`
 	HasIncrementalBudget bool                             `json:"has_incremental_budget"`
+	HoldPercentage       *float64                         `json:"hold_percentage,omitempty" validate:"omitempty,gt=0"`
+	ReservedAmount       float64                          `json:"reserved_amount,omitempty" validate:"omitempty,min=0"`
+	Timezone             string                           `json:"timezone,omitempty"`
+	FiscalYearStart      string                           `json:"fiscal_year_start,omitempty"` // MM-DD; empty uses the configured fiscal year
+	BurnRateEnabled      bool                             `json:"burn_rate_enabled,omitempty"`
+	ParentAccount        string                           `json:"parent_account,omitempty"` // SLURM account of the parent
+	Tags                 map[string]string                `json:"tags,omitempty"`
+	EstimationSource     string                           `json:"estimation_source,omitempty"`  // advisor, fallback or static; empty uses the service default
+	AllowedPartitions    []string                         `json:"allowed_partitions,omitempty"` // Partitions jobs may use; empty allows all
+	BudgetUnit           string                           `json:"budget_unit,omitempty"`        // dollars or su; empty is dollars
+	DollarsPerSU         *float64                         `json:"dollars_per_su,omitempty"`     // Required for su accounts
 	AllocationSchedule   *CreateAllocationScheduleRequest `json:"allocation_schedule,omitempty"`
 }
 
+// BulkCreateAccountsResponse reports the per-item outcome of a bulk account creation
+type BulkCreateAccountsResponse struct {
+	Total   int                 `json:"total"`
+	Created int                 `json:"created"`
+	Failed  int                 `json:"failed"`
+	Results []BulkAccountResult `json:"results"`
+}
+
+// BulkAccountResult represents the outcome of creating one account in a bulk request
+type BulkAccountResult struct {
+	Index        int            `json:"index"`
+	SlurmAccount string         `json:"slurm_account"`
+	Success      bool           `json:"success"`
+	Account      *BudgetAccount `json:"account,omitempty"`
+	ErrorCode    ErrorCode      `json:"error_code,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// CloneAccountRequest creates an account from an existing one, taking its settings,
+// parent and partition limits. Name, description, dates and budget follow the source
+// unless overridden; balances always start at zero.
+type CloneAccountRequest struct {
+	SlurmAccount           string     `json:"slurm_account"`
+	Name                   string     `json:"name,omitempty"`
+	Description            *string    `json:"description,omitempty"`
+	BudgetLimit            *float64   `json:"budget_limit,omitempty"`
+	StartDate              *time.Time `json:"start_date,omitempty"`
+	EndDate                *time.Time `json:"end_date,omitempty"`
+	CopyAllocationSchedule bool       `json:"copy_allocation_schedule,omitempty"` // Copy active and paused schedules, restarted from the start date
+}
+
+// AccountCloneResponse reports an account created by cloning and what was copied to it
+type AccountCloneResponse struct {
+	Account               *BudgetAccount `json:"account"`
+	SourceAccount         string         `json:"source_account"`
+	PartitionLimitsCopied int64          `json:"partition_limits_copied"`
+	SchedulesCopied       int64          `json:"schedules_copied"`
+}
+
+// AccountExportVersion is the format version of account exports this service writes and
+// can import
+const AccountExportVersion = 1
+
+// AccountExport is a complete copy of one account: its settings and balances, partition
+// limits, allocation schedules and transactions, oldest first. Importing it recreates the
+// account as it was exported. Accounts are linked by SLURM account rather than by ID, which
+// is not kept across an import.
+type AccountExport struct {
+	Version             int                        `json:"version"`
+	ExportedAt          time.Time                  `json:"exported_at"`
+	Account             *BudgetAccount             `json:"account"`
+	ParentAccount       string                     `json:"parent_account,omitempty"`
+	PartitionLimits     []BudgetPartitionLimit     `json:"partition_limits"`
+	AllocationSchedules []BudgetAllocationSchedule `json:"allocation_schedules"`
+	Transactions        []BudgetTransaction        `json:"transactions"`
+}
+
+// AccountImportResponse reports an account restored from an export and what was restored
+type AccountImportResponse struct {
+	Account             *BudgetAccount `json:"account"`
+	PartitionLimits     int            `json:"partition_limits"`
+	AllocationSchedules int            `json:"allocation_schedules"`
+	Transactions        int            `json:"transactions"`
+}
+
+// AccountMember is a user who may submit jobs under an account
+type AccountMember struct {
+	AccountID    int64     `json:"account_id"`
+	SlurmAccount string    `json:"slurm_account"`
+	User         string    `json:"user"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Account health, as reported to the users who submit under an account
+const (
+	AccountHealthHealthy     = "HEALTHY"     // below the utilization alert thresholds
+	AccountHealthWarning     = "WARNING"     // at or above the warning threshold
+	AccountHealthCritical    = "CRITICAL"    // at or above the critical threshold, or nothing left to spend
+	AccountHealthUnavailable = "UNAVAILABLE" // the account or a parent refuses new jobs
+)
+
+// UserAccount is one account a user may submit under, with the budget a job submitted
+// there could draw on and the account's health
+type UserAccount struct {
+	SlurmAccount    string    `json:"slurm_account"`
+	Name            string    `json:"name"`
+	Status          string    `json:"status"`
+	BudgetLimit     float64   `json:"budget_limit"`
+	BudgetUsed      float64   `json:"budget_used"`
+	BudgetHeld      float64   `json:"budget_held"`
+	BudgetUnit      string    `json:"budget_unit"`                // dollars or su; the unit of the limit and balances
+	BudgetAvailable float64   `json:"budget_available"`           // Spendable in the account and every parent account
+	LimitingAccount string    `json:"limiting_account,omitempty"` // Parent account whose pool caps budget_available
+	Utilization     float64   `json:"utilization"`                // Percentage of the budget spent or held
+	Health          string    `json:"health"`
+	HealthReason    string    `json:"health_reason,omitempty"`
+	EndDate         time.Time `json:"end_date"`
+}
+
+// UserAccountsResponse lists the accounts a user may submit under
+type UserAccountsResponse struct {
+	User     string         `json:"user"`
+	Accounts []*UserAccount `json:"accounts"`
+}
+
 // CreateAllocationScheduleRequest represents a request to create an allocation schedule
 type CreateAllocationScheduleRequest struct {
 	TotalBudget         float64    `json:"total_budget" validate:"required,min=0"`
@@ -135,33 +403,197 @@ type CreateAllocationScheduleRequest struct {
 
 // UpdateAccountRequest represents a request to update a budget account
 type UpdateAccountRequest struct {
-	Name        *string    `json:"name,omitempty"`
-	Description *string    `json:"description,omitempty"`
-	BudgetLimit *float64   `json:"budget_limit,omitempty" validate:"omitempty,min=0"`
-	StartDate   *time.Time `json:"start_date,omitempty"`
-	EndDate     *time.Time `json:"end_date,omitempty"`
-	Status      *string    `json:"status,omitempty" validate:"omitempty,oneof=active inactive suspended"`
+	Name              *string           `json:"name,omitempty"`
+	Description       *string           `json:"description,omitempty"`
+	BudgetLimit       *float64          `json:"budget_limit,omitempty" validate:"omitempty,min=0"`
+	StartDate         *time.Time        `json:"start_date,omitempty"`
+	EndDate           *time.Time        `json:"end_date,omitempty"`
+	Status            *string           `json:"status,omitempty" validate:"omitempty,oneof=active inactive suspended"`
+	HoldPercentage    *float64          `json:"hold_percentage,omitempty" validate:"omitempty,gt=0"`
+	ReservedAmount    *float64          `json:"reserved_amount,omitempty" validate:"omitempty,min=0"`
+	Timezone          *string           `json:"timezone,omitempty"`
+	BurnRateEnabled   *bool             `json:"burn_rate_enabled,omitempty"`
+	Frozen            *bool             `json:"frozen,omitempty"`
+	FiscalYearStart   *string           `json:"fiscal_year_start,omitempty"`  // MM-DD; empty reverts to the configured fiscal year
+	ParentAccount     *string           `json:"parent_account,omitempty"`     // SLURM account of the parent; empty detaches
+	Tags              map[string]string `json:"tags,omitempty"`               // Replaces the account's tags; {} clears them
+	EstimationSource  *string           `json:"estimation_source,omitempty"`  // advisor, fallback or static; empty reverts to the service default
+	AllowedPartitions []string          `json:"allowed_partitions,omitempty"` // Replaces the account's partitions; [] allows every partition
+	DollarsPerSU      *float64          `json:"dollars_per_su,omitempty"`     // Conversion rate of an su account; applies to later holds and charges
+}
+
+// BudgetAdjustmentRequest represents an administrative adjustment of an account's used budget
+type BudgetAdjustmentRequest struct {
+	Amount      float64 `json:"amount" validate:"required"` // Positive spends budget, negative credits it
+	Description string  `json:"description" validate:"required"`
+	FromReserve bool    `json:"from_reserve,omitempty"` // Draw the amount down from the reserved floor
+}
+
+// BudgetAdjustmentResponse represents the result of a budget adjustment
+type BudgetAdjustmentResponse struct {
+	TransactionID string         `json:"transaction_id"`
+	Account       *BudgetAccount `json:"account"`
 }
 
 // ListAccountsRequest represents a request to list budget accounts
 type ListAccountsRequest struct {
-	Limit  int    `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
-	Offset int    `json:"offset,omitempty" validate:"omitempty,min=0"`
-	Status string `json:"status,omitempty" validate:"omitempty,oneof=active inactive suspended"`
+	Limit  int      `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+	Offset int      `json:"offset,omitempty" validate:"omitempty,min=0"`
+	Status string   `json:"status,omitempty" validate:"omitempty,oneof=active inactive suspended"`
+	Tags   []string `json:"tags,omitempty"` // "key=value" or "key"; an account must match all
+}
+
+// AccountPage is a page of budget accounts, the v2 shape of the account list
+type AccountPage struct {
+	Accounts   []*BudgetAccount `json:"accounts"`
+	Limit      int              `json:"limit"`
+	Offset     int              `json:"offset"`
+	NextOffset *int             `json:"next_offset,omitempty"` // Unset on the last page
 }
 
 // BudgetCheckRequest represents a request to check budget availability
 type BudgetCheckRequest struct {
-	Account    string            `json:"account" validate:"required"`
-	Partition  string            `json:"partition" validate:"required"`
-	Nodes      int               `json:"nodes" validate:"required,min=1"`
-	CPUs       int               `json:"cpus" validate:"required,min=1"`
-	GPUs       int               `json:"gpus,omitempty" validate:"omitempty,min=0"`
-	Memory     string            `json:"memory,omitempty"`
-	WallTime   string            `json:"wall_time" validate:"required"`
-	JobScript  string            `json:"job_script,omitempty"`
-	UserID     string            `json:"user_id,omitempty"`
-	JobDetails map[string]string `json:"job_details,omitempty"`
+	Account        string            `json:"account" validate:"required"`
+	Partition      string            `json:"partition" validate:"required"`
+	Nodes          int               `json:"nodes" validate:"required,min=1"`
+	CPUs           int               `json:"cpus" validate:"required,min=1"`
+	GPUs           int               `json:"gpus,omitempty" validate:"omitempty,min=0"`
+	Memory         string            `json:"memory,omitempty"`
+	WallTime       string            `json:"wall_time" validate:"required"`
+	JobScript      string            `json:"job_script,omitempty"`
+	UserID         string            `json:"user_id,omitempty"`
+	ResearchDomain string            `json:"research_domain,omitempty"` // Selects a domain inflation factor
+	JobDetails     map[string]string `json:"job_details,omitempty"`
+	// CostShares splits the job between funding accounts, one of them Account; the
+	// percentages must sum to 100
+	CostShares []CostShare `json:"cost_shares,omitempty"`
+}
+
+// CostShare is one funding account's percentage of a cost-shared job
+type CostShare struct {
+	Account    string  `json:"account"`
+	Percentage float64 `json:"percentage"`
+}
+
+// CostShareAllocation is one funding account's part of a cost-shared job: its hold and,
+// once the job is reconciled, its charge and refund
+type CostShareAllocation struct {
+	Account       string  `json:"account"`
+	Percentage    float64 `json:"percentage"`
+	TransactionID string  `json:"transaction_id,omitempty"` // The account's hold
+	HoldAmount    float64 `json:"hold_amount"`
+	ActualCharge  float64 `json:"actual_charge,omitempty"`
+	RefundAmount  float64 `json:"refund_amount,omitempty"`
+}
+
+// EstimateRequest prices a job shape without checking or holding any account's budget.
+// The account is optional and only passed on to the advisor.
+type EstimateRequest struct {
+	Account        string `json:"account,omitempty"`
+	Partition      string `json:"partition"`
+	Nodes          int    `json:"nodes"`
+	CPUs           int    `json:"cpus"`
+	GPUs           int    `json:"gpus,omitempty"`
+	Memory         string `json:"memory,omitempty"`
+	WallTime       string `json:"wall_time"`
+	JobScript      string `json:"job_script,omitempty"`
+	ResearchDomain string `json:"research_domain,omitempty"` // Selects a domain inflation factor
+}
+
+// EstimateResponse is a job's estimated cost, as a budget check would price it
+type EstimateResponse struct {
+	EstimatedCost  float64 `json:"estimated_cost"`
+	Confidence     float64 `json:"confidence"`
+	Recommendation string  `json:"recommendation,omitempty"`
+	FailureMode    string  `json:"failure_mode,omitempty"`  // Set when the advisor was unavailable
+	DomainFactor   float64 `json:"domain_factor,omitempty"` // Set when the estimate was scaled for the research domain
+	Warning        string  `json:"warning,omitempty"`
+	// ScriptHistory is set when the job script's past actual costs informed the estimate
+	ScriptHistory *ScriptHistory `json:"script_history,omitempty"`
+	// AdvisorDivergence is set when the advisor's estimate was raised toward the fallback's
+	AdvisorDivergence *AdvisorDivergence `json:"advisor_divergence,omitempty"`
+}
+
+// JobValidateRequest is a batch script to check before it is submitted. Account and
+// Partition, when set, override the script's directives as sbatch's command-line options
+// would.
+type JobValidateRequest struct {
+	Script         string `json:"script"`
+	Account        string `json:"account,omitempty"`
+	Partition      string `json:"partition,omitempty"`
+	ResearchDomain string `json:"research_domain,omitempty"` // Selects a domain inflation factor
+	UserID         string `json:"-"`                         // The caller, whose account membership is checked
+}
+
+// JobResources is the resource request a batch script's #SBATCH directives make. CPUs are
+// per node; GPUs and memory are for the whole job.
+type JobResources struct {
+	Account   string `json:"account,omitempty"`
+	Partition string `json:"partition,omitempty"`
+	Nodes     int    `json:"nodes"`
+	CPUs      int    `json:"cpus"`
+	GPUs      int    `json:"gpus,omitempty"`
+	Memory    string `json:"memory,omitempty"`
+	WallTime  string `json:"wall_time,omitempty"` // HH:MM:SS or D-HH:MM:SS
+}
+
+// JobValidationIssue is a problem found validating a job before submission. Errors mean
+// the job would be rejected; warnings do not.
+type JobValidationIssue struct {
+	Severity string `json:"severity"`
+	Field    string `json:"field,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Severities of a job validation issue
+const (
+	ValidationSeverityError   = "error"
+	ValidationSeverityWarning = "warning"
+)
+
+// JobValidateResponse is what a job would request, cost and be told by a budget check if
+// it were submitted now. Valid is false when any issue is an error. The estimate is in
+// dollars; the hold and remaining budget are in the account's budget unit.
+type JobValidateResponse struct {
+	Valid           bool                 `json:"valid"`
+	Resources       JobResources         `json:"resources"`
+	EstimatedCost   float64              `json:"estimated_cost,omitempty"`
+	HoldAmount      float64              `json:"hold_amount,omitempty"`
+	Affordable      bool                 `json:"affordable"`
+	BudgetRemaining *float64             `json:"budget_remaining,omitempty"` // Set when the account was found
+	BudgetUnit      string               `json:"budget_unit,omitempty"`
+	Issues          []JobValidationIssue `json:"issues"`
+}
+
+// ScriptHistory describes how the actual costs of earlier jobs run from an identical job
+// script were blended into an estimate
+type ScriptHistory struct {
+	ScriptHash      string  `json:"script_hash"`
+	Samples         int     `json:"samples"`
+	MeanActualCost  float64 `json:"mean_actual_cost"`
+	Weight          float64 `json:"weight"`           // Share of the estimate taken from the history
+	AdvisorEstimate float64 `json:"advisor_estimate"` // The estimate before blending
+}
+
+// PriorActual is what an earlier reconciled job of the same account, partition and
+// resource shape was estimated to cost and actually cost, passed to the advisor as a prior.
+// Efficiencies are zero when the job did not report them.
+type PriorActual struct {
+	EstimatedCost    float64   `json:"estimated_cost"`
+	ActualCost       float64   `json:"actual_cost"`
+	CPUEfficiency    float64   `json:"cpu_efficiency,omitempty"`
+	MemoryEfficiency float64   `json:"memory_efficiency,omitempty"`
+	CompletedAt      time.Time `json:"completed_at"`
+}
+
+// AdvisorDivergence describes an advisor estimate that fell so far below the fallback
+// heuristic's for the same job that the estimate used was raised
+type AdvisorDivergence struct {
+	AdvisorEstimate   float64 `json:"advisor_estimate"`
+	FallbackEstimate  float64 `json:"fallback_estimate"`
+	Ratio             float64 `json:"ratio,omitempty"`    // Fallback estimate over the advisor's; unset when the advisor estimated nothing
+	Policy            string  `json:"policy"`             // MAX or BLEND
+	AdvisorConfidence float64 `json:"advisor_confidence"` // The advisor's confidence before it was lowered
 }
 
 // BudgetCheckResponse represents a response to budget check request
@@ -173,22 +605,65 @@ type BudgetCheckResponse struct {
 	Message         string  `json:"message,omitempty"`
 	BudgetRemaining float64 `json:"budget_remaining"`
 	Recommendation  string  `json:"recommendation,omitempty"`
-	Details         struct {
-		AccountBalance    float64 `json:"account_balance"`
-		CurrentHold       float64 `json:"current_hold"`
-		PartitionUsed     float64 `json:"partition_used,omitempty"`
-		PartitionLimit    float64 `json:"partition_limit,omitempty"`
-		HoldPercentage    float64 `json:"hold_percentage"`
-		AdvisorConfidence float64 `json:"advisor_confidence,omitempty"`
+	FailureMode     string  `json:"failure_mode,omitempty"`  // Set when the advisor was unavailable
+	DomainFactor    float64 `json:"domain_factor,omitempty"` // Set when the estimate was scaled for the research domain
+	// HoldGraceCredit is how much of the limiting account's recent holds was discounted from
+	// budget_remaining under budget.hold_availability GRACE
+	HoldGraceCredit float64 `json:"hold_grace_credit,omitempty"`
+	Warning         string  `json:"warning,omitempty"`
+	// BudgetUnit is the unit of hold_amount, budget_remaining and the details' balances;
+	// estimated_cost is always in dollars
+	BudgetUnit string `json:"budget_unit,omitempty"`
+	// FullHoldAmount is the hold a queued job is escalated to when it starts, set when only a
+	// reservation of it, hold_amount, was held at submission
+	FullHoldAmount float64 `json:"full_hold_amount,omitempty"`
+	// ScriptHistory is set when the job script's past actual costs informed the estimate
+	ScriptHistory *ScriptHistory `json:"script_history,omitempty"`
+	// AdvisorDivergence is set when the advisor's estimate was raised toward the fallback's
+	AdvisorDivergence *AdvisorDivergence `json:"advisor_divergence,omitempty"`
+	// CostShares lists each funding account's hold when the check was cost-shared
+	CostShares []CostShareAllocation `json:"cost_shares,omitempty"`
+	// CheckFailures lists every check that refused the job, when budget.admission_failures
+	// reports them all
+	CheckFailures []CheckFailure `json:"check_failures,omitempty"`
+	Details       struct {
+		AccountBalance float64 `json:"account_balance"`
+		CurrentHold    float64 `json:"current_hold"`
+		PartitionUsed  float64 `json:"partition_used,omitempty"`
+		PartitionLimit float64 `json:"partition_limit,omitempty"`
+		HoldPercentage float64 `json:"hold_percentage"`
+		// HoldPercentageSource is where HoldPercentage came from, one of the
+		// HoldPercentageSource constants
+		HoldPercentageSource string  `json:"hold_percentage_source,omitempty"`
+		AdvisorConfidence    float64 `json:"advisor_confidence,omitempty"`
 	} `json:"details,omitempty"`
 }
 
+// Where a budget check's hold percentage came from
+const (
+	HoldPercentageSourceAccount = "account" // The account's hold_percentage override
+	HoldPercentageSourceGPU     = "gpu"     // budget.gpu_hold_percentage, for a job requesting GPUs
+	HoldPercentageSourceDefault = "default" // budget.default_hold_percentage
+)
+
 // JobReconcileRequest represents a request to reconcile a completed job
 type JobReconcileRequest struct {
 	JobID         string  `json:"job_id" validate:"required"`
 	ActualCost    float64 `json:"actual_cost" validate:"required,min=0"`
-	TransactionID string  `json:"transaction_id" validate:"required"`
+	TransactionID string  `json:"transaction_id"`
+	Account       string  `json:"account,omitempty"`      // Identifies a job approved without a hold
 	JobMetadata   string  `json:"job_metadata,omitempty"` // JSON metadata
+	JobState      string  `json:"job_state,omitempty"`    // SLURM job state; FAILED jobs follow the failed job policy
+	// CostBreakdown splits the actual cost by component, e.g. compute, storage and network
+	CostBreakdown map[string]float64 `json:"cost_breakdown,omitempty"`
+	// ReconciliationID is recorded on the charges and refunds of an ASBX reconciliation
+	ReconciliationID string `json:"-"`
+	// ChargedAtEstimate is set when orphan recovery charges an expired hold at its estimate
+	// under the CHARGE expired hold policy
+	ChargedAtEstimate bool `json:"-"`
+	// CompletedAt is when the job ended, checked against the end dates of the grants
+	// funding the account; defaults to when the job is reconciled
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
 }
 
 // JobReconcileResponse represents a response to job reconciliation
@@ -199,15 +674,64 @@ type JobReconcileResponse struct {
 	RefundAmount  float64 `json:"refund_amount"`
 	TransactionID string  `json:"transaction_id"`
 	Message       string  `json:"message,omitempty"`
+	// AdditionalCharge is the part of actual_charge beyond the hold, charged directly
+	AdditionalCharge float64 `json:"additional_charge,omitempty"`
+	// Preview is set on a reconciliation previewed without being committed
+	Preview bool `json:"preview,omitempty"`
+	// FailedJobPolicy is the policy applied to a FAILED job: charge_actual or full_refund
+	FailedJobPolicy string `json:"failed_job_policy,omitempty"`
+	// CostShares splits the charge and refund between the funding accounts of a
+	// cost-shared job
+	CostShares []CostShareAllocation `json:"cost_shares,omitempty"`
+	// Warning is set when the job completed after a grant funding the account ended
+	Warning string `json:"warning,omitempty"`
+	// BudgetUnit is the unit of original_hold, actual_charge and refund_amount; the
+	// request's actual_cost is always in dollars
+	BudgetUnit string `json:"budget_unit,omitempty"`
 }
 
+// JobStartedRequest reports from a job's prolog that a queued job has started, so its hold
+// is escalated from the reservation to the full amount
+type JobStartedRequest struct {
+	JobID         string `json:"-"` // Taken from the URL path
+	TransactionID string `json:"transaction_id"`
+}
+
+// JobStartedResponse represents the result of reporting a job started
+type JobStartedResponse struct {
+	JobID         string `json:"job_id"`
+	TransactionID string `json:"transaction_id"`
+	// Escalated is false when the hold was already started or was placed in full
+	Escalated      bool    `json:"escalated"`
+	ReservedAmount float64 `json:"reserved_amount"` // Held before the report
+	HoldAmount     float64 `json:"hold_amount"`     // Held after it
+	Message        string  `json:"message,omitempty"`
+	// Warning is set when the account chain could no longer cover the full hold; the job has
+	// already started, so the hold is escalated regardless
+	Warning string `json:"warning,omitempty"`
+}
+
+// Policies for reconciling a job that ended in the FAILED state, set by
+// budget.charge_failed_jobs
+const (
+	FailedJobPolicyChargeActual = "charge_actual"
+	FailedJobPolicyFullRefund   = "full_refund"
+)
+
+// Policies for an ASBX reconciliation of a job that was never held, having bypassed the
+// budget check, set by integration.unheld_job_policy
+const (
+	UnheldJobPolicyCharge = "charge" // charge the job's account retroactively, flagged unreserved
+	UnheldJobPolicyReject = "reject"
+)
+
 // UsageReportRequest represents a request for usage reporting
 type UsageReportRequest struct {
 	Account   string     `json:"account,omitempty"`
 	StartDate *time.Time `json:"start_date,omitempty"`
 	EndDate   *time.Time `json:"end_date,omitempty"`
 	Partition string     `json:"partition,omitempty"`
-	GroupBy   string     `json:"group_by,omitempty" validate:"omitempty,oneof=day week month partition user"`
+	GroupBy   string     `json:"group_by,omitempty" validate:"omitempty,oneof=day week month fiscal_quarter partition user cost_component"`
 }
 
 // UsageReportResponse represents usage report data
@@ -217,6 +741,18 @@ type UsageReportResponse struct {
 	Summary   UsageSummary         `json:"summary"`
 	Breakdown []UsageBreakdownItem `json:"breakdown,omitempty"`
 	Forecast  *UsageForecast       `json:"forecast,omitempty"`
+	// ReconciliationLatency covers the holds reconciled in the period, per account
+	ReconciliationLatency []*AccountReconciliationLatency `json:"reconciliation_latency,omitempty"`
+}
+
+// AccountReconciliationLatency summarizes how long an account's holds waited between
+// being placed and being reconciled
+type AccountReconciliationLatency struct {
+	Account    string  `json:"account"`
+	Reconciled int64   `json:"reconciled"`
+	AvgHours   float64 `json:"avg_hours"`
+	MaxHours   float64 `json:"max_hours"`
+	OverSLA    int64   `json:"over_sla"` // Reconciled later than budget.reconciliation_sla
 }
 
 // UsageSummary provides summary statistics
@@ -254,8 +790,415 @@ type TransactionListRequest struct {
 	Status    string     `json:"status,omitempty" validate:"omitempty,oneof=pending completed failed cancelled"`
 	StartDate *time.Time `json:"start_date,omitempty"`
 	EndDate   *time.Time `json:"end_date,omitempty"`
+	Search    string     `json:"search,omitempty"` // Case-insensitive substring of the description or metadata
 	Limit     int        `json:"limit,omitempty" validate:"omitempty,min=1,max=1000"`
 	Offset    int        `json:"offset,omitempty" validate:"omitempty,min=0"`
+	// Cursor continues the list after the last transaction of a page, from that page's
+	// next_cursor. Unlike an offset it costs the same however deep the page is.
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// MaxTransactionListLimit is the most transactions a list request may ask for
+const MaxTransactionListLimit = 1000
+
+// TransactionPage is a page of transactions, the v2 shape of the transaction list
+type TransactionPage struct {
+	Transactions []*BudgetTransaction `json:"transactions"`
+	Limit        int                  `json:"limit"`
+	NextCursor   string               `json:"next_cursor,omitempty"` // Unset on the last page
+}
+
+// OrphanedHold represents a pending hold that has outlived the reconciliation timeout
+type OrphanedHold struct {
+	TransactionID string    `json:"transaction_id"`
+	AccountID     int64     `json:"account_id"`
+	Account       string    `json:"account"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	AgeHours      float64   `json:"age_hours"`
+}
+
+// OrphanedHoldsResponse represents the orphaned holds awaiting reconciliation or recovery
+type OrphanedHoldsResponse struct {
+	ReconciliationTimeout string          `json:"reconciliation_timeout"`
+	Holds                 []*OrphanedHold `json:"holds"`
+}
+
+// PendingReconciliation represents a pending hold still waiting for its job to be reconciled
+type PendingReconciliation struct {
+	TransactionID string    `json:"transaction_id"`
+	JobID         string    `json:"job_id,omitempty"`
+	AccountID     int64     `json:"account_id"`
+	Account       string    `json:"account"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	AgeHours      float64   `json:"age_hours"`
+	PastTimeout   bool      `json:"past_timeout"`
+}
+
+// PendingReconciliationResponse represents the holds awaiting reconciliation, oldest first
+type PendingReconciliationResponse struct {
+	Account               string                   `json:"account,omitempty"`
+	ReconciliationTimeout string                   `json:"reconciliation_timeout"`
+	ReconciliationSLA     string                   `json:"reconciliation_sla,omitempty"`
+	PastTimeout           int                      `json:"past_timeout"`
+	Holds                 []*PendingReconciliation `json:"holds"`
+	// ReconciliationLatency covers every hold reconciled so far, per account
+	ReconciliationLatency []*AccountReconciliationLatency `json:"reconciliation_latency,omitempty"`
+}
+
+// AccountingJob represents a finished job as recorded by SLURM accounting (sacct)
+type AccountingJob struct {
+	JobID             string     `json:"job_id" validate:"required"`
+	Account           string     `json:"account" validate:"required"`
+	Partition         string     `json:"partition,omitempty"`
+	User              string     `json:"user,omitempty"`
+	State             string     `json:"state" validate:"required"`
+	ElapsedSeconds    int64      `json:"elapsed_seconds" validate:"min=0"`
+	Nodes             int        `json:"nodes"`
+	CPUs              int        `json:"cpus"`
+	GPUs              int        `json:"gpus"`
+	Memory            string     `json:"memory,omitempty"`
+	Start             *time.Time `json:"start,omitempty"`
+	End               *time.Time `json:"end,omitempty"`
+	HoldTransactionID string     `json:"hold_transaction_id,omitempty"`
+}
+
+// AccountingReconcileRequest represents a request to reconcile holds from SLURM accounting records
+type AccountingReconcileRequest struct {
+	Jobs []*AccountingJob `json:"jobs" validate:"required,min=1,dive"`
+}
+
+// EpilogEnvRequest carries a SLURM epilog's environment, such as SLURM_JOB_ID and
+// SLURM_JOB_ACCOUNT, for reconciling the job without ASBX cost data
+type EpilogEnvRequest struct {
+	Env map[string]string `json:"env" validate:"required"`
+}
+
+// Accounting reconciliation outcomes for a single job
+const (
+	AccountingJobReconciled        = "reconciled"
+	AccountingJobAlreadyReconciled = "already_reconciled"
+	AccountingJobNoHold            = "no_hold"
+	AccountingJobUnfinished        = "unfinished"
+	AccountingJobFailed            = "failed"
+)
+
+// AccountingJobResult represents the outcome of reconciling one accounting record
+type AccountingJobResult struct {
+	JobID         string  `json:"job_id"`
+	Account       string  `json:"account"`
+	Status        string  `json:"status"`
+	TransactionID string  `json:"transaction_id,omitempty"`
+	ActualCost    float64 `json:"actual_cost,omitempty"`
+	RefundAmount  float64 `json:"refund_amount,omitempty"`
+	Message       string  `json:"message,omitempty"`
+}
+
+// AccountingReconcileResponse represents the outcome of an accounting reconciliation run
+type AccountingReconcileResponse struct {
+	Reconciled        int                    `json:"reconciled"`
+	AlreadyReconciled int                    `json:"already_reconciled"`
+	NoHold            int                    `json:"no_hold"`
+	Unfinished        int                    `json:"unfinished"`
+	Failed            int                    `json:"failed"`
+	Jobs              []*AccountingJobResult `json:"jobs"`
+}
+
+// OrphanRecoveryResponse represents the outcome of a recovery run over orphaned holds
+type OrphanRecoveryResponse struct {
+	Found      int      `json:"found"`
+	Recovered  []string `json:"recovered"`
+	Reconciled []string `json:"reconciled,omitempty"` // Reconciled from ASBX data instead of cancelled
+	Charged    []string `json:"charged,omitempty"`    // Charged at their estimate under the CHARGE expired hold policy
+	Failed     []string `json:"failed,omitempty"`
+	Batches    int      `json:"batches"` // Pages of pending holds worked through
+}
+
+// Where a failed reconciliation came from
+const (
+	ReconciliationSourceReconcile = "reconcile" // A reconciliation request for the hold
+	ReconciliationSourceRecovery  = "recovery"  // Orphan recovery cancelling the hold
+)
+
+// How a dead-lettered reconciliation was closed
+const (
+	DeadLetterReplayed   = "replayed"   // Replayed by an administrator
+	DeadLetterResolved   = "resolved"   // Closed by an administrator without replaying
+	DeadLetterReconciled = "reconciled" // The hold was later reconciled some other way
+)
+
+// ReconciliationDeadLetter is a hold whose reconciliation kept failing, set aside for an
+// administrator to replay or resolve
+type ReconciliationDeadLetter struct {
+	ID             int64                `json:"id"`
+	TransactionID  string               `json:"transaction_id"`
+	JobID          string               `json:"job_id,omitempty"`
+	Source         string               `json:"source"`
+	Request        *JobReconcileRequest `json:"request,omitempty"` // Nil when recovery was cancelling the hold
+	Attempts       int                  `json:"attempts"`
+	Reason         string               `json:"reason"` // The latest failure
+	FirstFailedAt  time.Time            `json:"first_failed_at"`
+	LastFailedAt   time.Time            `json:"last_failed_at"`
+	DeadLetteredAt *time.Time           `json:"dead_lettered_at,omitempty"`
+	ResolvedAt     *time.Time           `json:"resolved_at,omitempty"`
+	Resolution     string               `json:"resolution,omitempty"`
+	ResolvedBy     string               `json:"resolved_by,omitempty"`
+	Note           string               `json:"note,omitempty"`
+}
+
+// ResolveDeadLetterRequest closes a dead-lettered reconciliation, or records who replayed it
+type ResolveDeadLetterRequest struct {
+	ResolvedBy string `json:"resolved_by,omitempty"`
+	Note       string `json:"note,omitempty"`
+}
+
+// ConsistencyCheckRequest represents a request to compare cached account balances with the
+// transaction ledger, optionally repairing any drift
+type ConsistencyCheckRequest struct {
+	Account    string `json:"account,omitempty"` // Empty checks every account
+	Repair     bool   `json:"repair,omitempty"`
+	Reason     string `json:"reason,omitempty"`
+	RepairedBy string `json:"repaired_by,omitempty"`
+}
+
+// AccountConsistency compares an account's cached used and held balances with the balances
+// recomputed from its transaction ledger
+type AccountConsistency struct {
+	AccountID    int64   `json:"account_id"`
+	Account      string  `json:"account"`
+	CachedUsed   float64 `json:"cached_used"`
+	CachedHeld   float64 `json:"cached_held"`
+	ExpectedUsed float64 `json:"expected_used"`
+	ExpectedHeld float64 `json:"expected_held"`
+	UsedDrift    float64 `json:"used_drift"` // Cached minus expected
+	HeldDrift    float64 `json:"held_drift"`
+	Consistent   bool    `json:"consistent"`
+	Repaired     bool    `json:"repaired,omitempty"`
+}
+
+// ConsistencyCheckResponse represents the outcome of a consistency check
+type ConsistencyCheckResponse struct {
+	Checked      int                   `json:"checked"`
+	Inconsistent int                   `json:"inconsistent"`
+	Repaired     int                   `json:"repaired"`
+	Accounts     []*AccountConsistency `json:"accounts"`
+}
+
+// AdminSummary is an operational overview of the budget service. Totals are taken over
+// top-level accounts, since a parent's balances already include its descendants'.
+type AdminSummary struct {
+	Accounts       int           `json:"accounts"` // Every account except archived ones
+	ActiveAccounts int           `json:"active_accounts"`
+	PendingHolds   int           `json:"pending_holds"`
+	TotalLimit     float64       `json:"total_limit"`
+	TotalUsed      float64       `json:"total_used"`
+	TotalHeld      float64       `json:"total_held"`
+	TotalAvailable float64       `json:"total_available"`
+	Database       DatabaseStats `json:"database"`
+}
+
+// DatabaseStats describes the service's database connection pool
+type DatabaseStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+}
+
+// MigrationStatus reports the database schema version and the migrations behind it
+type MigrationStatus struct {
+	CurrentVersion uint        `json:"current_version"` // 0 before any migration is applied
+	LatestVersion  uint        `json:"latest_version"`
+	Dirty          bool        `json:"dirty"` // The current migration failed part way and needs repair
+	UpToDate       bool        `json:"up_to_date"`
+	Applied        []Migration `json:"applied"`
+	Pending        []Migration `json:"pending"`
+}
+
+// Migration is one schema migration, named after its file
+type Migration struct {
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+}
+
+// EffectiveConfig is the configuration the running service loaded, its file, environment
+// and defaults merged, with secrets redacted. Settings are keyed as in the configuration
+// file, section by section.
+type EffectiveConfig struct {
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// OverviewRequest narrows the org-wide overview to accounts funded by one grant agency or
+// charged to one cost center
+type OverviewRequest struct {
+	FundingAgency string   `json:"funding_agency,omitempty"`
+	CostCenter    string   `json:"cost_center,omitempty"`
+	Tags          []string `json:"tags,omitempty"` // "key=value" or "key"; an account must match all
+}
+
+// OverviewTotals aggregates the balances of a set of accounts. A parent's balances already
+// include its descendants', so an account under another account in the set is counted but
+// its balances are not added again.
+type OverviewTotals struct {
+	Accounts       int     `json:"accounts"`
+	TotalAllocated float64 `json:"total_allocated"`
+	TotalSpent     float64 `json:"total_spent"`
+	TotalHeld      float64 `json:"total_held"`
+	Utilization    float64 `json:"utilization"` // Percentage of the allocation spent or held
+}
+
+// WithUtilization returns the totals with their utilization filled in
+func (t OverviewTotals) WithUtilization() OverviewTotals {
+	t.Utilization = 0
+	if t.TotalAllocated > 0 {
+		t.Utilization = (t.TotalSpent + t.TotalHeld) / t.TotalAllocated * 100
+	}
+	return t
+}
+
+// StatusOverview is the overview of the accounts in one status
+type StatusOverview struct {
+	Status string `json:"status"`
+	OverviewTotals
+}
+
+// AgencyOverview is the overview of the active accounts funded by one agency; accounts not
+// linked to a grant have an empty agency
+type AgencyOverview struct {
+	FundingAgency string `json:"funding_agency"`
+	OverviewTotals
+}
+
+// Overview is the org-wide budget position. The totals and agency breakdown cover active
+// accounts; the status breakdown covers every account.
+type Overview struct {
+	FundingAgency string           `json:"funding_agency,omitempty"`
+	CostCenter    string           `json:"cost_center,omitempty"`
+	Tags          []string         `json:"tags,omitempty"`
+	Totals        OverviewTotals   `json:"totals"`
+	ByStatus      []StatusOverview `json:"by_status"`
+	ByAgency      []AgencyOverview `json:"by_agency"`
+}
+
+// Ways an account transfer handles the source account's allocation schedules
+const (
+	TransferSchedulesMerge  = "merge"  // Move them to the destination, where they keep allocating
+	TransferSchedulesRetire = "retire" // Cancel them; the destination keeps them as history
+)
+
+// AccountTransferRequest moves an account's balances, transactions and allocation schedules
+// into another account and archives it
+type AccountTransferRequest struct {
+	Schedules     string `json:"schedules,omitempty"` // merge (default) or retire
+	Reason        string `json:"reason,omitempty"`
+	TransferredBy string `json:"transferred_by,omitempty"`
+}
+
+// AccountTransfer is the audit record of an account being transferred into another, with
+// the balances and records moved
+type AccountTransfer struct {
+	ID                int64     `json:"id"`
+	SourceAccountID   int64     `json:"source_account_id"`
+	DestAccountID     int64     `json:"dest_account_id"`
+	SourceAccount     string    `json:"source_account"`
+	DestAccount       string    `json:"dest_account"`
+	BudgetLimit       float64   `json:"budget_limit"`
+	BudgetUsed        float64   `json:"budget_used"`
+	BudgetHeld        float64   `json:"budget_held"`
+	ReservedAmount    float64   `json:"reserved_amount"`
+	TotalAllocated    float64   `json:"total_allocated"`
+	TransactionsMoved int64     `json:"transactions_moved"`
+	SchedulesMerged   int64     `json:"schedules_merged"`
+	SchedulesRetired  int64     `json:"schedules_retired"`
+	Reason            string    `json:"reason,omitempty"`
+	TransferredBy     string    `json:"transferred_by,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// AccountTransferResponse reports a completed transfer and the destination account after it
+type AccountTransferResponse struct {
+	Transfer    *AccountTransfer `json:"transfer"`
+	Destination *BudgetAccount   `json:"destination"`
+}
+
+// HoldAdjustRequest changes the amount a live hold reserves, in the account's unit, e.g.
+// when the estimate for a running job was far off. The hold stays in place, so the job
+// keeps its budget throughout.
+type HoldAdjustRequest struct {
+	Amount     float64 `json:"amount" validate:"required,gt=0"`
+	Reason     string  `json:"reason" validate:"required"`
+	AdjustedBy string  `json:"adjusted_by,omitempty"`
+}
+
+// HoldAdjustment is the audit record of a live hold's amount being changed
+type HoldAdjustment struct {
+	ID             int64     `json:"id"`
+	TransactionID  string    `json:"transaction_id"`
+	AccountID      int64     `json:"account_id"`
+	Account        string    `json:"account"`
+	PreviousAmount float64   `json:"previous_amount"`
+	NewAmount      float64   `json:"new_amount"`
+	Reason         string    `json:"reason"`
+	AdjustedBy     string    `json:"adjusted_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// HoldAdjustResponse reports an adjusted hold and its account after the adjustment
+type HoldAdjustResponse struct {
+	Adjustment *HoldAdjustment `json:"adjustment"`
+	Account    *BudgetAccount  `json:"account"`
+}
+
+// BudgetDecision is the durable record of a budget check's outcome. Rejected checks are
+// recorded too, though they never reach the transaction ledger.
+type BudgetDecision struct {
+	ID              int64               `json:"id"`
+	AccountID       int64               `json:"account_id"`
+	Partition       string              `json:"partition"`
+	UserID          string              `json:"user_id,omitempty"`
+	Decision        string              `json:"decision"` // approved, rejected
+	Reason          string              `json:"reason"`
+	EstimatedCost   float64             `json:"estimated_cost"`
+	HoldAmount      float64             `json:"hold_amount"`
+	BudgetAvailable float64             `json:"budget_available"`
+	Confidence      float64             `json:"confidence,omitempty"`
+	FailureMode     string              `json:"failure_mode,omitempty"`
+	TransactionID   string              `json:"transaction_id,omitempty"`
+	Inputs          *BudgetCheckRequest `json:"inputs,omitempty"` // The job request, without its script
+	CreatedAt       time.Time           `json:"created_at"`
+}
+
+// Budget decision outcomes
+const (
+	DecisionApproved = "approved"
+	DecisionRejected = "rejected"
+)
+
+// DecisionListRequest filters an account's budget decisions. Dates are whole days with
+// the end date inclusive.
+type DecisionListRequest struct {
+	Account   string     `json:"account"`
+	Decision  string     `json:"decision,omitempty" validate:"omitempty,oneof=approved rejected"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+	Limit     int        `json:"limit,omitempty" validate:"omitempty,min=1,max=1000"`
+	Offset    int        `json:"offset,omitempty" validate:"omitempty,min=0"`
+}
+
+// BalanceRepair is the audit record of an account's cached balances being corrected
+type BalanceRepair struct {
+	ID           int64     `json:"id"`
+	AccountID    int64     `json:"account_id"`
+	PreviousUsed float64   `json:"previous_used"`
+	PreviousHeld float64   `json:"previous_held"`
+	RepairedUsed float64   `json:"repaired_used"`
+	RepairedHeld float64   `json:"repaired_held"`
+	Reason       string    `json:"reason,omitempty"`
+	RepairedBy   string    `json:"repaired_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 // AllocationScheduleRequest represents a request to list allocation schedules
@@ -275,6 +1218,21 @@ type UpdateAllocationScheduleRequest struct {
 	AutoAllocate        *bool      `json:"auto_allocate,omitempty"`
 }
 
+// BulkScheduleStatusRequest selects the allocation schedules a bulk pause or resume
+// applies to: those of accounts under a funding agency or cost center, or every schedule
+// without a filter
+type BulkScheduleStatusRequest struct {
+	FundingAgency string `json:"funding_agency,omitempty"`
+	CostCenter    string `json:"cost_center,omitempty"`
+}
+
+// BulkScheduleStatusResponse lists the schedules a bulk pause or resume changed
+type BulkScheduleStatusResponse struct {
+	Status      string  `json:"status"` // What the schedules were set to: paused or active
+	Updated     int     `json:"updated"`
+	ScheduleIDs []int64 `json:"schedule_ids"`
+}
+
 // ProcessAllocationsRequest represents a request to manually process allocations
 type ProcessAllocationsRequest struct {
 	AccountID  *int64 `json:"account_id,omitempty"`
@@ -288,6 +1246,7 @@ type ProcessAllocationsResponse struct {
 	TotalAllocated float64               `json:"total_allocated"`
 	Allocations    []ProcessedAllocation `json:"allocations,omitempty"`
 	DryRun         bool                  `json:"dry_run"`
+	Batches        int                   `json:"batches"` // Transactions the due schedules were allocated in
 }
 
 // Grant Management Request/Response Types
@@ -310,6 +1269,7 @@ type CreateGrantRequest struct {
 	FederalAwardID         string    `json:"federal_award_id,omitempty"`
 	InternalProjectCode    string    `json:"internal_project_code,omitempty"`
 	CostCenter             string    `json:"cost_center,omitempty"`
+	Timezone               string    `json:"timezone,omitempty"`
 }
 
 // BurnRateAnalysisRequest represents a request for burn rate analysis
@@ -391,6 +1351,7 @@ type GrantReportRequest struct {
 	StartDate      *time.Time `json:"start_date,omitempty"`
 	EndDate        *time.Time `json:"end_date,omitempty"`
 	BudgetPeriod   *int       `json:"budget_period,omitempty"`
+	FiscalYear     *int       `json:"fiscal_year,omitempty"` // Named by the year it ends in, e.g. 2026
 	Format         string     `json:"format" validate:"oneof=json csv pdf"`
 	IncludeDetails bool       `json:"include_details"`
 }
@@ -437,8 +1398,9 @@ type GrantAccount struct {
 	GrantEndDate           time.Time `json:"grant_end_date" db:"grant_end_date"`
 	TotalAwardAmount       float64   `json:"total_award_amount" db:"total_award_amount"`
 	DirectCosts            float64   `json:"direct_costs" db:"direct_costs"`
+	ExcludedDirectCosts    float64   `json:"excluded_direct_costs" db:"excluded_direct_costs"` // In categories exempt from indirect costs
 	IndirectCostRate       float64   `json:"indirect_cost_rate" db:"indirect_cost_rate"`
-	IndirectCosts          float64   `json:"indirect_costs" db:"indirect_costs"`
+	IndirectCosts          float64   `json:"indirect_costs" db:"indirect_costs"` // (direct - excluded) * rate
 	BudgetPeriodMonths     int       `json:"budget_period_months" db:"budget_period_months"`
 	CurrentBudgetPeriod    int       `json:"current_budget_period" db:"current_budget_period"`
 	Status                 string    `json:"status" db:"status"`
@@ -446,10 +1408,53 @@ type GrantAccount struct {
 	FederalAwardID         string    `json:"federal_award_id,omitempty" db:"federal_award_id"`
 	InternalProjectCode    string    `json:"internal_project_code,omitempty" db:"internal_project_code"`
 	CostCenter             string    `json:"cost_center,omitempty" db:"cost_center"`
+	Timezone               string    `json:"timezone" db:"timezone"`
 	CreatedAt              time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// Location returns the grant's time zone, defaulting to UTC
+func (ga *GrantAccount) Location() *time.Location {
+	return locationOrUTC(ga.Timezone)
+}
+
+// DaysRemaining returns the number of calendar days in the grant's time zone from now
+// until the grant end date
+func (ga *GrantAccount) DaysRemaining(now time.Time) int {
+	days := calendarDaysBetween(now, ga.GrantEndDate, ga.Location())
+	if days < 0 {
+		return 0
+	}
+	return days
+}
+
+// BudgetPeriodBounds returns the start and end of a budget period, which begin and end at
+// local midnight in the grant's time zone
+func (ga *GrantAccount) BudgetPeriodBounds(period int) (start, end time.Time) {
+	loc := ga.Location()
+	grantStart := localDate(ga.GrantStartDate, loc)
+	if ga.BudgetPeriodMonths <= 0 {
+		return grantStart, localDate(ga.GrantEndDate, loc)
+	}
+	return addMonthsClamped(grantStart, (period-1)*ga.BudgetPeriodMonths),
+		addMonthsClamped(grantStart, period*ga.BudgetPeriodMonths)
+}
+
+// BudgetPeriodAt returns the budget period containing t
+func (ga *GrantAccount) BudgetPeriodAt(t time.Time) int {
+	if ga.BudgetPeriodMonths <= 0 {
+		return 1
+	}
+	period := 1
+	for {
+		_, end := ga.BudgetPeriodBounds(period)
+		if t.Before(end) {
+			return period
+		}
+		period++
+	}
+}
+
 // GrantBudgetPeriod represents a budget period within a multi-year grant
 type GrantBudgetPeriod struct {
 	ID                    int64     `json:"id" db:"id"`
@@ -468,6 +1473,68 @@ type GrantBudgetPeriod struct {
 	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// GrantPeriodSummary splits a budget period's funds into spent, committed and remaining.
+// Committed is budget held for running and queued jobs on the accounts tied to the period.
+type GrantPeriodSummary struct {
+	GrantNumber     string    `json:"grant_number"`
+	PeriodNumber    int       `json:"period_number"`
+	PeriodStartDate time.Time `json:"period_start_date"`
+	PeriodEndDate   time.Time `json:"period_end_date"`
+	Budget          float64   `json:"budget"`
+	Spent           float64   `json:"spent"`
+	Committed       float64   `json:"committed"`
+	Remaining       float64   `json:"remaining"`
+}
+
+// GrantCostRecomputation is a grant's costs recomputed from the charges against its accounts
+type GrantCostRecomputation struct {
+	Grant                 *GrantAccount `json:"grant"`
+	ExcludedCategories    []string      `json:"excluded_categories,omitempty"` // Cost categories left out of the indirect cost base
+	PreviousDirectCosts   float64       `json:"previous_direct_costs"`
+	PreviousIndirectCosts float64       `json:"previous_indirect_costs"`
+}
+
+// Grant deadline types
+const (
+	DeadlineTypeConference  = "CONFERENCE"
+	DeadlineTypeGrantReport = "GRANT_REPORT"
+	DeadlineTypePeriodEnd   = "PERIOD_END"
+	DeadlineTypeRenewal     = "RENEWAL"
+)
+
+// GrantDeadline is a date a grant must meet. A financial report is generated ahead of
+// each GRANT_REPORT deadline.
+type GrantDeadline struct {
+	ID           int64     `json:"id" db:"id"`
+	GrantID      int64     `json:"grant_id" db:"grant_id"`
+	GrantNumber  string    `json:"grant_number"`
+	Type         string    `json:"type" db:"deadline_type"`
+	Description  string    `json:"description,omitempty" db:"description"`
+	DueDate      time.Time `json:"due_date" db:"due_date"`
+	BudgetPeriod *int      `json:"budget_period,omitempty" db:"budget_period"` // Period a report covers; unset for the period at the due date
+	ReportID     *int64    `json:"report_id,omitempty"`                        // Set once the deadline's report is generated
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
+
+// CreateGrantDeadlineRequest represents a request to add a grant deadline
+type CreateGrantDeadlineRequest struct {
+	Type         string    `json:"type" validate:"required,oneof=CONFERENCE GRANT_REPORT PERIOD_END RENEWAL"`
+	Description  string    `json:"description,omitempty"`
+	DueDate      time.Time `json:"due_date" validate:"required"`
+	BudgetPeriod *int      `json:"budget_period,omitempty"`
+}
+
+// StoredGrantReport is a grant report generated for a deadline and kept for download
+type StoredGrantReport struct {
+	ID          int64           `json:"id" db:"id"`
+	GrantID     int64           `json:"grant_id" db:"grant_id"`
+	GrantNumber string          `json:"grant_number"`
+	DeadlineID  *int64          `json:"deadline_id,omitempty" db:"deadline_id"`
+	ReportType  string          `json:"report_type" db:"report_type"`
+	Report      json.RawMessage `json:"report" db:"report"` // The GrantReportResponse as generated
+	GeneratedAt time.Time       `json:"generated_at" db:"generated_at"`
+}
+
 // BudgetBurnRate represents daily burn rate tracking
 type BudgetBurnRate struct {
 	ID                     int64      `json:"id" db:"id"`
@@ -487,6 +1554,81 @@ type BudgetBurnRate struct {
 	CreatedAt              time.Time  `json:"created_at" db:"created_at"`
 }
 
+// BurnRateBackfillRequest represents a request to compute burn rate history for past days
+type BurnRateBackfillRequest struct {
+	StartDate *time.Time `json:"start_date,omitempty"` // Defaults to the account start date
+	EndDate   *time.Time `json:"end_date,omitempty"`   // Defaults to yesterday
+}
+
+// BurnRateBackfillResponse reports the outcome of a burn rate backfill
+type BurnRateBackfillResponse struct {
+	Account   string    `json:"account"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Computed  int       `json:"computed"`
+	Skipped   int       `json:"skipped"`
+}
+
+// SimulatedJob is a hypothetical job shape, run Count times, in a budget simulation
+type SimulatedJob struct {
+	Partition string `json:"partition"`
+	Nodes     int    `json:"nodes"`
+	CPUs      int    `json:"cpus"`
+	GPUs      int    `json:"gpus,omitempty"`
+	Memory    string `json:"memory,omitempty"`
+	WallTime  string `json:"wall_time"`
+	Count     int    `json:"count"`
+}
+
+// SimulationRequest asks how a set of hypothetical jobs would affect an account's budget
+type SimulationRequest struct {
+	Jobs       []SimulatedJob `json:"jobs"`
+	PeriodDays int            `json:"period_days,omitempty"` // Days over which the jobs run; defaults to 7
+	Deadline   *time.Time     `json:"deadline,omitempty"`    // Defaults to the account end date
+}
+
+// SimulatedJobCost is the estimated cost of one simulated job shape
+type SimulatedJobCost struct {
+	SimulatedJob
+	UnitCost  float64 `json:"unit_cost"`
+	TotalCost float64 `json:"total_cost"`
+}
+
+// SimulationResponse is the projected budget outcome of a simulation. Nothing is written.
+type SimulationResponse struct {
+	Account                string             `json:"account"`
+	Jobs                   []SimulatedJobCost `json:"jobs"`
+	SimulatedCost          float64            `json:"simulated_cost"`
+	CurrentAvailable       float64            `json:"current_available"`
+	ProjectedAvailable     float64            `json:"projected_available"`
+	CurrentDailyBurnRate   float64            `json:"current_daily_burn_rate"`
+	ProjectedDailyBurnRate float64            `json:"projected_daily_burn_rate"` // Over the simulated period
+	PeriodDays             int                `json:"period_days"`
+	ProjectedDepletionDate *time.Time         `json:"projected_depletion_date,omitempty"` // Unset when the budget never runs out
+	Deadline               time.Time          `json:"deadline"`
+	DepletesBeforeDeadline bool               `json:"depletes_before_deadline"`
+	FailureMode            string             `json:"failure_mode,omitempty"` // Set when costs came from the fallback estimator
+}
+
+// AtRiskAccount is an active account whose budget is projected to run out soon at its
+// recent burn rate
+type AtRiskAccount struct {
+	Account                string    `json:"account"`
+	Description            string    `json:"description,omitempty"`
+	BudgetLimit            float64   `json:"budget_limit"`
+	BudgetAvailable        float64   `json:"budget_available"`
+	DailyBurnRate          float64   `json:"daily_burn_rate"`
+	ProjectedDepletionDate time.Time `json:"projected_depletion_date"`
+	DaysUntilDepletion     float64   `json:"days_until_depletion"`
+}
+
+// AtRiskAccountsResponse lists the accounts projected to deplete within a window, soonest
+// first
+type AtRiskAccountsResponse struct {
+	WithinDays int             `json:"within_days"`
+	Accounts   []AtRiskAccount `json:"accounts"`
+}
+
 // BudgetAlert represents automated budget alerts
 type BudgetAlert struct {
 	ID             int64      `json:"id" db:"id"`
@@ -505,6 +1647,94 @@ type BudgetAlert struct {
 	Status         string     `json:"status" db:"status"`
 }
 
+// Notification is what a recipient is sent about the alerts on the accounts they follow:
+// one alert as it is raised, or a digest of the alerts held for them since the last one
+type Notification struct {
+	Recipient string             `json:"recipient"`
+	Digest    bool               `json:"digest"`
+	Subject   string             `json:"subject"`
+	Items     []NotificationItem `json:"items"`
+}
+
+// NotificationItem is one alert in a notification
+type NotificationItem struct {
+	AlertID   int64     `json:"alert_id" db:"alert_id"`
+	Account   string    `json:"account" db:"account"`
+	AlertType string    `json:"alert_type" db:"alert_type"`
+	Severity  string    `json:"severity" db:"severity"`
+	Message   string    `json:"message" db:"message"`
+	RaisedAt  time.Time `json:"raised_at" db:"raised_at"`
+}
+
+// Where a queued notification's delivery stands
+const (
+	NotificationPending   = "pending"   // Waiting to be sent, or to be tried again
+	NotificationDelivered = "delivered" // Accepted by the webhook
+	NotificationFailed    = "failed"    // Given up on after budget.notification_max_attempts tries
+)
+
+// NotificationRecord is a queued notification and the outcome of its delivery attempts
+type NotificationRecord struct {
+	ID            int64         `json:"id"`
+	Recipient     string        `json:"recipient"`
+	Status        string        `json:"status"`
+	Attempts      int           `json:"attempts"`
+	LastError     string        `json:"last_error,omitempty"` // Why the latest attempt failed
+	CreatedAt     time.Time     `json:"created_at"`
+	LastAttemptAt *time.Time    `json:"last_attempt_at,omitempty"`
+	DeliveredAt   *time.Time    `json:"delivered_at,omitempty"`
+	Notification  *Notification `json:"notification"`
+}
+
+// BudgetSnapshot represents an account's balances at the end of a given day
+type BudgetSnapshot struct {
+	ID              int64     `json:"id,omitempty" db:"id"`
+	AccountID       int64     `json:"account_id" db:"account_id"`
+	SnapshotDate    time.Time `json:"snapshot_date" db:"snapshot_date"`
+	BudgetLimit     float64   `json:"budget_limit" db:"budget_limit"`
+	BudgetUsed      float64   `json:"budget_used" db:"budget_used"`
+	BudgetHeld      float64   `json:"budget_held" db:"budget_held"`
+	BudgetAvailable float64   `json:"budget_available" db:"budget_available"`
+	CapturedAt      time.Time `json:"captured_at" db:"captured_at"`
+	Source          string    `json:"source"` // snapshot, replay
+}
+
+// BudgetLimitChange is a change to an account's budget limit and when it took effect. An
+// account's first entry is the limit it opened with and has no previous limit.
+type BudgetLimitChange struct {
+	ID            int64     `json:"id" db:"id"`
+	AccountID     int64     `json:"account_id" db:"account_id"`
+	PreviousLimit *float64  `json:"previous_limit,omitempty" db:"previous_limit"`
+	BudgetLimit   float64   `json:"budget_limit" db:"budget_limit"`
+	EffectiveAt   time.Time `json:"effective_at" db:"effective_at"`
+}
+
+// GrantReportResponse represents a financial report for a grant over a reporting period
+type GrantReportResponse struct {
+	GrantNumber   string               `json:"grant_number"`
+	ReportType    string               `json:"report_type"`
+	StartDate     time.Time            `json:"start_date"`
+	EndDate       time.Time            `json:"end_date"`
+	Accounts      []GrantAccountReport `json:"accounts"`
+	TotalSpent    float64              `json:"total_spent"`
+	TotalHeld     float64              `json:"total_held"`
+	TotalAwarded  float64              `json:"total_awarded"`
+	PercentSpent  float64              `json:"percent_spent"`
+	BudgetPeriod  int                  `json:"budget_period"`
+	FiscalYear    int                  `json:"fiscal_year"`
+	DaysRemaining int                  `json:"days_remaining"`
+	GeneratedAt   time.Time            `json:"generated_at"`
+}
+
+// GrantAccountReport holds the opening and closing balances of one grant-funded account
+type GrantAccountReport struct {
+	Account         string         `json:"account"`
+	Opening         BudgetSnapshot `json:"opening"`
+	Closing         BudgetSnapshot `json:"closing"`
+	PeriodSpend     float64        `json:"period_spend"`
+	PeriodAllocated float64        `json:"period_allocated"`
+}
+
 // HealthCheckResponse represents service health status
 type HealthCheckResponse struct {
 	Status    string            `json:"status"`
@@ -514,6 +1744,27 @@ type HealthCheckResponse struct {
 	Uptime    string            `json:"uptime"`
 }
 
+// ReadinessResponse represents a readiness check: whether the database answers and every
+// background worker is still ticking
+type ReadinessResponse struct {
+	Ready     bool              `json:"ready"`
+	Timestamp time.Time         `json:"timestamp"`
+	Checks    map[string]string `json:"checks"`
+	Workers   []WorkerStatus    `json:"workers,omitempty"`
+}
+
+// WorkerStatus reports a background worker's liveness. A worker is alive while its last
+// tick is no older than stall_after, a multiple of its interval plus the time one tick may
+// take.
+type WorkerStatus struct {
+	Name               string    `json:"name"`
+	Interval           string    `json:"interval"`
+	StallAfter         string    `json:"stall_after"`
+	LastTick           time.Time `json:"last_tick"`
+	LastTickAgeSeconds float64   `json:"last_tick_age_seconds"`
+	Alive              bool      `json:"alive"`
+}
+
 // MetricsResponse represents Prometheus metrics endpoint response
 type MetricsResponse struct {
 	Metrics []MetricFamily `json:"metrics"`
@@ -537,41 +1788,438 @@ type Metric struct {
 
 // Validate performs basic validation on CreateAccountRequest
 func (car *CreateAccountRequest) Validate() error {
+	var errs ValidationErrors
 	if car.SlurmAccount == "" {
-		return NewValidationError("slurm_account", "is required")
+		errs.Add("slurm_account", "is required")
 	}
 	if car.Name == "" {
-		return NewValidationError("name", "is required")
+		errs.Add("name", "is required")
 	}
 	if car.BudgetLimit <= 0 {
-		return NewValidationError("budget_limit", "must be greater than 0")
+		errs.Add("budget_limit", "must be greater than 0")
 	}
 	if car.EndDate.Before(car.StartDate) {
-		return NewValidationError("end_date", "must be after start_date")
+		errs.Add("end_date", "must be after start_date")
+	}
+	if car.HoldPercentage != nil && *car.HoldPercentage <= 0 {
+		errs.Add("hold_percentage", "must be greater than 0")
+	}
+	if car.ReservedAmount < 0 {
+		errs.Add("reserved_amount", "must not be negative")
+	} else if car.BudgetLimit > 0 && car.ReservedAmount > car.BudgetLimit {
+		errs.Add("reserved_amount", "must not exceed budget_limit")
+	}
+	if _, err := LoadTimezone(car.Timezone); err != nil {
+		errs.Add("timezone", "must be a valid IANA time zone")
+	}
+	if _, err := ParseFiscalYearStart(car.FiscalYearStart); err != nil {
+		errs.Add("fiscal_year_start", "must be MM-DD with a day from 1 to 28")
+	}
+	if car.ParentAccount != "" && car.ParentAccount == car.SlurmAccount {
+		errs.Add("parent_account", "must not be the account itself")
+	}
+	if err := ValidateTags(car.Tags); err != nil {
+		errs.Add("tags", err.Error())
+	}
+	if !ValidEstimationSource(car.EstimationSource) {
+		errs.Add("estimation_source", "must be advisor, fallback or static")
+	}
+	if !ValidBudgetUnit(car.BudgetUnit) {
+		errs.Add("budget_unit", "must be dollars or su")
+	}
+	if car.BudgetUnit == BudgetUnitSU && car.DollarsPerSU == nil {
+		errs.Add("dollars_per_su", "is required for su accounts")
+	}
+	if car.DollarsPerSU != nil {
+		if car.BudgetUnit != BudgetUnitSU {
+			errs.Add("dollars_per_su", "only applies to su accounts")
+		} else if *car.DollarsPerSU <= 0 {
+			errs.Add("dollars_per_su", "must be positive")
+		}
+	}
+	if err := ValidatePartitions(car.AllowedPartitions); err != nil {
+		errs.Add("allowed_partitions", err.Error())
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on CloneAccountRequest. Overrides are checked again
+// against the source account's settings when the clone is built.
+func (car *CloneAccountRequest) Validate() error {
+	var errs ValidationErrors
+	if car.SlurmAccount == "" {
+		errs.Add("slurm_account", "is required")
+	}
+	if car.BudgetLimit != nil && *car.BudgetLimit <= 0 {
+		errs.Add("budget_limit", "must be greater than 0")
+	}
+	if car.StartDate != nil && car.EndDate != nil && car.EndDate.Before(*car.StartDate) {
+		errs.Add("end_date", "must be after start_date")
+	}
+	return errs.Err()
+}
+
+// Validate checks that the export is one this service can import and is complete in
+// itself: every transaction ID is unique, and every transaction a refund or charge settles
+// comes before it. A settled transaction outside the export must already exist where it is
+// imported, which only the import can check.
+func (ae *AccountExport) Validate() error {
+	var errs ValidationErrors
+	if ae.Version != AccountExportVersion {
+		errs.Add("version", fmt.Sprintf("must be %d", AccountExportVersion))
+	}
+	if ae.Account == nil {
+		errs.Add("account", "is required")
+		return errs.Err()
+	}
+	if ae.Account.SlurmAccount == "" {
+		errs.Add("account.slurm_account", "is required")
+	}
+	if ae.ParentAccount == ae.Account.SlurmAccount && ae.ParentAccount != "" {
+		errs.Add("parent_account", "must not be the account itself")
+	}
+
+	partitions := make(map[string]bool, len(ae.PartitionLimits))
+	for i, limit := range ae.PartitionLimits {
+		if limit.Partition == "" {
+			errs.Add(fmt.Sprintf("partition_limits[%d].partition", i), "is required")
+		} else if partitions[limit.Partition] {
+			errs.Add(fmt.Sprintf("partition_limits[%d].partition", i), "is a duplicate")
+		}
+		partitions[limit.Partition] = true
+	}
+
+	seen := make(map[string]bool, len(ae.Transactions))
+	for i, transaction := range ae.Transactions {
+		field := fmt.Sprintf("transactions[%d]", i)
+		if transaction.TransactionID == "" {
+			errs.Add(field+".transaction_id", "is required")
+			continue
+		}
+		if seen[transaction.TransactionID] {
+			errs.Add(field+".transaction_id", "is a duplicate")
+		}
+		if parent := transaction.ParentTransactionID; parent != nil && !seen[*parent] {
+			for _, later := range ae.Transactions[i:] {
+				if later.TransactionID == *parent {
+					errs.Add(field+".parent_transaction_id", "must come before the transaction")
+					break
+				}
+			}
+		}
+		seen[transaction.TransactionID] = true
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on UpdateAccountRequest
+func (uar *UpdateAccountRequest) Validate() error {
+	var errs ValidationErrors
+	if uar.BudgetLimit != nil && *uar.BudgetLimit < 0 {
+		errs.Add("budget_limit", "must not be negative")
+	}
+	if uar.HoldPercentage != nil && *uar.HoldPercentage <= 0 {
+		errs.Add("hold_percentage", "must be greater than 0")
+	}
+	if uar.ReservedAmount != nil && *uar.ReservedAmount < 0 {
+		errs.Add("reserved_amount", "must not be negative")
+	}
+	if uar.Status != nil {
+		switch *uar.Status {
+		case "active", "inactive", "suspended":
+		default:
+			errs.Add("status", "must be active, inactive or suspended")
+		}
+	}
+	if uar.Timezone != nil {
+		if _, err := LoadTimezone(*uar.Timezone); err != nil || *uar.Timezone == "" {
+			errs.Add("timezone", "must be a valid IANA time zone")
+		}
+	}
+	if uar.FiscalYearStart != nil {
+		if _, err := ParseFiscalYearStart(*uar.FiscalYearStart); err != nil {
+			errs.Add("fiscal_year_start", "must be MM-DD with a day from 1 to 28")
+		}
+	}
+	if err := ValidateTags(uar.Tags); err != nil {
+		errs.Add("tags", err.Error())
+	}
+	if uar.EstimationSource != nil && !ValidEstimationSource(*uar.EstimationSource) {
+		errs.Add("estimation_source", "must be advisor, fallback or static")
+	}
+	if uar.DollarsPerSU != nil && *uar.DollarsPerSU <= 0 {
+		errs.Add("dollars_per_su", "must be positive")
+	}
+	if err := ValidatePartitions(uar.AllowedPartitions); err != nil {
+		errs.Add("allowed_partitions", err.Error())
+	}
+	return errs.Err()
+}
+
+// ValidatePartitions checks an account's allowed partitions: names that are non-empty,
+// contain no whitespace and are not listed twice
+func ValidatePartitions(partitions []string) error {
+	seen := make(map[string]bool, len(partitions))
+	for _, partition := range partitions {
+		if partition == "" || strings.IndexFunc(partition, unicode.IsSpace) >= 0 {
+			return fmt.Errorf("partition %q must be non-empty and contain no whitespace", partition)
+		}
+		if seen[strings.ToLower(partition)] {
+			return fmt.Errorf("partition %q is listed more than once", partition)
+		}
+		seen[strings.ToLower(partition)] = true
+	}
+	return nil
+}
+
+// Validate performs basic validation on AccountTransferRequest
+func (atr *AccountTransferRequest) Validate() error {
+	switch atr.Schedules {
+	case "", TransferSchedulesMerge, TransferSchedulesRetire:
+	default:
+		return NewValidationError("schedules", "must be merge or retire")
 	}
 	return nil
 }
 
+// Validate performs basic validation on CreateAllocationScheduleRequest. A one-time
+// supplement is a schedule whose allocation amount is its total budget.
+func (casr *CreateAllocationScheduleRequest) Validate() error {
+	var errs ValidationErrors
+	if casr.TotalBudget <= 0 {
+		errs.Add("total_budget", "must be greater than 0")
+	}
+	if casr.AllocationAmount <= 0 {
+		errs.Add("allocation_amount", "must be greater than 0")
+	} else if casr.TotalBudget > 0 && casr.AllocationAmount > casr.TotalBudget {
+		errs.Add("allocation_amount", "must not exceed total_budget")
+	}
+	if !ValidAllocationFrequency(casr.AllocationFrequency) {
+		errs.Add("allocation_frequency", "must be daily, weekly, monthly, quarterly or yearly")
+	}
+	if casr.StartDate.IsZero() {
+		errs.Add("start_date", "is required")
+	}
+	if casr.EndDate != nil && casr.EndDate.Before(casr.StartDate) {
+		errs.Add("end_date", "must be after start_date")
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on HoldAdjustRequest
+func (har *HoldAdjustRequest) Validate() error {
+	var errs ValidationErrors
+	if har.Amount <= 0 {
+		errs.Add("amount", "must be positive")
+	}
+	if har.Reason == "" {
+		errs.Add("reason", "is required")
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on BudgetAdjustmentRequest
+func (bar *BudgetAdjustmentRequest) Validate() error {
+	var errs ValidationErrors
+	if bar.Amount == 0 {
+		errs.Add("amount", "must not be zero")
+	}
+	if bar.Description == "" {
+		errs.Add("description", "is required")
+	}
+	if bar.FromReserve && bar.Amount < 0 {
+		errs.Add("amount", "must be positive when drawing from the reserve")
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on JobReconcileRequest
+func (jrr *JobReconcileRequest) Validate() error {
+	components := make([]string, 0, len(jrr.CostBreakdown))
+	for component := range jrr.CostBreakdown {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	var errs ValidationErrors
+	for _, component := range components {
+		if component == "" {
+			errs.Add("cost_breakdown", "component names must not be empty")
+		} else if jrr.CostBreakdown[component] < 0 {
+			errs.Add("cost_breakdown", fmt.Sprintf("%s must not be negative", component))
+		}
+	}
+	if _, err := ParseJobMetadata(jrr.JobMetadata); err != nil {
+		errs.Add("job_metadata", err.Error())
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on JobStartedRequest
+func (jsr *JobStartedRequest) Validate() error {
+	var errs ValidationErrors
+	if jsr.JobID == "" {
+		errs.Add("job_id", "is required")
+	}
+	if jsr.TransactionID == "" {
+		errs.Add("transaction_id", "is required")
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on TransactionListRequest
+func (tlr *TransactionListRequest) Validate() error {
+	var errs ValidationErrors
+	if tlr.Limit < 0 || tlr.Limit > MaxTransactionListLimit {
+		errs.Add("limit", fmt.Sprintf("must be between 1 and %d", MaxTransactionListLimit))
+	}
+	if tlr.Offset < 0 {
+		errs.Add("offset", "must not be negative")
+	}
+	if tlr.Cursor != "" {
+		if _, err := ParseTransactionCursor(tlr.Cursor); err != nil {
+			errs.Add("cursor", "is not a valid transaction cursor")
+		} else if tlr.Offset > 0 {
+			errs.Add("offset", "cannot be combined with cursor")
+		}
+	}
+	return errs.Err()
+}
+
 // Validate performs basic validation on BudgetCheckRequest
 func (bcr *BudgetCheckRequest) Validate() error {
+	var errs ValidationErrors
 	if bcr.Account == "" {
-		return NewValidationError("account", "is required")
+		errs.Add("account", "is required")
 	}
 	if bcr.Partition == "" {
-		return NewValidationError("partition", "is required")
+		errs.Add("partition", "is required")
 	}
 	if bcr.Nodes < 1 {
-		return NewValidationError("nodes", "must be at least 1")
+		errs.Add("nodes", "must be at least 1")
 	}
 	if bcr.CPUs < 1 {
-		return NewValidationError("cpus", "must be at least 1")
+		errs.Add("cpus", "must be at least 1")
 	}
-	if bcr.WallTime == "" {
-		return NewValidationError("wall_time", "is required")
+	validateWallTime("wall_time", bcr.WallTime, &errs)
+	validateCostShares(bcr.Account, bcr.CostShares, &errs)
+	return errs.Err()
+}
+
+// costSharePrecision is how far cost share percentages may sum from 100, allowing for
+// splits such as thirds written to a few decimal places
+const costSharePrecision = 0.01
+
+// validateCostShares checks that a cost-shared job names each funding account once,
+// including the job's own account, with positive percentages summing to 100
+func validateCostShares(account string, shares []CostShare, errs *ValidationErrors) {
+	if len(shares) == 0 {
+		return
+	}
+
+	seen := make(map[string]bool, len(shares))
+	total := 0.0
+	for i, share := range shares {
+		field := fmt.Sprintf("cost_shares[%d]", i)
+		if share.Account == "" {
+			errs.Add(field+".account", "is required")
+		} else if seen[share.Account] {
+			errs.Add(field+".account", "must not repeat another share's account")
+		}
+		seen[share.Account] = true
+		if share.Percentage <= 0 || share.Percentage > 100 {
+			errs.Add(field+".percentage", "must be greater than 0 and at most 100")
+		}
+		total += share.Percentage
+	}
+	if math.Abs(total-100) > costSharePrecision {
+		errs.Add("cost_shares", fmt.Sprintf("percentages must sum to 100, not %g", total))
+	}
+	if account != "" && !seen[account] {
+		errs.Add("cost_shares", "must include the job's account")
+	}
+}
+
+// Validate performs basic validation on JobValidateRequest
+func (jvr *JobValidateRequest) Validate() error {
+	if strings.TrimSpace(jvr.Script) == "" {
+		return NewValidationError("script", "is required")
 	}
 	return nil
 }
 
+// Validate performs basic validation on EstimateRequest
+func (er *EstimateRequest) Validate() error {
+	var errs ValidationErrors
+	if er.Partition == "" {
+		errs.Add("partition", "is required")
+	}
+	if er.Nodes < 1 {
+		errs.Add("nodes", "must be at least 1")
+	}
+	if er.CPUs < 1 {
+		errs.Add("cpus", "must be at least 1")
+	}
+	if er.GPUs < 0 {
+		errs.Add("gpus", "must not be negative")
+	}
+	validateWallTime("wall_time", er.WallTime, &errs)
+	return errs.Err()
+}
+
+// Validate performs basic validation on AccountingReconcileRequest
+func (arr *AccountingReconcileRequest) Validate() error {
+	if len(arr.Jobs) == 0 {
+		return NewValidationError("jobs", "at least one job is required")
+	}
+	var errs ValidationErrors
+	for i, job := range arr.Jobs {
+		field := fmt.Sprintf("jobs[%d]", i)
+		if job == nil {
+			errs.Add(field, "is required")
+			continue
+		}
+		if job.JobID == "" {
+			errs.Add(field+".job_id", "is required")
+		}
+		if job.Account == "" {
+			errs.Add(field+".account", "is required")
+		}
+		if job.ElapsedSeconds < 0 {
+			errs.Add(field+".elapsed_seconds", "must not be negative")
+		}
+	}
+	return errs.Err()
+}
+
+// Validate performs basic validation on SimulationRequest
+func (sr *SimulationRequest) Validate() error {
+	var errs ValidationErrors
+	if len(sr.Jobs) == 0 {
+		errs.Add("jobs", "at least one job is required")
+	}
+	for i, job := range sr.Jobs {
+		field := fmt.Sprintf("jobs[%d]", i)
+		if job.Partition == "" {
+			errs.Add(field+".partition", "is required")
+		}
+		if job.Nodes < 1 {
+			errs.Add(field+".nodes", "must be at least 1")
+		}
+		if job.CPUs < 1 {
+			errs.Add(field+".cpus", "must be at least 1")
+		}
+		validateWallTime(field+".wall_time", job.WallTime, &errs)
+		if job.Count < 1 {
+			errs.Add(field+".count", "must be at least 1")
+		}
+	}
+	if sr.PeriodDays < 0 {
+		errs.Add("period_days", "must not be negative")
+	}
+	return errs.Err()
+}
+
 // String returns a string representation of the account
 func (ba *BudgetAccount) String() string {
 	return fmt.Sprintf("BudgetAccount{Account: %s, Name: %s, Limit: %.2f, Used: %.2f, Available: %.2f}",
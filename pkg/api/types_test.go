@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBudgetAccount_BudgetAvailable(t *testing.T) {
@@ -53,6 +54,15 @@ func TestBudgetAccount_BudgetAvailable(t *testing.T) {
 	}
 }
 
+func TestBudgetAccount_SpendableAvailable(t *testing.T) {
+	account := BudgetAccount{BudgetLimit: 1000.0, BudgetUsed: 300.0, BudgetHeld: 200.0}
+	assert.Equal(t, 500.0, account.SpendableAvailable())
+
+	account.ReservedAmount = 150.0
+	assert.Equal(t, 500.0, account.BudgetAvailable())
+	assert.Equal(t, 350.0, account.SpendableAvailable())
+}
+
 func TestBudgetAccount_IsActive(t *testing.T) {
 	now := time.Now()
 
@@ -203,6 +213,331 @@ func TestCreateAccountRequest_Validate(t *testing.T) {
 	}
 }
 
+func TestCreateAccountRequest_Validate_HoldPercentage(t *testing.T) {
+	now := time.Now()
+	valid := 1.05
+	invalid := 0.0
+
+	req := CreateAccountRequest{
+		SlurmAccount:   "proj001",
+		Name:           "Test Project",
+		BudgetLimit:    1000.0,
+		StartDate:      now,
+		EndDate:        now.Add(24 * time.Hour),
+		HoldPercentage: &valid,
+	}
+	assert.NoError(t, req.Validate())
+
+	req.HoldPercentage = &invalid
+	assert.Error(t, req.Validate())
+}
+
+func TestUpdateAccountRequest_Validate(t *testing.T) {
+	valid := 1.5
+	invalid := -1.0
+
+	assert.NoError(t, (&UpdateAccountRequest{}).Validate())
+	assert.NoError(t, (&UpdateAccountRequest{HoldPercentage: &valid}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{HoldPercentage: &invalid}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{BudgetLimit: &invalid}).Validate())
+	assert.NoError(t, (&UpdateAccountRequest{ReservedAmount: &valid}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{ReservedAmount: &invalid}).Validate())
+
+	suspended := "suspended"
+	expired := "expired"
+	assert.NoError(t, (&UpdateAccountRequest{Status: &suspended}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{Status: &expired}).Validate())
+}
+
+func TestAccountRequests_Validate_FiscalYearStart(t *testing.T) {
+	now := time.Now()
+	req := CreateAccountRequest{
+		SlurmAccount:    "proj001",
+		Name:            "Test Project",
+		BudgetLimit:     1000.0,
+		StartDate:       now,
+		EndDate:         now.Add(24 * time.Hour),
+		FiscalYearStart: "07-01",
+	}
+	assert.NoError(t, req.Validate())
+
+	req.FiscalYearStart = "07-31"
+	assert.Error(t, req.Validate())
+
+	july := "07-01"
+	cleared := ""
+	invalid := "July"
+	assert.NoError(t, (&UpdateAccountRequest{FiscalYearStart: &july}).Validate())
+	assert.NoError(t, (&UpdateAccountRequest{FiscalYearStart: &cleared}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{FiscalYearStart: &invalid}).Validate())
+}
+
+func TestCreateAccountRequest_Validate_ReportsEveryField(t *testing.T) {
+	now := time.Now()
+	req := CreateAccountRequest{
+		SlurmAccount: "proj001",
+		BudgetLimit:  -5,
+		StartDate:    now,
+		EndDate:      now.Add(24 * time.Hour),
+		Timezone:     "Mars/Olympus_Mons",
+	}
+
+	budgetErr, ok := AsBudgetError(req.Validate())
+	if assert.True(t, ok) {
+		assert.Equal(t, ErrCodeValidation, budgetErr.Code)
+		assert.Equal(t, []FieldError{
+			{Field: "name", Message: "is required"},
+			{Field: "budget_limit", Message: "must be greater than 0"},
+			{Field: "timezone", Message: "must be a valid IANA time zone"},
+		}, budgetErr.ValidationErrors)
+		assert.Equal(t, "name", budgetErr.Field)
+	}
+}
+
+func TestBudgetCheckRequest_Validate_ReportsEveryField(t *testing.T) {
+	req := BudgetCheckRequest{Account: "proj001", Partition: "cpu", CPUs: 4}
+
+	budgetErr, ok := AsBudgetError(req.Validate())
+	if assert.True(t, ok) {
+		assert.Len(t, budgetErr.ValidationErrors, 2)
+	}
+
+	req = BudgetCheckRequest{Account: "proj001", Nodes: 0, CPUs: 0, WallTime: "01:00:00"}
+	budgetErr, ok = AsBudgetError(req.Validate())
+	if assert.True(t, ok) {
+		var fields []string
+		for _, fe := range budgetErr.ValidationErrors {
+			fields = append(fields, fe.Field)
+		}
+		assert.Equal(t, []string{"partition", "nodes", "cpus"}, fields)
+	}
+}
+
+func TestBudgetCheckRequest_Validate_CostShares(t *testing.T) {
+	valid := BudgetCheckRequest{Account: "proj001", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00"}
+	fields := func(shares []CostShare) []string {
+		req := valid
+		req.CostShares = shares
+		budgetErr, ok := AsBudgetError(req.Validate())
+		if !ok {
+			return nil
+		}
+		var fields []string
+		for _, fe := range budgetErr.ValidationErrors {
+			fields = append(fields, fe.Field)
+		}
+		return fields
+	}
+
+	assert.Nil(t, fields(nil))
+	assert.Nil(t, fields([]CostShare{{Account: "proj001", Percentage: 60}, {Account: "proj002", Percentage: 40}}))
+	assert.Nil(t, fields([]CostShare{{Account: "proj002", Percentage: 33.33}, {Account: "proj001", Percentage: 33.33}, {Account: "proj003", Percentage: 33.34}}))
+
+	assert.Equal(t, []string{"cost_shares"}, fields([]CostShare{{Account: "proj001", Percentage: 60}, {Account: "proj002", Percentage: 30}}))
+	assert.Equal(t, []string{"cost_shares"}, fields([]CostShare{{Account: "proj002", Percentage: 60}, {Account: "proj003", Percentage: 40}}))
+	assert.Equal(t, []string{"cost_shares[1].account"}, fields([]CostShare{{Account: "proj001", Percentage: 50}, {Account: "proj001", Percentage: 50}}))
+
+	// Every bad share is reported, not just the first
+	assert.Equal(t, []string{"cost_shares[0].percentage", "cost_shares[1].account", "cost_shares[1].percentage", "cost_shares"},
+		fields([]CostShare{{Account: "proj001", Percentage: -10}, {Account: "", Percentage: 120}}))
+}
+
+func TestBudgetAccount_AllowsPartition(t *testing.T) {
+	account := &BudgetAccount{SlurmAccount: "phys101"}
+	assert.True(t, account.AllowsPartition("gpu"), "no list allows every partition")
+
+	account.AllowedPartitions = []string{"cpu"}
+	assert.True(t, account.AllowsPartition("cpu"))
+	assert.True(t, account.AllowsPartition("CPU"))
+	assert.False(t, account.AllowsPartition("gpu"))
+	assert.False(t, account.AllowsPartition("cpu-spot"))
+}
+
+func TestAccountRequests_Validate_AllowedPartitions(t *testing.T) {
+	assert.NoError(t, ValidatePartitions(nil))
+	assert.NoError(t, ValidatePartitions([]string{"cpu", "cpu-spot"}))
+	assert.Error(t, ValidatePartitions([]string{"cpu", ""}))
+	assert.Error(t, ValidatePartitions([]string{"gpu burst"}))
+	assert.Error(t, ValidatePartitions([]string{"cpu", "CPU"}))
+
+	update := UpdateAccountRequest{AllowedPartitions: []string{}}
+	assert.NoError(t, update.Validate(), "an empty list allows every partition again")
+
+	update.AllowedPartitions = []string{"cpu", "cpu"}
+	budgetErr, ok := AsBudgetError(update.Validate())
+	if assert.True(t, ok) {
+		assert.Equal(t, "allowed_partitions", budgetErr.Field)
+	}
+}
+
+func TestAccountRequests_Validate_EstimationSource(t *testing.T) {
+	now := time.Now()
+	req := CreateAccountRequest{
+		SlurmAccount: "proj001",
+		Name:         "Test Project",
+		BudgetLimit:  1000.0,
+		StartDate:    now,
+		EndDate:      now.Add(24 * time.Hour),
+	}
+	for _, source := range []string{"", EstimationSourceAdvisor, EstimationSourceFallback, EstimationSourceStatic} {
+		req.EstimationSource = source
+		assert.NoError(t, req.Validate(), source)
+	}
+
+	req.EstimationSource = "ADVISOR"
+	budgetErr, ok := AsBudgetError(req.Validate())
+	if assert.True(t, ok) {
+		assert.Equal(t, "estimation_source", budgetErr.Field)
+	}
+
+	fallback := EstimationSourceFallback
+	cleared := ""
+	invalid := "cheap"
+	assert.NoError(t, (&UpdateAccountRequest{EstimationSource: &fallback}).Validate())
+	assert.NoError(t, (&UpdateAccountRequest{EstimationSource: &cleared}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{EstimationSource: &invalid}).Validate())
+}
+
+func TestAccountRequests_Validate_BudgetUnit(t *testing.T) {
+	now := time.Now()
+	rate := 0.02
+	zero := 0.0
+	req := CreateAccountRequest{
+		SlurmAccount: "alloc01",
+		Name:         "Allocation",
+		BudgetLimit:  50000,
+		StartDate:    now,
+		EndDate:      now.Add(24 * time.Hour),
+	}
+	assert.NoError(t, req.Validate(), "an account is in dollars by default")
+
+	req.BudgetUnit = BudgetUnitSU
+	req.DollarsPerSU = &rate
+	assert.NoError(t, req.Validate())
+
+	for name, modify := range map[string]func(*CreateAccountRequest){
+		"budget_unit":    func(r *CreateAccountRequest) { r.BudgetUnit = "hours" },
+		"dollars_per_su": func(r *CreateAccountRequest) { r.DollarsPerSU = nil },
+	} {
+		invalid := req
+		modify(&invalid)
+		budgetErr, ok := AsBudgetError(invalid.Validate())
+		if assert.True(t, ok, name) {
+			assert.Equal(t, name, budgetErr.Field)
+		}
+	}
+
+	req.DollarsPerSU = &zero
+	assert.Error(t, req.Validate(), "the rate must be positive")
+	req.BudgetUnit, req.DollarsPerSU = BudgetUnitDollars, &rate
+	assert.Error(t, req.Validate(), "a dollar account has no rate")
+
+	assert.NoError(t, (&UpdateAccountRequest{DollarsPerSU: &rate}).Validate())
+	assert.Error(t, (&UpdateAccountRequest{DollarsPerSU: &zero}).Validate())
+}
+
+func TestBudgetAccount_FromDollars(t *testing.T) {
+	rate := 0.04
+	assert.Equal(t, 10.0, (&BudgetAccount{}).FromDollars(10))
+	assert.Equal(t, 10.0, (&BudgetAccount{BudgetUnit: BudgetUnitDollars}).FromDollars(10))
+	assert.InDelta(t, 250.0, (&BudgetAccount{BudgetUnit: BudgetUnitSU, DollarsPerSU: &rate}).FromDollars(10), 1e-9)
+	assert.Equal(t, BudgetUnitDollars, (&BudgetAccount{}).Unit())
+}
+
+func TestJobReconcileRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&JobReconcileRequest{JobID: "1"}).Validate())
+	assert.NoError(t, (&JobReconcileRequest{JobID: "1", CostBreakdown: map[string]float64{"compute": 8, "storage": 0}}).Validate())
+	assert.Error(t, (&JobReconcileRequest{JobID: "1", CostBreakdown: map[string]float64{"compute": -1}}).Validate())
+	assert.Error(t, (&JobReconcileRequest{JobID: "1", CostBreakdown: map[string]float64{"": 2}}).Validate())
+}
+
+func TestJobStartedRequest_Validate(t *testing.T) {
+	assert.NoError(t, (&JobStartedRequest{JobID: "1", TransactionID: "txn_1"}).Validate())
+	assert.Error(t, (&JobStartedRequest{JobID: "1"}).Validate())
+	assert.Error(t, (&JobStartedRequest{TransactionID: "txn_1"}).Validate())
+}
+
+func TestTransactionListRequest_Validate(t *testing.T) {
+	cursor := TransactionCursor{CreatedAt: time.Now(), ID: 7}.Encode()
+
+	assert.NoError(t, (&TransactionListRequest{}).Validate())
+	assert.NoError(t, (&TransactionListRequest{Limit: 50, Offset: 100}).Validate())
+	assert.NoError(t, (&TransactionListRequest{Limit: 50, Cursor: cursor}).Validate())
+	assert.Error(t, (&TransactionListRequest{Limit: MaxTransactionListLimit + 1}).Validate())
+	assert.Error(t, (&TransactionListRequest{Offset: -1}).Validate())
+	assert.Error(t, (&TransactionListRequest{Cursor: "garbage"}).Validate())
+	assert.Error(t, (&TransactionListRequest{Offset: 100, Cursor: cursor}).Validate(), "cursor and offset are exclusive")
+}
+
+func TestCreateAccountRequest_Validate_ReservedAmount(t *testing.T) {
+	now := time.Now()
+	req := CreateAccountRequest{
+		SlurmAccount:   "proj001",
+		Name:           "Test Project",
+		BudgetLimit:    1000.0,
+		StartDate:      now,
+		EndDate:        now.Add(24 * time.Hour),
+		ReservedAmount: 250.0,
+	}
+	assert.NoError(t, req.Validate())
+
+	req.ReservedAmount = 1500.0
+	assert.Error(t, req.Validate())
+
+	req.ReservedAmount = -1.0
+	assert.Error(t, req.Validate())
+}
+
+func TestBudgetAdjustmentRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request BudgetAdjustmentRequest
+		wantErr bool
+	}{
+		{"spend", BudgetAdjustmentRequest{Amount: 50, Description: "invoice"}, false},
+		{"credit", BudgetAdjustmentRequest{Amount: -50, Description: "refund"}, false},
+		{"draw from reserve", BudgetAdjustmentRequest{Amount: 50, Description: "invoice", FromReserve: true}, false},
+		{"zero amount", BudgetAdjustmentRequest{Amount: 0, Description: "noop"}, true},
+		{"missing description", BudgetAdjustmentRequest{Amount: 50}, true},
+		{"credit to reserve", BudgetAdjustmentRequest{Amount: -50, Description: "x", FromReserve: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHoldAdjustRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request HoldAdjustRequest
+		wantErr bool
+	}{
+		{"valid", HoldAdjustRequest{Amount: 80, Reason: "estimate too low"}, false},
+		{"zero amount", HoldAdjustRequest{Amount: 0, Reason: "release"}, true},
+		{"negative amount", HoldAdjustRequest{Amount: -5, Reason: "x"}, true},
+		{"missing reason", HoldAdjustRequest{Amount: 80}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestBudgetCheckRequest_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -272,6 +607,28 @@ func TestBudgetCheckRequest_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "wall time in days",
+			request: BudgetCheckRequest{
+				Account:   "proj001",
+				Partition: "cpu",
+				Nodes:     1,
+				CPUs:      4,
+				WallTime:  "2-00:00:00",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unreadable wall time",
+			request: BudgetCheckRequest{
+				Account:   "proj001",
+				Partition: "cpu",
+				Nodes:     1,
+				CPUs:      4,
+				WallTime:  "two days",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -338,3 +695,130 @@ func BenchmarkBudgetAccount_IsActive(b *testing.B) {
 		_ = account.IsActive()
 	}
 }
+
+func TestOverviewTotals_WithUtilization(t *testing.T) {
+	totals := OverviewTotals{Accounts: 2, TotalAllocated: 1000.0, TotalSpent: 300.0, TotalHeld: 100.0}.WithUtilization()
+	assert.InDelta(t, 40.0, totals.Utilization, 0.001)
+
+	assert.Zero(t, OverviewTotals{TotalSpent: 50.0}.WithUtilization().Utilization)
+}
+
+func TestASBAFeedbackRequest_Validate(t *testing.T) {
+	valid := func() *ASBAFeedbackRequest {
+		return &ASBAFeedbackRequest{
+			Account: "proj001", DecisionID: 3, RecommendedAction: BurstActionAWS,
+			ActualLocation: BurstActionLocal, ActualCost: 4.5,
+		}
+	}
+	require.NoError(t, valid().Validate())
+
+	byTransaction := valid()
+	byTransaction.DecisionID = 0
+	byTransaction.TransactionID = "txn_1"
+	require.NoError(t, byTransaction.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*ASBAFeedbackRequest)
+		field  string
+	}{
+		{name: "no account", modify: func(r *ASBAFeedbackRequest) { r.Account = "" }, field: "account"},
+		{name: "no decision", modify: func(r *ASBAFeedbackRequest) { r.DecisionID = 0 }, field: "decision_id"},
+		{name: "decision and transaction", modify: func(r *ASBAFeedbackRequest) { r.TransactionID = "txn_1" }, field: "decision_id"},
+		{name: "unknown action", modify: func(r *ASBAFeedbackRequest) { r.RecommendedAction = "CLOUD" }, field: "recommended_action"},
+		{name: "deferred location", modify: func(r *ASBAFeedbackRequest) { r.ActualLocation = BurstActionDefer }, field: "actual_location"},
+		{name: "negative cost", modify: func(r *ASBAFeedbackRequest) { r.ActualCost = -1 }, field: "actual_cost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+			budgetErr, ok := AsBudgetError(req.Validate())
+			require.True(t, ok)
+			assert.Equal(t, tt.field, budgetErr.Field)
+		})
+	}
+}
+
+func TestAccountExport_Validate(t *testing.T) {
+	parent := "txn_1"
+	valid := func() *AccountExport {
+		return &AccountExport{
+			Version:         AccountExportVersion,
+			Account:         &BudgetAccount{SlurmAccount: "proj001"},
+			ParentAccount:   "dept",
+			PartitionLimits: []BudgetPartitionLimit{{Partition: "gpu"}, {Partition: "cpu"}},
+			Transactions: []BudgetTransaction{
+				{TransactionID: "txn_1"},
+				{TransactionID: "txn_2", ParentTransactionID: &parent},
+			},
+		}
+	}
+	require.NoError(t, valid().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*AccountExport)
+		field  string
+	}{
+		{name: "unknown version", modify: func(e *AccountExport) { e.Version = 2 }, field: "version"},
+		{name: "no slurm account", modify: func(e *AccountExport) { e.Account.SlurmAccount = "" }, field: "account.slurm_account"},
+		{name: "own parent", modify: func(e *AccountExport) { e.ParentAccount = "proj001" }, field: "parent_account"},
+		{name: "duplicate partition", modify: func(e *AccountExport) { e.PartitionLimits[1].Partition = "gpu" }, field: "partition_limits[1].partition"},
+		{name: "no transaction id", modify: func(e *AccountExport) { e.Transactions[0].TransactionID = "" }, field: "transactions[0].transaction_id"},
+		{name: "duplicate transaction", modify: func(e *AccountExport) { e.Transactions[1].TransactionID = "txn_1" }, field: "transactions[1].transaction_id"},
+		{name: "parent after child", modify: func(e *AccountExport) {
+			e.Transactions[0], e.Transactions[1] = e.Transactions[1], e.Transactions[0]
+		}, field: "transactions[0].parent_transaction_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			export := valid()
+			tt.modify(export)
+			budgetErr, ok := AsBudgetError(export.Validate())
+			require.True(t, ok)
+			assert.Equal(t, tt.field, budgetErr.Field)
+		})
+	}
+
+	_, ok := AsBudgetError((&AccountExport{Version: AccountExportVersion}).Validate())
+	assert.True(t, ok, "an export without an account is invalid")
+}
+
+func TestCreateAllocationScheduleRequest_Validate(t *testing.T) {
+	valid := func() *CreateAllocationScheduleRequest {
+		return &CreateAllocationScheduleRequest{
+			TotalBudget: 1200, AllocationAmount: 100, AllocationFrequency: "monthly",
+			StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), AutoAllocate: true,
+		}
+	}
+	require.NoError(t, valid().Validate())
+
+	// A one-time supplement allocates its whole budget at once
+	once := valid()
+	once.AllocationAmount = once.TotalBudget
+	require.NoError(t, once.Validate())
+
+	before := time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		modify func(*CreateAllocationScheduleRequest)
+		field  string
+	}{
+		{name: "no budget", modify: func(r *CreateAllocationScheduleRequest) { r.TotalBudget = 0 }, field: "total_budget"},
+		{name: "no amount", modify: func(r *CreateAllocationScheduleRequest) { r.AllocationAmount = -1 }, field: "allocation_amount"},
+		{name: "amount over budget", modify: func(r *CreateAllocationScheduleRequest) { r.AllocationAmount = 1500 }, field: "allocation_amount"},
+		{name: "unknown frequency", modify: func(r *CreateAllocationScheduleRequest) { r.AllocationFrequency = "hourly" }, field: "allocation_frequency"},
+		{name: "no start", modify: func(r *CreateAllocationScheduleRequest) { r.StartDate = time.Time{} }, field: "start_date"},
+		{name: "end before start", modify: func(r *CreateAllocationScheduleRequest) { r.EndDate = &before }, field: "end_date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.modify(req)
+			budgetErr, ok := AsBudgetError(req.Validate())
+			require.True(t, ok)
+			assert.Equal(t, tt.field, budgetErr.Field)
+		})
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MinWallTimeHours is the shortest duration a job is priced or scheduled for
+	MinWallTimeHours = 1.0 / 60.0
+
+	// MaxWallTimeHours caps the duration a job is priced or scheduled for, so a mistyped
+	// wall time cannot produce an absurd cost
+	MaxWallTimeHours = 365 * 24.0
+)
+
+// ParseWallTime converts a SLURM wall time to hours. It accepts minutes, HH:MM, HH:MM:SS
+// and, with a days prefix, D-HH, D-HH:MM and D-HH:MM:SS. A wall time that cannot be read
+// comes back as the one-minute minimum and one beyond a year is capped; either way an
+// error explains the duration is unreliable.
+func ParseWallTime(wallTime string) (float64, error) {
+	hours, err := wallTimeHours(strings.TrimSpace(wallTime))
+	if err != nil {
+		return MinWallTimeHours, fmt.Errorf("wall time %q not understood: %w", wallTime, err)
+	}
+
+	if hours > MaxWallTimeHours {
+		return MaxWallTimeHours, fmt.Errorf("wall time %q capped at %.0f days", wallTime, MaxWallTimeHours/24)
+	}
+
+	// Minimum of 1 minute
+	if hours < MinWallTimeHours {
+		hours = MinWallTimeHours
+	}
+
+	return hours, nil
+}
+
+// validateWallTime reports a required wall time that is missing or cannot be read
+func validateWallTime(field, wallTime string, errs *ValidationErrors) {
+	if wallTime == "" {
+		errs.Add(field, "is required")
+		return
+	}
+	if _, err := wallTimeHours(strings.TrimSpace(wallTime)); err != nil {
+		errs.Add(field, "must be a SLURM time limit such as 2:00:00 or 1-12:00:00")
+	}
+}
+
+// wallTimeHours reads a wall time in any of the forms ParseWallTime accepts
+func wallTimeHours(wallTime string) (float64, error) {
+	var days float64
+	clock := wallTime
+	if d, rest, ok := strings.Cut(wallTime, "-"); ok {
+		value, err := wallTimeField(d, "days")
+		if err != nil {
+			return 0, err
+		}
+		days = value
+		clock = rest
+	}
+
+	fields := strings.Split(clock, ":")
+	values := make([]float64, len(fields))
+	for i, field := range fields {
+		value, err := wallTimeField(field, "field")
+		if err != nil {
+			return 0, err
+		}
+		values[i] = value
+	}
+
+	var hours, minutes, seconds float64
+	switch {
+	case len(values) == 3: // [D-]HH:MM:SS
+		hours, minutes, seconds = values[0], values[1], values[2]
+	case len(values) == 2: // [D-]HH:MM
+		hours, minutes = values[0], values[1]
+	case len(values) == 1 && clock != wallTime: // D-HH
+		hours = values[0]
+	case len(values) == 1: // Assume minutes
+		return values[0] / 60.0, nil
+	default:
+		return 0, fmt.Errorf("too many fields")
+	}
+
+	return days*24 + hours + minutes/60.0 + seconds/3600.0, nil
+}
+
+// wallTimeField reads one whole, non-negative number from a wall time
+func wallTimeField(field, name string) (float64, error) {
+	value, err := strconv.ParseUint(field, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, field)
+	}
+	return float64(value), nil
+}
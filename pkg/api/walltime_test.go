@@ -0,0 +1,54 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWallTime(t *testing.T) {
+	tests := []struct {
+		wallTime  string
+		wantHours float64
+	}{
+		{"04:00:00", 4},
+		{"01:30:00", 1.5},
+		{"30:00:00", 30},
+		{"02:30", 2.5},
+		{"90", 1.5},
+		{"0", MinWallTimeHours},
+		{" 01:00:00 ", 1},
+		// Days prefix
+		{"2-00:00:00", 48},
+		{"1-12:30:00", 36.5},
+		{"2-12:00:00", 60},
+		{"3-06:15", 78.25},
+		{"1-06", 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wallTime, func(t *testing.T) {
+			got, err := ParseWallTime(tt.wallTime)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantHours, got, 1e-9)
+		})
+	}
+
+	t.Run("capped", func(t *testing.T) {
+		got, err := ParseWallTime("9999-00:00:00")
+		assert.Error(t, err)
+		assert.InDelta(t, MaxWallTimeHours, got, 1e-9)
+	})
+
+	for _, wallTime := range []string{"", "soon", "1:2:3:4", "-1:00:00", "1-", "x-01:00:00", "01:xx:00", "1.5:00:00"} {
+		t.Run("invalid "+wallTime, func(t *testing.T) {
+			got, err := ParseWallTime(wallTime)
+			assert.Error(t, err)
+			assert.InDelta(t, MinWallTimeHours, got, 1e-9)
+		})
+	}
+}
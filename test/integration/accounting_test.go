@@ -0,0 +1,115 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_ReconcileAccountingJobs(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	// Jobs are checked for two hours but only run for one, at $5 an hour
+	var priced []*budget.CostEstimateRequest
+	estimator := &advisor.MockClient{
+		EstimateFunc: func(_ context.Context, req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
+			priced = append(priced, req)
+			cost := 10.0
+			if req.WallTime == "01:00:00" {
+				cost = 5.0
+			}
+			return &budget.CostEstimateResponse{EstimatedCost: cost, Confidence: 0.9}, nil
+		},
+	}
+	service := budget.NewService(db, estimator, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "sacct-project",
+		Name:         "Accounting Project",
+		BudgetLimit:  100.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+		Account: "sacct-project", Partition: "aws-cpu", Nodes: 2, CPUs: 8, WallTime: "02:00:00",
+	})
+	require.NoError(t, err)
+	require.True(t, check.Available)
+
+	jobs := []*api.AccountingJob{
+		{JobID: "5001", Account: "sacct-project", Partition: "aws-cpu", State: "COMPLETED",
+			ElapsedSeconds: 3600, Nodes: 2, CPUs: 16, HoldTransactionID: check.TransactionID},
+		{JobID: "5002", Account: "sacct-project", Partition: "local", State: "COMPLETED", ElapsedSeconds: 600, Nodes: 1, CPUs: 4},
+		{JobID: "5003", Account: "sacct-project", Partition: "aws-cpu", State: "RUNNING", ElapsedSeconds: 600, Nodes: 1, CPUs: 4},
+		{JobID: "5004", Account: "missing-project", State: "COMPLETED", ElapsedSeconds: 600},
+	}
+
+	resp, err := service.ReconcileAccountingJobs(ctx, &api.AccountingReconcileRequest{Jobs: jobs})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Reconciled)
+	assert.Equal(t, 1, resp.NoHold)
+	assert.Equal(t, 1, resp.Unfinished)
+	assert.Equal(t, 1, resp.Failed)
+	require.Len(t, resp.Jobs, 4)
+
+	reconciled := resp.Jobs[0]
+	assert.Equal(t, api.AccountingJobReconciled, reconciled.Status)
+	assert.Equal(t, check.TransactionID, reconciled.TransactionID)
+	assert.InDelta(t, 5.0, reconciled.ActualCost, 0.001)
+	assert.InDelta(t, 7.0, reconciled.RefundAmount, 0.001, "the $12 hold is released down to the $5 actual cost")
+
+	require.Len(t, priced, 2, "only the reconciled job is priced")
+	assert.Equal(t, 2, priced[1].Nodes)
+	assert.Equal(t, 8, priced[1].CPUs, "accounting CPUs are priced per node")
+
+	account, err := service.GetAccount(ctx, "sacct-project")
+	require.NoError(t, err)
+	assert.InDelta(t, 5.0, account.BudgetUsed, 0.001)
+
+	t.Run("overlapping runs skip charged jobs", func(t *testing.T) {
+		resp, err := service.ReconcileAccountingJobs(ctx, &api.AccountingReconcileRequest{Jobs: jobs[:1]})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.AlreadyReconciled)
+		assert.Equal(t, api.AccountingJobAlreadyReconciled, resp.Jobs[0].Status)
+
+		account, err := service.GetAccount(ctx, "sacct-project")
+		require.NoError(t, err)
+		assert.InDelta(t, 5.0, account.BudgetUsed, 0.001)
+	})
+
+	t.Run("jobs that never started release their hold", func(t *testing.T) {
+		check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "sacct-project", Partition: "aws-cpu", Nodes: 1, CPUs: 4, WallTime: "02:00:00",
+		})
+		require.NoError(t, err)
+
+		resp, err := service.ReconcileAccountingJobs(ctx, &api.AccountingReconcileRequest{Jobs: []*api.AccountingJob{
+			{JobID: "5005", Account: "sacct-project", State: "CANCELLED by 1000", HoldTransactionID: check.TransactionID},
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Reconciled)
+		assert.Zero(t, resp.Jobs[0].ActualCost)
+		assert.InDelta(t, 12.0, resp.Jobs[0].RefundAmount, 0.001)
+	})
+}
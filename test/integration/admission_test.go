@@ -0,0 +1,147 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_AdmissionFailures(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	// A frozen account asked to run on a partition it does not allow fails two checks
+	frozen := true
+	_, err := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget).CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount:      "admission",
+		Name:              "Admission",
+		BudgetLimit:       500.0,
+		StartDate:         time.Now().Add(-24 * time.Hour),
+		EndDate:           time.Now().Add(365 * 24 * time.Hour),
+		AllowedPartitions: []string{"cpu"},
+	})
+	require.NoError(t, err)
+	_, err = budget.NewService(db, &advisor.MockClient{}, &cfg.Budget).UpdateAccount(ctx, "admission", &api.UpdateAccountRequest{Frozen: &frozen})
+	require.NoError(t, err)
+
+	check := func(mode string) *api.BudgetError {
+		budgetCfg := cfg.Budget
+		budgetCfg.AdmissionFailures = mode
+		_, err := budget.NewService(db, &advisor.MockClient{}, &budgetCfg).CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "admission", Partition: "gpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		return budgetErr
+	}
+
+	t.Run("the first failure is reported", func(t *testing.T) {
+		budgetErr := check("FIRST")
+		assert.Equal(t, api.ErrCodeAccountFrozen, budgetErr.Code, "account status is checked before the partition")
+		assert.Empty(t, budgetErr.CheckFailures)
+	})
+
+	t.Run("every failure is reported", func(t *testing.T) {
+		budgetErr := check("ALL")
+		assert.Equal(t, api.ErrCodeAccountFrozen, budgetErr.Code)
+		require.Len(t, budgetErr.CheckFailures, 2)
+		assert.Equal(t, "account_status", budgetErr.CheckFailures[0].Check)
+		assert.Equal(t, "allowed_partition", budgetErr.CheckFailures[1].Check)
+		assert.Equal(t, api.ErrCodeForbidden, budgetErr.CheckFailures[1].Code)
+	})
+
+	t.Run("each refusal is recorded once", func(t *testing.T) {
+		decisions, err := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget).ListDecisions(ctx,
+			&api.DecisionListRequest{Account: "admission", Decision: api.DecisionRejected})
+		require.NoError(t, err)
+		assert.Len(t, decisions, 2)
+	})
+}
+
+func TestBudget_AdmissionCostChecks(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	account, err := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget).CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "priced",
+		Name:         "Priced Admission",
+		BudgetLimit:  500.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// $5 is left of the account's $20 on the GPU partition, short of the job's $12 hold
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_partition_limits (account_id, partition, limit_amount, used_amount)
+		VALUES ($1, 'gpu', 20, 15)`, account.ID)
+	require.NoError(t, err)
+
+	// The mock advisor prices the job at $10, over a $5 cap on the partition
+	check := func(mode string) *api.BudgetCheckResponse {
+		budgetCfg := cfg.Budget
+		budgetCfg.AdmissionFailures = mode
+		budgetCfg.PartitionMaxSingleJobCost = map[string]float64{"gpu": 5}
+		resp, err := budget.NewService(db, &advisor.MockClient{}, &budgetCfg).CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "priced", Partition: "gpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.False(t, resp.Available)
+		return resp
+	}
+
+	t.Run("the first failure is reported", func(t *testing.T) {
+		resp := check("FIRST")
+		assert.Contains(t, resp.Message, "exceeds the maximum single-job cost")
+		assert.Empty(t, resp.CheckFailures)
+	})
+
+	t.Run("every failure is reported", func(t *testing.T) {
+		resp := check("ALL")
+		assert.Contains(t, resp.Message, "exceeds the maximum single-job cost")
+		require.Len(t, resp.CheckFailures, 2)
+		assert.Equal(t, "max_job_cost", resp.CheckFailures[0].Check)
+		assert.Equal(t, "partition_limit", resp.CheckFailures[1].Check)
+		assert.Equal(t, api.ErrCodePartitionExceeded, resp.CheckFailures[1].Code)
+	})
+
+	t.Run("the partition limit refuses a job under the cap", func(t *testing.T) {
+		service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "priced", Partition: "gpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Available)
+		assert.Contains(t, resp.Message, "Partition limit exceeded for 'gpu'")
+
+		// Other partitions are not limited
+		resp, err = service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "priced", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+	})
+}
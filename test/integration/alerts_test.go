@@ -0,0 +1,156 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestAlerts_EscalateAndResolve(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.AlertWarningThreshold = 80
+	cfg.Budget.AlertCriticalThreshold = 95
+	cfg.Budget.AlertHysteresisMargin = 5
+	service := budget.NewService(db, nil, &cfg.Budget)
+	accountQueries := database.NewAccountQueries(db)
+	alertQueries := database.NewAlertQueries(db)
+	ctx := context.Background()
+
+	account, err := accountQueries.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "test-account-alerts",
+		Name:         "Alert Test Account",
+		BudgetLimit:  100.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	setUsed := func(used float64) {
+		_, err := db.ExecContext(ctx, "UPDATE budget_accounts SET budget_used = $1 WHERE id = $2", used, account.ID)
+		require.NoError(t, err)
+		require.NoError(t, service.EvaluateBudgetAlerts(ctx))
+	}
+
+	setUsed(82)
+	first, err := alertQueries.GetOpenAlert(ctx, account.ID, "budget_threshold")
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	assert.Equal(t, "warning", first.Severity)
+
+	setUsed(79)
+	setUsed(97)
+	escalated, err := alertQueries.GetOpenAlert(ctx, account.ID, "budget_threshold")
+	require.NoError(t, err)
+	require.NotNil(t, escalated)
+	assert.Equal(t, first.ID, escalated.ID)
+	assert.Equal(t, "critical", escalated.Severity)
+	assert.Equal(t, first.TriggeredAt.Unix(), escalated.TriggeredAt.Unix())
+
+	setUsed(70)
+	open, err := alertQueries.GetOpenAlert(ctx, account.ID, "budget_threshold")
+	require.NoError(t, err)
+	assert.Nil(t, open)
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM budget_alerts WHERE account_id = $1 AND resolved_at IS NOT NULL", account.ID).Scan(&count))
+	assert.Equal(t, 1, count)
+}
+
+func TestAlerts_ReconciliationSLA(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.ReconciliationSLA = 24 * time.Hour
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	alertQueries := database.NewAlertQueries(db)
+	ctx := context.Background()
+
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "test-account-sla",
+		Name:         "SLA Test Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-72 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	placeHold := func() string {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "test-account-sla", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp.TransactionID
+	}
+	reconcile := func(txn, jobID string) {
+		_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: jobID, TransactionID: txn, ActualCost: 5.0, JobState: "COMPLETED",
+		})
+		require.NoError(t, err)
+	}
+
+	// A prompt reconciliation stays inside the SLA
+	reconcile(placeHold(), "1001")
+	open, err := alertQueries.GetOpenAlert(ctx, account.ID, "reconciliation_sla")
+	require.NoError(t, err)
+	assert.Nil(t, open)
+
+	// A hold placed 30 hours ago breaches it
+	slow := placeHold()
+	_, err = db.ExecContext(ctx, "UPDATE budget_transactions SET created_at = NOW() - INTERVAL '30 hours' WHERE transaction_id = $1", slow)
+	require.NoError(t, err)
+	reconcile(slow, "1002")
+
+	open, err = alertQueries.GetOpenAlert(ctx, account.ID, "reconciliation_sla")
+	require.NoError(t, err)
+	require.NotNil(t, open)
+	assert.Equal(t, "warning", open.Severity)
+	assert.InDelta(t, 24.0, open.ThresholdValue, 0.001)
+	assert.InDelta(t, 30.0, open.ActualValue, 0.1)
+	assert.Contains(t, open.Message, slow)
+
+	// Usage reports the average over both holds
+	usage, err := service.UsageByComponent(ctx, &api.UsageReportRequest{Account: "test-account-sla"})
+	require.NoError(t, err)
+	require.Len(t, usage.ReconciliationLatency, 1)
+	latency := usage.ReconciliationLatency[0]
+	assert.Equal(t, "test-account-sla", latency.Account)
+	assert.Equal(t, int64(2), latency.Reconciled)
+	assert.InDelta(t, 15.0, latency.AvgHours, 0.1)
+	assert.InDelta(t, 30.0, latency.MaxHours, 0.1)
+	assert.Equal(t, int64(1), latency.OverSLA)
+
+	pending, err := service.ListPendingReconciliations(ctx, "test-account-sla")
+	require.NoError(t, err)
+	assert.Equal(t, "24h0m0s", pending.ReconciliationSLA)
+	require.Len(t, pending.ReconciliationLatency, 1)
+	assert.Equal(t, int64(1), pending.ReconciliationLatency[0].OverSLA)
+
+	var metrics strings.Builder
+	require.NoError(t, service.WriteMetrics(&metrics))
+	assert.Contains(t, metrics.String(), "asbb_reconciliation_latency_seconds_count 2\n")
+}
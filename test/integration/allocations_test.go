@@ -0,0 +1,475 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestAllocations_LocalTimezone(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	accountQueries := database.NewAccountQueries(db)
+	ctx := context.Background()
+
+	eastern, err := api.LoadTimezone("America/New_York")
+	require.NoError(t, err)
+
+	account, err := accountQueries.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "test-account-eastern",
+		Name:         "Eastern Time Account",
+		BudgetLimit:  0,
+		Timezone:     "America/New_York",
+		StartDate:    time.Date(2025, 1, 1, 0, 0, 0, 0, eastern),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", account.Timezone)
+
+	// A monthly allocation due at local midnight on March 1st, before the DST change
+	due := time.Date(2025, 3, 1, 0, 0, 0, 0, eastern)
+	var scheduleID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+		INSERT INTO budget_allocation_schedules
+			(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+		VALUES ($1, 1200, 100, 'monthly', $2, $2, 1200)
+		RETURNING id`, account.ID, due).Scan(&scheduleID))
+
+	_, err = db.ExecContext(ctx, "SELECT * FROM process_pending_allocations()")
+	require.NoError(t, err)
+
+	var next time.Time
+	require.NoError(t, db.QueryRowContext(ctx,
+		"SELECT next_allocation_date FROM budget_allocation_schedules WHERE id = $1", scheduleID).Scan(&next))
+
+	// April 1st local midnight is in daylight time, four hours behind UTC rather than five
+	expected := time.Date(2025, 4, 1, 0, 0, 0, 0, eastern)
+	assert.True(t, expected.Equal(next), "expected %s, got %s", expected, next)
+
+	goNext, err := api.NextAllocationDate(due, "monthly", eastern)
+	require.NoError(t, err)
+	assert.True(t, goNext.Equal(next), "Go and SQL disagree: %s vs %s", goNext, next)
+
+	updated, err := accountQueries.GetAccountByID(ctx, account.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 100.0, updated.BudgetLimit, 0.001)
+}
+
+func TestAllocations_PreviewMatchesProcessing(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "preview-lab",
+		Name:         "Preview Lab",
+		BudgetLimit:  0,
+		StartDate:    time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// $1,000 in $300 monthly steps, $200 already allocated: $300, $300, then a $200 top-up
+	due := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_allocation_schedules
+			(account_id, total_budget, allocation_amount, allocation_frequency, start_date,
+			 next_allocation_date, allocated_to_date, remaining_budget)
+		VALUES ($1, 1000, 300, 'monthly', $2, $2, 200, 800)`, account.ID, due)
+	require.NoError(t, err)
+
+	preview, err := service.PreviewAllocations(ctx, "preview-lab", 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, preview.Count)
+	assert.Equal(t, due, preview.Allocations[0].AllocationDate.UTC())
+	assert.Equal(t, time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC), preview.Allocations[2].AllocationDate.UTC())
+	assert.InDelta(t, 200.0, preview.Allocations[2].Amount, 0.001)
+	assert.True(t, preview.Allocations[2].Partial)
+	assert.InDelta(t, 800.0, preview.TotalAmount, 0.001)
+
+	first, err := service.PreviewAllocations(ctx, "preview-lab", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Count)
+
+	// Processing makes the first previewed allocation and the preview moves on
+	_, err = db.ExecContext(ctx, "SELECT * FROM process_pending_allocations()")
+	require.NoError(t, err)
+
+	preview, err = service.PreviewAllocations(ctx, "preview-lab", 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, preview.Count)
+	assert.Equal(t, time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC), preview.Allocations[0].AllocationDate.UTC())
+
+	_, err = service.PreviewAllocations(ctx, "preview-lab", 101)
+	assert.Error(t, err)
+	_, err = service.PreviewAllocations(ctx, "no-such-account", 5)
+	assert.Error(t, err)
+}
+
+func TestAllocations_LargeBacklogInBatches(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.WorkerBatchSize = 10
+	cfg.Budget.WorkerConcurrency = 3
+	service := budget.NewService(db, nil, &cfg.Budget)
+	accountQueries := database.NewAccountQueries(db)
+	ctx := context.Background()
+
+	// Twelve accounts with two due schedules each: 24 schedules in three batches
+	var accountIDs []int64
+	for i := 0; i < 12; i++ {
+		account, err := accountQueries.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: fmt.Sprintf("backlog-%02d", i),
+			Name:         "Backlog Account",
+			StartDate:    time.Now().AddDate(0, -1, 0),
+			EndDate:      time.Now().AddDate(1, 0, 0),
+		})
+		require.NoError(t, err)
+		accountIDs = append(accountIDs, account.ID)
+
+		for _, amount := range []float64{100, 50} {
+			_, err := db.ExecContext(ctx, `
+				INSERT INTO budget_allocation_schedules
+					(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+				VALUES ($1, 1200, $2, 'monthly', NOW() - INTERVAL '1 day', NOW() - INTERVAL '1 hour', 1200)`,
+				account.ID, amount)
+			require.NoError(t, err)
+		}
+	}
+
+	resp, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.Batches)
+	assert.Equal(t, int64(24), resp.ProcessedCount)
+	assert.InDelta(t, 1800.0, resp.TotalAllocated, 0.001)
+
+	for _, id := range accountIDs {
+		account, err := accountQueries.GetAccountByID(ctx, id)
+		require.NoError(t, err)
+		assert.InDelta(t, 150.0, account.BudgetLimit, 0.001, account.SlurmAccount)
+	}
+
+	// Every schedule stepped to its next month, so a second run has nothing to do
+	again, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+	require.NoError(t, err)
+	assert.Zero(t, again.Batches)
+	assert.Zero(t, again.ProcessedCount)
+}
+
+func TestAllocations_BulkPauseAndResume(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, nil, &cfg.Budget)
+	accountQueries := database.NewAccountQueries(db)
+	ctx := context.Background()
+
+	// Two NSF accounts, one under cost center CC-1, and one NIH account, each with a due schedule
+	schedules := map[string]int64{}
+	for _, name := range []string{"nsf-a", "nsf-b", "nih-a"} {
+		account, err := accountQueries.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: name,
+			Name:         "Pause Account",
+			StartDate:    time.Now().AddDate(0, -1, 0),
+			EndDate:      time.Now().AddDate(1, 0, 0),
+		})
+		require.NoError(t, err)
+
+		var scheduleID int64
+		require.NoError(t, db.QueryRowContext(ctx, `
+			INSERT INTO budget_allocation_schedules
+				(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+			VALUES ($1, 1200, 100, 'monthly', NOW() - INTERVAL '1 day', NOW() - INTERVAL '1 hour', 1200)
+			RETURNING id`, account.ID).Scan(&scheduleID))
+		schedules[name] = scheduleID
+	}
+	for _, grant := range []struct{ number, agency, costCenter, account string }{
+		{"NSF-PAUSE-1", "NSF", "CC-1", "nsf-a"},
+		{"NSF-PAUSE-2", "NSF", "CC-2", "nsf-b"},
+		{"NIH-PAUSE-1", "NIH", "CC-3", "nih-a"},
+	} {
+		_, err := db.ExecContext(ctx, `
+			WITH g AS (
+				INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+				                            grant_start_date, grant_end_date, total_award_amount, cost_center)
+				VALUES ($1, $2, 'Dr. Smith', 'University', NOW() - INTERVAL '30 days', NOW() + INTERVAL '3 years', 10000.00, $3)
+				RETURNING id
+			)
+			UPDATE budget_accounts SET grant_id = (SELECT id FROM g), is_grant_funded = TRUE WHERE slurm_account = $4`,
+			grant.number, grant.agency, grant.costCenter, grant.account)
+		require.NoError(t, err)
+	}
+
+	status := func(name string) string {
+		var s string
+		require.NoError(t, db.QueryRowContext(ctx,
+			"SELECT status FROM budget_allocation_schedules WHERE id = $1", schedules[name]).Scan(&s))
+		return s
+	}
+
+	t.Run("pause by agency", func(t *testing.T) {
+		resp, err := service.PauseAllocationSchedules(ctx, &api.BulkScheduleStatusRequest{FundingAgency: "NSF"})
+		require.NoError(t, err)
+		assert.Equal(t, "paused", resp.Status)
+		assert.ElementsMatch(t, []int64{schedules["nsf-a"], schedules["nsf-b"]}, resp.ScheduleIDs)
+		assert.Equal(t, "active", status("nih-a"))
+
+		account, err := service.GetAccount(ctx, "nsf-a")
+		require.NoError(t, err)
+		assert.Nil(t, account.NextAllocationDate, "a paused account has no next allocation")
+	})
+
+	t.Run("paused schedules are skipped", func(t *testing.T) {
+		resp, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), resp.ProcessedCount)
+		assert.Equal(t, schedules["nih-a"], resp.Allocations[0].ScheduleID)
+
+		// The database function skips them too, even when asked for them by ID
+		var processed int
+		require.NoError(t, db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM process_pending_allocations(NULL, $1)",
+			pq.Array([]int64{schedules["nsf-a"], schedules["nsf-b"]})).Scan(&processed))
+		assert.Zero(t, processed)
+	})
+
+	t.Run("resume by cost center", func(t *testing.T) {
+		resp, err := service.ResumeAllocationSchedules(ctx, &api.BulkScheduleStatusRequest{CostCenter: "CC-1"})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{schedules["nsf-a"]}, resp.ScheduleIDs)
+		assert.Equal(t, "active", status("nsf-a"))
+		assert.Equal(t, "paused", status("nsf-b"))
+
+		account, err := service.GetAccount(ctx, "nsf-a")
+		require.NoError(t, err)
+		assert.NotNil(t, account.NextAllocationDate)
+
+		processed, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), processed.ProcessedCount)
+		assert.Equal(t, schedules["nsf-a"], processed.Allocations[0].ScheduleID)
+	})
+
+	t.Run("resume everything", func(t *testing.T) {
+		resp, err := service.ResumeAllocationSchedules(ctx, &api.BulkScheduleStatusRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{schedules["nsf-b"]}, resp.ScheduleIDs, "only paused schedules resume")
+		assert.Equal(t, "active", status("nsf-b"))
+	})
+}
+
+func TestAllocations_ProratedFirstAllocation(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	for _, tt := range []struct {
+		name    string
+		prorate bool
+		first   float64
+		next    time.Time
+	}{
+		{"full amount", false, 310, time.Date(2025, 2, 15, 0, 0, 0, 0, time.UTC)},
+		// January's 17 remaining days of 31, then full months from February 1st
+		{"prorated", true, 170, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			db := SetupTestDatabase(t)
+			defer TeardownTestDatabase(t, db)
+
+			cfg := SetupTestConfig()
+			cfg.Budget.ProrateFirstAllocation = tt.prorate
+			service := budget.NewService(db, nil, &cfg.Budget)
+			ctx := context.Background()
+
+			// An account created mid-month with $1,000 to allocate in $310 monthly steps
+			created := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+			account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+				SlurmAccount: "prorate-lab",
+				Name:         "Prorate Lab",
+				StartDate:    created,
+				EndDate:      time.Now().AddDate(1, 0, 0),
+			})
+			require.NoError(t, err)
+			var scheduleID int64
+			require.NoError(t, db.QueryRowContext(ctx, `
+				INSERT INTO budget_allocation_schedules
+					(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+				VALUES ($1, 1000, 310, 'monthly', $2, $2, 1000)
+				RETURNING id`, account.ID, created).Scan(&scheduleID))
+
+			preview, err := service.PreviewAllocations(ctx, "prorate-lab", 2)
+			require.NoError(t, err)
+			require.Equal(t, 2, preview.Count)
+
+			resp, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+			require.NoError(t, err)
+			require.Equal(t, int64(1), resp.ProcessedCount)
+			assert.InDelta(t, tt.first, resp.Allocations[0].AllocatedAmount, 0.001)
+			assert.InDelta(t, preview.Allocations[0].Amount, resp.Allocations[0].AllocatedAmount, 0.001)
+
+			var next time.Time
+			require.NoError(t, db.QueryRowContext(ctx,
+				"SELECT next_allocation_date FROM budget_allocation_schedules WHERE id = $1", scheduleID).Scan(&next))
+			assert.True(t, tt.next.Equal(next), "expected %s, got %s", tt.next, next)
+			assert.True(t, preview.Allocations[1].AllocationDate.Equal(next), "preview and processing disagree")
+
+			// Later allocations are full amounts, and the total budget still caps them
+			var total float64
+			for i := 0; i < 5; i++ {
+				_, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+				require.NoError(t, err)
+			}
+			require.NoError(t, db.QueryRowContext(ctx,
+				"SELECT allocated_to_date FROM budget_allocation_schedules WHERE id = $1", scheduleID).Scan(&total))
+			assert.InDelta(t, 1000.0, total, 0.001)
+
+			var amounts []float64
+			rows, err := db.QueryContext(ctx,
+				"SELECT allocation_amount FROM budget_allocations WHERE schedule_id = $1 ORDER BY id", scheduleID)
+			require.NoError(t, err)
+			defer rows.Close()
+			for rows.Next() {
+				var amount float64
+				require.NoError(t, rows.Scan(&amount))
+				amounts = append(amounts, amount)
+			}
+			require.NoError(t, rows.Err())
+			require.GreaterOrEqual(t, len(amounts), 2)
+			assert.InDelta(t, 310.0, amounts[1], 0.001)
+		})
+	}
+}
+
+func TestAllocations_ConcurrentSchedulesOnOneAccount(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	// One schedule per batch would split the account's two schedules across batches
+	cfg.Budget.WorkerBatchSize = 1
+	cfg.Budget.WorkerConcurrency = 2
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "two-schedules",
+		Name:         "Two Schedules",
+		BudgetLimit:  100.0,
+		StartDate:    time.Now().AddDate(0, -1, 0),
+		EndDate:      time.Now().AddDate(1, 0, 0),
+	})
+	require.NoError(t, err)
+
+	// A monthly compute allocation and a one-time equipment supplement, both due
+	computeStart := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	compute, err := service.CreateAllocationSchedule(ctx, "two-schedules", &api.CreateAllocationScheduleRequest{
+		TotalBudget: 1200, AllocationAmount: 100, AllocationFrequency: "monthly",
+		StartDate: computeStart, AutoAllocate: true,
+	})
+	require.NoError(t, err)
+	supplement, err := service.CreateAllocationSchedule(ctx, "two-schedules", &api.CreateAllocationScheduleRequest{
+		TotalBudget: 5000, AllocationAmount: 5000, AllocationFrequency: "yearly",
+		StartDate: time.Now().Add(-time.Hour), AutoAllocate: true,
+	})
+	require.NoError(t, err)
+
+	account, err := service.GetAccount(ctx, "two-schedules")
+	require.NoError(t, err)
+	assert.True(t, account.HasIncrementalBudget)
+	require.NotNil(t, account.NextAllocationDate)
+	assert.True(t, computeStart.Equal(*account.NextAllocationDate), "soonest schedule is next")
+
+	resp, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, resp.Batches)
+	assert.Equal(t, int64(2), resp.ProcessedCount)
+	assert.InDelta(t, 5100.0, resp.TotalAllocated, 0.001)
+
+	t.Run("each schedule allocates independently", func(t *testing.T) {
+		account, err := service.GetAccount(ctx, "two-schedules")
+		require.NoError(t, err)
+		assert.InDelta(t, 5200.0, account.BudgetLimit, 0.001)
+		assert.InDelta(t, 5100.0, account.TotalAllocated, 0.001)
+
+		// The supplement is spent; the account's next allocation is the compute schedule's
+		expected, err := api.NextAllocationDate(computeStart, "monthly", time.UTC)
+		require.NoError(t, err)
+		require.NotNil(t, account.NextAllocationDate)
+		assert.True(t, expected.Equal(*account.NextAllocationDate), "expected %s, got %s", expected, account.NextAllocationDate)
+	})
+
+	t.Run("allocations are attributed to their schedule", func(t *testing.T) {
+		report, err := service.ListAccountSchedules(ctx, "two-schedules")
+		require.NoError(t, err)
+		require.Len(t, report.Schedules, 2)
+
+		byID := map[int64]api.ScheduleAllocations{}
+		for _, scheduled := range report.Schedules {
+			byID[scheduled.Schedule.ID] = scheduled
+		}
+		require.Len(t, byID[compute.ID].Allocations, 1)
+		assert.InDelta(t, 100.0, byID[compute.ID].Allocations[0].AllocationAmount, 0.001)
+		assert.Equal(t, "active", byID[compute.ID].Schedule.Status)
+		require.Len(t, byID[supplement.ID].Allocations, 1)
+		assert.InDelta(t, 5000.0, byID[supplement.ID].Allocations[0].AllocationAmount, 0.001)
+		assert.Equal(t, "completed", byID[supplement.ID].Schedule.Status)
+		assert.True(t, byID[supplement.ID].Schedule.NextAllocationDate.IsZero())
+		assert.NotEqual(t, byID[compute.ID].Allocations[0].TransactionID, byID[supplement.ID].Allocations[0].TransactionID)
+
+		assert.InDelta(t, 6200.0, report.Summary.TotalBudget, 0.001)
+		assert.InDelta(t, 5100.0, report.Summary.AllocatedToDate, 0.001)
+		assert.InDelta(t, 100.0, report.Summary.NextAllocationAmount, 0.001)
+		assert.Empty(t, report.Summary.AllocationFrequency)
+	})
+
+	t.Run("preview and export skip the spent supplement", func(t *testing.T) {
+		preview, err := service.PreviewAllocations(ctx, "two-schedules", 3)
+		require.NoError(t, err)
+		require.Equal(t, 3, preview.Count)
+		for _, allocation := range preview.Allocations {
+			assert.Equal(t, compute.ID, allocation.ScheduleID)
+		}
+
+		export, err := service.ExportAccount(ctx, "two-schedules")
+		require.NoError(t, err)
+		assert.Len(t, export.AllocationSchedules, 2)
+	})
+
+	t.Run("a second run has nothing to do", func(t *testing.T) {
+		again, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+		require.NoError(t, err)
+		assert.Zero(t, again.ProcessedCount)
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_AllowedPartitions(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	created, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount:      "phys101",
+		Name:              "Physics 101",
+		BudgetLimit:       300.0,
+		StartDate:         time.Now().Add(-24 * time.Hour),
+		EndDate:           time.Now().Add(365 * 24 * time.Hour),
+		AllowedPartitions: []string{"cpu"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cpu"}, created.AllowedPartitions)
+
+	check := func(partition string) (*api.BudgetCheckResponse, error) {
+		return service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "phys101", Partition: partition, Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+	}
+
+	t.Run("allowed partition is held", func(t *testing.T) {
+		resp, err := check("cpu")
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+	})
+
+	t.Run("other partition is forbidden", func(t *testing.T) {
+		_, err := check("gpu")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeForbidden, budgetErr.Code)
+		assert.Contains(t, budgetErr.Message, "partition 'gpu'")
+
+		account, err := service.GetAccount(ctx, "phys101")
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, account.BudgetHeld, 0.001, "only the allowed job holds budget")
+
+		decisions, err := service.ListDecisions(ctx, &api.DecisionListRequest{Account: "phys101", Decision: api.DecisionRejected})
+		require.NoError(t, err)
+		require.Len(t, decisions, 1)
+		assert.Equal(t, "gpu", decisions[0].Partition)
+	})
+
+	t.Run("clearing the list allows every partition", func(t *testing.T) {
+		updated, err := service.UpdateAccount(ctx, "phys101", &api.UpdateAccountRequest{AllowedPartitions: []string{}})
+		require.NoError(t, err)
+		assert.Empty(t, updated.AllowedPartitions)
+
+		resp, err := check("gpu")
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestASBA_Feedback(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, account := range []string{"feedback-a", "feedback-b"} {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         account,
+			BudgetLimit:  1000.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	check := func(account string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: account, Partition: "aws", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+
+	first := check("feedback-a")
+	second := check("feedback-a")
+	other := check("feedback-b")
+
+	decisions, err := service.ListDecisions(ctx, &api.DecisionListRequest{Account: "feedback-a"})
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+
+	t.Run("feedback is linked by decision or hold", func(t *testing.T) {
+		byDecision, err := service.RecordASBAFeedback(ctx, &api.ASBAFeedbackRequest{
+			Account: "feedback-a", DecisionID: decisions[1].ID, JobID: "1001",
+			RecommendedAction: api.BurstActionAWS, ActualLocation: api.BurstActionAWS,
+			ActualCost: 15, RecommendationFollowed: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, decisions[1].ID, byDecision.DecisionID)
+		assert.InDelta(t, 10.0, byDecision.EstimatedCost, 0.001)
+
+		byHold, err := service.RecordASBAFeedback(ctx, &api.ASBAFeedbackRequest{
+			Account: "feedback-a", TransactionID: second.TransactionID, JobID: "1002",
+			RecommendedAction: api.BurstActionLocal, ActualLocation: api.BurstActionAWS,
+			ActualCost: 5, Notes: "local queue was full",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, decisions[0].ID, byHold.DecisionID)
+
+		_, err = service.RecordASBAFeedback(ctx, &api.ASBAFeedbackRequest{
+			Account: "feedback-b", TransactionID: other.TransactionID,
+			RecommendedAction: api.BurstActionLocal, ActualLocation: api.BurstActionLocal, RecommendationFollowed: true,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("retried feedback replaces the outcome", func(t *testing.T) {
+		_, err := service.RecordASBAFeedback(ctx, &api.ASBAFeedbackRequest{
+			Account: "feedback-a", TransactionID: first.TransactionID, JobID: "1001",
+			RecommendedAction: api.BurstActionAWS, ActualLocation: api.BurstActionAWS,
+			ActualCost: 18, RecommendationFollowed: true,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("feedback is retrievable per account", func(t *testing.T) {
+		feedback, err := service.ListASBAFeedback(ctx, &api.ASBAFeedbackListRequest{Account: "feedback-a"})
+		require.NoError(t, err)
+		require.Len(t, feedback, 2)
+		assert.Equal(t, "1002", feedback[0].JobID)
+		assert.Equal(t, "local queue was full", feedback[0].Notes)
+		assert.False(t, feedback[0].RecommendationFollowed)
+		assert.Equal(t, "1001", feedback[1].JobID)
+		assert.InDelta(t, 18.0, feedback[1].ActualCost, 0.001)
+
+		feedback, err = service.ListASBAFeedback(ctx, &api.ASBAFeedbackListRequest{Account: "feedback-b"})
+		require.NoError(t, err)
+		require.Len(t, feedback, 1)
+		assert.Equal(t, api.BurstActionLocal, feedback[0].ActualLocation)
+	})
+
+	t.Run("another account's decision is not found", func(t *testing.T) {
+		_, err := service.RecordASBAFeedback(ctx, &api.ASBAFeedbackRequest{
+			Account: "feedback-b", DecisionID: decisions[0].ID,
+			RecommendedAction: api.BurstActionAWS, ActualLocation: api.BurstActionAWS,
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+
+	t.Run("feedback weights burst decisions", func(t *testing.T) {
+		factors, summary, err := service.WeightBurstDecisionFactors(ctx, "feedback-a", []api.DecisionFactor{
+			{Factor: api.DecisionFactorBudgetHealth, Weight: 0.5},
+			{Factor: api.DecisionFactorCostEfficiency, Weight: 0.5},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.Outcomes)
+		assert.Equal(t, 1, summary.Followed)
+		assert.Equal(t, 2, summary.AWSOutcomes)
+		assert.InDelta(t, 23.0, summary.AWSActualCost, 0.001)
+		assert.InDelta(t, 20.0, summary.AWSEstimatedCost, 0.001)
+		assert.Greater(t, factors[1].Weight, 0.5, "AWS jobs overran their estimates")
+	})
+}
@@ -0,0 +1,181 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestASBX_PartialReconciliationData(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	integration := asbx.NewIntegrationService(service, &asbx.IntegrationConfig{Enabled: true})
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "partial-lab",
+		Name:         "Partial Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	hold := func() string {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "partial-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp.TransactionID
+	}
+
+	reconcile := func(jobID, txn string, estimated, actual *float64) (*api.ASBXCostReconciliationResponse, error) {
+		return integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID:               jobID,
+				Account:             "partial-lab",
+				JobState:            "COMPLETED",
+				EstimatedCost:       estimated,
+				ActualCost:          actual,
+				BudgetTransactionID: txn,
+			},
+		})
+	}
+
+	t.Run("missing estimate uses the hold amount", func(t *testing.T) {
+		resp, err := reconcile("partial-1", hold(), nil, float64Ptr(9.0))
+		require.NoError(t, err)
+		assert.Equal(t, "hold", resp.EstimateSource)
+		assert.InDelta(t, 12.0, resp.EstimatedCost, 0.001)
+		assert.InDelta(t, -3.0, resp.CostVariance, 0.001)
+		assert.InDelta(t, 0.75, resp.EstimationAccuracy, 0.001)
+		assert.NotEmpty(t, resp.Warnings)
+	})
+
+	t.Run("missing actual is rejected and nothing is charged", func(t *testing.T) {
+		before, err := service.GetAccount(ctx, "partial-lab")
+		require.NoError(t, err)
+
+		_, err = reconcile("partial-2", hold(), float64Ptr(10.0), nil)
+		require.Error(t, err)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+
+		after, err := service.GetAccount(ctx, "partial-lab")
+		require.NoError(t, err)
+		assert.InDelta(t, before.BudgetUsed, after.BudgetUsed, 0.001)
+		assert.InDelta(t, before.BudgetHeld+12.0, after.BudgetHeld, 0.001)
+	})
+
+	t.Run("estimate and actual present", func(t *testing.T) {
+		resp, err := reconcile("partial-3", hold(), float64Ptr(10.0), float64Ptr(8.0))
+		require.NoError(t, err)
+		assert.Equal(t, "asbx", resp.EstimateSource)
+		assert.InDelta(t, 10.0, resp.EstimatedCost, 0.001)
+		assert.InDelta(t, -2.0, resp.CostVariance, 0.001)
+		assert.InDelta(t, 0.8, resp.EstimationAccuracy, 0.001)
+	})
+
+	t.Run("another account's hold is refused", func(t *testing.T) {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: "partial-other",
+			Name:         "Other Lab",
+			BudgetLimit:  1000.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		_, err = integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID:               "partial-4",
+				Account:             "partial-other",
+				JobState:            "COMPLETED",
+				ActualCost:          float64Ptr(0),
+				BudgetTransactionID: hold(),
+			},
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		assert.Equal(t, api.ErrCodeForbidden, budgetErr.Code)
+	})
+}
+
+func TestASBX_CostVarianceWarnings(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	integration := asbx.NewIntegrationService(service, &asbx.IntegrationConfig{
+		Enabled:                  true,
+		VarianceWarningPct:       50,
+		VarianceWarningMinAmount: 1.00,
+	})
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "variance-lab",
+		Name:         "Variance Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	reconcile := func(jobID string, estimated, actual float64) *api.ASBXCostReconciliationResponse {
+		check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "variance-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, check.Available)
+
+		resp, err := integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID:               jobID,
+				Account:             "variance-lab",
+				JobState:            "COMPLETED",
+				EstimatedCost:       float64Ptr(estimated),
+				ActualCost:          float64Ptr(actual),
+				BudgetTransactionID: check.TransactionID,
+			},
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("penny variance on a cheap job is quiet", func(t *testing.T) {
+		resp := reconcile("variance-1", 0.01, 0.02)
+		assert.InDelta(t, 100.0, resp.CostVariancePct, 0.001)
+		assert.Empty(t, resp.Warnings)
+	})
+
+	t.Run("large dollar variance warns", func(t *testing.T) {
+		resp := reconcile("variance-2", 4.0, 10.0)
+		require.Len(t, resp.Warnings, 1)
+		assert.Contains(t, resp.Warnings[0], "Large cost variance")
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestASBX_UnheldJobPolicy(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "bypass-lab",
+		Name:         "Bypass Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// ASBX reports a job that ran on an AWS partition whose prolog skipped the budget check
+	reconcile := func(policy, jobID, account string) (*api.ASBXCostReconciliationResponse, error) {
+		integration := asbx.NewIntegrationService(service, &asbx.IntegrationConfig{Enabled: true, UnheldJobPolicy: policy})
+		return integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID:      jobID,
+				Account:    account,
+				JobState:   "COMPLETED",
+				ActualCost: float64Ptr(42.50),
+			},
+		})
+	}
+
+	t.Run("charge policy charges the account retroactively", func(t *testing.T) {
+		resp, err := reconcile(api.UnheldJobPolicyCharge, "bypass-1", "bypass-lab")
+		require.NoError(t, err)
+		assert.True(t, resp.Unreserved)
+		assert.Equal(t, "none", resp.EstimateSource)
+		assert.InDelta(t, 42.50, resp.ChargedAmount, 0.001)
+		require.NotEmpty(t, resp.ChargeTransaction)
+		assert.NotEmpty(t, resp.Warnings)
+
+		account, err := service.GetAccount(ctx, "bypass-lab")
+		require.NoError(t, err)
+		assert.InDelta(t, 42.50, account.BudgetUsed, 0.001)
+		assert.InDelta(t, 0.0, account.BudgetHeld, 0.001)
+
+		charge, err := service.GetTransaction(ctx, resp.ChargeTransaction)
+		require.NoError(t, err)
+		assert.Equal(t, "charge", charge.Type)
+		metadata, err := api.DecodeTransactionMetadata(charge.Type, charge.Metadata)
+		require.NoError(t, err)
+		chargeMetadata, ok := metadata.(*api.ChargeMetadata)
+		require.True(t, ok)
+		assert.True(t, chargeMetadata.Unreserved)
+	})
+
+	t.Run("charge policy needs the account to exist", func(t *testing.T) {
+		_, err := reconcile(api.UnheldJobPolicyCharge, "bypass-2", "no-such-lab")
+		var budgetErr *api.BudgetError
+		require.ErrorAs(t, err, &budgetErr)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+
+	t.Run("reject policy charges nothing", func(t *testing.T) {
+		before, err := service.GetAccount(ctx, "bypass-lab")
+		require.NoError(t, err)
+
+		_, err = reconcile(api.UnheldJobPolicyReject, "bypass-3", "bypass-lab")
+		require.Error(t, err)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+
+		after, err := service.GetAccount(ctx, "bypass-lab")
+		require.NoError(t, err)
+		assert.InDelta(t, before.BudgetUsed, after.BudgetUsed, 0.001)
+	})
+}
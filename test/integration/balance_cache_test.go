@@ -0,0 +1,50 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+)
+
+func TestBalanceCache_InvalidatedByNewTransaction(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.BalanceCacheTTL = time.Hour
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	createHierarchyAccount(t, service, "cache-org", "", 1000)
+	createHierarchyAccount(t, service, "cache-lab", "cache-org", 100)
+
+	for _, name := range []string{"cache-org", "cache-lab"} {
+		account, err := service.GetAccountBalance(ctx, name)
+		require.NoError(t, err)
+		assert.Zero(t, account.BudgetHeld, name)
+	}
+
+	// The hold on cache-lab also counts against its parent, so both entries are stale
+	resp := checkHierarchyBudget(t, service, "cache-lab")
+	require.True(t, resp.Available, resp.Message)
+
+	for _, name := range []string{"cache-org", "cache-lab"} {
+		account, err := service.GetAccountBalance(ctx, name)
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, account.BudgetHeld, 0.001, name)
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_CheckBudgetHoldPercentage(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.GPUHoldPercentage = 1.5
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	override := 1.05
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "hold-default", Name: "Default Hold", BudgetLimit: 1000.0},
+		{SlurmAccount: "hold-override", Name: "Override Hold", BudgetLimit: 1000.0, HoldPercentage: &override},
+	} {
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	checkJob := func(account, partition string, gpus int) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account:   account,
+			Partition: partition,
+			Nodes:     1,
+			CPUs:      4,
+			GPUs:      gpus,
+			WallTime:  "01:00:00",
+		})
+		require.NoError(t, err)
+		return resp
+	}
+	check := func(account string) *api.BudgetCheckResponse {
+		return checkJob(account, "cpu", 0)
+	}
+
+	// The mock advisor estimates $10.00 for every job
+	t.Run("account without override uses global default", func(t *testing.T) {
+		resp := check("hold-default")
+		assert.True(t, resp.Available)
+		assert.Equal(t, cfg.Budget.DefaultHoldPercentage, resp.Details.HoldPercentage)
+		assert.Equal(t, api.HoldPercentageSourceDefault, resp.Details.HoldPercentageSource)
+		assert.InDelta(t, 10.0*cfg.Budget.DefaultHoldPercentage, resp.HoldAmount, 0.001)
+	})
+
+	t.Run("account override is applied", func(t *testing.T) {
+		resp := check("hold-override")
+		assert.True(t, resp.Available)
+		assert.Equal(t, override, resp.Details.HoldPercentage)
+		assert.Equal(t, api.HoldPercentageSourceAccount, resp.Details.HoldPercentageSource)
+		assert.InDelta(t, 10.5, resp.HoldAmount, 0.001)
+	})
+
+	t.Run("gpu job gets the gpu buffer", func(t *testing.T) {
+		resp := checkJob("hold-default", "accel", 2)
+		assert.True(t, resp.Available)
+		assert.Equal(t, 1.5, resp.Details.HoldPercentage)
+		assert.Equal(t, api.HoldPercentageSourceGPU, resp.Details.HoldPercentageSource)
+		assert.InDelta(t, 15.0, resp.HoldAmount, 0.001)
+
+		hold, err := service.GetTransaction(ctx, resp.TransactionID)
+		require.NoError(t, err)
+		metadata, err := api.DecodeTransactionMetadata(hold.Type, hold.Metadata)
+		require.NoError(t, err)
+		holdMetadata, ok := metadata.(*api.HoldMetadata)
+		require.True(t, ok)
+		assert.Equal(t, 1.5, holdMetadata.HoldPercentage)
+		assert.Equal(t, api.HoldPercentageSourceGPU, holdMetadata.HoldPercentageSource)
+	})
+
+	t.Run("cpu job on a gpu-named partition does not", func(t *testing.T) {
+		resp := checkJob("hold-default", "gpu-a100", 0)
+		assert.True(t, resp.Available)
+		assert.Equal(t, cfg.Budget.DefaultHoldPercentage, resp.Details.HoldPercentage)
+		assert.Equal(t, api.HoldPercentageSourceDefault, resp.Details.HoldPercentageSource)
+		assert.InDelta(t, 12.0, resp.HoldAmount, 0.001)
+	})
+}
+
+func TestBudget_BulkCreateAccounts(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	newReq := func(account string, limit float64) *api.CreateAccountRequest {
+		return &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         account,
+			BudgetLimit:  limit,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		}
+	}
+
+	resp, err := service.BulkCreateAccounts(ctx, []*api.CreateAccountRequest{
+		newReq("bulk-1", 100),
+		newReq("bulk-1", 100), // duplicate within the batch
+		newReq("bulk-invalid", 0),
+		newReq("bulk-2", 200),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, resp.Created)
+	assert.Equal(t, 2, resp.Failed)
+	assert.Equal(t, api.ErrCodeDuplicateAccount, resp.Results[1].ErrorCode)
+	assert.Equal(t, api.ErrCodeValidation, resp.Results[2].ErrorCode)
+
+	account, err := service.GetAccount(ctx, "bulk-2")
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, account.BudgetLimit)
+}
+
+func TestBudget_ReservedAmount(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount:   "reserve-account",
+		Name:           "Reserve Account",
+		BudgetLimit:    100.0,
+		ReservedAmount: 95.0,
+		StartDate:      time.Now().Add(-24 * time.Hour),
+		EndDate:        time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check := func() *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account:   "reserve-account",
+			Partition: "cpu",
+			Nodes:     1,
+			CPUs:      4,
+			WallTime:  "01:00:00",
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// The mock advisor estimates $10.00, so the $12 hold exceeds the $5 left above the reserve
+	t.Run("holds respect the reserve", func(t *testing.T) {
+		resp := check()
+		assert.False(t, resp.Available)
+		assert.InDelta(t, 5.0, resp.BudgetRemaining, 0.001)
+	})
+
+	t.Run("ordinary adjustment cannot spend the reserve", func(t *testing.T) {
+		_, err := service.AdjustBudget(ctx, "reserve-account", &api.BudgetAdjustmentRequest{
+			Amount:      50.0,
+			Description: "storage invoice",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("adjustment draws down the reserve", func(t *testing.T) {
+		resp, err := service.AdjustBudget(ctx, "reserve-account", &api.BudgetAdjustmentRequest{
+			Amount:      50.0,
+			Description: "storage invoice",
+			FromReserve: true,
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 45.0, resp.Account.ReservedAmount, 0.001)
+		assert.InDelta(t, 50.0, resp.Account.BudgetUsed, 0.001)
+		assert.InDelta(t, 5.0, resp.Account.SpendableAvailable(), 0.001)
+
+		_, err = service.AdjustBudget(ctx, "reserve-account", &api.BudgetAdjustmentRequest{
+			Amount:      50.0,
+			Description: "more than is reserved",
+			FromReserve: true,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("releasing the reserve allows holds", func(t *testing.T) {
+		released := 0.0
+		_, err := service.UpdateAccount(ctx, "reserve-account", &api.UpdateAccountRequest{ReservedAmount: &released})
+		require.NoError(t, err)
+		assert.True(t, check().Available)
+	})
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudgetUnits_SUAccountReconcilesDollarCost(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	// 10,000 SU at $0.02 per SU, funded by a grant whose costs are kept in dollars
+	rate := 0.02
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "su-alloc",
+		Name:         "SU Allocation",
+		BudgetLimit:  10000,
+		BudgetUnit:   api.BudgetUnitSU,
+		DollarsPerSU: &rate,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, api.BudgetUnitSU, account.BudgetUnit)
+	_, err = db.ExecContext(ctx, `
+		WITH g AS (
+			INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+			                            grant_start_date, grant_end_date, total_award_amount)
+			VALUES ('NSF-SU', 'NSF', 'Dr. Smith', 'University', NOW() - INTERVAL '1 year', NOW() + INTERVAL '1 year', 3000.00)
+			RETURNING id
+		)
+		UPDATE budget_accounts SET grant_id = (SELECT id FROM g), is_grant_funded = TRUE
+		WHERE slurm_account = 'su-alloc'`)
+	require.NoError(t, err)
+
+	// The mock advisor estimates $10, which is 500 SU; the hold adds the 20% buffer
+	check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+		Account:   "su-alloc",
+		Partition: "cpu",
+		Nodes:     1,
+		CPUs:      4,
+		WallTime:  "01:00:00",
+	})
+	require.NoError(t, err)
+	require.True(t, check.Available)
+	assert.Equal(t, api.BudgetUnitSU, check.BudgetUnit)
+	assert.Equal(t, 10.0, check.EstimatedCost, "the estimate stays in dollars")
+	assert.InDelta(t, 600.0, check.HoldAmount, 0.001)
+	assert.InDelta(t, 9400.0, check.BudgetRemaining, 0.001)
+
+	// ASBX reports the job cost $8, which is charged as 400 SU
+	reconciled, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID:         "su-job-1",
+		ActualCost:    8.0,
+		TransactionID: check.TransactionID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, api.BudgetUnitSU, reconciled.BudgetUnit)
+	assert.InDelta(t, 600.0, reconciled.OriginalHold, 0.001)
+	assert.InDelta(t, 400.0, reconciled.ActualCharge, 0.001)
+	assert.InDelta(t, 200.0, reconciled.RefundAmount, 0.001)
+
+	account, err = service.GetAccount(ctx, "su-alloc")
+	require.NoError(t, err)
+	assert.InDelta(t, 400.0, account.BudgetUsed, 0.001)
+	assert.InDelta(t, 0.0, account.BudgetHeld, 0.001)
+
+	var directCosts float64
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT direct_costs FROM grant_accounts WHERE grant_number = 'NSF-SU'`).Scan(&directCosts))
+	assert.InDelta(t, 8.0, directCosts, 0.001, "the grant is charged the job's dollar cost")
+
+	t.Run("job without a hold is charged in SU", func(t *testing.T) {
+		resp, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:      "su-job-2",
+			Account:    "su-alloc",
+			ActualCost: 3.0,
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 150.0, resp.ActualCharge, 0.001)
+	})
+
+	t.Run("child must share its parent's unit", func(t *testing.T) {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount:  "su-child",
+			Name:          "Dollar Child",
+			BudgetLimit:   100,
+			ParentAccount: "su-alloc",
+			StartDate:     time.Now().Add(-24 * time.Hour),
+			EndDate:       time.Now().Add(365 * 24 * time.Hour),
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		assert.Equal(t, "parent_account", budgetErr.Field)
+	})
+
+	t.Run("rate change applies to later jobs", func(t *testing.T) {
+		newRate := 0.05
+		_, err := service.UpdateAccount(ctx, "su-alloc", &api.UpdateAccountRequest{DollarsPerSU: &newRate})
+		require.NoError(t, err)
+
+		resp, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:      "su-job-3",
+			Account:    "su-alloc",
+			ActualCost: 3.0,
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 60.0, resp.ActualCharge, 0.001)
+	})
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBurnRate_BackfillMatchesDailyComputation(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, nil, &cfg.Budget)
+	transactionQueries := database.NewTransactionQueries(db)
+	burnRateQueries := database.NewBurnRateQueries(db)
+	ctx := context.Background()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "test-account-burn",
+		Name:         "Burn Rate Account",
+		BudgetLimit:  1000.0,
+		StartDate:    today.AddDate(0, 0, -10),
+		EndDate:      today.AddDate(0, 0, 90),
+	})
+	require.NoError(t, err)
+
+	// The account has existed since its start date
+	_, err = db.ExecContext(ctx, "UPDATE budget_accounts SET created_at = $2 WHERE id = $1", account.ID, today.AddDate(0, 0, -10))
+	require.NoError(t, err)
+
+	// Charges recorded on earlier days
+	for _, charge := range []struct {
+		id      string
+		amount  float64
+		daysAgo int
+	}{
+		{"burn-charge-1", 40.0, 8},
+		{"burn-charge-2", 25.0, 5},
+		{"burn-charge-3", 10.0, 5},
+	} {
+		require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, &api.BudgetTransaction{
+			TransactionID: charge.id, AccountID: account.ID, Type: "charge",
+			Amount: charge.amount, Description: "charge", Status: "completed",
+		}))
+		when := today.AddDate(0, 0, -charge.daysAgo).Add(12 * time.Hour)
+		_, err := db.ExecContext(ctx,
+			"UPDATE budget_transactions SET created_at = $2, completed_at = $2 WHERE transaction_id = $1", charge.id, when)
+		require.NoError(t, err)
+	}
+
+	// Enabling burn rate analysis backfills history automatically
+	enabled := true
+	_, err = service.UpdateAccount(ctx, "test-account-burn", &api.UpdateAccountRequest{BurnRateEnabled: &enabled})
+	require.NoError(t, err)
+
+	rates, err := burnRateQueries.ListBurnRates(ctx, account.ID, today.AddDate(0, 0, -10), today)
+	require.NoError(t, err)
+	require.Len(t, rates, 10)
+	assert.InDelta(t, 40.0, rates[2].DailySpendAmount, 0.001)
+	assert.InDelta(t, 35.0, rates[5].DailySpendAmount, 0.001)
+	assert.InDelta(t, 75.0, rates[9].CumulativeSpend, 0.001)
+
+	// Re-running the backfill skips every recorded day
+	resp, err := service.BackfillBurnRates(ctx, "test-account-burn", &api.BurnRateBackfillRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, resp.Computed)
+	assert.Equal(t, 10, resp.Skipped)
+
+	// The nightly computation of a day produces the same row as the backfill did
+	day := today.AddDate(0, 0, -5)
+	backfilled := rates[5]
+	_, err = db.ExecContext(ctx, "DELETE FROM budget_burn_rates WHERE account_id = $1 AND measurement_date = $2", account.ID, day)
+	require.NoError(t, err)
+
+	require.NoError(t, service.RecordDailyBurnRates(ctx, day))
+
+	recomputed, err := burnRateQueries.ListBurnRates(ctx, account.ID, day, day)
+	require.NoError(t, err)
+	require.Len(t, recomputed, 1)
+
+	assert.InDelta(t, backfilled.DailySpendAmount, recomputed[0].DailySpendAmount, 0.001)
+	assert.InDelta(t, backfilled.DailyExpectedAmount, recomputed[0].DailyExpectedAmount, 0.001)
+	assert.InDelta(t, backfilled.CumulativeSpend, recomputed[0].CumulativeSpend, 0.001)
+	assert.InDelta(t, backfilled.CumulativeExpected, recomputed[0].CumulativeExpected, 0.001)
+	assert.InDelta(t, backfilled.BudgetHealthScore, recomputed[0].BudgetHealthScore, 0.001)
+	assert.InDelta(t, backfilled.Rolling7DayAvg, recomputed[0].Rolling7DayAvg, 0.001)
+	assert.InDelta(t, backfilled.Rolling30DayAvg, recomputed[0].Rolling30DayAvg, 0.001)
+}
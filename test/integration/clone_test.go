@@ -0,0 +1,214 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_CloneAccountCopiesPoliciesNotBalances(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	holdPercentage := 1.5
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "clone-dept", Name: "Department", BudgetLimit: 5000.0},
+		{
+			SlurmAccount:      "clone-src",
+			Name:              "Lab 2025",
+			BudgetLimit:       500.0,
+			ParentAccount:     "clone-dept",
+			HoldPercentage:    &holdPercentage,
+			ReservedAmount:    50.0,
+			Timezone:          "America/Chicago",
+			FiscalYearStart:   "07-01",
+			Tags:              map[string]string{"department": "biology"},
+			AllowedPartitions: []string{"cpu", "gpu"},
+		},
+	} {
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	source, err := service.GetAccount(ctx, "clone-src")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_partition_limits (account_id, partition, limit_amount, used_amount)
+		VALUES ($1, 'cpu', 300, 40), ($1, 'gpu', 150, 0)`, source.ID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_allocation_schedules
+			(account_id, total_budget, allocation_amount, allocation_frequency, start_date, end_date,
+			 next_allocation_date, allocated_to_date, remaining_budget)
+		VALUES ($1, 1200, 100, 'monthly', NOW() - INTERVAL '2 months', NOW() + INTERVAL '10 months',
+		        NOW() + INTERVAL '1 month', 200, 1000)`, source.ID)
+	require.NoError(t, err)
+
+	// The source has spent and is holding budget before it is cloned
+	check := func(account string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: account, Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+	finished := check("clone-src")
+	check("clone-src")
+	_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID: "clone-1", ActualCost: 8.0, TransactionID: finished.TransactionID,
+	})
+	require.NoError(t, err)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	resp, err := service.CloneAccount(ctx, "clone-src", &api.CloneAccountRequest{
+		SlurmAccount:           "clone-new",
+		Name:                   "Lab 2026",
+		StartDate:              &start,
+		CopyAllocationSchedule: true,
+	})
+	require.NoError(t, err)
+	clone := resp.Account
+
+	t.Run("policies are copied", func(t *testing.T) {
+		assert.Equal(t, "clone-src", resp.SourceAccount)
+		assert.Equal(t, "Lab 2026", clone.Name)
+		assert.InDelta(t, 500.0, clone.BudgetLimit, 0.001)
+		require.NotNil(t, clone.HoldPercentage)
+		assert.InDelta(t, 1.5, *clone.HoldPercentage, 0.001)
+		assert.InDelta(t, 50.0, clone.ReservedAmount, 0.001)
+		assert.Equal(t, "America/Chicago", clone.Timezone)
+		require.NotNil(t, clone.FiscalYearStart)
+		assert.Equal(t, "07-01", *clone.FiscalYearStart)
+		assert.Equal(t, source.ParentAccountID, clone.ParentAccountID)
+		assert.Equal(t, map[string]string{"department": "biology"}, clone.Tags)
+		assert.ElementsMatch(t, []string{"cpu", "gpu"}, clone.AllowedPartitions)
+		assert.True(t, start.Equal(clone.StartDate))
+		assert.True(t, source.EndDate.Equal(clone.EndDate))
+	})
+
+	t.Run("balances start at zero", func(t *testing.T) {
+		assert.Zero(t, clone.BudgetUsed)
+		assert.Zero(t, clone.BudgetHeld)
+		assert.Zero(t, clone.TotalAllocated)
+		assert.Equal(t, "active", clone.Status)
+
+		var transactions int
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM budget_transactions WHERE account_id = $1`, clone.ID).Scan(&transactions))
+		assert.Zero(t, transactions)
+	})
+
+	t.Run("partition limits are copied without usage", func(t *testing.T) {
+		assert.Equal(t, int64(2), resp.PartitionLimitsCopied)
+
+		rows, err := db.QueryContext(ctx, `
+			SELECT partition, limit_amount, used_amount, held_amount
+			FROM budget_partition_limits WHERE account_id = $1 ORDER BY partition`, clone.ID)
+		require.NoError(t, err)
+		defer rows.Close()
+
+		limits := map[string]float64{}
+		for rows.Next() {
+			var partition string
+			var limit, used, held float64
+			require.NoError(t, rows.Scan(&partition, &limit, &used, &held))
+			assert.Zero(t, used)
+			assert.Zero(t, held)
+			limits[partition] = limit
+		}
+		require.NoError(t, rows.Err())
+		assert.Equal(t, map[string]float64{"cpu": 300, "gpu": 150}, limits)
+	})
+
+	t.Run("schedule restarts from the new start date", func(t *testing.T) {
+		assert.Equal(t, int64(1), resp.SchedulesCopied)
+		assert.True(t, clone.HasIncrementalBudget)
+		require.NotNil(t, clone.NextAllocationDate)
+		assert.True(t, start.Equal(*clone.NextAllocationDate))
+
+		var total, allocated, remaining float64
+		var scheduleStart, next time.Time
+		var end *time.Time
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT total_budget, allocated_to_date, remaining_budget, start_date, end_date, next_allocation_date
+			FROM budget_allocation_schedules WHERE account_id = $1`, clone.ID).
+			Scan(&total, &allocated, &remaining, &scheduleStart, &end, &next))
+		assert.InDelta(t, 1200.0, total, 0.001)
+		assert.Zero(t, allocated)
+		assert.InDelta(t, 1200.0, remaining, 0.001)
+		assert.True(t, start.Equal(scheduleStart))
+		assert.True(t, start.Equal(next))
+		require.NotNil(t, end)
+		assert.True(t, clone.EndDate.Equal(*end))
+	})
+
+	t.Run("balances are independent", func(t *testing.T) {
+		before, err := service.GetAccount(ctx, "clone-src")
+		require.NoError(t, err)
+
+		// Spending on the clone leaves the source alone, and the clone's copied hold
+		// percentage applies: $10 estimated, $15 held
+		held := check("clone-new")
+		assert.InDelta(t, 15.0, held.HoldAmount, 0.001)
+		_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "clone-2", ActualCost: 9.0, TransactionID: held.TransactionID,
+		})
+		require.NoError(t, err)
+
+		cloned, err := service.GetAccount(ctx, "clone-new")
+		require.NoError(t, err)
+		assert.InDelta(t, 9.0, cloned.BudgetUsed, 0.001)
+		assert.Zero(t, cloned.BudgetHeld)
+
+		after, err := service.GetAccount(ctx, "clone-src")
+		require.NoError(t, err)
+		assert.InDelta(t, before.BudgetUsed, after.BudgetUsed, 0.001)
+		assert.InDelta(t, before.BudgetHeld, after.BudgetHeld, 0.001)
+		assert.InDelta(t, 8.0, after.BudgetUsed, 0.001)
+		assert.InDelta(t, 15.0, after.BudgetHeld, 0.001)
+
+		// Changing the clone's policies leaves the source's alone
+		tighter := 1.1
+		_, err = service.UpdateAccount(ctx, "clone-new", &api.UpdateAccountRequest{HoldPercentage: &tighter})
+		require.NoError(t, err)
+		after, err = service.GetAccount(ctx, "clone-src")
+		require.NoError(t, err)
+		require.NotNil(t, after.HoldPercentage)
+		assert.InDelta(t, 1.5, *after.HoldPercentage, 0.001)
+	})
+
+	t.Run("duplicate account is refused", func(t *testing.T) {
+		_, err := service.CloneAccount(ctx, "clone-src", &api.CloneAccountRequest{SlurmAccount: "clone-new"})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeDuplicateAccount, budgetErr.Code)
+	})
+
+	t.Run("ledger matches cached balances everywhere", func(t *testing.T) {
+		check, err := service.CheckConsistency(ctx, &api.ConsistencyCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, check.Inconsistent)
+	})
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_ConsistencyCheckAndRepair(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	accountQueries := database.NewAccountQueries(db)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "verify-dept", Name: "Department"},
+		{SlurmAccount: "verify-project", Name: "Project", ParentAccount: "verify-dept"},
+	} {
+		req.BudgetLimit = 500.0
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	// One job reconciles below its $12 hold, another is still running
+	check := func() *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "verify-project", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+	finished := check()
+	check()
+	_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID: "verify-1", ActualCost: 8.0, TransactionID: finished.TransactionID,
+	})
+	require.NoError(t, err)
+
+	t.Run("ledger activity is consistent", func(t *testing.T) {
+		resp, err := service.CheckConsistency(ctx, &api.ConsistencyCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, resp.Checked)
+		assert.Equal(t, 0, resp.Inconsistent)
+
+		result, err := service.VerifyAccountConsistency(ctx, "verify-dept")
+		require.NoError(t, err)
+		assert.True(t, result.Consistent)
+		assert.InDelta(t, 8.0, result.ExpectedUsed, 0.001)
+		assert.InDelta(t, 12.0, result.ExpectedHeld, 0.001)
+	})
+
+	// Simulate drift from a manual edit that bypassed the ledger
+	_, err = db.ExecContext(ctx,
+		`UPDATE budget_accounts SET budget_used = budget_used + 50, budget_held = 0 WHERE slurm_account = 'verify-project'`)
+	require.NoError(t, err)
+
+	t.Run("drift is detected", func(t *testing.T) {
+		result, err := service.VerifyAccountConsistency(ctx, "verify-project")
+		require.NoError(t, err)
+		assert.False(t, result.Consistent)
+		assert.InDelta(t, 58.0, result.CachedUsed, 0.001)
+		assert.InDelta(t, 8.0, result.ExpectedUsed, 0.001)
+		assert.InDelta(t, 50.0, result.UsedDrift, 0.001)
+		assert.InDelta(t, -12.0, result.HeldDrift, 0.001)
+		assert.False(t, result.Repaired)
+
+		parent, err := service.VerifyAccountConsistency(ctx, "verify-dept")
+		require.NoError(t, err)
+		assert.True(t, parent.Consistent)
+
+		resp, err := service.CheckConsistency(ctx, &api.ConsistencyCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, resp.Inconsistent)
+		assert.Equal(t, 0, resp.Repaired)
+	})
+
+	t.Run("repair corrects balances with an audit record", func(t *testing.T) {
+		result, err := service.RepairAccountConsistency(ctx, "verify-project", "Manual edit", "ops")
+		require.NoError(t, err)
+		assert.True(t, result.Repaired)
+
+		account, err := service.GetAccount(ctx, "verify-project")
+		require.NoError(t, err)
+		assert.InDelta(t, 8.0, account.BudgetUsed, 0.001)
+		assert.InDelta(t, 12.0, account.BudgetHeld, 0.001)
+
+		repairs, err := accountQueries.ListBalanceRepairs(ctx, account.ID)
+		require.NoError(t, err)
+		require.Len(t, repairs, 1)
+		assert.InDelta(t, 58.0, repairs[0].PreviousUsed, 0.001)
+		assert.InDelta(t, 0.0, repairs[0].PreviousHeld, 0.001)
+		assert.InDelta(t, 8.0, repairs[0].RepairedUsed, 0.001)
+		assert.InDelta(t, 12.0, repairs[0].RepairedHeld, 0.001)
+		assert.Equal(t, "Manual edit", repairs[0].Reason)
+		assert.Equal(t, "ops", repairs[0].RepairedBy)
+	})
+
+	t.Run("consistent accounts are not repaired again", func(t *testing.T) {
+		resp, err := service.CheckConsistency(ctx, &api.ConsistencyCheckRequest{Repair: true})
+		require.NoError(t, err)
+		assert.Equal(t, 2, resp.Checked)
+		assert.Equal(t, 0, resp.Inconsistent)
+		assert.Equal(t, 0, resp.Repaired)
+
+		account, err := service.GetAccount(ctx, "verify-project")
+		require.NoError(t, err)
+		repairs, err := accountQueries.ListBalanceRepairs(ctx, account.ID)
+		require.NoError(t, err)
+		assert.Len(t, repairs, 1)
+	})
+}
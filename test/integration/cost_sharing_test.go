@@ -0,0 +1,173 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_CostSharing(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for account, limit := range map[string]float64{"lab": 1000.0, "grant": 1000.0, "small-grant": 4.0} {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         account,
+			BudgetLimit:  limit,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	check := func(shares []api.CostShare) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00", CostShares: shares,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	account := func(name string) *api.BudgetAccount {
+		a, err := service.GetAccount(ctx, name)
+		require.NoError(t, err)
+		return a
+	}
+
+	t.Run("60/40 split holds and reconciles on both accounts", func(t *testing.T) {
+		// The mock advisor estimates $10, held at $12
+		resp := check([]api.CostShare{{Account: "lab", Percentage: 60}, {Account: "grant", Percentage: 40}})
+		require.True(t, resp.Available, resp.Message)
+		assert.InDelta(t, 12.0, resp.HoldAmount, 0.001)
+		require.Len(t, resp.CostShares, 2)
+		assert.Equal(t, "lab", resp.CostShares[0].Account)
+		assert.InDelta(t, 7.2, resp.CostShares[0].HoldAmount, 0.001)
+		assert.Equal(t, resp.TransactionID, resp.CostShares[0].TransactionID)
+		assert.Equal(t, "grant", resp.CostShares[1].Account)
+		assert.InDelta(t, 4.8, resp.CostShares[1].HoldAmount, 0.001)
+
+		assert.InDelta(t, 7.2, account("lab").BudgetHeld, 0.001)
+		assert.InDelta(t, 4.8, account("grant").BudgetHeld, 0.001)
+
+		// Reconciling with either share's hold settles the whole job
+		reconcile, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:         "shared-1",
+			ActualCost:    10.0,
+			TransactionID: resp.CostShares[1].TransactionID,
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, reconcile.OriginalHold, 0.001)
+		assert.InDelta(t, 10.0, reconcile.ActualCharge, 0.001)
+		assert.InDelta(t, 2.0, reconcile.RefundAmount, 0.001)
+		require.Len(t, reconcile.CostShares, 2)
+		assert.InDelta(t, 6.0, reconcile.CostShares[0].ActualCharge, 0.001)
+		assert.InDelta(t, 1.2, reconcile.CostShares[0].RefundAmount, 0.001)
+		assert.InDelta(t, 4.0, reconcile.CostShares[1].ActualCharge, 0.001)
+		assert.InDelta(t, 0.8, reconcile.CostShares[1].RefundAmount, 0.001)
+
+		lab, grant := account("lab"), account("grant")
+		assert.InDelta(t, 6.0, lab.BudgetUsed, 0.001)
+		assert.InDelta(t, 0.0, lab.BudgetHeld, 0.001)
+		assert.InDelta(t, 4.0, grant.BudgetUsed, 0.001)
+		assert.InDelta(t, 0.0, grant.BudgetHeld, 0.001)
+	})
+
+	t.Run("a share that does not fit rejects the whole job", func(t *testing.T) {
+		resp := check([]api.CostShare{{Account: "lab", Percentage: 50}, {Account: "small-grant", Percentage: 50}})
+		assert.False(t, resp.Available)
+		assert.Empty(t, resp.TransactionID)
+		assert.Contains(t, resp.Message, "small-grant")
+
+		assert.InDelta(t, 0.0, account("lab").BudgetHeld, 0.001, "no share is held when one is rejected")
+		assert.InDelta(t, 0.0, account("small-grant").BudgetHeld, 0.001)
+	})
+
+	t.Run("splits must sum to 100", func(t *testing.T) {
+		_, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+			CostShares: []api.CostShare{{Account: "lab", Percentage: 60}, {Account: "grant", Percentage: 30}},
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		assert.Equal(t, "cost_shares", budgetErr.Field)
+	})
+}
+
+func TestBudget_CostSharingOpposingReconciliations(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	createHierarchyAccount(t, service, "dept-a", "", 1000)
+	createHierarchyAccount(t, service, "lab-a", "dept-a", 1000)
+	createHierarchyAccount(t, service, "dept-b", "", 1000)
+	createHierarchyAccount(t, service, "lab-b", "dept-b", 1000)
+
+	// Half the jobs belong to lab-a and half to lab-b, each split evenly with the other lab,
+	// so their reconciliations settle the two chains in opposite orders
+	const pairs = 10
+	var holds []string
+	for i := 0; i < pairs; i++ {
+		for _, owner := range []string{"lab-a", "lab-b"} {
+			resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+				Account: owner, Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+				CostShares: []api.CostShare{{Account: "lab-a", Percentage: 50}, {Account: "lab-b", Percentage: 50}},
+			})
+			require.NoError(t, err)
+			require.True(t, resp.Available, resp.Message)
+			holds = append(holds, resp.TransactionID)
+		}
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i, hold := range holds {
+		wg.Add(1)
+		go func(i int, hold string) {
+			defer wg.Done()
+			<-start
+			_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+				JobID: fmt.Sprintf("opposed-%d", i), ActualCost: 10.0, TransactionID: hold,
+			})
+			assert.NoError(t, err, "reconciliation must not deadlock")
+		}(i, hold)
+	}
+	close(start)
+	wg.Wait()
+
+	// Each of the 20 jobs held $6 and charged $5 to each lab and its department
+	for _, name := range []string{"lab-a", "dept-a", "lab-b", "dept-b"} {
+		account, err := service.GetAccount(ctx, name)
+		require.NoError(t, err)
+		assert.InDelta(t, 100.0, account.BudgetUsed, 0.001, name)
+		assert.InDelta(t, 0.0, account.BudgetHeld, 0.001, name)
+	}
+}
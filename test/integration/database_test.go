@@ -202,6 +202,93 @@ func TestDatabase_TransactionOperations(t *testing.T) {
 		}
 		assert.True(t, found, "Test transaction should be found in list")
 	})
+
+	t.Run("CreateTransactionDuplicateID", func(t *testing.T) {
+		transaction := &api.BudgetTransaction{
+			TransactionID: "test-txn-001",
+			AccountID:     account.ID,
+			Type:          "hold",
+			Amount:        50.0,
+			Description:   "Colliding hold transaction",
+			Status:        "pending",
+		}
+
+		err := transactionQueries.CreateTransaction(ctx, nil, transaction)
+		require.Error(t, err)
+		assert.True(t, api.IsRetryable(err))
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeDuplicateTransaction, budgetErr.Code)
+	})
+}
+
+func TestDatabase_TransactionSearch(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	accountQueries := database.NewAccountQueries(db)
+	transactionQueries := database.NewTransactionQueries(db)
+	ctx := context.Background()
+
+	for _, name := range []string{"search-a", "search-b"} {
+		_, err := accountQueries.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: name,
+			Name:         name,
+			BudgetLimit:  1000.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	accountA, err := accountQueries.GetAccountByName(ctx, "search-a")
+	require.NoError(t, err)
+	accountB, err := accountQueries.GetAccountByName(ctx, "search-b")
+	require.NoError(t, err)
+
+	for _, txn := range []*api.BudgetTransaction{
+		{TransactionID: "txn_search_1", AccountID: accountA.ID, Type: "charge", Amount: 300,
+			Description: "Manual charge for GPU node rental", Metadata: `{"version": 1, "reported_cost": 300, "held_amount": 0, "job": {"asbx_job_id": "OPS-4411"}}`},
+		{TransactionID: "txn_search_2", AccountID: accountA.ID, Type: "charge", Amount: 20,
+			Description: "Storage overage", Metadata: `{"version": 1, "reported_cost": 20, "held_amount": 0, "job": {"asbx_job_id": "OPS-4412"}}`},
+		{TransactionID: "txn_search_3", AccountID: accountB.ID, Type: "charge", Amount: 300,
+			Description: "Manual charge for gpu node rental", Metadata: `{"version": 1, "reported_cost": 300, "held_amount": 0, "job": {"asbx_job_id": "OPS-5000"}}`},
+		{TransactionID: "txn_search_4", AccountID: accountA.ID, Type: "adjustment", Amount: 5,
+			Description: "Rounded 100% of the fee_waiver", Metadata: `{"version": 1, "from_reserve": false}`},
+	} {
+		txn.Status = "completed"
+		require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, txn))
+	}
+
+	search := func(req *api.TransactionListRequest) []string {
+		transactions, err := transactionQueries.ListTransactions(ctx, req)
+		require.NoError(t, err)
+		var ids []string
+		for _, txn := range transactions {
+			ids = append(ids, txn.TransactionID)
+		}
+		return ids
+	}
+
+	t.Run("description substring ignores case", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"txn_search_1", "txn_search_3"}, search(&api.TransactionListRequest{Search: "GPU NODE"}))
+	})
+
+	t.Run("metadata substring", func(t *testing.T) {
+		assert.Equal(t, []string{"txn_search_2"}, search(&api.TransactionListRequest{Search: "ops-4412"}))
+	})
+
+	t.Run("combines with other filters", func(t *testing.T) {
+		assert.Equal(t, []string{"txn_search_1"}, search(&api.TransactionListRequest{Search: "gpu node", Account: "search-a"}))
+		assert.Empty(t, search(&api.TransactionListRequest{Search: "gpu node", Type: "adjustment"}))
+	})
+
+	t.Run("wildcards match literally", func(t *testing.T) {
+		assert.Equal(t, []string{"txn_search_4"}, search(&api.TransactionListRequest{Search: "100%"}))
+		assert.Equal(t, []string{"txn_search_4"}, search(&api.TransactionListRequest{Search: "fee_w"}))
+		assert.Empty(t, search(&api.TransactionListRequest{Search: "fee%waiver"}))
+	})
 }
 
 func TestDatabase_MigrationOperations(t *testing.T) {
@@ -0,0 +1,152 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestDeadLetter_RepeatedFailureIsQueuedAndReplayed(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.DeadLetterAfter = 3
+	service := budget.NewService(db, nil, &cfg.Budget)
+	transactionQueries := database.NewTransactionQueries(db)
+	ctx := context.Background()
+
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "dead-letters",
+		Name:         "Dead Letter Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-7 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// The hold the job's reconciliation names does not exist
+	req := &api.JobReconcileRequest{JobID: "4242", ActualCost: 30, TransactionID: "txn_missing_hold"}
+	for i := 0; i < 2; i++ {
+		_, err := service.ReconcileJob(ctx, req)
+		require.Error(t, err)
+	}
+	letters, err := service.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, letters, "two failures stay below the threshold")
+
+	_, err = service.ReconcileJob(ctx, req)
+	require.Error(t, err)
+	letters, err = service.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	letter := letters[0]
+	assert.Equal(t, "txn_missing_hold", letter.TransactionID)
+	assert.Equal(t, "4242", letter.JobID)
+	assert.Equal(t, api.ReconciliationSourceReconcile, letter.Source)
+	assert.Equal(t, 3, letter.Attempts)
+	assert.NotEmpty(t, letter.Reason)
+	require.NotNil(t, letter.Request)
+	assert.InDelta(t, 30.0, letter.Request.ActualCost, 0.001)
+
+	// Replaying before the problem is fixed fails again and leaves it queued
+	_, err = service.ReplayDeadLetter(ctx, letter.ID, &api.ResolveDeadLetterRequest{ResolvedBy: "ops"})
+	require.Error(t, err)
+	letters, err = service.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, 4, letters[0].Attempts)
+
+	// Restore the missing hold, then replay
+	require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, &api.BudgetTransaction{
+		TransactionID: "txn_missing_hold",
+		AccountID:     account.ID,
+		Type:          "hold",
+		Amount:        50,
+		Description:   "Restored hold",
+		Status:        "pending",
+	}))
+	replayed, err := service.ReplayDeadLetter(ctx, letter.ID, &api.ResolveDeadLetterRequest{ResolvedBy: "ops"})
+	require.NoError(t, err)
+	assert.Equal(t, api.DeadLetterReplayed, replayed.Resolution)
+	assert.Equal(t, "ops", replayed.ResolvedBy)
+	require.NotNil(t, replayed.ResolvedAt)
+
+	letters, err = service.ListDeadLetters(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+
+	updated, err := service.GetAccount(ctx, "dead-letters")
+	require.NoError(t, err)
+	assert.InDelta(t, 30.0, updated.BudgetUsed, 0.001)
+	assert.InDelta(t, 0.0, updated.BudgetHeld, 0.001)
+
+	_, err = service.ReplayDeadLetter(ctx, letter.ID, &api.ResolveDeadLetterRequest{})
+	assert.Error(t, err, "a replayed dead letter is no longer queued")
+}
+
+func TestDeadLetter_RecoverySkipsQueuedHolds(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, nil, &cfg.Budget)
+	transactionQueries := database.NewTransactionQueries(db)
+	deadLetterQueries := database.NewDeadLetterQueries(db)
+	ctx := context.Background()
+
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "dead-letter-recovery",
+		Name:         "Dead Letter Recovery Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-7 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, &api.BudgetTransaction{
+		TransactionID: "txn_stuck",
+		AccountID:     account.ID,
+		Type:          "hold",
+		Amount:        40,
+		Description:   "Seeded hold",
+		Status:        "pending",
+	}))
+	_, err = db.ExecContext(ctx, "UPDATE budget_transactions SET created_at = $1 WHERE transaction_id = $2",
+		time.Now().Add(-72*time.Hour), "txn_stuck")
+	require.NoError(t, err)
+
+	failure, err := deadLetterQueries.RecordFailure(ctx, "txn_stuck", "", api.ReconciliationSourceRecovery, nil, "refund failed")
+	require.NoError(t, err)
+	require.NoError(t, deadLetterQueries.MarkDeadLettered(ctx, failure.ID))
+
+	recovered, err := service.RecoverOrphanedHolds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, recovered.Found, "a dead-lettered hold is left for an administrator")
+
+	// Replaying a recovery dead letter cancels the hold as recovery would have
+	replayed, err := service.ReplayDeadLetter(ctx, failure.ID, &api.ResolveDeadLetterRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, api.DeadLetterReplayed, replayed.Resolution)
+
+	cancelled, err := service.GetTransaction(ctx, "txn_stuck")
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", cancelled.Status)
+}
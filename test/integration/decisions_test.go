@@ -0,0 +1,89 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_DecisionsRecorded(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	// Room for one $12 hold but not two
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "audit-lab",
+		Name:         "Audit Lab",
+		BudgetLimit:  20.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check := func() *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "audit-lab", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00",
+			UserID: "alice", JobScript: "#!/bin/bash\nsrun sim",
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	approved := check()
+	require.True(t, approved.Available)
+	rejected := check()
+	require.False(t, rejected.Available)
+
+	decisions, err := service.ListDecisions(ctx, &api.DecisionListRequest{Account: "audit-lab"})
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+
+	// Newest first
+	assert.Equal(t, api.DecisionRejected, decisions[0].Decision)
+	assert.Equal(t, "Insufficient budget", decisions[0].Reason)
+	assert.InDelta(t, 12.0, decisions[0].HoldAmount, 0.001)
+	assert.InDelta(t, 8.0, decisions[0].BudgetAvailable, 0.001)
+	assert.Empty(t, decisions[0].TransactionID)
+
+	assert.Equal(t, api.DecisionApproved, decisions[1].Decision)
+	assert.Equal(t, approved.TransactionID, decisions[1].TransactionID)
+	assert.InDelta(t, 10.0, decisions[1].EstimatedCost, 0.001)
+	assert.InDelta(t, 0.8, decisions[1].Confidence, 0.001)
+	assert.Equal(t, "alice", decisions[1].UserID)
+	require.NotNil(t, decisions[1].Inputs)
+	assert.Equal(t, 4, decisions[1].Inputs.CPUs)
+	assert.Empty(t, decisions[1].Inputs.JobScript)
+
+	onlyRejected, err := service.ListDecisions(ctx, &api.DecisionListRequest{Account: "audit-lab", Decision: api.DecisionRejected})
+	require.NoError(t, err)
+	assert.Len(t, onlyRejected, 1)
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1)
+	before, err := service.ListDecisions(ctx, &api.DecisionListRequest{Account: "audit-lab", EndDate: &yesterday})
+	require.NoError(t, err)
+	assert.Empty(t, before)
+
+	today := time.Now().UTC()
+	since, err := service.ListDecisions(ctx, &api.DecisionListRequest{Account: "audit-lab", StartDate: &yesterday, EndDate: &today})
+	require.NoError(t, err)
+	assert.Len(t, since, 2)
+}
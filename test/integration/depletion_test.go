@@ -0,0 +1,139 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestDepletion_AutoSuspendAndReactivate(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	alertQueries := database.NewAlertQueries(db)
+
+	newService := func(policy string) *budget.Service {
+		cfg := SetupTestConfig()
+		cfg.Budget.AutoSuspendOnDepletion = policy
+		return budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	}
+	scheduleAllocation := func(t *testing.T, service *budget.Service, account string) {
+		acct, err := service.GetAccount(ctx, account)
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO budget_allocation_schedules
+				(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+			VALUES ($1, 1200, 100, 'monthly', NOW() - INTERVAL '1 day', NOW() - INTERVAL '1 minute', 1200)`, acct.ID)
+		require.NoError(t, err)
+		_, err = service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+		require.NoError(t, err)
+	}
+
+	t.Run("suspends at depletion and reactivates on allocation", func(t *testing.T) {
+		service := newService("SUSPEND")
+		createHierarchyAccount(t, service, "deplete-suspend", "", 24.0)
+
+		// Two $12 holds use the whole budget; the next check finds it depleted
+		require.True(t, checkHierarchyBudget(t, service, "deplete-suspend").Available)
+		require.True(t, checkHierarchyBudget(t, service, "deplete-suspend").Available)
+		require.False(t, checkHierarchyBudget(t, service, "deplete-suspend").Available)
+
+		account, err := service.GetAccount(ctx, "deplete-suspend")
+		require.NoError(t, err)
+		assert.Equal(t, "suspended", account.Status)
+		assert.NotNil(t, account.DepletedAt)
+
+		alert, err := alertQueries.GetOpenAlert(ctx, account.ID, "budget_depleted")
+		require.NoError(t, err)
+		require.NotNil(t, alert)
+		assert.Equal(t, "critical", alert.Severity)
+
+		// Further submissions are refused by the account's state rather than checked one by one
+		_, err = service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "deplete-suspend", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00",
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAccountInactive, budgetErr.Code)
+
+		scheduleAllocation(t, service, "deplete-suspend")
+
+		account, err = service.GetAccount(ctx, "deplete-suspend")
+		require.NoError(t, err)
+		assert.Equal(t, "active", account.Status)
+		assert.Nil(t, account.DepletedAt)
+		alert, err = alertQueries.GetOpenAlert(ctx, account.ID, "budget_depleted")
+		require.NoError(t, err)
+		assert.Nil(t, alert, "the depletion alert is resolved")
+
+		assert.True(t, checkHierarchyBudget(t, service, "deplete-suspend").Available)
+	})
+
+	t.Run("freezes on the periodic check and unfreezes on allocation", func(t *testing.T) {
+		service := newService("FREEZE")
+		createHierarchyAccount(t, service, "deplete-freeze", "", 50.0)
+		_, err := db.ExecContext(ctx, `UPDATE budget_accounts SET budget_used = 50 WHERE slurm_account = 'deplete-freeze'`)
+		require.NoError(t, err)
+
+		require.NoError(t, service.EnforceDepletionPolicy(ctx))
+
+		account, err := service.GetAccount(ctx, "deplete-freeze")
+		require.NoError(t, err)
+		assert.Equal(t, "active", account.Status)
+		assert.True(t, account.Frozen)
+		assert.NotNil(t, account.DepletedAt)
+
+		scheduleAllocation(t, service, "deplete-freeze")
+
+		account, err = service.GetAccount(ctx, "deplete-freeze")
+		require.NoError(t, err)
+		assert.False(t, account.Frozen)
+		assert.Nil(t, account.DepletedAt)
+	})
+
+	t.Run("leaves a manually frozen account frozen", func(t *testing.T) {
+		service := newService("FREEZE")
+		createHierarchyAccount(t, service, "deplete-manual", "", 50.0)
+		frozen := true
+		_, err := service.UpdateAccount(ctx, "deplete-manual", &api.UpdateAccountRequest{Frozen: &frozen})
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, `UPDATE budget_accounts SET budget_used = 50 WHERE slurm_account = 'deplete-manual'`)
+		require.NoError(t, err)
+
+		require.NoError(t, service.EnforceDepletionPolicy(ctx))
+		scheduleAllocation(t, service, "deplete-manual")
+
+		account, err := service.GetAccount(ctx, "deplete-manual")
+		require.NoError(t, err)
+		assert.True(t, account.Frozen)
+		assert.Nil(t, account.DepletedAt)
+	})
+
+	t.Run("off by default", func(t *testing.T) {
+		service := newService("")
+		createHierarchyAccount(t, service, "deplete-off", "", 12.0)
+		require.True(t, checkHierarchyBudget(t, service, "deplete-off").Available)
+		require.False(t, checkHierarchyBudget(t, service, "deplete-off").Available)
+
+		account, err := service.GetAccount(ctx, "deplete-off")
+		require.NoError(t, err)
+		assert.Equal(t, "active", account.Status)
+		assert.Nil(t, account.DepletedAt)
+	})
+}
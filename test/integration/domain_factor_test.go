@@ -0,0 +1,93 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_DomainInflationFactor(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.DomainInflationFactors = map[string]float64{"genomics": 1.3}
+	cfg.Budget.DomainFactorLearning = true
+	cfg.Budget.DomainFactorMinSamples = 3
+	cfg.Budget.DomainFactorSampleLimit = 10
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	integration := asbx.NewIntegrationService(service, &asbx.IntegrationConfig{Enabled: true})
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "domain-lab",
+		Name:         "Domain Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check := func(domain string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "domain-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+			ResearchDomain: domain,
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+
+	t.Run("configured factor scales the hold", func(t *testing.T) {
+		plain := check("")
+		genomics := check("genomics")
+
+		assert.InDelta(t, 12.0, plain.HoldAmount, 0.001)
+		assert.Zero(t, plain.DomainFactor)
+		assert.InDelta(t, 13.0, genomics.EstimatedCost, 0.001)
+		assert.InDelta(t, plain.HoldAmount*1.3, genomics.HoldAmount, 0.001)
+		assert.InDelta(t, 1.3, genomics.DomainFactor, 0.001)
+	})
+
+	t.Run("learned factor replaces the default once enough jobs are reconciled", func(t *testing.T) {
+		require.NoError(t, service.RecordEstimateAccuracy(ctx, "CFD", "cfd-1", 10.0, 7.0))
+		require.NoError(t, service.RecordEstimateAccuracy(ctx, "cfd", "cfd-2", 20.0, 14.0))
+
+		// Two samples are not enough yet
+		assert.InDelta(t, 12.0, check("cfd").HoldAmount, 0.001)
+
+		// The third arrives through ASBX reconciliation
+		hold := check("cfd")
+		_, err := integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID:               "cfd-3",
+				Account:             "domain-lab",
+				EstimatedCost:       float64Ptr(10.0),
+				ActualCost:          float64Ptr(7.0),
+				ResearchDomain:      "cfd",
+				BudgetTransactionID: hold.TransactionID,
+			},
+		})
+		require.NoError(t, err)
+
+		learned := check("cfd")
+		assert.InDelta(t, 0.7, learned.DomainFactor, 0.001)
+		assert.InDelta(t, 12.0*0.7, learned.HoldAmount, 0.001)
+	})
+}
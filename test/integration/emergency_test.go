@@ -0,0 +1,93 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestEmergencyOptions_CriticalAccountNearDeadline(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.AlertWarningThreshold = 80
+	cfg.Budget.AlertCriticalThreshold = 95
+	cfg.Budget.EmergencyDeadlineWindow = 30 * 24 * time.Hour
+	service := budget.NewService(db, nil, &cfg.Budget)
+	ctx := context.Background()
+
+	create := func(slurmAccount, parent string, limit float64, ends time.Duration) {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount:  slurmAccount,
+			Name:          slurmAccount,
+			BudgetLimit:   limit,
+			ParentAccount: parent,
+			StartDate:     time.Now().Add(-30 * 24 * time.Hour),
+			EndDate:       time.Now().Add(ends),
+		})
+		require.NoError(t, err)
+	}
+	spend := func(slurmAccount string, amount float64) {
+		_, err := service.AdjustBudget(ctx, slurmAccount, &api.BudgetAdjustmentRequest{
+			Amount:      amount,
+			Description: "Seeded spending",
+		})
+		require.NoError(t, err)
+	}
+
+	create("emergency-dept", "", 10000, 365*24*time.Hour)
+	create("emergency-critical", "emergency-dept", 1000, 14*24*time.Hour)
+	create("emergency-healthy", "emergency-dept", 1000, 14*24*time.Hour)
+	create("emergency-rich", "emergency-dept", 3000, 365*24*time.Hour)
+	spend("emergency-critical", 980)
+	spend("emergency-healthy", 100)
+
+	options, err := service.GetEmergencyOptions(ctx, "emergency-healthy")
+	require.NoError(t, err)
+	assert.Empty(t, options, "a healthy account gets no emergency options")
+
+	options, err = service.GetEmergencyOptions(ctx, "emergency-critical")
+	require.NoError(t, err)
+	require.NotEmpty(t, options)
+
+	byKind := map[string]api.EmergencyOption{}
+	for _, option := range options {
+		if _, seen := byKind[option.Option]; !seen {
+			byKind[option.Option] = option
+		}
+	}
+	reallocate, ok := byKind[api.EmergencyOptionReallocate]
+	require.True(t, ok)
+	assert.Equal(t, "emergency-rich", reallocate.Account, "the sibling with the most to spare comes first")
+	assert.InDelta(t, 3000.0, reallocate.Available, 0.01)
+	assert.Greater(t, reallocate.EstimatedCost, 0.0)
+	assert.Contains(t, reallocate.Impact, "account 'emergency-critical' ends")
+	assert.NotEmpty(t, reallocate.Requirements)
+
+	sharing, ok := byKind[api.EmergencyOptionCostSharing]
+	require.True(t, ok)
+	assert.Equal(t, "emergency-rich", sharing.Account)
+	_, ok = byKind[api.EmergencyOptionEmergencyFund]
+	assert.False(t, ok, "the account holds no reserve")
+
+	// The same account far from any deadline gets no options
+	cfg.Budget.EmergencyDeadlineWindow = 7 * 24 * time.Hour
+	options, err = service.GetEmergencyOptions(ctx, "emergency-critical")
+	require.NoError(t, err)
+	assert.Empty(t, options)
+}
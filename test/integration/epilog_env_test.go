@@ -0,0 +1,131 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/slurm"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestEpilogEnv_ReconcilesWithoutASBX(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	// Jobs are checked for two hours; an hour of use costs $5
+	var priced []*budget.CostEstimateRequest
+	estimator := &advisor.MockClient{
+		EstimateFunc: func(_ context.Context, req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
+			priced = append(priced, req)
+			cost := 10.0
+			if req.WallTime == "01:00:00" {
+				cost = 5.0
+			}
+			return &budget.CostEstimateResponse{EstimatedCost: cost, Confidence: 0.9}, nil
+		},
+	}
+	service := budget.NewService(db, estimator, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "epilog-env",
+		Name:         "Epilog Env Project",
+		BudgetLimit:  100.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	hold := func() *api.BudgetCheckResponse {
+		check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "epilog-env", Partition: "aws-cpu", Nodes: 2, CPUs: 8, WallTime: "02:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, check.Available)
+		return check
+	}
+
+	// The environment slurmctld gives its epilog for a job that ran an hour on two nodes
+	end := time.Now().Add(-time.Minute).Truncate(time.Second)
+	env := func(jobID, comment string) map[string]string {
+		return map[string]string{
+			"SLURM_JOB_ID":            jobID,
+			"SLURM_JOB_ACCOUNT":       "epilog-env",
+			"SLURM_JOB_PARTITION":     "aws-cpu",
+			"SLURM_JOB_USER":          "alice",
+			"SLURM_JOB_NODELIST":      "aws-cpu-[001-002]",
+			"SLURM_JOB_CPUS_PER_NODE": "8(x2)",
+			"SLURM_JOB_START_TIME":    strconv.FormatInt(end.Add(-time.Hour).Unix(), 10),
+			"SLURM_JOB_END_TIME":      strconv.FormatInt(end.Unix(), 10),
+			"SLURM_JOB_EXIT_CODE":     "0",
+			"SLURM_JOB_EXIT_CODE2":    "0:0",
+			"SLURM_JOB_COMMENT":       comment,
+		}
+	}
+	reconcile := func(env map[string]string) *api.AccountingJobResult {
+		job, err := slurm.ParseEpilogEnv(env, time.Now())
+		require.NoError(t, err)
+		resp, err := service.ReconcileAccountingJobs(ctx, &api.AccountingReconcileRequest{Jobs: []*api.AccountingJob{job}})
+		require.NoError(t, err)
+		require.Len(t, resp.Jobs, 1)
+		return resp.Jobs[0]
+	}
+
+	t.Run("hold named in the job comment", func(t *testing.T) {
+		check := hold()
+		result := reconcile(env("6001", "asbb_txn="+check.TransactionID))
+
+		assert.Equal(t, api.AccountingJobReconciled, result.Status, result.Message)
+		assert.Equal(t, check.TransactionID, result.TransactionID)
+		assert.InDelta(t, 5.0, result.ActualCost, 0.001)
+		assert.InDelta(t, 7.0, result.RefundAmount, 0.001)
+
+		last := priced[len(priced)-1]
+		assert.Equal(t, "01:00:00", last.WallTime)
+		assert.Equal(t, 2, last.Nodes)
+		assert.Equal(t, 8, last.CPUs)
+	})
+
+	t.Run("hold recorded with the job ID", func(t *testing.T) {
+		check := hold()
+		_, err := db.ExecContext(ctx, `UPDATE budget_transactions SET job_id = '6002' WHERE transaction_id = $1`,
+			check.TransactionID)
+		require.NoError(t, err)
+
+		result := reconcile(env("6002", ""))
+		assert.Equal(t, api.AccountingJobReconciled, result.Status, result.Message)
+		assert.Equal(t, check.TransactionID, result.TransactionID)
+	})
+
+	t.Run("repeated epilog is not charged twice", func(t *testing.T) {
+		result := reconcile(env("6002", ""))
+		assert.Equal(t, api.AccountingJobAlreadyReconciled, result.Status)
+	})
+
+	t.Run("job without a hold is reported", func(t *testing.T) {
+		result := reconcile(env("6003", ""))
+		assert.Equal(t, api.AccountingJobNoHold, result.Status)
+	})
+
+	account, err := service.GetAccount(ctx, "epilog-env")
+	require.NoError(t, err)
+	assert.InDelta(t, 10.0, account.BudgetUsed, 0.001)
+	assert.InDelta(t, 0.0, account.BudgetHeld, 0.001)
+}
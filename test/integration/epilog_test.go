@@ -0,0 +1,181 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestASBX_EpilogReconcilesOnlyOwnHold(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	integration := asbx.NewIntegrationService(service, &asbx.IntegrationConfig{Enabled: true, AutoReconcile: true})
+	ctx := context.Background()
+
+	hold := func(account string) string {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         account,
+			BudgetLimit:  1000.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: account, Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.TransactionID)
+		return resp.TransactionID
+	}
+	ownHold := hold("epilog-own")
+	otherHold := hold("epilog-other")
+
+	dir := t.TempDir()
+	writeCostData := func(name string, data api.ASBXJobCostData) string {
+		content, err := json.Marshal(data)
+		require.NoError(t, err)
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, content, 0o600))
+		return path
+	}
+
+	t.Run("forged hold from another account is rejected", func(t *testing.T) {
+		path := writeCostData("forged.json", api.ASBXJobCostData{
+			JobID: "1001", Account: "epilog-own", ActualCost: float64Ptr(0), BudgetTransactionID: otherHold,
+		})
+
+		resp, err := integration.ProcessEpilogData(ctx, &api.ASBXEpilogRequest{
+			JobID: "1001", Account: "epilog-own", JobState: "COMPLETED", ASBXDataPath: path,
+		})
+		require.Error(t, err)
+		assert.Nil(t, resp)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeForbidden, budgetErr.Code)
+
+		other, err := service.GetAccount(ctx, "epilog-other")
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, other.BudgetHeld, 0.001)
+		assert.InDelta(t, 0.0, other.BudgetUsed, 0.001)
+	})
+
+	t.Run("own hold is reconciled", func(t *testing.T) {
+		path := writeCostData("own.json", api.ASBXJobCostData{
+			JobID: "1002", Account: "epilog-own", EstimatedCost: float64Ptr(10), ActualCost: float64Ptr(8), BudgetTransactionID: ownHold,
+		})
+
+		resp, err := integration.ProcessEpilogData(ctx, &api.ASBXEpilogRequest{
+			JobID: "1002", Account: "epilog-own", JobState: "COMPLETED", ASBXDataPath: path,
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.ReconciliationTriggered)
+		assert.Equal(t, "completed", resp.DataImportStatus)
+
+		own, err := service.GetAccount(ctx, "epilog-own")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.0, own.BudgetHeld, 0.001)
+		assert.InDelta(t, 8.0, own.BudgetUsed, 0.001)
+	})
+
+	t.Run("replayed post is refused and charges nothing", func(t *testing.T) {
+		path := filepath.Join(dir, "own.json")
+		_, err := integration.ProcessEpilogData(ctx, &api.ASBXEpilogRequest{
+			JobID: "1002", Account: "epilog-own", JobState: "COMPLETED", ASBXDataPath: path,
+		})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeHoldSettled, budgetErr.Code)
+
+		own, err := service.GetAccount(ctx, "epilog-own")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.0, own.BudgetHeld, 0.001)
+		assert.InDelta(t, 8.0, own.BudgetUsed, 0.001)
+
+		var charges int
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM budget_transactions WHERE parent_transaction_id = $1`, ownHold).Scan(&charges))
+		assert.Equal(t, 2, charges, "one charge and one refund")
+	})
+}
+
+func TestBudget_ConcurrentReconciliationsSettleOnce(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "settle-once",
+		Name:         "Settle Once",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+	check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+		Account: "settle-once", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00",
+	})
+	require.NoError(t, err)
+
+	const attempts = 5
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+				JobID: "2001", ActualCost: 15.0, TransactionID: check.TransactionID,
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	settled := 0
+	for err := range errs {
+		if err == nil {
+			settled++
+			continue
+		}
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, err)
+		assert.Equal(t, api.ErrCodeHoldSettled, budgetErr.Code)
+	}
+	assert.Equal(t, 1, settled)
+
+	account, err := service.GetAccount(ctx, "settle-once")
+	require.NoError(t, err)
+	assert.InDelta(t, 15.0, account.BudgetUsed, 0.001)
+	assert.InDelta(t, 0.0, account.BudgetHeld, 0.001)
+}
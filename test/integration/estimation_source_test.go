@@ -0,0 +1,101 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_AccountEstimationSource(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	// A healthy advisor that counts how often it is asked
+	advisorCalls := 0
+	mock := &advisor.MockClient{
+		EstimateFunc: func(ctx context.Context, req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
+			advisorCalls++
+			return &budget.CostEstimateResponse{EstimatedCost: 10.0, Confidence: 0.8}, nil
+		},
+	}
+	service := budget.NewService(db, mock, &cfg.Budget)
+	service.SetFailureMode("STRICT")
+	service.SetStaticCostRate(0.25)
+
+	createAccount := func(name, source string) {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount:     name,
+			Name:             name,
+			BudgetLimit:      1000.0,
+			StartDate:        time.Now().Add(-24 * time.Hour),
+			EndDate:          time.Now().Add(365 * 24 * time.Hour),
+			EstimationSource: source,
+		})
+		require.NoError(t, err)
+	}
+	check := func(name string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: name, Partition: "cpu", Nodes: 2, CPUs: 4, WallTime: "02:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+
+	createAccount("production", api.EstimationSourceAdvisor)
+	createAccount("exploratory", api.EstimationSourceFallback)
+	createAccount("flat-rate", api.EstimationSourceStatic)
+
+	t.Run("advisor account asks the advisor", func(t *testing.T) {
+		before := advisorCalls
+		resp := check("production")
+		assert.Equal(t, before+1, advisorCalls)
+		assert.Equal(t, 10.0, resp.EstimatedCost)
+	})
+
+	t.Run("fallback account bypasses a healthy advisor", func(t *testing.T) {
+		before := advisorCalls
+		resp := check("exploratory")
+		assert.Equal(t, before, advisorCalls)
+		assert.NotEqual(t, 10.0, resp.EstimatedCost)
+		assert.Empty(t, resp.FailureMode)
+		assert.Empty(t, resp.Warning)
+		assert.NotEmpty(t, resp.TransactionID)
+	})
+
+	t.Run("static account is priced at the flat rate", func(t *testing.T) {
+		before := advisorCalls
+		resp := check("flat-rate")
+		assert.Equal(t, before, advisorCalls)
+		assert.InDelta(t, 2*4*0.25*2, resp.EstimatedCost, 0.001)
+	})
+
+	t.Run("clearing the source reverts to the advisor", func(t *testing.T) {
+		cleared := ""
+		account, err := service.UpdateAccount(ctx, "exploratory", &api.UpdateAccountRequest{EstimationSource: &cleared})
+		require.NoError(t, err)
+		assert.Nil(t, account.EstimationSource)
+
+		before := advisorCalls
+		check("exploratory")
+		assert.Equal(t, before+1, advisorCalls)
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestRecovery_ExpiredHoldPolicy(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	// No reconciliation feedback: AWS partitions are charged their estimate on expiry,
+	// anything else is refunded
+	cfg := SetupTestConfig()
+	cfg.Budget.ExpiredHoldPolicy = "REFUND"
+	cfg.Budget.PartitionExpiredHoldPolicy = map[string]string{"aws": "CHARGE"}
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "expiring",
+		Name:         "Expiring Holds Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-7 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Each job is estimated at $10 and held at $12
+	awsHold := placeAgedHold(t, db, service,
+		&api.BudgetCheckRequest{Account: "expiring", Partition: "aws", Nodes: 1, CPUs: 4, WallTime: "01:00:00"}, 72*time.Hour)
+	onPremHold := placeAgedHold(t, db, service,
+		&api.BudgetCheckRequest{Account: "expiring", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00"}, 72*time.Hour)
+
+	recovered, err := service.RecoverOrphanedHolds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, recovered.Found)
+	assert.Equal(t, []string{awsHold}, recovered.Charged)
+	assert.Equal(t, []string{onPremHold}, recovered.Recovered)
+	assert.Empty(t, recovered.Failed)
+
+	t.Run("AWS hold is converted to a charge at its estimate", func(t *testing.T) {
+		hold, err := service.GetTransaction(ctx, awsHold)
+		require.NoError(t, err)
+		assert.Equal(t, "completed", hold.Status)
+
+		var amount float64
+		var metadata string
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT amount, metadata::text FROM budget_transactions
+			WHERE parent_transaction_id = $1 AND type = 'charge'`, awsHold).Scan(&amount, &metadata))
+		assert.InDelta(t, 10.0, amount, 0.001)
+		decoded, err := api.DecodeTransactionMetadata("charge", metadata)
+		require.NoError(t, err)
+		charge, ok := decoded.(*api.ChargeMetadata)
+		require.True(t, ok)
+		assert.True(t, charge.ChargedAtEstimate)
+	})
+
+	t.Run("on-premises hold is refunded", func(t *testing.T) {
+		hold, err := service.GetTransaction(ctx, onPremHold)
+		require.NoError(t, err)
+		assert.Equal(t, "cancelled", hold.Status)
+	})
+
+	// Only the AWS job's estimate is spent; both holds are released
+	updated, err := service.GetAccount(ctx, "expiring")
+	require.NoError(t, err)
+	assert.InDelta(t, 10.0, updated.BudgetUsed, 0.001)
+	assert.InDelta(t, 0.0, updated.BudgetHeld, 0.001)
+
+	result, err := service.VerifyAccountConsistency(ctx, "expiring")
+	require.NoError(t, err)
+	assert.True(t, result.Consistent)
+}
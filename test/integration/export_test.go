@@ -0,0 +1,177 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestAccountExport_ImportRestoresIdenticalAccount(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	holdPercentage := 1.5
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "export-dept", Name: "Department", BudgetLimit: 5000.0},
+		{
+			SlurmAccount:      "export-lab",
+			Name:              "Lab",
+			BudgetLimit:       500.0,
+			ParentAccount:     "export-dept",
+			HoldPercentage:    &holdPercentage,
+			ReservedAmount:    25.0,
+			Tags:              map[string]string{"department": "physics"},
+			AllowedPartitions: []string{"cpu", "gpu"},
+		},
+	} {
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	lab, err := service.GetAccount(ctx, "export-lab")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_partition_limits (account_id, partition, limit_amount, used_amount)
+		VALUES ($1, 'cpu', 300, 40), ($1, 'gpu', 150, 0)`, lab.ID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_allocation_schedules
+			(account_id, total_budget, allocation_amount, allocation_frequency, start_date, end_date,
+			 next_allocation_date, allocated_to_date, remaining_budget)
+		VALUES ($1, 1200, 100, 'monthly', NOW() - INTERVAL '2 months', NOW() + INTERVAL '10 months',
+		        NOW() + INTERVAL '1 month', 200, 1000)`, lab.ID)
+	require.NoError(t, err)
+
+	// One job has finished and one is still holding budget
+	finished := checkHierarchyBudget(t, service, "export-lab")
+	_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID: "export-1", ActualCost: 8.0, TransactionID: finished.TransactionID,
+	})
+	require.NoError(t, err)
+	checkHierarchyBudget(t, service, "export-lab")
+
+	export, err := service.ExportAccount(ctx, "export-lab")
+	require.NoError(t, err)
+	assert.Equal(t, api.AccountExportVersion, export.Version)
+	assert.Equal(t, "export-dept", export.ParentAccount)
+	assert.Len(t, export.PartitionLimits, 2)
+	assert.Len(t, export.AllocationSchedules, 1)
+	assert.Len(t, export.Transactions, 3)
+
+	t.Run("the account must not already exist", func(t *testing.T) {
+		_, err := service.ImportAccount(ctx, export)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Contains(t, []api.ErrorCode{api.ErrCodeDuplicateAccount, api.ErrCodeDuplicateTransaction}, budgetErr.Code)
+	})
+
+	dept, err := service.GetAccount(ctx, "export-dept")
+	require.NoError(t, err)
+
+	// The backup is written out and read back as an administrator would keep it
+	data, err := json.Marshal(export)
+	require.NoError(t, err)
+	var restored api.AccountExport
+	require.NoError(t, json.Unmarshal(data, &restored))
+
+	require.NoError(t, service.DeleteAccount(ctx, "export-lab"))
+	t.Run("a deleted account's balances come off its parent", func(t *testing.T) {
+		after, err := service.GetAccount(ctx, "export-dept")
+		require.NoError(t, err)
+		assert.InDelta(t, dept.BudgetUsed-export.Account.BudgetUsed, after.BudgetUsed, 0.001)
+		assert.InDelta(t, dept.BudgetHeld-export.Account.BudgetHeld, after.BudgetHeld, 0.001)
+	})
+
+	resp, err := service.ImportAccount(ctx, &restored)
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.PartitionLimits)
+	assert.Equal(t, 1, resp.AllocationSchedules)
+	assert.Equal(t, 3, resp.Transactions)
+
+	t.Run("a second export matches the first", func(t *testing.T) {
+		again, err := service.ExportAccount(ctx, "export-lab")
+		require.NoError(t, err)
+		assert.Equal(t, normalizeExport(t, export), normalizeExport(t, again))
+	})
+
+	t.Run("the restored balances roll up to the parent", func(t *testing.T) {
+		after, err := service.GetAccount(ctx, "export-dept")
+		require.NoError(t, err)
+		assert.InDelta(t, dept.BudgetUsed, after.BudgetUsed, 0.001)
+		assert.InDelta(t, dept.BudgetHeld, after.BudgetHeld, 0.001)
+	})
+
+	t.Run("the restored account keeps working", func(t *testing.T) {
+		account, err := service.GetAccount(ctx, "export-lab")
+		require.NoError(t, err)
+		assert.InDelta(t, 8.0, account.BudgetUsed, 0.001)
+
+		var hold string
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT transaction_id FROM budget_transactions
+			WHERE account_id = $1 AND type = 'hold' AND status = 'pending'`, account.ID).Scan(&hold))
+		_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{JobID: "export-2", ActualCost: 5.0, TransactionID: hold})
+		require.NoError(t, err)
+
+		account, err = service.GetAccount(ctx, "export-lab")
+		require.NoError(t, err)
+		assert.InDelta(t, 13.0, account.BudgetUsed, 0.001)
+		assert.Zero(t, account.BudgetHeld)
+	})
+}
+
+// normalizeExport renders an export without the values a restore is expected to change:
+// database IDs, the export time and the account's last update
+func normalizeExport(t *testing.T, export *api.AccountExport) string {
+	t.Helper()
+
+	copied := *export
+	account := *export.Account
+	account.ID = 0
+	account.UpdatedAt = time.Time{}
+	copied.Account = &account
+	copied.ExportedAt = time.Time{}
+
+	copied.PartitionLimits = append([]api.BudgetPartitionLimit(nil), export.PartitionLimits...)
+	for i := range copied.PartitionLimits {
+		copied.PartitionLimits[i].ID = 0
+		copied.PartitionLimits[i].AccountID = 0
+	}
+	copied.AllocationSchedules = append([]api.BudgetAllocationSchedule(nil), export.AllocationSchedules...)
+	for i := range copied.AllocationSchedules {
+		copied.AllocationSchedules[i].ID = 0
+		copied.AllocationSchedules[i].AccountID = 0
+		copied.AllocationSchedules[i].UpdatedAt = time.Time{}
+	}
+	copied.Transactions = append([]api.BudgetTransaction(nil), export.Transactions...)
+	for i := range copied.Transactions {
+		copied.Transactions[i].ID = 0
+		copied.Transactions[i].AccountID = 0
+	}
+
+	data, err := json.Marshal(copied)
+	require.NoError(t, err)
+	return string(data)
+}
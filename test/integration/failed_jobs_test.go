@@ -0,0 +1,136 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_FailedJobPolicy(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+
+	// holdFor opens an account and places the $12 hold for one job on it
+	holdFor := func(service *budget.Service, account string) string {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         account,
+			BudgetLimit:  100.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: account, Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.TransactionID)
+		return resp.TransactionID
+	}
+
+	// policyOf returns the failed job policy recorded on a job's transactions of a type
+	policyOf := func(service *budget.Service, account, jobID, txnType string) string {
+		transactions, err := service.ListTransactions(ctx, &api.TransactionListRequest{Account: account, JobID: jobID, Type: txnType})
+		require.NoError(t, err)
+		require.Len(t, transactions, 1)
+
+		var metadata map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(transactions[0].Metadata), &metadata))
+		assert.Equal(t, "FAILED", metadata["job_state"])
+		return metadata["failed_job_policy"].(string)
+	}
+
+	t.Run("failed job is charged its actual cost", func(t *testing.T) {
+		cfg := SetupTestConfig()
+		cfg.Budget.ChargeFailedJobs = true
+		service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+		hold := holdFor(service, "failed-charge")
+
+		resp, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "5001", ActualCost: 0.5, TransactionID: hold, JobState: "FAILED",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, api.FailedJobPolicyChargeActual, resp.FailedJobPolicy)
+		assert.InDelta(t, 0.5, resp.ActualCharge, 0.001)
+		assert.InDelta(t, 11.5, resp.RefundAmount, 0.001)
+
+		account, err := service.GetAccount(ctx, "failed-charge")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.5, account.BudgetUsed, 0.001)
+		assert.InDelta(t, 0.0, account.BudgetHeld, 0.001)
+		assert.Equal(t, api.FailedJobPolicyChargeActual, policyOf(service, "failed-charge", "5001", "charge"))
+	})
+
+	t.Run("failed job is refunded in full", func(t *testing.T) {
+		cfg := SetupTestConfig()
+		cfg.Budget.ChargeFailedJobs = false
+		service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+		hold := holdFor(service, "failed-refund")
+
+		resp, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "5002", ActualCost: 0.5, TransactionID: hold, JobState: "FAILED",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, api.FailedJobPolicyFullRefund, resp.FailedJobPolicy)
+		assert.InDelta(t, 0.0, resp.ActualCharge, 0.001)
+		assert.InDelta(t, 12.0, resp.RefundAmount, 0.001)
+
+		account, err := service.GetAccount(ctx, "failed-refund")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.0, account.BudgetUsed, 0.001)
+		assert.InDelta(t, 0.0, account.BudgetHeld, 0.001)
+		assert.Equal(t, api.FailedJobPolicyFullRefund, policyOf(service, "failed-refund", "5002", "refund"))
+
+		// A completed job is charged as usual under the same policy
+		hold = holdFor(service, "failed-refund-completed")
+		resp, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "5003", ActualCost: 0.5, TransactionID: hold, JobState: "COMPLETED",
+		})
+		require.NoError(t, err)
+		assert.Empty(t, resp.FailedJobPolicy)
+		assert.InDelta(t, 0.5, resp.ActualCharge, 0.001)
+	})
+
+	t.Run("ASBX reconciliation honors the policy", func(t *testing.T) {
+		cfg := SetupTestConfig()
+		cfg.Budget.ChargeFailedJobs = false
+		service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+		integration := asbx.NewIntegrationService(service, &asbx.IntegrationConfig{Enabled: true})
+		hold := holdFor(service, "failed-asbx")
+
+		resp, err := integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID: "5004", Account: "failed-asbx", JobState: "FAILED",
+				EstimatedCost: float64Ptr(10), ActualCost: float64Ptr(0.75), BudgetTransactionID: hold,
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, api.FailedJobPolicyFullRefund, resp.FailedJobPolicy)
+		assert.InDelta(t, 0.0, resp.ChargedAmount, 0.001)
+		assert.InDelta(t, 12.0, resp.RefundAmount, 0.001)
+
+		account, err := service.GetAccount(ctx, "failed-asbx")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.0, account.BudgetUsed, 0.001)
+	})
+}
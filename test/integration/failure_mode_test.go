@@ -0,0 +1,109 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// unavailableAdvisor fails every estimate, as an unreachable advisor service would
+type unavailableAdvisor struct{}
+
+func (unavailableAdvisor) EstimateCost(ctx context.Context, req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestBudget_CheckBudgetFailureModes(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, unavailableAdvisor{}, &cfg.Budget)
+	ctx := context.Background()
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "failure-mode",
+		Name:         "Failure Mode Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check := func() (*api.BudgetCheckResponse, error) {
+		return service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account:   "failure-mode",
+			Partition: "cpu",
+			Nodes:     1,
+			CPUs:      4,
+			WallTime:  "01:00:00",
+		})
+	}
+
+	t.Run("strict rejects", func(t *testing.T) {
+		service.SetFailureMode("STRICT")
+		resp, err := check()
+		require.Error(t, err)
+		assert.Nil(t, resp)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAdvisorUnavailable, budgetErr.Code)
+	})
+
+	t.Run("graceful holds the fallback estimate", func(t *testing.T) {
+		service.SetFailureMode("GRACEFUL")
+		resp, err := check()
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+		assert.Equal(t, "GRACEFUL", resp.FailureMode)
+		assert.NotEmpty(t, resp.Warning)
+		assert.Greater(t, resp.HoldAmount, 0.0)
+		assert.InDelta(t, resp.EstimatedCost*cfg.Budget.DefaultHoldPercentage, resp.HoldAmount, 0.001)
+	})
+
+	t.Run("permissive approves without a hold", func(t *testing.T) {
+		service.SetFailureMode("PERMISSIVE")
+		before, err := service.GetAccount(ctx, "failure-mode")
+		require.NoError(t, err)
+
+		resp, err := check()
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+		assert.Equal(t, "PERMISSIVE", resp.FailureMode)
+		assert.NotEmpty(t, resp.Warning)
+		assert.Zero(t, resp.HoldAmount)
+		require.NotEmpty(t, resp.TransactionID)
+
+		after, err := service.GetAccount(ctx, "failure-mode")
+		require.NoError(t, err)
+		assert.InDelta(t, before.BudgetHeld, after.BudgetHeld, 0.001)
+
+		// Reconciling the zero hold charges the actual cost without releasing other holds
+		_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:         "2001",
+			TransactionID: resp.TransactionID,
+			ActualCost:    3.0,
+		})
+		require.NoError(t, err)
+
+		reconciled, err := service.GetAccount(ctx, "failure-mode")
+		require.NoError(t, err)
+		assert.InDelta(t, before.BudgetUsed+3.0, reconciled.BudgetUsed, 0.001)
+		assert.InDelta(t, before.BudgetHeld, reconciled.BudgetHeld, 0.001)
+	})
+}
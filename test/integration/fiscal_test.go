@@ -0,0 +1,170 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestAllocations_FiscalYearStart(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.FiscalYearStart = "07-01"
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	createAccount := func(slurmAccount, fiscalYearStart string) *api.BudgetAccount {
+		account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount:    slurmAccount,
+			Name:            slurmAccount,
+			StartDate:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:         time.Now().Add(365 * 24 * time.Hour),
+			FiscalYearStart: fiscalYearStart,
+		})
+		require.NoError(t, err)
+		return account
+	}
+	addSchedule := func(account *api.BudgetAccount, frequency string, due time.Time) int64 {
+		var scheduleID int64
+		require.NoError(t, db.QueryRowContext(ctx, `
+			INSERT INTO budget_allocation_schedules
+				(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+			VALUES ($1, 1200, 100, $2, $3, $3, 1200)
+			RETURNING id`, account.ID, frequency, due).Scan(&scheduleID))
+		return scheduleID
+	}
+	nextDate := func(scheduleID int64) time.Time {
+		var next time.Time
+		require.NoError(t, db.QueryRowContext(ctx,
+			"SELECT next_allocation_date FROM budget_allocation_schedules WHERE id = $1", scheduleID).Scan(&next))
+		return next
+	}
+
+	october := createAccount("fiscal-october", "10-01")
+	assert.Equal(t, "10-01", *october.FiscalYearStart)
+	configured := createAccount("fiscal-configured", "")
+	assert.Nil(t, configured.FiscalYearStart)
+
+	midQuarter := time.Date(2025, 8, 15, 0, 0, 0, 0, time.UTC)
+	beforeYearStart := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	quarterly := addSchedule(october, "quarterly", time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC))
+	yearly := addSchedule(configured, "yearly", beforeYearStart)
+	monthly := addSchedule(configured, "monthly", midQuarter)
+
+	resp, err := service.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.ProcessedCount)
+	assert.InDelta(t, 300.0, resp.TotalAllocated, 0.001)
+
+	expectations := []struct {
+		name       string
+		scheduleID int64
+		fiscal     api.FiscalYearStart
+		due        time.Time
+		frequency  string
+		expected   time.Time
+	}{
+		{"account override aligns quarters", quarterly, api.FiscalYearStart{Month: time.October, Day: 1},
+			time.Date(2025, 11, 20, 0, 0, 0, 0, time.UTC), "quarterly", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"configured year aligns yearly", yearly, api.FiscalYearStart{Month: time.July, Day: 1},
+			beforeYearStart, "yearly", time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"monthly is unaffected", monthly, api.FiscalYearStart{Month: time.July, Day: 1},
+			midQuarter, "monthly", time.Date(2025, 9, 15, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range expectations {
+		t.Run(tt.name, func(t *testing.T) {
+			next := nextDate(tt.scheduleID)
+			assert.True(t, tt.expected.Equal(next), "expected %s, got %s", tt.expected, next)
+
+			goNext, err := tt.fiscal.NextAllocationDate(tt.due, tt.frequency, time.UTC)
+			require.NoError(t, err)
+			assert.True(t, goNext.Equal(next), "Go and SQL disagree: %s vs %s", goNext, next)
+		})
+	}
+
+	t.Run("without a fiscal year quarters step from the due date", func(t *testing.T) {
+		calendar := createAccount("fiscal-calendar", "")
+		scheduleID := addSchedule(calendar, "quarterly", midQuarter)
+
+		calendarService := budget.NewService(db, &advisor.MockClient{}, &SetupTestConfig().Budget)
+		_, err := calendarService.ProcessAllocations(ctx, &api.ProcessAllocationsRequest{})
+		require.NoError(t, err)
+
+		next := nextDate(scheduleID)
+		assert.True(t, time.Date(2025, 11, 15, 0, 0, 0, 0, time.UTC).Equal(next), "got %s", next)
+	})
+
+	t.Run("clearing the override reverts to the configured year", func(t *testing.T) {
+		cleared := ""
+		updated, err := service.UpdateAccount(ctx, "fiscal-october", &api.UpdateAccountRequest{FiscalYearStart: &cleared})
+		require.NoError(t, err)
+		assert.Nil(t, updated.FiscalYearStart)
+	})
+}
+
+func TestUsage_ByFiscalQuarter(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.FiscalYearStart = "07-01"
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "fiscal-usage",
+		Name:         "Fiscal Usage",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Charge jobs now, then move their charges to dates either side of the fiscal year end
+	chargedOn := map[string]time.Time{
+		"fy-1": time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC),
+		"fy-2": time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC),
+		"fy-3": time.Date(2025, 9, 30, 12, 0, 0, 0, time.UTC),
+	}
+	for jobID, date := range chargedOn {
+		check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "fiscal-usage", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{JobID: jobID, ActualCost: 10, TransactionID: check.TransactionID})
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, "UPDATE budget_transactions SET created_at = $1 WHERE job_id = $2", date, jobID)
+		require.NoError(t, err)
+	}
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC)
+	resp, err := service.UsageByFiscalQuarter(ctx, &api.UsageReportRequest{Account: "fiscal-usage", StartDate: &start, EndDate: &end})
+	require.NoError(t, err)
+	assert.InDelta(t, 30.0, resp.Summary.TotalSpent, 0.001)
+	require.Len(t, resp.Breakdown, 2)
+	assert.Equal(t, "FY2025 Q4", resp.Breakdown[0].Label)
+	assert.InDelta(t, 10.0, resp.Breakdown[0].Amount, 0.001)
+	assert.Equal(t, "FY2026 Q1", resp.Breakdown[1].Label)
+	assert.InDelta(t, 20.0, resp.Breakdown[1].Amount, 0.001)
+	assert.Equal(t, int64(2), resp.Breakdown[1].JobCount)
+}
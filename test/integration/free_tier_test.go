@@ -0,0 +1,106 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_MinChargeableCost(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	createAccount := func(service *budget.Service, name string) {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: name,
+			Name:         name,
+			BudgetLimit:  1000.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+	ledger := func(service *budget.Service, name string) []*api.BudgetTransaction {
+		transactions, err := service.ListTransactions(ctx, &api.TransactionListRequest{Account: name, Limit: 100})
+		require.NoError(t, err)
+		return transactions
+	}
+	check := func(service *budget.Service, name, partition string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: name, Partition: partition, Nodes: 1, CPUs: 1, WallTime: "00:05:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+
+	t.Run("sub-threshold job leaves a single charge", func(t *testing.T) {
+		freeCfg := cfg.Budget
+		freeCfg.MinChargeableCost = 20.0 // the mock advisor estimates $10
+		service := budget.NewService(db, &advisor.MockClient{}, &freeCfg)
+		createAccount(service, "free-tier")
+
+		resp := check(service, "free-tier", "cpu")
+		assert.Empty(t, resp.TransactionID)
+		assert.Equal(t, 0.0, resp.HoldAmount)
+		assert.Empty(t, ledger(service, "free-tier"))
+
+		reconciled, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "debug-1", Account: "free-tier", ActualCost: 0.004,
+		})
+		require.NoError(t, err)
+		assert.NotEmpty(t, reconciled.TransactionID)
+
+		transactions := ledger(service, "free-tier")
+		require.Len(t, transactions, 1)
+		assert.Equal(t, "charge", transactions[0].Type)
+
+		account, err := service.GetAccount(ctx, "free-tier")
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, account.BudgetHeld)
+	})
+
+	t.Run("partition override makes debug free", func(t *testing.T) {
+		partitionCfg := cfg.Budget
+		partitionCfg.PartitionMinChargeableCost = map[string]float64{"debug": 1000.0}
+		service := budget.NewService(db, &advisor.MockClient{}, &partitionCfg)
+		createAccount(service, "free-debug")
+
+		assert.Empty(t, check(service, "free-debug", "debug").TransactionID)
+		assert.NotEmpty(t, check(service, "free-debug", "cpu").TransactionID)
+		assert.Len(t, ledger(service, "free-debug"), 1)
+	})
+
+	t.Run("zero threshold holds as usual", func(t *testing.T) {
+		service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+		createAccount(service, "no-free-tier")
+
+		resp := check(service, "no-free-tier", "cpu")
+		assert.NotEmpty(t, resp.TransactionID)
+		assert.InDelta(t, 12.0, resp.HoldAmount, 0.001)
+		assert.Len(t, ledger(service, "no-free-tier"), 1)
+
+		_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{JobID: "job-1", ActualCost: 8})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	})
+}
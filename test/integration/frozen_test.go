@@ -0,0 +1,116 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_FrozenAccount(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "frozen-dept", Name: "Department"},
+		{SlurmAccount: "frozen-project", Name: "Project", ParentAccount: "frozen-dept"},
+	} {
+		req.BudgetLimit = 100.0
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	setFrozen := func(account string, frozen bool) *api.BudgetAccount {
+		updated, err := service.UpdateAccount(ctx, account, &api.UpdateAccountRequest{Frozen: &frozen})
+		require.NoError(t, err)
+		return updated
+	}
+	check := func() (*api.BudgetCheckResponse, error) {
+		return service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "frozen-project", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+	}
+	assertFrozen := func(err error, account string) {
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAccountFrozen, budgetErr.Code)
+		assert.Contains(t, budgetErr.Message, account)
+	}
+
+	// A job is already running when the account is frozen
+	running, err := check()
+	require.NoError(t, err)
+	require.True(t, running.Available)
+
+	account := setFrozen("frozen-project", true)
+	assert.True(t, account.Frozen)
+	assert.Equal(t, "active", account.Status)
+
+	t.Run("new holds are refused", func(t *testing.T) {
+		_, err := check()
+		assertFrozen(err, "frozen-project")
+	})
+
+	t.Run("running jobs still reconcile", func(t *testing.T) {
+		resp, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "frozen-1", ActualCost: 8.0, TransactionID: running.TransactionID,
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 4.0, resp.RefundAmount, 0.001)
+
+		updated, err := service.GetAccount(ctx, "frozen-project")
+		require.NoError(t, err)
+		assert.InDelta(t, 8.0, updated.BudgetUsed, 0.001)
+		assert.InDelta(t, 0.0, updated.BudgetHeld, 0.001)
+	})
+
+	t.Run("allocations still apply", func(t *testing.T) {
+		updated, err := service.GetAccount(ctx, "frozen-project")
+		require.NoError(t, err)
+
+		_, err = db.ExecContext(ctx, `
+			INSERT INTO budget_allocation_schedules
+				(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+			VALUES ($1, 500, 50, 'monthly', $2, $2, 500)`, updated.ID, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		_, err = db.ExecContext(ctx, "SELECT * FROM process_pending_allocations()")
+		require.NoError(t, err)
+
+		allocated, err := service.GetAccount(ctx, "frozen-project")
+		require.NoError(t, err)
+		assert.InDelta(t, 150.0, allocated.BudgetLimit, 0.001)
+		assert.True(t, allocated.Frozen)
+	})
+
+	t.Run("unfreezing resumes holds", func(t *testing.T) {
+		setFrozen("frozen-project", false)
+		_, err := check()
+		require.NoError(t, err)
+	})
+
+	t.Run("a frozen parent refuses holds for its children", func(t *testing.T) {
+		setFrozen("frozen-dept", true)
+		_, err := check()
+		assertFrozen(err, "frozen-dept")
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestGrant_IndirectCostsExcludeAgencyCategories(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.IndirectCostExclusions = map[string][]string{"nih": {"Storage"}}
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	createHierarchyAccount(t, service, "costs-lab", "", 500)
+	createHierarchyAccount(t, service, "costs-student", "costs-lab", 100)
+	createHierarchyAccount(t, service, "costs-other", "", 500)
+
+	grantStart := time.Now().Add(-30 * 24 * time.Hour)
+	var grantID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+		INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+		                            grant_start_date, grant_end_date, total_award_amount, indirect_cost_rate)
+		VALUES ('NIH-COSTS', 'NIH', 'Dr. Jones', 'University', $1, $2, 3000.00, 0.5)
+		RETURNING id`, grantStart, grantStart.AddDate(3, 0, 0)).Scan(&grantID))
+	_, err := db.ExecContext(ctx, `
+		UPDATE budget_accounts SET grant_id = $1, is_grant_funded = TRUE
+		WHERE slurm_account = 'costs-lab'`, grantID)
+	require.NoError(t, err)
+
+	costs := func() (direct, excluded, indirect float64) {
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT direct_costs, excluded_direct_costs, indirect_costs FROM grant_accounts WHERE id = $1`,
+			grantID).Scan(&direct, &excluded, &indirect))
+		return direct, excluded, indirect
+	}
+	runJob := func(account, jobID string, actualCost float64, breakdown map[string]float64) {
+		check := checkHierarchyBudget(t, service, account)
+		require.True(t, check.Available)
+		_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:         jobID,
+			ActualCost:    actualCost,
+			TransactionID: check.TransactionID,
+			CostBreakdown: breakdown,
+		})
+		require.NoError(t, err)
+	}
+
+	// The student account rolls up into the grant-funded lab, so its charges are the grant's
+	runJob("costs-student", "costs-1", 10, map[string]float64{"compute": 7, "storage": 3})
+	direct, excluded, indirect := costs()
+	assert.InDelta(t, 10.0, direct, 0.001)
+	assert.InDelta(t, 3.0, excluded, 0.001, "storage is excluded for NIH, matched case-insensitively")
+	assert.InDelta(t, 3.5, indirect, 0.001, "indirect costs are (10 - 3) * 0.5")
+
+	runJob("costs-lab", "costs-2", 5, nil)
+	runJob("costs-other", "costs-3", 8, map[string]float64{"storage": 8})
+	direct, excluded, indirect = costs()
+	assert.InDelta(t, 15.0, direct, 0.001, "charges outside the grant's accounts are not counted")
+	assert.InDelta(t, 3.0, excluded, 0.001)
+	assert.InDelta(t, 6.0, indirect, 0.001)
+
+	t.Run("recompute backfills costs", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, `
+			UPDATE grant_accounts SET direct_costs = 0, excluded_direct_costs = 0 WHERE id = $1`, grantID)
+		require.NoError(t, err)
+
+		result, err := service.RecomputeGrantCosts(ctx, "NIH-COSTS")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"storage"}, result.ExcludedCategories)
+		assert.InDelta(t, 0.0, result.PreviousDirectCosts, 0.001)
+		assert.InDelta(t, 0.0, result.PreviousIndirectCosts, 0.001)
+		assert.InDelta(t, 15.0, result.Grant.DirectCosts, 0.001)
+		assert.InDelta(t, 3.0, result.Grant.ExcludedDirectCosts, 0.001)
+		assert.InDelta(t, 6.0, result.Grant.IndirectCosts, 0.001)
+	})
+
+	t.Run("without exclusions every direct cost bears indirect costs", func(t *testing.T) {
+		unexcluded := SetupTestConfig()
+		result, err := budget.NewService(db, &advisor.MockClient{}, &unexcluded.Budget).RecomputeGrantCosts(ctx, "NIH-COSTS")
+		require.NoError(t, err)
+		assert.Empty(t, result.ExcludedCategories)
+		assert.InDelta(t, 0.0, result.Grant.ExcludedDirectCosts, 0.001)
+		assert.InDelta(t, 7.5, result.Grant.IndirectCosts, 0.001)
+	})
+
+	t.Run("unknown grant", func(t *testing.T) {
+		_, err := service.RecomputeGrantCosts(ctx, "NIH-MISSING")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+}
@@ -0,0 +1,147 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestGrant_EndDateEnforcement(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	blockCfg := SetupTestConfig()
+	blockCfg.Budget.GrantEndDatePolicy = "BLOCK"
+	blocking := budget.NewService(db, &advisor.MockClient{}, &blockCfg.Budget)
+
+	// The grant funds the lab and ends in two hours; the student account rolls up into the
+	// lab, so it is funded by the grant too
+	createHierarchyAccount(t, service, "end-lab", "", 500)
+	createHierarchyAccount(t, service, "end-student", "end-lab", 100)
+	grantEnd := time.Now().Add(2 * time.Hour)
+	_, err := db.ExecContext(ctx, `
+		WITH g AS (
+			INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+			                            grant_start_date, grant_end_date, total_award_amount)
+			VALUES ('NSF-ENDING', 'NSF', 'Dr. Smith', 'University', $1, $2, 3000.00)
+			RETURNING id
+		)
+		UPDATE budget_accounts SET grant_id = (SELECT id FROM g), is_grant_funded = TRUE
+		WHERE slurm_account = 'end-lab'`, grantEnd.AddDate(-1, 0, 0), grantEnd)
+	require.NoError(t, err)
+
+	check := func(service *budget.Service, wallTime string) (*api.BudgetCheckResponse, error) {
+		return service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account:   "end-student",
+			Partition: "cpu",
+			Nodes:     1,
+			CPUs:      1,
+			WallTime:  wallTime,
+		})
+	}
+
+	t.Run("job ending before the grant passes", func(t *testing.T) {
+		resp, err := check(blocking, "01:00:00")
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+		assert.Empty(t, resp.Warning)
+	})
+
+	t.Run("job running past the grant is warned", func(t *testing.T) {
+		resp, err := check(service, "04:00:00")
+		require.NoError(t, err)
+		assert.True(t, resp.Available)
+		assert.Contains(t, resp.Warning, "would run past the end of grant NSF-ENDING")
+	})
+
+	t.Run("job running past the grant is blocked", func(t *testing.T) {
+		_, err := check(blocking, "04:00:00")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		assert.Equal(t, api.ErrCodeGrantEnded, budgetErr.Code)
+	})
+
+	t.Run("multi-day job running past the grant is blocked", func(t *testing.T) {
+		_, err := check(blocking, "2-00:00:00")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		assert.Equal(t, api.ErrCodeGrantEnded, budgetErr.Code)
+	})
+
+	t.Run("hold attempted after the grant ended is blocked", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, `
+			UPDATE grant_accounts SET grant_end_date = NOW() - INTERVAL '1 day' WHERE grant_number = 'NSF-ENDING'`)
+		require.NoError(t, err)
+		defer func() {
+			_, err := db.ExecContext(ctx, `UPDATE grant_accounts SET grant_end_date = $1 WHERE grant_number = 'NSF-ENDING'`, grantEnd)
+			require.NoError(t, err)
+		}()
+
+		_, err = check(blocking, "00:10:00")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		assert.Equal(t, api.ErrCodeGrantEnded, budgetErr.Code)
+	})
+
+	afterGrantEnd := func(jobID string) []string {
+		var grants []string
+		rows, err := db.QueryContext(ctx, `
+			SELECT jsonb_array_elements_text(metadata->'after_grant_end')
+			FROM budget_transactions WHERE job_id = $1 AND type = 'charge'`, jobID)
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+		for rows.Next() {
+			var grant string
+			require.NoError(t, rows.Scan(&grant))
+			grants = append(grants, grant)
+		}
+		require.NoError(t, rows.Err())
+		return grants
+	}
+
+	reconcile := func(jobID string, completedAt time.Time) *api.JobReconcileResponse {
+		hold, err := check(service, "01:00:00")
+		require.NoError(t, err)
+		require.True(t, hold.Available)
+		resp, err := blocking.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:         jobID,
+			ActualCost:    5,
+			TransactionID: hold.TransactionID,
+			CompletedAt:   &completedAt,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("job completed before the grant ended is not flagged", func(t *testing.T) {
+		resp := reconcile("end-1", time.Now())
+		assert.Empty(t, resp.Warning)
+		assert.Empty(t, afterGrantEnd("end-1"))
+	})
+
+	t.Run("job completed after the grant ended is charged and flagged", func(t *testing.T) {
+		resp := reconcile("end-2", grantEnd.Add(time.Hour))
+		assert.True(t, resp.Success)
+		assert.InDelta(t, 5.0, resp.ActualCharge, 0.001, "the job's costs were incurred, so it is still charged")
+		assert.Contains(t, resp.Warning, "Job completed after grant NSF-ENDING ended")
+		assert.Equal(t, []string{"NSF-ENDING"}, afterGrantEnd("end-2"))
+	})
+}
@@ -0,0 +1,242 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestGrant_PeriodCommittedAmount(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "grant-lab", Name: "Lab"},
+		{SlurmAccount: "grant-student", Name: "Student", ParentAccount: "grant-lab"},
+		{SlurmAccount: "grant-other", Name: "Unfunded"},
+	} {
+		req.BudgetLimit = 500.0
+		req.StartDate = time.Now().Add(-30 * 24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	grantStart := time.Now().Add(-30 * 24 * time.Hour)
+	var grantID, periodID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+		INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+		                            grant_start_date, grant_end_date, total_award_amount, budget_period_months)
+		VALUES ('NSF-COMMIT', 'NSF', 'Dr. Smith', 'University', $1, $2, 3000.00, 12)
+		RETURNING id`, grantStart, grantStart.AddDate(3, 0, 0)).Scan(&grantID))
+	require.NoError(t, db.QueryRowContext(ctx, `
+		INSERT INTO grant_budget_periods (grant_id, period_number, period_start_date, period_end_date, period_budget_amount)
+		VALUES ($1, 1, $2, $3, 1000.00)
+		RETURNING id`, grantID, grantStart, grantStart.AddDate(1, 0, 0)).Scan(&periodID))
+
+	// The lab is funded by the grant period; the student account is tied to the same period
+	// and rolls up into the lab, so its holds must only be counted once
+	_, err := db.ExecContext(ctx, `
+		UPDATE budget_accounts SET grant_id = $1, grant_budget_period_id = $2, is_grant_funded = TRUE
+		WHERE slurm_account = 'grant-lab'`, grantID, periodID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE budget_accounts SET grant_budget_period_id = $1 WHERE slurm_account = 'grant-student'`, periodID)
+	require.NoError(t, err)
+
+	committed := func() float64 {
+		var amount float64
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT period_committed_amount FROM grant_budget_periods WHERE id = $1`, periodID).Scan(&amount))
+		return amount
+	}
+	hold := func(account string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: account, Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+
+	assert.InDelta(t, 0.0, committed(), 0.001)
+
+	first := hold("grant-student")
+	hold("grant-student")
+	assert.InDelta(t, 24.0, committed(), 0.001, "two $12 holds are committed once despite the shared period")
+
+	hold("grant-other")
+	assert.InDelta(t, 24.0, committed(), 0.001, "holds on accounts outside the period are not committed to it")
+
+	_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID: "commit-1", ActualCost: 8.0, TransactionID: first.TransactionID,
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, 12.0, committed(), 0.001, "reconciling releases the hold from committed")
+
+	t.Run("summary splits spent, committed and remaining", func(t *testing.T) {
+		summary, err := service.GetGrantPeriodSummary(ctx, "NSF-COMMIT", nil)
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.PeriodNumber)
+		assert.InDelta(t, 1000.0, summary.Budget, 0.001)
+		assert.InDelta(t, 8.0, summary.Spent, 0.001)
+		assert.InDelta(t, 12.0, summary.Committed, 0.001)
+		assert.InDelta(t, 980.0, summary.Remaining, 0.001)
+	})
+
+	t.Run("moving an account out of the period releases its commitment", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, `UPDATE budget_accounts SET grant_budget_period_id = NULL WHERE slurm_account = 'grant-lab'`)
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, committed(), 0.001, "the student account is still tied to the period")
+
+		_, err = db.ExecContext(ctx, `UPDATE budget_accounts SET grant_budget_period_id = NULL WHERE slurm_account = 'grant-student'`)
+		require.NoError(t, err)
+		assert.InDelta(t, 0.0, committed(), 0.001)
+	})
+
+	t.Run("missing period", func(t *testing.T) {
+		period := 2
+		_, err := service.GetGrantPeriodSummary(ctx, "NSF-COMMIT", &period)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+}
+
+func TestGrant_PeriodSpentFollowsHoldAcrossRollover(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "rollover-lab",
+		Name:         "Lab",
+		BudgetLimit:  500.0,
+		StartDate:    time.Now().Add(-365 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Period 1 of the grant ends in a month
+	grantStart := time.Now().AddDate(0, -11, 0)
+	var grantID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+		INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+		                            grant_start_date, grant_end_date, total_award_amount, budget_period_months)
+		VALUES ('NSF-ROLLOVER', 'NSF', 'Dr. Smith', 'University', $1, $2, 2000.00, 12)
+		RETURNING id`, grantStart, grantStart.AddDate(2, 0, 0)).Scan(&grantID))
+	periodIDs := make(map[int]int64)
+	for number := 1; number <= 2; number++ {
+		var id int64
+		require.NoError(t, db.QueryRowContext(ctx, `
+			INSERT INTO grant_budget_periods (grant_id, period_number, period_start_date, period_end_date, period_budget_amount)
+			VALUES ($1, $2, $3, $4, 1000.00)
+			RETURNING id`, grantID, number, grantStart.AddDate(number-1, 0, 0), grantStart.AddDate(number, 0, 0)).Scan(&id))
+		periodIDs[number] = id
+	}
+	_, err = db.ExecContext(ctx, `
+		UPDATE budget_accounts SET grant_id = $1, is_grant_funded = TRUE WHERE slurm_account = 'rollover-lab'`, grantID)
+	require.NoError(t, err)
+
+	hold := func() *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "rollover-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+	periodOf := func(transactionID string) int64 {
+		var id int64
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT grant_budget_period_id FROM budget_transactions WHERE transaction_id = $1`, transactionID).Scan(&id))
+		return id
+	}
+	spent := func(number int) float64 {
+		var amount float64
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT period_spent_amount FROM grant_budget_periods WHERE id = $1`, periodIDs[number]).Scan(&amount))
+		return amount
+	}
+	reconcile := func(jobID string, transactionID string, cost float64) {
+		_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: jobID, ActualCost: cost, TransactionID: transactionID,
+		})
+		require.NoError(t, err)
+	}
+
+	overrun := hold()
+	under := hold()
+	assert.Equal(t, periodIDs[1], periodOf(overrun.TransactionID))
+
+	// Two months pass: period 1 has ended and period 2 is in force
+	_, err = db.ExecContext(ctx, `
+		UPDATE grant_accounts
+		SET grant_start_date = grant_start_date - INTERVAL '2 months', grant_end_date = grant_end_date - INTERVAL '2 months'
+		WHERE id = $1`, grantID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		UPDATE grant_budget_periods
+		SET period_start_date = period_start_date - INTERVAL '2 months', period_end_date = period_end_date - INTERVAL '2 months'
+		WHERE grant_id = $1`, grantID)
+	require.NoError(t, err)
+
+	late := hold()
+	assert.Equal(t, periodIDs[2], periodOf(late.TransactionID))
+
+	// The $12 holds are reconciled above and below what they held
+	reconcile("rollover-1", overrun.TransactionID, 15.0)
+	reconcile("rollover-2", under.TransactionID, 8.0)
+	reconcile("rollover-3", late.TransactionID, 5.0)
+
+	assert.InDelta(t, 23.0, spent(1), 0.001, "jobs held in period 1 are charged to it, overrun included and refunds not")
+	assert.InDelta(t, 5.0, spent(2), 0.001, "period 2 is charged only for the job held in it")
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT t.type, t.grant_budget_period_id
+		FROM budget_transactions t
+		WHERE t.job_id IN ('rollover-1', 'rollover-2') AND t.type IN ('charge', 'refund')`)
+	require.NoError(t, err)
+	defer func() { _ = rows.Close() }()
+	var settled int
+	for rows.Next() {
+		var kind string
+		var periodID int64
+		require.NoError(t, rows.Scan(&kind, &periodID))
+		assert.Equal(t, periodIDs[1], periodID, "%s of a period 1 hold", kind)
+		settled++
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, 4, settled, "two charges for the overrun job, a charge and a refund for the other")
+
+	for number, want := range map[int]float64{1: 23.0, 2: 5.0} {
+		number := number
+		summary, err := service.GetGrantPeriodSummary(ctx, "NSF-ROLLOVER", &number)
+		require.NoError(t, err)
+		assert.InDelta(t, want, summary.Spent, 0.001, "period %d", number)
+		assert.InDelta(t, 1000.0-want, summary.Remaining, 0.001, "period %d", number)
+	}
+}
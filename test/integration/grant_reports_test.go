@@ -0,0 +1,123 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestGrant_DueReportDeadlineGeneratesReport(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.GrantReportLeadTime = 7 * 24 * time.Hour
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "report-lab",
+		Name:         "Report Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-30 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	grantStart := time.Now().Add(-30 * 24 * time.Hour)
+	var grantID int64
+	require.NoError(t, db.QueryRowContext(ctx, `
+		INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+		                            grant_start_date, grant_end_date, total_award_amount, budget_period_months)
+		VALUES ('NSF-REPORTS', 'NSF', 'Dr. Smith', 'University', $1, $2, 3000.00, 12)
+		RETURNING id`, grantStart, grantStart.AddDate(3, 0, 0)).Scan(&grantID))
+	_, err = db.ExecContext(ctx, `
+		UPDATE budget_accounts SET grant_id = $1, is_grant_funded = TRUE
+		WHERE slurm_account = 'report-lab'`, grantID)
+	require.NoError(t, err)
+
+	_, err = service.AdjustBudget(ctx, "report-lab", &api.BudgetAdjustmentRequest{Amount: 120.0, Description: "Storage charge"})
+	require.NoError(t, err)
+
+	addDeadline := func(deadlineType string, due time.Time) *api.GrantDeadline {
+		deadline, err := service.CreateGrantDeadline(ctx, "NSF-REPORTS", &api.CreateGrantDeadlineRequest{
+			Type: deadlineType, DueDate: due,
+		})
+		require.NoError(t, err)
+		return deadline
+	}
+	due := addDeadline(api.DeadlineTypeGrantReport, time.Now().Add(3*24*time.Hour))
+	addDeadline(api.DeadlineTypeGrantReport, time.Now().Add(60*24*time.Hour))
+	addDeadline(api.DeadlineTypeConference, time.Now().Add(24*time.Hour))
+
+	generated, err := service.GenerateDueGrantReports(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, generated, "only the report deadline within the lead time is due")
+
+	deadlines, err := service.ListGrantDeadlines(ctx, "NSF-REPORTS")
+	require.NoError(t, err)
+	require.Len(t, deadlines, 3)
+	var reportID int64
+	for _, deadline := range deadlines {
+		if deadline.ID == due.ID {
+			require.NotNil(t, deadline.ReportID)
+			reportID = *deadline.ReportID
+		} else {
+			assert.Nil(t, deadline.ReportID)
+		}
+	}
+
+	t.Run("report is stored for download", func(t *testing.T) {
+		stored, err := service.GetGrantReport(ctx, "NSF-REPORTS", reportID)
+		require.NoError(t, err)
+		assert.Equal(t, "financial", stored.ReportType)
+		require.NotNil(t, stored.DeadlineID)
+		assert.Equal(t, due.ID, *stored.DeadlineID)
+
+		var report api.GrantReportResponse
+		require.NoError(t, json.Unmarshal(stored.Report, &report))
+		assert.Equal(t, "NSF-REPORTS", report.GrantNumber)
+		assert.Equal(t, 1, report.BudgetPeriod)
+		assert.InDelta(t, 120.0, report.TotalSpent, 0.001)
+	})
+
+	t.Run("grant accounts are notified with the link", func(t *testing.T) {
+		var count int
+		var message string
+		require.NoError(t, db.QueryRowContext(ctx, `
+			SELECT COUNT(*), MAX(message) FROM budget_alerts
+			WHERE alert_type = 'grant_report_ready' AND grant_id = $1`, grantID).Scan(&count, &message))
+		assert.Equal(t, 1, count)
+		assert.Contains(t, message, "Dr. Smith")
+		assert.Contains(t, message, "/api/v1/grants/NSF-REPORTS/reports/")
+	})
+
+	t.Run("each deadline's report is generated once", func(t *testing.T) {
+		generated, err := service.GenerateDueGrantReports(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Zero(t, generated)
+	})
+
+	t.Run("unknown report", func(t *testing.T) {
+		_, err := service.GetGrantReport(ctx, "NSF-REPORTS", reportID+100)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+}
@@ -13,8 +13,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/config"
 	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
 )
 
 const (
@@ -131,6 +133,7 @@ func SetupTestConfig() *config.Config {
 			AutoRecoveryEnabled:   true,
 			RecoveryCheckInterval: 1 * time.Hour,
 			TransactionRetention:  2160 * time.Hour,
+			ChargeFailedJobs:      true,
 		},
 		Advisor: config.AdvisorConfig{
 			URL:           "http://localhost:8081",
@@ -140,3 +143,28 @@ func SetupTestConfig() *config.Config {
 		},
 	}
 }
+
+// float64Ptr returns a pointer to f, for the optional costs in ASBX job data
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+// placeAgedHold places a job's hold through a budget check and backdates it by age, as a
+// hold left behind by a job that never reconciled, returning its transaction ID
+func placeAgedHold(t *testing.T, db *database.DB, service *budget.Service, req *api.BudgetCheckRequest, age time.Duration) string {
+	t.Helper()
+	ctx := context.Background()
+
+	resp, err := service.CheckBudget(ctx, req)
+	if err != nil {
+		t.Fatalf("Failed to place hold: %v", err)
+	}
+	if !resp.Available || resp.TransactionID == "" {
+		t.Fatalf("Hold was not placed: %s", resp.Message)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE budget_transactions SET created_at = $1 WHERE transaction_id = $2",
+		time.Now().Add(-age), resp.TransactionID); err != nil {
+		t.Fatalf("Failed to backdate hold: %v", err)
+	}
+	return resp.TransactionID
+}
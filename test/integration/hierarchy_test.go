@@ -0,0 +1,191 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// createHierarchyAccount creates an account, optionally under a parent
+func createHierarchyAccount(t *testing.T, service *budget.Service, slurmAccount, parent string, limit float64) {
+	t.Helper()
+	_, err := service.CreateAccount(context.Background(), &api.CreateAccountRequest{
+		SlurmAccount:  slurmAccount,
+		Name:          slurmAccount,
+		BudgetLimit:   limit,
+		ParentAccount: parent,
+		StartDate:     time.Now().Add(-24 * time.Hour),
+		EndDate:       time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+}
+
+// checkHierarchyBudget submits a budget check; the mock advisor makes every hold $12
+func checkHierarchyBudget(t *testing.T, service *budget.Service, slurmAccount string) *api.BudgetCheckResponse {
+	t.Helper()
+	resp, err := service.CheckBudget(context.Background(), &api.BudgetCheckRequest{
+		Account:   slurmAccount,
+		Partition: "cpu",
+		Nodes:     1,
+		CPUs:      4,
+		WallTime:  "01:00:00",
+	})
+	require.NoError(t, err)
+	return resp
+}
+
+func TestHierarchy_ChildExhaustsParentPool(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	createHierarchyAccount(t, service, "dept", "", 30.0)
+	createHierarchyAccount(t, service, "dept-proj", "dept", 100.0)
+
+	first := checkHierarchyBudget(t, service, "dept-proj")
+	require.True(t, first.Available)
+	require.True(t, checkHierarchyBudget(t, service, "dept-proj").Available)
+
+	// The child has $76 of its own left, but the parent pool only has $6
+	rejected := checkHierarchyBudget(t, service, "dept-proj")
+	assert.False(t, rejected.Available)
+	assert.Contains(t, rejected.Message, "dept")
+	assert.InDelta(t, 6.0, rejected.BudgetRemaining, 0.001)
+
+	parent, err := service.GetAccount(ctx, "dept")
+	require.NoError(t, err)
+	assert.InDelta(t, 24.0, parent.BudgetHeld, 0.001)
+
+	// Reconciling releases the hold from the child and the parent alike
+	_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID:         "1001",
+		TransactionID: first.TransactionID,
+		ActualCost:    5.0,
+	})
+	require.NoError(t, err)
+
+	for _, name := range []string{"dept", "dept-proj"} {
+		account, err := service.GetAccount(ctx, name)
+		require.NoError(t, err)
+		assert.InDelta(t, 5.0, account.BudgetUsed, 0.001, name)
+		assert.InDelta(t, 12.0, account.BudgetHeld, 0.001, name)
+	}
+
+	assert.True(t, checkHierarchyBudget(t, service, "dept-proj").Available)
+}
+
+func TestHierarchy_IndependentChildrenShareParent(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	createHierarchyAccount(t, service, "dept", "", 30.0)
+	createHierarchyAccount(t, service, "proj-a", "dept", 100.0)
+	createHierarchyAccount(t, service, "proj-b", "dept", 100.0)
+
+	require.True(t, checkHierarchyBudget(t, service, "proj-a").Available)
+	require.True(t, checkHierarchyBudget(t, service, "proj-b").Available)
+
+	// Each child's own balance only reflects its own job
+	for _, name := range []string{"proj-a", "proj-b"} {
+		account, err := service.GetAccount(ctx, name)
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, account.BudgetHeld, 0.001, name)
+	}
+
+	// Together they have used up the shared pool
+	assert.False(t, checkHierarchyBudget(t, service, "proj-a").Available)
+	assert.False(t, checkHierarchyBudget(t, service, "proj-b").Available)
+
+	// Detaching a child gives its balances back to the parent pool
+	detach := ""
+	_, err := service.UpdateAccount(ctx, "proj-b", &api.UpdateAccountRequest{ParentAccount: &detach})
+	require.NoError(t, err)
+
+	parent, err := service.GetAccount(ctx, "dept")
+	require.NoError(t, err)
+	assert.InDelta(t, 12.0, parent.BudgetHeld, 0.001)
+	assert.True(t, checkHierarchyBudget(t, service, "proj-a").Available)
+}
+
+func TestHierarchy_RejectsCycles(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	createHierarchyAccount(t, service, "college", "", 1000.0)
+	createHierarchyAccount(t, service, "dept", "college", 500.0)
+	createHierarchyAccount(t, service, "proj", "dept", 100.0)
+
+	for _, parent := range []string{"proj", "college"} {
+		parent := parent
+		_, err := service.UpdateAccount(ctx, "college", &api.UpdateAccountRequest{ParentAccount: &parent})
+		require.Error(t, err, parent)
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	}
+
+	// The database refuses a cycle even if the service check is bypassed
+	_, err := db.ExecContext(ctx,
+		`SELECT set_account_parent((SELECT id FROM budget_accounts WHERE slurm_account = 'college'),
+		                           (SELECT id FROM budget_accounts WHERE slurm_account = 'proj'))`)
+	assert.Error(t, err)
+}
+
+func TestHierarchy_AdminSummaryCountsPoolsOnce(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	createHierarchyAccount(t, service, "summary-dept", "", 100.0)
+	createHierarchyAccount(t, service, "summary-proj", "summary-dept", 60.0)
+	createHierarchyAccount(t, service, "summary-solo", "", 40.0)
+
+	require.True(t, checkHierarchyBudget(t, service, "summary-proj").Available)
+	require.True(t, checkHierarchyBudget(t, service, "summary-solo").Available)
+
+	summary, err := service.AdminSummary(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, summary.Accounts)
+	assert.Equal(t, 3, summary.ActiveAccounts)
+	assert.Equal(t, 2, summary.PendingHolds)
+
+	// The child's hold is already in its parent's balance, so it is counted once
+	assert.InDelta(t, 140.0, summary.TotalLimit, 0.001)
+	assert.InDelta(t, 24.0, summary.TotalHeld, 0.001)
+	assert.InDelta(t, 116.0, summary.TotalAvailable, 0.001)
+	assert.Positive(t, summary.Database.OpenConnections)
+}
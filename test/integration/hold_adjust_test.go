@@ -0,0 +1,98 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestHoldAdjust_IncreaseAndDecrease(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	createHierarchyAccount(t, service, "adj-dept", "", 500.0)
+	createHierarchyAccount(t, service, "adj-proj", "adj-dept", 100.0)
+
+	check := checkHierarchyBudget(t, service, "adj-proj")
+	require.True(t, check.Available)
+
+	assertHeld := func(expected float64) {
+		t.Helper()
+		for _, name := range []string{"adj-dept", "adj-proj"} {
+			account, err := service.GetAccount(ctx, name)
+			require.NoError(t, err)
+			assert.InDelta(t, expected, account.BudgetHeld, 0.001, name)
+		}
+	}
+
+	t.Run("increase", func(t *testing.T) {
+		resp, err := service.AdjustHold(ctx, check.TransactionID, &api.HoldAdjustRequest{
+			Amount:     20,
+			Reason:     "estimate missed the GPU nodes",
+			AdjustedBy: "ops",
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, resp.Adjustment.PreviousAmount, 0.001)
+		assert.InDelta(t, 20.0, resp.Adjustment.NewAmount, 0.001)
+		assert.Equal(t, "adj-proj", resp.Adjustment.Account)
+		assert.InDelta(t, 20.0, resp.Account.BudgetHeld, 0.001)
+		assertHeld(20.0)
+
+		hold, err := service.GetTransaction(ctx, check.TransactionID)
+		require.NoError(t, err)
+		assert.InDelta(t, 20.0, hold.Amount, 0.001)
+	})
+
+	t.Run("decrease", func(t *testing.T) {
+		resp, err := service.AdjustHold(ctx, check.TransactionID, &api.HoldAdjustRequest{Amount: 5, Reason: "job shortened"})
+		require.NoError(t, err)
+		assert.InDelta(t, 20.0, resp.Adjustment.PreviousAmount, 0.001)
+		assertHeld(5.0)
+
+		var audits int
+		require.NoError(t, db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM budget_hold_adjustments WHERE transaction_id = $1", check.TransactionID).Scan(&audits))
+		assert.Equal(t, 2, audits)
+	})
+
+	t.Run("reconciled hold is refused", func(t *testing.T) {
+		_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:         "2001",
+			TransactionID: check.TransactionID,
+			ActualCost:    4.0,
+		})
+		require.NoError(t, err)
+		assertHeld(0.0)
+
+		_, err = service.AdjustHold(ctx, check.TransactionID, &api.HoldAdjustRequest{Amount: 30, Reason: "too late"})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeHoldSettled, budgetErr.Code)
+		assertHeld(0.0)
+	})
+
+	t.Run("unknown hold is not found", func(t *testing.T) {
+		_, err := service.AdjustHold(ctx, "txn_missing", &api.HoldAdjustRequest{Amount: 30, Reason: "typo"})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+}
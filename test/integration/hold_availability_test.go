@@ -0,0 +1,135 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_HoldAvailabilityBurst(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+
+	// A job array submits 20 jobs at once; each holds $12 against a $100 budget
+	burst := func(t *testing.T, service *budget.Service, account string) (approved int, last *api.BudgetCheckResponse) {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         "Burst Account",
+			BudgetLimit:  100.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+
+		for i := 0; i < 20; i++ {
+			resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+				Account: account, Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+			})
+			require.NoError(t, err)
+			if resp.Available {
+				approved++
+				last = resp
+			}
+		}
+		return approved, last
+	}
+
+	t.Run("strict counts every hold", func(t *testing.T) {
+		cfg := SetupTestConfig()
+		cfg.Budget.HoldAvailability = "STRICT"
+		service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+		approved, last := burst(t, service, "burst-strict")
+		assert.Equal(t, 8, approved)
+		assert.Zero(t, last.HoldGraceCredit)
+	})
+
+	t.Run("grace discounts recent holds", func(t *testing.T) {
+		cfg := SetupTestConfig()
+		cfg.Budget.HoldAvailability = "GRACE"
+		cfg.Budget.HoldGraceWindow = time.Hour
+		cfg.Budget.HoldGraceDiscount = 0.5
+		service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+		approved, last := burst(t, service, "burst-grace")
+		assert.Equal(t, 15, approved)
+		assert.InDelta(t, 84.0, last.HoldGraceCredit, 0.001)
+
+		account, err := service.GetAccount(ctx, "burst-grace")
+		require.NoError(t, err)
+		assert.InDelta(t, 180.0, account.BudgetHeld, 0.001)
+
+		// Once the burst's holds are past the window they count in full again
+		_, err = db.ExecContext(ctx, "UPDATE budget_transactions SET created_at = created_at - INTERVAL '2 hours' WHERE account_id = $1", account.ID)
+		require.NoError(t, err)
+
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "burst-grace", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		assert.False(t, resp.Available)
+		assert.InDelta(t, -80.0, resp.BudgetRemaining, 0.001)
+	})
+}
+
+func TestBudget_ConcurrentChecksCannotOverspend(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "burst-concurrent",
+		Name:         "Concurrent Burst Account",
+		BudgetLimit:  100.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// All 20 checks read the same empty balance, but each approval is decided again on
+	// the locked row, so only 8 of the $12 holds are placed
+	var wg sync.WaitGroup
+	var approved int64
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+				Account: "burst-concurrent", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+			})
+			if assert.NoError(t, err) && resp.Available {
+				atomic.AddInt64(&approved, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(8), approved)
+	account, err := service.GetAccount(ctx, "burst-concurrent")
+	require.NoError(t, err)
+	assert.InDelta(t, 96.0, account.BudgetHeld, 0.001)
+}
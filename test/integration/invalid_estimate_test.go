@@ -0,0 +1,79 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_NonPositiveAdvisorEstimateStillHolds(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	_, err := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget).CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "invalid-estimate",
+		Name:         "Invalid Estimate",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	for _, cost := range []float64{0, -25} {
+		// A broken advisor that answers promptly with a cost no job can have
+		broken := &advisor.MockClient{
+			EstimateFunc: func(ctx context.Context, req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
+				return &budget.CostEstimateResponse{EstimatedCost: cost, Confidence: 0.9}, nil
+			},
+		}
+		check := func(service *budget.Service) *api.BudgetCheckResponse {
+			resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+				Account: "invalid-estimate", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "02:00:00",
+			})
+			require.NoError(t, err)
+			require.True(t, resp.Available)
+			return resp
+		}
+
+		t.Run(fmt.Sprintf("advisor estimate %.0f falls back to the heuristic", cost), func(t *testing.T) {
+			service := budget.NewService(db, broken, &cfg.Budget)
+			service.SetAdvisorDivergence(0, "")
+
+			resp := check(service)
+			assert.Greater(t, resp.EstimatedCost, 0.0)
+			assert.Greater(t, resp.HoldAmount, 0.0)
+			assert.InDelta(t, resp.EstimatedCost*resp.Details.HoldPercentage, resp.HoldAmount, 0.001)
+			assert.NotEmpty(t, resp.Warning)
+		})
+
+		t.Run(fmt.Sprintf("advisor estimate %.0f is raised to the minimum", cost), func(t *testing.T) {
+			service := budget.NewService(db, broken, &cfg.Budget)
+			service.SetFailureMode("STRICT")
+			service.SetInvalidEstimatePolicy("MINIMUM", 5.0)
+
+			resp := check(service)
+			assert.Equal(t, 5.0, resp.EstimatedCost)
+			assert.InDelta(t, 5.0*resp.Details.HoldPercentage, resp.HoldAmount, 0.001)
+			assert.NotEmpty(t, resp.Warning)
+		})
+	}
+}
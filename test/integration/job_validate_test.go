@@ -0,0 +1,90 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// validateJobScript names no account, so a test can give one as a directive or not at all
+const validateJobScript = `#SBATCH --partition=cpu
+#SBATCH --nodes=1 --cpus-per-task=4
+#SBATCH --time=01:00:00
+
+srun ./simulate
+`
+
+func TestValidateJob_AgainstAccountBudget(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	// The mock advisor estimates $10, so every hold is $12
+	createHierarchyAccount(t, service, "validate-rich", "", 100)
+	createHierarchyAccount(t, service, "validate-poor", "", 5)
+
+	t.Run("valid script", func(t *testing.T) {
+		resp, err := service.ValidateJob(ctx, &api.JobValidateRequest{
+			Script: "#!/bin/bash\n#SBATCH --account=validate-rich\n" + validateJobScript,
+		})
+		require.NoError(t, err)
+		assert.True(t, resp.Valid, "issues: %v", resp.Issues)
+		assert.True(t, resp.Affordable)
+		assert.Equal(t, "validate-rich", resp.Resources.Account)
+		assert.Equal(t, 10.0, resp.EstimatedCost)
+		assert.InDelta(t, 12.0, resp.HoldAmount, 0.001)
+		require.NotNil(t, resp.BudgetRemaining)
+		assert.InDelta(t, 100.0, *resp.BudgetRemaining, 0.001)
+		assert.Empty(t, resp.Issues)
+	})
+
+	t.Run("missing account", func(t *testing.T) {
+		resp, err := service.ValidateJob(ctx, &api.JobValidateRequest{Script: validateJobScript})
+		require.NoError(t, err)
+		assert.False(t, resp.Valid)
+		assert.Equal(t, 10.0, resp.EstimatedCost)
+		require.Len(t, resp.Issues, 1)
+		assert.Equal(t, "account", resp.Issues[0].Field)
+	})
+
+	t.Run("over budget", func(t *testing.T) {
+		resp, err := service.ValidateJob(ctx, &api.JobValidateRequest{Script: validateJobScript, Account: "validate-poor"})
+		require.NoError(t, err)
+		assert.False(t, resp.Valid)
+		assert.False(t, resp.Affordable)
+		require.Len(t, resp.Issues, 1)
+		assert.Equal(t, api.ValidationSeverityError, resp.Issues[0].Severity)
+		assert.Contains(t, resp.Issues[0].Message, "Insufficient budget")
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		resp, err := service.ValidateJob(ctx, &api.JobValidateRequest{Script: validateJobScript, Account: "no-such-account"})
+		require.NoError(t, err)
+		assert.False(t, resp.Valid)
+		assert.Nil(t, resp.BudgetRemaining)
+		require.Len(t, resp.Issues, 1)
+		assert.Equal(t, "account", resp.Issues[0].Field)
+	})
+
+	// Nothing was held
+	account, err := service.GetAccount(ctx, "validate-rich")
+	require.NoError(t, err)
+	assert.Zero(t, account.BudgetHeld)
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_MaxSingleJobCost(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+
+	// The mock advisor estimates $10 for every job
+	capCfg := cfg.Budget
+	capCfg.MaxSingleJobCost = 10.01
+	capCfg.PartitionMaxSingleJobCost = map[string]float64{"gpu": 9.99}
+	service := budget.NewService(db, &advisor.MockClient{}, &capCfg)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "capped",
+		Name:         "capped",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check := func(partition string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "capped", Partition: partition, Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("job just under the cap is held", func(t *testing.T) {
+		resp := check("cpu")
+		assert.True(t, resp.Available)
+		assert.NotEmpty(t, resp.TransactionID)
+	})
+
+	t.Run("job just over the partition cap is rejected", func(t *testing.T) {
+		resp := check("gpu")
+		assert.False(t, resp.Available)
+		assert.Empty(t, resp.TransactionID)
+		assert.Contains(t, resp.Message, "maximum single-job cost")
+		assert.Contains(t, resp.Message, "verify")
+
+		account, err := service.GetAccount(ctx, "capped")
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, account.BudgetHeld, 0.001, "only the accepted job holds budget")
+
+		decisions, err := service.ListDecisions(ctx, &api.DecisionListRequest{Account: "capped", Decision: api.DecisionRejected})
+		require.NoError(t, err)
+		require.Len(t, decisions, 1)
+		assert.Equal(t, "gpu", decisions[0].Partition)
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_UserSeesEveryMemberAccount(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.AlertWarningThreshold = 80.0
+	cfg.Budget.AlertCriticalThreshold = 95.0
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "member-genomics", Name: "Genomics Lab", BudgetLimit: 1000.0},
+		{SlurmAccount: "member-imaging", Name: "Imaging Lab", BudgetLimit: 200.0},
+		{SlurmAccount: "member-other", Name: "Other Lab", BudgetLimit: 500.0},
+	} {
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	for account, users := range map[string][]string{
+		"member-genomics": {"alice"},
+		"member-imaging":  {"alice", "bob"},
+		"member-other":    {"bob"},
+	} {
+		for _, user := range users {
+			_, err := service.AddAccountMember(ctx, account, user)
+			require.NoError(t, err)
+		}
+	}
+
+	// The imaging lab spends most of its budget
+	_, err := service.AdjustBudget(ctx, "member-imaging", &api.BudgetAdjustmentRequest{
+		Amount: 170.0, Description: "Storage charge",
+	})
+	require.NoError(t, err)
+
+	t.Run("user in two accounts sees both balances", func(t *testing.T) {
+		resp, err := service.ListUserAccounts(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", resp.User)
+		require.Len(t, resp.Accounts, 2)
+
+		genomics, imaging := resp.Accounts[0], resp.Accounts[1]
+		assert.Equal(t, "member-genomics", genomics.SlurmAccount)
+		assert.InDelta(t, 1000.0, genomics.BudgetAvailable, 0.001)
+		assert.Equal(t, api.AccountHealthHealthy, genomics.Health)
+
+		assert.Equal(t, "member-imaging", imaging.SlurmAccount)
+		assert.InDelta(t, 170.0, imaging.BudgetUsed, 0.001)
+		assert.InDelta(t, 30.0, imaging.BudgetAvailable, 0.001)
+		assert.InDelta(t, 85.0, imaging.Utilization, 0.001)
+		assert.Equal(t, api.AccountHealthWarning, imaging.Health)
+	})
+
+	t.Run("adding a member again changes nothing", func(t *testing.T) {
+		first, err := service.AddAccountMember(ctx, "member-genomics", "alice")
+		require.NoError(t, err)
+		again, err := service.AddAccountMember(ctx, "member-genomics", "alice")
+		require.NoError(t, err)
+		assert.True(t, first.CreatedAt.Equal(again.CreatedAt))
+
+		members, err := service.ListAccountMembers(ctx, "member-genomics")
+		require.NoError(t, err)
+		assert.Len(t, members, 1)
+	})
+
+	t.Run("frozen account is unavailable", func(t *testing.T) {
+		frozen := true
+		_, err := service.UpdateAccount(ctx, "member-other", &api.UpdateAccountRequest{Frozen: &frozen})
+		require.NoError(t, err)
+
+		resp, err := service.ListUserAccounts(ctx, "bob")
+		require.NoError(t, err)
+		require.Len(t, resp.Accounts, 2)
+		assert.Equal(t, "member-other", resp.Accounts[1].SlurmAccount)
+		assert.Equal(t, api.AccountHealthUnavailable, resp.Accounts[1].Health)
+	})
+
+	t.Run("removed member no longer sees the account", func(t *testing.T) {
+		require.NoError(t, service.RemoveAccountMember(ctx, "member-imaging", "alice"))
+
+		resp, err := service.ListUserAccounts(ctx, "alice")
+		require.NoError(t, err)
+		require.Len(t, resp.Accounts, 1)
+		assert.Equal(t, "member-genomics", resp.Accounts[0].SlurmAccount)
+
+		err = service.RemoveAccountMember(ctx, "member-imaging", "alice")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+
+	t.Run("unknown user has no accounts", func(t *testing.T) {
+		resp, err := service.ListUserAccounts(ctx, "carol")
+		require.NoError(t, err)
+		assert.Empty(t, resp.Accounts)
+	})
+}
+
+func TestBudget_OnlyMembersCheckAndReconcile(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "auth-lab", Name: "Lab", BudgetLimit: 1000.0},
+		{SlurmAccount: "auth-partner", Name: "Partner Lab", BudgetLimit: 1000.0},
+	} {
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+	_, err := service.AddAccountMember(ctx, "auth-lab", "alice")
+	require.NoError(t, err)
+
+	checkReq := &api.BudgetCheckRequest{
+		Account: "auth-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+	}
+	forbidden := func(t *testing.T, err error) {
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		assert.Equal(t, api.ErrCodeForbidden, budgetErr.Code)
+	}
+
+	t.Run("member may check, non-member may not", func(t *testing.T) {
+		assert.NoError(t, service.AuthorizeBudgetCheck(ctx, "alice", checkReq))
+		forbidden(t, service.AuthorizeBudgetCheck(ctx, "mallory", checkReq))
+	})
+
+	t.Run("every cost-sharing account needs membership", func(t *testing.T) {
+		shared := *checkReq
+		shared.CostShares = []api.CostShare{
+			{Account: "auth-lab", Percentage: 50},
+			{Account: "auth-partner", Percentage: 50},
+		}
+		forbidden(t, service.AuthorizeBudgetCheck(ctx, "alice", &shared))
+
+		_, err := service.AddAccountMember(ctx, "auth-partner", "alice")
+		require.NoError(t, err)
+		assert.NoError(t, service.AuthorizeBudgetCheck(ctx, "alice", &shared))
+	})
+
+	t.Run("reconcile follows the hold's account", func(t *testing.T) {
+		resp, err := service.CheckBudget(ctx, checkReq)
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+
+		reconcile := &api.JobReconcileRequest{JobID: "auth-1", ActualCost: 5.0, TransactionID: resp.TransactionID}
+		assert.NoError(t, service.AuthorizeReconcile(ctx, "alice", reconcile))
+		forbidden(t, service.AuthorizeReconcile(ctx, "mallory", reconcile))
+
+		started := &api.JobStartedRequest{JobID: "auth-1", TransactionID: resp.TransactionID}
+		assert.NoError(t, service.AuthorizeJobStart(ctx, "alice", started))
+		forbidden(t, service.AuthorizeJobStart(ctx, "mallory", started))
+	})
+}
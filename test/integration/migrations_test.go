@@ -0,0 +1,58 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_MigrationStatus(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+
+	t.Run("fully migrated", func(t *testing.T) {
+		status, err := db.MigrationStatus(ctx)
+		require.NoError(t, err)
+		assert.True(t, status.UpToDate)
+		assert.False(t, status.Dirty)
+		assert.Equal(t, status.LatestVersion, status.CurrentVersion)
+		assert.Empty(t, status.Pending)
+		require.NotEmpty(t, status.Applied)
+		assert.Equal(t, uint(1), status.Applied[0].Version)
+		assert.Equal(t, "initial_schema", status.Applied[0].Name)
+	})
+
+	t.Run("latest migration rolled back", func(t *testing.T) {
+		before, err := db.MigrationStatus(ctx)
+		require.NoError(t, err)
+		latest := before.Applied[len(before.Applied)-1]
+
+		require.NoError(t, db.MigrateDown())
+
+		status, err := db.MigrationStatus(ctx)
+		require.NoError(t, err)
+		assert.False(t, status.UpToDate)
+		assert.Equal(t, before.Applied[len(before.Applied)-2].Version, status.CurrentVersion)
+		assert.Equal(t, before.LatestVersion, status.LatestVersion)
+		assert.Len(t, status.Applied, len(before.Applied)-1)
+		assert.Equal(t, latest, status.Pending[0])
+
+		// Migrating again brings the schema back up to date
+		require.NoError(t, db.Migrate())
+		status, err = db.MigrationStatus(ctx)
+		require.NoError(t, err)
+		assert.True(t, status.UpToDate)
+	})
+}
@@ -0,0 +1,270 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+// recordingNotifier keeps every notification it is asked to send, refusing them instead
+// while it is set to fail
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []*api.Notification
+	fail error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification *api.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.fail != nil {
+		return n.fail
+	}
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// failWith has every notification refused with err until it is called with nil
+func (n *recordingNotifier) failWith(err error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.fail = err
+}
+
+// take returns the notifications sent since the last take
+func (n *recordingNotifier) take() []*api.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sent := n.sent
+	n.sent = nil
+	return sent
+}
+
+func TestNotifications_DigestCoalescesAllButCritical(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.AlertWarningThreshold = 80
+	cfg.Budget.AlertCriticalThreshold = 95
+	cfg.Budget.NotificationMode = "DIGEST"
+	cfg.Budget.NotificationDigestInterval = 24 * time.Hour
+	cfg.Budget.NotificationRecipients = map[string][]string{
+		"grants@example.edu": {"notify-dept"},
+		"pi@example.edu":     {"notify-lab1"},
+	}
+	service := budget.NewService(db, nil, &cfg.Budget)
+	notifier := &recordingNotifier{}
+	service.SetNotifier(notifier)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "notify-dept", Name: "Department", BudgetLimit: 10000.0},
+		{SlurmAccount: "notify-lab1", Name: "Lab 1", BudgetLimit: 100.0, ParentAccount: "notify-dept"},
+		{SlurmAccount: "notify-lab2", Name: "Lab 2", BudgetLimit: 100.0, ParentAccount: "notify-dept"},
+		{SlurmAccount: "notify-lab3", Name: "Lab 3", BudgetLimit: 100.0, ParentAccount: "notify-dept"},
+	} {
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	setUsed := func(account string, used float64) {
+		_, err := db.ExecContext(ctx, "UPDATE budget_accounts SET budget_used = $1 WHERE slurm_account = $2", used, account)
+		require.NoError(t, err)
+	}
+
+	// Two accounts cross the warning threshold, and a third goes straight to critical
+	setUsed("notify-lab1", 85)
+	setUsed("notify-lab2", 90)
+	setUsed("notify-lab3", 97)
+	require.NoError(t, service.EvaluateBudgetAlerts(ctx))
+
+	t.Run("a critical alert is queued and sent by the next send", func(t *testing.T) {
+		assert.Empty(t, notifier.take(), "raising an alert never waits on the webhook")
+
+		sentCount, err := service.SendQueuedNotifications(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sentCount)
+
+		sent := notifier.take()
+		require.Len(t, sent, 1)
+		assert.Equal(t, "grants@example.edu", sent[0].Recipient)
+		assert.False(t, sent[0].Digest)
+		require.Len(t, sent[0].Items, 1)
+		assert.Equal(t, "notify-lab3", sent[0].Items[0].Account)
+		assert.Equal(t, "critical", sent[0].Items[0].Severity)
+	})
+
+	t.Run("warnings coalesce into one digest per recipient", func(t *testing.T) {
+		queued, err := service.FlushNotificationDigests(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, queued)
+
+		sentCount, err := service.SendQueuedNotifications(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, sentCount)
+
+		digests := make(map[string]*api.Notification)
+		for _, notification := range notifier.take() {
+			assert.True(t, notification.Digest)
+			digests[notification.Recipient] = notification
+		}
+		require.Len(t, digests, 2)
+
+		var accounts []string
+		for _, item := range digests["grants@example.edu"].Items {
+			assert.Equal(t, "warning", item.Severity)
+			accounts = append(accounts, item.Account)
+		}
+		assert.ElementsMatch(t, []string{"notify-lab1", "notify-lab2"}, accounts,
+			"following the department covers its labs, and the critical alert is not repeated")
+
+		require.Len(t, digests["pi@example.edu"].Items, 1)
+		assert.Equal(t, "notify-lab1", digests["pi@example.edu"].Items[0].Account)
+	})
+
+	t.Run("a flushed digest is not sent again", func(t *testing.T) {
+		queued, err := service.FlushNotificationDigests(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, queued)
+
+		sentCount, err := service.SendQueuedNotifications(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, sentCount)
+		assert.Empty(t, notifier.take())
+	})
+
+	t.Run("escalating to critical is sent without waiting for the digest", func(t *testing.T) {
+		setUsed("notify-lab1", 96)
+		require.NoError(t, service.EvaluateBudgetAlerts(ctx))
+		assert.Empty(t, notifier.take())
+
+		sentCount, err := service.SendQueuedNotifications(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, sentCount)
+
+		sent := notifier.take()
+		require.Len(t, sent, 2, "both of the lab's followers hear at once")
+		for _, notification := range sent {
+			assert.False(t, notification.Digest)
+			assert.Equal(t, "critical", notification.Items[0].Severity)
+		}
+	})
+}
+
+func TestNotifications_FailedAreListedAndRetried(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.AlertWarningThreshold = 80
+	cfg.Budget.AlertCriticalThreshold = 95
+	cfg.Budget.NotificationMaxAttempts = 2
+	cfg.Budget.NotificationRecipients = map[string][]string{"pi@example.edu": {"retry-lab"}}
+	service := budget.NewService(db, nil, &cfg.Budget)
+	notifier := &recordingNotifier{}
+	service.SetNotifier(notifier)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "retry-lab",
+		Name:         "Retry Lab",
+		BudgetLimit:  100.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "UPDATE budget_accounts SET budget_used = 97 WHERE slurm_account = 'retry-lab'")
+	require.NoError(t, err)
+	require.NoError(t, service.EvaluateBudgetAlerts(ctx))
+
+	// The relay is down for longer than the notification's attempts
+	notifier.failWith(errors.New("webhook returned 503 Service Unavailable"))
+	for i := 0; i < 2; i++ {
+		sentCount, err := service.SendQueuedNotifications(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, sentCount)
+	}
+
+	failed, err := service.ListNotifications(ctx, api.NotificationFailed)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "pi@example.edu", failed[0].Recipient)
+	assert.Equal(t, 2, failed[0].Attempts)
+	assert.Equal(t, "webhook returned 503 Service Unavailable", failed[0].LastError)
+	require.NotNil(t, failed[0].Notification)
+	assert.Equal(t, "retry-lab", failed[0].Notification.Items[0].Account)
+
+	// A failed notification is no longer sent on its own
+	notifier.failWith(nil)
+	sentCount, err := service.SendQueuedNotifications(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, sentCount)
+	assert.Empty(t, notifier.take())
+
+	t.Run("a retry that fails again stays failed", func(t *testing.T) {
+		notifier.failWith(errors.New("connection refused"))
+		defer notifier.failWith(nil)
+
+		_, err := service.RetryNotification(ctx, failed[0].ID)
+		require.Error(t, err)
+
+		failed, err := service.ListNotifications(ctx, api.NotificationFailed)
+		require.NoError(t, err)
+		require.Len(t, failed, 1)
+		assert.Equal(t, 3, failed[0].Attempts)
+		assert.Equal(t, "connection refused", failed[0].LastError)
+	})
+
+	t.Run("a successful retry is delivered", func(t *testing.T) {
+		retried, err := service.RetryNotification(ctx, failed[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, api.NotificationDelivered, retried.Status)
+		assert.Empty(t, retried.LastError)
+		assert.NotNil(t, retried.DeliveredAt)
+
+		sent := notifier.take()
+		require.Len(t, sent, 1)
+		assert.Equal(t, "pi@example.edu", sent[0].Recipient)
+
+		remaining, err := service.ListNotifications(ctx, api.NotificationFailed)
+		require.NoError(t, err)
+		assert.Empty(t, remaining)
+		delivered, err := service.ListNotifications(ctx, api.NotificationDelivered)
+		require.NoError(t, err)
+		require.Len(t, delivered, 1)
+		assert.Equal(t, failed[0].ID, delivered[0].ID)
+	})
+
+	t.Run("only failed notifications are retried", func(t *testing.T) {
+		_, err := service.RetryNotification(ctx, failed[0].ID)
+		var budgetErr *api.BudgetError
+		require.ErrorAs(t, err, &budgetErr)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+
+	t.Run("an unknown status is refused", func(t *testing.T) {
+		_, err := service.ListNotifications(ctx, "lost")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestOverview_MatchesSeededAccounts(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	createHierarchyAccount(t, service, "nsf-lab", "", 1000.0)
+	createHierarchyAccount(t, service, "nsf-student", "nsf-lab", 400.0)
+	createHierarchyAccount(t, service, "nih-lab", "", 2000.0)
+	createHierarchyAccount(t, service, "unfunded", "", 500.0)
+	createHierarchyAccount(t, service, "dormant", "", 300.0)
+
+	grantStart := time.Now().Add(-30 * 24 * time.Hour)
+	seedGrant := func(number, agency, costCenter string, accounts ...string) {
+		var grantID int64
+		require.NoError(t, db.QueryRowContext(ctx, `
+			INSERT INTO grant_accounts (grant_number, funding_agency, principal_investigator, institution,
+			                            grant_start_date, grant_end_date, total_award_amount, cost_center)
+			VALUES ($1, $2, 'Dr. Smith', 'University', $3, $4, 10000.00, $5)
+			RETURNING id`, number, agency, grantStart, grantStart.AddDate(3, 0, 0), costCenter).Scan(&grantID))
+		for _, account := range accounts {
+			_, err := db.ExecContext(ctx, `UPDATE budget_accounts SET grant_id = $1, is_grant_funded = TRUE WHERE slurm_account = $2`,
+				grantID, account)
+			require.NoError(t, err)
+		}
+	}
+	seedGrant("NSF-OVERVIEW", "NSF", "CC-1", "nsf-lab", "nsf-student")
+	seedGrant("NIH-OVERVIEW", "NIH", "CC-2", "nih-lab")
+
+	for account, used := range map[string]float64{"nsf-lab": 100.0, "nih-lab": 500.0, "dormant": 50.0} {
+		_, err := db.ExecContext(ctx, `UPDATE budget_accounts SET budget_used = $1 WHERE slurm_account = $2`, used, account)
+		require.NoError(t, err)
+	}
+	require.True(t, checkHierarchyBudget(t, service, "nsf-student").Available)
+	require.True(t, checkHierarchyBudget(t, service, "unfunded").Available)
+	_, err := db.ExecContext(ctx, `UPDATE budget_accounts SET status = 'suspended' WHERE slurm_account = 'dormant'`)
+	require.NoError(t, err)
+
+	t.Run("totals match the sum of active top-level accounts", func(t *testing.T) {
+		overview, err := service.Overview(ctx, &api.OverviewRequest{})
+		require.NoError(t, err)
+
+		accounts, err := service.ListAccounts(ctx, &api.ListAccountsRequest{})
+		require.NoError(t, err)
+		var want api.OverviewTotals
+		for _, account := range accounts {
+			if account.Status != "active" {
+				continue
+			}
+			want.Accounts++
+			if account.ParentAccountID == nil {
+				want.TotalAllocated += account.BudgetLimit
+				want.TotalSpent += account.BudgetUsed
+				want.TotalHeld += account.BudgetHeld
+			}
+		}
+
+		assert.Equal(t, want.Accounts, overview.Totals.Accounts)
+		assert.InDelta(t, want.TotalAllocated, overview.Totals.TotalAllocated, 0.001)
+		assert.InDelta(t, want.TotalSpent, overview.Totals.TotalSpent, 0.001)
+		assert.InDelta(t, want.TotalHeld, overview.Totals.TotalHeld, 0.001)
+
+		// The student's hold is already in the lab's balance, so it is counted once
+		assert.Equal(t, 4, overview.Totals.Accounts)
+		assert.InDelta(t, 3500.0, overview.Totals.TotalAllocated, 0.001)
+		assert.InDelta(t, 600.0, overview.Totals.TotalSpent, 0.001)
+		assert.InDelta(t, 24.0, overview.Totals.TotalHeld, 0.001)
+		assert.InDelta(t, 624.0/3500.0*100, overview.Totals.Utilization, 0.001)
+	})
+
+	t.Run("breakdowns by status and agency", func(t *testing.T) {
+		overview, err := service.Overview(ctx, &api.OverviewRequest{})
+		require.NoError(t, err)
+
+		require.Len(t, overview.ByStatus, 2)
+		assert.Equal(t, "active", overview.ByStatus[0].Status)
+		assert.Equal(t, 4, overview.ByStatus[0].Accounts)
+		assert.Equal(t, "suspended", overview.ByStatus[1].Status)
+		assert.Equal(t, 1, overview.ByStatus[1].Accounts)
+		assert.InDelta(t, 300.0, overview.ByStatus[1].TotalAllocated, 0.001)
+		assert.InDelta(t, 50.0, overview.ByStatus[1].TotalSpent, 0.001)
+
+		require.Len(t, overview.ByAgency, 3)
+		assert.Equal(t, "", overview.ByAgency[0].FundingAgency)
+		assert.InDelta(t, 500.0, overview.ByAgency[0].TotalAllocated, 0.001)
+		assert.InDelta(t, 12.0, overview.ByAgency[0].TotalHeld, 0.001)
+		assert.Equal(t, "NIH", overview.ByAgency[1].FundingAgency)
+		assert.InDelta(t, 500.0, overview.ByAgency[1].TotalSpent, 0.001)
+		assert.Equal(t, "NSF", overview.ByAgency[2].FundingAgency)
+		assert.Equal(t, 2, overview.ByAgency[2].Accounts)
+		assert.InDelta(t, 1000.0, overview.ByAgency[2].TotalAllocated, 0.001)
+		assert.InDelta(t, 12.0, overview.ByAgency[2].TotalHeld, 0.001)
+	})
+
+	t.Run("filtered by agency", func(t *testing.T) {
+		overview, err := service.Overview(ctx, &api.OverviewRequest{FundingAgency: "NSF"})
+		require.NoError(t, err)
+		assert.Equal(t, "NSF", overview.FundingAgency)
+		assert.Equal(t, 2, overview.Totals.Accounts)
+		assert.InDelta(t, 1000.0, overview.Totals.TotalAllocated, 0.001)
+		assert.InDelta(t, 100.0, overview.Totals.TotalSpent, 0.001)
+		assert.InDelta(t, 12.0, overview.Totals.TotalHeld, 0.001)
+		require.Len(t, overview.ByAgency, 1)
+	})
+
+	t.Run("filtered by cost center", func(t *testing.T) {
+		overview, err := service.Overview(ctx, &api.OverviewRequest{CostCenter: "CC-2"})
+		require.NoError(t, err)
+		assert.Equal(t, 1, overview.Totals.Accounts)
+		assert.InDelta(t, 2000.0, overview.Totals.TotalAllocated, 0.001)
+		assert.InDelta(t, 25.0, overview.Totals.Utilization, 0.001)
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_AdvisorReceivesRecentActuals(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.AdvisorPriorActuals = 2
+
+	// The advisor records the metadata of every request it is sent
+	var mu sync.Mutex
+	var requests []map[string]string
+	recording := &advisor.MockClient{
+		EstimateFunc: func(ctx context.Context, req *budget.CostEstimateRequest) (*budget.CostEstimateResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, req.Metadata)
+			return &budget.CostEstimateResponse{EstimatedCost: 10.0, Confidence: 0.9}, nil
+		},
+	}
+	lastMetadata := func() map[string]string {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[len(requests)-1]
+	}
+	service := budget.NewService(db, recording, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "priors-lab",
+		Name:         "Priors Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	gpuJob := &api.BudgetCheckRequest{Account: "priors-lab", Partition: "gpu", Nodes: 1, CPUs: 8, GPUs: 1, WallTime: "02:00:00"}
+	run := func(req *api.BudgetCheckRequest, jobID string, actualCost float64, jobMetadata string) {
+		resp, err := service.CheckBudget(ctx, req)
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: jobID, ActualCost: actualCost, TransactionID: resp.TransactionID, JobMetadata: jobMetadata,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("no history, no priors", func(t *testing.T) {
+		run(gpuJob, "priors-1", 7.0, `{"cpu_efficiency": 0.9}`)
+		assert.Empty(t, lastMetadata())
+	})
+
+	run(gpuJob, "priors-2", 8.0, `{"cpu_efficiency": 0.7, "memory_efficiency": 0.4}`)
+	run(gpuJob, "priors-3", 12.0, "")
+	// Jobs of another shape are not priors for this one
+	run(&api.BudgetCheckRequest{Account: "priors-lab", Partition: "gpu", Nodes: 1, CPUs: 8, GPUs: 4, WallTime: "02:00:00"},
+		"priors-4", 40.0, "")
+
+	t.Run("recent actuals of the same shape are sent", func(t *testing.T) {
+		resp, err := service.CheckBudget(ctx, gpuJob)
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+
+		metadata := lastMetadata()
+		assert.Equal(t, "2", metadata["prior_actual_count"], "only the configured number of the most recent jobs")
+		assert.Equal(t, "10.00", metadata["prior_mean_actual_cost"])
+		assert.Equal(t, "0.700", metadata["prior_mean_cpu_efficiency"])
+
+		var actuals []api.PriorActual
+		require.NoError(t, json.Unmarshal([]byte(metadata["prior_actuals"]), &actuals))
+		require.Len(t, actuals, 2)
+		assert.InDelta(t, 12.0, actuals[0].ActualCost, 0.001, "newest first")
+		assert.InDelta(t, 8.0, actuals[1].ActualCost, 0.001)
+		assert.InDelta(t, 10.0, actuals[1].EstimatedCost, 0.001)
+		assert.InDelta(t, 0.7, actuals[1].CPUEfficiency, 0.001)
+		assert.InDelta(t, 0.4, actuals[1].MemoryEfficiency, 0.001)
+	})
+
+	t.Run("another partition has no priors", func(t *testing.T) {
+		_, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{Account: "priors-lab", Partition: "cpu", Nodes: 1, CPUs: 8, WallTime: "02:00:00"})
+		require.NoError(t, err)
+		assert.Empty(t, lastMetadata())
+	})
+}
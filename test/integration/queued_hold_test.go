@@ -0,0 +1,161 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_QueuedHold(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	queuedCfg := cfg.Budget
+	queuedCfg.PartitionQueuedHoldFraction = map[string]float64{"aws": 0.1}
+	service := budget.NewService(db, &advisor.MockClient{}, &queuedCfg)
+
+	held := func(name string) float64 {
+		account, err := service.GetAccount(ctx, name)
+		require.NoError(t, err)
+		return account.BudgetHeld
+	}
+	check := func(name, partition string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: name, Partition: partition, Nodes: 1, CPUs: 4, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+
+	t.Run("reservation escalates to the full hold when the job starts", func(t *testing.T) {
+		createHierarchyAccount(t, service, "queued-dept", "", 1000)
+		createHierarchyAccount(t, service, "queued-lab", "queued-dept", 500)
+
+		// The mock advisor makes every full hold $12
+		resp := check("queued-lab", "aws")
+		assert.InDelta(t, 1.2, resp.HoldAmount, 0.001)
+		assert.InDelta(t, 12.0, resp.FullHoldAmount, 0.001)
+		assert.InDelta(t, 1.2, held("queued-lab"), 0.001)
+		assert.InDelta(t, 1.2, held("queued-dept"), 0.001)
+
+		started, err := service.StartJob(ctx, &api.JobStartedRequest{JobID: "q-1", TransactionID: resp.TransactionID})
+		require.NoError(t, err)
+		assert.True(t, started.Escalated)
+		assert.InDelta(t, 1.2, started.ReservedAmount, 0.001)
+		assert.InDelta(t, 12.0, started.HoldAmount, 0.001)
+		assert.Empty(t, started.Warning)
+		assert.InDelta(t, 12.0, held("queued-lab"), 0.001)
+		assert.InDelta(t, 12.0, held("queued-dept"), 0.001)
+
+		holds, err := service.ListTransactions(ctx, &api.TransactionListRequest{Account: "queued-lab", Type: "hold"})
+		require.NoError(t, err)
+		require.Len(t, holds, 1)
+		require.NotNil(t, holds[0].JobID)
+		assert.Equal(t, "q-1", *holds[0].JobID)
+		assert.NotNil(t, holds[0].StartedAt)
+		assert.InDelta(t, 12.0, holds[0].Amount, 0.001)
+
+		// A repeated report from the prolog changes nothing
+		again, err := service.StartJob(ctx, &api.JobStartedRequest{JobID: "q-1", TransactionID: resp.TransactionID})
+		require.NoError(t, err)
+		assert.False(t, again.Escalated)
+		assert.InDelta(t, 12.0, held("queued-lab"), 0.001)
+
+		// Another job's prolog cannot claim the hold
+		_, err = service.StartJob(ctx, &api.JobStartedRequest{JobID: "q-other", TransactionID: resp.TransactionID})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok, "expected a budget error, got %v", err)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		assert.Contains(t, budgetErr.Message, "q-1")
+
+		reconciled, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "q-1", TransactionID: resp.TransactionID, ActualCost: 8,
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 12.0, reconciled.OriginalHold, 0.001)
+		assert.InDelta(t, 4.0, reconciled.RefundAmount, 0.001)
+		assert.InDelta(t, 0.0, held("queued-lab"), 0.001)
+		assert.InDelta(t, 0.0, held("queued-dept"), 0.001)
+
+		for _, name := range []string{"queued-lab", "queued-dept"} {
+			consistency, err := service.VerifyAccountConsistency(ctx, name)
+			require.NoError(t, err)
+			assert.True(t, consistency.Consistent, name)
+		}
+	})
+
+	t.Run("job cancelled while queued releases only its reservation", func(t *testing.T) {
+		createHierarchyAccount(t, service, "queued-cancel", "", 1000)
+
+		resp := check("queued-cancel", "aws")
+		assert.InDelta(t, 1.2, held("queued-cancel"), 0.001)
+
+		reconciled, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "q-2", TransactionID: resp.TransactionID, ActualCost: 0, JobState: "CANCELLED",
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 1.2, reconciled.OriginalHold, 0.001)
+		assert.InDelta(t, 1.2, reconciled.RefundAmount, 0.001)
+
+		account, err := service.GetAccount(ctx, "queued-cancel")
+		require.NoError(t, err)
+		assert.InDelta(t, 0.0, account.BudgetHeld, 0.001)
+		assert.InDelta(t, 0.0, account.BudgetUsed, 0.001)
+
+		// A prolog reporting in after the job was reconciled cannot hold again
+		_, err = service.StartJob(ctx, &api.JobStartedRequest{JobID: "q-2", TransactionID: resp.TransactionID})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+		assert.InDelta(t, 0.0, held("queued-cancel"), 0.001)
+	})
+
+	t.Run("escalation beyond the budget warns", func(t *testing.T) {
+		createHierarchyAccount(t, service, "queued-tight", "", 20)
+
+		first := check("queued-tight", "aws")
+		second := check("queued-tight", "aws")
+
+		started, err := service.StartJob(ctx, &api.JobStartedRequest{JobID: "q-3", TransactionID: first.TransactionID})
+		require.NoError(t, err)
+		assert.Empty(t, started.Warning)
+
+		// $12 is held for the first job and $1.20 for the second, leaving $6.80 for its
+		// $10.80 escalation
+		started, err = service.StartJob(ctx, &api.JobStartedRequest{JobID: "q-4", TransactionID: second.TransactionID})
+		require.NoError(t, err)
+		assert.True(t, started.Escalated)
+		assert.NotEmpty(t, started.Warning)
+		assert.InDelta(t, 24.0, held("queued-tight"), 0.001)
+	})
+
+	t.Run("other partitions hold in full", func(t *testing.T) {
+		createHierarchyAccount(t, service, "queued-cpu", "", 1000)
+
+		resp := check("queued-cpu", "cpu")
+		assert.InDelta(t, 12.0, resp.HoldAmount, 0.001)
+		assert.Zero(t, resp.FullHoldAmount)
+
+		started, err := service.StartJob(ctx, &api.JobStartedRequest{JobID: "q-5", TransactionID: resp.TransactionID})
+		require.NoError(t, err)
+		assert.False(t, started.Escalated)
+		assert.InDelta(t, 12.0, held("queued-cpu"), 0.001)
+	})
+}
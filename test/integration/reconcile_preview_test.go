@@ -0,0 +1,134 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_ReconcilePreviewMatchesReconciliation(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, account := range []string{"preview-lab", "preview-grant"} {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: account,
+			Name:         account,
+			BudgetLimit:  1000.0,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	// The mock advisor estimates $10, held at $12
+	hold := func(shares []api.CostShare) string {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "preview-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00", CostShares: shares,
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available, resp.Message)
+		return resp.TransactionID
+	}
+
+	// ledger is what a preview must leave untouched
+	type ledger struct {
+		transactions int
+		held, used   float64
+	}
+	snapshot := func() ledger {
+		var l ledger
+		require.NoError(t, db.QueryRowContext(ctx, `SELECT COUNT(*) FROM budget_transactions`).Scan(&l.transactions))
+		account, err := service.GetAccount(ctx, "preview-lab")
+		require.NoError(t, err)
+		l.held, l.used = account.BudgetHeld, account.BudgetUsed
+		return l
+	}
+
+	// previewThenReconcile previews a reconciliation, checks it wrote nothing, then commits
+	// it and checks the preview foretold the outcome
+	previewThenReconcile := func(t *testing.T, req *api.JobReconcileRequest) *api.JobReconcileResponse {
+		before := snapshot()
+		preview, err := service.PreviewReconciliation(ctx, req)
+		require.NoError(t, err)
+		assert.True(t, preview.Preview)
+		assert.Equal(t, before, snapshot(), "a preview writes nothing")
+
+		// Previewing twice gives the same answer, since nothing was settled
+		again, err := service.PreviewReconciliation(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, preview, again)
+
+		actual, err := service.ReconcileJob(ctx, req)
+		require.NoError(t, err)
+		assert.False(t, actual.Preview)
+		assert.InDelta(t, actual.OriginalHold, preview.OriginalHold, 0.001)
+		assert.InDelta(t, actual.ActualCharge, preview.ActualCharge, 0.001)
+		assert.InDelta(t, actual.AdditionalCharge, preview.AdditionalCharge, 0.001)
+		assert.InDelta(t, actual.RefundAmount, preview.RefundAmount, 0.001)
+		assert.Equal(t, actual.FailedJobPolicy, preview.FailedJobPolicy)
+		assert.Equal(t, actual.BudgetUnit, preview.BudgetUnit)
+		assert.Equal(t, actual.Warning, preview.Warning)
+		assert.Equal(t, actual.CostShares, preview.CostShares)
+		return preview
+	}
+
+	t.Run("job under its hold is refunded the difference", func(t *testing.T) {
+		txn := hold(nil)
+		preview := previewThenReconcile(t, &api.JobReconcileRequest{JobID: "preview-1", ActualCost: 8.0, TransactionID: txn})
+		assert.InDelta(t, 12.0, preview.OriginalHold, 0.001)
+		assert.InDelta(t, 8.0, preview.ActualCharge, 0.001)
+		assert.InDelta(t, 4.0, preview.RefundAmount, 0.001)
+		assert.Zero(t, preview.AdditionalCharge)
+		assert.Equal(t, txn, preview.TransactionID)
+	})
+
+	t.Run("job over its hold is charged the overrun", func(t *testing.T) {
+		preview := previewThenReconcile(t, &api.JobReconcileRequest{JobID: "preview-2", ActualCost: 15.0, TransactionID: hold(nil)})
+		assert.InDelta(t, 15.0, preview.ActualCharge, 0.001)
+		assert.InDelta(t, 3.0, preview.AdditionalCharge, 0.001)
+		assert.Zero(t, preview.RefundAmount)
+	})
+
+	t.Run("cost-shared job is split between its funders", func(t *testing.T) {
+		txn := hold([]api.CostShare{{Account: "preview-lab", Percentage: 60}, {Account: "preview-grant", Percentage: 40}})
+		preview := previewThenReconcile(t, &api.JobReconcileRequest{JobID: "preview-3", ActualCost: 10.0, TransactionID: txn})
+		require.Len(t, preview.CostShares, 2)
+		assert.InDelta(t, 6.0, preview.CostShares[0].ActualCharge, 0.001)
+		assert.InDelta(t, 1.2, preview.CostShares[0].RefundAmount, 0.001)
+		assert.InDelta(t, 2.0, preview.RefundAmount, 0.001)
+	})
+
+	t.Run("job run without a hold is charged in full", func(t *testing.T) {
+		preview := previewThenReconcile(t, &api.JobReconcileRequest{JobID: "preview-4", ActualCost: 0.5, Account: "preview-lab"})
+		assert.InDelta(t, 0.5, preview.ActualCharge, 0.001)
+		assert.InDelta(t, 0.5, preview.AdditionalCharge, 0.001)
+		assert.Empty(t, preview.TransactionID, "no charge is made to identify")
+	})
+
+	t.Run("preview of a missing hold is not found", func(t *testing.T) {
+		_, err := service.PreviewReconciliation(ctx, &api.JobReconcileRequest{JobID: "preview-5", ActualCost: 1.0, TransactionID: "txn_missing"})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+}
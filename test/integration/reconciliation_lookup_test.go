@@ -0,0 +1,148 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestASBX_ReconciliationLookup(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	integration := asbx.NewIntegrationService(service, &asbx.IntegrationConfig{Enabled: true})
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "lookup-lab",
+		Name:         "Lookup Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// Each job is held 12.00 for its estimated 10.00
+	reconcile := func(jobID string, actual float64) (string, *api.ASBXCostReconciliationResponse) {
+		check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "lookup-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, check.Available)
+
+		resp, err := integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID:               jobID,
+				Account:             "lookup-lab",
+				JobState:            "COMPLETED",
+				EstimatedCost:       float64Ptr(10.0),
+				ActualCost:          float64Ptr(actual),
+				BudgetTransactionID: check.TransactionID,
+			},
+		})
+		require.NoError(t, err)
+		return check.TransactionID, resp
+	}
+
+	types := func(reconciliation *api.ASBXReconciliation) map[string]int {
+		counts := map[string]int{}
+		for _, txn := range reconciliation.Transactions {
+			counts[txn.Type]++
+		}
+		return counts
+	}
+
+	t.Run("status before any reconciliation", func(t *testing.T) {
+		status, err := integration.GetIntegrationStatus(ctx)
+		require.NoError(t, err)
+		assert.True(t, status.IntegrationEnabled)
+		assert.Zero(t, status.TotalJobsReconciled)
+		assert.Nil(t, status.LastDataImport)
+	})
+
+	t.Run("hold, charge and refund of a job under its hold", func(t *testing.T) {
+		hold, resp := reconcile("lookup-1", 9.0)
+
+		reconciliation, err := service.GetASBXReconciliation(ctx, resp.ReconciliationID)
+		require.NoError(t, err)
+		require.Len(t, reconciliation.Transactions, 3)
+		assert.Equal(t, map[string]int{"hold": 1, "charge": 1, "refund": 1}, types(reconciliation))
+		assert.Equal(t, hold, reconciliation.Transactions[0].TransactionID)
+
+		require.NotNil(t, reconciliation.Summary)
+		assert.Equal(t, hold, reconciliation.Summary.OriginalTransaction)
+		assert.Equal(t, "lookup-1", reconciliation.Summary.JobID)
+		assert.InDelta(t, 10.0, reconciliation.Summary.EstimatedCost, 0.001)
+		assert.InDelta(t, 9.0, reconciliation.Summary.ActualCost, 0.001)
+		assert.InDelta(t, -1.0, reconciliation.Summary.CostVariance, 0.001)
+		assert.InDelta(t, 3.0, reconciliation.Summary.RefundAmount, 0.001)
+	})
+
+	t.Run("hold and both charges of a job over its hold", func(t *testing.T) {
+		_, resp := reconcile("lookup-2", 15.0)
+		assert.InDelta(t, 3.0, resp.AdditionalCharge, 0.001, "the 3.00 beyond the 12.00 hold")
+		assert.Zero(t, resp.RefundAmount)
+
+		reconciliation, err := service.GetASBXReconciliation(ctx, resp.ReconciliationID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"hold": 1, "charge": 2}, types(reconciliation))
+		for _, txn := range reconciliation.Transactions {
+			require.NotNil(t, txn.JobID)
+			assert.Equal(t, "lookup-2", *txn.JobID, "transactions of other reconciliations are left out")
+		}
+	})
+
+	t.Run("retroactive charge of a job never held", func(t *testing.T) {
+		resp, err := integration.ProcessCostReconciliation(ctx, &api.ASBXCostReconciliationRequest{
+			JobCostData: api.ASBXJobCostData{
+				JobID:      "lookup-3",
+				Account:    "lookup-lab",
+				JobState:   "COMPLETED",
+				ActualCost: float64Ptr(5.0),
+			},
+		})
+		require.NoError(t, err)
+		assert.InDelta(t, 5.0, resp.AdditionalCharge, 0.001, "all of it, with no hold")
+
+		reconciliation, err := service.GetASBXReconciliation(ctx, resp.ReconciliationID)
+		require.NoError(t, err)
+		require.Len(t, reconciliation.Transactions, 1)
+		assert.Equal(t, resp.ChargeTransaction, reconciliation.Transactions[0].TransactionID)
+		require.NotNil(t, reconciliation.Summary)
+		assert.True(t, reconciliation.Summary.Unreserved)
+	})
+
+	t.Run("status summarizes the recorded reconciliations", func(t *testing.T) {
+		status, err := integration.GetIntegrationStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), status.TotalJobsReconciled)
+		require.NotNil(t, status.LastDataImport)
+		// 0.9 and 0.5 for the two jobs ASBX estimated; the unestimated job is left out
+		assert.InDelta(t, 0.7, status.CostModelAccuracy, 0.001)
+	})
+
+	t.Run("unknown reconciliation", func(t *testing.T) {
+		_, err := service.GetASBXReconciliation(ctx, "asbx_recon_0")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeNotFound, budgetErr.Code)
+	})
+}
@@ -0,0 +1,274 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/asbx"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestRecovery_ListAndRecoverOrphanedHolds(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.AutoRecoveryEnabled = false
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	transactionQueries := database.NewTransactionQueries(db)
+	ctx := context.Background()
+
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "orphans",
+		Name:         "Orphaned Holds Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-7 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	// The mock advisor estimates $10, held at $12
+	check := &api.BudgetCheckRequest{Account: "orphans", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00"}
+	oldHold := placeAgedHold(t, db, service, check, 72*time.Hour)
+	recentHold := placeAgedHold(t, db, service, check, 30*time.Hour)
+	placeAgedHold(t, db, service, check, time.Hour)
+
+	// An old job that did reconcile is no orphan
+	reconciledHold := placeAgedHold(t, db, service, check, 96*time.Hour)
+	_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{JobID: "1001", ActualCost: 8.0, TransactionID: reconciledHold})
+	require.NoError(t, err)
+
+	// A hold whose balance was never applied holds nothing on the account
+	require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, &api.BudgetTransaction{
+		TransactionID: "txn_never_applied",
+		AccountID:     account.ID,
+		Type:          "hold",
+		Amount:        40,
+		Description:   "Hold never applied",
+		Status:        "pending",
+	}))
+	_, err = db.ExecContext(ctx, "UPDATE budget_transactions SET created_at = $1 WHERE transaction_id = 'txn_never_applied'",
+		time.Now().Add(-60*time.Hour))
+	require.NoError(t, err)
+
+	listed, err := service.ListOrphanedHolds(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed.Holds, 3)
+	assert.Equal(t, oldHold, listed.Holds[0].TransactionID)
+	assert.Equal(t, "orphans", listed.Holds[0].Account)
+	assert.InDelta(t, 72, listed.Holds[0].AgeHours, 0.1)
+	assert.Equal(t, "txn_never_applied", listed.Holds[1].TransactionID)
+	assert.Equal(t, recentHold, listed.Holds[2].TransactionID)
+
+	// Automatic recovery is disabled, but an on-demand run still recovers
+	require.NoError(t, service.RecoverOrphanedTransactions(ctx))
+	recovered, err := service.RecoverOrphanedHolds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, recovered.Found)
+	assert.ElementsMatch(t, []string{oldHold, "txn_never_applied"}, recovered.Recovered)
+	assert.Empty(t, recovered.Failed)
+
+	// Only the old hold is released; the recent and fresh holds stay held, and the hold
+	// never applied releases nothing of theirs
+	updated, err := service.GetAccount(ctx, "orphans")
+	require.NoError(t, err)
+	assert.InDelta(t, 24.0, updated.BudgetHeld, 0.001)
+	assert.InDelta(t, 8.0, updated.BudgetUsed, 0.001)
+
+	cancelled, err := service.GetTransaction(ctx, oldHold)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", cancelled.Status)
+
+	listed, err = service.ListOrphanedHolds(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed.Holds, 1, "recovered holds are no longer orphaned")
+	assert.Equal(t, recentHold, listed.Holds[0].TransactionID)
+
+	result, err := service.VerifyAccountConsistency(ctx, "orphans")
+	require.NoError(t, err)
+	assert.True(t, result.Consistent)
+}
+
+func TestRecovery_LargeBacklogInBatches(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	cfg.Budget.WorkerBatchSize = 10
+	cfg.Budget.WorkerConcurrency = 3
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "orphan-backlog",
+		Name:         "Orphan Backlog Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-7 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check := &api.BudgetCheckRequest{Account: "orphan-backlog", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00"}
+	for i := 0; i < 25; i++ {
+		placeAgedHold(t, db, service, check, 72*time.Hour)
+	}
+
+	recovered, err := service.RecoverOrphanedHolds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 25, recovered.Found)
+	assert.Equal(t, 3, recovered.Batches)
+	assert.Len(t, recovered.Recovered, 25)
+	assert.Empty(t, recovered.Failed)
+
+	listed, err := service.ListOrphanedHolds(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, listed.Holds)
+
+	updated, err := service.GetAccount(ctx, "orphan-backlog")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0, updated.BudgetHeld, 0.001)
+
+	result, err := service.VerifyAccountConsistency(ctx, "orphan-backlog")
+	require.NoError(t, err)
+	assert.True(t, result.Consistent)
+}
+
+func TestRecovery_ASBXHoldExpiryCallback(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "expiry",
+		Name:         "Hold Expiry Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-7 * 24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	check := &api.BudgetCheckRequest{Account: "expiry", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00"}
+	knownHold := placeAgedHold(t, db, service, check, 72*time.Hour)
+	unknownHold := placeAgedHold(t, db, service, check, 72*time.Hour)
+
+	// ASBX knows what became of one job and has nothing for the other
+	asbxServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/budget-holds/"+knownHold+"/job-cost" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(api.ASBXJobCostData{
+			JobID:               "3001",
+			JobState:            "COMPLETED",
+			ActualCost:          float64Ptr(30),
+			BudgetTransactionID: knownHold,
+		})
+	}))
+	defer asbxServer.Close()
+	service.SetHoldExpiryChecker(asbx.NewClient(asbxServer.URL, ""), time.Second)
+
+	recovered, err := service.RecoverOrphanedHolds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, recovered.Found)
+	assert.Equal(t, []string{knownHold}, recovered.Reconciled)
+	assert.Equal(t, []string{unknownHold}, recovered.Recovered)
+	assert.Empty(t, recovered.Failed)
+
+	reconciled, err := service.GetTransaction(ctx, knownHold)
+	require.NoError(t, err)
+	assert.Equal(t, "completed", reconciled.Status)
+
+	cancelled, err := service.GetTransaction(ctx, unknownHold)
+	require.NoError(t, err)
+	assert.Equal(t, "cancelled", cancelled.Status)
+
+	updated, err := service.GetAccount(ctx, "expiry")
+	require.NoError(t, err)
+	assert.InDelta(t, 30.0, updated.BudgetUsed, 0.001)
+	assert.InDelta(t, 0.0, updated.BudgetHeld, 0.001)
+}
+
+func TestRecovery_ListPendingReconciliations(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	ctx := context.Background()
+
+	for _, name := range []string{"pending-a", "pending-b"} {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: name,
+			Name:         name,
+			BudgetLimit:  1000.0,
+			StartDate:    time.Now().Add(-7 * 24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+		})
+		require.NoError(t, err)
+	}
+
+	checkA := &api.BudgetCheckRequest{Account: "pending-a", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00"}
+	checkB := &api.BudgetCheckRequest{Account: "pending-b", Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00"}
+	staleHold := placeAgedHold(t, db, service, checkA, 30*time.Hour)
+	freshHold := placeAgedHold(t, db, service, checkA, time.Hour)
+	otherHold := placeAgedHold(t, db, service, checkB, 3*time.Hour)
+
+	// A reconciled hold is no longer pending
+	doneHold := placeAgedHold(t, db, service, checkA, 2*time.Hour)
+	_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{JobID: "2003", ActualCost: 9.0, TransactionID: doneHold})
+	require.NoError(t, err)
+
+	t.Run("all accounts", func(t *testing.T) {
+		resp, err := service.ListPendingReconciliations(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, resp.Holds, 3)
+		assert.Equal(t, 1, resp.PastTimeout)
+		assert.Equal(t, staleHold, resp.Holds[0].TransactionID)
+		assert.True(t, resp.Holds[0].PastTimeout)
+		assert.InDelta(t, 30, resp.Holds[0].AgeHours, 0.1)
+		assert.Equal(t, otherHold, resp.Holds[1].TransactionID)
+		assert.Equal(t, freshHold, resp.Holds[2].TransactionID)
+	})
+
+	t.Run("one account", func(t *testing.T) {
+		resp, err := service.ListPendingReconciliations(ctx, "pending-a")
+		require.NoError(t, err)
+		require.Len(t, resp.Holds, 2)
+		assert.Equal(t, staleHold, resp.Holds[0].TransactionID)
+		assert.Equal(t, freshHold, resp.Holds[1].TransactionID)
+		assert.False(t, resp.Holds[1].PastTimeout)
+		assert.InDelta(t, 12.0, resp.Holds[1].Amount, 0.001)
+	})
+
+	t.Run("unknown account", func(t *testing.T) {
+		_, err := service.ListPendingReconciliations(ctx, "no-such-account")
+		require.Error(t, err)
+	})
+}
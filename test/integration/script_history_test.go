@@ -0,0 +1,101 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_ScriptHistory(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	cfg.Budget.ScriptHistoryEnabled = true
+	cfg.Budget.ScriptHistoryMinSamples = 3
+	cfg.Budget.ScriptHistorySampleLimit = 20
+	cfg.Budget.ScriptHistoryWeight = 0.5
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "script-lab",
+		Name:         "Script Lab",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	script := "#!/bin/bash\n#SBATCH --nodes=1\nsrun ./solver\n"
+	check := func(jobScript string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "script-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+			JobScript: jobScript,
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+	reconcile := func(n int, resp *api.BudgetCheckResponse, actualCost float64, state string) {
+		_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: fmt.Sprintf("script-%d", n), TransactionID: resp.TransactionID,
+			ActualCost: actualCost, JobState: state,
+		})
+		require.NoError(t, err)
+	}
+
+	// The mock advisor estimates $10; the script's runs cost $4 and $6
+	reconcile(1, check(script), 4.0, "COMPLETED")
+	reconcile(2, check(script), 6.0, "")
+	// A failed run says nothing about the script's cost
+	reconcile(3, check(script), 50.0, "FAILED")
+
+	t.Run("too few samples leaves the estimate alone", func(t *testing.T) {
+		resp := check(script)
+		assert.InDelta(t, 10.0, resp.EstimatedCost, 0.001)
+		assert.Nil(t, resp.ScriptHistory)
+		reconcile(4, resp, 5.0, "COMPLETED")
+	})
+
+	t.Run("history is blended in once there are enough samples", func(t *testing.T) {
+		// Reformatting and comments do not change the script
+		resp := check("#!/bin/bash\r\n# solver run\r\n  #SBATCH --nodes=1\r\n\r\nsrun ./solver")
+		require.NotNil(t, resp.ScriptHistory)
+		assert.Equal(t, 3, resp.ScriptHistory.Samples)
+		assert.InDelta(t, 5.0, resp.ScriptHistory.MeanActualCost, 0.001)
+		assert.InDelta(t, 10.0, resp.ScriptHistory.AdvisorEstimate, 0.001)
+		assert.InDelta(t, 7.5, resp.EstimatedCost, 0.001)
+		assert.InDelta(t, 9.0, resp.HoldAmount, 0.001)
+
+		estimate, err := service.EstimateJobCost(ctx, &api.EstimateRequest{
+			Account: "script-lab", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+			JobScript: script,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, estimate.ScriptHistory)
+		assert.InDelta(t, 7.5, estimate.EstimatedCost, 0.001)
+	})
+
+	t.Run("other scripts are unaffected", func(t *testing.T) {
+		resp := check("#!/bin/bash\nsrun ./other-solver\n")
+		assert.Nil(t, resp.ScriptHistory)
+		assert.InDelta(t, 10.0, resp.EstimatedCost, 0.001)
+	})
+}
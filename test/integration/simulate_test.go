@@ -0,0 +1,94 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestSimulate_DepletionFollowsBurnRate(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+	transactionQueries := database.NewTransactionQueries(db)
+	ctx := context.Background()
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	account, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "simulate-account",
+		Name:         "Simulation Account",
+		BudgetLimit:  1000.0,
+		StartDate:    today.AddDate(0, 0, -60),
+		EndDate:      today.AddDate(0, 0, 60),
+	})
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "UPDATE budget_accounts SET created_at = $2 WHERE id = $1", account.ID, today.AddDate(0, 0, -60))
+	require.NoError(t, err)
+
+	// $300 spent over the last 30 days is a burn rate of $10/day
+	require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, &api.BudgetTransaction{
+		TransactionID: "simulate-charge", AccountID: account.ID, Type: "charge",
+		Amount: 300.0, Description: "charge", Status: "completed",
+	}))
+	_, err = db.ExecContext(ctx,
+		"UPDATE budget_transactions SET created_at = $2, completed_at = $2 WHERE transaction_id = $1",
+		"simulate-charge", today.AddDate(0, 0, -10).Add(12*time.Hour))
+	require.NoError(t, err)
+
+	simulate := func(count int) *api.SimulationResponse {
+		resp, err := service.SimulateBudget(ctx, "simulate-account", &api.SimulationRequest{
+			Jobs: []api.SimulatedJob{{
+				Partition: "cpu", Nodes: 1, CPUs: 4, WallTime: "01:00:00", Count: count,
+			}},
+			PeriodDays: 7,
+		})
+		require.NoError(t, err)
+		return resp
+	}
+
+	// The mock advisor estimates $10.00 per job. Five jobs over a week spend $120 in
+	// total with the base burn, leaving $580 at $10/day: 65 days, after the deadline.
+	t.Run("small experiment finishes before depletion", func(t *testing.T) {
+		resp := simulate(5)
+		assert.InDelta(t, 50.0, resp.SimulatedCost, 0.001)
+		assert.InDelta(t, 700.0, resp.CurrentAvailable, 0.001)
+		assert.InDelta(t, 650.0, resp.ProjectedAvailable, 0.001)
+		assert.InDelta(t, 10.0, resp.CurrentDailyBurnRate, 0.001)
+		assert.InDelta(t, 10.0+50.0/7, resp.ProjectedDailyBurnRate, 0.001)
+		require.NotNil(t, resp.ProjectedDepletionDate)
+		assert.WithinDuration(t, time.Now().AddDate(0, 0, 65), *resp.ProjectedDepletionDate, time.Hour)
+		assert.False(t, resp.DepletesBeforeDeadline)
+	})
+
+	// Fifty jobs spend $570 in the week, leaving $130: 20 days, well before the deadline
+	t.Run("large experiment depletes before deadline", func(t *testing.T) {
+		resp := simulate(50)
+		require.NotNil(t, resp.ProjectedDepletionDate)
+		assert.WithinDuration(t, time.Now().AddDate(0, 0, 20), *resp.ProjectedDepletionDate, time.Hour)
+		assert.True(t, resp.DepletesBeforeDeadline)
+	})
+
+	// Simulating records nothing
+	after, err := service.GetAccount(ctx, "simulate-account")
+	require.NoError(t, err)
+	assert.InDelta(t, 300.0, after.BudgetUsed, 0.001)
+	assert.InDelta(t, 0.0, after.BudgetHeld, 0.001)
+}
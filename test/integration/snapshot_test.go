@@ -0,0 +1,140 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/database"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestSnapshot_MatchesReplay(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, nil, &cfg.Budget)
+	accountQueries := database.NewAccountQueries(db)
+	transactionQueries := database.NewTransactionQueries(db)
+	ctx := context.Background()
+
+	account, err := accountQueries.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "test-account-snapshot",
+		Name:         "Snapshot Test Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	for _, txn := range []*api.BudgetTransaction{
+		{TransactionID: "snap-hold-1", AccountID: account.ID, Type: "hold", Amount: 120.0, Description: "hold", Status: "completed"},
+		{TransactionID: "snap-charge-1", AccountID: account.ID, Type: "charge", Amount: 75.0, Description: "charge", Status: "completed"},
+	} {
+		require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, txn))
+	}
+
+	today := time.Now().UTC()
+
+	// No snapshot exists yet, so this is replayed from account creation
+	replayed, err := service.GetSnapshot(ctx, "test-account-snapshot", today)
+	require.NoError(t, err)
+	assert.Equal(t, "replay", replayed.Source)
+
+	require.NoError(t, service.CaptureDailySnapshots(ctx, today))
+
+	captured, err := service.GetSnapshot(ctx, "test-account-snapshot", today)
+	require.NoError(t, err)
+	assert.Equal(t, "snapshot", captured.Source)
+
+	assert.InDelta(t, captured.BudgetLimit, replayed.BudgetLimit, 0.001)
+	assert.InDelta(t, captured.BudgetUsed, replayed.BudgetUsed, 0.001)
+	assert.InDelta(t, captured.BudgetHeld, replayed.BudgetHeld, 0.001)
+	assert.InDelta(t, captured.BudgetAvailable, replayed.BudgetAvailable, 0.001)
+
+	// A later date with no snapshot replays forward from today's snapshot
+	later, err := service.GetSnapshot(ctx, "test-account-snapshot", today.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, "replay", later.Source)
+	assert.InDelta(t, captured.BudgetAvailable, later.BudgetAvailable, 0.001)
+}
+
+func TestSnapshot_MidPeriodLimitIncrease(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, nil, &cfg.Budget)
+	accountQueries := database.NewAccountQueries(db)
+	transactionQueries := database.NewTransactionQueries(db)
+	ctx := context.Background()
+
+	account, err := accountQueries.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "test-account-limit-history",
+		Name:         "Limit History Test Account",
+		BudgetLimit:  1000.0,
+		StartDate:    time.Now().AddDate(0, 0, -30),
+		EndDate:      time.Now().AddDate(1, 0, 0),
+	})
+	require.NoError(t, err)
+	require.NoError(t, transactionQueries.CreateTransaction(ctx, nil, &api.BudgetTransaction{
+		TransactionID: "limit-history-charge-1", AccountID: account.ID, Type: "charge", Amount: 200.0,
+		Description: "charge", Status: "completed",
+	}))
+
+	// The account opened ten days ago; today its grant was increased
+	_, err = db.ExecContext(ctx, `UPDATE budget_accounts SET created_at = NOW() - INTERVAL '10 days' WHERE id = $1`, account.ID)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `UPDATE budget_limit_history SET effective_at = NOW() - INTERVAL '10 days' WHERE account_id = $1`, account.ID)
+	require.NoError(t, err)
+
+	newLimit := 1500.0
+	_, err = accountQueries.UpdateAccount(ctx, "test-account-limit-history", &api.UpdateAccountRequest{BudgetLimit: &newLimit})
+	require.NoError(t, err)
+
+	history, err := service.GetLimitHistory(ctx, "test-account-limit-history")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Nil(t, history[0].PreviousLimit)
+	assert.InDelta(t, 1000.0, history[0].BudgetLimit, 0.001)
+	require.NotNil(t, history[1].PreviousLimit)
+	assert.InDelta(t, 1000.0, *history[1].PreviousLimit, 0.001)
+	assert.InDelta(t, 1500.0, history[1].BudgetLimit, 0.001)
+
+	// Before the increase the snapshot has the old limit, not the current one
+	before, err := service.GetSnapshot(ctx, "test-account-limit-history", time.Now().UTC().AddDate(0, 0, -3))
+	require.NoError(t, err)
+	assert.Equal(t, "replay", before.Source)
+	assert.InDelta(t, 1000.0, before.BudgetLimit, 0.001)
+
+	after, err := service.GetSnapshot(ctx, "test-account-limit-history", time.Now().UTC())
+	require.NoError(t, err)
+	assert.InDelta(t, 1500.0, after.BudgetLimit, 0.001)
+	assert.InDelta(t, 200.0, after.BudgetUsed, 0.001)
+	assert.InDelta(t, 1300.0, after.BudgetAvailable, 0.001)
+
+	// A snapshot captured before the increase replays forward to the new limit
+	require.NoError(t, service.CaptureDailySnapshots(ctx, time.Now().UTC().AddDate(0, 0, -3)))
+	_, err = db.ExecContext(ctx, `UPDATE budget_snapshots SET budget_limit = 1000, budget_used = 0, budget_held = 0, budget_available = 1000, captured_at = NOW() - INTERVAL '2 days' WHERE account_id = $1`, account.ID)
+	require.NoError(t, err)
+	replayed, err := service.GetSnapshot(ctx, "test-account-limit-history", time.Now().UTC())
+	require.NoError(t, err)
+	assert.Equal(t, "replay", replayed.Source)
+	assert.InDelta(t, 1500.0, replayed.BudgetLimit, 0.001)
+	assert.InDelta(t, 1300.0, replayed.BudgetAvailable, 0.001)
+}
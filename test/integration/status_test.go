@@ -0,0 +1,93 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_AccountStatusTransitions(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+		SlurmAccount: "status-account",
+		Name:         "Status Account",
+		BudgetLimit:  100.0,
+		StartDate:    time.Now().Add(-24 * time.Hour),
+		EndDate:      time.Now().Add(365 * 24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	setStatus := func(status string) (*api.BudgetAccount, error) {
+		return service.UpdateAccount(ctx, "status-account", &api.UpdateAccountRequest{Status: &status})
+	}
+	check := func() (*api.BudgetCheckResponse, error) {
+		return service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: "status-account", Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+	}
+
+	t.Run("suspension blocks new holds", func(t *testing.T) {
+		_, err := check()
+		require.NoError(t, err)
+
+		_, err = setStatus("suspended")
+		require.NoError(t, err)
+
+		_, err = check()
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAccountInactive, budgetErr.Code)
+	})
+
+	t.Run("reactivation returns current availability", func(t *testing.T) {
+		account, err := setStatus("active")
+		require.NoError(t, err)
+		assert.Equal(t, "active", account.Status)
+		// The $12 hold placed before suspension is still outstanding
+		assert.InDelta(t, 88.0, account.BudgetAvailable(), 0.001)
+
+		_, err = check()
+		require.NoError(t, err)
+	})
+
+	t.Run("inactive account cannot be suspended", func(t *testing.T) {
+		_, err := setStatus("inactive")
+		require.NoError(t, err)
+
+		_, err = setStatus("suspended")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeInvalidStatusTransition, budgetErr.Code)
+	})
+
+	t.Run("expired account cannot be reactivated", func(t *testing.T) {
+		_, err := db.ExecContext(ctx, "UPDATE budget_accounts SET status = 'expired' WHERE slurm_account = $1", "status-account")
+		require.NoError(t, err)
+
+		_, err = setStatus("active")
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeInvalidStatusTransition, budgetErr.Code)
+	})
+}
@@ -0,0 +1,113 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestAccounts_TagFiltering(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	create := func(name string, limit float64, tags map[string]string) {
+		_, err := service.CreateAccount(ctx, &api.CreateAccountRequest{
+			SlurmAccount: name,
+			Name:         name,
+			BudgetLimit:  limit,
+			StartDate:    time.Now().Add(-24 * time.Hour),
+			EndDate:      time.Now().Add(365 * 24 * time.Hour),
+			Tags:         tags,
+		})
+		require.NoError(t, err)
+	}
+	create("phys101", 300.0, map[string]string{"kind": "course", "department": "physics"})
+	create("phys-lab", 1000.0, map[string]string{"kind": "research", "department": "physics", "funding": "NSF"})
+	create("chem-lab", 2000.0, map[string]string{"kind": "research", "department": "chemistry"})
+	create("untagged", 500.0, nil)
+
+	names := func(tags ...string) []string {
+		accounts, err := service.ListAccounts(ctx, &api.ListAccountsRequest{Tags: tags})
+		require.NoError(t, err)
+		var names []string
+		for _, account := range accounts {
+			names = append(names, account.SlurmAccount)
+		}
+		return names
+	}
+
+	t.Run("tags are stored and returned", func(t *testing.T) {
+		account, err := service.GetAccount(ctx, "phys101")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"kind": "course", "department": "physics"}, account.Tags)
+
+		untagged, err := service.GetAccount(ctx, "untagged")
+		require.NoError(t, err)
+		assert.Empty(t, untagged.Tags)
+	})
+
+	t.Run("list filters by key and value", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"phys101", "phys-lab"}, names("department=physics"))
+		assert.ElementsMatch(t, []string{"phys-lab"}, names("department=physics", "kind=research"))
+		assert.Empty(t, names("department=biology"))
+	})
+
+	t.Run("list filters by key alone", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"phys-lab"}, names("funding"))
+		assert.ElementsMatch(t, []string{"phys101", "phys-lab", "chem-lab"}, names("kind"))
+	})
+
+	t.Run("invalid filter is rejected", func(t *testing.T) {
+		_, err := service.ListAccounts(ctx, &api.ListAccountsRequest{Tags: []string{"=physics"}})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	})
+
+	t.Run("overview aggregates only tagged accounts", func(t *testing.T) {
+		overview, err := service.Overview(ctx, &api.OverviewRequest{Tags: []string{"kind=research"}})
+		require.NoError(t, err)
+		assert.Equal(t, 2, overview.Totals.Accounts)
+		assert.InDelta(t, 3000.0, overview.Totals.TotalAllocated, 0.001)
+		assert.Equal(t, []string{"kind=research"}, overview.Tags)
+	})
+
+	t.Run("update replaces and clears tags", func(t *testing.T) {
+		account, err := service.UpdateAccount(ctx, "phys101", &api.UpdateAccountRequest{
+			Tags: map[string]string{"kind": "course", "department": "astronomy"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"kind": "course", "department": "astronomy"}, account.Tags)
+		assert.ElementsMatch(t, []string{"phys-lab"}, names("department=physics"))
+
+		// Updating other fields leaves the tags alone
+		name := "Physics 101"
+		account, err = service.UpdateAccount(ctx, "phys101", &api.UpdateAccountRequest{Name: &name})
+		require.NoError(t, err)
+		assert.Len(t, account.Tags, 2)
+
+		account, err = service.UpdateAccount(ctx, "phys101", &api.UpdateAccountRequest{Tags: map[string]string{}})
+		require.NoError(t, err)
+		assert.Empty(t, account.Tags)
+		assert.NotContains(t, names("kind"), "phys101")
+	})
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestTransactions_CursorPagination(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	createHierarchyAccount(t, service, "paged", "", 1000)
+	createHierarchyAccount(t, service, "unpaged", "", 1000)
+
+	// 2,500 pending charges, ten to each creation instant so pages must break ties by ID,
+	// and some on another account the filter must leave out
+	const seeded = 2500
+	_, err := db.ExecContext(ctx, `
+		INSERT INTO budget_transactions (transaction_id, account_id, type, amount, description, status, created_at)
+		SELECT 'txn_paged_' || g, ba.id, 'charge', 1.00, 'Seeded charge ' || g, 'pending',
+		       TIMESTAMPTZ '2025-01-01 00:00:00+00' + (g / 10) * INTERVAL '1 second'
+		FROM generate_series(1, $1) AS g, budget_accounts ba
+		WHERE ba.slurm_account = 'paged'`, seeded)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_transactions (transaction_id, account_id, type, amount, description, status, created_at)
+		SELECT 'txn_unpaged_' || g, ba.id, 'charge', 1.00, 'Other charge ' || g, 'pending',
+		       TIMESTAMPTZ '2025-01-01 00:00:00+00' + g * INTERVAL '1 second'
+		FROM generate_series(1, 100) AS g, budget_accounts ba
+		WHERE ba.slurm_account = 'unpaged'`)
+	require.NoError(t, err)
+
+	t.Run("cursoring visits every row exactly once, newest first", func(t *testing.T) {
+		seen := make(map[int64]bool, seeded)
+		var previous *api.BudgetTransaction
+		req := &api.TransactionListRequest{Account: "paged", Limit: 97}
+		pages := 0
+		for {
+			page, err := service.ListTransactionPage(ctx, req)
+			require.NoError(t, err)
+			pages++
+			require.LessOrEqual(t, len(page.Transactions), 97)
+
+			for _, transaction := range page.Transactions {
+				require.False(t, seen[transaction.ID], "transaction %d visited twice", transaction.ID)
+				seen[transaction.ID] = true
+				if previous != nil {
+					ordered := transaction.CreatedAt.Before(previous.CreatedAt) ||
+						(transaction.CreatedAt.Equal(previous.CreatedAt) && transaction.ID < previous.ID)
+					require.True(t, ordered, "transaction %d out of order after %d", transaction.ID, previous.ID)
+				}
+				previous = transaction
+			}
+
+			if page.NextCursor == "" {
+				break
+			}
+			req.Cursor = page.NextCursor
+
+			// A transaction added mid-way is newer than the cursor, so it neither appears in
+			// nor shifts the remaining pages
+			if pages == 3 {
+				createTransaction := `
+					INSERT INTO budget_transactions (transaction_id, account_id, type, amount, description, status)
+					SELECT 'txn_paged_late', id, 'charge', 1.00, 'Late charge', 'pending'
+					FROM budget_accounts WHERE slurm_account = 'paged'`
+				_, err := db.ExecContext(ctx, createTransaction)
+				require.NoError(t, err)
+			}
+		}
+
+		assert.Len(t, seen, seeded)
+		assert.Equal(t, (seeded+96)/97, pages)
+	})
+
+	t.Run("offset pages agree with cursor pages", func(t *testing.T) {
+		first, err := service.ListTransactionPage(ctx, &api.TransactionListRequest{Account: "unpaged", Limit: 40})
+		require.NoError(t, err)
+		require.Len(t, first.Transactions, 40)
+
+		byCursor, err := service.ListTransactionPage(ctx, &api.TransactionListRequest{Account: "unpaged", Limit: 40, Cursor: first.NextCursor})
+		require.NoError(t, err)
+		byOffset, err := service.ListTransactions(ctx, &api.TransactionListRequest{Account: "unpaged", Limit: 40, Offset: 40})
+		require.NoError(t, err)
+		assert.Equal(t, byOffset, byCursor.Transactions)
+
+		last, err := service.ListTransactionPage(ctx, &api.TransactionListRequest{Account: "unpaged", Limit: 40, Offset: 80})
+		require.NoError(t, err)
+		assert.Len(t, last.Transactions, 20)
+		assert.Empty(t, last.NextCursor, "the last page has no next cursor")
+	})
+
+	t.Run("cursor and offset together", func(t *testing.T) {
+		cursor := api.TransactionCursor{CreatedAt: time.Now(), ID: 1}.Encode()
+		_, err := service.ListTransactionPage(ctx, &api.TransactionListRequest{Cursor: cursor, Offset: 10})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeValidation, budgetErr.Code)
+	})
+}
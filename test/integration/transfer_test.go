@@ -0,0 +1,152 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestBudget_AccountTransferKeepsLedgerIntact(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "xfer-dept", Name: "Department", BudgetLimit: 2000.0},
+		{SlurmAccount: "xfer-old", Name: "Old Grant", BudgetLimit: 500.0, ParentAccount: "xfer-dept"},
+		{SlurmAccount: "xfer-new", Name: "New Grant", BudgetLimit: 1000.0},
+	} {
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	// One job on the old account reconciles at $8, another still holds $12
+	check := func(account string) *api.BudgetCheckResponse {
+		resp, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: account, Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Available)
+		return resp
+	}
+	finished := check("xfer-old")
+	running := check("xfer-old")
+	_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+		JobID: "xfer-1", ActualCost: 8.0, TransactionID: finished.TransactionID,
+	})
+	require.NoError(t, err)
+
+	old, err := service.GetAccount(ctx, "xfer-old")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO budget_allocation_schedules
+			(account_id, total_budget, allocation_amount, allocation_frequency, start_date, next_allocation_date, remaining_budget)
+		VALUES ($1, 1200, 100, 'monthly', NOW(), NOW() + INTERVAL '1 month', 1200)`, old.ID)
+	require.NoError(t, err)
+
+	var transactions int
+	require.NoError(t, db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM budget_transactions WHERE account_id = $1`, old.ID).Scan(&transactions))
+
+	t.Run("inactive destination is refused", func(t *testing.T) {
+		status := "suspended"
+		_, err := service.UpdateAccount(ctx, "xfer-new", &api.UpdateAccountRequest{Status: &status})
+		require.NoError(t, err)
+
+		_, err = service.TransferAccount(ctx, "xfer-old", "xfer-new", &api.AccountTransferRequest{})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeAccountInactive, budgetErr.Code)
+
+		status = "active"
+		_, err = service.UpdateAccount(ctx, "xfer-new", &api.UpdateAccountRequest{Status: &status})
+		require.NoError(t, err)
+	})
+
+	resp, err := service.TransferAccount(ctx, "xfer-old", "xfer-new", &api.AccountTransferRequest{
+		Reason: "Grant consolidation", TransferredBy: "ops",
+	})
+	require.NoError(t, err)
+
+	t.Run("balances are combined", func(t *testing.T) {
+		assert.Equal(t, int64(transactions), resp.Transfer.TransactionsMoved)
+		assert.Equal(t, int64(1), resp.Transfer.SchedulesMerged)
+		assert.InDelta(t, 8.0, resp.Transfer.BudgetUsed, 0.001)
+		assert.InDelta(t, 12.0, resp.Transfer.BudgetHeld, 0.001)
+
+		dest := resp.Destination
+		assert.InDelta(t, 1500.0, dest.BudgetLimit, 0.001)
+		assert.InDelta(t, 8.0, dest.BudgetUsed, 0.001)
+		assert.InDelta(t, 12.0, dest.BudgetHeld, 0.001)
+		assert.True(t, dest.HasIncrementalBudget)
+		assert.NotNil(t, dest.NextAllocationDate)
+	})
+
+	t.Run("source is archived and empty", func(t *testing.T) {
+		source, err := service.GetAccount(ctx, "xfer-old")
+		require.NoError(t, err)
+		assert.Equal(t, "archived", source.Status)
+		assert.Zero(t, source.BudgetLimit)
+		assert.Zero(t, source.BudgetUsed)
+		assert.Zero(t, source.BudgetHeld)
+
+		var remaining int
+		require.NoError(t, db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM budget_transactions WHERE account_id = $1`, source.ID).Scan(&remaining))
+		assert.Zero(t, remaining)
+	})
+
+	t.Run("ledger matches cached balances everywhere", func(t *testing.T) {
+		check, err := service.CheckConsistency(ctx, &api.ConsistencyCheckRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, 0, check.Inconsistent)
+
+		dept, err := service.GetAccount(ctx, "xfer-dept")
+		require.NoError(t, err)
+		assert.Zero(t, dept.BudgetUsed)
+		assert.Zero(t, dept.BudgetHeld)
+	})
+
+	t.Run("moved hold reconciles against the destination", func(t *testing.T) {
+		_, err := service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID: "xfer-2", ActualCost: 10.0, TransactionID: running.TransactionID,
+		})
+		require.NoError(t, err)
+
+		dest, err := service.GetAccount(ctx, "xfer-new")
+		require.NoError(t, err)
+		assert.InDelta(t, 18.0, dest.BudgetUsed, 0.001)
+		assert.InDelta(t, 0.0, dest.BudgetHeld, 0.001)
+
+		result, err := service.VerifyAccountConsistency(ctx, "xfer-new")
+		require.NoError(t, err)
+		assert.True(t, result.Consistent)
+	})
+
+	t.Run("archived source cannot be transferred again", func(t *testing.T) {
+		_, err := service.TransferAccount(ctx, "xfer-old", "xfer-new", &api.AccountTransferRequest{})
+		budgetErr, ok := api.AsBudgetError(err)
+		require.True(t, ok)
+		assert.Equal(t, api.ErrCodeInvalidStatusTransition, budgetErr.Code)
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 Scott Friedman. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE file.
+
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/advisor"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/internal/budget"
+	"github.com/scttfrdmn/aws-slurm-burst-budget/pkg/api"
+)
+
+func TestUsage_ByCostComponent(t *testing.T) {
+	SkipIfNoDocker(t)
+
+	db := SetupTestDatabase(t)
+	defer TeardownTestDatabase(t, db)
+
+	ctx := context.Background()
+	cfg := SetupTestConfig()
+	service := budget.NewService(db, &advisor.MockClient{}, &cfg.Budget)
+
+	for _, req := range []*api.CreateAccountRequest{
+		{SlurmAccount: "usage-dept", Name: "Department"},
+		{SlurmAccount: "usage-a", Name: "Project A", ParentAccount: "usage-dept"},
+		{SlurmAccount: "usage-b", Name: "Project B", ParentAccount: "usage-dept"},
+	} {
+		req.BudgetLimit = 1000.0
+		req.StartDate = time.Now().Add(-24 * time.Hour)
+		req.EndDate = time.Now().Add(365 * 24 * time.Hour)
+		_, err := service.CreateAccount(ctx, req)
+		require.NoError(t, err)
+	}
+
+	// The mock advisor estimates $10, so every job holds $12
+	runJob := func(account, jobID string, actualCost float64, breakdown map[string]float64) {
+		check, err := service.CheckBudget(ctx, &api.BudgetCheckRequest{
+			Account: account, Partition: "cpu", Nodes: 1, CPUs: 1, WallTime: "01:00:00",
+		})
+		require.NoError(t, err)
+		require.True(t, check.Available)
+
+		_, err = service.ReconcileJob(ctx, &api.JobReconcileRequest{
+			JobID:         jobID,
+			ActualCost:    actualCost,
+			TransactionID: check.TransactionID,
+			CostBreakdown: breakdown,
+		})
+		require.NoError(t, err)
+	}
+	runJob("usage-a", "job-a1", 10, map[string]float64{"compute": 7, "storage": 2, "network": 1})
+	runJob("usage-b", "job-b1", 15, map[string]float64{"compute": 12, "storage": 3}) // $3 above the hold
+	runJob("usage-a", "job-a2", 5, nil)
+
+	report := func(req *api.UsageReportRequest) map[string]api.UsageBreakdownItem {
+		resp, err := service.UsageByComponent(ctx, req)
+		require.NoError(t, err)
+		items := make(map[string]api.UsageBreakdownItem)
+		for _, item := range resp.Breakdown {
+			items[item.Label] = item
+		}
+		return items
+	}
+
+	t.Run("breakdown is stored on the job's charge", func(t *testing.T) {
+		charges, err := service.ListTransactions(ctx, &api.TransactionListRequest{JobID: "job-b1", Type: "charge", Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, charges, 2)
+
+		found := 0
+		for _, charge := range charges {
+			transaction, err := service.GetTransaction(ctx, charge.TransactionID)
+			require.NoError(t, err)
+			if transaction.CostBreakdown != nil {
+				found++
+				assert.Equal(t, map[string]float64{"compute": 12, "storage": 3}, transaction.CostBreakdown)
+			}
+		}
+		assert.Equal(t, 1, found)
+	})
+
+	t.Run("all accounts", func(t *testing.T) {
+		resp, err := service.UsageByComponent(ctx, &api.UsageReportRequest{})
+		require.NoError(t, err)
+		assert.InDelta(t, 30.0, resp.Summary.TotalSpent, 0.001)
+		assert.Equal(t, int64(3), resp.Summary.TotalJobs)
+		require.NotEmpty(t, resp.Breakdown)
+		assert.Equal(t, "compute", resp.Breakdown[0].Label)
+
+		items := report(&api.UsageReportRequest{})
+		assert.InDelta(t, 19.0, items["compute"].Amount, 0.001)
+		assert.Equal(t, int64(2), items["compute"].JobCount)
+		assert.InDelta(t, 5.0, items["storage"].Amount, 0.001)
+		assert.InDelta(t, 1.0, items["network"].Amount, 0.001)
+		assert.InDelta(t, 5.0, items["unattributed"].Amount, 0.001)
+	})
+
+	t.Run("single account", func(t *testing.T) {
+		items := report(&api.UsageReportRequest{Account: "usage-b"})
+		require.Len(t, items, 2)
+		assert.InDelta(t, 80.0, items["compute"].Percentage, 0.001)
+		assert.InDelta(t, 20.0, items["storage"].Percentage, 0.001)
+	})
+
+	t.Run("parent includes its descendants", func(t *testing.T) {
+		resp, err := service.UsageByComponent(ctx, &api.UsageReportRequest{Account: "usage-dept"})
+		require.NoError(t, err)
+		assert.InDelta(t, 30.0, resp.Summary.TotalSpent, 0.001)
+		assert.InDelta(t, 3.0, resp.Summary.BudgetUtilized, 0.001)
+	})
+
+	t.Run("date range", func(t *testing.T) {
+		yesterday := time.Now().AddDate(0, 0, -1)
+		resp, err := service.UsageByComponent(ctx, &api.UsageReportRequest{EndDate: &yesterday})
+		require.NoError(t, err)
+		assert.Equal(t, 0.0, resp.Summary.TotalSpent)
+		assert.Empty(t, resp.Breakdown)
+	})
+}